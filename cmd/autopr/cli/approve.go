@@ -1,15 +1,22 @@
 package cli
 
 import (
+	"context"
 	"fmt"
 
+	"autopr/internal/config"
+	"autopr/internal/db"
 	"autopr/internal/git"
 	"autopr/internal/pipeline"
 
 	"github.com/spf13/cobra"
 )
 
-var approveDraft bool
+var (
+	approveDraft   bool
+	approveInclude []string
+	approveExclude []string
+)
 
 var approveCmd = &cobra.Command{
 	Use:   "approve <job-id>",
@@ -20,6 +27,8 @@ var approveCmd = &cobra.Command{
 
 func init() {
 	approveCmd.Flags().BoolVar(&approveDraft, "draft", false, "create PR as draft")
+	approveCmd.Flags().StringArrayVar(&approveInclude, "include", nil, "approve only this file or hunk (path or path:N); repeatable")
+	approveCmd.Flags().StringArrayVar(&approveExclude, "exclude", nil, "revert this file or hunk (path or path:N) before push; repeatable")
 	rootCmd.AddCommand(approveCmd)
 }
 
@@ -39,7 +48,15 @@ func runApprove(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	job, err := store.GetJob(cmd.Context(), jobID)
+	// Hold the job's lease so the daemon cannot record a PR or move the job
+	// while this command pushes and opens one.
+	return store.WithJobLease(cmd.Context(), jobID, db.LeaseHolder("ap approve"), func(ctx context.Context) error {
+		return approveJob(ctx, cfg, store, jobID)
+	})
+}
+
+func approveJob(ctx context.Context, cfg *config.Config, store *db.Store, jobID string) error {
+	job, err := store.GetJob(ctx, jobID)
 	if err != nil {
 		return err
 	}
@@ -48,74 +65,63 @@ func runApprove(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("job %s is in state %q, must be 'ready' to approve", jobID, job.State)
 	}
 
-	// Look up project config and issue for PR creation.
 	proj, ok := cfg.ProjectByName(job.ProjectName)
 	if !ok {
 		return fmt.Errorf("project %q not found in config", job.ProjectName)
 	}
 
-	issue, err := store.GetIssueByAPID(cmd.Context(), job.AutoPRIssueID)
-	if err != nil {
-		return fmt.Errorf("load issue: %w", err)
-	}
-
-	// Rebase onto latest base branch before pushing.
-	if err := pipeline.RebaseBeforePush(cmd.Context(), store, job.ID, job.AutoPRIssueID, proj.BaseBranch, job.WorktreePath, job.Iteration, cfg.GitTokenForProject(proj)); err != nil {
-		return fmt.Errorf("rebase before push: %w", err)
-	}
-
-	pushRemote := "origin"
-	pushHead := job.BranchName
-	if proj.GitHub != nil {
-		var err error
-		pushRemote, pushHead, err = pipeline.ResolveGitHubPushTarget(cmd.Context(), proj, job.BranchName, job.WorktreePath, cfg.Tokens.GitHub)
-		if err != nil {
-			return fmt.Errorf("resolve push target: %w", err)
+	// Partial approval: revert unselected files/hunks in a follow-up commit.
+	approval := pipeline.Approval{Approver: git.UserIdentity(ctx, job.WorktreePath), Draft: approveDraft}
+	if len(approveInclude) > 0 || len(approveExclude) > 0 {
+		if len(approveInclude) > 0 && len(approveExclude) > 0 {
+			return fmt.Errorf("--include and --exclude cannot be combined")
 		}
-	}
-
-	// Push branch to remote before creating PR.
-	if err := git.PushBranchWithLeaseToRemoteWithToken(cmd.Context(), job.WorktreePath, pushRemote, job.BranchName, cfg.GitTokenForProject(proj)); err != nil {
-		return fmt.Errorf("push branch: %w", err)
-	}
-
-	prURL := job.PRURL
-	if prURL != "" {
-		// PR already created (e.g. by auto_pr), skip creation.
-		fmt.Printf("PR already exists: %s\n", prURL)
-	} else {
-		prTitle, prBody := pipeline.BuildPRContent(cmd.Context(), store, job, issue)
-
-		// Create PR/MR depending on source.
-		prURL, err = pipeline.CreatePRForProject(cmd.Context(), cfg, proj, job, pushHead, prTitle, prBody, approveDraft)
+		excl, err := pipeline.ParseDiffSelectors(approveExclude)
 		if err != nil {
-			return fmt.Errorf("create PR: %w", err)
+			return err
 		}
-
-		// Store PR URL.
-		if prURL != "" {
-			if err := store.UpdateJobField(cmd.Context(), jobID, "pr_url", prURL); err != nil {
-				return fmt.Errorf("store PR URL: %w", err)
+		if len(approveInclude) > 0 {
+			include, err := pipeline.ParseDiffSelectors(approveInclude)
+			if err != nil {
+				return err
+			}
+			excl, err = pipeline.ExclusionsFromInclude(ctx, job.WorktreePath, pipeline.TargetBranch(job, proj), include)
+			if err != nil {
+				return err
 			}
 		}
+		approval.Exclusions = excl
 	}
 
-	// Transition to approved.
-	if err := store.TransitionState(cmd.Context(), jobID, "ready", "approved"); err != nil {
+	res, err := pipeline.Approve(ctx, cfg, store, jobID, approval)
+	if err != nil {
 		return err
 	}
 
 	if jsonOut {
 		out := map[string]string{"job_id": jobID, "state": "approved"}
-		if prURL != "" {
-			out["pr_url"] = prURL
+		if res.PRURL != "" {
+			out["pr_url"] = res.PRURL
+		}
+		if proj.IsLocal() {
+			out["branch"] = job.BranchName
 		}
 		printJSON(out)
 		return nil
 	}
+	if len(approval.Exclusions) > 0 {
+		fmt.Printf("Reverted %d excluded file(s); see `ap logs %s` for the excluded changes.\n", len(approval.Exclusions), db.ShortID(jobID))
+	}
+	if res.Existing {
+		// PR already created (e.g. by auto_pr).
+		fmt.Printf("PR already exists: %s\n", res.PRURL)
+	}
 	fmt.Printf("Job %s approved.\n", jobID)
-	if prURL != "" {
-		fmt.Printf("PR: %s\n", prURL)
+	if res.PRURL != "" {
+		fmt.Printf("PR: %s\n", res.PRURL)
+	}
+	if proj.IsLocal() {
+		fmt.Printf("Branch %s is ready in %s.\n", job.BranchName, proj.RepoURL)
 	}
 	return nil
 }
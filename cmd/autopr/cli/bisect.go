@@ -0,0 +1,75 @@
+package cli
+
+import (
+	"fmt"
+
+	"autopr/internal/db"
+	"autopr/internal/pipeline"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	bisectGood    string
+	bisectBad     string
+	bisectTestCmd string
+	bisectFix     bool
+)
+
+var bisectCmd = &cobra.Command{
+	Use:   "bisect <project>",
+	Short: "Create a job that bisects a regression and reports the culprit commit",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runBisect,
+}
+
+func init() {
+	bisectCmd.Flags().StringVar(&bisectGood, "good", "", "commit where the test command passes (required)")
+	bisectCmd.Flags().StringVar(&bisectBad, "bad", "", "commit where the test command fails (default: the project's base branch)")
+	bisectCmd.Flags().StringVar(&bisectTestCmd, "cmd", "", "test command run at each step (default: the project's test_cmd)")
+	bisectCmd.Flags().BoolVar(&bisectFix, "fix", false, "queue a fix job referencing the culprit once it is found")
+	rootCmd.AddCommand(bisectCmd)
+}
+
+func runBisect(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	proj, ok := cfg.ProjectByName(args[0])
+	if !ok {
+		return fmt.Errorf("project %q not found in config", args[0])
+	}
+	if bisectGood == "" {
+		return fmt.Errorf("--good is required")
+	}
+
+	req := pipeline.BisectRequest{Good: bisectGood, Bad: bisectBad, TestCmd: bisectTestCmd, Fix: bisectFix}
+	if req.Bad == "" {
+		req.Bad = proj.BaseBranch
+	}
+	if req.TestCmd == "" {
+		req.TestCmd = proj.TestCmd
+	}
+
+	store, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	jobID, err := pipeline.CreateBisectJob(cmd.Context(), store, proj, cfg.Daemon.MaxIterations, req)
+	if err != nil {
+		return fmt.Errorf("create bisect job: %w", err)
+	}
+
+	if jsonOut {
+		printJSON(map[string]any{"job_id": jobID, "good": req.Good, "bad": req.Bad, "cmd": req.TestCmd, "fix": req.Fix, "state": "queued"})
+		return nil
+	}
+	fmt.Printf("Bisect job %s queued (%s..%s, running %q).\n", db.ShortID(jobID), req.Good, req.Bad, req.TestCmd)
+	if req.Fix {
+		fmt.Println("A fix job is queued once the culprit commit is found.")
+	}
+	return nil
+}
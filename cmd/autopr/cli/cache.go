@@ -0,0 +1,173 @@
+package cli
+
+import (
+	"fmt"
+
+	"autopr/internal/config"
+	"autopr/internal/pipeline"
+
+	"github.com/spf13/cobra"
+)
+
+var cacheProject string
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Inspect and clear the caches shared by job worktrees",
+}
+
+var cacheListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the configured caches with their size and directory",
+	Args:  cobra.NoArgs,
+	RunE:  runCacheList,
+}
+
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear [cache...]",
+	Short: "Empty caches (all of them by default), waiting until no job uses them",
+	RunE:  runCacheClear,
+}
+
+func init() {
+	cacheCmd.PersistentFlags().StringVar(&cacheProject, "project", "", "only this project's caches")
+	cacheCmd.AddCommand(cacheListCmd)
+	cacheCmd.AddCommand(cacheClearCmd)
+	rootCmd.AddCommand(cacheCmd)
+}
+
+type cacheEntry struct {
+	Project   string `json:"project"`
+	Name      string `json:"name"`
+	Env       string `json:"env,omitempty"`
+	Link      string `json:"link,omitempty"`
+	Exclusive bool   `json:"exclusive"`
+	Dir       string `json:"dir"`
+	SizeBytes int64  `json:"size_bytes"`
+}
+
+// selectCaches returns the caches of the --project project, or of every
+// project, narrowed to names when any are given.
+func selectCaches(cfg *config.Config, names []string) ([]cacheEntry, error) {
+	if cacheProject != "" {
+		if _, ok := cfg.ProjectByName(cacheProject); !ok {
+			return nil, fmt.Errorf("project %q not found in config", cacheProject)
+		}
+	}
+	wanted := make(map[string]bool, len(names))
+	for _, name := range names {
+		wanted[name] = true
+	}
+	found := make(map[string]bool, len(names))
+	var out []cacheEntry
+	for _, p := range cfg.Projects {
+		if cacheProject != "" && p.Name != cacheProject {
+			continue
+		}
+		for _, c := range p.Caches {
+			if len(wanted) > 0 && !wanted[c.Name] {
+				continue
+			}
+			found[c.Name] = true
+			out = append(out, cacheEntry{
+				Project:   p.Name,
+				Name:      c.Name,
+				Env:       c.Env,
+				Link:      c.Link,
+				Exclusive: c.Exclusive,
+				Dir:       cfg.CacheDir(p.Name, c.Name),
+			})
+		}
+	}
+	for _, name := range names {
+		if !found[name] {
+			return nil, fmt.Errorf("no cache named %q is configured", name)
+		}
+	}
+	return out, nil
+}
+
+func runCacheList(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	caches, err := selectCaches(cfg, nil)
+	if err != nil {
+		return err
+	}
+	for i := range caches {
+		if caches[i].SizeBytes, err = pipeline.CacheSize(cfg, caches[i].Project, caches[i].Name); err != nil {
+			return err
+		}
+	}
+
+	if jsonOut {
+		if caches == nil {
+			caches = []cacheEntry{}
+		}
+		printJSON(caches)
+		return nil
+	}
+	if len(caches) == 0 {
+		fmt.Println("No caches configured (see [[projects.caches]]).")
+		return nil
+	}
+	fmt.Printf("%-20s %-16s %-24s %9s  %s\n", "PROJECT", "CACHE", "USED AS", "SIZE", "DIR")
+	for _, c := range caches {
+		fmt.Printf("%-20s %-16s %-24s %6d MiB  %s\n", truncate(c.Project, 20), truncate(c.Name, 16), truncate(cacheUsage(c), 24), c.SizeBytes>>20, c.Dir)
+	}
+	return nil
+}
+
+// cacheUsage describes how commands see a cache: "$GOMODCACHE", "node_modules",
+// or both, marked when the cache is exclusive.
+func cacheUsage(c cacheEntry) string {
+	usage := ""
+	if c.Env != "" {
+		usage = "$" + c.Env
+	}
+	if c.Link != "" {
+		if usage != "" {
+			usage += ", "
+		}
+		usage += c.Link
+	}
+	if c.Exclusive {
+		usage += " (exclusive)"
+	}
+	return usage
+}
+
+func runCacheClear(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	caches, err := selectCaches(cfg, args)
+	if err != nil {
+		return err
+	}
+	var cleared []cacheEntry
+	for _, c := range caches {
+		if err := pipeline.ClearCache(cmd.Context(), cfg, c.Project, c.Name); err != nil {
+			return err
+		}
+		cleared = append(cleared, c)
+		if !jsonOut {
+			fmt.Printf("Cleared %s cache %s.\n", c.Project, c.Name)
+		}
+	}
+
+	if jsonOut {
+		if cleared == nil {
+			cleared = []cacheEntry{}
+		}
+		printJSON(map[string]any{"cleared": cleared})
+		return nil
+	}
+	if len(cleared) == 0 {
+		fmt.Println("No caches configured (see [[projects.caches]]).")
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+
+	"autopr/internal/config"
+)
+
+func TestSelectCaches(t *testing.T) {
+	cfg := &config.Config{
+		ReposRoot: t.TempDir(),
+		Projects: []config.ProjectConfig{
+			{Name: "api", Caches: []config.ProjectCache{{Name: "gomod", Env: "GOMODCACHE"}}},
+			{Name: "web", Caches: []config.ProjectCache{{Name: "npm", Link: "node_modules", Exclusive: true}, {Name: "gomod", Env: "GOMODCACHE"}}},
+		},
+	}
+	defer func() { cacheProject = "" }()
+
+	all, err := selectCaches(cfg, nil)
+	if err != nil || len(all) != 3 {
+		t.Fatalf("selectCaches = %+v, %v; want 3 caches", all, err)
+	}
+	if all[1].Dir != cfg.CacheDir("web", "npm") || cacheUsage(all[1]) != "node_modules (exclusive)" {
+		t.Fatalf("unexpected npm cache %+v (%q)", all[1], cacheUsage(all[1]))
+	}
+
+	named, err := selectCaches(cfg, []string{"gomod"})
+	if err != nil || len(named) != 2 || named[0].Project != "api" || named[1].Project != "web" {
+		t.Fatalf("selectCaches(gomod) = %+v, %v", named, err)
+	}
+
+	cacheProject = "web"
+	if got, err := selectCaches(cfg, []string{"gomod"}); err != nil || len(got) != 1 || got[0].Project != "web" {
+		t.Fatalf("selectCaches(--project web gomod) = %+v, %v", got, err)
+	}
+	cacheProject = "api"
+	if _, err := selectCaches(cfg, []string{"npm"}); err == nil || !strings.Contains(err.Error(), `"npm"`) {
+		t.Fatalf("expected unknown cache error, got %v", err)
+	}
+	cacheProject = "missing"
+	if _, err := selectCaches(cfg, nil); err == nil {
+		t.Fatal("expected unknown project error")
+	}
+}
@@ -110,6 +110,9 @@ func cancelJobByID(ctx context.Context, store *db.Store, jobID, reposRoot string
 		if job.State == "ready" {
 			return nil, fmt.Errorf("job %s is in state %q and cannot be cancelled (use 'ap reject <job-id>')", jobID, job.State)
 		}
+		if job.State == "needs_review_oversize" {
+			return nil, fmt.Errorf("job %s is in state %q and cannot be cancelled (use 'ap oversize <job-id> reject')", jobID, job.State)
+		}
 		return nil, fmt.Errorf("job %s is in state %q and cannot be cancelled", jobID, job.State)
 	}
 	if err := store.CancelJob(ctx, jobID); err != nil {
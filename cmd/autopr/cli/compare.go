@@ -0,0 +1,157 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"autopr/internal/db"
+	"autopr/internal/git"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	compareIteration int
+	compareStat      bool
+	compareNoColor   bool
+)
+
+var compareCmd = &cobra.Command{
+	Use:   "compare <job-id>",
+	Short: "Compare a job iteration against the previous one (code, plan, review feedback)",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runCompare,
+}
+
+func init() {
+	compareCmd.Flags().IntVar(&compareIteration, "iteration", -1, "iteration to compare against its predecessor (default: latest)")
+	compareCmd.Flags().BoolVar(&compareStat, "stat", false, "show diffstat summary only for the code change")
+	compareCmd.Flags().BoolVar(&compareNoColor, "no-color", false, "disable ANSI colors")
+	rootCmd.AddCommand(compareCmd)
+}
+
+func runCompare(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	store, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	jobID, err := resolveJob(store, args[0])
+	if err != nil {
+		return err
+	}
+
+	job, err := store.GetJob(cmd.Context(), jobID)
+	if err != nil {
+		return err
+	}
+
+	iterations, err := store.ListJobIterations(cmd.Context(), jobID)
+	if err != nil {
+		return err
+	}
+	prev, cur, err := db.IterationPair(iterations, compareIteration)
+	if err != nil {
+		return err
+	}
+
+	codeDiff, diffErr := compareIterationCode(cmd, job, prev, cur)
+
+	if jsonOut {
+		out := map[string]any{
+			"job_id":         jobID,
+			"from_iteration": prev.Iteration,
+			"to_iteration":   cur.Iteration,
+			"from_commit":    prev.CommitSHA,
+			"to_commit":      cur.CommitSHA,
+			"plan_changed":   prev.Plan != cur.Plan,
+			"from_plan":      prev.Plan,
+			"to_plan":        cur.Plan,
+			"from_review":    prev.Review,
+			"to_review":      cur.Review,
+		}
+		if diffErr != nil {
+			out["diff_error"] = diffErr.Error()
+		} else if compareStat {
+			out["stat"] = codeDiff
+		} else {
+			out["diff"] = codeDiff
+		}
+		printJSON(out)
+		return nil
+	}
+
+	fmt.Printf("Job %s: iteration %d -> %d\n\n", db.ShortID(jobID), prev.Iteration, cur.Iteration)
+
+	fmt.Println("== Plan ==")
+	if prev.Plan == cur.Plan {
+		fmt.Printf("(unchanged since iteration %d)\n\n", cur.PlanIteration)
+	} else {
+		printCompareSection(fmt.Sprintf("iteration %d", prev.Iteration), prev.Plan)
+		printCompareSection(fmt.Sprintf("iteration %d", cur.Iteration), cur.Plan)
+	}
+
+	fmt.Println("== Review feedback ==")
+	printCompareSection(fmt.Sprintf("iteration %d", prev.Iteration), prev.Review)
+	printCompareSection(fmt.Sprintf("iteration %d", cur.Iteration), cur.Review)
+
+	fmt.Printf("== Code: %s..%s ==\n", shortCommit(prev.CommitSHA), shortCommit(cur.CommitSHA))
+	if diffErr != nil {
+		fmt.Printf("(%v)\n", diffErr)
+		return nil
+	}
+	if codeDiff == "" {
+		fmt.Println("(no changes)")
+		return nil
+	}
+	if compareStat || compareNoColor {
+		fmt.Print(codeDiff)
+		return nil
+	}
+	for line := range strings.SplitSeq(codeDiff, "\n") {
+		fmt.Println(colorDiffLine(line))
+	}
+	return nil
+}
+
+// compareIterationCode diffs the reviewed HEADs of two iterations. Commits from
+// before a retry are only available while the old branch still exists in the
+// current worktree, so failures are reported rather than fatal.
+func compareIterationCode(cmd *cobra.Command, job db.Job, prev, cur db.JobIteration) (string, error) {
+	if prev.CommitSHA == "" || cur.CommitSHA == "" {
+		return "", fmt.Errorf("commit not recorded for one of the iterations")
+	}
+	if job.WorktreePath == "" {
+		return "", fmt.Errorf("no worktree available (job may have been cleaned up)")
+	}
+	if _, err := os.Stat(job.WorktreePath); os.IsNotExist(err) {
+		return "", fmt.Errorf("worktree directory not found")
+	}
+	if compareStat {
+		return git.DiffStatCommits(cmd.Context(), job.WorktreePath, prev.CommitSHA, cur.CommitSHA)
+	}
+	return git.DiffCommits(cmd.Context(), job.WorktreePath, prev.CommitSHA, cur.CommitSHA)
+}
+
+func printCompareSection(label, content string) {
+	fmt.Printf("--- %s ---\n", label)
+	content = strings.TrimSpace(content)
+	if content == "" {
+		content = "(none)"
+	}
+	fmt.Println(content)
+	fmt.Println()
+}
+
+func shortCommit(sha string) string {
+	if sha == "" {
+		return "?"
+	}
+	return sha[:min(12, len(sha))]
+}
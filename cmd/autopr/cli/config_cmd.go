@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"runtime"
 
 	"github.com/spf13/cobra"
 )
@@ -26,6 +27,9 @@ func runConfig(cmd *cobra.Command, args []string) error {
 	editor := os.Getenv("EDITOR")
 	if editor == "" {
 		editor = "vi"
+		if runtime.GOOS == "windows" {
+			editor = "notepad"
+		}
 	}
 	c := exec.Command(editor, path)
 	c.Stdin = os.Stdin
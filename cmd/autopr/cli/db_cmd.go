@@ -0,0 +1,260 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"autopr/internal/daemon"
+	"autopr/internal/db"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	dbMigrateDryRun bool
+	dbBackupTo      string
+)
+
+var dbCmd = &cobra.Command{
+	Use:   "db",
+	Short: "Inspect and maintain the AutoPR database",
+}
+
+var dbMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Apply pending schema migrations (backs up the database first)",
+	Args:  cobra.NoArgs,
+	RunE:  runDBMigrate,
+}
+
+var dbBackupCmd = &cobra.Command{
+	Use:   "backup",
+	Short: "Copy the database to a backup file (safe while the daemon runs)",
+	Args:  cobra.NoArgs,
+	RunE:  runDBBackup,
+}
+
+var dbRestoreCmd = &cobra.Command{
+	Use:   "restore <backup-file>",
+	Short: "Replace the database with a backup (stop the daemon first)",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runDBRestore,
+}
+
+var dbEncryptCmd = &cobra.Command{
+	Use:   "encrypt",
+	Short: "Encrypt an existing plaintext database with the configured db_key (stop the daemon first)",
+	Long: `Rewrite the plaintext database as an SQLCipher database keyed with db_key
+from credentials.toml or AUTOPR_DB_KEY. Needs an ap build linked against
+SQLCipher (make build-sqlcipher). Earlier backups stay plaintext; delete them
+or re-take them after encrypting.`,
+	Args: cobra.NoArgs,
+	RunE: runDBEncrypt,
+}
+
+func init() {
+	dbMigrateCmd.Flags().BoolVar(&dbMigrateDryRun, "dry-run", false, "list pending migrations without applying them")
+	dbBackupCmd.Flags().StringVar(&dbBackupTo, "to", "", "backup file path (default: autopr-<timestamp>.db in daemon.backup_dir)")
+	dbCmd.AddCommand(dbMigrateCmd)
+	dbCmd.AddCommand(dbBackupCmd)
+	dbCmd.AddCommand(dbRestoreCmd)
+	dbCmd.AddCommand(dbEncryptCmd)
+	rootCmd.AddCommand(dbCmd)
+}
+
+type dbMigrateOutput struct {
+	Path           string         `json:"path"`
+	CurrentVersion int            `json:"current_version"`
+	LatestVersion  int            `json:"latest_version"`
+	Pending        []db.Migration `json:"pending"`
+	Applied        bool           `json:"applied"`
+}
+
+func runDBMigrate(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	current, pending, err := db.PendingMigrations(cfg.DBPath, cfg.DBKey)
+	if err != nil {
+		return err
+	}
+	latest, err := db.LatestSchemaVersion()
+	if err != nil {
+		return err
+	}
+	if pending == nil {
+		pending = []db.Migration{}
+	}
+	out := dbMigrateOutput{
+		Path:           cfg.DBPath,
+		CurrentVersion: current,
+		LatestVersion:  latest,
+		Pending:        pending,
+	}
+
+	if !dbMigrateDryRun && len(pending) > 0 {
+		// Open applies the migrations, backing up an existing database first.
+		store, err := openStore(cfg)
+		if err != nil {
+			return err
+		}
+		if out.CurrentVersion, err = store.SchemaVersion(); err != nil {
+			_ = store.Close()
+			return err
+		}
+		if err := store.Close(); err != nil {
+			return err
+		}
+		out.Applied = true
+	}
+
+	if jsonOut {
+		printJSON(out)
+		return nil
+	}
+	if len(pending) == 0 {
+		fmt.Printf("Database is up to date (schema version %d).\n", current)
+		return nil
+	}
+	if out.Applied {
+		fmt.Printf("Migrated %s from schema version %d to %d:\n", cfg.DBPath, current, out.CurrentVersion)
+	} else {
+		fmt.Printf("Database %s is at schema version %d; %d migration(s) pending:\n", cfg.DBPath, current, len(pending))
+	}
+	for _, m := range pending {
+		fmt.Printf("  %04d  %s\n", m.Version, m.Name)
+	}
+	if out.Applied && current > 0 {
+		fmt.Printf("The previous database was backed up to %s.bak.<timestamp>.\n", cfg.DBPath)
+	}
+	return nil
+}
+
+func runDBBackup(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(cfg.DBPath); err != nil {
+		return fmt.Errorf("database %s not found: %w", cfg.DBPath, err)
+	}
+	store, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	dest := dbBackupTo
+	if dest == "" {
+		dest = filepath.Join(cfg.Daemon.BackupDir, db.BackupFileName(time.Now()))
+	} else if dest, err = filepath.Abs(dest); err != nil {
+		return fmt.Errorf("resolve backup path: %w", err)
+	}
+	if dest == cfg.DBPath {
+		return fmt.Errorf("backup path must differ from the database path")
+	}
+	path, err := store.Backup(cmd.Context(), dest)
+	if err != nil {
+		return err
+	}
+
+	if jsonOut {
+		printJSON(map[string]any{"backup": path})
+		return nil
+	}
+	fmt.Printf("Database backed up to %s\n", path)
+	return nil
+}
+
+func runDBRestore(cmd *cobra.Command, args []string) error {
+	src, err := filepath.Abs(args[0])
+	if err != nil {
+		return fmt.Errorf("resolve backup path: %w", err)
+	}
+	if _, err := os.Stat(src); err != nil {
+		return fmt.Errorf("backup %s not found: %w", args[0], err)
+	}
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	if src == cfg.DBPath {
+		return fmt.Errorf("backup path must differ from the database path")
+	}
+	if pid, err := resolveStopPID(cfg); err == nil && daemon.ProcessAlive(pid) {
+		return fmt.Errorf("daemon is running (PID %d); stop it with `ap stop` before restoring", pid)
+	}
+
+	// Keep the current database so a wrong restore can be undone.
+	var previous string
+	if _, err := os.Stat(cfg.DBPath); err == nil {
+		store, err := openStore(cfg)
+		if err != nil {
+			return err
+		}
+		previous, err = store.Backup(cmd.Context(), "")
+		_ = store.Close()
+		if err != nil {
+			return fmt.Errorf("back up current database: %w", err)
+		}
+	}
+
+	if err := db.Restore(cmd.Context(), cfg.DBPath, src, cfg.DBKey); err != nil {
+		return err
+	}
+	// Opening the restored database applies any migrations it is missing.
+	store, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	version, err := store.SchemaVersion()
+	_ = store.Close()
+	if err != nil {
+		return err
+	}
+
+	if jsonOut {
+		printJSON(map[string]any{"restored": src, "previous_backup": previous, "schema_version": version})
+		return nil
+	}
+	fmt.Printf("Restored %s from %s (schema version %d).\n", cfg.DBPath, src, version)
+	if previous != "" {
+		fmt.Printf("The replaced database was saved to %s.\n", previous)
+	}
+	return nil
+}
+
+func runDBEncrypt(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	if cfg.DBKey == "" {
+		return fmt.Errorf("no database key: set db_key in credentials.toml or AUTOPR_DB_KEY")
+	}
+	if _, err := os.Stat(cfg.DBPath); err != nil {
+		return fmt.Errorf("database %s not found: %w", cfg.DBPath, err)
+	}
+	if pid, err := resolveStopPID(cfg); err == nil && daemon.ProcessAlive(pid) {
+		return fmt.Errorf("daemon is running (PID %d); stop it with `ap stop` before encrypting", pid)
+	}
+	if err := db.Encrypt(cmd.Context(), cfg.DBPath, cfg.DBKey); err != nil {
+		return err
+	}
+	// Make sure the key opens the result before reporting success.
+	store, err := openStore(cfg)
+	if err != nil {
+		return fmt.Errorf("open encrypted database: %w", err)
+	}
+	_ = store.Close()
+
+	if jsonOut {
+		printJSON(map[string]any{"encrypted": cfg.DBPath})
+		return nil
+	}
+	fmt.Printf("Encrypted %s. Keep db_key safe: the database cannot be read without it.\n", cfg.DBPath)
+	return nil
+}
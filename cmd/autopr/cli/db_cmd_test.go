@@ -0,0 +1,102 @@
+package cli
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"autopr/internal/db"
+
+	"github.com/spf13/cobra"
+)
+
+func TestDBMigrateDryRunLeavesDatabaseUntouched(t *testing.T) {
+	tmp := t.TempDir()
+	dbPath := filepath.Join(tmp, "autopr.db")
+	migrateCfgPath := writeMergeConfig(t, tmp)
+
+	prevCfgPath := cfgPath
+	prevJSON := jsonOut
+	prevDryRun := dbMigrateDryRun
+	defer func() {
+		cfgPath = prevCfgPath
+		jsonOut = prevJSON
+		dbMigrateDryRun = prevDryRun
+	}()
+	cfgPath = migrateCfgPath
+	jsonOut = true
+
+	dbMigrateDryRun = true
+	if err := runDBMigrate(&cobra.Command{}, nil); err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	if current, pending, err := db.PendingMigrations(dbPath, ""); err != nil || current != 0 || len(pending) == 0 {
+		t.Fatalf("expected dry run to leave db uncreated, got current=%d pending=%d err=%v", current, len(pending), err)
+	}
+
+	dbMigrateDryRun = false
+	if err := runDBMigrate(&cobra.Command{}, nil); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	latest, err := db.LatestSchemaVersion()
+	if err != nil {
+		t.Fatalf("latest version: %v", err)
+	}
+	if current, pending, err := db.PendingMigrations(dbPath, ""); err != nil || current != latest || len(pending) != 0 {
+		t.Fatalf("expected db migrated to %d, got current=%d pending=%d err=%v", latest, current, len(pending), err)
+	}
+}
+
+func TestDBBackupAndRestore(t *testing.T) {
+	tmp := t.TempDir()
+	dbPath := filepath.Join(tmp, "autopr.db")
+	backupCfgPath := writeMergeConfig(t, tmp)
+	jobID := createMergeJobForTest(t, dbPath, "project", "7201", "failed", "", "")
+
+	prevCfgPath := cfgPath
+	prevJSON := jsonOut
+	prevTo := dbBackupTo
+	defer func() {
+		cfgPath = prevCfgPath
+		jsonOut = prevJSON
+		dbBackupTo = prevTo
+	}()
+	cfgPath = backupCfgPath
+	jsonOut = true
+	backupPath := filepath.Join(tmp, "snapshot.db")
+	dbBackupTo = backupPath
+
+	cmd := &cobra.Command{}
+	cmd.SetContext(context.Background())
+	if err := runDBBackup(cmd, nil); err != nil {
+		t.Fatalf("backup: %v", err)
+	}
+
+	store, err := db.Open(dbPath)
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if err := store.UpdateJobField(context.Background(), jobID, "error_message", "changed after backup"); err != nil {
+		t.Fatalf("update job: %v", err)
+	}
+	_ = store.Close()
+
+	if err := runDBRestore(cmd, []string{backupPath}); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	store, err = db.Open(dbPath)
+	if err != nil {
+		t.Fatalf("reopen db: %v", err)
+	}
+	defer store.Close()
+	job, err := store.GetJob(context.Background(), jobID)
+	if err != nil {
+		t.Fatalf("get job: %v", err)
+	}
+	if job.ErrorMessage == "changed after backup" {
+		t.Fatal("expected restore to roll back the job update")
+	}
+	if previous, _ := filepath.Glob(dbPath + ".bak.*"); len(previous) != 1 {
+		t.Fatalf("expected the replaced database saved alongside, got %v", previous)
+	}
+}
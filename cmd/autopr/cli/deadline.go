@@ -0,0 +1,107 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"autopr/internal/db"
+
+	"github.com/spf13/cobra"
+)
+
+var deadlineClear bool
+
+var deadlineCmd = &cobra.Command{
+	Use:   "deadline <job-id> [when]",
+	Short: "Set, clear, or show when a job is due",
+	Long: `Set the time a job is due, overriding the due date synced from its issue
+(a GitLab or Gitea due date, or a GitHub milestone's). [when] is a date such as
+2025-03-01 (the end of that day, local time), an RFC 3339 time, or a duration
+from now such as 48h or 3d. With no [when], show the job's deadline.
+
+Overdue jobs are claimed before other queued jobs, and an unfinished job that
+passes its deadline sends a deadline_overdue notification.`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: runDeadline,
+}
+
+func init() {
+	deadlineCmd.Flags().BoolVar(&deadlineClear, "clear", false, "remove the deadline set with ap deadline, falling back to the issue's due date")
+	rootCmd.AddCommand(deadlineCmd)
+}
+
+func runDeadline(cmd *cobra.Command, args []string) error {
+	if deadlineClear && len(args) > 1 {
+		return fmt.Errorf("cannot use [when] with --clear")
+	}
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	store, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	jobID, err := resolveJob(store, args[0])
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case deadlineClear:
+		if err := store.SetJobDeadline(cmd.Context(), jobID, time.Time{}); err != nil {
+			return err
+		}
+	case len(args) == 2:
+		due, err := db.ParseDeadline(args[1], time.Now())
+		if err != nil {
+			return err
+		}
+		if err := store.SetJobDeadline(cmd.Context(), jobID, due); err != nil {
+			return err
+		}
+	}
+
+	job, err := store.GetJob(cmd.Context(), jobID)
+	if err != nil {
+		return err
+	}
+	if jsonOut {
+		printJSON(map[string]any{"job_id": jobID, "deadline": job.Deadline})
+		return nil
+	}
+	if job.Deadline == "" {
+		fmt.Printf("Job %s has no deadline.\n", db.ShortID(jobID))
+		return nil
+	}
+	due, err := time.Parse(time.RFC3339, job.Deadline)
+	if err != nil {
+		return fmt.Errorf("job %s has invalid deadline %q", db.ShortID(jobID), job.Deadline)
+	}
+	fmt.Printf("Job %s is due %s (%s).\n", db.ShortID(jobID), due.Local().Format("2006-01-02 15:04 MST"), timeLeft(due, time.Now()))
+	return nil
+}
+
+// timeLeft describes how long until due, or how long ago it passed.
+func timeLeft(due, now time.Time) string {
+	d := due.Sub(now).Round(time.Minute)
+	if d < 0 {
+		return "overdue by " + formatDays(-d)
+	}
+	return formatDays(d) + " left"
+}
+
+// formatDays renders a duration in days and hours, or minutes when under an
+// hour.
+func formatDays(d time.Duration) string {
+	switch {
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd%dh", int(d.Hours()/24), int(d.Hours())%24)
+	}
+}
@@ -0,0 +1,270 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"autopr/internal/config"
+	"autopr/internal/db"
+	"autopr/internal/debugbundle"
+
+	"github.com/BurntSushi/toml"
+	"github.com/spf13/cobra"
+)
+
+var (
+	debugBundleOut           string
+	debugBundleRedactPrompts bool
+	debugBundleLogLines      int
+)
+
+var debugBundleCmd = &cobra.Command{
+	Use:   "debug-bundle <job-id>",
+	Short: "Collect a job's state, logs, and config into a tarball for a bug report",
+	Long: `Collect everything needed to diagnose a job into a tar.gz to attach to an
+AutoPR bug report: the job row, LLM sessions, artifacts, daemon log lines that
+mention the job, the config with secrets scrubbed, and tool versions.
+
+Sessions include the prompts sent to the LLM, which contain issue text and
+code. Pass --redact-prompts to leave them out.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runDebugBundle,
+}
+
+func init() {
+	debugBundleCmd.Flags().StringVarP(&debugBundleOut, "out", "o", "", "bundle path (default ./<job>.autopr-debug.tar.gz)")
+	debugBundleCmd.Flags().BoolVar(&debugBundleRedactPrompts, "redact-prompts", false, "replace LLM prompt text with its size")
+	debugBundleCmd.Flags().IntVar(&debugBundleLogLines, "log-lines", 1000, "keep at most this many daemon log lines (0 for all)")
+	rootCmd.AddCommand(debugBundleCmd)
+}
+
+type debugBundleVersions struct {
+	AutoPR string `json:"autopr"`
+	Go     string `json:"go"`
+	OS     string `json:"os"`
+	Arch   string `json:"arch"`
+	Git    string `json:"git"`
+	LLM    string `json:"llm"`
+}
+
+type debugBundleManifest struct {
+	JobID           string              `json:"job_id"`
+	Project         string              `json:"project"`
+	State           string              `json:"state"`
+	CreatedAt       string              `json:"created_at"`
+	PromptsRedacted bool                `json:"prompts_redacted"`
+	Versions        debugBundleVersions `json:"versions"`
+	Files           []string            `json:"files"`
+	Notes           []string            `json:"notes,omitempty"`
+}
+
+type debugBundleOutput struct {
+	JobID string   `json:"job_id"`
+	Path  string   `json:"path"`
+	Files []string `json:"files"`
+	Notes []string `json:"notes,omitempty"`
+}
+
+func runDebugBundle(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	store, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	jobID, err := resolveJob(store, args[0])
+	if err != nil {
+		return err
+	}
+	ctx := cmd.Context()
+	job, err := store.GetJob(ctx, jobID)
+	if err != nil {
+		return err
+	}
+
+	var files []debugbundle.File
+	var notes []string
+	addJSON := func(name string, v any) error {
+		data, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return fmt.Errorf("encode %s: %w", name, err)
+		}
+		files = append(files, debugbundle.File{Name: name, Data: append(data, '\n')})
+		return nil
+	}
+
+	if err := addJSON("job.json", job); err != nil {
+		return err
+	}
+	sessions, err := debugBundleSessions(ctx, store, jobID, debugBundleRedactPrompts)
+	if err != nil {
+		return err
+	}
+	if err := addJSON("sessions.json", sessions); err != nil {
+		return err
+	}
+	artifacts, err := store.ListArtifactsByJob(ctx, jobID)
+	if err != nil {
+		return err
+	}
+	if err := addJSON("artifacts.json", artifacts); err != nil {
+		return err
+	}
+
+	if logData, err := debugBundleLog(cfg.LogFile, jobID, debugBundleLogLines); err != nil {
+		notes = append(notes, "daemon log not included: "+err.Error())
+	} else {
+		files = append(files, debugbundle.File{Name: "daemon.log", Data: logData})
+	}
+
+	var configBuf bytes.Buffer
+	redacted := cfg.Redacted()
+	if err := toml.NewEncoder(&configBuf).Encode(redacted); err != nil {
+		return fmt.Errorf("encode config: %w", err)
+	}
+	files = append(files, debugbundle.File{Name: "config.toml", Data: configBuf.Bytes()})
+
+	manifest := debugBundleManifest{
+		JobID:           jobID,
+		Project:         job.ProjectName,
+		State:           job.State,
+		CreatedAt:       time.Now().UTC().Format(time.RFC3339),
+		PromptsRedacted: debugBundleRedactPrompts,
+		Versions:        collectDebugVersions(ctx, cfg),
+		Notes:           notes,
+	}
+	for _, f := range files {
+		manifest.Files = append(manifest.Files, f.Name)
+	}
+	if err := addJSON("manifest.json", manifest); err != nil {
+		return err
+	}
+
+	out := debugBundleOut
+	if out == "" {
+		out = db.ShortID(jobID) + ".autopr-debug.tar.gz"
+	}
+	f, err := os.OpenFile(out, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("create bundle: %w", err)
+	}
+	if err := debugbundle.Write(f, files, debugBundleSecrets(cfg)); err != nil {
+		f.Close()
+		os.Remove(out)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("write bundle: %w", err)
+	}
+
+	result := debugBundleOutput{JobID: jobID, Path: out, Files: manifest.Files, Notes: notes}
+	if jsonOut {
+		printJSON(result)
+		return nil
+	}
+	fmt.Printf("Wrote debug bundle for job %s to %s\n", db.ShortID(jobID), out)
+	for _, n := range notes {
+		fmt.Printf("note: %s\n", n)
+	}
+	if !debugBundleRedactPrompts {
+		fmt.Println("The bundle includes LLM prompts and responses; review it before sharing, or rerun with --redact-prompts.")
+	}
+	return nil
+}
+
+// debugBundleSessions loads the job's sessions with their prompts. With
+// redact set, each prompt is replaced by its size.
+func debugBundleSessions(ctx context.Context, store *db.Store, jobID string, redact bool) ([]db.LLMSession, error) {
+	list, err := store.ListSessionsByJob(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	sessions := make([]db.LLMSession, 0, len(list))
+	for _, s := range list {
+		full, err := store.GetFullSession(ctx, s.ID)
+		if err != nil {
+			return nil, err
+		}
+		if redact && full.PromptText != "" {
+			full.PromptText = fmt.Sprintf("[redacted: %d bytes]", len(full.PromptText))
+		}
+		sessions = append(sessions, full)
+	}
+	return sessions, nil
+}
+
+// debugBundleLog returns the daemon log lines that mention the job by full
+// or short ID.
+func debugBundleLog(path, jobID string, max int) ([]byte, error) {
+	if path == "" {
+		return nil, fmt.Errorf("no log_file configured")
+	}
+	if path == config.LogStderr {
+		return nil, fmt.Errorf("the daemon logs to stderr (log_file = %q)", config.LogStderr)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open log: %w", err)
+	}
+	defer f.Close()
+	return debugbundle.LogExcerpt(f, []string{jobID, db.ShortID(jobID)}, max)
+}
+
+// debugBundleSecrets lists the configured secret values to scrub from every
+// bundle file.
+func debugBundleSecrets(cfg *config.Config) []string {
+	secrets := []string{
+		cfg.Tokens.GitHub,
+		cfg.Tokens.GitLab,
+		cfg.Tokens.Gitea,
+		cfg.Tokens.Sentry,
+		cfg.Daemon.WebhookSecret,
+		cfg.DBKey,
+		cfg.ChatOps.SlackSigningSecret,
+		cfg.ChatOps.MattermostToken,
+		cfg.Notifications.WebhookURL,
+		cfg.Notifications.SlackWebhook,
+	}
+	for _, v := range cfg.Secrets {
+		secrets = append(secrets, v)
+	}
+	return secrets
+}
+
+func collectDebugVersions(ctx context.Context, cfg *config.Config) debugBundleVersions {
+	return debugBundleVersions{
+		AutoPR: fmt.Sprintf("%s (%s, %s)", version, commit, date),
+		Go:     runtime.Version(),
+		OS:     runtime.GOOS,
+		Arch:   runtime.GOARCH,
+		Git:    commandVersion(ctx, "git"),
+		LLM:    cfg.LLM.Provider + " " + commandVersion(ctx, cfg.LLM.Provider),
+	}
+}
+
+// commandVersion returns the first line of `<name> --version`, or why it
+// could not be determined.
+func commandVersion(ctx context.Context, name string) string {
+	if name == "" {
+		return "unknown"
+	}
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, name, "--version").Output()
+	if err != nil {
+		return "unavailable: " + err.Error()
+	}
+	line, _, _ := strings.Cut(strings.TrimSpace(string(out)), "\n")
+	return line
+}
@@ -0,0 +1,126 @@
+package cli
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"autopr/internal/db"
+
+	"github.com/spf13/cobra"
+)
+
+func TestDebugBundleCollectsJobStateAndScrubsSecrets(t *testing.T) {
+	tmp := t.TempDir()
+	dbPath := filepath.Join(tmp, "autopr.db")
+	logPath := filepath.Join(tmp, "autopr.log")
+	configPath := filepath.Join(tmp, "autopr.toml")
+	content := fmt.Sprintf(`db_path = %q
+log_file = %q
+
+[daemon]
+webhook_secret = "hook-secret-value"
+
+[[projects]]
+name = "project"
+repo_url = "https://github.com/autopr/placeholder"
+test_cmd = "echo ok"
+
+[projects.github]
+owner = "autopr"
+repo = "placeholder"
+`, dbPath, logPath)
+	if err := os.WriteFile(configPath, []byte(content), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	jobID, sessionOne, sessionTwo := seedLogsJobForTest(t, dbPath)
+	if err := completeLogSessionFixtures(t, dbPath, sessionOne, sessionTwo); err != nil {
+		t.Fatalf("complete sessions: %v", err)
+	}
+	log := strings.Join([]string{
+		`{"level":"INFO","msg":"running step","job":"` + jobID + `"}`,
+		`{"level":"INFO","msg":"sync done"}`,
+		`{"level":"WARN","msg":"webhook rejected","secret":"hook-secret-value","job":"` + db.ShortID(jobID) + `"}`,
+	}, "\n") + "\n"
+	if err := os.WriteFile(logPath, []byte(log), 0o644); err != nil {
+		t.Fatalf("write log: %v", err)
+	}
+
+	prevCfgPath, prevJSON := cfgPath, jsonOut
+	prevOut, prevRedact, prevLines := debugBundleOut, debugBundleRedactPrompts, debugBundleLogLines
+	t.Cleanup(func() {
+		cfgPath, jsonOut = prevCfgPath, prevJSON
+		debugBundleOut, debugBundleRedactPrompts, debugBundleLogLines = prevOut, prevRedact, prevLines
+	})
+	cfgPath, jsonOut = configPath, false
+	debugBundleOut = filepath.Join(tmp, "bundle.tar.gz")
+	debugBundleRedactPrompts = true
+	debugBundleLogLines = 100
+
+	cmd := &cobra.Command{}
+	cmd.SetContext(context.Background())
+	out := captureStdout(t, func() error { return runDebugBundle(cmd, []string{jobID}) })
+	if !strings.Contains(out, "Wrote debug bundle") {
+		t.Fatalf("unexpected output: %q", out)
+	}
+
+	files := readDebugBundle(t, debugBundleOut)
+	for _, name := range []string{"manifest.json", "job.json", "sessions.json", "artifacts.json", "daemon.log", "config.toml"} {
+		if _, ok := files[name]; !ok {
+			t.Fatalf("bundle is missing %s: %v", name, files)
+		}
+	}
+	if strings.Contains(files["sessions.json"], "Prompt for session 1") || !strings.Contains(files["sessions.json"], "[redacted: 20 bytes]") {
+		t.Fatalf("prompts not redacted: %s", files["sessions.json"])
+	}
+	if !strings.Contains(files["sessions.json"], "Response for session 1") {
+		t.Fatalf("responses missing: %s", files["sessions.json"])
+	}
+	if got := strings.Count(files["daemon.log"], "\n"); got != 2 || strings.Contains(files["daemon.log"], "sync done") {
+		t.Fatalf("unexpected log excerpt: %q", files["daemon.log"])
+	}
+	for name, data := range files {
+		if strings.Contains(data, "hook-secret-value") {
+			t.Fatalf("%s leaks the webhook secret: %s", name, data)
+		}
+	}
+	if !strings.Contains(files["manifest.json"], `"prompts_redacted": true`) {
+		t.Fatalf("unexpected manifest: %s", files["manifest.json"])
+	}
+}
+
+func readDebugBundle(t *testing.T, path string) map[string]string {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("open bundle: %v", err)
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatalf("open gzip: %v", err)
+	}
+	files := map[string]string{}
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return files
+		}
+		if err != nil {
+			t.Fatalf("read bundle: %v", err)
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			t.Fatalf("read %s: %v", hdr.Name, err)
+		}
+		files[hdr.Name] = string(data)
+	}
+}
@@ -0,0 +1,79 @@
+package cli
+
+import (
+	"fmt"
+
+	"autopr/internal/pipeline"
+
+	"github.com/spf13/cobra"
+)
+
+var decisionsComment bool
+
+var decisionsCmd = &cobra.Command{
+	Use:   "decisions <job-id>",
+	Short: "Show the non-obvious choices recorded while working on a job",
+	Long: `Print the decisions log for a job: libraries chosen, behavior changed, and
+TODOs left, as reported by each plan, implement, and review step.
+
+With --comment, post the log as a comment on the job's PR/MR.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runDecisions,
+}
+
+func init() {
+	decisionsCmd.Flags().BoolVar(&decisionsComment, "comment", false, "post the decisions log as a comment on the PR/MR")
+	rootCmd.AddCommand(decisionsCmd)
+}
+
+func runDecisions(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	store, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	jobID, err := resolveJob(store, args[0])
+	if err != nil {
+		return err
+	}
+	job, err := store.GetJob(cmd.Context(), jobID)
+	if err != nil {
+		return err
+	}
+
+	if decisionsComment {
+		proj, ok := cfg.ProjectByName(job.ProjectName)
+		if !ok {
+			return fmt.Errorf("project %q not found in config", job.ProjectName)
+		}
+		if err := pipeline.CommentDecisions(cmd.Context(), cfg, store, proj, job); err != nil {
+			return fmt.Errorf("comment decisions: %w", err)
+		}
+		if jsonOut {
+			printJSON(map[string]string{"job_id": jobID, "pr_url": job.PRURL, "commented": "true"})
+			return nil
+		}
+		fmt.Printf("Posted decisions to %s\n", job.PRURL)
+		return nil
+	}
+
+	log, err := pipeline.DecisionsLog(cmd.Context(), store, jobID)
+	if err != nil {
+		return err
+	}
+	if jsonOut {
+		printJSON(map[string]string{"job_id": jobID, "decisions": log})
+		return nil
+	}
+	if log == "" {
+		fmt.Println("No decisions recorded.")
+		return nil
+	}
+	fmt.Println(log)
+	return nil
+}
@@ -0,0 +1,83 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"autopr/internal/db"
+	"autopr/internal/git"
+
+	"github.com/spf13/cobra"
+)
+
+var deleteCmd = &cobra.Command{
+	Use:   "delete <job-id>...",
+	Short: "Move finished jobs to the trash",
+	Long: `Move approved, rejected, failed, or cancelled jobs to the trash. Trashed
+jobs are hidden from ap list, the TUI, and other job lists, and their worktrees
+are removed; their history stays until the trash is emptied.
+
+Restore a job with ap trash restore. The daemon deletes trashed jobs for good
+after [retention] deleted_jobs_after (default 30 days), and ap trash empty
+does so right away.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runDelete,
+}
+
+func init() {
+	rootCmd.AddCommand(deleteCmd)
+}
+
+func runDelete(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	store, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	ids := make([]string, 0, len(args))
+	for _, arg := range args {
+		jobID, err := resolveJob(store, arg)
+		if err != nil {
+			return err
+		}
+		if err := deleteJob(cmd.Context(), store, jobID); err != nil {
+			return err
+		}
+		ids = append(ids, jobID)
+		if !jsonOut {
+			fmt.Printf("Moved job %s to the trash.\n", db.ShortID(jobID))
+		}
+	}
+	if jsonOut {
+		printJSON(map[string]any{"deleted": ids})
+	}
+	return nil
+}
+
+// deleteJob moves a job to the trash and removes its worktree, which a
+// trashed job no longer needs.
+func deleteJob(ctx context.Context, store *db.Store, jobID string) error {
+	job, err := store.GetJob(ctx, jobID)
+	if err != nil {
+		return err
+	}
+	if err := store.DeleteJob(ctx, jobID); err != nil {
+		return err
+	}
+	if job.WorktreePath == "" {
+		return nil
+	}
+	if _, err := os.Stat(job.WorktreePath); err == nil {
+		git.RemoveJobDir(job.WorktreePath)
+	}
+	if err := store.ClearWorktreePath(ctx, jobID); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"autopr/internal/db"
+)
+
+func TestDeleteJobTrashesJobAndRemovesWorktree(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	tmp := t.TempDir()
+
+	store, err := db.Open(filepath.Join(tmp, "autopr.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer store.Close()
+
+	issueID, err := store.UpsertIssue(ctx, db.IssueUpsert{
+		ProjectName:   "myproject",
+		Source:        "github",
+		SourceIssueID: "710",
+		Title:         "delete me",
+		State:         "open",
+	})
+	if err != nil {
+		t.Fatalf("upsert issue: %v", err)
+	}
+	jobID, err := store.CreateJob(ctx, issueID, "myproject", 3)
+	if err != nil {
+		t.Fatalf("create job: %v", err)
+	}
+	wt := filepath.Join(tmp, "wt")
+	if err := os.MkdirAll(wt, 0o755); err != nil {
+		t.Fatalf("create worktree: %v", err)
+	}
+	if err := store.UpdateJobField(ctx, jobID, "worktree_path", wt); err != nil {
+		t.Fatalf("set worktree path: %v", err)
+	}
+
+	// Queued jobs are not finished and stay put.
+	if err := deleteJob(ctx, store, jobID); err == nil || !strings.Contains(err.Error(), "is queued") {
+		t.Fatalf("delete queued job: err = %v", err)
+	}
+	if _, err := os.Stat(wt); err != nil {
+		t.Fatalf("worktree of queued job removed: %v", err)
+	}
+
+	if err := store.CancelJob(ctx, jobID); err != nil {
+		t.Fatalf("cancel job: %v", err)
+	}
+	if err := deleteJob(ctx, store, jobID); err != nil {
+		t.Fatalf("delete job: %v", err)
+	}
+	job, err := store.GetJob(ctx, jobID)
+	if err != nil {
+		t.Fatalf("get job: %v", err)
+	}
+	if job.DeletedAt == "" || job.WorktreePath != "" {
+		t.Fatalf("deleted_at %q, worktree %q; want trashed with no worktree", job.DeletedAt, job.WorktreePath)
+	}
+	if _, err := os.Stat(wt); !os.IsNotExist(err) {
+		t.Fatalf("worktree not removed: %v", err)
+	}
+}
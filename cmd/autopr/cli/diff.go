@@ -65,6 +65,12 @@ func runDiff(cmd *cobra.Command, args []string) error {
 	if p, ok := cfg.ProjectByName(job.ProjectName); ok && p.BaseBranch != "" {
 		baseBranch = p.BaseBranch
 	}
+	if job.BaseBranch != "" {
+		baseBranch = job.BaseBranch
+	}
+	if job.BackportBranch != "" {
+		baseBranch = job.BackportBranch
+	}
 
 	if diffFiles && diffStat {
 		return fmt.Errorf("--files cannot be combined with --stat")
@@ -101,8 +107,8 @@ func runDiff(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
-	// Print with ANSI colors.
-	for line := range strings.SplitSeq(diffText, "\n") {
+	// Print with ANSI colors, marking LFS and submodule changes.
+	for line := range strings.SplitSeq(git.AnnotateDiff(diffText), "\n") {
 		fmt.Println(colorDiffLine(line))
 	}
 	return nil
@@ -158,11 +164,12 @@ func runDiffStat(cmd *cobra.Command, worktreePath, baseBranch, jobID string) err
 // colorDiffLine applies ANSI color codes to a diff line for CLI output.
 func colorDiffLine(line string) string {
 	const (
-		reset = "\033[0m"
-		red   = "\033[31m"
-		green = "\033[32m"
-		cyan  = "\033[36m"
-		bold  = "\033[1m"
+		reset  = "\033[0m"
+		red    = "\033[31m"
+		green  = "\033[32m"
+		yellow = "\033[33m"
+		cyan   = "\033[36m"
+		bold   = "\033[1m"
 	)
 	switch {
 	case strings.HasPrefix(line, "+++ ") || strings.HasPrefix(line, "--- "):
@@ -175,6 +182,8 @@ func colorDiffLine(line string) string {
 		return cyan + line + reset
 	case strings.HasPrefix(line, "diff --git"):
 		return bold + line + reset
+	case strings.HasPrefix(line, git.DiffNotePrefix):
+		return bold + yellow + line + reset
 	default:
 		return line
 	}
@@ -0,0 +1,158 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"autopr/internal/config"
+	"autopr/internal/db"
+	"autopr/internal/pipeline"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	enqueueProject string
+	enqueueLabels  []string
+	enqueueLimit   int
+	enqueueDryRun  bool
+)
+
+var enqueueCmd = &cobra.Command{
+	Use:   "enqueue",
+	Short: "Queue jobs for matching eligible issues now",
+	Long: `Queue jobs for synced issues that are open and eligible, without waiting for
+the next sync. Issues that already have a job (other than one whose PR was
+merged or closed) are skipped, as are issues of disabled projects.
+
+Issues are taken oldest first, up to --limit.`,
+	Args: cobra.NoArgs,
+	RunE: runEnqueue,
+}
+
+func init() {
+	enqueueCmd.Flags().StringVar(&enqueueProject, "project", "", "only issues of this project")
+	enqueueCmd.Flags().StringSliceVar(&enqueueLabels, "label", nil, "only issues with this label (repeatable; all must match)")
+	enqueueCmd.Flags().IntVar(&enqueueLimit, "limit", 10, "queue at most this many jobs (0 for no limit)")
+	enqueueCmd.Flags().BoolVar(&enqueueDryRun, "dry-run", false, "show what would be queued without queueing it")
+	rootCmd.AddCommand(enqueueCmd)
+}
+
+// enqueuedJob is one issue ap enqueue queued (or would queue, with --dry-run).
+type enqueuedJob struct {
+	JobID         string `json:"job_id,omitempty"`
+	IssueID       string `json:"issue_id"`
+	Project       string `json:"project"`
+	Source        string `json:"source"`
+	SourceIssueID string `json:"source_issue_id"`
+	Title         string `json:"title"`
+}
+
+func runEnqueue(cmd *cobra.Command, args []string) error {
+	if enqueueLimit < 0 {
+		return fmt.Errorf("invalid --limit %d; expected >= 0", enqueueLimit)
+	}
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	if enqueueProject != "" {
+		if _, ok := cfg.ProjectByName(enqueueProject); !ok {
+			return fmt.Errorf("project %q not found in config", enqueueProject)
+		}
+	}
+	store, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	queued, skipped, err := enqueueIssues(cmd.Context(), store, cfg, enqueueProject, enqueueLabels, enqueueLimit, enqueueDryRun)
+	if err != nil {
+		return err
+	}
+
+	if jsonOut {
+		printJSON(map[string]any{"enqueued": queued, "skipped": skipped, "dry_run": enqueueDryRun})
+		return nil
+	}
+	if len(queued) == 0 {
+		fmt.Printf("No issues to queue (%d matching issues already have a job).\n", skipped)
+		return nil
+	}
+	verb := "Queued"
+	if enqueueDryRun {
+		verb = "Would queue"
+	}
+	fmt.Printf("%-8s %-13s %-10s %-8s %s\n", "JOB", "PROJECT", "SOURCE", "ISSUE", "TITLE")
+	for _, q := range queued {
+		job := "-"
+		if q.JobID != "" {
+			job = db.ShortID(q.JobID)
+		}
+		fmt.Printf("%-8s %-13s %-10s %-8s %s\n", job, truncate(q.Project, 13), q.Source, "#"+strings.TrimPrefix(q.SourceIssueID, "#"), truncate(q.Title, 60))
+	}
+	fmt.Printf("%s %d jobs; skipped %d issues that already have a job.\n", verb, len(queued), skipped)
+	return nil
+}
+
+// enqueueIssues queues a job for each issue that carries every label in
+// labels and passes pipeline.CheckEnqueue, oldest issue first, stopping
+// after limit jobs (0 means no limit). It returns the issues queued and how
+// many matching issues were skipped because they already have a job.
+func enqueueIssues(ctx context.Context, store *db.Store, cfg *config.Config, project string, labels []string, limit int, dryRun bool) ([]enqueuedJob, int, error) {
+	eligible := true
+	issues, err := store.ListIssues(ctx, project, &eligible)
+	if err != nil {
+		return nil, 0, err
+	}
+	slices.SortStableFunc(issues, func(a, b db.Issue) int { return strings.Compare(a.SourceUpdated, b.SourceUpdated) })
+
+	queued := []enqueuedJob{}
+	skipped := 0
+	for _, issue := range issues {
+		if limit > 0 && len(queued) >= limit {
+			break
+		}
+		if !hasAllLabels(issue.Labels(), labels) {
+			continue
+		}
+		q := enqueuedJob{
+			IssueID:       issue.AutoPRIssueID,
+			Project:       issue.ProjectName,
+			Source:        issue.Source,
+			SourceIssueID: issue.SourceIssueID,
+			Title:         issue.Title,
+		}
+		if dryRun {
+			_, err = pipeline.CheckEnqueue(ctx, cfg, store, issue)
+		} else {
+			q.JobID, err = pipeline.EnqueueIssue(ctx, cfg, store, issue)
+		}
+		var notQueueable *pipeline.NotQueueableError
+		switch {
+		case errors.Is(err, pipeline.ErrIssueHasJob):
+			skipped++
+			continue
+		case errors.As(err, &notQueueable):
+			continue
+		case err != nil:
+			return nil, 0, err
+		}
+		queued = append(queued, q)
+	}
+	return queued, skipped, nil
+}
+
+// hasAllLabels reports whether have contains every label in want, ignoring case.
+func hasAllLabels(have, want []string) bool {
+	for _, w := range want {
+		if !slices.ContainsFunc(have, func(h string) bool { return strings.EqualFold(h, w) }) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,81 @@
+package cli
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"autopr/internal/config"
+	"autopr/internal/db"
+)
+
+func TestEnqueueIssuesFiltersAndSkipsDuplicates(t *testing.T) {
+	store, err := db.Open(filepath.Join(t.TempDir(), "autopr.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer store.Close()
+	ctx := context.Background()
+
+	ineligible := false
+	seed := []db.IssueUpsert{
+		{SourceIssueID: "1", Title: "old debt", Labels: []string{"Tech-Debt"}, SourceUpdated: "2026-01-01T00:00:00Z"},
+		{SourceIssueID: "2", Title: "has job", Labels: []string{"tech-debt"}, SourceUpdated: "2026-01-02T00:00:00Z"},
+		{SourceIssueID: "3", Title: "closed", Labels: []string{"tech-debt"}, State: "closed", SourceUpdated: "2026-01-03T00:00:00Z"},
+		{SourceIssueID: "4", Title: "other label", Labels: []string{"bug"}, SourceUpdated: "2026-01-04T00:00:00Z"},
+		{SourceIssueID: "5", Title: "skipped", Labels: []string{"tech-debt"}, Eligible: &ineligible, SkipReason: "excluded", SourceUpdated: "2026-01-05T00:00:00Z"},
+		{SourceIssueID: "6", Title: "new debt", Labels: []string{"tech-debt"}, SourceUpdated: "2026-01-06T00:00:00Z"},
+		{SourceIssueID: "7", Title: "newest debt", Labels: []string{"tech-debt"}, SourceUpdated: "2026-01-07T00:00:00Z"},
+	}
+	ids := map[string]string{}
+	for _, in := range seed {
+		in.ProjectName, in.Source, in.URL = "project", "github", "https://example.com/"+in.SourceIssueID
+		if in.State == "" {
+			in.State = "open"
+		}
+		id, err := store.UpsertIssue(ctx, in)
+		if err != nil {
+			t.Fatalf("upsert issue %s: %v", in.SourceIssueID, err)
+		}
+		ids[in.SourceIssueID] = id
+	}
+	if _, err := store.CreateJob(ctx, ids["2"], "project", 3); err != nil {
+		t.Fatalf("create job: %v", err)
+	}
+
+	cfg := &config.Config{Projects: []config.ProjectConfig{{Name: "project"}}}
+	cfg.Daemon.MaxIterations = 3
+
+	dry, skipped, err := enqueueIssues(ctx, store, cfg, "project", []string{"tech-debt"}, 2, true)
+	if err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	if len(dry) != 2 || dry[0].SourceIssueID != "1" || dry[1].SourceIssueID != "6" || dry[0].JobID != "" || skipped != 1 {
+		t.Fatalf("dry run = %+v skipped %d; want issues 1 and 6 without jobs, 1 skipped", dry, skipped)
+	}
+
+	queued, _, err := enqueueIssues(ctx, store, cfg, "project", []string{"tech-debt"}, 0, false)
+	if err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	if len(queued) != 3 {
+		t.Fatalf("queued %d jobs, want 3: %+v", len(queued), queued)
+	}
+	for _, q := range queued {
+		job, err := store.GetJob(ctx, q.JobID)
+		if err != nil {
+			t.Fatalf("get job for issue %s: %v", q.SourceIssueID, err)
+		}
+		if job.State != "queued" || job.AutoPRIssueID != ids[q.SourceIssueID] {
+			t.Fatalf("job for issue %s = %+v", q.SourceIssueID, job)
+		}
+	}
+
+	again, skipped, err := enqueueIssues(ctx, store, cfg, "project", []string{"tech-debt"}, 0, false)
+	if err != nil {
+		t.Fatalf("enqueue again: %v", err)
+	}
+	if len(again) != 0 || skipped != 4 {
+		t.Fatalf("second enqueue = %+v skipped %d; want nothing queued, 4 skipped", again, skipped)
+	}
+}
@@ -0,0 +1,135 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"autopr/internal/csvexport"
+	"autopr/internal/db"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	exportFormat  string
+	exportRange   string
+	exportProject string
+	exportOut     string
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export jobs and LLM sessions as CSV for spreadsheets",
+	Long: `Write jobs.csv and sessions.csv for the jobs created within --range.
+
+jobs.csv has one row per job: its issue, state and outcome, seconds spent
+queued and running, LLM seconds per step, session count, token totals, and an
+estimated cost. sessions.csv has one row per LLM session with its step,
+iteration, provider, status, duration, tokens, and estimated cost. Issue
+titles and tags that a spreadsheet would evaluate as a formula are prefixed
+with '.`,
+	Args: cobra.NoArgs,
+	RunE: runExport,
+}
+
+func init() {
+	exportCmd.Flags().StringVar(&exportFormat, "format", "csv", "output format (csv)")
+	exportCmd.Flags().StringVar(&exportRange, "range", "30d", "include jobs created this far back, e.g. 30d or 72h; \"all\" for every job")
+	exportCmd.Flags().StringVar(&exportProject, "project", "", "only include this project")
+	exportCmd.Flags().StringVarP(&exportOut, "out", "o", ".", "directory to write the files to")
+	rootCmd.AddCommand(exportCmd)
+}
+
+func runExport(cmd *cobra.Command, args []string) error {
+	if exportFormat != "csv" {
+		return fmt.Errorf("invalid --format %q (expected csv)", exportFormat)
+	}
+	window, err := parseExportRange(exportRange)
+	if err != nil {
+		return err
+	}
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	if exportProject != "" {
+		if _, ok := cfg.ProjectByName(exportProject); !ok {
+			return fmt.Errorf("unknown project %q", exportProject)
+		}
+	}
+	store, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	since := ""
+	if window > 0 {
+		since = time.Now().UTC().Add(-window).Format(time.RFC3339)
+	}
+	ctx := cmd.Context()
+	jobs, err := store.ListJobs(ctx, exportProject, "all", "created_at", true)
+	if err != nil {
+		return err
+	}
+	jobs = slices.DeleteFunc(jobs, func(j db.Job) bool { return j.CreatedAt < since })
+	sessions, err := store.ListSessionStats(ctx, exportProject, since)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(exportOut, 0o755); err != nil {
+		return fmt.Errorf("create %s: %w", exportOut, err)
+	}
+	jobsPath := filepath.Join(exportOut, "jobs.csv")
+	if err := writeExportFile(jobsPath, func(f *os.File) error { return csvexport.WriteJobs(f, jobs, sessions) }); err != nil {
+		return err
+	}
+	sessionsPath := filepath.Join(exportOut, "sessions.csv")
+	if err := writeExportFile(sessionsPath, func(f *os.File) error { return csvexport.WriteSessions(f, jobs, sessions) }); err != nil {
+		return err
+	}
+
+	if jsonOut {
+		printJSON(map[string]any{"jobs": len(jobs), "sessions": len(sessions), "files": []string{jobsPath, sessionsPath}})
+		return nil
+	}
+	fmt.Printf("Wrote %d jobs to %s and %d sessions to %s.\n", len(jobs), jobsPath, len(sessions), sessionsPath)
+	return nil
+}
+
+// parseExportRange reads --range: a number of days such as "30d", a Go
+// duration, or "all" (zero) for no limit.
+func parseExportRange(s string) (time.Duration, error) {
+	if s == "all" {
+		return 0, nil
+	}
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n > 0 {
+			return time.Duration(n) * 24 * time.Hour, nil
+		}
+	} else if d, err := time.ParseDuration(s); err == nil && d > 0 {
+		return d, nil
+	}
+	return 0, fmt.Errorf("invalid --range %q (expected e.g. 30d, 72h, or all)", s)
+}
+
+func writeExportFile(path string, write func(*os.File) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("create %s: %w", path, err)
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("write %s: %w", path, err)
+	}
+	return nil
+}
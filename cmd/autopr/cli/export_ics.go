@@ -0,0 +1,103 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"time"
+
+	"autopr/internal/ics"
+	"autopr/internal/recurring"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	exportICSOut     string
+	exportICSProject string
+	exportICSSince   time.Duration
+	exportICSAhead   time.Duration
+)
+
+var exportICSCmd = &cobra.Command{
+	Use:   "export-ics",
+	Short: "Export job activity and upcoming recurring runs as an iCalendar file",
+	Long: `Write an iCalendar (.ics) feed of AutoPR activity for calendar tools: an
+event spanning each job run that finished within --since, named for its
+outcome, an event when each job's PR merged, and a tentative event for each
+run of a recurring task within --ahead. Event UIDs are stable, so re-importing
+a newer export, or serving the file from a cron job as a subscribed calendar,
+updates events in place.`,
+	Args: cobra.NoArgs,
+	RunE: runExportICS,
+}
+
+func init() {
+	exportICSCmd.Flags().StringVarP(&exportICSOut, "out", "o", "", "file to write (default stdout)")
+	exportICSCmd.Flags().StringVar(&exportICSProject, "project", "", "only include this project")
+	exportICSCmd.Flags().DurationVar(&exportICSSince, "since", 30*24*time.Hour, "include jobs that finished or merged this far back")
+	exportICSCmd.Flags().DurationVar(&exportICSAhead, "ahead", 14*24*time.Hour, "include recurring runs scheduled this far ahead; 0 for none")
+	rootCmd.AddCommand(exportICSCmd)
+}
+
+func runExportICS(cmd *cobra.Command, args []string) error {
+	if exportICSSince < 0 || exportICSAhead < 0 {
+		return fmt.Errorf("--since and --ahead must not be negative")
+	}
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	if exportICSProject != "" {
+		if _, ok := cfg.ProjectByName(exportICSProject); !ok {
+			return fmt.Errorf("unknown project %q", exportICSProject)
+		}
+	}
+	store, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	ctx := cmd.Context()
+	now := time.Now().UTC()
+	since := now.Add(-exportICSSince)
+	jobs, err := store.ListJobActivity(ctx, exportICSProject, since.Format(time.RFC3339))
+	if err != nil {
+		return err
+	}
+	events := ics.JobEvents(jobs, since)
+
+	if exportICSAhead > 0 {
+		overrides, err := store.ProjectEnabledOverrides(ctx)
+		if err != nil {
+			return err
+		}
+		runs := recurring.Upcoming(cfg, overrides, now, now.Add(exportICSAhead))
+		if exportICSProject != "" {
+			runs = slices.DeleteFunc(runs, func(r recurring.Activation) bool { return r.Project != exportICSProject })
+		}
+		events = append(events, ics.ScheduleEvents(runs)...)
+	}
+
+	if exportICSOut == "" {
+		return ics.Write(os.Stdout, events, now)
+	}
+	f, err := os.Create(exportICSOut)
+	if err != nil {
+		return fmt.Errorf("create %s: %w", exportICSOut, err)
+	}
+	if err := ics.Write(f, events, now); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("write %s: %w", exportICSOut, err)
+	}
+	if jsonOut {
+		printJSON(map[string]any{"path": exportICSOut, "events": len(events)})
+		return nil
+	}
+	fmt.Printf("Wrote %d events to %s.\n", len(events), exportICSOut)
+	return nil
+}
@@ -0,0 +1,30 @@
+package cli
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseExportRange(t *testing.T) {
+	for _, tc := range []struct {
+		in   string
+		want time.Duration
+	}{
+		{in: "30d", want: 30 * 24 * time.Hour},
+		{in: "72h", want: 72 * time.Hour},
+		{in: "all", want: 0},
+	} {
+		got, err := parseExportRange(tc.in)
+		if err != nil {
+			t.Fatalf("parseExportRange(%q): unexpected error: %v", tc.in, err)
+		}
+		if got != tc.want {
+			t.Fatalf("parseExportRange(%q): expected %v, got %v", tc.in, tc.want, got)
+		}
+	}
+	for _, in := range []string{"", "0d", "-3d", "1.5d", "-1h", "month"} {
+		if _, err := parseExportRange(in); err == nil {
+			t.Fatalf("parseExportRange(%q): expected an error", in)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package cli
+
+import (
+	"fmt"
+
+	"autopr/internal/db"
+	"autopr/internal/pipeline"
+
+	"github.com/spf13/cobra"
+)
+
+var followUpCmd = &cobra.Command{
+	Use:   "follow-up <job-id> <instructions>",
+	Short: "Create a linked follow-up job from a merged job",
+	Args:  cobra.ExactArgs(2),
+	RunE:  runFollowUp,
+}
+
+func init() {
+	rootCmd.AddCommand(followUpCmd)
+}
+
+func runFollowUp(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	store, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	jobID, err := resolveJob(store, args[0])
+	if err != nil {
+		return err
+	}
+
+	job, err := store.GetJob(cmd.Context(), jobID)
+	if err != nil {
+		return err
+	}
+
+	proj, ok := cfg.ProjectByName(job.ProjectName)
+	if !ok {
+		return fmt.Errorf("project %q not found in config", job.ProjectName)
+	}
+
+	newID, err := pipeline.CreateFollowUpJob(cmd.Context(), store, job, pipeline.TargetBranch(job, proj), args[1])
+	if err != nil {
+		return fmt.Errorf("create follow-up: %w", err)
+	}
+
+	if jsonOut {
+		printJSON(map[string]string{"job_id": newID, "parent_job_id": jobID, "state": "queued"})
+		return nil
+	}
+	fmt.Printf("Follow-up job %s queued (from %s).\n", db.ShortID(newID), db.ShortID(jobID))
+	return nil
+}
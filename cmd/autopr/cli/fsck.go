@@ -0,0 +1,92 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"autopr/internal/daemon"
+	"autopr/internal/db"
+	"autopr/internal/fsck"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	fsckFix     bool
+	fsckOffline bool
+)
+
+var fsckCmd = &cobra.Command{
+	Use:   "fsck",
+	Short: "Check jobs against worktrees, remote branches, PRs, and running sessions",
+	Args:  cobra.NoArgs,
+	RunE:  runFsck,
+}
+
+func init() {
+	fsckCmd.Flags().BoolVar(&fsckFix, "fix", false, "apply the automated fix for each finding")
+	fsckCmd.Flags().BoolVar(&fsckOffline, "offline", false, "skip remote branch and PR checks")
+	rootCmd.AddCommand(fsckCmd)
+}
+
+func runFsck(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	store, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	checker := fsck.New(cfg, store)
+	checker.Remote = !fsckOffline
+	if pid, err := resolveStopPID(cfg); err == nil && daemon.ProcessAlive(pid) {
+		checker.DaemonRunning = true
+	}
+
+	findings, err := checker.Check(cmd.Context())
+	if err != nil {
+		return err
+	}
+	if fsckFix {
+		for i := range findings {
+			checker.Fix(cmd.Context(), &findings[i])
+		}
+	}
+
+	if jsonOut {
+		if findings == nil {
+			findings = []fsck.Finding{}
+		}
+		printJSON(findings)
+		return nil
+	}
+	if len(findings) == 0 {
+		fmt.Println("No problems found.")
+		return nil
+	}
+
+	fmt.Printf("%-10s %-22s %s\n", "JOB", "PROBLEM", "DETAIL")
+	fmt.Println(strings.Repeat("-", 100))
+	fixable := 0
+	for _, f := range findings {
+		fmt.Printf("%-10s %-22s %s\n", db.ShortID(f.JobID), f.Kind, f.Detail)
+		switch {
+		case f.Fixed:
+			fmt.Printf("%-10s %-22s fixed: %s\n", "", "", f.Fix)
+		case f.FixError != "":
+			fmt.Printf("%-10s %-22s fix failed: %s\n", "", "", f.FixError)
+		case f.Fix != "":
+			fixable++
+			fmt.Printf("%-10s %-22s fix: %s\n", "", "", f.Fix)
+		default:
+			fmt.Printf("%-10s %-22s needs manual attention\n", "", "")
+		}
+	}
+	if fixable > 0 {
+		fmt.Printf("\n%d finding(s) can be fixed automatically; rerun with --fix.\n", fixable)
+	}
+	return nil
+}
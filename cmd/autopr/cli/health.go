@@ -0,0 +1,117 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"time"
+
+	"autopr/internal/config"
+
+	"github.com/spf13/cobra"
+)
+
+var healthLive bool
+
+var healthCmd = &cobra.Command{
+	Use:   "health",
+	Short: "Query the running daemon's health endpoint; exits 1 unless it is ready",
+	Long: `Query the running daemon's /health endpoint (or /health/live with --live)
+and exit 1 unless it answers 200. Container HEALTHCHECKs and orchestrator
+probes can run it without curl in the image.`,
+	Args: cobra.NoArgs,
+	RunE: runHealth,
+}
+
+func init() {
+	healthCmd.Flags().BoolVar(&healthLive, "live", false, "only check that the daemon answers (liveness)")
+	rootCmd.AddCommand(healthCmd)
+}
+
+// healthProbeURL returns the daemon's health URL. A wildcard bind address is
+// probed over loopback.
+func healthProbeURL(d config.DaemonConfig, live bool) string {
+	host := d.WebhookBind
+	if ip := net.ParseIP(host); ip != nil && ip.IsUnspecified() {
+		host = "127.0.0.1"
+		if ip.To4() == nil {
+			host = "::1"
+		}
+	}
+	path := "/health"
+	if live {
+		path = "/health/live"
+	}
+	return "http://" + net.JoinHostPort(host, strconv.Itoa(d.WebhookPort)) + path
+}
+
+func runHealth(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	url := healthProbeURL(cfg.Daemon, healthLive)
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Get(url)
+	if err != nil {
+		return fmt.Errorf("daemon not reachable at %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("read health response: %w", err)
+	}
+
+	if jsonOut {
+		os.Stdout.Write(body)
+	} else {
+		printHealthSummary(body, resp.StatusCode)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return &exitCodeError{code: 1}
+	}
+	return nil
+}
+
+// printHealthSummary prints whether the daemon is ready and every check that
+// is not ok.
+func printHealthSummary(body []byte, statusCode int) {
+	var report struct {
+		Ready         bool `json:"ready"`
+		UptimeSeconds int  `json:"uptime_seconds"`
+		Checks        map[string]struct {
+			Status string `json:"status"`
+			Detail string `json:"detail"`
+		} `json:"checks"`
+	}
+	if err := json.Unmarshal(body, &report); err != nil {
+		fmt.Printf("HTTP %d: %s\n", statusCode, truncate(string(body), 200))
+		return
+	}
+	uptime := (time.Duration(report.UptimeSeconds) * time.Second).String()
+	switch {
+	case healthLive && statusCode == http.StatusOK:
+		fmt.Printf("Daemon alive (up %s).\n", uptime)
+	case statusCode == http.StatusOK:
+		fmt.Printf("Daemon ready (up %s).\n", uptime)
+	default:
+		fmt.Printf("Daemon not ready (HTTP %d).\n", statusCode)
+	}
+	for _, name := range slices.Sorted(maps.Keys(report.Checks)) {
+		check := report.Checks[name]
+		if check.Status == "ok" {
+			continue
+		}
+		if check.Detail != "" {
+			fmt.Printf("  %-14s %s: %s\n", name, check.Status, check.Detail)
+		} else {
+			fmt.Printf("  %-14s %s\n", name, check.Status)
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package cli
+
+import (
+	"testing"
+
+	"autopr/internal/config"
+)
+
+func TestHealthProbeURL(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		bind string
+		live bool
+		want string
+	}{
+		{"127.0.0.1", false, "http://127.0.0.1:9847/health"},
+		{"0.0.0.0", true, "http://127.0.0.1:9847/health/live"},
+		{"::", false, "http://[::1]:9847/health"},
+		{"10.0.0.5", false, "http://10.0.0.5:9847/health"},
+	} {
+		got := healthProbeURL(config.DaemonConfig{WebhookBind: tc.bind, WebhookPort: 9847}, tc.live)
+		if got != tc.want {
+			t.Fatalf("healthProbeURL(%q, %t) = %q, want %q", tc.bind, tc.live, got, tc.want)
+		}
+	}
+}
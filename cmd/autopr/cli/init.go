@@ -36,6 +36,9 @@ func runInit(cmd *cobra.Command, args []string) error {
 
 // runGlobalInit is the interactive wizard for ~/.config/autopr/.
 func runGlobalInit() error {
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return fmt.Errorf("ap init is interactive and needs a terminal; without one, write config.toml by hand (see autopr.toml.example) and pass tokens as env vars like GITHUB_TOKEN")
+	}
 	reader := bufio.NewReader(os.Stdin)
 
 	configDir, err := config.ConfigDir()
@@ -140,7 +143,7 @@ func runGlobalInit() error {
 	}
 
 	fmt.Println("\nNext steps:")
-	fmt.Printf("  1. Edit your config to add projects: ap config\n")
+	fmt.Printf("  1. Add a project from its checkout:  ap init project <dir>\n")
 	if serviceInstalled {
 		fmt.Printf("  2. Check service status:             ap service status\n")
 		fmt.Printf("  3. Start the TUI:                    ap tui\n")
@@ -185,7 +188,7 @@ func maybeInstallServiceFromInit(
 	cfgPath string,
 	installFn func(*config.Config, string) error,
 ) (bool, error) {
-	if goos != "darwin" {
+	if !serviceSupported(goos) {
 		return false, nil
 	}
 
@@ -199,7 +202,8 @@ func maybeInstallServiceFromInit(
 	if err := installFn(cfg, cfgPath); err != nil {
 		return false, err
 	}
-	fmt.Fprintf(out, "Service installed: %s\n", "io.autopr.daemon")
+	label, _ := serviceNames(goos)
+	fmt.Fprintf(out, "Service installed: %s\n", label)
 	return true, nil
 }
 
@@ -207,7 +211,7 @@ const configTemplate = `# AutoPR configuration
 # See: https://github.com/ashwath-ramesh/autopr
 #
 # Tokens: store in ~/.config/autopr/credentials.toml or set env vars
-# (GITHUB_TOKEN, GITLAB_TOKEN, SENTRY_TOKEN, AUTOPR_WEBHOOK_SECRET)
+# (GITHUB_TOKEN, GITLAB_TOKEN, GITEA_TOKEN, SENTRY_TOKEN, AUTOPR_WEBHOOK_SECRET)
 #
 # Data files (DB, repos) default to ~/.local/share/autopr/
 # State files (logs, PID) default to ~/.local/state/autopr/
@@ -230,17 +234,27 @@ ci_check_timeout = "30m"        # max wait for CI checks before rejecting
 # [sentry]
 # base_url = "https://sentry.io"  # uncomment for self-hosted Sentry
 
+# [network]                     # corporate proxy / custom CA (defaults to HTTP(S)_PROXY env vars)
+# https_proxy = "http://proxy.corp:3128"
+# no_proxy = "gitlab.internal"
+# ca_bundle = "/etc/ssl/corp-ca.pem"
+
+# [github_app]                  # act as a GitHub App instead of using GITHUB_TOKEN
+# app_id = 123456
+# private_key_path = "/path/to/app.pem"
+
 [llm]
 provider = "codex"              # codex|claude
+# summarize_jobs = true         # one-line LLM summary of each job, shown instead of the issue title
 
 [notifications]
 # webhook_url = "https://example.com/hook"                     # generic JSON webhook
 # slack_webhook = "https://hooks.slack.com/services/..."       # Slack incoming webhook
 # desktop = true                                                # macOS desktop notifications
-# triggers = ["needs_pr", "failed", "pr_created", "pr_merged"]
+# triggers = ["needs_pr", "failed", "pr_created", "pr_merged", "ci_stuck", "queue_stale", "daemon_recovered", "deadline_overdue"]
 # Set triggers = [] to disable all notifications.
 
-# Issue gating: by default, only issues labeled "autopr" (GitHub/GitLab) are
+# Issue gating: by default, only issues labeled "autopr" (GitHub/GitLab/Gitea) are
 # processed, and issues labeled "autopr-skip" are skipped. Exclusion has precedence.
 # Set include_labels = [] in [projects.*] and exclude_labels = [] in [[projects]]
 # to disable label gating entirely.
@@ -301,4 +315,17 @@ base_branch = "main"
 #   # include_labels defaults to ["autopr"] -- label issues "autopr" to process them
 #   # include_labels = ["bug"]    # custom: only process issues labeled "bug"
 #   # include_labels = []             # opt-out: process ALL open issues
+
+# --- Gitea / Forgejo example ---
+# [[projects]]
+# name = "my-gitea-project"
+# repo_url = "https://gitea.example.com/org/repo.git"
+# test_cmd = "make test"
+# base_branch = "main"
+#
+#   [projects.gitea]
+#   base_url = "https://gitea.example.com"
+#   owner = "org"
+#   repo = "repo"
+#   # include_labels defaults to ["autopr"] -- label issues "autopr" to process them
 `
@@ -0,0 +1,197 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"autopr/internal/config"
+	"autopr/internal/onboard"
+	"autopr/internal/pipeline"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	initProjectYes     bool
+	initProjectNoSmoke bool
+)
+
+var initProjectCmd = &cobra.Command{
+	Use:   "project [dir]",
+	Short: "Add the git repository in dir (default: current directory) as a project",
+	Long: "Detects the repository's remote, default branch, and test command, asks to confirm each, " +
+		"appends a [[projects]] block to the config, and dry-runs a job: clone, checkout, and tests, without an LLM session or push.",
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir := "."
+		if len(args) == 1 {
+			dir = args[0]
+		}
+		return runInitProject(cmd, dir, os.Stdin, os.Stdout)
+	},
+}
+
+func init() {
+	initProjectCmd.Flags().BoolVarP(&initProjectYes, "yes", "y", false, "accept the detected settings without prompting")
+	initProjectCmd.Flags().BoolVar(&initProjectNoSmoke, "no-smoke", false, "skip the dry-run smoke job")
+	initCmd.AddCommand(initProjectCmd)
+}
+
+type initProjectOutput struct {
+	Project    string               `json:"project"`
+	ConfigPath string               `json:"config_path"`
+	Source     string               `json:"source"`
+	Smoke      []pipeline.SmokeStep `json:"smoke,omitempty"`
+}
+
+func runInitProject(cmd *cobra.Command, dir string, in io.Reader, out io.Writer) error {
+	path := cfgPath
+	if path == "" {
+		var err error
+		if path, err = config.GlobalConfigPath(); err != nil {
+			return err
+		}
+	}
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("no config at %s; run `ap init` first", path)
+	}
+	existing, err := config.LoadMinimal(path)
+	if err != nil {
+		return err
+	}
+
+	ctx := cmd.Context()
+	p, err := onboard.Detect(ctx, dir)
+	if err != nil {
+		return err
+	}
+
+	reader := bufio.NewReader(in)
+	ask := func(label, def string) string {
+		if initProjectYes {
+			return def
+		}
+		fmt.Fprintf(out, "%s [%s]: ", label, def)
+		answer, _ := reader.ReadString('\n')
+		if answer = strings.TrimSpace(answer); answer != "" {
+			return answer
+		}
+		return def
+	}
+
+	fmt.Fprintf(out, "Repository: %s\n", p.RepoDir)
+	p.Name = ask("Project name", p.Name)
+	p.RepoURL = ask("Repo URL", p.RepoURL)
+	p.BaseBranch = ask("Base branch", p.BaseBranch)
+	p.TestCmd = ask("Test command", p.TestCmd)
+	p.Source = ask("Issue source (github|gitlab|gitea|local)", p.Source)
+	switch p.Source {
+	case onboard.SourceGitHub, onboard.SourceGitLab, onboard.SourceGitea:
+		if p.Owner == "" || p.Repo == "" {
+			return fmt.Errorf("cannot derive %s owner/repo from %q; pick local or set the remote first", p.Source, p.RepoURL)
+		}
+		labels := ask(`Only process issues labeled (comma-separated, "none" for all issues)`, strings.Join(p.Labels, ", "))
+		p.Labels = parseLabelAnswer(labels)
+	case onboard.SourceLocal:
+		todo := p.TodoMD
+		if todo == "" {
+			todo = "none"
+		}
+		if todo = ask(`Issues file in the repo ("none" to use only ap run)`, todo); todo == "none" {
+			todo = ""
+		}
+		p.TodoMD = todo
+	default:
+		return fmt.Errorf("unknown issue source %q", p.Source)
+	}
+	if p.TestCmd == "" {
+		return fmt.Errorf("no test command detected; rerun without --yes and enter one")
+	}
+	if slices.ContainsFunc(existing.Projects, func(e config.ProjectConfig) bool { return e.Name == p.Name }) {
+		return fmt.Errorf("project %q already exists in %s", p.Name, path)
+	}
+
+	if err := appendProjectBlock(path, onboard.Block(p)); err != nil {
+		return err
+	}
+	result := initProjectOutput{Project: p.Name, ConfigPath: path, Source: p.Source}
+	if !jsonOut {
+		fmt.Fprintf(out, "Added project %s to %s\n", p.Name, path)
+	}
+
+	if !initProjectNoSmoke {
+		cfg, err := config.Load(path)
+		if err != nil {
+			return err
+		}
+		proj, _ := cfg.ProjectByName(p.Name)
+		if !jsonOut {
+			fmt.Fprintln(out, "Smoke run (no LLM session, nothing pushed):")
+		}
+		result.Smoke = pipeline.SmokeTest(ctx, cfg, proj)
+		if !jsonOut {
+			for _, step := range result.Smoke {
+				status := "ok  "
+				if !step.OK {
+					status = "FAIL"
+				}
+				fmt.Fprintf(out, "  %s %-12s %s\n", status, step.Name, step.Detail)
+			}
+		}
+	}
+
+	if jsonOut {
+		printJSON(result)
+	}
+	for _, step := range result.Smoke {
+		if !step.OK {
+			return fmt.Errorf("smoke run failed at %s; project %s was added, fix the problem or edit %s", step.Name, p.Name, path)
+		}
+	}
+	if !jsonOut {
+		fmt.Fprintln(out, "Next: start the daemon with `ap start`, or queue a task with `ap run "+p.Name+" \"...\"`.")
+	}
+	return nil
+}
+
+// parseLabelAnswer turns "bug, autopr" into labels; "none" means no gating.
+func parseLabelAnswer(answer string) []string {
+	if strings.EqualFold(strings.TrimSpace(answer), "none") {
+		return []string{}
+	}
+	var labels []string
+	for l := range strings.SplitSeq(answer, ",") {
+		if l = strings.TrimSpace(l); l != "" {
+			labels = append(labels, l)
+		}
+	}
+	return labels
+}
+
+// appendProjectBlock appends block to the config and checks the result loads,
+// restoring the original file if it does not.
+func appendProjectBlock(path, block string) error {
+	original, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read config: %w", err)
+	}
+	updated := append([]byte(nil), original...)
+	if len(updated) > 0 && updated[len(updated)-1] != '\n' {
+		updated = append(updated, '\n')
+	}
+	updated = append(updated, block...)
+	if err := os.WriteFile(path, updated, 0o644); err != nil {
+		return fmt.Errorf("write config: %w", err)
+	}
+	if _, err := config.Load(path); err != nil {
+		if rerr := os.WriteFile(path, original, 0o644); rerr != nil {
+			return fmt.Errorf("new project block is invalid (%v) and restoring the config failed: %w", err, rerr)
+		}
+		return fmt.Errorf("new project block is invalid, config left unchanged: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"autopr/internal/config"
+
+	"github.com/spf13/cobra"
+)
+
+func TestInitProjectAppendsDetectedGitHubProject(t *testing.T) {
+	tmp := t.TempDir()
+	configPath := writeMergeConfig(t, tmp)
+	repo := filepath.Join(tmp, "webapp")
+	if err := os.MkdirAll(repo, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	runGitCmd(t, repo, "init", "-q", "-b", "main")
+	runGitCmd(t, repo, "remote", "add", "origin", "git@github.com:acmecorp/webapp.git")
+	if err := os.WriteFile(filepath.Join(repo, "go.mod"), []byte("module webapp\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	prevCfgPath, prevJSON, prevYes, prevNoSmoke := cfgPath, jsonOut, initProjectYes, initProjectNoSmoke
+	cfgPath, jsonOut, initProjectYes, initProjectNoSmoke = configPath, false, true, true
+	defer func() {
+		cfgPath, jsonOut, initProjectYes, initProjectNoSmoke = prevCfgPath, prevJSON, prevYes, prevNoSmoke
+	}()
+
+	cmd := &cobra.Command{}
+	cmd.SetContext(context.Background())
+	var out bytes.Buffer
+	if err := runInitProject(cmd, repo, strings.NewReader(""), &out); err != nil {
+		t.Fatalf("init project: %v\n%s", err, out.String())
+	}
+
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	proj, ok := cfg.ProjectByName("webapp")
+	if !ok {
+		t.Fatalf("project webapp not added")
+	}
+	if proj.TestCmd != "go test ./..." || proj.GitHub == nil || proj.GitHub.Owner != "acmecorp" || proj.GitHub.Repo != "webapp" {
+		t.Fatalf("unexpected project %+v", proj)
+	}
+	if len(proj.GitHub.IncludeLabels) != 1 || proj.GitHub.IncludeLabels[0] != config.DefaultLabel {
+		t.Fatalf("expected default eligibility label, got %v", proj.GitHub.IncludeLabels)
+	}
+
+	if err := runInitProject(cmd, repo, strings.NewReader(""), &out); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Fatalf("expected duplicate project error, got %v", err)
+	}
+}
+
+func TestInitProjectPromptsAndRunsSmokeForLocalRepo(t *testing.T) {
+	tmp := t.TempDir()
+	configPath := filepath.Join(tmp, "autopr.toml")
+	content := fmt.Sprintf("db_path = %q\nrepos_root = %q\n\n[llm]\nprovider = \"codex\"\n",
+		filepath.Join(tmp, "autopr.db"), filepath.Join(tmp, "repos"))
+	if err := os.WriteFile(configPath, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// The smoke run only checks that the provider CLI is on PATH.
+	binDir := filepath.Join(tmp, "bin")
+	if err := os.MkdirAll(binDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(binDir, "codex"), []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	repo := filepath.Join(tmp, "tool")
+	if err := os.MkdirAll(repo, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	runGitCmd(t, repo, "init", "-q", "-b", "main")
+	runGitCmd(t, repo, "config", "user.email", "test@example.com")
+	runGitCmd(t, repo, "config", "user.name", "Test")
+	if err := os.WriteFile(filepath.Join(repo, "TODO.md"), []byte("- [ ] tidy\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	runGitCmd(t, repo, "add", ".")
+	runGitCmd(t, repo, "commit", "-q", "-m", "init")
+
+	prevCfgPath, prevJSON, prevYes, prevNoSmoke := cfgPath, jsonOut, initProjectYes, initProjectNoSmoke
+	cfgPath, jsonOut, initProjectYes, initProjectNoSmoke = configPath, false, false, false
+	defer func() {
+		cfgPath, jsonOut, initProjectYes, initProjectNoSmoke = prevCfgPath, prevJSON, prevYes, prevNoSmoke
+	}()
+
+	cmd := &cobra.Command{}
+	cmd.SetContext(context.Background())
+	// name, repo_url, base branch, test command, source, issues file
+	answers := "mytool\n\n\ngit status\n\n\n"
+	var out bytes.Buffer
+	if err := runInitProject(cmd, repo, strings.NewReader(answers), &out); err != nil {
+		t.Fatalf("init project: %v\n%s", err, out.String())
+	}
+	if !strings.Contains(out.String(), "ok   tests") {
+		t.Fatalf("expected passing smoke run, got:\n%s", out.String())
+	}
+
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	proj, ok := cfg.ProjectByName("mytool")
+	if !ok {
+		t.Fatalf("project mytool not added")
+	}
+	if !proj.IsLocal() || proj.TestCmd != "git status" || proj.Local.IssuesFile != filepath.Join(repo, "TODO.md") {
+		t.Fatalf("unexpected project %+v (local %+v)", proj, proj.Local)
+	}
+}
@@ -10,12 +10,12 @@ import (
 	"autopr/internal/config"
 )
 
-func TestMaybeInstallServiceFromInitSkipsOnNonDarwin(t *testing.T) {
+func TestMaybeInstallServiceFromInitSkipsOnUnsupportedOS(t *testing.T) {
 	t.Parallel()
 
 	called := false
 	installed, err := maybeInstallServiceFromInit(
-		"linux",
+		"freebsd",
 		bufio.NewReader(strings.NewReader("y\n")),
 		&bytes.Buffer{},
 		&config.Config{},
@@ -1,9 +1,12 @@
 package cli
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
+	"autopr/internal/db"
+
 	"github.com/spf13/cobra"
 )
 
@@ -98,3 +101,79 @@ func runIssues(cmd *cobra.Command, args []string) error {
 	}
 	return nil
 }
+
+var issuesClearProject string
+
+var issuesClearCmd = &cobra.Command{
+	Use:   "clear <issue>",
+	Short: "Let AutoPR work on an issue held by its issue policy again",
+	Long: `Clear the hold [projects.issue_policy] put on an issue that kept failing. The
+issue gets back the eligibility its last sync decided and a fresh job and
+failure budget. <issue> is the AutoPR issue ID or the source issue number
+(e.g. 123 or #123); pass --project when the number exists in several projects.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runIssuesClear,
+}
+
+func init() {
+	issuesClearCmd.Flags().StringVar(&issuesClearProject, "project", "", "project of the issue")
+	issuesCmd.AddCommand(issuesClearCmd)
+}
+
+func runIssuesClear(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	store, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	issue, err := findIssue(cmd.Context(), store, issuesClearProject, args[0])
+	if err != nil {
+		return err
+	}
+	cleared, err := store.ClearIssueHold(cmd.Context(), issue.AutoPRIssueID)
+	if err != nil {
+		return err
+	}
+
+	if jsonOut {
+		printJSON(map[string]any{"issue_id": issue.AutoPRIssueID, "project": issue.ProjectName, "cleared": cleared})
+		return nil
+	}
+	if !cleared {
+		fmt.Printf("Issue #%s (%s) is not held.\n", issue.SourceIssueID, issue.ProjectName)
+		return nil
+	}
+	fmt.Printf("Cleared hold on issue #%s (%s).\n", issue.SourceIssueID, issue.ProjectName)
+	return nil
+}
+
+// findIssue resolves ref, an AutoPR issue ID or a source issue number, to one
+// synced issue, optionally of project.
+func findIssue(ctx context.Context, store *db.Store, project, ref string) (db.Issue, error) {
+	ref = strings.TrimSpace(ref)
+	issues, err := store.ListIssues(ctx, project, nil)
+	if err != nil {
+		return db.Issue{}, err
+	}
+	var matches []db.Issue
+	for _, issue := range issues {
+		if issue.AutoPRIssueID == ref {
+			return issue, nil
+		}
+		if issue.SourceIssueID == strings.TrimPrefix(ref, "#") {
+			matches = append(matches, issue)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return db.Issue{}, fmt.Errorf("issue %q not found", ref)
+	case 1:
+		return matches[0], nil
+	}
+	return db.Issue{}, fmt.Errorf("issue %q matches %d issues; pass --project or use the AutoPR issue ID", ref, len(matches))
+}
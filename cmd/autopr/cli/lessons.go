@@ -0,0 +1,147 @@
+package cli
+
+import (
+	"fmt"
+	"strconv"
+
+	"autopr/internal/db"
+
+	"github.com/spf13/cobra"
+)
+
+var lessonsProject string
+
+var lessonsCmd = &cobra.Command{
+	Use:   "lessons",
+	Short: "List the lessons AutoPR learned for each project",
+	Long: `List the lessons of projects with [projects.lessons]: guidance learned from
+code reviews that asked for changes and from human feedback on jobs, such as
+"use the internal logger, not fmt.Println". The lessons that best match an
+issue are added to the prompts of its jobs.`,
+	Args: cobra.NoArgs,
+	RunE: runLessonsList,
+}
+
+var lessonsAddCmd = &cobra.Command{
+	Use:   "add --project <name> <text>",
+	Short: "Teach a project a lesson by hand",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runLessonsAdd,
+}
+
+var lessonsForgetCmd = &cobra.Command{
+	Use:   "forget <id>...",
+	Short: "Delete lessons that are wrong or out of date",
+	Args:  cobra.MinimumNArgs(1),
+	RunE:  runLessonsForget,
+}
+
+func init() {
+	lessonsCmd.PersistentFlags().StringVar(&lessonsProject, "project", "", "only this project's lessons")
+	lessonsCmd.AddCommand(lessonsAddCmd)
+	lessonsCmd.AddCommand(lessonsForgetCmd)
+	rootCmd.AddCommand(lessonsCmd)
+}
+
+func runLessonsList(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	store, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	lessons, err := store.ListLessons(cmd.Context(), lessonsProject)
+	if err != nil {
+		return err
+	}
+	if jsonOut {
+		if lessons == nil {
+			lessons = []db.Lesson{}
+		}
+		printJSON(lessons)
+		return nil
+	}
+	if len(lessons) == 0 {
+		fmt.Println("No lessons learned yet.")
+		return nil
+	}
+	fmt.Printf("%-5s %-16s %-9s %-5s %-10s %s\n", "ID", "PROJECT", "SOURCE", "SEEN", "JOB", "LESSON")
+	for _, l := range lessons {
+		fmt.Printf("%-5d %-16s %-9s %-5d %-10s %s\n", l.ID, truncate(l.ProjectName, 16), l.Source, l.TimesLearned, db.ShortID(l.JobID), l.Text)
+	}
+	return nil
+}
+
+func runLessonsAdd(cmd *cobra.Command, args []string) error {
+	if lessonsProject == "" {
+		return fmt.Errorf("--project is required")
+	}
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	proj, ok := cfg.ProjectByName(lessonsProject)
+	if !ok {
+		return fmt.Errorf("unknown project %q", lessonsProject)
+	}
+	store, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	id, isNew, err := store.AddLesson(cmd.Context(), proj.Name, args[0], db.LessonSourceManual, "")
+	if err != nil {
+		return err
+	}
+	if jsonOut {
+		printJSON(map[string]any{"project": proj.Name, "lesson_id": id, "new": isNew})
+		return nil
+	}
+	if !isNew {
+		fmt.Printf("Project %s already has lesson %d; counted it again.\n", proj.Name, id)
+	} else {
+		fmt.Printf("Added lesson %d to project %s.\n", id, proj.Name)
+	}
+	if proj.Lessons == nil {
+		fmt.Printf("Add [projects.lessons] to project %s for its jobs to use lessons.\n", proj.Name)
+	}
+	return nil
+}
+
+func runLessonsForget(cmd *cobra.Command, args []string) error {
+	ids := make([]int64, 0, len(args))
+	for _, arg := range args {
+		id, err := strconv.ParseInt(arg, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid lesson ID %q", arg)
+		}
+		ids = append(ids, id)
+	}
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	store, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	for _, id := range ids {
+		if err := store.DeleteLesson(cmd.Context(), id); err != nil {
+			return err
+		}
+		if !jsonOut {
+			fmt.Printf("Forgot lesson %d.\n", id)
+		}
+	}
+	if jsonOut {
+		printJSON(map[string]any{"forgotten": ids})
+	}
+	return nil
+}
@@ -15,6 +15,7 @@ import (
 var (
 	listProject  string
 	listState    string
+	listTag      string
 	listSort     string
 	listAsc      bool
 	listDesc     bool
@@ -35,6 +36,7 @@ var listCmd = &cobra.Command{
 func init() {
 	listCmd.Flags().StringVar(&listProject, "project", "", "filter by project name")
 	listCmd.Flags().StringVar(&listState, "state", "all", "filter by state")
+	listCmd.Flags().StringVar(&listTag, "tag", "", "only list jobs with this tag (see ap tag)")
 	listCmd.Flags().StringVar(&listSort, "sort", "updated_at", "sort by field: updated_at, created_at, state, or project")
 	listCmd.Flags().BoolVar(&listAsc, "asc", false, "sort in ascending order")
 	listCmd.Flags().BoolVar(&listDesc, "desc", false, "sort in descending order (default)")
@@ -60,6 +62,12 @@ func runList(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	tag := ""
+	if listTag != "" {
+		if tag, err = db.NormalizeTag(listTag); err != nil {
+			return err
+		}
+	}
 	if listAsc && listDesc {
 		return fmt.Errorf("--asc and --desc cannot be used together")
 	}
@@ -75,7 +83,7 @@ func runList(cmd *cobra.Command, args []string) error {
 	page := listPage
 	pageSize := listPageSize
 	snapshot := func(ctx context.Context) (listSnapshot, error) {
-		return collectListSnapshot(ctx, store, listProject, state, sortBy, ascending, paginate, page, pageSize, listCost)
+		return collectListSnapshot(ctx, store, listProject, state, tag, sortBy, ascending, paginate, page, pageSize, listCost)
 	}
 
 	render := func(ctx context.Context, snapshot listSnapshot, iteration int64) error {
@@ -111,7 +119,7 @@ type listSnapshot struct {
 	Cost     map[string]db.TokenSummary
 }
 
-func collectListSnapshot(ctx context.Context, store *db.Store, project, state, sortBy string, ascending bool, paginate bool, page int, pageSize int, withCost bool) (listSnapshot, error) {
+func collectListSnapshot(ctx context.Context, store *db.Store, project, state, tag, sortBy string, ascending bool, paginate bool, page int, pageSize int, withCost bool) (listSnapshot, error) {
 	if paginate {
 		if page < 1 {
 			return listSnapshot{}, fmt.Errorf("invalid page value %d; expected >= 1", page)
@@ -125,13 +133,13 @@ func collectListSnapshot(ctx context.Context, store *db.Store, project, state, s
 	total := 0
 	if paginate {
 		var err error
-		jobs, total, err = store.ListJobsPage(ctx, project, state, sortBy, ascending, page, pageSize)
+		jobs, total, err = store.ListJobsPageTagged(ctx, project, state, tag, sortBy, ascending, page, pageSize)
 		if err != nil {
 			return listSnapshot{}, err
 		}
 	} else {
 		var err error
-		jobs, err = store.ListJobs(ctx, project, state, sortBy, ascending)
+		jobs, err = store.ListJobsTagged(ctx, project, state, tag, sortBy, ascending)
 		if err != nil {
 			return listSnapshot{}, err
 		}
@@ -236,7 +244,7 @@ func renderListSnapshot(_ context.Context, asJSON bool, compactJSON bool, iterat
 				c := cost.Calculate(ts.Provider, ts.TotalInputTokens, ts.TotalOutputTokens)
 				costStr = cost.FormatUSD(c)
 			}
-			title := truncate(j.IssueTitle, 45)
+			title := truncate(taggedTitle(j), 45)
 			if err := writef("%-10s %-20s %-13s %-13s %-5s %-8s %-45s %s\n",
 				db.ShortID(j.ID), db.DisplayState(j.State, j.PRMergedAt, j.PRClosedAt), truncate(j.ProjectName, 12), source,
 				fmt.Sprintf("%d/%d", j.Iteration, j.MaxIterations),
@@ -244,7 +252,7 @@ func renderListSnapshot(_ context.Context, asJSON bool, compactJSON bool, iterat
 				return err
 			}
 		} else {
-			title := truncate(j.IssueTitle, 55)
+			title := truncate(taggedTitle(j), 55)
 			if err := writef("%-10s %-20s %-13s %-13s %-5s %-55s %s\n",
 				db.ShortID(j.ID), db.DisplayState(j.State, j.PRMergedAt, j.PRClosedAt), truncate(j.ProjectName, 12), source,
 				fmt.Sprintf("%d/%d", j.Iteration, j.MaxIterations),
@@ -285,22 +293,30 @@ func normalizeListState(state string) (string, error) {
 	}
 
 	switch state {
-	case "all", "active", "merged", "queued", "planning", "implementing", "reviewing", "testing", "ready", "rebasing", "resolving_conflicts", "awaiting_checks", "approved", "rejected", "failed", "cancelled":
+	case "all", "active", "merged", "queued", "planning", "implementing", "reviewing", "testing", "ready", "rebasing", "resolving_conflicts", "awaiting_checks", "waiting_network", "needs_review_oversize", "approved", "rejected", "failed", "cancelled":
 		return state, nil
 	default:
-		return "", fmt.Errorf("invalid --state %q (expected one of: all, active, merged, queued, planning, implementing, reviewing, testing, ready, rebasing, resolving, resolving_conflicts, awaiting_checks, approved, rejected, failed, cancelled)", state)
+		return "", fmt.Errorf("invalid --state %q (expected one of: all, active, merged, queued, planning, implementing, reviewing, testing, ready, rebasing, resolving, resolving_conflicts, awaiting_checks, waiting_network, needs_review_oversize, approved, rejected, failed, cancelled)", state)
 	}
 }
 
 func isActiveState(state string) bool {
 	switch state {
-	case "planning", "implementing", "reviewing", "testing", "rebasing", "resolving_conflicts", "awaiting_checks":
+	case "planning", "implementing", "reviewing", "testing", "rebasing", "resolving_conflicts", "awaiting_checks", "waiting_network":
 		return true
 	default:
 		return false
 	}
 }
 
+// taggedTitle prefixes a job's title with its tags, if any.
+func taggedTitle(j db.Job) string {
+	if len(j.Tags) == 0 {
+		return j.Title()
+	}
+	return "[" + strings.Join(j.Tags, ",") + "] " + j.Title()
+}
+
 func truncate(s string, n int) string {
 	if len(s) <= n {
 		return s
@@ -79,6 +79,11 @@ func runLogs(cmd *cobra.Command, args []string) error {
 
 	tokenSummary, _ := store.AggregateTokensByJob(cmd.Context(), jobID)
 
+	notes, err := store.ListJobNotes(cmd.Context(), jobID)
+	if err != nil {
+		return err
+	}
+
 	if logsSession != "" {
 		targetSession, err := resolveLogsSession(sessions, logsSession, jobID)
 		if err != nil {
@@ -100,6 +105,9 @@ func runLogs(cmd *cobra.Command, args []string) error {
 			"sessions":  sessions,
 			"artifacts": artifacts,
 		}
+		if len(notes) > 0 {
+			payload["notes"] = notes
+		}
 		if issueErr == nil {
 			payload["issue"] = issue
 		}
@@ -140,6 +148,9 @@ func runLogs(cmd *cobra.Command, args []string) error {
 	if job.BranchName != "" {
 		fmt.Printf("Branch: %s  Commit: %s\n", job.BranchName, job.CommitSHA)
 	}
+	if job.SignatureStatus != "" {
+		fmt.Printf("Signatures: %s\n", job.SignatureStatus)
+	}
 	if job.ErrorMessage != "" {
 		fmt.Printf("Error: %s\n", job.ErrorMessage)
 	}
@@ -152,6 +163,12 @@ func runLogs(cmd *cobra.Command, args []string) error {
 	if job.PRClosedAt != "" {
 		fmt.Printf("PR Closed: %s\n", job.PRClosedAt)
 	}
+	if len(job.Tags) > 0 {
+		fmt.Printf("Tags: %s\n", strings.Join(job.Tags, ", "))
+	}
+	for _, n := range notes {
+		fmt.Printf("Note [%d] %s: %s\n", n.ID, n.CreatedAt, n.Body)
+	}
 	fmt.Println()
 
 	if len(sessions) > 0 {
@@ -261,6 +278,11 @@ func printSessionText(session db.LLMSession, mode logsOutputMode) {
 	fmt.Printf("Provider: %s\n", session.LLMProvider)
 	fmt.Println()
 
+	if session.PurgedAt != "" {
+		fmt.Printf("Prompt and response text were purged at %s.\n", session.PurgedAt)
+		return
+	}
+
 	switch mode {
 	case logsOutputModeInput:
 		fmt.Println("Prompt Text:")
@@ -274,7 +296,7 @@ func printSessionText(session db.LLMSession, mode logsOutputMode) {
 // isTerminalState returns true if the job state is terminal.
 func isTerminalState(state string) bool {
 	switch state {
-	case "ready", "approved", "rejected", "failed", "cancelled":
+	case "ready", "needs_review_oversize", "approved", "rejected", "failed", "cancelled":
 		return true
 	default:
 		return false
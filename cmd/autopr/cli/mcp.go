@@ -0,0 +1,44 @@
+package cli
+
+import (
+	"os"
+
+	"autopr/internal/mcp"
+
+	"github.com/spf13/cobra"
+)
+
+var mcpReadOnly bool
+
+var mcpCmd = &cobra.Command{
+	Use:   "mcp",
+	Short: "Serve AutoPR jobs and issues to other agents over MCP on stdio",
+	Long: `Run a Model Context Protocol server on stdin/stdout, so other local AI tools
+can follow jobs, read their diffs, and queue jobs through a standard tool
+interface. Register it with your MCP client as the command "ap mcp".
+
+Tools: list_jobs, get_job, get_diff, list_issues, and enqueue_job.
+--read-only leaves out enqueue_job. Jobs are queued for the daemon, which must
+be running (ap start) to work on them. Logs go to stderr.`,
+	Args: cobra.NoArgs,
+	RunE: runMCP,
+}
+
+func init() {
+	mcpCmd.Flags().BoolVar(&mcpReadOnly, "read-only", false, "only offer tools that don't change state")
+	rootCmd.AddCommand(mcpCmd)
+}
+
+func runMCP(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	store, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	return mcp.NewServer(cfg, store, version, mcpReadOnly).Serve(cmd.Context(), os.Stdin, os.Stdout)
+}
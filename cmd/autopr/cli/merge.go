@@ -10,6 +10,9 @@ import (
 
 	"autopr/internal/db"
 	"autopr/internal/git"
+	"autopr/internal/githubapp"
+	"autopr/internal/netstate"
+	"autopr/internal/pipeline"
 
 	"github.com/spf13/cobra"
 )
@@ -26,6 +29,7 @@ var mergeCmd = &cobra.Command{
 var (
 	mergeGitHub = git.MergeGitHubPR
 	mergeGitLab = git.MergeGitLabMR
+	mergeGitea  = git.MergeGiteaPR
 	now         = func() string {
 		return time.Now().UTC().Format("2006-01-02T15:04:05Z")
 	}
@@ -91,24 +95,38 @@ func runMerge(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("project %q not found in config", job.ProjectName)
 	}
 
+	var mergeErr error
+	mergeNoun := "PR"
 	switch {
 	case proj.GitHub != nil:
-		if cfg.Tokens.GitHub == "" {
-			return fmt.Errorf("GITHUB_TOKEN required to merge PR")
+		token, err := githubapp.Token(cmd.Context(), cfg, proj)
+		if err != nil {
+			return fmt.Errorf("github token: %w", err)
 		}
-		if err := mergeGitHub(cmd.Context(), cfg.Tokens.GitHub, job.PRURL, method); err != nil {
-			return fmt.Errorf("merge PR: %w", err)
+		if token == "" {
+			return fmt.Errorf("GITHUB_TOKEN required to merge PR")
 		}
+		mergeErr = mergeGitHub(cmd.Context(), token, proj.GitHub.BaseURL, job.PRURL, method)
 	case proj.GitLab != nil:
 		if cfg.Tokens.GitLab == "" {
 			return fmt.Errorf("GITLAB_TOKEN required to merge MR")
 		}
 		squash := method == "squash"
-		if err := mergeGitLab(cmd.Context(), cfg.Tokens.GitLab, proj.GitLab.BaseURL, job.PRURL, squash); err != nil {
-			return fmt.Errorf("merge MR: %w", err)
+		mergeNoun = "MR"
+		mergeErr = mergeGitLab(cmd.Context(), cfg.Tokens.GitLab, proj.GitLab.BaseURL, job.PRURL, squash)
+	case proj.Gitea != nil:
+		if cfg.Tokens.Gitea == "" {
+			return fmt.Errorf("GITEA_TOKEN required to merge PR")
 		}
+		mergeErr = mergeGitea(cmd.Context(), cfg.Tokens.Gitea, proj.Gitea.BaseURL, job.PRURL, method)
 	default:
-		return fmt.Errorf("project %q has no GitHub or GitLab config for merge", proj.Name)
+		return fmt.Errorf("project %q has no GitHub, GitLab, or Gitea config for merge", proj.Name)
+	}
+	if mergeErr != nil {
+		if netstate.IsNetworkError(mergeErr) {
+			return queueOfflineMerge(cmd.Context(), store, job, method, mergeErr)
+		}
+		return fmt.Errorf("merge %s: %w", mergeNoun, mergeErr)
 	}
 
 	mergedAt := now()
@@ -116,7 +134,7 @@ func runMerge(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("mark job merged: %w", err)
 	}
 
-	if err := mergeCleanup(cmd.Context(), store, cfg.ReposRoot, job, cfg.GitTokenForProject(proj)); err != nil {
+	if err := mergeCleanup(cmd.Context(), store, cfg.ReposRoot, job, pipeline.GitTokenForProject(cmd.Context(), cfg, proj)); err != nil {
 		fmt.Fprintf(os.Stderr, "warning: cleanup worktree after merge: %v\n", err)
 	}
 
@@ -136,6 +154,29 @@ func runMerge(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// queueOfflineMerge defers a merge that failed because the forge was
+// unreachable; the daemon performs it once connectivity returns.
+func queueOfflineMerge(ctx context.Context, store *db.Store, job db.Job, method string, mergeErr error) error {
+	if _, err := store.EnqueueOutboxOp(ctx, job.ID, db.OutboxOpMergePR, method); err != nil {
+		return fmt.Errorf("merge failed (%v) and could not be queued: %w", mergeErr, err)
+	}
+
+	if jsonOut {
+		printJSON(map[string]any{
+			"job_id": job.ID,
+			"state":  "merge_queued",
+			"pr_url": job.PRURL,
+			"method": method,
+			"error":  mergeErr.Error(),
+		})
+		return nil
+	}
+
+	fmt.Printf("Network unavailable: %v\n", mergeErr)
+	fmt.Printf("Merge of job %s queued; the daemon will merge it when connectivity returns.\n", job.ID)
+	return nil
+}
+
 func cleanupMergedWorktree(ctx context.Context, store *db.Store, reposRoot string, job db.Job, token string) error {
 	worktreePath := strings.TrimSpace(job.WorktreePath)
 	if worktreePath == "" && reposRoot != "" {
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net"
 	"os"
 	"path/filepath"
 	"strings"
@@ -40,7 +41,7 @@ func TestMergeApprovedJobSetsMergedAt(t *testing.T) {
 
 	mergedAt := "2026-02-20T10:00:00Z"
 	mergeCalled := false
-	mergeGitHub = func(context.Context, string, string, string) error {
+	mergeGitHub = func(context.Context, string, string, string, string) error {
 		mergeCalled = true
 		return nil
 	}
@@ -73,6 +74,60 @@ func TestMergeApprovedJobSetsMergedAt(t *testing.T) {
 	}
 }
 
+func TestMergeQueuesWhenNetworkUnavailable(t *testing.T) {
+	ctx := context.Background()
+	tmp := t.TempDir()
+
+	dbPath := filepath.Join(tmp, "autopr.db")
+	mergeCfgPath := writeMergeConfig(t, tmp)
+
+	jobID := createMergeJobForTest(t, dbPath, "project", "7002", "approved", "https://github.com/acmecorp/placeholder/pull/124", "")
+
+	prevCfgPath := cfgPath
+	prevGitHub := mergeGitHub
+	prevMergeMethod := mergeMethod
+	prevJSON := jsonOut
+	defer func() {
+		cfgPath = prevCfgPath
+		mergeGitHub = prevGitHub
+		mergeMethod = prevMergeMethod
+		jsonOut = prevJSON
+	}()
+
+	mergeGitHub = func(context.Context, string, string, string, string) error {
+		return &net.DNSError{Err: "no such host", Name: "api.github.com"}
+	}
+	jsonOut = false
+	cfgPath = mergeCfgPath
+	mergeMethod = "squash"
+
+	cmd := &cobra.Command{}
+	cmd.SetContext(context.Background())
+	if err := runMerge(cmd, []string{jobID}); err != nil {
+		t.Fatalf("runMerge: %v", err)
+	}
+
+	store, err := db.Open(dbPath)
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer store.Close()
+	got, err := store.GetJob(ctx, jobID)
+	if err != nil {
+		t.Fatalf("get job: %v", err)
+	}
+	if got.PRMergedAt != "" {
+		t.Fatalf("expected job to stay unmerged, got pr_merged_at %q", got.PRMergedAt)
+	}
+	ops, err := store.ListPendingOutboxOps(ctx)
+	if err != nil {
+		t.Fatalf("list outbox ops: %v", err)
+	}
+	if len(ops) != 1 || ops[0].Kind != db.OutboxOpMergePR || ops[0].Payload != "squash" {
+		t.Fatalf("expected queued squash merge, got %+v", ops)
+	}
+}
+
 func TestMergeJobMustBeApproved(t *testing.T) {
 	ctx := context.Background()
 	tmp := t.TempDir()
@@ -89,7 +144,7 @@ func TestMergeJobMustBeApproved(t *testing.T) {
 		mergeGitHub = prevGitHub
 		mergeMethod = prevMergeMethod
 	}()
-	mergeGitHub = func(context.Context, string, string, string) error {
+	mergeGitHub = func(context.Context, string, string, string, string) error {
 		t.Fatalf("merge helper should not be called")
 		return nil
 	}
@@ -123,7 +178,7 @@ func TestMergeRequiresPRURL(t *testing.T) {
 		mergeGitHub = prevGitHub
 		mergeMethod = prevMergeMethod
 	}()
-	mergeGitHub = func(context.Context, string, string, string) error {
+	mergeGitHub = func(context.Context, string, string, string, string) error {
 		t.Fatalf("merge helper should not be called")
 		return nil
 	}
@@ -157,7 +212,7 @@ func TestMergeRejectsAlreadyMerged(t *testing.T) {
 		mergeGitHub = prevGitHub
 		mergeMethod = prevMergeMethod
 	}()
-	mergeGitHub = func(context.Context, string, string, string) error {
+	mergeGitHub = func(context.Context, string, string, string, string) error {
 		t.Fatalf("merge helper should not be called")
 		return nil
 	}
@@ -189,7 +244,7 @@ func TestMergeRejectsInvalidMethod(t *testing.T) {
 		mergeGitHub = prevGitHub
 		mergeMethod = prevMethod
 	}()
-	mergeGitHub = func(context.Context, string, string, string) error {
+	mergeGitHub = func(context.Context, string, string, string, string) error {
 		t.Fatalf("merge helper should not be called for invalid method")
 		return nil
 	}
@@ -230,7 +285,7 @@ func TestMergeJSONOutput(t *testing.T) {
 
 	cfgPath = mergeCfgPath
 	now = func() string { return "2026-02-20T11:00:00Z" }
-	mergeGitHub = func(context.Context, string, string, string) error { return nil }
+	mergeGitHub = func(context.Context, string, string, string, string) error { return nil }
 	mergeCleanup = func(context.Context, *db.Store, string, db.Job, string) error { return nil }
 	mergeMethod = "merge"
 	jsonOut = true
@@ -0,0 +1,91 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"autopr/internal/db"
+
+	"github.com/spf13/cobra"
+)
+
+var noteDelete int64
+
+var noteCmd = &cobra.Command{
+	Use:   "note <job-id> [text]",
+	Short: "Attach a freeform note to a job, or list its notes",
+	Long: `Attach a note to a job for your own bookkeeping. Notes are shown by
+ap logs and the TUI job detail; unlike retry notes they are never sent to the
+LLM. With no text, list the job's notes.`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: runNote,
+}
+
+func init() {
+	noteCmd.Flags().Int64Var(&noteDelete, "delete", 0, "delete the note with this ID")
+	rootCmd.AddCommand(noteCmd)
+}
+
+func runNote(cmd *cobra.Command, args []string) error {
+	if noteDelete != 0 && len(args) > 1 {
+		return fmt.Errorf("cannot use [text] with --delete")
+	}
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	store, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	jobID, err := resolveJob(store, args[0])
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case noteDelete != 0:
+		if err := store.DeleteJobNote(cmd.Context(), jobID, noteDelete); err != nil {
+			return err
+		}
+		if jsonOut {
+			printJSON(map[string]any{"job_id": jobID, "deleted": noteDelete})
+			return nil
+		}
+		fmt.Printf("Deleted note %d from job %s.\n", noteDelete, db.ShortID(jobID))
+		return nil
+	case len(args) == 2:
+		id, err := store.AddJobNote(cmd.Context(), jobID, args[1])
+		if err != nil {
+			return err
+		}
+		if jsonOut {
+			printJSON(map[string]any{"job_id": jobID, "note_id": id})
+			return nil
+		}
+		fmt.Printf("Added note %d to job %s.\n", id, db.ShortID(jobID))
+		return nil
+	}
+
+	notes, err := store.ListJobNotes(cmd.Context(), jobID)
+	if err != nil {
+		return err
+	}
+	if jsonOut {
+		if notes == nil {
+			notes = []db.JobNote{}
+		}
+		printJSON(notes)
+		return nil
+	}
+	if len(notes) == 0 {
+		fmt.Printf("Job %s has no notes.\n", db.ShortID(jobID))
+		return nil
+	}
+	for _, n := range notes {
+		fmt.Printf("[%d] %s  %s\n", n.ID, n.CreatedAt, strings.ReplaceAll(n.Body, "\n", "\n    "))
+	}
+	return nil
+}
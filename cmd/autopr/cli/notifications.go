@@ -0,0 +1,220 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"autopr/internal/db"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	notificationsStatus   string
+	notificationsRetryAll bool
+)
+
+// undeliveredNotificationStatuses are the statuses listed by default.
+var undeliveredNotificationStatuses = []string{
+	db.NotificationStatusPending,
+	db.NotificationStatusProcessing,
+	db.NotificationStatusFailed,
+	db.NotificationStatusDead,
+}
+
+var notificationsCmd = &cobra.Command{
+	Use:   "notifications",
+	Short: "Show pending, failed, and dead-lettered notifications",
+	Args:  cobra.NoArgs,
+	RunE:  runNotificationsList,
+}
+
+var notificationsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List notification events and their per-channel delivery state",
+	Args:  cobra.NoArgs,
+	RunE:  runNotificationsList,
+}
+
+var notificationsRetryCmd = &cobra.Command{
+	Use:   "retry [event-id...]",
+	Short: "Re-deliver failed or dead-lettered notifications",
+	RunE:  runNotificationsRetry,
+}
+
+func init() {
+	for _, cmd := range []*cobra.Command{notificationsCmd, notificationsListCmd} {
+		cmd.Flags().StringVar(&notificationsStatus, "status", "", "filter by status: pending, processing, failed, dead, sent, skipped, or all (default: undelivered)")
+	}
+	notificationsRetryCmd.Flags().BoolVar(&notificationsRetryAll, "all", false, "retry every failed and dead notification")
+	notificationsCmd.AddCommand(notificationsListCmd)
+	notificationsCmd.AddCommand(notificationsRetryCmd)
+	rootCmd.AddCommand(notificationsCmd)
+}
+
+type notificationOutput struct {
+	ID         int64                     `json:"id"`
+	JobID      string                    `json:"job_id"`
+	Event      string                    `json:"event"`
+	Status     string                    `json:"status"`
+	Attempts   int                       `json:"attempts"`
+	LastError  string                    `json:"last_error,omitempty"`
+	CreatedAt  string                    `json:"created_at"`
+	UpdatedAt  string                    `json:"updated_at"`
+	Deliveries []db.NotificationDelivery `json:"deliveries"`
+}
+
+func runNotificationsList(cmd *cobra.Command, args []string) error {
+	statuses, err := notificationStatusFilter(notificationsStatus)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	store, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	out, err := listNotifications(cmd.Context(), store, statuses)
+	if err != nil {
+		return err
+	}
+
+	if jsonOut {
+		printJSON(out)
+		return nil
+	}
+	if len(out) == 0 {
+		fmt.Println("No notifications found.")
+		return nil
+	}
+
+	fmt.Printf("%-6s %-10s %-11s %-10s %-8s %-30s %s\n", "ID", "JOB", "EVENT", "STATUS", "ATTEMPTS", "CHANNELS", "LAST ERROR")
+	fmt.Println(strings.Repeat("-", 120))
+	for _, n := range out {
+		channels := make([]string, 0, len(n.Deliveries))
+		for _, d := range n.Deliveries {
+			channels = append(channels, d.Channel+"="+d.Status)
+		}
+		channelText := strings.Join(channels, " ")
+		if channelText == "" {
+			channelText = "-"
+		}
+		lastError := n.LastError
+		if lastError == "" {
+			lastError = "-"
+		}
+		job := db.ShortID(n.JobID)
+		if job == "" {
+			job = "(daemon)"
+		}
+		fmt.Printf("%-6d %-10s %-11s %-10s %-8d %-30s %s\n",
+			n.ID,
+			job,
+			n.Event,
+			n.Status,
+			n.Attempts,
+			truncate(channelText, 30),
+			truncate(lastError, 60),
+		)
+	}
+	return nil
+}
+
+// notificationStatusFilter maps --status to the statuses to list; nil means all.
+func notificationStatusFilter(status string) ([]string, error) {
+	switch status = strings.ToLower(strings.TrimSpace(status)); status {
+	case "":
+		return undeliveredNotificationStatuses, nil
+	case "all":
+		return nil, nil
+	case db.NotificationStatusPending, db.NotificationStatusProcessing, db.NotificationStatusFailed,
+		db.NotificationStatusDead, db.NotificationStatusSent, db.NotificationStatusSkipped:
+		return []string{status}, nil
+	default:
+		return nil, fmt.Errorf("invalid --status %q (want pending, processing, failed, dead, sent, skipped, or all)", status)
+	}
+}
+
+func listNotifications(ctx context.Context, store *db.Store, statuses []string) ([]notificationOutput, error) {
+	events, err := store.ListNotificationEvents(ctx, "", 0)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]notificationOutput, 0, len(events))
+	for _, event := range events {
+		if statuses != nil && !slices.Contains(statuses, event.Status) {
+			continue
+		}
+		deliveries, err := store.ListNotificationDeliveries(ctx, event.ID)
+		if err != nil {
+			return nil, err
+		}
+		if deliveries == nil {
+			deliveries = []db.NotificationDelivery{}
+		}
+		out = append(out, notificationOutput{
+			ID:         event.ID,
+			JobID:      event.JobID,
+			Event:      event.EventType,
+			Status:     event.Status,
+			Attempts:   event.Attempts,
+			LastError:  event.LastError,
+			CreatedAt:  event.CreatedAt,
+			UpdatedAt:  event.UpdatedAt,
+			Deliveries: deliveries,
+		})
+	}
+	return out, nil
+}
+
+func runNotificationsRetry(cmd *cobra.Command, args []string) error {
+	if notificationsRetryAll && len(args) > 0 {
+		return fmt.Errorf("pass event IDs or --all, not both")
+	}
+	if !notificationsRetryAll && len(args) == 0 {
+		return fmt.Errorf("specify event IDs to retry, or --all")
+	}
+	ids := make([]int64, 0, len(args))
+	for _, arg := range args {
+		id, err := strconv.ParseInt(strings.TrimPrefix(arg, "#"), 10, 64)
+		if err != nil || id <= 0 {
+			return fmt.Errorf("invalid notification event ID %q", arg)
+		}
+		ids = append(ids, id)
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	store, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	retried, err := store.RetryNotificationEvents(cmd.Context(), ids)
+	if err != nil {
+		return err
+	}
+
+	if jsonOut {
+		printJSON(map[string]any{"retried": retried})
+		return nil
+	}
+	if retried == 0 {
+		fmt.Println("No failed or dead notifications to retry.")
+		return nil
+	}
+	fmt.Printf("Requeued %d notification(s); the daemon will deliver them shortly.\n", retried)
+	return nil
+}
@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"autopr/internal/db"
+
+	"github.com/spf13/cobra"
+)
+
+func TestNotificationsListAndRetry(t *testing.T) {
+	ctx := context.Background()
+	tmp := t.TempDir()
+	dbPath := filepath.Join(tmp, "autopr.db")
+	notifyCfgPath := writeMergeConfig(t, tmp)
+	jobID := createMergeJobForTest(t, dbPath, "project", "7101", "failed", "", "")
+
+	store, err := db.Open(dbPath)
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer store.Close()
+
+	sentID, err := store.EnqueueNotificationEvent(ctx, jobID, db.NotificationEventFailed)
+	if err != nil {
+		t.Fatalf("enqueue sent event: %v", err)
+	}
+	if err := store.MarkNotificationEventSent(ctx, sentID); err != nil {
+		t.Fatalf("mark sent: %v", err)
+	}
+	deadID, err := store.EnqueueNotificationEvent(ctx, jobID, db.NotificationEventFailed)
+	if err != nil {
+		t.Fatalf("enqueue dead event: %v", err)
+	}
+	if _, err := store.RecordNotificationDelivery(ctx, deadID, "slack", false, "HTTP 500", 1); err != nil {
+		t.Fatalf("record delivery: %v", err)
+	}
+	if err := store.MarkNotificationEventDead(ctx, deadID, "slack: HTTP 500"); err != nil {
+		t.Fatalf("mark dead: %v", err)
+	}
+
+	statuses, err := notificationStatusFilter("")
+	if err != nil {
+		t.Fatalf("status filter: %v", err)
+	}
+	out, err := listNotifications(ctx, store, statuses)
+	if err != nil {
+		t.Fatalf("list notifications: %v", err)
+	}
+	if len(out) != 1 || out[0].ID != deadID {
+		t.Fatalf("expected only the dead event by default, got %+v", out)
+	}
+	if len(out[0].Deliveries) != 1 || out[0].Deliveries[0].Status != db.NotificationStatusDead {
+		t.Fatalf("expected dead slack delivery, got %+v", out[0].Deliveries)
+	}
+	if _, err := notificationStatusFilter("bogus"); err == nil {
+		t.Fatal("expected invalid status error")
+	}
+
+	prevCfgPath := cfgPath
+	prevJSON := jsonOut
+	prevAll := notificationsRetryAll
+	defer func() {
+		cfgPath = prevCfgPath
+		jsonOut = prevJSON
+		notificationsRetryAll = prevAll
+	}()
+	cfgPath = notifyCfgPath
+	jsonOut = false
+	notificationsRetryAll = false
+
+	cmd := &cobra.Command{}
+	cmd.SetContext(ctx)
+	if err := runNotificationsRetry(cmd, nil); err == nil {
+		t.Fatal("expected error without event IDs or --all")
+	}
+	if err := runNotificationsRetry(cmd, []string{"abc"}); err == nil {
+		t.Fatal("expected invalid ID error")
+	}
+	notificationsRetryAll = true
+	if err := runNotificationsRetry(cmd, nil); err != nil {
+		t.Fatalf("retry --all: %v", err)
+	}
+
+	pending, err := store.ListNotificationEvents(ctx, db.NotificationStatusPending, 0)
+	if err != nil {
+		t.Fatalf("list pending: %v", err)
+	}
+	if len(pending) != 1 || pending[0].ID != deadID || pending[0].Attempts != 0 {
+		t.Fatalf("expected dead event requeued with fresh attempts, got %+v", pending)
+	}
+}
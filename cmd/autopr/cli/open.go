@@ -181,6 +181,9 @@ func openInEditor(path string) error {
 	if editor == "" {
 		if _, err := exec.LookPath("code"); err == nil {
 			editor = "code"
+		} else if runtime.GOOS == "windows" {
+			// The worktree is a directory, which notepad cannot open.
+			editor = "explorer"
 		} else {
 			editor = "vim"
 		}
@@ -199,7 +202,9 @@ func openInBrowser(url string) error {
 	case "darwin":
 		cmd = exec.Command("open", url)
 	case "windows":
-		cmd = exec.Command("cmd", "/c", "start", url)
+		// Not "cmd /c start": it treats a quoted first argument as a window
+		// title and splits the URL at "&".
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
 	default:
 		cmd = exec.Command("xdg-open", url)
 	}
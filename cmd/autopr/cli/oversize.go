@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"fmt"
+
+	"autopr/internal/pipeline"
+
+	"github.com/spf13/cobra"
+)
+
+var oversizeCmd = &cobra.Command{
+	Use:   "oversize <job-id> <allow|split|reject>",
+	Short: "Decide on a job paused because its diff exceeds the size limits",
+	Long: `Resolve a job in needs_review_oversize:
+
+  allow   accept the large diff and move the job to ready
+  split   reject this attempt and retry, asking for a smaller change
+  reject  reject the job`,
+	Args: cobra.ExactArgs(2),
+	RunE: runOversize,
+}
+
+func init() {
+	rootCmd.AddCommand(oversizeCmd)
+}
+
+func runOversize(cmd *cobra.Command, args []string) error {
+	action := args[1]
+	switch action {
+	case pipeline.OversizeAllow, pipeline.OversizeSplit, pipeline.OversizeReject:
+	default:
+		return fmt.Errorf("invalid action %q (expected allow, split, or reject)", action)
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	store, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	jobID, err := resolveJob(store, args[0])
+	if err != nil {
+		return err
+	}
+
+	job, err := store.GetJob(cmd.Context(), jobID)
+	if err != nil {
+		return err
+	}
+
+	if job.State != "needs_review_oversize" {
+		return fmt.Errorf("job %s is in state %q, must be 'needs_review_oversize'", jobID, job.State)
+	}
+
+	if err := pipeline.ResolveOversize(cmd.Context(), store, job, action); err != nil {
+		return err
+	}
+
+	state := map[string]string{
+		pipeline.OversizeAllow:  "ready",
+		pipeline.OversizeSplit:  "queued",
+		pipeline.OversizeReject: "rejected",
+	}[action]
+	if jsonOut {
+		printJSON(map[string]string{"job_id": jobID, "action": action, "state": state})
+		return nil
+	}
+	fmt.Printf("Job %s %s (%s).\n", jobID, state, job.OversizeSummary)
+	return nil
+}
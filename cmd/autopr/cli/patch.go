@@ -0,0 +1,268 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"autopr/internal/db"
+	"autopr/internal/git"
+	"autopr/internal/patchbundle"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	exportPatchOut   string
+	applyPatchRepo   string
+	applyPatchBranch string
+	applyPatchOnHEAD bool
+	applyPatchDryRun bool
+)
+
+var exportPatchCmd = &cobra.Command{
+	Use:   "export-patch <job-id>",
+	Short: "Write a job's commits as a format-patch bundle for review or apply elsewhere",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runExportPatch,
+}
+
+var applyPatchCmd = &cobra.Command{
+	Use:   "apply-patch <bundle>",
+	Short: "Apply a bundle from `ap export-patch` onto a new branch in a local repo",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runApplyPatch,
+}
+
+func init() {
+	exportPatchCmd.Flags().StringVarP(&exportPatchOut, "out", "o", "", "bundle path (default ./<job>.autopr-patch.tar.gz)")
+	applyPatchCmd.Flags().StringVar(&applyPatchRepo, "repo", ".", "git repository to apply the patches in")
+	applyPatchCmd.Flags().StringVar(&applyPatchBranch, "branch", "", "branch to create (default: the job's branch name)")
+	applyPatchCmd.Flags().BoolVar(&applyPatchOnHEAD, "onto-head", false, "apply on top of HEAD instead of the bundle's base commit")
+	applyPatchCmd.Flags().BoolVar(&applyPatchDryRun, "dry-run", false, "verify the bundle and print its manifest without applying")
+	rootCmd.AddCommand(exportPatchCmd)
+	rootCmd.AddCommand(applyPatchCmd)
+}
+
+type exportPatchOutput struct {
+	JobID   string `json:"job_id"`
+	Path    string `json:"path"`
+	Patches int    `json:"patches"`
+	Base    string `json:"base_commit"`
+	Head    string `json:"head_commit"`
+}
+
+func runExportPatch(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	store, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	jobID, err := resolveJob(store, args[0])
+	if err != nil {
+		return err
+	}
+	ctx := cmd.Context()
+	job, err := store.GetJob(ctx, jobID)
+	if err != nil {
+		return err
+	}
+	if job.State == "queued" {
+		return fmt.Errorf("job has not started yet")
+	}
+	if job.WorktreePath == "" {
+		return fmt.Errorf("no worktree available (job may have been cleaned up)")
+	}
+	if _, err := os.Stat(job.WorktreePath); os.IsNotExist(err) {
+		return fmt.Errorf("worktree directory not found (run `ap cleanup` removed it?)")
+	}
+
+	baseBranch := "main"
+	if p, ok := cfg.ProjectByName(job.ProjectName); ok && p.BaseBranch != "" {
+		baseBranch = p.BaseBranch
+	}
+	if job.BaseBranch != "" {
+		baseBranch = job.BaseBranch
+	}
+	if job.BackportBranch != "" {
+		baseBranch = job.BackportBranch
+	}
+
+	if dirty, err := git.HasUncommittedChanges(ctx, job.WorktreePath); err == nil && dirty {
+		fmt.Fprintf(os.Stderr, "warning: worktree has uncommitted changes; only committed work is exported\n")
+	}
+	base, err := git.MergeBase(ctx, job.WorktreePath, "origin/"+baseBranch, "HEAD")
+	if err != nil {
+		return err
+	}
+	head, err := git.LatestCommit(ctx, job.WorktreePath)
+	if err != nil {
+		return err
+	}
+	if head == base {
+		return fmt.Errorf("job %s has no commits on top of %s", db.ShortID(jobID), baseBranch)
+	}
+
+	tmp, err := os.MkdirTemp("", "autopr-export-")
+	if err != nil {
+		return fmt.Errorf("create temp dir: %w", err)
+	}
+	defer os.RemoveAll(tmp)
+	patches, err := git.FormatPatches(ctx, job.WorktreePath, base, tmp)
+	if err != nil {
+		return err
+	}
+
+	out := exportPatchOut
+	if out == "" {
+		out = db.ShortID(jobID) + ".autopr-patch.tar.gz"
+	}
+	f, err := os.Create(out)
+	if err != nil {
+		return fmt.Errorf("create bundle: %w", err)
+	}
+	m := &patchbundle.Manifest{
+		JobID:         jobID,
+		Project:       job.ProjectName,
+		Branch:        job.BranchName,
+		BaseBranch:    baseBranch,
+		BaseCommit:    base,
+		HeadCommit:    head,
+		AutoPRVersion: version,
+		CreatedAt:     time.Now().UTC().Format(time.RFC3339),
+	}
+	if issue, err := store.GetIssueByAPID(ctx, job.AutoPRIssueID); err == nil {
+		m.IssueSource = issue.Source
+		m.IssueID = issue.SourceIssueID
+		m.IssueTitle = issue.Title
+		m.IssueURL = issue.URL
+	}
+	if err := patchbundle.Write(f, m, patches); err != nil {
+		f.Close()
+		os.Remove(out)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("write bundle: %w", err)
+	}
+
+	result := exportPatchOutput{JobID: jobID, Path: out, Patches: len(patches), Base: base, Head: head}
+	if jsonOut {
+		printJSON(result)
+		return nil
+	}
+	fmt.Printf("Exported %d patch(es) for job %s to %s\n", len(patches), db.ShortID(jobID), out)
+	fmt.Printf("Apply with: ap apply-patch %s --repo <checkout>\n", filepath.Base(out))
+	return nil
+}
+
+type applyPatchOutput struct {
+	Manifest   patchbundle.Manifest `json:"manifest"`
+	Branch     string               `json:"branch,omitempty"`
+	StartPoint string               `json:"start_point,omitempty"`
+	Head       string               `json:"head_commit,omitempty"`
+	Applied    bool                 `json:"applied"`
+}
+
+// runApplyPatch needs no AutoPR config or database: the receiving machine
+// only has the bundle and a checkout of the repository.
+func runApplyPatch(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	f, err := os.Open(args[0])
+	if err != nil {
+		return fmt.Errorf("open bundle: %w", err)
+	}
+	defer f.Close()
+
+	tmp, err := os.MkdirTemp("", "autopr-apply-")
+	if err != nil {
+		return fmt.Errorf("create temp dir: %w", err)
+	}
+	defer os.RemoveAll(tmp)
+	m, patches, err := patchbundle.Extract(f, tmp)
+	if err != nil {
+		return err
+	}
+
+	result := applyPatchOutput{Manifest: m}
+	if applyPatchDryRun {
+		if jsonOut {
+			printJSON(result)
+			return nil
+		}
+		printPatchManifest(m)
+		return nil
+	}
+
+	repo, err := filepath.Abs(applyPatchRepo)
+	if err != nil {
+		return fmt.Errorf("resolve repo path: %w", err)
+	}
+	dirty, err := git.HasUncommittedChanges(ctx, repo)
+	if err != nil {
+		return err
+	}
+	if dirty {
+		return fmt.Errorf("%s has uncommitted changes; commit or stash them first", repo)
+	}
+
+	startPoint := "HEAD"
+	if !applyPatchOnHEAD {
+		if !git.CommitExists(ctx, repo, m.BaseCommit) {
+			return fmt.Errorf("base commit %s not found in %s; fetch %s or rerun with --onto-head", m.BaseCommit, repo, m.BaseBranch)
+		}
+		startPoint = m.BaseCommit
+	}
+	branch := applyPatchBranch
+	if branch == "" {
+		branch = m.Branch
+	}
+	if branch == "" {
+		return fmt.Errorf("bundle has no branch name; pass --branch")
+	}
+	if err := git.CreateBranch(ctx, repo, branch, startPoint); err != nil {
+		return err
+	}
+	if err := git.ApplyPatches(ctx, repo, patches); err != nil {
+		return err
+	}
+	head, err := git.LatestCommit(ctx, repo)
+	if err != nil {
+		return err
+	}
+
+	result.Branch = branch
+	result.StartPoint = startPoint
+	result.Head = head
+	result.Applied = true
+	if jsonOut {
+		printJSON(result)
+		return nil
+	}
+	fmt.Printf("Applied %d patch(es) from job %s onto branch %s\n", len(patches), db.ShortID(m.JobID), branch)
+	return nil
+}
+
+func printPatchManifest(m patchbundle.Manifest) {
+	fmt.Printf("Job:      %s (%s)\n", m.JobID, m.Project)
+	if m.IssueTitle != "" {
+		fmt.Printf("Issue:    %s\n", m.IssueTitle)
+	}
+	if m.IssueURL != "" {
+		fmt.Printf("URL:      %s\n", m.IssueURL)
+	}
+	fmt.Printf("Branch:   %s\n", m.Branch)
+	fmt.Printf("Base:     %s @ %s\n", m.BaseBranch, m.BaseCommit)
+	fmt.Printf("Head:     %s\n", m.HeadCommit)
+	fmt.Printf("Exported: %s by ap %s\n", m.CreatedAt, m.AutoPRVersion)
+	fmt.Printf("Patches:\n")
+	for _, p := range m.Patches {
+		fmt.Printf("  %s\n", p.Name)
+	}
+}
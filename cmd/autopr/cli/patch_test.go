@@ -0,0 +1,155 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"autopr/internal/db"
+
+	"github.com/spf13/cobra"
+)
+
+func setPatchFlags(t *testing.T, configPath string) {
+	t.Helper()
+	prevCfgPath, prevJSON := cfgPath, jsonOut
+	prevOut, prevRepo, prevBranch := exportPatchOut, applyPatchRepo, applyPatchBranch
+	prevOnHEAD, prevDryRun := applyPatchOnHEAD, applyPatchDryRun
+	cfgPath, jsonOut = configPath, false
+	t.Cleanup(func() {
+		cfgPath, jsonOut = prevCfgPath, prevJSON
+		exportPatchOut, applyPatchRepo, applyPatchBranch = prevOut, prevRepo, prevBranch
+		applyPatchOnHEAD, applyPatchDryRun = prevOnHEAD, prevDryRun
+	})
+}
+
+func gitOutput(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("git %s failed: %v", strings.Join(args, " "), err)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func TestExportAndApplyPatchRoundTrip(t *testing.T) {
+	tmp := t.TempDir()
+	configPath := writeMergeConfig(t, tmp)
+	dbPath := filepath.Join(tmp, "autopr.db")
+	jobID := createMergeJobForTest(t, dbPath, "project", "8201", "ready", "", "")
+
+	worktreePath := setupDiffWorktree(t, tmp)
+	runGitCmd(t, worktreePath, "config", "user.email", "bot@example.com")
+	runGitCmd(t, worktreePath, "config", "user.name", "AutoPR")
+	runGitCmd(t, worktreePath, "checkout", "-b", "autopr/8201")
+	if err := os.WriteFile(filepath.Join(worktreePath, "fix.go"), []byte("package fix\n"), 0o644); err != nil {
+		t.Fatalf("write fix: %v", err)
+	}
+	runGitCmd(t, worktreePath, "add", "fix.go")
+	runGitCmd(t, worktreePath, "commit", "-m", "add fix")
+	if err := os.WriteFile(filepath.Join(worktreePath, "README.md"), []byte("hello fixed\n"), 0o644); err != nil {
+		t.Fatalf("write readme: %v", err)
+	}
+	runGitCmd(t, worktreePath, "commit", "-am", "update readme")
+
+	store, err := db.Open(dbPath)
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	ctx := context.Background()
+	if err := store.UpdateJobField(ctx, jobID, "worktree_path", worktreePath); err != nil {
+		t.Fatalf("set worktree path: %v", err)
+	}
+	if err := store.UpdateJobField(ctx, jobID, "branch_name", "autopr/8201"); err != nil {
+		t.Fatalf("set branch: %v", err)
+	}
+	store.Close()
+
+	setPatchFlags(t, configPath)
+	bundle := filepath.Join(tmp, "job.autopr-patch.tar.gz")
+	exportPatchOut = bundle
+	cmd := &cobra.Command{}
+	cmd.SetContext(ctx)
+	out := captureStdout(t, func() error { return runExportPatch(cmd, []string{jobID}) })
+	if !strings.Contains(out, "Exported 2 patch(es)") {
+		t.Fatalf("unexpected export output: %q", out)
+	}
+
+	target := filepath.Join(tmp, "airgapped")
+	runGitCmd(t, "", "clone", filepath.Join(tmp, "remote.git"), target)
+	runGitCmd(t, target, "config", "user.email", "reviewer@example.com")
+	runGitCmd(t, target, "config", "user.name", "Reviewer")
+
+	applyPatchRepo = target
+	out = captureStdout(t, func() error { return runApplyPatch(cmd, []string{bundle}) })
+	if !strings.Contains(out, "Applied 2 patch(es)") {
+		t.Fatalf("unexpected apply output: %q", out)
+	}
+	if got := gitOutput(t, target, "rev-parse", "--abbrev-ref", "HEAD"); got != "autopr/8201" {
+		t.Fatalf("expected job branch checked out, got %q", got)
+	}
+	if got := gitOutput(t, target, "log", "--format=%an %s", "main..HEAD"); got != "AutoPR update readme\nAutoPR add fix" {
+		t.Fatalf("expected both commits with original author, got %q", got)
+	}
+	if got := gitOutput(t, target, "rev-parse", "HEAD^{tree}"); got != gitOutput(t, worktreePath, "rev-parse", "HEAD^{tree}") {
+		t.Fatalf("expected applied tree to match the job worktree")
+	}
+}
+
+func TestApplyPatchRequiresBaseCommit(t *testing.T) {
+	tmp := t.TempDir()
+	configPath := writeMergeConfig(t, tmp)
+	dbPath := filepath.Join(tmp, "autopr.db")
+	jobID := createMergeJobForTest(t, dbPath, "project", "8202", "ready", "", "")
+
+	worktreePath := setupDiffWorktree(t, tmp)
+	runGitCmd(t, worktreePath, "config", "user.email", "bot@example.com")
+	runGitCmd(t, worktreePath, "config", "user.name", "AutoPR")
+	if err := os.WriteFile(filepath.Join(worktreePath, "fix.go"), []byte("package fix\n"), 0o644); err != nil {
+		t.Fatalf("write fix: %v", err)
+	}
+	runGitCmd(t, worktreePath, "add", "fix.go")
+	runGitCmd(t, worktreePath, "commit", "-m", "add fix")
+
+	store, err := db.Open(dbPath)
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	ctx := context.Background()
+	if err := store.UpdateJobField(ctx, jobID, "worktree_path", worktreePath); err != nil {
+		t.Fatalf("set worktree path: %v", err)
+	}
+	store.Close()
+
+	setPatchFlags(t, configPath)
+	bundle := filepath.Join(tmp, "job.autopr-patch.tar.gz")
+	exportPatchOut = bundle
+	cmd := &cobra.Command{}
+	cmd.SetContext(ctx)
+	_ = captureStdout(t, func() error { return runExportPatch(cmd, []string{jobID}) })
+
+	// An unrelated repository does not contain the bundle's base commit.
+	target := filepath.Join(tmp, "unrelated")
+	runGitCmd(t, "", "init", target)
+	runGitCmd(t, target, "config", "user.email", "reviewer@example.com")
+	runGitCmd(t, target, "config", "user.name", "Reviewer")
+	runGitCmd(t, target, "commit", "--allow-empty", "-m", "root")
+
+	applyPatchRepo = target
+	applyPatchBranch = "review"
+	err = runApplyPatch(cmd, []string{bundle})
+	if err == nil || !strings.Contains(err.Error(), "--onto-head") {
+		t.Fatalf("expected missing base commit error, got %v", err)
+	}
+
+	applyPatchDryRun = true
+	out := captureStdout(t, func() error { return runApplyPatch(cmd, []string{bundle}) })
+	if !strings.Contains(out, "0001-add-fix.patch") {
+		t.Fatalf("expected dry run to list patches, got %q", out)
+	}
+}
@@ -0,0 +1,166 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"autopr/internal/db"
+	"autopr/internal/policy"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	policyProject string
+	policyIssues  bool
+)
+
+var policyCmd = &cobra.Command{
+	Use:   "policy",
+	Short: "Check the projects' Starlark policy scripts",
+}
+
+var policyCheckCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Load each project's policy_file and report the policy functions it defines",
+	Long: `Load the policy_file of each project (or --project) and report the policy
+functions it defines: eligible, route, priority, and gate. A script that fails
+to load is reported with its error and makes the command fail.
+
+With --issues, the script is also run on the project's open synced issues,
+showing whether each would be queued, skipped, or routed elsewhere, and the
+priority its job would get. Nothing is written to the database.`,
+	Args: cobra.NoArgs,
+	RunE: runPolicyCheck,
+}
+
+func init() {
+	policyCheckCmd.Flags().StringVar(&policyProject, "project", "", "only this project")
+	policyCheckCmd.Flags().BoolVar(&policyIssues, "issues", false, "also run the script on open synced issues")
+	policyCmd.AddCommand(policyCheckCmd)
+	rootCmd.AddCommand(policyCmd)
+}
+
+type policyCheckResult struct {
+	Project   string              `json:"project"`
+	File      string              `json:"file"`
+	Functions []string            `json:"functions"`
+	Error     string              `json:"error,omitempty"`
+	Issues    []policyIssueResult `json:"issues,omitempty"`
+}
+
+type policyIssueResult struct {
+	Issue    string `json:"issue"`
+	Title    string `json:"title"`
+	Decision string `json:"decision"` // queue, skip, route, or error
+	Detail   string `json:"detail,omitempty"`
+	Priority int    `json:"priority"`
+}
+
+func runPolicyCheck(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	if policyProject != "" {
+		if _, ok := cfg.ProjectByName(policyProject); !ok {
+			return fmt.Errorf("project %q not found in config", policyProject)
+		}
+	}
+	var store *db.Store
+	if policyIssues {
+		if store, err = openStore(cfg); err != nil {
+			return err
+		}
+		defer store.Close()
+	}
+
+	results := []policyCheckResult{}
+	failed := 0
+	for _, p := range cfg.Projects {
+		if p.PolicyFile == "" || (policyProject != "" && p.Name != policyProject) {
+			continue
+		}
+		res := policyCheckResult{Project: p.Name, File: p.PolicyFile, Functions: []string{}}
+		pol, err := policy.Load(p.PolicyFile)
+		if err != nil {
+			res.Error = err.Error()
+			failed++
+			results = append(results, res)
+			continue
+		}
+		res.Functions = pol.Functions()
+		if store != nil {
+			issues, err := store.ListIssues(cmd.Context(), p.Name, nil)
+			if err != nil {
+				return err
+			}
+			for _, issue := range issues {
+				if issue.State != "open" {
+					continue
+				}
+				res.Issues = append(res.Issues, checkPolicyIssue(pol, p.Name, issue, cfg.Daemon.MaxIterations))
+			}
+		}
+		results = append(results, res)
+	}
+
+	if jsonOut {
+		printJSON(results)
+	} else if len(results) == 0 {
+		fmt.Println("No policy_file configured.")
+	} else {
+		for _, res := range results {
+			if res.Error != "" {
+				fmt.Printf("%s: %s\n  FAILED: %s\n", res.Project, res.File, res.Error)
+				continue
+			}
+			functions := strings.Join(res.Functions, ", ")
+			if functions == "" {
+				functions = "no policy functions"
+			}
+			fmt.Printf("%s: %s\n  ok: %s\n", res.Project, res.File, functions)
+			for _, ir := range res.Issues {
+				decision := ir.Decision
+				if ir.Detail != "" {
+					decision += ": " + ir.Detail
+				}
+				fmt.Printf("  %-8s %-40s %-40s priority %d\n", ir.Issue, truncate(ir.Title, 40), truncate(decision, 40), ir.Priority)
+			}
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d policy file(s) failed to load", failed)
+	}
+	return nil
+}
+
+// checkPolicyIssue runs route, eligible, and priority on a synced issue the
+// way a sync round and a newly queued job would.
+func checkPolicyIssue(pol *policy.Policy, project string, issue db.Issue, maxIterations int) policyIssueResult {
+	in := policy.IssueFromDB(issue)
+	res := policyIssueResult{Issue: "#" + issue.SourceIssueID, Title: issue.Title, Decision: "queue"}
+	if owner, err := pol.Route(in); err != nil {
+		res.Decision, res.Detail = "error", err.Error()
+		return res
+	} else if owner != "" && owner != project {
+		res.Decision, res.Detail = "route", owner
+		return res
+	}
+	reason, err := pol.Eligible(in)
+	if err != nil {
+		res.Decision, res.Detail = "error", err.Error()
+		return res
+	}
+	if reason != "" {
+		res.Decision, res.Detail = "skip", reason
+		return res
+	}
+	job := policy.Job{Project: project, State: "queued", MaxIterations: maxIterations}
+	if priority, _, err := pol.Priority(job, in); err != nil {
+		res.Decision, res.Detail = "error", err.Error()
+	} else {
+		res.Priority = priority
+	}
+	return res
+}
@@ -0,0 +1,149 @@
+package cli
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var projectCmd = &cobra.Command{
+	Use:   "project",
+	Short: "List projects and enable or disable them without editing the config",
+	Args:  cobra.NoArgs,
+	RunE:  runProjectList,
+}
+
+var projectListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List configured projects, whether they are enabled, and their detected stack",
+	Args:  cobra.NoArgs,
+	RunE:  runProjectList,
+}
+
+var projectEnableCmd = &cobra.Command{
+	Use:   "enable <name>",
+	Short: "Resume syncing and claiming jobs for a project",
+	Args:  cobra.ExactArgs(1),
+	RunE:  func(cmd *cobra.Command, args []string) error { return runProjectSetEnabled(cmd, args[0], true) },
+}
+
+var projectDisableCmd = &cobra.Command{
+	Use:   "disable <name>",
+	Short: "Stop syncing and claiming jobs for a project, keeping its jobs and config",
+	Args:  cobra.ExactArgs(1),
+	RunE:  func(cmd *cobra.Command, args []string) error { return runProjectSetEnabled(cmd, args[0], false) },
+}
+
+func init() {
+	projectCmd.AddCommand(projectListCmd)
+	projectCmd.AddCommand(projectEnableCmd)
+	projectCmd.AddCommand(projectDisableCmd)
+	rootCmd.AddCommand(projectCmd)
+}
+
+type projectOutput struct {
+	Name    string `json:"name"`
+	Enabled bool   `json:"enabled"`
+	// Source is "config" or "override" (set with ap project enable|disable).
+	Source string `json:"source"`
+	// Languages and Frameworks are what sync last detected in the repository.
+	Languages  []string `json:"languages,omitempty"`
+	Frameworks []string `json:"frameworks,omitempty"`
+}
+
+func runProjectList(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	store, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	overrides, err := store.ProjectEnabledOverrides(cmd.Context())
+	if err != nil {
+		return err
+	}
+	out := make([]projectOutput, 0, len(cfg.Projects))
+	for _, p := range cfg.Projects {
+		source := "config"
+		if _, ok := overrides[p.Name]; ok {
+			source = "override"
+		}
+		po := projectOutput{Name: p.Name, Enabled: cfg.ProjectEnabled(p.Name, overrides), Source: source}
+		ps, ok, err := store.GetProjectStack(cmd.Context(), p.Name)
+		if err != nil {
+			return err
+		}
+		if ok {
+			po.Languages, po.Frameworks = ps.Languages, ps.Frameworks
+		}
+		out = append(out, po)
+	}
+
+	if jsonOut {
+		printJSON(out)
+		return nil
+	}
+	fmt.Printf("%-24s %-9s %-9s %s\n", "PROJECT", "STATE", "SOURCE", "STACK")
+	fmt.Println(strings.Repeat("-", 70))
+	for _, p := range out {
+		state := "enabled"
+		if !p.Enabled {
+			state = "disabled"
+		}
+		stack := strings.Join(append(slices.Clone(p.Languages), p.Frameworks...), ", ")
+		if stack == "" {
+			stack = "-"
+		}
+		fmt.Printf("%-24s %-9s %-9s %s\n", p.Name, state, p.Source, stack)
+	}
+	return nil
+}
+
+// runProjectSetEnabled records an override only while the wanted state
+// differs from the config file, so editing the config later still works.
+func runProjectSetEnabled(cmd *cobra.Command, name string, enabled bool) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	proj, ok := cfg.ProjectByName(name)
+	if !ok {
+		return fmt.Errorf("unknown project %q", name)
+	}
+	store, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	ctx := cmd.Context()
+	if enabled == proj.IsEnabled() {
+		err = store.ClearProjectEnabledOverride(ctx, name)
+	} else {
+		err = store.SetProjectEnabledOverride(ctx, name, enabled)
+	}
+	if err != nil {
+		return err
+	}
+
+	out := projectOutput{Name: name, Enabled: enabled, Source: "config"}
+	if enabled != proj.IsEnabled() {
+		out.Source = "override"
+	}
+	if jsonOut {
+		printJSON(out)
+		return nil
+	}
+	if enabled {
+		fmt.Printf("Project %s enabled.\n", name)
+	} else {
+		fmt.Printf("Project %s disabled. Its queued jobs wait until `ap project enable %s`.\n", name, name)
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"autopr/internal/db"
+
+	"github.com/spf13/cobra"
+)
+
+func TestProjectDisableAndEnableRoundTrip(t *testing.T) {
+	tmp := t.TempDir()
+	dbPath := filepath.Join(tmp, "autopr.db")
+	projectCfgPath := writeMergeConfig(t, tmp)
+
+	prevCfgPath := cfgPath
+	prevJSON := jsonOut
+	defer func() {
+		cfgPath = prevCfgPath
+		jsonOut = prevJSON
+	}()
+	cfgPath = projectCfgPath
+	jsonOut = true
+
+	cmd := &cobra.Command{}
+	cmd.SetContext(context.Background())
+	overrides := func() map[string]bool {
+		t.Helper()
+		store, err := db.Open(dbPath)
+		if err != nil {
+			t.Fatalf("open db: %v", err)
+		}
+		defer store.Close()
+		out, err := store.ProjectEnabledOverrides(context.Background())
+		if err != nil {
+			t.Fatalf("list overrides: %v", err)
+		}
+		return out
+	}
+
+	if err := runProjectSetEnabled(cmd, "missing", false); err == nil {
+		t.Fatalf("expected unknown project error")
+	}
+	if err := runProjectSetEnabled(cmd, "project", false); err != nil {
+		t.Fatalf("disable: %v", err)
+	}
+	if got := overrides(); len(got) != 1 || got["project"] {
+		t.Fatalf("expected a disabled override, got %v", got)
+	}
+	if err := runProjectList(cmd, nil); err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if err := runProjectSetEnabled(cmd, "project", true); err != nil {
+		t.Fatalf("enable: %v", err)
+	}
+	if got := overrides(); len(got) != 0 {
+		t.Fatalf("expected enabling a config-enabled project to clear the override, got %v", got)
+	}
+}
+
+func TestProjectEnableOverridesConfigDisabled(t *testing.T) {
+	tmp := t.TempDir()
+	dbPath := filepath.Join(tmp, "autopr.db")
+	projectCfgPath := writeMergeConfig(t, tmp)
+	content, err := os.ReadFile(projectCfgPath)
+	if err != nil {
+		t.Fatalf("read config: %v", err)
+	}
+	disabled := strings.Replace(string(content), `name = "project"`, "name = \"project\"\nenabled = false", 1)
+	if err := os.WriteFile(projectCfgPath, []byte(disabled), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	prevCfgPath := cfgPath
+	prevJSON := jsonOut
+	defer func() {
+		cfgPath = prevCfgPath
+		jsonOut = prevJSON
+	}()
+	cfgPath = projectCfgPath
+	jsonOut = true
+
+	cmd := &cobra.Command{}
+	cmd.SetContext(context.Background())
+	if err := runProjectSetEnabled(cmd, "project", true); err != nil {
+		t.Fatalf("enable: %v", err)
+	}
+	store, err := db.Open(dbPath)
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer store.Close()
+	got, err := store.ProjectEnabledOverrides(context.Background())
+	if err != nil {
+		t.Fatalf("list overrides: %v", err)
+	}
+	if len(got) != 1 || !got["project"] {
+		t.Fatalf("expected an enabled override for a config-disabled project, got %v", got)
+	}
+}
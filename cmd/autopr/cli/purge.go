@@ -0,0 +1,76 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"autopr/internal/db"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	purgeJob       string
+	purgeOlderThan time.Duration
+)
+
+var purgeCmd = &cobra.Command{
+	Use:   "purge --job <job-id> | --older-than <duration>",
+	Short: "Delete LLM prompt and response text, keeping token counts and hashes",
+	Long: `Delete the prompt and response text and transcript files of finished LLM
+sessions, either for one job or for every session older than a duration.
+Token counts, durations, and SHA-256 hashes of the text are kept, so costs and
+stats still work. Running sessions are skipped.
+
+The daemon does the same automatically when [retention] session_text_after
+is set.`,
+	Args: cobra.NoArgs,
+	RunE: runPurge,
+}
+
+func init() {
+	purgeCmd.Flags().StringVar(&purgeJob, "job", "", "purge every session of this job")
+	purgeCmd.Flags().DurationVar(&purgeOlderThan, "older-than", 0, "purge sessions older than this (e.g. 720h)")
+	rootCmd.AddCommand(purgeCmd)
+}
+
+func runPurge(cmd *cobra.Command, args []string) error {
+	if (purgeJob == "") == (purgeOlderThan <= 0) {
+		return fmt.Errorf("expected exactly one of --job or --older-than")
+	}
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	store, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	var jobID string
+	var n int
+	if purgeJob != "" {
+		jobID, err = resolveJob(store, purgeJob)
+		if err != nil {
+			return err
+		}
+		n, err = store.PurgeJobSessionText(cmd.Context(), jobID)
+	} else {
+		n, err = store.PurgeSessionTextBefore(cmd.Context(), time.Now().Add(-purgeOlderThan))
+	}
+	if err != nil {
+		return err
+	}
+
+	if jsonOut {
+		printJSON(map[string]any{"job_id": jobID, "sessions_purged": n})
+		return nil
+	}
+	if jobID != "" {
+		fmt.Printf("Purged text from %d session(s) of job %s\n", n, db.ShortID(jobID))
+		return nil
+	}
+	fmt.Printf("Purged text from %d session(s) older than %s\n", n, purgeOlderThan)
+	return nil
+}
@@ -0,0 +1,133 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"autopr/internal/config"
+	"autopr/internal/git"
+	"autopr/internal/pipeline"
+	"autopr/internal/releasenotes"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	releaseNotesSince   string
+	releaseNotesProject string
+	releaseNotesRepo    string
+	releaseNotesHeading string
+	releaseNotesPR      bool
+)
+
+var releaseNotesCmd = &cobra.Command{
+	Use:   "release-notes --since <tag|date>",
+	Short: "Assemble release notes from the jobs merged since a release",
+	Long: `Print Markdown release notes for the AutoPR jobs of a project whose PRs
+merged since a release, grouped into Features, Fixes, and Chores. A job's group
+comes from a conventional commit type (feat:, fix:, chore:, ...) on its issue
+title or summary, then from its issue labels; anything else counts as a fix.
+Each line is the job's summary, or its issue title, with a link to the PR.
+Backports are left out, since the job they copied is listed already.
+
+--since is a tag or other ref, looked up in --repo, or a date (2026-01-31 or
+RFC 3339). A ref stands for the date of the commit it names.
+
+With --pr, the notes are added to the top of CHANGELOG.md on a new branch and a
+PR is opened against the project's base branch.`,
+	Args: cobra.NoArgs,
+	RunE: runReleaseNotes,
+}
+
+func init() {
+	releaseNotesCmd.Flags().StringVar(&releaseNotesSince, "since", "", "tag, ref, or date of the last release (required)")
+	releaseNotesCmd.Flags().StringVar(&releaseNotesProject, "project", "", "project to assemble notes for (required with more than one project)")
+	releaseNotesCmd.Flags().StringVar(&releaseNotesRepo, "repo", ".", "git checkout to look up --since in")
+	releaseNotesCmd.Flags().StringVar(&releaseNotesHeading, "heading", "Unreleased", "heading of the notes, e.g. the new version")
+	releaseNotesCmd.Flags().BoolVar(&releaseNotesPR, "pr", false, "open a PR adding the notes to CHANGELOG.md")
+	_ = releaseNotesCmd.MarkFlagRequired("since")
+	rootCmd.AddCommand(releaseNotesCmd)
+}
+
+func runReleaseNotes(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	proj, err := releaseNotesProjectFor(cfg, releaseNotesProject)
+	if err != nil {
+		return err
+	}
+	ctx := cmd.Context()
+	since, err := resolveReleaseSince(ctx, releaseNotesSince, releaseNotesRepo)
+	if err != nil {
+		return err
+	}
+	store, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	jobs, err := store.ListMergedJobs(ctx, proj.Name, since.UTC().Format(time.RFC3339))
+	if err != nil {
+		return err
+	}
+	entries := releasenotes.Entries(jobs)
+	section := releasenotes.Render(releaseNotesHeading, entries)
+
+	if !releaseNotesPR {
+		if jsonOut {
+			printJSON(map[string]any{"project": proj.Name, "since": since.UTC().Format(time.RFC3339), "entries": entries, "markdown": section})
+			return nil
+		}
+		fmt.Print(section)
+		return nil
+	}
+	prURL, err := pipeline.OpenReleaseNotesPR(ctx, cfg, proj, releaseNotesHeading, section, git.UserIdentity(ctx, ""))
+	if err != nil {
+		return fmt.Errorf("open release notes PR: %w", err)
+	}
+	if jsonOut {
+		printJSON(map[string]any{"project": proj.Name, "entries": len(entries), "pr_url": prURL})
+		return nil
+	}
+	if prURL == "" {
+		fmt.Printf("Pushed the release notes branch for %s (%d entries).\n", proj.Name, len(entries))
+		return nil
+	}
+	fmt.Printf("Opened %s with %d entries.\n", prURL, len(entries))
+	return nil
+}
+
+// releaseNotesProjectFor returns the named project, or the only project when
+// name is empty.
+func releaseNotesProjectFor(cfg *config.Config, name string) (*config.ProjectConfig, error) {
+	if name == "" {
+		if len(cfg.Projects) != 1 {
+			return nil, fmt.Errorf("--project is required when more than one project is configured")
+		}
+		return &cfg.Projects[0], nil
+	}
+	proj, ok := cfg.ProjectByName(name)
+	if !ok {
+		return nil, fmt.Errorf("unknown project %q", name)
+	}
+	return proj, nil
+}
+
+// resolveReleaseSince turns --since into a time: a date as given, or else the
+// date of the commit a ref in repo names.
+func resolveReleaseSince(ctx context.Context, since, repo string) (time.Time, error) {
+	for _, layout := range []string{time.RFC3339, time.DateOnly} {
+		if t, err := time.Parse(layout, since); err == nil {
+			return t, nil
+		}
+	}
+	t, err := git.CommitTime(ctx, repo, since)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("--since %q is neither a date nor a ref in %s: %w", since, repo, err)
+	}
+	return t, nil
+}
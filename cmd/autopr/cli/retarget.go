@@ -0,0 +1,83 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	"autopr/internal/config"
+	"autopr/internal/db"
+	"autopr/internal/pipeline"
+
+	"github.com/spf13/cobra"
+)
+
+var retargetCmd = &cobra.Command{
+	Use:   "retarget <job-id> <branch>",
+	Short: "Move a job onto another base branch before approval",
+	Long: `Move a job that was started against the wrong base branch (say main
+instead of a release branch) onto branch. The job's commits are rebased onto
+the new branch, and its tests, diffs, and PR use that branch from then on. The
+change is recorded in the job's log as a rebase result.
+
+Jobs can be retargeted before they start, and when ready, needs_review_oversize,
+failed, rejected, or cancelled. A rebase with conflicts is aborted and leaves
+the job as it was.`,
+	Args: cobra.ExactArgs(2),
+	RunE: runRetarget,
+}
+
+func init() {
+	rootCmd.AddCommand(retargetCmd)
+}
+
+func runRetarget(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	store, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	jobID, err := resolveJob(store, args[0])
+	if err != nil {
+		return err
+	}
+
+	// Hold the job's lease so a worker cannot pick the job up mid-rebase.
+	var from string
+	err = store.WithJobLease(cmd.Context(), jobID, db.LeaseHolder("ap retarget"), func(ctx context.Context) error {
+		var err error
+		from, err = retargetJob(ctx, cfg, store, jobID, args[1])
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if jsonOut {
+		printJSON(map[string]string{"job_id": jobID, "from": from, "base_branch": args[1]})
+		return nil
+	}
+	fmt.Printf("Job %s now targets %s (was %s).\n", db.ShortID(jobID), args[1], from)
+	return nil
+}
+
+// retargetJob moves the job onto branch and returns the branch it targeted
+// before.
+func retargetJob(ctx context.Context, cfg *config.Config, store *db.Store, jobID, branch string) (string, error) {
+	job, err := store.GetJob(ctx, jobID)
+	if err != nil {
+		return "", err
+	}
+	proj, ok := cfg.ProjectByName(job.ProjectName)
+	if !ok {
+		return "", fmt.Errorf("project %q not found in config", job.ProjectName)
+	}
+	from := pipeline.TargetBranch(job, proj)
+	if err := pipeline.RetargetJob(ctx, cfg, store, job, proj, branch); err != nil {
+		return "", err
+	}
+	return from, nil
+}
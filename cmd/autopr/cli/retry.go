@@ -3,6 +3,8 @@ package cli
 import (
 	"fmt"
 
+	"autopr/internal/issuepolicy"
+
 	"github.com/spf13/cobra"
 )
 
@@ -54,6 +56,12 @@ func runRetry(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("cannot retry: another active job (%s) already exists for this issue", activeID)
 	}
 
+	// Hold the issue first if it is past its project's issue policy, so the
+	// retry is refused with the reason.
+	if err := issuepolicy.New(cfg, store).ApplyIssue(cmd.Context(), job.AutoPRIssueID); err != nil {
+		return err
+	}
+
 	if err := store.ResetJobForRetry(cmd.Context(), jobID, retryNotes); err != nil {
 		return err
 	}
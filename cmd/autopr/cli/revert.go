@@ -0,0 +1,68 @@
+package cli
+
+import (
+	"fmt"
+
+	"autopr/internal/db"
+	"autopr/internal/pipeline"
+
+	"github.com/spf13/cobra"
+)
+
+var revertCmd = &cobra.Command{
+	Use:   "revert <job-id>",
+	Short: "Create a job that reverts a merged job's PR and opens a revert PR",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runRevert,
+}
+
+func init() {
+	rootCmd.AddCommand(revertCmd)
+}
+
+func runRevert(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	store, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	jobID, err := resolveJob(store, args[0])
+	if err != nil {
+		return err
+	}
+
+	job, err := store.GetJob(cmd.Context(), jobID)
+	if err != nil {
+		return err
+	}
+	if !pipeline.CanRevert(&job) {
+		return fmt.Errorf("job %s must be approved with a merged PR to revert (state %q)", db.ShortID(jobID), job.State)
+	}
+
+	proj, ok := cfg.ProjectByName(job.ProjectName)
+	if !ok {
+		return fmt.Errorf("project %q not found in config", job.ProjectName)
+	}
+
+	commit, err := pipeline.MergeCommitForJob(cmd.Context(), cfg, proj, job)
+	if err != nil {
+		return fmt.Errorf("find merge commit: %w", err)
+	}
+	newID, err := pipeline.CreateRevertJob(cmd.Context(), store, job, commit)
+	if err != nil {
+		return fmt.Errorf("create revert job: %w", err)
+	}
+
+	if jsonOut {
+		printJSON(map[string]string{"job_id": newID, "parent_job_id": jobID, "revert_commit": commit, "state": "queued"})
+		return nil
+	}
+	fmt.Printf("Revert job %s queued (reverts %s, commit %s).\n", db.ShortID(newID), db.ShortID(jobID), shortCommit(commit))
+	fmt.Println("The daemon opens the revert PR once the revert applies cleanly and tests pass.")
+	return nil
+}
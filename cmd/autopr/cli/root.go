@@ -3,6 +3,7 @@ package cli
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
@@ -10,6 +11,7 @@ import (
 
 	"autopr/internal/config"
 	"autopr/internal/db"
+	"autopr/internal/httputil"
 
 	"github.com/spf13/cobra"
 )
@@ -47,20 +49,53 @@ func init() {
 
 func Execute() error {
 	if err := rootCmd.Execute(); err != nil {
-		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		var exitErr *exitCodeError
+		if !errors.As(err, &exitErr) || exitErr.err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		}
 		return err
 	}
 	return nil
 }
 
+// exitCodeError ends a command with a specific process exit code. A nil err
+// exits quietly; the command has already reported the outcome.
+type exitCodeError struct {
+	code int
+	err  error
+}
+
+func (e *exitCodeError) Error() string {
+	if e.err == nil {
+		return fmt.Sprintf("exit status %d", e.code)
+	}
+	return e.err.Error()
+}
+
+func (e *exitCodeError) Unwrap() error { return e.err }
+
+// ExitCode returns the process exit code for an error returned by Execute.
+func ExitCode(err error) int {
+	var exitErr *exitCodeError
+	if errors.As(err, &exitErr) {
+		return exitErr.code
+	}
+	return 1
+}
+
 // resolveConfigPath determines which config file to use.
-// Priority: --config flag > ./autopr.toml > ~/.config/autopr/config.toml.
+// Priority: --config flag > $AUTOPR_CONFIG > ./autopr.toml > ~/.config/autopr/config.toml.
 func resolveConfigPath() (string, error) {
 	// 1. Explicit --config flag.
 	if cfgPath != "" {
 		return cfgPath, nil
 	}
 
+	// 1b. AUTOPR_CONFIG, e.g. a config file mounted into a container.
+	if p := os.Getenv("AUTOPR_CONFIG"); p != "" {
+		return p, nil
+	}
+
 	// 2. Local autopr.toml in current directory (backward compat).
 	if _, err := os.Stat("autopr.toml"); err == nil {
 		return "autopr.toml", nil
@@ -85,7 +120,19 @@ func loadConfig() (*config.Config, error) {
 	if err := config.MigrateConfigFile(path); err != nil {
 		slog.Warn("config migration skipped", "err", err)
 	}
-	return config.Load(path)
+	cfg, err := config.Load(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := httputil.ApplyNetwork(httputil.NetworkConfig{
+		HTTPProxy:  cfg.Network.HTTPProxy,
+		HTTPSProxy: cfg.Network.HTTPSProxy,
+		NoProxy:    cfg.Network.NoProxy,
+		CABundle:   cfg.Network.CABundle,
+	}); err != nil {
+		return nil, fmt.Errorf("apply network config: %w", err)
+	}
+	return cfg, nil
 }
 
 func openStore(cfg *config.Config) (*db.Store, error) {
@@ -97,7 +144,7 @@ func openStore(cfg *config.Config) (*db.Store, error) {
 		_ = os.Remove(cfg.DBPath + "-shm")
 		_ = os.Remove(cfg.DBPath + "-wal")
 	}
-	return db.Open(cfg.DBPath)
+	return db.OpenWithKey(cfg.DBPath, cfg.DBKey)
 }
 
 func printJSON(v any) {
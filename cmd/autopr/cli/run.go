@@ -0,0 +1,148 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"autopr/internal/config"
+	"autopr/internal/db"
+	"autopr/internal/pipeline"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	runBody     string
+	runBodyFile string
+	runTemplate string
+	runVars     []string
+)
+
+var runCmd = &cobra.Command{
+	Use:   "run <project> \"title\" | run --template <name> [--var key=value ...]",
+	Short: "Queue a job for a task described on the command line or by a config template, without a tracker issue",
+	Args: func(cmd *cobra.Command, args []string) error {
+		if runTemplate != "" {
+			return cobra.NoArgs(cmd, args)
+		}
+		return cobra.ExactArgs(2)(cmd, args)
+	},
+	RunE: runRun,
+}
+
+func init() {
+	runCmd.Flags().StringVarP(&runBody, "body", "b", "", "task description")
+	runCmd.Flags().StringVar(&runBodyFile, "body-file", "", "read the task description from a file (- for stdin)")
+	runCmd.Flags().StringVarP(&runTemplate, "template", "t", "", "queue the task of a [[templates]] entry from the config")
+	runCmd.Flags().StringArrayVar(&runVars, "var", nil, "template variable as key=value (repeatable)")
+	rootCmd.AddCommand(runCmd)
+}
+
+func runRun(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	if runTemplate != "" {
+		return runRunTemplate(cmd, cfg)
+	}
+	if len(runVars) > 0 {
+		return fmt.Errorf("--var needs --template")
+	}
+	proj, ok := cfg.ProjectByName(args[0])
+	if !ok {
+		return fmt.Errorf("project %q not found in config", args[0])
+	}
+
+	body := runBody
+	if runBodyFile != "" {
+		if runBody != "" {
+			return fmt.Errorf("--body and --body-file cannot be combined")
+		}
+		var data []byte
+		if runBodyFile == "-" {
+			data, err = io.ReadAll(os.Stdin)
+		} else {
+			data, err = os.ReadFile(runBodyFile)
+		}
+		if err != nil {
+			return fmt.Errorf("read body: %w", err)
+		}
+		body = string(data)
+	}
+
+	store, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	jobID, err := pipeline.CreateManualJob(cmd.Context(), store, proj, cfg.Daemon.MaxIterations, args[1], body)
+	if err != nil {
+		return fmt.Errorf("create job: %w", err)
+	}
+
+	printQueuedRun(jobID, proj, "")
+	return nil
+}
+
+// runRunTemplate queues the task of the job template named by --template,
+// with the --var values filled in, for the template's project.
+func runRunTemplate(cmd *cobra.Command, cfg *config.Config) error {
+	if runBody != "" || runBodyFile != "" {
+		return fmt.Errorf("--body and --body-file cannot be combined with --template")
+	}
+	tmpl, ok := cfg.TemplateByName(runTemplate)
+	if !ok {
+		names := make([]string, 0, len(cfg.Templates))
+		for _, t := range cfg.Templates {
+			names = append(names, t.Name)
+		}
+		if len(names) == 0 {
+			return fmt.Errorf("template %q not found: the config has no [[templates]]", runTemplate)
+		}
+		return fmt.Errorf("template %q not found (have: %s)", runTemplate, strings.Join(names, ", "))
+	}
+	proj, ok := cfg.ProjectByName(tmpl.Project)
+	if !ok {
+		return fmt.Errorf("project %q not found in config", tmpl.Project)
+	}
+	vars, err := pipeline.ParseTemplateVars(runVars)
+	if err != nil {
+		return err
+	}
+
+	store, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	jobID, err := pipeline.CreateTemplateJob(cmd.Context(), store, proj, cfg.Daemon.MaxIterations, tmpl, vars)
+	if err != nil {
+		return fmt.Errorf("create job: %w", err)
+	}
+	printQueuedRun(jobID, proj, tmpl.Name)
+	return nil
+}
+
+func printQueuedRun(jobID string, proj *config.ProjectConfig, template string) {
+	if jsonOut {
+		out := map[string]any{"job_id": jobID, "project": proj.Name, "state": "queued"}
+		if template != "" {
+			out["template"] = template
+		}
+		printJSON(out)
+		return
+	}
+	if template != "" {
+		fmt.Printf("Job %s queued for %s from template %s.\n", db.ShortID(jobID), proj.Name, template)
+	} else {
+		fmt.Printf("Job %s queued for %s.\n", db.ShortID(jobID), proj.Name)
+	}
+	if proj.IsLocal() {
+		fmt.Printf("Approving it pushes the job branch to %s; no PR is opened.\n", proj.RepoURL)
+	}
+}
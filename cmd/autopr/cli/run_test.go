@@ -0,0 +1,134 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"autopr/internal/db"
+	"autopr/internal/pipeline"
+
+	"github.com/spf13/cobra"
+)
+
+func TestRunQueuesManualJobForLocalProject(t *testing.T) {
+	tmp := t.TempDir()
+	configPath := filepath.Join(tmp, "autopr.toml")
+	dbPath := filepath.Join(tmp, "autopr.db")
+	content := fmt.Sprintf(`db_path = %q
+repos_root = %q
+
+[[projects]]
+name = "local"
+repo_url = %q
+test_cmd = "echo ok"
+
+[projects.local]
+`, dbPath, filepath.Join(tmp, "repos"), filepath.Join(tmp, "app"))
+	if err := os.WriteFile(configPath, []byte(content), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	bodyPath := filepath.Join(tmp, "task.md")
+	if err := os.WriteFile(bodyPath, []byte("Return 404 instead of 500.\n"), 0o644); err != nil {
+		t.Fatalf("write body: %v", err)
+	}
+
+	prevCfgPath, prevJSON, prevBody, prevBodyFile := cfgPath, jsonOut, runBody, runBodyFile
+	cfgPath, jsonOut, runBody, runBodyFile = configPath, false, "", bodyPath
+	defer func() { cfgPath, jsonOut, runBody, runBodyFile = prevCfgPath, prevJSON, prevBody, prevBodyFile }()
+
+	cmd := &cobra.Command{}
+	cmd.SetContext(context.Background())
+	out := captureStdout(t, func() error { return runRun(cmd, []string{"local", "Fix missing user lookup"}) })
+	if !strings.Contains(out, "no PR is opened") {
+		t.Fatalf("expected local project note, got %q", out)
+	}
+
+	store, err := db.Open(dbPath)
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer store.Close()
+	jobs, err := store.ListJobs(context.Background(), "local", "queued", "created_at", true)
+	if err != nil || len(jobs) != 1 {
+		t.Fatalf("expected one queued job, got %d (err %v)", len(jobs), err)
+	}
+	issue, err := store.GetIssueByAPID(context.Background(), jobs[0].AutoPRIssueID)
+	if err != nil {
+		t.Fatalf("load issue: %v", err)
+	}
+	if issue.Source != pipeline.LocalSource || issue.Title != "Fix missing user lookup" || issue.Body != "Return 404 instead of 500." {
+		t.Fatalf("unexpected issue %+v", issue)
+	}
+
+	runBody = "inline"
+	if err := runRun(cmd, []string{"local", "again"}); err == nil || !strings.Contains(err.Error(), "cannot be combined") {
+		t.Fatalf("expected --body/--body-file conflict, got %v", err)
+	}
+}
+
+func TestRunQueuesTemplateJob(t *testing.T) {
+	tmp := t.TempDir()
+	configPath := filepath.Join(tmp, "autopr.toml")
+	dbPath := filepath.Join(tmp, "autopr.db")
+	content := fmt.Sprintf(`db_path = %q
+repos_root = %q
+
+[[projects]]
+name = "local"
+repo_url = %q
+test_cmd = "echo ok"
+
+[projects.local]
+
+[[templates]]
+name = "add-endpoint"
+project = "local"
+title = "Add {{name}} to {{service}}"
+prompt = "Add a {{name}} handler to the {{service}} service."
+test_cmd = "go test ./{{service}}/..."
+`, dbPath, filepath.Join(tmp, "repos"), filepath.Join(tmp, "app"))
+	if err := os.WriteFile(configPath, []byte(content), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	prevCfgPath, prevJSON, prevTemplate, prevVars := cfgPath, jsonOut, runTemplate, runVars
+	cfgPath, jsonOut, runTemplate, runVars = configPath, false, "add-endpoint", []string{"name=GetUser", "service=billing"}
+	defer func() { cfgPath, jsonOut, runTemplate, runVars = prevCfgPath, prevJSON, prevTemplate, prevVars }()
+
+	cmd := &cobra.Command{}
+	cmd.SetContext(context.Background())
+	out := captureStdout(t, func() error { return runRun(cmd, nil) })
+	if !strings.Contains(out, "from template add-endpoint") {
+		t.Fatalf("unexpected output %q", out)
+	}
+
+	store, err := db.Open(dbPath)
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer store.Close()
+	jobs, err := store.ListJobs(context.Background(), "local", "queued", "created_at", true)
+	if err != nil || len(jobs) != 1 {
+		t.Fatalf("expected one queued job, got %d (err %v)", len(jobs), err)
+	}
+	issue, err := store.GetIssueByAPID(context.Background(), jobs[0].AutoPRIssueID)
+	if err != nil {
+		t.Fatalf("load issue: %v", err)
+	}
+	if issue.Title != "Add GetUser to billing" || issue.Body != "Add a GetUser handler to the billing service." {
+		t.Fatalf("unexpected issue %+v", issue)
+	}
+	gates, ok, err := store.GetIssueTemplate(context.Background(), issue.AutoPRIssueID)
+	if err != nil || !ok || gates.TestCmd != "go test ./billing/..." {
+		t.Fatalf("unexpected template gates %+v, %v, %v", gates, ok, err)
+	}
+
+	runTemplate = "missing"
+	if err := runRun(cmd, nil); err == nil || !strings.Contains(err.Error(), "have: add-endpoint") {
+		t.Fatalf("expected unknown template error, got %v", err)
+	}
+}
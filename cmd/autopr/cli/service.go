@@ -2,6 +2,7 @@ package cli
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -39,33 +40,113 @@ func (launchdManager) PlistPath() (string, error) {
 	return launchdservice.PlistPath()
 }
 
+// taskManager runs the daemon as a Windows Task Scheduler task.
+type taskManager struct{}
+
+func (taskManager) Install(cfg *config.Config, resolvedConfigPath string) error {
+	return launchdservice.InstallTask(cfg, resolvedConfigPath)
+}
+
+func (taskManager) Uninstall() error {
+	return launchdservice.UninstallTask()
+}
+
+func (taskManager) Status(cfg *config.Config) (launchdservice.ServiceStatus, error) {
+	return launchdservice.TaskStatus(cfg)
+}
+
+func (taskManager) PlistPath() (string, error) {
+	return launchdservice.TaskScriptPath()
+}
+
+// systemdManager runs the daemon as a systemd user unit.
+type systemdManager struct{}
+
+func (systemdManager) Install(cfg *config.Config, resolvedConfigPath string) error {
+	return launchdservice.InstallUnit(cfg, resolvedConfigPath)
+}
+
+func (systemdManager) Uninstall() error {
+	return launchdservice.UninstallUnit()
+}
+
+func (systemdManager) Status(cfg *config.Config) (launchdservice.ServiceStatus, error) {
+	return launchdservice.UnitStatus(cfg)
+}
+
+func (systemdManager) PlistPath() (string, error) {
+	return launchdservice.UnitPath()
+}
+
 var (
 	servicePlatform                         = runtime.GOOS
 	serviceConfigLoader                     = loadConfig
 	serviceConfigPathResolve                = resolveConfigPath
-	serviceMgr               serviceManager = launchdManager{}
+	serviceMgr               serviceManager = platformServiceManager(runtime.GOOS)
 )
 
+// platformServiceManager returns the service backend for goos.
+func platformServiceManager(goos string) serviceManager {
+	switch goos {
+	case "windows":
+		return taskManager{}
+	case "linux":
+		return systemdManager{}
+	}
+	return launchdManager{}
+}
+
+// serviceSupported reports whether goos has a service backend.
+func serviceSupported(goos string) bool {
+	return goos == "darwin" || goos == "linux" || goos == "windows"
+}
+
+// serviceNames returns the service's label and what its definition file is
+// called on goos, for output.
+func serviceNames(goos string) (label, fileKind string) {
+	switch goos {
+	case "windows":
+		return launchdservice.TaskName, "Script"
+	case "linux":
+		return launchdservice.SystemdUnit, "Unit"
+	}
+	return launchdservice.LaunchdLabel, "Plist"
+}
+
+// serviceManagerName names what restarts the daemon on goos, for output.
+func serviceManagerName(goos string) string {
+	switch goos {
+	case "windows":
+		return "the scheduled task"
+	case "linux":
+		return "systemd"
+	}
+	return "launchd"
+}
+
+var errServiceUnsupported = errors.New("service commands are currently supported only on macOS, Linux (systemd), and Windows")
+
 var serviceCmd = &cobra.Command{
-	Use:   "service",
-	Short: "Manage daemon persistence service (macOS launchd)",
+	Use:     "service",
+	Aliases: []string{"daemon"},
+	Short:   "Manage daemon persistence service (launchd, systemd, Windows Task Scheduler)",
 }
 
 var serviceInstallCmd = &cobra.Command{
 	Use:   "install",
-	Short: "Install and start launchd service",
+	Short: "Install and start the daemon service",
 	RunE:  runServiceInstall,
 }
 
 var serviceUninstallCmd = &cobra.Command{
 	Use:   "uninstall",
-	Short: "Uninstall launchd service",
+	Short: "Uninstall the daemon service",
 	RunE:  runServiceUninstall,
 }
 
 var serviceStatusCmd = &cobra.Command{
 	Use:   "status",
-	Short: "Show launchd service status",
+	Short: "Show daemon service status",
 	RunE:  runServiceStatus,
 }
 
@@ -87,8 +168,8 @@ func runServiceInstallWith(
 	manager serviceManager,
 	out io.Writer,
 ) error {
-	if goos != "darwin" {
-		return fmt.Errorf("service commands are currently supported only on macOS")
+	if !serviceSupported(goos) {
+		return errServiceUnsupported
 	}
 
 	cfg, err := loadCfg()
@@ -110,8 +191,9 @@ func runServiceInstallWith(
 		return err
 	}
 
-	fmt.Fprintf(out, "Service installed: %s\n", launchdservice.LaunchdLabel)
-	fmt.Fprintf(out, "Plist: %s\n", plistPath)
+	label, fileKind := serviceNames(goos)
+	fmt.Fprintf(out, "Service installed: %s\n", label)
+	fmt.Fprintf(out, "%s: %s\n", fileKind, plistPath)
 	return nil
 }
 
@@ -120,8 +202,11 @@ func installCurrentService(cfg *config.Config, cfgPath string) error {
 }
 
 func installServiceForConfig(goos string, manager serviceManager, cfg *config.Config, cfgPath string) error {
-	if goos != "darwin" {
-		return fmt.Errorf("service commands are currently supported only on macOS")
+	if !serviceSupported(goos) {
+		return errServiceUnsupported
+	}
+	if cfg.LogFile == config.LogStderr {
+		return fmt.Errorf("log_file = %q is for containers; set a log file path for the service", config.LogStderr)
 	}
 	absCfgPath, err := filepath.Abs(cfgPath)
 	if err != nil {
@@ -138,13 +223,16 @@ func runServiceUninstall(cmd *cobra.Command, args []string) error {
 }
 
 func runServiceUninstallWith(goos string, manager serviceManager, out io.Writer) error {
-	if goos != "darwin" {
-		return fmt.Errorf("service commands are currently supported only on macOS")
+	if !serviceSupported(goos) {
+		return errServiceUnsupported
 	}
 	if err := manager.Uninstall(); err != nil {
 		return err
 	}
 	fmt.Fprintln(out, "Service uninstalled.")
+	if goos == "windows" {
+		fmt.Fprintln(out, "Run `ap stop` to stop a daemon the task already started.")
+	}
 	return nil
 }
 
@@ -159,8 +247,8 @@ func runServiceStatusWith(
 	out io.Writer,
 	asJSON bool,
 ) error {
-	if goos != "darwin" {
-		return fmt.Errorf("service commands are currently supported only on macOS")
+	if !serviceSupported(goos) {
+		return errServiceUnsupported
 	}
 
 	cfg, err := loadCfg()
@@ -172,8 +260,9 @@ func runServiceStatusWith(
 	if err != nil {
 		return err
 	}
+	label, fileKind := serviceNames(goos)
 	if status.Label == "" {
-		status.Label = launchdservice.LaunchdLabel
+		status.Label = label
 	}
 
 	if asJSON {
@@ -183,7 +272,7 @@ func runServiceStatusWith(
 	}
 
 	fmt.Fprintf(out, "Label: %s\n", status.Label)
-	fmt.Fprintf(out, "Plist: %s\n", status.PlistPath)
+	fmt.Fprintf(out, "%s: %s\n", fileKind, status.PlistPath)
 	fmt.Fprintf(out, "Installed: %t\n", status.Installed)
 	fmt.Fprintf(out, "Loaded: %t\n", status.Loaded)
 	fmt.Fprintf(out, "Running: %t\n", status.Running)
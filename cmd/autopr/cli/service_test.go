@@ -53,7 +53,7 @@ func TestRunServiceInstallUnsupportedOS(t *testing.T) {
 	t.Parallel()
 
 	err := runServiceInstallWith(
-		"linux",
+		"freebsd",
 		func() (*config.Config, error) { return &config.Config{}, nil },
 		func() (string, error) { return "config.toml", nil },
 		&stubServiceManager{},
@@ -108,6 +108,39 @@ func TestRunServiceInstallSuccess(t *testing.T) {
 	}
 }
 
+func TestRunServiceInstallWindowsNamesTask(t *testing.T) {
+	t.Parallel()
+
+	stub := &stubServiceManager{plistPath: `C:\Users\test\AppData\Local\autopr\autopr-daemon.cmd`}
+	var out bytes.Buffer
+	err := runServiceInstallWith(
+		"windows",
+		func() (*config.Config, error) { return &config.Config{}, nil },
+		func() (string, error) { return "config.toml", nil },
+		stub,
+		&out,
+	)
+	if err != nil {
+		t.Fatalf("runServiceInstallWith: %v", err)
+	}
+	got := out.String()
+	if !strings.Contains(got, "Service installed: autopr-daemon") || !strings.Contains(got, "Script: "+stub.plistPath) {
+		t.Fatalf("unexpected output: %q", got)
+	}
+}
+
+func TestDaemonAliasResolvesServiceCommands(t *testing.T) {
+	t.Parallel()
+
+	cmd, _, err := rootCmd.Find([]string{"daemon", "install"})
+	if err != nil {
+		t.Fatalf("find daemon install: %v", err)
+	}
+	if cmd != serviceInstallCmd {
+		t.Fatalf("ap daemon install resolved to %q", cmd.CommandPath())
+	}
+}
+
 func TestRunServiceUninstallAndError(t *testing.T) {
 	t.Parallel()
 
@@ -132,7 +165,7 @@ func TestRunServiceUninstallAndError(t *testing.T) {
 func TestRunServiceUninstallUnsupportedOS(t *testing.T) {
 	t.Parallel()
 
-	err := runServiceUninstallWith("linux", &stubServiceManager{}, &bytes.Buffer{})
+	err := runServiceUninstallWith("freebsd", &stubServiceManager{}, &bytes.Buffer{})
 	if err == nil {
 		t.Fatal("expected unsupported error")
 	}
@@ -182,7 +215,7 @@ func TestRunServiceStatusUnsupportedOS(t *testing.T) {
 	t.Parallel()
 
 	err := runServiceStatusWith(
-		"linux",
+		"freebsd",
 		func() (*config.Config, error) { return &config.Config{}, nil },
 		&stubServiceManager{},
 		&bytes.Buffer{},
@@ -7,7 +7,6 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
-	"syscall"
 	"time"
 
 	"autopr/internal/config"
@@ -82,7 +81,7 @@ func runStartWith(
 func runForeground(cfg *config.Config) error {
 	level := cfg.SlogLevel()
 	opts := &slog.HandlerOptions{Level: level}
-	if cfg.LogFile != "" {
+	if cfg.LogFile != "" && cfg.LogFile != config.LogStderr {
 		if err := os.MkdirAll(filepath.Dir(cfg.LogFile), 0o755); err != nil {
 			return fmt.Errorf("create log dir: %w", err)
 		}
@@ -116,6 +115,9 @@ func runBackground(cfg *config.Config) error {
 
 	// Ensure log directory exists and open the log file for the child.
 	logPath := cfg.LogFile
+	if logPath == config.LogStderr {
+		return fmt.Errorf("log_file = %q needs `ap start --foreground`; a background daemon has no stderr", config.LogStderr)
+	}
 	if err := os.MkdirAll(filepath.Dir(logPath), 0o755); err != nil {
 		return fmt.Errorf("create log dir: %w", err)
 	}
@@ -128,7 +130,7 @@ func runBackground(cfg *config.Config) error {
 	child := exec.Command(exe, childArgs...)
 	child.Stdout = logFile
 	child.Stderr = logFile
-	child.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	child.SysProcAttr = detachedProcAttr()
 	child.Env = childEnvWithSkippedUpdateNotice()
 
 	if err := child.Start(); err != nil {
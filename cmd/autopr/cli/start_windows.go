@@ -0,0 +1,18 @@
+//go:build windows
+
+package cli
+
+import "syscall"
+
+// detachedProcess starts a process without a console (DETACHED_PROCESS).
+const detachedProcess = 0x00000008
+
+// detachedProcAttr starts the background daemon without a console and in its
+// own process group so it outlives the terminal that ran `ap start`, and
+// Ctrl+C there does not reach it.
+func detachedProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{
+		CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP | detachedProcess,
+		HideWindow:    true,
+	}
+}
@@ -0,0 +1,277 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"autopr/internal/db"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	statsProject        string
+	statsSince          time.Duration
+	statsByTag          bool
+	statsExcludeDeleted bool
+)
+
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show what happened to AutoPR PRs after they were opened",
+	Long: `Show review outcomes for AutoPR PRs: how many were merged, how long a human
+took to approve and merge them, and how many merged PRs were later reverted or
+needed follow-up fixes. Outcomes are recorded by the daemon's sync loop.`,
+	Args: cobra.NoArgs,
+	RunE: runStats,
+}
+
+func init() {
+	statsCmd.Flags().StringVar(&statsProject, "project", "", "only count this project")
+	statsCmd.Flags().DurationVar(&statsSince, "since", 0, "only count PRs from this far back (e.g. 720h); 0 for all time")
+	statsCmd.Flags().BoolVar(&statsByTag, "by-tag", false, "also break the stats down by job tag (see ap tag)")
+	statsCmd.Flags().BoolVar(&statsExcludeDeleted, "exclude-deleted", false, "leave out jobs moved to the trash with ap delete")
+	rootCmd.AddCommand(statsCmd)
+}
+
+type statsDuration struct {
+	Count         int     `json:"count"`
+	MedianSeconds float64 `json:"median_seconds"`
+	P90Seconds    float64 `json:"p90_seconds"`
+}
+
+type statsOutput struct {
+	Opened        int           `json:"prs_opened"`
+	Merged        int           `json:"prs_merged"`
+	Closed        int           `json:"prs_closed_unmerged"`
+	Tracked       int           `json:"merged_tracked"`
+	Reverted      int           `json:"reverted"`
+	WithFollowUps int           `json:"with_follow_ups"`
+	FollowUps     int           `json:"follow_ups"`
+	TimeToApprove statsDuration `json:"time_to_approval"`
+	TimeToMerge   statsDuration `json:"time_to_merge"`
+
+	ByTag      map[string]statsOutput      `json:"by_tag,omitempty"`
+	FlakyTests map[string][]statsFlakyTest `json:"flaky_tests,omitempty"`
+}
+
+// statsFlakyTest is a test that failed and then passed when
+// retry_flaky_tests re-ran the test command.
+type statsFlakyTest struct {
+	Test      string `json:"test"`
+	Count     int    `json:"count"`
+	Jobs      int    `json:"jobs"`
+	LastSeen  string `json:"last_seen"`
+	LastJobID string `json:"last_job_id"`
+}
+
+// maxFlakyTestsShown bounds the flaky tests printed per project.
+const maxFlakyTestsShown = 10
+
+func runStats(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	store, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	since := ""
+	if statsSince > 0 {
+		since = time.Now().UTC().Add(-statsSince).Format(time.RFC3339)
+	}
+	counts, err := store.CountPRs(cmd.Context(), statsProject, since, statsExcludeDeleted)
+	if err != nil {
+		return err
+	}
+	outcomes, err := store.ListPROutcomes(cmd.Context(), statsProject, since, statsExcludeDeleted)
+	if err != nil {
+		return err
+	}
+	out := summarizeOutcomes(counts, outcomes)
+	if statsByTag {
+		tagCounts, err := store.CountPRsByTag(cmd.Context(), statsProject, since, statsExcludeDeleted)
+		if err != nil {
+			return err
+		}
+		out.ByTag = summarizeByTag(tagCounts, outcomes)
+	}
+	flaky, err := store.ListFlakyTests(cmd.Context(), statsProject, since, statsExcludeDeleted)
+	if err != nil {
+		return err
+	}
+	out.FlakyTests = groupFlakyTests(flaky)
+
+	if jsonOut {
+		printJSON(out)
+		return nil
+	}
+	fmt.Printf("PRs:        %d opened · %d merged (%s) · %d closed unmerged\n", out.Opened, out.Merged, percent(out.Merged, out.Opened), out.Closed)
+	if out.Tracked == 0 {
+		fmt.Println("Outcomes:   none recorded yet (the daemon records them as PRs merge)")
+	} else {
+		printOutcomeStats(out)
+	}
+	if statsByTag {
+		printTagStats(out.ByTag)
+	}
+	if len(out.FlakyTests) > 0 {
+		printFlakyTests(out.FlakyTests)
+	}
+	return nil
+}
+
+func printOutcomeStats(out statsOutput) {
+	fmt.Printf("Reverted:   %d of %d merged (%s)\n", out.Reverted, out.Tracked, percent(out.Reverted, out.Tracked))
+	fmt.Printf("Follow-ups: %d merged PRs needed %d follow-up fixes (%s)\n", out.WithFollowUps, out.FollowUps, percent(out.WithFollowUps, out.Tracked))
+	fmt.Printf("Approval:   median %s · p90 %s after opening\n", formatStatsDuration(out.TimeToApprove.MedianSeconds), formatStatsDuration(out.TimeToApprove.P90Seconds))
+	fmt.Printf("Merge:      median %s · p90 %s after opening\n", formatStatsDuration(out.TimeToMerge.MedianSeconds), formatStatsDuration(out.TimeToMerge.P90Seconds))
+}
+
+func printTagStats(byTag map[string]statsOutput) {
+	fmt.Println()
+	if len(byTag) == 0 {
+		fmt.Println("By tag:     no tagged jobs opened a PR")
+		return
+	}
+	tags := make([]string, 0, len(byTag))
+	for tag := range byTag {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	fmt.Printf("%-20s %6s %6s %6s %8s %10s %12s\n", "TAG", "OPENED", "MERGED", "CLOSED", "REVERTED", "FOLLOW-UPS", "MERGE MEDIAN")
+	for _, tag := range tags {
+		t := byTag[tag]
+		fmt.Printf("%-20s %6d %6d %6d %8d %10d %12s\n", truncate(tag, 20), t.Opened, t.Merged, t.Closed, t.Reverted, t.FollowUps, formatStatsDuration(t.TimeToMerge.MedianSeconds))
+	}
+}
+
+func printFlakyTests(byProject map[string][]statsFlakyTest) {
+	projects := make([]string, 0, len(byProject))
+	for project := range byProject {
+		projects = append(projects, project)
+	}
+	sort.Strings(projects)
+	for _, project := range projects {
+		tests := byProject[project]
+		fmt.Printf("\nFlaky tests in %s (passed on a re-run):\n", project)
+		fmt.Printf("%-50s %6s %6s  %s\n", "TEST", "FLAKY", "JOBS", "LAST JOB")
+		for i, t := range tests {
+			if i == maxFlakyTestsShown {
+				fmt.Printf("... and %d more\n", len(tests)-maxFlakyTestsShown)
+				break
+			}
+			fmt.Printf("%-50s %6d %6d  %s\n", truncate(t.Test, 50), t.Count, t.Jobs, t.LastJobID)
+		}
+	}
+}
+
+// groupFlakyTests groups flaky test totals by project, keeping the store's
+// most-flaky-first order.
+func groupFlakyTests(flaky []db.FlakyTest) map[string][]statsFlakyTest {
+	if len(flaky) == 0 {
+		return nil
+	}
+	out := map[string][]statsFlakyTest{}
+	for _, f := range flaky {
+		out[f.ProjectName] = append(out[f.ProjectName], statsFlakyTest{
+			Test:      f.TestName,
+			Count:     f.Count,
+			Jobs:      f.Jobs,
+			LastSeen:  f.LastSeen,
+			LastJobID: f.LastJobID,
+		})
+	}
+	return out
+}
+
+// summarizeOutcomes aggregates PR counts and recorded outcomes into stats.
+func summarizeOutcomes(counts db.PRCounts, outcomes []db.PROutcome) statsOutput {
+	out := statsOutput{Opened: counts.Opened, Merged: counts.Merged, Closed: counts.Closed, Tracked: len(outcomes)}
+	var toApprove, toMerge []time.Duration
+	for _, o := range outcomes {
+		if o.Reverted {
+			out.Reverted++
+		}
+		if o.FollowUps > 0 {
+			out.WithFollowUps++
+			out.FollowUps += o.FollowUps
+		}
+		if d, ok := elapsed(o.OpenedAt, o.ApprovedAt); ok {
+			toApprove = append(toApprove, d)
+		}
+		if d, ok := elapsed(o.OpenedAt, o.MergedAt); ok {
+			toMerge = append(toMerge, d)
+		}
+	}
+	out.TimeToApprove = durationStats(toApprove)
+	out.TimeToMerge = durationStats(toMerge)
+	return out
+}
+
+// summarizeByTag summarizes the outcomes of each tag's jobs. A job with
+// several tags counts toward each.
+func summarizeByTag(counts map[string]db.PRCounts, outcomes []db.PROutcome) map[string]statsOutput {
+	tagged := map[string][]db.PROutcome{}
+	for _, o := range outcomes {
+		for _, tag := range o.Tags {
+			tagged[tag] = append(tagged[tag], o)
+		}
+	}
+	out := make(map[string]statsOutput, len(counts))
+	for tag, c := range counts {
+		out[tag] = summarizeOutcomes(c, tagged[tag])
+	}
+	return out
+}
+
+// elapsed returns the time from start to end, both RFC3339, when both parse
+// and end is not before start.
+func elapsed(start, end string) (time.Duration, bool) {
+	s, err := time.Parse(time.RFC3339, start)
+	if err != nil {
+		return 0, false
+	}
+	e, err := time.Parse(time.RFC3339, end)
+	if err != nil || e.Before(s) {
+		return 0, false
+	}
+	return e.Sub(s), true
+}
+
+func durationStats(ds []time.Duration) statsDuration {
+	if len(ds) == 0 {
+		return statsDuration{}
+	}
+	sort.Slice(ds, func(i, j int) bool { return ds[i] < ds[j] })
+	return statsDuration{
+		Count:         len(ds),
+		MedianSeconds: ds[len(ds)/2].Seconds(),
+		P90Seconds:    ds[(len(ds)*9)/10].Seconds(),
+	}
+}
+
+func percent(n, total int) string {
+	if total == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%d%%", n*100/total)
+}
+
+func formatStatsDuration(seconds float64) string {
+	d := time.Duration(seconds) * time.Second
+	switch {
+	case d == 0:
+		return "-"
+	case d >= 48*time.Hour:
+		return fmt.Sprintf("%.1fd", d.Hours()/24)
+	case d >= time.Hour:
+		return fmt.Sprintf("%.1fh", d.Hours())
+	default:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	}
+}
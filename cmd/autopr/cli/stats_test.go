@@ -0,0 +1,76 @@
+package cli
+
+import (
+	"testing"
+
+	"autopr/internal/db"
+)
+
+func TestSummarizeOutcomes(t *testing.T) {
+	counts := db.PRCounts{Opened: 4, Merged: 3, Closed: 1}
+	outcomes := []db.PROutcome{
+		{OpenedAt: "2026-03-01T00:00:00Z", ApprovedAt: "2026-03-01T01:00:00Z", MergedAt: "2026-03-01T02:00:00Z"},
+		{OpenedAt: "2026-03-01T00:00:00Z", ApprovedAt: "2026-03-01T04:00:00Z", MergedAt: "2026-03-01T05:00:00Z", FollowUps: 2},
+		{OpenedAt: "", ApprovedAt: "2026-03-02T00:00:00Z", MergedAt: "2026-03-02T00:00:00Z", Reverted: true, FollowUps: 1},
+	}
+
+	out := summarizeOutcomes(counts, outcomes)
+	if out.Opened != 4 || out.Merged != 3 || out.Closed != 1 || out.Tracked != 3 {
+		t.Fatalf("unexpected counts: %+v", out)
+	}
+	if out.Reverted != 1 || out.WithFollowUps != 2 || out.FollowUps != 3 {
+		t.Fatalf("unexpected revert/follow-up counts: %+v", out)
+	}
+	// The outcome without an opened time is left out of the durations.
+	if out.TimeToApprove.Count != 2 || out.TimeToApprove.MedianSeconds != 4*3600 {
+		t.Fatalf("unexpected time to approval: %+v", out.TimeToApprove)
+	}
+	if out.TimeToMerge.Count != 2 || out.TimeToMerge.P90Seconds != 5*3600 {
+		t.Fatalf("unexpected time to merge: %+v", out.TimeToMerge)
+	}
+	if got := formatStatsDuration(out.TimeToApprove.MedianSeconds); got != "4.0h" {
+		t.Fatalf("unexpected formatted duration %q", got)
+	}
+}
+
+func TestSummarizeByTag(t *testing.T) {
+	counts := map[string]db.PRCounts{
+		"flaky-tests": {Opened: 2, Merged: 2},
+		"docs":        {Opened: 1},
+	}
+	outcomes := []db.PROutcome{
+		{MergedAt: "2026-03-01T02:00:00Z", Tags: []string{"flaky-tests"}, Reverted: true},
+		{MergedAt: "2026-03-02T02:00:00Z", Tags: []string{"flaky-tests", "urgent"}, FollowUps: 1},
+		{MergedAt: "2026-03-03T02:00:00Z"},
+	}
+
+	byTag := summarizeByTag(counts, outcomes)
+	if len(byTag) != 2 {
+		t.Fatalf("expected stats for the two counted tags, got %+v", byTag)
+	}
+	flaky := byTag["flaky-tests"]
+	if flaky.Opened != 2 || flaky.Merged != 2 || flaky.Tracked != 2 || flaky.Reverted != 1 || flaky.FollowUps != 1 {
+		t.Fatalf("unexpected flaky-tests stats: %+v", flaky)
+	}
+	if docs := byTag["docs"]; docs.Opened != 1 || docs.Tracked != 0 {
+		t.Fatalf("unexpected docs stats: %+v", docs)
+	}
+}
+
+func TestGroupFlakyTests(t *testing.T) {
+	if got := groupFlakyTests(nil); got != nil {
+		t.Fatalf("expected nil for no flaky tests, got %+v", got)
+	}
+	flaky := []db.FlakyTest{
+		{ProjectName: "api", TestName: "TestRetry", Count: 3, Jobs: 2, LastJobID: "ap-job-2"},
+		{ProjectName: "api", TestName: "TestTimeout", Count: 1, Jobs: 1, LastJobID: "ap-job-1"},
+		{ProjectName: "web", TestName: "renders the header", Count: 2, Jobs: 2, LastJobID: "ap-job-3"},
+	}
+	byProject := groupFlakyTests(flaky)
+	if len(byProject) != 2 || len(byProject["api"]) != 2 || len(byProject["web"]) != 1 {
+		t.Fatalf("unexpected grouping: %+v", byProject)
+	}
+	if first := byProject["api"][0]; first.Test != "TestRetry" || first.Count != 3 || first.Jobs != 2 || first.LastJobID != "ap-job-2" {
+		t.Fatalf("unexpected first api flaky test: %+v", first)
+	}
+}
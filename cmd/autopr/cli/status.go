@@ -6,30 +6,33 @@ import (
 	"os"
 	"strconv"
 	"strings"
-	"syscall"
 	"time"
 
+	"autopr/internal/daemon"
 	"autopr/internal/db"
 
 	"github.com/spf13/cobra"
 )
 
 type statusJobCounts struct {
-	Queued       int `json:"queued"`
-	Planning     int `json:"planning"`
-	Implementing int `json:"implementing"`
-	Reviewing    int `json:"reviewing"`
-	Testing      int `json:"testing"`
-	NeedsPR      int `json:"needs_pr"`
-	Failed       int `json:"failed"`
-	Cancelled    int `json:"cancelled"`
-	Rejected     int `json:"rejected"`
-	PRCreated    int `json:"pr_created"`
-	Merged       int `json:"merged"`
+	Queued         int `json:"queued"`
+	Planning       int `json:"planning"`
+	Implementing   int `json:"implementing"`
+	Reviewing      int `json:"reviewing"`
+	Testing        int `json:"testing"`
+	NeedsPR        int `json:"needs_pr"`
+	Oversize       int `json:"needs_review_oversize"`
+	Failed         int `json:"failed"`
+	Cancelled      int `json:"cancelled"`
+	Rejected       int `json:"rejected"`
+	WaitingNetwork int `json:"waiting_network"`
+	PRCreated      int `json:"pr_created"`
+	Merged         int `json:"merged"`
 }
 
 type statusOutput struct {
 	Running   bool            `json:"running"`
+	Idle      bool            `json:"idle,omitempty"`
 	PID       string          `json:"pid"`
 	JobCounts statusJobCounts `json:"job_counts"`
 }
@@ -114,6 +117,7 @@ func runStatus(cmd *cobra.Command, args []string) error {
 
 type statusSnapshot struct {
 	Running bool
+	Idle    bool // workers stopped under [daemon] idle_after
 	PID     string
 	Counts  statusJobCounts
 	Queued  int
@@ -129,11 +133,7 @@ func collectStatusSnapshot(ctx context.Context, store *db.Store, pidFile string)
 		pidStr = strings.TrimSpace(string(pidBytes))
 		pid, err := strconv.Atoi(pidStr)
 		if err == nil {
-			if proc, err := os.FindProcess(pid); err == nil {
-				if err := proc.Signal(syscall.Signal(0)); err == nil {
-					running = true
-				}
-			}
+			running = daemon.ProcessAlive(pid)
 		}
 	}
 
@@ -179,19 +179,22 @@ func collectStatusSnapshot(ctx context.Context, store *db.Store, pidFile string)
 	active := counts["planning"] + counts["implementing"] + counts["reviewing"] + counts["testing"] + counts["rebasing"] + counts["resolving_conflicts"]
 	return statusSnapshot{
 		Running: running,
+		Idle:    running && daemon.IsIdle(pidFile),
 		PID:     pidStr,
 		Counts: statusJobCounts{
-			Queued:       counts["queued"],
-			Planning:     counts["planning"],
-			Implementing: counts["implementing"],
-			Reviewing:    counts["reviewing"],
-			Testing:      counts["testing"],
-			NeedsPR:      counts["ready"],
-			Failed:       counts["failed"],
-			Cancelled:    counts["cancelled"],
-			Rejected:     counts["rejected"],
-			PRCreated:    prCreated,
-			Merged:       merged,
+			Queued:         counts["queued"],
+			Planning:       counts["planning"],
+			Implementing:   counts["implementing"],
+			Reviewing:      counts["reviewing"],
+			Testing:        counts["testing"],
+			NeedsPR:        counts["ready"],
+			Oversize:       counts["needs_review_oversize"],
+			Failed:         counts["failed"],
+			Cancelled:      counts["cancelled"],
+			Rejected:       counts["rejected"],
+			WaitingNetwork: counts["waiting_network"],
+			PRCreated:      prCreated,
+			Merged:         merged,
 		},
 		Queued: counts["queued"],
 		Active: active,
@@ -202,6 +205,7 @@ func renderStatusSnapshot(asJSON bool, asShort bool, compactJSON bool, snapshot
 	if asJSON {
 		output := statusOutput{
 			Running:   snapshot.Running,
+			Idle:      snapshot.Idle,
 			PID:       snapshot.PID,
 			JobCounts: snapshot.Counts,
 		}
@@ -216,7 +220,11 @@ func renderStatusSnapshot(asJSON bool, asShort bool, compactJSON bool, snapshot
 		return writef("%s\n", renderShortStatusSummary(snapshot.Running, snapshot.Queued, snapshot.Active))
 	}
 
-	if snapshot.Running {
+	if snapshot.Idle {
+		if err := writef("Daemon: idle (PID %s), workers stopped until a job is queued\n", snapshot.PID); err != nil {
+			return err
+		}
+	} else if snapshot.Running {
 		if err := writef("Daemon: running (PID %s)\n", snapshot.PID); err != nil {
 			return err
 		}
@@ -262,6 +270,18 @@ func renderStatusSnapshot(asJSON bool, asShort bool, compactJSON bool, snapshot
 				{label: "cancelled", count: snapshot.Counts.Cancelled},
 			},
 		},
+		{
+			title: "Review",
+			values: []statusSectionEntry{
+				{label: "oversize", count: snapshot.Counts.Oversize},
+			},
+		},
+		{
+			title: "Network",
+			values: []statusSectionEntry{
+				{label: "waiting_network", count: snapshot.Counts.WaitingNetwork},
+			},
+		},
 	}
 
 	sectionLines := make([]string, 0, len(sections))
@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"os"
 	"runtime"
-	"syscall"
 	"time"
 
 	"autopr/internal/config"
@@ -16,7 +15,9 @@ import (
 
 var (
 	stopPlatform      = runtime.GOOS
-	stopServiceStatus = launchdservice.Status
+	stopServiceStatus = func(cfg *config.Config) (launchdservice.ServiceStatus, error) {
+		return serviceMgr.Status(cfg)
+	}
 )
 
 var stopCmd = &cobra.Command{
@@ -40,35 +41,47 @@ func runStop(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("daemon not running (no PID file)")
 	}
 
+	forced, err := stopDaemonPID(cfg, pid)
+	if err != nil {
+		return err
+	}
+	if forced {
+		fmt.Println("Daemon stopped (forced).")
+	} else {
+		fmt.Println("Daemon stopped.")
+	}
+	printStopServiceKeepAliveNote(cfg)
+	return nil
+}
+
+// stopDaemonPID asks the daemon to shut down (SIGTERM, or a stop file on
+// Windows) and waits up to 10s for it to exit before killing it. It reports
+// whether the kill was needed.
+func stopDaemonPID(cfg *config.Config, pid int) (bool, error) {
 	proc, err := os.FindProcess(pid)
 	if err != nil {
-		return fmt.Errorf("find process %d: %w", pid, err)
+		return false, fmt.Errorf("find process %d: %w", pid, err)
 	}
 
-	// Send SIGTERM for graceful shutdown.
-	if err := proc.Signal(syscall.SIGTERM); err != nil {
+	if err := daemon.RequestStop(cfg.Daemon.PIDFile, pid); err != nil {
 		// Process might already be dead.
 		daemon.RemovePID(cfg.Daemon.PIDFile)
-		return fmt.Errorf("signal process %d: %w", pid, err)
+		return false, fmt.Errorf("signal process %d: %w", pid, err)
 	}
 
 	// Wait for process to exit.
 	deadline := time.Now().Add(10 * time.Second)
 	for time.Now().Before(deadline) {
 		if !daemon.ProcessAlive(pid) {
-			fmt.Println("Daemon stopped.")
-			printStopServiceKeepAliveNote(cfg)
-			return nil
+			return false, nil
 		}
 		time.Sleep(200 * time.Millisecond)
 	}
 
 	// Force kill.
-	_ = proc.Signal(syscall.SIGKILL)
+	_ = proc.Kill()
 	daemon.RemovePID(cfg.Daemon.PIDFile)
-	fmt.Println("Daemon stopped (forced).")
-	printStopServiceKeepAliveNote(cfg)
-	return nil
+	return true, nil
 }
 
 func resolveStopPID(cfg *config.Config) (int, error) {
@@ -77,7 +90,7 @@ func resolveStopPID(cfg *config.Config) (int, error) {
 		return pid, nil
 	}
 
-	if stopPlatform != "darwin" {
+	if !serviceSupported(stopPlatform) {
 		return 0, err
 	}
 	status, statusErr := stopServiceStatus(cfg)
@@ -91,14 +104,22 @@ func resolveStopPID(cfg *config.Config) (int, error) {
 }
 
 func printStopServiceKeepAliveNote(cfg *config.Config) {
-	if stopPlatform != "darwin" {
+	if !serviceSupported(stopPlatform) {
 		return
 	}
 	status, err := stopServiceStatus(cfg)
 	if err != nil {
 		return
 	}
-	if status.Installed {
+	if !status.Installed {
+		return
+	}
+	switch stopPlatform {
+	case "windows":
+		fmt.Println("Note: the autopr-daemon scheduled task restarts the daemon. Run `ap service uninstall` to disable auto-restart.")
+	case "linux":
+		fmt.Println("Note: systemd Restart=always restarts the daemon. Run `systemctl --user stop autopr` to stop it, or `ap service uninstall` to disable auto-restart.")
+	default:
 		fmt.Println("Note: launchd KeepAlive may restart the daemon. Run `ap service uninstall` to disable auto-restart.")
 	}
 }
@@ -34,7 +34,7 @@ func TestResolveStopPIDFallsBackToServiceOnDarwin(t *testing.T) {
 	}
 }
 
-func TestResolveStopPIDNonDarwinReturnsPIDError(t *testing.T) {
+func TestResolveStopPIDUnsupportedOSReturnsPIDError(t *testing.T) {
 	prevPlatform := stopPlatform
 	prevStatus := stopServiceStatus
 	t.Cleanup(func() {
@@ -42,7 +42,7 @@ func TestResolveStopPIDNonDarwinReturnsPIDError(t *testing.T) {
 		stopServiceStatus = prevStatus
 	})
 
-	stopPlatform = "linux"
+	stopPlatform = "freebsd"
 	stopServiceStatus = func(*config.Config) (launchdservice.ServiceStatus, error) {
 		t.Fatal("service status should not be called")
 		return launchdservice.ServiceStatus{}, nil
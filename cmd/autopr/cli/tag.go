@@ -0,0 +1,76 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"autopr/internal/db"
+
+	"github.com/spf13/cobra"
+)
+
+var tagRemove bool
+
+var tagCmd = &cobra.Command{
+	Use:   "tag <job-id> [tag...]",
+	Short: "Add, remove, or show a job's tags",
+	Long: `Tag a job for filtering (ap list --tag, the TUI filter) and reporting
+(ap stats --by-tag). Tags are lowercase letters, digits, and - _ . / :.
+With no tags, print the job's current tags.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runTag,
+}
+
+func init() {
+	tagCmd.Flags().BoolVar(&tagRemove, "remove", false, "remove the given tags instead of adding them")
+	rootCmd.AddCommand(tagCmd)
+}
+
+func runTag(cmd *cobra.Command, args []string) error {
+	if tagRemove && len(args) < 2 {
+		return fmt.Errorf("--remove needs at least one tag")
+	}
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	store, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	jobID, err := resolveJob(store, args[0])
+	if err != nil {
+		return err
+	}
+	if tags := args[1:]; len(tags) > 0 {
+		if tagRemove {
+			_, err = store.RemoveJobTags(cmd.Context(), jobID, tags)
+		} else {
+			err = store.AddJobTags(cmd.Context(), jobID, tags)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	job, err := store.GetJob(cmd.Context(), jobID)
+	if err != nil {
+		return err
+	}
+	if jsonOut {
+		tags := job.Tags
+		if tags == nil {
+			tags = []string{}
+		}
+		printJSON(map[string]any{"job_id": jobID, "tags": tags})
+		return nil
+	}
+	if len(job.Tags) == 0 {
+		fmt.Printf("Job %s has no tags.\n", db.ShortID(jobID))
+		return nil
+	}
+	fmt.Printf("Job %s tags: %s\n", db.ShortID(jobID), strings.Join(job.Tags, ", "))
+	return nil
+}
@@ -0,0 +1,199 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"autopr/internal/bundle"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	templateExportOutput      string
+	templateExportDescription string
+	templateImportProject     string
+	templateImportGates       bool
+)
+
+var templateCmd = &cobra.Command{
+	Use:   "template",
+	Short: "List job templates, or share them with prompt overrides and gates as a bundle",
+	Long: `List the [[templates]] of the config, queued with ap run --template.
+
+ap template export writes a project's job templates, prompt overrides, and
+gates (test_cmd, test_shards, lint_cmd) to a bundle file. ap template import
+adds a bundle to another project, so a team can share a curated setup across
+repositories. Its shell commands are imported only with --gates, after you
+have reviewed them.`,
+	Args: cobra.NoArgs,
+	RunE: runTemplateList,
+}
+
+var templateListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List job templates and the variables they take",
+	Args:  cobra.NoArgs,
+	RunE:  runTemplateList,
+}
+
+var templateExportCmd = &cobra.Command{
+	Use:   "export <project> [-o bundle.toml]",
+	Short: "Write a project's job templates, prompt overrides, and gates to a bundle",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runTemplateExport,
+}
+
+var templateImportCmd = &cobra.Command{
+	Use:   "import <bundle.toml> --project <name>",
+	Short: "Add a bundle's job templates, prompt overrides, and gates to a project",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runTemplateImport,
+}
+
+func init() {
+	templateExportCmd.Flags().StringVarP(&templateExportOutput, "output", "o", "", "write the bundle to this file instead of stdout")
+	templateExportCmd.Flags().StringVar(&templateExportDescription, "description", "", "description stored in the bundle")
+	templateImportCmd.Flags().StringVar(&templateImportProject, "project", "", "project to import into (required)")
+	templateImportCmd.Flags().BoolVar(&templateImportGates, "gates", false, "import the bundle's shell commands (gates and template test_cmd/lint_cmd) after reviewing them")
+	templateCmd.AddCommand(templateListCmd)
+	templateCmd.AddCommand(templateExportCmd)
+	templateCmd.AddCommand(templateImportCmd)
+	rootCmd.AddCommand(templateCmd)
+}
+
+type templateOutput struct {
+	Name    string   `json:"name"`
+	Project string   `json:"project"`
+	Vars    []string `json:"vars"`
+	Title   string   `json:"title"`
+}
+
+func runTemplateList(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	out := make([]templateOutput, 0, len(cfg.Templates))
+	for _, t := range cfg.Templates {
+		out = append(out, templateOutput{Name: t.Name, Project: t.Project, Vars: t.Vars(), Title: t.Title})
+	}
+	if jsonOut {
+		printJSON(out)
+		return nil
+	}
+	if len(out) == 0 {
+		fmt.Println("No job templates. Add [[templates]] to the config or import a bundle with `ap template import`.")
+		return nil
+	}
+	fmt.Printf("%-20s %-20s %s\n", "TEMPLATE", "PROJECT", "VARS")
+	fmt.Println(strings.Repeat("-", 60))
+	for _, t := range out {
+		vars := strings.Join(t.Vars, ", ")
+		if vars == "" {
+			vars = "-"
+		}
+		fmt.Printf("%-20s %-20s %s\n", t.Name, t.Project, vars)
+	}
+	return nil
+}
+
+func runTemplateExport(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	proj, ok := cfg.ProjectByName(args[0])
+	if !ok {
+		return fmt.Errorf("project %q not found in config", args[0])
+	}
+	b, err := bundle.Export(cfg, proj)
+	if err != nil {
+		return err
+	}
+	b.Description = templateExportDescription
+
+	if templateExportOutput == "" {
+		return bundle.Encode(os.Stdout, b)
+	}
+	f, err := os.Create(templateExportOutput)
+	if err != nil {
+		return fmt.Errorf("create bundle: %w", err)
+	}
+	if err := bundle.Encode(f, b); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("write bundle: %w", err)
+	}
+	if !jsonOut {
+		fmt.Printf("Exported %d template(s), %d prompt override(s), and %s of %s to %s.\n",
+			len(b.Templates), len(b.Prompts), gatesNoun(b.Gates != nil), proj.Name, templateExportOutput)
+	}
+	return nil
+}
+
+func runTemplateImport(cmd *cobra.Command, args []string) error {
+	if templateImportProject == "" {
+		return fmt.Errorf("--project is required")
+	}
+	path, err := resolveConfigPath()
+	if err != nil {
+		return err
+	}
+
+	var r io.Reader = os.Stdin
+	if args[0] != "-" {
+		f, err := os.Open(args[0])
+		if err != nil {
+			return fmt.Errorf("open bundle: %w", err)
+		}
+		defer f.Close()
+		r = f
+	}
+	b, err := bundle.Decode(r)
+	if err != nil {
+		return err
+	}
+	if b.Empty() {
+		return fmt.Errorf("bundle %s has nothing to import", args[0])
+	}
+
+	res, err := bundle.Import(path, b, templateImportProject, templateImportGates)
+	var cmdErr *bundle.CommandsError
+	if errors.As(err, &cmdErr) {
+		return fmt.Errorf("%w\nreview them and re-run with --gates to import them", err)
+	}
+	if err != nil {
+		return err
+	}
+	if jsonOut {
+		printJSON(map[string]any{"project": templateImportProject, "config": path, "templates": res.Templates, "prompt_files": res.PromptFiles, "gates": res.Gates, "commands": res.Commands})
+		return nil
+	}
+	fmt.Printf("Imported into %s in %s:\n", templateImportProject, path)
+	if len(res.Templates) > 0 {
+		fmt.Printf("  templates: %s\n", strings.Join(res.Templates, ", "))
+	}
+	if len(res.PromptFiles) > 0 {
+		fmt.Printf("  prompt overrides: %s\n", strings.Join(res.PromptFiles, ", "))
+	}
+	if res.Gates {
+		fmt.Println("  gates: set from the bundle")
+	}
+	if len(res.Commands) > 0 {
+		fmt.Printf("  commands jobs will run:\n    %s\n", strings.Join(res.Commands, "\n    "))
+	}
+	return nil
+}
+
+func gatesNoun(has bool) string {
+	if has {
+		return "the gates"
+	}
+	return "no gates"
+}
@@ -0,0 +1,140 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"autopr/internal/db"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	trashProject   string
+	trashOlderThan time.Duration
+)
+
+var trashCmd = &cobra.Command{
+	Use:   "trash",
+	Short: "List jobs moved to the trash with ap delete",
+	Args:  cobra.NoArgs,
+	RunE:  runTrashList,
+}
+
+var trashRestoreCmd = &cobra.Command{
+	Use:   "restore <job-id>...",
+	Short: "Take jobs out of the trash",
+	Args:  cobra.MinimumNArgs(1),
+	RunE:  runTrashRestore,
+}
+
+var trashEmptyCmd = &cobra.Command{
+	Use:   "empty [--older-than <duration>]",
+	Short: "Delete trashed jobs for good, with their sessions, artifacts, and notes",
+	Args:  cobra.NoArgs,
+	RunE:  runTrashEmpty,
+}
+
+func init() {
+	trashCmd.Flags().StringVar(&trashProject, "project", "", "only this project's jobs")
+	trashEmptyCmd.Flags().DurationVar(&trashOlderThan, "older-than", 0, "only jobs trashed longer ago than this (e.g. 168h)")
+	trashCmd.AddCommand(trashRestoreCmd)
+	trashCmd.AddCommand(trashEmptyCmd)
+	rootCmd.AddCommand(trashCmd)
+}
+
+func runTrashList(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	store, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	jobs, err := store.ListJobs(cmd.Context(), trashProject, "deleted", "updated_at", false)
+	if err != nil {
+		return err
+	}
+	if jsonOut {
+		if jobs == nil {
+			jobs = []db.Job{}
+		}
+		printJSON(jobs)
+		return nil
+	}
+	if len(jobs) == 0 {
+		fmt.Println("The trash is empty.")
+		return nil
+	}
+	fmt.Printf("%-10s %-16s %-10s %-20s %s\n", "JOB", "PROJECT", "STATE", "DELETED", "TITLE")
+	for _, j := range jobs {
+		fmt.Printf("%-10s %-16s %-10s %-20s %s\n", db.ShortID(j.ID), truncate(j.ProjectName, 16), j.State, j.DeletedAt, truncate(j.Title(), 60))
+	}
+	if after, _ := time.ParseDuration(cfg.Retention.DeletedJobsAfter); after > 0 {
+		fmt.Printf("\nJobs are deleted for good %s after they were trashed.\n", after)
+	}
+	return nil
+}
+
+func runTrashRestore(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	store, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	ids := make([]string, 0, len(args))
+	for _, arg := range args {
+		jobID, err := resolveJob(store, arg)
+		if err != nil {
+			return err
+		}
+		if err := store.RestoreJob(cmd.Context(), jobID); err != nil {
+			return err
+		}
+		ids = append(ids, jobID)
+		if !jsonOut {
+			fmt.Printf("Restored job %s.\n", db.ShortID(jobID))
+		}
+	}
+	if jsonOut {
+		printJSON(map[string]any{"restored": ids})
+	}
+	return nil
+}
+
+func runTrashEmpty(cmd *cobra.Command, args []string) error {
+	if trashOlderThan < 0 {
+		return fmt.Errorf("--older-than must not be negative")
+	}
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	store, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	ids, err := store.EmptyTrashBefore(cmd.Context(), time.Now().Add(-trashOlderThan))
+	if err != nil {
+		return err
+	}
+	if jsonOut {
+		if ids == nil {
+			ids = []string{}
+		}
+		printJSON(map[string]any{"deleted": ids, "count": len(ids)})
+		return nil
+	}
+	fmt.Printf("Deleted %d job(s) from the trash for good.\n", len(ids))
+	return nil
+}
@@ -2,11 +2,13 @@ package cli
 
 import (
 	"fmt"
+	"os"
 
 	"autopr/internal/tui"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 )
 
 var tuiCmd = &cobra.Command{
@@ -20,6 +22,13 @@ func init() {
 }
 
 func runTUI(cmd *cobra.Command, args []string) error {
+	if !term.IsTerminal(int(os.Stdin.Fd())) || !term.IsTerminal(int(os.Stdout.Fd())) {
+		return fmt.Errorf("ap tui needs an interactive terminal (with docker, use `docker exec -it`); try `ap status`, `ap list`, or `ap health`")
+	}
+	path, err := resolveConfigPath()
+	if err != nil {
+		return err
+	}
 	cfg, err := loadConfig()
 	if err != nil {
 		return err
@@ -30,7 +39,7 @@ func runTUI(cmd *cobra.Command, args []string) error {
 	}
 	defer store.Close()
 
-	model := tui.NewModel(store, cfg)
+	model := tui.NewModel(store, cfg).WithConfigPath(path)
 	p := tea.NewProgram(model, tea.WithAltScreen())
 	if _, err := p.Run(); err != nil {
 		return fmt.Errorf("tui error: %w", err)
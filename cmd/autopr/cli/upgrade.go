@@ -8,17 +8,23 @@ import (
 	"os"
 
 	"autopr/internal/config"
+	"autopr/internal/daemon"
 	"autopr/internal/update"
 
 	"github.com/spf13/cobra"
 )
 
-var upgradeCheckOnly bool
+var (
+	upgradeCheckOnly bool
+	upgradeChannel   string
+	upgradeRestart   bool
+)
 
 var upgradeCmd = &cobra.Command{
-	Use:   "upgrade",
-	Short: "Upgrade ap to the latest release",
-	RunE:  runUpgrade,
+	Use:     "upgrade",
+	Aliases: []string{"self-update"},
+	Short:   "Upgrade ap to the latest release",
+	RunE:    runUpgrade,
 }
 
 type upgradeService interface {
@@ -28,11 +34,31 @@ type upgradeService interface {
 
 func init() {
 	upgradeCmd.Flags().BoolVar(&upgradeCheckOnly, "check", false, "Check for updates without installing")
+	upgradeCmd.Flags().StringVar(&upgradeChannel, "channel", "", "Release channel: stable or beta (default: update.channel from config)")
+	upgradeCmd.Flags().BoolVar(&upgradeRestart, "restart", false, "Restart the daemon after upgrading")
 	rootCmd.AddCommand(upgradeCmd)
 }
 
 func runUpgrade(cmd *cobra.Command, args []string) error {
-	err := runUpgradeWith(cmd.Context(), os.Stdout, update.NewManager(version), version, upgradeCheckOnly)
+	// The config is optional here: without one, upgrade the stable channel.
+	cfg, cfgErr := loadConfig()
+	channel := upgradeChannel
+	if channel == "" && cfgErr == nil {
+		channel = cfg.Update.Channel
+	}
+	if channel == "" {
+		channel = update.ChannelStable
+	}
+	if channel != update.ChannelStable && channel != update.ChannelBeta {
+		return fmt.Errorf("invalid --channel %q (want stable or beta)", channel)
+	}
+	if upgradeRestart && cfgErr != nil {
+		return fmt.Errorf("--restart needs a valid config: %w", cfgErr)
+	}
+
+	mgr := update.NewManager(version)
+	mgr.Channel = channel
+	upgraded, err := runUpgradeWith(cmd.Context(), os.Stdout, mgr, version, upgradeCheckOnly)
 	if err != nil {
 		return err
 	}
@@ -45,36 +71,66 @@ func runUpgrade(cmd *cobra.Command, args []string) error {
 			}
 		}
 	}
+
+	if upgraded && upgradeRestart {
+		return restartDaemon(cfg, os.Stdout)
+	}
 	return nil
 }
 
-func runUpgradeWith(ctx context.Context, out io.Writer, svc upgradeService, currentVersion string, checkOnly bool) error {
+// runUpgradeWith checks for or installs the latest release and reports
+// whether a new binary was installed.
+func runUpgradeWith(ctx context.Context, out io.Writer, svc upgradeService, currentVersion string, checkOnly bool) (bool, error) {
 	if checkOnly {
 		res, err := svc.Check(ctx, currentVersion)
 		if err != nil {
-			return err
+			return false, err
 		}
 		if res.UpdateAvailable {
 			fmt.Fprintf(out, "update available: %s (current: %s)\n", res.LatestVersion, res.CurrentVersion)
-			return nil
+			return false, nil
 		}
 		fmt.Fprintf(out, "already up to date (%s)\n", nonEmptyVersion(res.CurrentVersion, currentVersion))
-		return nil
+		return false, nil
 	}
 
 	res, err := svc.Upgrade(ctx, currentVersion)
 	if err != nil {
-		return err
+		return false, err
 	}
 	if !res.UpdateAvailable {
 		fmt.Fprintf(out, "already up to date (%s)\n", nonEmptyVersion(res.CurrentVersion, currentVersion))
-		return nil
+		return false, nil
 	}
 	if res.Upgraded {
 		fmt.Fprintf(out, "upgraded ap to %s\n", res.LatestVersion)
-		return nil
+		return true, nil
 	}
 	fmt.Fprintf(out, "already up to date (%s)\n", nonEmptyVersion(res.CurrentVersion, currentVersion))
+	return false, nil
+}
+
+// restartDaemon restarts a running daemon so it runs the new binary. Under a
+// service (launchd, systemd, or a Windows scheduled task) the daemon is only
+// stopped; the service starts the new one.
+func restartDaemon(cfg *config.Config, out io.Writer) error {
+	pid, err := resolveStopPID(cfg)
+	if err != nil || !daemon.ProcessAlive(pid) {
+		fmt.Fprintln(out, "daemon not running; start it with `ap start`")
+		return nil
+	}
+	if _, err := stopDaemonPID(cfg, pid); err != nil {
+		return fmt.Errorf("restart daemon: %w", err)
+	}
+	if serviceSupported(stopPlatform) {
+		if status, err := stopServiceStatus(cfg); err == nil && status.Installed {
+			fmt.Fprintf(out, "daemon stopped; %s will start the new version\n", serviceManagerName(stopPlatform))
+			return nil
+		}
+	}
+	if err := runBackground(cfg); err != nil {
+		return fmt.Errorf("restart daemon: %w", err)
+	}
 	return nil
 }
 
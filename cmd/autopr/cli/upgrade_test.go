@@ -4,9 +4,11 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"path/filepath"
 	"strings"
 	"testing"
 
+	"autopr/internal/config"
 	"autopr/internal/update"
 )
 
@@ -33,7 +35,7 @@ func TestRunUpgradeCheckShowsAvailableUpdate(t *testing.T) {
 	t.Parallel()
 
 	var out bytes.Buffer
-	err := runUpgradeWith(context.Background(), &out, mockUpgradeService{
+	_, err := runUpgradeWith(context.Background(), &out, mockUpgradeService{
 		checkFn: func(context.Context, string) (update.CheckResult, error) {
 			return update.CheckResult{CurrentVersion: "v0.2.0", LatestVersion: "v0.3.0", UpdateAvailable: true}, nil
 		},
@@ -51,7 +53,7 @@ func TestRunUpgradeCheckShowsUpToDate(t *testing.T) {
 	t.Parallel()
 
 	var out bytes.Buffer
-	err := runUpgradeWith(context.Background(), &out, mockUpgradeService{
+	_, err := runUpgradeWith(context.Background(), &out, mockUpgradeService{
 		checkFn: func(context.Context, string) (update.CheckResult, error) {
 			return update.CheckResult{CurrentVersion: "v0.2.0", LatestVersion: "v0.2.0", UpdateAvailable: false}, nil
 		},
@@ -70,7 +72,7 @@ func TestRunUpgradeInstallsOnlyWhenNewer(t *testing.T) {
 
 	var out bytes.Buffer
 	calls := 0
-	err := runUpgradeWith(context.Background(), &out, mockUpgradeService{
+	upgraded, err := runUpgradeWith(context.Background(), &out, mockUpgradeService{
 		upgradeFn: func(context.Context, string) (update.UpgradeResult, error) {
 			calls++
 			return update.UpgradeResult{CheckResult: update.CheckResult{CurrentVersion: "v0.2.0", LatestVersion: "v0.3.0", UpdateAvailable: true}, Upgraded: true}, nil
@@ -82,6 +84,9 @@ func TestRunUpgradeInstallsOnlyWhenNewer(t *testing.T) {
 	if calls != 1 {
 		t.Fatalf("expected one upgrade call, got %d", calls)
 	}
+	if !upgraded {
+		t.Fatal("expected upgrade to be reported")
+	}
 	got := out.String()
 	if !strings.Contains(got, "upgraded ap to v0.3.0") {
 		t.Fatalf("unexpected output: %q", got)
@@ -92,7 +97,7 @@ func TestRunUpgradeSurfacesInstallErrors(t *testing.T) {
 	t.Parallel()
 
 	expectedErr := errors.New("install failed")
-	err := runUpgradeWith(context.Background(), &bytes.Buffer{}, mockUpgradeService{
+	_, err := runUpgradeWith(context.Background(), &bytes.Buffer{}, mockUpgradeService{
 		upgradeFn: func(context.Context, string) (update.UpgradeResult, error) {
 			return update.UpgradeResult{}, expectedErr
 		},
@@ -104,3 +109,20 @@ func TestRunUpgradeSurfacesInstallErrors(t *testing.T) {
 		t.Fatalf("unexpected error: %v", err)
 	}
 }
+
+func TestRestartDaemonSkipsWhenNotRunning(t *testing.T) {
+	prevPlatform := stopPlatform
+	t.Cleanup(func() { stopPlatform = prevPlatform })
+	stopPlatform = "linux"
+
+	cfg := &config.Config{}
+	cfg.Daemon.PIDFile = filepath.Join(t.TempDir(), "autopr.pid")
+
+	var out bytes.Buffer
+	if err := restartDaemon(cfg, &out); err != nil {
+		t.Fatalf("restartDaemon: %v", err)
+	}
+	if !strings.Contains(out.String(), "daemon not running") {
+		t.Fatalf("unexpected output: %q", out.String())
+	}
+}
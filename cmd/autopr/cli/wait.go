@@ -0,0 +1,190 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"autopr/internal/db"
+
+	"github.com/spf13/cobra"
+)
+
+// Exit codes of ap wait.
+const (
+	waitExitReached     = 0
+	waitExitFailed      = 1
+	waitExitTimeout     = 2
+	waitExitInterrupted = 130
+)
+
+var (
+	waitFor      string
+	waitTimeout  time.Duration
+	waitInterval time.Duration
+)
+
+var waitCmd = &cobra.Command{
+	Use:   "wait <job-id>",
+	Short: "Block until a job is ready or merged",
+	Long: `Block until a job reaches the requested state, for chaining on AutoPR outcomes
+in CI and shell scripts.
+
+  --for ready   the job has a change ready for review (ready or approved)
+  --for merged  the job's PR has been merged
+
+Exit codes:
+  0  the job reached the requested state
+  1  the job failed, was rejected or cancelled, or its PR was closed unmerged
+     (also used when ap wait itself fails)
+  2  --timeout elapsed first
+  130 interrupted`,
+	Args: cobra.ExactArgs(1),
+	RunE: runWait,
+}
+
+func init() {
+	waitCmd.Flags().StringVar(&waitFor, "for", "ready", "state to wait for: ready or merged")
+	waitCmd.Flags().DurationVar(&waitTimeout, "timeout", 0, "give up after this long (e.g. 30m, 2h; 0 waits forever)")
+	waitCmd.Flags().DurationVar(&waitInterval, "interval", defaultWatchInterval, "poll interval (e.g. 5s, 30s)")
+	rootCmd.AddCommand(waitCmd)
+}
+
+// waitOutcome is how far a job has got relative to the state ap wait wants.
+type waitOutcome string
+
+const (
+	waitOutcomePending waitOutcome = "pending"
+	waitOutcomeReached waitOutcome = "reached"
+	waitOutcomeFailed  waitOutcome = "failed"
+	waitOutcomeTimeout waitOutcome = "timeout"
+)
+
+func runWait(cmd *cobra.Command, args []string) error {
+	if waitFor != "ready" && waitFor != "merged" {
+		return fmt.Errorf("invalid --for %q; expected ready or merged", waitFor)
+	}
+	if waitTimeout < 0 {
+		return fmt.Errorf("invalid --timeout %v; expected >= 0", waitTimeout)
+	}
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	store, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	jobID, err := resolveJob(store, args[0])
+	if err != nil {
+		return err
+	}
+
+	ctx := cmd.Context()
+	if waitTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, waitTimeout)
+		defer cancel()
+	}
+
+	var (
+		job     db.Job
+		outcome = waitOutcomePending
+	)
+	err = runWatchLoop(ctx, waitInterval, func(ctx context.Context, _ int64) error {
+		j, err := store.GetJob(ctx, jobID)
+		if err != nil {
+			return err
+		}
+		job = j
+		if outcome = waitOutcomeFor(job, waitFor); outcome != waitOutcomePending {
+			return errWatchDone
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errWatchDone) {
+		return err
+	}
+
+	code := waitExitReached
+	switch {
+	case outcome == waitOutcomeFailed:
+		code = waitExitFailed
+	case outcome == waitOutcomePending && errors.Is(ctx.Err(), context.DeadlineExceeded):
+		outcome, code = waitOutcomeTimeout, waitExitTimeout
+	case outcome == waitOutcomePending:
+		code = waitExitInterrupted
+	}
+
+	if jsonOut {
+		out := map[string]any{
+			"job_id":        jobID,
+			"for":           waitFor,
+			"outcome":       outcome,
+			"state":         job.State,
+			"display_state": db.DisplayState(job.State, job.PRMergedAt, job.PRClosedAt),
+			"exit_code":     code,
+		}
+		if job.PRURL != "" {
+			out["pr_url"] = job.PRURL
+		}
+		if err := writeJSONLine(out); err != nil {
+			return err
+		}
+	} else if err := writef("%s\n", waitMessage(job, jobID, outcome)); err != nil {
+		return err
+	}
+	if code != waitExitReached {
+		return &exitCodeError{code: code}
+	}
+	return nil
+}
+
+// waitOutcomeFor reports whether job has reached target ("ready" or
+// "merged"), can no longer reach it, or is still on its way.
+func waitOutcomeFor(job db.Job, target string) waitOutcome {
+	switch job.State {
+	case "failed", "rejected", "cancelled":
+		return waitOutcomeFailed
+	}
+	if target == "merged" {
+		switch {
+		case job.PRMergedAt != "":
+			return waitOutcomeReached
+		case job.PRClosedAt != "":
+			return waitOutcomeFailed
+		case job.State == "approved" && job.PRURL == "":
+			// Finished without opening a PR (e.g. a bisect job).
+			return waitOutcomeFailed
+		}
+		return waitOutcomePending
+	}
+	switch job.State {
+	case "ready", "approved":
+		return waitOutcomeReached
+	}
+	return waitOutcomePending
+}
+
+func waitMessage(job db.Job, jobID string, outcome waitOutcome) string {
+	state := db.DisplayState(job.State, job.PRMergedAt, job.PRClosedAt)
+	short := db.ShortID(jobID)
+	switch outcome {
+	case waitOutcomeReached:
+		if job.PRURL != "" {
+			return fmt.Sprintf("Job %s is %s: %s", short, state, job.PRURL)
+		}
+		return fmt.Sprintf("Job %s is %s", short, state)
+	case waitOutcomeFailed:
+		if job.ErrorMessage != "" {
+			return fmt.Sprintf("Job %s will not be %s: %s (%s)", short, waitFor, state, job.ErrorMessage)
+		}
+		return fmt.Sprintf("Job %s will not be %s: %s", short, waitFor, state)
+	case waitOutcomeTimeout:
+		return fmt.Sprintf("Timed out after %s waiting for job %s to be %s (currently %s)", waitTimeout, short, waitFor, state)
+	}
+	return fmt.Sprintf("Interrupted waiting for job %s to be %s (currently %s)", short, waitFor, state)
+}
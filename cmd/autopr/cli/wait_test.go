@@ -0,0 +1,34 @@
+package cli
+
+import (
+	"testing"
+
+	"autopr/internal/db"
+)
+
+func TestWaitOutcomeFor(t *testing.T) {
+	cases := []struct {
+		name   string
+		job    db.Job
+		target string
+		want   waitOutcome
+	}{
+		{name: "queued for ready", job: db.Job{State: "queued"}, target: "ready", want: waitOutcomePending},
+		{name: "ready for ready", job: db.Job{State: "ready"}, target: "ready", want: waitOutcomeReached},
+		{name: "approved for ready", job: db.Job{State: "approved", PRURL: "https://example.com/pr/1"}, target: "ready", want: waitOutcomeReached},
+		{name: "failed for ready", job: db.Job{State: "failed"}, target: "ready", want: waitOutcomeFailed},
+		{name: "cancelled for merged", job: db.Job{State: "cancelled"}, target: "merged", want: waitOutcomeFailed},
+		{name: "ready for merged", job: db.Job{State: "ready"}, target: "merged", want: waitOutcomePending},
+		{name: "open pr for merged", job: db.Job{State: "approved", PRURL: "https://example.com/pr/1"}, target: "merged", want: waitOutcomePending},
+		{name: "merged pr", job: db.Job{State: "approved", PRURL: "https://example.com/pr/1", PRMergedAt: "2026-01-02T03:04:05Z"}, target: "merged", want: waitOutcomeReached},
+		{name: "closed pr", job: db.Job{State: "approved", PRURL: "https://example.com/pr/1", PRClosedAt: "2026-01-02T03:04:05Z"}, target: "merged", want: waitOutcomeFailed},
+		{name: "finished without pr", job: db.Job{State: "approved"}, target: "merged", want: waitOutcomeFailed},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := waitOutcomeFor(tc.job, tc.target); got != tc.want {
+				t.Fatalf("waitOutcomeFor(%+v, %q) = %s, want %s", tc.job, tc.target, got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,302 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"autopr/internal/db"
+
+	"github.com/spf13/cobra"
+)
+
+// Exit codes of ap watch once the job stops.
+const (
+	watchExitPR      = 0 // PR created or merged (or a bisect job finished)
+	watchExitFailed  = 2 // job failed
+	watchExitStopped = 3 // job rejected or cancelled
+	watchExitHuman   = 4 // job waits for a human: approval or an oversize decision
+)
+
+var (
+	watchJobInterval time.Duration
+	watchTailLines   int
+)
+
+var watchCmd = &cobra.Command{
+	Use:   "watch <job-id>",
+	Short: "Follow one job live until it stops",
+	Long: `Show a compact live view of one job: its state, current step, elapsed time,
+the tail of the running LLM session's output, and CI progress. On a terminal
+the view is redrawn in place; otherwise one line is printed per change, and
+--json prints one JSON object per change.
+
+ap watch exits when the job stops:
+  0  PR created or merged (or a bisect job finished)
+  1  ap watch itself failed
+  2  job failed
+  3  job rejected or cancelled
+  4  job waits for a human (approval without auto_pr, or an oversize decision)`,
+	Args: cobra.ExactArgs(1),
+	RunE: runWatch,
+}
+
+func init() {
+	watchCmd.Flags().DurationVar(&watchJobInterval, "interval", 2*time.Second, "refresh interval (e.g. 5s, 2s, 500ms)")
+	watchCmd.Flags().IntVar(&watchTailLines, "lines", 10, "lines of session output to show")
+	rootCmd.AddCommand(watchCmd)
+}
+
+// errWatchDone stops the watch loop once the job has stopped.
+var errWatchDone = errors.New("job stopped")
+
+func runWatch(cmd *cobra.Command, args []string) error {
+	if watchTailLines < 0 {
+		return fmt.Errorf("invalid --lines %d; expected >= 0", watchTailLines)
+	}
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	store, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	jobID, err := resolveJob(store, args[0])
+	if err != nil {
+		return err
+	}
+
+	tty := isTerminal(os.Stdout)
+	var (
+		lastLine string
+		last     db.Job
+		code     int
+	)
+	err = runWatchLoop(cmd.Context(), watchJobInterval, func(ctx context.Context, _ int64) error {
+		snap, err := collectWatchSnapshot(ctx, store, jobID, watchTailLines, time.Now())
+		if err != nil {
+			return err
+		}
+		last = snap.Job
+		c, done := watchExitCode(snap.Job, cfg.Daemon.AutoPR)
+		switch {
+		case jsonOut:
+			if line := snap.summary(); line != lastLine || done {
+				lastLine = line
+				if err := writeJSONLine(snap.jsonPayload(done, c)); err != nil {
+					return err
+				}
+			}
+		case tty:
+			if err := writef("\033[H\033[2J"); err != nil {
+				return err
+			}
+			if err := renderWatchFrame(os.Stdout, snap); err != nil {
+				return err
+			}
+		default:
+			if line := snap.summary(); line != lastLine {
+				lastLine = line
+				if err := writef("%s %s\n", time.Now().Format("15:04:05"), line); err != nil {
+					return err
+				}
+			}
+		}
+		if done {
+			code = c
+			return errWatchDone
+		}
+		return nil
+	})
+	if !errors.Is(err, errWatchDone) {
+		return err
+	}
+	if !jsonOut {
+		if err := writef("Job %s stopped: %s (exit %d)\n", db.ShortID(jobID), watchStateLabel(last), code); err != nil {
+			return err
+		}
+	}
+	if code != 0 {
+		return &exitCodeError{code: code}
+	}
+	return nil
+}
+
+type watchSnapshot struct {
+	Job     db.Job
+	Session *db.LLMSessionSummary // latest session, nil before the first step
+	Tail    []string              // last lines of the latest session's output
+	Elapsed time.Duration
+}
+
+func collectWatchSnapshot(ctx context.Context, store *db.Store, jobID string, tailLines int, now time.Time) (watchSnapshot, error) {
+	job, err := store.GetJob(ctx, jobID)
+	if err != nil {
+		return watchSnapshot{}, err
+	}
+	if issue, err := store.GetIssueByAPID(ctx, job.AutoPRIssueID); err == nil {
+		job.IssueSource, job.SourceIssueID, job.IssueTitle, job.IssueURL = issue.Source, issue.SourceIssueID, issue.Title, issue.URL
+	}
+	snap := watchSnapshot{Job: job, Elapsed: jobElapsed(job, now)}
+
+	sessions, err := store.ListSessionSummariesByJob(ctx, jobID)
+	if err != nil {
+		return watchSnapshot{}, err
+	}
+	if len(sessions) == 0 {
+		return snap, nil
+	}
+	latest := sessions[len(sessions)-1]
+	snap.Session = &latest
+	if tailLines > 0 {
+		full, err := store.GetFullSession(ctx, latest.ID)
+		if err != nil {
+			return watchSnapshot{}, err
+		}
+		snap.Tail = tailOf(full.ResponseText, tailLines)
+	}
+	return snap, nil
+}
+
+// jobElapsed is how long the job has been running, or ran if it finished.
+func jobElapsed(job db.Job, now time.Time) time.Duration {
+	startAt := job.StartedAt
+	if startAt == "" {
+		startAt = job.CreatedAt
+	}
+	start, err := time.Parse(time.RFC3339, startAt)
+	if err != nil {
+		return 0
+	}
+	end := now
+	if t, err := time.Parse(time.RFC3339, job.CompletedAt); err == nil {
+		end = t
+	}
+	if end.Before(start) {
+		return 0
+	}
+	return end.Sub(start).Truncate(time.Second)
+}
+
+// watchExitCode reports whether the job has stopped and the exit code for
+// how. A ready job only stops the watch when no PR will be opened for it
+// automatically.
+func watchExitCode(job db.Job, autoPR bool) (int, bool) {
+	switch job.State {
+	case "approved":
+		return watchExitPR, true
+	case "failed":
+		return watchExitFailed, true
+	case "rejected", "cancelled":
+		return watchExitStopped, true
+	case "needs_review_oversize":
+		return watchExitHuman, true
+	case "ready":
+		if autoPR || job.RevertCommit != "" {
+			return 0, false
+		}
+		return watchExitHuman, true
+	}
+	return 0, false
+}
+
+func watchStateLabel(job db.Job) string {
+	return db.DisplayState(job.State, job.PRMergedAt, job.PRClosedAt)
+}
+
+// summary is a one-line description of the job that changes whenever its
+// state, step, or CI progress does.
+func (s watchSnapshot) summary() string {
+	parts := []string{watchStateLabel(s.Job)}
+	if s.Session != nil {
+		parts = append(parts, fmt.Sprintf("%s %s (iter %d)", db.DisplayStep(s.Session.Step), s.Session.Status, s.Session.Iteration))
+	}
+	if s.Job.State == "awaiting_checks" {
+		parts = append(parts, "CI "+s.Job.CIChecks.Progress())
+	}
+	return strings.Join(parts, " · ")
+}
+
+func (s watchSnapshot) jsonPayload(done bool, code int) map[string]any {
+	payload := map[string]any{
+		"job_id":          s.Job.ID,
+		"state":           s.Job.State,
+		"display_state":   watchStateLabel(s.Job),
+		"iteration":       s.Job.Iteration,
+		"elapsed_seconds": int(s.Elapsed.Seconds()),
+		"done":            done,
+	}
+	if s.Session != nil {
+		payload["step"] = s.Session.Step
+		payload["step_status"] = s.Session.Status
+	}
+	if s.Job.State == "awaiting_checks" {
+		c := s.Job.CIChecks
+		payload["ci"] = map[string]int{"total": c.Total, "passed": c.Passed, "failed": c.Failed, "pending": c.Pending}
+	}
+	if s.Job.PRURL != "" {
+		payload["pr_url"] = s.Job.PRURL
+	}
+	if done {
+		payload["exit_code"] = code
+	}
+	return payload
+}
+
+func renderWatchFrame(w io.Writer, s watchSnapshot) error {
+	var b strings.Builder
+	job := s.Job
+	fmt.Fprintf(&b, "Job %s  %s  retry %d/%d  elapsed %s\n", db.ShortID(job.ID), watchStateLabel(job), job.Iteration, job.MaxIterations, s.Elapsed)
+	if job.IssueSource != "" && job.SourceIssueID != "" {
+		fmt.Fprintf(&b, "Issue: %s #%s  %s\n", capitalize(job.IssueSource), job.SourceIssueID, job.IssueTitle)
+	} else {
+		fmt.Fprintf(&b, "Issue: %s  %s\n", job.AutoPRIssueID, job.IssueTitle)
+	}
+	if sess := s.Session; sess != nil {
+		fmt.Fprintf(&b, "Step:  %s (iter %d, %s) %s  %d in / %d out tokens\n", db.DisplayStep(sess.Step), sess.Iteration, sess.LLMProvider, sess.Status, sess.InputTokens, sess.OutputTokens)
+	}
+	if job.State == "awaiting_checks" {
+		fmt.Fprintf(&b, "CI:    %s\n", job.CIChecks.Progress())
+	} else if job.CIStatusSummary != "" {
+		fmt.Fprintf(&b, "CI:    %s\n", job.CIStatusSummary)
+	}
+	if job.PRURL != "" {
+		fmt.Fprintf(&b, "PR:    %s\n", job.PRURL)
+	}
+	if job.ErrorMessage != "" {
+		fmt.Fprintf(&b, "Error: %s\n", job.ErrorMessage)
+	}
+	if len(s.Tail) > 0 {
+		b.WriteString("── output ──\n")
+		for _, line := range s.Tail {
+			b.WriteString(line)
+			b.WriteString("\n")
+		}
+	}
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return fmt.Errorf("write output: %w", err)
+	}
+	return nil
+}
+
+// tailOf returns the last n lines of text, ignoring trailing newlines.
+func tailOf(text string, n int) []string {
+	text = strings.TrimRight(text, "\n")
+	if text == "" {
+		return nil
+	}
+	lines := strings.Split(text, "\n")
+	return lines[max(0, len(lines)-n):]
+}
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"autopr/internal/db"
+)
+
+func TestWatchExitCode(t *testing.T) {
+	cases := []struct {
+		name     string
+		job      db.Job
+		autoPR   bool
+		wantCode int
+		wantDone bool
+	}{
+		{name: "running", job: db.Job{State: "implementing"}},
+		{name: "awaiting checks", job: db.Job{State: "awaiting_checks"}},
+		{name: "approved", job: db.Job{State: "approved"}, wantCode: watchExitPR, wantDone: true},
+		{name: "failed", job: db.Job{State: "failed"}, wantCode: watchExitFailed, wantDone: true},
+		{name: "rejected", job: db.Job{State: "rejected"}, wantCode: watchExitStopped, wantDone: true},
+		{name: "cancelled", job: db.Job{State: "cancelled"}, wantCode: watchExitStopped, wantDone: true},
+		{name: "oversize", job: db.Job{State: "needs_review_oversize"}, wantCode: watchExitHuman, wantDone: true},
+		{name: "ready needs approval", job: db.Job{State: "ready"}, wantCode: watchExitHuman, wantDone: true},
+		{name: "ready with auto pr", job: db.Job{State: "ready"}, autoPR: true},
+		{name: "ready revert", job: db.Job{State: "ready", RevertCommit: "abc123"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			code, done := watchExitCode(tc.job, tc.autoPR)
+			if code != tc.wantCode || done != tc.wantDone {
+				t.Fatalf("watchExitCode(%s, %v) = (%d, %v), want (%d, %v)", tc.job.State, tc.autoPR, code, done, tc.wantCode, tc.wantDone)
+			}
+		})
+	}
+}
+
+func TestCollectWatchSnapshotTailsLatestSession(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "autopr.db")
+	jobID, _, sessionTwo := seedLogsJobForTest(t, dbPath)
+
+	store, err := db.Open(dbPath)
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	if _, err := store.Writer.ExecContext(ctx, `UPDATE llm_sessions SET response_text = ? WHERE id = ?`, "one\ntwo\nthree\nfour\n", sessionTwo); err != nil {
+		t.Fatalf("set response text: %v", err)
+	}
+	if _, err := store.Writer.ExecContext(ctx, `UPDATE jobs SET state = 'implementing', started_at = '2026-01-02T03:00:00Z' WHERE id = ?`, jobID); err != nil {
+		t.Fatalf("start job: %v", err)
+	}
+
+	now := time.Date(2026, 1, 2, 3, 1, 30, 0, time.UTC)
+	snap, err := collectWatchSnapshot(ctx, store, jobID, 2, now)
+	if err != nil {
+		t.Fatalf("collect snapshot: %v", err)
+	}
+	if snap.Session == nil || int64(snap.Session.ID) != sessionTwo {
+		t.Fatalf("expected latest session %d, got %+v", sessionTwo, snap.Session)
+	}
+	if want := []string{"three", "four"}; !slices.Equal(snap.Tail, want) {
+		t.Fatalf("tail = %q, want %q", snap.Tail, want)
+	}
+	if snap.Elapsed != 90*time.Second {
+		t.Fatalf("elapsed = %s, want 1m30s", snap.Elapsed)
+	}
+
+	var out strings.Builder
+	if err := renderWatchFrame(&out, snap); err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	for _, want := range []string{"implementing", "Github #1010", "logs test", "Step:  implementing", "four"} {
+		if !strings.Contains(out.String(), want) {
+			t.Fatalf("frame missing %q:\n%s", want, out.String())
+		}
+	}
+}
@@ -0,0 +1,379 @@
+// Package attachments downloads the files uploaded to source issues, such as
+// logs and screenshots.
+//
+// For projects with [projects.attachments], the sync loop looks for uploads
+// linked from the body and synced comments of each eligible open issue
+// whenever the issue changed on its source, and the pipeline looks again
+// before planning. Files are saved next to the database and recorded in
+// issue_attachments; the plan step adds text files to its prompt and, with
+// images enabled, hands screenshots to the LLM.
+package attachments
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"autopr/internal/config"
+	"autopr/internal/db"
+	"autopr/internal/git"
+	"autopr/internal/githubapp"
+)
+
+var (
+	markdownLinkRE = regexp.MustCompile(`\[([^\]]*)\]\(\s*<?([^)\s>]+)>?(?:\s+"[^"]*")?\s*\)`)
+	htmlLinkRE     = regexp.MustCompile(`(?i)<(?:img|a)\b[^>]*?\b(?:src|href)\s*=\s*["']([^"']+)["']`)
+	bareURLRE      = regexp.MustCompile("https?://[^\\s<>()\\[\\]\"'`]+")
+
+	// gitlabUploadRE matches the path of a file uploaded to a GitLab
+	// project: /uploads/<secret>/<filename>.
+	gitlabUploadRE = regexp.MustCompile(`/uploads/([0-9a-f]{32})/([^/?#]+)$`)
+	// giteaAttachmentRE matches the path of a file uploaded to Gitea.
+	giteaAttachmentRE = regexp.MustCompile(`/attachments/[0-9a-f-]{36}$`)
+)
+
+// textExtensions are the file extensions read as text whatever content type
+// the forge serves them with.
+var textExtensions = []string{".txt", ".log", ".out", ".json", ".yaml", ".yml", ".toml", ".ini", ".conf", ".csv", ".xml", ".md", ".diff", ".patch", ".trace"}
+
+// imageTypes maps the image content types passed to the LLM to the file
+// extension they are saved with.
+var imageTypes = map[string]string{
+	"image/png":  ".png",
+	"image/jpeg": ".jpg",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+// Link is a link found in issue text.
+type Link struct {
+	URL  string
+	Name string // link text, when it has any
+}
+
+// Links returns the links in text, each URL once, in the order they appear:
+// Markdown links and images, HTML <a> and <img> tags, and bare URLs.
+func Links(text string) []Link {
+	var out []Link
+	seen := map[string]bool{}
+	add := func(u, name string) {
+		u = strings.TrimRight(u, ".,;:!?")
+		if u == "" || seen[u] {
+			return
+		}
+		seen[u] = true
+		out = append(out, Link{URL: u, Name: strings.TrimSpace(name)})
+	}
+	for _, m := range markdownLinkRE.FindAllStringSubmatch(text, -1) {
+		add(m[2], m[1])
+	}
+	for _, m := range htmlLinkRE.FindAllStringSubmatch(text, -1) {
+		add(m[1], "")
+	}
+	for _, m := range bareURLRE.FindAllString(text, -1) {
+		add(m, "")
+	}
+	return out
+}
+
+// Dir is the directory attachments are saved in, next to the database.
+func Dir(cfg *config.Config) string {
+	return filepath.Join(filepath.Dir(cfg.DBPath), "attachments")
+}
+
+// Fetcher downloads the attachments of issues on GitHub, GitLab, and Gitea
+// and records them.
+type Fetcher struct {
+	cfg   *config.Config
+	store *db.Store
+
+	download func(ctx context.Context, rawURL, authorization string, maxBytes int64) ([]byte, string, error)
+}
+
+func New(cfg *config.Config, store *db.Store) *Fetcher {
+	return &Fetcher{cfg: cfg, store: store, download: git.DownloadAttachment}
+}
+
+// Supported reports whether issues from source can have attachments.
+func Supported(source string) bool {
+	return source == "github" || source == "gitlab" || source == "gitea"
+}
+
+// target is where to download an attachment from.
+type target struct {
+	url           string
+	authorization string
+}
+
+// Refresh downloads the files linked from issue and its synced comments that
+// were not downloaded before, up to the project's max_files. Files that cannot
+// be downloaded are recorded with the reason and tried again on the next
+// refresh. Issues of projects without [projects.attachments], and of sources
+// without uploads, are left alone.
+func (f *Fetcher) Refresh(ctx context.Context, issue db.Issue) error {
+	p, ok := f.cfg.ProjectByName(issue.ProjectName)
+	if !ok || p.Attachments == nil || !Supported(issue.Source) {
+		return nil
+	}
+	stored, err := f.store.GetIssueByAPID(ctx, issue.AutoPRIssueID)
+	if err != nil {
+		return err
+	}
+	comments, err := f.store.ListIssueComments(ctx, issue.AutoPRIssueID)
+	if err != nil {
+		return err
+	}
+	texts := []string{stored.Body}
+	for _, c := range comments {
+		texts = append(texts, c.Body)
+	}
+	known, err := f.store.ListIssueAttachments(ctx, issue.AutoPRIssueID)
+	if err != nil {
+		return err
+	}
+	downloaded := map[string]bool{}
+	for _, a := range known {
+		if a.Path != "" {
+			downloaded[a.URL] = true
+		}
+	}
+
+	var links []Link
+	for _, text := range texts {
+		links = append(links, Links(text)...)
+	}
+	token, err := f.token(ctx, p, issue.Source)
+	if err != nil {
+		return err
+	}
+	seen := map[string]bool{}
+	for _, l := range links {
+		t, ok := resolve(p, issue.Source, token, l.URL)
+		if !ok || seen[l.URL] {
+			continue
+		}
+		seen[l.URL] = true
+		if downloaded[l.URL] {
+			continue
+		}
+		a := db.IssueAttachment{URL: l.URL, Name: attachmentName(l)}
+		if len(seen) > p.Attachments.MaxFiles {
+			a.Error = fmt.Sprintf("more than max_files (%d) attachments", p.Attachments.MaxFiles)
+		} else {
+			f.fetch(ctx, issue, p.Attachments, t, len(seen), &a)
+		}
+		if err := f.store.UpsertIssueAttachment(ctx, issue.AutoPRIssueID, a); err != nil {
+			return err
+		}
+	}
+	return f.store.SetIssueAttachmentsSynced(ctx, issue.AutoPRIssueID, issue.SourceUpdated)
+}
+
+// fetch downloads the attachment a from t and saves it as the n-th attachment
+// of issue, filling in a's kind, path, and size, or its error.
+func (f *Fetcher) fetch(ctx context.Context, issue db.Issue, at *config.ProjectAttachments, t target, n int, a *db.IssueAttachment) {
+	data, contentType, err := f.download(ctx, t.url, t.authorization, at.MaxBytes)
+	switch {
+	case errors.Is(err, git.ErrAttachmentTooLarge):
+		a.Error = fmt.Sprintf("larger than max_bytes (%d)", at.MaxBytes)
+		return
+	case err != nil:
+		slog.Warn("attachments: download", "issue", issue.Source+"#"+issue.SourceIssueID, "url", a.URL, "err", err)
+		a.Error = err.Error()
+		return
+	}
+	kind, ext := kindOf(a.Name, contentType, data)
+	if kind == "" {
+		a.Error = "unsupported file type " + contentType
+		return
+	}
+	name := safeName(a.Name)
+	if ext != "" && !strings.EqualFold(filepath.Ext(name), ext) {
+		name += ext
+	}
+	dir := filepath.Join(Dir(f.cfg), issue.AutoPRIssueID)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		a.Error = err.Error()
+		return
+	}
+	p := filepath.Join(dir, fmt.Sprintf("%02d-%s", n, name))
+	if err := os.WriteFile(p, data, 0o644); err != nil {
+		a.Error = err.Error()
+		return
+	}
+	a.Kind, a.Path, a.SizeBytes = kind, p, int64(len(data))
+}
+
+// Sync refreshes the attachments of issue unless they were looked for since
+// it last changed on its source. Failures are logged and retried on the next
+// sync.
+func (f *Fetcher) Sync(ctx context.Context, issue db.Issue) {
+	p, ok := f.cfg.ProjectByName(issue.ProjectName)
+	if !ok || p.Attachments == nil || !Supported(issue.Source) {
+		return
+	}
+	synced, err := f.store.IssueAttachmentsSourceUpdated(ctx, issue.AutoPRIssueID)
+	if err != nil {
+		slog.Warn("attachments: check sync", "issue", issue.AutoPRIssueID, "err", err)
+		return
+	}
+	if synced != "" && synced == issue.SourceUpdated {
+		return
+	}
+	if err := f.Refresh(ctx, issue); err != nil {
+		slog.Warn("attachments: sync", "project", issue.ProjectName, "issue", issue.Source+"#"+issue.SourceIssueID, "err", err)
+	}
+}
+
+// token returns the forge token attachments of the project's issues from
+// source are downloaded with.
+func (f *Fetcher) token(ctx context.Context, p *config.ProjectConfig, source string) (string, error) {
+	switch source {
+	case "github":
+		return githubapp.Token(ctx, f.cfg, p)
+	case "gitlab":
+		return f.cfg.Tokens.GitLab, nil
+	case "gitea":
+		return f.cfg.Tokens.Gitea, nil
+	}
+	return "", nil
+}
+
+// resolve returns where to download link from when it is a file uploaded to
+// the project's forge. Links elsewhere are not attachments and are never
+// fetched, and the token is only sent to the forge itself.
+func resolve(p *config.ProjectConfig, source, token, link string) (target, bool) {
+	u, err := url.Parse(link)
+	if err != nil {
+		return target{}, false
+	}
+	absolute := u.Host != "" && (u.Scheme == "https" || u.Scheme == "http")
+	switch {
+	case source == "github" && p.GitHub != nil && absolute:
+		host := strings.ToLower(u.Host)
+		if host == "user-images.githubusercontent.com" || host == "private-user-images.githubusercontent.com" {
+			// Signed or public image URLs: no token needed.
+			return target{url: link}, true
+		}
+		repoPrefix := "/" + p.GitHub.Owner + "/" + p.GitHub.Repo + "/"
+		if host != githubWebHost(p.GitHub.BaseURL) {
+			return target{}, false
+		}
+		if strings.HasPrefix(u.Path, "/user-attachments/") || strings.HasPrefix(u.Path, repoPrefix+"assets/") || strings.HasPrefix(u.Path, repoPrefix+"files/") {
+			return target{url: link, authorization: bearer(token)}, true
+		}
+	case source == "gitlab" && p.GitLab != nil:
+		if absolute && !strings.EqualFold(u.Host, hostOf(git.NormalizeGitLabBaseURL(p.GitLab.BaseURL))) {
+			return target{}, false
+		}
+		if !absolute && u.Host != "" {
+			return target{}, false
+		}
+		m := gitlabUploadRE.FindStringSubmatch(u.Path)
+		if m == nil {
+			return target{}, false
+		}
+		return target{url: git.GitLabUploadURL(p.GitLab.BaseURL, p.GitLab.ProjectID, m[1], m[2]), authorization: bearer(token)}, true
+	case source == "gitea" && p.Gitea != nil && absolute:
+		if !strings.EqualFold(u.Host, hostOf(git.NormalizeGiteaBaseURL(p.Gitea.BaseURL))) || !giteaAttachmentRE.MatchString(u.Path) {
+			return target{}, false
+		}
+		auth := ""
+		if token != "" {
+			auth = "token " + token
+		}
+		return target{url: link, authorization: auth}, true
+	}
+	return target{}, false
+}
+
+func bearer(token string) string {
+	if token == "" {
+		return ""
+	}
+	return "Bearer " + token
+}
+
+func hostOf(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return u.Host
+}
+
+// githubWebHost is the host of the GitHub web UI for an API base URL, where
+// uploads are served from.
+func githubWebHost(baseURL string) string {
+	host := strings.ToLower(hostOf(git.NormalizeGitHubAPIBaseURL(baseURL)))
+	if host == "api.github.com" {
+		return "github.com"
+	}
+	return host
+}
+
+// attachmentName is the file name of the attachment at l: the link text when
+// it names a file, else the last element of the URL path.
+func attachmentName(l Link) string {
+	if l.Name != "" && filepath.Ext(l.Name) != "" {
+		return l.Name
+	}
+	u, err := url.Parse(l.URL)
+	if err != nil {
+		return "attachment"
+	}
+	if base := path.Base(u.Path); base != "" && base != "/" && base != "." {
+		return base
+	}
+	return "attachment"
+}
+
+// kindOf reports whether data, downloaded as name with contentType, is text
+// or an image the LLM can be shown, with the extension to save an image
+// with. It returns "" for anything else.
+func kindOf(name, contentType string, data []byte) (kind, ext string) {
+	media, _, _ := mime.ParseMediaType(contentType)
+	if media == "" || media == "application/octet-stream" {
+		media, _, _ = mime.ParseMediaType(http.DetectContentType(data))
+	}
+	if ext, ok := imageTypes[media]; ok {
+		return db.AttachmentImage, ext
+	}
+	isText := strings.HasPrefix(media, "text/") || media == "application/json" || media == "application/xml" || media == "application/yaml" ||
+		slices.Contains(textExtensions, strings.ToLower(filepath.Ext(name)))
+	if isText && utf8.Valid(data) && !slices.Contains(data, 0) {
+		return db.AttachmentText, ""
+	}
+	return "", ""
+}
+
+// safeName is name with anything but letters, digits, '.', '-', and '_'
+// replaced, short enough for any file system.
+func safeName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_':
+			return r
+		}
+		return '-'
+	}, name)
+	name = strings.Trim(name, ".-")
+	if len(name) > 80 {
+		name = name[len(name)-80:]
+	}
+	if name == "" {
+		return "attachment"
+	}
+	return name
+}
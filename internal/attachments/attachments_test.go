@@ -0,0 +1,157 @@
+package attachments
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"autopr/internal/config"
+	"autopr/internal/db"
+	"autopr/internal/git"
+)
+
+func TestLinks(t *testing.T) {
+	t.Parallel()
+
+	body := "Crashes on save. Log: [crash.log](https://github.com/user-attachments/files/1/crash.log)\n" +
+		`<img width="400" alt="Screenshot" src="https://github.com/user-attachments/assets/5b7c-uuid" />` + "\n" +
+		"See also https://example.com/docs. And again [log](https://github.com/user-attachments/files/1/crash.log)."
+	got := Links(body)
+	want := []Link{
+		{URL: "https://github.com/user-attachments/files/1/crash.log", Name: "crash.log"},
+		{URL: "https://github.com/user-attachments/assets/5b7c-uuid"},
+		{URL: "https://example.com/docs"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Links = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("Links[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestResolveOnlyFetchesForgeUploads(t *testing.T) {
+	t.Parallel()
+
+	gh := &config.ProjectConfig{GitHub: &config.ProjectGitHub{Owner: "org", Repo: "repo"}}
+	gl := &config.ProjectConfig{GitLab: &config.ProjectGitLab{BaseURL: "https://gitlab.example.com", ProjectID: "7"}}
+	gt := &config.ProjectConfig{Gitea: &config.ProjectGitea{BaseURL: "https://git.example.com", Owner: "org", Repo: "repo"}}
+	tests := []struct {
+		name     string
+		proj     *config.ProjectConfig
+		source   string
+		link     string
+		wantURL  string
+		wantAuth string
+	}{
+		{"github user attachment", gh, "github", "https://github.com/user-attachments/files/1/crash.log", "https://github.com/user-attachments/files/1/crash.log", "Bearer tok"},
+		{"github repo asset", gh, "github", "https://github.com/org/repo/assets/1/abc", "https://github.com/org/repo/assets/1/abc", "Bearer tok"},
+		{"github image host gets no token", gh, "github", "https://user-images.githubusercontent.com/1/shot.png", "https://user-images.githubusercontent.com/1/shot.png", ""},
+		{"github other repo", gh, "github", "https://github.com/other/repo/assets/1/abc", "", ""},
+		{"github elsewhere", gh, "github", "https://example.com/user-attachments/files/1/crash.log", "", ""},
+		{"gitlab relative upload", gl, "gitlab", "/uploads/0123456789abcdef0123456789abcdef/trace.txt", "https://gitlab.example.com/api/v4/projects/7/uploads/0123456789abcdef0123456789abcdef/trace.txt", "Bearer tok"},
+		{"gitlab upload on another host", gl, "gitlab", "https://evil.example.com/g/r/uploads/0123456789abcdef0123456789abcdef/trace.txt", "", ""},
+		{"gitea attachment", gt, "gitea", "https://git.example.com/attachments/0b1c2d3e-4f50-6172-8394-a5b6c7d8e9f0", "https://git.example.com/attachments/0b1c2d3e-4f50-6172-8394-a5b6c7d8e9f0", "token tok"},
+		{"gitea page", gt, "gitea", "https://git.example.com/org/repo/issues/3", "", ""},
+	}
+	for _, tt := range tests {
+		got, ok := resolve(tt.proj, tt.source, "tok", tt.link)
+		if ok != (tt.wantURL != "") || got.url != tt.wantURL || got.authorization != tt.wantAuth {
+			t.Errorf("%s: resolve = %+v, %v; want %q with %q", tt.name, got, ok, tt.wantURL, tt.wantAuth)
+		}
+	}
+}
+
+func TestRefreshDownloadsTextAndImages(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	dir := t.TempDir()
+	store, err := db.Open(filepath.Join(dir, "autopr.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer store.Close()
+	body := "Crashes on save.\n\n[crash.log](https://github.com/user-attachments/files/1/crash.log)\n" +
+		"![shot](https://github.com/user-attachments/assets/shot-uuid)\n" +
+		"[core.bin](https://github.com/user-attachments/files/2/core.bin)\n" +
+		"[huge.log](https://github.com/user-attachments/files/3/huge.log)\n" +
+		"[more.log](https://github.com/user-attachments/files/4/more.log)"
+	issueID, err := store.UpsertIssue(ctx, db.IssueUpsert{ProjectName: "web", Source: "github", SourceIssueID: "42", Title: "t", Body: body, URL: "u", State: "open"})
+	if err != nil {
+		t.Fatalf("upsert issue: %v", err)
+	}
+
+	cfg := &config.Config{
+		DBPath: filepath.Join(dir, "autopr.db"),
+		Tokens: config.TokensConfig{GitHub: "tok"},
+		Projects: []config.ProjectConfig{{
+			Name:        "web",
+			GitHub:      &config.ProjectGitHub{Owner: "org", Repo: "repo"},
+			Attachments: &config.ProjectAttachments{MaxBytes: 100, MaxFiles: 4},
+		}},
+	}
+	png := append([]byte("\x89PNG\r\n\x1a\n"), make([]byte, 16)...)
+	f := New(cfg, store)
+	downloads := 0
+	f.download = func(_ context.Context, rawURL, authorization string, maxBytes int64) ([]byte, string, error) {
+		downloads++
+		if authorization != "Bearer tok" || maxBytes != 100 {
+			t.Fatalf("unexpected download of %s with %q, limit %d", rawURL, authorization, maxBytes)
+		}
+		switch {
+		case strings.HasSuffix(rawURL, "crash.log"):
+			return []byte("panic: nil map\n"), "application/octet-stream", nil
+		case strings.HasSuffix(rawURL, "shot-uuid"):
+			return png, "image/png", nil
+		case strings.HasSuffix(rawURL, "core.bin"):
+			return []byte{0x7f, 'E', 'L', 'F', 0, 0}, "application/octet-stream", nil
+		case strings.HasSuffix(rawURL, "huge.log"):
+			return nil, "", git.ErrAttachmentTooLarge
+		}
+		t.Fatalf("unexpected download of %s", rawURL)
+		return nil, "", nil
+	}
+
+	issue := db.Issue{AutoPRIssueID: issueID, ProjectName: "web", Source: "github", SourceIssueID: "42", SourceUpdated: "2026-01-01T00:00:00Z"}
+	f.Sync(ctx, issue)
+	got, err := store.ListIssueAttachments(ctx, issueID)
+	if err != nil {
+		t.Fatalf("list attachments: %v", err)
+	}
+	if len(got) != 5 {
+		t.Fatalf("expected 5 attachments recorded, got %+v", got)
+	}
+	if got[0].Kind != db.AttachmentText || got[0].Name != "crash.log" {
+		t.Fatalf("expected crash.log as text, got %+v", got[0])
+	}
+	if data, err := os.ReadFile(got[0].Path); err != nil || string(data) != "panic: nil map\n" {
+		t.Fatalf("expected crash.log saved, got %q, %v", data, err)
+	}
+	if got[1].Kind != db.AttachmentImage || filepath.Ext(got[1].Path) != ".png" {
+		t.Fatalf("expected the screenshot saved as a png, got %+v", got[1])
+	}
+	if got[2].Path != "" || !strings.Contains(got[2].Error, "unsupported") {
+		t.Fatalf("expected the binary skipped, got %+v", got[2])
+	}
+	if got[3].Path != "" || !strings.Contains(got[3].Error, "max_bytes") {
+		t.Fatalf("expected the large log skipped, got %+v", got[3])
+	}
+	if got[4].Path != "" || !strings.Contains(got[4].Error, "max_files") {
+		t.Fatalf("expected the fifth file over max_files, got %+v", got[4])
+	}
+
+	f.Sync(ctx, issue) // unchanged on the source: nothing downloaded again
+	if downloads != 4 {
+		t.Fatalf("expected 4 downloads, got %d", downloads)
+	}
+	issue.SourceUpdated = "2026-01-02T00:00:00Z"
+	f.Sync(ctx, issue) // files saved before are not downloaded again
+	if downloads != 6 {
+		t.Fatalf("expected only the failed files fetched again, got %d downloads", downloads)
+	}
+}
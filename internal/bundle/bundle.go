@@ -0,0 +1,289 @@
+// Package bundle exports a project's job templates, prompt overrides, and
+// gates as a shareable TOML file, and imports such a file into a config, so
+// a curated setup can be reused across repositories.
+package bundle
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"autopr/internal/config"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Version is the bundle format written by Export; Decode rejects newer ones.
+const Version = 1
+
+// Bundle is a project's setup without anything tied to its repository:
+// templates name no project, and prompts hold their text rather than paths.
+type Bundle struct {
+	Version     int               `toml:"bundle_version"`
+	Description string            `toml:"description,omitempty"`
+	Gates       *Gates            `toml:"gates,omitempty"`
+	Prompts     map[string]string `toml:"prompts,omitempty"` // step (see PromptSteps) -> template text
+	Templates   []Template        `toml:"templates,omitempty"`
+}
+
+// Gates are the commands a job must pass.
+type Gates struct {
+	TestCmd    string   `toml:"test_cmd,omitempty"`
+	TestShards []string `toml:"test_shards,omitempty"`
+	LintCmd    string   `toml:"lint_cmd,omitempty"`
+}
+
+// Template is a config.JobTemplate without its project.
+type Template struct {
+	Name    string `toml:"name"`
+	Title   string `toml:"title"`
+	Prompt  string `toml:"prompt"`
+	TestCmd string `toml:"test_cmd,omitempty"`
+	LintCmd string `toml:"lint_cmd,omitempty"`
+}
+
+// PromptSteps are the [projects.prompts] keys a bundle can carry.
+var PromptSteps = []string{"plan", "plan_review", "implement", "code_review", "conflict_resolve"}
+
+// promptPaths returns the prompt override files of p by step.
+func promptPaths(p *config.ProjectConfig) map[string]string {
+	if p.Prompts == nil {
+		return nil
+	}
+	return map[string]string{
+		"plan":             p.Prompts.Plan,
+		"plan_review":      p.Prompts.PlanReview,
+		"implement":        p.Prompts.Implement,
+		"code_review":      p.Prompts.CodeReview,
+		"conflict_resolve": p.Prompts.ConflictResolve,
+	}
+}
+
+// Export collects the job templates of proj in cfg, its prompt overrides
+// (read from their files), and its gates.
+func Export(cfg *config.Config, proj *config.ProjectConfig) (*Bundle, error) {
+	b := &Bundle{Version: Version}
+	if proj.TestCmd != "" || len(proj.TestShards) > 0 || proj.LintCmd != "" {
+		b.Gates = &Gates{TestCmd: proj.TestCmd, TestShards: proj.TestShards, LintCmd: proj.LintCmd}
+	}
+	for _, step := range PromptSteps {
+		path := promptPaths(proj)[step]
+		if path == "" {
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read %s prompt: %w", step, err)
+		}
+		if b.Prompts == nil {
+			b.Prompts = map[string]string{}
+		}
+		b.Prompts[step] = string(data)
+	}
+	for _, t := range cfg.Templates {
+		if t.Project == proj.Name {
+			b.Templates = append(b.Templates, Template{Name: t.Name, Title: t.Title, Prompt: t.Prompt, TestCmd: t.TestCmd, LintCmd: t.LintCmd})
+		}
+	}
+	return b, nil
+}
+
+// Encode writes b as TOML.
+func Encode(w io.Writer, b *Bundle) error {
+	if err := toml.NewEncoder(w).Encode(b); err != nil {
+		return fmt.Errorf("encode bundle: %w", err)
+	}
+	return nil
+}
+
+// Decode reads a bundle written by Encode.
+func Decode(r io.Reader) (*Bundle, error) {
+	var b Bundle
+	md, err := toml.NewDecoder(r).Decode(&b)
+	if err != nil {
+		return nil, fmt.Errorf("decode bundle: %w", err)
+	}
+	if undecoded := md.Undecoded(); len(undecoded) > 0 {
+		return nil, fmt.Errorf("decode bundle: unknown key %q", undecoded[0].String())
+	}
+	if b.Version < 1 || b.Version > Version {
+		return nil, fmt.Errorf("unsupported bundle_version %d (this version of ap reads 1 to %d)", b.Version, Version)
+	}
+	for step := range b.Prompts {
+		if !slices.Contains(PromptSteps, step) {
+			return nil, fmt.Errorf("bundle prompts: unknown step %q (want one of %s)", step, strings.Join(PromptSteps, ", "))
+		}
+	}
+	return &b, nil
+}
+
+// Empty reports whether b carries nothing to import.
+func (b *Bundle) Empty() bool {
+	return b.Gates == nil && len(b.Prompts) == 0 && len(b.Templates) == 0
+}
+
+// Commands returns the shell commands b would have jobs run, its gates and
+// its templates' test_cmd and lint_cmd, as "key = command" lines.
+func (b *Bundle) Commands() []string {
+	var out []string
+	add := func(key, cmd string) {
+		if cmd != "" {
+			out = append(out, fmt.Sprintf("%s = %q", key, cmd))
+		}
+	}
+	if g := b.Gates; g != nil {
+		add("test_cmd", g.TestCmd)
+		for i, shard := range g.TestShards {
+			add(fmt.Sprintf("test_shards[%d]", i), shard)
+		}
+		add("lint_cmd", g.LintCmd)
+	}
+	for _, t := range b.Templates {
+		add("templates."+t.Name+".test_cmd", t.TestCmd)
+		add("templates."+t.Name+".lint_cmd", t.LintCmd)
+	}
+	return out
+}
+
+// CommandsError refuses to import a bundle's shell commands that were not
+// allowed.
+type CommandsError struct {
+	Commands []string
+}
+
+func (e *CommandsError) Error() string {
+	return "bundle sets shell commands that jobs will run:\n  " + strings.Join(e.Commands, "\n  ")
+}
+
+// Result says what Import changed.
+type Result struct {
+	Templates   []string // names of the templates added
+	PromptFiles []string // prompt files written, relative to the config directory
+	Gates       bool     // whether the project's gates were set
+	Commands    []string // shell commands imported (see Bundle.Commands)
+}
+
+// Import adds b to the config at configPath for project: its templates are
+// appended as [[templates]] for the project, its prompts are written to
+// prompts/<project>/<step>.md next to the config and set in the project's
+// [projects.prompts], and its gates replace the project's test_cmd,
+// test_shards, and lint_cmd. Templates whose name is taken are an error. The
+// config is left unchanged when the result does not load.
+//
+// A bundle's commands run wherever the project's jobs run, so a bundle with
+// any is refused with a *CommandsError unless allowCommands is set.
+func Import(configPath string, b *Bundle, project string, allowCommands bool) (Result, error) {
+	var res Result
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		return res, err
+	}
+	if res.Commands = b.Commands(); len(res.Commands) > 0 && !allowCommands {
+		return Result{}, &CommandsError{Commands: res.Commands}
+	}
+	if _, ok := cfg.ProjectByName(project); !ok {
+		return res, fmt.Errorf("project %q not found in %s", project, configPath)
+	}
+	for _, t := range b.Templates {
+		if _, taken := cfg.TemplateByName(strings.ToLower(strings.TrimSpace(t.Name))); taken {
+			return res, fmt.Errorf("template %q already exists in %s", t.Name, configPath)
+		}
+	}
+
+	original, err := os.ReadFile(configPath)
+	if err != nil {
+		return res, fmt.Errorf("read config: %w", err)
+	}
+	doc := parseDoc(string(original))
+	if _, _, ok := doc.span(project); !ok {
+		return res, fmt.Errorf("project %q: no [[projects]] table with that name in %s", project, configPath)
+	}
+
+	if g := b.Gates; g != nil {
+		if g.TestCmd != "" {
+			doc.set(project, "", "test_cmd", g.TestCmd)
+		}
+		if len(g.TestShards) > 0 {
+			doc.set(project, "", "test_shards", g.TestShards)
+		}
+		if g.LintCmd != "" {
+			doc.set(project, "", "lint_cmd", g.LintCmd)
+		}
+		res.Gates = true
+	}
+
+	// Prompt files written so far and what they held before (nil when they
+	// did not exist), to put back if the import fails.
+	dir := filepath.Dir(configPath)
+	written := map[string][]byte{}
+	restore := func() {
+		for p, prev := range written {
+			if prev == nil {
+				_ = os.Remove(p)
+			} else {
+				_ = os.WriteFile(p, prev, 0o644)
+			}
+		}
+	}
+	for _, step := range PromptSteps {
+		text, ok := b.Prompts[step]
+		if !ok {
+			continue
+		}
+		rel := filepath.ToSlash(filepath.Join("prompts", project, step+".md"))
+		path := filepath.Join(dir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			restore()
+			return Result{}, fmt.Errorf("create prompts dir: %w", err)
+		}
+		prev, err := os.ReadFile(path)
+		if err != nil && !os.IsNotExist(err) {
+			restore()
+			return Result{}, fmt.Errorf("read %s prompt: %w", step, err)
+		}
+		written[path] = prev
+		if err := os.WriteFile(path, []byte(text), 0o644); err != nil {
+			restore()
+			return Result{}, fmt.Errorf("write %s prompt: %w", step, err)
+		}
+		doc.set(project, "prompts", step, rel)
+		res.PromptFiles = append(res.PromptFiles, rel)
+	}
+
+	var blocks bytes.Buffer
+	for _, t := range b.Templates {
+		blocks.WriteString("\n[[templates]]\n")
+		writeKey(&blocks, "", "name", t.Name)
+		writeKey(&blocks, "", "project", project)
+		writeKey(&blocks, "", "title", t.Title)
+		writeKey(&blocks, "", "prompt", t.Prompt)
+		if t.TestCmd != "" {
+			writeKey(&blocks, "", "test_cmd", t.TestCmd)
+		}
+		if t.LintCmd != "" {
+			writeKey(&blocks, "", "lint_cmd", t.LintCmd)
+		}
+		res.Templates = append(res.Templates, t.Name)
+	}
+
+	updated := doc.String()
+	if blocks.Len() > 0 {
+		updated = strings.TrimRight(updated, "\n") + "\n" + blocks.String()
+	}
+	if err := os.WriteFile(configPath, []byte(updated), 0o644); err != nil {
+		restore()
+		return Result{}, fmt.Errorf("write config: %w", err)
+	}
+	if _, err := config.Load(configPath); err != nil {
+		restore()
+		if rerr := os.WriteFile(configPath, original, 0o644); rerr != nil {
+			return Result{}, fmt.Errorf("imported config is invalid (%v) and restoring it failed: %w", err, rerr)
+		}
+		return Result{}, fmt.Errorf("imported config is invalid, config left unchanged: %w", err)
+	}
+	return res, nil
+}
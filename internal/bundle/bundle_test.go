@@ -0,0 +1,194 @@
+package bundle
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"autopr/internal/config"
+)
+
+const sourceConfig = `
+[[projects]]
+name = "billing"
+repo_url = "https://github.com/org/billing.git"
+test_cmd = "make test"
+lint_cmd = "go vet ./..."
+
+  [projects.github]
+  owner = "org"
+  repo = "billing"
+
+  [projects.prompts]
+  plan = "prompts/plan.md"
+
+[[templates]]
+name = "add-endpoint"
+project = "billing"
+title = "Add {{name}}"
+prompt = """
+Add a {{name}} handler.
+Register it in the router.
+"""
+test_cmd = "go test ./internal/{{service}}/..."
+`
+
+const targetConfig = `# team config
+[[projects]]
+name = "web"
+repo_url = "https://github.com/org/web.git"
+test_cmd = "npm test"   # keep this comment's line replaced
+
+  [projects.github]
+  owner = "org"
+  repo = "web"
+
+  # Sync issue comments.
+  # [projects.issue_comments]
+
+[[projects]]
+name = "api"
+repo_url = "https://github.com/org/api.git"
+test_shards = [
+  "go test ./a/...",
+  "go test ./b/...",
+]
+test_cmd = "go test ./..."
+
+  [projects.github]
+  owner = "org"
+  repo = "api"
+
+  [projects.prompts]
+  implement = "old-implement.md"
+`
+
+func writeConfig(t *testing.T, dir, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, "autopr.toml")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	return path
+}
+
+func TestExportImportRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	src := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(src, "prompts"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(src, "prompts", "plan.md"), []byte("Plan {{title}} carefully.\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := config.Load(writeConfig(t, src, sourceConfig))
+	if err != nil {
+		t.Fatalf("load source: %v", err)
+	}
+	proj, _ := cfg.ProjectByName("billing")
+	exported, err := Export(cfg, proj)
+	if err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := Encode(&buf, exported); err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	b, err := Decode(&buf)
+	if err != nil {
+		t.Fatalf("decode: %v\n%s", err, buf.String())
+	}
+	if b.Prompts["plan"] != "Plan {{title}} carefully.\n" || len(b.Templates) != 1 || b.Gates.LintCmd != "go vet ./..." {
+		t.Fatalf("unexpected bundle %+v", b)
+	}
+
+	for _, project := range []string{"web", "api"} {
+		dst := t.TempDir()
+		path := writeConfig(t, dst, targetConfig)
+		b.Templates[0].Name = "add-endpoint-" + project
+		if _, err := Import(path, b, project, false); err == nil || !strings.Contains(err.Error(), `lint_cmd = "go vet ./..."`) {
+			t.Fatalf("%s: expected the bundle's commands to need allowing, got %v", project, err)
+		}
+		if data, _ := os.ReadFile(path); string(data) != targetConfig {
+			t.Fatalf("%s: config changed by a refused import:\n%s", project, data)
+		}
+		res, err := Import(path, b, project, true)
+		if err != nil {
+			data, _ := os.ReadFile(path)
+			t.Fatalf("import into %s: %v\n%s", project, err, data)
+		}
+		if !res.Gates || !slices.Equal(res.PromptFiles, []string{"prompts/" + project + "/plan.md"}) || !slices.Equal(res.Templates, []string{"add-endpoint-" + project}) {
+			t.Fatalf("unexpected result %+v", res)
+		}
+		got, err := config.Load(path)
+		if err != nil {
+			t.Fatalf("load imported: %v", err)
+		}
+		p, _ := got.ProjectByName(project)
+		if p.TestCmd != "make test" || p.LintCmd != "go vet ./..." {
+			t.Fatalf("%s: gates not imported: test_cmd %q, lint_cmd %q", project, p.TestCmd, p.LintCmd)
+		}
+		if data, err := os.ReadFile(p.Prompts.Plan); err != nil || string(data) != "Plan {{title}} carefully.\n" {
+			t.Fatalf("%s: plan prompt %q, %v", project, data, err)
+		}
+		tmpl, ok := got.TemplateByName("add-endpoint-" + project)
+		if !ok || tmpl.Project != project || tmpl.Prompt != "Add a {{name}} handler.\nRegister it in the router.\n" {
+			t.Fatalf("%s: unexpected template %+v", project, tmpl)
+		}
+		data, _ := os.ReadFile(path)
+		if !strings.HasPrefix(string(data), "# team config\n") || !strings.Contains(string(data), "# Sync issue comments.") {
+			t.Fatalf("%s: comments lost:\n%s", project, data)
+		}
+		if project == "api" {
+			if p.Prompts.Implement == "" || !slices.Equal(p.TestShards, []string{"go test ./a/...", "go test ./b/..."}) {
+				t.Fatalf("api: untouched settings changed: %+v, %v", p.Prompts, p.TestShards)
+			}
+			if other, _ := got.ProjectByName("web"); other.TestCmd != "npm test" {
+				t.Fatalf("web changed by an import into api: %q", other.TestCmd)
+			}
+		}
+	}
+}
+
+func TestImportRejectsTakenTemplateNames(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := writeConfig(t, dir, targetConfig+`
+[[templates]]
+name = "bump"
+project = "web"
+title = "Bump"
+prompt = "Bump deps."
+`)
+	before, _ := os.ReadFile(path)
+	b := &Bundle{Version: Version, Templates: []Template{{Name: "bump", Title: "t", Prompt: "p"}}}
+	if _, err := Import(path, b, "api", false); err == nil || !strings.Contains(err.Error(), `template "bump" already exists`) {
+		t.Fatalf("expected a taken name error, got %v", err)
+	}
+	if _, err := Import(path, b, "nope", false); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Fatalf("expected an unknown project error, got %v", err)
+	}
+	after, _ := os.ReadFile(path)
+	if !bytes.Equal(before, after) {
+		t.Fatalf("config changed by a failed import")
+	}
+}
+
+func TestDecodeRejectsUnknownKeysAndVersions(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct{ bundle, want string }{
+		{"bundle_version = 2\n", "unsupported bundle_version 2"},
+		{"bundle_version = 1\nextra = 1\n", `unknown key "extra"`},
+		{"bundle_version = 1\n[prompts]\nreview = \"x\"\n", `unknown step "review"`},
+	} {
+		if _, err := Decode(strings.NewReader(tt.bundle)); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Decode(%q) = %v, want %q", tt.bundle, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,262 @@
+package bundle
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// doc is a config file edited line by line, so the comments and layout of
+// the parts Import does not touch are kept.
+type doc struct {
+	lines []string
+}
+
+// header is a table header line of a doc.
+type header struct {
+	line  int
+	path  string // e.g. "projects.prompts"
+	array bool   // [[...]]
+}
+
+var (
+	headerLine = regexp.MustCompile(`^\s*(\[\[|\[)\s*([A-Za-z0-9_.\- ]+?)\s*\]\]?\s*(#.*)?$`)
+	keyLine    = regexp.MustCompile(`^(\s*)([A-Za-z0-9_-]+)\s*=`)
+)
+
+func parseDoc(s string) *doc {
+	return &doc{lines: strings.Split(strings.TrimRight(s, "\n"), "\n")}
+}
+
+func (d *doc) String() string {
+	return strings.Join(d.lines, "\n") + "\n"
+}
+
+// headers returns the table headers of d, skipping lines inside multi-line
+// strings.
+func (d *doc) headers() []header {
+	var hs []header
+	open := ""
+	for i, line := range d.lines {
+		if open == "" {
+			if m := headerLine.FindStringSubmatch(line); m != nil {
+				hs = append(hs, header{line: i, path: strings.ReplaceAll(m[2], " ", ""), array: m[1] == "[["})
+				continue
+			}
+		}
+		open = multilineState(line, open)
+	}
+	return hs
+}
+
+// span returns the lines of the [[projects]] table called name: its header
+// and the end of its last subtable (exclusive).
+func (d *doc) span(name string) (start, end int, ok bool) {
+	hs := d.headers()
+	for i, h := range hs {
+		if !h.array || h.path != "projects" {
+			continue
+		}
+		end := len(d.lines)
+		top := end
+		for _, next := range hs[i+1:] {
+			if top == len(d.lines) {
+				top = next.line
+			}
+			if (next.array && next.path == "projects") || !strings.HasPrefix(next.path, "projects.") {
+				end = next.line
+				break
+			}
+		}
+		top = min(top, end)
+		for _, line := range d.lines[h.line+1 : top] {
+			var v struct {
+				Name string `toml:"name"`
+			}
+			if m := keyLine.FindStringSubmatch(line); m != nil && m[2] == "name" {
+				if _, err := toml.Decode(line, &v); err == nil && v.Name == name {
+					return h.line, end, true
+				}
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+// set sets key to value in the table of the named project, or in its
+// subtable [projects.<table>] when table is set, adding the subtable or the
+// key where missing.
+func (d *doc) set(project, table, key string, value any) {
+	start, end, ok := d.span(project)
+	if !ok {
+		return
+	}
+	// The region of the table: from its header to the next header.
+	from, to, indent := start+1, end, ""
+	found := table == ""
+	for _, h := range d.headers() {
+		if h.line <= start || h.line >= end {
+			continue
+		}
+		if found {
+			to = h.line
+			break
+		}
+		if !h.array && h.path == "projects."+table {
+			from, found = h.line+1, true
+			indent = leadingSpace(d.lines[h.line])
+		}
+	}
+	if !found {
+		at := lastContent(d.lines, start, end) + 1
+		block := []string{"", "  [projects." + table + "]", encodeKey("  ", key, value)}
+		d.lines = insert(d.lines, at, block...)
+		return
+	}
+
+	for i := from; i < to; i++ {
+		m := keyLine.FindStringSubmatch(d.lines[i])
+		if m == nil || m[2] != key {
+			continue
+		}
+		n := valueLines(d.lines[i:to])
+		d.lines = append(d.lines[:i], append([]string{encodeKey(m[1], key, value)}, d.lines[i+n:]...)...)
+		return
+	}
+	at := from
+	if last := lastKey(d.lines, from, to); last >= 0 {
+		at = last + valueLines(d.lines[last:to])
+		indent = leadingSpace(d.lines[last])
+	}
+	d.lines = insert(d.lines, at, encodeKey(indent, key, value))
+}
+
+// lastContent returns the last line in [from, to) that is not blank or a
+// comment.
+func lastContent(lines []string, from, to int) int {
+	for i := to - 1; i > from; i-- {
+		if t := strings.TrimSpace(lines[i]); t != "" && !strings.HasPrefix(t, "#") {
+			return i
+		}
+	}
+	return from
+}
+
+// lastKey returns the line of the last key in [from, to), or -1.
+func lastKey(lines []string, from, to int) int {
+	last := -1
+	open := ""
+	for i := from; i < to; i++ {
+		if open == "" && keyLine.MatchString(lines[i]) {
+			last = i
+		}
+		open = multilineState(lines[i], open)
+	}
+	return last
+}
+
+// valueLines returns how many lines the key/value pair starting lines
+// takes: more than one for multi-line strings and arrays.
+func valueLines(lines []string) int {
+	open, depth := "", 0
+	for i, line := range lines {
+		if open == "" {
+			depth += bracketDepth(line)
+		}
+		open = multilineState(line, open)
+		if open == "" && depth <= 0 {
+			return i + 1
+		}
+	}
+	return len(lines)
+}
+
+// bracketDepth returns how many more [ than ] line has outside strings and
+// comments.
+func bracketDepth(line string) int {
+	depth := 0
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#':
+			return depth
+		case c == '[':
+			depth++
+		case c == ']':
+			depth--
+		}
+	}
+	return depth
+}
+
+// multilineState returns the multi-line string delimiter still open at the
+// end of line, given the one open at its start ("" for none).
+func multilineState(line, open string) string {
+	for {
+		if open != "" {
+			i := strings.Index(line, open)
+			if i < 0 {
+				return open
+			}
+			line, open = line[i+3:], ""
+			continue
+		}
+		i, delim := -1, ""
+		for _, d := range []string{`"""`, `'''`} {
+			if j := strings.Index(line, d); j >= 0 && (i < 0 || j < i) {
+				i, delim = j, d
+			}
+		}
+		if i < 0 {
+			return ""
+		}
+		line, open = line[i+3:], delim
+	}
+}
+
+// encodeKey formats key = value as TOML. Multi-line text is written as a
+// literal multi-line string where it can be, so prompts stay readable.
+func encodeKey(indent, key string, value any) string {
+	if s, ok := value.(string); ok && strings.Contains(s, "\n") && !strings.Contains(s, "'''") && printable(s) {
+		return indent + key + " = '''\n" + s + "'''"
+	}
+	var buf bytes.Buffer
+	_ = toml.NewEncoder(&buf).Encode(map[string]any{key: value})
+	return indent + strings.TrimRight(buf.String(), "\n")
+}
+
+// writeKey writes key = value, as encodeKey formats it, and a newline.
+func writeKey(buf *bytes.Buffer, indent, key string, value any) {
+	buf.WriteString(encodeKey(indent, key, value))
+	buf.WriteString("\n")
+}
+
+// printable reports whether s can go in a TOML literal string: no control
+// characters other than tab and newline.
+func printable(s string) bool {
+	for _, r := range s {
+		if (r < 0x20 && r != '\t' && r != '\n') || r == 0x7f {
+			return false
+		}
+	}
+	return true
+}
+
+func leadingSpace(s string) string {
+	return s[:len(s)-len(strings.TrimLeft(s, " \t"))]
+}
+
+func insert(lines []string, at int, add ...string) []string {
+	return append(lines[:at], append(add, lines[at:]...)...)
+}
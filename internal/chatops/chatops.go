@@ -0,0 +1,195 @@
+// Package chatops answers the /autopr slash command from Slack and
+// Mattermost, so people who live in chat can check on jobs and approve,
+// reject, or retry them the way they would in the TUI.
+package chatops
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"autopr/internal/config"
+	"autopr/internal/db"
+)
+
+const maxBodySize = 64 << 10
+
+// maxRequestAge is how old a signed Slack request may be, against replays.
+const maxRequestAge = 5 * time.Minute
+
+// actionTimeout bounds an action that runs after the command is answered,
+// such as pushing a branch and opening a PR.
+const actionTimeout = 10 * time.Minute
+
+// Handler serves the slash command endpoints.
+type Handler struct {
+	cfg    *config.Config
+	store  *db.Store
+	client *http.Client
+	now    func() time.Time
+}
+
+func New(cfg *config.Config, store *db.Store) *Handler {
+	return &Handler{cfg: cfg, store: store, client: &http.Client{Timeout: 30 * time.Second}, now: time.Now}
+}
+
+// command is a slash command invocation.
+type command struct {
+	UserID      string
+	UserName    string
+	Text        string
+	ResponseURL string
+}
+
+// reply is the message a slash command responds with. Ephemeral replies are
+// shown only to the user who ran the command.
+type reply struct {
+	ResponseType string `json:"response_type"`
+	Text         string `json:"text"`
+}
+
+func ephemeral(format string, args ...any) reply {
+	return reply{ResponseType: "ephemeral", Text: fmt.Sprintf(format, args...)}
+}
+
+func inChannel(format string, args ...any) reply {
+	return reply{ResponseType: "in_channel", Text: fmt.Sprintf(format, args...)}
+}
+
+// ServeSlack handles POST /chatops/slack. Requests are verified with the
+// app's signing secret.
+func (h *Handler) ServeSlack(w http.ResponseWriter, r *http.Request) {
+	secret := h.cfg.ChatOps.SlackSigningSecret
+	if secret == "" {
+		http.Error(w, "slack commands not configured", http.StatusNotFound)
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxBodySize))
+	if err != nil {
+		http.Error(w, "read body", http.StatusBadRequest)
+		return
+	}
+	if err := verifySlackSignature(secret, r.Header, body, h.now()); err != nil {
+		slog.Warn("chatops: reject slack request", "err", err)
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		http.Error(w, "invalid form", http.StatusBadRequest)
+		return
+	}
+	h.serve(w, r.Context(), commandFromForm(form))
+}
+
+// ServeMattermost handles POST /chatops/mattermost. Requests must carry the
+// slash command's token.
+func (h *Handler) ServeMattermost(w http.ResponseWriter, r *http.Request) {
+	want := h.cfg.ChatOps.MattermostToken
+	if want == "" {
+		http.Error(w, "mattermost commands not configured", http.StatusNotFound)
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, maxBodySize)
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "invalid form", http.StatusBadRequest)
+		return
+	}
+	token := r.PostForm.Get("token")
+	if token == "" {
+		token = strings.TrimPrefix(r.Header.Get("Authorization"), "Token ")
+	}
+	if subtle.ConstantTimeCompare([]byte(token), []byte(want)) != 1 {
+		slog.Warn("chatops: reject mattermost request: bad token")
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	h.serve(w, r.Context(), commandFromForm(r.PostForm))
+}
+
+func commandFromForm(form url.Values) command {
+	return command{
+		UserID:      form.Get("user_id"),
+		UserName:    form.Get("user_name"),
+		Text:        strings.TrimSpace(form.Get("text")),
+		ResponseURL: form.Get("response_url"),
+	}
+}
+
+// verifySlackSignature checks Slack's v0 request signature: an HMAC-SHA256
+// of "v0:<timestamp>:<body>" keyed with the signing secret.
+func verifySlackSignature(secret string, header http.Header, body []byte, now time.Time) error {
+	ts := header.Get("X-Slack-Request-Timestamp")
+	sec, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid timestamp %q", ts)
+	}
+	if age := now.Sub(time.Unix(sec, 0)); age > maxRequestAge || age < -maxRequestAge {
+		return fmt.Errorf("timestamp %s is too far from now", ts)
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "v0:%s:", ts)
+	mac.Write(body)
+	want := "v0=" + hex.EncodeToString(mac.Sum(nil))
+	if !hmac.Equal([]byte(header.Get("X-Slack-Signature")), []byte(want)) {
+		return fmt.Errorf("signature mismatch")
+	}
+	return nil
+}
+
+func (h *Handler) serve(w http.ResponseWriter, ctx context.Context, cmd command) {
+	slog.Info("chatops command", "user", cmd.UserName, "user_id", cmd.UserID, "text", cmd.Text)
+	rep, async := h.run(ctx, cmd)
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(rep)
+	if async == nil {
+		return
+	}
+	// Chat platforms give up on a command after a few seconds, so slow actions
+	// answer at once and post their outcome to the response URL.
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), actionTimeout)
+		defer cancel()
+		h.respond(ctx, cmd.ResponseURL, async(ctx))
+	}()
+}
+
+// respond posts a follow-up message to a command's response URL.
+func (h *Handler) respond(ctx context.Context, responseURL string, rep reply) {
+	if responseURL == "" {
+		slog.Info("chatops: no response url for follow-up", "text", rep.Text)
+		return
+	}
+	body, err := json.Marshal(rep)
+	if err != nil {
+		slog.Warn("chatops: encode follow-up", "err", err)
+		return
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, responseURL, bytes.NewReader(body))
+	if err != nil {
+		slog.Warn("chatops: build follow-up request", "err", err)
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := h.client.Do(req)
+	if err != nil {
+		slog.Warn("chatops: post follow-up", "err", err)
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		slog.Warn("chatops: post follow-up", "status", resp.StatusCode)
+	}
+}
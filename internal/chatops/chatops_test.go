@@ -0,0 +1,275 @@
+package chatops
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"autopr/internal/config"
+	"autopr/internal/db"
+)
+
+const testSecret = "signing-secret"
+
+func newTestHandler(t *testing.T) (*Handler, *db.Store) {
+	t.Helper()
+	store, err := db.Open(filepath.Join(t.TempDir(), "autopr.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	cfg := &config.Config{
+		Projects: []config.ProjectConfig{{Name: "api", BaseBranch: "main"}},
+		ChatOps: config.ChatOpsConfig{
+			SlackSigningSecret: testSecret,
+			MattermostToken:    "mm-token",
+			Approvers:          []string{"U1", "U8"},
+		},
+	}
+	return New(cfg, store), store
+}
+
+// createJob creates a job for a new eligible issue and puts it in state.
+func createJob(t *testing.T, store *db.Store, sourceID, title, state string) string {
+	t.Helper()
+	ctx := context.Background()
+	eligible := true
+	issueID, err := store.UpsertIssue(ctx, db.IssueUpsert{
+		ProjectName:   "api",
+		Source:        "github",
+		SourceIssueID: sourceID,
+		Title:         title,
+		State:         "open",
+		Eligible:      &eligible,
+	})
+	if err != nil {
+		t.Fatalf("upsert issue: %v", err)
+	}
+	jobID, err := store.CreateJob(ctx, issueID, "api", 3)
+	if err != nil {
+		t.Fatalf("create job: %v", err)
+	}
+	if _, err := store.Writer.ExecContext(ctx, `UPDATE jobs SET state = ? WHERE id = ?`, state, jobID); err != nil {
+		t.Fatalf("set job state: %v", err)
+	}
+	return jobID
+}
+
+func signedSlackRequest(t *testing.T, form url.Values, ts time.Time) *http.Request {
+	t.Helper()
+	body := form.Encode()
+	stamp := strconv.FormatInt(ts.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(testSecret))
+	fmt.Fprintf(mac, "v0:%s:%s", stamp, body)
+	req := httptest.NewRequest(http.MethodPost, "/chatops/slack", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("X-Slack-Request-Timestamp", stamp)
+	req.Header.Set("X-Slack-Signature", "v0="+hex.EncodeToString(mac.Sum(nil)))
+	return req
+}
+
+func slackCommand(t *testing.T, h *Handler, userID, userName, text string) (int, reply) {
+	t.Helper()
+	form := url.Values{"user_id": {userID}, "user_name": {userName}, "text": {text}, "command": {"/autopr"}}
+	rec := httptest.NewRecorder()
+	h.ServeSlack(rec, signedSlackRequest(t, form, time.Now()))
+	var rep reply
+	if rec.Code == http.StatusOK {
+		if err := json.Unmarshal(rec.Body.Bytes(), &rep); err != nil {
+			t.Fatalf("decode reply %q: %v", rec.Body.String(), err)
+		}
+	}
+	return rec.Code, rep
+}
+
+func TestServeSlackVerifiesSignature(t *testing.T) {
+	t.Parallel()
+	h, _ := newTestHandler(t)
+	form := url.Values{"user_id": {"U1"}, "text": {"status"}}
+
+	rec := httptest.NewRecorder()
+	h.ServeSlack(rec, signedSlackRequest(t, form, time.Now().Add(-10*time.Minute)))
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("stale request: got status %d", rec.Code)
+	}
+
+	req := signedSlackRequest(t, form, time.Now())
+	req.Header.Set("X-Slack-Signature", "v0=deadbeef")
+	rec = httptest.NewRecorder()
+	h.ServeSlack(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("bad signature: got status %d", rec.Code)
+	}
+
+	if code, rep := slackCommand(t, h, "U1", "alice", "status"); code != http.StatusOK || !strings.HasPrefix(rep.Text, "AutoPR: 0 waiting for approval") {
+		t.Fatalf("signed request: got %d %+v", code, rep)
+	}
+}
+
+func TestServeMattermostChecksToken(t *testing.T) {
+	t.Parallel()
+	h, _ := newTestHandler(t)
+	post := func(token string) *httptest.ResponseRecorder {
+		form := url.Values{"token": {token}, "user_id": {"u"}, "user_name": {"dave"}, "text": {"help"}}
+		req := httptest.NewRequest(http.MethodPost, "/chatops/mattermost", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rec := httptest.NewRecorder()
+		h.ServeMattermost(rec, req)
+		return rec
+	}
+	if rec := post("wrong"); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("bad token: got status %d", rec.Code)
+	}
+	if rec := post("mm-token"); rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "Usage") {
+		t.Fatalf("good token: got %d %s", rec.Code, rec.Body.String())
+	}
+
+	h.cfg.ChatOps.MattermostToken = ""
+	if rec := post("mm-token"); rec.Code != http.StatusNotFound {
+		t.Fatalf("unconfigured: got status %d", rec.Code)
+	}
+}
+
+func TestCommandsCheckPermissions(t *testing.T) {
+	t.Parallel()
+	h, store := newTestHandler(t)
+	ready := createJob(t, store, "1", "Fix login redirect", "ready")
+
+	_, rep := slackCommand(t, h, "U9", "mallory", "reject "+db.ShortID(ready))
+	if rep.ResponseType != "ephemeral" || !strings.Contains(rep.Text, "not allowed") {
+		t.Fatalf("non-approver reject: %+v", rep)
+	}
+	job, err := store.GetJob(context.Background(), ready)
+	if err != nil {
+		t.Fatalf("get job: %v", err)
+	}
+	if job.State != "ready" {
+		t.Fatalf("job changed by a non-approver: %s", job.State)
+	}
+
+	_, rep = slackCommand(t, h, "U9", "mallory", "status")
+	if !strings.Contains(rep.Text, "1 waiting for approval") || !strings.Contains(rep.Text, db.ShortID(ready)) {
+		t.Fatalf("status: %+v", rep)
+	}
+
+	h.cfg.ChatOps.Viewers = []string{"U2"}
+	if _, rep = slackCommand(t, h, "U9", "mallory", "status"); !strings.Contains(rep.Text, "not allowed") {
+		t.Fatalf("status with viewers set: %+v", rep)
+	}
+	if _, rep = slackCommand(t, h, "U8", "carol", "job "+db.ShortID(ready)); !strings.Contains(rep.Text, "Fix login redirect") {
+		t.Fatalf("approver should view: %+v", rep)
+	}
+	// A user who renames themselves to an approver's name is not one.
+	if _, rep = slackCommand(t, h, "U9", "carol", "reject "+db.ShortID(ready)); !strings.Contains(rep.Text, "not allowed") {
+		t.Fatalf("approve permission matched by name: %+v", rep)
+	}
+}
+
+func TestRejectAndRetryCommands(t *testing.T) {
+	t.Parallel()
+	h, store := newTestHandler(t)
+	ctx := context.Background()
+	jobID := createJob(t, store, "1", "Fix login redirect", "ready")
+
+	_, rep := slackCommand(t, h, "U1", "alice", "reject "+db.ShortID(jobID)+" wrong approach")
+	if rep.ResponseType != "in_channel" || !strings.Contains(rep.Text, "@alice rejected") {
+		t.Fatalf("reject: %+v", rep)
+	}
+	job, err := store.GetJob(ctx, jobID)
+	if err != nil {
+		t.Fatalf("get job: %v", err)
+	}
+	if job.State != "rejected" || job.RejectReason != "wrong approach" {
+		t.Fatalf("after reject: state %q reason %q", job.State, job.RejectReason)
+	}
+
+	_, rep = slackCommand(t, h, "U1", "alice", "approve "+db.ShortID(jobID))
+	if !strings.Contains(rep.Text, "only ready jobs") {
+		t.Fatalf("approve of a rejected job: %+v", rep)
+	}
+
+	_, rep = slackCommand(t, h, "U1", "alice", `retry `+db.ShortID(jobID)+` “keep the redirect, fix the cookie”`)
+	if rep.ResponseType != "in_channel" || !strings.Contains(rep.Text, "queued") {
+		t.Fatalf("retry: %+v", rep)
+	}
+	job, err = store.GetJob(ctx, jobID)
+	if err != nil {
+		t.Fatalf("get job: %v", err)
+	}
+	if job.State != "queued" || job.HumanNotes != "keep the redirect, fix the cookie" {
+		t.Fatalf("after retry: state %q notes %q", job.State, job.HumanNotes)
+	}
+}
+
+func TestApprovePostsOutcomeToResponseURL(t *testing.T) {
+	t.Parallel()
+	h, store := newTestHandler(t)
+	jobID := createJob(t, store, "1", "Fix login redirect", "ready")
+
+	posted := make(chan reply, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var rep reply
+		_ = json.NewDecoder(r.Body).Decode(&rep)
+		posted <- rep
+	}))
+	defer srv.Close()
+
+	form := url.Values{"user_id": {"U1"}, "user_name": {"alice"}, "text": {"approve " + db.ShortID(jobID)}, "response_url": {srv.URL}}
+	rec := httptest.NewRecorder()
+	h.ServeSlack(rec, signedSlackRequest(t, form, time.Now()))
+	if !strings.Contains(rec.Body.String(), "Approving") {
+		t.Fatalf("expected an acknowledgement, got %s", rec.Body.String())
+	}
+
+	// The job has no worktree, so the push fails and the failure is posted.
+	select {
+	case rep := <-posted:
+		if rep.ResponseType != "in_channel" || !strings.Contains(rep.Text, "failed") {
+			t.Fatalf("unexpected follow-up %+v", rep)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("no follow-up posted")
+	}
+}
+
+func TestConcurrentApprovesDoNotShareALease(t *testing.T) {
+	t.Parallel()
+	h, store := newTestHandler(t)
+	ctx := context.Background()
+	jobID := createJob(t, store, "1", "Fix login redirect", "ready")
+
+	cmd := command{UserID: "U1", UserName: "alice"}
+	if err := store.AcquireJobLease(ctx, jobID, commandLeaseHolder(cmd), db.JobLeaseTTL); err != nil {
+		t.Fatalf("first approve lease: %v", err)
+	}
+	// The same user approving again while the first push runs is refused.
+	_, act := h.approve(ctx, cmd, db.ShortID(jobID), false)
+	if act == nil {
+		t.Fatal("expected an approve action")
+	}
+	if rep := act(ctx); !strings.Contains(rep.Text, "is busy") {
+		t.Fatalf("second approve: %+v", rep)
+	}
+}
+
+func TestSplitArgs(t *testing.T) {
+	t.Parallel()
+	args, err := splitArgs(`retry 2dad  "use the v2 API" now`)
+	if err != nil || strings.Join(args, "|") != "retry|2dad|use the v2 API|now" {
+		t.Fatalf("splitArgs = %q, %v", args, err)
+	}
+	if _, err := splitArgs(`retry 2dad "open`); err == nil {
+		t.Fatal("expected an unterminated quote error")
+	}
+}
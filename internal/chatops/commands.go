@@ -0,0 +1,284 @@
+package chatops
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"autopr/internal/db"
+	"autopr/internal/issuepolicy"
+	"autopr/internal/pipeline"
+)
+
+// statusListLimit caps the jobs listed per section of /autopr status.
+const statusListLimit = 10
+
+const helpText = "Usage:\n" +
+	"`/autopr status` - queue depth, jobs waiting for approval, and running jobs\n" +
+	"`/autopr job <id>` - one job's state, issue, PR, and error\n" +
+	"`/autopr approve <id> [draft]` - push the job's branch and open its PR\n" +
+	"`/autopr reject <id> [reason]` - reject a job waiting for approval\n" +
+	"`/autopr retry <id> [\"notes\"]` - queue a failed, rejected, or cancelled job again\n" +
+	"Job IDs may be any unique prefix."
+
+// action is the part of a command that runs after the command is answered.
+type action func(ctx context.Context) reply
+
+// run executes a command. A slow command returns an acknowledgement and the
+// action that produces its outcome.
+func (h *Handler) run(ctx context.Context, cmd command) (reply, action) {
+	args, err := splitArgs(cmd.Text)
+	if err != nil {
+		return ephemeral("%v\n%s", err, helpText), nil
+	}
+	if len(args) == 0 || args[0] == "help" {
+		return ephemeral("%s", helpText), nil
+	}
+	name, args := strings.ToLower(args[0]), args[1:]
+
+	switch name {
+	case "status", "job":
+		if !h.cfg.ChatOps.CanView(cmd.UserID, cmd.UserName) {
+			return ephemeral("You are not allowed to view AutoPR jobs."), nil
+		}
+	case "approve", "reject", "retry":
+		if !h.cfg.ChatOps.CanApprove(cmd.UserID) {
+			slog.Warn("chatops: command denied", "user", cmd.UserName, "user_id", cmd.UserID, "command", name)
+			return ephemeral("You are not allowed to %s AutoPR jobs. Ask an admin to add you to chatops.approvers.", name), nil
+		}
+		if len(args) == 0 {
+			return ephemeral("Usage: `/autopr %s <job-id>`", name), nil
+		}
+	default:
+		return ephemeral("Unknown command %q.\n%s", name, helpText), nil
+	}
+
+	switch name {
+	case "status":
+		return h.status(ctx), nil
+	case "job":
+		if len(args) == 0 {
+			return ephemeral("Usage: `/autopr job <job-id>`"), nil
+		}
+		return h.job(ctx, args[0]), nil
+	case "approve":
+		return h.approve(ctx, cmd, args[0], len(args) > 1 && strings.EqualFold(args[1], "draft"))
+	case "reject":
+		return h.reject(ctx, cmd, args[0], strings.Join(args[1:], " ")), nil
+	default:
+		return h.retry(ctx, cmd, args[0], strings.Join(args[1:], " ")), nil
+	}
+}
+
+// splitArgs splits command text on spaces, keeping double-quoted strings
+// (including the curly quotes chat clients substitute) together.
+func splitArgs(text string) ([]string, error) {
+	var args []string
+	var cur strings.Builder
+	inQuote, inArg := false, false
+	for _, r := range text {
+		switch {
+		case r == '"' || r == '“' || r == '”':
+			inQuote = !inQuote
+			inArg = true
+		case !inQuote && (r == ' ' || r == '\t' || r == '\n'):
+			if inArg {
+				args = append(args, cur.String())
+				cur.Reset()
+				inArg = false
+			}
+		default:
+			cur.WriteRune(r)
+			inArg = true
+		}
+	}
+	if inQuote {
+		return nil, errors.New("unterminated quote")
+	}
+	if inArg {
+		args = append(args, cur.String())
+	}
+	return args, nil
+}
+
+func jobLine(j db.Job) string {
+	line := fmt.Sprintf("`%s` %s - %s - %s", db.ShortID(j.ID), j.State, j.ProjectName, j.Title())
+	if j.PRURL != "" {
+		line += " " + j.PRURL
+	}
+	return line
+}
+
+func (h *Handler) status(ctx context.Context) reply {
+	var b strings.Builder
+	var counts []string
+	for _, sec := range []struct{ title, state string }{
+		{"waiting for approval", "ready"},
+		{"running", "active"},
+		{"queued", "queued"},
+	} {
+		jobs, total, err := h.store.ListJobsPage(ctx, "", sec.state, "updated_at", false, 1, statusListLimit)
+		if err != nil {
+			return ephemeral("Could not list jobs: %v", err)
+		}
+		counts = append(counts, fmt.Sprintf("%d %s", total, sec.title))
+		if total == 0 || sec.state == "queued" {
+			continue
+		}
+		fmt.Fprintf(&b, "\n%s:\n", capitalize(sec.title))
+		for _, j := range jobs {
+			b.WriteString(jobLine(j) + "\n")
+		}
+		if total > len(jobs) {
+			fmt.Fprintf(&b, "... and %d more\n", total-len(jobs))
+		}
+	}
+	return ephemeral("AutoPR: %s\n%s", strings.Join(counts, ", "), strings.TrimRight(b.String(), "\n"))
+}
+
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+func (h *Handler) resolveJob(ctx context.Context, arg string) (db.Job, error) {
+	id, err := h.store.ResolveJobID(ctx, arg)
+	if err != nil {
+		return db.Job{}, err
+	}
+	job, err := h.store.GetJob(ctx, id)
+	if err != nil {
+		return db.Job{}, err
+	}
+	// GetJob leaves out the issue, which replies name the job by.
+	if issue, err := h.store.GetIssueByAPID(ctx, job.AutoPRIssueID); err == nil {
+		job.IssueSource, job.SourceIssueID, job.IssueTitle, job.IssueURL = issue.Source, issue.SourceIssueID, issue.Title, issue.URL
+	}
+	return job, nil
+}
+
+func (h *Handler) job(ctx context.Context, arg string) reply {
+	job, err := h.resolveJob(ctx, arg)
+	if err != nil {
+		return ephemeral("%v", err)
+	}
+	lines := []string{jobLine(job)}
+	if job.IssueURL != "" {
+		lines = append(lines, "Issue: "+job.IssueURL)
+	}
+	lines = append(lines, fmt.Sprintf("Iteration: %d/%d", job.Iteration, job.MaxIterations))
+	if level := job.RiskLevel(); level != "" {
+		lines = append(lines, fmt.Sprintf("Risk: %s %d/100 (%s)", level, job.RiskScore, job.RiskSummary))
+	}
+	if job.CIStatusSummary != "" {
+		lines = append(lines, "CI: "+job.CIStatusSummary)
+	}
+	if job.ErrorMessage != "" {
+		lines = append(lines, "Error: "+job.ErrorMessage)
+	}
+	if job.RejectReason != "" {
+		lines = append(lines, "Rejected: "+job.RejectReason)
+	}
+	return ephemeral("%s", strings.Join(lines, "\n"))
+}
+
+// actor names the chat user in replies and job records.
+func actor(cmd command) string {
+	if cmd.UserName != "" {
+		return "@" + cmd.UserName
+	}
+	return cmd.UserID
+}
+
+// commandLeaseHolder returns a job lease holder for one chat command.
+func commandLeaseHolder(cmd command) string {
+	buf := make([]byte, 4)
+	rand.Read(buf)
+	return db.LeaseHolder(fmt.Sprintf("chatops %s %s", cmd.UserID, hex.EncodeToString(buf)))
+}
+
+func (h *Handler) approve(ctx context.Context, cmd command, arg string, draft bool) (reply, action) {
+	job, err := h.resolveJob(ctx, arg)
+	if err != nil {
+		return ephemeral("%v", err), nil
+	}
+	if job.State != "ready" {
+		return ephemeral("Job `%s` is %s; only ready jobs can be approved.", db.ShortID(job.ID), job.State), nil
+	}
+	slog.Info("chatops approve", "job", job.ID, "user", actor(cmd), "draft", draft)
+	return ephemeral("Approving `%s`: pushing the branch and opening the PR...", db.ShortID(job.ID)), func(ctx context.Context) reply {
+		// Chat shows no diff, so unlike the TUI the approval covers the
+		// branch as it is when pushed; it sets no ReviewedDiff.
+		//
+		// Hold the job's lease so workers cannot move the job while the
+		// branch is pushed and the PR opened. The holder is unique to this
+		// command, so a second approve of the job fails on the lease instead
+		// of entering it and pushing again.
+		var res pipeline.ApproveResult
+		err := h.store.WithJobLease(ctx, job.ID, commandLeaseHolder(cmd), func(ctx context.Context) error {
+			var err error
+			res, err = pipeline.Approve(ctx, h.cfg, h.store, job.ID, pipeline.Approval{
+				Approver: h.cfg.ChatOps.Identity(cmd.UserID, cmd.UserName),
+				Draft:    draft,
+			})
+			return err
+		})
+		if err != nil {
+			slog.Warn("chatops approve failed", "job", job.ID, "err", err)
+			return inChannel("Approving `%s` for %s failed: %v", db.ShortID(job.ID), actor(cmd), err)
+		}
+		msg := fmt.Sprintf("%s approved `%s` (%s).", actor(cmd), db.ShortID(job.ID), job.Title())
+		if res.PRURL != "" {
+			msg += " PR: " + res.PRURL
+		}
+		return inChannel("%s", msg)
+	}
+}
+
+func (h *Handler) reject(ctx context.Context, cmd command, arg, reason string) reply {
+	job, err := h.resolveJob(ctx, arg)
+	if err != nil {
+		return ephemeral("%v", err)
+	}
+	if job.State != "ready" {
+		return ephemeral("Job `%s` is %s; only ready jobs can be rejected.", db.ShortID(job.ID), job.State)
+	}
+	if reason == "" {
+		reason = "rejected in chat by " + actor(cmd)
+	}
+	if err := h.store.RejectJob(ctx, job.ID, "ready", reason); err != nil {
+		return ephemeral("Could not reject `%s`: %v", db.ShortID(job.ID), err)
+	}
+	slog.Info("chatops reject", "job", job.ID, "user", actor(cmd))
+	return inChannel("%s rejected `%s` (%s): %s", actor(cmd), db.ShortID(job.ID), job.Title(), reason)
+}
+
+func (h *Handler) retry(ctx context.Context, cmd command, arg, notes string) reply {
+	job, err := h.resolveJob(ctx, arg)
+	if err != nil {
+		return ephemeral("%v", err)
+	}
+	if job.State != "failed" && job.State != "rejected" && job.State != "cancelled" {
+		return ephemeral("Job `%s` is %s; only failed, rejected, or cancelled jobs can be retried.", db.ShortID(job.ID), job.State)
+	}
+	// Hold the issue first if it is past its project's issue policy, so the
+	// retry is refused with the reason.
+	if err := issuepolicy.New(h.cfg, h.store).ApplyIssue(ctx, job.AutoPRIssueID); err != nil {
+		return ephemeral("Could not retry `%s`: %v", db.ShortID(job.ID), err)
+	}
+	if err := h.store.ResetJobForRetry(ctx, job.ID, notes); err != nil {
+		return ephemeral("Could not retry `%s`: %v", db.ShortID(job.ID), err)
+	}
+	slog.Info("chatops retry", "job", job.ID, "user", actor(cmd))
+	msg := fmt.Sprintf("%s queued `%s` (%s) again.", actor(cmd), db.ShortID(job.ID), job.Title())
+	if notes != "" {
+		msg += " Notes: " + notes
+	}
+	return inChannel("%s", msg)
+}
@@ -5,24 +5,42 @@ import (
 	"fmt"
 	"io/fs"
 	"log/slog"
+	"maps"
+	"net"
 	"net/url"
 	"os"
+	"path"
 	"path/filepath"
+	"regexp"
 	"slices"
+	"strconv"
 	"strings"
 	"time"
 
+	"autopr/internal/cron"
+
 	"github.com/BurntSushi/toml"
 )
 
 const Version = "0.9.0"
 
+// LogStderr as log_file sends daemon logs to stderr instead of a file, for
+// containers whose runtime collects output.
+const LogStderr = "-"
+
 // Credentials holds tokens loaded from credentials.toml.
 type Credentials struct {
 	GitHubToken   string `toml:"github_token"`
 	GitLabToken   string `toml:"gitlab_token"`
+	GiteaToken    string `toml:"gitea_token"`
 	SentryToken   string `toml:"sentry_token"`
 	WebhookSecret string `toml:"webhook_secret"`
+	DBKey         string `toml:"db_key"`
+	// ChatOps slash-command credentials; see ChatOpsConfig.
+	SlackSigningSecret string `toml:"slack_signing_secret"`
+	MattermostToken    string `toml:"mattermost_token"`
+	// Secrets are the values project env entries refer to as "secret:<name>".
+	Secrets map[string]string `toml:"secrets"`
 }
 
 // LoadCredentials reads credentials.toml. Returns an empty Credentials if
@@ -84,40 +102,230 @@ type Config struct {
 
 	Daemon        DaemonConfig        `toml:"daemon"`
 	Tokens        TokensConfig        `toml:"tokens"`
+	GitHubApp     GitHubAppConfig     `toml:"github_app"`
+	Network       NetworkConfig       `toml:"network"`
 	Sentry        SentryConfig        `toml:"sentry"`
 	LLM           LLMConfig           `toml:"llm"`
 	Notifications NotificationsConfig `toml:"notifications"`
+	Update        UpdateConfig        `toml:"update"`
+	Tracing       TracingConfig       `toml:"tracing"`
+	Docker        DockerConfig        `toml:"docker"`
+	Kubernetes    KubernetesConfig    `toml:"kubernetes"`
+	Retention     RetentionConfig     `toml:"retention"`
+	TUI           TUIConfig           `toml:"tui"`
+	ChatOps       ChatOpsConfig       `toml:"chatops"`
 
 	Projects []ProjectConfig `toml:"projects"`
+	// Templates are routine tasks queued with `ap run --template`.
+	Templates []JobTemplate `toml:"templates"`
 
 	// Resolved at runtime (not in TOML).
 	BaseDir string `toml:"-"`
+	// DBKey encrypts the database with SQLCipher. It is read only from
+	// credentials.toml or AUTOPR_DB_KEY so it never sits next to the database
+	// in the config file.
+	DBKey string `toml:"-"`
+	// Secrets holds credentials.toml [secrets], for project env entries of
+	// the form "secret:<name>".
+	Secrets map[string]string `toml:"-"`
 }
 
 type DaemonConfig struct {
-	WebhookPort     int    `toml:"webhook_port"`
-	WebhookSecret   string `toml:"webhook_secret"`
-	MaxWorkers      int    `toml:"max_workers"`
-	MaxIterations   int    `toml:"max_iterations"`
-	SyncInterval    string `toml:"sync_interval"`
-	PIDFile         string `toml:"pid_file"`
-	AutoPR          bool   `toml:"auto_pr"`
+	WebhookPort   int    `toml:"webhook_port"`
+	WebhookBind   string `toml:"webhook_bind"` // listen address; loopback unless a container publishes the port
+	WebhookSecret string `toml:"webhook_secret"`
+	MaxWorkers    int    `toml:"max_workers"`
+	MaxIterations int    `toml:"max_iterations"`
+	SyncInterval  string `toml:"sync_interval"`
+	PIDFile       string `toml:"pid_file"`
+	AutoPR        bool   `toml:"auto_pr"`
+	// PRContext embeds the plan, review findings, and test results in a
+	// collapsed section of the PR body instead of the plan alone.
+	PRContext bool `toml:"pr_context"`
+	// PRCommands acts on /autopr comments from people with write access on
+	// open AutoPR PRs: feedback for another iteration, rebase, or close.
+	PRCommands      bool   `toml:"pr_commands"`
 	CICheckInterval string `toml:"ci_check_interval"`
 	CICheckTimeout  string `toml:"ci_check_timeout"`
+	// CIStaleAfter is how long CI may stay pending before the watchdog re-polls
+	// and sends a ci_stuck notification; "0" disables the watchdog.
+	CIStaleAfter string `toml:"ci_stale_after"`
+	// QueueStaleAfter is how long a job may stay queued before it is reported
+	// on the dashboard and a queue_stale notification is sent; "0" disables.
+	QueueStaleAfter string `toml:"queue_stale_after"`
+	// Scheduled database backups; an empty backup_interval disables them.
+	BackupInterval string `toml:"backup_interval"`
+	BackupKeep     int    `toml:"backup_keep"`
+	BackupDir      string `toml:"backup_dir"`
+	// Default diff size limits for projects that don't set their own; 0
+	// means unlimited.
+	MaxDiffFiles int `toml:"max_diff_files"`
+	MaxDiffLines int `toml:"max_diff_lines"`
+	// Issue sync runs up to SyncConcurrency projects at once and sends at
+	// most SyncHostRPS requests per second to any one API host.
+	SyncConcurrency int     `toml:"sync_concurrency"`
+	SyncHostRPS     float64 `toml:"sync_host_rps"`
+	// PRCheckInterval is how often open PRs are polled for merges and
+	// closes; it defaults to SyncInterval.
+	PRCheckInterval string `toml:"pr_check_interval"`
+	// Workers hold off claiming jobs while the 1-minute load average per
+	// CPU is above MaxLoadPerCPU or less than MinFreeMemoryMB of memory is
+	// available; 0 disables either limit.
+	MaxLoadPerCPU   float64 `toml:"max_load_per_cpu"`
+	MinFreeMemoryMB int     `toml:"min_free_memory_mb"`
+	// IdleAfter is how long the daemon may go without a queued or running
+	// job before it stops its workers and syncs every IdleSyncInterval
+	// instead; "0" disables idle mode.
+	IdleAfter        string `toml:"idle_after"`
+	IdleSyncInterval string `toml:"idle_sync_interval"`
 }
 
 type TokensConfig struct {
 	GitLab string `toml:"gitlab"`
 	GitHub string `toml:"github"`
+	Gitea  string `toml:"gitea"`
 	Sentry string `toml:"sentry"`
 }
 
+// GitHubAppConfig makes every GitHub project act as this GitHub App instead
+// of using the github token. The installation is looked up per repository
+// unless a project sets github.app_installation_id.
+type GitHubAppConfig struct {
+	AppID          int64  `toml:"app_id"`
+	PrivateKeyPath string `toml:"private_key_path"`
+}
+
+// NetworkConfig sets the outbound proxy and extra CA certificates used for
+// API calls, git, and LLM provider subprocesses. Empty fields fall back to
+// the HTTP_PROXY / HTTPS_PROXY / NO_PROXY environment variables.
+type NetworkConfig struct {
+	HTTPProxy  string `toml:"http_proxy"`
+	HTTPSProxy string `toml:"https_proxy"`
+	NoProxy    string `toml:"no_proxy"`
+	CABundle   string `toml:"ca_bundle"`
+}
+
+// UpdateConfig selects which releases `ap upgrade` installs: "stable" (the
+// default) or "beta", which also considers prereleases.
+type UpdateConfig struct {
+	Channel string `toml:"channel"`
+}
+
+// TracingConfig exports OpenTelemetry traces of daemon jobs over OTLP/HTTP.
+// An empty endpoint falls back to OTEL_EXPORTER_OTLP_ENDPOINT, then to
+// localhost:4318.
+type TracingConfig struct {
+	Enabled  bool   `toml:"enabled"`
+	Endpoint string `toml:"endpoint"` // host:port or http(s):// URL of the collector
+	Insecure bool   `toml:"insecure"` // plain HTTP for a host:port endpoint
+}
+
+// Executors run the commands of pipeline steps (LLM CLI, setup, tests,
+// regeneration). A project picks one with executor; the default is
+// kubernetes when [kubernetes] is enabled, else local.
+const (
+	ExecutorLocal      = "local"      // processes next to the daemon
+	ExecutorDocker     = "docker"     // `docker run` with repos_root bind-mounted
+	ExecutorKubernetes = "kubernetes" // Kubernetes Jobs on a shared volume
+	ExecutorSSH        = "ssh"        // a remote machine, with the worktree synced by rsync
+)
+
+// remoteDirPattern keeps ssh.remote_dir free of shell and rsync syntax.
+var remoteDirPattern = regexp.MustCompile(`^[A-Za-z0-9._/-]+$`)
+
+// DockerConfig configures the docker executor.
+type DockerConfig struct {
+	Image string `toml:"image"` // image with git, the LLM CLI, and project toolchains
+}
+
+// KubernetesConfig configures the kubernetes executor, which runs commands
+// as Kubernetes Jobs. The daemon and the pods share repos_root through
+// WorktreeClaim, mounted at the same path in both, so clones and caches stay
+// where the pipeline expects.
+type KubernetesConfig struct {
+	Enabled        bool   `toml:"enabled"`         // make kubernetes the default executor
+	Namespace      string `toml:"namespace"`       // default: the daemon's own namespace in-cluster, else "default"
+	Image          string `toml:"image"`           // worker image with git, the LLM CLI, and project toolchains
+	WorktreeClaim  string `toml:"worktree_claim"`  // PersistentVolumeClaim mounted at repos_root
+	ServiceAccount string `toml:"service_account"` // service account of the worker pods
+	SecretEnv      string `toml:"secret_env"`      // Secret whose keys become env vars in the pods (LLM API keys)
+	CPU            string `toml:"cpu"`             // e.g. "2"; request for each pod
+	Memory         string `toml:"memory"`          // e.g. "4Gi"; request and limit for each pod
+	StartTimeout   string `toml:"start_timeout"`   // how long a pod may stay pending; default "5m"
+	APIServer      string `toml:"api_server"`      // default: in-cluster from KUBERNETES_SERVICE_HOST
+	TokenFile      string `toml:"token_file"`      // default: the in-cluster service account token
+	CAFile         string `toml:"ca_file"`         // default: the in-cluster service account CA
+}
+
+// RetentionConfig bounds how long LLM prompt and response text is kept.
+// Once a finished session is older than SessionTextAfter, the daemon deletes
+// its text and transcript file and keeps only token counts, durations, and
+// hashes of the text. Jobs moved to the trash with `ap delete` are deleted
+// for good once they have been there for DeletedJobsAfter.
+type RetentionConfig struct {
+	SessionTextAfter string `toml:"session_text_after"` // e.g. "720h"; empty keeps text forever
+	DeletedJobsAfter string `toml:"deleted_jobs_after"` // default "720h"; "0" keeps trashed jobs until `ap trash empty`
+}
+
+// ChatOpsConfig enables the /autopr slash command on the webhook server, at
+// /chatops/slack and /chatops/mattermost. Each endpoint answers only once its
+// secret is set. Anyone who can run the command may use the read-only
+// subcommands unless Viewers is set; only Approvers may approve, reject, or
+// retry jobs. Viewers are matched by chat user ID or user name; Approvers by
+// user ID only, since users can change their own names.
+type ChatOpsConfig struct {
+	SlackSigningSecret string   `toml:"slack_signing_secret"` // Slack app signing secret
+	MattermostToken    string   `toml:"mattermost_token"`     // Mattermost slash command token
+	Viewers            []string `toml:"viewers"`
+	Approvers          []string `toml:"approvers"`
+	// Identities maps chat user IDs or names to "Name <email>", so
+	// Co-authored-by trailers can credit approvers (see ProjectTrailers).
+	Identities map[string]string `toml:"identities"`
+}
+
+// Identity returns the "Name <email>" configured for the chat user, or "".
+// A user ID entry wins over a user name entry.
+func (c ChatOpsConfig) Identity(userID, userName string) string {
+	if ident, ok := c.Identities[userID]; ok && userID != "" {
+		return ident
+	}
+	for u, ident := range c.Identities {
+		if userName != "" && strings.EqualFold(strings.TrimPrefix(u, "@"), userName) {
+			return ident
+		}
+	}
+	return ""
+}
+
+// CanView reports whether the chat user may run read-only commands.
+func (c ChatOpsConfig) CanView(userID, userName string) bool {
+	return len(c.Viewers) == 0 || chatUserListed(c.Viewers, userID, userName) || c.CanApprove(userID)
+}
+
+// CanApprove reports whether the chat user may run commands that change jobs.
+func (c ChatOpsConfig) CanApprove(userID string) bool {
+	return chatUserListed(c.Approvers, userID, "")
+}
+
+func chatUserListed(users []string, userID, userName string) bool {
+	for _, u := range users {
+		if (userID != "" && u == userID) || (userName != "" && strings.EqualFold(strings.TrimPrefix(u, "@"), userName)) {
+			return true
+		}
+	}
+	return false
+}
+
 type SentryConfig struct {
 	BaseURL string `toml:"base_url"`
 }
 
 type LLMConfig struct {
 	Provider string `toml:"provider"`
+	// SummarizeJobs runs a short LLM pass before planning that writes a
+	// one-line summary of the job, shown instead of the issue title in job
+	// lists and notifications.
+	SummarizeJobs bool `toml:"summarize_jobs"`
 }
 
 type NotificationsConfig struct {
@@ -128,10 +336,18 @@ type NotificationsConfig struct {
 }
 
 const (
-	TriggerNeedsPR   = "needs_pr"
-	TriggerFailed    = "failed"
-	TriggerPRCreated = "pr_created"
-	TriggerPRMerged  = "pr_merged"
+	TriggerNeedsPR    = "needs_pr"
+	TriggerFailed     = "failed"
+	TriggerPRCreated  = "pr_created"
+	TriggerPRMerged   = "pr_merged"
+	TriggerCIStuck    = "ci_stuck"
+	TriggerQueueStale = "queue_stale"
+	// TriggerDaemonRecovered fires when the daemon starts after an unclean
+	// shutdown, with a summary of what crash recovery reset.
+	TriggerDaemonRecovered = "daemon_recovered"
+	// TriggerDeadlineOverdue fires once when an unfinished job passes its
+	// deadline, and again if the deadline is moved and passed again.
+	TriggerDeadlineOverdue = "deadline_overdue"
 
 	DefaultMaxAutoResolvableConflictLines = 20
 )
@@ -141,19 +357,95 @@ var defaultNotificationTriggers = []string{
 	TriggerFailed,
 	TriggerPRCreated,
 	TriggerPRMerged,
+	TriggerCIStuck,
+	TriggerQueueStale,
+	TriggerDaemonRecovered,
+	TriggerDeadlineOverdue,
 }
 
 type ProjectConfig struct {
-	Name                           string          `toml:"name"`
-	RepoURL                        string          `toml:"repo_url"`
-	TestCmd                        string          `toml:"test_cmd"`
-	BaseBranch                     string          `toml:"base_branch"`
-	MaxAutoResolvableConflictLines int             `toml:"max_auto_resolvable_conflict_lines"`
-	ExcludeLabels                  []string        `toml:"exclude_labels"`
-	GitLab                         *ProjectGitLab  `toml:"gitlab"`
-	GitHub                         *ProjectGitHub  `toml:"github"`
-	Sentry                         *ProjectSentry  `toml:"sentry"`
-	Prompts                        *ProjectPrompts `toml:"prompts"`
+	Name                           string                 `toml:"name"`
+	Enabled                        *bool                  `toml:"enabled"` // nil means enabled
+	RepoURL                        string                 `toml:"repo_url"`
+	TestCmd                        string                 `toml:"test_cmd"`          // or AutoDetect
+	LintCmd                        string                 `toml:"lint_cmd"`          // run after the tests pass; or AutoDetect; "" for none
+	SetupCmd                       string                 `toml:"setup_cmd"`         // run once in each new job worktree, before planning
+	TestShards                     []string               `toml:"test_shards"`       // run concurrently in place of test_cmd in the testing step
+	RetryFlakyTests                bool                   `toml:"retry_flaky_tests"` // re-run failing tests once; a pass records them as flaky
+	Executor                       string                 `toml:"executor"`          // local, docker, kubernetes, or ssh; see ExecutorLocal
+	ExecutorImage                  string                 `toml:"executor_image"`    // overrides [docker] or [kubernetes] image for this project
+	BaseBranch                     string                 `toml:"base_branch"`
+	BranchTemplate                 string                 `toml:"branch_template"` // job branch names, e.g. "{user}/autopr/{issue-id}-{slug}"; see BranchPlaceholders
+	MaxAutoResolvableConflictLines int                    `toml:"max_auto_resolvable_conflict_lines"`
+	MaxDiffFiles                   int                    `toml:"max_diff_files"` // 0 means [daemon] default, then unlimited
+	MaxDiffLines                   int                    `toml:"max_diff_lines"` // 0 means [daemon] default, then unlimited
+	QueueWeight                    int                    `toml:"queue_weight"`   // share of job claims relative to other projects; 0 means 1
+	ExcludeLabels                  []string               `toml:"exclude_labels"`
+	CriticalPaths                  []string               `toml:"critical_paths"` // raise a job's risk score when its diff touches these; see IsCritical
+	ContextFiles                   []string               `toml:"context_files"`  // docs added to plan and implement prompts, besides .autopr/context.md
+	GitLab                         *ProjectGitLab         `toml:"gitlab"`
+	GitHub                         *ProjectGitHub         `toml:"github"`
+	Gitea                          *ProjectGitea          `toml:"gitea"`
+	Sentry                         *ProjectSentry         `toml:"sentry"`
+	Local                          *ProjectLocal          `toml:"local"`
+	Scope                          *ProjectScope          `toml:"scope"`
+	Paths                          *ProjectPaths          `toml:"paths"`
+	Generated                      *ProjectGenerated      `toml:"generated"`
+	Prompts                        *ProjectPrompts        `toml:"prompts"`
+	IssueLock                      *ProjectIssueLock      `toml:"issue_lock"`
+	IssuePolicy                    *ProjectIssuePolicy    `toml:"issue_policy"`
+	GitAuth                        *ProjectGitAuth        `toml:"git_auth"`
+	SSH                            *ProjectSSH            `toml:"ssh"`
+	Signing                        *ProjectSigning        `toml:"signing"`
+	Trailers                       *ProjectTrailers       `toml:"trailers"`
+	Epics                          *ProjectEpics          `toml:"epics"`
+	Lessons                        *ProjectLessons        `toml:"lessons"`
+	ChunkedReview                  *ProjectChunkedReview  `toml:"chunked_review"`
+	IssueComments                  *ProjectIssueComments  `toml:"issue_comments"`
+	Attachments                    *ProjectAttachments    `toml:"attachments"`
+	Recurring                      []ProjectRecurringTask `toml:"recurring"`
+	Caches                         []ProjectCache         `toml:"caches"`
+	Hooks                          []ProjectHook          `toml:"hooks"`
+	PolicyFile                     string                 `toml:"policy_file"` // Starlark policy script, relative to the config file; see internal/policy
+	// Env is added to the environment of the project's LLM sessions and
+	// commands; StepEnv overrides it per step (see EnvSteps). A value
+	// "secret:<name>" is read from credentials.toml [secrets].
+	Env     map[string]string            `toml:"env"`
+	StepEnv map[string]map[string]string `toml:"step_env"`
+	// Per-project polling intervals; empty means the [daemon] value.
+	SyncInterval    string `toml:"sync_interval"`
+	PRCheckInterval string `toml:"pr_check_interval"`
+	CICheckInterval string `toml:"ci_check_interval"`
+}
+
+// BranchPlaceholders are the fields a branch_template may use: the project
+// name, issue source ("github", "gitlab", ...), issue number or ID, slug of
+// the issue title, short job ID, and login of the user running AutoPR.
+var BranchPlaceholders = []string{"{project}", "{source}", "{issue-id}", "{slug}", "{job-id}", "{user}"}
+
+// branchTemplateLiteralRE matches the text a branch_template may have around
+// its placeholders.
+var branchTemplateLiteralRE = regexp.MustCompile(`^[A-Za-z0-9._/-]*$`)
+
+func validateBranchTemplate(tmpl string) error {
+	rest := tmpl
+	for _, ph := range BranchPlaceholders {
+		rest = strings.ReplaceAll(rest, ph, "-")
+	}
+	if strings.ContainsAny(rest, "{}") {
+		return fmt.Errorf("unknown placeholder in %q; use %s", tmpl, strings.Join(BranchPlaceholders, ", "))
+	}
+	if !branchTemplateLiteralRE.MatchString(rest) {
+		return fmt.Errorf("%q may only contain letters, digits, '.', '_', '-', '/', and placeholders", tmpl)
+	}
+	if strings.HasPrefix(tmpl, "/") || strings.HasPrefix(tmpl, ".") || strings.HasSuffix(tmpl, "/") ||
+		strings.Contains(tmpl, "//") || strings.Contains(tmpl, "/.") || strings.Contains(tmpl, "..") {
+		return fmt.Errorf("%q is not a valid branch name", tmpl)
+	}
+	if !strings.Contains(tmpl, "{issue-id}") && !strings.Contains(tmpl, "{slug}") && !strings.Contains(tmpl, "{job-id}") {
+		return fmt.Errorf("%q must use {issue-id}, {slug}, or {job-id} so jobs get different branches", tmpl)
+	}
+	return nil
 }
 
 type ProjectGitLab struct {
@@ -162,11 +454,180 @@ type ProjectGitLab struct {
 	IncludeLabels []string `toml:"include_labels"`
 }
 
+// ProjectGitea configures a Gitea or Forgejo repository. BaseURL is the web
+// root of the instance, e.g. "https://gitea.example.com".
+type ProjectGitea struct {
+	BaseURL       string   `toml:"base_url"`
+	Owner         string   `toml:"owner"`
+	Repo          string   `toml:"repo"`
+	IncludeLabels []string `toml:"include_labels"`
+}
+
 type ProjectGitHub struct {
 	Owner         string   `toml:"owner"`
 	Repo          string   `toml:"repo"`
 	ForkOwner     string   `toml:"fork_owner"`
 	IncludeLabels []string `toml:"include_labels"`
+	// CreateFork forks the repository into ForkOwner the first time a branch
+	// is pushed, when the fork does not exist yet.
+	CreateFork bool `toml:"create_fork"`
+	// BaseURL points at a GitHub Enterprise Server instance, e.g.
+	// "https://ghe.example.com" (an /api/v3 suffix is accepted). Empty means
+	// github.com.
+	BaseURL string `toml:"base_url"`
+	// UploadURL is the GHES uploads endpoint; defaults to <host>/api/uploads.
+	UploadURL string `toml:"upload_url"`
+	// AppID, AppInstallationID, and AppPrivateKeyPath configure a GitHub App
+	// whose installation tokens replace the github token for API calls and
+	// git transport. AppID and AppPrivateKeyPath default to [github_app];
+	// AppInstallationID is looked up from the repository when unset.
+	AppID             int64  `toml:"app_id"`
+	AppInstallationID int64  `toml:"app_installation_id"`
+	AppPrivateKeyPath string `toml:"app_private_key_path"`
+	// ReportChecks posts the outcome of each pipeline step (plan, implement,
+	// review, tests) on the PR's head commit when the branch is pushed: as a
+	// check run for GitHub App projects, or as a commit status otherwise.
+	ReportChecks bool `toml:"report_checks"`
+}
+
+// UsesApp reports whether the project authenticates as a GitHub App.
+func (github *ProjectGitHub) UsesApp() bool {
+	return github != nil && github.AppID != 0
+}
+
+// ProjectGitAuth configures git transport auth that does not use the source
+// token: a credential helper for HTTPS remotes and an ssh command for SSH
+// remotes. SSH remotes otherwise use the user's ssh-agent and ~/.ssh/config.
+type ProjectGitAuth struct {
+	CredentialHelper string `toml:"credential_helper"`
+	SSHCommand       string `toml:"ssh_command"`
+}
+
+// ProjectSigning signs the commits AutoPR makes in job clones, for
+// repositories that require signed commits.
+type ProjectSigning struct {
+	Format string `toml:"format"` // "ssh" or "gpg"
+	// Key is "secret:<name>" for a private key held in credentials.toml
+	// [secrets], or else an SSH private key file (ssh) or the ID of a key in
+	// the user's GPG keyring (gpg).
+	Key string `toml:"key"`
+}
+
+// SigningKey returns p's signing key and whether it is key material read from
+// [secrets] rather than a key file path or key ID.
+func (cfg *Config) SigningKey(p *ProjectConfig) (string, bool) {
+	if ref, ok := strings.CutPrefix(p.Signing.Key, secretRefPrefix); ok {
+		return cfg.Secrets[ref], true
+	}
+	return p.Signing.Key, false
+}
+
+// ProjectTrailers adds trailers to the messages of a job's commits when the
+// job is pushed for its PR.
+type ProjectTrailers struct {
+	SignedOffBy      bool   `toml:"signed_off_by"`      // Signed-off-by with the committer identity, for DCO repos
+	CoAuthorApprover bool   `toml:"co_author_approver"` // Co-authored-by the human who approved the job
+	CoAuthorLLM      bool   `toml:"co_author_llm"`      // Co-authored-by the LLM provider
+	LLMIdentity      string `toml:"llm_identity"`       // "Name <email>" for co_author_llm; empty uses the provider's default
+}
+
+// gitIdentityRE matches "Name <email>" as git writes it in trailers.
+var gitIdentityRE = regexp.MustCompile(`^[^<>]+ <[^<>\s@]+@[^<>\s]+>$`)
+
+// IsGitIdentity reports whether s has the form "Name <email>".
+func IsGitIdentity(s string) bool {
+	return gitIdentityRE.MatchString(s)
+}
+
+// DefaultEpicMinTasks is the fewest open task list items an issue needs to be
+// split when [projects.epics] leaves min_tasks unset.
+const DefaultEpicMinTasks = 2
+
+// ProjectEpics splits issues with a task list into one job per open item.
+// A nested item waits for the item it is nested under to merge; with
+// Sequential, every item waits for the one before it.
+type ProjectEpics struct {
+	Label      string `toml:"label"`      // split only issues with this label; empty splits any issue with a long enough task list
+	MinTasks   int    `toml:"min_tasks"`  // fewest open items to split; default DefaultEpicMinTasks
+	Sequential bool   `toml:"sequential"` // each task waits for the previous one to merge
+}
+
+// DefaultLessonsPerPrompt is how many lessons go into a prompt when
+// [projects.lessons] leaves per_prompt unset.
+const DefaultLessonsPerPrompt = 10
+
+// ProjectLessons keeps guidance learned from code reviews that asked for
+// changes and from human feedback, and adds the lessons that best match an
+// issue to the prompts of its later jobs.
+type ProjectLessons struct {
+	PerPrompt int `toml:"per_prompt"` // most lessons added to a prompt; default DefaultLessonsPerPrompt
+}
+
+// Defaults for [projects.chunked_review].
+const (
+	DefaultChunkedReviewMinLines = 1000
+	DefaultReviewChunkLines      = 400
+)
+
+// ProjectChunkedReview reviews large diffs in chunks: one LLM pass per group
+// of files, or per group of hunks of a large file, then a final pass that
+// merges their findings into the review.
+type ProjectChunkedReview struct {
+	MinLines   int `toml:"min_lines"`   // diffs changing more lines than this are chunked; default DefaultChunkedReviewMinLines
+	ChunkLines int `toml:"chunk_lines"` // most diff lines in one chunk; default DefaultReviewChunkLines
+}
+
+// Defaults for [projects.issue_comments].
+const (
+	DefaultIssueCommentsMaxChars = 8000
+	DefaultIssueCommentMaxChars  = 2000
+)
+
+// ProjectIssueComments syncs the comments on the project's issues and adds
+// the discussion to the plan prompt. The newest comments that fit in
+// MaxChars are included; older ones are left out with a note.
+type ProjectIssueComments struct {
+	MaxChars        int      `toml:"max_chars"`         // most comment text in a prompt; default DefaultIssueCommentsMaxChars
+	MaxCommentChars int      `toml:"max_comment_chars"` // longer comments are cut to this; default DefaultIssueCommentMaxChars
+	IgnoreAuthors   []string `toml:"ignore_authors"`    // accounts whose comments are left out, e.g. bots
+}
+
+// Defaults for [projects.attachments].
+const (
+	DefaultAttachmentsMaxBytes = 1 << 20
+	DefaultAttachmentsMaxFiles = 10
+	DefaultAttachmentsMaxChars = 20000
+)
+
+// ProjectAttachments downloads the files uploaded to the project's issues
+// and their comments, such as logs and screenshots. Text files are added to
+// the plan prompt; with Images, screenshots are handed to the plan step too.
+type ProjectAttachments struct {
+	MaxBytes int64 `toml:"max_bytes"` // larger files are not downloaded; default DefaultAttachmentsMaxBytes
+	MaxFiles int   `toml:"max_files"` // most files downloaded per issue; default DefaultAttachmentsMaxFiles
+	MaxChars int   `toml:"max_chars"` // most text attachment content in a prompt; default DefaultAttachmentsMaxChars
+	Images   bool  `toml:"images"`    // pass image attachments to the plan step
+}
+
+// ProjectSSH configures the ssh executor: commands run on a remote build
+// machine in a copy of the job worktree that rsync keeps in sync.
+type ProjectSSH struct {
+	Host         string `toml:"host"`          // [user@]host, or a Host alias from ~/.ssh/config
+	Port         int    `toml:"port"`          // 0 means ssh's default
+	IdentityFile string `toml:"identity_file"` // private key; empty uses ssh's defaults and agent
+	RemoteDir    string `toml:"remote_dir"`    // holds the job worktrees, relative to the remote home; default ".autopr/worktrees"
+}
+
+// WebURL returns the web root for the GitHub host, e.g. "https://github.com".
+func (github *ProjectGitHub) WebURL() string {
+	if github == nil || strings.TrimSpace(github.BaseURL) == "" {
+		return "https://github.com"
+	}
+	u, err := url.Parse(strings.TrimSpace(github.BaseURL))
+	if err != nil || u.Host == "" || strings.EqualFold(u.Host, "api.github.com") {
+		return "https://github.com"
+	}
+	return u.Scheme + "://" + u.Host
 }
 
 func (github *ProjectGitHub) GitHubForkHead(branch string) string {
@@ -185,7 +646,198 @@ func (github *ProjectGitHub) GitHubForkRemote() string {
 	if github == nil || strings.TrimSpace(github.ForkOwner) == "" || strings.TrimSpace(github.Repo) == "" {
 		return ""
 	}
-	return fmt.Sprintf("https://github.com/%s/%s.git", strings.TrimSpace(github.ForkOwner), strings.TrimSpace(github.Repo))
+	return fmt.Sprintf("%s/%s/%s.git", github.WebURL(), strings.TrimSpace(github.ForkOwner), strings.TrimSpace(github.Repo))
+}
+
+// ProjectLocal marks a project without a remote tracker. Its issues come from
+// a markdown checklist and `ap run`, and approving a job pushes the branch to
+// repo_url (typically a local path) without opening a PR.
+type ProjectLocal struct {
+	// IssuesFile is a markdown file of "- [ ] title" items, re-read on every
+	// sync. A relative path is resolved against repo_url when that is a local
+	// path, else against the config directory. Empty means jobs come only from
+	// `ap run`.
+	IssuesFile string `toml:"issues_file"`
+}
+
+// ProjectScope confines a project to one subdirectory of a repository that
+// other projects share (a monorepo). Tests run in Path, changes outside it
+// send the job back to implementing, and only issues carrying one of
+// RouteLabels are picked up.
+type ProjectScope struct {
+	Path        string   `toml:"path"`
+	RouteLabels []string `toml:"route_labels"`
+}
+
+// AutoDetect, as test_cmd or lint_cmd, runs the command detected from the
+// job's checkout; see internal/stack.
+const AutoDetect = "auto"
+
+// TestCommands returns the commands the testing step runs: the test shards
+// when any are configured, otherwise test_cmd.
+func (p *ProjectConfig) TestCommands() []string {
+	if len(p.TestShards) > 0 {
+		return p.TestShards
+	}
+	return []string{p.TestCmd}
+}
+
+// ScopePath returns the project's subdirectory (slash-separated, relative to
+// the repository root), or "" when it spans the whole repository.
+func (p *ProjectConfig) ScopePath() string {
+	if p.Scope == nil {
+		return ""
+	}
+	return p.Scope.Path
+}
+
+// InScope reports whether the repository-relative file path lies inside the
+// project's scope.
+func (p *ProjectConfig) InScope(file string) bool {
+	scope := p.ScopePath()
+	return scope == "" || strings.HasPrefix(filepath.ToSlash(file), scope+"/")
+}
+
+// RoutesLabels reports whether an issue with labels belongs to this project
+// rather than to another sub-project of the same repository.
+func (p *ProjectConfig) RoutesLabels(labels []string) bool {
+	if p.Scope == nil || len(p.Scope.RouteLabels) == 0 {
+		return true
+	}
+	for _, l := range labels {
+		if slices.Contains(p.Scope.RouteLabels, strings.ToLower(strings.TrimSpace(l))) {
+			return true
+		}
+	}
+	return false
+}
+
+// ProjectPaths limits which files a job may change. Patterns are
+// slash-separated globs relative to the repository root; "**" matches any
+// number of directories and a trailing "/" matches everything below it.
+type ProjectPaths struct {
+	Allow []string `toml:"allow"` // when set, every changed file must match one
+	Deny  []string `toml:"deny"`  // changed files must match none; wins over allow
+}
+
+// PathViolation explains why the repository-relative file may not be changed
+// by a job for this project, or returns "" when it may.
+func (p *ProjectConfig) PathViolation(file string) string {
+	file = filepath.ToSlash(file)
+	if !p.InScope(file) {
+		return "outside scope " + p.ScopePath() + "/"
+	}
+	if p.Paths == nil {
+		return ""
+	}
+	for _, pattern := range p.Paths.Deny {
+		if MatchPath(pattern, file) {
+			return "denied by " + pattern
+		}
+	}
+	if len(p.Paths.Allow) == 0 {
+		return ""
+	}
+	for _, pattern := range p.Paths.Allow {
+		if MatchPath(pattern, file) {
+			return ""
+		}
+	}
+	return "not in allowed paths"
+}
+
+// MatchPath reports whether the slash-separated file path matches pattern.
+// Segments match as in path.Match; a "**" segment matches zero or more
+// directories, and a pattern ending in "/" matches everything below it.
+func MatchPath(pattern, file string) bool {
+	if strings.HasSuffix(pattern, "/") {
+		pattern += "**"
+	}
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(file, "/"))
+}
+
+func matchSegments(pattern, file []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := len(file); i >= 0; i-- {
+				if matchSegments(pattern[1:], file[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(file) == 0 {
+			return false
+		}
+		if ok, err := path.Match(pattern[0], file[0]); err != nil || !ok {
+			return false
+		}
+		pattern, file = pattern[1:], file[1:]
+	}
+	return len(file) == 0
+}
+
+// IsCritical reports whether the repository-relative file matches one of the
+// project's critical_paths, using the same patterns as IsGenerated.
+func (p *ProjectConfig) IsCritical(file string) bool {
+	file = filepath.ToSlash(file)
+	for _, pattern := range p.CriticalPaths {
+		if !strings.Contains(pattern, "/") {
+			if ok, _ := path.Match(pattern, path.Base(file)); ok {
+				return true
+			}
+			continue
+		}
+		if MatchPath(pattern, file) {
+			return true
+		}
+	}
+	return false
+}
+
+// ProjectGenerated marks files produced by tools, such as codegen output and
+// lockfiles. They are left out of review prompts and diff size limits, and
+// RegenerateCmd (run like test_cmd, before tests) rebuilds them.
+type ProjectGenerated struct {
+	Paths         []string `toml:"paths"`
+	RegenerateCmd string   `toml:"regenerate_cmd"`
+}
+
+// IsGenerated reports whether the repository-relative file is generated.
+// Patterns without a "/" match the file name at any depth, so "*.pb.go" and
+// "package-lock.json" work anywhere in the tree.
+func (p *ProjectConfig) IsGenerated(file string) bool {
+	if p.Generated == nil {
+		return false
+	}
+	file = filepath.ToSlash(file)
+	for _, pattern := range p.Generated.Paths {
+		if !strings.Contains(pattern, "/") {
+			if ok, _ := path.Match(pattern, path.Base(file)); ok {
+				return true
+			}
+			continue
+		}
+		if MatchPath(pattern, file) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsLocal reports whether the project has no remote tracker.
+func (p *ProjectConfig) IsLocal() bool {
+	return p.Local != nil
+}
+
+// LocalRepoPath returns the directory a repo_url points at when it is a
+// local path or file:// URL, or "" for a remote URL.
+func LocalRepoPath(repoURL string) string {
+	path := strings.TrimPrefix(strings.TrimSpace(repoURL), "file://")
+	if !filepath.IsAbs(path) {
+		return ""
+	}
+	return filepath.Clean(path)
 }
 
 type ProjectSentry struct {
@@ -209,6 +861,205 @@ const DefaultExcludeLabel = "autopr-skip"
 // explicitly disable team gating.
 const DefaultAssignedTeam = "autopr"
 
+// DefaultInProgressLabel is the label added to a GitHub or GitLab source
+// issue while a job is working on it, when [projects.issue_lock] is configured
+// without an explicit in_progress_label.
+const DefaultInProgressLabel = "autopr-in-progress"
+
+// ProjectIssueLock marks source issues as taken while a job runs so humans
+// don't start duplicate work. Assignee is optional; labels are swapped when the
+// job reaches a terminal state.
+type ProjectIssueLock struct {
+	Assignee        string `toml:"assignee"`
+	InProgressLabel string `toml:"in_progress_label"`
+	DoneLabel       string `toml:"done_label"`
+	FailedLabel     string `toml:"failed_label"`
+}
+
+// ProjectIssuePolicy stops AutoPR from retrying issues that keep failing. An
+// issue that has had MaxJobs job runs (retries included) is held ineligible
+// until a human clears it with `ap issues clear`; one with CooldownAfter
+// failed runs is held for Cooldown, or until cleared when Cooldown is empty.
+// Zero values disable each rule.
+type ProjectIssuePolicy struct {
+	MaxJobs       int    `toml:"max_jobs"`
+	CooldownAfter int    `toml:"cooldown_after_failures"`
+	Cooldown      string `toml:"cooldown"` // e.g. "24h"; empty holds until cleared
+}
+
+// CooldownDuration parses Cooldown; empty means hold until cleared (zero).
+func (p *ProjectIssuePolicy) CooldownDuration() time.Duration {
+	d, _ := time.ParseDuration(p.Cooldown)
+	return d
+}
+
+// ProjectCache is a directory shared by all of a project's job worktrees, so
+// test commands reuse what earlier jobs downloaded. It lives under
+// <repos_root>/caches and is handed to test_cmd, test_shards, and
+// regenerate_cmd through Env, a symlink at Link, or both. Those commands hold
+// a shared lock on it, or an exclusive one when Exclusive is set, and
+// `ap cache clear` waits for every lock to be released.
+type ProjectCache struct {
+	Name      string `toml:"name"`
+	Env       string `toml:"env"`       // variable set to the directory, e.g. "GOMODCACHE"
+	Link      string `toml:"link"`      // path in the test directory symlinked to it, e.g. "node_modules"
+	Exclusive bool   `toml:"exclusive"` // one command at a time, for caches tools can't share
+}
+
+// Lifecycle points a project hook can run at.
+const (
+	HookPostPlan  = "post_plan"  // after the plan step, before implementing
+	HookPrePush   = "pre_push"   // before the job branch is pushed for a PR
+	HookPostReady = "post_ready" // once the pipeline has moved the job to ready
+)
+
+// HookPoints are the lifecycle points a project hook can run at.
+var HookPoints = []string{HookPostPlan, HookPrePush, HookPostReady}
+
+// defaultHookTimeout bounds a hook run when its timeout is unset.
+const defaultHookTimeout = 5 * time.Minute
+
+// ProjectHook is an executable the daemon runs at a job lifecycle point, in
+// the job worktree, with the job's context as JSON on stdin. A non-zero exit
+// blocks the job from going past that point.
+type ProjectHook struct {
+	Name    string   `toml:"name"`
+	Point   string   `toml:"point"`   // one of HookPoints
+	Command []string `toml:"command"` // executable and arguments, run without a shell
+	Timeout string   `toml:"timeout"` // e.g. "2m"; default 5m
+}
+
+// TimeoutDuration parses Timeout, defaulting to five minutes.
+func (h ProjectHook) TimeoutDuration() time.Duration {
+	if d, err := time.ParseDuration(h.Timeout); err == nil && d > 0 {
+		return d
+	}
+	return defaultHookTimeout
+}
+
+// HooksAt returns the project's hooks for a lifecycle point, in config order.
+func (p *ProjectConfig) HooksAt(point string) []ProjectHook {
+	var hooks []ProjectHook
+	for _, h := range p.Hooks {
+		if h.Point == point {
+			hooks = append(hooks, h)
+		}
+	}
+	return hooks
+}
+
+// EnvSteps are the steps step_env can target: the LLM steps, "tests" for
+// test_cmd, test_shards, and regenerate_cmd, "setup" for setup_cmd, and
+// "hooks" for project hooks.
+var EnvSteps = []string{"plan", "implement", "code_review", "conflict_resolution", "tests", "setup", "hooks"}
+
+// secretRefPrefix marks an env value that names a secret in credentials.toml
+// [secrets] instead of holding the value itself.
+const secretRefPrefix = "secret:"
+
+// EnvVar is one variable added to a project's LLM session or command.
+type EnvVar struct {
+	Name   string
+	Value  string
+	Secret bool // read from [secrets]; must not be logged or stored
+}
+
+// ProjectEnv returns the variables for one of p's steps: p.Env, overridden
+// by p.StepEnv[step], sorted by name, with secret references resolved.
+func (cfg *Config) ProjectEnv(p *ProjectConfig, step string) []EnvVar {
+	merged := make(map[string]string, len(p.Env)+len(p.StepEnv[step]))
+	maps.Copy(merged, p.Env)
+	maps.Copy(merged, p.StepEnv[step])
+	out := make([]EnvVar, 0, len(merged))
+	for _, name := range slices.Sorted(maps.Keys(merged)) {
+		value := merged[name]
+		if ref, ok := strings.CutPrefix(value, secretRefPrefix); ok {
+			out = append(out, EnvVar{Name: name, Value: cfg.Secrets[ref], Secret: true})
+			continue
+		}
+		out = append(out, EnvVar{Name: name, Value: value})
+	}
+	return out
+}
+
+// validateProjectEnv checks p's env and step_env: variable names, step names,
+// and that every secret reference exists in credentials.toml [secrets].
+func validateProjectEnv(cfg *Config, p *ProjectConfig) error {
+	check := func(key string, env map[string]string) error {
+		for name, value := range env {
+			if !envNamePattern.MatchString(name) {
+				return fmt.Errorf("project %q %s: %q is not a valid variable name", p.Name, key, name)
+			}
+			if ref, ok := strings.CutPrefix(value, secretRefPrefix); ok {
+				if _, found := cfg.Secrets[ref]; !found {
+					return fmt.Errorf("project %q %s.%s: secret %q not found in credentials.toml [secrets]", p.Name, key, name, ref)
+				}
+			}
+		}
+		return nil
+	}
+	if err := check("env", p.Env); err != nil {
+		return err
+	}
+	for step, env := range p.StepEnv {
+		if !slices.Contains(EnvSteps, step) {
+			return fmt.Errorf("project %q step_env: unknown step %q (want one of %s)", p.Name, step, strings.Join(EnvSteps, ", "))
+		}
+		if err := check("step_env."+step, env); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ProjectRecurringTask is a job template the daemon queues on a cron schedule
+// (e.g. "weekly: bump dependencies"). Each run creates a synthetic issue; a run
+// is skipped while the previous run's job is still open.
+type ProjectRecurringTask struct {
+	Name     string `toml:"name"`
+	Schedule string `toml:"schedule"`
+	Title    string `toml:"title"`
+	Body     string `toml:"body"`
+}
+
+// JobTemplate is a routine task queued with `ap run --template <name>
+// --var key=value ...` rather than from a tracker issue. Title and Prompt
+// become the job's synthetic issue; TestCmd and LintCmd, when set, replace
+// the project's test_cmd (and test shards) and lint_cmd as the job's gates.
+// Each {{key}} in them is replaced by the value passed for key.
+type JobTemplate struct {
+	Name    string `toml:"name"`
+	Project string `toml:"project"`
+	Title   string `toml:"title"`
+	Prompt  string `toml:"prompt"`
+	TestCmd string `toml:"test_cmd"`
+	LintCmd string `toml:"lint_cmd"`
+}
+
+var templateVarPattern = regexp.MustCompile(`\{\{([A-Za-z_][A-Za-z0-9_-]*)\}\}`)
+
+// Vars returns the names of the {{key}} variables t uses, sorted.
+func (t JobTemplate) Vars() []string {
+	var vars []string
+	for _, field := range []string{t.Title, t.Prompt, t.TestCmd, t.LintCmd} {
+		for _, m := range templateVarPattern.FindAllStringSubmatch(field, -1) {
+			if !slices.Contains(vars, m[1]) {
+				vars = append(vars, m[1])
+			}
+		}
+	}
+	slices.Sort(vars)
+	return vars
+}
+
+// FillTemplateVars replaces each {{key}} in s with vars[key]. Placeholders
+// are replaced once, so values may contain {{...}} themselves.
+func FillTemplateVars(s string, vars map[string]string) string {
+	return templateVarPattern.ReplaceAllStringFunc(s, func(m string) string {
+		return vars[templateVarPattern.FindStringSubmatch(m)[1]]
+	})
+}
+
 type ProjectPrompts struct {
 	Plan            string `toml:"plan"`
 	PlanReview      string `toml:"plan_review"`
@@ -225,6 +1076,9 @@ func Load(path string) (*Config, error) {
 	cfg.BaseDir = filepath.Dir(path)
 	// Snapshot tokens from config file before credentials/env are merged in.
 	fileTokens := cfg.Tokens
+	if err := applyEnvOverrides(cfg); err != nil {
+		return nil, err
+	}
 	applyDefaults(cfg)
 	applyCredentialsAndEnv(cfg)
 	warnTokensInFile(fileTokens)
@@ -243,12 +1097,34 @@ func LoadMinimal(path string) (*Config, error) {
 		return nil, fmt.Errorf("decode config %s: %w", path, err)
 	}
 	cfg.BaseDir = filepath.Dir(path)
+	if err := applyEnvOverrides(cfg); err != nil {
+		return nil, err
+	}
 	applyDefaults(cfg)
 	applyCredentialsAndEnv(cfg)
 	resolvePaths(cfg)
 	return cfg, nil
 }
 
+// TUIListColumns are the columns the TUI job list can show, in their default
+// order. "ci" only appears while some job is waiting on CI checks, "due"
+// while some unfinished job has a deadline, and "risk" while some job has a
+// risk score.
+var TUIListColumns = []string{"job", "state", "ci", "due", "risk", "project", "source", "retry", "issue", "updated", "branch", "tokens", "pr"}
+
+// TUIDefaultColumns are the job list columns shown when tui.columns is unset.
+var TUIDefaultColumns = []string{"job", "state", "ci", "due", "risk", "project", "source", "retry", "issue", "updated"}
+
+// TUIConfig customizes the TUI job list. Columns picks and orders the
+// visible columns; ColumnWidths overrides the width of any of them. Unless
+// FixedWidths is set, wide columns such as the issue title shrink to fit
+// narrow terminals before the list scrolls horizontally.
+type TUIConfig struct {
+	Columns      []string       `toml:"columns"`
+	ColumnWidths map[string]int `toml:"column_widths"`
+	FixedWidths  bool           `toml:"fixed_widths"`
+}
+
 func applyDefaults(cfg *Config) {
 	if cfg.DBPath == "" {
 		if d, err := DataDir(); err == nil {
@@ -277,6 +1153,9 @@ func applyDefaults(cfg *Config) {
 	if cfg.Daemon.WebhookPort == 0 {
 		cfg.Daemon.WebhookPort = 9847
 	}
+	if cfg.Daemon.WebhookBind == "" {
+		cfg.Daemon.WebhookBind = "127.0.0.1"
+	}
 	if cfg.Daemon.MaxWorkers == 0 {
 		cfg.Daemon.MaxWorkers = 3
 	}
@@ -286,6 +1165,15 @@ func applyDefaults(cfg *Config) {
 	if cfg.Daemon.SyncInterval == "" {
 		cfg.Daemon.SyncInterval = "5m"
 	}
+	if cfg.Daemon.PRCheckInterval == "" {
+		cfg.Daemon.PRCheckInterval = cfg.Daemon.SyncInterval
+	}
+	if cfg.Daemon.SyncConcurrency == 0 {
+		cfg.Daemon.SyncConcurrency = 4
+	}
+	if cfg.Daemon.SyncHostRPS == 0 {
+		cfg.Daemon.SyncHostRPS = 5
+	}
 	if cfg.Daemon.PIDFile == "" {
 		if d, err := StateDir(); err == nil {
 			cfg.Daemon.PIDFile = filepath.Join(d, "autopr.pid")
@@ -299,6 +1187,27 @@ func applyDefaults(cfg *Config) {
 	if cfg.Daemon.CICheckTimeout == "" {
 		cfg.Daemon.CICheckTimeout = "30m"
 	}
+	if cfg.Daemon.CIStaleAfter == "" {
+		cfg.Daemon.CIStaleAfter = "10m"
+	}
+	if cfg.Daemon.QueueStaleAfter == "" {
+		cfg.Daemon.QueueStaleAfter = "24h"
+	}
+	if cfg.Daemon.IdleAfter == "" {
+		cfg.Daemon.IdleAfter = "0"
+	}
+	if cfg.Daemon.IdleSyncInterval == "" {
+		cfg.Daemon.IdleSyncInterval = "30m"
+	}
+	if cfg.Daemon.BackupKeep == 0 {
+		cfg.Daemon.BackupKeep = 7
+	}
+	if cfg.Retention.DeletedJobsAfter == "" {
+		cfg.Retention.DeletedJobsAfter = "720h"
+	}
+	if cfg.Daemon.BackupDir == "" {
+		cfg.Daemon.BackupDir = filepath.Join(filepath.Dir(cfg.DBPath), "backups")
+	}
 	if cfg.Sentry.BaseURL == "" {
 		cfg.Sentry.BaseURL = "https://sentry.io"
 	}
@@ -308,6 +1217,9 @@ func applyDefaults(cfg *Config) {
 	if cfg.Notifications.Triggers == nil {
 		cfg.Notifications.Triggers = slices.Clone(defaultNotificationTriggers)
 	}
+	if cfg.Update.Channel == "" {
+		cfg.Update.Channel = "stable"
+	}
 	for i := range cfg.Projects {
 		if cfg.Projects[i].BaseBranch == "" {
 			cfg.Projects[i].BaseBranch = "main"
@@ -315,7 +1227,13 @@ func applyDefaults(cfg *Config) {
 		if cfg.Projects[i].MaxAutoResolvableConflictLines <= 0 {
 			cfg.Projects[i].MaxAutoResolvableConflictLines = DefaultMaxAutoResolvableConflictLines
 		}
-		if (cfg.Projects[i].GitHub != nil || cfg.Projects[i].GitLab != nil) && cfg.Projects[i].ExcludeLabels == nil {
+		if cfg.Projects[i].MaxDiffFiles == 0 {
+			cfg.Projects[i].MaxDiffFiles = cfg.Daemon.MaxDiffFiles
+		}
+		if cfg.Projects[i].MaxDiffLines == 0 {
+			cfg.Projects[i].MaxDiffLines = cfg.Daemon.MaxDiffLines
+		}
+		if (cfg.Projects[i].GitHub != nil || cfg.Projects[i].GitLab != nil || cfg.Projects[i].Gitea != nil) && cfg.Projects[i].ExcludeLabels == nil {
 			cfg.Projects[i].ExcludeLabels = []string{DefaultExcludeLabel}
 		}
 		// Safe defaults: require "autopr" label/team unless explicitly overridden.
@@ -325,6 +1243,12 @@ func applyDefaults(cfg *Config) {
 		if cfg.Projects[i].GitLab != nil && cfg.Projects[i].GitLab.IncludeLabels == nil {
 			cfg.Projects[i].GitLab.IncludeLabels = []string{DefaultLabel}
 		}
+		if cfg.Projects[i].Gitea != nil && cfg.Projects[i].Gitea.IncludeLabels == nil {
+			cfg.Projects[i].Gitea.IncludeLabels = []string{DefaultLabel}
+		}
+		if cfg.Projects[i].IssueLock != nil && cfg.Projects[i].IssueLock.InProgressLabel == "" {
+			cfg.Projects[i].IssueLock.InProgressLabel = DefaultInProgressLabel
+		}
 		if cfg.Projects[i].Sentry != nil && cfg.Projects[i].Sentry.AssignedTeam == nil {
 			defaultTeam := DefaultAssignedTeam
 			cfg.Projects[i].Sentry.AssignedTeam = &defaultTeam
@@ -332,6 +1256,46 @@ func applyDefaults(cfg *Config) {
 	}
 }
 
+// applyEnvOverrides applies the AUTOPR_* settings that let a container run
+// from a read-only config file: paths onto mounted volumes, the log target,
+// and the webhook listener. Relative paths are resolved against the working
+// directory.
+func applyEnvOverrides(cfg *Config) error {
+	for _, o := range []struct {
+		env  string
+		dest *string
+		path bool
+	}{
+		{"AUTOPR_DB_PATH", &cfg.DBPath, true},
+		{"AUTOPR_REPOS_ROOT", &cfg.ReposRoot, true},
+		{"AUTOPR_PID_FILE", &cfg.Daemon.PIDFile, true},
+		{"AUTOPR_LOG_FILE", &cfg.LogFile, true},
+		{"AUTOPR_LOG_LEVEL", &cfg.LogLevel, false},
+		{"AUTOPR_WEBHOOK_BIND", &cfg.Daemon.WebhookBind, false},
+	} {
+		v := strings.TrimSpace(os.Getenv(o.env))
+		if v == "" {
+			continue
+		}
+		if o.path && v != LogStderr {
+			abs, err := filepath.Abs(v)
+			if err != nil {
+				return fmt.Errorf("resolve %s: %w", o.env, err)
+			}
+			v = abs
+		}
+		*o.dest = v
+	}
+	if v := strings.TrimSpace(os.Getenv("AUTOPR_WEBHOOK_PORT")); v != "" {
+		port, err := strconv.Atoi(v)
+		if err != nil || port <= 0 || port > 65535 {
+			return fmt.Errorf("invalid AUTOPR_WEBHOOK_PORT %q: want a port number", v)
+		}
+		cfg.Daemon.WebhookPort = port
+	}
+	return nil
+}
+
 // applyCredentialsAndEnv merges token values from credentials.toml and then
 // from environment variables. Priority (highest → lowest): env > credentials.toml > config file.
 func applyCredentialsAndEnv(cfg *Config) {
@@ -347,24 +1311,49 @@ func applyCredentialsAndEnv(cfg *Config) {
 		if creds.GitLabToken != "" {
 			cfg.Tokens.GitLab = creds.GitLabToken
 		}
+		if creds.GiteaToken != "" {
+			cfg.Tokens.Gitea = creds.GiteaToken
+		}
 		if creds.SentryToken != "" {
 			cfg.Tokens.Sentry = creds.SentryToken
 		}
 		if creds.WebhookSecret != "" {
 			cfg.Daemon.WebhookSecret = creds.WebhookSecret
 		}
+		if creds.DBKey != "" {
+			cfg.DBKey = creds.DBKey
+		}
+		if creds.SlackSigningSecret != "" {
+			cfg.ChatOps.SlackSigningSecret = creds.SlackSigningSecret
+		}
+		if creds.MattermostToken != "" {
+			cfg.ChatOps.MattermostToken = creds.MattermostToken
+		}
+		cfg.Secrets = creds.Secrets
 	}
 
 	// Env vars win over everything.
 	if v := os.Getenv("AUTOPR_WEBHOOK_SECRET"); v != "" {
 		cfg.Daemon.WebhookSecret = v
 	}
+	if v := os.Getenv("AUTOPR_DB_KEY"); v != "" {
+		cfg.DBKey = v
+	}
+	if v := os.Getenv("AUTOPR_SLACK_SIGNING_SECRET"); v != "" {
+		cfg.ChatOps.SlackSigningSecret = v
+	}
+	if v := os.Getenv("AUTOPR_MATTERMOST_TOKEN"); v != "" {
+		cfg.ChatOps.MattermostToken = v
+	}
 	if v := os.Getenv("GITLAB_TOKEN"); v != "" {
 		cfg.Tokens.GitLab = v
 	}
 	if v := os.Getenv("GITHUB_TOKEN"); v != "" {
 		cfg.Tokens.GitHub = v
 	}
+	if v := os.Getenv("GITEA_TOKEN"); v != "" {
+		cfg.Tokens.Gitea = v
+	}
 	if v := os.Getenv("SENTRY_TOKEN"); v != "" {
 		cfg.Tokens.Sentry = v
 	}
@@ -380,6 +1369,9 @@ func warnTokensInFile(fileTokens TokensConfig) {
 	if fileTokens.GitHub != "" {
 		slog.Warn("github token found in config file; prefer credentials.toml or GITHUB_TOKEN env var")
 	}
+	if fileTokens.Gitea != "" {
+		slog.Warn("gitea token found in config file; prefer credentials.toml or GITEA_TOKEN env var")
+	}
 	if fileTokens.Sentry != "" {
 		slog.Warn("sentry token found in config file; prefer credentials.toml or SENTRY_TOKEN env var")
 	}
@@ -399,17 +1391,107 @@ func validate(cfg *Config) error {
 	if _, err := time.ParseDuration(cfg.Daemon.SyncInterval); err != nil {
 		return fmt.Errorf("invalid daemon.sync_interval %q: %w", cfg.Daemon.SyncInterval, err)
 	}
+	if _, err := time.ParseDuration(cfg.Daemon.PRCheckInterval); err != nil {
+		return fmt.Errorf("invalid daemon.pr_check_interval %q: %w", cfg.Daemon.PRCheckInterval, err)
+	}
+	if cfg.Daemon.SyncConcurrency < 0 {
+		return fmt.Errorf("invalid daemon.sync_concurrency %d: must be positive", cfg.Daemon.SyncConcurrency)
+	}
+	if cfg.Daemon.SyncHostRPS < 0 {
+		return fmt.Errorf("invalid daemon.sync_host_rps %g: must be positive", cfg.Daemon.SyncHostRPS)
+	}
 	if _, err := time.ParseDuration(cfg.Daemon.CICheckInterval); err != nil {
 		return fmt.Errorf("invalid daemon.ci_check_interval %q: %w", cfg.Daemon.CICheckInterval, err)
 	}
 	if _, err := time.ParseDuration(cfg.Daemon.CICheckTimeout); err != nil {
 		return fmt.Errorf("invalid daemon.ci_check_timeout %q: %w", cfg.Daemon.CICheckTimeout, err)
 	}
+	if d, err := time.ParseDuration(cfg.Daemon.CIStaleAfter); err != nil || d < 0 {
+		return fmt.Errorf("invalid daemon.ci_stale_after %q: want a duration like \"10m\", or \"0\" to disable", cfg.Daemon.CIStaleAfter)
+	}
+	if d, err := time.ParseDuration(cfg.Daemon.QueueStaleAfter); err != nil || d < 0 {
+		return fmt.Errorf("invalid daemon.queue_stale_after %q: want a duration like \"24h\", or \"0\" to disable", cfg.Daemon.QueueStaleAfter)
+	}
+	if d, err := time.ParseDuration(cfg.Daemon.IdleAfter); err != nil || d < 0 {
+		return fmt.Errorf("invalid daemon.idle_after %q: want a duration like \"30m\", or \"0\" to disable", cfg.Daemon.IdleAfter)
+	}
+	if d, err := time.ParseDuration(cfg.Daemon.IdleSyncInterval); err != nil || d <= 0 {
+		return fmt.Errorf("invalid daemon.idle_sync_interval %q: want a positive duration like \"30m\"", cfg.Daemon.IdleSyncInterval)
+	}
+	if cfg.Daemon.BackupInterval != "" {
+		if d, err := time.ParseDuration(cfg.Daemon.BackupInterval); err != nil || d <= 0 {
+			return fmt.Errorf("invalid daemon.backup_interval %q: want a positive duration like \"24h\"", cfg.Daemon.BackupInterval)
+		}
+	}
+	if cfg.Daemon.WebhookBind != "localhost" && net.ParseIP(cfg.Daemon.WebhookBind) == nil {
+		return fmt.Errorf("invalid daemon.webhook_bind %q: want an IP address like \"0.0.0.0\"", cfg.Daemon.WebhookBind)
+	}
+	if cfg.Daemon.BackupKeep < 0 {
+		return fmt.Errorf("daemon.backup_keep must be >= 0, got %d", cfg.Daemon.BackupKeep)
+	}
+	if cfg.Daemon.MaxDiffFiles < 0 || cfg.Daemon.MaxDiffLines < 0 {
+		return fmt.Errorf("daemon.max_diff_files and daemon.max_diff_lines must be >= 0")
+	}
+	if cfg.Daemon.MaxLoadPerCPU < 0 {
+		return fmt.Errorf("daemon.max_load_per_cpu must be >= 0, got %g", cfg.Daemon.MaxLoadPerCPU)
+	}
+	if cfg.Daemon.MinFreeMemoryMB < 0 {
+		return fmt.Errorf("daemon.min_free_memory_mb must be >= 0, got %d", cfg.Daemon.MinFreeMemoryMB)
+	}
 	normalizedTriggers, err := validateNotificationsConfig(cfg.Notifications)
 	if err != nil {
 		return err
 	}
 	cfg.Notifications.Triggers = normalizedTriggers
+	cfg.GitHubApp.PrivateKeyPath = strings.TrimSpace(cfg.GitHubApp.PrivateKeyPath)
+	if (cfg.GitHubApp.AppID != 0 || cfg.GitHubApp.PrivateKeyPath != "") && (cfg.GitHubApp.AppID <= 0 || cfg.GitHubApp.PrivateKeyPath == "") {
+		return fmt.Errorf("github_app: app_id and private_key_path must be set together")
+	}
+	if err := validateNetworkConfig(&cfg.Network); err != nil {
+		return err
+	}
+	if cfg.Retention.SessionTextAfter != "" {
+		if d, err := time.ParseDuration(cfg.Retention.SessionTextAfter); err != nil || d <= 0 {
+			return fmt.Errorf("invalid retention.session_text_after %q: want a positive duration like \"720h\"", cfg.Retention.SessionTextAfter)
+		}
+	}
+	if cfg.Retention.DeletedJobsAfter != "" {
+		if d, err := time.ParseDuration(cfg.Retention.DeletedJobsAfter); err != nil || d < 0 {
+			return fmt.Errorf("invalid retention.deleted_jobs_after %q: want a duration like \"720h\", or \"0\" to keep trashed jobs", cfg.Retention.DeletedJobsAfter)
+		}
+	}
+	if err := validateTUIConfig(&cfg.TUI); err != nil {
+		return err
+	}
+	for _, users := range [][]string{cfg.ChatOps.Viewers, cfg.ChatOps.Approvers} {
+		for i, u := range users {
+			if users[i] = strings.TrimSpace(u); users[i] == "" {
+				return fmt.Errorf("chatops: viewers and approvers must not contain empty entries")
+			}
+		}
+	}
+	for _, u := range cfg.ChatOps.Approvers {
+		if strings.HasPrefix(u, "@") {
+			return fmt.Errorf("chatops.approvers: %q is a user name; list approvers by user ID, which users cannot change", u)
+		}
+	}
+	for u, ident := range cfg.ChatOps.Identities {
+		if !IsGitIdentity(strings.TrimSpace(ident)) {
+			return fmt.Errorf("chatops.identities: %q must be \"Name <email>\", got %q", u, ident)
+		}
+		cfg.ChatOps.Identities[u] = strings.TrimSpace(ident)
+	}
+	cfg.Tracing.Endpoint = strings.TrimSpace(cfg.Tracing.Endpoint)
+	if strings.Contains(cfg.Tracing.Endpoint, "://") {
+		u, err := url.Parse(cfg.Tracing.Endpoint)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("tracing.endpoint: must be host:port or an http(s) URL, got %q", cfg.Tracing.Endpoint)
+		}
+	}
+	cfg.Update.Channel = strings.ToLower(strings.TrimSpace(cfg.Update.Channel))
+	if cfg.Update.Channel != "stable" && cfg.Update.Channel != "beta" {
+		return fmt.Errorf("update.channel must be stable or beta, got %q", cfg.Update.Channel)
+	}
 	if len(cfg.Projects) == 0 {
 		return fmt.Errorf("at least one [[projects]] entry is required")
 	}
@@ -423,8 +1505,87 @@ func validate(cfg *Config) error {
 		if p.TestCmd == "" {
 			return fmt.Errorf("project %q: test_cmd is required", p.Name)
 		}
-		if p.GitLab == nil && p.GitHub == nil && p.Sentry == nil {
-			return fmt.Errorf("project %q: at least one source (gitlab/github/sentry) is required", p.Name)
+		cfg.Projects[i].SetupCmd = strings.TrimSpace(p.SetupCmd)
+		cfg.Projects[i].LintCmd = strings.TrimSpace(p.LintCmd)
+		for j, shard := range p.TestShards {
+			shard = strings.TrimSpace(shard)
+			if shard == "" {
+				return fmt.Errorf("project %q test_shards[%d]: must be a non-empty command", p.Name, j)
+			}
+			p.TestShards[j] = shard
+		}
+		if p.MaxDiffFiles < 0 || p.MaxDiffLines < 0 {
+			return fmt.Errorf("project %q: max_diff_files and max_diff_lines must be >= 0", p.Name)
+		}
+		if p.QueueWeight < 0 {
+			return fmt.Errorf("project %q: queue_weight must be >= 0, got %d", p.Name, p.QueueWeight)
+		}
+		for _, iv := range []struct{ key, value string }{
+			{"sync_interval", p.SyncInterval},
+			{"pr_check_interval", p.PRCheckInterval},
+			{"ci_check_interval", p.CICheckInterval},
+		} {
+			if iv.value == "" {
+				continue
+			}
+			if d, err := time.ParseDuration(iv.value); err != nil || d <= 0 {
+				return fmt.Errorf("project %q: invalid %s %q: want a positive duration like \"10m\"", p.Name, iv.key, iv.value)
+			}
+		}
+		if p.GitLab == nil && p.GitHub == nil && p.Gitea == nil && p.Sentry == nil && p.Local == nil {
+			return fmt.Errorf("project %q: at least one source (gitlab/github/gitea/sentry/local) is required", p.Name)
+		}
+		if p.Local != nil {
+			if p.GitLab != nil || p.GitHub != nil || p.Gitea != nil {
+				return fmt.Errorf("project %q local: cannot be combined with a github, gitlab, or gitea source", p.Name)
+			}
+			p.Local.IssuesFile = strings.TrimSpace(p.Local.IssuesFile)
+		}
+		if p.Scope != nil {
+			scope := path.Clean(filepath.ToSlash(strings.TrimSpace(p.Scope.Path)))
+			if scope == "." || path.IsAbs(scope) || scope == ".." || strings.HasPrefix(scope, "../") {
+				return fmt.Errorf("project %q scope.path: must be a subdirectory of the repository, got %q", p.Name, p.Scope.Path)
+			}
+			p.Scope.Path = scope
+			normalized, err := normalizeLabels(p.Scope.RouteLabels)
+			if err != nil {
+				return fmt.Errorf("project %q scope.route_labels: %w", p.Name, err)
+			}
+			p.Scope.RouteLabels = normalized
+		}
+		for j, file := range p.ContextFiles {
+			file = path.Clean(filepath.ToSlash(strings.TrimSpace(file)))
+			if file == "." || path.IsAbs(file) || file == ".." || strings.HasPrefix(file, "../") {
+				return fmt.Errorf("project %q context_files[%d]: must be a file inside the repository, got %q", p.Name, j, p.ContextFiles[j])
+			}
+			p.ContextFiles[j] = file
+		}
+		type patternList struct {
+			key      string
+			patterns []string
+		}
+		var patternLists []patternList
+		if p.Paths != nil {
+			patternLists = append(patternLists, patternList{"paths.allow", p.Paths.Allow}, patternList{"paths.deny", p.Paths.Deny})
+		}
+		if p.Generated != nil {
+			patternLists = append(patternLists, patternList{"generated.paths", p.Generated.Paths})
+			p.Generated.RegenerateCmd = strings.TrimSpace(p.Generated.RegenerateCmd)
+		}
+		if len(p.CriticalPaths) > 0 {
+			patternLists = append(patternLists, patternList{"critical_paths", p.CriticalPaths})
+		}
+		for _, list := range patternLists {
+			for j, pattern := range list.patterns {
+				pattern = strings.TrimPrefix(strings.TrimSpace(pattern), "./")
+				if pattern == "" || strings.HasPrefix(pattern, "/") {
+					return fmt.Errorf("project %q %s[%d]: must be a non-empty path relative to the repository", p.Name, list.key, j)
+				}
+				if _, err := path.Match(pattern, ""); err != nil {
+					return fmt.Errorf("project %q %s[%d]: invalid pattern %q: %w", p.Name, list.key, j, pattern, err)
+				}
+				list.patterns[j] = pattern
+			}
 		}
 		normalized, err := normalizeLabels(p.ExcludeLabels)
 		if err != nil {
@@ -438,11 +1599,41 @@ func validate(cfg *Config) error {
 			if rawForkOwner != "" && p.GitHub.ForkOwner == "" {
 				return fmt.Errorf("project %q github.fork_owner: cannot be blank", p.Name)
 			}
+			if p.GitHub.CreateFork && p.GitHub.ForkOwner == "" {
+				return fmt.Errorf("project %q github.create_fork: requires fork_owner", p.Name)
+			}
 			normalized, err := normalizeLabels(p.GitHub.IncludeLabels)
 			if err != nil {
 				return fmt.Errorf("project %q github.include_labels: %w", p.Name, err)
 			}
 			cfg.Projects[i].GitHub.IncludeLabels = normalized
+			p.GitHub.BaseURL = strings.TrimRight(strings.TrimSpace(p.GitHub.BaseURL), "/")
+			p.GitHub.UploadURL = strings.TrimRight(strings.TrimSpace(p.GitHub.UploadURL), "/")
+			if p.GitHub.BaseURL != "" {
+				if err := validateWebhookURL(p.GitHub.BaseURL); err != nil {
+					return fmt.Errorf("project %q github.base_url: %w", p.Name, err)
+				}
+			}
+			if p.GitHub.UploadURL != "" {
+				if err := validateWebhookURL(p.GitHub.UploadURL); err != nil {
+					return fmt.Errorf("project %q github.upload_url: %w", p.Name, err)
+				}
+			}
+			if p.GitHub.UploadURL != "" && p.GitHub.BaseURL == "" {
+				return fmt.Errorf("project %q github.upload_url: requires github.base_url", p.Name)
+			}
+			if p.GitHub.BaseURL != "" && p.GitHub.UploadURL == "" && p.GitHub.WebURL() != "https://github.com" {
+				p.GitHub.UploadURL = p.GitHub.WebURL() + "/api/uploads"
+			}
+			p.GitHub.AppPrivateKeyPath = strings.TrimSpace(p.GitHub.AppPrivateKeyPath)
+			if p.GitHub.AppID == 0 && p.GitHub.AppPrivateKeyPath == "" {
+				p.GitHub.AppID = cfg.GitHubApp.AppID
+				p.GitHub.AppPrivateKeyPath = cfg.GitHubApp.PrivateKeyPath
+			}
+			appSet := p.GitHub.AppID != 0 || p.GitHub.AppInstallationID != 0 || p.GitHub.AppPrivateKeyPath != ""
+			if appSet && (p.GitHub.AppID <= 0 || p.GitHub.AppInstallationID < 0 || p.GitHub.AppPrivateKeyPath == "") {
+				return fmt.Errorf("project %q github: app_id and app_private_key_path must be set together (or via [github_app])", p.Name)
+			}
 		}
 		if p.GitLab != nil {
 			normalized, err := normalizeLabels(p.GitLab.IncludeLabels)
@@ -451,10 +1642,292 @@ func validate(cfg *Config) error {
 			}
 			cfg.Projects[i].GitLab.IncludeLabels = normalized
 		}
+		if p.Gitea != nil {
+			p.Gitea.BaseURL = strings.TrimRight(strings.TrimSpace(p.Gitea.BaseURL), "/")
+			p.Gitea.Owner = strings.TrimSpace(p.Gitea.Owner)
+			p.Gitea.Repo = strings.TrimSpace(p.Gitea.Repo)
+			if p.Gitea.BaseURL == "" {
+				return fmt.Errorf("project %q: gitea.base_url is required", p.Name)
+			}
+			if err := validateWebhookURL(p.Gitea.BaseURL); err != nil {
+				return fmt.Errorf("project %q gitea.base_url: %w", p.Name, err)
+			}
+			if p.Gitea.Owner == "" || p.Gitea.Repo == "" {
+				return fmt.Errorf("project %q: gitea.owner and gitea.repo are required", p.Name)
+			}
+			normalized, err := normalizeLabels(p.Gitea.IncludeLabels)
+			if err != nil {
+				return fmt.Errorf("project %q gitea.include_labels: %w", p.Name, err)
+			}
+			cfg.Projects[i].Gitea.IncludeLabels = normalized
+		}
+		if p.GitAuth != nil {
+			p.GitAuth.CredentialHelper = strings.TrimSpace(p.GitAuth.CredentialHelper)
+			p.GitAuth.SSHCommand = strings.TrimSpace(p.GitAuth.SSHCommand)
+			if p.GitAuth.CredentialHelper != "" && p.GitHub.UsesApp() {
+				return fmt.Errorf("project %q git_auth.credential_helper: cannot be combined with a github app", p.Name)
+			}
+		}
+		if p.IssueLock != nil {
+			lock := p.IssueLock
+			lock.Assignee = strings.TrimPrefix(strings.TrimSpace(lock.Assignee), "@")
+			lock.InProgressLabel = strings.TrimSpace(lock.InProgressLabel)
+			lock.DoneLabel = strings.TrimSpace(lock.DoneLabel)
+			lock.FailedLabel = strings.TrimSpace(lock.FailedLabel)
+			if lock.InProgressLabel == "" {
+				return fmt.Errorf("project %q issue_lock.in_progress_label: cannot be blank", p.Name)
+			}
+			if p.GitHub == nil && p.GitLab == nil {
+				return fmt.Errorf("project %q issue_lock: requires a github or gitlab source", p.Name)
+			}
+		}
+		if p.IssuePolicy != nil {
+			policy := p.IssuePolicy
+			policy.Cooldown = strings.TrimSpace(policy.Cooldown)
+			if policy.MaxJobs < 0 {
+				return fmt.Errorf("project %q issue_policy.max_jobs: must be >= 0", p.Name)
+			}
+			if policy.CooldownAfter < 0 {
+				return fmt.Errorf("project %q issue_policy.cooldown_after_failures: must be >= 0", p.Name)
+			}
+			if policy.Cooldown != "" {
+				d, err := time.ParseDuration(policy.Cooldown)
+				if err != nil || d <= 0 {
+					return fmt.Errorf("project %q issue_policy.cooldown: invalid duration %q", p.Name, policy.Cooldown)
+				}
+				if policy.CooldownAfter == 0 {
+					return fmt.Errorf("project %q issue_policy.cooldown: requires cooldown_after_failures", p.Name)
+				}
+			}
+		}
+		if err := validateProjectEnv(cfg, &cfg.Projects[i]); err != nil {
+			return err
+		}
+		if sg := p.Signing; sg != nil {
+			sg.Format = strings.ToLower(strings.TrimSpace(sg.Format))
+			sg.Key = strings.TrimSpace(sg.Key)
+			if sg.Format != "ssh" && sg.Format != "gpg" {
+				return fmt.Errorf("project %q signing.format: must be ssh or gpg, got %q", p.Name, sg.Format)
+			}
+			if sg.Key == "" {
+				return fmt.Errorf("project %q signing.key: required", p.Name)
+			}
+			if ref, ok := strings.CutPrefix(sg.Key, secretRefPrefix); ok {
+				if _, found := cfg.Secrets[ref]; !found {
+					return fmt.Errorf("project %q signing.key: secret %q not found in credentials.toml [secrets]", p.Name, ref)
+				}
+			}
+		}
+		p.BranchTemplate = strings.TrimSpace(p.BranchTemplate)
+		if p.BranchTemplate != "" {
+			if err := validateBranchTemplate(p.BranchTemplate); err != nil {
+				return fmt.Errorf("project %q branch_template: %w", p.Name, err)
+			}
+		}
+		if tr := p.Trailers; tr != nil {
+			tr.LLMIdentity = strings.TrimSpace(tr.LLMIdentity)
+			if tr.LLMIdentity != "" && !IsGitIdentity(tr.LLMIdentity) {
+				return fmt.Errorf("project %q trailers.llm_identity: must be \"Name <email>\", got %q", p.Name, tr.LLMIdentity)
+			}
+		}
+		if ep := p.Epics; ep != nil {
+			ep.Label = strings.TrimSpace(ep.Label)
+			if ep.MinTasks == 0 {
+				ep.MinTasks = DefaultEpicMinTasks
+			}
+			if ep.MinTasks < 2 {
+				return fmt.Errorf("project %q epics.min_tasks: must be at least 2, got %d", p.Name, ep.MinTasks)
+			}
+		}
+		if l := p.Lessons; l != nil {
+			if l.PerPrompt == 0 {
+				l.PerPrompt = DefaultLessonsPerPrompt
+			}
+			if l.PerPrompt < 0 {
+				return fmt.Errorf("project %q lessons.per_prompt: must not be negative, got %d", p.Name, l.PerPrompt)
+			}
+		}
+		if cr := p.ChunkedReview; cr != nil {
+			if cr.MinLines == 0 {
+				cr.MinLines = DefaultChunkedReviewMinLines
+			}
+			if cr.ChunkLines == 0 {
+				cr.ChunkLines = DefaultReviewChunkLines
+			}
+			if cr.MinLines < 0 {
+				return fmt.Errorf("project %q chunked_review.min_lines: must not be negative, got %d", p.Name, cr.MinLines)
+			}
+			if cr.ChunkLines < 50 {
+				return fmt.Errorf("project %q chunked_review.chunk_lines: must be at least 50, got %d", p.Name, cr.ChunkLines)
+			}
+		}
+		if ic := p.IssueComments; ic != nil {
+			if ic.MaxChars == 0 {
+				ic.MaxChars = DefaultIssueCommentsMaxChars
+			}
+			if ic.MaxCommentChars == 0 {
+				ic.MaxCommentChars = DefaultIssueCommentMaxChars
+			}
+			if ic.MaxChars < 0 || ic.MaxCommentChars < 0 {
+				return fmt.Errorf("project %q issue_comments: max_chars and max_comment_chars must not be negative", p.Name)
+			}
+		}
+		if at := p.Attachments; at != nil {
+			if at.MaxBytes == 0 {
+				at.MaxBytes = DefaultAttachmentsMaxBytes
+			}
+			if at.MaxFiles == 0 {
+				at.MaxFiles = DefaultAttachmentsMaxFiles
+			}
+			if at.MaxChars == 0 {
+				at.MaxChars = DefaultAttachmentsMaxChars
+			}
+			if at.MaxBytes < 0 || at.MaxFiles < 0 || at.MaxChars < 0 {
+				return fmt.Errorf("project %q attachments: max_bytes, max_files and max_chars must not be negative", p.Name)
+			}
+		}
+		seenCaches := make(map[string]bool, len(p.Caches))
+		for j := range p.Caches {
+			c := &cfg.Projects[i].Caches[j]
+			c.Name = strings.ToLower(strings.TrimSpace(c.Name))
+			c.Env = strings.TrimSpace(c.Env)
+			c.Link = strings.TrimSpace(c.Link)
+			if !recurringNamePattern.MatchString(c.Name) {
+				return fmt.Errorf("project %q caches[%d].name: %q must be lowercase letters, digits, '-' or '_'", p.Name, j, c.Name)
+			}
+			if seenCaches[c.Name] {
+				return fmt.Errorf("project %q caches: duplicate name %q", p.Name, c.Name)
+			}
+			seenCaches[c.Name] = true
+			if c.Env == "" && c.Link == "" {
+				return fmt.Errorf("project %q cache %q: env or link is required", p.Name, c.Name)
+			}
+			if c.Env != "" && !envNamePattern.MatchString(c.Env) {
+				return fmt.Errorf("project %q cache %q env: %q is not a valid variable name", p.Name, c.Name, c.Env)
+			}
+			if c.Link != "" {
+				link := path.Clean(filepath.ToSlash(c.Link))
+				if link == "." || path.IsAbs(link) || link == ".." || strings.HasPrefix(link, "../") || link == ".git" || strings.HasPrefix(link, ".git/") {
+					return fmt.Errorf("project %q cache %q link: must be a path inside the test directory, got %q", p.Name, c.Name, c.Link)
+				}
+				c.Link = link
+			}
+		}
+		seenHooks := make(map[string]bool, len(p.Hooks))
+		for j := range p.Hooks {
+			h := &cfg.Projects[i].Hooks[j]
+			h.Name = strings.ToLower(strings.TrimSpace(h.Name))
+			h.Point = strings.ToLower(strings.TrimSpace(h.Point))
+			h.Timeout = strings.TrimSpace(h.Timeout)
+			if !recurringNamePattern.MatchString(h.Name) {
+				return fmt.Errorf("project %q hooks[%d].name: %q must be lowercase letters, digits, '-' or '_'", p.Name, j, h.Name)
+			}
+			if seenHooks[h.Name] {
+				return fmt.Errorf("project %q hooks: duplicate name %q", p.Name, h.Name)
+			}
+			seenHooks[h.Name] = true
+			if !slices.Contains(HookPoints, h.Point) {
+				return fmt.Errorf("project %q hook %q point: %q must be one of %s", p.Name, h.Name, h.Point, strings.Join(HookPoints, ", "))
+			}
+			if len(h.Command) == 0 || strings.TrimSpace(h.Command[0]) == "" {
+				return fmt.Errorf("project %q hook %q: command is required", p.Name, h.Name)
+			}
+			if h.Timeout != "" {
+				if d, err := time.ParseDuration(h.Timeout); err != nil || d <= 0 {
+					return fmt.Errorf("project %q hook %q timeout: invalid duration %q", p.Name, h.Name, h.Timeout)
+				}
+			}
+		}
+		seenTasks := make(map[string]bool, len(p.Recurring))
+		for j := range p.Recurring {
+			task := &cfg.Projects[i].Recurring[j]
+			task.Name = strings.ToLower(strings.TrimSpace(task.Name))
+			task.Schedule = strings.TrimSpace(task.Schedule)
+			task.Title = strings.TrimSpace(task.Title)
+			if !recurringNamePattern.MatchString(task.Name) {
+				return fmt.Errorf("project %q recurring[%d].name: %q must be lowercase letters, digits, '-' or '_'", p.Name, j, task.Name)
+			}
+			if seenTasks[task.Name] {
+				return fmt.Errorf("project %q recurring: duplicate name %q", p.Name, task.Name)
+			}
+			seenTasks[task.Name] = true
+			if task.Title == "" {
+				return fmt.Errorf("project %q recurring %q: title is required", p.Name, task.Name)
+			}
+			if _, err := cron.Parse(task.Schedule); err != nil {
+				return fmt.Errorf("project %q recurring %q schedule: %w", p.Name, task.Name, err)
+			}
+		}
+	}
+	if err := validateExecutors(cfg); err != nil {
+		return err
+	}
+	if err := validateTemplates(cfg); err != nil {
+		return err
+	}
+	return validateScopes(cfg.Projects)
+}
+
+// validateTemplates checks each job template names a unique template and a
+// configured project, and has a title and a prompt.
+func validateTemplates(cfg *Config) error {
+	seen := make(map[string]bool, len(cfg.Templates))
+	for i := range cfg.Templates {
+		t := &cfg.Templates[i]
+		t.Name = strings.ToLower(strings.TrimSpace(t.Name))
+		t.Project = strings.TrimSpace(t.Project)
+		t.Title = strings.TrimSpace(t.Title)
+		t.TestCmd = strings.TrimSpace(t.TestCmd)
+		t.LintCmd = strings.TrimSpace(t.LintCmd)
+		if !recurringNamePattern.MatchString(t.Name) {
+			return fmt.Errorf("templates[%d].name: %q must be lowercase letters, digits, '-' or '_'", i, t.Name)
+		}
+		if seen[t.Name] {
+			return fmt.Errorf("templates: duplicate name %q", t.Name)
+		}
+		seen[t.Name] = true
+		if _, ok := cfg.ProjectByName(t.Project); !ok {
+			return fmt.Errorf("template %q: unknown project %q", t.Name, t.Project)
+		}
+		if t.Title == "" {
+			return fmt.Errorf("template %q: title is required", t.Name)
+		}
+		if strings.TrimSpace(t.Prompt) == "" {
+			return fmt.Errorf("template %q: prompt is required", t.Name)
+		}
+	}
+	return nil
+}
+
+// validateScopes checks sub-projects sharing a repository: their paths must
+// differ, and each needs route_labels so an issue goes to only one of them.
+func validateScopes(projects []ProjectConfig) error {
+	byRepo := make(map[string][]*ProjectConfig)
+	for i := range projects {
+		byRepo[projects[i].RepoURL] = append(byRepo[projects[i].RepoURL], &projects[i])
+	}
+	for _, group := range byRepo {
+		if len(group) < 2 || !slices.ContainsFunc(group, func(p *ProjectConfig) bool { return p.Scope != nil }) {
+			continue
+		}
+		paths := make(map[string]string, len(group))
+		for _, p := range group {
+			if p.Scope == nil || len(p.Scope.RouteLabels) == 0 {
+				return fmt.Errorf("project %q: shares repo_url with a scoped project; set scope.path and scope.route_labels", p.Name)
+			}
+			if other, ok := paths[p.Scope.Path]; ok {
+				return fmt.Errorf("projects %q and %q: same scope.path %q", other, p.Name, p.Scope.Path)
+			}
+			paths[p.Scope.Path] = p.Name
+		}
 	}
 	return nil
 }
 
+var recurringNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+var envNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
 func validateNotificationsConfig(cfg NotificationsConfig) ([]string, error) {
 	if cfg.WebhookURL != "" {
 		if err := validateWebhookURL(cfg.WebhookURL); err != nil {
@@ -487,6 +1960,136 @@ func validateWebhookURL(raw string) error {
 	return nil
 }
 
+func validateTUIConfig(t *TUIConfig) error {
+	seen := map[string]bool{}
+	for i, col := range t.Columns {
+		col = strings.ToLower(strings.TrimSpace(col))
+		if !slices.Contains(TUIListColumns, col) {
+			return fmt.Errorf("tui.columns: unknown column %q (expected one of: %s)", t.Columns[i], strings.Join(TUIListColumns, ", "))
+		}
+		if seen[col] {
+			return fmt.Errorf("tui.columns: %q is listed twice", col)
+		}
+		seen[col] = true
+		t.Columns[i] = col
+	}
+	for col, width := range t.ColumnWidths {
+		if !slices.Contains(TUIListColumns, col) {
+			return fmt.Errorf("tui.column_widths: unknown column %q (expected one of: %s)", col, strings.Join(TUIListColumns, ", "))
+		}
+		if width < 3 || width > 200 {
+			return fmt.Errorf("tui.column_widths.%s: width %d must be between 3 and 200", col, width)
+		}
+	}
+	return nil
+}
+
+func validateNetworkConfig(n *NetworkConfig) error {
+	n.HTTPProxy = strings.TrimSpace(n.HTTPProxy)
+	n.HTTPSProxy = strings.TrimSpace(n.HTTPSProxy)
+	n.NoProxy = strings.TrimSpace(n.NoProxy)
+	n.CABundle = strings.TrimSpace(n.CABundle)
+	for name, raw := range map[string]string{"http_proxy": n.HTTPProxy, "https_proxy": n.HTTPSProxy} {
+		if raw == "" {
+			continue
+		}
+		u, err := url.Parse(raw)
+		if err != nil {
+			return fmt.Errorf("network.%s: %w", name, err)
+		}
+		switch u.Scheme {
+		case "http", "https", "socks5", "socks5h":
+		default:
+			return fmt.Errorf("network.%s: scheme must be http, https, socks5, or socks5h", name)
+		}
+		if u.Host == "" {
+			return fmt.Errorf("network.%s: host is required", name)
+		}
+	}
+	return nil
+}
+
+// validateExecutors resolves each project's executor and checks that the
+// executors in use are configured.
+func validateExecutors(cfg *Config) error {
+	cfg.Docker.Image = strings.TrimSpace(cfg.Docker.Image)
+	cfg.Kubernetes.Image = strings.TrimSpace(cfg.Kubernetes.Image)
+	for i := range cfg.Projects {
+		p := &cfg.Projects[i]
+		p.Executor = strings.ToLower(strings.TrimSpace(p.Executor))
+		p.ExecutorImage = strings.TrimSpace(p.ExecutorImage)
+		if p.Executor == "" {
+			p.Executor = ExecutorLocal
+			if cfg.Kubernetes.Enabled {
+				p.Executor = ExecutorKubernetes
+			}
+		}
+		switch p.Executor {
+		case ExecutorLocal:
+		case ExecutorDocker:
+			if p.ExecutorImage == "" && cfg.Docker.Image == "" {
+				return fmt.Errorf("project %q: executor docker needs executor_image or docker.image", p.Name)
+			}
+		case ExecutorKubernetes:
+			if p.ExecutorImage == "" && cfg.Kubernetes.Image == "" {
+				return fmt.Errorf("project %q: executor kubernetes needs executor_image or kubernetes.image", p.Name)
+			}
+		case ExecutorSSH:
+			if err := validateProjectSSH(p); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("project %q: executor must be local, docker, kubernetes, or ssh, got %q", p.Name, p.Executor)
+		}
+	}
+	if cfg.UsesExecutor(ExecutorKubernetes) {
+		return validateKubernetesConfig(&cfg.Kubernetes)
+	}
+	return nil
+}
+
+func validateProjectSSH(p *ProjectConfig) error {
+	if p.SSH == nil || strings.TrimSpace(p.SSH.Host) == "" {
+		return fmt.Errorf("project %q: executor ssh needs [projects.ssh] host", p.Name)
+	}
+	p.SSH.Host = strings.TrimSpace(p.SSH.Host)
+	p.SSH.RemoteDir = strings.Trim(strings.TrimSpace(p.SSH.RemoteDir), "/")
+	if strings.HasPrefix(p.SSH.Host, "-") || strings.ContainsAny(p.SSH.Host, " \t'\"") {
+		return fmt.Errorf("project %q ssh.host: invalid host %q", p.Name, p.SSH.Host)
+	}
+	if p.SSH.Port < 0 || p.SSH.Port > 65535 {
+		return fmt.Errorf("project %q ssh.port: must be between 1 and 65535, got %d", p.Name, p.SSH.Port)
+	}
+	if p.SSH.RemoteDir == "" {
+		p.SSH.RemoteDir = ".autopr/worktrees"
+	}
+	if !remoteDirPattern.MatchString(p.SSH.RemoteDir) || slices.Contains(strings.Split(p.SSH.RemoteDir, "/"), "..") {
+		return fmt.Errorf("project %q ssh.remote_dir: %q may only contain letters, digits, '.', '_', '-', and '/', and no '..'", p.Name, p.SSH.RemoteDir)
+	}
+	return nil
+}
+
+func validateKubernetesConfig(k *KubernetesConfig) error {
+	k.WorktreeClaim = strings.TrimSpace(k.WorktreeClaim)
+	k.APIServer = strings.TrimSpace(k.APIServer)
+	if k.WorktreeClaim == "" {
+		return fmt.Errorf("kubernetes.worktree_claim is required by the kubernetes executor")
+	}
+	if k.StartTimeout == "" {
+		k.StartTimeout = "5m"
+	}
+	if d, err := time.ParseDuration(k.StartTimeout); err != nil || d <= 0 {
+		return fmt.Errorf("invalid kubernetes.start_timeout %q: want a positive duration like \"5m\"", k.StartTimeout)
+	}
+	if k.APIServer != "" {
+		u, err := url.Parse(k.APIServer)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("kubernetes.api_server: must be an http(s) URL, got %q", k.APIServer)
+		}
+	}
+	return nil
+}
+
 func normalizeTriggers(triggers []string) ([]string, error) {
 	out := make([]string, 0, len(triggers))
 	seen := make(map[string]struct{}, len(triggers))
@@ -509,7 +2112,7 @@ func normalizeTriggers(triggers []string) ([]string, error) {
 
 func isValidTrigger(trigger string) bool {
 	switch trigger {
-	case TriggerNeedsPR, TriggerFailed, TriggerPRCreated, TriggerPRMerged:
+	case TriggerNeedsPR, TriggerFailed, TriggerPRCreated, TriggerPRMerged, TriggerCIStuck, TriggerQueueStale, TriggerDaemonRecovered, TriggerDeadlineOverdue:
 		return true
 	default:
 		return false
@@ -541,11 +2144,37 @@ func resolvePaths(cfg *Config) {
 	cfg.DBPath = absPath(cfg.BaseDir, cfg.DBPath)
 	cfg.ReposRoot = absPath(cfg.BaseDir, cfg.ReposRoot)
 	cfg.Daemon.PIDFile = absPath(cfg.BaseDir, cfg.Daemon.PIDFile)
-	if cfg.LogFile != "" {
+	cfg.Daemon.BackupDir = absPath(cfg.BaseDir, cfg.Daemon.BackupDir)
+	if cfg.LogFile != "" && cfg.LogFile != LogStderr {
 		cfg.LogFile = absPath(cfg.BaseDir, cfg.LogFile)
 	}
+	if cfg.Network.CABundle != "" {
+		cfg.Network.CABundle = absPath(cfg.BaseDir, cfg.Network.CABundle)
+	}
+	if cfg.Kubernetes.TokenFile != "" {
+		cfg.Kubernetes.TokenFile = absPath(cfg.BaseDir, cfg.Kubernetes.TokenFile)
+	}
+	if cfg.Kubernetes.CAFile != "" {
+		cfg.Kubernetes.CAFile = absPath(cfg.BaseDir, cfg.Kubernetes.CAFile)
+	}
 	for i := range cfg.Projects {
 		p := &cfg.Projects[i]
+		if p.Local != nil && p.Local.IssuesFile != "" {
+			base := LocalRepoPath(p.RepoURL)
+			if base == "" {
+				base = cfg.BaseDir
+			}
+			p.Local.IssuesFile = absPath(base, p.Local.IssuesFile)
+		}
+		if p.SSH != nil && p.SSH.IdentityFile != "" {
+			p.SSH.IdentityFile = absPath(cfg.BaseDir, p.SSH.IdentityFile)
+		}
+		if p.Signing != nil && p.Signing.Format == "ssh" && !strings.HasPrefix(p.Signing.Key, secretRefPrefix) {
+			p.Signing.Key = absPath(cfg.BaseDir, p.Signing.Key)
+		}
+		if p.PolicyFile != "" {
+			p.PolicyFile = absPath(cfg.BaseDir, p.PolicyFile)
+		}
 		if p.Prompts != nil {
 			if p.Prompts.Plan != "" {
 				p.Prompts.Plan = absPath(cfg.BaseDir, p.Prompts.Plan)
@@ -582,20 +2211,103 @@ func (cfg *Config) ProjectByName(name string) (*ProjectConfig, bool) {
 	return nil, false
 }
 
-// GitTokenForProject returns the git token for a project source.
+// TemplateByName returns the job template called name.
+func (cfg *Config) TemplateByName(name string) (*JobTemplate, bool) {
+	for i := range cfg.Templates {
+		if cfg.Templates[i].Name == name {
+			return &cfg.Templates[i], true
+		}
+	}
+	return nil, false
+}
+
+// SyncInterval returns how often p's issues are synced.
+func (cfg *Config) SyncInterval(p *ProjectConfig) time.Duration {
+	return projectInterval(p.SyncInterval, cfg.Daemon.SyncInterval)
+}
+
+// PRCheckInterval returns how often p's open PRs are checked for merges and
+// closes.
+func (cfg *Config) PRCheckInterval(p *ProjectConfig) time.Duration {
+	return projectInterval(p.PRCheckInterval, cfg.Daemon.PRCheckInterval)
+}
+
+// CICheckInterval returns how often CI is polled for p's jobs.
+func (cfg *Config) CICheckInterval(p *ProjectConfig) time.Duration {
+	return projectInterval(p.CICheckInterval, cfg.Daemon.CICheckInterval)
+}
+
+func projectInterval(value, daemonValue string) time.Duration {
+	if value == "" {
+		value = daemonValue
+	}
+	d, _ := time.ParseDuration(value)
+	return d
+}
+
+// IsEnabled reports the project's enabled setting in the config file.
+func (p *ProjectConfig) IsEnabled() bool {
+	return p.Enabled == nil || *p.Enabled
+}
+
+// ProjectEnabled reports whether the named project syncs and runs jobs. An
+// override set with `ap project enable|disable` wins over the config file.
+func (cfg *Config) ProjectEnabled(name string, overrides map[string]bool) bool {
+	if enabled, ok := overrides[name]; ok {
+		return enabled
+	}
+	p, ok := cfg.ProjectByName(name)
+	return !ok || p.IsEnabled()
+}
+
+// DisabledProjects returns the names of configured projects that are disabled.
+func (cfg *Config) DisabledProjects(overrides map[string]bool) []string {
+	var out []string
+	for i := range cfg.Projects {
+		if !cfg.ProjectEnabled(cfg.Projects[i].Name, overrides) {
+			out = append(out, cfg.Projects[i].Name)
+		}
+	}
+	return out
+}
+
+// QueueWeights returns each project's queue_weight, its share of job claims
+// while several projects have jobs queued. Projects without one weigh 1.
+func (cfg *Config) QueueWeights() map[string]int {
+	weights := make(map[string]int, len(cfg.Projects))
+	for _, p := range cfg.Projects {
+		weights[p.Name] = max(p.QueueWeight, 1)
+	}
+	return weights
+}
+
+// GitTokenForProject returns the git token for a project source. It is empty
+// when git_auth.credential_helper supplies credentials instead. GitHub App
+// tokens are minted at runtime by the githubapp package, not returned here.
 func (cfg *Config) GitTokenForProject(p *ProjectConfig) string {
 	if p == nil {
 		return ""
 	}
+	if p.GitAuth != nil && p.GitAuth.CredentialHelper != "" {
+		return ""
+	}
 	if p.GitLab != nil {
 		return cfg.Tokens.GitLab
 	}
 	if p.GitHub != nil {
 		return cfg.Tokens.GitHub
 	}
+	if p.Gitea != nil {
+		return cfg.Tokens.Gitea
+	}
 	return ""
 }
 
+// WebhookAddr is the host:port the webhook and health server listens on.
+func (d DaemonConfig) WebhookAddr() string {
+	return net.JoinHostPort(d.WebhookBind, strconv.Itoa(d.WebhookPort))
+}
+
 func (cfg *Config) SlogLevel() slog.Level {
 	switch cfg.LogLevel {
 	case "debug":
@@ -609,6 +2321,75 @@ func (cfg *Config) SlogLevel() slog.Level {
 	}
 }
 
+// redactedValue replaces secrets in Redacted configs.
+const redactedValue = "[redacted]"
+
+// Redacted returns a copy of cfg that is safe to share: tokens, the webhook
+// and chat command secrets, notification webhook URLs, inline credential
+// helpers, and credentials embedded in URLs are replaced.
+func (cfg *Config) Redacted() Config {
+	out := *cfg
+	redact := func(s *string) {
+		if *s != "" {
+			*s = redactedValue
+		}
+	}
+	redact(&out.Tokens.GitHub)
+	redact(&out.Tokens.GitLab)
+	redact(&out.Tokens.Gitea)
+	redact(&out.Tokens.Sentry)
+	redact(&out.Daemon.WebhookSecret)
+	redact(&out.DBKey)
+	redact(&out.ChatOps.SlackSigningSecret)
+	redact(&out.ChatOps.MattermostToken)
+	out.Secrets = nil
+	redact(&out.Notifications.WebhookURL)
+	redact(&out.Notifications.SlackWebhook)
+	out.Network.HTTPProxy = redactURLUserinfo(out.Network.HTTPProxy)
+	out.Network.HTTPSProxy = redactURLUserinfo(out.Network.HTTPSProxy)
+
+	out.Projects = make([]ProjectConfig, len(cfg.Projects))
+	for i, p := range cfg.Projects {
+		p.RepoURL = redactURLUserinfo(p.RepoURL)
+		// A helper starting with "!" is a shell snippet that may embed a
+		// password; a helper name is kept since it helps debugging.
+		if p.GitAuth != nil && strings.HasPrefix(p.GitAuth.CredentialHelper, "!") {
+			auth := *p.GitAuth
+			auth.CredentialHelper = redactedValue
+			p.GitAuth = &auth
+		}
+		out.Projects[i] = p
+	}
+	return out
+}
+
+// redactURLUserinfo replaces the user:password part of a URL, if any.
+func redactURLUserinfo(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.User == nil {
+		return raw
+	}
+	u.User = url.User("redacted")
+	return u.String()
+}
+
+// UsesExecutor reports whether any project runs its commands through the
+// named executor. An unset executor counts as local.
+func (cfg *Config) UsesExecutor(name string) bool {
+	for _, p := range cfg.Projects {
+		if p.Executor == name || (p.Executor == "" && name == ExecutorLocal) {
+			return true
+		}
+	}
+	return false
+}
+
+// CacheDir returns the directory shared by a project's job worktrees for the
+// named cache.
+func (cfg *Config) CacheDir(projectName, cacheName string) string {
+	return filepath.Join(cfg.ReposRoot, "caches", sanitize(projectName), sanitize(cacheName))
+}
+
 // LocalRepoPath returns the local clone path for a project.
 func (cfg *Config) LocalRepoPath(projectName string) string {
 	return filepath.Join(cfg.ReposRoot, sanitize(projectName))
@@ -0,0 +1,43 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNext(t *testing.T) {
+	t.Parallel()
+	base := time.Date(2026, 3, 14, 10, 30, 0, 0, time.UTC) // Saturday
+	cases := []struct {
+		spec string
+		want time.Time
+	}{
+		{"*/15 * * * *", time.Date(2026, 3, 14, 10, 45, 0, 0, time.UTC)},
+		{"0 9 * * 1", time.Date(2026, 3, 16, 9, 0, 0, 0, time.UTC)},
+		{"@weekly", time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{"30 10 * * *", time.Date(2026, 3, 15, 10, 30, 0, 0, time.UTC)},
+		{"0 12 1,15 * *", time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC)},
+		{"0 8 1 * 1-5", time.Date(2026, 3, 16, 8, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tc := range cases {
+		s, err := Parse(tc.spec)
+		if err != nil {
+			t.Fatalf("parse %q: %v", tc.spec, err)
+		}
+		if got := s.Next(base); !got.Equal(tc.want) {
+			t.Fatalf("%q: next = %s, want %s", tc.spec, got, tc.want)
+		}
+	}
+}
+
+func TestParseRejectsInvalidSpecs(t *testing.T) {
+	t.Parallel()
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "*/0 * * * *", "5-1 * * * *", "a * * * *", "@fortnightly"} {
+		if _, err := Parse(spec); err == nil {
+			t.Fatalf("expected error for %q", spec)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+// Package csvexport writes jobs and their LLM sessions as CSV files for
+// analysis in spreadsheets.
+package csvexport
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"autopr/internal/cost"
+	"autopr/internal/db"
+)
+
+// Steps are the pipeline steps that get their own duration column in
+// jobs.csv; sessions.csv has every session whatever its step.
+var Steps = []string{"plan", "implement", "code_review", "conflict_resolution"}
+
+// jobTotals sums the finished sessions of one job.
+type jobTotals struct {
+	sessions     int
+	stepMS       map[string]int
+	durationMS   int
+	inputTokens  int
+	outputTokens int
+	costUSD      float64
+}
+
+func totalsByJob(sessions []db.SessionStat) map[string]*jobTotals {
+	out := map[string]*jobTotals{}
+	for _, s := range sessions {
+		if s.Status != "completed" && s.Status != "failed" {
+			continue
+		}
+		t := out[s.JobID]
+		if t == nil {
+			t = &jobTotals{stepMS: map[string]int{}}
+			out[s.JobID] = t
+		}
+		t.sessions++
+		t.stepMS[s.Step] += s.DurationMS
+		t.durationMS += s.DurationMS
+		t.inputTokens += s.InputTokens
+		t.outputTokens += s.OutputTokens
+		t.costUSD += cost.Calculate(s.LLMProvider, s.InputTokens, s.OutputTokens)
+	}
+	return out
+}
+
+// WriteJobs writes one row per job: its issue, outcome, time spent queued and
+// running, per-step LLM time, and token totals with an estimated cost.
+func WriteJobs(w io.Writer, jobs []db.Job, sessions []db.SessionStat) error {
+	header := []string{
+		"job_id", "project", "source", "source_issue_id", "issue_title", "issue_url", "tags",
+		"state", "outcome", "created_at", "started_at", "completed_at", "queue_seconds", "run_seconds",
+		"iterations", "max_iterations", "sessions",
+	}
+	for _, step := range Steps {
+		header = append(header, step+"_seconds")
+	}
+	header = append(header, "llm_seconds", "input_tokens", "output_tokens", "est_cost_usd", "pr_url", "pr_merged_at")
+
+	totals := totalsByJob(sessions)
+	cw := csv.NewWriter(w)
+	if err := cw.Write(header); err != nil {
+		return fmt.Errorf("write jobs csv: %w", err)
+	}
+	for _, j := range jobs {
+		t := totals[j.ID]
+		if t == nil {
+			t = &jobTotals{stepMS: map[string]int{}}
+		}
+		row := []string{
+			j.ID, j.ProjectName, j.IssueSource, j.SourceIssueID, text(j.IssueTitle), j.IssueURL, text(strings.Join(j.Tags, " ")),
+			j.State, db.DisplayState(j.State, j.PRMergedAt, j.PRClosedAt), j.CreatedAt, j.StartedAt, j.CompletedAt,
+			secondsBetween(j.CreatedAt, j.StartedAt), secondsBetween(j.StartedAt, j.CompletedAt),
+			strconv.Itoa(j.Iteration), strconv.Itoa(j.MaxIterations), strconv.Itoa(t.sessions),
+		}
+		for _, step := range Steps {
+			row = append(row, seconds(t.stepMS[step]))
+		}
+		row = append(row, seconds(t.durationMS), strconv.Itoa(t.inputTokens), strconv.Itoa(t.outputTokens),
+			usd(t.costUSD), j.PRURL, j.PRMergedAt)
+		if err := cw.Write(row); err != nil {
+			return fmt.Errorf("write jobs csv: %w", err)
+		}
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("write jobs csv: %w", err)
+	}
+	return nil
+}
+
+// WriteSessions writes one row per LLM session of the given jobs.
+func WriteSessions(w io.Writer, jobs []db.Job, sessions []db.SessionStat) error {
+	project := make(map[string]string, len(jobs))
+	for _, j := range jobs {
+		project[j.ID] = j.ProjectName
+	}
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{
+		"session_id", "job_id", "project", "step", "iteration", "provider", "status",
+		"created_at", "completed_at", "duration_seconds", "input_tokens", "output_tokens", "est_cost_usd",
+	}); err != nil {
+		return fmt.Errorf("write sessions csv: %w", err)
+	}
+	for _, s := range sessions {
+		p, ok := project[s.JobID]
+		if !ok {
+			continue
+		}
+		if err := cw.Write([]string{
+			strconv.Itoa(s.ID), s.JobID, p, s.Step, strconv.Itoa(s.Iteration), s.LLMProvider, s.Status,
+			s.CreatedAt, s.CompletedAt, seconds(s.DurationMS), strconv.Itoa(s.InputTokens), strconv.Itoa(s.OutputTokens),
+			usd(cost.Calculate(s.LLMProvider, s.InputTokens, s.OutputTokens)),
+		}); err != nil {
+			return fmt.Errorf("write sessions csv: %w", err)
+		}
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("write sessions csv: %w", err)
+	}
+	return nil
+}
+
+// text guards free text from issue trackers against spreadsheet formula
+// injection by prefixing values that a spreadsheet would evaluate with '.
+func text(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+func seconds(ms int) string {
+	return strconv.FormatFloat(float64(ms)/1000, 'f', 1, 64)
+}
+
+func usd(v float64) string {
+	return strconv.FormatFloat(v, 'f', 4, 64)
+}
+
+// secondsBetween returns the whole seconds from one RFC3339 time to another,
+// or "" if either is unset.
+func secondsBetween(from, to string) string {
+	a, err := time.Parse(time.RFC3339, from)
+	if err != nil {
+		return ""
+	}
+	b, err := time.Parse(time.RFC3339, to)
+	if err != nil || b.Before(a) {
+		return ""
+	}
+	return strconv.Itoa(int(b.Sub(a).Seconds()))
+}
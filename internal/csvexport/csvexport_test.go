@@ -0,0 +1,89 @@
+package csvexport
+
+import (
+	"encoding/csv"
+	"strings"
+	"testing"
+
+	"autopr/internal/db"
+)
+
+func readCSV(t *testing.T, s string) []map[string]string {
+	t.Helper()
+	records, err := csv.NewReader(strings.NewReader(s)).ReadAll()
+	if err != nil {
+		t.Fatalf("parse csv: %v", err)
+	}
+	var out []map[string]string
+	for _, rec := range records[1:] {
+		row := map[string]string{}
+		for i, name := range records[0] {
+			row[name] = rec[i]
+		}
+		out = append(out, row)
+	}
+	return out
+}
+
+func TestWriteJobsAndSessions(t *testing.T) {
+	t.Parallel()
+
+	jobs := []db.Job{
+		{
+			ID: "ap-job-1111111111111111", ProjectName: "web", IssueSource: "github", SourceIssueID: "12",
+			IssueTitle: "=HYPERLINK(\"http://evil\")", Tags: []string{"ci", "flaky"},
+			State: "approved", PRMergedAt: "2026-03-03T08:00:00Z", PRURL: "https://github.com/acme/web/pull/9",
+			CreatedAt: "2026-03-02T09:00:00Z", StartedAt: "2026-03-02T09:05:00Z", CompletedAt: "2026-03-02T09:45:00Z",
+			Iteration: 1, MaxIterations: 3,
+		},
+		{ID: "ap-job-2222222222222222", ProjectName: "api", IssueTitle: "Queued, not started", State: "queued", CreatedAt: "2026-03-04T09:00:00Z"},
+	}
+	sessions := []db.SessionStat{
+		{ID: 1, JobID: jobs[0].ID, Step: "plan", LLMProvider: "claude", Status: "completed", InputTokens: 1_000_000, OutputTokens: 100_000, DurationMS: 60_000},
+		{ID: 2, JobID: jobs[0].ID, Step: "implement", LLMProvider: "claude", Status: "completed", InputTokens: 2000, OutputTokens: 500, DurationMS: 125_500},
+		{ID: 3, JobID: jobs[0].ID, Step: "implement", Iteration: 1, LLMProvider: "claude", Status: "running", DurationMS: 5000},
+		{ID: 4, JobID: "ap-job-elsewhere", Step: "plan", LLMProvider: "claude", Status: "completed"},
+	}
+
+	var b strings.Builder
+	if err := WriteJobs(&b, jobs, sessions); err != nil {
+		t.Fatalf("write jobs: %v", err)
+	}
+	rows := readCSV(t, b.String())
+	if len(rows) != 2 {
+		t.Fatalf("expected 2 job rows, got %d", len(rows))
+	}
+	for col, want := range map[string]string{
+		"issue_title":       "'=HYPERLINK(\"http://evil\")",
+		"tags":              "ci flaky",
+		"outcome":           "merged",
+		"queue_seconds":     "300",
+		"run_seconds":       "2400",
+		"sessions":          "2",
+		"plan_seconds":      "60.0",
+		"implement_seconds": "125.5",
+		"llm_seconds":       "185.5",
+		"input_tokens":      "1002000",
+		"output_tokens":     "100500",
+		"est_cost_usd":      "4.5135",
+	} {
+		if got := rows[0][col]; got != want {
+			t.Fatalf("jobs.csv %s: got %q, want %q", col, got, want)
+		}
+	}
+	if rows[1]["queue_seconds"] != "" || rows[1]["sessions"] != "0" || rows[1]["issue_title"] != "Queued, not started" {
+		t.Fatalf("unexpected row for a queued job: %v", rows[1])
+	}
+
+	b.Reset()
+	if err := WriteSessions(&b, jobs, sessions); err != nil {
+		t.Fatalf("write sessions: %v", err)
+	}
+	rows = readCSV(t, b.String())
+	if len(rows) != 3 {
+		t.Fatalf("expected the 3 sessions of exported jobs, got %d", len(rows))
+	}
+	if r := rows[1]; r["session_id"] != "2" || r["project"] != "web" || r["step"] != "implement" || r["duration_seconds"] != "125.5" {
+		t.Fatalf("unexpected session row %v", r)
+	}
+}
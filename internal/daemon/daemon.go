@@ -14,21 +14,34 @@ import (
 
 	"autopr/internal/config"
 	"autopr/internal/db"
+	"autopr/internal/deadlinewatch"
+	"autopr/internal/executor"
+	"autopr/internal/idle"
 	"autopr/internal/issuesync"
+	"autopr/internal/kube"
 	"autopr/internal/llm"
 	"autopr/internal/notify"
 	"autopr/internal/pipeline"
+	"autopr/internal/queuewatch"
+	"autopr/internal/tracing"
 	"autopr/internal/webhook"
 	"autopr/internal/worker"
 )
 
+// outboxInterval is how often operations deferred while the network was down
+// are retried.
+const outboxInterval = 30 * time.Second
+
 // Run starts the daemon: webhook server + worker pool + sync loop.
-// Blocks until SIGINT/SIGTERM is received.
+// Blocks until SIGINT/SIGTERM is received or `ap stop` requests a stop.
 func Run(cfg *config.Config, foreground bool) error {
-	// Write PID file.
+	// Write PID file. A PID file left behind means the last daemon did not
+	// shut down cleanly; WritePID fails if that daemon is still running.
 	if err := os.MkdirAll(filepath.Dir(cfg.Daemon.PIDFile), 0o755); err != nil {
 		return fmt.Errorf("create pid dir: %w", err)
 	}
+	_, statErr := os.Stat(cfg.Daemon.PIDFile)
+	uncleanShutdown := statErr == nil
 	if err := WritePID(cfg.Daemon.PIDFile); err != nil {
 		return err
 	}
@@ -38,7 +51,7 @@ func Run(cfg *config.Config, foreground bool) error {
 	if err := os.MkdirAll(filepath.Dir(cfg.DBPath), 0o755); err != nil {
 		return fmt.Errorf("create db dir: %w", err)
 	}
-	store, err := db.Open(cfg.DBPath)
+	store, err := db.OpenWithKey(cfg.DBPath, cfg.DBKey)
 	if err != nil {
 		return fmt.Errorf("open db: %w", err)
 	}
@@ -49,8 +62,8 @@ func Run(cfg *config.Config, foreground bool) error {
 	if err != nil {
 		return fmt.Errorf("crash recovery: %w", err)
 	}
-	if recovered > 0 {
-		slog.Info("recovered in-flight jobs", "count", recovered)
+	if len(recovered) > 0 {
+		slog.Info("recovered in-flight jobs", "count", len(recovered))
 	}
 	recoveredSessions, err := store.RecoverRunningSessions(context.Background())
 	if err != nil {
@@ -59,10 +72,33 @@ func Run(cfg *config.Config, foreground bool) error {
 	if recoveredSessions > 0 {
 		slog.Info("recovered stale llm sessions", "count", recoveredSessions)
 	}
+	if uncleanShutdown {
+		summary := recoverySummary(recovered, recoveredSessions)
+		slog.Warn("daemon restarted after an unclean shutdown", "jobs", len(recovered), "sessions", recoveredSessions)
+		if _, err := store.EnqueueDaemonNotificationEvent(context.Background(), db.NotificationEventDaemonRecovered, summary); err != nil {
+			slog.Warn("enqueue recovery notification", "err", err)
+		}
+	}
+
+	// Tracing: export one trace per job when [tracing] is enabled.
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing)
+	if err != nil {
+		return fmt.Errorf("setup tracing: %w", err)
+	}
+	defer func() {
+		flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(flushCtx); err != nil {
+			slog.Warn("flush traces", "err", err)
+		}
+	}()
 
-	// Signal context.
+	// Signal context. `ap stop` writes a stop file instead where SIGTERM
+	// cannot be sent (Windows).
 	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer stop()
+	ctx, stopWatch := watchStopFile(ctx, cfg.Daemon.PIDFile)
+	defer stopWatch()
 
 	// Create LLM provider.
 	provider := llm.NewCLIProvider(cfg.LLM.Provider)
@@ -70,6 +106,16 @@ func Run(cfg *config.Config, foreground bool) error {
 	// Create pipeline runner.
 	pipelineRunner := pipeline.New(store, provider, cfg)
 
+	// Kubernetes executor: commands of projects using it run as Kubernetes Jobs.
+	var kubeExecutor *kube.Executor
+	if cfg.UsesExecutor(config.ExecutorKubernetes) {
+		kubeExecutor, err = kube.NewExecutor(cfg)
+		if err != nil {
+			return fmt.Errorf("kubernetes executor: %w", err)
+		}
+		pipelineRunner.SetKubernetes(kubeExecutor)
+	}
+
 	// Create job channel (notification-only, SQLite is authoritative).
 	jobCh := make(chan string, 100)
 
@@ -86,13 +132,23 @@ func Run(cfg *config.Config, foreground bool) error {
 	}
 
 	// Start worker pool.
-	pool := worker.NewPool(cfg.Daemon.MaxWorkers, store, pipelineRunner, jobCh)
+	pool := worker.NewPool(cfg.Daemon.MaxWorkers, cfg, store, pipelineRunner, jobCh)
 	pool.Start(ctx)
 
+	// Idle mode: with idle_after set, the workers stop and sync slows down
+	// while there is nothing to do.
+	var idleMode *idle.Mode
+	idleAfter, _ := time.ParseDuration(cfg.Daemon.IdleAfter)
+	idleSyncInterval, _ := time.ParseDuration(cfg.Daemon.IdleSyncInterval)
+	if idleAfter > 0 {
+		idleMode = idle.NewMode()
+	}
+
 	// Start webhook server.
 	whSrv := webhook.NewServer(cfg, store, jobCh)
+	whSrv.SetWorkerPool(pool)
 	httpSrv := &http.Server{
-		Addr:         fmt.Sprintf("127.0.0.1:%d", cfg.Daemon.WebhookPort),
+		Addr:         cfg.Daemon.WebhookAddr(),
 		Handler:      whSrv,
 		ReadTimeout:  10 * time.Second,
 		WriteTimeout: 10 * time.Second,
@@ -113,10 +169,22 @@ func Run(cfg *config.Config, foreground bool) error {
 	if syncInterval > 0 {
 		wg.Go(func() {
 			syncer := issuesync.NewSyncer(cfg, store, jobCh)
+			syncer.SetIdle(idleMode, idleSyncInterval)
 			syncer.RunLoop(ctx, syncInterval)
 		})
 	}
 
+	// PR merge/close detection goroutine, on its own interval so it can run
+	// more often than issue sync.
+	prInterval, _ := time.ParseDuration(cfg.Daemon.PRCheckInterval)
+	if prInterval > 0 {
+		wg.Go(func() {
+			syncer := issuesync.NewSyncer(cfg, store, jobCh)
+			syncer.SetIdle(idleMode, idleSyncInterval)
+			syncer.RunPRLoop(ctx, prInterval)
+		})
+	}
+
 	// CI check-run polling goroutine (separate from sync loop for responsive CI feedback).
 	ciInterval, _ := time.ParseDuration(cfg.Daemon.CICheckInterval)
 	if ciInterval <= 0 {
@@ -124,18 +192,77 @@ func Run(cfg *config.Config, foreground bool) error {
 	}
 	wg.Go(func() {
 		syncer := issuesync.NewSyncer(cfg, store, jobCh)
-		ticker := time.NewTicker(ciInterval)
-		defer ticker.Stop()
-		for {
-			select {
-			case <-ctx.Done():
-				return
-			case <-ticker.C:
-				syncer.CheckCIStatus(ctx)
-			}
-		}
+		syncer.RunCILoop(ctx, ciInterval)
 	})
 
+	// CI watchdog goroutine: re-polls and escalates jobs whose CI has been pending too long.
+	if staleAfter, _ := time.ParseDuration(cfg.Daemon.CIStaleAfter); staleAfter > 0 {
+		wg.Go(func() {
+			syncer := issuesync.NewSyncer(cfg, store, jobCh)
+			syncer.RunCIWatchdog(ctx, staleAfter)
+		})
+	}
+
+	// Idle goroutine: stops the workers after idle_after without work and
+	// restarts them when a job is queued or the TUI asks.
+	if idleMode != nil {
+		wg.Go(func() {
+			newIdler(idleAfter, cfg.Daemon.PIDFile, store, pool, jobCh, idleMode).run(ctx)
+		})
+	}
+
+	// Queue watch goroutine: alerts on jobs that stay queued too long.
+	if queuewatch.StaleAfter(cfg) > 0 {
+		wg.Go(func() {
+			queuewatch.New(cfg, store).Run(ctx)
+		})
+	}
+
+	// Deadline watch goroutine: alerts on jobs that miss their deadline.
+	wg.Go(func() {
+		deadlinewatch.New(store).Run(ctx)
+	})
+
+	// Kubernetes reconcile goroutine: deletes Kubernetes Jobs no worker is
+	// waiting on, such as ones left by a crashed daemon.
+	if kubeExecutor != nil {
+		wg.Go(func() {
+			kubeExecutor.RunReconcileLoop(ctx)
+		})
+	}
+
+	// SSH prune goroutines: remove job copies on build hosts once the local
+	// worktree is gone.
+	for _, target := range executor.SSHTargets(cfg) {
+		wg.Go(func() {
+			target.RunPruneLoop(ctx)
+		})
+	}
+
+	// Outbox goroutine: replays operations deferred while the network was down.
+	wg.Go(func() {
+		pipelineRunner.RunOutbox(ctx, outboxInterval, jobCh)
+	})
+
+	// Scheduled database backups.
+	if backupInterval, _ := time.ParseDuration(cfg.Daemon.BackupInterval); backupInterval > 0 {
+		wg.Go(func() {
+			store.RunBackupLoop(ctx, cfg.Daemon.BackupDir, backupInterval, cfg.Daemon.BackupKeep)
+		})
+	}
+
+	// Retention: delete LLM prompt and response text past its age limit.
+	if after, _ := time.ParseDuration(cfg.Retention.SessionTextAfter); after > 0 {
+		wg.Go(func() {
+			store.RunRetentionLoop(ctx, after)
+		})
+	}
+	if after, _ := time.ParseDuration(cfg.Retention.DeletedJobsAfter); after > 0 {
+		wg.Go(func() {
+			store.RunTrashLoop(ctx, after)
+		})
+	}
+
 	// Notification dispatcher goroutine.
 	notificationDispatcher := notify.NewDispatcher(
 		store,
@@ -0,0 +1,151 @@
+package daemon
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"time"
+
+	"autopr/internal/db"
+	"autopr/internal/idle"
+	"autopr/internal/worker"
+)
+
+// idleCheckInterval is how often the daemon looks for work: while awake, to
+// tell when it ran out, and while idle, for a wake request or a job queued
+// without a notification, such as by `ap retry`.
+const idleCheckInterval = 5 * time.Second
+
+// wakeFilePath is where RequestWake asks the daemon owning pidFile to wake.
+func wakeFilePath(pidFile string) string {
+	return pidFile + ".wake"
+}
+
+// idleFilePath exists while the daemon owning pidFile is idle.
+func idleFilePath(pidFile string) string {
+	return pidFile + ".idle"
+}
+
+// RequestWake asks the daemon owning pidFile to leave idle mode, or to put off
+// entering it when awake. The TUI calls it when it opens and on refresh.
+func RequestWake(pidFile string) error {
+	return os.WriteFile(wakeFilePath(pidFile), nil, 0o644)
+}
+
+// IsIdle reports whether the daemon owning pidFile is in idle mode.
+func IsIdle(pidFile string) bool {
+	_, err := os.Stat(idleFilePath(pidFile))
+	return err == nil
+}
+
+// idler puts the daemon into idle mode once it has gone after without a
+// queued or running job: the worker pool stops and the sync loops slow down
+// (see issuesync.Syncer.SetIdle). A job notification, a queued job, or a wake
+// request restarts the pool at once.
+type idler struct {
+	after   time.Duration
+	pidFile string
+	store   *db.Store
+	pool    *worker.Pool
+	jobCh   chan string
+	mode    *idle.Mode
+
+	lastActive time.Time
+}
+
+func newIdler(after time.Duration, pidFile string, store *db.Store, pool *worker.Pool, jobCh chan string, mode *idle.Mode) *idler {
+	return &idler{after: after, pidFile: pidFile, store: store, pool: pool, jobCh: jobCh, mode: mode, lastActive: time.Now()}
+}
+
+func (d *idler) run(ctx context.Context) {
+	// A wake request left from an earlier run means nothing to this one.
+	_ = os.Remove(wakeFilePath(d.pidFile))
+	defer os.Remove(idleFilePath(d.pidFile))
+
+	ticker := time.NewTicker(idleCheckInterval)
+	defer ticker.Stop()
+	for {
+		// The stopped workers no longer read their channel, so a job
+		// notification reaches the idler instead and wakes the daemon.
+		var notified <-chan string
+		if d.mode.Idle() {
+			notified = d.jobCh
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case jobID := <-notified:
+			d.lastActive = time.Now()
+			d.wake(ctx, "job notified")
+			select {
+			case d.jobCh <- jobID:
+			default:
+			}
+		case now := <-ticker.C:
+			d.check(ctx, now)
+		}
+	}
+}
+
+// check wakes the daemon when there is work, or puts it to sleep once there
+// has been none for d.after.
+func (d *idler) check(ctx context.Context, now time.Time) {
+	if reason := d.activity(ctx); reason != "" {
+		d.lastActive = now
+		d.wake(ctx, reason)
+		return
+	}
+	if !d.mode.Idle() && now.Sub(d.lastActive) >= d.after {
+		d.sleep(ctx, now)
+	}
+}
+
+// activity returns what keeps the daemon awake, or "" when nothing does. A
+// job count that cannot be read keeps it awake.
+func (d *idler) activity(ctx context.Context) string {
+	if err := os.Remove(wakeFilePath(d.pidFile)); err == nil {
+		return "wake requested"
+	}
+	if d.pool.Busy() > 0 {
+		return "job running"
+	}
+	n, err := d.store.CountActiveJobs(ctx)
+	if err != nil {
+		slog.Warn("idle check: count active jobs", "err", err)
+		return "job count unavailable"
+	}
+	if n > 0 {
+		return "jobs queued or in flight"
+	}
+	return ""
+}
+
+// sleep stops the workers and enters idle mode. A worker may have claimed a
+// job since check looked, and stopping the pool would cancel it mid-step, so
+// claims are paused and the activity checked again first.
+func (d *idler) sleep(ctx context.Context, now time.Time) {
+	d.pool.Pause()
+	if reason := d.activity(ctx); reason != "" {
+		d.pool.Resume()
+		d.lastActive = now
+		slog.Debug("idle check: staying awake", "reason", reason)
+		return
+	}
+	d.pool.Stop()
+	if !d.mode.Sleep() {
+		return
+	}
+	if err := os.WriteFile(idleFilePath(d.pidFile), nil, 0o644); err != nil {
+		slog.Warn("write idle file", "err", err)
+	}
+	slog.Info("daemon idle, workers stopped", "idle_after", d.after)
+}
+
+func (d *idler) wake(ctx context.Context, reason string) {
+	if ctx.Err() != nil || !d.mode.Wake() {
+		return
+	}
+	_ = os.Remove(idleFilePath(d.pidFile))
+	d.pool.Start(ctx)
+	slog.Info("daemon woke from idle, workers started", "reason", reason)
+}
@@ -0,0 +1,100 @@
+package daemon
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"autopr/internal/config"
+	"autopr/internal/db"
+	"autopr/internal/idle"
+	"autopr/internal/worker"
+)
+
+func TestIdlerSleepsWithoutWorkAndWakesOnRequest(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	dir := t.TempDir()
+	store, err := db.Open(filepath.Join(dir, "autopr.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer store.Close()
+
+	pidFile := filepath.Join(dir, "autopr.pid")
+	jobCh := make(chan string, 1)
+	pool := worker.NewPool(1, &config.Config{}, store, nil, jobCh)
+	pool.Start(ctx)
+	defer pool.Stop()
+	mode := idle.NewMode()
+	d := newIdler(10*time.Minute, pidFile, store, pool, jobCh, mode)
+	start := d.lastActive
+
+	d.check(ctx, start.Add(5*time.Minute))
+	if mode.Idle() || !pool.Running() {
+		t.Fatal("went idle before idle_after passed")
+	}
+	d.check(ctx, start.Add(10*time.Minute))
+	if !mode.Idle() || pool.Running() || !IsIdle(pidFile) {
+		t.Fatal("expected idle with the workers stopped after idle_after")
+	}
+
+	// The TUI asks the daemon to wake.
+	if err := RequestWake(pidFile); err != nil {
+		t.Fatalf("request wake: %v", err)
+	}
+	d.check(ctx, start.Add(20*time.Minute))
+	if mode.Idle() || !pool.Running() || IsIdle(pidFile) {
+		t.Fatal("expected the wake request to restart the workers")
+	}
+
+	// The request also put off idling for another idle_after.
+	d.check(ctx, start.Add(29*time.Minute))
+	if mode.Idle() {
+		t.Fatal("went idle too soon after a wake request")
+	}
+	d.check(ctx, start.Add(30*time.Minute))
+	if !mode.Idle() {
+		t.Fatal("expected idle again idle_after past the wake request")
+	}
+}
+
+func TestIdlerStaysAwakeForAJobClaimedBeforeSleep(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	dir := t.TempDir()
+	store, err := db.Open(filepath.Join(dir, "autopr.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer store.Close()
+
+	pidFile := filepath.Join(dir, "autopr.pid")
+	jobCh := make(chan string, 1)
+	pool := worker.NewPool(1, &config.Config{}, store, nil, jobCh)
+	pool.Start(ctx)
+	defer pool.Stop()
+	mode := idle.NewMode()
+	d := newIdler(10*time.Minute, pidFile, store, pool, jobCh, mode)
+
+	// A job turns up after check found none, as when a worker claims one
+	// between the check and the sleep.
+	issueID, err := store.UpsertIssue(ctx, db.IssueUpsert{ProjectName: "myproject", Source: "github", SourceIssueID: "1", Title: "bug", State: "open"})
+	if err != nil {
+		t.Fatalf("upsert issue: %v", err)
+	}
+	if _, err := store.CreateJob(ctx, issueID, "myproject", 3); err != nil {
+		t.Fatalf("create job: %v", err)
+	}
+	now := d.lastActive.Add(10 * time.Minute)
+	d.sleep(ctx, now)
+	if mode.Idle() || !pool.Running() || IsIdle(pidFile) {
+		t.Fatal("expected the daemon to stay awake with a job in flight")
+	}
+	if d.lastActive != now {
+		t.Fatal("expected the job to count as activity")
+	}
+}
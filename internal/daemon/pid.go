@@ -5,7 +5,6 @@ import (
 	"os"
 	"strconv"
 	"strings"
-	"syscall"
 )
 
 // WritePID creates the PID file atomically with O_EXCL.
@@ -53,21 +52,16 @@ func IsRunning(path string) bool {
 	if err != nil {
 		return false
 	}
-	return ProcessAlive(pid)
+	return !isOwnPID(pid) && ProcessAlive(pid)
 }
 
-// RemovePID removes the PID file.
+// RemovePID removes the PID file, any pending stop or wake request, and the
+// idle marker.
 func RemovePID(path string) {
 	_ = os.Remove(path)
-}
-
-// ProcessAlive checks whether the given PID is still running.
-func ProcessAlive(pid int) bool {
-	proc, err := os.FindProcess(pid)
-	if err != nil {
-		return false
-	}
-	return proc.Signal(syscall.Signal(0)) == nil
+	_ = os.Remove(stopFilePath(path))
+	_ = os.Remove(wakeFilePath(path))
+	_ = os.Remove(idleFilePath(path))
 }
 
 func cleanStalePID(path string) bool {
@@ -76,9 +70,16 @@ func cleanStalePID(path string) bool {
 		_ = os.Remove(path)
 		return true
 	}
-	if !ProcessAlive(pid) {
+	if isOwnPID(pid) || !ProcessAlive(pid) {
 		_ = os.Remove(path)
 		return true
 	}
 	return false
 }
+
+// isOwnPID reports whether a PID file names this process, which means it was
+// left by an earlier run that had the same PID: typically PID 1 in a
+// restarted container whose state directory is a volume.
+func isOwnPID(pid int) bool {
+	return pid == os.Getpid()
+}
@@ -0,0 +1,25 @@
+package daemon
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWritePIDReplacesFileNamingThisProcess(t *testing.T) {
+	t.Parallel()
+
+	// A restarted container runs as PID 1 again and finds the PID file its
+	// previous run left on the volume.
+	path := filepath.Join(t.TempDir(), "autopr.pid")
+	if err := os.WriteFile(path, fmt.Appendf(nil, "%d\n", os.Getpid()), 0o644); err != nil {
+		t.Fatalf("write pid file: %v", err)
+	}
+	if IsRunning(path) {
+		t.Fatal("a PID file naming this process must not count as a running daemon")
+	}
+	if err := WritePID(path); err != nil {
+		t.Fatalf("write pid: %v", err)
+	}
+}
@@ -0,0 +1,39 @@
+//go:build windows
+
+package daemon
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+const (
+	processQueryLimitedInformation = 0x1000
+	stillActive                    = 259
+)
+
+// ProcessAlive checks whether the given PID is still running. Windows has no
+// signal 0, so the process's exit code is queried instead.
+func ProcessAlive(pid int) bool {
+	h, err := syscall.OpenProcess(processQueryLimitedInformation, false, uint32(pid))
+	if err != nil {
+		return false
+	}
+	defer syscall.CloseHandle(h)
+	var code uint32
+	if err := syscall.GetExitCodeProcess(h, &code); err != nil {
+		return false
+	}
+	return code == stillActive
+}
+
+// RequestStop asks the daemon with the given PID to shut down gracefully.
+// Windows cannot deliver SIGTERM to a detached process, so a stop file is
+// written next to the PID file for the daemon to notice.
+func RequestStop(pidFile string, pid int) error {
+	if !ProcessAlive(pid) {
+		return fmt.Errorf("process %d is not running", pid)
+	}
+	return os.WriteFile(stopFilePath(pidFile), []byte(fmt.Sprintf("%d\n", pid)), 0o644)
+}
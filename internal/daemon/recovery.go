@@ -0,0 +1,62 @@
+package daemon
+
+import (
+	"fmt"
+	"strings"
+
+	"autopr/internal/db"
+)
+
+// repeatedRecoveries is how many restarts may interrupt a job before the
+// recovery summary flags it: a job that keeps being in flight when the
+// daemon dies may be what kills it.
+const repeatedRecoveries = 2
+
+// recoverySummary describes what crash recovery reset, for the
+// daemon_recovered notification. The first line stands alone.
+func recoverySummary(jobs []db.RecoveredJob, sessions int64) string {
+	var requeued, ready int
+	var attention []string
+	for _, job := range jobs {
+		if job.State == "ready" {
+			ready++
+		} else {
+			requeued++
+		}
+		if job.Recoveries >= repeatedRecoveries {
+			attention = append(attention, fmt.Sprintf("- %s (%s): interrupted in %s by %d restarts; it may be crashing the daemon",
+				db.ShortID(job.ID), job.Project, job.FromState, job.Recoveries))
+		}
+	}
+
+	var parts []string
+	if requeued > 0 {
+		parts = append(parts, "requeued "+plural(requeued, "job"))
+	}
+	if ready > 0 {
+		parts = append(parts, fmt.Sprintf("returned %s with an interrupted rebase to ready", plural(ready, "job")))
+	}
+	if sessions > 0 {
+		parts = append(parts, fmt.Sprintf("marked %s failed", plural(int(sessions), "LLM session")))
+	}
+
+	var b strings.Builder
+	b.WriteString("Restarted after an unclean shutdown: ")
+	if len(parts) == 0 {
+		b.WriteString("nothing was in flight.")
+	} else {
+		b.WriteString(strings.Join(parts, ", ") + ".")
+	}
+	if len(attention) > 0 {
+		b.WriteString("\nNeeds attention:\n")
+		b.WriteString(strings.Join(attention, "\n"))
+	}
+	return b.String()
+}
+
+func plural(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
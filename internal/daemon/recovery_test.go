@@ -0,0 +1,47 @@
+package daemon
+
+import (
+	"testing"
+
+	"autopr/internal/db"
+)
+
+func TestRecoverySummary(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name     string
+		jobs     []db.RecoveredJob
+		sessions int64
+		want     string
+	}{
+		{
+			name: "nothing in flight",
+			want: "Restarted after an unclean shutdown: nothing was in flight.",
+		},
+		{
+			name: "requeued and ready",
+			jobs: []db.RecoveredJob{
+				{ID: "ap-job-1111111111111111", Project: "web", FromState: "implementing", State: "queued", Recoveries: 1},
+				{ID: "ap-job-2222222222222222", Project: "web", FromState: "rebasing", State: "ready", Recoveries: 1},
+			},
+			sessions: 2,
+			want:     "Restarted after an unclean shutdown: requeued 1 job, returned 1 job with an interrupted rebase to ready, marked 2 LLM sessions failed.",
+		},
+		{
+			name: "repeatedly interrupted job",
+			jobs: []db.RecoveredJob{
+				{ID: "ap-job-3333333333333333", Project: "api", FromState: "testing", State: "queued", Recoveries: 3},
+				{ID: "ap-job-4444444444444444", Project: "api", FromState: "planning", State: "queued", Recoveries: 1},
+			},
+			sessions: 1,
+			want: "Restarted after an unclean shutdown: requeued 2 jobs, marked 1 LLM session failed.\n" +
+				"Needs attention:\n" +
+				"- 33333333 (api): interrupted in testing by 3 restarts; it may be crashing the daemon",
+		},
+	} {
+		if got := recoverySummary(tc.jobs, tc.sessions); got != tc.want {
+			t.Fatalf("%s:\ngot  %q\nwant %q", tc.name, got, tc.want)
+		}
+	}
+}
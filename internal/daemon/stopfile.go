@@ -0,0 +1,41 @@
+package daemon
+
+import (
+	"context"
+	"os"
+	"time"
+)
+
+// stopFilePollInterval is how often the daemon checks for a stop file.
+const stopFilePollInterval = time.Second
+
+// stopFilePath is where RequestStop asks the daemon owning pidFile to stop on
+// platforms without SIGTERM.
+func stopFilePath(pidFile string) string {
+	return pidFile + ".stop"
+}
+
+// watchStopFile returns a context that is cancelled once a stop file appears
+// next to pidFile. A stop file left from an earlier run is removed first so it
+// cannot stop this daemon.
+func watchStopFile(ctx context.Context, pidFile string) (context.Context, context.CancelFunc) {
+	path := stopFilePath(pidFile)
+	_ = os.Remove(path)
+	ctx, cancel := context.WithCancel(ctx)
+	go func() {
+		ticker := time.NewTicker(stopFilePollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := os.Stat(path); err == nil {
+					cancel()
+					return
+				}
+			}
+		}
+	}()
+	return ctx, cancel
+}
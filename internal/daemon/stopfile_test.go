@@ -0,0 +1,41 @@
+package daemon
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatchStopFileCancelsOnStopRequest(t *testing.T) {
+	t.Parallel()
+
+	pidFile := filepath.Join(t.TempDir(), "autopr.pid")
+	// A stop file left by an earlier run must not stop the new daemon.
+	if err := os.WriteFile(stopFilePath(pidFile), []byte("1\n"), 0o644); err != nil {
+		t.Fatalf("write stale stop file: %v", err)
+	}
+	ctx, cancel := watchStopFile(context.Background(), pidFile)
+	defer cancel()
+
+	select {
+	case <-ctx.Done():
+		t.Fatal("stale stop file stopped the daemon")
+	case <-time.After(2 * stopFilePollInterval):
+	}
+
+	if err := os.WriteFile(stopFilePath(pidFile), []byte("1\n"), 0o644); err != nil {
+		t.Fatalf("write stop file: %v", err)
+	}
+	select {
+	case <-ctx.Done():
+	case <-time.After(5 * stopFilePollInterval):
+		t.Fatal("stop file did not stop the daemon")
+	}
+
+	RemovePID(pidFile)
+	if _, err := os.Stat(stopFilePath(pidFile)); !os.IsNotExist(err) {
+		t.Fatalf("expected stop file removed with the PID file, err=%v", err)
+	}
+}
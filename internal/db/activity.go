@@ -0,0 +1,153 @@
+package db
+
+import (
+	"context"
+	"fmt"
+)
+
+// JobActivity is when a job ran and whether its PR merged, for activity
+// feeds such as `ap export-ics`.
+type JobActivity struct {
+	JobID       string
+	ProjectName string
+	IssueTitle  string
+	IssueURL    string
+	State       string
+	StartedAt   string
+	CompletedAt string
+	PRURL       string
+	PRMergedAt  string
+}
+
+// ListJobActivity returns jobs that finished running or had their PR merged
+// at or after since (RFC3339), oldest first. An empty project matches every project.
+func (s *Store) ListJobActivity(ctx context.Context, project, since string) ([]JobActivity, error) {
+	q := `
+SELECT j.id, j.project_name, COALESCE(i.title,''), COALESCE(i.url,''), j.state,
+       COALESCE(j.started_at,''), COALESCE(j.completed_at,''), COALESCE(j.pr_url,''), COALESCE(j.pr_merged_at,'')
+FROM jobs j
+LEFT JOIN issues i ON i.autopr_issue_id = j.autopr_issue_id
+WHERE (COALESCE(j.completed_at,'') >= ? OR COALESCE(j.pr_merged_at,'') >= ?)
+  AND (COALESCE(j.completed_at,'') != '' OR COALESCE(j.pr_merged_at,'') != '')`
+	args := []any{since, since}
+	if project != "" {
+		q += ` AND j.project_name = ?`
+		args = append(args, project)
+	}
+	q += ` ORDER BY COALESCE(NULLIF(j.completed_at,''), j.pr_merged_at) ASC, j.id`
+
+	rows, err := s.Reader.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list job activity: %w", err)
+	}
+	defer rows.Close()
+
+	var out []JobActivity
+	for rows.Next() {
+		var a JobActivity
+		if err := rows.Scan(&a.JobID, &a.ProjectName, &a.IssueTitle, &a.IssueURL, &a.State,
+			&a.StartedAt, &a.CompletedAt, &a.PRURL, &a.PRMergedAt); err != nil {
+			return nil, fmt.Errorf("scan job activity: %w", err)
+		}
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
+
+// SessionStat is an LLM session's step, timing, and token counts, without
+// its prompt and response text.
+type SessionStat struct {
+	ID           int
+	JobID        string
+	Step         string
+	Iteration    int
+	LLMProvider  string
+	Status       string
+	InputTokens  int
+	OutputTokens int
+	DurationMS   int
+	CreatedAt    string
+	CompletedAt  string
+}
+
+// ListSessionStats returns the sessions of jobs created at or after since
+// (RFC3339), in the order they ran. An empty project matches every project.
+func (s *Store) ListSessionStats(ctx context.Context, project, since string) ([]SessionStat, error) {
+	q := `
+SELECT l.id, l.job_id, l.step, l.iteration, l.llm_provider, l.status,
+       COALESCE(l.input_tokens,0), COALESCE(l.output_tokens,0), COALESCE(l.duration_ms,0),
+       l.created_at, COALESCE(l.completed_at,'')
+FROM llm_sessions l
+JOIN jobs j ON j.id = l.job_id
+WHERE j.created_at >= ?`
+	args := []any{since}
+	if project != "" {
+		q += ` AND j.project_name = ?`
+		args = append(args, project)
+	}
+	q += ` ORDER BY l.id ASC`
+
+	rows, err := s.Reader.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list session stats: %w", err)
+	}
+	defer rows.Close()
+
+	var out []SessionStat
+	for rows.Next() {
+		var st SessionStat
+		if err := rows.Scan(&st.ID, &st.JobID, &st.Step, &st.Iteration, &st.LLMProvider, &st.Status,
+			&st.InputTokens, &st.OutputTokens, &st.DurationMS, &st.CreatedAt, &st.CompletedAt); err != nil {
+			return nil, fmt.Errorf("scan session stat: %w", err)
+		}
+		out = append(out, st)
+	}
+	return out, rows.Err()
+}
+
+// MergedJob is a job whose PR merged, with what release notes say about it.
+type MergedJob struct {
+	JobID          string
+	ProjectName    string
+	IssueTitle     string
+	IssueURL       string
+	IssueSource    string
+	LabelsJSON     string
+	Summary        string
+	PRURL          string
+	PRMergedAt     string
+	RevertCommit   string
+	BackportBranch string
+}
+
+// Labels returns the labels of the job's issue.
+func (m MergedJob) Labels() []string {
+	return Issue{LabelsJSON: m.LabelsJSON}.Labels()
+}
+
+// ListMergedJobs returns the project's jobs whose PR merged at or after since
+// (RFC3339), in merge order.
+func (s *Store) ListMergedJobs(ctx context.Context, project, since string) ([]MergedJob, error) {
+	rows, err := s.Reader.QueryContext(ctx, `
+SELECT j.id, j.project_name, COALESCE(i.title,''), COALESCE(i.url,''), COALESCE(i.source,''), COALESCE(i.labels_json,''),
+       j.summary, COALESCE(j.pr_url,''), j.pr_merged_at, j.revert_commit, j.backport_branch
+FROM jobs j
+LEFT JOIN issues i ON i.autopr_issue_id = j.autopr_issue_id
+WHERE j.project_name = ? AND COALESCE(j.pr_merged_at,'') != '' AND j.pr_merged_at >= ?
+ORDER BY j.pr_merged_at ASC, j.id`, project, since)
+	if err != nil {
+		return nil, fmt.Errorf("list merged jobs: %w", err)
+	}
+	defer rows.Close()
+
+	var out []MergedJob
+	for rows.Next() {
+		var m MergedJob
+		if err := rows.Scan(&m.JobID, &m.ProjectName, &m.IssueTitle, &m.IssueURL, &m.IssueSource, &m.LabelsJSON,
+			&m.Summary, &m.PRURL, &m.PRMergedAt, &m.RevertCommit, &m.BackportBranch); err != nil {
+			return nil, fmt.Errorf("scan merged job: %w", err)
+		}
+		out = append(out, m)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,125 @@
+package db
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+func TestListJobActivity(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	store, err := Open(filepath.Join(t.TempDir(), "autopr.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer store.Close()
+
+	failed := createTestJobWithStateAndProject(t, ctx, store, "1", "failed", "web")
+	merged := createTestJobWithStateAndProject(t, ctx, store, "2", "approved", "web")
+	old := createTestJobWithStateAndProject(t, ctx, store, "3", "failed", "web")
+	other := createTestJobWithStateAndProject(t, ctx, store, "4", "cancelled", "api")
+	createTestJobWithStateAndProject(t, ctx, store, "5", "queued", "web")
+	for id, times := range map[string][2]string{
+		failed: {"2026-03-04T10:05:00Z", ""},
+		merged: {"2026-02-20T11:00:00Z", "2026-03-01T09:00:00Z"},
+		old:    {"2026-02-01T10:00:00Z", ""},
+		other:  {"2026-03-02T10:00:00Z", ""},
+	} {
+		if _, err := store.Writer.ExecContext(ctx, `UPDATE jobs SET completed_at = ?, pr_merged_at = NULLIF(?, '') WHERE id = ?`, times[0], times[1], id); err != nil {
+			t.Fatalf("set times: %v", err)
+		}
+	}
+
+	activity, err := store.ListJobActivity(ctx, "web", "2026-03-01T00:00:00Z")
+	if err != nil {
+		t.Fatalf("list job activity: %v", err)
+	}
+	if len(activity) != 2 || activity[0].JobID != merged || activity[1].JobID != failed {
+		t.Fatalf("expected the merged then the failed job, got %+v", activity)
+	}
+	if activity[0].PRMergedAt != "2026-03-01T09:00:00Z" || activity[1].State != "failed" || activity[1].IssueTitle != "1" {
+		t.Fatalf("unexpected activity fields %+v", activity)
+	}
+	if all, err := store.ListJobActivity(ctx, "", "2026-03-01T00:00:00Z"); err != nil || len(all) != 3 {
+		t.Fatalf("expected activity across projects, got %+v, %v", all, err)
+	}
+}
+
+func TestListMergedJobs(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	store, err := Open(filepath.Join(t.TempDir(), "autopr.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer store.Close()
+
+	first := createTestJobWithStateAndProject(t, ctx, store, "1", "approved", "web")
+	second := createTestJobWithStateAndProject(t, ctx, store, "2", "approved", "web")
+	before := createTestJobWithStateAndProject(t, ctx, store, "3", "approved", "web")
+	other := createTestJobWithStateAndProject(t, ctx, store, "4", "approved", "api")
+	createTestJobWithStateAndProject(t, ctx, store, "5", "approved", "web")
+	for id, mergedAt := range map[string]string{
+		first:  "2026-03-02T10:00:00Z",
+		second: "2026-03-05T10:00:00Z",
+		before: "2026-02-20T10:00:00Z",
+		other:  "2026-03-03T10:00:00Z",
+	} {
+		if _, err := store.Writer.ExecContext(ctx, `UPDATE jobs SET pr_merged_at = ?, summary = 'summary of ' || id WHERE id = ?`, mergedAt, id); err != nil {
+			t.Fatalf("set merged: %v", err)
+		}
+	}
+
+	merged, err := store.ListMergedJobs(ctx, "web", "2026-03-01T00:00:00Z")
+	if err != nil {
+		t.Fatalf("list merged jobs: %v", err)
+	}
+	if len(merged) != 2 || merged[0].JobID != first || merged[1].JobID != second {
+		t.Fatalf("expected the two jobs merged since March in order, got %+v", merged)
+	}
+	if merged[0].IssueTitle != "1" || merged[0].Summary != "summary of "+first || merged[0].PRMergedAt != "2026-03-02T10:00:00Z" {
+		t.Fatalf("unexpected merged job fields %+v", merged[0])
+	}
+}
+
+func TestListSessionStats(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	store, err := Open(filepath.Join(t.TempDir(), "autopr.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer store.Close()
+
+	recent := createTestJobWithOrderFields(t, ctx, store, "1", "web", "approved", "2026-03-02T09:00:00Z", "2026-03-02T09:00:00Z", "")
+	old := createTestJobWithOrderFields(t, ctx, store, "2", "web", "failed", "2026-01-02T09:00:00Z", "2026-01-02T09:00:00Z", "")
+	other := createTestJobWithOrderFields(t, ctx, store, "3", "api", "failed", "2026-03-03T09:00:00Z", "2026-03-03T09:00:00Z", "")
+	for _, jobID := range []string{recent, old, other} {
+		id, err := store.CreateSession(ctx, jobID, "plan", 0, "claude", "")
+		if err != nil {
+			t.Fatalf("create session: %v", err)
+		}
+		if err := store.CompleteSession(ctx, id, "completed", "plan text", "prompt", "", "", "", "", 1200, 300, 4500); err != nil {
+			t.Fatalf("complete session: %v", err)
+		}
+	}
+
+	stats, err := store.ListSessionStats(ctx, "web", "2026-03-01T00:00:00Z")
+	if err != nil {
+		t.Fatalf("list session stats: %v", err)
+	}
+	if len(stats) != 1 || stats[0].JobID != recent {
+		t.Fatalf("expected the session of the recent web job, got %+v", stats)
+	}
+	if st := stats[0]; st.Step != "plan" || st.LLMProvider != "claude" || st.Status != "completed" ||
+		st.InputTokens != 1200 || st.OutputTokens != 300 || st.DurationMS != 4500 {
+		t.Fatalf("unexpected session stat %+v", st)
+	}
+	if all, err := store.ListSessionStats(ctx, "", ""); err != nil || len(all) != 3 {
+		t.Fatalf("expected every session, got %+v, %v", all, err)
+	}
+}
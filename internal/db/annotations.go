@@ -0,0 +1,207 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// maxTagLen bounds a job tag so it fits the TUI list column.
+const maxTagLen = 32
+
+// JobNote is a freeform note a human attached to a job.
+type JobNote struct {
+	ID        int64
+	JobID     string
+	Body      string
+	CreatedAt string
+}
+
+// NormalizeTag lowercases and trims tag and checks it only uses letters,
+// digits, and - _ . / :, so tags stay easy to type on the command line.
+func NormalizeTag(tag string) (string, error) {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if tag == "" {
+		return "", fmt.Errorf("tag is empty")
+	}
+	if len(tag) > maxTagLen {
+		return "", fmt.Errorf("tag %q is longer than %d characters", tag, maxTagLen)
+	}
+	for _, r := range tag {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', strings.ContainsRune("-_./:", r):
+		default:
+			return "", fmt.Errorf("tag %q has invalid character %q (use letters, digits, and - _ . / :)", tag, r)
+		}
+	}
+	return tag, nil
+}
+
+// NormalizeTags normalizes each tag and drops duplicates, keeping the result
+// sorted.
+func NormalizeTags(tags []string) ([]string, error) {
+	out := make([]string, 0, len(tags))
+	for _, t := range tags {
+		n, err := NormalizeTag(t)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, n)
+	}
+	slices.Sort(out)
+	return slices.Compact(out), nil
+}
+
+// AddJobTags tags a job. Tags the job already has are left alone.
+func (s *Store) AddJobTags(ctx context.Context, jobID string, tags []string) error {
+	tags, err := NormalizeTags(tags)
+	if err != nil {
+		return err
+	}
+	return s.retryBusy(ctx, "add job tags", func() error {
+		tx, err := s.Writer.BeginTx(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("tag job %s: %w", jobID, err)
+		}
+		defer tx.Rollback()
+		for _, tag := range tags {
+			if _, err := tx.ExecContext(ctx, `INSERT OR IGNORE INTO job_tags(job_id, tag) VALUES(?,?)`, jobID, tag); err != nil {
+				return fmt.Errorf("tag job %s: %w", jobID, err)
+			}
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("tag job %s: %w", jobID, err)
+		}
+		return nil
+	})
+}
+
+// RemoveJobTags removes tags from a job and returns how many it had.
+func (s *Store) RemoveJobTags(ctx context.Context, jobID string, tags []string) (int64, error) {
+	tags, err := NormalizeTags(tags)
+	if err != nil {
+		return 0, err
+	}
+	if len(tags) == 0 {
+		return 0, nil
+	}
+	args := []any{jobID}
+	for _, tag := range tags {
+		args = append(args, tag)
+	}
+	placeholders := strings.Repeat("?,", len(tags)-1) + "?"
+	res, err := s.Writer.ExecContext(ctx, `DELETE FROM job_tags WHERE job_id = ? AND tag IN (`+placeholders+`)`, args...)
+	if err != nil {
+		return 0, fmt.Errorf("untag job %s: %w", jobID, err)
+	}
+	n, _ := res.RowsAffected()
+	return n, nil
+}
+
+// SetJobTags replaces a job's tags with tags.
+func (s *Store) SetJobTags(ctx context.Context, jobID string, tags []string) error {
+	tags, err := NormalizeTags(tags)
+	if err != nil {
+		return err
+	}
+	return s.retryBusy(ctx, "set job tags", func() error {
+		tx, err := s.Writer.BeginTx(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("set tags of job %s: %w", jobID, err)
+		}
+		defer tx.Rollback()
+		if _, err := tx.ExecContext(ctx, `DELETE FROM job_tags WHERE job_id = ?`, jobID); err != nil {
+			return fmt.Errorf("set tags of job %s: %w", jobID, err)
+		}
+		for _, tag := range tags {
+			if _, err := tx.ExecContext(ctx, `INSERT INTO job_tags(job_id, tag) VALUES(?,?)`, jobID, tag); err != nil {
+				return fmt.Errorf("set tags of job %s: %w", jobID, err)
+			}
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("set tags of job %s: %w", jobID, err)
+		}
+		return nil
+	})
+}
+
+// ListTags returns every tag in use with the number of jobs carrying it,
+// keyed by tag.
+func (s *Store) ListTags(ctx context.Context) (map[string]int, error) {
+	rows, err := s.Reader.QueryContext(ctx, `SELECT tag, COUNT(*) FROM job_tags GROUP BY tag`)
+	if err != nil {
+		return nil, fmt.Errorf("list tags: %w", err)
+	}
+	defer rows.Close()
+
+	out := map[string]int{}
+	for rows.Next() {
+		var (
+			tag string
+			n   int
+		)
+		if err := rows.Scan(&tag, &n); err != nil {
+			return nil, fmt.Errorf("scan tag: %w", err)
+		}
+		out[tag] = n
+	}
+	return out, rows.Err()
+}
+
+// AddJobNote attaches a freeform note to a job and returns its ID.
+func (s *Store) AddJobNote(ctx context.Context, jobID, body string) (int64, error) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return 0, fmt.Errorf("note is empty")
+	}
+	res, err := s.Writer.ExecContext(ctx, `INSERT INTO job_notes(job_id, body) VALUES(?,?)`, jobID, body)
+	if err != nil {
+		return 0, fmt.Errorf("add note to job %s: %w", jobID, err)
+	}
+	return res.LastInsertId()
+}
+
+// DeleteJobNote removes one of a job's notes.
+func (s *Store) DeleteJobNote(ctx context.Context, jobID string, noteID int64) error {
+	res, err := s.Writer.ExecContext(ctx, `DELETE FROM job_notes WHERE id = ? AND job_id = ?`, noteID, jobID)
+	if err != nil {
+		return fmt.Errorf("delete note %d of job %s: %w", noteID, jobID, err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("note %d not found on job %s", noteID, jobID)
+	}
+	return nil
+}
+
+// ListJobNotes returns a job's notes, oldest first.
+func (s *Store) ListJobNotes(ctx context.Context, jobID string) ([]JobNote, error) {
+	rows, err := s.Reader.QueryContext(ctx, `
+SELECT id, job_id, body, created_at FROM job_notes WHERE job_id = ? ORDER BY id`, jobID)
+	if err != nil {
+		return nil, fmt.Errorf("list notes of job %s: %w", jobID, err)
+	}
+	defer rows.Close()
+
+	var out []JobNote
+	for rows.Next() {
+		var n JobNote
+		if err := rows.Scan(&n.ID, &n.JobID, &n.Body, &n.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan job note: %w", err)
+		}
+		out = append(out, n)
+	}
+	return out, rows.Err()
+}
+
+// jobTagsColumn selects a job's tags, comma-separated in tag order, for the
+// job queries that alias jobs as j.
+const jobTagsColumn = `COALESCE((SELECT group_concat(tag, ',') FROM (SELECT tag FROM job_tags t WHERE t.job_id = j.id ORDER BY tag)),'')`
+
+// splitTags parses jobTagsColumn.
+func splitTags(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, ",")
+}
@@ -0,0 +1,103 @@
+package db
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestJobTagsAndNotes(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	store, err := Open(filepath.Join(t.TempDir(), "autopr.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer store.Close()
+
+	var jobIDs []string
+	for _, n := range []string{"1", "2"} {
+		issueID, err := store.UpsertIssue(ctx, IssueUpsert{
+			ProjectName:   "myproject",
+			Source:        "github",
+			SourceIssueID: n,
+			Title:         "issue " + n,
+			URL:           "https://github.com/org/repo/issues/" + n,
+			State:         "open",
+		})
+		if err != nil {
+			t.Fatalf("upsert issue: %v", err)
+		}
+		jobID, err := store.CreateJob(ctx, issueID, "myproject", 3)
+		if err != nil {
+			t.Fatalf("create job: %v", err)
+		}
+		jobIDs = append(jobIDs, jobID)
+	}
+
+	if err := store.AddJobTags(ctx, jobIDs[0], []string{"Flaky-Tests", "ci", "ci"}); err != nil {
+		t.Fatalf("add tags: %v", err)
+	}
+	if err := store.AddJobTags(ctx, jobIDs[0], []string{"bad tag"}); err == nil {
+		t.Fatal("expected a tag with a space to be rejected")
+	}
+	job, err := store.GetJob(ctx, jobIDs[0])
+	if err != nil {
+		t.Fatalf("get job: %v", err)
+	}
+	if !slices.Equal(job.Tags, []string{"ci", "flaky-tests"}) {
+		t.Fatalf("expected normalized sorted tags, got %v", job.Tags)
+	}
+
+	tagged, err := store.ListJobsTagged(ctx, "", "all", "flaky-tests", "updated_at", false)
+	if err != nil {
+		t.Fatalf("list tagged jobs: %v", err)
+	}
+	if len(tagged) != 1 || tagged[0].ID != jobIDs[0] || !slices.Equal(tagged[0].Tags, job.Tags) {
+		t.Fatalf("expected only the tagged job, got %+v", tagged)
+	}
+	page, total, err := store.ListJobsPageTagged(ctx, "", "all", "ci", "updated_at", false, 1, 10)
+	if err != nil {
+		t.Fatalf("list tagged page: %v", err)
+	}
+	if total != 1 || len(page) != 1 {
+		t.Fatalf("expected one tagged job in page, got total=%d jobs=%d", total, len(page))
+	}
+
+	if n, err := store.RemoveJobTags(ctx, jobIDs[0], []string{"ci", "missing"}); err != nil || n != 1 {
+		t.Fatalf("remove tags: n=%d err=%v", n, err)
+	}
+	if err := store.SetJobTags(ctx, jobIDs[1], []string{"docs"}); err != nil {
+		t.Fatalf("set tags: %v", err)
+	}
+	counts, err := store.ListTags(ctx)
+	if err != nil {
+		t.Fatalf("list tags: %v", err)
+	}
+	if len(counts) != 2 || counts["flaky-tests"] != 1 || counts["docs"] != 1 {
+		t.Fatalf("unexpected tag counts: %v", counts)
+	}
+
+	noteID, err := store.AddJobNote(ctx, jobIDs[0], "  fails only on arm64  ")
+	if err != nil {
+		t.Fatalf("add note: %v", err)
+	}
+	if _, err := store.AddJobNote(ctx, jobIDs[0], "   "); err == nil {
+		t.Fatal("expected an empty note to be rejected")
+	}
+	notes, err := store.ListJobNotes(ctx, jobIDs[0])
+	if err != nil {
+		t.Fatalf("list notes: %v", err)
+	}
+	if len(notes) != 1 || notes[0].ID != noteID || notes[0].Body != "fails only on arm64" {
+		t.Fatalf("unexpected notes: %+v", notes)
+	}
+	if err := store.DeleteJobNote(ctx, jobIDs[1], noteID); err == nil {
+		t.Fatal("expected deleting another job's note to fail")
+	}
+	if err := store.DeleteJobNote(ctx, jobIDs[0], noteID); err != nil {
+		t.Fatalf("delete note: %v", err)
+	}
+}
@@ -0,0 +1,202 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// backupTimeFormat stamps backup file names; it sorts chronologically.
+const backupTimeFormat = "20060102-150405"
+
+// Backup copies the live database to dest with SQLite's online backup API,
+// which is safe while the daemon keeps writing. An empty dest writes
+// <db path>.bak.<YYYYMMdd-HHmmss>. It returns the path written.
+func (s *Store) Backup(ctx context.Context, dest string) (string, error) {
+	if dest == "" {
+		dest = fmt.Sprintf("%s.bak.%s", s.path, time.Now().Format(backupTimeFormat))
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+		return "", fmt.Errorf("create backup directory: %w", err)
+	}
+	// Copy to a temp file first so dest is never a partial database.
+	tmp := dest + ".tmp"
+	_ = os.Remove(tmp)
+	if err := onlineBackup(ctx, s.Reader, tmp, s.key); err != nil {
+		_ = os.Remove(tmp)
+		return "", err
+	}
+	if err := os.Rename(tmp, dest); err != nil {
+		_ = os.Remove(tmp)
+		return "", fmt.Errorf("move backup into place: %w", err)
+	}
+	return dest, nil
+}
+
+// Restore replaces the database at dbPath with the backup at src. The daemon
+// must not be running. Backups from an older schema are migrated on the next
+// Open. key is the database key, or "" for an unencrypted database.
+func Restore(ctx context.Context, dbPath, src, key string) error {
+	srcDB, err := openDB(src, key, "_busy_timeout=5000&mode=ro")
+	if err != nil {
+		return fmt.Errorf("open backup: %w", err)
+	}
+	defer srcDB.Close()
+
+	var check string
+	if err := srcDB.QueryRowContext(ctx, `PRAGMA integrity_check`).Scan(&check); err != nil {
+		return fmt.Errorf("check backup %s: %w", src, err)
+	}
+	if check != "ok" {
+		return fmt.Errorf("backup %s failed integrity check: %s", src, check)
+	}
+	version, err := schemaVersionOf(srcDB)
+	if err != nil {
+		return err
+	}
+	if version == 0 {
+		return fmt.Errorf("%s is not an AutoPR database", src)
+	}
+	latest, err := LatestSchemaVersion()
+	if err != nil {
+		return err
+	}
+	if version > latest {
+		return fmt.Errorf("backup schema version %d is newer than this ap supports (%d); upgrade ap", version, latest)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dbPath), 0o755); err != nil {
+		return fmt.Errorf("create db directory: %w", err)
+	}
+	return onlineBackup(ctx, srcDB, dbPath, key)
+}
+
+// onlineBackup copies the main database of src into the file at destPath,
+// replacing its contents. An encrypted source needs its key so the copy is
+// encrypted the same way.
+func onlineBackup(ctx context.Context, src *sql.DB, destPath, key string) error {
+	dst, err := openDB(destPath, key, "_busy_timeout=5000")
+	if err != nil {
+		return fmt.Errorf("open backup destination: %w", err)
+	}
+	defer dst.Close()
+
+	dstConn, err := dst.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("connect backup destination: %w", err)
+	}
+	defer dstConn.Close()
+	srcConn, err := src.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("connect backup source: %w", err)
+	}
+	defer srcConn.Close()
+
+	return dstConn.Raw(func(dc any) error {
+		return srcConn.Raw(func(sc any) error {
+			dstSQLite, ok := dc.(*sqlite3.SQLiteConn)
+			if !ok {
+				return fmt.Errorf("backup destination is not a sqlite connection")
+			}
+			srcSQLite, ok := sc.(*sqlite3.SQLiteConn)
+			if !ok {
+				return fmt.Errorf("backup source is not a sqlite connection")
+			}
+			b, err := dstSQLite.Backup("main", srcSQLite, "main")
+			if err != nil {
+				return fmt.Errorf("start backup: %w", err)
+			}
+			if _, err := b.Step(-1); err != nil {
+				_ = b.Finish()
+				return fmt.Errorf("copy database: %w", err)
+			}
+			if err := b.Finish(); err != nil {
+				return fmt.Errorf("finish backup: %w", err)
+			}
+			return nil
+		})
+	})
+}
+
+// BackupFileName is the name used for backups written to a backup directory.
+func BackupFileName(t time.Time) string {
+	return "autopr-" + t.Format(backupTimeFormat) + ".db"
+}
+
+// ListBackups returns the backups in dir, newest first.
+func ListBackups(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read backup directory: %w", err)
+	}
+	var out []string
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasPrefix(name, "autopr-") || !strings.HasSuffix(name, ".db") {
+			continue
+		}
+		out = append(out, filepath.Join(dir, name))
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(out)))
+	return out, nil
+}
+
+// PruneBackups deletes all but the newest keep backups in dir.
+func PruneBackups(dir string, keep int) (int, error) {
+	backups, err := ListBackups(dir)
+	if err != nil {
+		return 0, err
+	}
+	removed := 0
+	for i := keep; i < len(backups); i++ {
+		if err := os.Remove(backups[i]); err != nil {
+			return removed, fmt.Errorf("remove old backup: %w", err)
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+// RunBackupLoop writes a backup to dir every interval and keeps the newest
+// keep of them. A backup is taken at startup when the newest one is older
+// than interval, so restarts do not push the schedule back.
+func (s *Store) RunBackupLoop(ctx context.Context, dir string, interval time.Duration, keep int) {
+	wait := time.Duration(0)
+	if backups, err := ListBackups(dir); err == nil && len(backups) > 0 {
+		if info, err := os.Stat(backups[0]); err == nil {
+			wait = max(interval-time.Since(info.ModTime()), 0)
+		}
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+		path, err := s.Backup(ctx, filepath.Join(dir, BackupFileName(time.Now())))
+		if err != nil {
+			slog.Error("scheduled database backup failed", "err", err)
+		} else {
+			slog.Info("database backed up", "path", path)
+			if removed, err := PruneBackups(dir, keep); err != nil {
+				slog.Warn("prune database backups", "err", err)
+			} else if removed > 0 {
+				slog.Debug("pruned old database backups", "removed", removed)
+			}
+		}
+		timer.Reset(interval)
+	}
+}
@@ -0,0 +1,102 @@
+package db
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestBackupAndRestoreRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "autopr.db")
+	store, err := Open(dbPath)
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	kept := createTestJobWithStateAndProject(t, ctx, store, "backup-1", "queued", "proj")
+
+	backupPath, err := store.Backup(ctx, filepath.Join(dir, "backups", BackupFileName(time.Now())))
+	if err != nil {
+		t.Fatalf("backup: %v", err)
+	}
+	if _, err := os.Stat(backupPath + ".tmp"); !os.IsNotExist(err) {
+		t.Fatalf("expected temp backup file removed, stat err=%v", err)
+	}
+
+	// Changes after the backup must disappear on restore.
+	dropped := createTestJobWithStateAndProject(t, ctx, store, "backup-2", "queued", "proj")
+	if err := store.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	if err := Restore(ctx, dbPath, backupPath, ""); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	store, err = Open(dbPath)
+	if err != nil {
+		t.Fatalf("reopen db: %v", err)
+	}
+	defer store.Close()
+	if _, err := store.GetJob(ctx, kept); err != nil {
+		t.Fatalf("expected backed-up job after restore: %v", err)
+	}
+	if _, err := store.GetJob(ctx, dropped); err == nil {
+		t.Fatal("expected job created after the backup to be gone")
+	}
+}
+
+func TestRestoreRejectsNonAutoPRDatabase(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	bogus := filepath.Join(dir, "bogus.db")
+	if err := os.WriteFile(bogus, nil, 0o644); err != nil {
+		t.Fatalf("write bogus db: %v", err)
+	}
+	if err := Restore(context.Background(), filepath.Join(dir, "autopr.db"), bogus, ""); err == nil {
+		t.Fatal("expected restore of a non-AutoPR database to fail")
+	}
+}
+
+func TestPruneBackupsKeepsNewest(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := range 4 {
+		name := filepath.Join(dir, BackupFileName(base.Add(time.Duration(i)*time.Hour)))
+		if err := os.WriteFile(name, []byte("x"), 0o644); err != nil {
+			t.Fatalf("write backup: %v", err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("keep me"), 0o644); err != nil {
+		t.Fatalf("write unrelated file: %v", err)
+	}
+
+	removed, err := PruneBackups(dir, 2)
+	if err != nil {
+		t.Fatalf("prune: %v", err)
+	}
+	if removed != 2 {
+		t.Fatalf("expected 2 backups removed, got %d", removed)
+	}
+	left, err := ListBackups(dir)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	want := []string{
+		filepath.Join(dir, BackupFileName(base.Add(3*time.Hour))),
+		filepath.Join(dir, BackupFileName(base.Add(2*time.Hour))),
+	}
+	if len(left) != 2 || left[0] != want[0] || left[1] != want[1] {
+		t.Fatalf("expected newest backups %v, got %v", want, left)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "notes.txt")); err != nil {
+		t.Fatalf("expected unrelated file untouched: %v", err)
+	}
+}
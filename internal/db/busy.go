@@ -0,0 +1,106 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+const (
+	// busyTimeoutMS is how long SQLite itself waits for a lock before
+	// returning SQLITE_BUSY.
+	busyTimeoutMS = 5000
+	// busyMaxAttempts bounds how often retryBusy runs an operation that
+	// keeps failing with a lock error.
+	busyMaxAttempts = 4
+	// busyBackoff is the wait before the first retry; it doubles each time.
+	busyBackoff = 100 * time.Millisecond
+)
+
+// Contention counts SQLite lock errors seen by a Store since it was opened.
+type Contention struct {
+	Retries    int64     // lock errors that were retried
+	Failures   int64     // operations that still hit a lock error on the last attempt
+	LastBusyAt time.Time // zero if none
+	LastFailAt time.Time // zero if none
+}
+
+type contentionStats struct {
+	retries    atomic.Int64
+	failures   atomic.Int64
+	lastBusyAt atomic.Int64 // unix nanoseconds
+	lastFailAt atomic.Int64
+}
+
+// Contention returns the store's lock error counts.
+func (s *Store) Contention() Contention {
+	c := Contention{
+		Retries:  s.contention.retries.Load(),
+		Failures: s.contention.failures.Load(),
+	}
+	if ns := s.contention.lastBusyAt.Load(); ns != 0 {
+		c.LastBusyAt = time.Unix(0, ns)
+	}
+	if ns := s.contention.lastFailAt.Load(); ns != 0 {
+		c.LastFailAt = time.Unix(0, ns)
+	}
+	return c
+}
+
+// isBusy reports whether err is a transient SQLite lock error.
+func isBusy(err error) bool {
+	var sqliteErr sqlite3.Error
+	if !errors.As(err, &sqliteErr) {
+		return false
+	}
+	return sqliteErr.Code == sqlite3.ErrBusy || sqliteErr.Code == sqlite3.ErrLocked
+}
+
+// retryBusy runs fn, running it again with backoff while it fails with a lock
+// error that outlasted busy_timeout. fn must be safe to repeat: a single
+// statement or a whole transaction, which SQLite rolls back on error.
+func (s *Store) retryBusy(ctx context.Context, op string, fn func() error) error {
+	wait := busyBackoff
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if !isBusy(err) {
+			return err
+		}
+		now := time.Now().UnixNano()
+		s.contention.lastBusyAt.Store(now)
+		if attempt == busyMaxAttempts {
+			s.contention.failures.Add(1)
+			s.contention.lastFailAt.Store(now)
+			slog.Error("database busy, giving up", "op", op, "attempts", attempt, "err", err)
+			return err
+		}
+		s.contention.retries.Add(1)
+		slog.Warn("database busy, retrying", "op", op, "attempt", attempt, "wait", wait, "err", err)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(wait):
+		}
+		wait *= 2
+	}
+}
+
+// execBusy runs a single write statement on the Writer with retryBusy,
+// reusing a prepared statement for query.
+func (s *Store) execBusy(ctx context.Context, op, query string, args ...any) (sql.Result, error) {
+	var res sql.Result
+	err := s.retryBusy(ctx, op, func() error {
+		stmt, err := s.prepared(ctx, query)
+		if err != nil {
+			return err
+		}
+		res, err = stmt.ExecContext(ctx, args...)
+		return err
+	})
+	return res, err
+}
@@ -0,0 +1,55 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+func TestRetryBusyRetriesLockErrors(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	store, err := Open(filepath.Join(t.TempDir(), "autopr.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer store.Close()
+
+	busy := fmt.Errorf("transition job: %w", sqlite3.Error{Code: sqlite3.ErrBusy})
+	calls := 0
+	err = store.retryBusy(ctx, "test", func() error {
+		calls++
+		if calls < 3 {
+			return busy
+		}
+		return nil
+	})
+	if err != nil || calls != 3 {
+		t.Fatalf("expected success on third call, got calls=%d err=%v", calls, err)
+	}
+	if c := store.Contention(); c.Retries != 2 || c.Failures != 0 || c.LastBusyAt.IsZero() || !c.LastFailAt.IsZero() {
+		t.Fatalf("unexpected contention after recovery: %+v", c)
+	}
+
+	// Other errors are returned at once.
+	calls = 0
+	other := errors.New("job not in state ready")
+	if err := store.retryBusy(ctx, "test", func() error { calls++; return other }); err != other || calls != 1 {
+		t.Fatalf("expected non-busy error without retry, got calls=%d err=%v", calls, err)
+	}
+
+	// A lock that never clears fails after busyMaxAttempts.
+	calls = 0
+	err = store.retryBusy(ctx, "test", func() error { calls++; return busy })
+	if !isBusy(err) || calls != busyMaxAttempts {
+		t.Fatalf("expected busy error after %d calls, got calls=%d err=%v", busyMaxAttempts, calls, err)
+	}
+	if c := store.Contention(); c.Retries != int64(2+busyMaxAttempts-1) || c.Failures != 1 || c.LastFailAt.IsZero() {
+		t.Fatalf("unexpected contention after failure: %+v", c)
+	}
+}
@@ -3,10 +3,14 @@ package db
 import (
 	"context"
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestUpsertIssueAssignsAndPreservesAutoPRIssueID(t *testing.T) {
@@ -179,18 +183,21 @@ func TestValidTransitionsContract(t *testing.T) {
 	t.Parallel()
 	t.Run("edges", func(t *testing.T) {
 		expected := map[string][]string{
-			"queued":              {"planning", "cancelled"},
-			"planning":            {"implementing", "failed", "cancelled"},
-			"implementing":        {"reviewing", "failed", "cancelled"},
-			"reviewing":           {"implementing", "testing", "failed", "cancelled"},
-			"testing":             {"ready", "implementing", "rebasing", "failed", "cancelled"},
-			"rebasing":            {"resolving_conflicts", "ready", "failed", "cancelled"},
-			"resolving_conflicts": {"ready", "failed", "cancelled"},
-			"ready":               {"awaiting_checks", "approved", "rejected"},
-			"awaiting_checks":     {"approved", "rejected", "cancelled"},
-			"failed":              {"queued"},
-			"rejected":            {"queued"},
-			"cancelled":           {"queued"},
+			"queued":                {"planning", "cancelled"},
+			"planning":              {"implementing", "rebasing", "testing", "failed", "cancelled", "waiting_network"},
+			"implementing":          {"reviewing", "needs_review_oversize", "failed", "cancelled", "waiting_network"},
+			"reviewing":             {"implementing", "testing", "failed", "cancelled", "waiting_network"},
+			"testing":               {"ready", "implementing", "rebasing", "needs_review_oversize", "approved", "failed", "cancelled", "waiting_network"},
+			"rebasing":              {"resolving_conflicts", "ready", "failed", "cancelled", "waiting_network"},
+			"resolving_conflicts":   {"ready", "failed", "cancelled", "waiting_network"},
+			"ready":                 {"awaiting_checks", "approved", "rejected", "waiting_network"},
+			"needs_review_oversize": {"ready", "rejected"},
+			"awaiting_checks":       {"approved", "rejected", "cancelled"},
+			"waiting_network":       {"queued", "ready", "awaiting_checks", "approved", "cancelled"},
+			"approved":              {"queued"},
+			"failed":                {"queued"},
+			"rejected":              {"queued"},
+			"cancelled":             {"queued"},
 		}
 
 		if got, want := len(ValidTransitions), len(expected); got != want {
@@ -437,12 +444,32 @@ func TestRecoverInFlightJobs(t *testing.T) {
 	}
 
 	// Recover.
-	n, err := store.RecoverInFlightJobs(ctx, tmp)
+	recovered, err := store.RecoverInFlightJobs(ctx, tmp)
 	if err != nil {
 		t.Fatalf("recover: %v", err)
 	}
-	if n != 3 {
-		t.Fatalf("expected 3 recovered, got %d", n)
+	if len(recovered) != 3 {
+		t.Fatalf("expected 3 recovered, got %+v", recovered)
+	}
+	for _, job := range recovered {
+		if job.Project != "myproject" || job.Recoveries != 1 {
+			t.Fatalf("unexpected recovered job %+v", job)
+		}
+		if job.ID == rebaseJobID && (job.FromState != "rebasing" || job.State != "ready") {
+			t.Fatalf("expected rebasing job reported back to ready, got %+v", job)
+		}
+	}
+
+	// A second interruption of the same job is counted.
+	if _, err := store.Writer.ExecContext(ctx, `UPDATE jobs SET state = 'testing' WHERE id = ?`, jobID); err != nil {
+		t.Fatalf("seed testing job: %v", err)
+	}
+	recovered, err = store.RecoverInFlightJobs(ctx, tmp)
+	if err != nil {
+		t.Fatalf("recover again: %v", err)
+	}
+	if len(recovered) != 1 || recovered[0].ID != jobID || recovered[0].FromState != "testing" || recovered[0].Recoveries != 2 {
+		t.Fatalf("expected the testing job recovered a second time, got %+v", recovered)
 	}
 
 	job1, err := store.GetJob(ctx, jobID)
@@ -534,12 +561,12 @@ func TestRecoverInFlightJobsCleansRebaseMetadata(t *testing.T) {
 		t.Fatalf("seed resolving job: %v", err)
 	}
 
-	n, err := store.RecoverInFlightJobs(ctx, tmp)
+	recovered, err := store.RecoverInFlightJobs(ctx, tmp)
 	if err != nil {
 		t.Fatalf("recover: %v", err)
 	}
-	if n != 2 {
-		t.Fatalf("expected 2 recovered jobs, got %d", n)
+	if len(recovered) != 2 {
+		t.Fatalf("expected 2 recovered jobs, got %d", len(recovered))
 	}
 
 	if _, err := os.Stat(filepath.Join(rebaseWorktree, ".git", "rebase-merge")); !os.IsNotExist(err) {
@@ -598,12 +625,12 @@ func TestRecoverInFlightJobsSkipsOutOfRootRebaseCleanup(t *testing.T) {
 		t.Fatalf("seed outside job: %v", err)
 	}
 
-	n, err := store.RecoverInFlightJobs(ctx, tmp)
+	recovered, err := store.RecoverInFlightJobs(ctx, tmp)
 	if err != nil {
 		t.Fatalf("recover jobs: %v", err)
 	}
-	if n != 1 {
-		t.Fatalf("expected 1 recovered job, got %d", n)
+	if len(recovered) != 1 {
+		t.Fatalf("expected 1 recovered job, got %d", len(recovered))
 	}
 
 	if _, err := os.Stat(filepath.Join(outsideWorktree, ".git", "rebase-merge")); err != nil {
@@ -794,6 +821,69 @@ func TestClaimJobSkipsIneligibleIssues(t *testing.T) {
 	}
 }
 
+func TestClaimJobSkipsDisabledProjects(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	tmp := t.TempDir()
+
+	store, err := Open(filepath.Join(tmp, "autopr.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer store.Close()
+
+	pausedJobID := createTestJobWithStateAndProject(t, ctx, store, "200", "queued", "paused")
+	activeJobID := createTestJobWithStateAndProject(t, ctx, store, "201", "queued", "active")
+
+	claimedID, err := store.ClaimJob(ctx, "paused", "other")
+	if err != nil {
+		t.Fatalf("claim job: %v", err)
+	}
+	if claimedID != activeJobID {
+		t.Fatalf("expected job of enabled project %q, got %q", activeJobID, claimedID)
+	}
+	if claimedID, err = store.ClaimJob(ctx, "paused"); err != nil || claimedID != "" {
+		t.Fatalf("expected nothing claimable, got %q (err %v)", claimedID, err)
+	}
+	if claimedID, err = store.ClaimJob(ctx); err != nil || claimedID != pausedJobID {
+		t.Fatalf("expected job %q once its project is enabled, got %q (err %v)", pausedJobID, claimedID, err)
+	}
+}
+
+func TestProjectEnabledOverrides(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	store, err := Open(filepath.Join(t.TempDir(), "autopr.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer store.Close()
+
+	if err := store.SetProjectEnabledOverride(ctx, "a", false); err != nil {
+		t.Fatalf("disable a: %v", err)
+	}
+	if err := store.SetProjectEnabledOverride(ctx, "b", false); err != nil {
+		t.Fatalf("disable b: %v", err)
+	}
+	if err := store.SetProjectEnabledOverride(ctx, "b", true); err != nil {
+		t.Fatalf("enable b: %v", err)
+	}
+	got, err := store.ProjectEnabledOverrides(ctx)
+	if err != nil {
+		t.Fatalf("list overrides: %v", err)
+	}
+	if len(got) != 2 || got["a"] || !got["b"] {
+		t.Fatalf("expected a disabled and b enabled, got %v", got)
+	}
+	if err := store.ClearProjectEnabledOverride(ctx, "a"); err != nil {
+		t.Fatalf("clear a: %v", err)
+	}
+	if got, _ := store.ProjectEnabledOverrides(ctx); len(got) != 1 {
+		t.Fatalf("expected one override left, got %v", got)
+	}
+}
+
 func TestResetJobForRetryBlockedWhenIssueIneligible(t *testing.T) {
 	t.Parallel()
 	ctx := context.Background()
@@ -988,6 +1078,61 @@ func TestListIssuesFilters(t *testing.T) {
 	}
 }
 
+func TestListPlannedIssues(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	store, err := Open(filepath.Join(t.TempDir(), "autopr.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer store.Close()
+
+	ineligible := false
+	upsert := func(project, id, state, updated string, eligible *bool) string {
+		t.Helper()
+		issueID, err := store.UpsertIssue(ctx, IssueUpsert{
+			ProjectName:   project,
+			Source:        "github",
+			SourceIssueID: id,
+			Title:         "issue " + id,
+			URL:           "https://github.com/org/repo/issues/" + id,
+			State:         state,
+			Eligible:      eligible,
+			SourceUpdated: updated,
+		})
+		if err != nil {
+			t.Fatalf("upsert issue %s: %v", id, err)
+		}
+		return issueID
+	}
+	upsert("project-a", "1", "open", "2026-02-03T00:00:00Z", nil)
+	upsert("project-a", "2", "open", "2026-02-01T00:00:00Z", &ineligible)
+	upsert("project-a", "3", "closed", "2026-02-01T00:00:00Z", nil)
+	upsert("project-b", "4", "open", "2026-02-02T00:00:00Z", nil)
+	queued := upsert("project-a", "5", "open", "2026-02-01T00:00:00Z", nil)
+	if _, err := store.CreateJob(ctx, queued, "project-a", 3); err != nil {
+		t.Fatalf("create job: %v", err)
+	}
+
+	planned, err := store.ListPlannedIssues(ctx, "")
+	if err != nil {
+		t.Fatalf("list planned issues: %v", err)
+	}
+	var got []string
+	for _, it := range planned {
+		got = append(got, it.SourceIssueID)
+	}
+	if strings.Join(got, ",") != "4,1,2" {
+		t.Fatalf("expected open issues without jobs, eligible and oldest first, got %v", got)
+	}
+
+	planned, err = store.ListPlannedIssues(ctx, "project-b")
+	if err != nil || len(planned) != 1 || planned[0].SourceIssueID != "4" {
+		t.Fatalf("expected only project-b's issue, got %+v, %v", planned, err)
+	}
+}
+
 func TestGetIssueSyncSummary(t *testing.T) {
 	t.Parallel()
 	ctx := context.Background()
@@ -2570,6 +2715,87 @@ func TestCreateSessionStoresJSONLPath(t *testing.T) {
 	}
 }
 
+func TestSetSessionContextFiles(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	store, err := Open(filepath.Join(t.TempDir(), "autopr.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer store.Close()
+
+	jobID := createTestJobWithState(t, ctx, store, "context-1", "planning", "", "", "", "")
+	sessionID, err := store.CreateSession(ctx, jobID, "plan", 0, "claude", "")
+	if err != nil {
+		t.Fatalf("create session: %v", err)
+	}
+	if sess, err := store.GetFullSession(ctx, int(sessionID)); err != nil || sess.ContextFiles != nil {
+		t.Fatalf("expected no context files, got %+v, %v", sess.ContextFiles, err)
+	}
+
+	files := []ContextFile{{Path: ".autopr/context.md", Bytes: 120}, {Path: "docs/STYLE.md", Bytes: 4096, Truncated: true}}
+	if err := store.SetSessionContextFiles(ctx, sessionID, files); err != nil {
+		t.Fatalf("set context files: %v", err)
+	}
+	sess, err := store.GetFullSession(ctx, int(sessionID))
+	if err != nil {
+		t.Fatalf("get session: %v", err)
+	}
+	if !slices.Equal(sess.ContextFiles, files) {
+		t.Fatalf("context files = %+v, want %+v", sess.ContextFiles, files)
+	}
+}
+
+func TestSessionCommitRange(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	store, err := Open(filepath.Join(t.TempDir(), "autopr.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer store.Close()
+
+	jobID := createTestJobWithState(t, ctx, store, "commits-1", "implementing", "", "", "", "")
+	first, err := store.CreateSession(ctx, jobID, "implement", 0, "claude", "")
+	if err != nil {
+		t.Fatalf("create session: %v", err)
+	}
+	second, err := store.CreateSession(ctx, jobID, "implement", 1, "claude", "")
+	if err != nil {
+		t.Fatalf("create session: %v", err)
+	}
+	if err := store.SetSessionBaseSHA(ctx, second, "base1"); err != nil {
+		t.Fatalf("set base sha: %v", err)
+	}
+	if err := store.CompleteSession(ctx, second, "completed", "done", "", "", "", "base1", "", 1, 1, 1); err != nil {
+		t.Fatalf("complete session: %v", err)
+	}
+	// The safety-net commit lands on the latest implement session only.
+	if err := store.SetLatestSessionCommit(ctx, jobID, "implement", "head2"); err != nil {
+		t.Fatalf("set session commit: %v", err)
+	}
+
+	sess, err := store.GetFullSession(ctx, int(second))
+	if err != nil {
+		t.Fatalf("get session: %v", err)
+	}
+	if sess.BaseSHA != "base1" || sess.CommitSHA != "head2" {
+		t.Fatalf("session range = %s..%s, want base1..head2", sess.BaseSHA, sess.CommitSHA)
+	}
+	sessions, err := store.ListSessionsByJob(ctx, jobID)
+	if err != nil {
+		t.Fatalf("list sessions: %v", err)
+	}
+	if sessions[0].ID != int(first) || sessions[0].BaseSHA != "" || sessions[0].CommitSHA != "" {
+		t.Fatalf("expected the earlier session untouched, got %+v", sessions[0])
+	}
+	if sessions[1].BaseSHA != "base1" {
+		t.Fatalf("listed base sha = %q, want base1", sessions[1].BaseSHA)
+	}
+}
+
 func TestListReadyOrApprovedJobsWithBranchNoPR(t *testing.T) {
 	t.Parallel()
 	ctx := context.Background()
@@ -2704,6 +2930,133 @@ WHERE id = ?`, "2026-02-18T00:00:00Z", mergedAlpha); err != nil {
 	}
 }
 
+func TestClaimJobWeightedSharesClaimsAcrossProjects(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	store, err := Open(filepath.Join(t.TempDir(), "autopr.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer store.Close()
+
+	names := map[string]string{}
+	n := 0
+	queue := func(project string, count int) {
+		t.Helper()
+		for range count {
+			n++
+			id := createTestJobWithStateAndProject(t, ctx, store, strconv.Itoa(300+n), "queued", project)
+			createdAt := time.Date(2026, 1, 1, 0, n, 0, 0, time.UTC).Format(time.RFC3339)
+			if _, err := store.Writer.ExecContext(ctx, `UPDATE jobs SET created_at = ? WHERE id = ?`, createdAt, id); err != nil {
+				t.Fatalf("age job: %v", err)
+			}
+			names[id] = fmt.Sprintf("%s%d", project, n)
+		}
+	}
+	claimAll := func() []string {
+		t.Helper()
+		var got []string
+		for {
+			id, err := store.ClaimJobWeighted(ctx, map[string]int{"a": 2})
+			if err != nil {
+				t.Fatalf("claim job: %v", err)
+			}
+			if id == "" {
+				return got
+			}
+			got = append(got, names[id])
+		}
+	}
+
+	// Project a has twice b's weight, so it gets two claims for each of b's,
+	// however long b waited behind a's backlog.
+	queue("a", 5)
+	queue("b", 2)
+	if got, want := claimAll(), []string{"a1", "b6", "a2", "a3", "b7", "a4", "a5"}; !slices.Equal(got, want) {
+		t.Fatalf("claim order = %v, want %v", got, want)
+	}
+
+	// A project returning from idle gets the next turn, but not the turns
+	// it missed while idle.
+	queue("a", 2)
+	queue("c", 2)
+	if got, want := claimAll(), []string{"c10", "a8", "a9", "c11"}; !slices.Equal(got, want) {
+		t.Fatalf("claim order after c joined = %v, want %v", got, want)
+	}
+}
+
+func TestClaimJobWeightedKeepsSharesWhenTheHeavierProjectIsBehind(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	for _, weights := range []map[string]int{{"a": 10}, {"b": 10}} {
+		store, err := Open(filepath.Join(t.TempDir(), "autopr.db"))
+		if err != nil {
+			t.Fatalf("open db: %v", err)
+		}
+		defer store.Close()
+
+		project := map[string]string{}
+		for i := range 44 {
+			name := "a"
+			if i >= 22 {
+				name = "b"
+			}
+			id := createTestJobWithStateAndProject(t, ctx, store, strconv.Itoa(500+i), "queued", name)
+			createdAt := time.Date(2026, 1, 1, 0, i, 0, 0, time.UTC).Format(time.RFC3339)
+			if _, err := store.Writer.ExecContext(ctx, `UPDATE jobs SET created_at = ? WHERE id = ?`, createdAt, id); err != nil {
+				t.Fatalf("age job: %v", err)
+			}
+			project[id] = name
+		}
+
+		// Both projects stay backlogged for the first 22 claims, so they
+		// split them 10:1 whichever project is heavier, even when the
+		// heavier one starts behind after the older job of the other.
+		claims := map[string]int{}
+		for range 22 {
+			id, err := store.ClaimJobWeighted(ctx, weights)
+			if err != nil || id == "" {
+				t.Fatalf("claim job: %q, %v", id, err)
+			}
+			claims[project[id]]++
+		}
+		heavy, light := "a", "b"
+		if weights["b"] == 10 {
+			heavy, light = "b", "a"
+		}
+		if claims[heavy] != 20 || claims[light] != 2 {
+			t.Fatalf("weights %v: claims = %v, want %s:20 %s:2", weights, claims, heavy, light)
+		}
+	}
+}
+
+func TestCountActiveJobs(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	store, err := Open(filepath.Join(t.TempDir(), "autopr.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer store.Close()
+
+	for i, state := range []string{"queued", "awaiting_checks", "implementing", "failed", "approved", "queued"} {
+		id := createTestJobWithStateAndProject(t, ctx, store, strconv.Itoa(400+i), state, "a")
+		if i == 5 {
+			if _, err := store.Writer.ExecContext(ctx, `UPDATE jobs SET deleted_at = '2026-01-01T00:00:00Z' WHERE id = ?`, id); err != nil {
+				t.Fatalf("delete job: %v", err)
+			}
+		}
+	}
+	// Queued, waiting on CI, and running jobs count; finished and deleted
+	// ones do not.
+	if n, err := store.CountActiveJobs(ctx); err != nil || n != 3 {
+		t.Fatalf("CountActiveJobs = %d, %v; want 3", n, err)
+	}
+}
+
 func createTestJobWithStateAndProject(t *testing.T, ctx context.Context, store *Store, sourceIssueID, state, project string) string {
 	t.Helper()
 	issueID, err := store.UpsertIssue(ctx, IssueUpsert{
@@ -3329,6 +3682,60 @@ func TestListAwaitingChecksJobs(t *testing.T) {
 	}
 }
 
+func TestListStaleAwaitingChecksJobsAndMarkCIStale(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	tmp := t.TempDir()
+
+	store, err := Open(filepath.Join(tmp, "autopr.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer store.Close()
+
+	staleID := createTestJobWithState(t, ctx, store, "stale-ci", "awaiting_checks", "autopr/stale-1", "https://github.com/org/repo/pull/12", "", "")
+	freshID := createTestJobWithState(t, ctx, store, "fresh-ci", "awaiting_checks", "autopr/stale-2", "https://github.com/org/repo/pull/13", "", "")
+	approvedID := createTestJobWithState(t, ctx, store, "approved-ci", "approved", "autopr/stale-3", "https://github.com/org/repo/pull/14", "", "")
+	for id, offset := range map[string]string{staleID: "-30 minutes", freshID: "-1 minutes", approvedID: "-30 minutes"} {
+		if _, err := store.Writer.ExecContext(ctx, `UPDATE jobs SET ci_started_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now', ?) WHERE id = ?`, offset, id); err != nil {
+			t.Fatalf("set ci_started_at: %v", err)
+		}
+	}
+
+	jobs, err := store.ListStaleAwaitingChecksJobs(ctx, 10*time.Minute)
+	if err != nil {
+		t.Fatalf("list stale awaiting_checks jobs: %v", err)
+	}
+	if len(jobs) != 1 || jobs[0].ID != staleID {
+		t.Fatalf("expected only %s to be stale, got %+v", staleID, jobs)
+	}
+
+	if marked, err := store.MarkJobCIStale(ctx, staleID); err != nil || !marked {
+		t.Fatalf("expected stale job to be marked, got %v (err %v)", marked, err)
+	}
+	if marked, err := store.MarkJobCIStale(ctx, staleID); err != nil || marked {
+		t.Fatalf("expected second mark to be a no-op, got %v (err %v)", marked, err)
+	}
+	if marked, err := store.MarkJobCIStale(ctx, approvedID); err != nil || marked {
+		t.Fatalf("expected approved job not to be marked, got %v (err %v)", marked, err)
+	}
+	jobs, err = store.ListStaleAwaitingChecksJobs(ctx, 10*time.Minute)
+	if err != nil {
+		t.Fatalf("list stale awaiting_checks jobs: %v", err)
+	}
+	if len(jobs) != 0 {
+		t.Fatalf("expected flagged job to be skipped, got %+v", jobs)
+	}
+
+	events, err := store.ListNotificationEvents(ctx, "", 0)
+	if err != nil {
+		t.Fatalf("list notification events: %v", err)
+	}
+	if len(events) != 1 || events[0].JobID != staleID || events[0].EventType != NotificationEventCIStuck {
+		t.Fatalf("expected one ci_stuck event for %s, got %+v", staleID, events)
+	}
+}
+
 func TestCancelJobAwaitingChecks(t *testing.T) {
 	t.Parallel()
 	ctx := context.Background()
@@ -3385,3 +3792,273 @@ func TestRejectJobAwaitingChecksSetsCICompletedAt(t *testing.T) {
 		t.Fatalf("expected ci_completed_at to be set after reject from awaiting_checks")
 	}
 }
+
+func TestListJobIterationsCarriesPlanForwardAndPairs(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	store, err := Open(filepath.Join(t.TempDir(), "autopr.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer store.Close()
+
+	issueID, err := store.UpsertIssue(ctx, IssueUpsert{
+		ProjectName:   "myproject",
+		Source:        "github",
+		SourceIssueID: "88",
+		Title:         "iterate",
+		URL:           "https://github.com/org/repo/issues/88",
+		State:         "open",
+	})
+	if err != nil {
+		t.Fatalf("upsert issue: %v", err)
+	}
+	jobID, err := store.CreateJob(ctx, issueID, "myproject", 3)
+	if err != nil {
+		t.Fatalf("create job: %v", err)
+	}
+
+	artifacts := []struct {
+		kind, content string
+		iteration     int
+		sha           string
+	}{
+		{"plan", "plan v0", 0, ""},
+		{"code_review", "needs tests", 0, "aaa"},
+		{"code_review", "still missing edge case", 1, "bbb"},
+		{"plan", "plan v2", 2, ""},
+		{"code_review", "LGTM", 2, "ccc"},
+	}
+	for _, a := range artifacts {
+		if _, err := store.CreateArtifact(ctx, jobID, issueID, a.kind, a.content, a.iteration, a.sha); err != nil {
+			t.Fatalf("create artifact: %v", err)
+		}
+	}
+
+	iterations, err := store.ListJobIterations(ctx, jobID)
+	if err != nil {
+		t.Fatalf("list iterations: %v", err)
+	}
+	if len(iterations) != 3 {
+		t.Fatalf("expected 3 iterations, got %+v", iterations)
+	}
+	if iterations[1].Plan != "plan v0" || iterations[1].PlanIteration != 0 {
+		t.Fatalf("expected iteration 1 to inherit plan from iteration 0, got %+v", iterations[1])
+	}
+	if iterations[2].Plan != "plan v2" || iterations[2].CommitSHA != "ccc" {
+		t.Fatalf("unexpected iteration 2: %+v", iterations[2])
+	}
+
+	prev, cur, err := IterationPair(iterations, -1)
+	if err != nil {
+		t.Fatalf("latest pair: %v", err)
+	}
+	if prev.Iteration != 1 || cur.Iteration != 2 {
+		t.Fatalf("expected latest pair 1->2, got %d->%d", prev.Iteration, cur.Iteration)
+	}
+	prev, cur, err = IterationPair(iterations, 1)
+	if err != nil {
+		t.Fatalf("pair for iteration 1: %v", err)
+	}
+	if prev.Review != "needs tests" || cur.Review != "still missing edge case" {
+		t.Fatalf("unexpected reviews: %q / %q", prev.Review, cur.Review)
+	}
+	if _, _, err := IterationPair(iterations, 0); err == nil {
+		t.Fatalf("expected error comparing first iteration")
+	}
+	if _, _, err := IterationPair(iterations[:1], -1); err == nil {
+		t.Fatalf("expected error with a single iteration")
+	}
+}
+
+func TestCreateFollowUpJobLinksParentAndSurvivesClosedIssue(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	store, err := Open(filepath.Join(t.TempDir(), "autopr.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer store.Close()
+
+	parentID := createTestJobWithState(t, ctx, store, "follow-up-parent", "approved", "autopr/parent", "https://github.com/org/repo/pull/1", "2026-01-01T00:00:00Z", "")
+	parent, err := store.GetJob(ctx, parentID)
+	if err != nil {
+		t.Fatalf("get parent: %v", err)
+	}
+
+	childID, err := store.CreateFollowUpJob(ctx, parent, "add tests")
+	if err != nil {
+		t.Fatalf("create follow-up: %v", err)
+	}
+	if _, err := store.CreateFollowUpJob(ctx, parent, "again"); !errors.Is(err, ErrDuplicateActiveJob) {
+		t.Fatalf("expected duplicate active job error, got %v", err)
+	}
+
+	child, err := store.GetJob(ctx, childID)
+	if err != nil {
+		t.Fatalf("get child: %v", err)
+	}
+	if child.ParentJobID != parentID || child.HumanNotes != "add tests" || child.State != "queued" {
+		t.Fatalf("unexpected follow-up job: %+v", child)
+	}
+
+	// The source issue is typically closed (and possibly ineligible) once the
+	// parent merged; the follow-up must neither be cancelled nor stuck.
+	if _, err := store.Writer.ExecContext(ctx, `UPDATE issues SET eligible = 0, state = 'closed' WHERE autopr_issue_id = ?`, parent.AutoPRIssueID); err != nil {
+		t.Fatalf("close issue: %v", err)
+	}
+	cancelled, err := store.CancelCancellableJobsForIssue(ctx, parent.AutoPRIssueID, CancelReasonSourceIssueClosed)
+	if err != nil {
+		t.Fatalf("cancel for closed issue: %v", err)
+	}
+	if len(cancelled) != 0 {
+		t.Fatalf("expected follow-up job to survive closed issue, cancelled %v", cancelled)
+	}
+	claimed, err := store.ClaimJob(ctx)
+	if err != nil {
+		t.Fatalf("claim job: %v", err)
+	}
+	if claimed != childID {
+		t.Fatalf("expected follow-up job %s to be claimable, got %q", childID, claimed)
+	}
+}
+
+func TestCreateBackportJobAllowsOnePerBranch(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	store, err := Open(filepath.Join(t.TempDir(), "autopr.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer store.Close()
+
+	parentID := createTestJobWithState(t, ctx, store, "backport-parent", "approved", "autopr/parent", "https://github.com/org/repo/pull/1", "2026-01-01T00:00:00Z", "")
+	parent, err := store.GetJob(ctx, parentID)
+	if err != nil {
+		t.Fatalf("get parent: %v", err)
+	}
+
+	first, err := store.CreateBackportJob(ctx, parent, "release-1.2", "abc123")
+	if err != nil {
+		t.Fatalf("create backport 1.2: %v", err)
+	}
+	if _, err := store.CreateBackportJob(ctx, parent, "release-1.3", "abc123"); err != nil {
+		t.Fatalf("create backport 1.3 alongside 1.2: %v", err)
+	}
+	if _, err := store.CreateBackportJob(ctx, parent, "release-1.2", "abc123"); !errors.Is(err, ErrDuplicateActiveJob) {
+		t.Fatalf("expected duplicate active job error, got %v", err)
+	}
+
+	job, err := store.GetJob(ctx, first)
+	if err != nil {
+		t.Fatalf("get backport: %v", err)
+	}
+	if job.ParentJobID != parentID || job.BackportBranch != "release-1.2" || job.BackportCommit != "abc123" {
+		t.Fatalf("unexpected backport job: %+v", job)
+	}
+
+	has, err := store.HasBackportJob(ctx, parentID, "release-1.2")
+	if err != nil || !has {
+		t.Fatalf("expected backport to exist, got %v err=%v", has, err)
+	}
+	has, err = store.HasBackportJob(ctx, parentID, "release-2.0")
+	if err != nil || has {
+		t.Fatalf("expected no backport for release-2.0, got %v err=%v", has, err)
+	}
+}
+
+func TestCreateBisectJobRoundTrip(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	store, err := Open(filepath.Join(t.TempDir(), "autopr.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer store.Close()
+
+	issueID, err := store.UpsertIssue(ctx, IssueUpsert{
+		ProjectName:   "myproject",
+		Source:        "bisect",
+		SourceIssueID: "v1.0..main",
+		Title:         "Find regression",
+		State:         "open",
+	})
+	if err != nil {
+		t.Fatalf("upsert bisect issue: %v", err)
+	}
+	jobID, err := store.CreateBisectJob(ctx, issueID, "myproject", 3, "v1.0", "main", "go test ./...", true)
+	if err != nil {
+		t.Fatalf("create bisect job: %v", err)
+	}
+
+	job, err := store.GetJob(ctx, jobID)
+	if err != nil {
+		t.Fatalf("get bisect job: %v", err)
+	}
+	if job.BisectGood != "v1.0" || job.BisectBad != "main" || job.BisectCmd != "go test ./..." || !job.BisectFix {
+		t.Fatalf("unexpected bisect job: %+v", job)
+	}
+	if _, err := store.CreateArtifact(ctx, jobID, issueID, "bisect_result", "culprit", 0, "abc123"); err != nil {
+		t.Fatalf("create bisect_result artifact: %v", err)
+	}
+}
+
+func TestGiteaSourceAcceptedForIssuesAndCursors(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	store, err := Open(filepath.Join(t.TempDir(), "autopr.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer store.Close()
+
+	if _, err := store.UpsertIssue(ctx, IssueUpsert{
+		ProjectName:   "myproject",
+		Source:        "gitea",
+		SourceIssueID: "7",
+		Title:         "Fix login",
+		URL:           "https://gitea.example.com/org/repo/issues/7",
+		State:         "open",
+	}); err != nil {
+		t.Fatalf("upsert gitea issue: %v", err)
+	}
+	if err := store.SetCursor(ctx, "myproject", "gitea", "2026-03-01T00:00:00Z"); err != nil {
+		t.Fatalf("set gitea cursor: %v", err)
+	}
+	got, err := store.GetCursor(ctx, "myproject", "gitea")
+	if err != nil || got != "2026-03-01T00:00:00Z" {
+		t.Fatalf("expected cursor round-trip, got %q err=%v", got, err)
+	}
+}
+
+func TestAddJobResourceUsage(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	store, err := Open(filepath.Join(t.TempDir(), "autopr.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer store.Close()
+
+	jobID := createTestJobWithState(t, ctx, store, "resources-1", "testing", "", "", "", "")
+	for _, u := range []struct {
+		cpu time.Duration
+		rss int64
+	}{{1500 * time.Millisecond, 300 << 20}, {2 * time.Second, 100 << 20}} {
+		if err := store.AddJobResourceUsage(ctx, jobID, u.cpu, u.rss); err != nil {
+			t.Fatalf("add resource usage: %v", err)
+		}
+	}
+	job, err := store.GetJob(ctx, jobID)
+	if err != nil {
+		t.Fatalf("get job: %v", err)
+	}
+	if job.CPUTimeMS != 3500 || job.PeakRSSBytes != 300<<20 {
+		t.Fatalf("cpu = %dms, peak rss = %d; want 3500ms, %d", job.CPUTimeMS, job.PeakRSSBytes, 300<<20)
+	}
+}
@@ -0,0 +1,135 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// jobDeadlineColumn selects when a job is due, for the job queries that alias
+// jobs as j: the deadline set with `ap deadline`, else its issue's due date.
+const jobDeadlineColumn = `COALESCE(NULLIF(j.deadline,''),(SELECT di.due_at FROM issues di WHERE di.autopr_issue_id = j.autopr_issue_id),'')`
+
+// jobOverdueWhere matches unfinished jobs whose deadline is at or before the
+// parameter and that have not been alerted on since their deadline last
+// moved: an alert is always raised after the deadline it was for.
+const jobOverdueWhere = `j.state NOT IN ('approved','rejected','failed','cancelled')
+  AND NULLIF(` + jobDeadlineColumn + `,'') <= ?
+  AND (j.deadline_alerted_at = '' OR j.deadline_alerted_at < ` + jobDeadlineColumn + `)`
+
+// DeadlineApplies reports whether a job in state can still miss its
+// deadline, i.e. it has not finished.
+func DeadlineApplies(state string) bool {
+	switch state {
+	case "approved", "rejected", "failed", "cancelled":
+		return false
+	default:
+		return true
+	}
+}
+
+// ParseDeadline reads a deadline as an RFC 3339 time, a date (the end of that
+// day in now's location), or a duration from now such as "36h" or "3d".
+func ParseDeadline(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, fmt.Errorf("deadline is empty")
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t.UTC().Truncate(time.Second), nil
+	}
+	if t, err := time.ParseInLocation(time.DateOnly, s, now.Location()); err == nil {
+		return t.AddDate(0, 0, 1).Add(-time.Second).UTC(), nil
+	}
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n > 0 {
+			return now.AddDate(0, 0, n).UTC().Truncate(time.Second), nil
+		}
+	}
+	if d, err := time.ParseDuration(s); err == nil && d > 0 {
+		return now.Add(d).UTC().Truncate(time.Second), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid deadline %q: want a date like 2025-03-01, an RFC 3339 time, or a duration like 48h or 3d", s)
+}
+
+// SetJobDeadline sets the time a job is due, overriding its issue's due date.
+// A zero deadline clears the override.
+func (s *Store) SetJobDeadline(ctx context.Context, jobID string, deadline time.Time) error {
+	value := ""
+	if !deadline.IsZero() {
+		value = deadline.UTC().Format(time.RFC3339)
+	}
+	res, err := s.Writer.ExecContext(ctx, `UPDATE jobs SET deadline = ? WHERE id = ?`, value, jobID)
+	if err != nil {
+		return fmt.Errorf("set job %s deadline: %w", jobID, err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("job %s not found", jobID)
+	}
+	return nil
+}
+
+// OverdueJob is an unfinished job past its deadline.
+type OverdueJob struct {
+	JobID       string
+	ProjectName string
+	IssueTitle  string
+	State       string
+	Deadline    string
+}
+
+// ListOverdueJobs returns unfinished jobs whose deadline passed before now and
+// that have no deadline_overdue alert yet, most overdue first.
+func (s *Store) ListOverdueJobs(ctx context.Context, now time.Time) ([]OverdueJob, error) {
+	rows, err := s.Reader.QueryContext(ctx, `
+SELECT j.id, j.project_name, COALESCE(i.title,''), j.state, `+jobDeadlineColumn+`
+FROM jobs j
+LEFT JOIN issues i ON i.autopr_issue_id = j.autopr_issue_id
+WHERE `+jobOverdueWhere+`
+ORDER BY 5 ASC, j.created_at ASC`, now.UTC().Format(time.RFC3339))
+	if err != nil {
+		return nil, fmt.Errorf("list overdue jobs: %w", err)
+	}
+	defer rows.Close()
+
+	var out []OverdueJob
+	for rows.Next() {
+		var j OverdueJob
+		if err := rows.Scan(&j.JobID, &j.ProjectName, &j.IssueTitle, &j.State, &j.Deadline); err != nil {
+			return nil, fmt.Errorf("scan overdue job: %w", err)
+		}
+		out = append(out, j)
+	}
+	return out, rows.Err()
+}
+
+// MarkJobDeadlineOverdue records that a job missed its deadline and enqueues a
+// deadline_overdue notification. It reports false when the job finished, is
+// no longer overdue, or was already alerted on.
+func (s *Store) MarkJobDeadlineOverdue(ctx context.Context, jobID string) (bool, error) {
+	tx, err := s.Writer.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("mark job %s overdue: %w", jobID, err)
+	}
+	defer tx.Rollback()
+
+	now := nowRFC3339()
+	res, err := tx.ExecContext(ctx, `
+UPDATE jobs AS j SET deadline_alerted_at = ?
+WHERE j.id = ? AND `+jobOverdueWhere, now, jobID, now)
+	if err != nil {
+		return false, fmt.Errorf("mark job %s overdue: %w", jobID, err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return false, nil
+	}
+	if err := enqueueNotificationEventTx(ctx, tx, jobID, NotificationEventDeadlineOverdue); err != nil {
+		return false, err
+	}
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("mark job %s overdue: %w", jobID, err)
+	}
+	return true, nil
+}
@@ -0,0 +1,226 @@
+package db
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseDeadline(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 2, 20, 10, 30, 0, 0, time.FixedZone("CET", 3600))
+	for _, tc := range []struct {
+		in   string
+		want string
+	}{
+		{"2025-03-01", "2025-03-01T22:59:59Z"},
+		{"2025-03-01T12:00:00+02:00", "2025-03-01T10:00:00Z"},
+		{"36h", "2025-02-21T21:30:00Z"},
+		{" 3d ", "2025-02-23T09:30:00Z"},
+	} {
+		got, err := ParseDeadline(tc.in, now)
+		if err != nil {
+			t.Fatalf("parse %q: %v", tc.in, err)
+		}
+		if s := got.Format(time.RFC3339); s != tc.want {
+			t.Fatalf("parse %q: got %s, want %s", tc.in, s, tc.want)
+		}
+	}
+	for _, in := range []string{"", "tomorrow", "-2h", "0d", "2025-13-01"} {
+		if _, err := ParseDeadline(in, now); err == nil {
+			t.Fatalf("expected %q to be rejected", in)
+		}
+	}
+}
+
+func TestJobDeadlineFallsBackToIssueDueDate(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	store, err := Open(filepath.Join(t.TempDir(), "autopr.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer store.Close()
+
+	issueID, err := store.UpsertIssue(ctx, IssueUpsert{
+		ProjectName:   "myproject",
+		Source:        "gitlab",
+		SourceIssueID: "7",
+		Title:         "due soon",
+		URL:           "https://gitlab.com/org/repo/-/issues/7",
+		State:         "open",
+		DueAt:         "2025-03-01T23:59:59Z",
+	})
+	if err != nil {
+		t.Fatalf("upsert issue: %v", err)
+	}
+	jobID, err := store.CreateJob(ctx, issueID, "myproject", 3)
+	if err != nil {
+		t.Fatalf("create job: %v", err)
+	}
+
+	deadline := func() string {
+		t.Helper()
+		job, err := store.GetJob(ctx, jobID)
+		if err != nil {
+			t.Fatalf("get job: %v", err)
+		}
+		return job.Deadline
+	}
+	if got := deadline(); got != "2025-03-01T23:59:59Z" {
+		t.Fatalf("expected the issue due date, got %q", got)
+	}
+	if err := store.SetJobDeadline(ctx, jobID, time.Date(2025, 2, 25, 12, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatalf("set deadline: %v", err)
+	}
+	if got := deadline(); got != "2025-02-25T12:00:00Z" {
+		t.Fatalf("expected the override, got %q", got)
+	}
+	jobs, err := store.ListJobs(ctx, "", "all", "updated_at", false)
+	if err != nil || len(jobs) != 1 || jobs[0].Deadline != "2025-02-25T12:00:00Z" {
+		t.Fatalf("expected the override in the job list, got %+v, %v", jobs, err)
+	}
+	if err := store.SetJobDeadline(ctx, jobID, time.Time{}); err != nil {
+		t.Fatalf("clear deadline: %v", err)
+	}
+	if got := deadline(); got != "2025-03-01T23:59:59Z" {
+		t.Fatalf("expected the issue due date after clearing, got %q", got)
+	}
+	if err := store.SetJobDeadline(ctx, "ap-job-missing", time.Now()); err == nil {
+		t.Fatal("expected an error for an unknown job")
+	}
+}
+
+func TestMarkJobDeadlineOverdueAlertsOncePerDeadline(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	store, err := Open(filepath.Join(t.TempDir(), "autopr.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer store.Close()
+
+	overdueID := createTestJobWithStateAndProject(t, ctx, store, "1", "implementing", "myproject")
+	futureID := createTestJobWithStateAndProject(t, ctx, store, "2", "queued", "myproject")
+	doneID := createTestJobWithStateAndProject(t, ctx, store, "3", "failed", "myproject")
+	createTestJobWithStateAndProject(t, ctx, store, "4", "queued", "myproject")
+
+	now := time.Now().UTC()
+	for id, due := range map[string]time.Time{
+		overdueID: now.Add(-time.Hour),
+		futureID:  now.Add(time.Hour),
+		doneID:    now.Add(-time.Hour),
+	} {
+		if err := store.SetJobDeadline(ctx, id, due); err != nil {
+			t.Fatalf("set deadline: %v", err)
+		}
+	}
+
+	overdue, err := store.ListOverdueJobs(ctx, now)
+	if err != nil {
+		t.Fatalf("list overdue jobs: %v", err)
+	}
+	if len(overdue) != 1 || overdue[0].JobID != overdueID || overdue[0].State != "implementing" {
+		t.Fatalf("expected only the unfinished overdue job, got %+v", overdue)
+	}
+	if marked, err := store.MarkJobDeadlineOverdue(ctx, overdueID); err != nil || !marked {
+		t.Fatalf("expected the job marked, got %v, %v", marked, err)
+	}
+	if marked, err := store.MarkJobDeadlineOverdue(ctx, overdueID); err != nil || marked {
+		t.Fatalf("expected a second mark to be a no-op, got %v, %v", marked, err)
+	}
+	if marked, err := store.MarkJobDeadlineOverdue(ctx, futureID); err != nil || marked {
+		t.Fatalf("expected a job not yet due to be left alone, got %v, %v", marked, err)
+	}
+	events, err := store.ListNotificationEvents(ctx, "", 10)
+	if err != nil {
+		t.Fatalf("list notification events: %v", err)
+	}
+	if len(events) != 1 || events[0].JobID != overdueID || events[0].EventType != NotificationEventDeadlineOverdue {
+		t.Fatalf("expected one deadline_overdue event, got %+v", events)
+	}
+	if overdue, err := store.ListOverdueJobs(ctx, now); err != nil || len(overdue) != 0 {
+		t.Fatalf("expected no unalerted overdue jobs, got %+v, %v", overdue, err)
+	}
+
+	// Moving the deadline past the alert arms it again.
+	if err := store.SetJobDeadline(ctx, overdueID, now.Add(2*time.Hour)); err != nil {
+		t.Fatalf("move deadline: %v", err)
+	}
+	if overdue, err := store.ListOverdueJobs(ctx, now.Add(3*time.Hour)); err != nil || len(overdue) != 2 {
+		t.Fatalf("expected both jobs overdue later, got %+v, %v", overdue, err)
+	}
+}
+
+func TestClaimJobPrefersOverdueJobs(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	store, err := Open(filepath.Join(t.TempDir(), "autopr.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer store.Close()
+
+	oldest := createTestJobWithOrderFields(t, ctx, store, "1", "myproject", "queued", "2025-01-01T00:00:00Z", "2025-01-01T00:00:00Z", "")
+	dueLater := createTestJobWithOrderFields(t, ctx, store, "2", "myproject", "queued", "2025-01-02T00:00:00Z", "2025-01-02T00:00:00Z", "")
+	overdue := createTestJobWithOrderFields(t, ctx, store, "3", "myproject", "queued", "2025-01-03T00:00:00Z", "2025-01-03T00:00:00Z", "")
+	if err := store.SetJobDeadline(ctx, dueLater, time.Now().Add(24*time.Hour)); err != nil {
+		t.Fatalf("set deadline: %v", err)
+	}
+	if err := store.SetJobDeadline(ctx, overdue, time.Now().Add(-time.Hour)); err != nil {
+		t.Fatalf("set deadline: %v", err)
+	}
+
+	for _, want := range []string{overdue, oldest, dueLater} {
+		got, err := store.ClaimJob(ctx)
+		if err != nil {
+			t.Fatalf("claim job: %v", err)
+		}
+		if got != want {
+			t.Fatalf("expected %s claimed next, got %s", want, got)
+		}
+	}
+}
+
+func TestClaimJobOrdersByPriority(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	store, err := Open(filepath.Join(t.TempDir(), "autopr.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer store.Close()
+
+	oldest := createTestJobWithOrderFields(t, ctx, store, "1", "myproject", "queued", "2025-01-01T00:00:00Z", "2025-01-01T00:00:00Z", "")
+	urgent := createTestJobWithOrderFields(t, ctx, store, "2", "myproject", "queued", "2025-01-02T00:00:00Z", "2025-01-02T00:00:00Z", "")
+	overdue := createTestJobWithOrderFields(t, ctx, store, "3", "myproject", "queued", "2025-01-03T00:00:00Z", "2025-01-03T00:00:00Z", "")
+	if err := store.SetJobPriority(ctx, urgent, 10); err != nil {
+		t.Fatalf("set priority: %v", err)
+	}
+	if err := store.SetJobDeadline(ctx, overdue, time.Now().Add(-time.Hour)); err != nil {
+		t.Fatalf("set deadline: %v", err)
+	}
+
+	for _, want := range []string{overdue, urgent, oldest} {
+		got, err := store.ClaimJob(ctx)
+		if err != nil {
+			t.Fatalf("claim job: %v", err)
+		}
+		if got != want {
+			t.Fatalf("expected %s claimed next, got %s", want, got)
+		}
+	}
+	job, err := store.GetJob(ctx, urgent)
+	if err != nil {
+		t.Fatalf("get job: %v", err)
+	}
+	if job.Priority != 10 {
+		t.Fatalf("expected priority 10, got %d", job.Priority)
+	}
+}
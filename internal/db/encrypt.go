@@ -0,0 +1,187 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// ErrEncryptionUnsupported is returned when a database key is configured but
+// ap is linked against a SQLite without SQLCipher.
+var ErrEncryptionUnsupported = errors.New("database encryption needs an ap build linked against SQLCipher (see `make build-sqlcipher`)")
+
+// postKeyParams are DSN parameters that make the driver read the database
+// file, with their pragmas. On an encrypted database they must run after
+// PRAGMA key, so openDB runs them itself.
+var postKeyParams = [][2]string{
+	{"_journal_mode", "journal_mode"},
+	{"_synchronous", "synchronous"},
+	{"_foreign_keys", "foreign_keys"},
+}
+
+// openDB opens the SQLite database at path with the driver parameters in
+// params. A non-empty key opens it as an SQLCipher database.
+func openDB(path, key, params string) (*sql.DB, error) {
+	if key == "" {
+		return sql.Open("sqlite3", fmt.Sprintf("file:%s?%s", path, params))
+	}
+	values, err := url.ParseQuery(params)
+	if err != nil {
+		return nil, fmt.Errorf("parse db params: %w", err)
+	}
+	var pragmas []string
+	for _, p := range postKeyParams {
+		if v := values.Get(p[0]); v != "" {
+			pragmas = append(pragmas, fmt.Sprintf("PRAGMA %s = %s", p[1], v))
+			values.Del(p[0])
+		}
+	}
+	return sql.OpenDB(&keyedConnector{
+		dsn:     fmt.Sprintf("file:%s?%s", path, values.Encode()),
+		key:     key,
+		pragmas: pragmas,
+	}), nil
+}
+
+// keyedConnector opens SQLCipher connections, setting the key before
+// anything reads the file.
+type keyedConnector struct {
+	dsn     string
+	key     string
+	pragmas []string
+}
+
+func (c *keyedConnector) Connect(context.Context) (driver.Conn, error) {
+	conn, err := c.Driver().Open(c.dsn)
+	if err != nil {
+		return nil, err
+	}
+	sc := conn.(*sqlite3.SQLiteConn)
+	if err := applyKey(sc, c.key); err != nil {
+		_ = sc.Close()
+		return nil, err
+	}
+	for _, p := range c.pragmas {
+		if _, err := sc.Exec(p, nil); err != nil {
+			_ = sc.Close()
+			return nil, fmt.Errorf("%s: %w", p, err)
+		}
+	}
+	return sc, nil
+}
+
+func (c *keyedConnector) Driver() driver.Driver {
+	return &sqlite3.SQLiteDriver{}
+}
+
+// applyKey keys an SQLCipher connection and checks that the key opens the
+// file.
+func applyKey(conn *sqlite3.SQLiteConn, key string) error {
+	if _, err := conn.Exec("PRAGMA key = "+quoteSQL(key), nil); err != nil {
+		return fmt.Errorf("set database key: %w", err)
+	}
+	version, err := queryString(conn, "PRAGMA cipher_version")
+	if err != nil {
+		return fmt.Errorf("check sqlcipher: %w", err)
+	}
+	if version == "" {
+		return ErrEncryptionUnsupported
+	}
+	if _, err := queryString(conn, "SELECT count(*) FROM sqlite_master"); err != nil {
+		return fmt.Errorf("open encrypted database (wrong key, or the database is not encrypted?): %w", err)
+	}
+	return nil
+}
+
+// queryString returns the first column of the first row of query, or "" when
+// it returns no rows.
+func queryString(conn *sqlite3.SQLiteConn, query string) (string, error) {
+	rows, err := conn.Query(query, nil)
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+	dest := make([]driver.Value, len(rows.Columns()))
+	if err := rows.Next(dest); err != nil {
+		if errors.Is(err, io.EOF) {
+			return "", nil
+		}
+		return "", err
+	}
+	if len(dest) == 0 || dest[0] == nil {
+		return "", nil
+	}
+	switch v := dest[0].(type) {
+	case []byte:
+		return string(v), nil
+	default:
+		return fmt.Sprint(v), nil
+	}
+}
+
+func quoteSQL(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// Encrypt rewrites the plaintext database at dbPath as an SQLCipher database
+// keyed with key. The daemon must not be running. Backups taken before the
+// switch stay plaintext.
+func Encrypt(ctx context.Context, dbPath, key string) error {
+	if key == "" {
+		return errors.New("no database key configured")
+	}
+	plain, err := openDB(dbPath, "", "_busy_timeout=5000")
+	if err != nil {
+		return fmt.Errorf("open db: %w", err)
+	}
+	defer plain.Close()
+	conn, err := plain.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("open db: %w", err)
+	}
+	defer conn.Close()
+
+	var version string
+	if err := conn.QueryRowContext(ctx, "PRAGMA cipher_version").Scan(&version); err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("check sqlcipher: %w", err)
+	}
+	if version == "" {
+		return ErrEncryptionUnsupported
+	}
+	if _, err := conn.ExecContext(ctx, "PRAGMA wal_checkpoint(TRUNCATE)"); err != nil {
+		return fmt.Errorf("open db (already encrypted?): %w", err)
+	}
+
+	tmp := dbPath + ".encrypting"
+	_ = os.Remove(tmp)
+	if _, err := conn.ExecContext(ctx, "ATTACH DATABASE ? AS encrypted KEY ?", tmp, key); err != nil {
+		return fmt.Errorf("create encrypted copy: %w", err)
+	}
+	if _, err := conn.ExecContext(ctx, "SELECT sqlcipher_export('encrypted')"); err != nil {
+		_, _ = conn.ExecContext(ctx, "DETACH DATABASE encrypted")
+		_ = os.Remove(tmp)
+		return fmt.Errorf("export encrypted copy: %w", err)
+	}
+	if _, err := conn.ExecContext(ctx, "DETACH DATABASE encrypted"); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("finish encrypted copy: %w", err)
+	}
+	_ = conn.Close()
+	_ = plain.Close()
+
+	if err := os.Rename(tmp, dbPath); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("replace database: %w", err)
+	}
+	_ = os.Remove(dbPath + "-wal")
+	_ = os.Remove(dbPath + "-shm")
+	return nil
+}
@@ -0,0 +1,47 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+// The test binary links plain SQLite, so a configured key must fail loudly
+// instead of silently writing an unencrypted database.
+func TestOpenWithKeyRequiresSQLCipher(t *testing.T) {
+	t.Parallel()
+
+	_, err := OpenWithKey(filepath.Join(t.TempDir(), "autopr.db"), "secret")
+	if !errors.Is(err, ErrEncryptionUnsupported) {
+		t.Fatalf("expected ErrEncryptionUnsupported, got %v", err)
+	}
+}
+
+func TestEncryptRequiresSQLCipherAndKeepsDatabase(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	dbPath := filepath.Join(t.TempDir(), "autopr.db")
+	store, err := Open(dbPath)
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	jobID := createTestJobWithStateAndProject(t, ctx, store, "encrypt-1", "queued", "proj")
+	store.Close()
+
+	if err := Encrypt(ctx, dbPath, ""); err == nil {
+		t.Fatalf("expected an error without a key")
+	}
+	if err := Encrypt(ctx, dbPath, "secret"); !errors.Is(err, ErrEncryptionUnsupported) {
+		t.Fatalf("expected ErrEncryptionUnsupported, got %v", err)
+	}
+
+	store, err = Open(dbPath)
+	if err != nil {
+		t.Fatalf("reopen db: %v", err)
+	}
+	defer store.Close()
+	if _, err := store.GetJob(ctx, jobID); err != nil {
+		t.Fatalf("database changed by failed encrypt: %v", err)
+	}
+}
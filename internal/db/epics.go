@@ -0,0 +1,178 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// EpicItem is one open item of an issue's task list, to become a task job.
+type EpicItem struct {
+	Text      string
+	DependsOn []int // 1-based positions of the items it waits for
+}
+
+// Epic is an issue split into task jobs.
+type Epic struct {
+	AutoPRIssueID   string
+	ProjectName     string
+	RollupCommentID string // source issue comment holding the rollup; "" until posted
+	Rollup          string // rollup last posted, to skip unchanged updates
+}
+
+// EpicTask is one task job of an epic.
+type EpicTask struct {
+	JobID      string
+	Index      int
+	Text       string
+	State      string
+	PRURL      string
+	PRMergedAt string
+	PRClosedAt string
+	DependsOn  []int // Index of each task it waits for
+}
+
+// dependencyMergedSQL is the SQL condition, on a jobs row aliased dep, for a
+// job dependency being met.
+const dependencyMergedSQL = `dep.state = 'approved' AND COALESCE(dep.pr_merged_at,'') != ''`
+
+// SplitEpic records job's issue as an epic of items. job becomes the task
+// job of the first item, which must not depend on another; a queued job is
+// created for each other item, waiting for the jobs of the items it depends
+// on. It returns the task job IDs in item order.
+func (s *Store) SplitEpic(ctx context.Context, job Job, items []EpicItem) ([]string, error) {
+	if len(items) == 0 || len(items[0].DependsOn) > 0 {
+		return nil, fmt.Errorf("split epic: the first item must not depend on another")
+	}
+	ids := make([]string, len(items))
+	err := s.retryBusy(ctx, "split epic", func() error {
+		tx, err := s.Writer.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+		if _, err := tx.ExecContext(ctx, `INSERT INTO epics(autopr_issue_id, project_name) VALUES(?,?)`, job.AutoPRIssueID, job.ProjectName); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, `UPDATE jobs SET epic_index = 1, epic_task = ?, updated_at = ? WHERE id = ?`, items[0].Text, nowRFC3339(), job.ID); err != nil {
+			return err
+		}
+		ids[0] = job.ID
+		for i, item := range items[1:] {
+			id := newJobID()
+			const q = `INSERT INTO jobs(id, autopr_issue_id, project_name, state, max_iterations, priority, epic_index, epic_task) VALUES(?,?,?,'queued',?,?,?,?)`
+			if _, err := tx.ExecContext(ctx, q, id, job.AutoPRIssueID, job.ProjectName, job.MaxIterations, job.Priority, i+2, item.Text); err != nil {
+				return err
+			}
+			ids[i+1] = id
+		}
+		for i, item := range items {
+			for _, dep := range item.DependsOn {
+				if dep < 1 || dep > len(items) || dep == i+1 {
+					return fmt.Errorf("item %d depends on unknown item %d", i+1, dep)
+				}
+				if _, err := tx.ExecContext(ctx, `INSERT OR IGNORE INTO job_dependencies(job_id, depends_on) VALUES(?,?)`, ids[i], ids[dep-1]); err != nil {
+					return err
+				}
+			}
+		}
+		return tx.Commit()
+	})
+	if err != nil {
+		return nil, fmt.Errorf("split epic: %w", err)
+	}
+	return ids, nil
+}
+
+// IsEpic reports whether the issue was split into task jobs.
+func (s *Store) IsEpic(ctx context.Context, autoprIssueID string) (bool, error) {
+	var n int
+	if err := s.Reader.QueryRowContext(ctx, `SELECT COUNT(*) FROM epics WHERE autopr_issue_id = ?`, autoprIssueID).Scan(&n); err != nil {
+		return false, fmt.Errorf("check epic: %w", err)
+	}
+	return n > 0, nil
+}
+
+// ListEpics returns every split issue, oldest first.
+func (s *Store) ListEpics(ctx context.Context) ([]Epic, error) {
+	rows, err := s.Reader.QueryContext(ctx, `SELECT autopr_issue_id, project_name, rollup_comment_id, rollup FROM epics ORDER BY created_at, autopr_issue_id`)
+	if err != nil {
+		return nil, fmt.Errorf("list epics: %w", err)
+	}
+	defer rows.Close()
+	var out []Epic
+	for rows.Next() {
+		var e Epic
+		if err := rows.Scan(&e.AutoPRIssueID, &e.ProjectName, &e.RollupCommentID, &e.Rollup); err != nil {
+			return nil, fmt.Errorf("scan epic: %w", err)
+		}
+		out = append(out, e)
+	}
+	return out, rows.Err()
+}
+
+// ListEpicTasks returns the task jobs of an epic in task list order.
+func (s *Store) ListEpicTasks(ctx context.Context, autoprIssueID string) ([]EpicTask, error) {
+	const q = `
+SELECT j.id, j.epic_index, j.epic_task, j.state, COALESCE(j.pr_url,''), COALESCE(j.pr_merged_at,''), COALESCE(j.pr_closed_at,''),
+       COALESCE((SELECT GROUP_CONCAT(p.epic_index) FROM job_dependencies d JOIN jobs p ON p.id = d.depends_on WHERE d.job_id = j.id), '')
+FROM jobs j
+WHERE j.autopr_issue_id = ? AND j.epic_index > 0
+ORDER BY j.epic_index, j.created_at`
+	rows, err := s.Reader.QueryContext(ctx, q, autoprIssueID)
+	if err != nil {
+		return nil, fmt.Errorf("list epic tasks: %w", err)
+	}
+	defer rows.Close()
+	var out []EpicTask
+	for rows.Next() {
+		var (
+			t    EpicTask
+			deps string
+		)
+		if err := rows.Scan(&t.JobID, &t.Index, &t.Text, &t.State, &t.PRURL, &t.PRMergedAt, &t.PRClosedAt, &deps); err != nil {
+			return nil, fmt.Errorf("scan epic task: %w", err)
+		}
+		for dep := range strings.SplitSeq(deps, ",") {
+			if n, err := strconv.Atoi(dep); err == nil {
+				t.DependsOn = append(t.DependsOn, n)
+			}
+		}
+		slices.Sort(t.DependsOn)
+		out = append(out, t)
+	}
+	return out, rows.Err()
+}
+
+// UnmetDependencies returns the IDs of the jobs jobID waits for that have not
+// merged yet.
+func (s *Store) UnmetDependencies(ctx context.Context, jobID string) ([]string, error) {
+	rows, err := s.Reader.QueryContext(ctx, `
+SELECT dep.id FROM job_dependencies d JOIN jobs dep ON dep.id = d.depends_on
+WHERE d.job_id = ? AND NOT (`+dependencyMergedSQL+`)
+ORDER BY dep.epic_index, dep.id`, jobID)
+	if err != nil {
+		return nil, fmt.Errorf("list unmet dependencies: %w", err)
+	}
+	defer rows.Close()
+	var out []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan dependency: %w", err)
+		}
+		out = append(out, id)
+	}
+	return out, rows.Err()
+}
+
+// SetEpicRollup records the rollup last posted to the epic's source issue and
+// the comment holding it.
+func (s *Store) SetEpicRollup(ctx context.Context, autoprIssueID, commentID, rollup string) error {
+	if _, err := s.Writer.ExecContext(ctx, `UPDATE epics SET rollup_comment_id = ?, rollup = ? WHERE autopr_issue_id = ?`, commentID, rollup, autoprIssueID); err != nil {
+		return fmt.Errorf("set epic rollup: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package db
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestSplitEpicQueuesTasksBehindDependencies(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	store, err := Open(filepath.Join(t.TempDir(), "autopr.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer store.Close()
+
+	first := createTestJobWithStateAndProject(t, ctx, store, "10", "planning", "web")
+	job, err := store.GetJob(ctx, first)
+	if err != nil {
+		t.Fatalf("get job: %v", err)
+	}
+	if _, err := store.Writer.ExecContext(ctx, `UPDATE issues SET eligible = 1 WHERE autopr_issue_id = ?`, job.AutoPRIssueID); err != nil {
+		t.Fatalf("mark issue eligible: %v", err)
+	}
+
+	ids, err := store.SplitEpic(ctx, job, []EpicItem{
+		{Text: "Add the API"},
+		{Text: "Add the UI", DependsOn: []int{1}},
+		{Text: "Write docs"},
+	})
+	if err != nil {
+		t.Fatalf("split epic: %v", err)
+	}
+	if len(ids) != 3 || ids[0] != first {
+		t.Fatalf("unexpected task jobs: %v", ids)
+	}
+	if epic, err := store.IsEpic(ctx, job.AutoPRIssueID); err != nil || !epic {
+		t.Fatalf("IsEpic = %v, %v", epic, err)
+	}
+	if _, err := store.SplitEpic(ctx, job, []EpicItem{{Text: "again"}}); err == nil {
+		t.Fatal("expected splitting an epic twice to fail")
+	}
+
+	tasks, err := store.ListEpicTasks(ctx, job.AutoPRIssueID)
+	if err != nil {
+		t.Fatalf("list epic tasks: %v", err)
+	}
+	if len(tasks) != 3 || tasks[0].JobID != first || tasks[1].Text != "Add the UI" || !slices.Equal(tasks[1].DependsOn, []int{1}) || len(tasks[2].DependsOn) != 0 {
+		t.Fatalf("unexpected tasks: %+v", tasks)
+	}
+	if got, _ := store.GetJob(ctx, first); got.EpicIndex != 1 || got.Title() != "Add the API" {
+		t.Fatalf("first job not turned into task 1: %+v", got)
+	}
+
+	// The UI task waits for the API task; the docs task does not.
+	if claimed, err := store.ClaimJob(ctx); err != nil || claimed != ids[2] {
+		t.Fatalf("claim = %q, %v; want the docs task", claimed, err)
+	}
+	if claimed, err := store.ClaimJob(ctx); err != nil || claimed != "" {
+		t.Fatalf("claim = %q, %v; want nothing while the API task is unmerged", claimed, err)
+	}
+	if unmet, err := store.UnmetDependencies(ctx, ids[1]); err != nil || !slices.Equal(unmet, []string{first}) {
+		t.Fatalf("unmet dependencies = %v, %v", unmet, err)
+	}
+
+	if _, err := store.Writer.ExecContext(ctx, `UPDATE jobs SET state = 'approved' WHERE id = ?`, first); err != nil {
+		t.Fatalf("approve task 1: %v", err)
+	}
+	if claimed, _ := store.ClaimJob(ctx); claimed != "" {
+		t.Fatalf("claimed %q before task 1 merged", claimed)
+	}
+	if err := store.MarkJobMerged(ctx, first, "2026-01-02T00:00:00Z"); err != nil {
+		t.Fatalf("mark merged: %v", err)
+	}
+	if claimed, err := store.ClaimJob(ctx); err != nil || claimed != ids[1] {
+		t.Fatalf("claim = %q, %v; want the UI task", claimed, err)
+	}
+}
@@ -0,0 +1,82 @@
+package db
+
+import (
+	"context"
+	"fmt"
+)
+
+// FlakyTest is a test that failed and then passed on a re-run, totalled
+// over a project's jobs.
+type FlakyTest struct {
+	ProjectName string
+	TestName    string
+	Count       int    // runs where the test was flaky
+	Jobs        int    // distinct jobs those runs belong to
+	LastSeen    string // when it was last flaky
+	LastJobID   string
+}
+
+// RecordFlakyTests records that each of tests was flaky in one of jobID's
+// test runs.
+func (s *Store) RecordFlakyTests(ctx context.Context, projectName, jobID string, tests []string) error {
+	return s.retryBusy(ctx, "record flaky tests", func() error {
+		tx, err := s.Writer.BeginTx(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("record flaky tests of job %s: %w", jobID, err)
+		}
+		defer tx.Rollback()
+		for _, name := range tests {
+			if _, err := tx.ExecContext(ctx, `INSERT INTO flaky_tests(project_name, test_name, job_id) VALUES(?,?,?)`, projectName, name, jobID); err != nil {
+				return fmt.Errorf("record flaky tests of job %s: %w", jobID, err)
+			}
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("record flaky tests of job %s: %w", jobID, err)
+		}
+		return nil
+	})
+}
+
+// ListFlakyTests totals flaky test runs per project and test, most flaky
+// first. Empty project means all projects; since (RFC3339) limits the count to
+// runs at or after it; excludeDeleted leaves out runs of jobs in the trash.
+func (s *Store) ListFlakyTests(ctx context.Context, project, since string, excludeDeleted bool) ([]FlakyTest, error) {
+	q := `
+SELECT project_name, test_name, COUNT(*), COUNT(DISTINCT job_id), MAX(created_at),
+       (SELECT f2.job_id FROM flaky_tests f2
+        WHERE f2.project_name = f.project_name AND f2.test_name = f.test_name
+        ORDER BY f2.id DESC LIMIT 1)
+FROM flaky_tests f
+WHERE 1=1`
+	var args []any
+	if project != "" {
+		q += ` AND project_name = ?`
+		args = append(args, project)
+	}
+	if since != "" {
+		q += ` AND created_at >= ?`
+		args = append(args, since)
+	}
+	if excludeDeleted {
+		q += ` AND NOT EXISTS(SELECT 1 FROM jobs j WHERE j.id = f.job_id AND j.deleted_at != '')`
+	}
+	q += `
+GROUP BY project_name, test_name
+ORDER BY project_name, COUNT(*) DESC, test_name`
+
+	rows, err := s.Reader.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list flaky tests: %w", err)
+	}
+	defer rows.Close()
+
+	var out []FlakyTest
+	for rows.Next() {
+		var f FlakyTest
+		if err := rows.Scan(&f.ProjectName, &f.TestName, &f.Count, &f.Jobs, &f.LastSeen, &f.LastJobID); err != nil {
+			return nil, fmt.Errorf("scan flaky test: %w", err)
+		}
+		out = append(out, f)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,78 @@
+package db
+
+import (
+	"context"
+	"fmt"
+)
+
+// Kinds of issue attachments.
+const (
+	AttachmentText  = "text"
+	AttachmentImage = "image"
+)
+
+// IssueAttachment is a file uploaded to a source issue or one of its
+// comments. Path is empty when the file was not downloaded; Error says why.
+type IssueAttachment struct {
+	URL       string
+	Name      string
+	Kind      string // AttachmentText or AttachmentImage; "" when not downloaded
+	Path      string
+	SizeBytes int64
+	Error     string
+	FetchedAt string
+}
+
+// UpsertIssueAttachment records a, replacing what was recorded for its URL.
+func (s *Store) UpsertIssueAttachment(ctx context.Context, autoprIssueID string, a IssueAttachment) error {
+	const q = `
+INSERT INTO issue_attachments(autopr_issue_id, url, name, kind, path, size_bytes, error, fetched_at) VALUES(?,?,?,?,?,?,?,?)
+ON CONFLICT(autopr_issue_id, url) DO UPDATE SET
+  name = excluded.name, kind = excluded.kind, path = excluded.path,
+  size_bytes = excluded.size_bytes, error = excluded.error, fetched_at = excluded.fetched_at`
+	if _, err := s.Writer.ExecContext(ctx, q, autoprIssueID, a.URL, a.Name, a.Kind, a.Path, a.SizeBytes, a.Error, nowRFC3339()); err != nil {
+		return fmt.Errorf("upsert issue attachment: %w", err)
+	}
+	return nil
+}
+
+// ListIssueAttachments returns the attachments of an issue in the order they
+// were first found.
+func (s *Store) ListIssueAttachments(ctx context.Context, autoprIssueID string) ([]IssueAttachment, error) {
+	rows, err := s.Reader.QueryContext(ctx, `
+SELECT url, name, kind, path, size_bytes, error, fetched_at FROM issue_attachments
+WHERE autopr_issue_id = ?
+ORDER BY rowid`, autoprIssueID)
+	if err != nil {
+		return nil, fmt.Errorf("list issue attachments: %w", err)
+	}
+	defer rows.Close()
+	var out []IssueAttachment
+	for rows.Next() {
+		var a IssueAttachment
+		if err := rows.Scan(&a.URL, &a.Name, &a.Kind, &a.Path, &a.SizeBytes, &a.Error, &a.FetchedAt); err != nil {
+			return nil, fmt.Errorf("scan issue attachment: %w", err)
+		}
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
+
+// SetIssueAttachmentsSynced records that the issue's attachments were looked
+// for when it was last updated at sourceUpdated.
+func (s *Store) SetIssueAttachmentsSynced(ctx context.Context, autoprIssueID, sourceUpdated string) error {
+	if _, err := s.Writer.ExecContext(ctx, `UPDATE issues SET attachments_source_updated_at = ? WHERE autopr_issue_id = ?`, sourceUpdated, autoprIssueID); err != nil {
+		return fmt.Errorf("set issue attachments sync: %w", err)
+	}
+	return nil
+}
+
+// IssueAttachmentsSourceUpdated returns the issue's source update time when
+// its attachments were last looked for, or "" when they never were.
+func (s *Store) IssueAttachmentsSourceUpdated(ctx context.Context, autoprIssueID string) (string, error) {
+	var v string
+	if err := s.Reader.QueryRowContext(ctx, `SELECT attachments_source_updated_at FROM issues WHERE autopr_issue_id = ?`, autoprIssueID).Scan(&v); err != nil {
+		return "", fmt.Errorf("get issue attachments sync: %w", err)
+	}
+	return v, nil
+}
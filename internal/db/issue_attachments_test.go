@@ -0,0 +1,50 @@
+package db
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+func TestUpsertIssueAttachmentReplacesByURL(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	store, err := Open(filepath.Join(t.TempDir(), "autopr.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer store.Close()
+
+	issueID, err := store.UpsertIssue(ctx, IssueUpsert{ProjectName: "web", Source: "github", SourceIssueID: "7", Title: "t", URL: "u", State: "open"})
+	if err != nil {
+		t.Fatalf("upsert issue: %v", err)
+	}
+	if v, err := store.IssueAttachmentsSourceUpdated(ctx, issueID); err != nil || v != "" {
+		t.Fatalf("expected attachments never synced, got %q, %v", v, err)
+	}
+
+	if err := store.UpsertIssueAttachment(ctx, issueID, IssueAttachment{URL: "https://x/crash.log", Name: "crash.log", Error: "HTTP 502"}); err != nil {
+		t.Fatalf("upsert attachment: %v", err)
+	}
+	if err := store.UpsertIssueAttachment(ctx, issueID, IssueAttachment{URL: "https://x/shot.png", Name: "shot.png", Kind: AttachmentImage, Path: "/a/shot.png", SizeBytes: 10}); err != nil {
+		t.Fatalf("upsert attachment: %v", err)
+	}
+	if err := store.UpsertIssueAttachment(ctx, issueID, IssueAttachment{URL: "https://x/crash.log", Name: "crash.log", Kind: AttachmentText, Path: "/a/crash.log", SizeBytes: 5}); err != nil {
+		t.Fatalf("upsert attachment: %v", err)
+	}
+	if err := store.SetIssueAttachmentsSynced(ctx, issueID, "2026-01-02T00:00:00Z"); err != nil {
+		t.Fatalf("set synced: %v", err)
+	}
+
+	got, err := store.ListIssueAttachments(ctx, issueID)
+	if err != nil {
+		t.Fatalf("list attachments: %v", err)
+	}
+	if len(got) != 2 || got[0].Name != "crash.log" || got[0].Kind != AttachmentText || got[0].Error != "" || got[0].Path != "/a/crash.log" || got[1].Kind != AttachmentImage {
+		t.Fatalf("unexpected attachments: %+v", got)
+	}
+	if v, err := store.IssueAttachmentsSourceUpdated(ctx, issueID); err != nil || v != "2026-01-02T00:00:00Z" {
+		t.Fatalf("expected sync recorded, got %q, %v", v, err)
+	}
+}
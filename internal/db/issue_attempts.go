@@ -0,0 +1,237 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// IssueAttempts is how often jobs for an issue have run and failed, and the
+// hold [projects.issue_policy] put on it, if any.
+type IssueAttempts struct {
+	AutoPRIssueID string
+	ProjectName   string
+	SourceIssueID string
+	Jobs          int    // job runs, retries included
+	Failures      int    // failed runs since the last cooldown or clear
+	HoldReason    string // empty when the issue is not held
+	HoldUntil     string // when the hold ends; empty means until cleared
+	Busy          bool   // a job is running or waiting on review or CI
+	Succeeded     bool   // a job reached approved
+}
+
+// issueAttemptsJobColumns are the Busy and Succeeded columns of the issue
+// aliased as i.
+const issueAttemptsJobColumns = `
+       EXISTS(SELECT 1 FROM jobs j WHERE j.autopr_issue_id = i.autopr_issue_id
+              AND j.state NOT IN ('queued','approved','rejected','failed','cancelled')),
+       EXISTS(SELECT 1 FROM jobs j WHERE j.autopr_issue_id = i.autopr_issue_id AND j.state = 'approved')`
+
+// Held reports whether the issue is held by its project's issue policy.
+func (a IssueAttempts) Held() bool { return a.HoldReason != "" }
+
+// countIssueJobSQL counts a job run against its issue.
+const countIssueJobSQL = `
+INSERT INTO issue_attempts(autopr_issue_id, jobs)
+SELECT autopr_issue_id, 1 FROM jobs WHERE id = ?
+ON CONFLICT(autopr_issue_id) DO UPDATE SET
+    jobs = jobs + 1,
+    updated_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now')`
+
+// countIssueFailureSQL counts a failed job run against its issue.
+const countIssueFailureSQL = `
+INSERT INTO issue_attempts(autopr_issue_id, failures)
+SELECT autopr_issue_id, 1 FROM jobs WHERE id = ?
+ON CONFLICT(autopr_issue_id) DO UPDATE SET
+    failures = failures + 1,
+    updated_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now')`
+
+// ListIssueAttempts returns the attempt counts of project's issues that have
+// had a job run, held issues included.
+func (s *Store) ListIssueAttempts(ctx context.Context, project string) ([]IssueAttempts, error) {
+	rows, err := s.Reader.QueryContext(ctx, `
+SELECT a.autopr_issue_id, i.project_name, i.source_issue_id, a.jobs, a.failures, a.hold_reason, a.hold_until,`+issueAttemptsJobColumns+`
+FROM issue_attempts a
+JOIN issues i ON i.autopr_issue_id = a.autopr_issue_id
+WHERE i.project_name = ?
+ORDER BY a.autopr_issue_id`, project)
+	if err != nil {
+		return nil, fmt.Errorf("list issue attempts of %s: %w", project, err)
+	}
+	defer rows.Close()
+
+	var out []IssueAttempts
+	for rows.Next() {
+		var a IssueAttempts
+		if err := rows.Scan(&a.AutoPRIssueID, &a.ProjectName, &a.SourceIssueID, &a.Jobs, &a.Failures, &a.HoldReason, &a.HoldUntil, &a.Busy, &a.Succeeded); err != nil {
+			return nil, fmt.Errorf("scan issue attempts: %w", err)
+		}
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
+
+// GetIssueAttempts returns an issue's attempt counts; an issue no job has run
+// for has zero counts and no hold.
+func (s *Store) GetIssueAttempts(ctx context.Context, autoprIssueID string) (IssueAttempts, error) {
+	a := IssueAttempts{AutoPRIssueID: autoprIssueID}
+	err := s.Reader.QueryRowContext(ctx, `
+SELECT i.project_name, i.source_issue_id, COALESCE(a.jobs, 0), COALESCE(a.failures, 0),
+       COALESCE(a.hold_reason, ''), COALESCE(a.hold_until, ''),`+issueAttemptsJobColumns+`
+FROM issues i
+LEFT JOIN issue_attempts a ON a.autopr_issue_id = i.autopr_issue_id
+WHERE i.autopr_issue_id = ?`, autoprIssueID).Scan(&a.ProjectName, &a.SourceIssueID, &a.Jobs, &a.Failures, &a.HoldReason, &a.HoldUntil, &a.Busy, &a.Succeeded)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return IssueAttempts{}, fmt.Errorf("issue %s not found", autoprIssueID)
+		}
+		return IssueAttempts{}, fmt.Errorf("get attempts of issue %s: %w", autoprIssueID, err)
+	}
+	return a, nil
+}
+
+// HoldIssue makes an issue ineligible with reason as its skip_reason, until
+// until or, when until is zero, until ClearIssueHold. The issue's current
+// eligibility is kept to restore when the hold ends. A cooldown (non-zero
+// until) also resets the failure count so the next cooldown needs as many
+// new failures.
+func (s *Store) HoldIssue(ctx context.Context, autoprIssueID, reason string, until time.Time) error {
+	holdUntil := ""
+	if !until.IsZero() {
+		holdUntil = until.UTC().Format(time.RFC3339)
+	}
+	return s.retryBusy(ctx, "hold issue", func() error {
+		tx, err := s.Writer.BeginTx(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("hold issue %s: %w", autoprIssueID, err)
+		}
+		defer tx.Rollback()
+
+		if _, err := tx.ExecContext(ctx, `
+INSERT INTO issue_attempts(autopr_issue_id, hold_reason, hold_until, prev_eligible, prev_skip_reason)
+SELECT autopr_issue_id, ?, ?, eligible, skip_reason FROM issues WHERE autopr_issue_id = ?
+ON CONFLICT(autopr_issue_id) DO UPDATE SET
+    failures = CASE WHEN excluded.hold_until != '' THEN 0 ELSE failures END,
+    prev_eligible = CASE WHEN hold_reason = '' THEN excluded.prev_eligible ELSE prev_eligible END,
+    prev_skip_reason = CASE WHEN hold_reason = '' THEN excluded.prev_skip_reason ELSE prev_skip_reason END,
+    hold_reason = excluded.hold_reason,
+    hold_until = excluded.hold_until,
+    updated_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now')`, reason, holdUntil, autoprIssueID); err != nil {
+			return fmt.Errorf("hold issue %s: %w", autoprIssueID, err)
+		}
+		if _, err := tx.ExecContext(ctx, `
+UPDATE issues SET eligible = 0, skip_reason = ?, evaluated_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now')
+WHERE autopr_issue_id = ?`, reason, autoprIssueID); err != nil {
+			return fmt.Errorf("hold issue %s: %w", autoprIssueID, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("hold issue %s: %w", autoprIssueID, err)
+		}
+		return nil
+	})
+}
+
+// ClearIssueHold ends an issue's hold, restores the eligibility it had, and
+// resets its job and failure counts. It reports false if the issue was not
+// held.
+func (s *Store) ClearIssueHold(ctx context.Context, autoprIssueID string) (bool, error) {
+	var cleared bool
+	err := s.retryBusy(ctx, "clear issue hold", func() error {
+		tx, err := s.Writer.BeginTx(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("clear hold of issue %s: %w", autoprIssueID, err)
+		}
+		defer tx.Rollback()
+
+		if cleared, err = releaseIssueHoldTx(ctx, tx, autoprIssueID, true); err != nil {
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("clear hold of issue %s: %w", autoprIssueID, err)
+		}
+		return nil
+	})
+	return cleared, err
+}
+
+// ReleaseExpiredIssueHolds ends the holds whose hold_until has passed and
+// returns the issues released.
+func (s *Store) ReleaseExpiredIssueHolds(ctx context.Context, now time.Time) ([]string, error) {
+	rows, err := s.Reader.QueryContext(ctx, `
+SELECT autopr_issue_id FROM issue_attempts
+WHERE hold_reason != '' AND hold_until != '' AND hold_until <= ?`, now.UTC().Format(time.RFC3339))
+	if err != nil {
+		return nil, fmt.Errorf("list expired issue holds: %w", err)
+	}
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan expired issue hold: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list expired issue holds: %w", err)
+	}
+
+	var released []string
+	for _, id := range ids {
+		err := s.retryBusy(ctx, "release issue hold", func() error {
+			tx, err := s.Writer.BeginTx(ctx, nil)
+			if err != nil {
+				return fmt.Errorf("release hold of issue %s: %w", id, err)
+			}
+			defer tx.Rollback()
+			ok, err := releaseIssueHoldTx(ctx, tx, id, false)
+			if err != nil {
+				return err
+			}
+			if err := tx.Commit(); err != nil {
+				return fmt.Errorf("release hold of issue %s: %w", id, err)
+			}
+			if ok {
+				released = append(released, id)
+			}
+			return nil
+		})
+		if err != nil {
+			return released, err
+		}
+	}
+	return released, nil
+}
+
+// releaseIssueHoldTx ends an issue's hold and restores its eligibility. A
+// human clear (resetJobs) also gives the issue a fresh job budget.
+func releaseIssueHoldTx(ctx context.Context, tx *sql.Tx, autoprIssueID string, resetJobs bool) (bool, error) {
+	var (
+		prevEligible int
+		prevReason   string
+	)
+	err := tx.QueryRowContext(ctx, `
+SELECT prev_eligible, prev_skip_reason FROM issue_attempts
+WHERE autopr_issue_id = ? AND hold_reason != ''`, autoprIssueID).Scan(&prevEligible, &prevReason)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("release hold of issue %s: %w", autoprIssueID, err)
+	}
+	if _, err := tx.ExecContext(ctx, `
+UPDATE issue_attempts SET hold_reason = '', hold_until = '', failures = 0,
+    jobs = CASE WHEN ? THEN 0 ELSE jobs END,
+    updated_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now')
+WHERE autopr_issue_id = ?`, resetJobs, autoprIssueID); err != nil {
+		return false, fmt.Errorf("release hold of issue %s: %w", autoprIssueID, err)
+	}
+	if _, err := tx.ExecContext(ctx, `
+UPDATE issues SET eligible = ?, skip_reason = ?, evaluated_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now')
+WHERE autopr_issue_id = ?`, prevEligible, prevReason, autoprIssueID); err != nil {
+		return false, fmt.Errorf("release hold of issue %s: %w", autoprIssueID, err)
+	}
+	return true, nil
+}
@@ -0,0 +1,117 @@
+package db
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestIssueAttemptsCountRunsAndHoldIssues(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	store, err := Open(filepath.Join(t.TempDir(), "autopr.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer store.Close()
+
+	upsert := IssueUpsert{
+		ProjectName:   "myproject",
+		Source:        "github",
+		SourceIssueID: "7",
+		Title:         "flaky",
+		URL:           "https://github.com/org/repo/issues/7",
+		State:         "open",
+	}
+	issueID, err := store.UpsertIssue(ctx, upsert)
+	if err != nil {
+		t.Fatalf("upsert issue: %v", err)
+	}
+	jobID, err := store.CreateJob(ctx, issueID, "myproject", 3)
+	if err != nil {
+		t.Fatalf("create job: %v", err)
+	}
+
+	// Two runs that both fail, the second one a retry of the same job.
+	for run := range 2 {
+		if run > 0 {
+			if err := store.ResetJobForRetry(ctx, jobID, ""); err != nil {
+				t.Fatalf("retry: %v", err)
+			}
+		}
+		claimed, err := store.ClaimJob(ctx)
+		if err != nil || claimed != jobID {
+			t.Fatalf("claim = %q, %v; want %s", claimed, err, jobID)
+		}
+		if err := store.TransitionState(ctx, jobID, "planning", "failed"); err != nil {
+			t.Fatalf("fail job: %v", err)
+		}
+	}
+	a, err := store.GetIssueAttempts(ctx, issueID)
+	if err != nil {
+		t.Fatalf("get attempts: %v", err)
+	}
+	if a.Jobs != 2 || a.Failures != 2 || a.Held() || a.Busy || a.Succeeded {
+		t.Fatalf("attempts = %+v, want 2 jobs, 2 failures, not held", a)
+	}
+
+	until := time.Now().Add(time.Hour)
+	if err := store.HoldIssue(ctx, issueID, "cooling down", until); err != nil {
+		t.Fatalf("hold issue: %v", err)
+	}
+	// A sync while held keeps the issue ineligible.
+	if _, err := store.UpsertIssue(ctx, upsert); err != nil {
+		t.Fatalf("re-upsert issue: %v", err)
+	}
+	issue, err := store.GetIssueByAPID(ctx, issueID)
+	if err != nil {
+		t.Fatalf("get issue: %v", err)
+	}
+	if issue.Eligible || issue.SkipReason != "cooling down" {
+		t.Fatalf("held issue eligible=%v skip_reason=%q", issue.Eligible, issue.SkipReason)
+	}
+	if err := store.ResetJobForRetry(ctx, jobID, ""); err == nil {
+		t.Fatal("expected retry of a held issue to be refused")
+	}
+
+	released, err := store.ReleaseExpiredIssueHolds(ctx, time.Now())
+	if err != nil || len(released) != 0 {
+		t.Fatalf("release before expiry = %v, %v; want none", released, err)
+	}
+	released, err = store.ReleaseExpiredIssueHolds(ctx, until.Add(time.Second))
+	if err != nil || len(released) != 1 || released[0] != issueID {
+		t.Fatalf("release after expiry = %v, %v; want %s", released, err, issueID)
+	}
+	issue, _ = store.GetIssueByAPID(ctx, issueID)
+	if !issue.Eligible || issue.SkipReason != "" {
+		t.Fatalf("released issue eligible=%v skip_reason=%q", issue.Eligible, issue.SkipReason)
+	}
+	a, _ = store.GetIssueAttempts(ctx, issueID)
+	if a.Jobs != 2 || a.Failures != 0 {
+		t.Fatalf("after cooldown attempts = %+v, want jobs kept and failures reset", a)
+	}
+
+	// A hold until cleared, lifted by a human.
+	if err := store.HoldIssue(ctx, issueID, "max jobs", time.Time{}); err != nil {
+		t.Fatalf("hold issue: %v", err)
+	}
+	if released, _ := store.ReleaseExpiredIssueHolds(ctx, time.Now().Add(24*time.Hour)); len(released) != 0 {
+		t.Fatalf("hold until cleared was released: %v", released)
+	}
+	cleared, err := store.ClearIssueHold(ctx, issueID)
+	if err != nil || !cleared {
+		t.Fatalf("clear hold = %v, %v", cleared, err)
+	}
+	a, _ = store.GetIssueAttempts(ctx, issueID)
+	if a.Held() || a.Jobs != 0 || a.Failures != 0 {
+		t.Fatalf("after clear attempts = %+v, want fresh budget", a)
+	}
+	if cleared, _ := store.ClearIssueHold(ctx, issueID); cleared {
+		t.Fatal("clearing an issue that is not held reported true")
+	}
+	if err := store.ResetJobForRetry(ctx, jobID, ""); err != nil {
+		t.Fatalf("retry after clear: %v", err)
+	}
+}
@@ -0,0 +1,75 @@
+package db
+
+import (
+	"context"
+	"fmt"
+)
+
+// IssueComment is one comment on a source issue, as last synced.
+type IssueComment struct {
+	CommentID string
+	Author    string
+	Body      string
+	CreatedAt string
+}
+
+// ReplaceIssueComments stores comments as the issue's whole discussion,
+// fetched when the issue was last updated at sourceUpdated.
+func (s *Store) ReplaceIssueComments(ctx context.Context, autoprIssueID, sourceUpdated string, comments []IssueComment) error {
+	err := s.retryBusy(ctx, "replace issue comments", func() error {
+		tx, err := s.Writer.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+		if _, err := tx.ExecContext(ctx, `DELETE FROM issue_comments WHERE autopr_issue_id = ?`, autoprIssueID); err != nil {
+			return err
+		}
+		for _, c := range comments {
+			if _, err := tx.ExecContext(ctx, `INSERT OR REPLACE INTO issue_comments(autopr_issue_id, comment_id, author, body, created_at) VALUES(?,?,?,?,?)`,
+				autoprIssueID, c.CommentID, c.Author, c.Body, c.CreatedAt); err != nil {
+				return err
+			}
+		}
+		if _, err := tx.ExecContext(ctx, `UPDATE issues SET comments_synced_at = ?, comments_source_updated_at = ? WHERE autopr_issue_id = ?`,
+			nowRFC3339(), sourceUpdated, autoprIssueID); err != nil {
+			return err
+		}
+		return tx.Commit()
+	})
+	if err != nil {
+		return fmt.Errorf("replace issue comments: %w", err)
+	}
+	return nil
+}
+
+// ListIssueComments returns the synced comments of an issue, oldest first.
+func (s *Store) ListIssueComments(ctx context.Context, autoprIssueID string) ([]IssueComment, error) {
+	rows, err := s.Reader.QueryContext(ctx, `
+SELECT comment_id, author, body, created_at FROM issue_comments
+WHERE autopr_issue_id = ?
+ORDER BY created_at, rowid`, autoprIssueID)
+	if err != nil {
+		return nil, fmt.Errorf("list issue comments: %w", err)
+	}
+	defer rows.Close()
+	var out []IssueComment
+	for rows.Next() {
+		var c IssueComment
+		if err := rows.Scan(&c.CommentID, &c.Author, &c.Body, &c.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan issue comment: %w", err)
+		}
+		out = append(out, c)
+	}
+	return out, rows.Err()
+}
+
+// IssueCommentsSourceUpdated returns the issue's source update time when its
+// comments were last synced, or "" when they never were.
+func (s *Store) IssueCommentsSourceUpdated(ctx context.Context, autoprIssueID string) (string, error) {
+	var v string
+	if err := s.Reader.QueryRowContext(ctx, `SELECT comments_source_updated_at FROM issues WHERE autopr_issue_id = ?`, autoprIssueID).Scan(&v); err != nil {
+		return "", fmt.Errorf("get issue comments sync: %w", err)
+	}
+	return v, nil
+}
@@ -0,0 +1,54 @@
+package db
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestReplaceIssueCommentsKeepsLatestThread(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	store, err := Open(filepath.Join(t.TempDir(), "autopr.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer store.Close()
+
+	issueID, err := store.UpsertIssue(ctx, IssueUpsert{ProjectName: "web", Source: "github", SourceIssueID: "7", Title: "t", URL: "u", State: "open"})
+	if err != nil {
+		t.Fatalf("upsert issue: %v", err)
+	}
+	if v, err := store.IssueCommentsSourceUpdated(ctx, issueID); err != nil || v != "" {
+		t.Fatalf("expected comments never synced, got %q, %v", v, err)
+	}
+
+	first := []IssueComment{
+		{CommentID: "2", Author: "bob", Body: "Also on Safari.", CreatedAt: "2026-01-02T00:00:00Z"},
+		{CommentID: "1", Author: "ann", Body: "Repro: click twice.", CreatedAt: "2026-01-01T00:00:00Z"},
+	}
+	if err := store.ReplaceIssueComments(ctx, issueID, "2026-01-02T00:00:00Z", first); err != nil {
+		t.Fatalf("replace comments: %v", err)
+	}
+	// A deleted comment is gone after the next sync; a new one is added.
+	second := []IssueComment{
+		first[1],
+		{CommentID: "3", Author: "ann", Body: "Only the header button.", CreatedAt: "2026-01-03T00:00:00Z"},
+	}
+	if err := store.ReplaceIssueComments(ctx, issueID, "2026-01-03T00:00:00Z", second); err != nil {
+		t.Fatalf("replace comments again: %v", err)
+	}
+
+	got, err := store.ListIssueComments(ctx, issueID)
+	if err != nil {
+		t.Fatalf("list comments: %v", err)
+	}
+	if !slices.Equal(got, second) {
+		t.Fatalf("comments = %+v, want %+v", got, second)
+	}
+	if v, err := store.IssueCommentsSourceUpdated(ctx, issueID); err != nil || v != "2026-01-03T00:00:00Z" {
+		t.Fatalf("expected comments synced at the last update, got %q, %v", v, err)
+	}
+}
@@ -0,0 +1,80 @@
+package db
+
+import (
+	"context"
+	"fmt"
+)
+
+const (
+	IssueLockStatusHeld     = "held"
+	IssueLockStatusReleased = "released"
+)
+
+// IssueLock records that a job has marked its source issue as in progress
+// (assignee and/or label) and whether that marking has been undone.
+type IssueLock struct {
+	JobID     string
+	JobState  string
+	Status    string
+	CreatedAt string
+	UpdatedAt string
+}
+
+// MarkIssueLockHeld records that jobID holds its source issue. Returns true if
+// the lock was newly acquired (not already held), so callers only touch the
+// remote tracker once per job run.
+func (s *Store) MarkIssueLockHeld(ctx context.Context, jobID string) (bool, error) {
+	res, err := s.Writer.ExecContext(ctx, `
+INSERT INTO issue_locks(job_id, status) VALUES(?, 'held')
+ON CONFLICT(job_id) DO UPDATE SET
+    status = 'held',
+    updated_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now')
+WHERE issue_locks.status = 'released'`, jobID)
+	if err != nil {
+		return false, fmt.Errorf("mark issue lock held for job %s: %w", jobID, err)
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// MarkIssueLockReleased records that jobID no longer holds its source issue.
+func (s *Store) MarkIssueLockReleased(ctx context.Context, jobID string) error {
+	_, err := s.Writer.ExecContext(ctx, `
+UPDATE issue_locks
+SET status = 'released',
+    updated_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now')
+WHERE job_id = ?`, jobID)
+	if err != nil {
+		return fmt.Errorf("mark issue lock released for job %s: %w", jobID, err)
+	}
+	return nil
+}
+
+// ListReleasableIssueLocks returns held locks whose job has reached a terminal
+// state (approved, rejected, failed, cancelled).
+func (s *Store) ListReleasableIssueLocks(ctx context.Context) ([]IssueLock, error) {
+	rows, err := s.Reader.QueryContext(ctx, `
+SELECT l.job_id, j.state, l.status, l.created_at, l.updated_at
+FROM issue_locks l
+JOIN jobs j ON j.id = l.job_id
+WHERE l.status = 'held'
+  AND j.state IN ('approved', 'rejected', 'failed', 'cancelled')
+ORDER BY l.updated_at ASC`)
+	if err != nil {
+		return nil, fmt.Errorf("list releasable issue locks: %w", err)
+	}
+	defer rows.Close()
+
+	var out []IssueLock
+	for rows.Next() {
+		var lock IssueLock
+		if err := rows.Scan(&lock.JobID, &lock.JobState, &lock.Status, &lock.CreatedAt, &lock.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("scan issue lock: %w", err)
+		}
+		out = append(out, lock)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list releasable issue locks: %w", err)
+	}
+	return out, nil
+}
@@ -0,0 +1,90 @@
+package db
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+func TestIssueLockLifecycle(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	store, err := Open(filepath.Join(t.TempDir(), "autopr.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer store.Close()
+
+	issueID, err := store.UpsertIssue(ctx, IssueUpsert{
+		ProjectName:   "myproject",
+		Source:        "github",
+		SourceIssueID: "77",
+		Title:         "lock me",
+		URL:           "https://github.com/org/repo/issues/77",
+		State:         "open",
+	})
+	if err != nil {
+		t.Fatalf("upsert issue: %v", err)
+	}
+	jobID, err := store.CreateJob(ctx, issueID, "myproject", 3)
+	if err != nil {
+		t.Fatalf("create job: %v", err)
+	}
+	if _, err := store.ClaimJob(ctx); err != nil {
+		t.Fatalf("claim job: %v", err)
+	}
+
+	acquired, err := store.MarkIssueLockHeld(ctx, jobID)
+	if err != nil {
+		t.Fatalf("mark held: %v", err)
+	}
+	if !acquired {
+		t.Fatalf("expected first mark to acquire lock")
+	}
+	acquired, err = store.MarkIssueLockHeld(ctx, jobID)
+	if err != nil {
+		t.Fatalf("mark held again: %v", err)
+	}
+	if acquired {
+		t.Fatalf("expected second mark to be a no-op while held")
+	}
+
+	locks, err := store.ListReleasableIssueLocks(ctx)
+	if err != nil {
+		t.Fatalf("list releasable: %v", err)
+	}
+	if len(locks) != 0 {
+		t.Fatalf("expected no releasable locks for active job, got %d", len(locks))
+	}
+
+	if err := store.TransitionState(ctx, jobID, "planning", "failed"); err != nil {
+		t.Fatalf("planning->failed: %v", err)
+	}
+	locks, err = store.ListReleasableIssueLocks(ctx)
+	if err != nil {
+		t.Fatalf("list releasable: %v", err)
+	}
+	if len(locks) != 1 || locks[0].JobID != jobID || locks[0].JobState != "failed" {
+		t.Fatalf("unexpected releasable locks: %+v", locks)
+	}
+
+	if err := store.MarkIssueLockReleased(ctx, jobID); err != nil {
+		t.Fatalf("mark released: %v", err)
+	}
+	locks, err = store.ListReleasableIssueLocks(ctx)
+	if err != nil {
+		t.Fatalf("list releasable: %v", err)
+	}
+	if len(locks) != 0 {
+		t.Fatalf("expected no releasable locks after release, got %d", len(locks))
+	}
+
+	acquired, err = store.MarkIssueLockHeld(ctx, jobID)
+	if err != nil {
+		t.Fatalf("re-mark held: %v", err)
+	}
+	if !acquired {
+		t.Fatalf("expected released lock to be re-acquirable on retry")
+	}
+}
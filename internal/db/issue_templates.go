@@ -0,0 +1,41 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// IssueTemplate records the job template an issue was created from.
+type IssueTemplate struct {
+	Template string
+	TestCmd  string // replaces the project's test_cmd; "" keeps it
+	LintCmd  string // replaces the project's lint_cmd; "" keeps it
+}
+
+// SetIssueTemplate records that an issue was created from a job template.
+func (s *Store) SetIssueTemplate(ctx context.Context, autoprIssueID string, t IssueTemplate) error {
+	const q = `
+INSERT INTO issue_templates(autopr_issue_id, template, test_cmd, lint_cmd) VALUES(?,?,?,?)
+ON CONFLICT(autopr_issue_id) DO UPDATE SET
+  template = excluded.template, test_cmd = excluded.test_cmd, lint_cmd = excluded.lint_cmd`
+	if _, err := s.Writer.ExecContext(ctx, q, autoprIssueID, t.Template, t.TestCmd, t.LintCmd); err != nil {
+		return fmt.Errorf("set issue template: %w", err)
+	}
+	return nil
+}
+
+// GetIssueTemplate returns the job template an issue was created from. ok is
+// false for issues not created from one.
+func (s *Store) GetIssueTemplate(ctx context.Context, autoprIssueID string) (t IssueTemplate, ok bool, err error) {
+	err = s.Reader.QueryRowContext(ctx, `SELECT template, test_cmd, lint_cmd FROM issue_templates WHERE autopr_issue_id = ?`, autoprIssueID).
+		Scan(&t.Template, &t.TestCmd, &t.LintCmd)
+	if errors.Is(err, sql.ErrNoRows) {
+		return IssueTemplate{}, false, nil
+	}
+	if err != nil {
+		return IssueTemplate{}, false, fmt.Errorf("get issue template: %w", err)
+	}
+	return t, true, nil
+}
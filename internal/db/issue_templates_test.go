@@ -0,0 +1,34 @@
+package db
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+func TestIssueTemplateRoundTrip(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	store, err := Open(filepath.Join(t.TempDir(), "autopr.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer store.Close()
+	issueID, err := store.UpsertIssue(ctx, IssueUpsert{ProjectName: "api", Source: "local", SourceIssueID: "run-1", Title: "t", State: "open"})
+	if err != nil {
+		t.Fatalf("upsert issue: %v", err)
+	}
+
+	if _, ok, err := store.GetIssueTemplate(ctx, issueID); err != nil || ok {
+		t.Fatalf("expected no template, got %v, %v", ok, err)
+	}
+	want := IssueTemplate{Template: "add-endpoint", TestCmd: "go test ./billing/..."}
+	if err := store.SetIssueTemplate(ctx, issueID, want); err != nil {
+		t.Fatalf("set template: %v", err)
+	}
+	got, ok, err := store.GetIssueTemplate(ctx, issueID)
+	if err != nil || !ok || got != want {
+		t.Fatalf("GetIssueTemplate = %+v, %v, %v; want %+v", got, ok, err, want)
+	}
+}
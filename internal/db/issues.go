@@ -29,6 +29,13 @@ type Issue struct {
 	SyncedAt       string
 }
 
+// Labels returns the issue's labels as synced from its source.
+func (i Issue) Labels() []string {
+	var labels []string
+	_ = json.Unmarshal([]byte(i.LabelsJSON), &labels)
+	return labels
+}
+
 type IssueSyncSummary struct {
 	Synced   int
 	Eligible int
@@ -49,6 +56,7 @@ type IssueUpsert struct {
 	SkipReason    string
 	EvaluatedAt   string
 	SourceUpdated string
+	DueAt         string // RFC 3339 UTC due date from the source, or empty
 }
 
 func (s *Store) UpsertIssue(ctx context.Context, in IssueUpsert) (string, error) {
@@ -82,8 +90,8 @@ func (s *Store) UpsertIssue(ctx context.Context, in IssueUpsert) (string, error)
 	const q = `
 INSERT INTO issues(
   autopr_issue_id, project_name, source, source_issue_id, title, body, url, state,
-  labels_json, source_meta_json, eligible, skip_reason, evaluated_at, source_updated_at, synced_at
-) VALUES(?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)
+  labels_json, source_meta_json, eligible, skip_reason, evaluated_at, source_updated_at, synced_at, due_at
+) VALUES(?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)
 ON CONFLICT(project_name, source, source_issue_id) DO UPDATE SET
   title=excluded.title,
   body=excluded.body,
@@ -91,20 +99,30 @@ ON CONFLICT(project_name, source, source_issue_id) DO UPDATE SET
   state=excluded.state,
   labels_json=excluded.labels_json,
   source_meta_json=excluded.source_meta_json,
-  eligible=excluded.eligible,
-  skip_reason=excluded.skip_reason,
+  eligible=CASE WHEN EXISTS(SELECT 1 FROM issue_attempts a WHERE a.autopr_issue_id = issues.autopr_issue_id AND a.hold_reason != '')
+               THEN 0 ELSE excluded.eligible END,
+  skip_reason=COALESCE((SELECT a.hold_reason FROM issue_attempts a WHERE a.autopr_issue_id = issues.autopr_issue_id AND a.hold_reason != ''),
+                       excluded.skip_reason),
   evaluated_at=excluded.evaluated_at,
   source_updated_at=excluded.source_updated_at,
-  synced_at=excluded.synced_at
+  synced_at=excluded.synced_at,
+  due_at=excluded.due_at
 RETURNING autopr_issue_id`
 	var actualID string
 	err := s.Writer.QueryRowContext(ctx, q,
 		newID, in.ProjectName, in.Source, in.SourceIssueID, in.Title, in.Body, in.URL, in.State,
-		labelsJSON, metaJSON, boolToInt(eligible), skipReason, evaluatedAt, in.SourceUpdated, now,
+		labelsJSON, metaJSON, boolToInt(eligible), skipReason, evaluatedAt, in.SourceUpdated, now, in.DueAt,
 	).Scan(&actualID)
 	if err != nil {
 		return "", fmt.Errorf("upsert issue %s/%s/%s: %w", in.ProjectName, in.Source, in.SourceIssueID, err)
 	}
+	// A held issue stays ineligible; remember what the sync decided so it is
+	// restored when the hold ends.
+	if _, err := s.Writer.ExecContext(ctx, `
+UPDATE issue_attempts SET prev_eligible = ?, prev_skip_reason = ?
+WHERE autopr_issue_id = ? AND hold_reason != ''`, boolToInt(eligible), skipReason, actualID); err != nil {
+		return "", fmt.Errorf("upsert issue %s/%s/%s: %w", in.ProjectName, in.Source, in.SourceIssueID, err)
+	}
 	return actualID, nil
 }
 
@@ -170,6 +188,51 @@ WHERE 1=1`
 	return out, rows.Err()
 }
 
+// ListPlannedIssues returns the open issues that have no job other than ones
+// whose PR was merged or closed, eligible issues first, oldest update first
+// within each group. An empty project lists every project.
+func (s *Store) ListPlannedIssues(ctx context.Context, project string) ([]Issue, error) {
+	q := `
+SELECT i.autopr_issue_id, i.project_name, i.source, i.source_issue_id, i.title, i.body, i.url, i.state,
+       i.labels_json, i.source_meta_json, i.eligible, i.skip_reason, i.evaluated_at, i.source_updated_at, i.synced_at
+FROM issues i
+WHERE i.state = 'open'
+  AND NOT EXISTS (
+    SELECT 1 FROM jobs j WHERE j.autopr_issue_id = i.autopr_issue_id AND (
+      j.state != 'approved'
+      OR (j.state = 'approved' AND (j.pr_merged_at IS NULL OR j.pr_merged_at = '') AND (j.pr_closed_at IS NULL OR j.pr_closed_at = ''))
+    )
+  )`
+	var args []any
+	if project != "" {
+		q += ` AND i.project_name = ?`
+		args = append(args, project)
+	}
+	q += ` ORDER BY i.eligible DESC, i.source_updated_at ASC, i.autopr_issue_id ASC`
+
+	rows, err := s.Reader.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list planned issues: %w", err)
+	}
+	defer rows.Close()
+
+	var out []Issue
+	for rows.Next() {
+		var it Issue
+		var eligibleInt int
+		if err := rows.Scan(
+			&it.AutoPRIssueID, &it.ProjectName, &it.Source, &it.SourceIssueID,
+			&it.Title, &it.Body, &it.URL, &it.State,
+			&it.LabelsJSON, &it.SourceMetaJSON, &eligibleInt, &it.SkipReason, &it.EvaluatedAt, &it.SourceUpdated, &it.SyncedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scan issue: %w", err)
+		}
+		it.Eligible = eligibleInt == 1
+		out = append(out, it)
+	}
+	return out, rows.Err()
+}
+
 func (s *Store) GetIssueSyncSummary(ctx context.Context, project string) (IssueSyncSummary, error) {
 	q := `
 SELECT
@@ -5,10 +5,12 @@ import (
 	"crypto/rand"
 	"database/sql"
 	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"slices"
 	"strings"
+	"time"
 )
 
 // ErrDuplicateActiveJob is returned when attempting to create a job for an issue
@@ -29,33 +31,46 @@ var ValidTransitions = func() map[string][]string {
 	// queued: accepted by the system and waiting to be claimed; can enter planning or be cancelled.
 	registerTransition(transitions, "queued", "planning", "cancelled")
 	// planning: issue has an execution plan; can begin implementing, or terminally fail/cancel.
-	registerTransition(transitions, "planning", "implementing", "failed", "cancelled")
+	// Backport and revert jobs skip planning and go straight to rebasing (cherry-pick or revert);
+	// bisect jobs go straight to testing.
+	registerTransition(transitions, "planning", "implementing", "rebasing", "testing", "failed", "cancelled", "waiting_network")
 
 	// implementation phase
 	// implementing: code is being written; can be reviewed, or move to terminal failed/cancelled states.
-	registerTransition(transitions, "implementing", "reviewing", "failed", "cancelled")
+	registerTransition(transitions, "implementing", "reviewing", "needs_review_oversize", "failed", "cancelled", "waiting_network")
 
 	// review phase
 	// reviewing: code review is active; can request more implementation, pass to testing, or fail/cancel.
-	registerTransition(transitions, "reviewing", "implementing", "testing", "failed", "cancelled")
+	registerTransition(transitions, "reviewing", "implementing", "testing", "failed", "cancelled", "waiting_network")
 
 	// testing phase
 	// testing: automated checks are running; can pass to rebasing (if rebase enabled), ready, request implementing fixes, or fail/cancel.
-	registerTransition(transitions, "testing", "ready", "implementing", "rebasing", "failed", "cancelled")
+	// Bisect jobs have no diff to review and finish as approved once the culprit is recorded.
+	registerTransition(transitions, "testing", "ready", "implementing", "rebasing", "needs_review_oversize", "approved", "failed", "cancelled", "waiting_network")
 
 	// rebase phase
 	// rebasing: branch is being rebased onto latest base. Clean rebase → ready, conflicts → resolving_conflicts, failure → failed.
-	registerTransition(transitions, "rebasing", "resolving_conflicts", "ready", "failed", "cancelled")
+	registerTransition(transitions, "rebasing", "resolving_conflicts", "ready", "failed", "cancelled", "waiting_network")
 	// resolving_conflicts: LLM-assisted conflict resolution. Success → ready, failure → failed.
-	registerTransition(transitions, "resolving_conflicts", "ready", "failed", "cancelled")
+	registerTransition(transitions, "resolving_conflicts", "ready", "failed", "cancelled", "waiting_network")
 
 	// completion phase
 	// ready: implementation appears complete and awaits approval decision.
-	registerTransition(transitions, "ready", "awaiting_checks", "approved", "rejected")
+	registerTransition(transitions, "ready", "awaiting_checks", "approved", "rejected", "waiting_network")
+	// needs_review_oversize: the diff exceeds the project's size limits. A human allows it
+	// (→ ready), asks for a smaller change (rejected, then retried), or rejects it.
+	registerTransition(transitions, "needs_review_oversize", "ready", "rejected")
 	// awaiting_checks: PR created, waiting for CI check-runs to pass.
 	registerTransition(transitions, "awaiting_checks", "approved", "rejected", "cancelled")
+	// waiting_network: a step or PR creation failed while the forge was unreachable. The
+	// outbox requeues the job (resuming from its last completed step) or creates the PR once
+	// connectivity returns; a PR that then fails for other reasons returns the job to ready.
+	registerTransition(transitions, "waiting_network", "queued", "ready", "awaiting_checks", "approved", "cancelled")
 	// failed: implementation failed and can be retried by returning to queue.
 	registerTransition(transitions, "failed", "queued")
+	// approved: the PR is open or done. A /autopr comment on an open PR queues another
+	// iteration on the same branch and PR; see ReopenJobForFeedback.
+	registerTransition(transitions, "approved", "queued")
 	// rejected: review outcome was not accepted; can be retried by returning to queue.
 	registerTransition(transitions, "rejected", "queued")
 	// cancelled: job execution was manually stopped; can be retried by returning to queue.
@@ -67,7 +82,7 @@ var ValidTransitions = func() map[string][]string {
 // IsCancellableState reports whether a job can be cancelled.
 func IsCancellableState(state string) bool {
 	switch state {
-	case "queued", "planning", "implementing", "reviewing", "testing", "rebasing", "resolving_conflicts", "awaiting_checks":
+	case "queued", "planning", "implementing", "reviewing", "testing", "rebasing", "resolving_conflicts", "awaiting_checks", "waiting_network":
 		return true
 	default:
 		return false
@@ -113,6 +128,10 @@ func DisplayState(state, prMergedAt, prClosedAt string) string {
 		return "resolving"
 	case "awaiting_checks":
 		return "checking ci"
+	case "waiting_network":
+		return "waiting on network"
+	case "needs_review_oversize":
+		return "oversize"
 	case "approved":
 		return "pr created"
 	default:
@@ -126,12 +145,16 @@ func DisplayStep(step string) string {
 	switch step {
 	case "plan":
 		return "planning"
+	case "summarize":
+		return "summarizing"
 	case "plan_review":
 		return "reviewing plan"
 	case "implement":
 		return "implementing"
 	case "code_review":
 		return "reviewing"
+	case "review_chunk":
+		return "reviewing chunk"
 	case "tests":
 		return "testing"
 	case "rebase":
@@ -174,12 +197,76 @@ type Job struct {
 	CIStartedAt     string
 	CICompletedAt   string
 	CIStatusSummary string
+	CIChecks        CIChecks
+	ParentJobID     string // set for follow-up, backport, and revert jobs created from an earlier job
+	BackportBranch  string // release branch a backport job cherry-picks onto
+	BaseBranch      string // branch set with `ap retarget`, used instead of the project's base branch; "" otherwise
+	SignatureStatus string // result of checking the job's commit signatures before push; "" when the project does not sign commits
+	EpicIndex       int    // 1-based position of an epic task job in its issue's task list; 0 otherwise
+	EpicTask        string // task list item an epic task job works on; "" otherwise
+	BackportCommit  string // merged commit a backport job cherry-picks
+	RevertCommit    string // merged commit a revert job reverts
+	BisectGood      string // known-good commit a bisect job starts from
+	BisectBad       string // known-bad commit a bisect job starts from
+	BisectCmd       string // test command run at each bisect step
+	BisectFix       bool   // queue a fix job once the culprit is found
+	OversizeSummary string // diff size and limits of a job paused in needs_review_oversize
+	Deadline        string // RFC 3339 UTC time the job is due: set with `ap deadline`, else the issue's due date
+	Summary         string // one-line LLM summary of the job; see Title
+	RiskScore       int    // 0-100 review risk of the final diff; see RiskLevel
+	RiskSummary     string // factors behind RiskScore; "" until the job has been scored
+	Priority        int    // claim order among queued jobs, higher first; set by a project's policy script
+	CPUTimeMS       int64  // CPU time of the job's commands run by the local executor
+	PeakRSSBytes    int64  // peak resident memory of any one of those commands
+	DeletedAt       string // when `ap delete` moved the job to the trash; "" otherwise (populated by GetJob and ListJobs)
 
 	// Joined from issues table (populated by ListJobs).
 	IssueSource   string
 	SourceIssueID string
 	IssueTitle    string
 	IssueURL      string
+
+	// Tags set with `ap tag`, sorted (populated by GetJob and ListJobs).
+	Tags []string
+}
+
+// Title is the job's one-line summary, or, when the job has not been
+// summarized, its epic task or issue title.
+func (j Job) Title() string {
+	if j.Summary != "" {
+		return j.Summary
+	}
+	if j.EpicTask != "" {
+		return j.EpicTask
+	}
+	return j.IssueTitle
+}
+
+// Risk levels of a scored job, from its RiskScore.
+const (
+	RiskLow    = "low"
+	RiskMedium = "medium"
+	RiskHigh   = "high"
+)
+
+// RiskLevel buckets a risk score into RiskLow, RiskMedium, or RiskHigh.
+func RiskLevel(score int) string {
+	switch {
+	case score >= 60:
+		return RiskHigh
+	case score >= 30:
+		return RiskMedium
+	default:
+		return RiskLow
+	}
+}
+
+// RiskLevel is the job's risk level, or "" when it has not been scored.
+func (j Job) RiskLevel() string {
+	if j.RiskSummary == "" {
+		return ""
+	}
+	return RiskLevel(j.RiskScore)
 }
 
 func (s *Store) CreateJob(ctx context.Context, autoprIssueID, projectName string, maxIterations int) (string, error) {
@@ -195,26 +282,193 @@ func (s *Store) CreateJob(ctx context.Context, autoprIssueID, projectName string
 	return id, nil
 }
 
-// ClaimJob atomically claims the next queued job. Returns empty string if none available.
-func (s *Store) ClaimJob(ctx context.Context) (string, error) {
-	const q = `
-UPDATE jobs SET state = 'planning', started_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now'),
-               updated_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now')
-WHERE id = (
-	SELECT j.id
+// CreateFollowUpJob queues a new job for the parent's issue, linked to the
+// parent and seeded with notes for the plan step.
+func (s *Store) CreateFollowUpJob(ctx context.Context, parent Job, notes string) (string, error) {
+	id := newJobID()
+	const q = `INSERT INTO jobs(id, autopr_issue_id, project_name, state, max_iterations, human_notes, parent_job_id) VALUES(?,?,?,'queued',?,?,?)`
+	_, err := s.Writer.ExecContext(ctx, q, id, parent.AutoPRIssueID, parent.ProjectName, parent.MaxIterations, notes, parent.ID)
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE constraint failed") {
+			return "", ErrDuplicateActiveJob
+		}
+		return "", fmt.Errorf("create follow-up job: %w", err)
+	}
+	return id, nil
+}
+
+// CreateBackportJob queues a job that cherry-picks commit from the merged
+// parent job onto the release branch. It shares the parent's issue.
+func (s *Store) CreateBackportJob(ctx context.Context, parent Job, branch, commit string) (string, error) {
+	id := newJobID()
+	const q = `INSERT INTO jobs(id, autopr_issue_id, project_name, state, max_iterations, parent_job_id, backport_branch, backport_commit) VALUES(?,?,?,'queued',?,?,?,?)`
+	_, err := s.Writer.ExecContext(ctx, q, id, parent.AutoPRIssueID, parent.ProjectName, parent.MaxIterations, parent.ID, branch, commit)
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE constraint failed") {
+			return "", ErrDuplicateActiveJob
+		}
+		return "", fmt.Errorf("create backport job: %w", err)
+	}
+	return id, nil
+}
+
+// CreateRevertJob queues a job that reverts commit, the merged change of the
+// parent job, on a fresh branch. It shares the parent's issue.
+func (s *Store) CreateRevertJob(ctx context.Context, parent Job, commit string) (string, error) {
+	id := newJobID()
+	const q = `INSERT INTO jobs(id, autopr_issue_id, project_name, state, max_iterations, parent_job_id, revert_commit) VALUES(?,?,?,'queued',?,?,?)`
+	_, err := s.Writer.ExecContext(ctx, q, id, parent.AutoPRIssueID, parent.ProjectName, parent.MaxIterations, parent.ID, commit)
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE constraint failed") {
+			return "", ErrDuplicateActiveJob
+		}
+		return "", fmt.Errorf("create revert job: %w", err)
+	}
+	return id, nil
+}
+
+// CreateBisectJob queues a job that bisects good..bad on the issue's project,
+// running cmd at each step. When fix is set, a fix job is queued for the same
+// issue once the culprit is found.
+func (s *Store) CreateBisectJob(ctx context.Context, autoprIssueID, projectName string, maxIterations int, good, bad, cmd string, fix bool) (string, error) {
+	id := newJobID()
+	const q = `INSERT INTO jobs(id, autopr_issue_id, project_name, state, max_iterations, bisect_good, bisect_bad, bisect_cmd, bisect_fix) VALUES(?,?,?,'queued',?,?,?,?,?)`
+	_, err := s.Writer.ExecContext(ctx, q, id, autoprIssueID, projectName, maxIterations, good, bad, cmd, fix)
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE constraint failed") {
+			return "", ErrDuplicateActiveJob
+		}
+		return "", fmt.Errorf("create bisect job: %w", err)
+	}
+	return id, nil
+}
+
+// HasBackportJob reports whether a backport of parentJobID onto branch was
+// already created, in any state.
+func (s *Store) HasBackportJob(ctx context.Context, parentJobID, branch string) (bool, error) {
+	var n int
+	err := s.Reader.QueryRowContext(ctx, `SELECT COUNT(*) FROM jobs WHERE parent_job_id = ? AND backport_branch = ?`, parentJobID, branch).Scan(&n)
+	if err != nil {
+		return false, fmt.Errorf("check backport job: %w", err)
+	}
+	return n > 0, nil
+}
+
+// BranchNameInUse reports whether a job of the project, in any state, already
+// has the branch name.
+func (s *Store) BranchNameInUse(ctx context.Context, projectName, branch string) (bool, error) {
+	var n int
+	err := s.Reader.QueryRowContext(ctx, `SELECT COUNT(*) FROM jobs WHERE project_name = ? AND branch_name = ?`, projectName, branch).Scan(&n)
+	if err != nil {
+		return false, fmt.Errorf("check branch name: %w", err)
+	}
+	return n > 0, nil
+}
+
+// ClaimJob claims the next queued job like ClaimJobWeighted, with every
+// project weighted equally.
+func (s *Store) ClaimJob(ctx context.Context, skipProjects ...string) (string, error) {
+	return s.ClaimJobWeighted(ctx, nil, skipProjects...)
+}
+
+// ClaimJobWeighted atomically claims the next queued job, skipping jobs of
+// skipProjects and jobs waiting for a dependency to merge. Overdue jobs are
+// claimed before the rest. Otherwise projects with queued jobs take turns,
+// each getting a share of claims in proportion to its weight (1 when missing
+// from weights); see pickClaim. Within a project, jobs are claimed by
+// priority and then in queue order. Returns empty string if none available.
+func (s *Store) ClaimJobWeighted(ctx context.Context, weights map[string]int, skipProjects ...string) (string, error) {
+	skip := ""
+	args := make([]any, 0, len(skipProjects)+2)
+	args = append(args, nowRFC3339())
+	if len(skipProjects) > 0 {
+		skip = " AND j.project_name NOT IN (" + strings.Repeat("?,", len(skipProjects)-1) + "?)"
+		for _, p := range skipProjects {
+			args = append(args, p)
+		}
+	}
+	// The next job of each project with a claimable one.
+	q := `
+SELECT x.id, x.project_name, x.late, x.created_at, COALESCE(c.pass, 0)
+FROM (
+	SELECT j.id, j.project_name, j.created_at,
+	       CASE WHEN NULLIF(` + jobDeadlineColumn + `,'') <= ?1 THEN 0 ELSE 1 END AS late,
+	       ROW_NUMBER() OVER (PARTITION BY j.project_name
+	                          ORDER BY CASE WHEN NULLIF(` + jobDeadlineColumn + `,'') <= ?1 THEN 0 ELSE 1 END,
+	                                   j.priority DESC, j.created_at ASC, j.rowid) AS rn
 	FROM jobs j
 	JOIN issues i ON i.autopr_issue_id = j.autopr_issue_id
-	WHERE j.state = 'queued' AND i.eligible = 1
-	ORDER BY j.created_at ASC
-	LIMIT 1
-)
-RETURNING id`
+	WHERE j.state = 'queued' AND (i.eligible = 1 OR j.parent_job_id IS NOT NULL)` + skip + `
+	  AND NOT EXISTS (SELECT 1 FROM job_dependencies d JOIN jobs dep ON dep.id = d.depends_on
+	                  WHERE d.job_id = j.id AND NOT (` + dependencyMergedSQL + `))
+) x
+LEFT JOIN project_claims c ON c.project_name = x.project_name
+WHERE x.rn = 1`
 	var id string
-	err := s.Writer.QueryRowContext(ctx, q).Scan(&id)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			return "", nil
+	err := s.retryBusy(ctx, "claim job", func() error {
+		id = ""
+		tx, err := s.Writer.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+		rows, err := tx.QueryContext(ctx, q, args...)
+		if err != nil {
+			return err
+		}
+		var heads []claimCandidate
+		for rows.Next() {
+			var c claimCandidate
+			var late int
+			if err := rows.Scan(&c.JobID, &c.Project, &late, &c.CreatedAt, &c.Pass); err != nil {
+				rows.Close()
+				return err
+			}
+			c.Overdue = late == 0
+			heads = append(heads, c)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		var vtime float64
+		if err := tx.QueryRowContext(ctx, `SELECT vtime FROM claim_clock WHERE id = 1`).Scan(&vtime); err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+		next, pass, vtime, ok := pickClaim(heads, weights, vtime)
+		if !ok {
+			return nil
+		}
+		res, err := tx.ExecContext(ctx, `
+UPDATE jobs SET state = 'planning', started_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now'),
+               updated_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now'), queue_stale_at = ''
+WHERE id = ? AND state = 'queued'`, next.JobID)
+		if err != nil {
+			return err
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			return nil
+		}
+		if _, err := tx.ExecContext(ctx, `
+INSERT INTO project_claims(project_name, pass) VALUES(?, ?)
+ON CONFLICT(project_name) DO UPDATE SET pass = excluded.pass`, next.Project, pass); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, `
+INSERT INTO claim_clock(id, vtime) VALUES(1, ?)
+ON CONFLICT(id) DO UPDATE SET vtime = excluded.vtime`, vtime); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, countIssueJobSQL, next.JobID); err != nil {
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return err
 		}
+		id = next.JobID
+		return nil
+	})
+	if err != nil {
 		return "", fmt.Errorf("claim job: %w", err)
 	}
 	return id, nil
@@ -222,13 +476,19 @@ RETURNING id`
 
 // TransitionState validates and performs a state transition on a job.
 func (s *Store) TransitionState(ctx context.Context, jobID, from, to string) error {
+	return s.retryBusy(ctx, "transition job", func() error {
+		return s.transitionState(ctx, jobID, from, to)
+	})
+}
+
+func (s *Store) transitionState(ctx context.Context, jobID, from, to string) error {
 	allowed := ValidTransitions[from]
 	valid := slices.Contains(allowed, to)
 	if !valid {
 		return fmt.Errorf("invalid transition: %s -> %s", from, to)
 	}
 	updates := make([]string, 0, 4)
-	if to == "approved" || to == "rejected" || to == "ready" || to == "failed" || to == "cancelled" {
+	if to == "approved" || to == "rejected" || to == "ready" || to == "needs_review_oversize" || to == "failed" || to == "cancelled" {
 		updates = append(updates, "completed_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now')")
 	}
 	if from == "ready" && to == "awaiting_checks" {
@@ -237,6 +497,7 @@ func (s *Store) TransitionState(ctx context.Context, jobID, from, to string) err
 			"ci_started_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now')",
 			"ci_completed_at = NULL",
 			"ci_status_summary = ''",
+			"ci_stale_at = ''",
 		)
 	}
 	if from == "awaiting_checks" && (to == "approved" || to == "rejected" || to == "cancelled") {
@@ -262,10 +523,16 @@ func (s *Store) TransitionState(ctx context.Context, jobID, from, to string) err
 		return fmt.Errorf("job %s not in state %s (concurrent modification?)", jobID, from)
 	}
 
+	if to == "failed" {
+		if _, err := tx.ExecContext(ctx, countIssueFailureSQL, jobID); err != nil {
+			return fmt.Errorf("transition job %s %s->%s: count failure: %w", jobID, from, to, err)
+		}
+	}
+
 	var eventType string
 	switch to {
 	case "ready":
-		if from == "rebasing" || from == "resolving_conflicts" {
+		if from == "rebasing" || from == "resolving_conflicts" || from == "waiting_network" {
 			break
 		}
 		eventType = NotificationEventNeedsPR
@@ -304,6 +571,12 @@ func (s *Store) TransitionState(ctx context.Context, jobID, from, to string) err
 
 // RejectJob atomically sets reject_reason and transitions a job to rejected.
 func (s *Store) RejectJob(ctx context.Context, jobID, from, reason string) error {
+	return s.retryBusy(ctx, "reject job", func() error {
+		return s.rejectJob(ctx, jobID, from, reason)
+	})
+}
+
+func (s *Store) rejectJob(ctx context.Context, jobID, from, reason string) error {
 	allowed := ValidTransitions[from]
 	if !slices.Contains(allowed, "rejected") {
 		return fmt.Errorf("invalid transition: %s -> rejected", from)
@@ -344,6 +617,12 @@ UPDATE jobs SET state = 'rejected', reject_reason = ?,
 // EnsureJobApproved transitions a ready job to approved. If the job is already
 // approved (or in another state due to concurrent updates), this is a no-op.
 func (s *Store) EnsureJobApproved(ctx context.Context, jobID string) error {
+	return s.retryBusy(ctx, "ensure job approved", func() error {
+		return s.ensureJobApproved(ctx, jobID)
+	})
+}
+
+func (s *Store) ensureJobApproved(ctx context.Context, jobID string) error {
 	tx, err := s.Writer.BeginTx(ctx, nil)
 	if err != nil {
 		return fmt.Errorf("ensure job approved %s: %w", jobID, err)
@@ -373,22 +652,29 @@ RETURNING COALESCE(pr_url, '')`, jobID).Scan(&prURL)
 }
 
 func (s *Store) GetJob(ctx context.Context, jobID string) (Job, error) {
-	const q = `
+	q := `
 	SELECT id, autopr_issue_id, project_name, state, iteration, max_iterations,
 	       COALESCE(worktree_path,''), COALESCE(branch_name,''), COALESCE(commit_sha,''),
 	       COALESCE(human_notes,''), COALESCE(error_message,''), COALESCE(pr_url,''),
 	       COALESCE(reject_reason,''), COALESCE(pr_merged_at,''), COALESCE(pr_closed_at,''),
 	       created_at, updated_at, COALESCE(started_at,''), COALESCE(completed_at,''),
-	       COALESCE(ci_started_at,''), COALESCE(ci_completed_at,''), COALESCE(ci_status_summary,'')
-	FROM jobs WHERE id = ?`
-	var j Job
+	       COALESCE(ci_started_at,''), COALESCE(ci_completed_at,''), COALESCE(ci_status_summary,''), COALESCE(parent_job_id,''), backport_branch, base_branch, signature_status, epic_index, epic_task, backport_commit, revert_commit, bisect_good, bisect_bad, bisect_cmd, bisect_fix, oversize_summary, summary, risk_score, risk_summary, priority, cpu_ms, peak_rss_bytes, ` + jobDeadlineColumn + `, deleted_at,
+	       ci_checks_total, ci_checks_passed, ci_checks_failed, ci_checks_pending,
+	       ` + jobTagsColumn + `
+	FROM jobs j WHERE id = ?`
+	var (
+		j    Job
+		tags string
+	)
 	err := s.Reader.QueryRowContext(ctx, q, jobID).Scan(
 		&j.ID, &j.AutoPRIssueID, &j.ProjectName, &j.State, &j.Iteration, &j.MaxIterations,
 		&j.WorktreePath, &j.BranchName, &j.CommitSHA,
 		&j.HumanNotes, &j.ErrorMessage, &j.PRURL,
 		&j.RejectReason, &j.PRMergedAt, &j.PRClosedAt,
 		&j.CreatedAt, &j.UpdatedAt, &j.StartedAt, &j.CompletedAt,
-		&j.CIStartedAt, &j.CICompletedAt, &j.CIStatusSummary,
+		&j.CIStartedAt, &j.CICompletedAt, &j.CIStatusSummary, &j.ParentJobID, &j.BackportBranch, &j.BaseBranch, &j.SignatureStatus, &j.EpicIndex, &j.EpicTask, &j.BackportCommit, &j.RevertCommit, &j.BisectGood, &j.BisectBad, &j.BisectCmd, &j.BisectFix, &j.OversizeSummary, &j.Summary, &j.RiskScore, &j.RiskSummary, &j.Priority, &j.CPUTimeMS, &j.PeakRSSBytes, &j.Deadline, &j.DeletedAt,
+		&j.CIChecks.Total, &j.CIChecks.Passed, &j.CIChecks.Failed, &j.CIChecks.Pending,
+		&tags,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -396,19 +682,32 @@ func (s *Store) GetJob(ctx context.Context, jobID string) (Job, error) {
 		}
 		return Job{}, fmt.Errorf("get job %s: %w", jobID, err)
 	}
+	j.Tags = splitTags(tags)
 	return j, nil
 }
 
-func buildJobsFilterClause(project, state string) (string, []any) {
-	activeStates := []string{"planning", "implementing", "reviewing", "testing", "rebasing", "resolving_conflicts", "awaiting_checks"}
+func buildJobsFilterClause(project, state, tag string) (string, []any) {
+	activeStates := []string{"planning", "implementing", "reviewing", "testing", "rebasing", "resolving_conflicts", "awaiting_checks", "waiting_network"}
 	clause := []string{"1=1"}
 	args := make([]any, 0, 3)
 
+	// Jobs in the trash are listed only when asked for.
+	if state == "deleted" {
+		clause = append(clause, "j.deleted_at != ''")
+	} else {
+		clause = append(clause, "j.deleted_at = ''")
+	}
+
 	if project != "" {
 		clause = append(clause, "j.project_name = ?")
 		args = append(args, project)
 	}
 
+	if tag != "" {
+		clause = append(clause, "EXISTS(SELECT 1 FROM job_tags t WHERE t.job_id = j.id AND t.tag = ?)")
+		args = append(args, tag)
+	}
+
 	if state != "" && state != "all" {
 		switch state {
 		case "active":
@@ -420,6 +719,7 @@ func buildJobsFilterClause(project, state string) (string, []any) {
 		case "merged":
 			clause = append(clause, "j.state = ? AND COALESCE(j.pr_merged_at,'') != ''")
 			args = append(args, "approved")
+		case "deleted":
 		default:
 			clause = append(clause, "j.state = ?")
 			args = append(args, state)
@@ -430,7 +730,13 @@ func buildJobsFilterClause(project, state string) (string, []any) {
 }
 
 func (s *Store) ListJobs(ctx context.Context, project, state, orderBy string, ascending bool) ([]Job, error) {
-	whereClause, args := buildJobsFilterClause(project, state)
+	return s.ListJobsTagged(ctx, project, state, "", orderBy, ascending)
+}
+
+// ListJobsTagged is ListJobs limited to jobs carrying tag. An empty tag
+// matches every job.
+func (s *Store) ListJobsTagged(ctx context.Context, project, state, tag, orderBy string, ascending bool) ([]Job, error) {
+	whereClause, args := buildJobsFilterClause(project, state, tag)
 
 	q := `
 	SELECT j.id, j.autopr_issue_id, j.project_name, j.state, j.iteration, j.max_iterations,
@@ -438,8 +744,10 @@ func (s *Store) ListJobs(ctx context.Context, project, state, orderBy string, as
 	       COALESCE(j.human_notes,''), COALESCE(j.error_message,''), COALESCE(j.pr_url,''),
 	       COALESCE(j.reject_reason,''), COALESCE(j.pr_merged_at,''), COALESCE(j.pr_closed_at,''),
 	       j.created_at, j.updated_at, COALESCE(j.started_at,''), COALESCE(j.completed_at,''),
-	       COALESCE(j.ci_started_at,''), COALESCE(j.ci_completed_at,''), COALESCE(j.ci_status_summary,''),
-	       COALESCE(i.source,''), COALESCE(i.source_issue_id,''), COALESCE(i.title,''), COALESCE(i.url,'')
+	       COALESCE(j.ci_started_at,''), COALESCE(j.ci_completed_at,''), COALESCE(j.ci_status_summary,''), COALESCE(j.parent_job_id,''), j.backport_branch, j.base_branch, j.signature_status, j.epic_index, j.epic_task, j.backport_commit, j.revert_commit, j.bisect_good, j.bisect_bad, j.bisect_cmd, j.bisect_fix, j.oversize_summary, j.summary, j.risk_score, j.risk_summary, j.priority, j.cpu_ms, j.peak_rss_bytes, ` + jobDeadlineColumn + `, j.deleted_at,
+	       j.ci_checks_total, j.ci_checks_passed, j.ci_checks_failed, j.ci_checks_pending,
+	       COALESCE(i.source,''), COALESCE(i.source_issue_id,''), COALESCE(i.title,''), COALESCE(i.url,''),
+	       ` + jobTagsColumn + `
 FROM jobs j
 LEFT JOIN issues i ON j.autopr_issue_id = i.autopr_issue_id ` + whereClause
 	orderExpr := resolveJobOrderExpression(orderBy)
@@ -457,18 +765,24 @@ LEFT JOIN issues i ON j.autopr_issue_id = i.autopr_issue_id ` + whereClause
 
 	var out []Job
 	for rows.Next() {
-		var j Job
+		var (
+			j    Job
+			tags string
+		)
 		if err := rows.Scan(
 			&j.ID, &j.AutoPRIssueID, &j.ProjectName, &j.State, &j.Iteration, &j.MaxIterations,
 			&j.WorktreePath, &j.BranchName, &j.CommitSHA,
 			&j.HumanNotes, &j.ErrorMessage, &j.PRURL,
 			&j.RejectReason, &j.PRMergedAt, &j.PRClosedAt,
 			&j.CreatedAt, &j.UpdatedAt, &j.StartedAt, &j.CompletedAt,
-			&j.CIStartedAt, &j.CICompletedAt, &j.CIStatusSummary,
+			&j.CIStartedAt, &j.CICompletedAt, &j.CIStatusSummary, &j.ParentJobID, &j.BackportBranch, &j.BaseBranch, &j.SignatureStatus, &j.EpicIndex, &j.EpicTask, &j.BackportCommit, &j.RevertCommit, &j.BisectGood, &j.BisectBad, &j.BisectCmd, &j.BisectFix, &j.OversizeSummary, &j.Summary, &j.RiskScore, &j.RiskSummary, &j.Priority, &j.CPUTimeMS, &j.PeakRSSBytes, &j.Deadline, &j.DeletedAt,
+			&j.CIChecks.Total, &j.CIChecks.Passed, &j.CIChecks.Failed, &j.CIChecks.Pending,
 			&j.IssueSource, &j.SourceIssueID, &j.IssueTitle, &j.IssueURL,
+			&tags,
 		); err != nil {
 			return nil, fmt.Errorf("scan job: %w", err)
 		}
+		j.Tags = splitTags(tags)
 		out = append(out, j)
 	}
 	return out, rows.Err()
@@ -476,11 +790,17 @@ LEFT JOIN issues i ON j.autopr_issue_id = i.autopr_issue_id ` + whereClause
 
 // ListJobsPage returns a single paged result set and the total row count for matching jobs.
 func (s *Store) ListJobsPage(ctx context.Context, project, state, orderBy string, ascending bool, page, pageSize int) ([]Job, int, error) {
+	return s.ListJobsPageTagged(ctx, project, state, "", orderBy, ascending, page, pageSize)
+}
+
+// ListJobsPageTagged is ListJobsPage limited to jobs carrying tag. An empty
+// tag matches every job.
+func (s *Store) ListJobsPageTagged(ctx context.Context, project, state, tag, orderBy string, ascending bool, page, pageSize int) ([]Job, int, error) {
 	if page < 1 || pageSize < 1 {
 		return nil, 0, fmt.Errorf("invalid pagination: page and pageSize must be >= 1")
 	}
 
-	whereClause, args := buildJobsFilterClause(project, state)
+	whereClause, args := buildJobsFilterClause(project, state, tag)
 
 	countQuery := `SELECT COUNT(*) FROM jobs j ` + whereClause
 	var total int64
@@ -501,8 +821,10 @@ func (s *Store) ListJobsPage(ctx context.Context, project, state, orderBy string
 	       COALESCE(j.human_notes,''), COALESCE(j.error_message,''), COALESCE(j.pr_url,''),
 	       COALESCE(j.reject_reason,''), COALESCE(j.pr_merged_at,''), COALESCE(j.pr_closed_at,''),
 	       j.created_at, j.updated_at, COALESCE(j.started_at,''), COALESCE(j.completed_at,''),
-	       COALESCE(j.ci_started_at,''), COALESCE(j.ci_completed_at,''), COALESCE(j.ci_status_summary,''),
-	       COALESCE(i.source,''), COALESCE(i.source_issue_id,''), COALESCE(i.title,''), COALESCE(i.url,'')
+	       COALESCE(j.ci_started_at,''), COALESCE(j.ci_completed_at,''), COALESCE(j.ci_status_summary,''), COALESCE(j.parent_job_id,''), j.backport_branch, j.base_branch, j.signature_status, j.epic_index, j.epic_task, j.backport_commit, j.revert_commit, j.bisect_good, j.bisect_bad, j.bisect_cmd, j.bisect_fix, j.oversize_summary, j.summary, j.risk_score, j.risk_summary, j.priority, j.cpu_ms, j.peak_rss_bytes, ` + jobDeadlineColumn + `, j.deleted_at,
+	       j.ci_checks_total, j.ci_checks_passed, j.ci_checks_failed, j.ci_checks_pending,
+	       COALESCE(i.source,''), COALESCE(i.source_issue_id,''), COALESCE(i.title,''), COALESCE(i.url,''),
+	       ` + jobTagsColumn + `
 FROM jobs j
 LEFT JOIN issues i ON j.autopr_issue_id = i.autopr_issue_id ` + whereClause + " ORDER BY " + orderExpr + " " + direction + ", j.id LIMIT ? OFFSET ?"
 	args = append(args, pageSize, offset)
@@ -515,18 +837,24 @@ LEFT JOIN issues i ON j.autopr_issue_id = i.autopr_issue_id ` + whereClause + "
 
 	var out []Job
 	for rows.Next() {
-		var j Job
+		var (
+			j    Job
+			tags string
+		)
 		if err := rows.Scan(
 			&j.ID, &j.AutoPRIssueID, &j.ProjectName, &j.State, &j.Iteration, &j.MaxIterations,
 			&j.WorktreePath, &j.BranchName, &j.CommitSHA,
 			&j.HumanNotes, &j.ErrorMessage, &j.PRURL,
 			&j.RejectReason, &j.PRMergedAt, &j.PRClosedAt,
 			&j.CreatedAt, &j.UpdatedAt, &j.StartedAt, &j.CompletedAt,
-			&j.CIStartedAt, &j.CICompletedAt, &j.CIStatusSummary,
+			&j.CIStartedAt, &j.CICompletedAt, &j.CIStatusSummary, &j.ParentJobID, &j.BackportBranch, &j.BaseBranch, &j.SignatureStatus, &j.EpicIndex, &j.EpicTask, &j.BackportCommit, &j.RevertCommit, &j.BisectGood, &j.BisectBad, &j.BisectCmd, &j.BisectFix, &j.OversizeSummary, &j.Summary, &j.RiskScore, &j.RiskSummary, &j.Priority, &j.CPUTimeMS, &j.PeakRSSBytes, &j.Deadline, &j.DeletedAt,
+			&j.CIChecks.Total, &j.CIChecks.Passed, &j.CIChecks.Failed, &j.CIChecks.Pending,
 			&j.IssueSource, &j.SourceIssueID, &j.IssueTitle, &j.IssueURL,
+			&tags,
 		); err != nil {
 			return nil, 0, fmt.Errorf("scan job: %w", err)
 		}
+		j.Tags = splitTags(tags)
 		out = append(out, j)
 	}
 	return out, int(total), rows.Err()
@@ -546,14 +874,15 @@ CASE
     WHEN j.state = 'testing' THEN 5
     WHEN j.state = 'rebasing' THEN 6
     WHEN j.state = 'resolving_conflicts' THEN 7
-    WHEN j.state = 'ready' THEN 8
-    WHEN j.state = 'awaiting_checks' THEN 9
-    WHEN j.state = 'approved' AND COALESCE(j.pr_merged_at, '') = '' THEN 10
-    WHEN j.state = 'merged' OR COALESCE(j.pr_merged_at, '') <> '' THEN 11
-    WHEN j.state = 'rejected' THEN 12
-    WHEN j.state = 'failed' THEN 13
-    WHEN j.state = 'cancelled' THEN 14
-    ELSE 15
+    WHEN j.state = 'waiting_network' THEN 8
+    WHEN j.state = 'ready' THEN 9
+    WHEN j.state = 'awaiting_checks' THEN 10
+    WHEN j.state = 'approved' AND COALESCE(j.pr_merged_at, '') = '' THEN 11
+    WHEN j.state = 'merged' OR COALESCE(j.pr_merged_at, '') <> '' THEN 12
+    WHEN j.state = 'rejected' THEN 13
+    WHEN j.state = 'failed' THEN 14
+    WHEN j.state = 'cancelled' THEN 15
+    ELSE 16
 END`
 	case "created_at":
 		return "j.created_at"
@@ -570,19 +899,113 @@ func (s *Store) UpdateJobField(ctx context.Context, jobID, field, value string)
 		"worktree_path": true, "branch_name": true, "commit_sha": true,
 		"human_notes": true, "error_message": true, "pr_url": true,
 		"reject_reason": true, "pr_merged_at": true, "pr_closed_at": true,
-		"ci_status_summary": true,
+		"ci_status_summary": true, "oversize_summary": true, "summary": true,
+		"base_branch": true, "signature_status": true,
 	}
 	if !allowed[field] {
 		return fmt.Errorf("cannot update field %q", field)
 	}
 	q := fmt.Sprintf(`UPDATE jobs SET %s = ?, updated_at = strftime('%%Y-%%m-%%dT%%H:%%M:%%SZ', 'now') WHERE id = ?`, field)
-	_, err := s.Writer.ExecContext(ctx, q, value, jobID)
+	_, err := s.execBusy(ctx, "update job field", q, value, jobID)
 	if err != nil {
 		return fmt.Errorf("update job %s.%s: %w", jobID, field, err)
 	}
 	return nil
 }
 
+// CIChecks counts a job's CI checks as of the latest poll. All zero until CI
+// has been polled.
+type CIChecks struct {
+	Total   int
+	Passed  int
+	Failed  int
+	Pending int
+}
+
+// Progress summarizes the counts, e.g. "3/7 passed" or "5/7, 1 failing", or
+// "waiting" before any check has reported.
+func (c CIChecks) Progress() string {
+	switch {
+	case c.Total == 0:
+		return "waiting"
+	case c.Failed > 0:
+		return fmt.Sprintf("%d/%d, %d failing", c.Passed, c.Total, c.Failed)
+	default:
+		return fmt.Sprintf("%d/%d passed", c.Passed, c.Total)
+	}
+}
+
+// UpdateJobCIChecks records the check counts of the latest CI poll without
+// touching updated_at.
+func (s *Store) UpdateJobCIChecks(ctx context.Context, jobID string, checks CIChecks) error {
+	_, err := s.Writer.ExecContext(ctx, `
+UPDATE jobs SET ci_checks_total = ?, ci_checks_passed = ?, ci_checks_failed = ?, ci_checks_pending = ?
+WHERE id = ?`, checks.Total, checks.Passed, checks.Failed, checks.Pending, jobID)
+	if err != nil {
+		return fmt.Errorf("update job %s ci checks: %w", jobID, err)
+	}
+	return nil
+}
+
+// SetJobPriority sets the order in which a queued job is claimed relative to
+// other queued jobs; higher is claimed first.
+func (s *Store) SetJobPriority(ctx context.Context, jobID string, priority int) error {
+	_, err := s.execBusy(ctx, "set job priority", `UPDATE jobs SET priority = ? WHERE id = ?`, priority, jobID)
+	if err != nil {
+		return fmt.Errorf("set job %s priority: %w", jobID, err)
+	}
+	return nil
+}
+
+// UpdateJobRisk records the risk score of a job's final diff and the factors
+// behind it.
+func (s *Store) UpdateJobRisk(ctx context.Context, jobID string, score int, summary string) error {
+	_, err := s.execBusy(ctx, "update job risk", `
+UPDATE jobs SET risk_score = ?, risk_summary = ?, updated_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now')
+WHERE id = ?`, score, summary, jobID)
+	if err != nil {
+		return fmt.Errorf("update job %s risk: %w", jobID, err)
+	}
+	return nil
+}
+
+// AddJobResourceUsage adds the CPU time of a finished command of the job to
+// its total, and raises its peak memory to rssBytes when higher.
+func (s *Store) AddJobResourceUsage(ctx context.Context, jobID string, cpu time.Duration, rssBytes int64) error {
+	_, err := s.execBusy(ctx, "add job resource usage", `
+UPDATE jobs SET cpu_ms = cpu_ms + ?, peak_rss_bytes = MAX(peak_rss_bytes, ?) WHERE id = ?`, cpu.Milliseconds(), rssBytes, jobID)
+	if err != nil {
+		return fmt.Errorf("add job %s resource usage: %w", jobID, err)
+	}
+	return nil
+}
+
+// Outcomes of the test step recorded by SetJobTestsResult.
+const (
+	TestsPassed = "passed"
+	TestsFailed = "failed"
+)
+
+// SetJobTestsResult records the outcome of the test step in the job's current
+// iteration, TestsPassed or TestsFailed.
+func (s *Store) SetJobTestsResult(ctx context.Context, jobID, result string) error {
+	_, err := s.execBusy(ctx, "set job tests result", `UPDATE jobs SET tests_result = ? WHERE id = ?`, result, jobID)
+	if err != nil {
+		return fmt.Errorf("set job %s tests result: %w", jobID, err)
+	}
+	return nil
+}
+
+// JobTestsResult returns the outcome of the test step in the job's current
+// iteration, or "" when tests have not run on it.
+func (s *Store) JobTestsResult(ctx context.Context, jobID string) (string, error) {
+	var result string
+	if err := s.Reader.QueryRowContext(ctx, `SELECT tests_result FROM jobs WHERE id = ?`, jobID).Scan(&result); err != nil {
+		return "", fmt.Errorf("get job %s tests result: %w", jobID, err)
+	}
+	return result, nil
+}
+
 // UpdateJobCIStatusSummary updates the latest CI status summary without touching updated_at.
 func (s *Store) UpdateJobCIStatusSummary(ctx context.Context, jobID, summary string) error {
 	_, err := s.Writer.ExecContext(ctx, `UPDATE jobs SET ci_status_summary = ? WHERE id = ?`, summary, jobID)
@@ -592,10 +1015,38 @@ func (s *Store) UpdateJobCIStatusSummary(ctx context.Context, jobID, summary str
 	return nil
 }
 
+// MarkJobCIStale flags an awaiting_checks job whose CI is stuck and enqueues
+// a ci_stuck notification. It reports false when the job has left
+// awaiting_checks or was already flagged.
+func (s *Store) MarkJobCIStale(ctx context.Context, jobID string) (bool, error) {
+	tx, err := s.Writer.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("mark job %s ci stale: %w", jobID, err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `
+UPDATE jobs SET ci_stale_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now')
+WHERE id = ? AND state = 'awaiting_checks' AND ci_stale_at = ''`, jobID)
+	if err != nil {
+		return false, fmt.Errorf("mark job %s ci stale: %w", jobID, err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return false, nil
+	}
+	if err := enqueueNotificationEventTx(ctx, tx, jobID, NotificationEventCIStuck); err != nil {
+		return false, err
+	}
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("mark job %s ci stale: %w", jobID, err)
+	}
+	return true, nil
+}
+
 // IncrementIteration bumps the iteration counter.
 func (s *Store) IncrementIteration(ctx context.Context, jobID string) error {
-	_, err := s.Writer.ExecContext(ctx,
-		`UPDATE jobs SET iteration = iteration + 1, updated_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now') WHERE id = ?`, jobID)
+	_, err := s.execBusy(ctx, "increment iteration",
+		`UPDATE jobs SET iteration = iteration + 1, tests_result = '', updated_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now') WHERE id = ?`, jobID)
 	if err != nil {
 		return fmt.Errorf("increment iteration %s: %w", jobID, err)
 	}
@@ -608,7 +1059,7 @@ func (s *Store) ResetJobForRetry(ctx context.Context, jobID, notes string) error
 	UPDATE jobs SET state = 'queued', iteration = iteration + 1, worktree_path = NULL, branch_name = NULL,
 	               commit_sha = NULL, error_message = NULL, human_notes = ?,
 	               started_at = NULL, completed_at = NULL,
-	               ci_started_at = NULL, ci_completed_at = NULL, ci_status_summary = '',
+	               ci_started_at = NULL, ci_completed_at = NULL, ci_status_summary = '', ci_checks_total = 0, ci_checks_passed = 0, ci_checks_failed = 0, ci_checks_pending = 0, ci_stale_at = '', queue_stale_at = '', oversize_summary = '', risk_score = 0, risk_summary = '', tests_result = '',
 	               updated_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now')
 WHERE id = ? AND state IN ('failed', 'rejected', 'cancelled')
   AND EXISTS (
@@ -675,12 +1126,63 @@ func (s *Store) HasCompletedSessionForStep(ctx context.Context, jobID string, it
 	return count > 0, nil
 }
 
+// ReopenJobForFeedback queues another iteration of an approved job whose PR
+// is still open, with notes (the reviewer's feedback) for the plan step. The
+// worktree, branch, and PR are kept, so the new changes are pushed to the
+// same PR.
+func (s *Store) ReopenJobForFeedback(ctx context.Context, jobID, notes string) error {
+	res, err := s.Writer.ExecContext(ctx, `
+UPDATE jobs SET state = 'queued', iteration = iteration + 1, human_notes = ?, error_message = NULL,
+               started_at = NULL, completed_at = NULL,
+               ci_started_at = NULL, ci_completed_at = NULL, ci_status_summary = '', ci_checks_total = 0, ci_checks_passed = 0, ci_checks_failed = 0, ci_checks_pending = 0, ci_stale_at = '', queue_stale_at = '', oversize_summary = '', risk_score = 0, risk_summary = '', tests_result = '',
+               updated_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now')
+WHERE id = ? AND state = 'approved' AND COALESCE(pr_url, '') != '' AND COALESCE(worktree_path, '') != ''
+  AND (pr_merged_at IS NULL OR pr_merged_at = '')
+  AND (pr_closed_at IS NULL OR pr_closed_at = '')`, notes, jobID)
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE constraint failed") {
+			return ErrDuplicateActiveJob
+		}
+		return fmt.Errorf("reopen job %s: %w", jobID, err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("job %s has no open PR with a worktree to iterate on", ShortID(jobID))
+	}
+	return nil
+}
+
+// PRCommentCursorStart is the PR comment cursor from which every comment on
+// the job's PR is read for commands. A PR opened while pr_commands is on
+// starts there. A cursor of 0 means commands have not been read on the PR
+// yet, such as one opened before pr_commands was turned on.
+const PRCommentCursorStart int64 = -1
+
+// JobPRCommentCursor returns the ID of the newest PR comment already read for
+// commands on the job, PRCommentCursorStart, or 0.
+func (s *Store) JobPRCommentCursor(ctx context.Context, jobID string) (int64, error) {
+	var id int64
+	if err := s.Reader.QueryRowContext(ctx, `SELECT pr_comment_id FROM jobs WHERE id = ?`, jobID).Scan(&id); err != nil {
+		return 0, fmt.Errorf("get job %s PR comment cursor: %w", jobID, err)
+	}
+	return id, nil
+}
+
+// SetJobPRCommentCursor records commentID as the newest PR comment read for
+// commands on the job.
+func (s *Store) SetJobPRCommentCursor(ctx context.Context, jobID string, commentID int64) error {
+	_, err := s.execBusy(ctx, "set job PR comment cursor", `UPDATE jobs SET pr_comment_id = ? WHERE id = ?`, commentID, jobID)
+	if err != nil {
+		return fmt.Errorf("set job %s PR comment cursor: %w", jobID, err)
+	}
+	return nil
+}
+
 // ResetJobForResume resets a failed/cancelled job to queued without incrementing iteration.
 func (s *Store) ResetJobForResume(ctx context.Context, jobID string) error {
 	res, err := s.Writer.ExecContext(ctx, `
 UPDATE jobs SET state = 'queued', error_message = NULL,
                started_at = NULL, completed_at = NULL,
-               ci_started_at = NULL, ci_completed_at = NULL, ci_status_summary = '',
+               ci_started_at = NULL, ci_completed_at = NULL, ci_status_summary = '', ci_checks_total = 0, ci_checks_passed = 0, ci_checks_failed = 0, ci_checks_pending = 0, ci_stale_at = '', queue_stale_at = '', oversize_summary = '', risk_score = 0, risk_summary = '', tests_result = '',
                updated_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now')
 WHERE id = ? AND state IN ('failed', 'cancelled')
   AND EXISTS (
@@ -753,7 +1255,7 @@ func (s *Store) CancelJob(ctx context.Context, jobID string) error {
 	    END,
 	    completed_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now'),
 	    updated_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now')
-WHERE id = ? AND state IN ('queued', 'planning', 'implementing', 'reviewing', 'testing', 'rebasing', 'resolving_conflicts', 'awaiting_checks')`, jobID)
+WHERE id = ? AND state IN ('queued', 'planning', 'implementing', 'reviewing', 'testing', 'rebasing', 'resolving_conflicts', 'awaiting_checks', 'waiting_network')`, jobID)
 	if err != nil {
 		return fmt.Errorf("cancel job %s: %w", jobID, err)
 	}
@@ -784,7 +1286,7 @@ func (s *Store) CancelAllCancellableJobs(ctx context.Context) ([]string, error)
 	    END,
 	    completed_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now'),
 	    updated_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now')
-WHERE state IN ('queued', 'planning', 'implementing', 'reviewing', 'testing', 'rebasing', 'resolving_conflicts', 'awaiting_checks')
+WHERE state IN ('queued', 'planning', 'implementing', 'reviewing', 'testing', 'rebasing', 'resolving_conflicts', 'awaiting_checks', 'waiting_network')
 RETURNING id`)
 	if err != nil {
 		return nil, fmt.Errorf("cancel all jobs: %w", err)
@@ -818,7 +1320,8 @@ func (s *Store) CancelCancellableJobsForIssue(ctx context.Context, autoprIssueID
 	    completed_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now'),
 	    updated_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now')
 WHERE autopr_issue_id = ?
-  AND state IN ('queued', 'planning', 'implementing', 'reviewing', 'testing', 'rebasing', 'resolving_conflicts', 'awaiting_checks')
+  AND state IN ('queued', 'planning', 'implementing', 'reviewing', 'testing', 'rebasing', 'resolving_conflicts', 'awaiting_checks', 'waiting_network')
+  AND parent_job_id IS NULL
 RETURNING id`, reason, autoprIssueID)
 	if err != nil {
 		return nil, fmt.Errorf("cancel jobs for issue %s: %w", autoprIssueID, err)
@@ -900,7 +1403,8 @@ func (s *Store) ListApprovedJobsWithPR(ctx context.Context) ([]Job, error) {
 	       COALESCE(j.human_notes,''), COALESCE(j.error_message,''), COALESCE(j.pr_url,''),
 	       COALESCE(j.reject_reason,''), COALESCE(j.pr_merged_at,''), COALESCE(j.pr_closed_at,''),
 	       j.created_at, j.updated_at, COALESCE(j.started_at,''), COALESCE(j.completed_at,''),
-	       COALESCE(j.ci_started_at,''), COALESCE(j.ci_completed_at,''), COALESCE(j.ci_status_summary,''),
+	       COALESCE(j.ci_started_at,''), COALESCE(j.ci_completed_at,''), COALESCE(j.ci_status_summary,''), COALESCE(j.parent_job_id,''), j.backport_branch, j.base_branch, j.signature_status, j.epic_index, j.epic_task, j.backport_commit, j.revert_commit, j.bisect_good, j.bisect_bad, j.bisect_cmd, j.bisect_fix, j.oversize_summary, j.summary, j.risk_score, j.risk_summary, j.priority, j.cpu_ms, j.peak_rss_bytes, ` + jobDeadlineColumn + `,
+	       j.ci_checks_total, j.ci_checks_passed, j.ci_checks_failed, j.ci_checks_pending,
 	       COALESCE(i.source,''), COALESCE(i.source_issue_id,''), COALESCE(i.title,''), COALESCE(i.url,'')
 FROM jobs j
 LEFT JOIN issues i ON j.autopr_issue_id = i.autopr_issue_id
@@ -923,7 +1427,8 @@ ORDER BY j.updated_at DESC`
 			&j.HumanNotes, &j.ErrorMessage, &j.PRURL,
 			&j.RejectReason, &j.PRMergedAt, &j.PRClosedAt,
 			&j.CreatedAt, &j.UpdatedAt, &j.StartedAt, &j.CompletedAt,
-			&j.CIStartedAt, &j.CICompletedAt, &j.CIStatusSummary,
+			&j.CIStartedAt, &j.CICompletedAt, &j.CIStatusSummary, &j.ParentJobID, &j.BackportBranch, &j.BaseBranch, &j.SignatureStatus, &j.EpicIndex, &j.EpicTask, &j.BackportCommit, &j.RevertCommit, &j.BisectGood, &j.BisectBad, &j.BisectCmd, &j.BisectFix, &j.OversizeSummary, &j.Summary, &j.RiskScore, &j.RiskSummary, &j.Priority, &j.CPUTimeMS, &j.PeakRSSBytes, &j.Deadline,
+			&j.CIChecks.Total, &j.CIChecks.Passed, &j.CIChecks.Failed, &j.CIChecks.Pending,
 			&j.IssueSource, &j.SourceIssueID, &j.IssueTitle, &j.IssueURL,
 		); err != nil {
 			return nil, fmt.Errorf("scan approved job: %w", err)
@@ -935,19 +1440,32 @@ ORDER BY j.updated_at DESC`
 
 // ListAwaitingChecksJobs returns jobs in the awaiting_checks state that have a PR URL.
 func (s *Store) ListAwaitingChecksJobs(ctx context.Context) ([]Job, error) {
-	const q = `
+	return s.listAwaitingChecksJobs(ctx, "")
+}
+
+// ListStaleAwaitingChecksJobs returns awaiting_checks jobs whose CI has been
+// pending for longer than olderThan and that have not been flagged stale yet.
+func (s *Store) ListStaleAwaitingChecksJobs(ctx context.Context, olderThan time.Duration) ([]Job, error) {
+	cutoff := time.Now().UTC().Add(-olderThan).Format(time.RFC3339)
+	return s.listAwaitingChecksJobs(ctx, `
+  AND j.ci_stale_at = '' AND COALESCE(NULLIF(j.ci_started_at,''), j.updated_at) < ?`, cutoff)
+}
+
+func (s *Store) listAwaitingChecksJobs(ctx context.Context, filter string, args ...any) ([]Job, error) {
+	q := `
 	SELECT j.id, j.autopr_issue_id, j.project_name, j.state, j.iteration, j.max_iterations,
 	       COALESCE(j.worktree_path,''), COALESCE(j.branch_name,''), COALESCE(j.commit_sha,''),
 	       COALESCE(j.human_notes,''), COALESCE(j.error_message,''), COALESCE(j.pr_url,''),
 	       COALESCE(j.reject_reason,''), COALESCE(j.pr_merged_at,''), COALESCE(j.pr_closed_at,''),
 	       j.created_at, j.updated_at, COALESCE(j.started_at,''), COALESCE(j.completed_at,''),
-	       COALESCE(j.ci_started_at,''), COALESCE(j.ci_completed_at,''), COALESCE(j.ci_status_summary,''),
+	       COALESCE(j.ci_started_at,''), COALESCE(j.ci_completed_at,''), COALESCE(j.ci_status_summary,''), COALESCE(j.parent_job_id,''), j.backport_branch, j.base_branch, j.signature_status, j.epic_index, j.epic_task, j.backport_commit, j.revert_commit, j.bisect_good, j.bisect_bad, j.bisect_cmd, j.bisect_fix, j.oversize_summary, j.summary, j.risk_score, j.risk_summary, j.priority, j.cpu_ms, j.peak_rss_bytes, ` + jobDeadlineColumn + `,
+	       j.ci_checks_total, j.ci_checks_passed, j.ci_checks_failed, j.ci_checks_pending,
 	       COALESCE(i.source,''), COALESCE(i.source_issue_id,''), COALESCE(i.title,''), COALESCE(i.url,'')
 FROM jobs j
 LEFT JOIN issues i ON j.autopr_issue_id = i.autopr_issue_id
-WHERE j.state = 'awaiting_checks' AND j.pr_url != ''
+WHERE j.state = 'awaiting_checks' AND j.pr_url != ''` + filter + `
 ORDER BY j.updated_at DESC`
-	rows, err := s.Reader.QueryContext(ctx, q)
+	rows, err := s.Reader.QueryContext(ctx, q, args...)
 	if err != nil {
 		return nil, fmt.Errorf("list awaiting_checks jobs: %w", err)
 	}
@@ -962,7 +1480,8 @@ ORDER BY j.updated_at DESC`
 			&j.HumanNotes, &j.ErrorMessage, &j.PRURL,
 			&j.RejectReason, &j.PRMergedAt, &j.PRClosedAt,
 			&j.CreatedAt, &j.UpdatedAt, &j.StartedAt, &j.CompletedAt,
-			&j.CIStartedAt, &j.CICompletedAt, &j.CIStatusSummary,
+			&j.CIStartedAt, &j.CICompletedAt, &j.CIStatusSummary, &j.ParentJobID, &j.BackportBranch, &j.BaseBranch, &j.SignatureStatus, &j.EpicIndex, &j.EpicTask, &j.BackportCommit, &j.RevertCommit, &j.BisectGood, &j.BisectBad, &j.BisectCmd, &j.BisectFix, &j.OversizeSummary, &j.Summary, &j.RiskScore, &j.RiskSummary, &j.Priority, &j.CPUTimeMS, &j.PeakRSSBytes, &j.Deadline,
+			&j.CIChecks.Total, &j.CIChecks.Passed, &j.CIChecks.Failed, &j.CIChecks.Pending,
 			&j.IssueSource, &j.SourceIssueID, &j.IssueTitle, &j.IssueURL,
 		); err != nil {
 			return nil, fmt.Errorf("scan awaiting_checks job: %w", err)
@@ -981,7 +1500,8 @@ func (s *Store) ListReadyOrApprovedJobsWithBranchNoPR(ctx context.Context) ([]Jo
 	       COALESCE(j.human_notes,''), COALESCE(j.error_message,''), COALESCE(j.pr_url,''),
 	       COALESCE(j.reject_reason,''), COALESCE(j.pr_merged_at,''), COALESCE(j.pr_closed_at,''),
 	       j.created_at, j.updated_at, COALESCE(j.started_at,''), COALESCE(j.completed_at,''),
-	       COALESCE(j.ci_started_at,''), COALESCE(j.ci_completed_at,''), COALESCE(j.ci_status_summary,''),
+	       COALESCE(j.ci_started_at,''), COALESCE(j.ci_completed_at,''), COALESCE(j.ci_status_summary,''), COALESCE(j.parent_job_id,''), j.backport_branch, j.base_branch, j.signature_status, j.epic_index, j.epic_task, j.backport_commit, j.revert_commit, j.bisect_good, j.bisect_bad, j.bisect_cmd, j.bisect_fix, j.oversize_summary, j.summary, j.risk_score, j.risk_summary, j.priority, j.cpu_ms, j.peak_rss_bytes, ` + jobDeadlineColumn + `,
+	       j.ci_checks_total, j.ci_checks_passed, j.ci_checks_failed, j.ci_checks_pending,
 	       COALESCE(i.source,''), COALESCE(i.source_issue_id,''), COALESCE(i.title,''), COALESCE(i.url,'')
 FROM jobs j
 LEFT JOIN issues i ON j.autopr_issue_id = i.autopr_issue_id
@@ -1006,7 +1526,8 @@ ORDER BY j.updated_at DESC`
 			&j.HumanNotes, &j.ErrorMessage, &j.PRURL,
 			&j.RejectReason, &j.PRMergedAt, &j.PRClosedAt,
 			&j.CreatedAt, &j.UpdatedAt, &j.StartedAt, &j.CompletedAt,
-			&j.CIStartedAt, &j.CICompletedAt, &j.CIStatusSummary,
+			&j.CIStartedAt, &j.CICompletedAt, &j.CIStatusSummary, &j.ParentJobID, &j.BackportBranch, &j.BaseBranch, &j.SignatureStatus, &j.EpicIndex, &j.EpicTask, &j.BackportCommit, &j.RevertCommit, &j.BisectGood, &j.BisectBad, &j.BisectCmd, &j.BisectFix, &j.OversizeSummary, &j.Summary, &j.RiskScore, &j.RiskSummary, &j.Priority, &j.CPUTimeMS, &j.PeakRSSBytes, &j.Deadline,
+			&j.CIChecks.Total, &j.CIChecks.Passed, &j.CIChecks.Failed, &j.CIChecks.Pending,
 			&j.IssueSource, &j.SourceIssueID, &j.IssueTitle, &j.IssueURL,
 		); err != nil {
 			return nil, fmt.Errorf("scan ready/approved branch job: %w", err)
@@ -1025,8 +1546,9 @@ func (s *Store) ListCleanableJobs(ctx context.Context) ([]Job, error) {
 	       COALESCE(human_notes,''), COALESCE(error_message,''), COALESCE(pr_url,''),
 	       COALESCE(reject_reason,''), COALESCE(pr_merged_at,''), COALESCE(pr_closed_at,''),
 	       created_at, updated_at, COALESCE(started_at,''), COALESCE(completed_at,''),
-	       COALESCE(ci_started_at,''), COALESCE(ci_completed_at,''), COALESCE(ci_status_summary,'')
-FROM jobs
+	       COALESCE(ci_started_at,''), COALESCE(ci_completed_at,''), COALESCE(ci_status_summary,''), COALESCE(parent_job_id,''), backport_branch, base_branch, signature_status, epic_index, epic_task, backport_commit, revert_commit, bisect_good, bisect_bad, bisect_cmd, bisect_fix, oversize_summary, summary, risk_score, risk_summary, priority, cpu_ms, peak_rss_bytes, ` + jobDeadlineColumn + `,
+	       ci_checks_total, ci_checks_passed, ci_checks_failed, ci_checks_pending
+FROM jobs j
 WHERE worktree_path IS NOT NULL AND worktree_path != ''
   AND (
     state IN ('rejected', 'failed', 'cancelled')
@@ -1049,7 +1571,8 @@ ORDER BY updated_at DESC`
 			&j.HumanNotes, &j.ErrorMessage, &j.PRURL,
 			&j.RejectReason, &j.PRMergedAt, &j.PRClosedAt,
 			&j.CreatedAt, &j.UpdatedAt, &j.StartedAt, &j.CompletedAt,
-			&j.CIStartedAt, &j.CICompletedAt, &j.CIStatusSummary,
+			&j.CIStartedAt, &j.CICompletedAt, &j.CIStatusSummary, &j.ParentJobID, &j.BackportBranch, &j.BaseBranch, &j.SignatureStatus, &j.EpicIndex, &j.EpicTask, &j.BackportCommit, &j.RevertCommit, &j.BisectGood, &j.BisectBad, &j.BisectCmd, &j.BisectFix, &j.OversizeSummary, &j.Summary, &j.RiskScore, &j.RiskSummary, &j.Priority, &j.CPUTimeMS, &j.PeakRSSBytes, &j.Deadline,
+			&j.CIChecks.Total, &j.CIChecks.Passed, &j.CIChecks.Failed, &j.CIChecks.Pending,
 		); err != nil {
 			return nil, fmt.Errorf("scan cleanable job: %w", err)
 		}
@@ -1130,26 +1653,77 @@ type LLMSession struct {
 	OutputTokens int
 	DurationMS   int
 	JSONLPath    string
-	CommitSHA    string
+	BaseSHA      string // HEAD of the worktree when the session started
+	PID          int    // daemon-host process running the session; 0 if unknown
+	CommitSHA    string // HEAD when it ended, after any safety-net commit
 	Status       string
 	ErrorMessage string
 	CreatedAt    string
 	CompletedAt  string
+	PurgedAt     string // set once the retention policy or `ap purge` deleted the text
+	ContextFiles []ContextFile
+}
+
+// ContextFile is a repository doc added to an LLM session's prompt.
+type ContextFile struct {
+	Path      string `json:"path"`      // relative to the repository root
+	Bytes     int    `json:"bytes"`     // bytes added to the prompt
+	Truncated bool   `json:"truncated"` // the file was cut to fit the prompt
 }
 
 const recoveredSessionErrorMessage = "session recovered on daemon startup: previous run interrupted"
 
 func (s *Store) CreateSession(ctx context.Context, jobID, step string, iteration int, provider, jsonlPath string) (int64, error) {
 	const q = `INSERT INTO llm_sessions(job_id, step, iteration, llm_provider, jsonl_path) VALUES(?,?,?,?,?)`
-	res, err := s.Writer.ExecContext(ctx, q, jobID, step, iteration, provider, jsonlPath)
+	res, err := s.execBusy(ctx, "create session", q, jobID, step, iteration, provider, jsonlPath)
 	if err != nil {
 		return 0, fmt.Errorf("create session: %w", err)
 	}
 	return res.LastInsertId()
 }
 
+// SetSessionContextFiles records the repository docs added to a session's
+// prompt.
+func (s *Store) SetSessionContextFiles(ctx context.Context, sessionID int64, files []ContextFile) error {
+	data, err := json.Marshal(files)
+	if err != nil {
+		return fmt.Errorf("encode context files: %w", err)
+	}
+	if _, err := s.execBusy(ctx, "set session context files", `UPDATE llm_sessions SET context_files = ? WHERE id = ?`, string(data), sessionID); err != nil {
+		return fmt.Errorf("set context files of session %d: %w", sessionID, err)
+	}
+	return nil
+}
+
+// SetSessionBaseSHA records the commit a session started from.
+func (s *Store) SetSessionBaseSHA(ctx context.Context, sessionID int64, sha string) error {
+	if _, err := s.execBusy(ctx, "set session base sha", `UPDATE llm_sessions SET base_sha = ? WHERE id = ?`, sha, sessionID); err != nil {
+		return fmt.Errorf("set base sha of session %d: %w", sessionID, err)
+	}
+	return nil
+}
+
+// SetSessionPID records the process running a session.
+func (s *Store) SetSessionPID(ctx context.Context, sessionID int64, pid int) error {
+	if _, err := s.execBusy(ctx, "set session pid", `UPDATE llm_sessions SET pid = ? WHERE id = ?`, pid, sessionID); err != nil {
+		return fmt.Errorf("set pid of session %d: %w", sessionID, err)
+	}
+	return nil
+}
+
+// SetLatestSessionCommit sets the commit of the job's latest session of step,
+// for commits the pipeline makes on the session's behalf once it ends.
+func (s *Store) SetLatestSessionCommit(ctx context.Context, jobID, step, sha string) error {
+	const q = `UPDATE llm_sessions SET commit_sha = ?
+WHERE id = (SELECT MAX(id) FROM llm_sessions WHERE job_id = ? AND step = ?)`
+	if _, err := s.execBusy(ctx, "set session commit", q, sha, jobID, step); err != nil {
+		return fmt.Errorf("set commit of %s session of job %s: %w", step, jobID, err)
+	}
+	return nil
+}
+
 func (s *Store) CompleteSession(ctx context.Context, sessionID int64, status, responseText, promptText, promptHash, jsonlPath, commitSHA, errMsg string, inputTokens, outputTokens, durationMS int) error {
-	res, err := s.Writer.ExecContext(ctx, `
+	res, err := s.execBusy(ctx, "complete session", `
 UPDATE llm_sessions SET status = ?, response_text = ?, prompt_text = ?, prompt_hash = ?, jsonl_path = ?,
                        commit_sha = ?, error_message = ?, input_tokens = ?, output_tokens = ?,
                        duration_ms = ?, completed_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now')
@@ -1183,20 +1757,49 @@ WHERE status = 'running'`,
 	return res.RowsAffected()
 }
 
-func (s *Store) ListSessionsByJob(ctx context.Context, jobID string) ([]LLMSession, error) {
-	const q = `
-SELECT id, job_id, step, iteration, llm_provider,
+// FailRunningSession marks a single running LLM session as failed with msg.
+func (s *Store) FailRunningSession(ctx context.Context, sessionID int, msg string) error {
+	_, err := s.execBusy(ctx, "fail session", `
+UPDATE llm_sessions
+SET status = 'failed',
+    error_message = COALESCE(NULLIF(error_message, ''), ?),
+    input_tokens = COALESCE(input_tokens, 0),
+    output_tokens = COALESCE(output_tokens, 0),
+    duration_ms = COALESCE(duration_ms, 0),
+    completed_at = COALESCE(completed_at, strftime('%Y-%m-%dT%H:%M:%SZ', 'now'))
+WHERE id = ? AND status = 'running'`, msg, sessionID)
+	if err != nil {
+		return fmt.Errorf("fail session %d: %w", sessionID, err)
+	}
+	return nil
+}
+
+const sessionColumns = `id, job_id, step, iteration, llm_provider,
        COALESCE(prompt_hash,''), COALESCE(response_text,''),
        COALESCE(input_tokens,0), COALESCE(output_tokens,0), COALESCE(duration_ms,0),
-       COALESCE(jsonl_path,''), COALESCE(commit_sha,''), status,
-       COALESCE(error_message,''), created_at, COALESCE(completed_at,'')
-FROM llm_sessions WHERE job_id = ? ORDER BY id ASC`
-	rows, err := s.Reader.QueryContext(ctx, q, jobID)
+       COALESCE(jsonl_path,''), base_sha, pid, COALESCE(commit_sha,''), status,
+       COALESCE(error_message,''), created_at, COALESCE(completed_at,''), purged_at`
+
+// ListRunningSessions returns every LLM session still marked running.
+func (s *Store) ListRunningSessions(ctx context.Context) ([]LLMSession, error) {
+	rows, err := s.Reader.QueryContext(ctx, `SELECT `+sessionColumns+` FROM llm_sessions WHERE status = 'running' ORDER BY id ASC`)
+	if err != nil {
+		return nil, fmt.Errorf("list running sessions: %w", err)
+	}
+	defer rows.Close()
+	return scanSessions(rows)
+}
+
+func (s *Store) ListSessionsByJob(ctx context.Context, jobID string) ([]LLMSession, error) {
+	rows, err := s.Reader.QueryContext(ctx, `SELECT `+sessionColumns+` FROM llm_sessions WHERE job_id = ? ORDER BY id ASC`, jobID)
 	if err != nil {
 		return nil, fmt.Errorf("list sessions: %w", err)
 	}
 	defer rows.Close()
+	return scanSessions(rows)
+}
 
+func scanSessions(rows *sql.Rows) ([]LLMSession, error) {
 	var out []LLMSession
 	for rows.Next() {
 		var sess LLMSession
@@ -1204,8 +1807,8 @@ FROM llm_sessions WHERE job_id = ? ORDER BY id ASC`
 			&sess.ID, &sess.JobID, &sess.Step, &sess.Iteration, &sess.LLMProvider,
 			&sess.PromptHash, &sess.ResponseText,
 			&sess.InputTokens, &sess.OutputTokens, &sess.DurationMS,
-			&sess.JSONLPath, &sess.CommitSHA, &sess.Status,
-			&sess.ErrorMessage, &sess.CreatedAt, &sess.CompletedAt,
+			&sess.JSONLPath, &sess.BaseSHA, &sess.PID, &sess.CommitSHA, &sess.Status,
+			&sess.ErrorMessage, &sess.CreatedAt, &sess.CompletedAt, &sess.PurgedAt,
 		); err != nil {
 			return nil, fmt.Errorf("scan session: %w", err)
 		}
@@ -1262,16 +1865,19 @@ func (s *Store) GetFullSession(ctx context.Context, sessionID int) (LLMSession,
 SELECT id, job_id, step, iteration, llm_provider,
        COALESCE(prompt_hash,''), COALESCE(response_text,''), COALESCE(prompt_text,''),
        COALESCE(input_tokens,0), COALESCE(output_tokens,0), COALESCE(duration_ms,0),
-       COALESCE(jsonl_path,''), COALESCE(commit_sha,''), status,
-       COALESCE(error_message,''), created_at, COALESCE(completed_at,'')
+       COALESCE(jsonl_path,''), base_sha, pid, COALESCE(commit_sha,''), status,
+       COALESCE(error_message,''), created_at, COALESCE(completed_at,''), purged_at, context_files
 FROM llm_sessions WHERE id = ?`
-	var sess LLMSession
+	var (
+		sess         LLMSession
+		contextFiles string
+	)
 	err := s.Reader.QueryRowContext(ctx, q, sessionID).Scan(
 		&sess.ID, &sess.JobID, &sess.Step, &sess.Iteration, &sess.LLMProvider,
 		&sess.PromptHash, &sess.ResponseText, &sess.PromptText,
 		&sess.InputTokens, &sess.OutputTokens, &sess.DurationMS,
-		&sess.JSONLPath, &sess.CommitSHA, &sess.Status,
-		&sess.ErrorMessage, &sess.CreatedAt, &sess.CompletedAt,
+		&sess.JSONLPath, &sess.BaseSHA, &sess.PID, &sess.CommitSHA, &sess.Status,
+		&sess.ErrorMessage, &sess.CreatedAt, &sess.CompletedAt, &sess.PurgedAt, &contextFiles,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -1279,6 +1885,11 @@ FROM llm_sessions WHERE id = ?`
 		}
 		return LLMSession{}, fmt.Errorf("get session %d: %w", sessionID, err)
 	}
+	if contextFiles != "" {
+		if err := json.Unmarshal([]byte(contextFiles), &sess.ContextFiles); err != nil {
+			return LLMSession{}, fmt.Errorf("decode context files of session %d: %w", sessionID, err)
+		}
+	}
 	return sess, nil
 }
 
@@ -1297,7 +1908,7 @@ type Artifact struct {
 
 func (s *Store) CreateArtifact(ctx context.Context, jobID, autoprIssueID, kind, content string, iteration int, commitSHA string) (int64, error) {
 	const q = `INSERT INTO artifacts(job_id, autopr_issue_id, kind, content, iteration, commit_sha) VALUES(?,?,?,?,?,?)`
-	res, err := s.Writer.ExecContext(ctx, q, jobID, autoprIssueID, kind, content, iteration, commitSHA)
+	res, err := s.execBusy(ctx, "create artifact", q, jobID, autoprIssueID, kind, content, iteration, commitSHA)
 	if err != nil {
 		return 0, fmt.Errorf("create artifact: %w", err)
 	}
@@ -1342,6 +1953,86 @@ FROM artifacts WHERE job_id = ? ORDER BY id ASC`
 	return out, rows.Err()
 }
 
+// JobIteration is a snapshot of one pipeline iteration: the plan in effect,
+// the review feedback it received, and the branch HEAD that was reviewed.
+type JobIteration struct {
+	Iteration     int
+	PlanIteration int // iteration that produced Plan (earlier when carried forward)
+	Plan          string
+	Review        string
+	CommitSHA     string
+}
+
+// ListJobIterations groups a job's plan and code_review artifacts by iteration.
+// Iterations without their own plan inherit the most recent earlier plan.
+func (s *Store) ListJobIterations(ctx context.Context, jobID string) ([]JobIteration, error) {
+	artifacts, err := s.ListArtifactsByJob(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	byIteration := map[int]*JobIteration{}
+	var order []int
+	get := func(iteration int) *JobIteration {
+		it, ok := byIteration[iteration]
+		if !ok {
+			it = &JobIteration{Iteration: iteration, PlanIteration: -1}
+			byIteration[iteration] = it
+			order = append(order, iteration)
+		}
+		return it
+	}
+	for _, a := range artifacts {
+		switch a.Kind {
+		case "plan":
+			it := get(a.Iteration)
+			it.Plan = a.Content
+			it.PlanIteration = a.Iteration
+		case "code_review":
+			it := get(a.Iteration)
+			it.Review = a.Content
+			if a.CommitSHA != "" {
+				it.CommitSHA = a.CommitSHA
+			}
+		}
+	}
+	slices.Sort(order)
+
+	out := make([]JobIteration, 0, len(order))
+	var lastPlan string
+	lastPlanIteration := -1
+	for _, iteration := range order {
+		it := *byIteration[iteration]
+		if it.PlanIteration < 0 {
+			it.Plan = lastPlan
+			it.PlanIteration = lastPlanIteration
+		} else {
+			lastPlan = it.Plan
+			lastPlanIteration = it.PlanIteration
+		}
+		out = append(out, it)
+	}
+	return out, nil
+}
+
+// IterationPair returns the snapshots for iteration and the one before it.
+// A negative iteration selects the latest pair.
+func IterationPair(iterations []JobIteration, iteration int) (JobIteration, JobIteration, error) {
+	if len(iterations) < 2 {
+		return JobIteration{}, JobIteration{}, fmt.Errorf("job has %d recorded iteration(s); need at least 2 to compare", len(iterations))
+	}
+	idx := len(iterations) - 1
+	if iteration >= 0 {
+		idx = slices.IndexFunc(iterations, func(it JobIteration) bool { return it.Iteration == iteration })
+		if idx < 0 {
+			return JobIteration{}, JobIteration{}, fmt.Errorf("iteration %d not found", iteration)
+		}
+		if idx == 0 {
+			return JobIteration{}, JobIteration{}, fmt.Errorf("iteration %d has no earlier iteration to compare against", iteration)
+		}
+	}
+	return iterations[idx-1], iterations[idx], nil
+}
+
 // ResolveJobID resolves a full or partial job ID prefix to a single job ID.
 // Accepts full IDs (ap-job-2dad8b6b5f96e0df), short prefixes (2dad), or
 // prefixed short forms (ap-job-2dad). Returns an error if zero or multiple matches.
@@ -0,0 +1,104 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+)
+
+// JobLeaseTTL is how long a job lease lasts without renewal. WithJobLease
+// renews well before it runs out, so only a crashed holder lets one expire.
+const JobLeaseTTL = 2 * time.Minute
+
+// JobLeasedError is returned when another process holds a job's lease.
+type JobLeasedError struct {
+	JobID     string
+	Holder    string
+	ExpiresAt string
+}
+
+func (e *JobLeasedError) Error() string {
+	return fmt.Sprintf("job %s is busy: %s holds it until %s", ShortID(e.JobID), e.Holder, e.ExpiresAt)
+}
+
+// ErrJobLeased matches any *JobLeasedError with errors.Is.
+var ErrJobLeased = errors.New("job is leased")
+
+func (e *JobLeasedError) Is(target error) bool { return target == ErrJobLeased }
+
+// LeaseHolder names a lease holder after its role (e.g. "worker", "ap
+// approve") and the current process.
+func LeaseHolder(role string) string {
+	return fmt.Sprintf("%s (pid %d)", role, os.Getpid())
+}
+
+// AcquireJobLease takes the lease on jobID for holder, or extends it if holder
+// already has it. It fails with a *JobLeasedError while another holder's
+// lease is live.
+func (s *Store) AcquireJobLease(ctx context.Context, jobID, holder string, ttl time.Duration) error {
+	expires := time.Now().Add(ttl).UTC().Format(time.RFC3339)
+	res, err := s.Writer.ExecContext(ctx, `
+INSERT INTO job_leases(job_id, holder, expires_at) VALUES(?, ?, ?)
+ON CONFLICT(job_id) DO UPDATE SET holder = excluded.holder, expires_at = excluded.expires_at
+WHERE job_leases.holder = excluded.holder
+   OR job_leases.expires_at <= strftime('%Y-%m-%dT%H:%M:%SZ', 'now')`, jobID, holder, expires)
+	if err != nil {
+		return fmt.Errorf("acquire lease on job %s: %w", jobID, err)
+	}
+	if n, _ := res.RowsAffected(); n > 0 {
+		return nil
+	}
+	leased := &JobLeasedError{JobID: jobID}
+	if err := s.Reader.QueryRowContext(ctx, `SELECT holder, expires_at FROM job_leases WHERE job_id = ?`, jobID).Scan(&leased.Holder, &leased.ExpiresAt); err != nil {
+		return fmt.Errorf("acquire lease on job %s: %w", jobID, err)
+	}
+	return leased
+}
+
+// ReleaseJobLease drops holder's lease on jobID. Releasing a lease held by
+// someone else, or no lease, is a no-op.
+func (s *Store) ReleaseJobLease(ctx context.Context, jobID, holder string) error {
+	if _, err := s.Writer.ExecContext(ctx, `DELETE FROM job_leases WHERE job_id = ? AND holder = ?`, jobID, holder); err != nil {
+		return fmt.Errorf("release lease on job %s: %w", jobID, err)
+	}
+	return nil
+}
+
+// WithJobLease runs fn while holding the lease on jobID, renewing it in the
+// background until fn returns. It returns a *JobLeasedError without running
+// fn if another process holds the lease.
+func (s *Store) WithJobLease(ctx context.Context, jobID, holder string, fn func(context.Context) error) error {
+	if err := s.AcquireJobLease(ctx, jobID, holder, JobLeaseTTL); err != nil {
+		return err
+	}
+	renewCtx, stop := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(JobLeaseTTL / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-renewCtx.Done():
+				return
+			case <-ticker.C:
+			}
+			if err := s.AcquireJobLease(renewCtx, jobID, holder, JobLeaseTTL); err != nil && renewCtx.Err() == nil {
+				slog.Warn("renew job lease", "job", ShortID(jobID), "holder", holder, "err", err)
+			}
+		}
+	}()
+	defer func() {
+		stop()
+		<-done
+		// Release even if ctx was cancelled, so the job is not blocked until
+		// the lease expires.
+		if err := s.ReleaseJobLease(context.WithoutCancel(ctx), jobID, holder); err != nil {
+			slog.Warn("release job lease", "job", ShortID(jobID), "holder", holder, "err", err)
+		}
+	}()
+	return fn(ctx)
+}
@@ -0,0 +1,81 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestJobLeaseExcludesOtherHolders(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	path := filepath.Join(t.TempDir(), "autopr.db")
+	store, err := Open(path)
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer store.Close()
+	// A second Store stands in for another ap process on the same database.
+	other, err := Open(path)
+	if err != nil {
+		t.Fatalf("open second db: %v", err)
+	}
+	defer other.Close()
+
+	issueID, err := store.UpsertIssue(ctx, IssueUpsert{
+		ProjectName:   "myproject",
+		Source:        "github",
+		SourceIssueID: "88",
+		Title:         "lease me",
+		URL:           "https://github.com/org/repo/issues/88",
+		State:         "open",
+	})
+	if err != nil {
+		t.Fatalf("upsert issue: %v", err)
+	}
+	jobID, err := store.CreateJob(ctx, issueID, "myproject", 3)
+	if err != nil {
+		t.Fatalf("create job: %v", err)
+	}
+
+	ran := false
+	err = store.WithJobLease(ctx, jobID, "worker", func(ctx context.Context) error {
+		ran = true
+		err := other.WithJobLease(ctx, jobID, "cli", func(context.Context) error {
+			t.Fatal("second holder ran while the lease was held")
+			return nil
+		})
+		var leased *JobLeasedError
+		if !errors.As(err, &leased) || !errors.Is(err, ErrJobLeased) || leased.Holder != "worker" {
+			t.Fatalf("expected JobLeasedError held by worker, got %v", err)
+		}
+		// The holder itself can extend its lease.
+		return store.AcquireJobLease(ctx, jobID, "worker", JobLeaseTTL)
+	})
+	if err != nil || !ran {
+		t.Fatalf("with lease: ran=%v err=%v", ran, err)
+	}
+
+	// Released when fn returns.
+	if err := other.AcquireJobLease(ctx, jobID, "cli", time.Minute); err != nil {
+		t.Fatalf("acquire after release: %v", err)
+	}
+	// Releasing someone else's lease is a no-op.
+	if err := store.ReleaseJobLease(ctx, jobID, "worker"); err != nil {
+		t.Fatalf("release other holder: %v", err)
+	}
+	if err := store.AcquireJobLease(ctx, jobID, "worker", time.Minute); !errors.Is(err, ErrJobLeased) {
+		t.Fatalf("expected lease still held by cli, got %v", err)
+	}
+
+	// An expired lease can be taken over, as after a crash.
+	if err := other.AcquireJobLease(ctx, jobID, "cli", -time.Minute); err != nil {
+		t.Fatalf("expire lease: %v", err)
+	}
+	if err := store.AcquireJobLease(ctx, jobID, "worker", time.Minute); err != nil {
+		t.Fatalf("take over expired lease: %v", err)
+	}
+}
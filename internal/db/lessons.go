@@ -0,0 +1,100 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Lesson sources.
+const (
+	LessonSourceReview   = "review"   // a code review that asked for changes
+	LessonSourceFeedback = "feedback" // human notes on a retry or PR feedback
+	LessonSourceManual   = "manual"   // added with ap lessons add
+)
+
+// maxLessonLen bounds a lesson, so a prompt stays short however many it gets.
+const maxLessonLen = 300
+
+// Lesson is guidance for a project's future jobs.
+type Lesson struct {
+	ID           int64  `json:"id"`
+	ProjectName  string `json:"project"`
+	Text         string `json:"text"`
+	Source       string `json:"source"`
+	JobID        string `json:"job_id,omitempty"` // job it was learned from; "" for manual lessons
+	TimesLearned int    `json:"times_learned"`
+	CreatedAt    string `json:"created_at"`
+	UpdatedAt    string `json:"updated_at"` // when it was last learned
+}
+
+// normalizeLesson is the form lessons are told apart by: lowercased, with
+// whitespace collapsed and a final period dropped.
+func normalizeLesson(text string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.Join(strings.Fields(text), " ")), ".")
+}
+
+// AddLesson records a lesson for a project. A lesson the project has already
+// learned is counted again and credited to jobID instead of being stored
+// twice. It returns the lesson's ID and whether it is new.
+func (s *Store) AddLesson(ctx context.Context, project, text, source, jobID string) (int64, bool, error) {
+	text = strings.Join(strings.Fields(text), " ")
+	if text == "" {
+		return 0, false, fmt.Errorf("lesson is empty")
+	}
+	if len(text) > maxLessonLen {
+		return 0, false, fmt.Errorf("lesson is longer than %d characters", maxLessonLen)
+	}
+	var (
+		id    int64
+		times int
+	)
+	err := s.retryBusy(ctx, "add lesson", func() error {
+		return s.Writer.QueryRowContext(ctx, `
+INSERT INTO lessons(project_name, text, norm, source, job_id) VALUES(?,?,?,?,?)
+ON CONFLICT(project_name, norm) DO UPDATE SET
+    times_learned = times_learned + 1,
+    job_id = CASE WHEN excluded.job_id != '' THEN excluded.job_id ELSE lessons.job_id END,
+    updated_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now')
+RETURNING id, times_learned`, project, text, normalizeLesson(text), source, jobID).Scan(&id, &times)
+	})
+	if err != nil {
+		return 0, false, fmt.Errorf("add lesson: %w", err)
+	}
+	return id, times == 1, nil
+}
+
+// ListLessons returns the lessons of a project, or of every project when
+// project is "", the most often learned first.
+func (s *Store) ListLessons(ctx context.Context, project string) ([]Lesson, error) {
+	rows, err := s.Reader.QueryContext(ctx, `
+SELECT id, project_name, text, source, job_id, times_learned, created_at, updated_at
+FROM lessons
+WHERE ? = '' OR project_name = ?
+ORDER BY project_name, times_learned DESC, updated_at DESC, id`, project, project)
+	if err != nil {
+		return nil, fmt.Errorf("list lessons: %w", err)
+	}
+	defer rows.Close()
+	var out []Lesson
+	for rows.Next() {
+		var l Lesson
+		if err := rows.Scan(&l.ID, &l.ProjectName, &l.Text, &l.Source, &l.JobID, &l.TimesLearned, &l.CreatedAt, &l.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("scan lesson: %w", err)
+		}
+		out = append(out, l)
+	}
+	return out, rows.Err()
+}
+
+// DeleteLesson forgets a lesson.
+func (s *Store) DeleteLesson(ctx context.Context, id int64) error {
+	res, err := s.Writer.ExecContext(ctx, `DELETE FROM lessons WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("delete lesson %d: %w", id, err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("lesson %d not found", id)
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package db
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAddLessonCountsRepeats(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	store, err := Open(filepath.Join(t.TempDir(), "autopr.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer store.Close()
+
+	id, isNew, err := store.AddLesson(ctx, "web", "Use the internal logger, not fmt.Println.", LessonSourceReview, "job-1")
+	if err != nil || !isNew {
+		t.Fatalf("add lesson = %d, %v, %v", id, isNew, err)
+	}
+	again, isNew, err := store.AddLesson(ctx, "web", "use the internal   logger, not fmt.Println", LessonSourceFeedback, "job-2")
+	if err != nil || isNew || again != id {
+		t.Fatalf("add repeated lesson = %d, %v, %v; want %d, false", again, isNew, err, id)
+	}
+	if _, _, err := store.AddLesson(ctx, "api", "Wrap errors with %w.", LessonSourceManual, ""); err != nil {
+		t.Fatalf("add lesson to another project: %v", err)
+	}
+	if _, _, err := store.AddLesson(ctx, "web", "  ", LessonSourceManual, ""); err == nil {
+		t.Fatal("expected an empty lesson to be refused")
+	}
+	if _, _, err := store.AddLesson(ctx, "web", strings.Repeat("x", maxLessonLen+1), LessonSourceManual, ""); err == nil {
+		t.Fatal("expected a long lesson to be refused")
+	}
+
+	lessons, err := store.ListLessons(ctx, "web")
+	if err != nil {
+		t.Fatalf("list lessons: %v", err)
+	}
+	if len(lessons) != 1 || lessons[0].TimesLearned != 2 || lessons[0].JobID != "job-2" || lessons[0].Text != "Use the internal logger, not fmt.Println." {
+		t.Fatalf("unexpected lessons: %+v", lessons)
+	}
+	if all, _ := store.ListLessons(ctx, ""); len(all) != 2 {
+		t.Fatalf("expected lessons of both projects, got %+v", all)
+	}
+
+	if err := store.DeleteLesson(ctx, id); err != nil {
+		t.Fatalf("delete lesson: %v", err)
+	}
+	if err := store.DeleteLesson(ctx, id); err == nil {
+		t.Fatal("expected deleting a deleted lesson to fail")
+	}
+}
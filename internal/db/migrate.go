@@ -0,0 +1,200 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// baselineSchemaVersion is the schema produced by schemaSQL plus the legacy
+// in-place migrations in createSchema. Numbered migration files build on it.
+const baselineSchemaVersion = 1
+
+// migrationFiles holds the ordered schema migrations, named NNNN_name.sql.
+//
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// Migration is one numbered schema upgrade.
+type Migration struct {
+	Version int    `json:"version"`
+	Name    string `json:"name"`
+	SQL     string `json:"-"`
+}
+
+// Migrations returns every embedded migration in version order. Versions must
+// be contiguous, starting right after the baseline.
+func Migrations() ([]Migration, error) {
+	entries, err := fs.ReadDir(migrationFiles, "migrations")
+	if err != nil {
+		return nil, fmt.Errorf("read migrations: %w", err)
+	}
+	out := make([]Migration, 0, len(entries))
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".sql") {
+			continue
+		}
+		prefix, label, ok := strings.Cut(strings.TrimSuffix(name, ".sql"), "_")
+		version, err := strconv.Atoi(prefix)
+		if !ok || err != nil || version <= 0 {
+			return nil, fmt.Errorf("invalid migration file name %q (want NNNN_name.sql)", name)
+		}
+		body, err := migrationFiles.ReadFile(path.Join("migrations", name))
+		if err != nil {
+			return nil, fmt.Errorf("read migration %s: %w", name, err)
+		}
+		out = append(out, Migration{Version: version, Name: label, SQL: string(body)})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Version < out[j].Version })
+	for i, m := range out {
+		if want := baselineSchemaVersion + 1 + i; m.Version != want {
+			return nil, fmt.Errorf("migration %04d_%s out of sequence (want version %d)", m.Version, m.Name, want)
+		}
+	}
+	return out, nil
+}
+
+// LatestSchemaVersion is the version a database has once every migration is applied.
+func LatestSchemaVersion() (int, error) {
+	all, err := Migrations()
+	if err != nil {
+		return 0, err
+	}
+	return baselineSchemaVersion + len(all), nil
+}
+
+// pendingMigrations returns the migrations newer than current.
+func pendingMigrations(all []Migration, current int) []Migration {
+	var out []Migration
+	for _, m := range all {
+		if m.Version > current {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+// PendingMigrations reports the schema version of the database at dbPath and
+// the migrations Open would apply to it, without modifying the file. A
+// missing database reports version 0 with every migration pending. key is
+// the database key, or "" for an unencrypted database.
+func PendingMigrations(dbPath, key string) (int, []Migration, error) {
+	all, err := Migrations()
+	if err != nil {
+		return 0, nil, err
+	}
+	if _, err := os.Stat(dbPath); errors.Is(err, os.ErrNotExist) {
+		return 0, all, nil
+	}
+
+	ro, err := openDB(dbPath, key, "_busy_timeout=5000&mode=ro")
+	if err != nil {
+		return 0, nil, fmt.Errorf("open db read-only: %w", err)
+	}
+	defer ro.Close()
+
+	current, err := schemaVersionOf(ro)
+	if err != nil {
+		return 0, nil, err
+	}
+	return current, pendingMigrations(all, current), nil
+}
+
+// SchemaVersion returns the highest version recorded in schema_version, or 0
+// for a database that has never been initialised.
+func (s *Store) SchemaVersion() (int, error) {
+	return schemaVersionOf(s.Reader)
+}
+
+func schemaVersionOf(conn *sql.DB) (int, error) {
+	var exists int
+	if err := conn.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type='table' AND name='schema_version'`).Scan(&exists); err != nil {
+		return 0, fmt.Errorf("check schema_version table: %w", err)
+	}
+	if exists == 0 {
+		return 0, nil
+	}
+	var version int
+	if err := conn.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM schema_version`).Scan(&version); err != nil {
+		return 0, fmt.Errorf("read schema version: %w", err)
+	}
+	return version, nil
+}
+
+// applyMigrations brings the schema up to date, one transaction per
+// migration. Databases that already held data are backed up first.
+func (s *Store) applyMigrations(existing bool) error {
+	all, err := Migrations()
+	if err != nil {
+		return err
+	}
+	var current int
+	if err := s.Writer.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM schema_version`).Scan(&current); err != nil {
+		return fmt.Errorf("read schema version: %w", err)
+	}
+	if latest := baselineSchemaVersion + len(all); current > latest {
+		return fmt.Errorf("database schema version %d is newer than this ap supports (%d); upgrade ap", current, latest)
+	}
+	pending := pendingMigrations(all, current)
+	if len(pending) == 0 {
+		return nil
+	}
+
+	if existing {
+		backupPath, err := s.Backup(context.Background(), "")
+		if err != nil {
+			return fmt.Errorf("backup database before migration: %w", err)
+		}
+		slog.Info("database backed up before migration", "path", backupPath, "from_version", current)
+	}
+
+	// Foreign keys are off while migrating so a migration can recreate a
+	// table without cascading deletes; each one is checked before commit.
+	return s.withForeignKeysOff(func() error {
+		for _, m := range pending {
+			if err := s.applyMigration(m); err != nil {
+				return err
+			}
+			if existing {
+				slog.Info("applied schema migration", "version", m.Version, "name", m.Name)
+			}
+		}
+		return nil
+	})
+}
+
+func (s *Store) applyMigration(m Migration) error {
+	tx, err := s.Writer.Begin()
+	if err != nil {
+		return fmt.Errorf("begin migration %04d: %w", m.Version, err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(m.SQL); err != nil {
+		return fmt.Errorf("apply migration %04d_%s: %w", m.Version, m.Name, err)
+	}
+	var violations int
+	if err := tx.QueryRow(`SELECT COUNT(*) FROM pragma_foreign_key_check`).Scan(&violations); err != nil {
+		return fmt.Errorf("check foreign keys after migration %04d: %w", m.Version, err)
+	}
+	if violations > 0 {
+		return fmt.Errorf("migration %04d_%s left %d foreign key violation(s)", m.Version, m.Name, violations)
+	}
+	if _, err := tx.Exec(`INSERT INTO schema_version (version) VALUES (?)`, m.Version); err != nil {
+		return fmt.Errorf("record migration %04d: %w", m.Version, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit migration %04d: %w", m.Version, err)
+	}
+	return nil
+}
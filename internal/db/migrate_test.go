@@ -0,0 +1,113 @@
+package db
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+)
+
+func TestMigrationsAreContiguous(t *testing.T) {
+	t.Parallel()
+
+	all, err := Migrations()
+	if err != nil {
+		t.Fatalf("migrations: %v", err)
+	}
+	if len(all) == 0 {
+		t.Fatal("expected at least one embedded migration")
+	}
+	for i, m := range all {
+		if m.Version != baselineSchemaVersion+1+i || m.Name == "" || m.SQL == "" {
+			t.Fatalf("unexpected migration at %d: %+v", i, m)
+		}
+	}
+}
+
+// createBaselineDB writes a database at the baseline schema, as an ap
+// release from before numbered migrations would have left it.
+func createBaselineDB(t *testing.T, dbPath string) {
+	t.Helper()
+	conn, err := sql.Open("sqlite3", "file:"+dbPath+"?_journal_mode=WAL")
+	if err != nil {
+		t.Fatalf("open baseline db: %v", err)
+	}
+	defer conn.Close()
+	if _, err := conn.Exec(schemaSQL); err != nil {
+		t.Fatalf("create baseline schema: %v", err)
+	}
+	if _, err := conn.Exec(`INSERT INTO schema_version (version) VALUES (?)`, baselineSchemaVersion); err != nil {
+		t.Fatalf("record baseline version: %v", err)
+	}
+}
+
+func TestOpenAppliesPendingMigrationsWithBackup(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "autopr.db")
+	latest, err := LatestSchemaVersion()
+	if err != nil {
+		t.Fatalf("latest version: %v", err)
+	}
+
+	fresh, err := Open(filepath.Join(dir, "fresh.db"))
+	if err != nil {
+		t.Fatalf("open fresh db: %v", err)
+	}
+	if v, err := fresh.SchemaVersion(); err != nil || v != latest {
+		t.Fatalf("expected fresh db at version %d, got %d (err %v)", latest, v, err)
+	}
+	_ = fresh.Close()
+	if backups, _ := filepath.Glob(filepath.Join(dir, "fresh.db.bak.*")); len(backups) != 0 {
+		t.Fatalf("expected no backup for a fresh db, got %v", backups)
+	}
+
+	createBaselineDB(t, dbPath)
+	current, pending, err := PendingMigrations(dbPath, "")
+	if err != nil {
+		t.Fatalf("pending migrations: %v", err)
+	}
+	if current != baselineSchemaVersion || len(pending) != latest-baselineSchemaVersion {
+		t.Fatalf("expected %d pending from baseline, got current=%d pending=%+v", latest-baselineSchemaVersion, current, pending)
+	}
+
+	store, err := Open(dbPath)
+	if err != nil {
+		t.Fatalf("open baseline db: %v", err)
+	}
+	defer store.Close()
+	if v, err := store.SchemaVersion(); err != nil || v != latest {
+		t.Fatalf("expected migrated db at version %d, got %d (err %v)", latest, v, err)
+	}
+	var indexes int
+	if err := store.Reader.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type='index' AND name='idx_outbox_ops_status_updated'`).Scan(&indexes); err != nil || indexes != 1 {
+		t.Fatalf("expected migration to create index, got %d (err %v)", indexes, err)
+	}
+	backups, _ := filepath.Glob(dbPath + ".bak.*")
+	if len(backups) != 1 {
+		t.Fatalf("expected one backup before migrating, got %v", backups)
+	}
+	backupVersion, _, err := PendingMigrations(backups[0], "")
+	if err != nil || backupVersion != baselineSchemaVersion {
+		t.Fatalf("expected backup at baseline version, got %d (err %v)", backupVersion, err)
+	}
+}
+
+func TestOpenRejectsNewerSchema(t *testing.T) {
+	t.Parallel()
+
+	dbPath := filepath.Join(t.TempDir(), "autopr.db")
+	store, err := Open(dbPath)
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if _, err := store.Writer.Exec(`INSERT INTO schema_version (version) VALUES (9999)`); err != nil {
+		t.Fatalf("bump version: %v", err)
+	}
+	_ = store.Close()
+
+	if store, err := Open(dbPath); err == nil {
+		_ = store.Close()
+		t.Fatal("expected error opening a database from a newer ap")
+	}
+}
@@ -0,0 +1,4 @@
+-- Outbox replay and retention both filter on status; the daemon scans these
+-- tables on every outbox tick.
+CREATE INDEX IF NOT EXISTS idx_outbox_ops_status_updated
+    ON outbox_ops(status, updated_at);
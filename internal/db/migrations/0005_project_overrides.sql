@@ -0,0 +1,7 @@
+-- Per-project settings changed at runtime with `ap project enable|disable`.
+-- A row exists only while the setting differs from the config file.
+CREATE TABLE IF NOT EXISTS project_overrides (
+    project_name TEXT PRIMARY KEY,
+    enabled      INTEGER NOT NULL CHECK(enabled IN (0,1)),
+    updated_at   TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now'))
+);
@@ -0,0 +1,32 @@
+-- Issues of local projects (no remote tracker) come from a markdown checklist
+-- in the repository or from `ap run`, under source 'local'.
+CREATE TABLE issues_new (
+    autopr_issue_id   TEXT PRIMARY KEY,
+    project_name      TEXT NOT NULL,
+    source            TEXT NOT NULL CHECK(source IN ('gitlab', 'github', 'gitea', 'sentry', 'recurring', 'bisect', 'local')),
+    source_issue_id   TEXT NOT NULL,
+    title             TEXT NOT NULL,
+    body              TEXT NOT NULL DEFAULT '',
+    url               TEXT NOT NULL,
+    state             TEXT NOT NULL CHECK(state IN ('open', 'closed')),
+    labels_json       TEXT NOT NULL DEFAULT '[]',
+    source_meta_json  TEXT NOT NULL DEFAULT '{}',
+    eligible          INTEGER NOT NULL DEFAULT 1 CHECK(eligible IN (0,1)),
+    skip_reason       TEXT NOT NULL DEFAULT '',
+    evaluated_at      TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now')),
+    source_updated_at TEXT NOT NULL,
+    synced_at         TEXT NOT NULL,
+    UNIQUE(project_name, source, source_issue_id)
+);
+
+INSERT INTO issues_new (
+    autopr_issue_id, project_name, source, source_issue_id, title, body, url, state,
+    labels_json, source_meta_json, eligible, skip_reason, evaluated_at, source_updated_at, synced_at
+)
+SELECT
+    autopr_issue_id, project_name, source, source_issue_id, title, body, url, state,
+    labels_json, source_meta_json, eligible, skip_reason, evaluated_at, source_updated_at, synced_at
+FROM issues;
+
+DROP TABLE issues;
+ALTER TABLE issues_new RENAME TO issues;
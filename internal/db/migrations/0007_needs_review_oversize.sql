@@ -0,0 +1,65 @@
+-- Jobs whose diff exceeds the project's size limits pause in
+-- needs_review_oversize until a human allows, splits, or rejects them.
+CREATE TABLE jobs_new (
+    id              TEXT PRIMARY KEY,
+    autopr_issue_id TEXT NOT NULL REFERENCES issues(autopr_issue_id) ON DELETE RESTRICT,
+    project_name     TEXT NOT NULL,
+    state            TEXT NOT NULL DEFAULT 'queued'
+        CHECK(state IN ('queued','planning','implementing','reviewing','testing','ready','rebasing','resolving_conflicts','awaiting_checks','waiting_network','needs_review_oversize','approved','rejected','failed','cancelled')),
+    iteration        INTEGER NOT NULL DEFAULT 0 CHECK(iteration >= 0),
+    max_iterations   INTEGER NOT NULL DEFAULT 3 CHECK(max_iterations > 0),
+    worktree_path    TEXT,
+    branch_name      TEXT,
+    commit_sha       TEXT,
+    human_notes      TEXT,
+    error_message    TEXT,
+    pr_url           TEXT,
+    pr_merged_at     TEXT,
+    pr_closed_at     TEXT,
+    reject_reason    TEXT,
+    created_at       TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now')),
+    updated_at       TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now')),
+    started_at       TEXT,
+    completed_at     TEXT,
+    ci_started_at    TEXT,
+    ci_completed_at  TEXT,
+    ci_status_summary TEXT,
+    parent_job_id    TEXT,
+    backport_branch  TEXT NOT NULL DEFAULT '',
+    backport_commit  TEXT NOT NULL DEFAULT '',
+    revert_commit    TEXT NOT NULL DEFAULT '',
+    bisect_good      TEXT NOT NULL DEFAULT '',
+    bisect_bad       TEXT NOT NULL DEFAULT '',
+    bisect_cmd       TEXT NOT NULL DEFAULT '',
+    bisect_fix       INTEGER NOT NULL DEFAULT 0 CHECK(bisect_fix IN (0,1)),
+    ci_stale_at      TEXT NOT NULL DEFAULT '',
+    queue_stale_at   TEXT NOT NULL DEFAULT '',
+    oversize_summary TEXT NOT NULL DEFAULT ''
+);
+
+INSERT INTO jobs_new (
+    id, autopr_issue_id, project_name, state, iteration, max_iterations,
+    worktree_path, branch_name, commit_sha, human_notes, error_message, pr_url,
+    pr_merged_at, pr_closed_at, reject_reason, created_at, updated_at, started_at, completed_at,
+    ci_started_at, ci_completed_at, ci_status_summary, parent_job_id,
+    backport_branch, backport_commit, revert_commit,
+    bisect_good, bisect_bad, bisect_cmd, bisect_fix, ci_stale_at, queue_stale_at
+)
+SELECT
+    id, autopr_issue_id, project_name, state, iteration, max_iterations,
+    worktree_path, branch_name, commit_sha, human_notes, error_message, pr_url,
+    pr_merged_at, pr_closed_at, reject_reason, created_at, updated_at, started_at, completed_at,
+    ci_started_at, ci_completed_at, ci_status_summary, parent_job_id,
+    backport_branch, backport_commit, revert_commit,
+    bisect_good, bisect_bad, bisect_cmd, bisect_fix, ci_stale_at, queue_stale_at
+FROM jobs;
+
+DROP TABLE jobs;
+ALTER TABLE jobs_new RENAME TO jobs;
+
+CREATE INDEX IF NOT EXISTS idx_jobs_state ON jobs(state);
+CREATE INDEX IF NOT EXISTS idx_jobs_issue ON jobs(autopr_issue_id);
+CREATE INDEX IF NOT EXISTS idx_jobs_state_project ON jobs(state, project_name);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_jobs_one_active_per_issue
+    ON jobs(autopr_issue_id, backport_branch)
+    WHERE state NOT IN ('approved', 'rejected', 'failed', 'cancelled');
@@ -0,0 +1,22 @@
+-- What happened to merged AutoPR PRs, recorded by the sync loop for `ap stats`:
+-- when a human first approved them, and whether they were later reverted or
+-- needed follow-up fixes.
+CREATE TABLE IF NOT EXISTS pr_outcomes (
+    job_id       TEXT PRIMARY KEY REFERENCES jobs(id) ON DELETE CASCADE,
+    project_name TEXT NOT NULL,
+    pr_url       TEXT NOT NULL,
+    opened_at    TEXT NOT NULL DEFAULT '',
+    approved_at  TEXT NOT NULL DEFAULT '',
+    merged_at    TEXT NOT NULL,
+    created_at   TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now'))
+);
+
+-- Later work that points back at a merged PR: a revert, or a follow-up fix
+-- (an AutoPR follow-up job, or an issue that links the PR).
+CREATE TABLE IF NOT EXISTS pr_outcome_refs (
+    job_id     TEXT NOT NULL REFERENCES pr_outcomes(job_id) ON DELETE CASCADE,
+    kind       TEXT NOT NULL CHECK(kind IN ('revert','followup')),
+    ref        TEXT NOT NULL,
+    created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now')),
+    PRIMARY KEY (job_id, kind, ref)
+);
@@ -0,0 +1,4 @@
+-- The retention policy deletes LLM prompt and response text after a while,
+-- keeping token counts and hashes of the text; purged_at records when.
+ALTER TABLE llm_sessions ADD COLUMN response_hash TEXT NOT NULL DEFAULT '';
+ALTER TABLE llm_sessions ADD COLUMN purged_at TEXT NOT NULL DEFAULT '';
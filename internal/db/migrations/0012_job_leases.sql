@@ -0,0 +1,8 @@
+-- A job lease gives one process (daemon worker, ap CLI, TUI) exclusive use of
+-- a job for a multi-step mutation. Leases expire so a crashed holder cannot
+-- block the job forever.
+CREATE TABLE IF NOT EXISTS job_leases (
+    job_id     TEXT PRIMARY KEY REFERENCES jobs(id) ON DELETE CASCADE,
+    holder     TEXT NOT NULL,
+    expires_at TEXT NOT NULL
+);
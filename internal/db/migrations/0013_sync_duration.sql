@@ -0,0 +1,2 @@
+-- How long each project's most recent issue sync took, successful or not.
+ALTER TABLE project_sync_status ADD COLUMN last_duration_ms INTEGER NOT NULL DEFAULT 0;
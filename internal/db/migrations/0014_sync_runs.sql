@@ -0,0 +1,10 @@
+-- One row per issue sync of a project, for the TUI's sync history. Only the
+-- newest runs of each project are kept.
+CREATE TABLE IF NOT EXISTS sync_runs (
+    id           INTEGER PRIMARY KEY AUTOINCREMENT,
+    project_name TEXT NOT NULL,
+    finished_at  TEXT NOT NULL,
+    duration_ms  INTEGER NOT NULL DEFAULT 0,
+    error        TEXT NOT NULL DEFAULT ''
+);
+CREATE INDEX IF NOT EXISTS idx_sync_runs_project ON sync_runs(project_name, id);
@@ -0,0 +1,6 @@
+-- CI check counts from the latest poll of an awaiting_checks job, shown as
+-- progress in the TUI job list.
+ALTER TABLE jobs ADD COLUMN ci_checks_total INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE jobs ADD COLUMN ci_checks_passed INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE jobs ADD COLUMN ci_checks_failed INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE jobs ADD COLUMN ci_checks_pending INTEGER NOT NULL DEFAULT 0;
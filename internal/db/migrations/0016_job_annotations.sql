@@ -0,0 +1,18 @@
+-- Freeform tags and notes a human attaches to a job with `ap tag`, `ap note`,
+-- or the TUI annotation editor. They are for filtering and reporting only and
+-- are never shown to the LLM (human_notes is).
+CREATE TABLE IF NOT EXISTS job_tags (
+    job_id     TEXT NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
+    tag        TEXT NOT NULL,
+    created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now')),
+    PRIMARY KEY (job_id, tag)
+);
+CREATE INDEX IF NOT EXISTS idx_job_tags_tag ON job_tags(tag);
+
+CREATE TABLE IF NOT EXISTS job_notes (
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    job_id     TEXT NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
+    body       TEXT NOT NULL,
+    created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now'))
+);
+CREATE INDEX IF NOT EXISTS idx_job_notes_job ON job_notes(job_id, id);
@@ -0,0 +1,16 @@
+-- Per-issue attempt counts for [projects.issue_policy]. jobs counts job runs
+-- (retries included) and failures counts failed runs since the last cooldown
+-- or clear. While hold_reason is set the issue is kept ineligible with that
+-- skip_reason, until hold_until or, when that is empty, until a human clears
+-- it; prev_eligible and prev_skip_reason are what the last sync decided, to
+-- restore when the hold ends.
+CREATE TABLE IF NOT EXISTS issue_attempts (
+    autopr_issue_id  TEXT PRIMARY KEY REFERENCES issues(autopr_issue_id) ON DELETE CASCADE,
+    jobs             INTEGER NOT NULL DEFAULT 0,
+    failures         INTEGER NOT NULL DEFAULT 0,
+    hold_reason      TEXT NOT NULL DEFAULT '',
+    hold_until       TEXT NOT NULL DEFAULT '',
+    prev_eligible    INTEGER NOT NULL DEFAULT 1 CHECK(prev_eligible IN (0,1)),
+    prev_skip_reason TEXT NOT NULL DEFAULT '',
+    updated_at       TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now'))
+);
@@ -0,0 +1,31 @@
+-- flaky_tests artifacts record tests that failed and then passed when the test
+-- command was re-run (retry_flaky_tests), and flaky_tests keeps one row per
+-- such test per run so `ap stats` can report flakiness per project.
+CREATE TABLE artifacts_new (
+    id               INTEGER PRIMARY KEY AUTOINCREMENT,
+    job_id           TEXT NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
+    autopr_issue_id  TEXT NOT NULL,
+    kind             TEXT NOT NULL CHECK(kind IN ('plan','plan_review','code_review','test_output','rebase_conflict','rebase_result','excluded_changes','bisect_result','generated_files','decisions','flaky_tests')),
+    content          TEXT NOT NULL,
+    iteration        INTEGER NOT NULL DEFAULT 0,
+    commit_sha       TEXT,
+    created_at       TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now'))
+);
+
+INSERT INTO artifacts_new (id, job_id, autopr_issue_id, kind, content, iteration, commit_sha, created_at)
+SELECT id, job_id, autopr_issue_id, kind, content, iteration, commit_sha, created_at
+FROM artifacts;
+
+DROP TABLE artifacts;
+ALTER TABLE artifacts_new RENAME TO artifacts;
+
+CREATE INDEX IF NOT EXISTS idx_artifacts_job ON artifacts(job_id);
+
+CREATE TABLE IF NOT EXISTS flaky_tests (
+    id           INTEGER PRIMARY KEY AUTOINCREMENT,
+    project_name TEXT NOT NULL,
+    test_name    TEXT NOT NULL,
+    job_id       TEXT NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
+    created_at   TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now'))
+);
+CREATE INDEX IF NOT EXISTS idx_flaky_tests_project ON flaky_tests(project_name, test_name);
@@ -0,0 +1,21 @@
+-- setup_output artifacts hold the output of a project's setup_cmd, run once
+-- in each new job worktree before planning.
+CREATE TABLE artifacts_new (
+    id               INTEGER PRIMARY KEY AUTOINCREMENT,
+    job_id           TEXT NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
+    autopr_issue_id  TEXT NOT NULL,
+    kind             TEXT NOT NULL CHECK(kind IN ('plan','plan_review','code_review','test_output','rebase_conflict','rebase_result','excluded_changes','bisect_result','generated_files','decisions','flaky_tests','setup_output')),
+    content          TEXT NOT NULL,
+    iteration        INTEGER NOT NULL DEFAULT 0,
+    commit_sha       TEXT,
+    created_at       TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now'))
+);
+
+INSERT INTO artifacts_new (id, job_id, autopr_issue_id, kind, content, iteration, commit_sha, created_at)
+SELECT id, job_id, autopr_issue_id, kind, content, iteration, commit_sha, created_at
+FROM artifacts;
+
+DROP TABLE artifacts;
+ALTER TABLE artifacts_new RENAME TO artifacts;
+
+CREATE INDEX IF NOT EXISTS idx_artifacts_job ON artifacts(job_id);
@@ -0,0 +1,28 @@
+-- Crash recovery counts how often each job was interrupted, and raises a
+-- daemon_recovered notification that belongs to no job, so job_id becomes
+-- nullable and the event carries its own message.
+ALTER TABLE jobs ADD COLUMN recoveries INTEGER NOT NULL DEFAULT 0;
+
+CREATE TABLE notification_events_new (
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    job_id     TEXT REFERENCES jobs(id) ON DELETE CASCADE,
+    event_type TEXT NOT NULL CHECK(event_type IN ('needs_pr','failed','pr_created','pr_merged','ci_stuck','queue_stale','daemon_recovered')),
+    status     TEXT NOT NULL DEFAULT 'pending' CHECK(status IN ('pending','processing','sent','failed','skipped','dead')),
+    attempts   INTEGER NOT NULL DEFAULT 0 CHECK(attempts >= 0),
+    last_error TEXT NOT NULL DEFAULT '',
+    message    TEXT NOT NULL DEFAULT '',
+    created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now')),
+    updated_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now'))
+);
+
+INSERT INTO notification_events_new (id, job_id, event_type, status, attempts, last_error, created_at, updated_at)
+SELECT id, job_id, event_type, status, attempts, last_error, created_at, updated_at
+FROM notification_events;
+
+DROP TABLE notification_events;
+ALTER TABLE notification_events_new RENAME TO notification_events;
+
+CREATE INDEX IF NOT EXISTS idx_notification_events_status_created
+    ON notification_events(status, created_at);
+CREATE INDEX IF NOT EXISTS idx_notification_events_job
+    ON notification_events(job_id);
@@ -0,0 +1,30 @@
+-- Jobs can be due: issues carry the due date synced from their source, a job
+-- may override it with `ap deadline`, and an overdue job raises one
+-- deadline_overdue notification until its deadline changes.
+ALTER TABLE issues ADD COLUMN due_at TEXT NOT NULL DEFAULT '';
+ALTER TABLE jobs ADD COLUMN deadline TEXT NOT NULL DEFAULT '';
+ALTER TABLE jobs ADD COLUMN deadline_alerted_at TEXT NOT NULL DEFAULT '';
+
+CREATE TABLE notification_events_new (
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    job_id     TEXT REFERENCES jobs(id) ON DELETE CASCADE,
+    event_type TEXT NOT NULL CHECK(event_type IN ('needs_pr','failed','pr_created','pr_merged','ci_stuck','queue_stale','daemon_recovered','deadline_overdue')),
+    status     TEXT NOT NULL DEFAULT 'pending' CHECK(status IN ('pending','processing','sent','failed','skipped','dead')),
+    attempts   INTEGER NOT NULL DEFAULT 0 CHECK(attempts >= 0),
+    last_error TEXT NOT NULL DEFAULT '',
+    message    TEXT NOT NULL DEFAULT '',
+    created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now')),
+    updated_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now'))
+);
+
+INSERT INTO notification_events_new (id, job_id, event_type, status, attempts, last_error, message, created_at, updated_at)
+SELECT id, job_id, event_type, status, attempts, last_error, message, created_at, updated_at
+FROM notification_events;
+
+DROP TABLE notification_events;
+ALTER TABLE notification_events_new RENAME TO notification_events;
+
+CREATE INDEX IF NOT EXISTS idx_notification_events_status_created
+    ON notification_events(status, created_at);
+CREATE INDEX IF NOT EXISTS idx_notification_events_job
+    ON notification_events(job_id);
@@ -0,0 +1,6 @@
+-- risk_score (0-100) rates how carefully a job's final diff needs reviewing,
+-- from critical paths touched, size, missing tests, and file churn; it is
+-- computed before the job goes ready. risk_summary lists the factors and is
+-- '' until the job has been scored.
+ALTER TABLE jobs ADD COLUMN risk_score INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE jobs ADD COLUMN risk_summary TEXT NOT NULL DEFAULT '';
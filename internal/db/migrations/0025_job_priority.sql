@@ -0,0 +1,4 @@
+-- priority orders queued jobs for claiming after overdue deadlines: higher
+-- first, then oldest first. It is set by a project's policy script when the
+-- job is queued and is 0 otherwise.
+ALTER TABLE jobs ADD COLUMN priority INTEGER NOT NULL DEFAULT 0;
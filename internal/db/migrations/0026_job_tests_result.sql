@@ -0,0 +1,5 @@
+-- tests_result is the outcome of the test step in a job's current iteration:
+-- 'passed', 'failed', or '' when tests have not run on it. It is cleared when
+-- the job starts a new iteration, so a job handed to review after its last
+-- iteration can be told apart from one whose final changes passed.
+ALTER TABLE jobs ADD COLUMN tests_result TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,3 @@
+-- pr_comment_id is the newest comment on the job's PR that has been read for
+-- /autopr commands, so each comment is acted on once. 0 means none yet.
+ALTER TABLE jobs ADD COLUMN pr_comment_id INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,6 @@
+-- Soft deletion: `ap delete` moves a finished job to the trash by setting
+-- deleted_at, which hides it from job lists. `ap trash restore` clears it,
+-- and the retention loop deletes trashed jobs for good once they are older
+-- than retention.deleted_jobs_after.
+ALTER TABLE jobs ADD COLUMN deleted_at TEXT NOT NULL DEFAULT '';
+CREATE INDEX IF NOT EXISTS idx_jobs_deleted_at ON jobs(deleted_at);
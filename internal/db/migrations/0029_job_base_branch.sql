@@ -0,0 +1,4 @@
+-- base_branch overrides the project's base branch for one job. `ap retarget`
+-- sets it when a job was started against the wrong branch; the job is rebased
+-- onto it and its PR is opened against it.
+ALTER TABLE jobs ADD COLUMN base_branch TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,4 @@
+-- signature_status records whether a signing project's job commits carry
+-- good signatures, checked before each push; "" when the project does not
+-- sign commits.
+ALTER TABLE jobs ADD COLUMN signature_status TEXT NOT NULL DEFAULT '';
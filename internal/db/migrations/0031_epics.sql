@@ -0,0 +1,29 @@
+-- An epic is an issue whose task list was split into one job per open item.
+-- epic_index is a task job's 1-based position among the items and epic_task
+-- the item's text; 0 and '' for every other job.
+ALTER TABLE jobs ADD COLUMN epic_index INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE jobs ADD COLUMN epic_task TEXT NOT NULL DEFAULT '';
+
+-- Task jobs of one epic share its issue, so each may be active at once.
+DROP INDEX IF EXISTS idx_jobs_one_active_per_issue;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_jobs_one_active_per_issue
+    ON jobs(autopr_issue_id, backport_branch, epic_index)
+    WHERE state NOT IN ('approved', 'rejected', 'failed', 'cancelled');
+
+-- A queued job is not claimed until every job it depends on has merged.
+CREATE TABLE IF NOT EXISTS job_dependencies (
+    job_id     TEXT NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
+    depends_on TEXT NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
+    PRIMARY KEY (job_id, depends_on)
+);
+CREATE INDEX IF NOT EXISTS idx_job_dependencies_depends_on ON job_dependencies(depends_on);
+
+-- epics records the issues that were split, and the status comment kept up
+-- to date on each source issue.
+CREATE TABLE IF NOT EXISTS epics (
+    autopr_issue_id   TEXT PRIMARY KEY REFERENCES issues(autopr_issue_id) ON DELETE CASCADE,
+    project_name      TEXT NOT NULL,
+    rollup_comment_id TEXT NOT NULL DEFAULT '',
+    rollup            TEXT NOT NULL DEFAULT '',
+    created_at        TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now'))
+);
@@ -0,0 +1,16 @@
+-- Lessons are guidance for a project's future jobs, learned from code reviews
+-- that asked for changes and from human feedback, or added with ap lessons.
+-- norm is the lowercased text with whitespace collapsed, so a lesson learned
+-- again counts up times_learned instead of being stored twice.
+CREATE TABLE IF NOT EXISTS lessons (
+    id            INTEGER PRIMARY KEY AUTOINCREMENT,
+    project_name  TEXT NOT NULL,
+    text          TEXT NOT NULL,
+    norm          TEXT NOT NULL,
+    source        TEXT NOT NULL CHECK(source IN ('review','feedback','manual')),
+    job_id        TEXT NOT NULL DEFAULT '',
+    times_learned INTEGER NOT NULL DEFAULT 1,
+    created_at    TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now')),
+    updated_at    TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now')),
+    UNIQUE (project_name, norm)
+);
@@ -0,0 +1,4 @@
+-- The repository docs (.autopr/context.md and the project's context_files)
+-- added to an LLM session's prompt, as a JSON array of {path, bytes,
+-- truncated}; '' when none were.
+ALTER TABLE llm_sessions ADD COLUMN context_files TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,40 @@
+-- Large diffs can be reviewed in chunks: each chunk is an LLM session with
+-- step 'review_chunk', and the pass merging their findings is the step's
+-- 'code_review' session.
+CREATE TABLE llm_sessions_new (
+    id            INTEGER PRIMARY KEY AUTOINCREMENT,
+    job_id        TEXT NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
+    step          TEXT NOT NULL CHECK(step IN ('summarize','plan','plan_review','implement','code_review','review_chunk','tests','conflict_resolution')),
+    iteration     INTEGER NOT NULL DEFAULT 0,
+    llm_provider  TEXT NOT NULL CHECK(llm_provider IN ('codex', 'claude')),
+    prompt_hash   TEXT,
+    response_text TEXT,
+    prompt_text   TEXT,
+    input_tokens  INTEGER,
+    output_tokens INTEGER,
+    duration_ms   INTEGER,
+    jsonl_path    TEXT,
+    commit_sha    TEXT,
+    status        TEXT NOT NULL DEFAULT 'running' CHECK(status IN ('running','completed','failed','cancelled')),
+    error_message TEXT,
+    created_at    TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now')),
+    completed_at  TEXT,
+    response_hash TEXT NOT NULL DEFAULT '',
+    purged_at     TEXT NOT NULL DEFAULT '',
+    context_files TEXT NOT NULL DEFAULT ''
+);
+
+INSERT INTO llm_sessions_new (id, job_id, step, iteration, llm_provider, prompt_hash, response_text, prompt_text,
+    input_tokens, output_tokens, duration_ms, jsonl_path, commit_sha, status, error_message, created_at, completed_at,
+    response_hash, purged_at, context_files)
+SELECT id, job_id, step, iteration, llm_provider, prompt_hash, response_text, prompt_text,
+    input_tokens, output_tokens, duration_ms, jsonl_path, commit_sha, status, error_message, created_at, completed_at,
+    response_hash, purged_at, context_files
+FROM llm_sessions;
+
+DROP TABLE llm_sessions;
+ALTER TABLE llm_sessions_new RENAME TO llm_sessions;
+
+CREATE INDEX IF NOT EXISTS idx_sessions_job ON llm_sessions(job_id);
+CREATE INDEX IF NOT EXISTS idx_sessions_job_iteration_step_status
+    ON llm_sessions(job_id, iteration, step, status);
@@ -0,0 +1,4 @@
+-- Resources used by the commands of a job run by the local executor: CPU
+-- time summed over them, and the peak resident memory of any one.
+ALTER TABLE jobs ADD COLUMN cpu_ms INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE jobs ADD COLUMN peak_rss_bytes INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,7 @@
+-- Fair scheduling state of each project: its pass grows by 1/weight with
+-- every job claimed, and the claimable project with the lowest pass goes
+-- next.
+CREATE TABLE IF NOT EXISTS project_claims (
+    project_name TEXT PRIMARY KEY,
+    pass         REAL NOT NULL DEFAULT 0
+);
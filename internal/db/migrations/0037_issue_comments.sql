@@ -0,0 +1,14 @@
+-- Comments on source issues, synced for projects with [projects.issue_comments]
+-- so the plan step sees the discussion, not just the title and body.
+-- comments_source_updated_at is the issue's source_updated_at when its
+-- comments were last fetched; sync fetches them again once it moves on.
+CREATE TABLE IF NOT EXISTS issue_comments (
+    autopr_issue_id TEXT NOT NULL REFERENCES issues(autopr_issue_id) ON DELETE CASCADE,
+    comment_id      TEXT NOT NULL,
+    author          TEXT NOT NULL DEFAULT '',
+    body            TEXT NOT NULL DEFAULT '',
+    created_at      TEXT NOT NULL DEFAULT '',
+    PRIMARY KEY (autopr_issue_id, comment_id)
+);
+ALTER TABLE issues ADD COLUMN comments_synced_at TEXT NOT NULL DEFAULT '';
+ALTER TABLE issues ADD COLUMN comments_source_updated_at TEXT NOT NULL DEFAULT '';
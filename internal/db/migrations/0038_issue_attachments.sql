@@ -0,0 +1,16 @@
+-- Files uploaded to source issues and their comments, downloaded for projects
+-- with [projects.attachments]. path is where the file was saved; error says
+-- why it was not, e.g. it was too large. attachments_source_updated_at is the
+-- issue's source_updated_at when its attachments were last looked for.
+CREATE TABLE IF NOT EXISTS issue_attachments (
+    autopr_issue_id TEXT NOT NULL REFERENCES issues(autopr_issue_id) ON DELETE CASCADE,
+    url             TEXT NOT NULL,
+    name            TEXT NOT NULL DEFAULT '',
+    kind            TEXT NOT NULL DEFAULT '',
+    path            TEXT NOT NULL DEFAULT '',
+    size_bytes      INTEGER NOT NULL DEFAULT 0,
+    error           TEXT NOT NULL DEFAULT '',
+    fetched_at      TEXT NOT NULL DEFAULT '',
+    PRIMARY KEY (autopr_issue_id, url)
+);
+ALTER TABLE issues ADD COLUMN attachments_source_updated_at TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,13 @@
+-- Languages, frameworks, and build tools detected in each project's
+-- repository by the sync loop (see internal/stack). checked_at is the last
+-- detection attempt; error is why it failed, "" when it succeeded.
+CREATE TABLE IF NOT EXISTS project_stacks (
+    project_name    TEXT PRIMARY KEY,
+    languages_json  TEXT NOT NULL DEFAULT '[]',
+    frameworks_json TEXT NOT NULL DEFAULT '[]',
+    test_cmd        TEXT NOT NULL DEFAULT '',
+    lint_cmd        TEXT NOT NULL DEFAULT '',
+    detected_at     TEXT NOT NULL DEFAULT '',
+    checked_at      TEXT NOT NULL DEFAULT '',
+    error           TEXT NOT NULL DEFAULT ''
+);
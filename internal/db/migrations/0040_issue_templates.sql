@@ -0,0 +1,9 @@
+-- Issues created by `ap run --template` from a [[templates]] entry. test_cmd
+-- and lint_cmd are the template's gates with its variables filled in; ""
+-- leaves the project's own command.
+CREATE TABLE IF NOT EXISTS issue_templates (
+    autopr_issue_id TEXT PRIMARY KEY REFERENCES issues(autopr_issue_id) ON DELETE CASCADE,
+    template        TEXT NOT NULL,
+    test_cmd        TEXT NOT NULL DEFAULT '',
+    lint_cmd        TEXT NOT NULL DEFAULT ''
+);
@@ -0,0 +1,4 @@
+-- base_sha is HEAD of the job's worktree when an LLM session started, so
+-- base_sha..commit_sha is exactly what the session's step changed. '' for
+-- sessions recorded before it was kept, or run outside a git worktree.
+ALTER TABLE llm_sessions ADD COLUMN base_sha TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,7 @@
+-- The fair scheduler's virtual time: the lowest pass among the projects
+-- with claimable jobs at the last claim. A project that comes back after
+-- its queue was empty resumes from here instead of its old, lower pass.
+CREATE TABLE IF NOT EXISTS claim_clock (
+    id    INTEGER PRIMARY KEY CHECK (id = 1),
+    vtime REAL NOT NULL DEFAULT 0
+);
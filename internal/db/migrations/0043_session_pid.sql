@@ -0,0 +1,5 @@
+-- pid is the process on the daemon host that runs an LLM session's CLI (the
+-- CLI itself, or the docker or ssh client driving it), so ap fsck can tell a
+-- session marked running from one whose process is gone. 0 when none was
+-- recorded, such as for Kubernetes pods.
+ALTER TABLE llm_sessions ADD COLUMN pid INTEGER NOT NULL DEFAULT 0;
@@ -13,6 +13,12 @@ const (
 	NotificationEventFailed   = "failed"
 	NotificationEventPRCreated = "pr_created"
 	NotificationEventPRMerged  = "pr_merged"
+	NotificationEventCIStuck   = "ci_stuck"
+	NotificationEventQueueStale = "queue_stale"
+	// NotificationEventDaemonRecovered belongs to no job; its message
+	// summarizes what crash recovery did.
+	NotificationEventDaemonRecovered = "daemon_recovered"
+	NotificationEventDeadlineOverdue = "deadline_overdue"
 )
 
 const (
@@ -21,21 +27,34 @@ const (
 	NotificationStatusSent       = "sent"
 	NotificationStatusFailed     = "failed"
 	NotificationStatusSkipped    = "skipped"
+	NotificationStatusDead       = "dead" // a channel exhausted its attempts; retry with `ap notifications retry`
 )
 
 const recoveredNotificationEventError = "notification dispatcher restarted while event was processing"
 
 type NotificationEvent struct {
 	ID        int64
-	JobID     string
+	JobID     string // empty for daemon events
 	EventType string
 	Status    string
 	Attempts  int
 	LastError string
+	Message   string // text of daemon events
 	CreatedAt string
 	UpdatedAt string
 }
 
+// NotificationDelivery is the delivery state of one event on one channel.
+// Channel status is pending, sent, failed (will be retried), or dead.
+type NotificationDelivery struct {
+	EventID   int64  `json:"event_id"`
+	Channel   string `json:"channel"`
+	Status    string `json:"status"`
+	Attempts  int    `json:"attempts"`
+	LastError string `json:"last_error,omitempty"`
+	UpdatedAt string `json:"updated_at"`
+}
+
 func (s *Store) EnqueueNotificationEvent(ctx context.Context, jobID, eventType string) (int64, error) {
 	if err := validateNotificationEventType(eventType); err != nil {
 		return 0, err
@@ -53,6 +72,25 @@ VALUES(?, ?, 'pending')`, jobID, eventType)
 	return id, nil
 }
 
+// EnqueueDaemonNotificationEvent queues an event about the daemon itself
+// rather than a job, with message as its text.
+func (s *Store) EnqueueDaemonNotificationEvent(ctx context.Context, eventType, message string) (int64, error) {
+	if err := validateNotificationEventType(eventType); err != nil {
+		return 0, err
+	}
+	res, err := s.Writer.ExecContext(ctx, `
+INSERT INTO notification_events(job_id, event_type, status, message)
+VALUES(NULL, ?, 'pending', ?)`, eventType, message)
+	if err != nil {
+		return 0, fmt.Errorf("enqueue %s notification event: %w", eventType, err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("enqueue %s notification event: %w", eventType, err)
+	}
+	return id, nil
+}
+
 func enqueueNotificationEventTx(ctx context.Context, tx *sql.Tx, jobID, eventType string) error {
 	if eventType == "" {
 		return nil
@@ -70,7 +108,7 @@ VALUES(?, ?, 'pending')`, jobID, eventType); err != nil {
 
 func (s *Store) ListNotificationEvents(ctx context.Context, status string, limit int) ([]NotificationEvent, error) {
 	q := `
-SELECT id, job_id, event_type, status, attempts, COALESCE(last_error, ''), created_at, updated_at
+SELECT id, COALESCE(job_id, ''), event_type, status, attempts, COALESCE(last_error, ''), message, created_at, updated_at
 FROM notification_events`
 	args := make([]any, 0, 2)
 	if status != "" {
@@ -99,6 +137,7 @@ FROM notification_events`
 			&event.Status,
 			&event.Attempts,
 			&event.LastError,
+			&event.Message,
 			&event.CreatedAt,
 			&event.UpdatedAt,
 		); err != nil {
@@ -139,7 +178,7 @@ WHERE id = (
 	ORDER BY created_at ASC
 	LIMIT 1
 )
-RETURNING id, job_id, event_type, status, attempts, COALESCE(last_error, ''), created_at, updated_at`
+RETURNING id, COALESCE(job_id, ''), event_type, status, attempts, COALESCE(last_error, ''), message, created_at, updated_at`
 
 	var event NotificationEvent
 	err := s.Writer.QueryRowContext(ctx, q, maxAttempts).Scan(
@@ -149,6 +188,7 @@ RETURNING id, job_id, event_type, status, attempts, COALESCE(last_error, ''), cr
 		&event.Status,
 		&event.Attempts,
 		&event.LastError,
+		&event.Message,
 		&event.CreatedAt,
 		&event.UpdatedAt,
 	)
@@ -188,6 +228,21 @@ WHERE id = ?`, trimNotificationError(lastError), id)
 	return nil
 }
 
+// RequeueNotificationEvent returns a claimed event to pending without counting
+// an attempt, for deliveries that failed only because the network was down.
+func (s *Store) RequeueNotificationEvent(ctx context.Context, id int64, lastError string) error {
+	_, err := s.Writer.ExecContext(ctx, `
+UPDATE notification_events
+SET status = 'pending',
+    last_error = ?,
+    updated_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now')
+WHERE id = ?`, trimNotificationError(lastError), id)
+	if err != nil {
+		return fmt.Errorf("requeue notification event %d: %w", id, err)
+	}
+	return nil
+}
+
 func (s *Store) MarkNotificationEventSkipped(ctx context.Context, id int64, reason string) error {
 	_, err := s.Writer.ExecContext(ctx, `
 UPDATE notification_events
@@ -218,13 +273,29 @@ WHERE status = 'processing'`, recoveredNotificationEventError)
 	return res.RowsAffected()
 }
 
-func (s *Store) SkipExhaustedNotificationEvents(ctx context.Context, maxAttempts int) (int64, error) {
+func (s *Store) MarkNotificationEventDead(ctx context.Context, id int64, lastError string) error {
+	_, err := s.Writer.ExecContext(ctx, `
+UPDATE notification_events
+SET status = 'dead',
+    attempts = attempts + 1,
+    last_error = ?,
+    updated_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now')
+WHERE id = ?`, trimNotificationError(lastError), id)
+	if err != nil {
+		return fmt.Errorf("mark notification event %d dead: %w", id, err)
+	}
+	return nil
+}
+
+// DeadLetterExhaustedNotificationEvents moves failed events that have used up
+// their attempts to dead, where they wait for `ap notifications retry`.
+func (s *Store) DeadLetterExhaustedNotificationEvents(ctx context.Context, maxAttempts int) (int64, error) {
 	if maxAttempts <= 0 {
 		return 0, nil
 	}
 	res, err := s.Writer.ExecContext(ctx, `
 UPDATE notification_events
-SET status = 'skipped',
+SET status = 'dead',
     updated_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now'),
     last_error = CASE
 		WHEN last_error = '' THEN 'max attempts reached'
@@ -232,11 +303,150 @@ SET status = 'skipped',
 	END
 WHERE status = 'failed' AND attempts >= ?`, maxAttempts)
 	if err != nil {
-		return 0, fmt.Errorf("skip exhausted notification events: %w", err)
+		return 0, fmt.Errorf("dead-letter exhausted notification events: %w", err)
 	}
 	return res.RowsAffected()
 }
 
+// RecordNotificationDelivery records one send attempt of an event on a
+// channel and returns the channel's new status: sent on success, otherwise
+// failed, or dead once the channel has failed maxAttempts times.
+func (s *Store) RecordNotificationDelivery(ctx context.Context, eventID int64, channel string, success bool, lastError string, maxAttempts int) (string, error) {
+	if maxAttempts <= 0 {
+		maxAttempts = 1
+	}
+	if success {
+		lastError = ""
+	} else {
+		lastError = trimNotificationError(lastError)
+	}
+	var status string
+	err := s.Writer.QueryRowContext(ctx, `
+INSERT INTO notification_deliveries(event_id, channel, status, attempts, last_error)
+VALUES(?1, ?2, CASE WHEN ?3 THEN 'sent' WHEN ?4 <= 1 THEN 'dead' ELSE 'failed' END, 1, ?5)
+ON CONFLICT(event_id, channel) DO UPDATE SET
+    status = CASE
+		WHEN ?3 THEN 'sent'
+		WHEN notification_deliveries.attempts + 1 >= ?4 THEN 'dead'
+		ELSE 'failed'
+	END,
+    attempts = notification_deliveries.attempts + 1,
+    last_error = excluded.last_error,
+    updated_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now')
+RETURNING status`, eventID, channel, success, maxAttempts, lastError).Scan(&status)
+	if err != nil {
+		return "", fmt.Errorf("record %s delivery for notification event %d: %w", channel, eventID, err)
+	}
+	return status, nil
+}
+
+// ListNotificationDeliveries returns the per-channel delivery state of an event.
+func (s *Store) ListNotificationDeliveries(ctx context.Context, eventID int64) ([]NotificationDelivery, error) {
+	rows, err := s.Reader.QueryContext(ctx, `
+SELECT event_id, channel, status, attempts, last_error, updated_at
+FROM notification_deliveries
+WHERE event_id = ?
+ORDER BY channel ASC`, eventID)
+	if err != nil {
+		return nil, fmt.Errorf("list deliveries for notification event %d: %w", eventID, err)
+	}
+	defer rows.Close()
+
+	var out []NotificationDelivery
+	for rows.Next() {
+		var d NotificationDelivery
+		if err := rows.Scan(&d.EventID, &d.Channel, &d.Status, &d.Attempts, &d.LastError, &d.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("scan notification delivery: %w", err)
+		}
+		out = append(out, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list deliveries for notification event %d: %w", eventID, err)
+	}
+	return out, nil
+}
+
+// CountNotificationEventsByStatus returns the number of events per status.
+func (s *Store) CountNotificationEventsByStatus(ctx context.Context) (map[string]int, error) {
+	rows, err := s.Reader.QueryContext(ctx, `SELECT status, COUNT(*) FROM notification_events GROUP BY status`)
+	if err != nil {
+		return nil, fmt.Errorf("count notification events: %w", err)
+	}
+	defer rows.Close()
+
+	counts := map[string]int{}
+	for rows.Next() {
+		var status string
+		var n int
+		if err := rows.Scan(&status, &n); err != nil {
+			return nil, fmt.Errorf("scan notification event count: %w", err)
+		}
+		counts[status] = n
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("count notification events: %w", err)
+	}
+	return counts, nil
+}
+
+// OldestUndeliveredNotificationAt returns when the oldest event still waiting
+// for delivery (pending, processing, or failed) was created, or "" if none.
+func (s *Store) OldestUndeliveredNotificationAt(ctx context.Context) (string, error) {
+	var createdAt sql.NullString
+	err := s.Reader.QueryRowContext(ctx, `
+SELECT MIN(created_at) FROM notification_events
+WHERE status IN ('pending', 'processing', 'failed')`).Scan(&createdAt)
+	if err != nil {
+		return "", fmt.Errorf("oldest undelivered notification: %w", err)
+	}
+	return createdAt.String, nil
+}
+
+// RetryNotificationEvents returns failed and dead events to pending with
+// fresh attempts so the daemon delivers them again. Channels that already
+// delivered an event are not re-sent. With no ids, every failed and dead
+// event is retried. It returns the number of events requeued.
+func (s *Store) RetryNotificationEvents(ctx context.Context, ids []int64) (int64, error) {
+	where := `status IN ('failed', 'dead')`
+	args := make([]any, 0, len(ids))
+	if len(ids) > 0 {
+		where += ` AND id IN (?` + strings.Repeat(`, ?`, len(ids)-1) + `)`
+		for _, id := range ids {
+			args = append(args, id)
+		}
+	}
+
+	tx, err := s.Writer.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("retry notification events: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `
+UPDATE notification_deliveries
+SET status = 'pending',
+    attempts = 0,
+    updated_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now')
+WHERE status IN ('failed', 'dead')
+  AND event_id IN (SELECT id FROM notification_events WHERE `+where+`)`, args...); err != nil {
+		return 0, fmt.Errorf("retry notification deliveries: %w", err)
+	}
+	res, err := tx.ExecContext(ctx, `
+UPDATE notification_events
+SET status = 'pending',
+    attempts = 0,
+    updated_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now')
+WHERE `+where, args...)
+	if err != nil {
+		return 0, fmt.Errorf("retry notification events: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("retry notification events: %w", err)
+	}
+	return n, nil
+}
+
 func (s *Store) DeleteOldNotificationEvents(ctx context.Context, olderThan time.Duration) (int64, error) {
 	if olderThan <= 0 {
 		return 0, nil
@@ -254,7 +464,7 @@ WHERE status IN ('sent', 'skipped')
 
 func validateNotificationEventType(eventType string) error {
 	switch eventType {
-	case NotificationEventNeedsPR, NotificationEventFailed, NotificationEventPRCreated, NotificationEventPRMerged:
+	case NotificationEventNeedsPR, NotificationEventFailed, NotificationEventPRCreated, NotificationEventPRMerged, NotificationEventCIStuck, NotificationEventQueueStale, NotificationEventDaemonRecovered, NotificationEventDeadlineOverdue:
 		return nil
 	default:
 		return fmt.Errorf("unsupported notification event type %q", eventType)
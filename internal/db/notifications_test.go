@@ -224,12 +224,12 @@ WHERE id = ?`, eventID); err != nil {
 			t.Fatalf("mark failed %d: %v", i, err)
 		}
 	}
-	skipped, err := store.SkipExhaustedNotificationEvents(ctx, 3)
+	dead, err := store.DeadLetterExhaustedNotificationEvents(ctx, 3)
 	if err != nil {
-		t.Fatalf("skip exhausted: %v", err)
+		t.Fatalf("dead-letter exhausted: %v", err)
 	}
-	if skipped != 1 {
-		t.Fatalf("expected 1 skipped exhausted event, got %d", skipped)
+	if dead != 1 {
+		t.Fatalf("expected 1 dead-lettered exhausted event, got %d", dead)
 	}
 
 	jobID3 := createTestJobWithState(t, ctx, store, "922", "failed", "", "", "", "")
@@ -248,3 +248,93 @@ WHERE id = ?`, eventID); err != nil {
 		t.Fatalf("expected 1 recovered processing event, got %d", recovered)
 	}
 }
+
+func TestRetryNotificationEventsResetsDeadChannels(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	store, err := Open(filepath.Join(t.TempDir(), "autopr.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer store.Close()
+
+	jobID := createTestJobWithState(t, ctx, store, "930", "failed", "", "", "", "")
+	eventID, err := store.EnqueueNotificationEvent(ctx, jobID, NotificationEventFailed)
+	if err != nil {
+		t.Fatalf("enqueue event: %v", err)
+	}
+
+	if status, err := store.RecordNotificationDelivery(ctx, eventID, "webhook", true, "", 2); err != nil || status != NotificationStatusSent {
+		t.Fatalf("record webhook delivery: status=%q err=%v", status, err)
+	}
+	if status, err := store.RecordNotificationDelivery(ctx, eventID, "slack", false, "HTTP 500", 2); err != nil || status != NotificationStatusFailed {
+		t.Fatalf("record first slack failure: status=%q err=%v", status, err)
+	}
+	if status, err := store.RecordNotificationDelivery(ctx, eventID, "slack", false, "HTTP 500", 2); err != nil || status != NotificationStatusDead {
+		t.Fatalf("record second slack failure: status=%q err=%v", status, err)
+	}
+	if err := store.MarkNotificationEventDead(ctx, eventID, "slack: HTTP 500"); err != nil {
+		t.Fatalf("mark dead: %v", err)
+	}
+
+	n, err := store.RetryNotificationEvents(ctx, []int64{eventID})
+	if err != nil {
+		t.Fatalf("retry: %v", err)
+	}
+	if n != 1 {
+		t.Fatalf("expected 1 retried event, got %d", n)
+	}
+
+	event, ok, err := store.ClaimNextNotificationEvent(ctx, 2)
+	if err != nil || !ok || event.ID != eventID {
+		t.Fatalf("expected retried event to be claimable, ok=%v err=%v", ok, err)
+	}
+	deliveries, err := store.ListNotificationDeliveries(ctx, eventID)
+	if err != nil {
+		t.Fatalf("list deliveries: %v", err)
+	}
+	got := map[string]NotificationDelivery{}
+	for _, d := range deliveries {
+		got[d.Channel] = d
+	}
+	if got["webhook"].Status != NotificationStatusSent {
+		t.Fatalf("expected delivered channel to stay sent, got %+v", got["webhook"])
+	}
+	if got["slack"].Status != NotificationStatusPending || got["slack"].Attempts != 0 {
+		t.Fatalf("expected dead channel reset to pending, got %+v", got["slack"])
+	}
+
+	if n, err := store.RetryNotificationEvents(ctx, nil); err != nil || n != 0 {
+		t.Fatalf("expected nothing left to retry, n=%d err=%v", n, err)
+	}
+}
+
+func TestDaemonNotificationEventHasNoJob(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	store, err := Open(filepath.Join(t.TempDir(), "autopr.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer store.Close()
+
+	if _, err := store.EnqueueDaemonNotificationEvent(ctx, NotificationEventDaemonRecovered, "requeued 2 jobs"); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	event, ok, err := store.ClaimNextNotificationEvent(ctx, 5)
+	if err != nil || !ok {
+		t.Fatalf("claim: ok=%v err=%v", ok, err)
+	}
+	if event.JobID != "" || event.EventType != NotificationEventDaemonRecovered || event.Message != "requeued 2 jobs" {
+		t.Fatalf("unexpected event %+v", event)
+	}
+	if err := store.MarkNotificationEventSent(ctx, event.ID); err != nil {
+		t.Fatalf("mark sent: %v", err)
+	}
+	events, err := store.ListNotificationEvents(ctx, NotificationStatusSent, 0)
+	if err != nil || len(events) != 1 || events[0].Message != "requeued 2 jobs" {
+		t.Fatalf("expected the sent event listed, got %+v, %v", events, err)
+	}
+}
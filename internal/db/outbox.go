@@ -0,0 +1,195 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Outbox operations are network-bound actions deferred while a project's
+// forge is unreachable. The daemon replays them once connectivity returns.
+const (
+	OutboxOpResumeJob = "resume_job" // requeue a waiting_network job
+	OutboxOpCreatePR  = "create_pr"  // push and open the PR for a waiting_network job
+	OutboxOpMergePR   = "merge_pr"   // merge an approved job's PR; payload is the merge method
+)
+
+const (
+	OutboxStatusPending = "pending"
+	OutboxStatusDone    = "done"
+	OutboxStatusFailed  = "failed"
+)
+
+type OutboxOp struct {
+	ID        int64
+	JobID     string
+	Kind      string
+	Payload   string
+	Status    string
+	Attempts  int
+	LastError string
+	CreatedAt string
+	UpdatedAt string
+}
+
+// EnqueueOutboxOp records a deferred operation for a job. At most one op of
+// each kind is pending per job; enqueueing a duplicate is a no-op and returns
+// false.
+func (s *Store) EnqueueOutboxOp(ctx context.Context, jobID, kind, payload string) (bool, error) {
+	if err := validateOutboxOpKind(kind); err != nil {
+		return false, err
+	}
+	res, err := s.Writer.ExecContext(ctx, `
+INSERT OR IGNORE INTO outbox_ops(job_id, kind, payload, status)
+VALUES(?, ?, ?, 'pending')`, jobID, kind, payload)
+	if err != nil {
+		return false, fmt.Errorf("enqueue %s op for job %s: %w", kind, jobID, err)
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// DeferJobForNetwork atomically moves a job from `from` to waiting_network,
+// records reason as its error message, and enqueues the outbox op that will
+// continue it once connectivity returns.
+func (s *Store) DeferJobForNetwork(ctx context.Context, jobID, from, kind, reason string) error {
+	if !slices.Contains(ValidTransitions[from], "waiting_network") {
+		return fmt.Errorf("invalid transition: %s -> waiting_network", from)
+	}
+	if err := validateOutboxOpKind(kind); err != nil {
+		return err
+	}
+	tx, err := s.Writer.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("defer job %s for network: %w", jobID, err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `
+UPDATE jobs SET state = 'waiting_network', error_message = ?,
+	updated_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now')
+WHERE id = ? AND state = ?`, reason, jobID, from)
+	if err != nil {
+		return fmt.Errorf("defer job %s for network: %w", jobID, err)
+	}
+	n, _ := res.RowsAffected()
+	if n == 0 {
+		return fmt.Errorf("job %s not in state %s (concurrent modification?)", jobID, from)
+	}
+	if _, err := tx.ExecContext(ctx, `
+INSERT OR IGNORE INTO outbox_ops(job_id, kind, status)
+VALUES(?, ?, 'pending')`, jobID, kind); err != nil {
+		return fmt.Errorf("defer job %s for network: enqueue %s op: %w", jobID, kind, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("defer job %s for network: %w", jobID, err)
+	}
+	return nil
+}
+
+// ListPendingOutboxOps returns pending ops oldest first.
+func (s *Store) ListPendingOutboxOps(ctx context.Context) ([]OutboxOp, error) {
+	rows, err := s.Reader.QueryContext(ctx, `
+SELECT id, job_id, kind, payload, status, attempts, last_error, created_at, updated_at
+FROM outbox_ops
+WHERE status = 'pending'
+ORDER BY id ASC`)
+	if err != nil {
+		return nil, fmt.Errorf("list pending outbox ops: %w", err)
+	}
+	defer rows.Close()
+
+	var out []OutboxOp
+	for rows.Next() {
+		var op OutboxOp
+		if err := rows.Scan(&op.ID, &op.JobID, &op.Kind, &op.Payload, &op.Status, &op.Attempts, &op.LastError, &op.CreatedAt, &op.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("scan outbox op: %w", err)
+		}
+		out = append(out, op)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list pending outbox ops: %w", err)
+	}
+	return out, nil
+}
+
+// CountPendingOutboxOps returns the number of pending ops.
+func (s *Store) CountPendingOutboxOps(ctx context.Context) (int, error) {
+	var n int
+	err := s.Reader.QueryRowContext(ctx, `SELECT COUNT(*) FROM outbox_ops WHERE status = 'pending'`).Scan(&n)
+	if err != nil && err != sql.ErrNoRows {
+		return 0, fmt.Errorf("count pending outbox ops: %w", err)
+	}
+	return n, nil
+}
+
+// RecordOutboxOpAttempt notes a failed attempt that should be retried.
+func (s *Store) RecordOutboxOpAttempt(ctx context.Context, id int64, lastError string) error {
+	_, err := s.Writer.ExecContext(ctx, `
+UPDATE outbox_ops
+SET attempts = attempts + 1,
+    last_error = ?,
+    updated_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now')
+WHERE id = ?`, trimNotificationError(lastError), id)
+	if err != nil {
+		return fmt.Errorf("record outbox op %d attempt: %w", id, err)
+	}
+	return nil
+}
+
+// MarkOutboxOpDone marks an op as completed.
+func (s *Store) MarkOutboxOpDone(ctx context.Context, id int64) error {
+	_, err := s.Writer.ExecContext(ctx, `
+UPDATE outbox_ops
+SET status = 'done',
+    attempts = attempts + 1,
+    last_error = '',
+    updated_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now')
+WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("mark outbox op %d done: %w", id, err)
+	}
+	return nil
+}
+
+// MarkOutboxOpFailed marks an op as permanently failed.
+func (s *Store) MarkOutboxOpFailed(ctx context.Context, id int64, lastError string) error {
+	_, err := s.Writer.ExecContext(ctx, `
+UPDATE outbox_ops
+SET status = 'failed',
+    attempts = attempts + 1,
+    last_error = ?,
+    updated_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now')
+WHERE id = ?`, trimNotificationError(lastError), id)
+	if err != nil {
+		return fmt.Errorf("mark outbox op %d failed: %w", id, err)
+	}
+	return nil
+}
+
+// DeleteOldOutboxOps removes finished ops last updated before olderThan.
+func (s *Store) DeleteOldOutboxOps(ctx context.Context, olderThan time.Duration) (int64, error) {
+	if olderThan <= 0 {
+		return 0, nil
+	}
+	cutoff := time.Now().UTC().Add(-olderThan).Format(time.RFC3339)
+	res, err := s.Writer.ExecContext(ctx, `
+DELETE FROM outbox_ops
+WHERE status IN ('done', 'failed')
+  AND updated_at < ?`, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("delete old outbox ops: %w", err)
+	}
+	return res.RowsAffected()
+}
+
+func validateOutboxOpKind(kind string) error {
+	switch kind {
+	case OutboxOpResumeJob, OutboxOpCreatePR, OutboxOpMergePR:
+		return nil
+	default:
+		return fmt.Errorf("unsupported outbox op kind %q", kind)
+	}
+}
@@ -0,0 +1,68 @@
+package db
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+func TestDeferJobForNetworkEnqueuesOnce(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	store, err := Open(filepath.Join(t.TempDir(), "autopr.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer store.Close()
+
+	jobID := createTestJobWithState(t, ctx, store, "net-1", "implementing", "autopr/net-1", "", "", "")
+	if err := store.DeferJobForNetwork(ctx, jobID, "implementing", OutboxOpResumeJob, "dial tcp: no route to host"); err != nil {
+		t.Fatalf("defer: %v", err)
+	}
+	job, err := store.GetJob(ctx, jobID)
+	if err != nil {
+		t.Fatalf("get job: %v", err)
+	}
+	if job.State != "waiting_network" || job.ErrorMessage != "dial tcp: no route to host" {
+		t.Fatalf("unexpected job after defer: state=%q error=%q", job.State, job.ErrorMessage)
+	}
+	if err := store.DeferJobForNetwork(ctx, jobID, "implementing", OutboxOpResumeJob, "again"); err == nil {
+		t.Fatal("expected defer from stale state to fail")
+	}
+
+	added, err := store.EnqueueOutboxOp(ctx, jobID, OutboxOpResumeJob, "")
+	if err != nil {
+		t.Fatalf("enqueue duplicate: %v", err)
+	}
+	if added {
+		t.Fatal("expected duplicate pending op to be ignored")
+	}
+
+	ops, err := store.ListPendingOutboxOps(ctx)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(ops) != 1 || ops[0].JobID != jobID || ops[0].Kind != OutboxOpResumeJob {
+		t.Fatalf("unexpected pending ops: %+v", ops)
+	}
+
+	if err := store.RecordOutboxOpAttempt(ctx, ops[0].ID, "still offline"); err != nil {
+		t.Fatalf("record attempt: %v", err)
+	}
+	if err := store.MarkOutboxOpDone(ctx, ops[0].ID); err != nil {
+		t.Fatalf("mark done: %v", err)
+	}
+	if n, err := store.CountPendingOutboxOps(ctx); err != nil || n != 0 {
+		t.Fatalf("expected no pending ops, got %d (%v)", n, err)
+	}
+
+	// A finished op no longer blocks a new one of the same kind.
+	added, err = store.EnqueueOutboxOp(ctx, jobID, OutboxOpResumeJob, "")
+	if err != nil || !added {
+		t.Fatalf("expected new op after previous finished, added=%v err=%v", added, err)
+	}
+
+	if err := store.TransitionState(ctx, jobID, "waiting_network", "queued"); err != nil {
+		t.Fatalf("waiting_network -> queued: %v", err)
+	}
+}
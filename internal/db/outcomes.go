@@ -0,0 +1,192 @@
+package db
+
+import (
+	"context"
+	"fmt"
+)
+
+// PROutcome is what happened to a merged AutoPR PR after it left AutoPR's
+// hands, as recorded by the sync loop.
+type PROutcome struct {
+	JobID         string
+	AutoPRIssueID string // issue the job worked on; filled by ListPROutcomes
+	ProjectName   string
+	PRURL         string
+	OpenedAt      string // RFC3339; empty when the forge did not report it
+	ApprovedAt    string // first approving review, or the merge time when none was found
+	MergedAt      string
+	Reverted      bool // a revert of the PR is known
+	FollowUps     int  // follow-up fixes that point back at the PR
+	Tags          []string
+}
+
+// PRCounts is the number of AutoPR PRs opened, merged, and closed unmerged.
+type PRCounts struct {
+	Opened int
+	Merged int
+	Closed int
+}
+
+// RecordPROutcome stores the review timeline of a merged PR. The first
+// record for a job wins, so re-polling a merged PR changes nothing.
+func (s *Store) RecordPROutcome(ctx context.Context, o PROutcome) error {
+	_, err := s.Writer.ExecContext(ctx, `
+INSERT INTO pr_outcomes(job_id, project_name, pr_url, opened_at, approved_at, merged_at)
+VALUES(?,?,?,?,?,?)
+ON CONFLICT(job_id) DO NOTHING`, o.JobID, o.ProjectName, o.PRURL, o.OpenedAt, o.ApprovedAt, o.MergedAt)
+	if err != nil {
+		return fmt.Errorf("record PR outcome for %s: %w", o.JobID, err)
+	}
+	return nil
+}
+
+// AddPROutcomeRef links later work (kind "revert" or "followup") to a merged
+// PR's outcome. It reports whether the link is new.
+func (s *Store) AddPROutcomeRef(ctx context.Context, jobID, kind, ref string) (bool, error) {
+	res, err := s.Writer.ExecContext(ctx, `
+INSERT OR IGNORE INTO pr_outcome_refs(job_id, kind, ref)
+SELECT job_id, ?, ? FROM pr_outcomes WHERE job_id = ?`, kind, ref, jobID)
+	if err != nil {
+		return false, fmt.Errorf("add PR outcome ref for %s: %w", jobID, err)
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// LinkChildJobOutcomes links revert and follow-up jobs to the outcome of the
+// merged job they were created from. Backports are not follow-up fixes and
+// are skipped. It returns how many new links were made.
+func (s *Store) LinkChildJobOutcomes(ctx context.Context) (int64, error) {
+	res, err := s.Writer.ExecContext(ctx, `
+INSERT OR IGNORE INTO pr_outcome_refs(job_id, kind, ref)
+SELECT j.parent_job_id,
+       CASE WHEN COALESCE(j.revert_commit,'') != '' THEN 'revert' ELSE 'followup' END,
+       'job:' || j.id
+FROM jobs j
+JOIN pr_outcomes o ON o.job_id = j.parent_job_id
+WHERE COALESCE(j.backport_commit,'') = ''`)
+	if err != nil {
+		return 0, fmt.Errorf("link child job outcomes: %w", err)
+	}
+	return res.RowsAffected()
+}
+
+// ListPROutcomes returns recorded outcomes, optionally limited to one project
+// and to PRs merged at or after since (RFC3339), oldest merge first.
+// excludeDeleted leaves out jobs in the trash.
+func (s *Store) ListPROutcomes(ctx context.Context, project, since string, excludeDeleted bool) ([]PROutcome, error) {
+	q := `
+SELECT o.job_id, j.autopr_issue_id, o.project_name, o.pr_url, o.opened_at, o.approved_at, o.merged_at,
+       EXISTS(SELECT 1 FROM pr_outcome_refs r WHERE r.job_id = o.job_id AND r.kind = 'revert'),
+       (SELECT COUNT(*) FROM pr_outcome_refs r WHERE r.job_id = o.job_id AND r.kind = 'followup'),
+       ` + jobTagsColumn + `
+FROM pr_outcomes o
+JOIN jobs j ON j.id = o.job_id
+WHERE 1=1`
+	var args []any
+	if project != "" {
+		q += ` AND o.project_name = ?`
+		args = append(args, project)
+	}
+	if since != "" {
+		q += ` AND o.merged_at >= ?`
+		args = append(args, since)
+	}
+	if excludeDeleted {
+		q += ` AND j.deleted_at = ''`
+	}
+	q += ` ORDER BY o.merged_at ASC`
+
+	rows, err := s.Reader.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list PR outcomes: %w", err)
+	}
+	defer rows.Close()
+
+	var out []PROutcome
+	for rows.Next() {
+		var (
+			o    PROutcome
+			tags string
+		)
+		if err := rows.Scan(&o.JobID, &o.AutoPRIssueID, &o.ProjectName, &o.PRURL, &o.OpenedAt, &o.ApprovedAt, &o.MergedAt, &o.Reverted, &o.FollowUps, &tags); err != nil {
+			return nil, fmt.Errorf("scan PR outcome: %w", err)
+		}
+		o.Tags = splitTags(tags)
+		out = append(out, o)
+	}
+	return out, rows.Err()
+}
+
+// CountPRs counts jobs that opened a PR, optionally limited to one project and
+// to jobs created at or after since (RFC3339). excludeDeleted leaves out jobs
+// in the trash.
+func (s *Store) CountPRs(ctx context.Context, project, since string, excludeDeleted bool) (PRCounts, error) {
+	q := `
+SELECT COUNT(*),
+       COALESCE(SUM(CASE WHEN COALESCE(pr_merged_at,'') != '' THEN 1 ELSE 0 END), 0),
+       COALESCE(SUM(CASE WHEN COALESCE(pr_closed_at,'') != '' AND COALESCE(pr_merged_at,'') = '' THEN 1 ELSE 0 END), 0)
+FROM jobs
+WHERE COALESCE(pr_url,'') != ''`
+	var args []any
+	if project != "" {
+		q += ` AND project_name = ?`
+		args = append(args, project)
+	}
+	if since != "" {
+		q += ` AND created_at >= ?`
+		args = append(args, since)
+	}
+	if excludeDeleted {
+		q += ` AND deleted_at = ''`
+	}
+	var c PRCounts
+	if err := s.Reader.QueryRowContext(ctx, q, args...).Scan(&c.Opened, &c.Merged, &c.Closed); err != nil {
+		return PRCounts{}, fmt.Errorf("count PRs: %w", err)
+	}
+	return c, nil
+}
+
+// CountPRsByTag is CountPRs split by job tag. A job with several tags counts
+// toward each; untagged jobs are left out.
+func (s *Store) CountPRsByTag(ctx context.Context, project, since string, excludeDeleted bool) (map[string]PRCounts, error) {
+	q := `
+SELECT t.tag, COUNT(*),
+       COALESCE(SUM(CASE WHEN COALESCE(j.pr_merged_at,'') != '' THEN 1 ELSE 0 END), 0),
+       COALESCE(SUM(CASE WHEN COALESCE(j.pr_closed_at,'') != '' AND COALESCE(j.pr_merged_at,'') = '' THEN 1 ELSE 0 END), 0)
+FROM jobs j
+JOIN job_tags t ON t.job_id = j.id
+WHERE COALESCE(j.pr_url,'') != ''`
+	var args []any
+	if project != "" {
+		q += ` AND j.project_name = ?`
+		args = append(args, project)
+	}
+	if since != "" {
+		q += ` AND j.created_at >= ?`
+		args = append(args, since)
+	}
+	if excludeDeleted {
+		q += ` AND j.deleted_at = ''`
+	}
+	q += ` GROUP BY t.tag`
+
+	rows, err := s.Reader.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("count PRs by tag: %w", err)
+	}
+	defer rows.Close()
+
+	out := map[string]PRCounts{}
+	for rows.Next() {
+		var (
+			tag string
+			c   PRCounts
+		)
+		if err := rows.Scan(&tag, &c.Opened, &c.Merged, &c.Closed); err != nil {
+			return nil, fmt.Errorf("scan PR counts: %w", err)
+		}
+		out[tag] = c
+	}
+	return out, rows.Err()
+}
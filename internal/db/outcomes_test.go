@@ -0,0 +1,109 @@
+package db
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+func TestPROutcomesTrackRevertsAndFollowUps(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	store, err := Open(filepath.Join(t.TempDir(), "autopr.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer store.Close()
+
+	mergedJob := func(sourceID, prURL string) Job {
+		t.Helper()
+		issueID, err := store.UpsertIssue(ctx, IssueUpsert{ProjectName: "myproject", Source: "github", SourceIssueID: sourceID, Title: "bug " + sourceID, State: "open"})
+		if err != nil {
+			t.Fatalf("upsert issue: %v", err)
+		}
+		jobID, err := store.CreateJob(ctx, issueID, "myproject", 3)
+		if err != nil {
+			t.Fatalf("create job: %v", err)
+		}
+		if _, err := store.Writer.ExecContext(ctx, `UPDATE jobs SET state = 'approved', pr_url = ?, pr_merged_at = '2026-03-02T12:00:00Z' WHERE id = ?`, prURL, jobID); err != nil {
+			t.Fatalf("mark merged: %v", err)
+		}
+		job, err := store.GetJob(ctx, jobID)
+		if err != nil {
+			t.Fatalf("get job: %v", err)
+		}
+		return job
+	}
+	first := mergedJob("1", "https://github.com/org/repo/pull/1")
+	second := mergedJob("2", "https://github.com/org/repo/pull/2")
+	for _, j := range []Job{first, second} {
+		if err := store.RecordPROutcome(ctx, PROutcome{JobID: j.ID, ProjectName: j.ProjectName, PRURL: j.PRURL, OpenedAt: "2026-03-02T09:00:00Z", ApprovedAt: "2026-03-02T11:00:00Z", MergedAt: "2026-03-02T12:00:00Z"}); err != nil {
+			t.Fatalf("record outcome: %v", err)
+		}
+	}
+	// A second record for the same job is ignored.
+	if err := store.RecordPROutcome(ctx, PROutcome{JobID: first.ID, ProjectName: "myproject", PRURL: first.PRURL, MergedAt: "2026-04-01T00:00:00Z"}); err != nil {
+		t.Fatalf("re-record outcome: %v", err)
+	}
+
+	if _, err := store.CreateFollowUpJob(ctx, first, "also handle nil"); err != nil {
+		t.Fatalf("create follow-up: %v", err)
+	}
+	if _, err := store.CreateBackportJob(ctx, first, "release-1", "abc"); err != nil {
+		t.Fatalf("create backport: %v", err)
+	}
+	if _, err := store.CreateRevertJob(ctx, second, "def"); err != nil {
+		t.Fatalf("create revert: %v", err)
+	}
+	if n, err := store.LinkChildJobOutcomes(ctx); err != nil || n != 2 {
+		t.Fatalf("expected 2 links, got %d (err=%v)", n, err)
+	}
+	if n, err := store.LinkChildJobOutcomes(ctx); err != nil || n != 0 {
+		t.Fatalf("expected links to be idempotent, got %d (err=%v)", n, err)
+	}
+	if added, err := store.AddPROutcomeRef(ctx, first.ID, "followup", "issue:ap-9"); err != nil || !added {
+		t.Fatalf("expected new issue ref, got added=%v err=%v", added, err)
+	}
+	if added, err := store.AddPROutcomeRef(ctx, "missing", "followup", "issue:ap-9"); err != nil || added {
+		t.Fatalf("expected no ref for unknown outcome, got added=%v err=%v", added, err)
+	}
+
+	outcomes, err := store.ListPROutcomes(ctx, "myproject", "", false)
+	if err != nil {
+		t.Fatalf("list outcomes: %v", err)
+	}
+	if len(outcomes) != 2 {
+		t.Fatalf("expected 2 outcomes, got %+v", outcomes)
+	}
+	byJob := map[string]PROutcome{}
+	for _, o := range outcomes {
+		byJob[o.JobID] = o
+	}
+	if o := byJob[first.ID]; o.Reverted || o.FollowUps != 2 || o.MergedAt != "2026-03-02T12:00:00Z" || o.AutoPRIssueID != first.AutoPRIssueID {
+		t.Fatalf("unexpected first outcome: %+v", o)
+	}
+	if o := byJob[second.ID]; !o.Reverted || o.FollowUps != 0 {
+		t.Fatalf("unexpected second outcome: %+v", o)
+	}
+
+	counts, err := store.CountPRs(ctx, "myproject", "", false)
+	if err != nil {
+		t.Fatalf("count PRs: %v", err)
+	}
+	if counts.Opened != 2 || counts.Merged != 2 || counts.Closed != 0 {
+		t.Fatalf("unexpected counts: %+v", counts)
+	}
+	// Jobs in the trash can be left out.
+	if err := store.DeleteJob(ctx, second.ID); err != nil {
+		t.Fatalf("delete job: %v", err)
+	}
+	if counts, err := store.CountPRs(ctx, "myproject", "", true); err != nil || counts.Opened != 1 || counts.Merged != 1 {
+		t.Fatalf("counts without deleted jobs = %+v, %v", counts, err)
+	}
+	if outcomes, err := store.ListPROutcomes(ctx, "myproject", "", true); err != nil || len(outcomes) != 1 || outcomes[0].JobID != first.ID {
+		t.Fatalf("outcomes without deleted jobs = %+v, %v", outcomes, err)
+	}
+	if counts, err := store.CountPRs(ctx, "myproject", "", false); err != nil || counts.Opened != 2 {
+		t.Fatalf("counts with deleted jobs = %+v, %v", counts, err)
+	}
+}
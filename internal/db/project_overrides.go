@@ -0,0 +1,53 @@
+package db
+
+import (
+	"context"
+	"fmt"
+)
+
+// ProjectEnabledOverrides returns the enabled state set with `ap project
+// enable|disable`, keyed by project name. Projects without an override use
+// their config file setting.
+func (s *Store) ProjectEnabledOverrides(ctx context.Context) (map[string]bool, error) {
+	rows, err := s.Reader.QueryContext(ctx, `SELECT project_name, enabled FROM project_overrides`)
+	if err != nil {
+		return nil, fmt.Errorf("list project overrides: %w", err)
+	}
+	defer rows.Close()
+
+	out := map[string]bool{}
+	for rows.Next() {
+		var (
+			name    string
+			enabled bool
+		)
+		if err := rows.Scan(&name, &enabled); err != nil {
+			return nil, fmt.Errorf("scan project override: %w", err)
+		}
+		out[name] = enabled
+	}
+	return out, rows.Err()
+}
+
+// SetProjectEnabledOverride records that project is enabled or disabled
+// regardless of its config file setting.
+func (s *Store) SetProjectEnabledOverride(ctx context.Context, project string, enabled bool) error {
+	_, err := s.Writer.ExecContext(ctx, `
+INSERT INTO project_overrides (project_name, enabled) VALUES (?, ?)
+ON CONFLICT(project_name) DO UPDATE SET
+  enabled = excluded.enabled,
+  updated_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now')`, project, enabled)
+	if err != nil {
+		return fmt.Errorf("set project %s override: %w", project, err)
+	}
+	return nil
+}
+
+// ClearProjectEnabledOverride drops project's override so its config file
+// setting applies again.
+func (s *Store) ClearProjectEnabledOverride(ctx context.Context, project string) error {
+	if _, err := s.Writer.ExecContext(ctx, `DELETE FROM project_overrides WHERE project_name = ?`, project); err != nil {
+		return fmt.Errorf("clear project %s override: %w", project, err)
+	}
+	return nil
+}
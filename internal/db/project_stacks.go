@@ -0,0 +1,88 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ProjectStack is what was last detected about a project's repository.
+type ProjectStack struct {
+	ProjectName string
+	Languages   []string
+	Frameworks  []string
+	TestCmd     string
+	LintCmd     string
+	DetectedAt  string // RFC3339, UTC; empty until a detection succeeds
+	CheckedAt   string // RFC3339, UTC; the last detection attempt
+	Error       string // why the last attempt failed; "" when it succeeded
+}
+
+// SetProjectStack records a successful detection of a project's stack.
+func (s *Store) SetProjectStack(ctx context.Context, ps ProjectStack) error {
+	languages, err := json.Marshal(nonNilStrings(ps.Languages))
+	if err != nil {
+		return fmt.Errorf("encode languages: %w", err)
+	}
+	frameworks, err := json.Marshal(nonNilStrings(ps.Frameworks))
+	if err != nil {
+		return fmt.Errorf("encode frameworks: %w", err)
+	}
+	now := nowRFC3339()
+	_, err = s.Writer.ExecContext(ctx, `
+INSERT INTO project_stacks(project_name, languages_json, frameworks_json, test_cmd, lint_cmd, detected_at, checked_at, error) VALUES(?,?,?,?,?,?,?,'')
+ON CONFLICT(project_name) DO UPDATE SET
+    languages_json = excluded.languages_json,
+    frameworks_json = excluded.frameworks_json,
+    test_cmd = excluded.test_cmd,
+    lint_cmd = excluded.lint_cmd,
+    detected_at = excluded.detected_at,
+    checked_at = excluded.checked_at,
+    error = ''`, ps.ProjectName, string(languages), string(frameworks), ps.TestCmd, ps.LintCmd, now, now)
+	if err != nil {
+		return fmt.Errorf("set project %s stack: %w", ps.ProjectName, err)
+	}
+	return nil
+}
+
+// RecordProjectStackError records a failed detection of a project's stack,
+// keeping what an earlier detection found.
+func (s *Store) RecordProjectStackError(ctx context.Context, project string, detectErr error) error {
+	_, err := s.Writer.ExecContext(ctx, `
+INSERT INTO project_stacks(project_name, checked_at, error) VALUES(?,?,?)
+ON CONFLICT(project_name) DO UPDATE SET
+    checked_at = excluded.checked_at,
+    error = excluded.error`, project, nowRFC3339(), trimNotificationError(detectErr.Error()))
+	if err != nil {
+		return fmt.Errorf("record project %s stack error: %w", project, err)
+	}
+	return nil
+}
+
+// GetProjectStack returns what was last detected about a project. ok is
+// false when detection was never attempted.
+func (s *Store) GetProjectStack(ctx context.Context, project string) (ps ProjectStack, ok bool, err error) {
+	var languages, frameworks string
+	err = s.Reader.QueryRowContext(ctx, `
+SELECT project_name, languages_json, frameworks_json, test_cmd, lint_cmd, detected_at, checked_at, error
+FROM project_stacks WHERE project_name = ?`, project).Scan(
+		&ps.ProjectName, &languages, &frameworks, &ps.TestCmd, &ps.LintCmd, &ps.DetectedAt, &ps.CheckedAt, &ps.Error)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ProjectStack{}, false, nil
+	}
+	if err != nil {
+		return ProjectStack{}, false, fmt.Errorf("get project %s stack: %w", project, err)
+	}
+	_ = json.Unmarshal([]byte(languages), &ps.Languages)
+	_ = json.Unmarshal([]byte(frameworks), &ps.Frameworks)
+	return ps, true, nil
+}
+
+func nonNilStrings(v []string) []string {
+	if v == nil {
+		return []string{}
+	}
+	return v
+}
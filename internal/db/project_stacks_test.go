@@ -0,0 +1,41 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestProjectStackKeepsLastDetectionAfterError(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	store, err := Open(filepath.Join(t.TempDir(), "autopr.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer store.Close()
+
+	if _, ok, err := store.GetProjectStack(ctx, "web"); err != nil || ok {
+		t.Fatalf("expected no stack before detection, got %v, %v", ok, err)
+	}
+	if err := store.SetProjectStack(ctx, ProjectStack{ProjectName: "web", Languages: []string{"TypeScript"}, Frameworks: []string{"React"}, TestCmd: "npm test", LintCmd: "npm run lint"}); err != nil {
+		t.Fatalf("set stack: %v", err)
+	}
+	if err := store.RecordProjectStackError(ctx, "web", errors.New("clone repository tree: exit status 128")); err != nil {
+		t.Fatalf("record error: %v", err)
+	}
+
+	ps, ok, err := store.GetProjectStack(ctx, "web")
+	if err != nil || !ok {
+		t.Fatalf("get stack: %v, %v", ok, err)
+	}
+	if !slices.Equal(ps.Languages, []string{"TypeScript"}) || !slices.Equal(ps.Frameworks, []string{"React"}) || ps.TestCmd != "npm test" || ps.LintCmd != "npm run lint" {
+		t.Fatalf("expected the earlier detection kept, got %+v", ps)
+	}
+	if ps.DetectedAt == "" || ps.CheckedAt == "" || ps.Error == "" {
+		t.Fatalf("expected detection and error times recorded, got %+v", ps)
+	}
+}
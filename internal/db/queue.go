@@ -0,0 +1,163 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// StaleQueuedJob is a job that has been queued longer than the alert
+// threshold, with what the claim query needs to know about it.
+type StaleQueuedJob struct {
+	JobID       string
+	ProjectName string
+	IssueTitle  string
+	QueuedAt    string // when the job last entered queued
+	Claimable   bool   // the issue is eligible, or the job is a follow-up of another job
+	SkipReason  string // why the issue is ineligible
+	Ahead       int    // older claimable queued jobs of the same project, which are claimed first
+	StaleAt     string // when the queue_stale alert was raised; empty until then
+}
+
+// ListStaleQueuedJobs returns queued jobs that entered the queue more than
+// olderThan ago, oldest first.
+func (s *Store) ListStaleQueuedJobs(ctx context.Context, olderThan time.Duration) ([]StaleQueuedJob, error) {
+	cutoff := time.Now().UTC().Add(-olderThan).Format(time.RFC3339)
+	rows, err := s.Reader.QueryContext(ctx, `
+SELECT j.id, j.project_name, COALESCE(i.title,''), j.updated_at,
+       CASE WHEN i.eligible = 1 OR j.parent_job_id IS NOT NULL THEN 1 ELSE 0 END,
+       COALESCE(i.skip_reason,''),
+       (SELECT COUNT(*)
+        FROM jobs o
+        JOIN issues oi ON oi.autopr_issue_id = o.autopr_issue_id
+        WHERE o.state = 'queued' AND (oi.eligible = 1 OR o.parent_job_id IS NOT NULL)
+          AND o.project_name = j.project_name AND o.created_at < j.created_at),
+       j.queue_stale_at
+FROM jobs j
+JOIN issues i ON i.autopr_issue_id = j.autopr_issue_id
+WHERE j.state = 'queued' AND j.updated_at < ?
+ORDER BY j.updated_at ASC`, cutoff)
+	if err != nil {
+		return nil, fmt.Errorf("list stale queued jobs: %w", err)
+	}
+	defer rows.Close()
+
+	var out []StaleQueuedJob
+	for rows.Next() {
+		var j StaleQueuedJob
+		if err := rows.Scan(&j.JobID, &j.ProjectName, &j.IssueTitle, &j.QueuedAt, &j.Claimable, &j.SkipReason, &j.Ahead, &j.StaleAt); err != nil {
+			return nil, fmt.Errorf("scan stale queued job: %w", err)
+		}
+		out = append(out, j)
+	}
+	return out, rows.Err()
+}
+
+// claimCandidate is the next claimable job of one project.
+type claimCandidate struct {
+	JobID     string
+	Project   string
+	CreatedAt string
+	Overdue   bool
+	Pass      float64 // the project's fair scheduling pass
+}
+
+// pickClaim picks the job to claim among the next job of each project, and
+// returns the pass to record for its project and the new virtual time.
+// Overdue jobs go first. Within that, the project with the lowest pass goes
+// next, ties going to the older job, and the claim adds 1/weight to its
+// pass, so over time each project gets claims in proportion to its weight.
+// vtime is the lowest pass among the projects that had claimable jobs at the
+// last claim. A project whose pass is behind it had no claimable jobs while
+// the others moved on, so it resumes from vtime: it gets the next turn, but
+// not the turns it did not need while idle. Projects that stayed backlogged
+// are never behind vtime, so their shares are left alone.
+func pickClaim(heads []claimCandidate, weights map[string]int, vtime float64) (claimCandidate, float64, float64, bool) {
+	if len(heads) == 0 {
+		return claimCandidate{}, 0, vtime, false
+	}
+	stride := func(project string) float64 {
+		return 1 / float64(max(weights[project], 1))
+	}
+	pass := make([]float64, len(heads))
+	for i, c := range heads {
+		pass[i] = max(c.Pass, vtime)
+	}
+	best := 0
+	for i, c := range heads {
+		b := heads[best]
+		switch {
+		case c.Overdue != b.Overdue:
+			if c.Overdue {
+				best = i
+			}
+		case pass[i] != pass[best]:
+			if pass[i] < pass[best] {
+				best = i
+			}
+		case c.CreatedAt != b.CreatedAt:
+			if c.CreatedAt < b.CreatedAt {
+				best = i
+			}
+		case c.Project < b.Project:
+			best = i
+		}
+	}
+	next := heads[best]
+	return next, pass[best] + stride(next.Project), slices.Min(pass), true
+}
+
+// CountWorkingJobs returns the number of jobs a worker is currently running.
+func (s *Store) CountWorkingJobs(ctx context.Context) (int, error) {
+	var n int
+	err := s.Reader.QueryRowContext(ctx, `
+SELECT COUNT(*) FROM jobs
+WHERE state IN ('planning','implementing','reviewing','testing','rebasing','resolving_conflicts')`).Scan(&n)
+	if err != nil {
+		return 0, fmt.Errorf("count working jobs: %w", err)
+	}
+	return n, nil
+}
+
+// CountActiveJobs returns the number of jobs queued or in flight, which keep
+// the daemon out of idle mode.
+func (s *Store) CountActiveJobs(ctx context.Context) (int, error) {
+	var n int
+	err := s.Reader.QueryRowContext(ctx, `
+SELECT COUNT(*) FROM jobs
+WHERE state IN ('queued','planning','implementing','reviewing','testing','rebasing','resolving_conflicts','awaiting_checks','waiting_network')
+  AND deleted_at = ''`).Scan(&n)
+	if err != nil {
+		return 0, fmt.Errorf("count active jobs: %w", err)
+	}
+	return n, nil
+}
+
+// MarkJobQueueStale flags a queued job that has waited too long and enqueues
+// a queue_stale notification. It reports false when the job has left the
+// queue or was already flagged.
+func (s *Store) MarkJobQueueStale(ctx context.Context, jobID string) (bool, error) {
+	tx, err := s.Writer.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("mark job %s queue stale: %w", jobID, err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `
+UPDATE jobs SET queue_stale_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now')
+WHERE id = ? AND state = 'queued' AND queue_stale_at = ''`, jobID)
+	if err != nil {
+		return false, fmt.Errorf("mark job %s queue stale: %w", jobID, err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return false, nil
+	}
+	if err := enqueueNotificationEventTx(ctx, tx, jobID, NotificationEventQueueStale); err != nil {
+		return false, err
+	}
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("mark job %s queue stale: %w", jobID, err)
+	}
+	return true, nil
+}
@@ -0,0 +1,54 @@
+package db
+
+import (
+	"context"
+	"fmt"
+)
+
+// APIRateLimit is the last rate limit the daemon observed for an API host.
+type APIRateLimit struct {
+	Host      string
+	Resource  string // e.g. GitHub's "core"; empty when the API reports one bucket
+	Limit     int
+	Remaining int
+	ResetAt   string // RFC3339, UTC; empty when unknown
+	UpdatedAt string // when the limit was observed (RFC3339, UTC)
+}
+
+// UpsertAPIRateLimit records the latest observed rate limit for a host.
+func (s *Store) UpsertAPIRateLimit(ctx context.Context, rl APIRateLimit) error {
+	_, err := s.Writer.ExecContext(ctx, `
+INSERT INTO api_rate_limits(host, resource, limit_max, remaining, reset_at, updated_at) VALUES(?,?,?,?,?,?)
+ON CONFLICT(host, resource) DO UPDATE SET
+    limit_max = excluded.limit_max,
+    remaining = excluded.remaining,
+    reset_at = excluded.reset_at,
+    updated_at = excluded.updated_at
+WHERE excluded.updated_at >= api_rate_limits.updated_at`,
+		rl.Host, rl.Resource, rl.Limit, rl.Remaining, rl.ResetAt, rl.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("upsert api rate limit %s: %w", rl.Host, err)
+	}
+	return nil
+}
+
+// ListAPIRateLimits returns all recorded rate limits ordered by host.
+func (s *Store) ListAPIRateLimits(ctx context.Context) ([]APIRateLimit, error) {
+	rows, err := s.Reader.QueryContext(ctx, `
+SELECT host, resource, limit_max, remaining, reset_at, updated_at
+FROM api_rate_limits ORDER BY host, resource`)
+	if err != nil {
+		return nil, fmt.Errorf("list api rate limits: %w", err)
+	}
+	defer rows.Close()
+
+	var out []APIRateLimit
+	for rows.Next() {
+		var rl APIRateLimit
+		if err := rows.Scan(&rl.Host, &rl.Resource, &rl.Limit, &rl.Remaining, &rl.ResetAt, &rl.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("scan api rate limit: %w", err)
+		}
+		out = append(out, rl)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,40 @@
+package db
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+func TestAPIRateLimitUpsertKeepsNewest(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	store, err := Open(filepath.Join(t.TempDir(), "autopr.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer store.Close()
+
+	newer := APIRateLimit{Host: "api.github.com", Resource: "core", Limit: 5000, Remaining: 120, ResetAt: "2026-03-01T13:00:00Z", UpdatedAt: "2026-03-01T12:30:00Z"}
+	older := APIRateLimit{Host: "api.github.com", Resource: "core", Limit: 5000, Remaining: 4000, ResetAt: "2026-03-01T13:00:00Z", UpdatedAt: "2026-03-01T12:00:00Z"}
+	gitlab := APIRateLimit{Host: "gitlab.com", Limit: 2000, Remaining: 1999, UpdatedAt: "2026-03-01T12:00:00Z"}
+	for _, rl := range []APIRateLimit{newer, older, gitlab} {
+		if err := store.UpsertAPIRateLimit(ctx, rl); err != nil {
+			t.Fatalf("upsert: %v", err)
+		}
+	}
+
+	limits, err := store.ListAPIRateLimits(ctx)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(limits) != 2 {
+		t.Fatalf("expected 2 rate limits, got %+v", limits)
+	}
+	if limits[0] != newer {
+		t.Fatalf("expected newest github observation to win, got %+v", limits[0])
+	}
+	if limits[1] != gitlab {
+		t.Fatalf("unexpected gitlab rate limit %+v", limits[1])
+	}
+}
@@ -0,0 +1,46 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// RecurringRun tracks the last scheduled run of a recurring task.
+type RecurringRun struct {
+	ProjectName string
+	TaskName    string
+	LastRunAt   string // scheduled time of the last processed run (RFC3339, UTC)
+	LastIssueID string // autopr_issue_id created by the last run, if any
+}
+
+// GetRecurringRun returns the last run of a recurring task. The bool is false
+// when the task has never been scheduled.
+func (s *Store) GetRecurringRun(ctx context.Context, projectName, taskName string) (RecurringRun, bool, error) {
+	run := RecurringRun{ProjectName: projectName, TaskName: taskName}
+	err := s.Reader.QueryRowContext(ctx, `
+SELECT last_run_at, last_issue_id FROM recurring_runs
+WHERE project_name = ? AND task_name = ?`, projectName, taskName).Scan(&run.LastRunAt, &run.LastIssueID)
+	if err == sql.ErrNoRows {
+		return run, false, nil
+	}
+	if err != nil {
+		return run, false, fmt.Errorf("get recurring run %s/%s: %w", projectName, taskName, err)
+	}
+	return run, true, nil
+}
+
+// SetRecurringRun records the last processed run of a recurring task. An empty
+// issueID keeps the previously recorded issue (the run was skipped).
+func (s *Store) SetRecurringRun(ctx context.Context, projectName, taskName, lastRunAt, issueID string) error {
+	_, err := s.Writer.ExecContext(ctx, `
+INSERT INTO recurring_runs(project_name, task_name, last_run_at, last_issue_id) VALUES(?,?,?,?)
+ON CONFLICT(project_name, task_name) DO UPDATE SET
+    last_run_at = excluded.last_run_at,
+    last_issue_id = CASE WHEN excluded.last_issue_id = '' THEN recurring_runs.last_issue_id ELSE excluded.last_issue_id END,
+    updated_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now')`, projectName, taskName, lastRunAt, issueID)
+	if err != nil {
+		return fmt.Errorf("set recurring run %s/%s: %w", projectName, taskName, err)
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package db
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+func TestRecurringRunRoundTrip(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	store, err := Open(filepath.Join(t.TempDir(), "autopr.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer store.Close()
+
+	if _, ok, err := store.GetRecurringRun(ctx, "myproject", "deps"); err != nil || ok {
+		t.Fatalf("expected no run yet, got ok=%v err=%v", ok, err)
+	}
+
+	if err := store.SetRecurringRun(ctx, "myproject", "deps", "2026-03-02T09:00:00Z", "ap-1"); err != nil {
+		t.Fatalf("set run: %v", err)
+	}
+	// A skipped run advances the time but keeps the last issue.
+	if err := store.SetRecurringRun(ctx, "myproject", "deps", "2026-03-09T09:00:00Z", ""); err != nil {
+		t.Fatalf("set skipped run: %v", err)
+	}
+
+	run, ok, err := store.GetRecurringRun(ctx, "myproject", "deps")
+	if err != nil || !ok {
+		t.Fatalf("get run: ok=%v err=%v", ok, err)
+	}
+	if run.LastRunAt != "2026-03-09T09:00:00Z" || run.LastIssueID != "ap-1" {
+		t.Fatalf("unexpected run: %+v", run)
+	}
+}
+
+func TestUpsertIssueAcceptsRecurringSource(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	store, err := Open(filepath.Join(t.TempDir(), "autopr.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer store.Close()
+
+	if _, err := store.UpsertIssue(ctx, IssueUpsert{
+		ProjectName:   "myproject",
+		Source:        "recurring",
+		SourceIssueID: "deps-20260302-0900",
+		Title:         "Bump dependencies",
+		State:         "open",
+	}); err != nil {
+		t.Fatalf("upsert recurring issue: %v", err)
+	}
+}
@@ -0,0 +1,125 @@
+package db
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+)
+
+// retentionInterval is how often RunRetentionLoop purges expired session text.
+const retentionInterval = time.Hour
+
+// PurgeSessionTextBefore deletes the prompt and response text and transcript
+// files of finished LLM sessions created before cutoff. Token counts,
+// durations, and SHA-256 hashes of the text are kept. It returns the number
+// of sessions purged.
+func (s *Store) PurgeSessionTextBefore(ctx context.Context, cutoff time.Time) (int, error) {
+	return s.purgeSessionText(ctx, "created_at < ?", cutoff.UTC().Format(time.RFC3339))
+}
+
+// PurgeJobSessionText deletes the prompt and response text and transcript
+// files of every finished LLM session of a job, regardless of age.
+func (s *Store) PurgeJobSessionText(ctx context.Context, jobID string) (int, error) {
+	return s.purgeSessionText(ctx, "job_id = ?", jobID)
+}
+
+type sessionText struct {
+	id               int
+	prompt, response string
+	promptHash       string
+	jsonlPath        string
+}
+
+func (s *Store) purgeSessionText(ctx context.Context, where string, arg any) (int, error) {
+	rows, err := s.Reader.QueryContext(ctx, `
+SELECT id, COALESCE(prompt_text,''), COALESCE(response_text,''), COALESCE(prompt_hash,''), COALESCE(jsonl_path,'')
+FROM llm_sessions WHERE status != 'running' AND purged_at = '' AND `+where+` ORDER BY id ASC`, arg)
+	if err != nil {
+		return 0, fmt.Errorf("list sessions to purge: %w", err)
+	}
+	var sessions []sessionText
+	for rows.Next() {
+		var st sessionText
+		if err := rows.Scan(&st.id, &st.prompt, &st.response, &st.promptHash, &st.jsonlPath); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("scan session to purge: %w", err)
+		}
+		sessions = append(sessions, st)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("list sessions to purge: %w", err)
+	}
+	if len(sessions) == 0 {
+		return 0, nil
+	}
+
+	tx, err := s.Writer.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("begin purge: %w", err)
+	}
+	defer tx.Rollback()
+	for _, st := range sessions {
+		promptHash := st.promptHash
+		if promptHash == "" {
+			promptHash = textHash(st.prompt)
+		}
+		if _, err := tx.ExecContext(ctx, `
+UPDATE llm_sessions SET prompt_text = NULL, response_text = NULL, jsonl_path = NULL,
+                        prompt_hash = ?, response_hash = ?,
+                        purged_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now')
+WHERE id = ? AND purged_at = ''`, promptHash, textHash(st.response), st.id); err != nil {
+			return 0, fmt.Errorf("purge session %d: %w", st.id, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("commit purge: %w", err)
+	}
+
+	// Transcripts hold the same text; remove them once the rows no longer
+	// point at them.
+	for _, st := range sessions {
+		if st.jsonlPath == "" {
+			continue
+		}
+		if err := os.Remove(st.jsonlPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			slog.Warn("remove purged session transcript", "session_id", st.id, "path", st.jsonlPath, "err", err)
+		}
+	}
+	return len(sessions), nil
+}
+
+// textHash returns the hex SHA-256 of text, or "" for empty text.
+func textHash(text string) string {
+	if text == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:])
+}
+
+// RunRetentionLoop purges the text of sessions older than after, once at
+// startup and then every hour, until ctx is cancelled.
+func (s *Store) RunRetentionLoop(ctx context.Context, after time.Duration) {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+		n, err := s.PurgeSessionTextBefore(ctx, time.Now().Add(-after))
+		if err != nil {
+			slog.Error("retention purge failed", "err", err)
+		} else if n > 0 {
+			slog.Info("purged expired session text", "sessions", n)
+		}
+		timer.Reset(retentionInterval)
+	}
+}
@@ -0,0 +1,102 @@
+package db
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestPurgeSessionTextKeepsCountsAndHashes(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	tmp := t.TempDir()
+	store, err := Open(filepath.Join(tmp, "autopr.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer store.Close()
+
+	issueID, err := store.UpsertIssue(ctx, IssueUpsert{ProjectName: "myproject", Source: "github", SourceIssueID: "1", Title: "bug", State: "open"})
+	if err != nil {
+		t.Fatalf("upsert issue: %v", err)
+	}
+	jobID, err := store.CreateJob(ctx, issueID, "myproject", 3)
+	if err != nil {
+		t.Fatalf("create job: %v", err)
+	}
+	newSession := func(createdAt, prompt string) (int64, string) {
+		t.Helper()
+		transcript := filepath.Join(tmp, prompt+".jsonl")
+		if err := os.WriteFile(transcript, []byte("{}\n"), 0o644); err != nil {
+			t.Fatalf("write transcript: %v", err)
+		}
+		id, err := store.CreateSession(ctx, jobID, "plan", 0, "claude", transcript)
+		if err != nil {
+			t.Fatalf("create session: %v", err)
+		}
+		if err := store.CompleteSession(ctx, id, "completed", "response to "+prompt, prompt, "", transcript, "", "", 10, 20, 300); err != nil {
+			t.Fatalf("complete session: %v", err)
+		}
+		if _, err := store.Writer.ExecContext(ctx, `UPDATE llm_sessions SET created_at = ? WHERE id = ?`, createdAt, id); err != nil {
+			t.Fatalf("backdate session: %v", err)
+		}
+		return id, transcript
+	}
+	oldID, oldTranscript := newSession("2026-01-01T00:00:00Z", "old")
+	newID, newTranscript := newSession("2026-03-01T00:00:00Z", "new")
+	running, err := store.CreateSession(ctx, jobID, "implement", 0, "claude", "")
+	if err != nil {
+		t.Fatalf("create running session: %v", err)
+	}
+
+	n, err := store.PurgeSessionTextBefore(ctx, time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("purge: %v", err)
+	}
+	if n != 1 {
+		t.Fatalf("expected 1 purged session, got %d", n)
+	}
+	old, err := store.GetFullSession(ctx, int(oldID))
+	if err != nil {
+		t.Fatalf("get old session: %v", err)
+	}
+	if old.PromptText != "" || old.ResponseText != "" || old.JSONLPath != "" || old.PurgedAt == "" {
+		t.Fatalf("old session text not purged: %+v", old)
+	}
+	if old.PromptHash != textHash("old") || old.InputTokens != 10 || old.OutputTokens != 20 || old.DurationMS != 300 {
+		t.Fatalf("old session lost its counts or hash: %+v", old)
+	}
+	var responseHash string
+	if err := store.Reader.QueryRowContext(ctx, `SELECT response_hash FROM llm_sessions WHERE id = ?`, oldID).Scan(&responseHash); err != nil {
+		t.Fatalf("read response hash: %v", err)
+	}
+	if responseHash != textHash("response to old") {
+		t.Fatalf("unexpected response hash %q", responseHash)
+	}
+	if _, err := os.Stat(oldTranscript); !os.IsNotExist(err) {
+		t.Fatalf("old transcript not removed: %v", err)
+	}
+	if _, err := os.Stat(newTranscript); err != nil {
+		t.Fatalf("new transcript removed: %v", err)
+	}
+
+	n, err = store.PurgeJobSessionText(ctx, jobID)
+	if err != nil {
+		t.Fatalf("purge job: %v", err)
+	}
+	if n != 1 {
+		t.Fatalf("expected only the newer finished session to be purged, got %d", n)
+	}
+	recent, err := store.GetFullSession(ctx, int(newID))
+	if err != nil {
+		t.Fatalf("get new session: %v", err)
+	}
+	if recent.PromptText != "" || recent.PurgedAt == "" {
+		t.Fatalf("new session text not purged: %+v", recent)
+	}
+	if sess, err := store.GetFullSession(ctx, int(running)); err != nil || sess.PurgedAt != "" {
+		t.Fatalf("running session was purged: %+v %v", sess, err)
+	}
+}
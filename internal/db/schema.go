@@ -4,13 +4,12 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"sort"
 	"strings"
 
 	"autopr/internal/git"
 )
 
-const schemaVersion = 1
-
 const schemaSQL = `
 CREATE TABLE IF NOT EXISTS schema_version (
     version    INTEGER NOT NULL,
@@ -20,7 +19,7 @@ CREATE TABLE IF NOT EXISTS schema_version (
 CREATE TABLE IF NOT EXISTS issues (
     autopr_issue_id   TEXT PRIMARY KEY,
     project_name      TEXT NOT NULL,
-    source            TEXT NOT NULL CHECK(source IN ('gitlab', 'github', 'sentry')),
+    source            TEXT NOT NULL CHECK(source IN ('gitlab', 'github', 'gitea', 'sentry', 'recurring', 'bisect')),
     source_issue_id   TEXT NOT NULL,
     title             TEXT NOT NULL,
     body              TEXT NOT NULL DEFAULT '',
@@ -41,7 +40,7 @@ CREATE TABLE IF NOT EXISTS jobs (
     autopr_issue_id TEXT NOT NULL REFERENCES issues(autopr_issue_id) ON DELETE RESTRICT,
     project_name     TEXT NOT NULL,
     state            TEXT NOT NULL DEFAULT 'queued'
-        CHECK(state IN ('queued','planning','implementing','reviewing','testing','ready','rebasing','resolving_conflicts','awaiting_checks','approved','rejected','failed','cancelled')),
+        CHECK(state IN ('queued','planning','implementing','reviewing','testing','ready','rebasing','resolving_conflicts','awaiting_checks','waiting_network','approved','rejected','failed','cancelled')),
     iteration        INTEGER NOT NULL DEFAULT 0 CHECK(iteration >= 0),
     max_iterations   INTEGER NOT NULL DEFAULT 3 CHECK(max_iterations > 0),
     worktree_path    TEXT,
@@ -59,14 +58,22 @@ CREATE TABLE IF NOT EXISTS jobs (
     completed_at     TEXT,
     ci_started_at    TEXT,
     ci_completed_at  TEXT,
-    ci_status_summary TEXT
+    ci_status_summary TEXT,
+    parent_job_id    TEXT,
+    backport_branch  TEXT NOT NULL DEFAULT '',
+    backport_commit  TEXT NOT NULL DEFAULT '',
+    revert_commit    TEXT NOT NULL DEFAULT '',
+    bisect_good      TEXT NOT NULL DEFAULT '',
+    bisect_bad       TEXT NOT NULL DEFAULT '',
+    bisect_cmd       TEXT NOT NULL DEFAULT '',
+    bisect_fix       INTEGER NOT NULL DEFAULT 0 CHECK(bisect_fix IN (0,1))
 );
 
 CREATE INDEX IF NOT EXISTS idx_jobs_state ON jobs(state);
 CREATE INDEX IF NOT EXISTS idx_jobs_issue ON jobs(autopr_issue_id);
 CREATE INDEX IF NOT EXISTS idx_jobs_state_project ON jobs(state, project_name);
 CREATE UNIQUE INDEX IF NOT EXISTS idx_jobs_one_active_per_issue
-    ON jobs(autopr_issue_id)
+    ON jobs(autopr_issue_id, backport_branch)
     WHERE state NOT IN ('approved', 'rejected', 'failed', 'cancelled');
 
 CREATE TABLE IF NOT EXISTS llm_sessions (
@@ -97,7 +104,7 @@ CREATE TABLE IF NOT EXISTS artifacts (
     id               INTEGER PRIMARY KEY AUTOINCREMENT,
     job_id           TEXT NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
     autopr_issue_id  TEXT NOT NULL,
-    kind             TEXT NOT NULL CHECK(kind IN ('plan','plan_review','code_review','test_output','rebase_conflict','rebase_result')),
+    kind             TEXT NOT NULL CHECK(kind IN ('plan','plan_review','code_review','test_output','rebase_conflict','rebase_result','excluded_changes','bisect_result')),
     content          TEXT NOT NULL,
     iteration        INTEGER NOT NULL DEFAULT 0,
     commit_sha       TEXT,
@@ -108,7 +115,7 @@ CREATE INDEX IF NOT EXISTS idx_artifacts_job ON artifacts(job_id);
 
 CREATE TABLE IF NOT EXISTS sync_cursors (
     project_name   TEXT NOT NULL,
-    source         TEXT NOT NULL CHECK(source IN ('gitlab', 'github', 'sentry')),
+    source         TEXT NOT NULL CHECK(source IN ('gitlab', 'github', 'gitea', 'sentry')),
     cursor_value   TEXT NOT NULL DEFAULT '',
     last_synced_at TEXT NOT NULL,
     PRIMARY KEY(project_name, source)
@@ -118,7 +125,7 @@ CREATE TABLE IF NOT EXISTS notification_events (
     id         INTEGER PRIMARY KEY AUTOINCREMENT,
     job_id     TEXT NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
     event_type TEXT NOT NULL CHECK(event_type IN ('needs_pr','failed','pr_created','pr_merged')),
-    status     TEXT NOT NULL DEFAULT 'pending' CHECK(status IN ('pending','processing','sent','failed','skipped')),
+    status     TEXT NOT NULL DEFAULT 'pending' CHECK(status IN ('pending','processing','sent','failed','skipped','dead')),
     attempts   INTEGER NOT NULL DEFAULT 0 CHECK(attempts >= 0),
     last_error TEXT NOT NULL DEFAULT '',
     created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now')),
@@ -129,6 +136,67 @@ CREATE INDEX IF NOT EXISTS idx_notification_events_status_created
     ON notification_events(status, created_at);
 CREATE INDEX IF NOT EXISTS idx_notification_events_job
     ON notification_events(job_id);
+
+CREATE TABLE IF NOT EXISTS notification_deliveries (
+    event_id   INTEGER NOT NULL REFERENCES notification_events(id) ON DELETE CASCADE,
+    channel    TEXT NOT NULL,
+    status     TEXT NOT NULL DEFAULT 'pending' CHECK(status IN ('pending','sent','failed','dead')),
+    attempts   INTEGER NOT NULL DEFAULT 0 CHECK(attempts >= 0),
+    last_error TEXT NOT NULL DEFAULT '',
+    updated_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now')),
+    PRIMARY KEY(event_id, channel)
+);
+
+CREATE TABLE IF NOT EXISTS issue_locks (
+    job_id     TEXT PRIMARY KEY REFERENCES jobs(id) ON DELETE CASCADE,
+    status     TEXT NOT NULL DEFAULT 'held' CHECK(status IN ('held','released')),
+    created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now')),
+    updated_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_issue_locks_status
+    ON issue_locks(status);
+
+CREATE TABLE IF NOT EXISTS recurring_runs (
+    project_name  TEXT NOT NULL,
+    task_name     TEXT NOT NULL,
+    last_run_at   TEXT NOT NULL,
+    last_issue_id TEXT NOT NULL DEFAULT '',
+    updated_at    TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now')),
+    PRIMARY KEY(project_name, task_name)
+);
+
+CREATE TABLE IF NOT EXISTS api_rate_limits (
+    host       TEXT NOT NULL,
+    resource   TEXT NOT NULL DEFAULT '',
+    limit_max  INTEGER NOT NULL,
+    remaining  INTEGER NOT NULL,
+    reset_at   TEXT NOT NULL DEFAULT '',
+    updated_at TEXT NOT NULL,
+    PRIMARY KEY(host, resource)
+);
+
+CREATE TABLE IF NOT EXISTS project_sync_status (
+    project_name    TEXT PRIMARY KEY,
+    last_success_at TEXT NOT NULL DEFAULT '',
+    last_error      TEXT NOT NULL DEFAULT '',
+    last_error_at   TEXT NOT NULL DEFAULT ''
+);
+
+CREATE TABLE IF NOT EXISTS outbox_ops (
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    job_id     TEXT NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
+    kind       TEXT NOT NULL CHECK(kind IN ('resume_job','create_pr','merge_pr')),
+    payload    TEXT NOT NULL DEFAULT '',
+    status     TEXT NOT NULL DEFAULT 'pending' CHECK(status IN ('pending','done','failed')),
+    attempts   INTEGER NOT NULL DEFAULT 0 CHECK(attempts >= 0),
+    last_error TEXT NOT NULL DEFAULT '',
+    created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now')),
+    updated_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now'))
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_outbox_ops_pending
+    ON outbox_ops(job_id, kind) WHERE status = 'pending';
 `
 
 func (s *Store) createSchema() error {
@@ -140,8 +208,9 @@ func (s *Store) createSchema() error {
 	if err := s.Writer.QueryRow("SELECT COUNT(*) FROM schema_version").Scan(&count); err != nil {
 		return fmt.Errorf("check schema version: %w", err)
 	}
-	if count == 0 {
-		if _, err := s.Writer.Exec("INSERT INTO schema_version (version) VALUES (?)", schemaVersion); err != nil {
+	existing := count > 0
+	if !existing {
+		if _, err := s.Writer.Exec("INSERT INTO schema_version (version) VALUES (?)", baselineSchemaVersion); err != nil {
 			return fmt.Errorf("insert schema version: %w", err)
 		}
 	}
@@ -175,15 +244,42 @@ func (s *Store) createSchema() error {
 	if err := s.migrateArtifactsForRebaseResultKind(); err != nil {
 		return err
 	}
+	if err := s.migrateArtifactsForExcludedChangesKind(); err != nil {
+		return err
+	}
+	if err := s.migrateIssuesForRecurringSource(); err != nil {
+		return err
+	}
+	if err := s.migrateArtifactsForBisectResultKind(); err != nil {
+		return err
+	}
+	if err := s.migrateIssuesForBisectSource(); err != nil {
+		return err
+	}
+	if err := s.migrateIssuesForGiteaSource(); err != nil {
+		return err
+	}
+	if err := s.migrateSyncCursorsForGiteaSource(); err != nil {
+		return err
+	}
 	if err := s.migrateJobsForAwaitingChecksState(); err != nil {
 		return err
 	}
+	// Backport jobs run alongside other jobs for the same issue, one per
+	// release branch, so the active-job index is keyed on both columns.
+	_, _ = s.Writer.Exec("ALTER TABLE jobs ADD COLUMN backport_branch TEXT NOT NULL DEFAULT ''")
+	_, _ = s.Writer.Exec("ALTER TABLE jobs ADD COLUMN backport_commit TEXT NOT NULL DEFAULT ''")
+	_, _ = s.Writer.Exec("ALTER TABLE jobs ADD COLUMN revert_commit TEXT NOT NULL DEFAULT ''")
+	_, _ = s.Writer.Exec("ALTER TABLE jobs ADD COLUMN bisect_good TEXT NOT NULL DEFAULT ''")
+	_, _ = s.Writer.Exec("ALTER TABLE jobs ADD COLUMN bisect_bad TEXT NOT NULL DEFAULT ''")
+	_, _ = s.Writer.Exec("ALTER TABLE jobs ADD COLUMN bisect_cmd TEXT NOT NULL DEFAULT ''")
+	_, _ = s.Writer.Exec("ALTER TABLE jobs ADD COLUMN bisect_fix INTEGER NOT NULL DEFAULT 0 CHECK(bisect_fix IN (0,1))")
 	// Recreate to ensure predicate includes cancelled for existing DBs.
 	if _, err := s.Writer.Exec("DROP INDEX IF EXISTS idx_jobs_one_active_per_issue"); err != nil {
 		return fmt.Errorf("drop active-job index: %w", err)
 	}
 	if _, err := s.Writer.Exec(`CREATE UNIQUE INDEX IF NOT EXISTS idx_jobs_one_active_per_issue
-		ON jobs(autopr_issue_id)
+		ON jobs(autopr_issue_id, backport_branch)
 		WHERE state NOT IN ('approved', 'rejected', 'failed', 'cancelled')`); err != nil {
 		return fmt.Errorf("create active-job index: %w", err)
 	}
@@ -191,13 +287,23 @@ func (s *Store) createSchema() error {
 	if err := s.migrateNotificationEventsNeedsPR(); err != nil {
 		return err
 	}
+	if err := s.migrateNotificationEventsDeadStatus(); err != nil {
+		return err
+	}
 
 	// Ensure CI metadata columns exist even if an older migration recreated jobs.
 	_, _ = s.Writer.Exec("ALTER TABLE jobs ADD COLUMN ci_started_at TEXT")
 	_, _ = s.Writer.Exec("ALTER TABLE jobs ADD COLUMN ci_completed_at TEXT")
 	_, _ = s.Writer.Exec("ALTER TABLE jobs ADD COLUMN ci_status_summary TEXT")
+	_, _ = s.Writer.Exec("ALTER TABLE jobs ADD COLUMN parent_job_id TEXT")
 
-	return nil
+	if err := s.migrateJobsForWaitingNetworkState(); err != nil {
+		return err
+	}
+
+	// Everything above is the version 1 baseline; later changes ship as
+	// numbered files under migrations/.
+	return s.applyMigrations(existing)
 }
 
 func (s *Store) tableSQL(table string) (string, error) {
@@ -512,6 +618,101 @@ FROM jobs`); err != nil {
 	})
 }
 
+// migrateJobsForWaitingNetworkState widens the jobs state CHECK to include
+// waiting_network. It runs after every column migration so the copy carries
+// all columns.
+func (s *Store) migrateJobsForWaitingNetworkState() error {
+	sqlText, err := s.tableSQL("jobs")
+	if err != nil {
+		return err
+	}
+	if strings.Contains(sqlText, "'waiting_network'") {
+		return nil
+	}
+
+	return s.withForeignKeysOff(func() error {
+		tx, err := s.Writer.Begin()
+		if err != nil {
+			return fmt.Errorf("begin jobs waiting_network migration: %w", err)
+		}
+		defer tx.Rollback()
+
+		if _, err := tx.Exec(`
+CREATE TABLE jobs_new (
+    id              TEXT PRIMARY KEY,
+    autopr_issue_id TEXT NOT NULL REFERENCES issues(autopr_issue_id) ON DELETE RESTRICT,
+    project_name     TEXT NOT NULL,
+    state            TEXT NOT NULL DEFAULT 'queued'
+        CHECK(state IN ('queued','planning','implementing','reviewing','testing','ready','rebasing','resolving_conflicts','awaiting_checks','waiting_network','approved','rejected','failed','cancelled')),
+    iteration        INTEGER NOT NULL DEFAULT 0 CHECK(iteration >= 0),
+    max_iterations   INTEGER NOT NULL DEFAULT 3 CHECK(max_iterations > 0),
+    worktree_path    TEXT,
+    branch_name      TEXT,
+    commit_sha       TEXT,
+    human_notes      TEXT,
+    error_message    TEXT,
+    pr_url           TEXT,
+    pr_merged_at     TEXT,
+    pr_closed_at     TEXT,
+    reject_reason    TEXT,
+    created_at       TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now')),
+    updated_at       TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now')),
+    started_at       TEXT,
+    completed_at     TEXT,
+    ci_started_at    TEXT,
+    ci_completed_at  TEXT,
+    ci_status_summary TEXT,
+    parent_job_id    TEXT,
+    backport_branch  TEXT NOT NULL DEFAULT '',
+    backport_commit  TEXT NOT NULL DEFAULT '',
+    revert_commit    TEXT NOT NULL DEFAULT '',
+    bisect_good      TEXT NOT NULL DEFAULT '',
+    bisect_bad       TEXT NOT NULL DEFAULT '',
+    bisect_cmd       TEXT NOT NULL DEFAULT '',
+    bisect_fix       INTEGER NOT NULL DEFAULT 0 CHECK(bisect_fix IN (0,1))
+)`); err != nil {
+			return fmt.Errorf("create jobs_new for waiting_network migration: %w", err)
+		}
+
+		const columns = `
+    id, autopr_issue_id, project_name, state, iteration, max_iterations,
+    worktree_path, branch_name, commit_sha, human_notes, error_message,
+    pr_url, pr_merged_at, pr_closed_at, reject_reason, created_at, updated_at,
+    started_at, completed_at, ci_started_at, ci_completed_at, ci_status_summary,
+    parent_job_id, backport_branch, backport_commit, revert_commit,
+    bisect_good, bisect_bad, bisect_cmd, bisect_fix`
+		if _, err := tx.Exec(`INSERT INTO jobs_new (` + columns + `) SELECT` + columns + ` FROM jobs`); err != nil {
+			return fmt.Errorf("copy jobs rows for waiting_network migration: %w", err)
+		}
+
+		if _, err := tx.Exec(`DROP TABLE jobs`); err != nil {
+			return fmt.Errorf("drop jobs for waiting_network migration: %w", err)
+		}
+		if _, err := tx.Exec(`ALTER TABLE jobs_new RENAME TO jobs`); err != nil {
+			return fmt.Errorf("rename jobs_new for waiting_network migration: %w", err)
+		}
+		if _, err := tx.Exec(`CREATE INDEX IF NOT EXISTS idx_jobs_state ON jobs(state)`); err != nil {
+			return fmt.Errorf("create idx_jobs_state for waiting_network migration: %w", err)
+		}
+		if _, err := tx.Exec(`CREATE INDEX IF NOT EXISTS idx_jobs_issue ON jobs(autopr_issue_id)`); err != nil {
+			return fmt.Errorf("create idx_jobs_issue for waiting_network migration: %w", err)
+		}
+		if _, err := tx.Exec(`CREATE INDEX IF NOT EXISTS idx_jobs_state_project ON jobs(state, project_name)`); err != nil {
+			return fmt.Errorf("create idx_jobs_state_project for waiting_network migration: %w", err)
+		}
+		if _, err := tx.Exec(`CREATE UNIQUE INDEX IF NOT EXISTS idx_jobs_one_active_per_issue
+    ON jobs(autopr_issue_id, backport_branch)
+    WHERE state NOT IN ('approved', 'rejected', 'failed', 'cancelled')`); err != nil {
+			return fmt.Errorf("create active-job index for waiting_network migration: %w", err)
+		}
+
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("commit jobs waiting_network migration: %w", err)
+		}
+		return nil
+	})
+}
+
 func (s *Store) migrateSessionsForCancelledStatus() error {
 	sqlText, err := s.tableSQL("llm_sessions")
 	if err != nil {
@@ -767,6 +968,360 @@ FROM artifacts`); err != nil {
 	})
 }
 
+func (s *Store) migrateArtifactsForExcludedChangesKind() error {
+	sqlText, err := s.tableSQL("artifacts")
+	if err != nil {
+		return err
+	}
+	if strings.Contains(sqlText, "'excluded_changes'") {
+		return nil
+	}
+
+	return s.withForeignKeysOff(func() error {
+		tx, err := s.Writer.Begin()
+		if err != nil {
+			return fmt.Errorf("begin artifacts excluded_changes migration: %w", err)
+		}
+		defer tx.Rollback()
+
+		if _, err := tx.Exec(`
+CREATE TABLE artifacts_new (
+    id               INTEGER PRIMARY KEY AUTOINCREMENT,
+    job_id           TEXT NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
+    autopr_issue_id  TEXT NOT NULL,
+    kind             TEXT NOT NULL CHECK(kind IN ('plan','plan_review','code_review','test_output','rebase_conflict','rebase_result','excluded_changes')),
+    content          TEXT NOT NULL,
+    iteration        INTEGER NOT NULL DEFAULT 0,
+    commit_sha       TEXT,
+    created_at       TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now'))
+)`); err != nil {
+			return fmt.Errorf("create artifacts_new for excluded_changes migration: %w", err)
+		}
+
+		if _, err := tx.Exec(`
+INSERT INTO artifacts_new (
+    id, job_id, autopr_issue_id, kind, content, iteration, commit_sha, created_at
+)
+SELECT
+    id, job_id, autopr_issue_id, kind, content, iteration, commit_sha, created_at
+FROM artifacts`); err != nil {
+			return fmt.Errorf("copy artifacts rows for excluded_changes migration: %w", err)
+		}
+
+		if _, err := tx.Exec(`DROP TABLE artifacts`); err != nil {
+			return fmt.Errorf("drop artifacts for excluded_changes migration: %w", err)
+		}
+		if _, err := tx.Exec(`ALTER TABLE artifacts_new RENAME TO artifacts`); err != nil {
+			return fmt.Errorf("rename artifacts_new for excluded_changes migration: %w", err)
+		}
+		if _, err := tx.Exec(`CREATE INDEX IF NOT EXISTS idx_artifacts_job ON artifacts(job_id)`); err != nil {
+			return fmt.Errorf("create idx_artifacts_job for excluded_changes migration: %w", err)
+		}
+
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("commit artifacts excluded_changes migration: %w", err)
+		}
+		return nil
+	})
+}
+
+func (s *Store) migrateIssuesForRecurringSource() error {
+	sqlText, err := s.tableSQL("issues")
+	if err != nil {
+		return err
+	}
+	if strings.Contains(sqlText, "'recurring'") {
+		return nil
+	}
+
+	return s.withForeignKeysOff(func() error {
+		tx, err := s.Writer.Begin()
+		if err != nil {
+			return fmt.Errorf("begin issues recurring migration: %w", err)
+		}
+		defer tx.Rollback()
+
+		if _, err := tx.Exec(`
+CREATE TABLE issues_new (
+    autopr_issue_id   TEXT PRIMARY KEY,
+    project_name      TEXT NOT NULL,
+    source            TEXT NOT NULL CHECK(source IN ('gitlab', 'github', 'sentry', 'recurring')),
+    source_issue_id   TEXT NOT NULL,
+    title             TEXT NOT NULL,
+    body              TEXT NOT NULL DEFAULT '',
+    url               TEXT NOT NULL,
+    state             TEXT NOT NULL CHECK(state IN ('open', 'closed')),
+    labels_json       TEXT NOT NULL DEFAULT '[]',
+    source_meta_json  TEXT NOT NULL DEFAULT '{}',
+    eligible          INTEGER NOT NULL DEFAULT 1 CHECK(eligible IN (0,1)),
+    skip_reason       TEXT NOT NULL DEFAULT '',
+    evaluated_at      TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now')),
+    source_updated_at TEXT NOT NULL,
+    synced_at         TEXT NOT NULL,
+    UNIQUE(project_name, source, source_issue_id)
+)`); err != nil {
+			return fmt.Errorf("create issues_new for recurring migration: %w", err)
+		}
+
+		if _, err := tx.Exec(`
+INSERT INTO issues_new (
+    autopr_issue_id, project_name, source, source_issue_id, title, body, url, state,
+    labels_json, source_meta_json, eligible, skip_reason, evaluated_at, source_updated_at, synced_at
+)
+SELECT
+    autopr_issue_id, project_name, source, source_issue_id, title, body, url, state,
+    labels_json, source_meta_json, eligible, skip_reason, evaluated_at, source_updated_at, synced_at
+FROM issues`); err != nil {
+			return fmt.Errorf("copy issues rows for recurring migration: %w", err)
+		}
+
+		if _, err := tx.Exec(`DROP TABLE issues`); err != nil {
+			return fmt.Errorf("drop issues for recurring migration: %w", err)
+		}
+		if _, err := tx.Exec(`ALTER TABLE issues_new RENAME TO issues`); err != nil {
+			return fmt.Errorf("rename issues_new for recurring migration: %w", err)
+		}
+
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("commit issues recurring migration: %w", err)
+		}
+		return nil
+	})
+}
+
+func (s *Store) migrateArtifactsForBisectResultKind() error {
+	sqlText, err := s.tableSQL("artifacts")
+	if err != nil {
+		return err
+	}
+	if strings.Contains(sqlText, "'bisect_result'") {
+		return nil
+	}
+
+	return s.withForeignKeysOff(func() error {
+		tx, err := s.Writer.Begin()
+		if err != nil {
+			return fmt.Errorf("begin artifacts bisect_result migration: %w", err)
+		}
+		defer tx.Rollback()
+
+		if _, err := tx.Exec(`
+CREATE TABLE artifacts_new (
+    id               INTEGER PRIMARY KEY AUTOINCREMENT,
+    job_id           TEXT NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
+    autopr_issue_id  TEXT NOT NULL,
+    kind             TEXT NOT NULL CHECK(kind IN ('plan','plan_review','code_review','test_output','rebase_conflict','rebase_result','excluded_changes','bisect_result')),
+    content          TEXT NOT NULL,
+    iteration        INTEGER NOT NULL DEFAULT 0,
+    commit_sha       TEXT,
+    created_at       TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now'))
+)`); err != nil {
+			return fmt.Errorf("create artifacts_new for bisect_result migration: %w", err)
+		}
+
+		if _, err := tx.Exec(`
+INSERT INTO artifacts_new (
+    id, job_id, autopr_issue_id, kind, content, iteration, commit_sha, created_at
+)
+SELECT
+    id, job_id, autopr_issue_id, kind, content, iteration, commit_sha, created_at
+FROM artifacts`); err != nil {
+			return fmt.Errorf("copy artifacts rows for bisect_result migration: %w", err)
+		}
+
+		if _, err := tx.Exec(`DROP TABLE artifacts`); err != nil {
+			return fmt.Errorf("drop artifacts for bisect_result migration: %w", err)
+		}
+		if _, err := tx.Exec(`ALTER TABLE artifacts_new RENAME TO artifacts`); err != nil {
+			return fmt.Errorf("rename artifacts_new for bisect_result migration: %w", err)
+		}
+		if _, err := tx.Exec(`CREATE INDEX IF NOT EXISTS idx_artifacts_job ON artifacts(job_id)`); err != nil {
+			return fmt.Errorf("create idx_artifacts_job for bisect_result migration: %w", err)
+		}
+
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("commit artifacts bisect_result migration: %w", err)
+		}
+		return nil
+	})
+}
+
+func (s *Store) migrateIssuesForBisectSource() error {
+	sqlText, err := s.tableSQL("issues")
+	if err != nil {
+		return err
+	}
+	if strings.Contains(sqlText, "'bisect'") {
+		return nil
+	}
+
+	return s.withForeignKeysOff(func() error {
+		tx, err := s.Writer.Begin()
+		if err != nil {
+			return fmt.Errorf("begin issues bisect migration: %w", err)
+		}
+		defer tx.Rollback()
+
+		if _, err := tx.Exec(`
+CREATE TABLE issues_new (
+    autopr_issue_id   TEXT PRIMARY KEY,
+    project_name      TEXT NOT NULL,
+    source            TEXT NOT NULL CHECK(source IN ('gitlab', 'github', 'sentry', 'recurring', 'bisect')),
+    source_issue_id   TEXT NOT NULL,
+    title             TEXT NOT NULL,
+    body              TEXT NOT NULL DEFAULT '',
+    url               TEXT NOT NULL,
+    state             TEXT NOT NULL CHECK(state IN ('open', 'closed')),
+    labels_json       TEXT NOT NULL DEFAULT '[]',
+    source_meta_json  TEXT NOT NULL DEFAULT '{}',
+    eligible          INTEGER NOT NULL DEFAULT 1 CHECK(eligible IN (0,1)),
+    skip_reason       TEXT NOT NULL DEFAULT '',
+    evaluated_at      TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now')),
+    source_updated_at TEXT NOT NULL,
+    synced_at         TEXT NOT NULL,
+    UNIQUE(project_name, source, source_issue_id)
+)`); err != nil {
+			return fmt.Errorf("create issues_new for bisect migration: %w", err)
+		}
+
+		if _, err := tx.Exec(`
+INSERT INTO issues_new (
+    autopr_issue_id, project_name, source, source_issue_id, title, body, url, state,
+    labels_json, source_meta_json, eligible, skip_reason, evaluated_at, source_updated_at, synced_at
+)
+SELECT
+    autopr_issue_id, project_name, source, source_issue_id, title, body, url, state,
+    labels_json, source_meta_json, eligible, skip_reason, evaluated_at, source_updated_at, synced_at
+FROM issues`); err != nil {
+			return fmt.Errorf("copy issues rows for bisect migration: %w", err)
+		}
+
+		if _, err := tx.Exec(`DROP TABLE issues`); err != nil {
+			return fmt.Errorf("drop issues for bisect migration: %w", err)
+		}
+		if _, err := tx.Exec(`ALTER TABLE issues_new RENAME TO issues`); err != nil {
+			return fmt.Errorf("rename issues_new for bisect migration: %w", err)
+		}
+
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("commit issues bisect migration: %w", err)
+		}
+		return nil
+	})
+}
+
+func (s *Store) migrateIssuesForGiteaSource() error {
+	sqlText, err := s.tableSQL("issues")
+	if err != nil {
+		return err
+	}
+	if strings.Contains(sqlText, "'gitea'") {
+		return nil
+	}
+
+	return s.withForeignKeysOff(func() error {
+		tx, err := s.Writer.Begin()
+		if err != nil {
+			return fmt.Errorf("begin issues gitea migration: %w", err)
+		}
+		defer tx.Rollback()
+
+		if _, err := tx.Exec(`
+CREATE TABLE issues_new (
+    autopr_issue_id   TEXT PRIMARY KEY,
+    project_name      TEXT NOT NULL,
+    source            TEXT NOT NULL CHECK(source IN ('gitlab', 'github', 'gitea', 'sentry', 'recurring', 'bisect')),
+    source_issue_id   TEXT NOT NULL,
+    title             TEXT NOT NULL,
+    body              TEXT NOT NULL DEFAULT '',
+    url               TEXT NOT NULL,
+    state             TEXT NOT NULL CHECK(state IN ('open', 'closed')),
+    labels_json       TEXT NOT NULL DEFAULT '[]',
+    source_meta_json  TEXT NOT NULL DEFAULT '{}',
+    eligible          INTEGER NOT NULL DEFAULT 1 CHECK(eligible IN (0,1)),
+    skip_reason       TEXT NOT NULL DEFAULT '',
+    evaluated_at      TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now')),
+    source_updated_at TEXT NOT NULL,
+    synced_at         TEXT NOT NULL,
+    UNIQUE(project_name, source, source_issue_id)
+)`); err != nil {
+			return fmt.Errorf("create issues_new for gitea migration: %w", err)
+		}
+
+		if _, err := tx.Exec(`
+INSERT INTO issues_new (
+    autopr_issue_id, project_name, source, source_issue_id, title, body, url, state,
+    labels_json, source_meta_json, eligible, skip_reason, evaluated_at, source_updated_at, synced_at
+)
+SELECT
+    autopr_issue_id, project_name, source, source_issue_id, title, body, url, state,
+    labels_json, source_meta_json, eligible, skip_reason, evaluated_at, source_updated_at, synced_at
+FROM issues`); err != nil {
+			return fmt.Errorf("copy issues rows for gitea migration: %w", err)
+		}
+
+		if _, err := tx.Exec(`DROP TABLE issues`); err != nil {
+			return fmt.Errorf("drop issues for gitea migration: %w", err)
+		}
+		if _, err := tx.Exec(`ALTER TABLE issues_new RENAME TO issues`); err != nil {
+			return fmt.Errorf("rename issues_new for gitea migration: %w", err)
+		}
+
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("commit issues gitea migration: %w", err)
+		}
+		return nil
+	})
+}
+
+func (s *Store) migrateSyncCursorsForGiteaSource() error {
+	sqlText, err := s.tableSQL("sync_cursors")
+	if err != nil {
+		return err
+	}
+	if strings.Contains(sqlText, "'gitea'") {
+		return nil
+	}
+
+	return s.withForeignKeysOff(func() error {
+		tx, err := s.Writer.Begin()
+		if err != nil {
+			return fmt.Errorf("begin sync_cursors gitea migration: %w", err)
+		}
+		defer tx.Rollback()
+
+		if _, err := tx.Exec(`
+CREATE TABLE sync_cursors_new (
+    project_name   TEXT NOT NULL,
+    source         TEXT NOT NULL CHECK(source IN ('gitlab', 'github', 'gitea', 'sentry')),
+    cursor_value   TEXT NOT NULL DEFAULT '',
+    last_synced_at TEXT NOT NULL,
+    PRIMARY KEY(project_name, source)
+)`); err != nil {
+			return fmt.Errorf("create sync_cursors_new for gitea migration: %w", err)
+		}
+
+		if _, err := tx.Exec(`
+INSERT INTO sync_cursors_new (project_name, source, cursor_value, last_synced_at)
+SELECT project_name, source, cursor_value, last_synced_at
+FROM sync_cursors`); err != nil {
+			return fmt.Errorf("copy sync_cursors rows for gitea migration: %w", err)
+		}
+
+		if _, err := tx.Exec(`DROP TABLE sync_cursors`); err != nil {
+			return fmt.Errorf("drop sync_cursors for gitea migration: %w", err)
+		}
+		if _, err := tx.Exec(`ALTER TABLE sync_cursors_new RENAME TO sync_cursors`); err != nil {
+			return fmt.Errorf("rename sync_cursors_new for gitea migration: %w", err)
+		}
+
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("commit sync_cursors gitea migration: %w", err)
+		}
+		return nil
+	})
+}
+
 // migrateNotificationEventsNeedsPR renames event_type 'awaiting_approval' → 'needs_pr'
 // and recreates the table with an updated CHECK constraint.
 func (s *Store) migrateNotificationEventsNeedsPR() error {
@@ -828,20 +1383,90 @@ FROM notification_events`); err != nil {
 	})
 }
 
+// migrateNotificationEventsDeadStatus recreates notification_events so its
+// status CHECK accepts 'dead' (dead-lettered events).
+func (s *Store) migrateNotificationEventsDeadStatus() error {
+	sqlText, err := s.tableSQL("notification_events")
+	if err != nil {
+		return err
+	}
+	if strings.Contains(sqlText, "'dead'") {
+		return nil
+	}
+
+	return s.withForeignKeysOff(func() error {
+		tx, err := s.Writer.Begin()
+		if err != nil {
+			return fmt.Errorf("begin notification_events dead migration: %w", err)
+		}
+		defer tx.Rollback()
+
+		if _, err := tx.Exec(`
+CREATE TABLE notification_events_new (
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    job_id     TEXT NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
+    event_type TEXT NOT NULL CHECK(event_type IN ('needs_pr','failed','pr_created','pr_merged')),
+    status     TEXT NOT NULL DEFAULT 'pending' CHECK(status IN ('pending','processing','sent','failed','skipped','dead')),
+    attempts   INTEGER NOT NULL DEFAULT 0 CHECK(attempts >= 0),
+    last_error TEXT NOT NULL DEFAULT '',
+    created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now')),
+    updated_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now'))
+)`); err != nil {
+			return fmt.Errorf("create notification_events_new: %w", err)
+		}
+
+		if _, err := tx.Exec(`
+INSERT INTO notification_events_new (id, job_id, event_type, status, attempts, last_error, created_at, updated_at)
+SELECT id, job_id, event_type, status, attempts, last_error, created_at, updated_at
+FROM notification_events`); err != nil {
+			return fmt.Errorf("copy notification_events rows: %w", err)
+		}
+
+		if _, err := tx.Exec(`DROP TABLE notification_events`); err != nil {
+			return fmt.Errorf("drop notification_events: %w", err)
+		}
+		if _, err := tx.Exec(`ALTER TABLE notification_events_new RENAME TO notification_events`); err != nil {
+			return fmt.Errorf("rename notification_events_new: %w", err)
+		}
+		if _, err := tx.Exec(`CREATE INDEX IF NOT EXISTS idx_notification_events_status_created ON notification_events(status, created_at)`); err != nil {
+			return fmt.Errorf("create idx_notification_events_status_created: %w", err)
+		}
+		if _, err := tx.Exec(`CREATE INDEX IF NOT EXISTS idx_notification_events_job ON notification_events(job_id)`); err != nil {
+			return fmt.Errorf("create idx_notification_events_job: %w", err)
+		}
+
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("commit notification_events dead migration: %w", err)
+		}
+		return nil
+	})
+}
+
+// RecoveredJob is a job that RecoverInFlightJobs reset.
+type RecoveredJob struct {
+	ID         string
+	Project    string
+	FromState  string // state the interrupted run was in
+	State      string // queued, or ready for interrupted rebases
+	Recoveries int    // times the job was interrupted by a restart, this one included
+}
+
 // RecoverInFlightJobs resets any jobs stuck in active states back to queued,
 // except rebasing/resolving_conflicts which return to ready to continue readiness checks.
+// Backport and revert jobs always return to queued since they restart from scratch.
+// Each reset job's recoveries count goes up by one.
 // Called on daemon startup after a crash.
 //
 // reposRoot is used as a safety boundary so cleanup logic only removes metadata
 // under the configured repository root.
-func (s *Store) RecoverInFlightJobs(ctx context.Context, reposRoot string) (int64, error) {
+func (s *Store) RecoverInFlightJobs(ctx context.Context, reposRoot string) ([]RecoveredJob, error) {
 	inFlightQuery := `
 SELECT id, state, COALESCE(worktree_path, '')
 FROM jobs
 WHERE state IN ('planning', 'implementing', 'reviewing', 'testing', 'rebasing', 'resolving_conflicts')`
 	rows, err := s.Reader.QueryContext(ctx, inFlightQuery)
 	if err != nil {
-		return 0, fmt.Errorf("recover in-flight jobs: query in-flight jobs: %w", err)
+		return nil, fmt.Errorf("recover in-flight jobs: query in-flight jobs: %w", err)
 	}
 	defer rows.Close()
 	type inflightJob struct {
@@ -853,15 +1478,17 @@ WHERE state IN ('planning', 'implementing', 'reviewing', 'testing', 'rebasing',
 	for rows.Next() {
 		var job inflightJob
 		if err := rows.Scan(&job.ID, &job.State, &job.Worktree); err != nil {
-			return 0, fmt.Errorf("recover in-flight jobs: scan in-flight job: %w", err)
+			return nil, fmt.Errorf("recover in-flight jobs: scan in-flight job: %w", err)
 		}
 		recoveredJobs = append(recoveredJobs, job)
 	}
 	if err := rows.Err(); err != nil {
-		return 0, fmt.Errorf("recover in-flight jobs: in-flight rows: %w", err)
+		return nil, fmt.Errorf("recover in-flight jobs: in-flight rows: %w", err)
 	}
 
+	fromStates := make(map[string]string, len(recoveredJobs))
 	for _, job := range recoveredJobs {
+		fromStates[job.ID] = job.State
 		if job.State != "rebasing" && job.State != "resolving_conflicts" {
 			continue
 		}
@@ -870,17 +1497,32 @@ WHERE state IN ('planning', 'implementing', 'reviewing', 'testing', 'rebasing',
 		}
 	}
 
-	res, err := s.Writer.ExecContext(ctx,
+	updated, err := s.Writer.QueryContext(ctx,
 		`UPDATE jobs
 	SET state = CASE
-		WHEN state IN ('rebasing', 'resolving_conflicts') THEN 'ready'
+		WHEN state IN ('rebasing', 'resolving_conflicts') AND backport_branch = '' AND revert_commit = '' THEN 'ready'
 		ELSE 'queued'
 	END,
+	recoveries = recoveries + 1,
 	updated_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now')
-	WHERE state IN ('planning', 'implementing', 'reviewing', 'testing', 'rebasing', 'resolving_conflicts')`)
+	WHERE state IN ('planning', 'implementing', 'reviewing', 'testing', 'rebasing', 'resolving_conflicts')
+	RETURNING id, project_name, state, recoveries`)
 	if err != nil {
-		return 0, fmt.Errorf("recover in-flight jobs: %w", err)
+		return nil, fmt.Errorf("recover in-flight jobs: %w", err)
 	}
-
-	return res.RowsAffected()
+	defer updated.Close()
+	var out []RecoveredJob
+	for updated.Next() {
+		var job RecoveredJob
+		if err := updated.Scan(&job.ID, &job.Project, &job.State, &job.Recoveries); err != nil {
+			return nil, fmt.Errorf("recover in-flight jobs: scan recovered job: %w", err)
+		}
+		job.FromState = fromStates[job.ID]
+		out = append(out, job)
+	}
+	if err := updated.Err(); err != nil {
+		return nil, fmt.Errorf("recover in-flight jobs: %w", err)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out, nil
 }
@@ -0,0 +1,97 @@
+package db
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// SessionFlushInterval is how often a SessionBuffer writes a streaming
+// session's partial response to the database.
+const SessionFlushInterval = 5 * time.Second
+
+const updateSessionProgressQuery = `
+UPDATE llm_sessions SET response_text = ?, input_tokens = ?, output_tokens = ?
+WHERE id = ? AND status = 'running'`
+
+// SessionBuffer collects a running LLM session's streaming output in memory
+// and writes the latest partial response and token counts at most once per
+// interval, so `ap logs` and the TUI see progress without one write per
+// streamed line. CompleteSession writes the final response.
+type SessionBuffer struct {
+	store     *Store
+	sessionID int64
+
+	mu           sync.Mutex
+	text         string
+	inputTokens  int
+	outputTokens int
+	dirty        bool
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewSessionBuffer starts buffering progress for sessionID, flushing every
+// interval until Close.
+func (s *Store) NewSessionBuffer(sessionID int64, interval time.Duration) *SessionBuffer {
+	b := &SessionBuffer{
+		store:     s,
+		sessionID: sessionID,
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	go b.run(interval)
+	return b
+}
+
+// Update records the session's latest response text and token counts.
+func (b *SessionBuffer) Update(text string, inputTokens, outputTokens int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if text == b.text && inputTokens == b.inputTokens && outputTokens == b.outputTokens {
+		return
+	}
+	b.text, b.inputTokens, b.outputTokens = text, inputTokens, outputTokens
+	b.dirty = true
+}
+
+// Close stops flushing. Pending progress is dropped: the caller completes the
+// session with the final response instead.
+func (b *SessionBuffer) Close() {
+	close(b.stop)
+	<-b.done
+}
+
+func (b *SessionBuffer) run(interval time.Duration) {
+	defer close(b.done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-b.stop:
+			return
+		case <-ticker.C:
+		}
+		if err := b.flush(); err != nil {
+			slog.Warn("flush llm session progress", "session_id", b.sessionID, "err", err)
+		}
+	}
+}
+
+func (b *SessionBuffer) flush() error {
+	b.mu.Lock()
+	if !b.dirty {
+		b.mu.Unlock()
+		return nil
+	}
+	text, in, out := b.text, b.inputTokens, b.outputTokens
+	b.dirty = false
+	b.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, err := b.store.execBusy(ctx, "update session progress", updateSessionProgressQuery, text, in, out, b.sessionID)
+	return err
+}
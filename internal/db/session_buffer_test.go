@@ -0,0 +1,79 @@
+package db
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSessionBufferFlushesProgressUntilComplete(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	store, err := Open(filepath.Join(t.TempDir(), "autopr.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer store.Close()
+
+	issueID, err := store.UpsertIssue(ctx, IssueUpsert{
+		ProjectName:   "myproject",
+		Source:        "github",
+		SourceIssueID: "99",
+		Title:         "stream me",
+		URL:           "https://github.com/org/repo/issues/99",
+		State:         "open",
+	})
+	if err != nil {
+		t.Fatalf("upsert issue: %v", err)
+	}
+	jobID, err := store.CreateJob(ctx, issueID, "myproject", 3)
+	if err != nil {
+		t.Fatalf("create job: %v", err)
+	}
+	sessionID, err := store.CreateSession(ctx, jobID, "plan", 0, "claude", "")
+	if err != nil {
+		t.Fatalf("create session: %v", err)
+	}
+
+	buf := store.NewSessionBuffer(sessionID, 10*time.Millisecond)
+	buf.Update("first", 10, 1)
+	buf.Update("partial plan", 20, 5)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		sess, err := store.GetFullSession(ctx, int(sessionID))
+		if err != nil {
+			t.Fatalf("get session: %v", err)
+		}
+		if sess.ResponseText == "partial plan" {
+			if sess.Status != "running" || sess.InputTokens != 20 || sess.OutputTokens != 5 {
+				t.Fatalf("unexpected partial session: %+v", sess)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("partial response never flushed, got %q", sess.ResponseText)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// Completion writes the final response; late progress cannot overwrite it.
+	if err := store.CompleteSession(ctx, sessionID, "completed", "final plan", "prompt", "", "", "", "", 30, 8, 100); err != nil {
+		t.Fatalf("complete session: %v", err)
+	}
+	buf.Update("stale partial", 25, 6)
+	if err := buf.flush(); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+	buf.Close()
+
+	sess, err := store.GetFullSession(ctx, int(sessionID))
+	if err != nil {
+		t.Fatalf("get session: %v", err)
+	}
+	if sess.Status != "completed" || sess.ResponseText != "final plan" || sess.InputTokens != 30 || sess.OutputTokens != 8 {
+		t.Fatalf("expected final response kept, got %+v", sess)
+	}
+}
@@ -1,46 +1,92 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"strings"
+	"sync"
 
 	_ "github.com/mattn/go-sqlite3"
 )
 
 // Store provides read/write access to the SQLite database.
 // Writer has MaxOpenConns(1); Reader has MaxOpenConns(4) and is read-only.
+// Writer transactions take SQLite's write lock when they begin, so writes from
+// the daemon, CLI, and TUI processes queue on busy_timeout instead of
+// interleaving. Multi-step job mutations also hold a job lease (leases.go).
+// The pipeline's hot write paths retry lock errors that outlast busy_timeout
+// (busy.go).
 type Store struct {
 	Writer *sql.DB
 	Reader *sql.DB
 	path   string
+	key    string
+
+	contention contentionStats
+
+	stmtMu sync.Mutex
+	stmts  map[string]*sql.Stmt // prepared Writer statements, by query
 }
 
 func Open(path string) (*Store, error) {
-	writerDSN := fmt.Sprintf("file:%s?_journal_mode=WAL&_busy_timeout=5000&_synchronous=NORMAL&_foreign_keys=ON", path)
-	w, err := sql.Open("sqlite3", writerDSN)
+	return OpenWithKey(path, "")
+}
+
+// OpenWithKey opens the database like Open. A non-empty key opens it as an
+// SQLCipher database, which needs an ap build linked against SQLCipher.
+func OpenWithKey(path, key string) (*Store, error) {
+	w, err := openDB(path, key, fmt.Sprintf("_journal_mode=WAL&_busy_timeout=%d&_synchronous=NORMAL&_foreign_keys=ON&_txlock=immediate", busyTimeoutMS))
 	if err != nil {
 		return nil, fmt.Errorf("open writer db: %w", err)
 	}
 	w.SetMaxOpenConns(1)
 
-	readerDSN := fmt.Sprintf("file:%s?_journal_mode=WAL&_busy_timeout=5000&_foreign_keys=ON&mode=ro", path)
-	r, err := sql.Open("sqlite3", readerDSN)
+	r, err := openDB(path, key, fmt.Sprintf("_journal_mode=WAL&_busy_timeout=%d&_foreign_keys=ON&mode=ro", busyTimeoutMS))
 	if err != nil {
 		_ = w.Close()
 		return nil, fmt.Errorf("open reader db: %w", err)
 	}
 	r.SetMaxOpenConns(4)
 
-	s := &Store{Writer: w, Reader: r, path: path}
+	s := &Store{Writer: w, Reader: r, path: path, key: key}
 	if err := s.createSchema(); err != nil {
 		_ = w.Close()
 		_ = r.Close()
+		if key == "" && strings.Contains(err.Error(), "file is not a database") {
+			return nil, fmt.Errorf("%w (if the database is encrypted, set db_key in credentials.toml or AUTOPR_DB_KEY)", err)
+		}
 		return nil, err
 	}
 	return s, nil
 }
 
+// prepared returns a Writer statement for query, preparing it on first use.
+// Hot-path writes reuse it instead of re-parsing the SQL each call.
+func (s *Store) prepared(ctx context.Context, query string) (*sql.Stmt, error) {
+	s.stmtMu.Lock()
+	defer s.stmtMu.Unlock()
+	if stmt, ok := s.stmts[query]; ok {
+		return stmt, nil
+	}
+	stmt, err := s.Writer.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	if s.stmts == nil {
+		s.stmts = make(map[string]*sql.Stmt)
+	}
+	s.stmts[query] = stmt
+	return stmt, nil
+}
+
 func (s *Store) Close() error {
+	s.stmtMu.Lock()
+	for _, stmt := range s.stmts {
+		_ = stmt.Close()
+	}
+	s.stmts = nil
+	s.stmtMu.Unlock()
 	e1 := s.Reader.Close()
 	e2 := s.Writer.Close()
 	if e1 != nil {
@@ -0,0 +1,126 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// ProjectSyncStatus is the outcome of the most recent issue syncs of a project.
+type ProjectSyncStatus struct {
+	ProjectName   string
+	LastSuccessAt string        // RFC3339, UTC; empty until a sync succeeds
+	LastError     string        // error of the last failed sync, kept after later successes
+	LastErrorAt   string        // RFC3339, UTC; empty when no sync has failed
+	LastDuration  time.Duration // how long the most recent sync took
+}
+
+// syncRunsKept is how many sync_runs rows are kept per project.
+const syncRunsKept = 50
+
+// SyncRun is one recorded issue sync of a project.
+type SyncRun struct {
+	ID          int64
+	ProjectName string
+	FinishedAt  string // RFC3339, UTC
+	Duration    time.Duration
+	Error       string // empty when the sync succeeded
+}
+
+// RecordProjectSync records the outcome of one sync of project and how long
+// it took: the success time when syncErr is nil, otherwise the error and when
+// it happened. The run is also added to the project's sync history.
+func (s *Store) RecordProjectSync(ctx context.Context, project string, took time.Duration, syncErr error) error {
+	now := nowRFC3339()
+	ms := took.Milliseconds()
+	errText := ""
+	if syncErr != nil {
+		errText = trimNotificationError(syncErr.Error())
+	}
+
+	tx, err := s.Writer.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin record sync for %s: %w", project, err)
+	}
+	defer tx.Rollback()
+	if syncErr == nil {
+		_, err = tx.ExecContext(ctx, `
+INSERT INTO project_sync_status(project_name, last_success_at, last_duration_ms) VALUES(?, ?, ?)
+ON CONFLICT(project_name) DO UPDATE SET
+    last_success_at = excluded.last_success_at,
+    last_duration_ms = excluded.last_duration_ms`, project, now, ms)
+	} else {
+		_, err = tx.ExecContext(ctx, `
+INSERT INTO project_sync_status(project_name, last_error, last_error_at, last_duration_ms) VALUES(?, ?, ?, ?)
+ON CONFLICT(project_name) DO UPDATE SET
+    last_error = excluded.last_error,
+    last_error_at = excluded.last_error_at,
+    last_duration_ms = excluded.last_duration_ms`, project, errText, now, ms)
+	}
+	if err != nil {
+		return fmt.Errorf("record sync status for %s: %w", project, err)
+	}
+	if _, err := tx.ExecContext(ctx, `
+INSERT INTO sync_runs(project_name, finished_at, duration_ms, error) VALUES(?, ?, ?, ?)`,
+		project, now, ms, errText); err != nil {
+		return fmt.Errorf("record sync run for %s: %w", project, err)
+	}
+	if _, err := tx.ExecContext(ctx, `
+DELETE FROM sync_runs WHERE project_name = ? AND id <= (
+    SELECT id FROM sync_runs WHERE project_name = ? ORDER BY id DESC LIMIT 1 OFFSET ?)`,
+		project, project, syncRunsKept); err != nil {
+		return fmt.Errorf("prune sync runs for %s: %w", project, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit sync status for %s: %w", project, err)
+	}
+	return nil
+}
+
+// ListSyncRuns returns up to limit of project's most recent sync runs, newest
+// first.
+func (s *Store) ListSyncRuns(ctx context.Context, project string, limit int) ([]SyncRun, error) {
+	rows, err := s.Reader.QueryContext(ctx, `
+SELECT id, project_name, finished_at, duration_ms, error
+FROM sync_runs WHERE project_name = ? ORDER BY id DESC LIMIT ?`, project, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list sync runs: %w", err)
+	}
+	defer rows.Close()
+
+	var out []SyncRun
+	for rows.Next() {
+		var run SyncRun
+		var ms int64
+		if err := rows.Scan(&run.ID, &run.ProjectName, &run.FinishedAt, &ms, &run.Error); err != nil {
+			return nil, fmt.Errorf("scan sync run: %w", err)
+		}
+		run.Duration = time.Duration(ms) * time.Millisecond
+		out = append(out, run)
+	}
+	return out, rows.Err()
+}
+
+// ListProjectSyncStatus returns the recorded sync status of every project
+// ordered by name.
+func (s *Store) ListProjectSyncStatus(ctx context.Context) ([]ProjectSyncStatus, error) {
+	rows, err := s.Reader.QueryContext(ctx, `
+SELECT project_name, last_success_at, last_error, last_error_at, last_duration_ms
+FROM project_sync_status ORDER BY project_name`)
+	if err != nil {
+		return nil, fmt.Errorf("list project sync status: %w", err)
+	}
+	defer rows.Close()
+
+	var out []ProjectSyncStatus
+	for rows.Next() {
+		var st ProjectSyncStatus
+		var ms int64
+		if err := rows.Scan(&st.ProjectName, &st.LastSuccessAt, &st.LastError, &st.LastErrorAt, &ms); err != nil {
+			return nil, fmt.Errorf("scan project sync status: %w", err)
+		}
+		st.LastDuration = time.Duration(ms) * time.Millisecond
+		out = append(out, st)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,83 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRecordProjectSyncKeepsSuccessAndError(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	store, err := Open(filepath.Join(t.TempDir(), "autopr.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer store.Close()
+
+	if err := store.RecordProjectSync(ctx, "proj", 2*time.Second, nil); err != nil {
+		t.Fatalf("record success: %v", err)
+	}
+	if err := store.RecordProjectSync(ctx, "proj", 1500*time.Millisecond, errors.New("gitlab sync: HTTP 502")); err != nil {
+		t.Fatalf("record error: %v", err)
+	}
+
+	statuses, err := store.ListProjectSyncStatus(ctx)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(statuses) != 1 {
+		t.Fatalf("expected 1 project, got %+v", statuses)
+	}
+	st := statuses[0]
+	if st.ProjectName != "proj" || st.LastSuccessAt == "" || st.LastErrorAt == "" || st.LastError != "gitlab sync: HTTP 502" || st.LastDuration != 1500*time.Millisecond {
+		t.Fatalf("expected both success and error recorded with the last duration, got %+v", st)
+	}
+}
+
+func TestRecordProjectSyncKeepsRecentRuns(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	store, err := Open(filepath.Join(t.TempDir(), "autopr.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer store.Close()
+
+	for i := range syncRunsKept + 5 {
+		var syncErr error
+		if i == syncRunsKept+4 {
+			syncErr = errors.New("github sync: HTTP 401")
+		}
+		if err := store.RecordProjectSync(ctx, "proj", time.Duration(i)*time.Millisecond, syncErr); err != nil {
+			t.Fatalf("record run %d: %v", i, err)
+		}
+	}
+	if err := store.RecordProjectSync(ctx, "other", time.Second, nil); err != nil {
+		t.Fatalf("record other: %v", err)
+	}
+
+	runs, err := store.ListSyncRuns(ctx, "proj", 100)
+	if err != nil {
+		t.Fatalf("list runs: %v", err)
+	}
+	if len(runs) != syncRunsKept {
+		t.Fatalf("expected %d runs kept, got %d", syncRunsKept, len(runs))
+	}
+	if runs[0].Error != "github sync: HTTP 401" || runs[0].Duration != time.Duration(syncRunsKept+4)*time.Millisecond {
+		t.Fatalf("expected newest run first, got %+v", runs[0])
+	}
+	if runs[1].Error != "" || runs[len(runs)-1].Duration != 5*time.Millisecond {
+		t.Fatalf("expected oldest runs pruned, got first ok %+v and last %+v", runs[1], runs[len(runs)-1])
+	}
+
+	other, err := store.ListSyncRuns(ctx, "other", 10)
+	if err != nil {
+		t.Fatalf("list other runs: %v", err)
+	}
+	if len(other) != 1 {
+		t.Fatalf("expected other project's run kept, got %+v", other)
+	}
+}
@@ -0,0 +1,150 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+)
+
+// trashInterval is how often RunTrashLoop empties expired jobs from the trash.
+const trashInterval = time.Hour
+
+// finishedJobCondition matches jobs with nothing left to do: rejected,
+// failed, or cancelled, or approved with no open PR. It is the complement of
+// the active-job check in HasActiveJobForIssue.
+const finishedJobCondition = `(state IN ('rejected', 'failed', 'cancelled')
+	OR (state = 'approved' AND (COALESCE(pr_url,'') = '' OR COALESCE(pr_merged_at,'') != '' OR COALESCE(pr_closed_at,'') != '')))`
+
+// DeleteJob moves a finished job to the trash. Trashed jobs are hidden from
+// ListJobs and can be brought back with RestoreJob until the trash is
+// emptied.
+func (s *Store) DeleteJob(ctx context.Context, jobID string) error {
+	res, err := s.Writer.ExecContext(ctx, `
+UPDATE jobs SET deleted_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now'),
+                updated_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now')
+WHERE id = ? AND deleted_at = '' AND `+finishedJobCondition, jobID)
+	if err != nil {
+		return fmt.Errorf("delete job %s: %w", jobID, err)
+	}
+	if n, _ := res.RowsAffected(); n > 0 {
+		return nil
+	}
+	job, err := s.GetJob(ctx, jobID)
+	if err != nil {
+		return err
+	}
+	if job.DeletedAt != "" {
+		return fmt.Errorf("job %s is already in the trash", ShortID(jobID))
+	}
+	if job.State == "approved" {
+		return fmt.Errorf("job %s has an open PR; merge or close it first", ShortID(jobID))
+	}
+	return fmt.Errorf("job %s is %s; only approved, rejected, failed, and cancelled jobs can be deleted", ShortID(jobID), job.State)
+}
+
+// RestoreJob takes a job out of the trash.
+func (s *Store) RestoreJob(ctx context.Context, jobID string) error {
+	res, err := s.Writer.ExecContext(ctx, `
+UPDATE jobs SET deleted_at = '', updated_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now')
+WHERE id = ? AND deleted_at != ''`, jobID)
+	if err != nil {
+		return fmt.Errorf("restore job %s: %w", jobID, err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		if _, err := s.GetJob(ctx, jobID); err != nil {
+			return err
+		}
+		return fmt.Errorf("job %s is not in the trash", ShortID(jobID))
+	}
+	return nil
+}
+
+// EmptyTrashBefore deletes the jobs moved to the trash at or before cutoff
+// for good, with their sessions, artifacts, notes, and other rows, and removes
+// their session transcript files. It returns the IDs of the deleted jobs.
+func (s *Store) EmptyTrashBefore(ctx context.Context, cutoff time.Time) ([]string, error) {
+	rows, err := s.Reader.QueryContext(ctx, `
+SELECT j.id, COALESCE(l.jsonl_path,'')
+FROM jobs j LEFT JOIN llm_sessions l ON l.job_id = j.id
+WHERE j.deleted_at != '' AND j.deleted_at <= ?
+ORDER BY j.id`, cutoff.UTC().Format(time.RFC3339))
+	if err != nil {
+		return nil, fmt.Errorf("list trashed jobs: %w", err)
+	}
+	var ids []string
+	transcripts := map[string][]string{}
+	for rows.Next() {
+		var id, path string
+		if err := rows.Scan(&id, &path); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan trashed job: %w", err)
+		}
+		if len(ids) == 0 || ids[len(ids)-1] != id {
+			ids = append(ids, id)
+		}
+		if path != "" {
+			transcripts[id] = append(transcripts[id], path)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list trashed jobs: %w", err)
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	tx, err := s.Writer.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin empty trash: %w", err)
+	}
+	defer tx.Rollback()
+	deleted := ids[:0]
+	for _, id := range ids {
+		// Rows that reference the job are removed by ON DELETE CASCADE. The
+		// deleted_at check skips a job restored since it was listed.
+		res, err := tx.ExecContext(ctx, `DELETE FROM jobs WHERE id = ? AND deleted_at != ''`, id)
+		if err != nil {
+			return nil, fmt.Errorf("delete job %s: %w", id, err)
+		}
+		if n, _ := res.RowsAffected(); n > 0 {
+			deleted = append(deleted, id)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit empty trash: %w", err)
+	}
+
+	for _, id := range deleted {
+		for _, path := range transcripts[id] {
+			if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+				slog.Warn("remove trashed job transcript", "job_id", id, "path", path, "err", err)
+			}
+		}
+	}
+	return deleted, nil
+}
+
+// RunTrashLoop deletes jobs that have been in the trash longer than after,
+// once at startup and then every hour, until ctx is cancelled.
+func (s *Store) RunTrashLoop(ctx context.Context, after time.Duration) {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+		ids, err := s.EmptyTrashBefore(ctx, time.Now().Add(-after))
+		if err != nil {
+			slog.Error("empty trash failed", "err", err)
+		} else if len(ids) > 0 {
+			slog.Info("deleted expired jobs from the trash", "jobs", len(ids))
+		}
+		timer.Reset(trashInterval)
+	}
+}
@@ -0,0 +1,122 @@
+package db
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDeleteRestoreAndEmptyTrash(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	tmp := t.TempDir()
+	store, err := Open(filepath.Join(tmp, "autopr.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer store.Close()
+
+	newJob := func(n, state, prURL string) string {
+		t.Helper()
+		issueID, err := store.UpsertIssue(ctx, IssueUpsert{ProjectName: "myproject", Source: "github", SourceIssueID: n, Title: "bug " + n, State: "open"})
+		if err != nil {
+			t.Fatalf("upsert issue: %v", err)
+		}
+		jobID, err := store.CreateJob(ctx, issueID, "myproject", 3)
+		if err != nil {
+			t.Fatalf("create job: %v", err)
+		}
+		if _, err := store.Writer.ExecContext(ctx, `UPDATE jobs SET state = ?, pr_url = ? WHERE id = ?`, state, prURL, jobID); err != nil {
+			t.Fatalf("set job state: %v", err)
+		}
+		return jobID
+	}
+	failed := newJob("1", "failed", "")
+	queued := newJob("2", "queued", "")
+	openPR := newJob("3", "approved", "https://github.com/org/repo/pull/3")
+
+	if err := store.DeleteJob(ctx, queued); err == nil || !strings.Contains(err.Error(), "is queued") {
+		t.Fatalf("delete queued job: err = %v", err)
+	}
+	if err := store.DeleteJob(ctx, openPR); err == nil || !strings.Contains(err.Error(), "open PR") {
+		t.Fatalf("delete job with open PR: err = %v", err)
+	}
+	if err := store.DeleteJob(ctx, failed); err != nil {
+		t.Fatalf("delete failed job: %v", err)
+	}
+	if err := store.DeleteJob(ctx, failed); err == nil || !strings.Contains(err.Error(), "already in the trash") {
+		t.Fatalf("delete twice: err = %v", err)
+	}
+
+	listIDs := func(state string) []string {
+		t.Helper()
+		jobs, err := store.ListJobs(ctx, "", state, "created_at", true)
+		if err != nil {
+			t.Fatalf("list jobs: %v", err)
+		}
+		var ids []string
+		for _, j := range jobs {
+			ids = append(ids, j.ID)
+		}
+		return ids
+	}
+	if got := listIDs("all"); len(got) != 2 || slices.Contains(got, failed) {
+		t.Fatalf("all jobs = %v, want the trashed job hidden", got)
+	}
+	if got := listIDs("failed"); len(got) != 0 {
+		t.Fatalf("failed jobs = %v, want none", got)
+	}
+	trash, err := store.ListJobs(ctx, "", "deleted", "updated_at", false)
+	if err != nil {
+		t.Fatalf("list trash: %v", err)
+	}
+	if len(trash) != 1 || trash[0].ID != failed || trash[0].DeletedAt == "" {
+		t.Fatalf("trash = %+v", trash)
+	}
+
+	if err := store.RestoreJob(ctx, failed); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	if got := listIDs("failed"); len(got) != 1 {
+		t.Fatalf("restored job not listed: %v", got)
+	}
+	if err := store.RestoreJob(ctx, failed); err == nil || !strings.Contains(err.Error(), "not in the trash") {
+		t.Fatalf("restore twice: err = %v", err)
+	}
+
+	// Emptying the trash removes the job, its rows, and its transcripts.
+	transcript := filepath.Join(tmp, "session.jsonl")
+	if err := os.WriteFile(transcript, []byte("{}\n"), 0o644); err != nil {
+		t.Fatalf("write transcript: %v", err)
+	}
+	if _, err := store.CreateSession(ctx, failed, "plan", 0, "claude", transcript); err != nil {
+		t.Fatalf("create session: %v", err)
+	}
+	if err := store.DeleteJob(ctx, failed); err != nil {
+		t.Fatalf("delete failed job: %v", err)
+	}
+	if ids, err := store.EmptyTrashBefore(ctx, time.Now().Add(-time.Hour)); err != nil || len(ids) != 0 {
+		t.Fatalf("empty trash before an hour ago: %v, %v; want nothing deleted", ids, err)
+	}
+	ids, err := store.EmptyTrashBefore(ctx, time.Now().Add(time.Minute))
+	if err != nil {
+		t.Fatalf("empty trash: %v", err)
+	}
+	if len(ids) != 1 || ids[0] != failed {
+		t.Fatalf("emptied %v, want %s", ids, failed)
+	}
+	if _, err := store.GetJob(ctx, failed); err == nil {
+		t.Fatal("job still exists after emptying the trash")
+	}
+	var sessions int
+	if err := store.Reader.QueryRowContext(ctx, `SELECT COUNT(*) FROM llm_sessions WHERE job_id = ?`, failed).Scan(&sessions); err != nil || sessions != 0 {
+		t.Fatalf("sessions left: %d, %v", sessions, err)
+	}
+	if _, err := os.Stat(transcript); !os.IsNotExist(err) {
+		t.Fatalf("transcript not removed: %v", err)
+	}
+}
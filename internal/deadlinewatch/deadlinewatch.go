@@ -0,0 +1,64 @@
+// Package deadlinewatch raises deadline_overdue alerts for unfinished jobs
+// that have passed their deadline.
+package deadlinewatch
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"autopr/internal/db"
+)
+
+// checkInterval is how often the daemon looks for overdue jobs.
+const checkInterval = time.Minute
+
+// Watcher finds overdue jobs and raises deadline_overdue alerts.
+type Watcher struct {
+	store *db.Store
+}
+
+func New(store *db.Store) *Watcher {
+	return &Watcher{store: store}
+}
+
+// Check flags jobs that passed their deadline before now and have not been
+// alerted on, sending one deadline_overdue notification per job, and returns
+// them.
+func (w *Watcher) Check(ctx context.Context, now time.Time) []db.OverdueJob {
+	overdue, err := w.store.ListOverdueJobs(ctx, now)
+	if err != nil {
+		slog.Error("deadline watch: list overdue jobs", "err", err)
+		return nil
+	}
+	var flagged []db.OverdueJob
+	for _, job := range overdue {
+		marked, err := w.store.MarkJobDeadlineOverdue(ctx, job.JobID)
+		if err != nil {
+			slog.Error("deadline watch: flag overdue job", "job", job.JobID, "err", err)
+			continue
+		}
+		if marked {
+			slog.Warn("job missed its deadline", "job", db.ShortID(job.JobID), "state", job.State, "deadline", job.Deadline)
+			flagged = append(flagged, job)
+		}
+	}
+	return flagged
+}
+
+// Run calls Check periodically until ctx is cancelled.
+func (w *Watcher) Run(ctx context.Context) {
+	slog.Info("deadline watch starting")
+	w.Check(ctx, time.Now())
+
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			w.Check(ctx, now)
+		}
+	}
+}
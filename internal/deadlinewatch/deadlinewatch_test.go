@@ -0,0 +1,58 @@
+package deadlinewatch
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"autopr/internal/db"
+)
+
+func TestCheckAlertsOnceOnOverdueJobs(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	store, err := db.Open(filepath.Join(t.TempDir(), "autopr.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer store.Close()
+
+	issueID, err := store.UpsertIssue(ctx, db.IssueUpsert{
+		ProjectName:   "proj",
+		Source:        "gitea",
+		SourceIssueID: "12",
+		Title:         "ship the fix",
+		URL:           "https://gitea.example.com/acme/repo/issues/12",
+		State:         "open",
+		DueAt:         "2025-03-01T23:59:59Z",
+	})
+	if err != nil {
+		t.Fatalf("upsert issue: %v", err)
+	}
+	jobID, err := store.CreateJob(ctx, issueID, "proj", 3)
+	if err != nil {
+		t.Fatalf("create job: %v", err)
+	}
+
+	w := New(store)
+	if flagged := w.Check(ctx, time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)); len(flagged) != 0 {
+		t.Fatalf("expected nothing overdue before the due date, got %+v", flagged)
+	}
+	flagged := w.Check(ctx, time.Date(2025, 3, 2, 9, 0, 0, 0, time.UTC))
+	if len(flagged) != 1 || flagged[0].JobID != jobID || flagged[0].Deadline != "2025-03-01T23:59:59Z" {
+		t.Fatalf("expected the job flagged, got %+v", flagged)
+	}
+	if flagged := w.Check(ctx, time.Date(2025, 3, 3, 9, 0, 0, 0, time.UTC)); len(flagged) != 0 {
+		t.Fatalf("expected one alert per deadline, got %+v", flagged)
+	}
+
+	events, err := store.ListNotificationEvents(ctx, db.NotificationStatusPending, 10)
+	if err != nil {
+		t.Fatalf("list notification events: %v", err)
+	}
+	if len(events) != 1 || events[0].EventType != db.NotificationEventDeadlineOverdue {
+		t.Fatalf("expected one deadline_overdue notification, got %+v", events)
+	}
+}
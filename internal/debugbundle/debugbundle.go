@@ -0,0 +1,97 @@
+// Package debugbundle packs what is needed to diagnose a job — its database
+// rows, LLM sessions, daemon log lines, scrubbed config, and tool versions —
+// into a tar.gz that can be attached to an AutoPR bug report.
+package debugbundle
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// redacted replaces secret values found in bundle files.
+const redacted = "[redacted]"
+
+// File is one entry in a bundle.
+type File struct {
+	Name string
+	Data []byte
+}
+
+// Write packs files into w as a tar.gz. Every occurrence of a secret is
+// replaced first, so a token that leaked into a log line or error message
+// does not end up in the bundle.
+func Write(w io.Writer, files []File, secrets []string) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	now := time.Now()
+	for _, f := range files {
+		data := Scrub(f.Data, secrets)
+		hdr := &tar.Header{Name: f.Name, Mode: 0o644, Size: int64(len(data)), ModTime: now, Typeflag: tar.TypeReg}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return fmt.Errorf("write %s: %w", f.Name, err)
+		}
+		if _, err := tw.Write(data); err != nil {
+			return fmt.Errorf("write %s: %w", f.Name, err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("close bundle: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("close bundle: %w", err)
+	}
+	return nil
+}
+
+// Scrub replaces every occurrence of each non-empty secret in data.
+func Scrub(data []byte, secrets []string) []byte {
+	for _, s := range secrets {
+		if strings.TrimSpace(s) == "" {
+			continue
+		}
+		data = bytes.ReplaceAll(data, []byte(s), []byte(redacted))
+	}
+	return data
+}
+
+// LogExcerpt returns the last max lines of r that mention any of ids, in
+// order. A max of 0 or less returns every matching line.
+func LogExcerpt(r io.Reader, ids []string, max int) ([]byte, error) {
+	var lines [][]byte
+	br := bufio.NewReader(r)
+	for {
+		line, err := br.ReadBytes('\n')
+		if len(line) > 0 && mentionsAny(line, ids) {
+			if line[len(line)-1] != '\n' {
+				line = append(line, '\n')
+			}
+			lines = append(lines, line)
+			if max > 0 && len(lines) > max {
+				lines = lines[1:]
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read log: %w", err)
+		}
+	}
+	return bytes.Join(lines, nil), nil
+}
+
+func mentionsAny(line []byte, ids []string) bool {
+	for _, id := range ids {
+		if id != "" && bytes.Contains(line, []byte(id)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,169 @@
+// Package epicstatus keeps a status comment on the source issue of each epic,
+// an issue whose task list was split into one job per item (see
+// pipeline.EpicItems).
+//
+// The comment lists the tasks with their jobs and PRs. The sync loop edits it
+// in place whenever a task moves on, so the issue shows how far the epic got
+// without anyone opening AutoPR.
+package epicstatus
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"autopr/internal/config"
+	"autopr/internal/db"
+	"autopr/internal/git"
+	"autopr/internal/githubapp"
+)
+
+// marker starts the status comment, so it can be told apart from others.
+const marker = "<!-- autopr-epic -->"
+
+// Updater posts and edits epic status comments on GitHub, GitLab, and Gitea.
+type Updater struct {
+	cfg   *config.Config
+	store *db.Store
+
+	upsertGitHubComment func(ctx context.Context, token, baseURL, owner, repo, number string, commentID int64, body string) (int64, error)
+	upsertGiteaComment  func(ctx context.Context, token, baseURL, owner, repo, index string, commentID int64, body string) (int64, error)
+	upsertGitLabNote    func(ctx context.Context, token, baseURL, projectID, iid string, noteID int64, body string) (int64, error)
+}
+
+func New(cfg *config.Config, store *db.Store) *Updater {
+	return &Updater{
+		cfg:                 cfg,
+		store:               store,
+		upsertGitHubComment: git.UpsertGitHubIssueComment,
+		upsertGiteaComment:  git.UpsertGiteaIssueComment,
+		upsertGitLabNote:    git.UpsertGitLabIssueNote,
+	}
+}
+
+// Update posts or edits the status comment of each epic whose status changed
+// since it was last posted. Failures are logged and retried on the next call.
+func (u *Updater) Update(ctx context.Context) {
+	epics, err := u.store.ListEpics(ctx)
+	if err != nil {
+		slog.Error("epic status: list epics", "err", err)
+		return
+	}
+	for _, e := range epics {
+		tasks, err := u.store.ListEpicTasks(ctx, e.AutoPRIssueID)
+		if err != nil {
+			slog.Warn("epic status: list tasks", "issue", e.AutoPRIssueID, "err", err)
+			continue
+		}
+		status := Render(tasks)
+		if status == e.Rollup {
+			continue
+		}
+		commentID, err := u.post(ctx, e, status)
+		if err != nil {
+			slog.Warn("epic status: update comment", "issue", e.AutoPRIssueID, "err", err)
+			continue
+		}
+		if commentID == "" {
+			continue
+		}
+		if err := u.store.SetEpicRollup(ctx, e.AutoPRIssueID, commentID, status); err != nil {
+			slog.Error("epic status: record comment", "issue", e.AutoPRIssueID, "err", err)
+			continue
+		}
+		slog.Info("epic status updated", "issue", e.AutoPRIssueID, "comment", commentID)
+	}
+}
+
+// post writes status to the epic's comment and returns the comment ID, or ""
+// when the issue's source takes no comments (local, Sentry) or the project
+// was removed from the config.
+func (u *Updater) post(ctx context.Context, e db.Epic, status string) (string, error) {
+	proj, ok := u.cfg.ProjectByName(e.ProjectName)
+	if !ok {
+		return "", nil
+	}
+	issue, err := u.store.GetIssueByAPID(ctx, e.AutoPRIssueID)
+	if err != nil {
+		return "", err
+	}
+	prev, _ := strconv.ParseInt(e.RollupCommentID, 10, 64)
+	var id int64
+	switch {
+	case issue.Source == "github" && proj.GitHub != nil:
+		token, err := githubapp.Token(ctx, u.cfg, proj)
+		if err != nil {
+			return "", fmt.Errorf("github token: %w", err)
+		}
+		id, err = u.upsertGitHubComment(ctx, token, proj.GitHub.BaseURL, proj.GitHub.Owner, proj.GitHub.Repo, issue.SourceIssueID, prev, status)
+		if err != nil {
+			return "", err
+		}
+	case issue.Source == "gitlab" && proj.GitLab != nil:
+		id, err = u.upsertGitLabNote(ctx, u.cfg.Tokens.GitLab, proj.GitLab.BaseURL, proj.GitLab.ProjectID, issue.SourceIssueID, prev, status)
+		if err != nil {
+			return "", err
+		}
+	case issue.Source == "gitea" && proj.Gitea != nil:
+		id, err = u.upsertGiteaComment(ctx, u.cfg.Tokens.Gitea, proj.Gitea.BaseURL, proj.Gitea.Owner, proj.Gitea.Repo, issue.SourceIssueID, prev, status)
+		if err != nil {
+			return "", err
+		}
+	default:
+		return "", nil
+	}
+	return strconv.FormatInt(id, 10), nil
+}
+
+// Render is the status comment of an epic with tasks: one checklist line per
+// task, checked once its PR merged.
+func Render(tasks []db.EpicTask) string {
+	merged := map[int]bool{}
+	for _, t := range tasks {
+		if t.PRMergedAt != "" {
+			merged[t.Index] = true
+		}
+	}
+	var b strings.Builder
+	b.WriteString(marker + "\n")
+	fmt.Fprintf(&b, "**AutoPR epic status:** %d of %d tasks merged.\n\n", len(merged), len(tasks))
+	for _, t := range tasks {
+		box := " "
+		if merged[t.Index] {
+			box = "x"
+		}
+		fmt.Fprintf(&b, "- [%s] %s: %s\n", box, t.Text, taskStatus(t, merged))
+	}
+	return b.String()
+}
+
+// taskStatus describes where one task of an epic stands.
+func taskStatus(t db.EpicTask, merged map[int]bool) string {
+	job := "job `" + db.ShortID(t.JobID) + "`"
+	var waiting []string
+	for _, dep := range t.DependsOn {
+		if !merged[dep] {
+			waiting = append(waiting, strconv.Itoa(dep))
+		}
+	}
+	switch {
+	case t.PRMergedAt != "" && t.PRURL != "":
+		return "merged in " + t.PRURL
+	case t.PRMergedAt != "":
+		return "merged"
+	case t.PRClosedAt != "":
+		return "PR closed without merging: " + t.PRURL
+	case t.State == "approved" && t.PRURL != "":
+		return "PR open: " + t.PRURL
+	case t.State == "queued" && len(waiting) > 0:
+		return job + " waits for task " + strings.Join(waiting, ", ")
+	case t.State == "queued":
+		return job + " queued"
+	case db.IsCancellableState(t.State):
+		return job + " in progress"
+	default:
+		return job + " " + db.DisplayState(t.State, t.PRMergedAt, t.PRClosedAt)
+	}
+}
@@ -0,0 +1,91 @@
+package epicstatus
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"autopr/internal/config"
+	"autopr/internal/db"
+)
+
+func TestUpdatePostsThenEditsStatusComment(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	store, err := db.Open(filepath.Join(t.TempDir(), "autopr.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	issueID, err := store.UpsertIssue(ctx, db.IssueUpsert{
+		ProjectName:   "myproject",
+		Source:        "github",
+		SourceIssueID: "42",
+		Title:         "Rework login",
+		URL:           "https://github.com/org/repo/issues/42",
+		State:         "open",
+	})
+	if err != nil {
+		t.Fatalf("upsert issue: %v", err)
+	}
+	jobID, err := store.CreateJob(ctx, issueID, "myproject", 3)
+	if err != nil {
+		t.Fatalf("create job: %v", err)
+	}
+	job, _ := store.GetJob(ctx, jobID)
+	ids, err := store.SplitEpic(ctx, job, []db.EpicItem{{Text: "Add the API"}, {Text: "Add the UI", DependsOn: []int{1}}})
+	if err != nil {
+		t.Fatalf("split epic: %v", err)
+	}
+
+	cfg := &config.Config{
+		Tokens: config.TokensConfig{GitHub: "tok"},
+		Projects: []config.ProjectConfig{{
+			Name:   "myproject",
+			GitHub: &config.ProjectGitHub{Owner: "org", Repo: "repo"},
+		}},
+	}
+	type call struct {
+		number    string
+		commentID int64
+		body      string
+	}
+	var calls []call
+	u := New(cfg, store)
+	u.upsertGitHubComment = func(_ context.Context, token, _, owner, repo, number string, commentID int64, body string) (int64, error) {
+		if token != "tok" || owner != "org" || repo != "repo" {
+			t.Errorf("unexpected target %s %s/%s", token, owner, repo)
+		}
+		calls = append(calls, call{number, commentID, body})
+		return 77, nil
+	}
+
+	u.Update(ctx)
+	if len(calls) != 1 || calls[0].number != "42" || calls[0].commentID != 0 {
+		t.Fatalf("expected one new comment, got %+v", calls)
+	}
+	if !strings.Contains(calls[0].body, "0 of 2 tasks merged") || !strings.Contains(calls[0].body, "- [ ] Add the UI: job `"+db.ShortID(ids[1])+"` waits for task 1") {
+		t.Fatalf("unexpected status:\n%s", calls[0].body)
+	}
+
+	u.Update(ctx)
+	if len(calls) != 1 {
+		t.Fatalf("expected no update while nothing changed, got %+v", calls)
+	}
+
+	if err := store.UpdateJobField(ctx, ids[0], "pr_url", "https://github.com/org/repo/pull/5"); err != nil {
+		t.Fatalf("set pr url: %v", err)
+	}
+	if err := store.MarkJobMerged(ctx, ids[0], "2026-01-02T00:00:00Z"); err != nil {
+		t.Fatalf("mark merged: %v", err)
+	}
+	u.Update(ctx)
+	if len(calls) != 2 || calls[1].commentID != 77 {
+		t.Fatalf("expected an edit of comment 77, got %+v", calls)
+	}
+	if !strings.Contains(calls[1].body, "1 of 2 tasks merged") || !strings.Contains(calls[1].body, "- [x] Add the API: merged in https://github.com/org/repo/pull/5") {
+		t.Fatalf("unexpected status:\n%s", calls[1].body)
+	}
+}
@@ -0,0 +1,85 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// dockerStopGrace is how long a cancelled command may take to exit after its
+// container was removed before the docker client is killed.
+const dockerStopGrace = 10 * time.Second
+
+// Docker runs each command in a fresh container of Image, with repos_root
+// bind-mounted at the same path so worktree paths are unchanged. Commands run
+// as the daemon's user, so files they write stay writable by the daemon.
+type Docker struct {
+	Image     string
+	ReposRoot string
+}
+
+func (d Docker) Command(ctx context.Context, spec Spec) Process {
+	name := UniqueName(spec.JobID)
+	args := []string{"run", "--rm", "--name", name,
+		"--volume", d.ReposRoot + ":" + d.ReposRoot,
+		"--workdir", spec.Dir,
+	}
+	if uid := os.Getuid(); uid >= 0 {
+		args = append(args, "--user", fmt.Sprintf("%d:%d", uid, os.Getgid()))
+	}
+	var env []string
+	for _, kv := range spec.Env {
+		key, _, ok := strings.Cut(kv, "=")
+		if !ok || key == "" {
+			continue
+		}
+		// Pass only the name: docker copies the value from its own
+		// environment, which keeps secrets off the command line.
+		args = append(args, "--env", key)
+		env = append(env, kv)
+	}
+	if spec.Stdin != nil {
+		args = append(args, "--interactive")
+	}
+	args = append(args, d.Image)
+	args = append(args, spec.Argv...)
+
+	cmd := exec.CommandContext(ctx, "docker", args...)
+	cmd.Env = append(os.Environ(), env...)
+	if spec.Stdin != nil {
+		cmd.Stdin = bytes.NewReader(spec.Stdin)
+	}
+	// Killing the docker client would leave the container running.
+	cmd.Cancel = func() error {
+		return exec.Command("docker", "rm", "--force", name).Run()
+	}
+	cmd.WaitDelay = dockerStopGrace
+	return cmd
+}
+
+// UniqueName returns a name for one command of jobID that is unique and valid
+// as a container name and a Kubernetes object name (DNS-1123 label).
+func UniqueName(jobID string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(strings.TrimPrefix(jobID, "ap-job-")) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '-' {
+			b.WriteRune(r)
+		}
+	}
+	id := strings.Trim(b.String(), "-")
+	if len(id) > 40 {
+		id = id[:40]
+	}
+	buf := make([]byte, 3)
+	rand.Read(buf)
+	if id == "" {
+		return "autopr-" + hex.EncodeToString(buf)
+	}
+	return "autopr-" + id + "-" + hex.EncodeToString(buf)
+}
@@ -0,0 +1,58 @@
+package executor
+
+import (
+	"context"
+	"os/exec"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestDockerRunsCommandInContainer(t *testing.T) {
+	t.Parallel()
+
+	d := Docker{Image: "registry.example/autopr-node:2", ReposRoot: "/data/repos"}
+	cmd := d.Command(context.Background(), Spec{
+		JobID: "ap-job-0123456789abcdef",
+		Dir:   "/data/repos/worktrees/ap-job-0123456789abcdef",
+		Env:   []string{"NPM_TOKEN=s3cret", "malformed"},
+		Argv:  []string{"npm", "test"},
+	}).(*exec.Cmd)
+
+	args := strings.Join(cmd.Args, " ")
+	for _, want := range []string{
+		"docker run --rm --name autopr-0123456789abcdef-",
+		"--volume /data/repos:/data/repos",
+		"--workdir /data/repos/worktrees/ap-job-0123456789abcdef",
+		"--env NPM_TOKEN registry.example/autopr-node:2 npm test",
+	} {
+		if !strings.Contains(args, want) {
+			t.Fatalf("expected %q in %q", want, args)
+		}
+	}
+	if strings.Contains(args, "s3cret") {
+		t.Fatalf("secret value on the docker command line: %q", args)
+	}
+	if !slices.Contains(cmd.Env, "NPM_TOKEN=s3cret") {
+		t.Fatal("expected the value in the docker client's environment")
+	}
+	if cmd.Cancel == nil || cmd.WaitDelay != dockerStopGrace {
+		t.Fatal("expected cancellation to remove the container")
+	}
+}
+
+func TestUniqueNameIsAValidDNSLabel(t *testing.T) {
+	t.Parallel()
+
+	label := regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+	for _, jobID := range []string{"ap-job-0123456789abcdef", "ap-job-", strings.Repeat("X_y", 40)} {
+		a, b := UniqueName(jobID), UniqueName(jobID)
+		if a == b {
+			t.Fatalf("%q: expected unique names, got %q twice", jobID, a)
+		}
+		if len(a) > 63 || !label.MatchString(a) {
+			t.Fatalf("%q: invalid name %q", jobID, a)
+		}
+	}
+}
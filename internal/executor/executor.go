@@ -0,0 +1,165 @@
+// Package executor runs the commands of pipeline steps: the LLM CLI, setup,
+// tests, and regeneration. The pipeline picks an Executor per project and
+// puts it in the job's context with WithJob; step code calls Command and
+// stays the same whether the command runs locally, in a container, or in a
+// Kubernetes pod.
+package executor
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"os/exec"
+	"time"
+)
+
+// Spec describes one command.
+type Spec struct {
+	JobID string   // job the command belongs to; set by Command from the context
+	Dir   string   // working directory, inside a job worktree under repos_root
+	Env   []string // NAME=value pairs added to the environment
+	Argv  []string // command name and arguments
+	Stdin []byte   // written to the command's stdin; nil for none
+}
+
+// Process is a command that has not been started yet. Its methods behave like
+// the exec.Cmd methods of the same name; the output of a remote process is
+// its combined stdout and stderr.
+type Process interface {
+	StdoutPipe() (io.ReadCloser, error)
+	Start() error
+	Wait() error
+	CombinedOutput() ([]byte, error)
+}
+
+// Executor creates the processes of pipeline step commands. Cancelling the
+// context passed to Command must stop the process.
+type Executor interface {
+	Command(ctx context.Context, spec Spec) Process
+}
+
+type scopeKey struct{}
+
+type scope struct {
+	ex    Executor
+	jobID string
+}
+
+// WithJob returns a context under which Command runs the commands of jobID
+// through ex.
+func WithJob(ctx context.Context, ex Executor, jobID string) context.Context {
+	return context.WithValue(ctx, scopeKey{}, scope{ex: ex, jobID: jobID})
+}
+
+// HasJob reports whether ctx carries an executor set by WithJob.
+func HasJob(ctx context.Context) bool {
+	sc, ok := ctx.Value(scopeKey{}).(scope)
+	return ok && sc.ex != nil
+}
+
+// Command returns a process for spec from the executor set by WithJob, or a
+// local process when ctx carries none.
+func Command(ctx context.Context, spec Spec) Process {
+	sc, ok := ctx.Value(scopeKey{}).(scope)
+	if !ok || sc.ex == nil {
+		return meter(ctx, Local{}.Command(ctx, spec))
+	}
+	spec.JobID = sc.jobID
+	p := sc.ex.Command(ctx, spec)
+	if _, local := sc.ex.(Local); local {
+		return meter(ctx, p)
+	}
+	return p
+}
+
+// PID returns the ID of the daemon-host process of a started process: the
+// command itself, or the docker or ssh client driving it. It returns 0 when
+// there is none, as for Kubernetes pods.
+func PID(p Process) int {
+	var cmd *exec.Cmd
+	switch p := p.(type) {
+	case *exec.Cmd:
+		cmd = p
+	case metered:
+		cmd = p.Cmd
+	case *sshProcess:
+		cmd = p.cmd
+	}
+	if cmd == nil || cmd.Process == nil {
+		return 0
+	}
+	return cmd.Process.Pid
+}
+
+// Usage is what a finished command used.
+type Usage struct {
+	CPU    time.Duration // user plus system time
+	MaxRSS int64         // peak resident memory in bytes; 0 where unknown
+}
+
+type usageKey struct{}
+
+// WithUsage returns a context under which record gets the Usage of each
+// local command once it finishes. Commands of the other executors are not
+// measured, since their processes only drive work done elsewhere.
+func WithUsage(ctx context.Context, record func(Usage)) context.Context {
+	return context.WithValue(ctx, usageKey{}, record)
+}
+
+// meter wraps a local process to report its usage to the recorder set by
+// WithUsage, if any.
+func meter(ctx context.Context, p Process) Process {
+	record, ok := ctx.Value(usageKey{}).(func(Usage))
+	cmd, isCmd := p.(*exec.Cmd)
+	if !ok || !isCmd {
+		return p
+	}
+	return metered{Cmd: cmd, record: record}
+}
+
+// metered is a local command that reports its usage when it finishes.
+type metered struct {
+	*exec.Cmd
+	record func(Usage)
+}
+
+func (m metered) Wait() error {
+	err := m.Cmd.Wait()
+	m.report()
+	return err
+}
+
+func (m metered) CombinedOutput() ([]byte, error) {
+	out, err := m.Cmd.CombinedOutput()
+	m.report()
+	return out, err
+}
+
+func (m metered) report() {
+	ps := m.ProcessState
+	if ps == nil {
+		return
+	}
+	m.record(Usage{CPU: ps.UserTime() + ps.SystemTime(), MaxRSS: maxRSS(ps)})
+}
+
+// localStopGrace is how long a cancelled local command's output may stay
+// open, held by a child that outlived it, before Wait gives up on it.
+const localStopGrace = 10 * time.Second
+
+// Local runs commands as processes next to the daemon.
+type Local struct{}
+
+func (Local) Command(ctx context.Context, spec Spec) Process {
+	cmd := exec.CommandContext(ctx, spec.Argv[0], spec.Argv[1:]...)
+	cmd.Dir = spec.Dir
+	if len(spec.Env) > 0 {
+		cmd.Env = append(os.Environ(), spec.Env...)
+	}
+	if spec.Stdin != nil {
+		cmd.Stdin = bytes.NewReader(spec.Stdin)
+	}
+	cmd.WaitDelay = localStopGrace
+	return cmd
+}
@@ -0,0 +1,87 @@
+package executor
+
+import (
+	"context"
+	"os/exec"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+type recordingExecutor struct{ specs []Spec }
+
+func (r *recordingExecutor) Command(ctx context.Context, spec Spec) Process {
+	r.specs = append(r.specs, spec)
+	return Local{}.Command(ctx, spec)
+}
+
+func TestCommandRoutesThroughJobExecutor(t *testing.T) {
+	t.Parallel()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	rec := &recordingExecutor{}
+	ctx := WithJob(context.Background(), rec, "ap-job-1")
+	out, err := Command(ctx, Spec{Dir: t.TempDir(), Env: []string{"AUTOPR_TEST_VALUE=42"}, Argv: []string{"sh", "-c", "echo $AUTOPR_TEST_VALUE"}}).CombinedOutput()
+	if err != nil {
+		t.Fatalf("run: %v (%s)", err, out)
+	}
+	if strings.TrimSpace(string(out)) != "42" {
+		t.Fatalf("expected the added env in the process, got %q", out)
+	}
+	if len(rec.specs) != 1 || rec.specs[0].JobID != "ap-job-1" {
+		t.Fatalf("expected the job's executor to get the spec with its job ID, got %+v", rec.specs)
+	}
+}
+
+func TestCommandWithoutJobRunsLocally(t *testing.T) {
+	t.Parallel()
+
+	if _, ok := Command(context.Background(), Spec{Argv: []string{"git", "--version"}}).(*exec.Cmd); !ok {
+		t.Fatal("expected a local process without a job executor")
+	}
+}
+
+func TestCommandReportsLocalUsage(t *testing.T) {
+	t.Parallel()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	var got []Usage
+	ctx := WithUsage(WithJob(context.Background(), Local{}, "ap-job-1"), func(u Usage) { got = append(got, u) })
+	if out, err := Command(ctx, Spec{Dir: t.TempDir(), Argv: []string{"sh", "-c", "i=0; while [ $i -lt 20000 ]; do i=$((i+1)); done"}}).CombinedOutput(); err != nil {
+		t.Fatalf("run: %v (%s)", err, out)
+	}
+	if len(got) != 1 || got[0].MaxRSS <= 0 {
+		t.Fatalf("expected the usage of one command with its peak memory, got %+v", got)
+	}
+
+	// Other executors only run clients of the real work, so nothing is measured.
+	got = nil
+	ctx = WithUsage(WithJob(context.Background(), &recordingExecutor{}, "ap-job-1"), func(u Usage) { got = append(got, u) })
+	if out, err := Command(ctx, Spec{Dir: t.TempDir(), Argv: []string{"true"}}).CombinedOutput(); err != nil {
+		t.Fatalf("run: %v (%s)", err, out)
+	}
+	if len(got) != 0 {
+		t.Fatalf("expected no usage from a non-local executor, got %+v", got)
+	}
+}
+
+func TestPIDOfStartedLocalProcess(t *testing.T) {
+	t.Parallel()
+
+	p := Command(context.Background(), Spec{Argv: []string{"git", "--version"}})
+	if PID(p) != 0 {
+		t.Fatal("expected no PID before Start")
+	}
+	if err := p.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	pid := PID(p)
+	_ = p.Wait()
+	if pid <= 0 {
+		t.Fatalf("expected the process's PID, got %d", pid)
+	}
+}
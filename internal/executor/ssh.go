@@ -0,0 +1,375 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"autopr/internal/config"
+)
+
+const (
+	// sshStopGrace is how long a cancelled command may take to exit after
+	// its remote process group was signalled before ssh is killed.
+	sshStopGrace = 10 * time.Second
+	// sshKillTimeout bounds the ssh call that signals a cancelled command.
+	sshKillTimeout = 15 * time.Second
+	// sshPruneInterval is how often remote job directories are pruned.
+	sshPruneInterval = 10 * time.Minute
+)
+
+var (
+	envNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	jobDirPattern  = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+)
+
+// sshJobLocks serializes the commands of one job on one host, keyed by
+// host and remote job directory. Each command pushes the worktree before it
+// runs and pulls it back after, so two at once would undo each other's
+// changes.
+var sshJobLocks sync.Map
+
+// SSH runs each command on a remote build machine. The job worktree is
+// pushed to RemoteDir/<job id> with rsync before the command starts and
+// pulled back when it exits, so the rest of the pipeline sees the remote
+// changes as if they were made locally.
+type SSH struct {
+	ReposRoot    string
+	Host         string // [user@]host or an ssh config alias
+	Port         int
+	IdentityFile string
+	RemoteDir    string // relative to the remote home directory
+
+	sshBin   string // default "ssh"; replaced in tests
+	rsyncBin string // default "rsync"; replaced in tests
+}
+
+// NewSSH returns the ssh executor for a project's [projects.ssh] settings.
+func NewSSH(reposRoot string, cfg config.ProjectSSH) SSH {
+	return SSH{
+		ReposRoot:    reposRoot,
+		Host:         cfg.Host,
+		Port:         cfg.Port,
+		IdentityFile: cfg.IdentityFile,
+		RemoteDir:    cfg.RemoteDir,
+	}
+}
+
+// SSHTargets returns one ssh executor per distinct host and remote_dir among
+// the projects using the ssh executor.
+func SSHTargets(cfg *config.Config) []SSH {
+	var targets []SSH
+	for _, p := range cfg.Projects {
+		if p.Executor != config.ExecutorSSH || p.SSH == nil {
+			continue
+		}
+		s := NewSSH(cfg.ReposRoot, *p.SSH)
+		if !slices.ContainsFunc(targets, func(t SSH) bool {
+			return t.Host == s.Host && t.Port == s.Port && t.RemoteDir == s.RemoteDir
+		}) {
+			targets = append(targets, s)
+		}
+	}
+	return targets
+}
+
+func (s SSH) Command(ctx context.Context, spec Spec) Process {
+	p := &sshProcess{ctx: ctx, s: s}
+	script, err := p.prepare(spec)
+	if err != nil {
+		p.err = err
+		script = "exit 1\n"
+	}
+	p.cmd = exec.CommandContext(ctx, s.ssh(), append(s.sshArgs(), s.Host, "sh -s")...)
+	p.cmd.Stdin = strings.NewReader(script)
+	p.cmd.Cancel = p.cancel
+	p.cmd.WaitDelay = sshStopGrace
+	return p
+}
+
+// Prune removes the job directories under RemoteDir whose local worktree is
+// gone, such as ones of jobs cleaned up while the daemon kept running. It
+// returns how many it removed.
+func (s SSH) Prune(ctx context.Context) (int, error) {
+	list := "cd " + shellQuote(s.RemoteDir) + " 2>/dev/null || exit 0\n" +
+		`for d in *; do [ -d "$d" ] && printf '%s\n' "$d"; done` + "\n"
+	out, err := s.remote(ctx, list)
+	if err != nil {
+		return 0, fmt.Errorf("list remote job directories on %s: %w", s.Host, err)
+	}
+	var orphans []string
+	for name := range strings.Lines(string(out)) {
+		name = strings.TrimSpace(name)
+		if !jobDirPattern.MatchString(name) || name == "." || name == ".." {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(s.ReposRoot, "worktrees", name)); errors.Is(err, os.ErrNotExist) {
+			orphans = append(orphans, name)
+		}
+	}
+	if len(orphans) == 0 {
+		return 0, nil
+	}
+	rm := "cd " + shellQuote(s.RemoteDir) + " && rm -rf --"
+	for _, name := range orphans {
+		rm += " " + shellQuote(name)
+	}
+	if _, err := s.remote(ctx, rm+"\n"); err != nil {
+		return 0, fmt.Errorf("remove remote job directories on %s: %w", s.Host, err)
+	}
+	for _, name := range orphans {
+		slog.Info("removed remote job directory", "host", s.Host, "job", name)
+	}
+	return len(orphans), nil
+}
+
+// RunPruneLoop prunes at startup and then every sshPruneInterval until ctx
+// is done.
+func (s SSH) RunPruneLoop(ctx context.Context) {
+	ticker := time.NewTicker(sshPruneInterval)
+	defer ticker.Stop()
+	for {
+		if _, err := s.Prune(ctx); err != nil && ctx.Err() == nil {
+			slog.Warn("prune remote job directories", "host", s.Host, "err", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s SSH) ssh() string {
+	if s.sshBin != "" {
+		return s.sshBin
+	}
+	return "ssh"
+}
+
+func (s SSH) rsync() string {
+	if s.rsyncBin != "" {
+		return s.rsyncBin
+	}
+	return "rsync"
+}
+
+// sshArgs returns the ssh options shared by commands and rsync. BatchMode
+// fails fast instead of prompting for a password the daemon can't type.
+func (s SSH) sshArgs() []string {
+	args := []string{"-o", "BatchMode=yes", "-o", "ServerAliveInterval=30"}
+	if s.Port != 0 {
+		args = append(args, "-p", strconv.Itoa(s.Port))
+	}
+	if s.IdentityFile != "" {
+		args = append(args, "-i", s.IdentityFile)
+	}
+	return args
+}
+
+// remote runs script with sh on the remote host and returns its output.
+func (s SSH) remote(ctx context.Context, script string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, s.ssh(), append(s.sshArgs(), s.Host, "sh -s")...)
+	cmd.Stdin = strings.NewReader(script)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return out, commandError(err, stderr.Bytes())
+	}
+	return out, nil
+}
+
+// sync copies the worktree from src to dst with rsync. Exactly one of them
+// is remote (host:path).
+func (s SSH) sync(ctx context.Context, src, dst string) error {
+	shell := s.ssh()
+	for _, arg := range s.sshArgs() {
+		shell += " " + shellQuote(arg)
+	}
+	args := []string{"--archive", "--delete", "--rsh", shell}
+	if strings.HasPrefix(dst, s.Host+":") {
+		args = append(args, "--rsync-path", "mkdir -p "+shellQuote(strings.TrimPrefix(dst, s.Host+":"))+" && rsync")
+	}
+	args = append(args, src+"/", dst+"/")
+	out, err := exec.CommandContext(ctx, s.rsync(), args...).CombinedOutput()
+	if err != nil {
+		return commandError(err, out)
+	}
+	return nil
+}
+
+type sshProcess struct {
+	ctx context.Context
+	s   SSH
+	cmd *exec.Cmd
+	err error // from prepare, returned by Start
+
+	localDir  string // job worktree
+	remoteDir string // its copy on the host, relative to the remote home
+	pidFile   string // remote file holding the pid of the command's shell
+
+	started    bool
+	unlock     func()
+	combined   *bytes.Buffer
+	stdoutPipe bool
+}
+
+// prepare works out the remote paths for spec and returns the script that
+// runs it.
+func (p *sshProcess) prepare(spec Spec) (string, error) {
+	if len(spec.Argv) == 0 {
+		return "", fmt.Errorf("ssh executor: empty command")
+	}
+	if !jobDirPattern.MatchString(spec.JobID) {
+		return "", fmt.Errorf("ssh executor: command is not part of a job")
+	}
+	p.localDir = filepath.Join(p.s.ReposRoot, "worktrees", spec.JobID)
+	rel, err := filepath.Rel(p.localDir, spec.Dir)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("ssh executor: %s is outside the job worktree %s", spec.Dir, p.localDir)
+	}
+	p.remoteDir = path.Join(p.s.RemoteDir, spec.JobID)
+	p.pidFile = path.Join(p.s.RemoteDir, UniqueName(spec.JobID)+".pid")
+
+	var b strings.Builder
+	// The pid file path is made absolute before cd.
+	fmt.Fprintf(&b, "pidfile=\"$PWD\"/%s\n", shellQuote(p.pidFile))
+	b.WriteString("echo $$ > \"$pidfile\" || exit 1\n")
+	b.WriteString("trap 'rm -f \"$pidfile\"' EXIT\n")
+	// Exit through the EXIT trap when signalled by cancel.
+	b.WriteString("trap 'exit 143' HUP INT TERM\n")
+	fmt.Fprintf(&b, "cd %s || exit 1\n", shellQuote(path.Join(p.remoteDir, filepath.ToSlash(rel))))
+	for _, kv := range spec.Env {
+		key, value, ok := strings.Cut(kv, "=")
+		if !ok || !envNamePattern.MatchString(key) {
+			continue
+		}
+		// Values travel in the script on stdin, not on the command line.
+		fmt.Fprintf(&b, "export %s=%s\n", key, shellQuote(value))
+	}
+	quoted := make([]string, len(spec.Argv))
+	for i, arg := range spec.Argv {
+		quoted[i] = shellQuote(arg)
+	}
+	if spec.Stdin != nil {
+		// The script itself is on stdin, so the command's input travels
+		// encoded inside it.
+		fmt.Fprintf(&b, "printf '%%s' %s | base64 -d | %s\n", base64.StdEncoding.EncodeToString(spec.Stdin), strings.Join(quoted, " "))
+		return b.String(), nil
+	}
+	// The script itself is on stdin; the command must not read the rest of it.
+	fmt.Fprintf(&b, "%s < /dev/null\n", strings.Join(quoted, " "))
+	return b.String(), nil
+}
+
+func (p *sshProcess) StdoutPipe() (io.ReadCloser, error) {
+	p.stdoutPipe = true
+	return p.cmd.StdoutPipe()
+}
+
+func (p *sshProcess) Start() error {
+	if p.started {
+		return errors.New("ssh executor: already started")
+	}
+	p.started = true
+	if p.err != nil {
+		return p.err
+	}
+	mu, _ := sshJobLocks.LoadOrStore(p.s.Host+"\x00"+p.remoteDir, &sync.Mutex{})
+	mu.(*sync.Mutex).Lock()
+	p.unlock = mu.(*sync.Mutex).Unlock
+
+	if err := p.s.sync(p.ctx, p.localDir, p.s.Host+":"+p.remoteDir); err != nil {
+		p.unlock()
+		return fmt.Errorf("push worktree to %s: %w", p.s.Host, err)
+	}
+	if err := p.cmd.Start(); err != nil {
+		p.unlock()
+		return err
+	}
+	return nil
+}
+
+// Wait waits for the remote command and then pulls the worktree back, also
+// when the command failed, so its changes and test artifacts are kept. A
+// cancelled command's worktree is left as it was.
+func (p *sshProcess) Wait() error {
+	if !p.started || p.err != nil {
+		return errors.New("ssh executor: not started")
+	}
+	defer p.unlock()
+	err := p.cmd.Wait()
+	if p.ctx.Err() != nil {
+		return err
+	}
+	if pullErr := p.s.sync(p.ctx, p.s.Host+":"+p.remoteDir, p.localDir); pullErr != nil {
+		pullErr = fmt.Errorf("pull worktree from %s: %w", p.s.Host, pullErr)
+		if err == nil {
+			return pullErr
+		}
+		slog.Warn("pull worktree after failed remote command", "host", p.s.Host, "err", pullErr)
+	}
+	return err
+}
+
+func (p *sshProcess) CombinedOutput() ([]byte, error) {
+	if p.stdoutPipe || p.combined != nil {
+		return nil, errors.New("ssh executor: output already captured")
+	}
+	p.combined = &bytes.Buffer{}
+	p.cmd.Stdout = p.combined
+	p.cmd.Stderr = p.combined
+	if err := p.Start(); err != nil {
+		return p.combined.Bytes(), err
+	}
+	err := p.Wait()
+	return p.combined.Bytes(), err
+}
+
+// cancel signals the process group of the remote command, then lets
+// WaitDelay kill ssh if it does not exit. Without it the remote command would
+// outlive the connection: sshd does not signal commands run without a tty.
+func (p *sshProcess) cancel() error {
+	ctx, cancel := context.WithTimeout(context.Background(), sshKillTimeout)
+	defer cancel()
+	kill := fmt.Sprintf("pid=$(cat %s 2>/dev/null) || exit 0\n", shellQuote(p.pidFile)) +
+		`pgid=$(ps -o pgid= -p "$pid" | tr -d ' ')` + "\n" +
+		`[ -n "$pgid" ] && kill -TERM "-$pgid"` + "\n"
+	if _, err := p.s.remote(ctx, kill); err != nil {
+		slog.Warn("signal cancelled remote command", "host", p.s.Host, "err", err)
+		return p.cmd.Process.Kill()
+	}
+	return nil
+}
+
+// commandError adds the trimmed output of a failed command to err.
+func commandError(err error, out []byte) error {
+	msg := strings.TrimSpace(string(out))
+	if msg == "" {
+		return err
+	}
+	if len(msg) > 2048 {
+		msg = msg[len(msg)-2048:]
+	}
+	return fmt.Errorf("%w: %s", err, msg)
+}
+
+// shellQuote quotes s as one word for a POSIX shell.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,227 @@
+package executor
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newFakeSSH returns an SSH executor whose ssh and rsync are shell scripts
+// that treat home as the remote host's home directory.
+func newFakeSSH(t *testing.T) (SSH, string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake ssh needs a POSIX shell")
+	}
+	if _, err := exec.LookPath("setsid"); err != nil {
+		t.Skip("fake ssh needs setsid")
+	}
+	dir := t.TempDir()
+	home := filepath.Join(dir, "home")
+	if err := os.MkdirAll(home, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	// Like sshd, run the command in its own session under the remote home, in a
+	// child, so that killing ssh alone would leave it running.
+	fakeSSH := `#!/bin/sh
+while [ $# -gt 0 ]; do
+	case "$1" in
+	-o|-p|-i) shift 2 ;;
+	*) break ;;
+	esac
+done
+shift
+cd '` + home + `' || exit 255
+setsid sh -c "$*"
+`
+	fakeRsync := `#!/bin/sh
+while [ $# -gt 2 ]; do
+	case "$1" in
+	--rsh|--rsync-path) shift 2 ;;
+	*) shift ;;
+	esac
+done
+src=$1 dst=$2
+case "$src" in remote:*) src='` + home + `'/${src#remote:} ;; esac
+case "$dst" in remote:*) dst='` + home + `'/${dst#remote:} ;; esac
+rm -rf "$dst" && mkdir -p "$dst" && cp -a "$src." "$dst"
+`
+	s := SSH{ReposRoot: filepath.Join(dir, "repos"), Host: "remote", Port: 2222, RemoteDir: ".autopr/worktrees"}
+	s.sshBin = filepath.Join(dir, "ssh")
+	s.rsyncBin = filepath.Join(dir, "rsync")
+	if err := os.WriteFile(s.sshBin, []byte(fakeSSH), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(s.rsyncBin, []byte(fakeRsync), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return s, home
+}
+
+func makeWorktree(t *testing.T, s SSH, jobID string) string {
+	t.Helper()
+	wt := filepath.Join(s.ReposRoot, "worktrees", jobID)
+	if err := os.MkdirAll(filepath.Join(wt, "pkg"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(wt, "pkg", "input.txt"), []byte("from local\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return wt
+}
+
+func TestSSHRunsCommandInSyncedWorktree(t *testing.T) {
+	t.Parallel()
+
+	s, home := newFakeSSH(t)
+	wt := makeWorktree(t, s, "ap-job-1")
+
+	out, err := s.Command(context.Background(), Spec{
+		JobID: "ap-job-1",
+		Dir:   filepath.Join(wt, "pkg"),
+		Env:   []string{"GREETING=it's a 'test'", "bad-name=x"},
+		Argv:  []string{"sh", "-c", `cat input.txt; echo "$GREETING"; echo built > output.txt`},
+	}).CombinedOutput()
+	if err != nil {
+		t.Fatalf("run: %v\n%s", err, out)
+	}
+	if got := string(out); got != "from local\nit's a 'test'\n" {
+		t.Fatalf("unexpected output %q", got)
+	}
+	if data, err := os.ReadFile(filepath.Join(wt, "pkg", "output.txt")); err != nil || string(data) != "built\n" {
+		t.Fatalf("expected the remote change pulled back, got %q, %v", data, err)
+	}
+	if _, err := os.Stat(filepath.Join(home, ".autopr/worktrees/ap-job-1/pkg/output.txt")); err != nil {
+		t.Fatalf("expected the worktree on the remote: %v", err)
+	}
+	pids, _ := filepath.Glob(filepath.Join(home, ".autopr/worktrees/*.pid"))
+	if len(pids) != 0 {
+		t.Fatalf("expected the pid file removed, got %v", pids)
+	}
+}
+
+func TestSSHPullsBackAfterFailureAndKeepsExitCode(t *testing.T) {
+	t.Parallel()
+
+	s, _ := newFakeSSH(t)
+	wt := makeWorktree(t, s, "ap-job-2")
+
+	out, err := s.Command(context.Background(), Spec{
+		JobID: "ap-job-2",
+		Dir:   wt,
+		Argv:  []string{"sh", "-c", "echo report > junit.xml; echo FAIL; exit 3"},
+	}).CombinedOutput()
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() != 3 {
+		t.Fatalf("expected exit status 3, got %v", err)
+	}
+	if string(out) != "FAIL\n" {
+		t.Fatalf("unexpected output %q", out)
+	}
+	if _, err := os.Stat(filepath.Join(wt, "junit.xml")); err != nil {
+		t.Fatalf("expected artifacts of the failed command pulled back: %v", err)
+	}
+}
+
+func TestSSHPassesStdinToCommand(t *testing.T) {
+	t.Parallel()
+
+	s, _ := newFakeSSH(t)
+	wt := makeWorktree(t, s, "ap-job-5")
+
+	out, err := s.Command(context.Background(), Spec{
+		JobID: "ap-job-5",
+		Dir:   wt,
+		Stdin: []byte("{\"point\": \"pre_push\"}\nit's input\n"),
+		Argv:  []string{"cat"},
+	}).CombinedOutput()
+	if err != nil {
+		t.Fatalf("run: %v\n%s", err, out)
+	}
+	if got := string(out); got != "{\"point\": \"pre_push\"}\nit's input\n" {
+		t.Fatalf("expected stdin echoed back, got %q", got)
+	}
+}
+
+func TestSSHRejectsDirOutsideWorktree(t *testing.T) {
+	t.Parallel()
+
+	s, _ := newFakeSSH(t)
+	makeWorktree(t, s, "ap-job-3")
+
+	err := s.Command(context.Background(), Spec{JobID: "ap-job-3", Dir: s.ReposRoot, Argv: []string{"true"}}).Start()
+	if err == nil || !strings.Contains(err.Error(), "outside the job worktree") {
+		t.Fatalf("expected an outside-worktree error, got %v", err)
+	}
+	err = s.Command(context.Background(), Spec{Dir: s.ReposRoot, Argv: []string{"true"}}).Start()
+	if err == nil || !strings.Contains(err.Error(), "not part of a job") {
+		t.Fatalf("expected a missing-job error, got %v", err)
+	}
+}
+
+func TestSSHCancelStopsRemoteCommand(t *testing.T) {
+	t.Parallel()
+
+	s, home := newFakeSSH(t)
+	wt := makeWorktree(t, s, "ap-job-4")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	proc := s.Command(ctx, Spec{JobID: "ap-job-4", Dir: wt, Argv: []string{"sh", "-c",
+		"trap 'echo stopped > stopped; exit 1' TERM; echo started; sleep 60 & wait"}})
+	stdout, err := proc.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := proc.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	if line, err := bufio.NewReader(stdout).ReadString('\n'); err != nil || line != "started\n" {
+		t.Fatalf("expected the command to start, got %q, %v", line, err)
+	}
+
+	cancel()
+	if err := proc.Wait(); err == nil {
+		t.Fatal("expected a cancelled command to fail")
+	}
+	// Killing ssh alone would leave the remote command running.
+	marker := filepath.Join(home, ".autopr/worktrees/ap-job-4/stopped")
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(20 * time.Millisecond) {
+		if _, err := os.Stat(marker); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("expected the remote command to be signalled")
+		}
+	}
+}
+
+func TestSSHPruneRemovesOrphanedJobDirs(t *testing.T) {
+	t.Parallel()
+
+	s, home := newFakeSSH(t)
+	makeWorktree(t, s, "ap-job-live")
+	for _, name := range []string{"ap-job-live", "ap-job-gone"} {
+		if err := os.MkdirAll(filepath.Join(home, ".autopr/worktrees", name), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	removed, err := s.Prune(context.Background())
+	if err != nil || removed != 1 {
+		t.Fatalf("expected one directory pruned, got %d, %v", removed, err)
+	}
+	if _, err := os.Stat(filepath.Join(home, ".autopr/worktrees/ap-job-gone")); !os.IsNotExist(err) {
+		t.Fatalf("expected the orphan removed, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(home, ".autopr/worktrees/ap-job-live")); err != nil {
+		t.Fatalf("expected the live job kept: %v", err)
+	}
+}
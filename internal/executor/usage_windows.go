@@ -0,0 +1,8 @@
+//go:build windows
+
+package executor
+
+import "os"
+
+// maxRSS is unknown on Windows.
+func maxRSS(*os.ProcessState) int64 { return 0 }
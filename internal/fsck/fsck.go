@@ -0,0 +1,280 @@
+// Package fsck cross-checks jobs recorded in the database against the
+// filesystem and the remote forge, and repairs what it safely can.
+package fsck
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"autopr/internal/config"
+	"autopr/internal/daemon"
+	"autopr/internal/db"
+	"autopr/internal/git"
+	"autopr/internal/githubapp"
+	"autopr/internal/pipeline"
+)
+
+// Finding kinds.
+const (
+	KindMissingWorktree     = "missing_worktree"
+	KindMissingRemoteBranch = "missing_remote_branch"
+	KindPRNotFound          = "pr_not_found"
+	KindStaleSession        = "stale_session"
+)
+
+const staleSessionMessage = "marked failed by ap fsck: session was not running"
+
+var (
+	terminalStates    = []string{"approved", "rejected", "failed", "cancelled"}
+	cancellableStates = []string{"queued", "planning", "implementing", "reviewing", "testing", "rebasing", "resolving_conflicts", "awaiting_checks", "waiting_network"}
+	// workingStates are the states in which a worker may hold a live LLM session
+	// or be (re)creating the job's worktree.
+	workingStates = []string{"planning", "implementing", "reviewing", "testing", "rebasing", "resolving_conflicts"}
+)
+
+// Finding is one inconsistency. Fix describes the automated repair; it is
+// empty when the finding needs a human.
+type Finding struct {
+	Kind      string `json:"kind"`
+	JobID     string `json:"job_id"`
+	SessionID int    `json:"session_id,omitempty"`
+	Detail    string `json:"detail"`
+	Fix       string `json:"fix,omitempty"`
+	Fixed     bool   `json:"fixed"`
+	FixError  string `json:"fix_error,omitempty"`
+
+	job     db.Job
+	project *config.ProjectConfig
+}
+
+// Checker runs the consistency checks.
+type Checker struct {
+	cfg   *config.Config
+	store *db.Store
+
+	// DaemonRunning says whether the daemon is alive; running sessions and
+	// in-progress worktrees are only expected while it is.
+	DaemonRunning bool
+	// Remote enables the checks that call git remotes and forge APIs.
+	Remote bool
+
+	remoteBranchExists  func(ctx context.Context, remoteURL, token, branch string) (bool, error)
+	checkGitHubPRStatus func(ctx context.Context, token, baseURL, prURL string) (git.PRMergeStatus, error)
+	checkGitLabMRStatus func(ctx context.Context, token, baseURL, mrURL string) (git.PRMergeStatus, error)
+	checkGiteaPRStatus  func(ctx context.Context, token, baseURL, prURL string) (git.PRMergeStatus, error)
+	githubToken         func(ctx context.Context, cfg *config.Config, proj *config.ProjectConfig) (string, error)
+	gitToken            func(ctx context.Context, cfg *config.Config, proj *config.ProjectConfig) string
+	resolvePushTarget   func(ctx context.Context, proj *config.ProjectConfig, branchName, worktreePath, token string) (string, string, error)
+	pushBranch          func(ctx context.Context, dir, remoteName, branchName, token string) error
+	processAlive        func(pid int) bool
+}
+
+func New(cfg *config.Config, store *db.Store) *Checker {
+	return &Checker{
+		cfg:                 cfg,
+		store:               store,
+		Remote:              true,
+		remoteBranchExists:  git.RemoteBranchExists,
+		checkGitHubPRStatus: git.CheckGitHubPRStatus,
+		checkGitLabMRStatus: git.CheckGitLabMRStatus,
+		checkGiteaPRStatus:  git.CheckGiteaPRStatus,
+		githubToken:         githubapp.Token,
+		gitToken:            pipeline.GitTokenForProject,
+		resolvePushTarget:   pipeline.ResolveGitHubPushTarget,
+		pushBranch:          git.PushBranchWithLeaseToRemoteWithToken,
+		processAlive:        daemon.ProcessAlive,
+	}
+}
+
+// Check returns every finding, jobs first in list order, then sessions.
+func (c *Checker) Check(ctx context.Context) ([]Finding, error) {
+	jobs, err := c.store.ListJobs(ctx, "", "all", "updated_at", false)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []Finding
+	for _, job := range jobs {
+		if ctx.Err() != nil {
+			return out, ctx.Err()
+		}
+		proj, _ := c.cfg.ProjectByName(job.ProjectName)
+		if f, ok := c.checkWorktree(job); ok {
+			f.project = proj
+			out = append(out, f)
+		}
+		if !c.Remote || proj == nil || job.PRURL == "" || job.PRMergedAt != "" || job.PRClosedAt != "" {
+			continue
+		}
+		// A PR that is gone makes its branch moot, so report only the PR.
+		if f, ok := c.checkPR(ctx, job, proj); ok {
+			out = append(out, f)
+			continue
+		}
+		if f, ok := c.checkRemoteBranch(ctx, job, proj); ok {
+			out = append(out, f)
+		}
+	}
+
+	sessions, err := c.checkSessions(ctx)
+	if err != nil {
+		return out, err
+	}
+	return append(out, sessions...), nil
+}
+
+func (c *Checker) checkWorktree(job db.Job) (Finding, bool) {
+	if job.WorktreePath == "" {
+		return Finding{}, false
+	}
+	if c.DaemonRunning && slices.Contains(workingStates, job.State) {
+		return Finding{}, false
+	}
+	if _, err := os.Stat(job.WorktreePath); !os.IsNotExist(err) {
+		return Finding{}, false
+	}
+	f := Finding{
+		Kind:   KindMissingWorktree,
+		JobID:  job.ID,
+		Detail: fmt.Sprintf("%s job worktree %s does not exist", job.State, job.WorktreePath),
+		job:    job,
+	}
+	switch {
+	case slices.Contains(terminalStates, job.State):
+		f.Fix = "clear the recorded worktree path"
+	case job.State == "ready" || job.State == "needs_review_oversize":
+		f.Fix = "reject the job; `ap retry` starts it over"
+	case slices.Contains(cancellableStates, job.State):
+		f.Fix = "cancel the job; `ap retry` starts it over"
+	}
+	return f, true
+}
+
+func (c *Checker) checkPR(ctx context.Context, job db.Job, proj *config.ProjectConfig) (Finding, bool) {
+	var err error
+	switch {
+	case proj.GitHub != nil && strings.Contains(job.PRURL, "/pull/"):
+		token, tokenErr := c.githubToken(ctx, c.cfg, proj)
+		if tokenErr != nil || token == "" {
+			return Finding{}, false
+		}
+		_, err = c.checkGitHubPRStatus(ctx, token, proj.GitHub.BaseURL, job.PRURL)
+	case proj.GitLab != nil && strings.Contains(job.PRURL, "/merge_requests/"):
+		if c.cfg.Tokens.GitLab == "" {
+			return Finding{}, false
+		}
+		_, err = c.checkGitLabMRStatus(ctx, c.cfg.Tokens.GitLab, git.NormalizeGitLabBaseURL(proj.GitLab.BaseURL), job.PRURL)
+	case proj.Gitea != nil && strings.Contains(job.PRURL, "/pulls/"):
+		if c.cfg.Tokens.Gitea == "" {
+			return Finding{}, false
+		}
+		_, err = c.checkGiteaPRStatus(ctx, c.cfg.Tokens.Gitea, proj.Gitea.BaseURL, job.PRURL)
+	default:
+		return Finding{}, false
+	}
+	if err == nil || !strings.Contains(err.Error(), "HTTP 404") {
+		return Finding{}, false
+	}
+	return Finding{
+		Kind:    KindPRNotFound,
+		JobID:   job.ID,
+		Detail:  fmt.Sprintf("PR %s returns 404", job.PRURL),
+		Fix:     "clear the PR URL so the job can open a new PR",
+		job:     job,
+		project: proj,
+	}, true
+}
+
+func (c *Checker) checkRemoteBranch(ctx context.Context, job db.Job, proj *config.ProjectConfig) (Finding, bool) {
+	if job.BranchName == "" {
+		return Finding{}, false
+	}
+	exists, err := c.remoteBranchExists(ctx, pipeline.BranchRemoteURL(proj), c.gitToken(ctx, c.cfg, proj), job.BranchName)
+	if err != nil || exists {
+		return Finding{}, false
+	}
+	f := Finding{
+		Kind:    KindMissingRemoteBranch,
+		JobID:   job.ID,
+		Detail:  fmt.Sprintf("branch %s is missing on the remote but PR %s is open", job.BranchName, job.PRURL),
+		job:     job,
+		project: proj,
+	}
+	if job.WorktreePath != "" {
+		if _, err := os.Stat(job.WorktreePath); err == nil {
+			f.Fix = "push the branch again from the job worktree"
+		}
+	}
+	return f, true
+}
+
+func (c *Checker) checkSessions(ctx context.Context) ([]Finding, error) {
+	sessions, err := c.store.ListRunningSessions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var out []Finding
+	for _, sess := range sessions {
+		var detail string
+		switch {
+		case !c.DaemonRunning:
+			detail = fmt.Sprintf("%s session #%d is marked running but the daemon is not running", sess.Step, sess.ID)
+		case sess.PID > 0 && !c.processAlive(sess.PID):
+			detail = fmt.Sprintf("%s session #%d is marked running but its process %d is gone", sess.Step, sess.ID, sess.PID)
+		default:
+			job, err := c.store.GetJob(ctx, sess.JobID)
+			if err == nil && slices.Contains(workingStates, job.State) {
+				continue
+			}
+			detail = fmt.Sprintf("%s session #%d is marked running but its job is %s", sess.Step, sess.ID, job.State)
+		}
+		out = append(out, Finding{
+			Kind:      KindStaleSession,
+			JobID:     sess.JobID,
+			SessionID: sess.ID,
+			Detail:    detail,
+			Fix:       "mark the session failed",
+		})
+	}
+	return out, nil
+}
+
+// Fix applies f's automated repair and records the outcome on f.
+func (c *Checker) Fix(ctx context.Context, f *Finding) {
+	if f.Fix == "" {
+		return
+	}
+	var err error
+	switch f.Kind {
+	case KindMissingWorktree:
+		switch {
+		case f.job.State == "ready" || f.job.State == "needs_review_oversize":
+			err = c.store.RejectJob(ctx, f.JobID, f.job.State, "worktree missing (ap fsck)")
+		case !slices.Contains(terminalStates, f.job.State):
+			err = c.store.CancelJob(ctx, f.JobID)
+		}
+		if err == nil {
+			err = c.store.ClearWorktreePath(ctx, f.JobID)
+		}
+	case KindPRNotFound:
+		err = c.store.UpdateJobField(ctx, f.JobID, "pr_url", "")
+	case KindMissingRemoteBranch:
+		token := c.gitToken(ctx, c.cfg, f.project)
+		var remote string
+		if remote, _, err = c.resolvePushTarget(ctx, f.project, f.job.BranchName, f.job.WorktreePath, token); err == nil {
+			err = c.pushBranch(ctx, f.job.WorktreePath, remote, f.job.BranchName, token)
+		}
+	case KindStaleSession:
+		err = c.store.FailRunningSession(ctx, f.SessionID, staleSessionMessage)
+	default:
+		err = fmt.Errorf("no fix for %s", f.Kind)
+	}
+	if err != nil {
+		f.FixError = err.Error()
+		return
+	}
+	f.Fixed = true
+}
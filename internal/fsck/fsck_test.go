@@ -0,0 +1,220 @@
+package fsck
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"autopr/internal/config"
+	"autopr/internal/db"
+	"autopr/internal/git"
+)
+
+func seedJob(t *testing.T, store *db.Store, suffix, state, worktree, branch, prURL string) string {
+	t.Helper()
+	ctx := context.Background()
+	issueID, err := store.UpsertIssue(ctx, db.IssueUpsert{
+		ProjectName:   "proj",
+		Source:        "github",
+		SourceIssueID: suffix,
+		Title:         "fsck " + suffix,
+		URL:           "https://github.com/acme/repo/issues/" + suffix,
+		State:         "open",
+	})
+	if err != nil {
+		t.Fatalf("upsert issue: %v", err)
+	}
+	jobID, err := store.CreateJob(ctx, issueID, "proj", 3)
+	if err != nil {
+		t.Fatalf("create job: %v", err)
+	}
+	if _, err := store.Writer.ExecContext(ctx, `
+UPDATE jobs SET state = ?, worktree_path = NULLIF(?, ''), branch_name = NULLIF(?, ''), pr_url = NULLIF(?, '')
+WHERE id = ?`, state, worktree, branch, prURL, jobID); err != nil {
+		t.Fatalf("seed job: %v", err)
+	}
+	return jobID
+}
+
+func newTestChecker(t *testing.T) (*Checker, *db.Store, string) {
+	t.Helper()
+	tmp := t.TempDir()
+	store, err := db.Open(filepath.Join(tmp, "autopr.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+
+	cfg := &config.Config{
+		Projects: []config.ProjectConfig{{
+			Name:    "proj",
+			RepoURL: "https://github.com/acme/repo.git",
+			GitHub:  &config.ProjectGitHub{Owner: "acme", Repo: "repo"},
+		}},
+	}
+	cfg.Tokens.GitHub = "gh-token"
+	c := New(cfg, store)
+	c.githubToken = func(context.Context, *config.Config, *config.ProjectConfig) (string, error) { return "gh-token", nil }
+	c.gitToken = func(context.Context, *config.Config, *config.ProjectConfig) string { return "gh-token" }
+	return c, store, tmp
+}
+
+func findingsByKind(findings []Finding) map[string][]Finding {
+	out := map[string][]Finding{}
+	for _, f := range findings {
+		out[f.Kind] = append(out[f.Kind], f)
+	}
+	return out
+}
+
+func TestCheckAndFixFindings(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	c, store, tmp := newTestChecker(t)
+	gone := filepath.Join(tmp, "repos", "gone")
+
+	failedJob := seedJob(t, store, "1", "failed", gone, "", "")
+	readyJob := seedJob(t, store, "2", "ready", gone, "", "")
+	missingPR := seedJob(t, store, "3", "approved", tmp, "autopr/3", "https://github.com/acme/repo/pull/3")
+	missingBranch := seedJob(t, store, "4", "approved", tmp, "autopr/4", "https://github.com/acme/repo/pull/4")
+	healthy := seedJob(t, store, "5", "approved", tmp, "autopr/5", "https://github.com/acme/repo/pull/5")
+	sessionID, err := store.CreateSession(ctx, healthy, "implement", 0, "codex", "")
+	if err != nil {
+		t.Fatalf("create session: %v", err)
+	}
+
+	c.checkGitHubPRStatus = func(_ context.Context, _, _, prURL string) (git.PRMergeStatus, error) {
+		if prURL == "https://github.com/acme/repo/pull/3" {
+			return git.PRMergeStatus{}, fmt.Errorf("check PR status: HTTP 404")
+		}
+		return git.PRMergeStatus{}, nil
+	}
+	c.remoteBranchExists = func(_ context.Context, remoteURL, token, branch string) (bool, error) {
+		if remoteURL != "https://github.com/acme/repo.git" || token != "gh-token" {
+			t.Errorf("unexpected remote check %q token %q", remoteURL, token)
+		}
+		return branch != "autopr/4", nil
+	}
+	var pushed []string
+	c.resolvePushTarget = func(context.Context, *config.ProjectConfig, string, string, string) (string, string, error) {
+		return "origin", "", nil
+	}
+	c.pushBranch = func(_ context.Context, dir, remote, branch, _ string) error {
+		pushed = append(pushed, remote+" "+branch)
+		return nil
+	}
+
+	findings, err := c.Check(ctx)
+	if err != nil {
+		t.Fatalf("check: %v", err)
+	}
+	byKind := findingsByKind(findings)
+	if got := byKind[KindMissingWorktree]; len(got) != 2 {
+		t.Fatalf("expected two missing worktrees, got %+v", got)
+	}
+	if got := byKind[KindPRNotFound]; len(got) != 1 || got[0].JobID != missingPR {
+		t.Fatalf("expected missing PR for %s, got %+v", missingPR, got)
+	}
+	if got := byKind[KindMissingRemoteBranch]; len(got) != 1 || got[0].JobID != missingBranch || got[0].Fix == "" {
+		t.Fatalf("expected fixable missing branch for %s, got %+v", missingBranch, got)
+	}
+	if got := byKind[KindStaleSession]; len(got) != 1 || got[0].SessionID != int(sessionID) {
+		t.Fatalf("expected stale session %d, got %+v", sessionID, got)
+	}
+
+	for i := range findings {
+		c.Fix(ctx, &findings[i])
+		if !findings[i].Fixed {
+			t.Fatalf("expected finding fixed, got %+v", findings[i])
+		}
+	}
+
+	if job, _ := store.GetJob(ctx, failedJob); job.WorktreePath != "" {
+		t.Fatalf("expected failed job worktree cleared, got %q", job.WorktreePath)
+	}
+	if job, _ := store.GetJob(ctx, readyJob); job.State != "rejected" {
+		t.Fatalf("expected ready job rejected, got %s", job.State)
+	}
+	if job, _ := store.GetJob(ctx, missingPR); job.PRURL != "" {
+		t.Fatalf("expected PR URL cleared, got %q", job.PRURL)
+	}
+	if len(pushed) != 1 || pushed[0] != "origin autopr/4" {
+		t.Fatalf("expected branch re-pushed, got %v", pushed)
+	}
+	if running, _ := store.ListRunningSessions(ctx); len(running) != 0 {
+		t.Fatalf("expected no running sessions after fix, got %+v", running)
+	}
+
+	again, err := c.Check(ctx)
+	if err != nil {
+		t.Fatalf("recheck: %v", err)
+	}
+	if len(again) != 1 || again[0].Kind != KindMissingRemoteBranch {
+		// The fake remote still reports autopr/4 missing; everything else is repaired.
+		t.Fatalf("expected only the simulated missing branch after fixes, got %+v", again)
+	}
+}
+
+func TestCheckSkipsLiveWorkWhileDaemonRuns(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	c, store, tmp := newTestChecker(t)
+	c.Remote = false
+	c.DaemonRunning = true
+
+	working := seedJob(t, store, "1", "implementing", filepath.Join(tmp, "cloning"), "", "")
+	if _, err := store.CreateSession(ctx, working, "implement", 0, "codex", ""); err != nil {
+		t.Fatalf("create session: %v", err)
+	}
+	done := seedJob(t, store, "2", "failed", "", "", "")
+	if _, err := store.CreateSession(ctx, done, "implement", 0, "codex", ""); err != nil {
+		t.Fatalf("create session: %v", err)
+	}
+
+	findings, err := c.Check(ctx)
+	if err != nil {
+		t.Fatalf("check: %v", err)
+	}
+	if len(findings) != 1 || findings[0].Kind != KindStaleSession || findings[0].JobID != done {
+		t.Fatalf("expected only the failed job's session reported, got %+v", findings)
+	}
+}
+
+func TestCheckReportsSessionWhoseProcessIsGone(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	c, store, tmp := newTestChecker(t)
+	c.Remote = false
+	c.DaemonRunning = true
+	c.processAlive = func(pid int) bool { return pid == 100 }
+
+	alive := seedJob(t, store, "1", "implementing", filepath.Join(tmp, "alive"), "", "")
+	aliveSession, err := store.CreateSession(ctx, alive, "implement", 0, "codex", "")
+	if err != nil {
+		t.Fatalf("create session: %v", err)
+	}
+	gone := seedJob(t, store, "2", "reviewing", filepath.Join(tmp, "gone"), "", "")
+	goneSession, err := store.CreateSession(ctx, gone, "code_review", 0, "codex", "")
+	if err != nil {
+		t.Fatalf("create session: %v", err)
+	}
+	if err := store.SetSessionPID(ctx, aliveSession, 100); err != nil {
+		t.Fatalf("set pid: %v", err)
+	}
+	if err := store.SetSessionPID(ctx, goneSession, 200); err != nil {
+		t.Fatalf("set pid: %v", err)
+	}
+
+	findings, err := c.Check(ctx)
+	if err != nil {
+		t.Fatalf("check: %v", err)
+	}
+	sessions := findingsByKind(findings)[KindStaleSession]
+	if len(sessions) != 1 || sessions[0].SessionID != int(goneSession) || sessions[0].Detail != "code_review session #2 is marked running but its process 200 is gone" {
+		t.Fatalf("expected only the session with a dead process reported, got %+v", sessions)
+	}
+}
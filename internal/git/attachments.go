@@ -0,0 +1,57 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"autopr/internal/httputil"
+)
+
+// ErrAttachmentTooLarge is returned by DownloadAttachment for files larger
+// than the limit it was given.
+var ErrAttachmentTooLarge = errors.New("attachment too large")
+
+// DownloadAttachment fetches a file uploaded to an issue and returns it with
+// its content type. authorization, when set, is sent as the Authorization
+// header; it is dropped if the forge redirects to another host, such as
+// object storage. Files larger than maxBytes fail with ErrAttachmentTooLarge.
+func DownloadAttachment(ctx context.Context, rawURL, authorization string, maxBytes int64) ([]byte, string, error) {
+	resp, err := httputil.Do(ctx, func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+		if err != nil {
+			return nil, err
+		}
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		return req, nil
+	}, httputil.DefaultRetryConfig())
+	if err != nil {
+		return nil, "", fmt.Errorf("download attachment: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("download attachment: HTTP %d", resp.StatusCode)
+	}
+	if resp.ContentLength > maxBytes {
+		return nil, "", ErrAttachmentTooLarge
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxBytes+1))
+	if err != nil {
+		return nil, "", fmt.Errorf("read attachment: %w", err)
+	}
+	if int64(len(data)) > maxBytes {
+		return nil, "", ErrAttachmentTooLarge
+	}
+	return data, resp.Header.Get("Content-Type"), nil
+}
+
+// GitLabUploadURL is the API URL of a file uploaded to a GitLab project,
+// linked from issues as /uploads/<secret>/<filename>.
+func GitLabUploadURL(baseURL, projectID, secret, filename string) string {
+	return fmt.Sprintf("%s/api/v4/projects/%s/uploads/%s/%s", NormalizeGitLabBaseURL(baseURL), url.PathEscape(projectID), url.PathEscape(secret), url.PathEscape(filename))
+}
@@ -0,0 +1,44 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDownloadAttachment(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer tok" {
+			t.Errorf("authorization mismatch: %q", r.Header.Get("Authorization"))
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Write([]byte(strings.Repeat("x", 64)))
+	}))
+	defer srv.Close()
+
+	data, contentType, err := DownloadAttachment(context.Background(), srv.URL+"/files/crash.log", "Bearer tok", 64)
+	if err != nil {
+		t.Fatalf("download: %v", err)
+	}
+	if len(data) != 64 || contentType != "text/plain; charset=utf-8" {
+		t.Fatalf("unexpected download: %d bytes, %q", len(data), contentType)
+	}
+	if _, _, err := DownloadAttachment(context.Background(), srv.URL+"/files/crash.log", "Bearer tok", 63); !errors.Is(err, ErrAttachmentTooLarge) {
+		t.Fatalf("expected ErrAttachmentTooLarge, got %v", err)
+	}
+}
+
+func TestGitLabUploadURL(t *testing.T) {
+	t.Parallel()
+
+	got := GitLabUploadURL("https://gitlab.example.com/", "group/repo", "0123456789abcdef0123456789abcdef", "crash log.txt")
+	want := "https://gitlab.example.com/api/v4/projects/group%2Frepo/uploads/0123456789abcdef0123456789abcdef/crash%20log.txt"
+	if got != want {
+		t.Fatalf("GitLabUploadURL = %q, want %q", got, want)
+	}
+}
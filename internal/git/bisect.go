@@ -0,0 +1,72 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var firstBadCommitRE = regexp.MustCompile(`(?m)^([0-9a-f]{7,64}) is the first bad commit`)
+
+// Bisect runs `git bisect` between good and bad, using args as the test
+// command at each step (exit 0 = good, 125 = skip, anything else = bad).
+// It returns the first bad commit and the bisect log. The repository is
+// always reset back to its original HEAD afterwards.
+func Bisect(ctx context.Context, dir, good, bad string, args []string) (string, string, error) {
+	if len(args) == 0 {
+		return "", "", fmt.Errorf("bisect command is empty")
+	}
+	if err := BisectReset(ctx, dir); err != nil {
+		return "", "", err
+	}
+	if err := runGit(ctx, dir, "bisect", "start", bad, good); err != nil {
+		return "", "", fmt.Errorf("git bisect start: %w", err)
+	}
+	defer func() {
+		resetCtx := context.WithoutCancel(ctx)
+		_ = runGit(resetCtx, dir, "bisect", "reset")
+	}()
+
+	stdout, stderr, err := runGitOutputAndErr(ctx, dir, append([]string{"bisect", "run"}, args...)...)
+	output := stdout + stderr
+	if err != nil {
+		if ctx.Err() != nil {
+			return "", output, ctx.Err()
+		}
+		return "", output, fmt.Errorf("git bisect run: %w: %s", err, strings.TrimSpace(output))
+	}
+	culprit, ok := ParseFirstBadCommit(output)
+	if !ok {
+		return "", output, fmt.Errorf("git bisect run did not report a first bad commit")
+	}
+	if full, err := runGitOutput(ctx, dir, "rev-parse", culprit); err == nil {
+		culprit = strings.TrimSpace(full)
+	}
+	return culprit, output, nil
+}
+
+// ParseFirstBadCommit extracts the culprit from `git bisect run` output.
+func ParseFirstBadCommit(output string) (string, bool) {
+	m := firstBadCommitRE.FindStringSubmatch(output)
+	if m == nil {
+		return "", false
+	}
+	return m[1], true
+}
+
+// BisectReset ends any bisect session left behind in dir.
+func BisectReset(ctx context.Context, dir string) error {
+	if !IsBisectInProgress(dir) {
+		return nil
+	}
+	return runGit(ctx, dir, "bisect", "reset")
+}
+
+// IsBisectInProgress reports whether a bisect session is active.
+func IsBisectInProgress(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, ".git", "BISECT_START"))
+	return err == nil
+}
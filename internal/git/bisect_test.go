@@ -0,0 +1,59 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBisectFindsFirstBadCommit(t *testing.T) {
+	ctx := context.Background()
+	tmp := t.TempDir()
+
+	createRemoteWithMainBranch(t, tmp)
+	seed := filepath.Join(tmp, "seed")
+	good := strings.TrimSpace(runGitCmdOutput(t, seed, "rev-parse", "HEAD"))
+
+	var culprit string
+	for i, content := range []string{"pass 1\n", "pass 2\n", "fail 3\n", "fail 4\n"} {
+		if err := os.WriteFile(filepath.Join(seed, "status.txt"), []byte(content), 0o644); err != nil {
+			t.Fatalf("write status: %v", err)
+		}
+		runGitCmd(t, seed, "add", "status.txt")
+		runGitCmd(t, seed, "commit", "-m", fmt.Sprintf("step %d", i+1))
+		if i == 2 {
+			culprit = strings.TrimSpace(runGitCmdOutput(t, seed, "rev-parse", "HEAD"))
+		}
+	}
+	head := strings.TrimSpace(runGitCmdOutput(t, seed, "rev-parse", "HEAD"))
+
+	got, log, err := Bisect(ctx, seed, good, head, []string{"grep", "-q", "pass", "status.txt"})
+	if err != nil {
+		t.Fatalf("bisect: %v\n%s", err, log)
+	}
+	if got != culprit {
+		t.Fatalf("expected culprit %s, got %s\n%s", culprit, got, log)
+	}
+	if IsBisectInProgress(seed) {
+		t.Fatalf("expected bisect session to be reset")
+	}
+	if after := strings.TrimSpace(runGitCmdOutput(t, seed, "rev-parse", "HEAD")); after != head {
+		t.Fatalf("expected HEAD restored to %s, got %s", head, after)
+	}
+}
+
+func TestParseFirstBadCommit(t *testing.T) {
+	t.Parallel()
+
+	out := "running grep\nabc1234def is the first bad commit\ncommit abc1234def\n"
+	got, ok := ParseFirstBadCommit(out)
+	if !ok || got != "abc1234def" {
+		t.Fatalf("expected abc1234def, got %q ok=%v", got, ok)
+	}
+	if _, ok := ParseFirstBadCommit("bisect run failed"); ok {
+		t.Fatalf("expected no culprit")
+	}
+}
@@ -0,0 +1,104 @@
+package git
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// AutoPRCheckName is the name of the check run, and the context of the commit
+// status, that AutoPR reports its own pipeline results under. CI gating
+// ignores it.
+const AutoPRCheckName = "AutoPR"
+
+// maxStatusDescription is GitHub's limit on a commit status description.
+const maxStatusDescription = 140
+
+// CheckRunReport is a completed check run.
+type CheckRunReport struct {
+	HeadSHA    string
+	Conclusion string // success, failure, or neutral
+	Title      string
+	Summary    string // markdown
+	DetailsURL string
+}
+
+// CreateGitHubCheckRun posts a completed check run named AutoPRCheckName on a
+// commit. The Checks API only accepts GitHub App installation tokens.
+func CreateGitHubCheckRun(ctx context.Context, token, baseURL, owner, repo string, run CheckRunReport) error {
+	payload := map[string]any{
+		"name":       AutoPRCheckName,
+		"head_sha":   run.HeadSHA,
+		"status":     "completed",
+		"conclusion": run.Conclusion,
+		"output": map[string]string{
+			"title":   run.Title,
+			"summary": run.Summary,
+		},
+	}
+	if run.DetailsURL != "" {
+		payload["details_url"] = run.DetailsURL
+	}
+	buf, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("marshal check run payload: %w", err)
+	}
+
+	apiURL := fmt.Sprintf("%s/repos/%s/%s/check-runs", NormalizeGitHubAPIBaseURL(baseURL), owner, repo)
+	resp, err := DoGitHubRequest(ctx, token, "POST", apiURL, buf)
+	if err != nil {
+		return fmt.Errorf("github create check run: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		body, _ := io.ReadAll(resp.Body)
+		msg := string(body)
+		if len(msg) > 4096 {
+			msg = msg[:4096]
+		}
+		return fmt.Errorf("github create check run: HTTP %d: %s", resp.StatusCode, msg)
+	}
+	return nil
+}
+
+// CreateGitHubCommitStatus sets the AutoPRCheckName commit status on sha.
+// state is success, failure, error, or pending; description is cut to
+// GitHub's 140 character limit.
+func CreateGitHubCommitStatus(ctx context.Context, token, baseURL, owner, repo, sha, state, description, targetURL string) error {
+	if runes := []rune(description); len(runes) > maxStatusDescription {
+		description = string(runes[:maxStatusDescription-3]) + "..."
+	}
+	payload := map[string]string{
+		"state":       state,
+		"context":     AutoPRCheckName,
+		"description": description,
+	}
+	if targetURL != "" {
+		payload["target_url"] = targetURL
+	}
+	buf, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("marshal commit status payload: %w", err)
+	}
+
+	apiURL := fmt.Sprintf("%s/repos/%s/%s/statuses/%s", NormalizeGitHubAPIBaseURL(baseURL), owner, repo, url.PathEscape(sha))
+	resp, err := DoGitHubRequest(ctx, token, "POST", apiURL, buf)
+	if err != nil {
+		return fmt.Errorf("github create commit status: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		body, _ := io.ReadAll(resp.Body)
+		msg := string(body)
+		if len(msg) > 4096 {
+			msg = msg[:4096]
+		}
+		return fmt.Errorf("github create commit status: HTTP %d: %s", resp.StatusCode, msg)
+	}
+	return nil
+}
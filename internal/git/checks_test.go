@@ -0,0 +1,114 @@
+package git
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCreateGitHubCheckRun(t *testing.T) {
+	var gotPath string
+	var gotBody map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		_ = json.NewDecoder(r.Body).Decode(&gotBody)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id": 1}`))
+	}))
+	defer srv.Close()
+
+	withGitHubAPIBase(t, srv.URL, func() {
+		err := CreateGitHubCheckRun(context.Background(), "tok", "", "org", "repo", CheckRunReport{
+			HeadSHA:    "abc123",
+			Conclusion: "failure",
+			Title:      "Tests failed",
+			Summary:    "| Step | Result |",
+		})
+		if err != nil {
+			t.Fatalf("create check run: %v", err)
+		}
+	})
+	if gotPath != "/repos/org/repo/check-runs" {
+		t.Fatalf("path = %q", gotPath)
+	}
+	if gotBody["name"] != AutoPRCheckName || gotBody["head_sha"] != "abc123" || gotBody["status"] != "completed" || gotBody["conclusion"] != "failure" {
+		t.Fatalf("unexpected payload: %v", gotBody)
+	}
+	output, _ := gotBody["output"].(map[string]any)
+	if output["title"] != "Tests failed" || output["summary"] != "| Step | Result |" {
+		t.Fatalf("unexpected output: %v", gotBody["output"])
+	}
+	if _, ok := gotBody["details_url"]; ok {
+		t.Fatal("empty details_url should be left out")
+	}
+}
+
+func TestCreateGitHubCheckRunReportsHTTPError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"message":"You must authenticate via a GitHub App."}`))
+	}))
+	defer srv.Close()
+
+	withGitHubAPIBase(t, srv.URL, func() {
+		err := CreateGitHubCheckRun(context.Background(), "tok", "", "org", "repo", CheckRunReport{HeadSHA: "abc", Conclusion: "success"})
+		if err == nil || !strings.Contains(err.Error(), "HTTP 403") {
+			t.Fatalf("err = %v, want HTTP 403", err)
+		}
+	})
+}
+
+func TestCreateGitHubCommitStatusTruncatesDescription(t *testing.T) {
+	var gotPath string
+	var gotBody map[string]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		_ = json.NewDecoder(r.Body).Decode(&gotBody)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+
+	withGitHubAPIBase(t, srv.URL, func() {
+		err := CreateGitHubCommitStatus(context.Background(), "tok", "", "org", "repo", "abc123", "success", strings.Repeat("✓", 200), "https://github.com/org/repo/pull/1")
+		if err != nil {
+			t.Fatalf("create commit status: %v", err)
+		}
+	})
+	if gotPath != "/repos/org/repo/statuses/abc123" {
+		t.Fatalf("path = %q", gotPath)
+	}
+	if gotBody["context"] != AutoPRCheckName || gotBody["state"] != "success" || gotBody["target_url"] != "https://github.com/org/repo/pull/1" {
+		t.Fatalf("unexpected payload: %v", gotBody)
+	}
+	if n := len([]rune(gotBody["description"])); n != maxStatusDescription {
+		t.Fatalf("description is %d runes, want %d", n, maxStatusDescription)
+	}
+}
+
+func TestGetGitHubCheckRunStatusIgnoresAutoPRCheck(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{
+			"total_count": 2,
+			"check_runs": []map[string]any{
+				{"name": "build", "status": "completed", "conclusion": "success"},
+				{"name": AutoPRCheckName, "status": "completed", "conclusion": "failure"},
+			},
+		})
+	}))
+	defer srv.Close()
+
+	var status CheckRunStatus
+	withGitHubAPIBase(t, srv.URL, func() {
+		var err error
+		status, err = GetGitHubCheckRunStatus(context.Background(), "tok", "", "org", "repo", "abc")
+		if err != nil {
+			t.Fatalf("check run status: %v", err)
+		}
+	})
+	if status.Total != 1 || status.Passed != 1 || status.Failed != 0 {
+		t.Fatalf("status = %+v, want only the build check", status)
+	}
+}
@@ -0,0 +1,75 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// CherryPick applies commit onto the current branch, recording the original
+// commit in the message (-x). Merge commits are picked relative to their first
+// parent. Returns true when conflicts are detected.
+func CherryPick(ctx context.Context, dir, commit string) (bool, error) {
+	parents, err := runGitOutput(ctx, dir, "rev-list", "--parents", "-n", "1", commit)
+	if err != nil {
+		return false, fmt.Errorf("resolve commit %s: %w", commit, err)
+	}
+	args := []string{"cherry-pick", "-x"}
+	if len(strings.Fields(parents)) > 2 {
+		args = append(args, "-m", "1")
+	}
+	args = append(args, commit)
+
+	stdout, stderr, err := runGitOutputAndErr(ctx, dir, args...)
+	if err == nil {
+		return false, nil
+	}
+	if isGitCherryPickConflict(stderr) || isGitCherryPickConflict(stdout) {
+		return true, nil
+	}
+	return false, fmt.Errorf("git cherry-pick %s: %w: %s %s", commit, err, strings.TrimSpace(stdout), strings.TrimSpace(stderr))
+}
+
+// CherryPickContinue commits a cherry-pick after conflict resolution.
+// Returns true when conflicts remain.
+func CherryPickContinue(ctx context.Context, dir string) (bool, error) {
+	stdout, stderr, err := runGitOutputAndErrWithNoEditor(ctx, dir, "cherry-pick", "--continue")
+	if err == nil {
+		return false, nil
+	}
+	if isGitCherryPickConflict(stderr) || isGitCherryPickConflict(stdout) {
+		return true, nil
+	}
+	return false, fmt.Errorf("git cherry-pick --continue: %w: %s %s", err, strings.TrimSpace(stdout), strings.TrimSpace(stderr))
+}
+
+// CherryPickAbort aborts the current cherry-pick.
+func CherryPickAbort(ctx context.Context, dir string) error {
+	if !IsCherryPickInProgress(dir) {
+		return nil
+	}
+	return runGit(ctx, dir, "cherry-pick", "--abort")
+}
+
+// IsCherryPickInProgress reports whether a cherry-pick is currently running.
+func IsCherryPickInProgress(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, ".git", "CHERRY_PICK_HEAD"))
+	return err == nil
+}
+
+func isGitCherryPickConflict(msg string) bool {
+	return strings.Contains(msg, "CONFLICT") ||
+		strings.Contains(msg, "could not apply") ||
+		strings.Contains(msg, "after resolving the conflicts")
+}
+
+// ResetToRemoteBranch discards local commits and changes so the current branch
+// matches origin/<branch>.
+func ResetToRemoteBranch(ctx context.Context, dir, branch string) error {
+	if err := runGit(ctx, dir, "reset", "--hard", "origin/"+branch); err != nil {
+		return fmt.Errorf("reset to origin/%s: %w", branch, err)
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package git
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCherryPickAppliesMergedCommitOntoReleaseBranch(t *testing.T) {
+	ctx := context.Background()
+	tmp := t.TempDir()
+
+	remote := createRemoteWithMainBranch(t, tmp)
+	seed := filepath.Join(tmp, "seed")
+	if err := os.WriteFile(filepath.Join(seed, "app.txt"), []byte("one\ntwo\nthree\n"), 0o644); err != nil {
+		t.Fatalf("write app: %v", err)
+	}
+	runGitCmd(t, seed, "add", "app.txt")
+	runGitCmd(t, seed, "commit", "-m", "add app")
+	runGitCmd(t, seed, "push", "origin", "main")
+	runGitCmd(t, seed, "push", "origin", "main:release-1.2")
+
+	if err := os.WriteFile(filepath.Join(seed, "app.txt"), []byte("one\ntwo fixed\nthree\n"), 0o644); err != nil {
+		t.Fatalf("write fix: %v", err)
+	}
+	runGitCmd(t, seed, "commit", "-am", "fix two")
+	runGitCmd(t, seed, "push", "origin", "main")
+	fix := strings.TrimSpace(runGitCmdOutput(t, seed, "rev-parse", "HEAD"))
+
+	worktree := filepath.Join(tmp, "worktree")
+	if err := CloneForJob(ctx, remote, "", worktree, "autopr/backport", "release-1.2"); err != nil {
+		t.Fatalf("clone for job: %v", err)
+	}
+	runGitCmd(t, worktree, "config", "user.email", "test@example.com")
+	runGitCmd(t, worktree, "config", "user.name", "Test User")
+
+	conflict, err := CherryPick(ctx, worktree, fix)
+	if err != nil || conflict {
+		t.Fatalf("cherry-pick: conflict=%v err=%v", conflict, err)
+	}
+	got, err := os.ReadFile(filepath.Join(worktree, "app.txt"))
+	if err != nil {
+		t.Fatalf("read app: %v", err)
+	}
+	if string(got) != "one\ntwo fixed\nthree\n" {
+		t.Fatalf("unexpected app.txt after cherry-pick:\n%s", got)
+	}
+	msg := runGitCmdOutput(t, worktree, "log", "-1", "--format=%B")
+	if !strings.Contains(msg, "cherry picked from commit "+fix) {
+		t.Fatalf("expected -x trailer in message, got:\n%s", msg)
+	}
+}
+
+func TestCherryPickReportsConflicts(t *testing.T) {
+	ctx := context.Background()
+	tmp := t.TempDir()
+
+	remote := createRemoteWithMainBranch(t, tmp)
+	seed := filepath.Join(tmp, "seed")
+	if err := os.WriteFile(filepath.Join(seed, "app.txt"), []byte("value = 1\n"), 0o644); err != nil {
+		t.Fatalf("write app: %v", err)
+	}
+	runGitCmd(t, seed, "add", "app.txt")
+	runGitCmd(t, seed, "commit", "-m", "add app")
+	runGitCmd(t, seed, "push", "origin", "main")
+
+	runGitCmd(t, seed, "checkout", "-b", "release-1.2")
+	if err := os.WriteFile(filepath.Join(seed, "app.txt"), []byte("value = 2\n"), 0o644); err != nil {
+		t.Fatalf("write release change: %v", err)
+	}
+	runGitCmd(t, seed, "commit", "-am", "release tweak")
+	runGitCmd(t, seed, "push", "origin", "release-1.2")
+	runGitCmd(t, seed, "checkout", "main")
+
+	if err := os.WriteFile(filepath.Join(seed, "app.txt"), []byte("value = 3\n"), 0o644); err != nil {
+		t.Fatalf("write main change: %v", err)
+	}
+	runGitCmd(t, seed, "commit", "-am", "main change")
+	runGitCmd(t, seed, "push", "origin", "main")
+	fix := strings.TrimSpace(runGitCmdOutput(t, seed, "rev-parse", "HEAD"))
+
+	worktree := filepath.Join(tmp, "worktree")
+	if err := CloneForJob(ctx, remote, "", worktree, "autopr/backport", "release-1.2"); err != nil {
+		t.Fatalf("clone for job: %v", err)
+	}
+	runGitCmd(t, worktree, "config", "user.email", "test@example.com")
+	runGitCmd(t, worktree, "config", "user.name", "Test User")
+
+	conflict, err := CherryPick(ctx, worktree, fix)
+	if err != nil {
+		t.Fatalf("cherry-pick: %v", err)
+	}
+	if !conflict || !IsCherryPickInProgress(worktree) {
+		t.Fatalf("expected conflicting cherry-pick in progress, conflict=%v", conflict)
+	}
+	files, err := ConflictedFiles(ctx, worktree)
+	if err != nil || len(files) != 1 || files[0] != "app.txt" {
+		t.Fatalf("expected app.txt conflicted, got %v err=%v", files, err)
+	}
+
+	if err := os.WriteFile(filepath.Join(worktree, "app.txt"), []byte("value = 3\n"), 0o644); err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if err := StageFiles(ctx, worktree, "app.txt"); err != nil {
+		t.Fatalf("stage: %v", err)
+	}
+	more, err := CherryPickContinue(ctx, worktree)
+	if err != nil || more {
+		t.Fatalf("cherry-pick continue: more=%v err=%v", more, err)
+	}
+	if IsCherryPickInProgress(worktree) {
+		t.Fatalf("expected cherry-pick to be finished")
+	}
+}
@@ -0,0 +1,95 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"autopr/internal/httputil"
+)
+
+// CommentGitHubPR adds a comment to a GitHub pull request.
+func CommentGitHubPR(ctx context.Context, token, baseURL, prURL, body string) error {
+	owner, repo, number, err := parseGitHubPRURL(prURL)
+	if err != nil {
+		return err
+	}
+	apiURL := fmt.Sprintf("%s/repos/%s/%s/issues/%s/comments", NormalizeGitHubAPIBaseURL(baseURL), owner, repo, number)
+	if _, err := githubIssueRequest(ctx, token, http.MethodPost, apiURL, map[string]any{"body": body}, "comment"); err != nil {
+		return err
+	}
+	return nil
+}
+
+// CommentGitLabMR adds a note to a GitLab merge request.
+// mrURL should be like "https://gitlab.com/org/repo/-/merge_requests/123".
+func CommentGitLabMR(ctx context.Context, token, baseURL, mrURL, body string) error {
+	baseURL = NormalizeGitLabBaseURL(baseURL)
+
+	matches := gitlabMRNumberRe.FindStringSubmatch(mrURL)
+	if len(matches) < 2 {
+		return fmt.Errorf("cannot parse MR number from URL: %s", mrURL)
+	}
+	mrNumber := matches[1]
+
+	trimmed := strings.TrimPrefix(mrURL, baseURL+"/")
+	before, _, ok := strings.Cut(trimmed, "/-/merge_requests/")
+	if !ok {
+		return fmt.Errorf("cannot parse project path from URL: %s", mrURL)
+	}
+	projectPath := strings.ReplaceAll(before, "/", "%2F")
+
+	buf, err := json.Marshal(map[string]any{"body": body})
+	if err != nil {
+		return fmt.Errorf("marshal gitlab note payload: %w", err)
+	}
+	apiURL := fmt.Sprintf("%s/api/v4/projects/%s/merge_requests/%s/notes", baseURL, projectPath, mrNumber)
+
+	resp, err := httputil.Do(ctx, func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, apiURL, bytes.NewReader(buf))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("PRIVATE-TOKEN", token)
+		req.Header.Set("Content-Type", "application/json")
+		return req, nil
+	}, httputil.DefaultRetryConfig())
+	if err != nil {
+		return fmt.Errorf("gitlab comment MR: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("gitlab comment MR: HTTP %d: %s", resp.StatusCode, string(respBody))
+	}
+	return nil
+}
+
+// CommentGiteaPR adds a comment to a Gitea/Forgejo pull request.
+func CommentGiteaPR(ctx context.Context, token, baseURL, prURL, body string) error {
+	owner, repo, index, err := parseGiteaPRURL(prURL)
+	if err != nil {
+		return err
+	}
+	buf, err := json.Marshal(map[string]any{"body": body})
+	if err != nil {
+		return fmt.Errorf("marshal gitea comment payload: %w", err)
+	}
+	apiURL := GiteaAPIURL(baseURL, fmt.Sprintf("/repos/%s/%s/issues/%s/comments", owner, repo, index))
+	resp, err := DoGiteaRequest(ctx, token, http.MethodPost, apiURL, buf)
+	if err != nil {
+		return fmt.Errorf("gitea comment PR: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("gitea comment PR: HTTP %d: %s", resp.StatusCode, truncateBody(respBody, 4096))
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package git
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCommentGiteaPRPostsIssueComment(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/api/v1/repos/org/repo/issues/9/comments" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		var payload map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("decode payload: %v", err)
+		}
+		if payload["body"] != "decisions" {
+			t.Errorf("unexpected body %q", payload["body"])
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+
+	if err := CommentGiteaPR(context.Background(), "tok", srv.URL, "https://gitea.example.com/org/repo/pulls/9", "decisions"); err != nil {
+		t.Fatalf("comment PR: %v", err)
+	}
+}
+
+func TestCommentGitLabMRPostsNote(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.EscapedPath() != "/api/v4/projects/acmecorp%2Fplaceholder/merge_requests/7/notes" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.EscapedPath())
+		}
+		if r.Header.Get("PRIVATE-TOKEN") != "tok" {
+			t.Errorf("token header mismatch: %q", r.Header.Get("PRIVATE-TOKEN"))
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+
+	mrURL := srv.URL + "/acmecorp/placeholder/-/merge_requests/7"
+	if err := CommentGitLabMR(context.Background(), "tok", srv.URL, mrURL, "decisions"); err != nil {
+		t.Fatalf("comment MR: %v", err)
+	}
+}
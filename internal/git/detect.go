@@ -0,0 +1,41 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// RepoRoot returns the top-level directory of the work tree containing dir.
+func RepoRoot(ctx context.Context, dir string) (string, error) {
+	out, err := runGitOutput(ctx, dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", fmt.Errorf("%s is not inside a git repository: %w", dir, err)
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// RemoteURL returns the URL of the named remote, or "" if it does not exist.
+func RemoteURL(ctx context.Context, dir, remoteName string) string {
+	url, err := getRemoteURL(ctx, dir, remoteName)
+	if err != nil {
+		return ""
+	}
+	return url
+}
+
+// DefaultBranch guesses the repository's default branch: the branch origin's
+// HEAD points at, else the checked-out branch, else "main".
+func DefaultBranch(ctx context.Context, dir string) string {
+	if out, err := runGitOutput(ctx, dir, "symbolic-ref", "--quiet", "refs/remotes/origin/HEAD"); err == nil {
+		if branch := strings.TrimPrefix(strings.TrimSpace(out), "refs/remotes/origin/"); branch != "" {
+			return branch
+		}
+	}
+	if out, err := runGitOutput(ctx, dir, "symbolic-ref", "--quiet", "--short", "HEAD"); err == nil {
+		if branch := strings.TrimSpace(out); branch != "" {
+			return branch
+		}
+	}
+	return "main"
+}
@@ -2,8 +2,12 @@ package git
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"os/exec"
+	"strconv"
+	"strings"
 )
 
 // DiffAgainstBase returns the raw diff of a worktree against origin/<baseBranch>.
@@ -23,6 +27,13 @@ func DiffAgainstBase(ctx context.Context, worktreePath, baseBranch string) (stri
 	return out, nil
 }
 
+// DiffChecksum returns a SHA-256 fingerprint of a diff, to tell whether a
+// branch changed between two looks at it.
+func DiffChecksum(diff string) string {
+	sum := sha256.Sum256([]byte(diff))
+	return hex.EncodeToString(sum[:])
+}
+
 // DiffFilesAgainstBase returns changed file paths against origin/<baseBranch>.
 // It runs `git add -N .` first so untracked files appear in the diff output.
 func DiffFilesAgainstBase(ctx context.Context, worktreePath, baseBranch string) (string, error) {
@@ -51,3 +62,81 @@ func DiffStatAgainstBase(ctx context.Context, worktreePath, baseBranch string) (
 	}
 	return out, nil
 }
+
+// FileChange is one file's line count in a diff: lines added plus removed.
+// Binary files have zero lines.
+type FileChange struct {
+	Path  string
+	Lines int
+}
+
+// DiffNumstatAgainstBase lists the files changed against origin/<baseBranch>,
+// including untracked files, with their added plus removed line counts.
+func DiffNumstatAgainstBase(ctx context.Context, worktreePath, baseBranch string) ([]FileChange, error) {
+	addN := exec.CommandContext(ctx, "git", "add", "-N", ".")
+	addN.Dir = worktreePath
+	_, _ = addN.CombinedOutput()
+
+	out, err := runGitOutput(ctx, worktreePath, "diff", "--numstat", "--no-renames", fmt.Sprintf("origin/%s", baseBranch))
+	if err != nil {
+		return nil, fmt.Errorf("diff numstat against origin/%s: %w", baseBranch, err)
+	}
+	var changes []FileChange
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		fields := strings.SplitN(line, "\t", 3)
+		if len(fields) < 3 {
+			continue
+		}
+		added, _ := strconv.Atoi(fields[0]) // "-" for binary files
+		removed, _ := strconv.Atoi(fields[1])
+		changes = append(changes, FileChange{Path: fields[2], Lines: added + removed})
+	}
+	return changes, nil
+}
+
+// FileChurn counts the commits on origin/<baseBranch> since the given git date
+// (e.g. "90 days ago") that touched each of files. Files with no such commits
+// are left out.
+func FileChurn(ctx context.Context, worktreePath, baseBranch, since string, files []string) (map[string]int, error) {
+	churn := map[string]int{}
+	if len(files) == 0 {
+		return churn, nil
+	}
+	args := append([]string{"log", "--format=", "--name-only", "--no-renames", "--since=" + since, "origin/" + baseBranch, "--"}, files...)
+	out, err := runGitOutput(ctx, worktreePath, args...)
+	if err != nil {
+		return nil, fmt.Errorf("log churn on origin/%s: %w", baseBranch, err)
+	}
+	for _, line := range strings.Split(out, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			churn[line]++
+		}
+	}
+	return churn, nil
+}
+
+// DiffCommits returns the raw diff between two commits in a worktree, e.g. the
+// branch state reviewed in one iteration against the next.
+func DiffCommits(ctx context.Context, worktreePath, fromSHA, toSHA string) (string, error) {
+	out, err := runGitOutput(ctx, worktreePath, "diff", fromSHA, toSHA)
+	if err != nil {
+		return "", fmt.Errorf("diff %s..%s: %w", shortSHA(fromSHA), shortSHA(toSHA), err)
+	}
+	return out, nil
+}
+
+// DiffStatCommits returns the --stat summary between two commits.
+func DiffStatCommits(ctx context.Context, worktreePath, fromSHA, toSHA string) (string, error) {
+	out, err := runGitOutput(ctx, worktreePath, "diff", "--stat", fromSHA, toSHA)
+	if err != nil {
+		return "", fmt.Errorf("diff stat %s..%s: %w", shortSHA(fromSHA), shortSHA(toSHA), err)
+	}
+	return out, nil
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 12 {
+		return sha[:12]
+	}
+	return sha
+}
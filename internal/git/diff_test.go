@@ -72,3 +72,62 @@ func TestDiffFilesAgainstBaseNoChanges(t *testing.T) {
 		t.Fatalf("expected no file changes, got %q", filesText)
 	}
 }
+
+func TestDiffNumstatAgainstBaseCountsLines(t *testing.T) {
+	ctx := context.Background()
+	tmp := t.TempDir()
+
+	remote := createRemoteWithMainBranch(t, tmp)
+	worktree := filepath.Join(tmp, "worktree")
+	if err := CloneForJob(ctx, remote, "", worktree, "autopr/job-1", "main"); err != nil {
+		t.Fatalf("clone for job: %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(worktree, "README.md"), []byte("hello changed\n"), 0o644); err != nil {
+		t.Fatalf("write tracked file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(worktree, "new file.txt"), []byte("one\ntwo\nthree\n"), 0o644); err != nil {
+		t.Fatalf("write untracked file: %v", err)
+	}
+
+	changes, err := DiffNumstatAgainstBase(ctx, worktree, "main")
+	if err != nil {
+		t.Fatalf("diff numstat against base: %v", err)
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	want := []FileChange{{Path: "README.md", Lines: 2}, {Path: "new file.txt", Lines: 3}}
+	if len(changes) != len(want) {
+		t.Fatalf("expected %v, got %v", want, changes)
+	}
+	for i := range want {
+		if changes[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, changes)
+		}
+	}
+}
+
+func TestFileChurnCountsBaseCommits(t *testing.T) {
+	ctx := context.Background()
+	tmp := t.TempDir()
+
+	remote := createRemoteWithMainBranch(t, tmp)
+	worktree := filepath.Join(tmp, "worktree")
+	if err := CloneForJob(ctx, remote, "", worktree, "autopr/job-1", "main"); err != nil {
+		t.Fatalf("clone for job: %v", err)
+	}
+	// A commit on the job branch isn't on origin/main and doesn't count.
+	if err := os.WriteFile(filepath.Join(worktree, "README.md"), []byte("branch edit\n"), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	runGitCmd(t, worktree, "config", "user.email", "test@example.com")
+	runGitCmd(t, worktree, "config", "user.name", "Test User")
+	runGitCmd(t, worktree, "commit", "-am", "branch edit")
+
+	churn, err := FileChurn(ctx, worktree, "main", "1 year ago", []string{"README.md", "missing.go"})
+	if err != nil {
+		t.Fatalf("file churn: %v", err)
+	}
+	if len(churn) != 1 || churn["README.md"] != 1 {
+		t.Fatalf("expected README.md touched once on main, got %v", churn)
+	}
+}
@@ -0,0 +1,131 @@
+package git
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DiffNotePrefix starts the lines AnnotateDiff adds to a diff. Git never
+// starts a file header line with it, and ParseDiff skips such lines.
+const DiffNotePrefix = "# "
+
+const lfsPointerVersion = "version https://git-lfs.github.com/spec/"
+
+// AnnotateDiff marks the files of a raw diff whose changes are not what they
+// look like: LFS files, whose diff shows the pointer rather than the content,
+// and submodules, whose diff is a commit bump. Each such file gets a note line
+// after its "diff --git" line. The result is for people to read; apply and
+// checksum the raw diff.
+func AnnotateDiff(diff string) string {
+	files := ParseDiff(diff)
+	if len(files) == 0 {
+		return diff
+	}
+	var b strings.Builder
+	i := -1
+	for line := range strings.SplitSeq(diff, "\n") {
+		b.WriteString(line)
+		b.WriteString("\n")
+		if !strings.HasPrefix(line, "diff --git ") {
+			continue
+		}
+		i++
+		if i < len(files) {
+			if note := DiffFileNote(files[i]); note != "" {
+				b.WriteString(DiffNotePrefix + note + "\n")
+			}
+		}
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// DiffFileNote describes an LFS or submodule change in one file of a diff,
+// or returns "" for an ordinary file.
+func DiffFileNote(f DiffFile) string {
+	oldVals, newVals := map[string]string{}, map[string]string{}
+	submodule, lfs := false, false
+	for _, h := range f.Header {
+		if strings.HasSuffix(h, " 160000") {
+			submodule = true
+		}
+	}
+	for _, hunk := range f.Hunks {
+		for _, line := range hunk.Lines {
+			if line == "" {
+				continue
+			}
+			sign, text := line[0], line[1:]
+			if strings.HasPrefix(text, lfsPointerVersion) {
+				lfs = true
+			}
+			key, val, _ := strings.Cut(text, " ")
+			if key == "Subproject" {
+				submodule = true
+				key, val = "commit", strings.TrimPrefix(val, "commit ")
+			}
+			switch sign {
+			case '-':
+				oldVals[key] = val
+			case '+':
+				newVals[key] = val
+			case ' ':
+				oldVals[key], newVals[key] = val, val
+			}
+		}
+	}
+
+	switch {
+	case submodule:
+		from, to := shortSubmoduleCommit(oldVals["commit"]), shortSubmoduleCommit(newVals["commit"])
+		switch {
+		case from == "" && to != "":
+			return fmt.Sprintf("submodule %s added at %s", f.Path, to)
+		case to == "" && from != "":
+			return fmt.Sprintf("submodule %s removed (was %s)", f.Path, from)
+		default:
+			return fmt.Sprintf("submodule %s bumped %s → %s", f.Path, from, to)
+		}
+	case lfs:
+		from, to := lfsSize(oldVals["size"]), lfsSize(newVals["size"])
+		switch {
+		case from == "" && to != "":
+			return fmt.Sprintf("LFS file %s added (%s); the diff shows its pointer, not its content", f.Path, to)
+		case to == "" && from != "":
+			return fmt.Sprintf("LFS file %s removed (was %s); the diff shows its pointer", f.Path, from)
+		default:
+			return fmt.Sprintf("LFS file %s changed (%s → %s); the diff shows its pointer, not its content", f.Path, from, to)
+		}
+	}
+	return ""
+}
+
+// shortSubmoduleCommit abbreviates a submodule commit, keeping a "-dirty" suffix.
+func shortSubmoduleCommit(sha string) string {
+	sha = strings.TrimSpace(sha)
+	base, dirty := strings.CutSuffix(sha, "-dirty")
+	if len(base) > 12 {
+		base = base[:12]
+	}
+	if dirty {
+		return base + "-dirty"
+	}
+	return base
+}
+
+// lfsSize formats the byte size from an LFS pointer's size line.
+func lfsSize(s string) string {
+	n, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+	if err != nil {
+		return ""
+	}
+	switch {
+	case n >= 1<<30:
+		return fmt.Sprintf("%.1f GB", float64(n)/(1<<30))
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%d bytes", n)
+}
@@ -0,0 +1,78 @@
+package git
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const specialFilesDiff = `diff --git a/assets/logo.psd b/assets/logo.psd
+index 1111111..2222222 100644
+--- a/assets/logo.psd
++++ b/assets/logo.psd
+@@ -1,3 +1,3 @@
+ version https://git-lfs.github.com/spec/v1
+-oid sha256:aaaa
+-size 1024
++oid sha256:bbbb
++size 3145728
+diff --git a/vendor/lib b/vendor/lib
+index 0123456789abcdef0123456789abcdef01234567..fedcba9876543210fedcba9876543210fedcba98 160000
+--- a/vendor/lib
++++ b/vendor/lib
+@@ -1 +1 @@
+-Subproject commit 0123456789abcdef0123456789abcdef01234567
++Subproject commit fedcba9876543210fedcba9876543210fedcba98
+diff --git a/main.go b/main.go
+index 3333333..4444444 100644
+--- a/main.go
++++ b/main.go
+@@ -1 +1 @@
+-package old
++package main
+`
+
+func TestAnnotateDiffMarksLFSAndSubmodules(t *testing.T) {
+	t.Parallel()
+	got := AnnotateDiff(specialFilesDiff)
+
+	wantNotes := []string{
+		"# LFS file assets/logo.psd changed (1.0 KB → 3.0 MB); the diff shows its pointer, not its content",
+		"# submodule vendor/lib bumped 0123456789ab → fedcba987654",
+	}
+	var notes []string
+	for line := range strings.SplitSeq(got, "\n") {
+		if strings.HasPrefix(line, DiffNotePrefix) {
+			notes = append(notes, line)
+		}
+	}
+	if !reflect.DeepEqual(notes, wantNotes) {
+		t.Fatalf("notes = %q, want %q", notes, wantNotes)
+	}
+	if !strings.HasPrefix(got, "diff --git a/assets/logo.psd b/assets/logo.psd\n"+wantNotes[0]+"\n") {
+		t.Fatalf("expected note right after the file's diff line:\n%s", got)
+	}
+	if strings.Count(got, "\n") != strings.Count(specialFilesDiff, "\n")+2 {
+		t.Fatalf("expected exactly two added lines:\n%s", got)
+	}
+
+	if !reflect.DeepEqual(ParseDiff(got), ParseDiff(specialFilesDiff)) {
+		t.Fatalf("expected ParseDiff to skip notes")
+	}
+}
+
+func TestDiffFileNoteAddedSubmoduleAndNewLFSFile(t *testing.T) {
+	t.Parallel()
+	sub := DiffFile{Path: "lib", Header: []string{"diff --git a/lib b/lib", "new file mode 160000"}, Hunks: []DiffHunk{{Lines: []string{"+Subproject commit abc"}}}}
+	if got := DiffFileNote(sub); got != "submodule lib added at abc" {
+		t.Fatalf("got %q", got)
+	}
+	lfs := DiffFile{Path: "a.bin", Hunks: []DiffHunk{{Lines: []string{"+version https://git-lfs.github.com/spec/v1", "+oid sha256:x", "+size 10"}}}}
+	if got := DiffFileNote(lfs); got != "LFS file a.bin added (10 bytes); the diff shows its pointer, not its content" {
+		t.Fatalf("got %q", got)
+	}
+	plain := DiffFile{Path: "main.go", Hunks: []DiffHunk{{Lines: []string{"+size 10"}}}}
+	if got := DiffFileNote(plain); got != "" {
+		t.Fatalf("expected no note for an ordinary file, got %q", got)
+	}
+}
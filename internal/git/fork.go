@@ -0,0 +1,124 @@
+package git
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// GitHubRepo is the part of a GitHub repository AutoPR reads to manage a fork.
+type GitHubRepo struct {
+	FullName      string `json:"full_name"`
+	DefaultBranch string `json:"default_branch"`
+	Fork          bool   `json:"fork"`
+	Parent        *struct {
+		FullName string `json:"full_name"`
+	} `json:"parent"`
+}
+
+// ParentFullName returns "owner/repo" of the repository a fork was made from,
+// or "" when repo is not a fork.
+func (r GitHubRepo) ParentFullName() string {
+	if r.Parent == nil {
+		return ""
+	}
+	return r.Parent.FullName
+}
+
+// GetGitHubRepo looks up a repository. It reports false when the repository
+// does not exist or the token cannot see it.
+func GetGitHubRepo(ctx context.Context, token, baseURL, owner, repo string) (GitHubRepo, bool, error) {
+	apiURL := fmt.Sprintf("%s/repos/%s/%s", NormalizeGitHubAPIBaseURL(baseURL), owner, repo)
+	resp, err := DoGitHubRequest(ctx, token, http.MethodGet, apiURL, nil)
+	if err != nil {
+		return GitHubRepo{}, false, fmt.Errorf("github get repo: %w", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode == http.StatusNotFound {
+		return GitHubRepo{}, false, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return GitHubRepo{}, false, fmt.Errorf("github get repo: HTTP %d: %s", resp.StatusCode, truncateBody(body, 4096))
+	}
+	var r GitHubRepo
+	if err := json.Unmarshal(body, &r); err != nil {
+		return GitHubRepo{}, false, fmt.Errorf("decode repo response: %w", err)
+	}
+	return r, true, nil
+}
+
+// CreateGitHubFork forks owner/repo into forkOwner, which is either the
+// token's user or an organization the user can create repositories in. Only
+// the default branch is copied. GitHub creates the fork in the background,
+// so it may take a little while to appear.
+func CreateGitHubFork(ctx context.Context, token, baseURL, owner, repo, forkOwner string) error {
+	apiBase := NormalizeGitHubAPIBaseURL(baseURL)
+	login, err := githubLogin(ctx, token, apiBase)
+	if err != nil {
+		return err
+	}
+	payload := map[string]any{"default_branch_only": true}
+	if !strings.EqualFold(login, forkOwner) {
+		payload["organization"] = forkOwner
+	}
+	buf, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("marshal fork payload: %w", err)
+	}
+	apiURL := fmt.Sprintf("%s/repos/%s/%s/forks", apiBase, owner, repo)
+	resp, err := DoGitHubRequest(ctx, token, http.MethodPost, apiURL, buf)
+	if err != nil {
+		return fmt.Errorf("github create fork: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted && resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("github create fork: HTTP %d: %s", resp.StatusCode, truncateBody(body, 4096))
+	}
+	return nil
+}
+
+// githubLogin returns the login of the token's user.
+func githubLogin(ctx context.Context, token, apiBase string) (string, error) {
+	resp, err := DoGitHubRequest(ctx, token, http.MethodGet, apiBase+"/user", nil)
+	if err != nil {
+		return "", fmt.Errorf("github get user: %w", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("github get user: HTTP %d: %s", resp.StatusCode, truncateBody(body, 4096))
+	}
+	var user struct {
+		Login string `json:"login"`
+	}
+	if err := json.Unmarshal(body, &user); err != nil {
+		return "", fmt.Errorf("decode user response: %w", err)
+	}
+	return user.Login, nil
+}
+
+// SyncGitHubFork fast-forwards branch of the fork forkOwner/repo to the same
+// branch of the repository it was forked from. It fails with HTTP 409 when
+// the fork's branch has commits of its own.
+func SyncGitHubFork(ctx context.Context, token, baseURL, forkOwner, repo, branch string) error {
+	buf, err := json.Marshal(map[string]string{"branch": branch})
+	if err != nil {
+		return fmt.Errorf("marshal sync fork payload: %w", err)
+	}
+	apiURL := fmt.Sprintf("%s/repos/%s/%s/merge-upstream", NormalizeGitHubAPIBaseURL(baseURL), forkOwner, repo)
+	resp, err := DoGitHubRequest(ctx, token, http.MethodPost, apiURL, buf)
+	if err != nil {
+		return fmt.Errorf("github sync fork: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("github sync fork %s/%s branch %s: HTTP %d: %s", forkOwner, repo, branch, resp.StatusCode, truncateBody(body, 4096))
+	}
+	return nil
+}
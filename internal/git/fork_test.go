@@ -0,0 +1,46 @@
+package git
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestGetGitHubRepo(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/repos/bots/repo" {
+			_, _ = w.Write([]byte(`{"full_name": "bots/repo", "default_branch": "trunk", "fork": true, "parent": {"full_name": "acme/repo"}}`))
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	withGitHubAPIBase(t, srv.URL, func() {
+		ctx := context.Background()
+		repo, found, err := GetGitHubRepo(ctx, "tok", "", "bots", "repo")
+		if err != nil || !found || repo.DefaultBranch != "trunk" || repo.ParentFullName() != "acme/repo" {
+			t.Fatalf("bots/repo: %+v, %v, %v", repo, found, err)
+		}
+		if _, found, err := GetGitHubRepo(ctx, "tok", "", "nobody", "repo"); err != nil || found {
+			t.Fatalf("nobody/repo: found %v, err %v; want not found", found, err)
+		}
+	})
+}
+
+func TestSyncGitHubForkReportsDivergedBranch(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusConflict)
+		_, _ = w.Write([]byte(`{"message": "merge conflict"}`))
+	}))
+	defer srv.Close()
+
+	withGitHubAPIBase(t, srv.URL, func() {
+		err := SyncGitHubFork(context.Background(), "tok", "", "bots", "repo", "main")
+		if err == nil || !strings.Contains(err.Error(), "HTTP 409") {
+			t.Fatalf("expected HTTP 409 error, got %v", err)
+		}
+	})
+}
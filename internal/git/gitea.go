@@ -0,0 +1,282 @@
+package git
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"autopr/internal/httputil"
+)
+
+// Gitea and Forgejo share the same REST API (served under /api/v1). It is
+// close to GitHub's but differs in auth, pagination, merge payloads, and in
+// reporting CI through commit statuses rather than check-runs.
+
+var giteaPRURLRe = regexp.MustCompile(`/([^/]+)/([^/]+)/pulls/(\d+)`)
+
+// NormalizeGiteaBaseURL trims whitespace and trailing slashes from a Gitea
+// base URL, e.g. "https://gitea.example.com/".
+func NormalizeGiteaBaseURL(baseURL string) string {
+	return strings.TrimRight(strings.TrimSpace(baseURL), "/")
+}
+
+// GiteaAPIURL returns the API URL for path (which must start with "/").
+func GiteaAPIURL(baseURL, path string) string {
+	return NormalizeGiteaBaseURL(baseURL) + "/api/v1" + path
+}
+
+// DoGiteaRequest sends an authenticated Gitea API request with retries.
+// body, when non-nil, is sent as JSON.
+func DoGiteaRequest(ctx context.Context, token, method, apiURL string, body []byte) (*http.Response, error) {
+	return httputil.Do(ctx, func() (*http.Request, error) {
+		var reader io.Reader
+		if body != nil {
+			reader = strings.NewReader(string(body))
+		}
+		req, err := http.NewRequestWithContext(ctx, method, apiURL, reader)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", "token "+token)
+		req.Header.Set("Accept", "application/json")
+		if body != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		return req, nil
+	}, httputil.DefaultRetryConfig())
+}
+
+// CreateGiteaPR creates a pull request on Gitea/Forgejo and returns its HTML URL.
+func CreateGiteaPR(ctx context.Context, token, baseURL, owner, repo, head, base, title, body string) (string, error) {
+	buf, err := json.Marshal(map[string]any{
+		"head":  head,
+		"base":  base,
+		"title": title,
+		"body":  body,
+	})
+	if err != nil {
+		return "", fmt.Errorf("marshal PR payload: %w", err)
+	}
+
+	apiURL := GiteaAPIURL(baseURL, fmt.Sprintf("/repos/%s/%s/pulls", owner, repo))
+	resp, err := DoGiteaRequest(ctx, token, "POST", apiURL, buf)
+	if err != nil {
+		return "", fmt.Errorf("gitea create PR: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(resp.Body)
+
+	// 409 Conflict — a PR already exists for this head branch.
+	if resp.StatusCode == http.StatusConflict {
+		if existing, err := FindGiteaPRByBranch(ctx, token, baseURL, owner, repo, head, "open"); err == nil && existing != "" {
+			return existing, nil
+		}
+	}
+	if resp.StatusCode != http.StatusCreated {
+		return "", fmt.Errorf("gitea create PR: HTTP %d: %s", resp.StatusCode, truncateBody(respBody, 4096))
+	}
+
+	var result struct {
+		HTMLURL string `json:"html_url"`
+	}
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return "", fmt.Errorf("decode PR response: %w", err)
+	}
+	return result.HTMLURL, nil
+}
+
+// FindGiteaPRByBranch looks up a PR whose head is the given branch.
+// state should be "open" or "all"; defaults to "open".
+func FindGiteaPRByBranch(ctx context.Context, token, baseURL, owner, repo, head, state string) (string, error) {
+	if state == "" {
+		state = "open"
+	}
+	const limit = 50
+	for page := 1; page <= 20; page++ {
+		apiURL := GiteaAPIURL(baseURL, fmt.Sprintf("/repos/%s/%s/pulls?state=%s&page=%d&limit=%d",
+			owner, repo, url.QueryEscape(state), page, limit))
+		resp, err := DoGiteaRequest(ctx, token, "GET", apiURL, nil)
+		if err != nil {
+			return "", err
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return "", fmt.Errorf("list PRs: HTTP %d", resp.StatusCode)
+		}
+
+		var prs []struct {
+			HTMLURL string `json:"html_url"`
+			Head    struct {
+				Ref string `json:"ref"`
+			} `json:"head"`
+		}
+		if err := json.Unmarshal(body, &prs); err != nil {
+			return "", err
+		}
+		for _, pr := range prs {
+			if pr.Head.Ref == head {
+				return pr.HTMLURL, nil
+			}
+		}
+		if len(prs) < limit {
+			break
+		}
+	}
+	return "", nil
+}
+
+// MergeGiteaPR merges a Gitea/Forgejo pull request.
+func MergeGiteaPR(ctx context.Context, token, baseURL, prURL, method string) error {
+	method, err := normalizeMergeMethod(method)
+	if err != nil {
+		return err
+	}
+	owner, repo, index, err := parseGiteaPRURL(prURL)
+	if err != nil {
+		return err
+	}
+
+	buf, err := json.Marshal(map[string]any{"Do": method})
+	if err != nil {
+		return fmt.Errorf("marshal gitea merge payload: %w", err)
+	}
+	apiURL := GiteaAPIURL(baseURL, fmt.Sprintf("/repos/%s/%s/pulls/%s/merge", owner, repo, index))
+	resp, err := DoGiteaRequest(ctx, token, "POST", apiURL, buf)
+	if err != nil {
+		return fmt.Errorf("gitea merge PR: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(resp.Body)
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNoContent:
+		return nil
+	case http.StatusConflict, http.StatusMethodNotAllowed, http.StatusUnprocessableEntity:
+		return fmt.Errorf("PR is not mergeable: HTTP %d: %s", resp.StatusCode, truncateBody(respBody, 4096))
+	}
+	return fmt.Errorf("gitea merge PR: HTTP %d: %s", resp.StatusCode, truncateBody(respBody, 4096))
+}
+
+// CheckGiteaPRStatus checks whether a Gitea/Forgejo PR has been merged or closed.
+// prURL should be like "https://gitea.example.com/owner/repo/pulls/12".
+func CheckGiteaPRStatus(ctx context.Context, token, baseURL, prURL string) (PRMergeStatus, error) {
+	owner, repo, index, err := parseGiteaPRURL(prURL)
+	if err != nil {
+		return PRMergeStatus{}, err
+	}
+
+	apiURL := GiteaAPIURL(baseURL, fmt.Sprintf("/repos/%s/%s/pulls/%s", owner, repo, index))
+	resp, err := DoGiteaRequest(ctx, token, "GET", apiURL, nil)
+	if err != nil {
+		return PRMergeStatus{}, fmt.Errorf("check PR status: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return PRMergeStatus{}, fmt.Errorf("check PR status: HTTP %d", resp.StatusCode)
+	}
+
+	var pr struct {
+		State          string `json:"state"`
+		Merged         bool   `json:"merged"`
+		CreatedAt      string `json:"created_at"`
+		MergedAt       string `json:"merged_at"`
+		ClosedAt       string `json:"closed_at"`
+		MergeCommitSHA string `json:"merge_commit_sha"`
+		Labels         []struct {
+			Name string `json:"name"`
+		} `json:"labels"`
+	}
+	if err := json.Unmarshal(body, &pr); err != nil {
+		return PRMergeStatus{}, fmt.Errorf("decode PR status: %w", err)
+	}
+	status := PRMergeStatus{Merged: pr.Merged, MergedAt: pr.MergedAt, CreatedAt: pr.CreatedAt}
+	if pr.Merged {
+		status.MergeCommitSHA = pr.MergeCommitSHA
+	}
+	for _, l := range pr.Labels {
+		status.Labels = append(status.Labels, l.Name)
+	}
+	if pr.State == "closed" && !pr.Merged {
+		status.Closed = true
+		status.ClosedAt = pr.ClosedAt
+	}
+	return status, nil
+}
+
+// GetGiteaCommitStatus fetches the combined commit status for ref and maps it
+// onto CheckRunStatus so CI gating treats Gitea like GitHub check-runs.
+func GetGiteaCommitStatus(ctx context.Context, token, baseURL, owner, repo, ref string) (CheckRunStatus, error) {
+	apiURL := GiteaAPIURL(baseURL, fmt.Sprintf("/repos/%s/%s/commits/%s/status", owner, repo, url.PathEscape(ref)))
+	resp, err := DoGiteaRequest(ctx, token, "GET", apiURL, nil)
+	if err != nil {
+		return CheckRunStatus{}, fmt.Errorf("gitea commit status: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return CheckRunStatus{}, fmt.Errorf("gitea commit status: HTTP %d: %s", resp.StatusCode, truncateBody(body, 512))
+	}
+
+	var result struct {
+		Statuses []struct {
+			Context   string `json:"context"`
+			Status    string `json:"status"`
+			TargetURL string `json:"target_url"`
+		} `json:"statuses"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return CheckRunStatus{}, fmt.Errorf("decode commit status: %w", err)
+	}
+
+	var status CheckRunStatus
+	status.Total = len(result.Statuses)
+	for _, s := range result.Statuses {
+		switch s.Status {
+		case "pending", "":
+			status.Pending++
+			continue
+		}
+		status.Completed++
+		switch s.Status {
+		case "success", "warning":
+			status.Passed++
+		default: // error, failure
+			status.Failed++
+			if status.FailedCheckName == "" {
+				status.FailedCheckName = s.Context
+				status.FailedCheckURL = s.TargetURL
+			}
+		}
+	}
+	return status, nil
+}
+
+func parseGiteaPRURL(prURL string) (owner, repo, index string, err error) {
+	u, err := url.Parse(prURL)
+	if err != nil {
+		return "", "", "", fmt.Errorf("cannot parse PR URL: %s", prURL)
+	}
+	matches := giteaPRURLRe.FindStringSubmatch(u.Path)
+	if len(matches) < 4 {
+		return "", "", "", fmt.Errorf("cannot parse owner/repo/index from URL: %s", prURL)
+	}
+	return matches[1], matches[2], matches[3], nil
+}
+
+func truncateBody(body []byte, max int) string {
+	msg := string(body)
+	if len(msg) > max {
+		msg = msg[:max]
+	}
+	return msg
+}
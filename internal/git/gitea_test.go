@@ -0,0 +1,124 @@
+package git
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCreateGiteaPRFallsBackToExistingOnConflict(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "token tok" {
+			t.Errorf("unexpected auth header %q", got)
+		}
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/api/v1/repos/org/repo/pulls":
+			w.WriteHeader(http.StatusConflict)
+			_, _ = io.WriteString(w, `{"message":"pull request already exists"}`)
+		case r.Method == "GET" && r.URL.Path == "/api/v1/repos/org/repo/pulls":
+			if r.URL.Query().Get("state") != "open" {
+				t.Errorf("unexpected state %q", r.URL.Query().Get("state"))
+			}
+			_, _ = io.WriteString(w, `[{"html_url":"https://gitea.example.com/org/repo/pulls/3","head":{"ref":"other"}},
+				{"html_url":"https://gitea.example.com/org/repo/pulls/4","head":{"ref":"autopr/abc"}}]`)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+
+	got, err := CreateGiteaPR(context.Background(), "tok", srv.URL+"/", "org", "repo", "autopr/abc", "main", "title", "body")
+	if err != nil {
+		t.Fatalf("create PR: %v", err)
+	}
+	if got != "https://gitea.example.com/org/repo/pulls/4" {
+		t.Fatalf("unexpected PR URL %q", got)
+	}
+}
+
+func TestMergeGiteaPRSendsMergeStyle(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/api/v1/repos/org/repo/pulls/9/merge" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		var payload map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("decode payload: %v", err)
+		}
+		if payload["Do"] != "squash" {
+			t.Errorf("unexpected merge style %q", payload["Do"])
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	if err := MergeGiteaPR(context.Background(), "tok", srv.URL, srv.URL+"/org/repo/pulls/9", "squash"); err != nil {
+		t.Fatalf("merge PR: %v", err)
+	}
+}
+
+func TestCheckGiteaPRStatus(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/repos/org/repo/pulls/7" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		_, _ = io.WriteString(w, `{"state":"closed","merged":true,"merged_at":"2026-01-01T00:00:00Z","merge_commit_sha":"abc","labels":[{"name":"backport/release-1"}]}`)
+	}))
+	defer srv.Close()
+
+	status, err := CheckGiteaPRStatus(context.Background(), "tok", srv.URL, srv.URL+"/org/repo/pulls/7")
+	if err != nil {
+		t.Fatalf("check status: %v", err)
+	}
+	if !status.Merged || status.Closed || status.MergeCommitSHA != "abc" {
+		t.Fatalf("unexpected status: %+v", status)
+	}
+	if len(status.Labels) != 1 || status.Labels[0] != "backport/release-1" {
+		t.Fatalf("unexpected labels: %v", status.Labels)
+	}
+}
+
+func TestGetGiteaCommitStatus(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/repos/org/repo/commits/abc123/status" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		_, _ = io.WriteString(w, `{"statuses":[
+			{"context":"build","status":"success"},
+			{"context":"lint","status":"failure","target_url":"https://ci.example.com/1"},
+			{"context":"e2e","status":"pending"}]}`)
+	}))
+	defer srv.Close()
+
+	status, err := GetGiteaCommitStatus(context.Background(), "tok", srv.URL, "org", "repo", "abc123")
+	if err != nil {
+		t.Fatalf("commit status: %v", err)
+	}
+	want := CheckRunStatus{Total: 3, Completed: 2, Passed: 1, Failed: 1, Pending: 1, FailedCheckName: "lint", FailedCheckURL: "https://ci.example.com/1"}
+	if status != want {
+		t.Fatalf("unexpected status: %+v", status)
+	}
+}
+
+func TestParseGiteaPRURL(t *testing.T) {
+	t.Parallel()
+
+	owner, repo, index, err := parseGiteaPRURL("https://gitea.example.com/org/repo/pulls/12")
+	if err != nil || owner != "org" || repo != "repo" || index != "12" {
+		t.Fatalf("unexpected parse: %q %q %q err=%v", owner, repo, index, err)
+	}
+	if _, _, _, err := parseGiteaPRURL("https://github.com/org/repo/pull/12"); err == nil {
+		t.Fatalf("expected error for GitHub PR URL")
+	}
+}
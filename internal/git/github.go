@@ -0,0 +1,114 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"autopr/internal/httputil"
+)
+
+var githubAPIBase = "https://api.github.com"
+
+// githubAPIVersion is the REST API version requested via X-GitHub-Api-Version.
+// Older GitHub Enterprise Server releases reject the header with HTTP 400;
+// such hosts are remembered and queried without it.
+const githubAPIVersion = "2022-11-28"
+
+var githubUnversionedHosts sync.Map
+
+// NormalizeGitHubAPIBaseURL returns the REST API root for a GitHub base URL.
+// Empty (or github.com) means the public API. Any other host is treated as
+// GitHub Enterprise Server, whose API lives under /api/v3.
+func NormalizeGitHubAPIBaseURL(baseURL string) string {
+	baseURL = strings.TrimRight(strings.TrimSpace(baseURL), "/")
+	if baseURL == "" {
+		return githubAPIBase
+	}
+	u, err := url.Parse(baseURL)
+	if err != nil || u.Host == "" {
+		return baseURL
+	}
+	switch strings.ToLower(u.Host) {
+	case "github.com", "api.github.com":
+		return "https://api.github.com"
+	}
+	if !strings.HasSuffix(u.Path, "/api/v3") {
+		return baseURL + "/api/v3"
+	}
+	return baseURL
+}
+
+// DoGitHubRequest sends an authenticated GitHub REST request with retries.
+// body, when non-nil, is sent as JSON.
+func DoGitHubRequest(ctx context.Context, token, method, apiURL string, body []byte) (*http.Response, error) {
+	host := ""
+	if u, err := url.Parse(apiURL); err == nil {
+		host = u.Host
+	}
+	for {
+		_, unversioned := githubUnversionedHosts.Load(host)
+		resp, err := httputil.Do(ctx, func() (*http.Request, error) {
+			var reader io.Reader
+			if body != nil {
+				reader = bytes.NewReader(body)
+			}
+			req, err := http.NewRequestWithContext(ctx, method, apiURL, reader)
+			if err != nil {
+				return nil, err
+			}
+			req.Header.Set("Authorization", "Bearer "+token)
+			req.Header.Set("Accept", "application/vnd.github+json")
+			if body != nil {
+				req.Header.Set("Content-Type", "application/json")
+			}
+			if !unversioned {
+				req.Header.Set("X-GitHub-Api-Version", githubAPIVersion)
+			}
+			return req, nil
+		}, httputil.DefaultRetryConfig())
+		if err != nil || unversioned || resp.StatusCode != http.StatusBadRequest {
+			return resp, err
+		}
+
+		respBody, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if !isUnsupportedGitHubAPIVersion(string(respBody)) {
+			resp.Body = io.NopCloser(bytes.NewReader(respBody))
+			return resp, nil
+		}
+		slog.Info("github: API version not supported by server, retrying without version header", "host", host, "version", githubAPIVersion)
+		githubUnversionedHosts.Store(host, true)
+	}
+}
+
+func isUnsupportedGitHubAPIVersion(body string) bool {
+	lower := strings.ToLower(body)
+	return strings.Contains(lower, "x-github-api-version") || strings.Contains(lower, "api version")
+}
+
+// parseGitHubPRURL extracts owner, repo, and number from a PR URL such as
+// "https://github.com/owner/repo/pull/123" or the GHES equivalent.
+func parseGitHubPRURL(prURL string) (owner, repo, number string, err error) {
+	matches := githubPRNumberRe.FindStringSubmatch(prURL)
+	if len(matches) < 2 {
+		return "", "", "", fmt.Errorf("cannot parse PR number from URL: %s", prURL)
+	}
+	u, err := url.Parse(prURL)
+	if err != nil {
+		return "", "", "", fmt.Errorf("cannot parse owner/repo from URL: %s", prURL)
+	}
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	for i := 2; i < len(parts); i++ {
+		if parts[i] == "pull" && parts[i-2] != "" && parts[i-1] != "" {
+			return parts[i-2], parts[i-1], matches[1], nil
+		}
+	}
+	return "", "", "", fmt.Errorf("cannot parse owner/repo from URL: %s", prURL)
+}
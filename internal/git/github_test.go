@@ -0,0 +1,109 @@
+package git
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestNormalizeGitHubAPIBaseURL(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]string{
+		"https://github.com":              "https://api.github.com",
+		"https://api.github.com/":         "https://api.github.com",
+		"https://ghe.example.com":         "https://ghe.example.com/api/v3",
+		"https://ghe.example.com/api/v3/": "https://ghe.example.com/api/v3",
+	}
+	for in, want := range cases {
+		if got := NormalizeGitHubAPIBaseURL(in); got != want {
+			t.Errorf("NormalizeGitHubAPIBaseURL(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestParseGitHubPRURLSupportsEnterpriseHosts(t *testing.T) {
+	t.Parallel()
+
+	owner, repo, number, err := parseGitHubPRURL("https://ghe.example.com/org/repo/pull/42")
+	if err != nil || owner != "org" || repo != "repo" || number != "42" {
+		t.Fatalf("unexpected parse: %q %q %q err=%v", owner, repo, number, err)
+	}
+	if _, _, _, err := parseGitHubPRURL("https://ghe.example.com/pull/42"); err == nil {
+		t.Fatalf("expected error for URL without owner/repo")
+	}
+}
+
+func TestCheckGitHubPRStatusUsesEnterpriseBaseURL(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v3/repos/org/repo/pulls/7" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		_, _ = io.WriteString(w, `{"state":"closed","merged":true,"merged_at":"2026-01-01T00:00:00Z","merge_commit_sha":"abc"}`)
+	}))
+	defer srv.Close()
+
+	status, err := CheckGitHubPRStatus(context.Background(), "tok", srv.URL, srv.URL+"/org/repo/pull/7")
+	if err != nil {
+		t.Fatalf("check status: %v", err)
+	}
+	if !status.Merged || status.MergeCommitSHA != "abc" {
+		t.Fatalf("unexpected status: %+v", status)
+	}
+}
+
+func TestDoGitHubRequestFallsBackWhenAPIVersionUnsupported(t *testing.T) {
+	t.Parallel()
+
+	var versioned, unversioned atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-GitHub-Api-Version") != "" {
+			versioned.Add(1)
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = io.WriteString(w, `{"message":"Unsupported 'X-GitHub-Api-Version' header"}`)
+			return
+		}
+		unversioned.Add(1)
+		_, _ = io.WriteString(w, `{}`)
+	}))
+	defer srv.Close()
+
+	for range 2 {
+		resp, err := DoGitHubRequest(context.Background(), "tok", "GET", srv.URL+"/api/v3/meta", nil)
+		if err != nil {
+			t.Fatalf("request: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("expected 200 after fallback, got %d", resp.StatusCode)
+		}
+	}
+	if versioned.Load() != 1 || unversioned.Load() != 2 {
+		t.Fatalf("expected one versioned attempt then unversioned requests, got %d/%d", versioned.Load(), unversioned.Load())
+	}
+}
+
+func TestDoGitHubRequestKeepsOtherBadRequests(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = io.WriteString(w, `{"message":"Problems parsing JSON"}`)
+	}))
+	defer srv.Close()
+
+	resp, err := DoGitHubRequest(context.Background(), "tok", "POST", srv.URL+"/repos/o/r/pulls", []byte("{"))
+	if err != nil {
+		t.Fatalf("request: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusBadRequest || string(body) != `{"message":"Problems parsing JSON"}` {
+		t.Fatalf("expected original 400 response, got %d %s", resp.StatusCode, body)
+	}
+}
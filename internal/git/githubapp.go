@@ -0,0 +1,186 @@
+package git
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// githubAppTokenRefreshMargin is how long before expiry a cached installation
+// token is replaced. Tokens live for an hour; a job step can take minutes.
+const githubAppTokenRefreshMargin = 10 * time.Minute
+
+// GitHubApp authenticates as a GitHub App and mints installation access
+// tokens, caching one per installation until close to expiry.
+type GitHubApp struct {
+	baseURL string
+	appID   int64
+	key     *rsa.PrivateKey
+	now     func() time.Time
+
+	mu            sync.Mutex
+	tokens        map[int64]githubAppToken
+	installations map[string]int64 // "owner/repo" -> installation ID
+}
+
+type githubAppToken struct {
+	token     string
+	expiresAt time.Time
+}
+
+// NewGitHubApp reads the App's PEM private key from keyPath. baseURL selects
+// a GitHub Enterprise Server instance; empty means github.com.
+func NewGitHubApp(baseURL string, appID int64, keyPath string) (*GitHubApp, error) {
+	pemBytes, err := os.ReadFile(keyPath)
+	if err != nil {
+		return nil, fmt.Errorf("read github app private key: %w", err)
+	}
+	key, err := parseRSAPrivateKey(pemBytes)
+	if err != nil {
+		return nil, fmt.Errorf("parse github app private key: %w", err)
+	}
+	return &GitHubApp{
+		baseURL:       baseURL,
+		appID:         appID,
+		key:           key,
+		now:           time.Now,
+		tokens:        make(map[int64]githubAppToken),
+		installations: make(map[string]int64),
+	}, nil
+}
+
+// InstallationToken returns a valid token for installationID, minting a new
+// one when the cached token is missing or close to expiry.
+func (a *GitHubApp) InstallationToken(ctx context.Context, installationID int64) (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if cached, ok := a.tokens[installationID]; ok && a.now().Add(githubAppTokenRefreshMargin).Before(cached.expiresAt) {
+		return cached.token, nil
+	}
+
+	apiURL := fmt.Sprintf("%s/app/installations/%d/access_tokens", NormalizeGitHubAPIBaseURL(a.baseURL), installationID)
+	body, err := a.appRequest(ctx, "POST", apiURL, http.StatusCreated)
+	if err != nil {
+		return "", fmt.Errorf("github app installation token: %w", err)
+	}
+
+	var result struct {
+		Token     string    `json:"token"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return "", fmt.Errorf("decode installation token: %w", err)
+	}
+	if result.Token == "" {
+		return "", fmt.Errorf("github app installation token: empty token in response")
+	}
+	a.tokens[installationID] = githubAppToken{token: result.Token, expiresAt: result.ExpiresAt}
+	return result.Token, nil
+}
+
+// RepoInstallationID looks up the App installation covering owner/repo.
+// Results are cached for the life of the process.
+func (a *GitHubApp) RepoInstallationID(ctx context.Context, owner, repo string) (int64, error) {
+	key := owner + "/" + repo
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if id, ok := a.installations[key]; ok {
+		return id, nil
+	}
+
+	apiURL := fmt.Sprintf("%s/repos/%s/%s/installation", NormalizeGitHubAPIBaseURL(a.baseURL), owner, repo)
+	body, err := a.appRequest(ctx, "GET", apiURL, http.StatusOK)
+	if err != nil {
+		return 0, fmt.Errorf("github app installation for %s: %w", key, err)
+	}
+	var result struct {
+		ID int64 `json:"id"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return 0, fmt.Errorf("decode installation: %w", err)
+	}
+	if result.ID == 0 {
+		return 0, fmt.Errorf("github app installation for %s: missing id", key)
+	}
+	a.installations[key] = result.ID
+	return result.ID, nil
+}
+
+// appRequest sends a request authenticated as the App itself (JWT).
+func (a *GitHubApp) appRequest(ctx context.Context, method, apiURL string, wantStatus int) ([]byte, error) {
+	jwt, err := a.appJWT()
+	if err != nil {
+		return nil, err
+	}
+	resp, err := DoGitHubRequest(ctx, jwt, method, apiURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != wantStatus {
+		msg := string(body)
+		if len(msg) > 512 {
+			msg = msg[:512]
+		}
+		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, msg)
+	}
+	return body, nil
+}
+
+// appJWT builds the short-lived RS256 JWT that authenticates as the App.
+func (a *GitHubApp) appJWT() (string, error) {
+	now := a.now()
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"RS256","typ":"JWT"}`))
+	claims, err := json.Marshal(map[string]any{
+		// Backdate to tolerate clock drift, as GitHub recommends.
+		"iat": now.Add(-60 * time.Second).Unix(),
+		"exp": now.Add(9 * time.Minute).Unix(),
+		"iss": strconv.FormatInt(a.appID, 10),
+	})
+	if err != nil {
+		return "", fmt.Errorf("marshal github app claims: %w", err)
+	}
+	signingInput := header + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(signingInput))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, a.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("sign github app jwt: %w", err)
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(sig), nil
+}
+
+func parseRSAPrivateKey(pemBytes []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(pemBytes)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM block found")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("private key is not RSA")
+	}
+	return key, nil
+}
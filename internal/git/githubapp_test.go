@@ -0,0 +1,87 @@
+package git
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGitHubAppInstallationTokenMintsAndCaches(t *testing.T) {
+	t.Parallel()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	keyPath := filepath.Join(t.TempDir(), "app.pem")
+	pemBytes := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	if err := os.WriteFile(keyPath, pemBytes, 0o600); err != nil {
+		t.Fatalf("write key: %v", err)
+	}
+
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if r.Method != "POST" || r.URL.Path != "/api/v3/app/installations/42/access_tokens" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		jwt := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		parts := strings.Split(jwt, ".")
+		if len(parts) != 3 {
+			t.Errorf("malformed jwt %q", jwt)
+			return
+		}
+		sig, _ := base64.RawURLEncoding.DecodeString(parts[2])
+		digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+		if err := rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, digest[:], sig); err != nil {
+			t.Errorf("jwt signature: %v", err)
+		}
+		claims, _ := base64.RawURLEncoding.DecodeString(parts[1])
+		if !strings.Contains(string(claims), `"iss":"7"`) {
+			t.Errorf("unexpected claims %s", claims)
+		}
+		w.WriteHeader(http.StatusCreated)
+		_, _ = io.WriteString(w, `{"token":"ghs_installation","expires_at":"`+time.Now().Add(time.Hour).UTC().Format(time.RFC3339)+`"}`)
+	}))
+	defer srv.Close()
+
+	app, err := NewGitHubApp(srv.URL, 7, keyPath)
+	if err != nil {
+		t.Fatalf("new github app: %v", err)
+	}
+	for range 2 {
+		token, err := app.InstallationToken(context.Background(), 42)
+		if err != nil {
+			t.Fatalf("token: %v", err)
+		}
+		if token != "ghs_installation" {
+			t.Fatalf("unexpected token %q", token)
+		}
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("expected cached token to be reused, got %d mint calls", calls.Load())
+	}
+
+	// Near expiry the token is minted again.
+	app.now = func() time.Time { return time.Now().Add(55 * time.Minute) }
+	if _, err := app.InstallationToken(context.Background(), 42); err != nil {
+		t.Fatalf("refresh token: %v", err)
+	}
+	if calls.Load() != 2 {
+		t.Fatalf("expected refresh near expiry, got %d mint calls", calls.Load())
+	}
+}
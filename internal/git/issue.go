@@ -0,0 +1,169 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"autopr/internal/httputil"
+)
+
+// AddGitHubIssueLabels adds labels to a GitHub issue. Missing labels are
+// created by GitHub on the fly.
+func AddGitHubIssueLabels(ctx context.Context, token, baseURL, owner, repo, number string, labels []string) error {
+	if len(labels) == 0 {
+		return nil
+	}
+	apiURL := fmt.Sprintf("%s/repos/%s/%s/issues/%s/labels", NormalizeGitHubAPIBaseURL(baseURL), owner, repo, url.PathEscape(number))
+	_, err := githubIssueRequest(ctx, token, "POST", apiURL, map[string]any{"labels": labels}, "add labels")
+	return err
+}
+
+// RemoveGitHubIssueLabel removes a single label from a GitHub issue. A label
+// that is not present on the issue is not treated as an error.
+func RemoveGitHubIssueLabel(ctx context.Context, token, baseURL, owner, repo, number, label string) error {
+	apiURL := fmt.Sprintf("%s/repos/%s/%s/issues/%s/labels/%s", NormalizeGitHubAPIBaseURL(baseURL), owner, repo, url.PathEscape(number), url.PathEscape(label))
+	status, err := githubIssueRequest(ctx, token, "DELETE", apiURL, nil, "remove label")
+	if status == http.StatusNotFound {
+		return nil
+	}
+	return err
+}
+
+// AddGitHubIssueAssignees assigns users to a GitHub issue.
+func AddGitHubIssueAssignees(ctx context.Context, token, baseURL, owner, repo, number string, assignees []string) error {
+	if len(assignees) == 0 {
+		return nil
+	}
+	apiURL := fmt.Sprintf("%s/repos/%s/%s/issues/%s/assignees", NormalizeGitHubAPIBaseURL(baseURL), owner, repo, url.PathEscape(number))
+	_, err := githubIssueRequest(ctx, token, "POST", apiURL, map[string]any{"assignees": assignees}, "add assignees")
+	return err
+}
+
+// RemoveGitHubIssueAssignees unassigns users from a GitHub issue.
+func RemoveGitHubIssueAssignees(ctx context.Context, token, baseURL, owner, repo, number string, assignees []string) error {
+	if len(assignees) == 0 {
+		return nil
+	}
+	apiURL := fmt.Sprintf("%s/repos/%s/%s/issues/%s/assignees", NormalizeGitHubAPIBaseURL(baseURL), owner, repo, url.PathEscape(number))
+	_, err := githubIssueRequest(ctx, token, "DELETE", apiURL, map[string]any{"assignees": assignees}, "remove assignees")
+	return err
+}
+
+// githubIssueRequest sends an issue mutation and returns the HTTP status code
+// alongside any error so callers can tolerate specific statuses.
+func githubIssueRequest(ctx context.Context, token, method, apiURL string, payload map[string]any, action string) (int, error) {
+	var buf []byte
+	if payload != nil {
+		var err error
+		buf, err = json.Marshal(payload)
+		if err != nil {
+			return 0, fmt.Errorf("marshal issue payload: %w", err)
+		}
+	}
+
+	resp, err := DoGitHubRequest(ctx, token, method, apiURL, buf)
+	if err != nil {
+		return 0, fmt.Errorf("github issue %s: %w", action, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return resp.StatusCode, fmt.Errorf("github issue %s: HTTP %d: %s", action, resp.StatusCode, string(respBody))
+	}
+	return resp.StatusCode, nil
+}
+
+// GitLabIssueUpdate describes label and assignee changes for a GitLab issue.
+// A nil AssigneeIDs leaves assignees untouched; an empty slice unassigns all.
+type GitLabIssueUpdate struct {
+	AddLabels    []string
+	RemoveLabels []string
+	AssigneeIDs  []int
+}
+
+// UpdateGitLabIssue applies label and assignee changes to a GitLab issue.
+func UpdateGitLabIssue(ctx context.Context, token, baseURL, projectID, iid string, update GitLabIssueUpdate) error {
+	baseURL = NormalizeGitLabBaseURL(baseURL)
+
+	payload := map[string]any{}
+	if len(update.AddLabels) > 0 {
+		payload["add_labels"] = strings.Join(update.AddLabels, ",")
+	}
+	if len(update.RemoveLabels) > 0 {
+		payload["remove_labels"] = strings.Join(update.RemoveLabels, ",")
+	}
+	if update.AssigneeIDs != nil {
+		payload["assignee_ids"] = update.AssigneeIDs
+	}
+	if len(payload) == 0 {
+		return nil
+	}
+	buf, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("marshal issue payload: %w", err)
+	}
+
+	apiURL := fmt.Sprintf("%s/api/v4/projects/%s/issues/%s", baseURL, url.PathEscape(projectID), url.PathEscape(iid))
+
+	resp, err := httputil.Do(ctx, func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, "PUT", apiURL, bytes.NewReader(buf))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("PRIVATE-TOKEN", token)
+		req.Header.Set("Content-Type", "application/json")
+		return req, nil
+	}, httputil.DefaultRetryConfig())
+	if err != nil {
+		return fmt.Errorf("gitlab update issue: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("gitlab update issue: HTTP %d: %s", resp.StatusCode, string(respBody))
+	}
+	return nil
+}
+
+// LookupGitLabUserID resolves a GitLab username to its numeric user ID.
+func LookupGitLabUserID(ctx context.Context, token, baseURL, username string) (int, error) {
+	baseURL = NormalizeGitLabBaseURL(baseURL)
+	apiURL := fmt.Sprintf("%s/api/v4/users?username=%s", baseURL, url.QueryEscape(username))
+
+	resp, err := httputil.Do(ctx, func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, "GET", apiURL, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("PRIVATE-TOKEN", token)
+		return req, nil
+	}, httputil.DefaultRetryConfig())
+	if err != nil {
+		return 0, fmt.Errorf("gitlab lookup user: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("gitlab lookup user: HTTP %d", resp.StatusCode)
+	}
+
+	var users []struct {
+		ID int `json:"id"`
+	}
+	if err := json.Unmarshal(body, &users); err != nil {
+		return 0, fmt.Errorf("decode gitlab users: %w", err)
+	}
+	if len(users) == 0 {
+		return 0, fmt.Errorf("gitlab lookup user: %q not found", username)
+	}
+	return users[0].ID, nil
+}
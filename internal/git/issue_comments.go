@@ -0,0 +1,278 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+
+	"autopr/internal/httputil"
+)
+
+// issueCommentsPerPage is the page size of the issue comment listings. Gitea
+// caps pages at 50 items by default, so it is asked for that many.
+const (
+	issueCommentsPerPage      = 100
+	giteaIssueCommentsPerPage = 50
+)
+
+// maxIssueCommentPages bounds the pages an issue comment listing fetches. On
+// longer threads the oldest pages are left out, so the newest comments are
+// always listed.
+const maxIssueCommentPages = 20
+
+// lastPageRE matches the rel="last" entry of a Link header.
+var lastPageRE = regexp.MustCompile(`<([^>]+)>;\s*rel="last"`)
+
+// IssueComment is one comment on an issue, oldest first in listings.
+type IssueComment struct {
+	ID        int64
+	Author    string
+	Body      string
+	CreatedAt string
+}
+
+type forgeIssueComment struct {
+	ID   int64 `json:"id"`
+	User struct {
+		Login string `json:"login"`
+	} `json:"user"`
+	Body      string `json:"body"`
+	CreatedAt string `json:"created_at"`
+}
+
+// ListGitHubIssueComments returns the comments on a GitHub issue, oldest
+// first.
+func ListGitHubIssueComments(ctx context.Context, token, baseURL, owner, repo, number string) ([]IssueComment, error) {
+	commentsURL := fmt.Sprintf("%s/repos/%s/%s/issues/%s/comments", NormalizeGitHubAPIBaseURL(baseURL), owner, repo, url.PathEscape(number))
+	return listForgeIssueComments("github", func(page int) (*http.Response, error) {
+		return DoGitHubRequest(ctx, token, http.MethodGet, fmt.Sprintf("%s?per_page=%d&page=%d", commentsURL, issueCommentsPerPage, page), nil)
+	})
+}
+
+// ListGiteaIssueComments returns the comments on a Gitea/Forgejo issue,
+// oldest first.
+func ListGiteaIssueComments(ctx context.Context, token, baseURL, owner, repo, index string) ([]IssueComment, error) {
+	commentsURL := GiteaAPIURL(baseURL, fmt.Sprintf("/repos/%s/%s/issues/%s/comments", owner, repo, url.PathEscape(index)))
+	return listForgeIssueComments("gitea", func(page int) (*http.Response, error) {
+		return DoGiteaRequest(ctx, token, http.MethodGet, fmt.Sprintf("%s?limit=%d&page=%d", commentsURL, giteaIssueCommentsPerPage, page), nil)
+	})
+}
+
+// listForgeIssueComments fetches the pages of a GitHub or Gitea comment
+// listing, which both list comments oldest first. The first page's Link
+// header gives the last page; past maxIssueCommentPages pages, the listing
+// skips to the newest ones.
+func listForgeIssueComments(forge string, fetch func(page int) (*http.Response, error)) ([]IssueComment, error) {
+	resp, err := fetch(1)
+	if err != nil {
+		return nil, fmt.Errorf("%s list issue comments: %w", forge, err)
+	}
+	last := lastLinkPage(resp.Header.Get("Link"))
+	out, err := decodeForgeIssueComments(resp, forge)
+	if err != nil || last <= 1 {
+		return out, err
+	}
+	first := 2
+	if last > maxIssueCommentPages {
+		first = last - maxIssueCommentPages + 1
+		out = nil
+	}
+	for page := first; page <= last; page++ {
+		resp, err := fetch(page)
+		if err != nil {
+			return nil, fmt.Errorf("%s list issue comments: %w", forge, err)
+		}
+		comments, err := decodeForgeIssueComments(resp, forge)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, comments...)
+	}
+	return out, nil
+}
+
+// lastLinkPage returns the page number of the rel="last" link in a Link
+// header, or 0 when there is none.
+func lastLinkPage(link string) int {
+	m := lastPageRE.FindStringSubmatch(link)
+	if m == nil {
+		return 0
+	}
+	u, err := url.Parse(m[1])
+	if err != nil {
+		return 0
+	}
+	page, _ := strconv.Atoi(u.Query().Get("page"))
+	return page
+}
+
+func decodeForgeIssueComments(resp *http.Response, forge string) ([]IssueComment, error) {
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("%s list issue comments: HTTP %d: %s", forge, resp.StatusCode, string(body))
+	}
+	var raw []forgeIssueComment
+	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
+		return nil, fmt.Errorf("decode %s issue comments: %w", forge, err)
+	}
+	out := make([]IssueComment, 0, len(raw))
+	for _, c := range raw {
+		out = append(out, IssueComment{ID: c.ID, Author: c.User.Login, Body: c.Body, CreatedAt: c.CreatedAt})
+	}
+	return out, nil
+}
+
+// ListGitLabIssueNotes returns the newest comments on a GitLab issue, up to
+// a page of them, oldest first. System notes such as label changes are left
+// out.
+func ListGitLabIssueNotes(ctx context.Context, token, baseURL, projectID, iid string) ([]IssueComment, error) {
+	apiURL := fmt.Sprintf("%s/api/v4/projects/%s/issues/%s/notes?sort=desc&order_by=created_at&per_page=%d",
+		NormalizeGitLabBaseURL(baseURL), url.PathEscape(projectID), url.PathEscape(iid), issueCommentsPerPage)
+	resp, err := httputil.Do(ctx, func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("PRIVATE-TOKEN", token)
+		return req, nil
+	}, httputil.DefaultRetryConfig())
+	if err != nil {
+		return nil, fmt.Errorf("gitlab list issue notes: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("gitlab list issue notes: HTTP %d: %s", resp.StatusCode, string(body))
+	}
+	var raw []struct {
+		ID     int64 `json:"id"`
+		Author struct {
+			Username string `json:"username"`
+		} `json:"author"`
+		Body      string `json:"body"`
+		CreatedAt string `json:"created_at"`
+		System    bool   `json:"system"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
+		return nil, fmt.Errorf("decode gitlab issue notes: %w", err)
+	}
+	out := make([]IssueComment, 0, len(raw))
+	for _, n := range slices.Backward(raw) {
+		if n.System {
+			continue
+		}
+		out = append(out, IssueComment{ID: n.ID, Author: n.Author.Username, Body: n.Body, CreatedAt: n.CreatedAt})
+	}
+	return out, nil
+}
+
+// UpsertGitHubIssueComment posts body as a comment on a GitHub issue, or
+// replaces the body of comment commentID when it is set and still exists. It
+// returns the ID of the comment holding body.
+func UpsertGitHubIssueComment(ctx context.Context, token, baseURL, owner, repo, number string, commentID int64, body string) (int64, error) {
+	buf, err := json.Marshal(map[string]any{"body": body})
+	if err != nil {
+		return 0, fmt.Errorf("marshal github comment payload: %w", err)
+	}
+	api := NormalizeGitHubAPIBaseURL(baseURL)
+	if commentID != 0 {
+		resp, err := DoGitHubRequest(ctx, token, http.MethodPatch, fmt.Sprintf("%s/repos/%s/%s/issues/comments/%d", api, owner, repo, commentID), buf)
+		if err != nil {
+			return 0, fmt.Errorf("github edit issue comment: %w", err)
+		}
+		if id, err := decodeCommentID(resp, "github edit issue comment"); !errors.Is(err, errNotFound) {
+			return id, err
+		}
+	}
+	resp, err := DoGitHubRequest(ctx, token, http.MethodPost, fmt.Sprintf("%s/repos/%s/%s/issues/%s/comments", api, owner, repo, url.PathEscape(number)), buf)
+	if err != nil {
+		return 0, fmt.Errorf("github comment issue: %w", err)
+	}
+	return decodeCommentID(resp, "github comment issue")
+}
+
+// UpsertGiteaIssueComment is UpsertGitHubIssueComment for a Gitea/Forgejo
+// issue.
+func UpsertGiteaIssueComment(ctx context.Context, token, baseURL, owner, repo, index string, commentID int64, body string) (int64, error) {
+	buf, err := json.Marshal(map[string]any{"body": body})
+	if err != nil {
+		return 0, fmt.Errorf("marshal gitea comment payload: %w", err)
+	}
+	if commentID != 0 {
+		resp, err := DoGiteaRequest(ctx, token, http.MethodPatch, GiteaAPIURL(baseURL, fmt.Sprintf("/repos/%s/%s/issues/comments/%d", owner, repo, commentID)), buf)
+		if err != nil {
+			return 0, fmt.Errorf("gitea edit issue comment: %w", err)
+		}
+		if id, err := decodeCommentID(resp, "gitea edit issue comment"); !errors.Is(err, errNotFound) {
+			return id, err
+		}
+	}
+	resp, err := DoGiteaRequest(ctx, token, http.MethodPost, GiteaAPIURL(baseURL, fmt.Sprintf("/repos/%s/%s/issues/%s/comments", owner, repo, url.PathEscape(index))), buf)
+	if err != nil {
+		return 0, fmt.Errorf("gitea comment issue: %w", err)
+	}
+	return decodeCommentID(resp, "gitea comment issue")
+}
+
+// UpsertGitLabIssueNote is UpsertGitHubIssueComment for a GitLab issue.
+func UpsertGitLabIssueNote(ctx context.Context, token, baseURL, projectID, iid string, noteID int64, body string) (int64, error) {
+	buf, err := json.Marshal(map[string]any{"body": body})
+	if err != nil {
+		return 0, fmt.Errorf("marshal gitlab note payload: %w", err)
+	}
+	notesURL := fmt.Sprintf("%s/api/v4/projects/%s/issues/%s/notes", NormalizeGitLabBaseURL(baseURL), url.PathEscape(projectID), url.PathEscape(iid))
+	do := func(method, apiURL, action string) (int64, error) {
+		resp, err := httputil.Do(ctx, func() (*http.Request, error) {
+			req, err := http.NewRequestWithContext(ctx, method, apiURL, bytes.NewReader(buf))
+			if err != nil {
+				return nil, err
+			}
+			req.Header.Set("PRIVATE-TOKEN", token)
+			req.Header.Set("Content-Type", "application/json")
+			return req, nil
+		}, httputil.DefaultRetryConfig())
+		if err != nil {
+			return 0, fmt.Errorf("%s: %w", action, err)
+		}
+		return decodeCommentID(resp, action)
+	}
+	if noteID != 0 {
+		if id, err := do(http.MethodPut, fmt.Sprintf("%s/%d", notesURL, noteID), "gitlab edit issue note"); !errors.Is(err, errNotFound) {
+			return id, err
+		}
+	}
+	return do(http.MethodPost, notesURL, "gitlab comment issue")
+}
+
+// errNotFound is decodeCommentID's error for HTTP 404, which an edit gets
+// when the comment was deleted.
+var errNotFound = errors.New("HTTP 404: not found")
+
+// decodeCommentID reads the ID of the comment a create or edit request
+// returned, closing resp.
+func decodeCommentID(resp *http.Response, action string) (int64, error) {
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return 0, fmt.Errorf("%s: %w", action, errNotFound)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return 0, fmt.Errorf("%s: HTTP %d: %s", action, resp.StatusCode, string(body))
+	}
+	var out struct {
+		ID int64 `json:"id"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return 0, fmt.Errorf("%s: decode response: %w", action, err)
+	}
+	return out.ID, nil
+}
@@ -0,0 +1,149 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestListGitHubIssueComments(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/api/v3/repos/org/repo/issues/12/comments" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		w.Write([]byte(`[{"user":{"login":"octocat"},"body":"Still happens on 1.4","created_at":"2025-02-01T10:00:00Z"}]`))
+	}))
+	defer srv.Close()
+
+	comments, err := ListGitHubIssueComments(context.Background(), "tok", srv.URL, "org", "repo", "12")
+	if err != nil {
+		t.Fatalf("list comments: %v", err)
+	}
+	if len(comments) != 1 || comments[0].Author != "octocat" || comments[0].Body != "Still happens on 1.4" || comments[0].CreatedAt != "2025-02-01T10:00:00Z" {
+		t.Fatalf("unexpected comments %+v", comments)
+	}
+}
+
+func TestListGitHubIssueCommentsReachesTheNewestPages(t *testing.T) {
+	t.Parallel()
+
+	const last = maxIssueCommentPages + 5
+	var mu sync.Mutex
+	var pages []string
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page := r.URL.Query().Get("page")
+		mu.Lock()
+		pages = append(pages, page)
+		mu.Unlock()
+		w.Header().Set("Link", fmt.Sprintf(`<%s%s?per_page=100&page=%d>; rel="last"`, srv.URL, r.URL.Path, last))
+		fmt.Fprintf(w, `[{"id":%s,"user":{"login":"octocat"},"body":"comment"}]`, page)
+	}))
+	defer srv.Close()
+
+	comments, err := ListGitHubIssueComments(context.Background(), "tok", srv.URL, "org", "repo", "12")
+	if err != nil {
+		t.Fatalf("list comments: %v", err)
+	}
+	if len(comments) != maxIssueCommentPages || comments[0].ID != 6 || comments[len(comments)-1].ID != last {
+		t.Fatalf("expected the newest %d pages, got %+v", maxIssueCommentPages, comments)
+	}
+	if len(pages) != maxIssueCommentPages+1 || pages[0] != "1" || pages[1] != "6" {
+		t.Fatalf("fetched pages %v", pages)
+	}
+}
+
+func TestListGitLabIssueNotesSkipsSystemNotes(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.EscapedPath() != "/api/v4/projects/group%2Frepo/issues/7/notes" {
+			t.Errorf("unexpected path %s", r.URL.EscapedPath())
+		}
+		if r.Header.Get("PRIVATE-TOKEN") != "tok" {
+			t.Errorf("token header mismatch: %q", r.Header.Get("PRIVATE-TOKEN"))
+		}
+		if r.URL.Query().Get("sort") != "desc" {
+			t.Errorf("expected the newest notes first, got sort=%q", r.URL.Query().Get("sort"))
+		}
+		w.Write([]byte(`[
+			{"author":{"username":"bob"},"body":"Fixed on main?","created_at":"2025-02-01T11:00:00Z","system":false},
+			{"author":{"username":"alice"},"body":"Repro attached","created_at":"2025-02-01T10:00:00Z","system":false},
+			{"author":{"username":"bot"},"body":"added ~bug label","created_at":"2025-02-01T09:00:00Z","system":true}
+		]`))
+	}))
+	defer srv.Close()
+
+	comments, err := ListGitLabIssueNotes(context.Background(), "tok", srv.URL, "group/repo", "7")
+	if err != nil {
+		t.Fatalf("list notes: %v", err)
+	}
+	if len(comments) != 2 || comments[0].Author != "alice" || comments[1].Author != "bob" {
+		t.Fatalf("expected the user notes oldest first, got %+v", comments)
+	}
+}
+
+func TestListGiteaIssueCommentsReportsHTTPErrors(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/repos/org/repo/issues/3/comments" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		http.Error(w, "not found", http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	if _, err := ListGiteaIssueComments(context.Background(), "tok", srv.URL, "org", "repo", "3"); err == nil {
+		t.Fatal("expected an error for a missing issue")
+	}
+}
+
+func TestUpsertGitHubIssueCommentRepostsDeletedComment(t *testing.T) {
+	t.Parallel()
+
+	var requests []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		switch {
+		case r.Method == http.MethodPatch && r.URL.Path == "/api/v3/repos/org/repo/issues/comments/5":
+			http.Error(w, "not found", http.StatusNotFound)
+		case r.Method == http.MethodPost && r.URL.Path == "/api/v3/repos/org/repo/issues/12/comments":
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"id":9}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+
+	id, err := UpsertGitHubIssueComment(context.Background(), "tok", srv.URL, "org", "repo", "12", 5, "status")
+	if err != nil || id != 9 {
+		t.Fatalf("upsert = %d, %v", id, err)
+	}
+	if len(requests) != 2 {
+		t.Fatalf("expected an edit and then a post, got %v", requests)
+	}
+}
+
+func TestUpsertGitLabIssueNoteEditsExistingNote(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut || r.URL.EscapedPath() != "/api/v4/projects/group%2Frepo/issues/7/notes/3" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.EscapedPath())
+		}
+		w.Write([]byte(`{"id":3}`))
+	}))
+	defer srv.Close()
+
+	id, err := UpsertGitLabIssueNote(context.Background(), "tok", srv.URL, "group/repo", "7", 3, "status")
+	if err != nil || id != 3 {
+		t.Fatalf("upsert = %d, %v", id, err)
+	}
+}
@@ -0,0 +1,63 @@
+package git
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// lfsSkipSmudgeEnv keeps git-lfs from downloading objects during clone, where
+// a failed download fails the whole clone; pullLFS fetches them afterwards.
+const lfsSkipSmudgeEnv = "GIT_LFS_SKIP_SMUDGE=1"
+
+// usesLFS reports whether the checkout's top-level .gitattributes sends any
+// paths through the LFS filter.
+func usesLFS(dir string) bool {
+	f, err := os.Open(filepath.Join(dir, ".gitattributes"))
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.Contains(line, "filter=lfs") {
+			return true
+		}
+	}
+	return false
+}
+
+// lfsInstalled reports whether the git-lfs extension is on PATH.
+var lfsInstalled = func(ctx context.Context) bool {
+	return exec.CommandContext(ctx, "git", "lfs", "version").Run() == nil
+}
+
+// pullLFS sets up git-lfs in a fresh clone and replaces its LFS pointers with
+// the real files. The local install adds the hooks that upload LFS objects on
+// push, so files the job adds under LFS patterns reach the LFS server. Without
+// git-lfs the pointers stay in place and a warning is logged.
+func pullLFS(ctx context.Context, dir string, opts gitRunOptions) error {
+	if !usesLFS(dir) {
+		return nil
+	}
+	if !lfsInstalled(ctx) {
+		slog.Warn("repository uses git lfs but git-lfs is not installed; LFS files stay as pointers", "path", dir)
+		return nil
+	}
+	if err := runGitWithOptions(ctx, dir, opts, "lfs", "install", "--local"); err != nil {
+		return fmt.Errorf("git lfs install: %w", err)
+	}
+	if err := runGitWithOptions(ctx, dir, opts, "lfs", "pull"); err != nil {
+		return fmt.Errorf("git lfs pull: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,225 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// DiffFile is one file section of a unified diff.
+type DiffFile struct {
+	OldPath string
+	Path    string
+	Header  []string // lines before the first hunk (diff --git, index, ---/+++)
+	Hunks   []DiffHunk
+	Binary  bool
+}
+
+// DiffHunk is a single @@ section of a file diff.
+type DiffHunk struct {
+	Header string
+	Lines  []string
+}
+
+// DiffExclusions maps a file path to the 1-based hunk numbers excluded from it.
+// An empty slice excludes the whole file.
+type DiffExclusions map[string][]int
+
+// ParseDiff splits raw `git diff` output into files and hunks.
+func ParseDiff(diff string) []DiffFile {
+	var files []DiffFile
+	var cur *DiffFile
+	var hunk *DiffHunk
+
+	flushHunk := func() {
+		if cur != nil && hunk != nil {
+			cur.Hunks = append(cur.Hunks, *hunk)
+		}
+		hunk = nil
+	}
+	flushFile := func() {
+		flushHunk()
+		if cur != nil {
+			files = append(files, *cur)
+		}
+		cur = nil
+	}
+
+	for line := range strings.SplitSeq(strings.TrimRight(diff, "\n"), "\n") {
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			flushFile()
+			oldPath, newPath := parseDiffGitPaths(line)
+			cur = &DiffFile{OldPath: oldPath, Path: newPath, Header: []string{line}}
+		case cur == nil:
+			continue
+		case hunk == nil && strings.HasPrefix(line, DiffNotePrefix):
+			// A note added by AnnotateDiff, not part of the diff.
+			continue
+		case strings.HasPrefix(line, "@@"):
+			flushHunk()
+			hunk = &DiffHunk{Header: line}
+		case hunk != nil:
+			hunk.Lines = append(hunk.Lines, line)
+		default:
+			cur.Header = append(cur.Header, line)
+			switch {
+			case strings.HasPrefix(line, "rename from "):
+				cur.OldPath = strings.TrimPrefix(line, "rename from ")
+			case strings.HasPrefix(line, "rename to "):
+				cur.Path = strings.TrimPrefix(line, "rename to ")
+			case strings.HasPrefix(line, "Binary files ") || line == "GIT binary patch":
+				cur.Binary = true
+			}
+		}
+	}
+	flushFile()
+	return files
+}
+
+func parseDiffGitPaths(line string) (string, string) {
+	rest := strings.TrimPrefix(line, "diff --git ")
+	// Paths are "a/<path> b/<path>"; split on the last " b/" so paths with
+	// spaces are kept intact.
+	idx := strings.LastIndex(rest, " b/")
+	if idx < 0 {
+		return rest, rest
+	}
+	return strings.TrimPrefix(rest[:idx], "a/"), rest[idx+len(" b/"):]
+}
+
+// ExcludedPatch renders the parts of files selected by excl as a patch.
+func ExcludedPatch(files []DiffFile, excl DiffExclusions) string {
+	var b strings.Builder
+	for _, f := range files {
+		hunks, ok := excl[f.Path]
+		if !ok {
+			continue
+		}
+		for _, line := range f.Header {
+			b.WriteString(line)
+			b.WriteString("\n")
+		}
+		for i, h := range f.Hunks {
+			if len(hunks) > 0 && !slices.Contains(hunks, i+1) {
+				continue
+			}
+			b.WriteString(h.Header)
+			b.WriteString("\n")
+			for _, line := range h.Lines {
+				b.WriteString(line)
+				b.WriteString("\n")
+			}
+		}
+	}
+	return b.String()
+}
+
+// RevertExclusions undoes the excluded parts of the worktree's changes against
+// origin/<baseBranch>. Whole files are restored from the base branch (or
+// deleted if they are new); individual hunks are reverse-applied.
+// The caller is responsible for committing the result.
+func RevertExclusions(ctx context.Context, worktreePath, baseBranch string, files []DiffFile, excl DiffExclusions) error {
+	base := fmt.Sprintf("origin/%s", baseBranch)
+	var partial []DiffFile
+	for _, f := range files {
+		hunks, ok := excl[f.Path]
+		if !ok {
+			continue
+		}
+		if len(hunks) > 0 && len(uniqueInts(hunks)) < len(f.Hunks) {
+			partial = append(partial, f)
+			continue
+		}
+		if err := restoreFromBase(ctx, worktreePath, base, f); err != nil {
+			return err
+		}
+	}
+	if len(partial) == 0 {
+		return nil
+	}
+
+	patch := ExcludedPatch(partial, excl)
+	tmp, err := os.CreateTemp("", "autopr-exclude-*.patch")
+	if err != nil {
+		return fmt.Errorf("create exclusion patch: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.WriteString(patch); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write exclusion patch: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("write exclusion patch: %w", err)
+	}
+	if err := runGit(ctx, worktreePath, "apply", "-R", "--whitespace=nowarn", tmp.Name()); err != nil {
+		return fmt.Errorf("reverse-apply excluded hunks: %w", err)
+	}
+	return nil
+}
+
+func restoreFromBase(ctx context.Context, worktreePath, base string, f DiffFile) error {
+	paths := []string{f.OldPath}
+	if f.Path != f.OldPath {
+		paths = append(paths, f.Path)
+	}
+	for _, p := range paths {
+		if _, err := runGitOutput(ctx, worktreePath, "cat-file", "-e", base+":"+p); err == nil {
+			if err := runGit(ctx, worktreePath, "checkout", base, "--", p); err != nil {
+				return fmt.Errorf("restore %s from %s: %w", p, base, err)
+			}
+			continue
+		}
+		if err := runGit(ctx, worktreePath, "rm", "-f", "-q", "--ignore-unmatch", "--", p); err != nil {
+			return fmt.Errorf("remove %s: %w", p, err)
+		}
+	}
+	return nil
+}
+
+// ValidateExclusions checks that every excluded path and hunk exists in files
+// and that at least one change is left after excluding.
+func ValidateExclusions(files []DiffFile, excl DiffExclusions) error {
+	if len(excl) == 0 {
+		return fmt.Errorf("no changes excluded")
+	}
+	byPath := make(map[string]DiffFile, len(files))
+	for _, f := range files {
+		byPath[f.Path] = f
+	}
+	paths := make([]string, 0, len(excl))
+	for p := range excl {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	kept := len(files)
+	for _, p := range paths {
+		f, ok := byPath[p]
+		if !ok {
+			return fmt.Errorf("file %q is not part of the diff", p)
+		}
+		hunks := excl[p]
+		for _, n := range hunks {
+			if n < 1 || n > len(f.Hunks) {
+				return fmt.Errorf("file %q has no hunk %d (hunks: %d)", p, n, len(f.Hunks))
+			}
+		}
+		if len(hunks) == 0 || len(uniqueInts(hunks)) == len(f.Hunks) {
+			kept--
+		}
+	}
+	if kept == 0 {
+		return fmt.Errorf("all changes excluded; nothing left to approve")
+	}
+	return nil
+}
+
+func uniqueInts(values []int) []int {
+	out := slices.Clone(values)
+	slices.Sort(out)
+	return slices.Compact(out)
+}
@@ -0,0 +1,104 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRevertExclusionsDropsFilesAndHunks(t *testing.T) {
+	ctx := context.Background()
+	tmp := t.TempDir()
+
+	remote := createRemoteWithMainBranch(t, tmp)
+	seed := filepath.Join(tmp, "seed")
+	var base []string
+	for i := 1; i <= 20; i++ {
+		base = append(base, fmt.Sprintf("line %d", i))
+	}
+	if err := os.WriteFile(filepath.Join(seed, "lines.txt"), []byte(strings.Join(base, "\n")+"\n"), 0o644); err != nil {
+		t.Fatalf("write seed lines: %v", err)
+	}
+	runGitCmd(t, seed, "add", "lines.txt")
+	runGitCmd(t, seed, "commit", "-m", "add lines")
+	runGitCmd(t, seed, "push", "origin", "main")
+
+	worktree := filepath.Join(tmp, "worktree")
+	if err := CloneForJob(ctx, remote, "", worktree, "autopr/job-1", "main"); err != nil {
+		t.Fatalf("clone for job: %v", err)
+	}
+	runGitCmd(t, worktree, "config", "user.email", "test@example.com")
+	runGitCmd(t, worktree, "config", "user.name", "Test User")
+
+	changed := append([]string(nil), base...)
+	changed[1] = "line 2 keep"
+	changed[17] = "line 18 drop"
+	if err := os.WriteFile(filepath.Join(worktree, "lines.txt"), []byte(strings.Join(changed, "\n")+"\n"), 0o644); err != nil {
+		t.Fatalf("write lines: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(worktree, "new.txt"), []byte("new file\n"), 0o644); err != nil {
+		t.Fatalf("write new file: %v", err)
+	}
+	if _, err := CommitAll(ctx, worktree, "change"); err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+
+	diff, err := DiffAgainstBase(ctx, worktree, "main")
+	if err != nil {
+		t.Fatalf("diff: %v", err)
+	}
+	files := ParseDiff(diff)
+	if len(files) != 2 {
+		t.Fatalf("expected 2 files in diff, got %+v", files)
+	}
+	if files[0].Path != "lines.txt" || len(files[0].Hunks) != 2 {
+		t.Fatalf("expected lines.txt with 2 hunks, got %+v", files[0])
+	}
+
+	excl := DiffExclusions{"new.txt": nil, "lines.txt": {2}}
+	if err := ValidateExclusions(files, excl); err != nil {
+		t.Fatalf("validate exclusions: %v", err)
+	}
+	patch := ExcludedPatch(files, excl)
+	if !strings.Contains(patch, "+line 18 drop") || strings.Contains(patch, "line 2 keep") {
+		t.Fatalf("unexpected excluded patch:\n%s", patch)
+	}
+
+	if err := RevertExclusions(ctx, worktree, "main", files, excl); err != nil {
+		t.Fatalf("revert exclusions: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(worktree, "new.txt")); !os.IsNotExist(err) {
+		t.Fatalf("expected new.txt to be removed, stat err=%v", err)
+	}
+	got, err := os.ReadFile(filepath.Join(worktree, "lines.txt"))
+	if err != nil {
+		t.Fatalf("read lines: %v", err)
+	}
+	if !strings.Contains(string(got), "line 2 keep") || strings.Contains(string(got), "line 18 drop") {
+		t.Fatalf("unexpected lines.txt after revert:\n%s", got)
+	}
+}
+
+func TestValidateExclusionsRejectsUnknownAndTotalExclusion(t *testing.T) {
+	files := []DiffFile{
+		{Path: "a.go", OldPath: "a.go", Hunks: []DiffHunk{{Header: "@@ -1 +1 @@"}, {Header: "@@ -9 +9 @@"}}},
+		{Path: "b.go", OldPath: "b.go", Hunks: []DiffHunk{{Header: "@@ -1 +1 @@"}}},
+	}
+	cases := map[string]DiffExclusions{
+		"empty":        {},
+		"unknown file": {"c.go": nil},
+		"bad hunk":     {"a.go": {3}},
+		"everything":   {"a.go": {1, 2}, "b.go": nil},
+	}
+	for name, excl := range cases {
+		if err := ValidateExclusions(files, excl); err == nil {
+			t.Fatalf("%s: expected validation error", name)
+		}
+	}
+	if err := ValidateExclusions(files, DiffExclusions{"a.go": {1}, "b.go": nil}); err != nil {
+		t.Fatalf("expected partial exclusion to validate: %v", err)
+	}
+}
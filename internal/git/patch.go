@@ -0,0 +1,97 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// MergeBase returns the best common ancestor of two revisions.
+func MergeBase(ctx context.Context, dir, a, b string) (string, error) {
+	out, err := runGitOutput(ctx, dir, "merge-base", a, b)
+	if err != nil {
+		return "", fmt.Errorf("merge-base %s %s: %w", a, b, err)
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// HasUncommittedChanges reports whether the working tree or index differs
+// from HEAD, including untracked files.
+func HasUncommittedChanges(ctx context.Context, dir string) (bool, error) {
+	out, err := runGitOutput(ctx, dir, "status", "--porcelain")
+	if err != nil {
+		return false, fmt.Errorf("git status: %w", err)
+	}
+	return strings.TrimSpace(out) != "", nil
+}
+
+// CommitExists reports whether rev names a commit in the repository.
+func CommitExists(ctx context.Context, dir, rev string) bool {
+	return runGit(ctx, dir, "cat-file", "-e", rev+"^{commit}") == nil
+}
+
+// CommitTime returns the committer date of the commit rev names, such as a
+// release tag.
+func CommitTime(ctx context.Context, dir, rev string) (time.Time, error) {
+	out, err := runGitOutput(ctx, dir, "log", "-1", "--format=%cI", rev+"^{commit}", "--")
+	if err != nil {
+		return time.Time{}, fmt.Errorf("read commit date of %s: %w", rev, err)
+	}
+	t, err := time.Parse(time.RFC3339, strings.TrimSpace(out))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("parse commit date of %s: %w", rev, err)
+	}
+	return t, nil
+}
+
+// FormatPatches writes one mbox patch per commit in base..HEAD into outDir
+// and returns the file paths in apply order.
+func FormatPatches(ctx context.Context, dir, base, outDir string) ([]string, error) {
+	out, err := runGitOutput(ctx, dir, "format-patch", "--no-stat", "--output-directory", outDir, base+"..HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("format-patch %s..HEAD: %w", shortSHA(base), err)
+	}
+	var files []string
+	for line := range strings.SplitSeq(strings.TrimSpace(out), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			if !filepath.IsAbs(line) {
+				line = filepath.Join(dir, line)
+			}
+			files = append(files, line)
+		}
+	}
+	return files, nil
+}
+
+// ApplyPatches applies mbox patches onto the current branch with
+// `git am --3way`, keeping the original authors and messages. On failure the
+// am session is aborted so the repository is left as it was.
+func ApplyPatches(ctx context.Context, dir string, patches []string) error {
+	args := append([]string{"am", "--3way", "--keep-cr"}, patches...)
+	stdout, stderr, err := runGitOutputAndErrWithNoEditor(ctx, dir, args...)
+	if err == nil {
+		return nil
+	}
+	if IsAmInProgress(dir) {
+		_ = runGit(ctx, dir, "am", "--abort")
+	}
+	return fmt.Errorf("git am: %w: %s %s", err, strings.TrimSpace(stdout), strings.TrimSpace(stderr))
+}
+
+// IsAmInProgress reports whether a `git am` session is currently running.
+func IsAmInProgress(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, ".git", "rebase-apply"))
+	return err == nil
+}
+
+// CreateBranch creates branchName at startPoint and checks it out. It fails
+// if the branch already exists.
+func CreateBranch(ctx context.Context, dir, branchName, startPoint string) error {
+	if err := runGit(ctx, dir, "checkout", "-b", branchName, startPoint); err != nil {
+		return fmt.Errorf("create branch %s at %s: %w", branchName, shortSHA(startPoint), err)
+	}
+	return nil
+}
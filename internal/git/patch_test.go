@@ -0,0 +1,82 @@
+package git
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestFormatAndApplyPatchesAbortsOnConflict(t *testing.T) {
+	ctx := context.Background()
+	tmp := t.TempDir()
+
+	remote := createRemoteWithMainBranch(t, tmp)
+	seed := filepath.Join(tmp, "seed")
+	base := strings.TrimSpace(runGitCmdOutput(t, seed, "rev-parse", "HEAD"))
+	if err := os.WriteFile(filepath.Join(seed, "README.md"), []byte("patched\n"), 0o644); err != nil {
+		t.Fatalf("write readme: %v", err)
+	}
+	runGitCmd(t, seed, "commit", "-am", "patch readme")
+
+	patches, err := FormatPatches(ctx, seed, base, t.TempDir())
+	if err != nil || len(patches) != 1 {
+		t.Fatalf("format patches: %v %v", patches, err)
+	}
+
+	target := filepath.Join(tmp, "target")
+	runGitCmd(t, "", "clone", "-b", "main", remote, target)
+	runGitCmd(t, target, "config", "user.email", "test@example.com")
+	runGitCmd(t, target, "config", "user.name", "Test User")
+	if err := os.WriteFile(filepath.Join(target, "README.md"), []byte("diverged\n"), 0o644); err != nil {
+		t.Fatalf("write readme: %v", err)
+	}
+	runGitCmd(t, target, "commit", "-am", "diverge")
+	head := strings.TrimSpace(runGitCmdOutput(t, target, "rev-parse", "HEAD"))
+
+	if err := ApplyPatches(ctx, target, patches); err == nil {
+		t.Fatal("expected conflict error")
+	}
+	if IsAmInProgress(target) {
+		t.Fatal("expected am session to be aborted")
+	}
+	if got := strings.TrimSpace(runGitCmdOutput(t, target, "rev-parse", "HEAD")); got != head {
+		t.Fatalf("expected HEAD unchanged, got %s", got)
+	}
+	if dirty, err := HasUncommittedChanges(ctx, target); err != nil || dirty {
+		t.Fatalf("expected clean tree after abort: dirty=%v err=%v", dirty, err)
+	}
+
+	if err := CreateBranch(ctx, target, "review", base); err != nil {
+		t.Fatalf("create branch: %v", err)
+	}
+	if err := ApplyPatches(ctx, target, patches); err != nil {
+		t.Fatalf("apply onto base: %v", err)
+	}
+	got, _ := os.ReadFile(filepath.Join(target, "README.md"))
+	if string(got) != "patched\n" {
+		t.Fatalf("unexpected README after apply: %q", got)
+	}
+}
+
+func TestCommitTimeResolvesTags(t *testing.T) {
+	ctx := context.Background()
+	tmp := t.TempDir()
+	createRemoteWithMainBranch(t, tmp)
+	seed := filepath.Join(tmp, "seed")
+
+	runGitCmd(t, seed, "tag", "-a", "v1.0.0", "-m", "release")
+	want := strings.TrimSpace(runGitCmdOutput(t, seed, "log", "-1", "--format=%ct"))
+	got, err := CommitTime(ctx, seed, "v1.0.0")
+	if err != nil {
+		t.Fatalf("commit time: %v", err)
+	}
+	if ts := strconv.FormatInt(got.Unix(), 10); ts != want {
+		t.Fatalf("expected %s, got %s", want, ts)
+	}
+	if _, err := CommitTime(ctx, seed, "v9.9.9"); err == nil {
+		t.Fatal("expected an error for an unknown ref")
+	}
+}
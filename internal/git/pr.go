@@ -2,6 +2,7 @@ package git
 
 import (
 	"bytes"
+	"cmp"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -14,8 +15,6 @@ import (
 	"autopr/internal/httputil"
 )
 
-var githubAPIBase = "https://api.github.com"
-
 func normalizeGitHubHead(owner, head string) string {
 	head = strings.TrimSpace(head)
 	if strings.Contains(head, ":") {
@@ -29,8 +28,9 @@ func normalizeGitHubHead(owner, head string) string {
 
 // CreateGitHubPR creates a pull request on GitHub and returns its HTML URL.
 // head may be a branch name ("feature/abc") or an owner-qualified ref
-// ("alice:feature/abc").
-func CreateGitHubPR(ctx context.Context, token, owner, repo, head, base, title, body string, draft bool) (string, error) {
+// ("alice:feature/abc"). baseURL selects a GitHub Enterprise Server instance;
+// empty means github.com.
+func CreateGitHubPR(ctx context.Context, token, baseURL, owner, repo, head, base, title, body string, draft bool) (string, error) {
 	head = normalizeGitHubHead(owner, head)
 	payload := map[string]any{
 		"title": title,
@@ -44,18 +44,9 @@ func CreateGitHubPR(ctx context.Context, token, owner, repo, head, base, title,
 		return "", fmt.Errorf("marshal PR payload: %w", err)
 	}
 
-	apiURL := fmt.Sprintf("%s/repos/%s/%s/pulls", githubAPIBase, owner, repo)
+	apiURL := fmt.Sprintf("%s/repos/%s/%s/pulls", NormalizeGitHubAPIBaseURL(baseURL), owner, repo)
 
-	resp, err := httputil.Do(ctx, func() (*http.Request, error) {
-		req, err := http.NewRequestWithContext(ctx, "POST", apiURL, bytes.NewReader(buf))
-		if err != nil {
-			return nil, err
-		}
-		req.Header.Set("Authorization", "Bearer "+token)
-		req.Header.Set("Accept", "application/vnd.github+json")
-		req.Header.Set("Content-Type", "application/json")
-		return req, nil
-	}, httputil.DefaultRetryConfig())
+	resp, err := DoGitHubRequest(ctx, token, "POST", apiURL, buf)
 	if err != nil {
 		return "", fmt.Errorf("github create PR: %w", err)
 	}
@@ -65,7 +56,7 @@ func CreateGitHubPR(ctx context.Context, token, owner, repo, head, base, title,
 
 	if resp.StatusCode == http.StatusUnprocessableEntity {
 		// PR may already exist for this branch — try to find it.
-		if existingURL, err := findGitHubPR(ctx, token, owner, repo, head); err == nil && existingURL != "" {
+		if existingURL, err := findGitHubPR(ctx, token, baseURL, owner, repo, head); err == nil && existingURL != "" {
 			return existingURL, nil
 		}
 		msg := string(respBody)
@@ -93,31 +84,23 @@ func CreateGitHubPR(ctx context.Context, token, owner, repo, head, base, title,
 }
 
 // findGitHubPR looks up an existing open PR for the given head branch.
-func findGitHubPR(ctx context.Context, token, owner, repo, head string) (string, error) {
+func findGitHubPR(ctx context.Context, token, baseURL, owner, repo, head string) (string, error) {
 	head = normalizeGitHubHead(owner, head)
-	return FindGitHubPRByBranch(ctx, token, owner, repo, head, "open")
+	return FindGitHubPRByBranch(ctx, token, baseURL, owner, repo, head, "open")
 }
 
 // FindGitHubPRByBranch looks up an existing PR for the given head branch.
 // state should be "open" or "all"; defaults to "open".
-func FindGitHubPRByBranch(ctx context.Context, token, owner, repo, head, state string) (string, error) {
+func FindGitHubPRByBranch(ctx context.Context, token, baseURL, owner, repo, head, state string) (string, error) {
 	if state == "" {
 		state = "open"
 	}
 	headRef := normalizeGitHubHead(owner, head)
 	apiURL := fmt.Sprintf("%s/repos/%s/%s/pulls?head=%s&state=%s",
-		githubAPIBase,
+		NormalizeGitHubAPIBaseURL(baseURL),
 		owner, repo, url.QueryEscape(headRef), url.QueryEscape(state))
 
-	resp, err := httputil.Do(ctx, func() (*http.Request, error) {
-		req, err := http.NewRequestWithContext(ctx, "GET", apiURL, nil)
-		if err != nil {
-			return nil, err
-		}
-		req.Header.Set("Authorization", "Bearer "+token)
-		req.Header.Set("Accept", "application/vnd.github+json")
-		return req, nil
-	}, httputil.DefaultRetryConfig())
+	resp, err := DoGitHubRequest(ctx, token, "GET", apiURL, nil)
 	if err != nil {
 		return "", err
 	}
@@ -251,43 +234,25 @@ func FindGitLabMRByBranch(ctx context.Context, token, baseURL, projectID, source
 }
 
 // MergeGitHubPR merges a GitHub pull request via the merge API.
-func MergeGitHubPR(ctx context.Context, token, prURL, method string) error {
+func MergeGitHubPR(ctx context.Context, token, baseURL, prURL, method string) error {
 	method, err := normalizeMergeMethod(method)
 	if err != nil {
 		return err
 	}
 
-	matches := githubPRNumberRe.FindStringSubmatch(prURL)
-	if len(matches) < 2 {
-		return fmt.Errorf("cannot parse PR number from URL: %s", prURL)
-	}
-	prNumber := matches[1]
-
-	// Extract owner/repo from URL.
-	// URL format: https://github.com/{owner}/{repo}/pull/{number}
-	parts := strings.Split(strings.TrimPrefix(prURL, "https://github.com/"), "/")
-	if len(parts) < 2 {
-		return fmt.Errorf("cannot parse owner/repo from URL: %s", prURL)
+	owner, repo, prNumber, err := parseGitHubPRURL(prURL)
+	if err != nil {
+		return err
 	}
-	owner, repo := parts[0], parts[1]
 
-	apiURL := fmt.Sprintf("%s/repos/%s/%s/pulls/%s/merge", githubAPIBase, owner, repo, prNumber)
+	apiURL := fmt.Sprintf("%s/repos/%s/%s/pulls/%s/merge", NormalizeGitHubAPIBaseURL(baseURL), owner, repo, prNumber)
 	payload := map[string]any{"merge_method": method}
 	payloadBody, err := json.Marshal(payload)
 	if err != nil {
 		return fmt.Errorf("marshal github merge payload: %w", err)
 	}
 
-	resp, err := httputil.Do(ctx, func() (*http.Request, error) {
-		req, err := http.NewRequestWithContext(ctx, http.MethodPut, apiURL, strings.NewReader(string(payloadBody)))
-		if err != nil {
-			return nil, err
-		}
-		req.Header.Set("Authorization", "Bearer "+token)
-		req.Header.Set("Accept", "application/vnd.github+json")
-		req.Header.Set("Content-Type", "application/json")
-		return req, nil
-	}, httputil.DefaultRetryConfig())
+	resp, err := DoGitHubRequest(ctx, token, http.MethodPut, apiURL, payloadBody)
 	if err != nil {
 		return fmt.Errorf("github merge PR: %w", err)
 	}
@@ -388,42 +353,28 @@ func normalizeMergeMethod(method string) (string, error) {
 
 // PRMergeStatus holds the result of a PR/MR status check.
 type PRMergeStatus struct {
-	Merged   bool
-	MergedAt string // ISO 8601 timestamp, empty if not merged
-	Closed   bool
-	ClosedAt string // ISO 8601 timestamp, empty if not closed
+	Merged         bool
+	MergedAt       string // ISO 8601 timestamp, empty if not merged
+	CreatedAt      string // ISO 8601 timestamp the PR/MR was opened
+	Closed         bool
+	ClosedAt       string // ISO 8601 timestamp, empty if not closed
+	MergeCommitSHA string // commit that landed the change on the base branch
+	Labels         []string
 }
 
 var githubPRNumberRe = regexp.MustCompile(`/pull/(\d+)`)
 
 // CheckGitHubPRStatus checks whether a GitHub PR has been merged or closed.
 // prURL should be like "https://github.com/owner/repo/pull/123".
-func CheckGitHubPRStatus(ctx context.Context, token, prURL string) (PRMergeStatus, error) {
-	matches := githubPRNumberRe.FindStringSubmatch(prURL)
-	if len(matches) < 2 {
-		return PRMergeStatus{}, fmt.Errorf("cannot parse PR number from URL: %s", prURL)
-	}
-	prNumber := matches[1]
-
-	// Extract owner/repo from URL.
-	// URL format: https://github.com/{owner}/{repo}/pull/{number}
-	parts := strings.Split(strings.TrimPrefix(prURL, "https://github.com/"), "/")
-	if len(parts) < 3 {
-		return PRMergeStatus{}, fmt.Errorf("cannot parse owner/repo from URL: %s", prURL)
+func CheckGitHubPRStatus(ctx context.Context, token, baseURL, prURL string) (PRMergeStatus, error) {
+	owner, repo, prNumber, err := parseGitHubPRURL(prURL)
+	if err != nil {
+		return PRMergeStatus{}, err
 	}
-	owner, repo := parts[0], parts[1]
 
-	apiURL := fmt.Sprintf("%s/repos/%s/%s/pulls/%s", githubAPIBase, owner, repo, prNumber)
+	apiURL := fmt.Sprintf("%s/repos/%s/%s/pulls/%s", NormalizeGitHubAPIBaseURL(baseURL), owner, repo, prNumber)
 
-	resp, err := httputil.Do(ctx, func() (*http.Request, error) {
-		req, err := http.NewRequestWithContext(ctx, "GET", apiURL, nil)
-		if err != nil {
-			return nil, err
-		}
-		req.Header.Set("Authorization", "Bearer "+token)
-		req.Header.Set("Accept", "application/vnd.github+json")
-		return req, nil
-	}, httputil.DefaultRetryConfig())
+	resp, err := DoGitHubRequest(ctx, token, "GET", apiURL, nil)
 	if err != nil {
 		return PRMergeStatus{}, fmt.Errorf("check PR status: %w", err)
 	}
@@ -435,15 +386,26 @@ func CheckGitHubPRStatus(ctx context.Context, token, prURL string) (PRMergeStatu
 	}
 
 	var pr struct {
-		State    string `json:"state"`
-		Merged   bool   `json:"merged"`
-		MergedAt string `json:"merged_at"`
-		ClosedAt string `json:"closed_at"`
+		State          string `json:"state"`
+		Merged         bool   `json:"merged"`
+		CreatedAt      string `json:"created_at"`
+		MergedAt       string `json:"merged_at"`
+		ClosedAt       string `json:"closed_at"`
+		MergeCommitSHA string `json:"merge_commit_sha"`
+		Labels         []struct {
+			Name string `json:"name"`
+		} `json:"labels"`
 	}
 	if err := json.Unmarshal(body, &pr); err != nil {
 		return PRMergeStatus{}, fmt.Errorf("decode PR status: %w", err)
 	}
-	status := PRMergeStatus{Merged: pr.Merged, MergedAt: pr.MergedAt}
+	status := PRMergeStatus{Merged: pr.Merged, MergedAt: pr.MergedAt, CreatedAt: pr.CreatedAt}
+	if pr.Merged {
+		status.MergeCommitSHA = pr.MergeCommitSHA
+	}
+	for _, l := range pr.Labels {
+		status.Labels = append(status.Labels, l.Name)
+	}
 	// GitHub: state "closed" + merged false = closed without merge.
 	if pr.State == "closed" && !pr.Merged {
 		status.Closed = true
@@ -495,14 +457,24 @@ func CheckGitLabMRStatus(ctx context.Context, token, baseURL, mrURL string) (PRM
 	}
 
 	var mr struct {
-		State    string `json:"state"`
-		MergedAt string `json:"merged_at"`
-		ClosedAt string `json:"closed_at"`
+		State           string   `json:"state"`
+		CreatedAt       string   `json:"created_at"`
+		MergedAt        string   `json:"merged_at"`
+		ClosedAt        string   `json:"closed_at"`
+		MergeCommitSHA  string   `json:"merge_commit_sha"`
+		SquashCommitSHA string   `json:"squash_commit_sha"`
+		SHA             string   `json:"sha"`
+		Labels          []string `json:"labels"`
 	}
 	if err := json.Unmarshal(body, &mr); err != nil {
 		return PRMergeStatus{}, fmt.Errorf("decode MR status: %w", err)
 	}
-	status := PRMergeStatus{Merged: mr.State == "merged", MergedAt: mr.MergedAt}
+	status := PRMergeStatus{Merged: mr.State == "merged", MergedAt: mr.MergedAt, CreatedAt: mr.CreatedAt, Labels: mr.Labels}
+	if status.Merged {
+		// Fast-forward merges have no merge commit; fall back to the squash
+		// commit or the MR head.
+		status.MergeCommitSHA = cmp.Or(mr.MergeCommitSHA, mr.SquashCommitSHA, mr.SHA)
+	}
 	// GitLab: "closed" is distinct from "merged".
 	if mr.State == "closed" {
 		status.Closed = true
@@ -524,25 +496,17 @@ type CheckRunStatus struct {
 
 // GetGitHubCheckRunStatus fetches the check-run status for a commit ref,
 // paginating through all pages to handle repos with >100 check-runs.
-func GetGitHubCheckRunStatus(ctx context.Context, token, owner, repo, ref string) (CheckRunStatus, error) {
-	baseURL := fmt.Sprintf("%s/repos/%s/%s/commits/%s/check-runs", githubAPIBase, owner, repo, url.PathEscape(ref))
+func GetGitHubCheckRunStatus(ctx context.Context, token, baseURL, owner, repo, ref string) (CheckRunStatus, error) {
+	checksURL := fmt.Sprintf("%s/repos/%s/%s/commits/%s/check-runs", NormalizeGitHubAPIBaseURL(baseURL), owner, repo, url.PathEscape(ref))
 
 	var status CheckRunStatus
 	page := 1
 	const perPage = 100
 
 	for {
-		apiURL := fmt.Sprintf("%s?per_page=%d&page=%d", baseURL, perPage, page)
+		apiURL := fmt.Sprintf("%s?per_page=%d&page=%d", checksURL, perPage, page)
 
-		resp, err := httputil.Do(ctx, func() (*http.Request, error) {
-			req, err := http.NewRequestWithContext(ctx, "GET", apiURL, nil)
-			if err != nil {
-				return nil, err
-			}
-			req.Header.Set("Authorization", "Bearer "+token)
-			req.Header.Set("Accept", "application/vnd.github+json")
-			return req, nil
-		}, httputil.DefaultRetryConfig())
+		resp, err := DoGitHubRequest(ctx, token, "GET", apiURL, nil)
 		if err != nil {
 			return CheckRunStatus{}, fmt.Errorf("github check-runs: %w", err)
 		}
@@ -577,6 +541,11 @@ func GetGitHubCheckRunStatus(ctx context.Context, token, owner, repo, ref string
 		}
 
 		for _, cr := range result.CheckRuns {
+			// AutoPR's own report is not CI; a job must not wait on itself.
+			if cr.Name == AutoPRCheckName {
+				status.Total--
+				continue
+			}
 			if cr.Status != "completed" {
 				status.Pending++
 				continue
@@ -0,0 +1,136 @@
+package git
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// ListGitHubPRComments returns the conversation comments on a GitHub pull
+// request, oldest first. Review comments on lines of the diff are not listed.
+func ListGitHubPRComments(ctx context.Context, token, baseURL, prURL string) ([]IssueComment, error) {
+	owner, repo, number, err := parseGitHubPRURL(prURL)
+	if err != nil {
+		return nil, err
+	}
+	return ListGitHubIssueComments(ctx, token, baseURL, owner, repo, number)
+}
+
+// ListGiteaPRComments returns the conversation comments on a Gitea/Forgejo
+// pull request, oldest first.
+func ListGiteaPRComments(ctx context.Context, token, baseURL, prURL string) ([]IssueComment, error) {
+	owner, repo, index, err := parseGiteaPRURL(prURL)
+	if err != nil {
+		return nil, err
+	}
+	return ListGiteaIssueComments(ctx, token, baseURL, owner, repo, index)
+}
+
+// CloseGitHubPR closes a GitHub pull request without merging it.
+func CloseGitHubPR(ctx context.Context, token, baseURL, prURL string) error {
+	owner, repo, number, err := parseGitHubPRURL(prURL)
+	if err != nil {
+		return err
+	}
+	buf, err := json.Marshal(map[string]string{"state": "closed"})
+	if err != nil {
+		return fmt.Errorf("marshal close PR payload: %w", err)
+	}
+	apiURL := fmt.Sprintf("%s/repos/%s/%s/pulls/%s", NormalizeGitHubAPIBaseURL(baseURL), owner, repo, number)
+	resp, err := DoGitHubRequest(ctx, token, http.MethodPatch, apiURL, buf)
+	if err != nil {
+		return fmt.Errorf("github close PR: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("github close PR: HTTP %d: %s", resp.StatusCode, truncateBody(body, 4096))
+	}
+	return nil
+}
+
+// CloseGiteaPR closes a Gitea/Forgejo pull request without merging it.
+func CloseGiteaPR(ctx context.Context, token, baseURL, prURL string) error {
+	owner, repo, index, err := parseGiteaPRURL(prURL)
+	if err != nil {
+		return err
+	}
+	buf, err := json.Marshal(map[string]string{"state": "closed"})
+	if err != nil {
+		return fmt.Errorf("marshal close PR payload: %w", err)
+	}
+	apiURL := GiteaAPIURL(baseURL, fmt.Sprintf("/repos/%s/%s/pulls/%s", owner, repo, index))
+	resp, err := DoGiteaRequest(ctx, token, http.MethodPatch, apiURL, buf)
+	if err != nil {
+		return fmt.Errorf("gitea close PR: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("gitea close PR: HTTP %d: %s", resp.StatusCode, truncateBody(body, 4096))
+	}
+	return nil
+}
+
+// GitHubRepoPermission returns user's permission on the repository of prURL:
+// admin, write, read, or none. Maintainers report write.
+func GitHubRepoPermission(ctx context.Context, token, baseURL, prURL, user string) (string, error) {
+	owner, repo, _, err := parseGitHubPRURL(prURL)
+	if err != nil {
+		return "", err
+	}
+	apiURL := fmt.Sprintf("%s/repos/%s/%s/collaborators/%s/permission", NormalizeGitHubAPIBaseURL(baseURL), owner, repo, url.PathEscape(user))
+	resp, err := DoGitHubRequest(ctx, token, http.MethodGet, apiURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("github repo permission: %w", err)
+	}
+	return decodeRepoPermission(resp, "github")
+}
+
+// GiteaRepoPermission returns user's permission on the repository of prURL:
+// owner, admin, write, read, or none.
+func GiteaRepoPermission(ctx context.Context, token, baseURL, prURL, user string) (string, error) {
+	owner, repo, _, err := parseGiteaPRURL(prURL)
+	if err != nil {
+		return "", err
+	}
+	apiURL := GiteaAPIURL(baseURL, fmt.Sprintf("/repos/%s/%s/collaborators/%s/permission", owner, repo, url.PathEscape(user)))
+	resp, err := DoGiteaRequest(ctx, token, http.MethodGet, apiURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("gitea repo permission: %w", err)
+	}
+	return decodeRepoPermission(resp, "gitea")
+}
+
+func decodeRepoPermission(resp *http.Response, forge string) (string, error) {
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		// Not a collaborator.
+		return "none", nil
+	default:
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return "", fmt.Errorf("%s repo permission: HTTP %d: %s", forge, resp.StatusCode, string(body))
+	}
+	var result struct {
+		Permission string `json:"permission"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("decode %s repo permission: %w", forge, err)
+	}
+	return result.Permission, nil
+}
+
+// CanPush reports whether a repository permission returned by
+// GitHubRepoPermission or GiteaRepoPermission allows pushing.
+func CanPush(permission string) bool {
+	switch permission {
+	case "owner", "admin", "maintain", "write":
+		return true
+	}
+	return false
+}
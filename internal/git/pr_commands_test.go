@@ -0,0 +1,62 @@
+package git
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGitHubRepoPermission(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/org/repo/collaborators/alice/permission":
+			_, _ = w.Write([]byte(`{"permission": "write"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	withGitHubAPIBase(t, srv.URL, func() {
+		ctx := context.Background()
+		prURL := "https://github.com/org/repo/pull/7"
+		got, err := GitHubRepoPermission(ctx, "tok", "", prURL, "alice")
+		if err != nil || got != "write" {
+			t.Fatalf("alice: %q, %v; want write", got, err)
+		}
+		got, err = GitHubRepoPermission(ctx, "tok", "", prURL, "mallory")
+		if err != nil || got != "none" {
+			t.Fatalf("mallory: %q, %v; want none", got, err)
+		}
+	})
+}
+
+func TestCloseGitHubPR(t *testing.T) {
+	var gotMethod, gotPath string
+	var gotBody map[string]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotMethod, gotPath = r.Method, r.URL.Path
+		_ = json.NewDecoder(r.Body).Decode(&gotBody)
+		_, _ = w.Write([]byte(`{"state": "closed"}`))
+	}))
+	defer srv.Close()
+
+	withGitHubAPIBase(t, srv.URL, func() {
+		if err := CloseGitHubPR(context.Background(), "tok", "", "https://github.com/org/repo/pull/7"); err != nil {
+			t.Fatalf("close PR: %v", err)
+		}
+	})
+	if gotMethod != http.MethodPatch || gotPath != "/repos/org/repo/pulls/7" || gotBody["state"] != "closed" {
+		t.Fatalf("got %s %s %v", gotMethod, gotPath, gotBody)
+	}
+}
+
+func TestCanPush(t *testing.T) {
+	for perm, want := range map[string]bool{"owner": true, "admin": true, "maintain": true, "write": true, "triage": false, "read": false, "none": false, "": false} {
+		if got := CanPush(perm); got != want {
+			t.Errorf("CanPush(%q) = %v, want %v", perm, got, want)
+		}
+	}
+}
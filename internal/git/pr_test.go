@@ -72,7 +72,7 @@ func TestCreateGitHubPR_UsesForkQualifiedHeadWhenProvided(t *testing.T) {
 	defer srv.Close()
 
 	withGitHubAPIBase(t, srv.URL, func() {
-		got, err := CreateGitHubPR(context.Background(), "tok", "acme", "repo", "feature/forked", "main", "title", "body", false)
+		got, err := CreateGitHubPR(context.Background(), "tok", "", "acme", "repo", "feature/forked", "main", "title", "body", false)
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -103,7 +103,7 @@ func TestFindGitHubPRByBranch_PassesBranchAsHeadWhenAlreadyQualified(t *testing.
 	defer srv.Close()
 
 	withGitHubAPIBase(t, srv.URL, func() {
-		got, err := FindGitHubPRByBranch(context.Background(), "tok", "acme", "repo", "alice:feature/forked", "all")
+		got, err := FindGitHubPRByBranch(context.Background(), "tok", "", "acme", "repo", "alice:feature/forked", "all")
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -130,7 +130,7 @@ func TestFindGitHubPRByBranch_PrefixesOwnerForPlainHead(t *testing.T) {
 	defer srv.Close()
 
 	withGitHubAPIBase(t, srv.URL, func() {
-		if _, err := FindGitHubPRByBranch(context.Background(), "tok", "acme", "repo", "feature/forked", "open"); err != nil {
+		if _, err := FindGitHubPRByBranch(context.Background(), "tok", "", "acme", "repo", "feature/forked", "open"); err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
 		if gotHead != "acme:feature/forked" {
@@ -473,15 +473,130 @@ func TestMergeGitLabMR_NotMergeableStatuses(t *testing.T) {
 }
 
 func TestMergeGitHubPR_InvalidMethod(t *testing.T) {
-	err := MergeGitHubPR(context.Background(), "tok", "https://github.com/acmecorp/placeholder/pull/123", "bad")
+	err := MergeGitHubPR(context.Background(), "tok", "", "https://github.com/acmecorp/placeholder/pull/123", "bad")
 	if err == nil || !strings.Contains(err.Error(), "invalid merge method") {
 		t.Fatalf("want invalid method error, got: %v", err)
 	}
 }
 
 func TestMergeGitHubPR_BadPRURL(t *testing.T) {
-	err := MergeGitHubPR(context.Background(), "tok", "https://example.invalid/no-pull", "merge")
+	err := MergeGitHubPR(context.Background(), "tok", "", "https://example.invalid/no-pull", "merge")
 	if err == nil || !strings.Contains(err.Error(), "cannot parse PR number") {
 		t.Fatalf("want parse error, got: %v", err)
 	}
 }
+
+func TestRemoveGitHubIssueLabel_IgnoresMissingLabel(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "DELETE" || r.URL.Path != "/repos/org/repo/issues/42/labels/autopr-in-progress" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"message":"Label does not exist"}`)
+	}))
+	defer srv.Close()
+
+	withGitHubAPIBase(t, srv.URL, func() {
+		if err := RemoveGitHubIssueLabel(context.Background(), "tok", "", "org", "repo", "42", "autopr-in-progress"); err != nil {
+			t.Fatalf("expected missing label to be ignored, got %v", err)
+		}
+	})
+}
+
+func TestAddGitHubIssueAssignees_SendsAssignees(t *testing.T) {
+	var gotBody map[string][]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/repos/org/repo/issues/42/assignees" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&gotBody); err != nil {
+			t.Errorf("decode body: %v", err)
+		}
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{}`)
+	}))
+	defer srv.Close()
+
+	withGitHubAPIBase(t, srv.URL, func() {
+		if err := AddGitHubIssueAssignees(context.Background(), "tok", "", "org", "repo", "42", []string{"autopr-bot"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+	if got := gotBody["assignees"]; len(got) != 1 || got[0] != "autopr-bot" {
+		t.Fatalf("unexpected assignees payload: %v", gotBody)
+	}
+}
+
+func TestUpdateGitLabIssue_SendsLabelChanges(t *testing.T) {
+	t.Parallel()
+
+	var gotBody map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "PUT" || r.URL.Path != "/api/v4/projects/123/issues/7" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&gotBody); err != nil {
+			t.Errorf("decode body: %v", err)
+		}
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, `{}`)
+	}))
+	defer srv.Close()
+
+	err := UpdateGitLabIssue(context.Background(), "tok", srv.URL, "123", "7", GitLabIssueUpdate{
+		AddLabels:    []string{"autopr-failed"},
+		RemoveLabels: []string{"autopr-in-progress"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotBody["add_labels"] != "autopr-failed" || gotBody["remove_labels"] != "autopr-in-progress" {
+		t.Fatalf("unexpected payload: %v", gotBody)
+	}
+	if _, ok := gotBody["assignee_ids"]; ok {
+		t.Fatalf("expected assignees untouched, got %v", gotBody)
+	}
+}
+
+func TestCheckGitHubPRStatus_ReturnsLabelsAndMergeCommit(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/org/repo/pulls/7" {
+			t.Fatalf("unexpected path %q", r.URL.Path)
+		}
+		fmt.Fprint(w, `{"state":"closed","merged":true,"merged_at":"2026-01-02T03:04:05Z","merge_commit_sha":"abc123","labels":[{"name":"bug"},{"name":"backport/release-1.2"}]}`)
+	}))
+	defer srv.Close()
+
+	withGitHubAPIBase(t, srv.URL, func() {
+		status, err := CheckGitHubPRStatus(context.Background(), "tok", "", "https://github.com/org/repo/pull/7")
+		if err != nil {
+			t.Fatalf("CheckGitHubPRStatus: %v", err)
+		}
+		if !status.Merged || status.MergeCommitSHA != "abc123" {
+			t.Fatalf("unexpected merge status: %+v", status)
+		}
+		if len(status.Labels) != 2 || status.Labels[1] != "backport/release-1.2" {
+			t.Fatalf("unexpected labels: %v", status.Labels)
+		}
+	})
+}
+
+func TestCheckGitLabMRStatus_FallsBackToSquashCommit(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"state":"merged","merged_at":"2026-01-02T03:04:05Z","merge_commit_sha":null,"squash_commit_sha":"def456","sha":"head789","labels":["backport/release-1.2"]}`)
+	}))
+	defer srv.Close()
+
+	status, err := CheckGitLabMRStatus(context.Background(), "tok", srv.URL, srv.URL+"/group/proj/-/merge_requests/3")
+	if err != nil {
+		t.Fatalf("CheckGitLabMRStatus: %v", err)
+	}
+	if !status.Merged || status.MergeCommitSHA != "def456" {
+		t.Fatalf("unexpected merge status: %+v", status)
+	}
+	if len(status.Labels) != 1 || status.Labels[0] != "backport/release-1.2" {
+		t.Fatalf("unexpected labels: %v", status.Labels)
+	}
+}
@@ -50,6 +50,25 @@ func RebaseOntoBase(ctx context.Context, dir, baseBranch string) (bool, error) {
 	return false, fmt.Errorf("git rebase origin/%s: %w: %s %s", baseBranch, err, strings.TrimSpace(stdout), strings.TrimSpace(stderr))
 }
 
+// RebaseOntoOtherBase moves the commits the current branch has on top of
+// origin/<fromBranch> onto origin/<toBranch>, leaving fromBranch's own commits
+// behind. Both branches must have been fetched. Returns true when conflicts
+// are detected.
+func RebaseOntoOtherBase(ctx context.Context, dir, fromBranch, toBranch string) (bool, error) {
+	forkPoint, err := MergeBase(ctx, dir, "HEAD", "origin/"+fromBranch)
+	if err != nil {
+		return false, err
+	}
+	stdout, stderr, err := runGitOutputAndErr(ctx, dir, "rebase", "--onto", "origin/"+toBranch, forkPoint)
+	if err == nil {
+		return false, nil
+	}
+	if isGitRebaseError(stderr) || isGitRebaseError(stdout) {
+		return true, nil
+	}
+	return false, fmt.Errorf("git rebase --onto origin/%s: %w: %s %s", toBranch, err, strings.TrimSpace(stdout), strings.TrimSpace(stderr))
+}
+
 // RebaseContinue resumes a rebase after conflict resolution.
 // Returns true when more conflicts remain.
 func RebaseContinue(ctx context.Context, dir string) (bool, error) {
@@ -0,0 +1,62 @@
+package git
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// RemoteAuth holds per-project git transport settings that replace or
+// complement token auth. They are written into a job clone's config so every
+// later fetch and push from that clone uses them too.
+type RemoteAuth struct {
+	// CredentialHelper replaces any globally configured credential helpers,
+	// e.g. "osxkeychain" or "store --file /path/to/credentials".
+	CredentialHelper string
+	// SSHCommand is used for SSH remotes, e.g. "ssh -i ~/.ssh/deploy_key".
+	// Empty means plain ssh, which uses the user's ssh-agent and ~/.ssh/config.
+	SSHCommand string
+}
+
+// cloneConfigArgs returns "git clone" --config flags for the settings.
+func (a RemoteAuth) cloneConfigArgs() []string {
+	var args []string
+	if helper := strings.TrimSpace(a.CredentialHelper); helper != "" {
+		// An empty value resets helpers inherited from global config.
+		args = append(args, "--config", "credential.helper=", "--config", "credential.helper="+helper)
+	}
+	if sshCmd := strings.TrimSpace(a.SSHCommand); sshCmd != "" {
+		args = append(args, "--config", "core.sshCommand="+sshCmd)
+	}
+	return args
+}
+
+var scpLikeRemoteRe = regexp.MustCompile(`^(?:[^@/:]+@)?([^/:]+):(.+)$`)
+
+// IsSSHRemoteURL reports whether remoteURL uses SSH transport, either as
+// ssh://host/path or the scp-like user@host:path form.
+func IsSSHRemoteURL(remoteURL string) bool {
+	remoteURL = strings.TrimSpace(remoteURL)
+	if strings.HasPrefix(remoteURL, "ssh://") || strings.HasPrefix(remoteURL, "git+ssh://") {
+		return true
+	}
+	if strings.Contains(remoteURL, "://") {
+		return false
+	}
+	return scpLikeRemoteRe.MatchString(remoteURL)
+}
+
+// SSHRemoteURL converts an HTTP(S) remote such as
+// "https://github.com/owner/repo.git" to "git@github.com:owner/repo.git".
+// Other URLs are returned unchanged.
+func SSHRemoteURL(remoteURL string) string {
+	remoteURL = strings.TrimSpace(remoteURL)
+	if !isHTTPRemoteURL(remoteURL) {
+		return remoteURL
+	}
+	u, err := url.Parse(remoteURL)
+	if err != nil || u.Hostname() == "" {
+		return remoteURL
+	}
+	return "git@" + u.Hostname() + ":" + strings.TrimPrefix(u.Path, "/")
+}
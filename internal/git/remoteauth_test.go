@@ -0,0 +1,58 @@
+package git
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCloneForJobWithAuthPersistsTransportConfig(t *testing.T) {
+	ctx := context.Background()
+	tmp := t.TempDir()
+	remote := createRemoteWithMainBranch(t, tmp)
+	worktree := filepath.Join(tmp, "worktree")
+
+	auth := RemoteAuth{CredentialHelper: "store --file /tmp/creds", SSHCommand: "ssh -i /tmp/deploy_key"}
+	if err := CloneForJobWithAuth(ctx, remote, "", worktree, "autopr/job-1", "main", auth); err != nil {
+		t.Fatalf("clone: %v", err)
+	}
+
+	helpers := strings.Split(strings.TrimRight(runGitCmdOutput(t, worktree, "config", "--local", "--get-all", "credential.helper"), "\n"), "\n")
+	if len(helpers) != 2 || helpers[0] != "" || helpers[1] != "store --file /tmp/creds" {
+		t.Fatalf("expected reset then project helper, got %q", helpers)
+	}
+	if got := strings.TrimSpace(runGitCmdOutput(t, worktree, "config", "--local", "core.sshCommand")); got != "ssh -i /tmp/deploy_key" {
+		t.Fatalf("unexpected core.sshCommand %q", got)
+	}
+}
+
+func TestIsSSHRemoteURL(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]bool{
+		"git@github.com:org/repo.git":      true,
+		"ssh://git@github.com/org/repo":    true,
+		"deploy@gitea.local:org/repo.git":  true,
+		"https://github.com/org/repo.git":  false,
+		"file:///tmp/repo.git":             false,
+		"/tmp/repo.git":                    false,
+		"https://ghe.example.com:8443/o/r": false,
+	}
+	for in, want := range cases {
+		if got := IsSSHRemoteURL(in); got != want {
+			t.Errorf("IsSSHRemoteURL(%q) = %v, want %v", in, got, want)
+		}
+	}
+}
+
+func TestSSHRemoteURL(t *testing.T) {
+	t.Parallel()
+
+	if got := SSHRemoteURL("https://ghe.example.com/fork-user/repo.git"); got != "git@ghe.example.com:fork-user/repo.git" {
+		t.Fatalf("unexpected SSH URL %q", got)
+	}
+	if got := SSHRemoteURL("git@github.com:org/repo.git"); got != "git@github.com:org/repo.git" {
+		t.Fatalf("expected SSH URL unchanged, got %q", got)
+	}
+}
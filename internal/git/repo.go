@@ -13,6 +13,11 @@ import (
 	"runtime"
 	"strings"
 	"sync"
+
+	"autopr/internal/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const (
@@ -283,6 +288,30 @@ func CheckGitRemoteReachable(ctx context.Context, remoteURL, token string) error
 	return nil
 }
 
+// RemoteBranchExists reports whether branch exists on the remote at remoteURL.
+func RemoteBranchExists(ctx context.Context, remoteURL, token, branch string) (bool, error) {
+	remoteURL = strings.TrimSpace(remoteURL)
+	branch = strings.TrimSpace(branch)
+	if remoteURL == "" {
+		return false, fmt.Errorf("remote URL is empty")
+	}
+	if branch == "" {
+		return false, fmt.Errorf("branch name is empty")
+	}
+
+	authURL, auth, err := prepareGitRemoteAuth(remoteURL, token)
+	if err != nil {
+		return false, err
+	}
+	defer closeGitAuth(auth)
+
+	out, _, err := runGitOutputAndErrWithOptions(ctx, "", false, optionsFromAuth(auth), "ls-remote", "--heads", authURL, "refs/heads/"+branch)
+	if err != nil {
+		return false, fmt.Errorf("list remote branch: %w", err)
+	}
+	return strings.TrimSpace(out) != "", nil
+}
+
 func getRemoteURL(ctx context.Context, dir, remoteName string) (string, error) {
 	remoteName = strings.TrimSpace(remoteName)
 	if remoteName == "" {
@@ -476,7 +505,9 @@ func runGit(ctx context.Context, dir string, args ...string) error {
 	return runGitWithOptions(ctx, dir, gitRunOptions{}, args...)
 }
 
-func runGitWithOptions(ctx context.Context, dir string, opts gitRunOptions, args ...string) error {
+func runGitWithOptions(ctx context.Context, dir string, opts gitRunOptions, args ...string) (err error) {
+	ctx, span := startGitSpan(ctx, args)
+	defer func() { tracing.End(span, err) }()
 	cmd := exec.CommandContext(ctx, "git", args...)
 	if dir != "" {
 		cmd.Dir = dir
@@ -499,7 +530,9 @@ func runGitOutputAndErrWithNoEditor(ctx context.Context, dir string, args ...str
 	return runGitOutputAndErrWithOptions(ctx, dir, true, gitRunOptions{}, args...)
 }
 
-func runGitOutputAndErrWithOptions(ctx context.Context, dir string, noEditor bool, opts gitRunOptions, args ...string) (string, string, error) {
+func runGitOutputAndErrWithOptions(ctx context.Context, dir string, noEditor bool, opts gitRunOptions, args ...string) (_ string, _ string, err error) {
+	ctx, span := startGitSpan(ctx, args)
+	defer func() { tracing.End(span, err) }()
 	cmd := exec.CommandContext(ctx, "git", args...)
 	if dir != "" {
 		cmd.Dir = dir
@@ -514,7 +547,7 @@ func runGitOutputAndErrWithOptions(ctx context.Context, dir string, noEditor boo
 	var stderr bytes.Buffer
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
-	err := cmd.Run()
+	err = cmd.Run()
 	if err == nil {
 		return redactSensitiveText(stdout.String(), opts.secrets), redactSensitiveText(stderr.String(), opts.secrets), nil
 	}
@@ -525,7 +558,9 @@ func runGitOutput(ctx context.Context, dir string, args ...string) (string, erro
 	return runGitOutputWithOptions(ctx, dir, gitRunOptions{}, args...)
 }
 
-func runGitOutputWithOptions(ctx context.Context, dir string, opts gitRunOptions, args ...string) (string, error) {
+func runGitOutputWithOptions(ctx context.Context, dir string, opts gitRunOptions, args ...string) (_ string, err error) {
+	ctx, span := startGitSpan(ctx, args)
+	defer func() { tracing.End(span, err) }()
 	cmd := exec.CommandContext(ctx, "git", args...)
 	if dir != "" {
 		cmd.Dir = dir
@@ -548,6 +583,28 @@ func runGitOutputWithOptions(ctx context.Context, dir string, opts gitRunOptions
 	return redactSensitiveText(string(out), opts.secrets), nil
 }
 
+// startGitSpan starts a span for one git command. Only the subcommand is
+// recorded: the full arguments can carry remote URLs and tokens.
+func startGitSpan(ctx context.Context, args []string) (context.Context, trace.Span) {
+	sub := gitSubcommand(args)
+	return tracing.Start(ctx, "git "+sub, attribute.String("git.subcommand", sub))
+}
+
+// gitSubcommand returns the subcommand of a git argument list, skipping
+// global options such as "-c key=value" and "-C dir".
+func gitSubcommand(args []string) string {
+	for i := 0; i < len(args); i++ {
+		switch a := args[i]; {
+		case a == "-c" || a == "-C":
+			i++
+		case strings.HasPrefix(a, "-"):
+		default:
+			return a
+		}
+	}
+	return ""
+}
+
 func formatGitCommandError(args []string, out []byte, err error, secrets []string) error {
 	cmdText := redactSensitiveText(strings.Join(args, " "), secrets)
 	msg := strings.TrimSpace(redactSensitiveText(string(out), secrets))
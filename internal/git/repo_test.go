@@ -152,6 +152,37 @@ func TestCheckGitRemoteReachable(t *testing.T) {
 	}
 }
 
+func TestRemoteBranchExists(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	tmp := t.TempDir()
+
+	remote := filepath.Join(tmp, "remote.git")
+	runGitCmd(t, "", "init", "--bare", remote)
+	repo := filepath.Join(tmp, "repo")
+	runGitCmd(t, "", "init", repo)
+	runGitCmd(t, repo, "config", "user.email", "test@example.com")
+	runGitCmd(t, repo, "config", "user.name", "Test User")
+	runGitCmd(t, repo, "remote", "add", "origin", remote)
+	runGitCmd(t, repo, "checkout", "-B", "autopr/present")
+	if err := os.WriteFile(filepath.Join(repo, "README.md"), []byte("hello\n"), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	runGitCmd(t, repo, "add", "README.md")
+	runGitCmd(t, repo, "commit", "-m", "init")
+	runGitCmd(t, repo, "push", "origin", "autopr/present")
+
+	if ok, err := RemoteBranchExists(ctx, remote, "", "autopr/present"); err != nil || !ok {
+		t.Fatalf("expected pushed branch to exist, got %v (err %v)", ok, err)
+	}
+	if ok, err := RemoteBranchExists(ctx, remote, "", "autopr/missing"); err != nil || ok {
+		t.Fatalf("expected missing branch, got %v (err %v)", ok, err)
+	}
+	if _, err := RemoteBranchExists(ctx, filepath.Join(tmp, "missing.git"), "", "autopr/present"); err == nil {
+		t.Fatal("expected error for unreachable remote")
+	}
+}
+
 func TestDeleteRemoteBranchSuccess(t *testing.T) {
 	t.Parallel()
 	ctx := context.Background()
@@ -491,3 +522,19 @@ func runGitCmdOutputErr(t *testing.T, dir string, args ...string) error {
 	_, err := cmd.Output()
 	return err
 }
+
+func TestGitSubcommandSkipsGlobalOptions(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string][]string{
+		"push":   {"-c", "credential.helper=", "-C", "/tmp/repo", "push", "origin", "main"},
+		"status": {"--no-pager", "status", "--porcelain"},
+		"clone":  {"clone", "https://example.com/repo.git"},
+		"":       {"-c", "core.askPass=x"},
+	}
+	for want, args := range cases {
+		if got := gitSubcommand(args); got != want {
+			t.Errorf("gitSubcommand(%q) = %q, want %q", args, got, want)
+		}
+	}
+}
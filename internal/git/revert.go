@@ -0,0 +1,66 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Revert commits the inverse of commit onto the current branch. Merge commits
+// are reverted relative to their first parent. Returns true when conflicts
+// are detected.
+func Revert(ctx context.Context, dir, commit string) (bool, error) {
+	parents, err := runGitOutput(ctx, dir, "rev-list", "--parents", "-n", "1", commit)
+	if err != nil {
+		return false, fmt.Errorf("resolve commit %s: %w", commit, err)
+	}
+	args := []string{"revert", "--no-edit"}
+	if len(strings.Fields(parents)) > 2 {
+		args = append(args, "-m", "1")
+	}
+	args = append(args, commit)
+
+	stdout, stderr, err := runGitOutputAndErr(ctx, dir, args...)
+	if err == nil {
+		return false, nil
+	}
+	if isGitRevertConflict(stderr) || isGitRevertConflict(stdout) {
+		return true, nil
+	}
+	return false, fmt.Errorf("git revert %s: %w: %s %s", commit, err, strings.TrimSpace(stdout), strings.TrimSpace(stderr))
+}
+
+// RevertContinue commits a revert after conflict resolution.
+// Returns true when conflicts remain.
+func RevertContinue(ctx context.Context, dir string) (bool, error) {
+	stdout, stderr, err := runGitOutputAndErrWithNoEditor(ctx, dir, "revert", "--continue")
+	if err == nil {
+		return false, nil
+	}
+	if isGitRevertConflict(stderr) || isGitRevertConflict(stdout) {
+		return true, nil
+	}
+	return false, fmt.Errorf("git revert --continue: %w: %s %s", err, strings.TrimSpace(stdout), strings.TrimSpace(stderr))
+}
+
+// RevertAbort aborts the current revert.
+func RevertAbort(ctx context.Context, dir string) error {
+	if !IsRevertInProgress(dir) {
+		return nil
+	}
+	return runGit(ctx, dir, "revert", "--abort")
+}
+
+// IsRevertInProgress reports whether a revert is currently running.
+func IsRevertInProgress(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, ".git", "REVERT_HEAD"))
+	return err == nil
+}
+
+func isGitRevertConflict(msg string) bool {
+	return strings.Contains(msg, "CONFLICT") ||
+		strings.Contains(msg, "could not revert") ||
+		strings.Contains(msg, "after resolving the conflicts")
+}
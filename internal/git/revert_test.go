@@ -0,0 +1,54 @@
+package git
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRevertUndoesMergedCommit(t *testing.T) {
+	ctx := context.Background()
+	tmp := t.TempDir()
+
+	remote := createRemoteWithMainBranch(t, tmp)
+	seed := filepath.Join(tmp, "seed")
+	if err := os.WriteFile(filepath.Join(seed, "app.txt"), []byte("stable\n"), 0o644); err != nil {
+		t.Fatalf("write app: %v", err)
+	}
+	runGitCmd(t, seed, "add", "app.txt")
+	runGitCmd(t, seed, "commit", "-m", "add app")
+
+	runGitCmd(t, seed, "checkout", "-b", "feature")
+	if err := os.WriteFile(filepath.Join(seed, "app.txt"), []byte("broken\n"), 0o644); err != nil {
+		t.Fatalf("write change: %v", err)
+	}
+	runGitCmd(t, seed, "commit", "-am", "risky change")
+	runGitCmd(t, seed, "checkout", "main")
+	runGitCmd(t, seed, "merge", "--no-ff", "-m", "merge feature", "feature")
+	runGitCmd(t, seed, "push", "origin", "main")
+	merge := strings.TrimSpace(runGitCmdOutput(t, seed, "rev-parse", "HEAD"))
+
+	worktree := filepath.Join(tmp, "worktree")
+	if err := CloneForJob(ctx, remote, "", worktree, "autopr/revert", "main"); err != nil {
+		t.Fatalf("clone for job: %v", err)
+	}
+	runGitCmd(t, worktree, "config", "user.email", "test@example.com")
+	runGitCmd(t, worktree, "config", "user.name", "Test User")
+
+	conflict, err := Revert(ctx, worktree, merge)
+	if err != nil || conflict {
+		t.Fatalf("revert: conflict=%v err=%v", conflict, err)
+	}
+	got, err := os.ReadFile(filepath.Join(worktree, "app.txt"))
+	if err != nil {
+		t.Fatalf("read app: %v", err)
+	}
+	if string(got) != "stable\n" {
+		t.Fatalf("expected merge to be reverted, got %q", got)
+	}
+	if IsRevertInProgress(worktree) {
+		t.Fatalf("expected revert to be finished")
+	}
+}
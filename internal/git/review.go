@@ -0,0 +1,68 @@
+package git
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// FirstGitHubApprovalAt returns when a GitHub PR first received an approving
+// review, or "" when it has none.
+func FirstGitHubApprovalAt(ctx context.Context, token, baseURL, prURL string) (string, error) {
+	owner, repo, number, err := parseGitHubPRURL(prURL)
+	if err != nil {
+		return "", err
+	}
+	apiURL := fmt.Sprintf("%s/repos/%s/%s/pulls/%s/reviews?per_page=100", NormalizeGitHubAPIBaseURL(baseURL), owner, repo, number)
+	resp, err := DoGitHubRequest(ctx, token, http.MethodGet, apiURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("list PR reviews: %w", err)
+	}
+	defer resp.Body.Close()
+	return firstApprovalAt(resp)
+}
+
+// FirstGiteaApprovalAt returns when a Gitea/Forgejo PR first received an
+// approving review, or "" when it has none.
+func FirstGiteaApprovalAt(ctx context.Context, token, baseURL, prURL string) (string, error) {
+	owner, repo, index, err := parseGiteaPRURL(prURL)
+	if err != nil {
+		return "", err
+	}
+	apiURL := GiteaAPIURL(baseURL, fmt.Sprintf("/repos/%s/%s/pulls/%s/reviews", owner, repo, index))
+	resp, err := DoGiteaRequest(ctx, token, http.MethodGet, apiURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("list PR reviews: %w", err)
+	}
+	defer resp.Body.Close()
+	return firstApprovalAt(resp)
+}
+
+// firstApprovalAt decodes a GitHub-style review list (Gitea uses the same
+// shape) and returns the earliest APPROVED submission time.
+func firstApprovalAt(resp *http.Response) (string, error) {
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("list PR reviews: HTTP %d: %s", resp.StatusCode, truncateBody(body, 4096))
+	}
+	var reviews []struct {
+		State       string `json:"state"`
+		SubmittedAt string `json:"submitted_at"`
+	}
+	if err := json.Unmarshal(body, &reviews); err != nil {
+		return "", fmt.Errorf("decode PR reviews: %w", err)
+	}
+	first := ""
+	for _, r := range reviews {
+		if !strings.EqualFold(r.State, "APPROVED") || r.SubmittedAt == "" {
+			continue
+		}
+		if first == "" || r.SubmittedAt < first {
+			first = r.SubmittedAt
+		}
+	}
+	return first, nil
+}
@@ -0,0 +1,49 @@
+package git
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFirstGiteaApprovalAtPicksEarliestApproval(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/repos/org/repo/pulls/7/reviews" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		_, _ = io.WriteString(w, `[
+			{"state":"REQUEST_CHANGES","submitted_at":"2026-01-01T00:00:00Z"},
+			{"state":"APPROVED","submitted_at":"2026-01-03T00:00:00Z"},
+			{"state":"APPROVED","submitted_at":"2026-01-02T00:00:00Z"}]`)
+	}))
+	defer srv.Close()
+
+	got, err := FirstGiteaApprovalAt(context.Background(), "tok", srv.URL, srv.URL+"/org/repo/pulls/7")
+	if err != nil {
+		t.Fatalf("first approval: %v", err)
+	}
+	if got != "2026-01-02T00:00:00Z" {
+		t.Fatalf("expected earliest approval, got %q", got)
+	}
+}
+
+func TestFirstGiteaApprovalAtEmptyWithoutApproval(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, `[{"state":"COMMENT","submitted_at":"2026-01-01T00:00:00Z"}]`)
+	}))
+	defer srv.Close()
+
+	got, err := FirstGiteaApprovalAt(context.Background(), "tok", srv.URL, srv.URL+"/org/repo/pulls/7")
+	if err != nil {
+		t.Fatalf("first approval: %v", err)
+	}
+	if got != "" {
+		t.Fatalf("expected no approval, got %q", got)
+	}
+}
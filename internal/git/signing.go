@@ -0,0 +1,150 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// CommitSigning is how a clone signs the commits made in it.
+type CommitSigning struct {
+	Format string // "ssh" or "gpg"
+	// Key is the private key file (ssh) or the key ID or fingerprint (gpg).
+	Key string
+	// Program replaces git's gpg.program; "" keeps gpg from PATH. Unused for
+	// ssh.
+	Program string
+	// AllowedSignersFile lists the keys git trusts when it checks SSH
+	// signatures; without it SignatureStatus cannot verify ssh-signed commits.
+	AllowedSignersFile string
+}
+
+// ConfigureSigning sets the clone's local config so every commit it makes,
+// including commits rewritten by rebase, cherry-pick, and revert, is signed.
+func ConfigureSigning(ctx context.Context, dir string, s CommitSigning) error {
+	settings := [][2]string{{"user.signingkey", s.Key}, {"commit.gpgsign", "true"}}
+	switch s.Format {
+	case "ssh":
+		settings = append(settings, [2]string{"gpg.format", "ssh"})
+		if s.AllowedSignersFile != "" {
+			settings = append(settings, [2]string{"gpg.ssh.allowedSignersFile", s.AllowedSignersFile})
+		}
+	case "gpg":
+		settings = append(settings, [2]string{"gpg.format", "openpgp"})
+		if s.Program != "" {
+			settings = append(settings, [2]string{"gpg.program", s.Program})
+		}
+	default:
+		return fmt.Errorf("unknown signing format %q", s.Format)
+	}
+	for _, kv := range settings {
+		if err := runGit(ctx, dir, "config", "--local", kv[0], kv[1]); err != nil {
+			return fmt.Errorf("set %s: %w", kv[0], err)
+		}
+	}
+	return nil
+}
+
+// SSHPublicKey returns the public key of an unencrypted SSH private key file.
+func SSHPublicKey(ctx context.Context, keyPath string) (string, error) {
+	out, err := exec.CommandContext(ctx, "ssh-keygen", "-y", "-f", keyPath).Output()
+	if err != nil {
+		return "", fmt.Errorf("read public key of %s: %w", keyPath, commandError(err))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// ImportGPGKey imports an armored, unprotected secret key into the GPG home
+// directory homeDir, creating it if needed, and returns the key's
+// fingerprint.
+func ImportGPGKey(ctx context.Context, homeDir, armoredKey string) (string, error) {
+	if err := os.MkdirAll(homeDir, 0o700); err != nil {
+		return "", fmt.Errorf("create gpg home: %w", err)
+	}
+	cmd := exec.CommandContext(ctx, "gpg", "--homedir", homeDir, "--batch", "--import")
+	cmd.Stdin = strings.NewReader(armoredKey)
+	if _, err := cmd.Output(); err != nil {
+		return "", fmt.Errorf("gpg import: %w", commandError(err))
+	}
+	out, err := exec.CommandContext(ctx, "gpg", "--homedir", homeDir, "--batch", "--with-colons", "--list-secret-keys").Output()
+	if err != nil {
+		return "", fmt.Errorf("gpg list secret keys: %w", commandError(err))
+	}
+	for line := range strings.SplitSeq(string(out), "\n") {
+		if fields := strings.Split(line, ":"); len(fields) > 9 && fields[0] == "fpr" {
+			return fields[9], nil
+		}
+	}
+	return "", fmt.Errorf("gpg import: no secret key found in the key material")
+}
+
+// WriteGPGProgram writes a script into homeDir that runs gpg against that
+// home directory, for use as gpg.program, and returns its path.
+func WriteGPGProgram(homeDir string) (string, error) {
+	if runtime.GOOS == "windows" {
+		path := filepath.Join(homeDir, "gpg.cmd")
+		script := "@echo off\r\ngpg --homedir \"" + homeDir + "\" %*\r\n"
+		return path, os.WriteFile(path, []byte(script), 0o700)
+	}
+	path := filepath.Join(homeDir, "gpg.sh")
+	script := "#!/bin/sh\nexec gpg --homedir '" + strings.ReplaceAll(homeDir, "'", `'\''`) + "' \"$@\"\n"
+	return path, os.WriteFile(path, []byte(script), 0o700)
+}
+
+// SignatureStatus checks the signatures of the commits in base..HEAD against
+// the keys the clone trusts. It returns "" when the clone does not sign
+// commits or the range is empty, "verified (N commits)" when every commit carries a good signature,
+// and a count of the unsigned and unverified commits otherwise.
+func SignatureStatus(ctx context.Context, dir, base string) (string, error) {
+	if out, err := runGitOutput(ctx, dir, "config", "--local", "--get", "commit.gpgsign"); err != nil || strings.TrimSpace(out) != "true" {
+		return "", nil
+	}
+	out, err := runGitOutput(ctx, dir, "log", "--format=%G?", base+"..HEAD")
+	if err != nil {
+		return "", fmt.Errorf("read commit signatures: %w", err)
+	}
+	var total, unsigned, bad int
+	for line := range strings.SplitSeq(strings.TrimSpace(out), "\n") {
+		if line = strings.TrimSpace(line); line == "" {
+			continue
+		}
+		total++
+		switch line {
+		case "G", "U": // good; U is a good signature from a key of unknown trust
+		case "N":
+			unsigned++
+		default:
+			bad++
+		}
+	}
+	if total == 0 {
+		return "", nil
+	}
+	commits := fmt.Sprintf("%d commits", total)
+	if total == 1 {
+		commits = "1 commit"
+	}
+	if unsigned == 0 && bad == 0 {
+		return "verified (" + commits + ")", nil
+	}
+	var parts []string
+	if unsigned > 0 {
+		parts = append(parts, fmt.Sprintf("%d unsigned", unsigned))
+	}
+	if bad > 0 {
+		parts = append(parts, fmt.Sprintf("%d unverified", bad))
+	}
+	return fmt.Sprintf("not verified: %s of %s", strings.Join(parts, ", "), commits), nil
+}
+
+// commandError adds a failed command's stderr to its error.
+func commandError(err error) error {
+	if ee, ok := err.(*exec.ExitError); ok && len(ee.Stderr) > 0 {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(ee.Stderr)))
+	}
+	return err
+}
@@ -0,0 +1,63 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// updateSubmodules checks out the submodules of a fresh clone, recursively.
+// The clone's credentials are only offered when every top-level submodule
+// lives on the same host as repoURL (or is relative to it), so the token is
+// never sent to another host; submodules elsewhere must be public or reachable
+// over SSH.
+func updateSubmodules(ctx context.Context, dir, repoURL string, opts gitRunOptions) error {
+	if _, err := os.Stat(filepath.Join(dir, ".gitmodules")); err != nil {
+		return nil
+	}
+	urls, err := runGitOutput(ctx, dir, "config", "--file", ".gitmodules", "--get-regexp", `^submodule\..*\.url$`)
+	if err != nil {
+		// No url entries: nothing to check out.
+		return nil
+	}
+	host := remoteHost(repoURL)
+	for _, line := range strings.Split(strings.TrimSpace(urls), "\n") {
+		_, subURL, _ := strings.Cut(line, " ")
+		subURL = strings.TrimSpace(subURL)
+		if strings.HasPrefix(subURL, "./") || strings.HasPrefix(subURL, "../") {
+			continue
+		}
+		if h := remoteHost(subURL); h == "" || !strings.EqualFold(h, host) {
+			slog.Info("submodule on another host; updating submodules without credentials", "path", dir, "url", redactSensitiveText(subURL, nil))
+			opts = gitRunOptions{env: []string{"GIT_TERMINAL_PROMPT=0"}, secrets: opts.secrets}
+			break
+		}
+	}
+	if err := runGitWithOptions(ctx, dir, opts, "submodule", "update", "--init", "--recursive"); err != nil {
+		return fmt.Errorf("update submodules: %w", err)
+	}
+	return nil
+}
+
+// remoteHost returns the host of an HTTP(S), ssh://, or scp-style
+// (user@host:path) remote URL, or "" for local paths.
+func remoteHost(remote string) string {
+	remote = strings.TrimSpace(remote)
+	if strings.Contains(remote, "://") {
+		u, err := url.Parse(remote)
+		if err != nil {
+			return ""
+		}
+		return u.Hostname()
+	}
+	if at := strings.Index(remote, "@"); at >= 0 {
+		if host, _, ok := strings.Cut(remote[at+1:], ":"); ok {
+			return host
+		}
+	}
+	return ""
+}
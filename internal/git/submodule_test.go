@@ -0,0 +1,76 @@
+package git
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCloneForJobChecksOutSubmodules(t *testing.T) {
+	// Local submodule URLs need the file transport, which git disables for
+	// submodules by default.
+	t.Setenv("GIT_CONFIG_COUNT", "1")
+	t.Setenv("GIT_CONFIG_KEY_0", "protocol.file.allow")
+	t.Setenv("GIT_CONFIG_VALUE_0", "always")
+
+	ctx := context.Background()
+	tmp := t.TempDir()
+	lib := createRemoteWithMainBranch(t, filepath.Join(tmp, "lib"))
+	app := createRemoteWithMainBranch(t, filepath.Join(tmp, "app"))
+	seed := filepath.Join(tmp, "app", "seed")
+	runGitCmd(t, seed, "submodule", "add", "-b", "main", lib, "vendor/lib")
+	runGitCmd(t, seed, "commit", "-m", "add lib")
+	runGitCmd(t, seed, "push", "origin", "main")
+
+	dest := filepath.Join(tmp, "worktrees", "ap-job-sub")
+	if err := CloneForJob(ctx, app, "", dest, "autopr/job-sub", "main"); err != nil {
+		t.Fatalf("clone for job: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dest, "vendor", "lib", "README.md"))
+	if err != nil || string(data) != "hello\n" {
+		t.Fatalf("expected submodule checked out, got %q, %v", data, err)
+	}
+}
+
+func TestRemoteHost(t *testing.T) {
+	t.Parallel()
+	for remote, want := range map[string]string{
+		"https://github.com/org/repo.git":    "github.com",
+		"https://oauth2@ghe.example.com/o/r": "ghe.example.com",
+		"ssh://git@gitlab.com:2222/o/r.git":  "gitlab.com",
+		"git@github.com:org/repo.git":        "github.com",
+		"/srv/git/repo.git":                  "",
+		"file:///srv/git/repo.git":           "",
+	} {
+		if got := remoteHost(remote); got != want {
+			t.Errorf("remoteHost(%q) = %q, want %q", remote, got, want)
+		}
+	}
+}
+
+func TestUsesLFS(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	if usesLFS(dir) {
+		t.Fatalf("expected no LFS without .gitattributes")
+	}
+	attrs := "# *.bin filter=lfs\n*.txt text\n"
+	if err := os.WriteFile(filepath.Join(dir, ".gitattributes"), []byte(attrs), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if usesLFS(dir) {
+		t.Fatalf("expected commented-out LFS pattern to be ignored")
+	}
+	attrs += "*.psd filter=lfs diff=lfs merge=lfs -text\n"
+	if err := os.WriteFile(filepath.Join(dir, ".gitattributes"), []byte(attrs), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if !usesLFS(dir) {
+		t.Fatalf("expected LFS pattern to be found")
+	}
+	if err := pullLFS(context.Background(), dir, gitRunOptions{}); err != nil && !strings.Contains(err.Error(), "lfs") {
+		t.Fatalf("unexpected pull error: %v", err)
+	}
+}
@@ -0,0 +1,88 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// CommitterIdentity returns the "Name <email>" git commits with in dir.
+func CommitterIdentity(ctx context.Context, dir string) (string, error) {
+	out, err := runGitOutput(ctx, dir, "var", "GIT_COMMITTER_IDENT")
+	if err != nil {
+		return "", fmt.Errorf("read committer identity: %w", err)
+	}
+	// The ident ends with the commit time: "Name <email> 1700000000 +0000".
+	ident := strings.TrimSpace(out)
+	if i := strings.LastIndex(ident, ">"); i >= 0 {
+		ident = ident[:i+1]
+	}
+	return ident, nil
+}
+
+// UserIdentity returns "Name <email>" from user.name and user.email as git
+// config sets them for dir, or "" when either is unset.
+func UserIdentity(ctx context.Context, dir string) string {
+	name, _ := runGitOutput(ctx, dir, "config", "user.name")
+	email, _ := runGitOutput(ctx, dir, "config", "user.email")
+	name, email = strings.TrimSpace(name), strings.TrimSpace(email)
+	if name == "" || email == "" {
+		return ""
+	}
+	return name + " <" + email + ">"
+}
+
+// AddTrailers adds trailers ("Key: value") to the message of every commit in
+// base..HEAD, keeping trailers a message already has. Commits are rewritten
+// with rebase, so clones that sign commits sign the new ones. It does nothing
+// when every commit has all the trailers already.
+func AddTrailers(ctx context.Context, dir, base string, trailers []string) error {
+	if len(trailers) == 0 {
+		return nil
+	}
+	out, err := runGitOutput(ctx, dir, "log", "--format=%(trailers:only,unfold)%x00", base+"..HEAD")
+	if err != nil {
+		return fmt.Errorf("read commit trailers: %w", err)
+	}
+	if !missingTrailers(out, trailers) {
+		return nil
+	}
+
+	amend := []string{"git", "-c", "trailer.ifexists=addIfDifferent", "commit", "--amend", "--no-edit", "--no-verify"}
+	for _, t := range trailers {
+		amend = append(amend, "--trailer", t)
+	}
+	quoted := make([]string, len(amend))
+	for i, arg := range amend {
+		quoted[i] = "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+	}
+	stdout, stderr, err := runGitOutputAndErrWithNoEditor(ctx, dir, "rebase", "--exec", strings.Join(quoted, " "), base)
+	if err != nil {
+		if IsRebaseInProgress(dir) {
+			_ = RebaseAbort(ctx, dir)
+		}
+		return fmt.Errorf("add commit trailers: %w: %s %s", err, strings.TrimSpace(stdout), strings.TrimSpace(stderr))
+	}
+	return nil
+}
+
+// missingTrailers reports whether any commit's trailers, as listed by
+// `git log --format=%(trailers:only,unfold)%x00`, lack one of want.
+func missingTrailers(log string, want []string) bool {
+	commits := strings.Split(log, "\x00")
+	// The last element follows the final separator.
+	commits = commits[:len(commits)-1]
+	for _, c := range commits {
+		var have []string
+		for line := range strings.SplitSeq(c, "\n") {
+			have = append(have, strings.TrimSpace(line))
+		}
+		for _, t := range want {
+			if !slices.Contains(have, t) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,59 @@
+package git
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAddTrailersToEveryJobCommitOnce(t *testing.T) {
+	ctx := context.Background()
+	tmp := t.TempDir()
+
+	remote := createRemoteWithMainBranch(t, tmp)
+	worktree := filepath.Join(tmp, "worktree")
+	if err := CloneForJob(ctx, remote, "", worktree, "autopr/fix", "main"); err != nil {
+		t.Fatalf("clone for job: %v", err)
+	}
+	runGitCmd(t, worktree, "config", "user.email", "bot@example.com")
+	runGitCmd(t, worktree, "config", "user.name", "AutoPR Bot")
+	for _, name := range []string{"one.txt", "two.txt"} {
+		if err := os.WriteFile(filepath.Join(worktree, name), []byte(name+"\n"), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+		runGitCmd(t, worktree, "add", name)
+		runGitCmd(t, worktree, "commit", "-m", "add "+name, "-m", "Co-authored-by: Jane Doe <jane@example.com>")
+	}
+
+	signer, err := CommitterIdentity(ctx, worktree)
+	if err != nil {
+		t.Fatalf("committer identity: %v", err)
+	}
+	if signer != "AutoPR Bot <bot@example.com>" {
+		t.Fatalf("committer identity = %q", signer)
+	}
+	trailers := []string{"Signed-off-by: " + signer, "Co-authored-by: Jane Doe <jane@example.com>", "Co-authored-by: Claude <noreply@anthropic.com>"}
+	if err := AddTrailers(ctx, worktree, "origin/main", trailers); err != nil {
+		t.Fatalf("add trailers: %v", err)
+	}
+	head := strings.TrimSpace(runGitCmdOutput(t, worktree, "rev-parse", "HEAD"))
+
+	for _, rev := range []string{"HEAD", "HEAD~1"} {
+		msg := runGitCmdOutput(t, worktree, "log", "-1", "--format=%B", rev)
+		for _, tr := range trailers {
+			if n := strings.Count(msg, tr); n != 1 {
+				t.Fatalf("%s has %q %d times:\n%s", rev, tr, n, msg)
+			}
+		}
+	}
+
+	// A second push finds the trailers in place and leaves the commits alone.
+	if err := AddTrailers(ctx, worktree, "origin/main", trailers); err != nil {
+		t.Fatalf("add trailers again: %v", err)
+	}
+	if got := strings.TrimSpace(runGitCmdOutput(t, worktree, "rev-parse", "HEAD")); got != head {
+		t.Fatalf("expected commits unchanged, HEAD %s -> %s", head, got)
+	}
+}
@@ -0,0 +1,73 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// treeBlobLimit is the largest file a tree inspection clone fetches; larger
+// files are listed but read as missing.
+const treeBlobLimit = "1m"
+
+// InspectTree makes a shallow clone of branch without a checkout in
+// destPath, which is removed afterwards, and calls fn with the paths of the
+// files under dir ("" for the whole repository), relative to dir and
+// slash-separated, and a function reading one of them. Only small files are
+// fetched, so inspecting a large repository stays cheap.
+func InspectTree(ctx context.Context, repoURL, token, destPath, branch, dir string, remoteAuth RemoteAuth, fn func(paths []string, read func(path string) ([]byte, error)) error) error {
+	destPath, err := prepareCloneDestination(destPath)
+	if err != nil {
+		return fmt.Errorf("prepare clone destination: %w", err)
+	}
+	defer RemoveJobDir(destPath)
+
+	authURL, auth, err := prepareGitRemoteAuth(repoURL, token)
+	if err != nil {
+		return err
+	}
+	defer closeGitAuth(auth)
+	opts := optionsFromAuth(auth)
+
+	slog.Debug("cloning repository tree", "url", redactSensitiveText(authURL, nil), "path", destPath, "branch", branch)
+	args := append([]string{"clone"}, remoteAuth.cloneConfigArgs()...)
+	args = append(args, "--depth", "1", "--filter", "blob:limit="+treeBlobLimit, "--no-checkout", "--branch", branch, authURL, destPath)
+	if err := runGitWithOptions(ctx, "", opts, args...); err != nil {
+		return fmt.Errorf("clone repository tree: %w", err)
+	}
+
+	dir = strings.Trim(dir, "/")
+	lsArgs := []string{"ls-tree", "-r", "--name-only", "-z", "HEAD"}
+	if dir != "" {
+		lsArgs = append(lsArgs, "--", dir+"/")
+	}
+	out, err := runGitOutputWithOptions(ctx, destPath, opts, lsArgs...)
+	if err != nil {
+		return fmt.Errorf("list repository tree: %w", err)
+	}
+	var paths []string
+	for p := range strings.SplitSeq(out, "\x00") {
+		if dir != "" {
+			p = strings.TrimPrefix(p, dir+"/")
+		}
+		if p != "" {
+			paths = append(paths, p)
+		}
+	}
+	read := func(path string) ([]byte, error) {
+		if dir != "" {
+			path = dir + "/" + path
+		}
+		// Files over the blob limit would be fetched on demand; with
+		// GIT_NO_LAZY_FETCH they read as missing instead.
+		readOpts := opts
+		readOpts.env = append(append([]string(nil), opts.env...), "GIT_NO_LAZY_FETCH=1")
+		out, err := runGitOutputWithOptions(ctx, destPath, readOpts, "cat-file", "blob", "HEAD:"+path)
+		if err != nil {
+			return nil, err
+		}
+		return []byte(out), nil
+	}
+	return fn(paths, read)
+}
@@ -0,0 +1,58 @@
+package git
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestInspectTreeListsAndReadsFilesUnderDir(t *testing.T) {
+	t.Parallel()
+
+	tmp := t.TempDir()
+	remote := createRemoteWithMainBranch(t, tmp)
+	seed := filepath.Join(tmp, "seed")
+	if err := os.MkdirAll(filepath.Join(seed, "web", "src"), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	for name, content := range map[string]string{
+		"web/package.json": `{"scripts":{"test":"vitest"}}`,
+		"web/src/app.tsx":  "export {}\n",
+		"other/main.go":    "package main\n",
+	} {
+		path := filepath.Join(seed, filepath.FromSlash(name))
+		_ = os.MkdirAll(filepath.Dir(path), 0o755)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	runGitCmd(t, seed, "add", "-A")
+	runGitCmd(t, seed, "commit", "-m", "add web")
+	runGitCmd(t, seed, "push", "origin", "main")
+
+	dest := filepath.Join(tmp, "inspect")
+	var (
+		paths []string
+		pkg   []byte
+	)
+	err := InspectTree(context.Background(), remote, "", dest, "main", "web", RemoteAuth{}, func(p []string, read func(string) ([]byte, error)) error {
+		paths = p
+		var err error
+		pkg, err = read("package.json")
+		return err
+	})
+	if err != nil {
+		t.Fatalf("inspect tree: %v", err)
+	}
+	if !slices.Equal(paths, []string{"package.json", "src/app.tsx"}) {
+		t.Fatalf("unexpected paths %v", paths)
+	}
+	if string(pkg) != `{"scripts":{"test":"vitest"}}` {
+		t.Fatalf("unexpected package.json %q", pkg)
+	}
+	if _, err := os.Stat(dest); !os.IsNotExist(err) {
+		t.Fatalf("expected the clone removed, got %v", err)
+	}
+}
@@ -7,7 +7,10 @@ import (
 	"log/slog"
 	"os"
 	"path/filepath"
+	"runtime"
+	"slices"
 	"strings"
+	"time"
 )
 
 // CloneForJob clones the remote repo into destPath and creates a job branch
@@ -15,6 +18,13 @@ import (
 // tools (e.g. codex) may run `git init` in the working directory, which
 // destroys worktree .git link files but is a no-op on a .git directory.
 func CloneForJob(ctx context.Context, repoURL, token, destPath, branchName, baseBranch string) error {
+	return CloneForJobWithAuth(ctx, repoURL, token, destPath, branchName, baseBranch, RemoteAuth{})
+}
+
+// CloneForJobWithAuth is CloneForJob with per-project transport settings,
+// which are persisted in the clone for later fetches and pushes. Submodules
+// are checked out and LFS files fetched after the job branch is created.
+func CloneForJobWithAuth(ctx context.Context, repoURL, token, destPath, branchName, baseBranch string, remoteAuth RemoteAuth) error {
 	destPath, err := prepareCloneDestination(destPath)
 	if err != nil {
 		return fmt.Errorf("prepare clone destination: %w", err)
@@ -30,7 +40,16 @@ func CloneForJob(ctx context.Context, repoURL, token, destPath, branchName, base
 	// git will use hard links for shared objects automatically when on the
 	// same filesystem.
 	slog.Info("cloning job repository", "url", redactSensitiveText(authURL, nil), "path", destPath, "base_branch", baseBranch)
-	if err := runGitWithOptions(ctx, "", optionsFromAuth(auth), "clone", "--branch", baseBranch, authURL, destPath); err != nil {
+	args := append([]string{"clone"}, remoteAuth.cloneConfigArgs()...)
+	if runtime.GOOS == "windows" {
+		// Worktrees live deep under repos_root; without long paths, checkouts
+		// of nested repositories fail at 260 characters.
+		args = append(args, "--config", "core.longpaths=true")
+	}
+	args = append(args, "--branch", baseBranch, authURL, destPath)
+	cloneOpts := optionsFromAuth(auth)
+	cloneOpts.env = append(slices.Clone(cloneOpts.env), lfsSkipSmudgeEnv)
+	if err := runGitWithOptions(ctx, "", cloneOpts, args...); err != nil {
 		return fmt.Errorf("clone for job: %w", err)
 	}
 
@@ -43,7 +62,10 @@ func CloneForJob(ctx context.Context, repoURL, token, destPath, branchName, base
 		return fmt.Errorf("create job branch: %w", err)
 	}
 
-	return nil
+	if err := updateSubmodules(ctx, destPath, repoURL, optionsFromAuth(auth)); err != nil {
+		return err
+	}
+	return pullLFS(ctx, destPath, optionsFromAuth(auth))
 }
 
 func prepareCloneDestination(destPath string) (string, error) {
@@ -57,7 +79,7 @@ func prepareCloneDestination(destPath string) (string, error) {
 	}
 
 	if _, err := os.Stat(cleanPath); err == nil {
-		if err := os.RemoveAll(cleanPath); err != nil {
+		if err := removeAll(cleanPath); err != nil {
 			return "", fmt.Errorf("remove stale worktree %q: %w", cleanPath, err)
 		}
 	} else if !errors.Is(err, os.ErrNotExist) {
@@ -83,5 +105,56 @@ func isFilesystemRoot(path string) bool {
 
 // RemoveJobDir removes a job's cloned working directory.
 func RemoveJobDir(worktreePath string) {
-	_ = os.RemoveAll(worktreePath)
+	_ = removeAll(worktreePath)
+}
+
+// removeAllAttempts bounds removeAll's retries on Windows.
+const removeAllAttempts = 5
+
+// removeAll is os.RemoveAll, retried on Windows, where a file that an exiting
+// LLM or test process (or a virus scanner) still has open cannot be deleted.
+func removeAll(path string) error {
+	err := os.RemoveAll(path)
+	for i := 1; err != nil && runtime.GOOS == "windows" && i < removeAllAttempts; i++ {
+		time.Sleep(time.Duration(i) * 200 * time.Millisecond)
+		err = os.RemoveAll(path)
+	}
+	return err
+}
+
+// ExcludeLocally adds pattern to the clone's .git/info/exclude, if it is not
+// there yet, so git ignores matching files without a change to .gitignore.
+func ExcludeLocally(ctx context.Context, dir, pattern string) error {
+	out, err := runGitOutput(ctx, dir, "rev-parse", "--git-path", "info/exclude")
+	if err != nil {
+		return fmt.Errorf("locate info/exclude: %w", err)
+	}
+	excludePath := strings.TrimSpace(out)
+	if !filepath.IsAbs(excludePath) {
+		excludePath = filepath.Join(dir, excludePath)
+	}
+	data, err := os.ReadFile(excludePath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("read info/exclude: %w", err)
+	}
+	for line := range strings.SplitSeq(string(data), "\n") {
+		if strings.TrimSpace(line) == pattern {
+			return nil
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(excludePath), 0o755); err != nil {
+		return fmt.Errorf("create info dir: %w", err)
+	}
+	if len(data) > 0 && !strings.HasSuffix(string(data), "\n") {
+		pattern = "\n" + pattern
+	}
+	f, err := os.OpenFile(excludePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("open info/exclude: %w", err)
+	}
+	if _, err := f.WriteString(pattern + "\n"); err != nil {
+		f.Close()
+		return fmt.Errorf("write info/exclude: %w", err)
+	}
+	return f.Close()
 }
@@ -112,3 +112,39 @@ func createRemoteWithMainBranch(t *testing.T, tmp string) string {
 
 	return remote
 }
+
+func TestExcludeLocally(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	tmp := t.TempDir()
+	remote := createRemoteWithMainBranch(t, tmp)
+	destPath := filepath.Join(tmp, "job")
+	if err := CloneForJob(ctx, remote, "", destPath, "autopr/job-1", "main"); err != nil {
+		t.Fatalf("clone for job: %v", err)
+	}
+
+	for range 2 {
+		if err := ExcludeLocally(ctx, destPath, "/node_modules"); err != nil {
+			t.Fatalf("exclude: %v", err)
+		}
+	}
+	data, err := os.ReadFile(filepath.Join(destPath, ".git", "info", "exclude"))
+	if err != nil {
+		t.Fatalf("read exclude: %v", err)
+	}
+	if strings.Count(string(data), "/node_modules\n") != 1 {
+		t.Fatalf("expected one exclude entry, got:\n%s", data)
+	}
+
+	if err := os.Symlink(tmp, filepath.Join(destPath, "node_modules")); err != nil {
+		t.Fatalf("symlink: %v", err)
+	}
+	status, err := runGitOutput(ctx, destPath, "status", "--porcelain")
+	if err != nil {
+		t.Fatalf("git status: %v", err)
+	}
+	if strings.TrimSpace(status) != "" {
+		t.Fatalf("expected excluded link to be ignored, status:\n%s", status)
+	}
+}
@@ -0,0 +1,55 @@
+// Package githubapp resolves the GitHub credential for a project.
+//
+// Projects configured as a GitHub App (per project or via [github_app]) use
+// short-lived installation tokens for every GitHub API call and git push, so
+// actions are attributed to the App, use its rate limits, and carry only the
+// permissions granted to the installation. Other projects use the configured
+// github token.
+package githubapp
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"autopr/internal/config"
+	"autopr/internal/git"
+)
+
+// apps caches one *git.GitHubApp per App and host so installation tokens and
+// installation lookups are shared across jobs and packages.
+var apps sync.Map
+
+// Token returns the token for GitHub API calls on proj: an installation token
+// for GitHub App projects, otherwise cfg.Tokens.GitHub (which may be empty).
+func Token(ctx context.Context, cfg *config.Config, proj *config.ProjectConfig) (string, error) {
+	if proj == nil || !proj.GitHub.UsesApp() {
+		return cfg.Tokens.GitHub, nil
+	}
+	gh := proj.GitHub
+	app, err := appFor(gh)
+	if err != nil {
+		return "", err
+	}
+	installationID := gh.AppInstallationID
+	if installationID == 0 {
+		installationID, err = app.RepoInstallationID(ctx, gh.Owner, gh.Repo)
+		if err != nil {
+			return "", err
+		}
+	}
+	return app.InstallationToken(ctx, installationID)
+}
+
+func appFor(gh *config.ProjectGitHub) (*git.GitHubApp, error) {
+	key := fmt.Sprintf("%s|%d|%s", gh.BaseURL, gh.AppID, gh.AppPrivateKeyPath)
+	if app, ok := apps.Load(key); ok {
+		return app.(*git.GitHubApp), nil
+	}
+	app, err := git.NewGitHubApp(gh.BaseURL, gh.AppID, gh.AppPrivateKeyPath)
+	if err != nil {
+		return nil, err
+	}
+	actual, _ := apps.LoadOrStore(key, app)
+	return actual.(*git.GitHubApp), nil
+}
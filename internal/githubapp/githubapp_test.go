@@ -0,0 +1,85 @@
+package githubapp
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"autopr/internal/config"
+)
+
+func TestTokenUsesConfiguredTokenWithoutApp(t *testing.T) {
+	t.Parallel()
+
+	cfg := &config.Config{Tokens: config.TokensConfig{GitHub: "ghp_pat"}}
+	proj := &config.ProjectConfig{Name: "p", GitHub: &config.ProjectGitHub{Owner: "org", Repo: "repo"}}
+	token, err := Token(context.Background(), cfg, proj)
+	if err != nil {
+		t.Fatalf("token: %v", err)
+	}
+	if token != "ghp_pat" {
+		t.Fatalf("expected configured token, got %q", token)
+	}
+}
+
+func TestTokenMintsInstallationTokenForApp(t *testing.T) {
+	t.Parallel()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	keyPath := filepath.Join(t.TempDir(), "app.pem")
+	pemBytes := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	if err := os.WriteFile(keyPath, pemBytes, 0o600); err != nil {
+		t.Fatalf("write key: %v", err)
+	}
+
+	var lookups, mints atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/api/v3/repos/org/repo/installation":
+			lookups.Add(1)
+			_, _ = io.WriteString(w, `{"id":42}`)
+		case r.Method == "POST" && r.URL.Path == "/api/v3/app/installations/42/access_tokens":
+			mints.Add(1)
+			w.WriteHeader(http.StatusCreated)
+			_, _ = io.WriteString(w, `{"token":"ghs_installation","expires_at":"`+time.Now().Add(time.Hour).UTC().Format(time.RFC3339)+`"}`)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	cfg := &config.Config{Tokens: config.TokensConfig{GitHub: "ghp_pat"}}
+	proj := &config.ProjectConfig{Name: "p", GitHub: &config.ProjectGitHub{
+		Owner:             "org",
+		Repo:              "repo",
+		BaseURL:           srv.URL,
+		AppID:             7,
+		AppPrivateKeyPath: keyPath,
+	}}
+	for range 2 {
+		token, err := Token(context.Background(), cfg, proj)
+		if err != nil {
+			t.Fatalf("token: %v", err)
+		}
+		if token != "ghs_installation" {
+			t.Fatalf("expected installation token, got %q", token)
+		}
+	}
+	if lookups.Load() != 1 || mints.Load() != 1 {
+		t.Fatalf("expected one lookup and one mint, got %d and %d", lookups.Load(), mints.Load())
+	}
+}
@@ -0,0 +1,55 @@
+package httputil
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// HostLimiter spaces requests to each host evenly, so many callers sharing
+// one API host stay under a request rate without slowing other hosts.
+type HostLimiter struct {
+	interval time.Duration
+
+	mu   sync.Mutex
+	next map[string]time.Time // earliest time of the next request per host
+}
+
+// NewHostLimiter allows rps requests per second to each host. rps <= 0
+// disables limiting.
+func NewHostLimiter(rps float64) *HostLimiter {
+	l := &HostLimiter{next: make(map[string]time.Time)}
+	if rps > 0 {
+		l.interval = time.Duration(float64(time.Second) / rps)
+	}
+	return l
+}
+
+// Wait blocks until a request to host may be sent, or ctx is done.
+func (l *HostLimiter) Wait(ctx context.Context, host string) error {
+	if l == nil || l.interval <= 0 {
+		return nil
+	}
+	l.mu.Lock()
+	now := time.Now()
+	at := l.next[host]
+	if at.Before(now) {
+		at = now
+	}
+	l.next[host] = at.Add(l.interval)
+	l.mu.Unlock()
+	return sleepWithContext(ctx, time.Until(at))
+}
+
+type hostLimiterKey struct{}
+
+// WithHostLimiter returns a context under which Do waits on l before each
+// request attempt.
+func WithHostLimiter(ctx context.Context, l *HostLimiter) context.Context {
+	return context.WithValue(ctx, hostLimiterKey{}, l)
+}
+
+func hostLimiterFrom(ctx context.Context) *HostLimiter {
+	l, _ := ctx.Value(hostLimiterKey{}).(*HostLimiter)
+	return l
+}
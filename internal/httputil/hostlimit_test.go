@@ -0,0 +1,44 @@
+package httputil
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestHostLimiterSpacesRequestsPerHost(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	l := NewHostLimiter(20) // one request per 50ms
+
+	start := time.Now()
+	for range 3 {
+		if err := l.Wait(ctx, "gitlab.example.com"); err != nil {
+			t.Fatalf("wait: %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Fatalf("expected three requests to one host to take ~100ms, took %s", elapsed)
+	}
+
+	// Another host is not held up by the first.
+	start = time.Now()
+	if err := l.Wait(ctx, "api.github.com"); err != nil {
+		t.Fatalf("wait: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 20*time.Millisecond {
+		t.Fatalf("expected first request to another host to go at once, took %s", elapsed)
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	_ = l.Wait(ctx, "slow.example.com")
+	if err := l.Wait(cancelled, "slow.example.com"); err == nil {
+		t.Fatal("expected cancelled wait to fail")
+	}
+
+	var disabled *HostLimiter
+	if err := disabled.Wait(ctx, "any"); err != nil {
+		t.Fatalf("nil limiter should not wait: %v", err)
+	}
+}
@@ -0,0 +1,98 @@
+package httputil
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+
+	"golang.org/x/net/http/httpproxy"
+)
+
+// NetworkConfig holds outbound proxy and CA settings. Empty fields keep
+// whatever the environment already provides (HTTPS_PROXY etc.).
+type NetworkConfig struct {
+	HTTPProxy  string
+	HTTPSProxy string
+	NoProxy    string
+	CABundle   string // PEM file trusted in addition to the system roots
+}
+
+// ApplyNetwork routes every HTTP client in the process that uses
+// http.DefaultTransport (including http.DefaultClient) through the configured
+// proxy and CA bundle, and exports the matching environment variables so git
+// and LLM provider subprocesses use them too.
+func ApplyNetwork(n NetworkConfig) error {
+	transport, err := newTransport(n)
+	if err != nil {
+		return err
+	}
+	for _, kv := range networkEnv(n) {
+		if err := os.Setenv(kv[0], kv[1]); err != nil {
+			return fmt.Errorf("set %s: %w", kv[0], err)
+		}
+	}
+	http.DefaultTransport = transport
+	return nil
+}
+
+func newTransport(n NetworkConfig) (*http.Transport, error) {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+
+	proxyCfg := httpproxy.FromEnvironment()
+	if n.HTTPProxy != "" {
+		proxyCfg.HTTPProxy = n.HTTPProxy
+	}
+	if n.HTTPSProxy != "" {
+		proxyCfg.HTTPSProxy = n.HTTPSProxy
+	}
+	if n.NoProxy != "" {
+		proxyCfg.NoProxy = n.NoProxy
+	}
+	proxyFunc := proxyCfg.ProxyFunc()
+	t.Proxy = func(req *http.Request) (*url.URL, error) {
+		return proxyFunc(req.URL)
+	}
+
+	if n.CABundle != "" {
+		pemBytes, err := os.ReadFile(n.CABundle)
+		if err != nil {
+			return nil, fmt.Errorf("read ca bundle: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pemBytes) {
+			return nil, fmt.Errorf("ca bundle %s: no PEM certificates found", n.CABundle)
+		}
+		if t.TLSClientConfig == nil {
+			t.TLSClientConfig = &tls.Config{}
+		}
+		t.TLSClientConfig.RootCAs = pool
+	}
+	return t, nil
+}
+
+// networkEnv returns the environment variables subprocesses read for the
+// configured settings. Proxies are exported in both cases since tools differ
+// in which they honour. The CA bundle is exported for OpenSSL-based tools and
+// git (which use it instead of the system roots) and for Node (which adds it).
+func networkEnv(n NetworkConfig) [][2]string {
+	var env [][2]string
+	add := func(value string, names ...string) {
+		if value == "" {
+			return
+		}
+		for _, name := range names {
+			env = append(env, [2]string{name, value})
+		}
+	}
+	add(n.HTTPProxy, "HTTP_PROXY", "http_proxy")
+	add(n.HTTPSProxy, "HTTPS_PROXY", "https_proxy")
+	add(n.NoProxy, "NO_PROXY", "no_proxy")
+	add(n.CABundle, "SSL_CERT_FILE", "GIT_SSL_CAINFO", "NODE_EXTRA_CA_CERTS")
+	return env
+}
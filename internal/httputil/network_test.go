@@ -0,0 +1,115 @@
+package httputil
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNewTransportUsesConfiguredProxy(t *testing.T) {
+	t.Parallel()
+
+	tr, err := newTransport(NetworkConfig{
+		HTTPProxy:  "http://proxy.corp:3128",
+		HTTPSProxy: "http://secure-proxy.corp:3129",
+		NoProxy:    "gitlab.internal",
+	})
+	if err != nil {
+		t.Fatalf("new transport: %v", err)
+	}
+
+	cases := map[string]string{
+		"http://example.com/api":      "http://proxy.corp:3128",
+		"https://api.github.com/x":    "http://secure-proxy.corp:3129",
+		"https://gitlab.internal/api": "",
+	}
+	for target, want := range cases {
+		req, _ := http.NewRequest("GET", target, nil)
+		got, err := tr.Proxy(req)
+		if err != nil {
+			t.Fatalf("proxy for %s: %v", target, err)
+		}
+		gotStr := ""
+		if got != nil {
+			gotStr = got.String()
+		}
+		if gotStr != want {
+			t.Errorf("proxy for %s = %q, want %q", target, gotStr, want)
+		}
+	}
+}
+
+func TestNewTransportTrustsCABundle(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	caPath := filepath.Join(t.TempDir(), "ca.pem")
+	pemBytes := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	if err := os.WriteFile(caPath, pemBytes, 0o600); err != nil {
+		t.Fatalf("write ca: %v", err)
+	}
+
+	tr, err := newTransport(NetworkConfig{CABundle: caPath})
+	if err != nil {
+		t.Fatalf("new transport: %v", err)
+	}
+	resp, err := (&http.Client{Transport: tr}).Get(srv.URL)
+	if err != nil {
+		t.Fatalf("expected custom CA to be trusted: %v", err)
+	}
+	resp.Body.Close()
+
+	// Without the bundle the test server's certificate is rejected.
+	plain, err := newTransport(NetworkConfig{})
+	if err != nil {
+		t.Fatalf("new transport: %v", err)
+	}
+	if _, err := (&http.Client{Transport: plain}).Get(srv.URL); err == nil || !strings.Contains(err.Error(), "certificate") {
+		t.Fatalf("expected unknown CA to be rejected, got %v", err)
+	}
+}
+
+func TestNewTransportRejectsInvalidCABundle(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(path, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatalf("write ca: %v", err)
+	}
+	if _, err := newTransport(NetworkConfig{CABundle: path}); err == nil || !strings.Contains(err.Error(), "no PEM certificates") {
+		t.Fatalf("expected invalid bundle error, got %v", err)
+	}
+}
+
+func TestNetworkEnv(t *testing.T) {
+	t.Parallel()
+
+	env := networkEnv(NetworkConfig{HTTPSProxy: "http://proxy:3128", CABundle: "/etc/corp-ca.pem"})
+	got := map[string]string{}
+	for _, kv := range env {
+		got[kv[0]] = kv[1]
+	}
+	want := map[string]string{
+		"HTTPS_PROXY":         "http://proxy:3128",
+		"https_proxy":         "http://proxy:3128",
+		"SSL_CERT_FILE":       "/etc/corp-ca.pem",
+		"GIT_SSL_CAINFO":      "/etc/corp-ca.pem",
+		"NODE_EXTRA_CA_CERTS": "/etc/corp-ca.pem",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("unexpected env %v", got)
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("%s = %q, want %q", k, got[k], v)
+		}
+	}
+}
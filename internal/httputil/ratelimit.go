@@ -0,0 +1,114 @@
+package httputil
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RateLimit is the most recent API rate limit reported by a host.
+type RateLimit struct {
+	Host       string
+	Resource   string // GitHub's X-RateLimit-Resource (e.g. "core"); empty for GitLab
+	Limit      int
+	Remaining  int
+	ResetAt    time.Time
+	ObservedAt time.Time
+}
+
+var (
+	rateLimitsMu sync.Mutex
+	rateLimits   = map[string]RateLimit{}
+)
+
+// recordRateLimit stores the rate limit headers of resp, if any. GitHub sends
+// X-RateLimit-*; GitLab sends RateLimit-*.
+func recordRateLimit(resp *http.Response) {
+	if resp == nil || resp.Request == nil || resp.Request.URL == nil {
+		return
+	}
+	rl, ok := parseRateLimit(resp.Header)
+	if !ok {
+		return
+	}
+	rl.Host = resp.Request.URL.Host
+	rl.ObservedAt = time.Now().UTC()
+
+	rateLimitsMu.Lock()
+	rateLimits[rl.Host+"|"+rl.Resource] = rl
+	rateLimitsMu.Unlock()
+}
+
+func parseRateLimit(h http.Header) (RateLimit, bool) {
+	for _, prefix := range []string{"X-RateLimit-", "RateLimit-"} {
+		limit, err1 := strconv.Atoi(strings.TrimSpace(h.Get(prefix + "Limit")))
+		remaining, err2 := strconv.Atoi(strings.TrimSpace(h.Get(prefix + "Remaining")))
+		if err1 != nil || err2 != nil || limit <= 0 {
+			continue
+		}
+		rl := RateLimit{
+			Resource:  strings.TrimSpace(h.Get(prefix + "Resource")),
+			Limit:     limit,
+			Remaining: max(remaining, 0),
+		}
+		if reset, err := strconv.ParseInt(strings.TrimSpace(h.Get(prefix+"Reset")), 10, 64); err == nil && reset > 0 {
+			rl.ResetAt = time.Unix(reset, 0).UTC()
+		}
+		return rl, true
+	}
+	return RateLimit{}, false
+}
+
+// RateLimits returns the latest rate limit seen per host and resource, sorted
+// by host then resource.
+func RateLimits() []RateLimit {
+	rateLimitsMu.Lock()
+	out := make([]RateLimit, 0, len(rateLimits))
+	for _, rl := range rateLimits {
+		out = append(out, rl)
+	}
+	rateLimitsMu.Unlock()
+
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Host != out[j].Host {
+			return out[i].Host < out[j].Host
+		}
+		return out[i].Resource < out[j].Resource
+	})
+	return out
+}
+
+// maxAdaptiveInterval caps how far AdaptiveInterval stretches a poll interval.
+const maxAdaptiveInterval = time.Hour
+
+// AdaptiveInterval stretches a polling interval when any API is close to its
+// rate limit: 2x below 20% remaining, 4x below 10%, and until the reset below
+// 5%. Limits whose reset time has passed are ignored.
+func AdaptiveInterval(base time.Duration, limits []RateLimit, now time.Time) time.Duration {
+	interval := base
+	for _, rl := range limits {
+		if rl.Limit <= 0 || (!rl.ResetAt.IsZero() && !rl.ResetAt.After(now)) {
+			continue
+		}
+		frac := float64(rl.Remaining) / float64(rl.Limit)
+		var next time.Duration
+		switch {
+		case frac < 0.05:
+			next = base * 4
+			if !rl.ResetAt.IsZero() {
+				next = max(base, rl.ResetAt.Sub(now))
+			}
+		case frac < 0.10:
+			next = base * 4
+		case frac < 0.20:
+			next = base * 2
+		default:
+			continue
+		}
+		interval = max(interval, next)
+	}
+	return min(interval, max(base, maxAdaptiveInterval))
+}
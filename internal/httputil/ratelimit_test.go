@@ -0,0 +1,96 @@
+package httputil
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestDoRecordsRateLimitHeaders(t *testing.T) {
+	t.Parallel()
+
+	reset := time.Now().Add(30 * time.Minute).Unix()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Limit", "5000")
+		w.Header().Set("X-RateLimit-Remaining", "4321")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset, 10))
+		w.Header().Set("X-RateLimit-Resource", "core")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	resp, err := Do(context.Background(), func() (*http.Request, error) {
+		return http.NewRequest("GET", srv.URL, nil)
+	}, DefaultRetryConfig())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	u, _ := url.Parse(srv.URL)
+	for _, rl := range RateLimits() {
+		if rl.Host != u.Host {
+			continue
+		}
+		if rl.Resource != "core" || rl.Limit != 5000 || rl.Remaining != 4321 || rl.ResetAt.Unix() != reset {
+			t.Fatalf("unexpected rate limit: %+v", rl)
+		}
+		return
+	}
+	t.Fatalf("rate limit for %s not recorded", u.Host)
+}
+
+func TestParseRateLimitGitLabHeaders(t *testing.T) {
+	t.Parallel()
+
+	h := http.Header{}
+	h.Set("RateLimit-Limit", "2000")
+	h.Set("RateLimit-Remaining", "10")
+	h.Set("RateLimit-Reset", "1767225600")
+	rl, ok := parseRateLimit(h)
+	if !ok {
+		t.Fatal("expected GitLab headers to parse")
+	}
+	if rl.Limit != 2000 || rl.Remaining != 10 || rl.Resource != "" || !rl.ResetAt.Equal(time.Unix(1767225600, 0)) {
+		t.Fatalf("unexpected rate limit: %+v", rl)
+	}
+
+	if _, ok := parseRateLimit(http.Header{}); ok {
+		t.Fatal("expected no rate limit without headers")
+	}
+}
+
+func TestAdaptiveInterval(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	base := time.Minute
+	limit := func(remaining int, reset time.Time) []RateLimit {
+		return []RateLimit{{Host: "api.github.com", Limit: 1000, Remaining: remaining, ResetAt: reset}}
+	}
+	soon := now.Add(20 * time.Minute)
+
+	cases := []struct {
+		name   string
+		limits []RateLimit
+		want   time.Duration
+	}{
+		{"no limits", nil, base},
+		{"plenty left", limit(900, soon), base},
+		{"below 20%", limit(150, soon), 2 * base},
+		{"below 10%", limit(80, soon), 4 * base},
+		{"nearly exhausted waits for reset", limit(10, soon), 20 * time.Minute},
+		{"exhausted without reset", limit(0, time.Time{}), 4 * base},
+		{"reset already passed", limit(0, now.Add(-time.Second)), base},
+		{"capped at an hour", limit(0, now.Add(3*time.Hour)), time.Hour},
+	}
+	for _, tc := range cases {
+		if got := AdaptiveInterval(base, tc.limits, now); got != tc.want {
+			t.Errorf("%s: got %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
@@ -10,6 +10,10 @@ import (
 	"strconv"
 	"strings"
 	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"autopr/internal/tracing"
 )
 
 // RetryConfig controls the retry behavior.
@@ -35,7 +39,10 @@ func DefaultRetryConfig() RetryConfig {
 //
 // Retries on: network errors, HTTP 429, HTTP 5xx.
 // Fails fast on 4xx (non-429) — the response is returned with body intact.
-func Do(ctx context.Context, buildReq func() (*http.Request, error), cfg RetryConfig) (*http.Response, error) {
+func Do(ctx context.Context, buildReq func() (*http.Request, error), cfg RetryConfig) (_ *http.Response, err error) {
+	ctx, span := tracing.Start(ctx, "HTTP")
+	defer func() { tracing.End(span, err) }()
+
 	var lastErr error
 
 	for attempt := range cfg.MaxAttempts {
@@ -43,8 +50,25 @@ func Do(ctx context.Context, buildReq func() (*http.Request, error), cfg RetryCo
 		if err != nil {
 			return nil, fmt.Errorf("build request: %w", err)
 		}
+		if attempt == 0 {
+			// Record only host and path: query strings can carry tokens.
+			span.SetName("HTTP " + req.Method)
+			span.SetAttributes(
+				attribute.String("http.request.method", req.Method),
+				attribute.String("server.address", req.URL.Host),
+				attribute.String("url.path", req.URL.Path),
+			)
+		}
+		span.SetAttributes(attribute.Int("http.attempts", attempt+1))
 
+		if err := hostLimiterFrom(ctx).Wait(ctx, req.URL.Host); err != nil {
+			return nil, err
+		}
 		resp, err := http.DefaultClient.Do(req)
+		recordRateLimit(resp)
+		if resp != nil {
+			span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+		}
 		if err != nil {
 			lastErr = err
 			if attempt < cfg.MaxAttempts-1 {
@@ -0,0 +1,187 @@
+// Package ics renders AutoPR job activity and upcoming recurring-task runs as
+// an iCalendar (RFC 5545) feed, so they can be followed in calendar tools.
+package ics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"autopr/internal/db"
+	"autopr/internal/recurring"
+)
+
+// timeLayout is the UTC DATE-TIME form.
+const timeLayout = "20060102T150405Z"
+
+// maxLineOctets is the longest content line before it is folded.
+const maxLineOctets = 75
+
+// Event is one calendar entry. A zero End makes it a point in time.
+type Event struct {
+	UID         string
+	Summary     string
+	Description string
+	URL         string
+	Start       time.Time
+	End         time.Time
+	Tentative   bool // a planned run that may yet be skipped
+}
+
+// JobEvents turns job activity into events at or after since: one spanning
+// each job's run, ending in its outcome, and one when its PR merged.
+func JobEvents(jobs []db.JobActivity, since time.Time) []Event {
+	var out []Event
+	for _, j := range jobs {
+		title := fmt.Sprintf("%s (%s)", j.IssueTitle, j.ProjectName)
+		detail := "Job " + db.ShortID(j.JobID) + ", " + j.State
+		if j.IssueURL != "" {
+			detail += "\nIssue: " + j.IssueURL
+		}
+		if j.PRURL != "" {
+			detail += "\nPR: " + j.PRURL
+		}
+		url := j.PRURL
+		if url == "" {
+			url = j.IssueURL
+		}
+
+		if end, ok := parseTime(j.CompletedAt); ok && !end.Before(since) {
+			start, ok := parseTime(j.StartedAt)
+			if !ok || start.After(end) {
+				start = end
+			}
+			out = append(out, Event{
+				UID:         j.JobID + "-run@autopr",
+				Summary:     outcome(j) + ": " + title,
+				Description: detail,
+				URL:         url,
+				Start:       start,
+				End:         end,
+			})
+		}
+		if merged, ok := parseTime(j.PRMergedAt); ok && !merged.Before(since) {
+			out = append(out, Event{
+				UID:         j.JobID + "-merged@autopr",
+				Summary:     "Merged: " + title,
+				Description: detail,
+				URL:         url,
+				Start:       merged,
+			})
+		}
+	}
+	return out
+}
+
+// outcome names how a job's run ended.
+func outcome(j db.JobActivity) string {
+	switch j.State {
+	case "approved":
+		if j.PRURL != "" {
+			return "PR opened"
+		}
+		return "Approved"
+	case "ready":
+		return "Ready for PR"
+	case "needs_review_oversize":
+		return "Oversize diff"
+	case "rejected":
+		return "Rejected"
+	case "failed":
+		return "Failed"
+	case "cancelled":
+		return "Cancelled"
+	default:
+		return "Ran"
+	}
+}
+
+// ScheduleEvents turns upcoming recurring-task runs into tentative events.
+func ScheduleEvents(runs []recurring.Activation) []Event {
+	out := make([]Event, 0, len(runs))
+	for _, r := range runs {
+		out = append(out, Event{
+			UID:         fmt.Sprintf("recurring-%s-%s-%s@autopr", r.Project, r.Task.Name, r.At.UTC().Format("20060102T1504")),
+			Summary:     fmt.Sprintf("Scheduled: %s (%s)", r.Task.Title, r.Project),
+			Description: fmt.Sprintf("Recurring task %s, schedule %s. Skipped if the previous run's job is still open.", r.Task.Name, r.Task.Schedule),
+			Start:       r.At,
+			Tentative:   true,
+		})
+	}
+	return out
+}
+
+// Write encodes events as a VCALENDAR stamped with now.
+func Write(w io.Writer, events []Event, now time.Time) error {
+	bw := bufio.NewWriter(w)
+	line := func(name, value string) {
+		writeFolded(bw, name+":"+value)
+	}
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", "-//AutoPR//ap export-ics//EN")
+	line("CALSCALE", "GREGORIAN")
+	line("METHOD", "PUBLISH")
+	line("X-WR-CALNAME", "AutoPR")
+	stamp := now.UTC().Format(timeLayout)
+	for _, e := range events {
+		line("BEGIN", "VEVENT")
+		line("UID", escapeText(e.UID))
+		line("DTSTAMP", stamp)
+		line("DTSTART", e.Start.UTC().Format(timeLayout))
+		if !e.End.IsZero() && e.End.After(e.Start) {
+			line("DTEND", e.End.UTC().Format(timeLayout))
+		}
+		line("SUMMARY", escapeText(e.Summary))
+		if e.Description != "" {
+			line("DESCRIPTION", escapeText(e.Description))
+		}
+		if e.URL != "" {
+			line("URL", e.URL)
+		}
+		if e.Tentative {
+			line("STATUS", "TENTATIVE")
+		} else {
+			line("STATUS", "CONFIRMED")
+		}
+		line("END", "VEVENT")
+	}
+	line("END", "VCALENDAR")
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("write calendar: %w", err)
+	}
+	return nil
+}
+
+// escapeText escapes a TEXT value: backslashes, commas, semicolons, and
+// newlines.
+func escapeText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ",", `\,`, ";", `\;`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`).Replace(s)
+}
+
+// writeFolded writes a content line with CRLF, folding it onto continuation
+// lines that start with a space so no line exceeds maxLineOctets, without
+// splitting a UTF-8 sequence.
+func writeFolded(w *bufio.Writer, s string) {
+	limit := maxLineOctets
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		w.WriteString(s[:cut])
+		w.WriteString("\r\n ")
+		s = s[cut:]
+		limit = maxLineOctets - 1
+	}
+	w.WriteString(s)
+	w.WriteString("\r\n")
+}
+
+func parseTime(s string) (time.Time, bool) {
+	t, err := time.Parse(time.RFC3339, s)
+	return t, err == nil
+}
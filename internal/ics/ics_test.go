@@ -0,0 +1,112 @@
+package ics
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"autopr/internal/config"
+	"autopr/internal/db"
+	"autopr/internal/recurring"
+)
+
+func TestJobEvents(t *testing.T) {
+	t.Parallel()
+
+	since := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	events := JobEvents([]db.JobActivity{
+		{
+			JobID: "ap-job-1111111111111111", ProjectName: "web", IssueTitle: "Fix login", State: "approved",
+			IssueURL:  "https://github.com/acme/web/issues/1",
+			StartedAt: "2026-03-02T10:00:00Z", CompletedAt: "2026-03-02T10:40:00Z",
+			PRURL: "https://github.com/acme/web/pull/9", PRMergedAt: "2026-03-03T08:00:00Z",
+		},
+		{
+			// Ran before the window; only the merge is recent.
+			JobID: "ap-job-2222222222222222", ProjectName: "web", IssueTitle: "Old fix", State: "approved",
+			StartedAt: "2026-02-20T10:00:00Z", CompletedAt: "2026-02-20T11:00:00Z",
+			PRURL: "https://github.com/acme/web/pull/7", PRMergedAt: "2026-03-01T09:00:00Z",
+		},
+		{
+			JobID: "ap-job-3333333333333333", ProjectName: "api", IssueTitle: "Flaky test", State: "failed",
+			StartedAt: "2026-03-04T10:00:00Z", CompletedAt: "2026-03-04T10:05:00Z",
+		},
+	}, since)
+
+	var got []string
+	for _, e := range events {
+		got = append(got, e.UID+" "+e.Summary)
+	}
+	want := []string{
+		"ap-job-1111111111111111-run@autopr PR opened: Fix login (web)",
+		"ap-job-1111111111111111-merged@autopr Merged: Fix login (web)",
+		"ap-job-2222222222222222-merged@autopr Merged: Old fix (web)",
+		"ap-job-3333333333333333-run@autopr Failed: Flaky test (api)",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("got\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if run := events[0]; !run.Start.Equal(time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)) || run.End.Sub(run.Start) != 40*time.Minute ||
+		run.URL != "https://github.com/acme/web/pull/9" || !strings.Contains(run.Description, "Issue: https://github.com/acme/web/issues/1") {
+		t.Fatalf("unexpected run event %+v", run)
+	}
+}
+
+func TestWriteEscapesAndFolds(t *testing.T) {
+	t.Parallel()
+
+	at := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	events := ScheduleEvents([]recurring.Activation{{
+		Project: "web",
+		Task:    config.ProjectRecurringTask{Name: "deps", Schedule: "0 9 * * 1", Title: "Bump deps; upgrade Go, Node"},
+		At:      at,
+	}})
+	events = append(events, Event{
+		UID:         "ap-job-1-run@autopr",
+		Summary:     "Ready for PR: " + strings.Repeat("ü", 60),
+		Description: "line one\nline two",
+		Start:       at,
+		End:         at.Add(time.Hour),
+	})
+
+	var b strings.Builder
+	if err := Write(&b, events, at); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	out := b.String()
+	if !strings.HasPrefix(out, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n") || !strings.HasSuffix(out, "END:VCALENDAR\r\n") {
+		t.Fatalf("unexpected calendar framing:\n%s", out)
+	}
+	for _, want := range []string{
+		"UID:recurring-web-deps-20260302T0900@autopr\r\n",
+		"SUMMARY:Scheduled: Bump deps\\; upgrade Go\\, Node (web)\r\n",
+		"STATUS:TENTATIVE\r\n",
+		"DTSTART:20260302T090000Z\r\nSUMMARY:",
+		"DTEND:20260302T100000Z\r\n",
+		"DESCRIPTION:line one\\nline two\r\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in:\n%s", want, out)
+		}
+	}
+
+	lines := strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n")
+	var summary string
+	for i, line := range lines {
+		if len(line) > maxLineOctets {
+			t.Fatalf("line %d is %d octets: %q", i, len(line), line)
+		}
+		if strings.HasPrefix(line, "SUMMARY:Ready") {
+			summary = line
+			for _, cont := range lines[i+1:] {
+				if !strings.HasPrefix(cont, " ") {
+					break
+				}
+				summary += cont[1:]
+			}
+		}
+	}
+	if summary != "SUMMARY:Ready for PR: "+strings.Repeat("ü", 60) {
+		t.Fatalf("folded summary did not unfold intact: %q", summary)
+	}
+}
@@ -0,0 +1,65 @@
+// Package idle tracks the daemon's low-power idle mode, entered once
+// [daemon] idle_after passes without a queued or running job. While idle the
+// workers are stopped and issue sync and PR checks run at idle_sync_interval;
+// a queued job or a wake request from the TUI ends it at once.
+package idle
+
+import "sync"
+
+// Mode is whether the daemon is idle. A nil Mode is never idle, for daemons
+// without an idle policy.
+type Mode struct {
+	mu    sync.Mutex
+	idle  bool
+	woken chan struct{} // closed on the next wake
+}
+
+func NewMode() *Mode {
+	return &Mode{woken: make(chan struct{})}
+}
+
+// Idle reports whether the daemon is idle.
+func (m *Mode) Idle() bool {
+	if m == nil {
+		return false
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.idle
+}
+
+// Woken returns a channel that is closed when the daemon next wakes from
+// idle. For a nil Mode it is nil, which never receives.
+func (m *Mode) Woken() <-chan struct{} {
+	if m == nil {
+		return nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.woken
+}
+
+// Sleep enters idle mode. It reports false when the daemon was idle already.
+func (m *Mode) Sleep() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.idle {
+		return false
+	}
+	m.idle = true
+	return true
+}
+
+// Wake leaves idle mode, releasing everything waiting on Woken. It reports
+// false when the daemon was awake already.
+func (m *Mode) Wake() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.idle {
+		return false
+	}
+	m.idle = false
+	close(m.woken)
+	m.woken = make(chan struct{})
+	return true
+}
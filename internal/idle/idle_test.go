@@ -0,0 +1,39 @@
+package idle
+
+import "testing"
+
+func TestModeWakeReleasesWaiters(t *testing.T) {
+	t.Parallel()
+
+	var none *Mode
+	if none.Idle() || none.Woken() != nil {
+		t.Fatal("expected a nil mode never to be idle")
+	}
+
+	m := NewMode()
+	woken := m.Woken()
+	if m.Wake() {
+		t.Fatal("expected waking an awake daemon to do nothing")
+	}
+	if !m.Sleep() || !m.Idle() || m.Sleep() {
+		t.Fatal("expected the first sleep to enter idle mode")
+	}
+	select {
+	case <-woken:
+		t.Fatal("woken before the daemon woke")
+	default:
+	}
+	if !m.Wake() || m.Idle() {
+		t.Fatal("expected wake to leave idle mode")
+	}
+	select {
+	case <-woken:
+	default:
+		t.Fatal("expected waiters released on wake")
+	}
+	select {
+	case <-m.Woken():
+		t.Fatal("expected a fresh channel for the next wake")
+	default:
+	}
+}
@@ -0,0 +1,122 @@
+// Package issuecomments keeps a copy of the discussion on source issues.
+//
+// For projects with [projects.issue_comments], the sync loop fetches the
+// comments of each eligible open issue whenever the issue changed on its
+// source, and the pipeline fetches them again before planning, so a retried
+// job sees clarifications added after it was first queued. The plan step
+// adds the stored thread to its prompt.
+package issuecomments
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	"autopr/internal/config"
+	"autopr/internal/db"
+	"autopr/internal/git"
+	"autopr/internal/githubapp"
+)
+
+// maxComments is how many of an issue's comments are stored: the newest
+// ones, as the plan prompt keeps the newest that fit anyway.
+const maxComments = 100
+
+// Fetcher lists issue comments on GitHub, GitLab, and Gitea and stores them.
+type Fetcher struct {
+	cfg   *config.Config
+	store *db.Store
+
+	listGitHubComments func(ctx context.Context, token, baseURL, owner, repo, number string) ([]git.IssueComment, error)
+	listGitLabNotes    func(ctx context.Context, token, baseURL, projectID, iid string) ([]git.IssueComment, error)
+	listGiteaComments  func(ctx context.Context, token, baseURL, owner, repo, index string) ([]git.IssueComment, error)
+}
+
+func New(cfg *config.Config, store *db.Store) *Fetcher {
+	return &Fetcher{
+		cfg:                cfg,
+		store:              store,
+		listGitHubComments: git.ListGitHubIssueComments,
+		listGitLabNotes:    git.ListGitLabIssueNotes,
+		listGiteaComments:  git.ListGiteaIssueComments,
+	}
+}
+
+// Supported reports whether issues from source have comments to fetch.
+func Supported(source string) bool {
+	return source == "github" || source == "gitlab" || source == "gitea"
+}
+
+// List fetches the comments of issue from its source, oldest first.
+func (f *Fetcher) List(ctx context.Context, issue db.Issue) ([]git.IssueComment, error) {
+	p, ok := f.cfg.ProjectByName(issue.ProjectName)
+	if !ok {
+		return nil, fmt.Errorf("project %q not found in config", issue.ProjectName)
+	}
+	switch {
+	case issue.Source == "github" && p.GitHub != nil:
+		token, err := githubapp.Token(ctx, f.cfg, p)
+		if err != nil {
+			return nil, err
+		}
+		if token == "" {
+			return nil, fmt.Errorf("no GitHub token configured")
+		}
+		return f.listGitHubComments(ctx, token, p.GitHub.BaseURL, p.GitHub.Owner, p.GitHub.Repo, issue.SourceIssueID)
+	case issue.Source == "gitlab" && p.GitLab != nil:
+		if f.cfg.Tokens.GitLab == "" {
+			return nil, fmt.Errorf("no GitLab token configured")
+		}
+		return f.listGitLabNotes(ctx, f.cfg.Tokens.GitLab, p.GitLab.BaseURL, p.GitLab.ProjectID, issue.SourceIssueID)
+	case issue.Source == "gitea" && p.Gitea != nil:
+		if f.cfg.Tokens.Gitea == "" {
+			return nil, fmt.Errorf("no Gitea token configured")
+		}
+		return f.listGiteaComments(ctx, f.cfg.Tokens.Gitea, p.Gitea.BaseURL, p.Gitea.Owner, p.Gitea.Repo, issue.SourceIssueID)
+	}
+	return nil, fmt.Errorf("project %q has no %s source configured", issue.ProjectName, issue.Source)
+}
+
+// Refresh fetches the comments of issue and stores them in place of the
+// ones synced before. Issues of projects without [projects.issue_comments],
+// and of sources without comments, are left alone.
+func (f *Fetcher) Refresh(ctx context.Context, issue db.Issue) error {
+	p, ok := f.cfg.ProjectByName(issue.ProjectName)
+	if !ok || p.IssueComments == nil || !Supported(issue.Source) {
+		return nil
+	}
+	comments, err := f.List(ctx, issue)
+	if err != nil {
+		return err
+	}
+	if len(comments) > maxComments {
+		comments = comments[len(comments)-maxComments:]
+	}
+	stored := make([]db.IssueComment, 0, len(comments))
+	for _, c := range comments {
+		stored = append(stored, db.IssueComment{CommentID: strconv.FormatInt(c.ID, 10), Author: c.Author, Body: c.Body, CreatedAt: c.CreatedAt})
+	}
+	return f.store.ReplaceIssueComments(ctx, issue.AutoPRIssueID, issue.SourceUpdated, stored)
+}
+
+// Sync refreshes the comments of issue unless they were fetched since it
+// last changed on its source. Failures are logged and retried on the next
+// sync.
+func (f *Fetcher) Sync(ctx context.Context, issue db.Issue) {
+	p, ok := f.cfg.ProjectByName(issue.ProjectName)
+	if !ok || p.IssueComments == nil || !Supported(issue.Source) {
+		return
+	}
+	synced, err := f.store.IssueCommentsSourceUpdated(ctx, issue.AutoPRIssueID)
+	if err != nil {
+		slog.Warn("issue comments: check sync", "issue", issue.AutoPRIssueID, "err", err)
+		return
+	}
+	if synced != "" && synced == issue.SourceUpdated {
+		return
+	}
+	if err := f.Refresh(ctx, issue); err != nil {
+		slog.Warn("issue comments: sync", "project", issue.ProjectName, "issue", issue.Source+"#"+issue.SourceIssueID, "err", err)
+	}
+}
@@ -0,0 +1,86 @@
+package issuecomments
+
+import (
+	"context"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"autopr/internal/config"
+	"autopr/internal/db"
+	"autopr/internal/git"
+)
+
+func TestSyncFetchesCommentsOnceIssueChanges(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	store, err := db.Open(filepath.Join(t.TempDir(), "autopr.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer store.Close()
+	issueID, err := store.UpsertIssue(ctx, db.IssueUpsert{ProjectName: "web", Source: "github", SourceIssueID: "42", Title: "t", URL: "u", State: "open"})
+	if err != nil {
+		t.Fatalf("upsert issue: %v", err)
+	}
+
+	cfg := &config.Config{
+		Tokens: config.TokensConfig{GitHub: "tok"},
+		Projects: []config.ProjectConfig{{
+			Name:          "web",
+			GitHub:        &config.ProjectGitHub{Owner: "org", Repo: "repo"},
+			IssueComments: &config.ProjectIssueComments{},
+		}},
+	}
+	f := New(cfg, store)
+	fetches := 0
+	thread := []git.IssueComment{{ID: 1, Author: "ann", Body: "It only fails on the second click.", CreatedAt: "2026-01-01T00:00:00Z"}}
+	f.listGitHubComments = func(_ context.Context, token, _, owner, repo, number string) ([]git.IssueComment, error) {
+		if token != "tok" || owner != "org" || repo != "repo" || number != "42" {
+			t.Fatalf("unexpected listing of %s/%s#%s with %q", owner, repo, number, token)
+		}
+		fetches++
+		return thread, nil
+	}
+
+	issue := db.Issue{AutoPRIssueID: issueID, ProjectName: "web", Source: "github", SourceIssueID: "42", SourceUpdated: "2026-01-01T00:00:00Z"}
+	f.Sync(ctx, issue)
+	f.Sync(ctx, issue) // unchanged on the source: no second fetch
+	if fetches != 1 {
+		t.Fatalf("expected 1 fetch for an unchanged issue, got %d", fetches)
+	}
+
+	thread = append(thread, git.IssueComment{ID: 2, Author: "bob", Body: "Same on Firefox.", CreatedAt: "2026-01-02T00:00:00Z"})
+	issue.SourceUpdated = "2026-01-02T00:00:00Z"
+	f.Sync(ctx, issue)
+	stored, err := store.ListIssueComments(ctx, issueID)
+	if err != nil {
+		t.Fatalf("list comments: %v", err)
+	}
+	if fetches != 2 || len(stored) != 2 || stored[1].CommentID != "2" || stored[1].Author != "bob" {
+		t.Fatalf("expected the new comment stored after the issue changed, got %d fetches and %+v", fetches, stored)
+	}
+
+	// A long thread keeps its newest comments.
+	for id := int64(3); id <= maxComments+5; id++ {
+		thread = append(thread, git.IssueComment{ID: id, Author: "ann", Body: "+1"})
+	}
+	if err := f.Refresh(ctx, issue); err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+	stored, err = store.ListIssueComments(ctx, issueID)
+	if err != nil {
+		t.Fatalf("list comments: %v", err)
+	}
+	if len(stored) != maxComments || stored[len(stored)-1].CommentID != strconv.Itoa(maxComments+5) {
+		t.Fatalf("expected the newest %d comments, got %d ending at %s", maxComments, len(stored), stored[len(stored)-1].CommentID)
+	}
+
+	// Projects without [projects.issue_comments] are not fetched.
+	cfg.Projects[0].IssueComments = nil
+	issue.SourceUpdated = "2026-01-03T00:00:00Z"
+	if err := f.Refresh(ctx, issue); err != nil || fetches != 3 {
+		t.Fatalf("expected no fetch without issue_comments, got %d fetches, %v", fetches, err)
+	}
+}
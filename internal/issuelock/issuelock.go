@@ -0,0 +1,220 @@
+// Package issuelock marks source issues as taken while a job works on them.
+//
+// When a project configures [projects.issue_lock], the pipeline assigns the
+// source issue to the configured bot account and adds an in-progress label as
+// soon as a job starts. Once the job reaches a terminal state the sync loop
+// removes the in-progress label and optionally swaps in a done/failed label.
+package issuelock
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+
+	"autopr/internal/config"
+	"autopr/internal/db"
+	"autopr/internal/git"
+	"autopr/internal/githubapp"
+)
+
+// Locker applies and releases issue locks on GitHub and GitLab.
+type Locker struct {
+	cfg   *config.Config
+	store *db.Store
+
+	addGitHubLabels       func(ctx context.Context, token, baseURL, owner, repo, number string, labels []string) error
+	removeGitHubLabel     func(ctx context.Context, token, baseURL, owner, repo, number, label string) error
+	addGitHubAssignees    func(ctx context.Context, token, baseURL, owner, repo, number string, assignees []string) error
+	removeGitHubAssignees func(ctx context.Context, token, baseURL, owner, repo, number string, assignees []string) error
+	updateGitLabIssue     func(ctx context.Context, token, baseURL, projectID, iid string, update git.GitLabIssueUpdate) error
+	lookupGitLabUserID    func(ctx context.Context, token, baseURL, username string) (int, error)
+}
+
+func New(cfg *config.Config, store *db.Store) *Locker {
+	return &Locker{
+		cfg:                   cfg,
+		store:                 store,
+		addGitHubLabels:       git.AddGitHubIssueLabels,
+		removeGitHubLabel:     git.RemoveGitHubIssueLabel,
+		addGitHubAssignees:    git.AddGitHubIssueAssignees,
+		removeGitHubAssignees: git.RemoveGitHubIssueAssignees,
+		updateGitLabIssue:     git.UpdateGitLabIssue,
+		lookupGitLabUserID:    git.LookupGitLabUserID,
+	}
+}
+
+// Acquire assigns the job's source issue and adds the in-progress label.
+// Failures are logged and never block the pipeline.
+func (l *Locker) Acquire(ctx context.Context, job db.Job) {
+	proj, issue, ok := l.target(ctx, job)
+	if !ok {
+		return
+	}
+	acquired, err := l.store.MarkIssueLockHeld(ctx, job.ID)
+	if err != nil {
+		slog.Warn("issue lock: record acquire", "job", db.ShortID(job.ID), "err", err)
+		return
+	}
+	if !acquired {
+		return
+	}
+
+	lock := proj.IssueLock
+	switch issue.Source {
+	case "github":
+		token, err := githubapp.Token(ctx, l.cfg, proj)
+		if err != nil {
+			slog.Warn("issue lock: github token", "job", db.ShortID(job.ID), "err", err)
+			break
+		}
+		baseURL, owner, repo, number := proj.GitHub.BaseURL, proj.GitHub.Owner, proj.GitHub.Repo, issue.SourceIssueID
+		if lock.Assignee != "" {
+			if err := l.addGitHubAssignees(ctx, token, baseURL, owner, repo, number, []string{lock.Assignee}); err != nil {
+				slog.Warn("issue lock: assign github issue", "job", db.ShortID(job.ID), "issue", number, "err", err)
+			}
+		}
+		labels := []string{lock.InProgressLabel}
+		if err := l.addGitHubLabels(ctx, token, baseURL, owner, repo, number, labels); err != nil {
+			slog.Warn("issue lock: label github issue", "job", db.ShortID(job.ID), "issue", number, "err", err)
+		}
+		for _, stale := range []string{lock.DoneLabel, lock.FailedLabel} {
+			if stale == "" {
+				continue
+			}
+			if err := l.removeGitHubLabel(ctx, token, baseURL, owner, repo, number, stale); err != nil {
+				slog.Warn("issue lock: remove stale github label", "job", db.ShortID(job.ID), "issue", number, "label", stale, "err", err)
+			}
+		}
+	case "gitlab":
+		update := git.GitLabIssueUpdate{
+			AddLabels:    []string{lock.InProgressLabel},
+			RemoveLabels: nonEmpty(lock.DoneLabel, lock.FailedLabel),
+		}
+		if lock.Assignee != "" {
+			userID, err := l.lookupGitLabUserID(ctx, l.cfg.Tokens.GitLab, proj.GitLab.BaseURL, lock.Assignee)
+			if err != nil {
+				slog.Warn("issue lock: resolve gitlab assignee", "job", db.ShortID(job.ID), "assignee", lock.Assignee, "err", err)
+			} else {
+				update.AssigneeIDs = []int{userID}
+			}
+		}
+		if err := l.updateGitLabIssue(ctx, l.cfg.Tokens.GitLab, proj.GitLab.BaseURL, proj.GitLab.ProjectID, issue.SourceIssueID, update); err != nil {
+			slog.Warn("issue lock: update gitlab issue", "job", db.ShortID(job.ID), "issue", issue.SourceIssueID, "err", err)
+		}
+	}
+	slog.Info("issue lock acquired", "job", db.ShortID(job.ID), "source", issue.Source, "issue", issue.SourceIssueID)
+}
+
+// ReleaseTerminal releases locks held by jobs that reached a terminal state:
+// the in-progress label is removed and the done label (approved) or failed
+// label (failed/rejected/cancelled) is added when configured. On GitHub the
+// bot assignee is also removed for unsuccessful outcomes so humans can pick
+// the issue up again.
+func (l *Locker) ReleaseTerminal(ctx context.Context) {
+	locks, err := l.store.ListReleasableIssueLocks(ctx)
+	if err != nil {
+		slog.Error("issue lock: list releasable", "err", err)
+		return
+	}
+	for _, lk := range locks {
+		job, err := l.store.GetJob(ctx, lk.JobID)
+		if err != nil {
+			slog.Warn("issue lock: load job", "job", db.ShortID(lk.JobID), "err", err)
+			continue
+		}
+		if err := l.release(ctx, job); err != nil {
+			slog.Warn("issue lock: release", "job", db.ShortID(job.ID), "err", err)
+			continue
+		}
+		if err := l.store.MarkIssueLockReleased(ctx, job.ID); err != nil {
+			slog.Error("issue lock: record release", "job", db.ShortID(job.ID), "err", err)
+			continue
+		}
+		slog.Info("issue lock released", "job", db.ShortID(job.ID), "state", job.State)
+	}
+}
+
+func (l *Locker) release(ctx context.Context, job db.Job) error {
+	proj, issue, ok := l.target(ctx, job)
+	if !ok {
+		// Config changed since the lock was taken; nothing left to undo.
+		return nil
+	}
+	lock := proj.IssueLock
+	succeeded := job.State == "approved"
+	outcomeLabel := lock.FailedLabel
+	if succeeded {
+		outcomeLabel = lock.DoneLabel
+	}
+
+	switch issue.Source {
+	case "github":
+		token, err := githubapp.Token(ctx, l.cfg, proj)
+		if err != nil {
+			return err
+		}
+		baseURL, owner, repo, number := proj.GitHub.BaseURL, proj.GitHub.Owner, proj.GitHub.Repo, issue.SourceIssueID
+		if err := l.removeGitHubLabel(ctx, token, baseURL, owner, repo, number, lock.InProgressLabel); err != nil {
+			return err
+		}
+		if outcomeLabel != "" {
+			if err := l.addGitHubLabels(ctx, token, baseURL, owner, repo, number, []string{outcomeLabel}); err != nil {
+				return err
+			}
+		}
+		if !succeeded && lock.Assignee != "" {
+			if err := l.removeGitHubAssignees(ctx, token, baseURL, owner, repo, number, []string{lock.Assignee}); err != nil {
+				return err
+			}
+		}
+	case "gitlab":
+		update := git.GitLabIssueUpdate{
+			AddLabels:    nonEmpty(outcomeLabel),
+			RemoveLabels: []string{lock.InProgressLabel},
+		}
+		if err := l.updateGitLabIssue(ctx, l.cfg.Tokens.GitLab, proj.GitLab.BaseURL, proj.GitLab.ProjectID, issue.SourceIssueID, update); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// target resolves the project and source issue for job, reporting false when
+// issue locking does not apply (not configured, unsupported source, no token).
+func (l *Locker) target(ctx context.Context, job db.Job) (*config.ProjectConfig, db.Issue, bool) {
+	proj, ok := l.cfg.ProjectByName(job.ProjectName)
+	if !ok || proj.IssueLock == nil {
+		return nil, db.Issue{}, false
+	}
+	issue, err := l.store.GetIssueByAPID(ctx, job.AutoPRIssueID)
+	if err != nil {
+		slog.Warn("issue lock: load issue", "job", db.ShortID(job.ID), "err", err)
+		return nil, db.Issue{}, false
+	}
+	if strings.TrimSpace(issue.SourceIssueID) == "" {
+		return nil, db.Issue{}, false
+	}
+	switch issue.Source {
+	case "github":
+		if proj.GitHub == nil || (l.cfg.Tokens.GitHub == "" && !proj.GitHub.UsesApp()) {
+			return nil, db.Issue{}, false
+		}
+	case "gitlab":
+		if proj.GitLab == nil || l.cfg.Tokens.GitLab == "" {
+			return nil, db.Issue{}, false
+		}
+	default:
+		return nil, db.Issue{}, false
+	}
+	return proj, issue, true
+}
+
+func nonEmpty(values ...string) []string {
+	var out []string
+	for _, v := range values {
+		if v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
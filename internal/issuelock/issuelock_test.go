@@ -0,0 +1,239 @@
+package issuelock
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"autopr/internal/config"
+	"autopr/internal/db"
+	"autopr/internal/git"
+)
+
+type githubCall struct {
+	op     string
+	number string
+	values []string
+}
+
+func newGitHubLocker(t *testing.T, lock *config.ProjectIssueLock) (*Locker, *db.Store, *[]githubCall, string) {
+	t.Helper()
+	ctx := context.Background()
+
+	store, err := db.Open(filepath.Join(t.TempDir(), "autopr.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	issueID, err := store.UpsertIssue(ctx, db.IssueUpsert{
+		ProjectName:   "myproject",
+		Source:        "github",
+		SourceIssueID: "42",
+		Title:         "fix it",
+		URL:           "https://github.com/org/repo/issues/42",
+		State:         "open",
+	})
+	if err != nil {
+		t.Fatalf("upsert issue: %v", err)
+	}
+	jobID, err := store.CreateJob(ctx, issueID, "myproject", 3)
+	if err != nil {
+		t.Fatalf("create job: %v", err)
+	}
+	if _, err := store.ClaimJob(ctx); err != nil {
+		t.Fatalf("claim job: %v", err)
+	}
+
+	cfg := &config.Config{
+		Tokens: config.TokensConfig{GitHub: "tok"},
+		Projects: []config.ProjectConfig{{
+			Name:      "myproject",
+			GitHub:    &config.ProjectGitHub{Owner: "org", Repo: "repo"},
+			IssueLock: lock,
+		}},
+	}
+
+	calls := &[]githubCall{}
+	l := New(cfg, store)
+	l.addGitHubLabels = func(_ context.Context, _, _, _, _, number string, labels []string) error {
+		*calls = append(*calls, githubCall{op: "add_labels", number: number, values: labels})
+		return nil
+	}
+	l.removeGitHubLabel = func(_ context.Context, _, _, _, _, number, label string) error {
+		*calls = append(*calls, githubCall{op: "remove_label", number: number, values: []string{label}})
+		return nil
+	}
+	l.addGitHubAssignees = func(_ context.Context, _, _, _, _, number string, assignees []string) error {
+		*calls = append(*calls, githubCall{op: "assign", number: number, values: assignees})
+		return nil
+	}
+	l.removeGitHubAssignees = func(_ context.Context, _, _, _, _, number string, assignees []string) error {
+		*calls = append(*calls, githubCall{op: "unassign", number: number, values: assignees})
+		return nil
+	}
+	return l, store, calls, jobID
+}
+
+func TestAcquireAssignsAndLabelsGitHubIssueOnce(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	l, store, calls, jobID := newGitHubLocker(t, &config.ProjectIssueLock{
+		Assignee:        "autopr-bot",
+		InProgressLabel: "autopr-in-progress",
+	})
+
+	job, err := store.GetJob(ctx, jobID)
+	if err != nil {
+		t.Fatalf("get job: %v", err)
+	}
+	l.Acquire(ctx, job)
+	l.Acquire(ctx, job)
+
+	want := []githubCall{
+		{op: "assign", number: "42", values: []string{"autopr-bot"}},
+		{op: "add_labels", number: "42", values: []string{"autopr-in-progress"}},
+	}
+	if !reflect.DeepEqual(*calls, want) {
+		t.Fatalf("unexpected calls:\n got %+v\nwant %+v", *calls, want)
+	}
+}
+
+func TestReleaseTerminalSwapsLabelsForFailedJob(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	l, store, calls, jobID := newGitHubLocker(t, &config.ProjectIssueLock{
+		Assignee:        "autopr-bot",
+		InProgressLabel: "autopr-in-progress",
+		FailedLabel:     "autopr-failed",
+	})
+
+	job, err := store.GetJob(ctx, jobID)
+	if err != nil {
+		t.Fatalf("get job: %v", err)
+	}
+	l.Acquire(ctx, job)
+
+	// Active jobs keep their lock.
+	*calls = nil
+	l.ReleaseTerminal(ctx)
+	if len(*calls) != 0 {
+		t.Fatalf("expected no release calls for active job, got %+v", *calls)
+	}
+
+	if err := store.TransitionState(ctx, jobID, "planning", "failed"); err != nil {
+		t.Fatalf("planning->failed: %v", err)
+	}
+	l.ReleaseTerminal(ctx)
+
+	want := []githubCall{
+		{op: "remove_label", number: "42", values: []string{"autopr-in-progress"}},
+		{op: "add_labels", number: "42", values: []string{"autopr-failed"}},
+		{op: "unassign", number: "42", values: []string{"autopr-bot"}},
+	}
+	if !reflect.DeepEqual(*calls, want) {
+		t.Fatalf("unexpected calls:\n got %+v\nwant %+v", *calls, want)
+	}
+
+	// Released locks are not processed again.
+	*calls = nil
+	l.ReleaseTerminal(ctx)
+	if len(*calls) != 0 {
+		t.Fatalf("expected release to run once, got %+v", *calls)
+	}
+}
+
+func TestAcquireSkipsProjectsWithoutIssueLock(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	l, store, calls, jobID := newGitHubLocker(t, nil)
+
+	job, err := store.GetJob(ctx, jobID)
+	if err != nil {
+		t.Fatalf("get job: %v", err)
+	}
+	l.Acquire(ctx, job)
+	if err := store.TransitionState(ctx, jobID, "planning", "failed"); err != nil {
+		t.Fatalf("planning->failed: %v", err)
+	}
+	l.ReleaseTerminal(ctx)
+
+	if len(*calls) != 0 {
+		t.Fatalf("expected no tracker calls, got %+v", *calls)
+	}
+}
+
+func TestAcquireUpdatesGitLabIssueWithResolvedAssignee(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	store, err := db.Open(filepath.Join(t.TempDir(), "autopr.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer store.Close()
+
+	issueID, err := store.UpsertIssue(ctx, db.IssueUpsert{
+		ProjectName:   "myproject",
+		Source:        "gitlab",
+		SourceIssueID: "7",
+		Title:         "fix it",
+		URL:           "https://gitlab.com/org/repo/-/issues/7",
+		State:         "open",
+	})
+	if err != nil {
+		t.Fatalf("upsert issue: %v", err)
+	}
+	jobID, err := store.CreateJob(ctx, issueID, "myproject", 3)
+	if err != nil {
+		t.Fatalf("create job: %v", err)
+	}
+	if _, err := store.ClaimJob(ctx); err != nil {
+		t.Fatalf("claim job: %v", err)
+	}
+
+	cfg := &config.Config{
+		Tokens: config.TokensConfig{GitLab: "tok"},
+		Projects: []config.ProjectConfig{{
+			Name:   "myproject",
+			GitLab: &config.ProjectGitLab{BaseURL: "https://gitlab.com", ProjectID: "123"},
+			IssueLock: &config.ProjectIssueLock{
+				Assignee:        "autopr-bot",
+				InProgressLabel: "autopr-in-progress",
+				DoneLabel:       "autopr-done",
+			},
+		}},
+	}
+
+	var updates []git.GitLabIssueUpdate
+	l := New(cfg, store)
+	l.lookupGitLabUserID = func(_ context.Context, _, _, username string) (int, error) {
+		if username != "autopr-bot" {
+			t.Fatalf("unexpected username %q", username)
+		}
+		return 99, nil
+	}
+	l.updateGitLabIssue = func(_ context.Context, _, _, projectID, iid string, update git.GitLabIssueUpdate) error {
+		if projectID != "123" || iid != "7" {
+			t.Fatalf("unexpected target project=%q iid=%q", projectID, iid)
+		}
+		updates = append(updates, update)
+		return nil
+	}
+
+	job, err := store.GetJob(ctx, jobID)
+	if err != nil {
+		t.Fatalf("get job: %v", err)
+	}
+	l.Acquire(ctx, job)
+
+	want := []git.GitLabIssueUpdate{{
+		AddLabels:    []string{"autopr-in-progress"},
+		RemoveLabels: []string{"autopr-done"},
+		AssigneeIDs:  []int{99},
+	}}
+	if !reflect.DeepEqual(updates, want) {
+		t.Fatalf("unexpected updates:\n got %+v\nwant %+v", updates, want)
+	}
+}
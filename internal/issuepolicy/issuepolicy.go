@@ -0,0 +1,106 @@
+// Package issuepolicy holds back issues that keep failing.
+//
+// When a project configures [projects.issue_policy], every sync round holds
+// issues that have used up max_jobs job runs, until a human clears them with
+// `ap issues clear`, and issues with cooldown_after_failures failed runs, for
+// the cooldown. A held issue is ineligible with a skip_reason saying why, so
+// no job is claimed or retried for it. Cooldowns that have ended are released
+// on the same round.
+package issuepolicy
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"autopr/internal/config"
+	"autopr/internal/db"
+)
+
+// Enforcer applies the projects' issue policies.
+type Enforcer struct {
+	cfg   *config.Config
+	store *db.Store
+	now   func() time.Time
+}
+
+func New(cfg *config.Config, store *db.Store) *Enforcer {
+	return &Enforcer{cfg: cfg, store: store, now: time.Now}
+}
+
+// Apply releases cooldowns that have ended and holds every issue that is
+// past its project's policy. Failures are logged.
+func (e *Enforcer) Apply(ctx context.Context) {
+	released, err := e.store.ReleaseExpiredIssueHolds(ctx, e.now())
+	if err != nil {
+		slog.Error("issue policy: release cooldowns", "err", err)
+	}
+	for _, id := range released {
+		slog.Info("issue policy: cooldown ended", "issue", id)
+	}
+
+	for i := range e.cfg.Projects {
+		p := &e.cfg.Projects[i]
+		if p.IssuePolicy == nil {
+			continue
+		}
+		attempts, err := e.store.ListIssueAttempts(ctx, p.Name)
+		if err != nil {
+			slog.Error("issue policy: list attempts", "project", p.Name, "err", err)
+			continue
+		}
+		for _, a := range attempts {
+			if err := e.hold(ctx, p.IssuePolicy, a); err != nil {
+				slog.Error("issue policy: hold issue", "project", p.Name, "issue", a.SourceIssueID, "err", err)
+			}
+		}
+	}
+}
+
+// ApplyIssue holds one issue if it is past its project's policy, so a manual
+// retry sees the policy without waiting for the next sync.
+func (e *Enforcer) ApplyIssue(ctx context.Context, autoprIssueID string) error {
+	a, err := e.store.GetIssueAttempts(ctx, autoprIssueID)
+	if err != nil {
+		return err
+	}
+	p, ok := e.cfg.ProjectByName(a.ProjectName)
+	if !ok || p.IssuePolicy == nil {
+		return nil
+	}
+	return e.hold(ctx, p.IssuePolicy, a)
+}
+
+func (e *Enforcer) hold(ctx context.Context, policy *config.ProjectIssuePolicy, a db.IssueAttempts) error {
+	reason, until, ok := Decide(policy, a, e.now())
+	if !ok {
+		return nil
+	}
+	if err := e.store.HoldIssue(ctx, a.AutoPRIssueID, reason, until); err != nil {
+		return err
+	}
+	slog.Info("issue policy: issue held", "project", a.ProjectName, "issue", a.SourceIssueID, "reason", reason)
+	return nil
+}
+
+// Decide reports whether an issue should be held under policy, with the
+// skip_reason and when the hold ends (zero: until cleared). Issues that are
+// already held, have a job in flight, or were fixed are left alone.
+func Decide(policy *config.ProjectIssuePolicy, a db.IssueAttempts, now time.Time) (string, time.Time, bool) {
+	if a.Held() || a.Busy || a.Succeeded {
+		return "", time.Time{}, false
+	}
+	if policy.MaxJobs > 0 && a.Jobs >= policy.MaxJobs {
+		return fmt.Sprintf("issue policy: %d job runs reached max_jobs %d; held until cleared with `ap issues clear`", a.Jobs, policy.MaxJobs), time.Time{}, true
+	}
+	if policy.CooldownAfter > 0 && a.Failures >= policy.CooldownAfter {
+		cooldown := policy.CooldownDuration()
+		if cooldown <= 0 {
+			return fmt.Sprintf("issue policy: %d failed runs; held until cleared with `ap issues clear`", a.Failures), time.Time{}, true
+		}
+		until := now.Add(cooldown).UTC()
+		return fmt.Sprintf("issue policy: %d failed runs; cooling down until %s", a.Failures, until.Format(time.RFC3339)), until, true
+	}
+	return "", time.Time{}, false
+}
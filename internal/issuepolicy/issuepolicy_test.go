@@ -0,0 +1,41 @@
+package issuepolicy
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"autopr/internal/config"
+	"autopr/internal/db"
+)
+
+func TestDecide(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	policy := &config.ProjectIssuePolicy{MaxJobs: 5, CooldownAfter: 2, Cooldown: "24h"}
+	untilCleared := &config.ProjectIssuePolicy{CooldownAfter: 2}
+
+	cases := []struct {
+		name       string
+		policy     *config.ProjectIssuePolicy
+		attempts   db.IssueAttempts
+		wantHold   bool
+		wantUntil  time.Time
+		wantReason string
+	}{
+		{name: "under budget", policy: policy, attempts: db.IssueAttempts{Jobs: 2, Failures: 1}},
+		{name: "max jobs", policy: policy, attempts: db.IssueAttempts{Jobs: 5, Failures: 3}, wantHold: true, wantReason: "max_jobs 5"},
+		{name: "cooldown", policy: policy, attempts: db.IssueAttempts{Jobs: 2, Failures: 2}, wantHold: true, wantUntil: now.Add(24 * time.Hour), wantReason: "cooling down until 2026-03-02T12:00:00Z"},
+		{name: "cooldown until cleared", policy: untilCleared, attempts: db.IssueAttempts{Jobs: 9, Failures: 2}, wantHold: true, wantReason: "held until cleared"},
+		{name: "already held", policy: policy, attempts: db.IssueAttempts{Jobs: 5, HoldReason: "x"}},
+		{name: "job running", policy: policy, attempts: db.IssueAttempts{Jobs: 5, Busy: true}},
+		{name: "fixed", policy: policy, attempts: db.IssueAttempts{Jobs: 5, Failures: 4, Succeeded: true}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			reason, until, hold := Decide(tc.policy, tc.attempts, now)
+			if hold != tc.wantHold || !until.Equal(tc.wantUntil) || !strings.Contains(reason, tc.wantReason) {
+				t.Fatalf("Decide = (%q, %v, %v), want hold=%v until=%v reason containing %q", reason, until, hold, tc.wantHold, tc.wantUntil, tc.wantReason)
+			}
+		})
+	}
+}
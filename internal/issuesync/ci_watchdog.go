@@ -0,0 +1,70 @@
+package issuesync
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"autopr/internal/db"
+)
+
+// ciWatchdogInterval is how often the watchdog looks for stuck CI.
+const ciWatchdogInterval = time.Minute
+
+// RunCIWatchdog periodically calls CheckStaleCI until ctx is cancelled.
+func (s *Syncer) RunCIWatchdog(ctx context.Context, staleAfter time.Duration) {
+	slog.Info("ci watchdog starting", "stale_after", staleAfter)
+	ticker := time.NewTicker(ciWatchdogInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.CheckStaleCI(ctx, staleAfter)
+		}
+	}
+}
+
+// CheckStaleCI finds awaiting_checks jobs whose CI has been pending for longer
+// than staleAfter, which usually means a webhook or poll result was lost. Each
+// is re-polled at once; a job still awaiting checks afterwards is flagged and
+// a ci_stuck notification is sent. It returns the IDs of the flagged jobs.
+func (s *Syncer) CheckStaleCI(ctx context.Context, staleAfter time.Duration) []string {
+	jobs, err := s.store.ListStaleAwaitingChecksJobs(ctx, staleAfter)
+	if err != nil {
+		slog.Error("ci watchdog: list stale jobs", "err", err)
+		return nil
+	}
+
+	var flagged []string
+	ciTimeout := s.ciCheckTimeout()
+	for _, job := range jobs {
+		proj, ok := s.cfg.ProjectByName(job.ProjectName)
+		if !ok {
+			continue
+		}
+		s.checkJobCI(ctx, job, proj, ciTimeout)
+
+		current, err := s.store.GetJob(ctx, job.ID)
+		if err != nil {
+			slog.Warn("ci watchdog: reload job", "job", job.ID, "err", err)
+			continue
+		}
+		if current.State != "awaiting_checks" {
+			slog.Info("ci watchdog: re-poll settled stuck job", "job", db.ShortID(job.ID), "state", current.State)
+			continue
+		}
+		marked, err := s.store.MarkJobCIStale(ctx, job.ID)
+		if err != nil {
+			slog.Error("ci watchdog: flag stuck job", "job", job.ID, "err", err)
+			continue
+		}
+		if marked {
+			slog.Warn("CI stuck", "job", db.ShortID(job.ID), "pending_for_over", staleAfter, "summary", current.CIStatusSummary)
+			flagged = append(flagged, job.ID)
+		}
+	}
+	return flagged
+}
@@ -0,0 +1,121 @@
+package issuesync
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"autopr/internal/config"
+	"autopr/internal/db"
+	"autopr/internal/git"
+)
+
+func ciWatchdogTestSyncer(t *testing.T, store *db.Store, status git.CheckRunStatus, polls *int) *Syncer {
+	t.Helper()
+	cfg := &config.Config{
+		Tokens: config.TokensConfig{GitHub: "token"},
+		Daemon: config.DaemonConfig{CICheckTimeout: "2h"},
+		Projects: []config.ProjectConfig{
+			{
+				Name:   "project-gh",
+				GitHub: &config.ProjectGitHub{Owner: "acme", Repo: "repo"},
+			},
+		},
+	}
+	s := NewSyncer(cfg, store, make(chan string, 1))
+	s.checkGitHubPRStatus = func(ctx context.Context, token, baseURL, prURL string) (git.PRMergeStatus, error) {
+		return git.PRMergeStatus{}, nil
+	}
+	s.getGitHubCheckRunStatus = func(ctx context.Context, token, baseURL, owner, repo, ref string) (git.CheckRunStatus, error) {
+		*polls++
+		return status, nil
+	}
+	return s
+}
+
+func backdateCIStart(t *testing.T, ctx context.Context, store *db.Store, jobID, offset string) {
+	t.Helper()
+	if _, err := store.Writer.ExecContext(ctx, `
+UPDATE jobs SET ci_started_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now', ?)
+WHERE id = ?`, offset, jobID); err != nil {
+		t.Fatalf("backdate ci_started_at: %v", err)
+	}
+}
+
+func TestCheckStaleCI_FlagsJobStillPendingAfterRepoll(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	store := openTestStore(t)
+	defer store.Close()
+
+	staleID := createSyncTestJob(t, ctx, store, "project-gh", "ci-stuck", "awaiting_checks", "autopr/ci-stuck", "https://github.com/acme/repo/pull/110")
+	backdateCIStart(t, ctx, store, staleID, "-20 minutes")
+	freshID := createSyncTestJob(t, ctx, store, "project-gh", "ci-fresh", "awaiting_checks", "autopr/ci-fresh", "https://github.com/acme/repo/pull/111")
+	backdateCIStart(t, ctx, store, freshID, "-1 minutes")
+
+	polls := 0
+	s := ciWatchdogTestSyncer(t, store, git.CheckRunStatus{Total: 2, Completed: 1, Passed: 1, Pending: 1}, &polls)
+
+	flagged := s.CheckStaleCI(ctx, 10*time.Minute)
+	if len(flagged) != 1 || flagged[0] != staleID {
+		t.Fatalf("expected only %s flagged, got %v", staleID, flagged)
+	}
+	if polls != 1 {
+		t.Fatalf("expected the stale job to be re-polled once, got %d polls", polls)
+	}
+	job, err := store.GetJob(ctx, staleID)
+	if err != nil {
+		t.Fatalf("get job: %v", err)
+	}
+	if job.State != "awaiting_checks" {
+		t.Fatalf("expected job to stay awaiting_checks, got %q", job.State)
+	}
+	events, err := store.ListNotificationEvents(ctx, db.NotificationStatusPending, 0)
+	if err != nil {
+		t.Fatalf("list notification events: %v", err)
+	}
+	if len(events) != 1 || events[0].JobID != staleID || events[0].EventType != db.NotificationEventCIStuck {
+		t.Fatalf("expected one ci_stuck event for %s, got %+v", staleID, events)
+	}
+
+	// A flagged job is escalated once, not on every watchdog pass.
+	if again := s.CheckStaleCI(ctx, 10*time.Minute); len(again) != 0 {
+		t.Fatalf("expected no re-flagging, got %v", again)
+	}
+	if polls != 1 {
+		t.Fatalf("expected flagged job not to be re-polled by the watchdog, got %d polls", polls)
+	}
+}
+
+func TestCheckStaleCI_RepollSettlesJob(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	store := openTestStore(t)
+	defer store.Close()
+
+	jobID := createSyncTestJob(t, ctx, store, "project-gh", "ci-lost-webhook", "awaiting_checks", "autopr/ci-lost", "https://github.com/acme/repo/pull/112")
+	backdateCIStart(t, ctx, store, jobID, "-20 minutes")
+
+	polls := 0
+	s := ciWatchdogTestSyncer(t, store, git.CheckRunStatus{Total: 2, Completed: 2, Passed: 2}, &polls)
+
+	if flagged := s.CheckStaleCI(ctx, 10*time.Minute); len(flagged) != 0 {
+		t.Fatalf("expected no flagged jobs, got %v", flagged)
+	}
+	job, err := store.GetJob(ctx, jobID)
+	if err != nil {
+		t.Fatalf("get job: %v", err)
+	}
+	if job.State != "approved" {
+		t.Fatalf("expected re-poll to approve job, got %q", job.State)
+	}
+	events, err := store.ListNotificationEvents(ctx, "", 0)
+	if err != nil {
+		t.Fatalf("list notification events: %v", err)
+	}
+	for _, ev := range events {
+		if ev.EventType == db.NotificationEventCIStuck {
+			t.Fatalf("unexpected ci_stuck event: %+v", ev)
+		}
+	}
+}
@@ -0,0 +1,16 @@
+package issuesync
+
+import "time"
+
+// DueAt normalizes an issue due date from a source to RFC 3339 UTC for
+// db.IssueUpsert. A date without a time means the end of that day in UTC.
+// Empty or unparseable dates yield "", clearing the due date.
+func DueAt(s string) string {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t.UTC().Format(time.RFC3339)
+	}
+	if t, err := time.Parse(time.DateOnly, s); err == nil {
+		return t.AddDate(0, 0, 1).Add(-time.Second).Format(time.RFC3339)
+	}
+	return ""
+}
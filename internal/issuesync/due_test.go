@@ -0,0 +1,19 @@
+package issuesync
+
+import "testing"
+
+func TestDueAt(t *testing.T) {
+	t.Parallel()
+
+	for in, want := range map[string]string{
+		"":                          "",
+		"2025-03-01":                "2025-03-01T23:59:59Z",
+		"2025-03-01T08:00:00Z":      "2025-03-01T08:00:00Z",
+		"2025-03-01T08:00:00+05:00": "2025-03-01T03:00:00Z",
+		"next week":                 "",
+	} {
+		if got := DueAt(in); got != want {
+			t.Fatalf("DueAt(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
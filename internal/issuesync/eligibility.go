@@ -1,8 +1,12 @@
 package issuesync
 
 import (
+	"log/slog"
 	"strings"
 	"time"
+
+	"autopr/internal/config"
+	"autopr/internal/policy"
 )
 
 type IssueEligibility struct {
@@ -58,6 +62,61 @@ func EvaluateIssueEligibility(includeLabels, excludeLabels, issueLabels []string
 	return evaluateIssueEligibility(includeLabels, excludeLabels, issueLabels, evaluatedAt)
 }
 
+// routeIssueEligibility marks an otherwise eligible issue ineligible when p is
+// a monorepo sub-project and the issue carries none of its route labels, so
+// that only the sub-project owning the issue queues a job for it.
+func routeIssueEligibility(p *config.ProjectConfig, eligibility issueEligibility, issueLabels []string) issueEligibility {
+	if !eligibility.Eligible || p.RoutesLabels(issueLabels) {
+		return eligibility
+	}
+	eligibility.Eligible = false
+	eligibility.SkipReason = "routed elsewhere: missing route labels: " + strings.Join(p.Scope.RouteLabels, ", ")
+	return eligibility
+}
+
+func RouteIssueEligibility(p *config.ProjectConfig, eligibility IssueEligibility, issueLabels []string) IssueEligibility {
+	return routeIssueEligibility(p, eligibility, issueLabels)
+}
+
+// policyIssueEligibility applies p's policy script to an otherwise eligible
+// issue: route() naming another project or eligible() refusing the issue
+// makes it ineligible. A policy that fails is logged and leaves the built-in
+// decision in place.
+func policyIssueEligibility(p *config.ProjectConfig, eligibility issueEligibility, issue policy.Issue) issueEligibility {
+	if !eligibility.Eligible {
+		return eligibility
+	}
+	pol, err := policy.ForProject(p)
+	if err != nil {
+		slog.Warn("sync: load policy", "project", p.Name, "err", err)
+		return eligibility
+	}
+	if pol == nil {
+		return eligibility
+	}
+	issue.Project = p.Name
+	owner, err := pol.Route(issue)
+	if err != nil {
+		slog.Warn("sync: policy route", "project", p.Name, "issue", issue.ID, "err", err)
+	} else if owner != "" && owner != p.Name {
+		eligibility.Eligible = false
+		eligibility.SkipReason = "routed elsewhere: policy routes to " + owner
+		return eligibility
+	}
+	reason, err := pol.Eligible(issue)
+	if err != nil {
+		slog.Warn("sync: policy eligible", "project", p.Name, "issue", issue.ID, "err", err)
+	} else if reason != "" {
+		eligibility.Eligible = false
+		eligibility.SkipReason = "policy: " + reason
+	}
+	return eligibility
+}
+
+func PolicyIssueEligibility(p *config.ProjectConfig, eligibility IssueEligibility, issue policy.Issue) IssueEligibility {
+	return policyIssueEligibility(p, eligibility, issue)
+}
+
 func normalizeLabelSet(labels []string) []string {
 	if len(labels) == 0 {
 		return nil
@@ -0,0 +1,212 @@
+package issuesync
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"autopr/internal/config"
+	"autopr/internal/db"
+	"autopr/internal/git"
+	"autopr/internal/policy"
+)
+
+const giteaPageLimit = 50
+
+func (s *Syncer) syncGitea(ctx context.Context, p *config.ProjectConfig) error {
+	if s.cfg.Tokens.Gitea == "" {
+		slog.Debug("sync: skipping gitea (no token)", "project", p.Name)
+		return nil
+	}
+
+	// Get cursor (last updated since timestamp).
+	cursor, err := s.store.GetCursor(ctx, p.Name, "gitea")
+	if err != nil {
+		return err
+	}
+
+	token := s.cfg.Tokens.Gitea
+
+	const maxPages = 50
+	var latestUpdated string
+
+	for page := 1; page <= maxPages; page++ {
+		params := giteaIssueQueryParams(cursor, page)
+		apiURL := git.GiteaAPIURL(p.Gitea.BaseURL, fmt.Sprintf("/repos/%s/%s/issues?%s", p.Gitea.Owner, p.Gitea.Repo, params.Encode()))
+
+		resp, err := git.DoGiteaRequest(ctx, token, "GET", apiURL, nil)
+		if err != nil {
+			return fmt.Errorf("fetch gitea issues: %w", err)
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+			resp.Body.Close()
+			return fmt.Errorf("gitea API %d: %s", resp.StatusCode, string(body))
+		}
+
+		var issues []giteaIssue
+		if err := json.NewDecoder(resp.Body).Decode(&issues); err != nil {
+			resp.Body.Close()
+			return fmt.Errorf("decode gitea issues: %w", err)
+		}
+		resp.Body.Close()
+
+		slog.Debug("sync: gitea issues fetched", "project", p.Name, "page", page, "count", len(issues))
+
+		if len(issues) == 0 {
+			break
+		}
+
+		// Gitea lists issues newest-first with no sort parameter, so the
+		// cursor tracks the latest update seen rather than the last row.
+		if lu := s.syncGiteaIssues(ctx, p, issues); laterTimestamp(lu, latestUpdated) {
+			latestUpdated = lu
+		}
+
+		if len(issues) < giteaPageLimit {
+			break
+		}
+	}
+
+	if latestUpdated != "" {
+		if err := s.store.SetCursor(ctx, p.Name, "gitea", latestUpdated); err != nil {
+			slog.Error("sync: set gitea cursor", "err", err)
+		}
+	}
+
+	return nil
+}
+
+func giteaIssueQueryParams(cursor string, page int) url.Values {
+	params := url.Values{
+		"state": {"open"},
+		"type":  {"issues"},
+		"page":  {strconv.Itoa(page)},
+		"limit": {strconv.Itoa(giteaPageLimit)},
+	}
+	if cursor != "" {
+		params.Set("state", "all")
+		params.Set("since", cursor)
+	}
+	return params
+}
+
+func (s *Syncer) syncGiteaIssues(ctx context.Context, p *config.ProjectConfig, issues []giteaIssue) string {
+	includeLabels := []string(nil)
+	if p.Gitea != nil {
+		includeLabels = p.Gitea.IncludeLabels
+	}
+	excludeLabels := p.ExcludeLabels
+
+	var latestUpdated string
+	for _, issue := range issues {
+		// Skip pull requests (older servers ignore type=issues).
+		if issue.PullRequest != nil {
+			continue
+		}
+
+		// Skip self-created issues.
+		if containsMarker(issue.Body) {
+			continue
+		}
+
+		labels := make([]string, 0, len(issue.Labels))
+		for _, l := range issue.Labels {
+			labels = append(labels, l.Name)
+		}
+
+		eligibility := evaluateIssueEligibility(includeLabels, excludeLabels, labels, time.Now().UTC())
+		eligibility = routeIssueEligibility(p, eligibility, labels)
+		eligibility = policyIssueEligibility(p, eligibility, policy.Issue{
+			Source: "gitea", ID: fmt.Sprintf("%d", issue.Number), Title: issue.Title, Body: issue.Body, URL: issue.HTMLURL, Labels: labels,
+		})
+		eligible := eligibility.Eligible
+		state := "open"
+		if issue.State == "closed" {
+			state = "closed"
+		}
+		sourceIssueID := fmt.Sprintf("%d", issue.Number)
+
+		ffid, err := s.store.UpsertIssue(ctx, db.IssueUpsert{
+			ProjectName:   p.Name,
+			Source:        "gitea",
+			SourceIssueID: sourceIssueID,
+			Title:         issue.Title,
+			Body:          issue.Body,
+			URL:           issue.HTMLURL,
+			State:         state,
+			Labels:        labels,
+			Eligible:      &eligible,
+			SkipReason:    eligibility.SkipReason,
+			EvaluatedAt:   eligibility.EvaluatedAt,
+			SourceUpdated: issue.UpdatedAt,
+			DueAt:         DueAt(issue.DueDate),
+		})
+		if err != nil {
+			slog.Error("sync: upsert gitea issue", "number", issue.Number, "err", err)
+			continue
+		}
+		if laterTimestamp(issue.UpdatedAt, latestUpdated) {
+			latestUpdated = issue.UpdatedAt
+		}
+
+		if state == "closed" {
+			s.cancelJobsForClosedIssue(ctx, p.Name, "gitea", sourceIssueID, ffid)
+			continue
+		}
+
+		if eligibility.Eligible {
+			synced := db.Issue{AutoPRIssueID: ffid, ProjectName: p.Name, Source: "gitea", SourceIssueID: sourceIssueID, SourceUpdated: issue.UpdatedAt}
+			s.syncIssueComments(ctx, synced)
+			s.syncIssueAttachments(ctx, synced)
+			s.createJobIfNeeded(ctx, ffid, p.Name)
+		} else {
+			slog.Info("sync: gitea issue skipped by label gate",
+				"project", p.Name,
+				"number", issue.Number,
+				"skip_reason", eligibility.SkipReason)
+		}
+	}
+
+	return latestUpdated
+}
+
+// laterTimestamp reports whether RFC 3339 timestamp a is after b. An empty b
+// is always earlier; unparseable values fall back to string comparison.
+func laterTimestamp(a, b string) bool {
+	if a == "" {
+		return false
+	}
+	if b == "" {
+		return true
+	}
+	ta, errA := time.Parse(time.RFC3339, a)
+	tb, errB := time.Parse(time.RFC3339, b)
+	if errA != nil || errB != nil {
+		return a > b
+	}
+	return ta.After(tb)
+}
+
+type giteaIssue struct {
+	Number      int          `json:"number"`
+	Title       string       `json:"title"`
+	Body        string       `json:"body"`
+	HTMLURL     string       `json:"html_url"`
+	State       string       `json:"state"`
+	Labels      []giteaLabel `json:"labels"`
+	UpdatedAt   string       `json:"updated_at"`
+	DueDate     string       `json:"due_date"`
+	PullRequest *struct{}    `json:"pull_request,omitempty"`
+}
+
+type giteaLabel struct {
+	Name string `json:"name"`
+}
@@ -0,0 +1,83 @@
+package issuesync
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"autopr/internal/config"
+)
+
+func TestSyncGiteaCreatesJobsAndAdvancesCursor(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	store := openTestStore(t)
+	defer store.Close()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/repos/org/repo/issues" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		q := r.URL.Query()
+		if q.Get("type") != "issues" || q.Get("state") != "open" || q.Get("since") != "" {
+			t.Errorf("unexpected query %q", r.URL.RawQuery)
+		}
+		// Newest first, as Gitea returns them.
+		_, _ = io.WriteString(w, `[
+			{"number":9,"title":"skip me","body":"b","html_url":"https://gitea.example.com/org/repo/issues/9","state":"open","labels":[],"updated_at":"2026-03-02T08:00:00+01:00"},
+			{"number":8,"title":"fix it","body":"b","html_url":"https://gitea.example.com/org/repo/issues/8","state":"open","labels":[{"name":"autopr"}],"updated_at":"2026-03-01T10:00:00Z"}]`)
+	}))
+	defer srv.Close()
+
+	cfg := &config.Config{
+		Tokens: config.TokensConfig{Gitea: "token"},
+		Daemon: config.DaemonConfig{MaxIterations: 3},
+	}
+	project := &config.ProjectConfig{
+		Name: "my-project",
+		Gitea: &config.ProjectGitea{
+			BaseURL:       srv.URL,
+			Owner:         "org",
+			Repo:          "repo",
+			IncludeLabels: []string{"autopr"},
+		},
+	}
+	syncer := NewSyncer(cfg, store, make(chan string, 8))
+
+	if err := syncer.syncGitea(ctx, project); err != nil {
+		t.Fatalf("sync gitea: %v", err)
+	}
+
+	if issue := getIssueBySourceID(t, ctx, store, "my-project", "gitea", "8"); !issue.Eligible {
+		t.Fatalf("expected labelled issue to be eligible")
+	}
+	if issue := getIssueBySourceID(t, ctx, store, "my-project", "gitea", "9"); issue.Eligible {
+		t.Fatalf("expected unlabelled issue to be ineligible")
+	}
+	if countJobs(t, ctx, store) != 1 {
+		t.Fatalf("expected one job for the eligible issue")
+	}
+	cursor, err := store.GetCursor(ctx, "my-project", "gitea")
+	if err != nil {
+		t.Fatalf("get cursor: %v", err)
+	}
+	if cursor != "2026-03-02T08:00:00+01:00" {
+		t.Fatalf("expected cursor at newest update, got %q", cursor)
+	}
+}
+
+func TestLaterTimestamp(t *testing.T) {
+	t.Parallel()
+
+	if !laterTimestamp("2026-03-01T10:00:00Z", "") {
+		t.Fatalf("expected any timestamp to beat empty")
+	}
+	if laterTimestamp("2026-03-01T10:00:00+02:00", "2026-03-01T09:00:00Z") {
+		t.Fatalf("expected offset-aware comparison")
+	}
+	if !laterTimestamp("2026-03-01T12:00:00+02:00", "2026-03-01T09:00:00Z") {
+		t.Fatalf("expected later timestamp to win")
+	}
+}
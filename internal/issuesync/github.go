@@ -13,11 +13,13 @@ import (
 
 	"autopr/internal/config"
 	"autopr/internal/db"
-	"autopr/internal/httputil"
+	"autopr/internal/git"
+	"autopr/internal/policy"
 )
 
 func (s *Syncer) syncGitHub(ctx context.Context, p *config.ProjectConfig) error {
-	if s.cfg.Tokens.GitHub == "" {
+	token := s.githubToken(ctx, p)
+	if token == "" {
 		slog.Debug("sync: skipping github (no token)", "project", p.Name)
 		return nil
 	}
@@ -33,24 +35,14 @@ func (s *Syncer) syncGitHub(ctx context.Context, p *config.ProjectConfig) error
 
 	params := githubIssueQueryParams(cursor)
 
-	nextURL := fmt.Sprintf("https://api.github.com/repos/%s/%s/issues?%s", owner, repo, params.Encode())
-	token := s.cfg.Tokens.GitHub
+	nextURL := fmt.Sprintf("%s/repos/%s/%s/issues?%s", git.NormalizeGitHubAPIBaseURL(p.GitHub.BaseURL), owner, repo, params.Encode())
 
 	const maxPages = 50
-	var latestUpdated string
 
 	for page := range maxPages {
 		currentURL := nextURL
 
-		resp, err := httputil.Do(ctx, func() (*http.Request, error) {
-			req, err := http.NewRequestWithContext(ctx, "GET", currentURL, nil)
-			if err != nil {
-				return nil, err
-			}
-			req.Header.Set("Authorization", "Bearer "+token)
-			req.Header.Set("Accept", "application/vnd.github+json")
-			return req, nil
-		}, httputil.DefaultRetryConfig())
+		resp, err := git.DoGitHubRequest(ctx, token, "GET", currentURL, nil)
 		if err != nil {
 			return fmt.Errorf("fetch github issues: %w", err)
 		}
@@ -76,8 +68,13 @@ func (s *Syncer) syncGitHub(ctx context.Context, p *config.ProjectConfig) error
 			break
 		}
 
+		// Issues come oldest update first, so checkpoint the cursor after
+		// every page: a sync that fails or stops at maxPages resumes here
+		// instead of refetching the pages already stored.
 		if lu := s.syncGitHubIssues(ctx, p, issues); lu != "" {
-			latestUpdated = lu
+			if err := s.store.SetCursor(ctx, p.Name, "github", lu); err != nil {
+				slog.Error("sync: set github cursor", "err", err)
+			}
 		}
 
 		nextURL = parseGitHubNextURL(linkHeader)
@@ -86,12 +83,6 @@ func (s *Syncer) syncGitHub(ctx context.Context, p *config.ProjectConfig) error
 		}
 	}
 
-	if latestUpdated != "" {
-		if err := s.store.SetCursor(ctx, p.Name, "github", latestUpdated); err != nil {
-			slog.Error("sync: set github cursor", "err", err)
-		}
-	}
-
 	return nil
 }
 
@@ -134,6 +125,10 @@ func (s *Syncer) syncGitHubIssues(ctx context.Context, p *config.ProjectConfig,
 		}
 
 		eligibility := evaluateIssueEligibility(includeLabels, excludeLabels, labels, time.Now().UTC())
+		eligibility = routeIssueEligibility(p, eligibility, labels)
+		eligibility = policyIssueEligibility(p, eligibility, policy.Issue{
+			Source: "github", ID: fmt.Sprintf("%d", issue.Number), Title: issue.Title, Body: issue.Body, URL: issue.HTMLURL, Labels: labels,
+		})
 		eligible := eligibility.Eligible
 		state := "open"
 		if issue.State == "closed" {
@@ -154,6 +149,7 @@ func (s *Syncer) syncGitHubIssues(ctx context.Context, p *config.ProjectConfig,
 			SkipReason:    eligibility.SkipReason,
 			EvaluatedAt:   eligibility.EvaluatedAt,
 			SourceUpdated: issue.UpdatedAt,
+			DueAt:         issue.dueAt(),
 		})
 		if err != nil {
 			slog.Error("sync: upsert github issue", "number", issue.Number, "err", err)
@@ -167,6 +163,9 @@ func (s *Syncer) syncGitHubIssues(ctx context.Context, p *config.ProjectConfig,
 		}
 
 		if eligibility.Eligible {
+			synced := db.Issue{AutoPRIssueID: ffid, ProjectName: p.Name, Source: "github", SourceIssueID: sourceIssueID, SourceUpdated: issue.UpdatedAt}
+			s.syncIssueComments(ctx, synced)
+			s.syncIssueAttachments(ctx, synced)
 			s.createJobIfNeeded(ctx, ffid, p.Name)
 		} else {
 			slog.Info("sync: github issue skipped by label gate",
@@ -181,14 +180,28 @@ func (s *Syncer) syncGitHubIssues(ctx context.Context, p *config.ProjectConfig,
 }
 
 type githubIssue struct {
-	Number      int           `json:"number"`
-	Title       string        `json:"title"`
-	Body        string        `json:"body"`
-	HTMLURL     string        `json:"html_url"`
-	State       string        `json:"state"`
-	Labels      []githubLabel `json:"labels"`
-	UpdatedAt   string        `json:"updated_at"`
-	PullRequest *struct{}     `json:"pull_request,omitempty"`
+	Number      int              `json:"number"`
+	Title       string           `json:"title"`
+	Body        string           `json:"body"`
+	HTMLURL     string           `json:"html_url"`
+	State       string           `json:"state"`
+	Labels      []githubLabel    `json:"labels"`
+	UpdatedAt   string           `json:"updated_at"`
+	Milestone   *githubMilestone `json:"milestone"`
+	PullRequest *struct{}        `json:"pull_request,omitempty"`
+}
+
+type githubMilestone struct {
+	DueOn string `json:"due_on"`
+}
+
+// dueAt returns the due date of the issue's milestone; GitHub issues have
+// none of their own.
+func (i githubIssue) dueAt() string {
+	if i.Milestone == nil {
+		return ""
+	}
+	return DueAt(i.Milestone.DueOn)
 }
 
 type githubLabel struct {
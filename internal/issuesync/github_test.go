@@ -2,6 +2,10 @@ package issuesync
 
 import (
 	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
 	"path/filepath"
 	"strings"
 	"testing"
@@ -9,6 +13,7 @@ import (
 
 	"autopr/internal/config"
 	"autopr/internal/db"
+	"autopr/internal/policy"
 )
 
 func TestEvaluateGitHubIssueEligibility(t *testing.T) {
@@ -75,6 +80,53 @@ func TestEvaluateGitHubIssueEligibility(t *testing.T) {
 	}
 }
 
+func TestPolicyIssueEligibility(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "policy.star")
+	script := `
+def eligible(issue):
+    if "question" in issue.labels:
+        return "questions are answered by people"
+    return True
+
+def route(issue):
+    if "frontend" in issue.labels:
+        return "web"
+`
+	if err := os.WriteFile(path, []byte(script), 0o644); err != nil {
+		t.Fatalf("write policy: %v", err)
+	}
+	p := &config.ProjectConfig{Name: "api", PolicyFile: path}
+	eligible := issueEligibility{Eligible: true}
+
+	tests := []struct {
+		labels     []string
+		wantReason string
+	}{
+		{labels: []string{"bug"}},
+		{labels: []string{"question"}, wantReason: "policy: questions are answered by people"},
+		{labels: []string{"frontend"}, wantReason: "routed elsewhere: policy routes to web"},
+	}
+	for _, tc := range tests {
+		got := policyIssueEligibility(p, eligible, policy.Issue{ID: "1", Labels: tc.labels})
+		if got.Eligible != (tc.wantReason == "") || got.SkipReason != tc.wantReason {
+			t.Fatalf("labels %v: got eligible=%v reason=%q, want reason %q", tc.labels, got.Eligible, got.SkipReason, tc.wantReason)
+		}
+	}
+
+	// Issues the built-in rules already skip keep their reason.
+	skipped := issueEligibility{Eligible: false, SkipReason: "missing required labels: autopr"}
+	if got := policyIssueEligibility(p, skipped, policy.Issue{Labels: []string{"question"}}); got.SkipReason != skipped.SkipReason {
+		t.Fatalf("skip reason overwritten: %q", got.SkipReason)
+	}
+
+	// A broken policy leaves the built-in decision in place.
+	broken := &config.ProjectConfig{Name: "api", PolicyFile: filepath.Join(t.TempDir(), "missing.star")}
+	if got := policyIssueEligibility(broken, eligible, policy.Issue{}); !got.Eligible {
+		t.Fatalf("missing policy file should not skip issues, got %q", got.SkipReason)
+	}
+}
+
 func TestSyncGitHubIssuesEligibilityTransitions(t *testing.T) {
 	t.Parallel()
 	ctx := context.Background()
@@ -177,6 +229,76 @@ func TestSyncGitHubIssuesEligibilityTransitions(t *testing.T) {
 	}
 }
 
+func TestSyncGitHubIssuesRoutesToMonorepoSubProject(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	store := openTestStore(t)
+	defer store.Close()
+
+	cfg := &config.Config{Daemon: config.DaemonConfig{MaxIterations: 3}}
+	newProject := func(name, routeLabel string) *config.ProjectConfig {
+		return &config.ProjectConfig{
+			Name:   name,
+			GitHub: &config.ProjectGitHub{Owner: "org", Repo: "mono", IncludeLabels: []string{"autopr"}},
+			Scope:  &config.ProjectScope{Path: "services/" + name, RouteLabels: []string{routeLabel}},
+		}
+	}
+	api, web := newProject("api", "area:api"), newProject("web", "area:web")
+	syncer := NewSyncer(cfg, store, make(chan string, 8))
+
+	issues := []githubIssue{{
+		Number:    12,
+		Title:     "500 on login",
+		HTMLURL:   "https://github.com/org/mono/issues/12",
+		UpdatedAt: "2026-02-17T10:00:00Z",
+		Labels:    []githubLabel{{Name: "autopr"}, {Name: "Area:API"}},
+	}}
+	syncer.syncGitHubIssues(ctx, api, issues)
+	syncer.syncGitHubIssues(ctx, web, issues)
+
+	if issue := getIssueBySourceID(t, ctx, store, "api", "github", "12"); !issue.Eligible {
+		t.Fatalf("expected issue routed to api, skip reason %q", issue.SkipReason)
+	}
+	issue := getIssueBySourceID(t, ctx, store, "web", "github", "12")
+	if issue.Eligible || !strings.Contains(issue.SkipReason, "route labels: area:web") {
+		t.Fatalf("expected web to skip the issue, got eligible=%v reason=%q", issue.Eligible, issue.SkipReason)
+	}
+	if countJobs(t, ctx, store) != 1 {
+		t.Fatalf("expected exactly one job across sub-projects")
+	}
+}
+
+func TestSyncGitHubIssuesSyncsCommentsAndAttachmentsOfEligibleIssues(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	store := openTestStore(t)
+	defer store.Close()
+
+	cfg := &config.Config{Daemon: config.DaemonConfig{MaxIterations: 3}}
+	project := &config.ProjectConfig{
+		Name:   "my-project",
+		GitHub: &config.ProjectGitHub{Owner: "org", Repo: "repo", IncludeLabels: []string{"autopr"}},
+	}
+	syncer := NewSyncer(cfg, store, make(chan string, 8))
+	var synced []db.Issue
+	var attached []string
+	syncer.syncIssueComments = func(_ context.Context, issue db.Issue) { synced = append(synced, issue) }
+	syncer.syncIssueAttachments = func(_ context.Context, issue db.Issue) { attached = append(attached, issue.SourceIssueID) }
+
+	syncer.syncGitHubIssues(ctx, project, []githubIssue{
+		{Number: 5, Title: "eligible", HTMLURL: "https://github.com/org/repo/issues/5", UpdatedAt: "2026-02-17T11:00:00Z", Labels: []githubLabel{{Name: "autopr"}}},
+		{Number: 6, Title: "not labelled", HTMLURL: "https://github.com/org/repo/issues/6", UpdatedAt: "2026-02-17T11:00:00Z"},
+		{Number: 7, Title: "closed", HTMLURL: "https://github.com/org/repo/issues/7", State: "closed", UpdatedAt: "2026-02-17T11:00:00Z", Labels: []githubLabel{{Name: "autopr"}}},
+	})
+
+	if len(synced) != 1 || synced[0].SourceIssueID != "5" || synced[0].SourceUpdated != "2026-02-17T11:00:00Z" || synced[0].AutoPRIssueID == "" {
+		t.Fatalf("expected comments synced for the eligible open issue only, got %+v", synced)
+	}
+	if len(attached) != 1 || attached[0] != "5" {
+		t.Fatalf("expected attachments synced for the eligible open issue only, got %v", attached)
+	}
+}
+
 func TestSyncGitHubIssuesIdempotentWhileActiveJobExists(t *testing.T) {
 	t.Parallel()
 	ctx := context.Background()
@@ -645,3 +767,45 @@ func TestSyncGitHubPaginates(t *testing.T) {
 		_ = getIssueBySourceID(t, ctx, store, "paginate-test", "github", num)
 	}
 }
+
+func TestSyncGitHubCheckpointsCursorPerPage(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	store := openTestStore(t)
+	defer store.Close()
+
+	var srvURL string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") == "2" {
+			http.Error(w, "gone", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Link", `<`+srvURL+r.URL.Path+`?page=2>; rel="next"`)
+		_, _ = io.WriteString(w, `[
+			{"number":1,"title":"one","body":"b","html_url":"https://github.com/o/r/issues/1","state":"open","updated_at":"2026-02-17T10:00:00Z"},
+			{"number":2,"title":"two","body":"b","html_url":"https://github.com/o/r/issues/2","state":"open","updated_at":"2026-02-17T10:01:00Z"}]`)
+	}))
+	defer srv.Close()
+	srvURL = srv.URL
+
+	cfg := &config.Config{
+		Tokens: config.TokensConfig{GitHub: "test-token"},
+		Daemon: config.DaemonConfig{MaxIterations: 3},
+	}
+	project := &config.ProjectConfig{
+		Name:   "checkpoint-test",
+		GitHub: &config.ProjectGitHub{BaseURL: srv.URL, Owner: "o", Repo: "r"},
+	}
+	syncer := NewSyncer(cfg, store, make(chan string, 8))
+
+	if err := syncer.syncGitHub(ctx, project); err == nil {
+		t.Fatal("expected the failing second page to fail the sync")
+	}
+	cursor, err := store.GetCursor(ctx, "checkpoint-test", "github")
+	if err != nil {
+		t.Fatalf("get cursor: %v", err)
+	}
+	if cursor != "2026-02-17T10:01:00Z" {
+		t.Fatalf("expected cursor checkpointed after the first page, got %q", cursor)
+	}
+}
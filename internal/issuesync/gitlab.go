@@ -14,6 +14,7 @@ import (
 	"autopr/internal/config"
 	"autopr/internal/db"
 	"autopr/internal/httputil"
+	"autopr/internal/policy"
 )
 
 func (s *Syncer) syncGitLab(ctx context.Context, p *config.ProjectConfig) error {
@@ -48,7 +49,6 @@ func (s *Syncer) syncGitLab(ctx context.Context, p *config.ProjectConfig) error
 	token := s.cfg.Tokens.GitLab
 
 	const maxPages = 50
-	var latestUpdated string
 	nextPage := "1"
 
 	for page := range maxPages {
@@ -87,8 +87,12 @@ func (s *Syncer) syncGitLab(ctx context.Context, p *config.ProjectConfig) error
 			break
 		}
 
+		// Checkpoint after every page, as for GitHub: issues come oldest
+		// update first.
 		if lu := s.syncGitLabPage(ctx, p, issues); lu != "" {
-			latestUpdated = lu
+			if err := s.store.SetCursor(ctx, p.Name, "gitlab", lu); err != nil {
+				slog.Error("sync: set gitlab cursor", "err", err)
+			}
 		}
 
 		nextPage = strings.TrimSpace(xNextPage)
@@ -97,13 +101,6 @@ func (s *Syncer) syncGitLab(ctx context.Context, p *config.ProjectConfig) error
 		}
 	}
 
-	// Update cursor.
-	if latestUpdated != "" {
-		if err := s.store.SetCursor(ctx, p.Name, "gitlab", latestUpdated); err != nil {
-			slog.Error("sync: set gitlab cursor", "err", err)
-		}
-	}
-
 	return nil
 }
 
@@ -125,6 +122,10 @@ func (s *Syncer) syncGitLabPage(ctx context.Context, p *config.ProjectConfig, is
 		labels = append(labels, issue.Labels...)
 
 		eligibility := evaluateIssueEligibility(includeLabels, excludeLabels, labels, time.Now().UTC())
+		eligibility = routeIssueEligibility(p, eligibility, labels)
+		eligibility = policyIssueEligibility(p, eligibility, policy.Issue{
+			Source: "gitlab", ID: fmt.Sprintf("%d", issue.IID), Title: issue.Title, Body: issue.Description, URL: issue.WebURL, Labels: labels,
+		})
 		eligible := eligibility.Eligible
 
 		ffid, err := s.store.UpsertIssue(ctx, db.IssueUpsert{
@@ -140,6 +141,7 @@ func (s *Syncer) syncGitLabPage(ctx context.Context, p *config.ProjectConfig, is
 			SkipReason:    eligibility.SkipReason,
 			EvaluatedAt:   eligibility.EvaluatedAt,
 			SourceUpdated: issue.UpdatedAt,
+			DueAt:         DueAt(issue.DueDate),
 		})
 		if err != nil {
 			slog.Error("sync: upsert gitlab issue", "iid", issue.IID, "err", err)
@@ -147,6 +149,9 @@ func (s *Syncer) syncGitLabPage(ctx context.Context, p *config.ProjectConfig, is
 		}
 
 		if eligibility.Eligible {
+			synced := db.Issue{AutoPRIssueID: ffid, ProjectName: p.Name, Source: "gitlab", SourceIssueID: fmt.Sprintf("%d", issue.IID), SourceUpdated: issue.UpdatedAt}
+			s.syncIssueComments(ctx, synced)
+			s.syncIssueAttachments(ctx, synced)
 			s.createJobIfNeeded(ctx, ffid, p.Name)
 		} else {
 			slog.Info("sync: gitlab issue skipped by label gate",
@@ -169,6 +174,7 @@ type gitlabIssue struct {
 	Labels      []string `json:"labels"`
 	UpdatedAt   string   `json:"updated_at"`
 	CreatedAt   string   `json:"created_at"`
+	DueDate     string   `json:"due_date"`
 }
 
 func containsMarker(s string) bool {
@@ -0,0 +1,175 @@
+package issuesync
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"autopr/internal/config"
+	"autopr/internal/db"
+	"autopr/internal/pipeline"
+)
+
+// localFileIssuePrefix starts the source issue ID of issues-file items.
+const localFileIssuePrefix = "file-"
+
+// localTaskPattern matches a markdown task item: "- [ ] title" or "* [x] title".
+var localTaskPattern = regexp.MustCompile(`^[-*+]\s+\[([ xX])\]\s+(.+?)\s*$`)
+
+// localIssue is one task item parsed from a local project's issues file.
+type localIssue struct {
+	ID    string // stable across syncs while the title is unchanged
+	Title string
+	Body  string // indented lines under the item
+	Line  int
+	Done  bool
+}
+
+// parseLocalIssues reads markdown task items. Lines indented under an item
+// form its body; anything else (headings, prose) is ignored.
+func parseLocalIssues(content string) []localIssue {
+	var issues []localIssue
+	var body []string
+	seen := map[string]int{}
+	flush := func() {
+		if len(issues) > 0 {
+			issues[len(issues)-1].Body = strings.TrimSpace(strings.Join(body, "\n"))
+		}
+		body = nil
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	inItem := false
+	for n := 1; scanner.Scan(); n++ {
+		line := scanner.Text()
+		if m := localTaskPattern.FindStringSubmatch(line); m != nil {
+			flush()
+			id := localIssueSlug(m[2])
+			seen[id]++
+			if seen[id] > 1 {
+				id += "-" + strconv.Itoa(seen[id])
+			}
+			issues = append(issues, localIssue{ID: id, Title: m[2], Line: n, Done: m[1] != " "})
+			inItem = true
+			continue
+		}
+		switch {
+		case !inItem:
+		case strings.TrimSpace(line) == "":
+			body = append(body, "")
+		case line[0] == ' ' || line[0] == '\t':
+			body = append(body, strings.TrimSpace(line))
+		default:
+			flush()
+			inItem = false
+		}
+	}
+	flush()
+	return issues
+}
+
+// localIssueSlug derives an issue ID from its title.
+func localIssueSlug(title string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(title) {
+		switch {
+		case r >= 'a' && r <= 'z' || r >= '0' && r <= '9':
+			b.WriteRune(r)
+			dash = false
+		case !dash && b.Len() > 0:
+			b.WriteByte('-')
+			dash = true
+		}
+		if b.Len() >= 60 {
+			break
+		}
+	}
+	slug := strings.Trim(b.String(), "-")
+	if slug == "" {
+		slug = "item"
+	}
+	return slug
+}
+
+// syncLocal upserts the task items of a local project's issues file. Unchecked
+// items are open and get a job; checked or removed items are closed, which
+// cancels their unfinished jobs. Issues from `ap run` are left alone.
+func (s *Syncer) syncLocal(ctx context.Context, p *config.ProjectConfig) error {
+	path := p.Local.IssuesFile
+	if path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read issues file: %w", err)
+	}
+	updated := time.Now().UTC().Format(time.RFC3339)
+	if info, err := os.Stat(path); err == nil {
+		updated = info.ModTime().UTC().Format(time.RFC3339)
+	}
+
+	items := parseLocalIssues(string(data))
+	present := make(map[string]bool, len(items))
+	for _, item := range items {
+		sourceIssueID := localFileIssuePrefix + item.ID
+		present[sourceIssueID] = true
+		state := "open"
+		if item.Done {
+			state = "closed"
+		}
+		ffid, err := s.store.UpsertIssue(ctx, db.IssueUpsert{
+			ProjectName:   p.Name,
+			Source:        pipeline.LocalSource,
+			SourceIssueID: sourceIssueID,
+			Title:         item.Title,
+			Body:          item.Body,
+			URL:           fmt.Sprintf("file://%s#L%d", path, item.Line),
+			State:         state,
+			SourceUpdated: updated,
+		})
+		if err != nil {
+			slog.Error("sync: upsert local issue", "project", p.Name, "line", item.Line, "err", err)
+			continue
+		}
+		if state == "closed" {
+			s.cancelJobsForClosedIssue(ctx, p.Name, pipeline.LocalSource, sourceIssueID, ffid)
+			continue
+		}
+		s.createJobIfNeeded(ctx, ffid, p.Name)
+	}
+
+	known, err := s.store.ListIssues(ctx, p.Name, nil)
+	if err != nil {
+		return err
+	}
+	for _, issue := range known {
+		if issue.Source != pipeline.LocalSource || issue.State != "open" ||
+			!strings.HasPrefix(issue.SourceIssueID, localFileIssuePrefix) || present[issue.SourceIssueID] {
+			continue
+		}
+		ffid, err := s.store.UpsertIssue(ctx, db.IssueUpsert{
+			ProjectName:   p.Name,
+			Source:        pipeline.LocalSource,
+			SourceIssueID: issue.SourceIssueID,
+			Title:         issue.Title,
+			Body:          issue.Body,
+			URL:           issue.URL,
+			State:         "closed",
+			SourceUpdated: updated,
+		})
+		if err != nil {
+			slog.Error("sync: close removed local issue", "project", p.Name, "issue", issue.SourceIssueID, "err", err)
+			continue
+		}
+		s.cancelJobsForClosedIssue(ctx, p.Name, pipeline.LocalSource, issue.SourceIssueID, ffid)
+	}
+	return nil
+}
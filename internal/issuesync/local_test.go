@@ -0,0 +1,112 @@
+package issuesync
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"autopr/internal/config"
+	"autopr/internal/pipeline"
+)
+
+func TestParseLocalIssues(t *testing.T) {
+	t.Parallel()
+	content := `# TODO
+
+Some notes that are not tasks.
+
+- [ ] Fix login redirect
+  Users land on /home instead of the page they asked for.
+
+  Keep the query string.
+- [x] Add CSV export
+* [ ] Fix login redirect
+Trailing prose ends the body.
+`
+	got := parseLocalIssues(content)
+	if len(got) != 3 {
+		t.Fatalf("expected 3 items, got %+v", got)
+	}
+	if got[0].ID != "fix-login-redirect" || got[0].Line != 5 || got[0].Done {
+		t.Fatalf("unexpected first item %+v", got[0])
+	}
+	if got[0].Body != "Users land on /home instead of the page they asked for.\n\nKeep the query string." {
+		t.Fatalf("unexpected body %q", got[0].Body)
+	}
+	if !got[1].Done || got[1].ID != "add-csv-export" {
+		t.Fatalf("expected checked item closed, got %+v", got[1])
+	}
+	if got[2].ID != "fix-login-redirect-2" || got[2].Body != "" {
+		t.Fatalf("expected duplicate title disambiguated with empty body, got %+v", got[2])
+	}
+}
+
+func TestSyncLocalOpensAndClosesIssues(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	store := openTestStore(t)
+	defer store.Close()
+
+	path := filepath.Join(t.TempDir(), "TODO.md")
+	write := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("write issues file: %v", err)
+		}
+	}
+	write("- [ ] first task\n- [ ] second task\n")
+
+	cfg := &config.Config{Daemon: config.DaemonConfig{MaxIterations: 3}}
+	project := &config.ProjectConfig{Name: "local-proj", Local: &config.ProjectLocal{IssuesFile: path}}
+	syncer := NewSyncer(cfg, store, make(chan string, 8))
+
+	manual, err := pipeline.CreateManualJob(ctx, store, project, 3, "manual task", "")
+	if err != nil {
+		t.Fatalf("create manual job: %v", err)
+	}
+
+	if err := syncer.syncLocal(ctx, project); err != nil {
+		t.Fatalf("sync local: %v", err)
+	}
+	if countJobs(t, ctx, store) != 3 {
+		t.Fatalf("expected a job per open item plus the manual job")
+	}
+	first := getIssueBySourceID(t, ctx, store, "local-proj", pipeline.LocalSource, "file-first-task")
+	if first.State != "open" || first.URL != "file://"+path+"#L1" {
+		t.Fatalf("unexpected first issue %+v", first)
+	}
+
+	// Check off the first item and delete the second.
+	write("- [x] first task\n")
+	if err := syncer.syncLocal(ctx, project); err != nil {
+		t.Fatalf("resync local: %v", err)
+	}
+	for _, id := range []string{"file-first-task", "file-second-task"} {
+		if issue := getIssueBySourceID(t, ctx, store, "local-proj", pipeline.LocalSource, id); issue.State != "closed" {
+			t.Fatalf("expected %s closed, got %q", id, issue.State)
+		}
+	}
+	jobs, err := store.ListJobs(ctx, "local-proj", "cancelled", "updated_at", false)
+	if err != nil {
+		t.Fatalf("list jobs: %v", err)
+	}
+	if len(jobs) != 2 {
+		t.Fatalf("expected jobs of closed items cancelled, got %d", len(jobs))
+	}
+	job, err := store.GetJob(ctx, manual)
+	if err != nil || job.State != "queued" {
+		t.Fatalf("expected manual job untouched, got %q (err %v)", job.State, err)
+	}
+}
+
+func TestSyncLocalMissingFile(t *testing.T) {
+	t.Parallel()
+	store := openTestStore(t)
+	defer store.Close()
+	project := &config.ProjectConfig{Name: "local-proj", Local: &config.ProjectLocal{IssuesFile: filepath.Join(t.TempDir(), "missing.md")}}
+	syncer := NewSyncer(&config.Config{}, store, make(chan string, 1))
+	if err := syncer.syncLocal(context.Background(), project); err == nil {
+		t.Fatal("expected error for missing issues file")
+	}
+}
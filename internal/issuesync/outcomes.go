@@ -0,0 +1,115 @@
+package issuesync
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"unicode"
+
+	"autopr/internal/config"
+	"autopr/internal/db"
+	"autopr/internal/git"
+)
+
+// recordPROutcome stores when a just-merged PR was opened and first approved,
+// the start of its review outcome record. Approval lookups are best-effort:
+// without an approving review (or on GitLab) the merge counts as approval.
+func (s *Syncer) recordPROutcome(ctx context.Context, job db.Job, proj *config.ProjectConfig, status git.PRMergeStatus, mergedAt string) {
+	approvedAt := ""
+	var err error
+	switch {
+	case proj.GitHub != nil && strings.Contains(job.PRURL, "/pull/"):
+		if token := s.githubToken(ctx, proj); token != "" {
+			approvedAt, err = s.firstGitHubApproval(ctx, token, proj.GitHub.BaseURL, job.PRURL)
+		}
+	case proj.Gitea != nil && strings.Contains(job.PRURL, "/pulls/"):
+		approvedAt, err = s.firstGiteaApproval(ctx, s.cfg.Tokens.Gitea, proj.Gitea.BaseURL, job.PRURL)
+	}
+	if err != nil {
+		slog.Warn("look up PR approval", "job", db.ShortID(job.ID), "err", err)
+	}
+	if approvedAt == "" {
+		approvedAt = mergedAt
+	}
+	openedAt := status.CreatedAt
+	if openedAt == "" {
+		openedAt = job.CompletedAt
+	}
+	if err := s.store.RecordPROutcome(ctx, db.PROutcome{
+		JobID:       job.ID,
+		ProjectName: job.ProjectName,
+		PRURL:       job.PRURL,
+		OpenedAt:    openedAt,
+		ApprovedAt:  approvedAt,
+		MergedAt:    mergedAt,
+	}); err != nil {
+		slog.Error("record PR outcome", "job", db.ShortID(job.ID), "err", err)
+	}
+}
+
+// trackPROutcomes links later work back to merged PRs: revert and follow-up
+// jobs created from them, and synced issues that link the PR. Issues whose
+// title starts with "revert" count as reverts, others as follow-up fixes.
+func (s *Syncer) trackPROutcomes(ctx context.Context) {
+	if n, err := s.store.LinkChildJobOutcomes(ctx); err != nil {
+		slog.Warn("link child job outcomes", "err", err)
+	} else if n > 0 {
+		slog.Debug("linked jobs to PR outcomes", "count", n)
+	}
+
+	outcomes, err := s.store.ListPROutcomes(ctx, "", "", false)
+	if err != nil {
+		slog.Warn("list PR outcomes", "err", err)
+		return
+	}
+	issuesByProject := map[string][]db.Issue{}
+	for _, o := range outcomes {
+		issues, ok := issuesByProject[o.ProjectName]
+		if !ok {
+			issues, err = s.store.ListIssues(ctx, o.ProjectName, nil)
+			if err != nil {
+				slog.Warn("list issues for PR outcomes", "project", o.ProjectName, "err", err)
+				continue
+			}
+			issuesByProject[o.ProjectName] = issues
+		}
+		for _, issue := range issues {
+			if issue.AutoPRIssueID == o.AutoPRIssueID || !referencesURL(issue.Title+"\n"+issue.Body, o.PRURL) {
+				continue
+			}
+			kind := "followup"
+			if strings.HasPrefix(strings.ToLower(strings.TrimSpace(issue.Title)), "revert") {
+				kind = "revert"
+			}
+			added, err := s.store.AddPROutcomeRef(ctx, o.JobID, kind, "issue:"+issue.AutoPRIssueID)
+			if err != nil {
+				slog.Warn("add PR outcome ref", "job", db.ShortID(o.JobID), "err", err)
+				continue
+			}
+			if added {
+				slog.Info("issue references merged PR", "job", db.ShortID(o.JobID), "issue", issue.URL, "kind", kind)
+			}
+		}
+	}
+}
+
+// referencesURL reports whether text contains url as a whole link, so
+// ".../pull/1" does not match ".../pull/12".
+func referencesURL(text, url string) bool {
+	if url == "" {
+		return false
+	}
+	for rest := text; ; {
+		i := strings.Index(rest, url)
+		if i < 0 {
+			return false
+		}
+		rest = rest[i+len(url):]
+		if rest == "" {
+			return true
+		}
+		if r := rune(rest[0]); !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			return true
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package issuesync
+
+import (
+	"context"
+	"testing"
+
+	"autopr/internal/config"
+	"autopr/internal/db"
+	"autopr/internal/git"
+)
+
+func TestMergedPRRecordsOutcomeAndLinksReferencingIssues(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	store := openTestStore(t)
+	defer store.Close()
+
+	prURL := "https://github.com/acme/repo/pull/7"
+	jobID := createSyncTestJob(t, ctx, store, "project-gh", "outcome", "approved", "autopr/outcome", prURL)
+
+	cfg := &config.Config{
+		Tokens: config.TokensConfig{GitHub: "token"},
+		Projects: []config.ProjectConfig{
+			{Name: "project-gh", GitHub: &config.ProjectGitHub{Owner: "acme", Repo: "repo"}},
+		},
+	}
+	s := NewSyncer(cfg, store, make(chan string, 1))
+	s.checkGitHubPRStatus = func(ctx context.Context, token, baseURL, prURL string) (git.PRMergeStatus, error) {
+		return git.PRMergeStatus{Merged: true, CreatedAt: "2026-02-18T00:00:00Z", MergedAt: "2026-02-18T05:00:00Z"}, nil
+	}
+	s.firstGitHubApproval = func(ctx context.Context, token, baseURL, got string) (string, error) {
+		if got != prURL {
+			t.Fatalf("unexpected PR URL: %q", got)
+		}
+		return "2026-02-18T03:00:00Z", nil
+	}
+
+	s.checkPRStatus(ctx)
+
+	for _, in := range []db.IssueUpsert{
+		{ProjectName: "project-gh", Source: "github", SourceIssueID: "8", Title: "Crash after #7", Body: "Regressed in " + prURL + ".", State: "open"},
+		{ProjectName: "project-gh", Source: "github", SourceIssueID: "9", Title: "Revert login change", Body: "See " + prURL, State: "open"},
+		{ProjectName: "project-gh", Source: "github", SourceIssueID: "10", Title: "Unrelated", Body: "Mentions " + prURL + "0 only", State: "open"},
+	} {
+		if _, err := store.UpsertIssue(ctx, in); err != nil {
+			t.Fatalf("upsert issue: %v", err)
+		}
+	}
+	s.trackPROutcomes(ctx)
+	s.trackPROutcomes(ctx)
+
+	outcomes, err := store.ListPROutcomes(ctx, "", "", false)
+	if err != nil {
+		t.Fatalf("list outcomes: %v", err)
+	}
+	if len(outcomes) != 1 {
+		t.Fatalf("expected one outcome, got %+v", outcomes)
+	}
+	o := outcomes[0]
+	if o.JobID != jobID || o.OpenedAt != "2026-02-18T00:00:00Z" || o.ApprovedAt != "2026-02-18T03:00:00Z" || o.MergedAt != "2026-02-18T05:00:00Z" {
+		t.Fatalf("unexpected outcome timeline: %+v", o)
+	}
+	if !o.Reverted || o.FollowUps != 1 {
+		t.Fatalf("expected one revert and one follow-up, got %+v", o)
+	}
+}
+
+func TestReferencesURLRequiresWholeLink(t *testing.T) {
+	t.Parallel()
+
+	url := "https://github.com/acme/repo/pull/1"
+	cases := map[string]bool{
+		"broken by " + url:         true,
+		"(" + url + ")":            true,
+		"see " + url + "2 instead": false,
+		url + "2 and later " + url: true,
+		"nothing here":             false,
+	}
+	for text, want := range cases {
+		if got := referencesURL(text, url); got != want {
+			t.Errorf("referencesURL(%q) = %v, want %v", text, got, want)
+		}
+	}
+}
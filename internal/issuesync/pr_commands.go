@@ -0,0 +1,281 @@
+package issuesync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"autopr/internal/config"
+	"autopr/internal/db"
+	"autopr/internal/git"
+	"autopr/internal/pipeline"
+)
+
+// PR comment commands. A comment whose first line starts with /autopr drives
+// the job behind the PR: "/autopr rebase" and "/autopr close" act on the PR,
+// "/autopr help" lists the commands, and anything else is feedback for
+// another iteration.
+const (
+	prCommandPrefix = "/autopr"
+
+	prActionFeedback = "feedback"
+	prActionRebase   = "rebase"
+	prActionClose    = "close"
+	prActionHelp     = "help"
+)
+
+const prCommandHelp = "AutoPR commands, for people with write access to this repository:\n\n" +
+	"- `/autopr <instructions>`: run another iteration with these instructions and push it to this PR\n" +
+	"- `/autopr rebase`: rebase the branch onto its base branch and push it\n" +
+	"- `/autopr close`: close this PR and delete its branch"
+
+// prCommand is a parsed /autopr comment.
+type prCommand struct {
+	action string
+	text   string // feedback instructions
+}
+
+// parsePRCommand reads a /autopr command from a comment body. It reports
+// false for comments that are not commands.
+func parsePRCommand(body string) (prCommand, bool) {
+	body = strings.TrimSpace(strings.ReplaceAll(body, "\r\n", "\n"))
+	first, rest, _ := strings.Cut(body, "\n")
+	first = strings.TrimSpace(first)
+	args, ok := strings.CutPrefix(first, prCommandPrefix)
+	if !ok || (args != "" && args[0] != ' ' && args[0] != '\t') {
+		return prCommand{}, false
+	}
+	args = strings.TrimSpace(args)
+	rest = strings.TrimSpace(rest)
+
+	switch strings.ToLower(args) {
+	case prActionRebase, prActionClose, prActionHelp:
+		return prCommand{action: strings.ToLower(args)}, true
+	case "":
+		if rest == "" {
+			return prCommand{action: prActionHelp}, true
+		}
+	}
+	text := strings.TrimSpace(args + "\n" + rest)
+	return prCommand{action: prActionFeedback, text: text}, true
+}
+
+// prForge is the PR comment API of a project's forge. GitLab is not
+// supported.
+type prForge struct {
+	token          string
+	baseURL        string
+	listComments   func(ctx context.Context, token, baseURL, prURL string) ([]git.IssueComment, error)
+	comment        func(ctx context.Context, token, baseURL, prURL, body string) error
+	permission     func(ctx context.Context, token, baseURL, prURL, user string) (string, error)
+	closePR        func(ctx context.Context, token, baseURL, prURL string) error
+	prURLSeparator string
+}
+
+func (s *Syncer) prForgeFor(ctx context.Context, proj *config.ProjectConfig) (prForge, bool) {
+	switch {
+	case proj.GitHub != nil:
+		token := s.githubToken(ctx, proj)
+		return prForge{
+			token:          token,
+			baseURL:        proj.GitHub.BaseURL,
+			listComments:   git.ListGitHubPRComments,
+			comment:        git.CommentGitHubPR,
+			permission:     git.GitHubRepoPermission,
+			closePR:        git.CloseGitHubPR,
+			prURLSeparator: "/pull/",
+		}, token != ""
+	case proj.Gitea != nil:
+		return prForge{
+			token:          s.cfg.Tokens.Gitea,
+			baseURL:        proj.Gitea.BaseURL,
+			listComments:   git.ListGiteaPRComments,
+			comment:        git.CommentGiteaPR,
+			permission:     git.GiteaRepoPermission,
+			closePR:        git.CloseGiteaPR,
+			prURLSeparator: "/pulls/",
+		}, s.cfg.Tokens.Gitea != ""
+	}
+	return prForge{}, false
+}
+
+// checkPRCommands acts on new /autopr comments on the open PRs of approved
+// jobs in the projects in due (all projects when due is nil).
+func (s *Syncer) checkPRCommands(ctx context.Context, due map[string]bool) {
+	if !s.cfg.Daemon.PRCommands {
+		return
+	}
+	jobs, err := s.store.ListApprovedJobsWithPR(ctx)
+	if err != nil {
+		slog.Error("pr commands: list approved jobs", "err", err)
+		return
+	}
+	if due != nil {
+		jobs = jobsOfProjects(jobs, due)
+	}
+	for _, job := range jobs {
+		proj, ok := s.cfg.ProjectByName(job.ProjectName)
+		if !ok {
+			continue
+		}
+		forge, ok := s.prForgeFor(ctx, proj)
+		if !ok || !strings.Contains(job.PRURL, forge.prURLSeparator) {
+			continue
+		}
+		s.checkJobPRCommands(ctx, job, proj, forge)
+	}
+}
+
+func (s *Syncer) checkJobPRCommands(ctx context.Context, job db.Job, proj *config.ProjectConfig, forge prForge) {
+	cursor, err := s.store.JobPRCommentCursor(ctx, job.ID)
+	if err != nil {
+		slog.Warn("pr commands: read cursor", "job", db.ShortID(job.ID), "err", err)
+		return
+	}
+	comments, err := forge.listComments(ctx, forge.token, forge.baseURL, job.PRURL)
+	if err != nil {
+		slog.Warn("pr commands: list comments", "job", db.ShortID(job.ID), "err", err)
+		return
+	}
+
+	if cursor == 0 {
+		// The PR was opened before pr_commands was turned on. Its comments
+		// so far are history, not requests: start after them.
+		newest := db.PRCommentCursorStart
+		for _, c := range comments {
+			newest = max(newest, c.ID)
+		}
+		if err := s.store.SetJobPRCommentCursor(ctx, job.ID, newest); err != nil {
+			slog.Warn("pr commands: save cursor", "job", db.ShortID(job.ID), "err", err)
+		}
+		return
+	}
+
+	newest := cursor
+	for _, c := range comments {
+		if c.ID <= cursor {
+			continue
+		}
+		newest = max(newest, c.ID)
+		cmd, ok := parsePRCommand(c.Body)
+		if !ok {
+			continue
+		}
+		slog.Info("pr command", "job", db.ShortID(job.ID), "author", c.Author, "action", cmd.action)
+		done := s.runPRCommand(ctx, job, proj, forge, c, cmd)
+		if done {
+			// The job has left approved. Comments after this one are read
+			// when it is back, after the new iteration.
+			newest = c.ID
+			break
+		}
+	}
+	if newest > cursor {
+		if err := s.store.SetJobPRCommentCursor(ctx, job.ID, newest); err != nil {
+			slog.Warn("pr commands: save cursor", "job", db.ShortID(job.ID), "err", err)
+		}
+	}
+}
+
+// runPRCommand carries out one command and answers it on the PR. It reports
+// whether the job left the approved state.
+func (s *Syncer) runPRCommand(ctx context.Context, job db.Job, proj *config.ProjectConfig, forge prForge, c git.IssueComment, cmd prCommand) bool {
+	reply := func(format string, args ...any) {
+		body := "AutoPR: " + fmt.Sprintf(format, args...)
+		if err := forge.comment(ctx, forge.token, forge.baseURL, job.PRURL, body); err != nil {
+			slog.Warn("pr commands: reply", "job", db.ShortID(job.ID), "err", err)
+		}
+	}
+
+	if cmd.action == prActionHelp {
+		reply("%s", prCommandHelp)
+		return false
+	}
+	permission, err := forge.permission(ctx, forge.token, forge.baseURL, job.PRURL, c.Author)
+	if err != nil {
+		slog.Warn("pr commands: check permission", "job", db.ShortID(job.ID), "author", c.Author, "err", err)
+		reply("could not check @%s's permission on this repository, so `%s %s` was ignored.", c.Author, prCommandPrefix, cmd.action)
+		return false
+	}
+	if !git.CanPush(permission) {
+		reply("@%s needs write access to this repository to use `%s` commands.", c.Author, prCommandPrefix)
+		return false
+	}
+
+	switch cmd.action {
+	case prActionRebase:
+		err := s.store.WithJobLease(ctx, job.ID, db.LeaseHolder("pr command"), func(ctx context.Context) error {
+			return rebaseAndPush(ctx, s.cfg, s.store, job, proj)
+		})
+		if err != nil {
+			reply("rebase failed: %v", err)
+			return false
+		}
+		reply("rebased onto `%s` and pushed.", pipeline.TargetBranch(job, proj))
+		return false
+
+	case prActionClose:
+		if err := forge.closePR(ctx, forge.token, forge.baseURL, job.PRURL); err != nil {
+			reply("could not close this PR: %v", err)
+			return false
+		}
+		if err := s.store.MarkJobPRClosed(ctx, job.ID, time.Now().UTC().Format("2006-01-02T15:04:05Z")); err != nil {
+			slog.Error("pr commands: mark job PR closed", "job", db.ShortID(job.ID), "err", err)
+		}
+		reply("closed at @%s's request.", c.Author)
+		s.cleanupWorktree(ctx, job)
+		return true
+
+	default:
+		notes := feedbackNotes(job, c.Author, cmd.text)
+		err := s.store.WithJobLease(ctx, job.ID, db.LeaseHolder("pr command"), func(ctx context.Context) error {
+			return s.store.ReopenJobForFeedback(ctx, job.ID, notes)
+		})
+		if errors.Is(err, db.ErrDuplicateActiveJob) {
+			reply("another job is already running for this issue; try again when it finishes.")
+			return false
+		}
+		if err != nil {
+			reply("could not start another iteration: %v", err)
+			return false
+		}
+		select {
+		case s.jobCh <- job.ID:
+		default:
+			slog.Warn("sync: job channel full", "job_id", job.ID)
+		}
+		reply("working on it (job `%s`, iteration %d). The changes will be pushed to this PR.", db.ShortID(job.ID), job.Iteration+1)
+		return true
+	}
+}
+
+// feedbackNotes are the human notes for an iteration asked for in a PR
+// comment.
+func feedbackNotes(job db.Job, author, text string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Feedback from @%s on the open PR (%s):\n\n", author, job.PRURL)
+	b.WriteString(text)
+	b.WriteString("\n\nThe branch already has this job's earlier changes, which the PR shows. Change them as asked rather than starting over.")
+	return b.String()
+}
+
+// rebaseAndPush rebases the job's branch onto its base branch and pushes it
+// to the remote the PR was opened from, as approving does.
+func rebaseAndPush(ctx context.Context, cfg *config.Config, store *db.Store, job db.Job, proj *config.ProjectConfig) error {
+	if job.WorktreePath == "" {
+		return fmt.Errorf("the job's worktree is gone")
+	}
+	if _, err := os.Stat(job.WorktreePath); err != nil {
+		return fmt.Errorf("the job's worktree is gone")
+	}
+	issue, err := store.GetIssueByAPID(ctx, job.AutoPRIssueID)
+	if err != nil {
+		return fmt.Errorf("load issue: %w", err)
+	}
+	_, err = pipeline.PushJobBranch(ctx, cfg, store, proj, job, issue, "")
+	return err
+}
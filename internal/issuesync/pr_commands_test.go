@@ -0,0 +1,228 @@
+package issuesync
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"autopr/internal/config"
+	"autopr/internal/db"
+	"autopr/internal/git"
+)
+
+func TestParsePRCommand(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		body   string
+		ok     bool
+		action string
+		text   string
+	}{
+		{"/autopr rebase", true, prActionRebase, ""},
+		{"  /autopr CLOSE\r\n", true, prActionClose, ""},
+		{"/autopr", true, prActionHelp, ""},
+		{"/autopr help", true, prActionHelp, ""},
+		{"/autopr fix the failing lint", true, prActionFeedback, "fix the failing lint"},
+		{"/autopr\nRename the flag.\nKeep the old one as an alias.", true, prActionFeedback, "Rename the flag.\nKeep the old one as an alias."},
+		{"/autopr rebase please", true, prActionFeedback, "rebase please"},
+		{"/autoprs rebase", false, "", ""},
+		{"Looks good. /autopr rebase", false, "", ""},
+		{"> /autopr close\nquoted", false, "", ""},
+	} {
+		cmd, ok := parsePRCommand(tc.body)
+		if ok != tc.ok || cmd.action != tc.action || cmd.text != tc.text {
+			t.Errorf("parsePRCommand(%q) = %+v, %v; want %s %q, %v", tc.body, cmd, ok, tc.action, tc.text, tc.ok)
+		}
+	}
+}
+
+// fakePRForge is a PR forge whose comments, permissions, and replies live in
+// memory.
+type fakePRForge struct {
+	comments    []git.IssueComment
+	permissions map[string]string
+	replies     []string
+	closed      bool
+}
+
+func (f *fakePRForge) forge() prForge {
+	return prForge{
+		token: "tok",
+		listComments: func(ctx context.Context, token, baseURL, prURL string) ([]git.IssueComment, error) {
+			return f.comments, nil
+		},
+		comment: func(ctx context.Context, token, baseURL, prURL, body string) error {
+			f.replies = append(f.replies, body)
+			return nil
+		},
+		permission: func(ctx context.Context, token, baseURL, prURL, user string) (string, error) {
+			if p, ok := f.permissions[user]; ok {
+				return p, nil
+			}
+			return "none", nil
+		},
+		closePR: func(ctx context.Context, token, baseURL, prURL string) error {
+			f.closed = true
+			return nil
+		},
+		prURLSeparator: "/pull/",
+	}
+}
+
+func setupPRCommandJob(t *testing.T) (*Syncer, *db.Store, db.Job, *config.ProjectConfig, chan string) {
+	t.Helper()
+	ctx := context.Background()
+	store := openTestStore(t)
+	t.Cleanup(func() { store.Close() })
+
+	jobID := createSyncTestJob(t, ctx, store, "project-gh", "pr-commands", "approved", "autopr/branch", "https://github.com/acme/repo/pull/9")
+	if err := store.UpdateJobField(ctx, jobID, "worktree_path", t.TempDir()); err != nil {
+		t.Fatalf("set worktree: %v", err)
+	}
+	// The PR was opened with pr_commands on.
+	if err := store.SetJobPRCommentCursor(ctx, jobID, db.PRCommentCursorStart); err != nil {
+		t.Fatalf("set cursor: %v", err)
+	}
+	cfg := &config.Config{
+		Daemon:   config.DaemonConfig{PRCommands: true},
+		Projects: []config.ProjectConfig{{Name: "project-gh", GitHub: &config.ProjectGitHub{Owner: "acme", Repo: "repo"}}},
+	}
+	jobCh := make(chan string, 1)
+	job, err := store.GetJob(ctx, jobID)
+	if err != nil {
+		t.Fatalf("get job: %v", err)
+	}
+	return NewSyncer(cfg, store, jobCh), store, job, &cfg.Projects[0], jobCh
+}
+
+func TestPRCommandFeedbackQueuesIteration(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	s, store, job, proj, jobCh := setupPRCommandJob(t)
+
+	fake := &fakePRForge{
+		comments: []git.IssueComment{
+			{ID: 10, Author: "alice", Body: "Nice work."},
+			{ID: 11, Author: "alice", Body: "/autopr fix the failing lint"},
+			{ID: 12, Author: "alice", Body: "/autopr close"},
+		},
+		permissions: map[string]string{"alice": "write"},
+	}
+	s.checkJobPRCommands(ctx, job, proj, fake.forge())
+
+	got, err := store.GetJob(ctx, job.ID)
+	if err != nil {
+		t.Fatalf("get job: %v", err)
+	}
+	if got.State != "queued" || got.Iteration != job.Iteration+1 {
+		t.Fatalf("job state %s iteration %d, want queued at %d", got.State, got.Iteration, job.Iteration+1)
+	}
+	if !strings.Contains(got.HumanNotes, "Feedback from @alice") || !strings.Contains(got.HumanNotes, "fix the failing lint") {
+		t.Fatalf("human notes = %q", got.HumanNotes)
+	}
+	if got.PRURL != job.PRURL || got.BranchName != job.BranchName || got.WorktreePath != job.WorktreePath {
+		t.Fatalf("feedback iteration lost the PR, branch, or worktree: %+v", got)
+	}
+	select {
+	case id := <-jobCh:
+		if id != job.ID {
+			t.Fatalf("queued %s, want %s", id, job.ID)
+		}
+	default:
+		t.Fatal("job was not sent to the workers")
+	}
+	if fake.closed {
+		t.Fatal("the close command after the feedback should wait for the next iteration")
+	}
+	if len(fake.replies) != 1 || !strings.Contains(fake.replies[0], "working on it") {
+		t.Fatalf("replies = %q", fake.replies)
+	}
+	if cursor, _ := store.JobPRCommentCursor(ctx, job.ID); cursor != 11 {
+		t.Fatalf("cursor = %d, want 11", cursor)
+	}
+}
+
+func TestPRCommandsSkipCommentsFromBeforeTheyWereRead(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	s, store, job, proj, _ := setupPRCommandJob(t)
+	// The PR was opened before pr_commands was turned on.
+	if err := store.SetJobPRCommentCursor(ctx, job.ID, 0); err != nil {
+		t.Fatalf("reset cursor: %v", err)
+	}
+
+	fake := &fakePRForge{
+		comments:    []git.IssueComment{{ID: 20, Author: "alice", Body: "/autopr close"}},
+		permissions: map[string]string{"alice": "write"},
+	}
+	s.checkJobPRCommands(ctx, job, proj, fake.forge())
+	if fake.closed || len(fake.replies) != 0 {
+		t.Fatalf("old command replayed: closed=%v replies=%q", fake.closed, fake.replies)
+	}
+	if cursor, _ := store.JobPRCommentCursor(ctx, job.ID); cursor != 20 {
+		t.Fatalf("cursor = %d, want 20", cursor)
+	}
+
+	// Commands posted after that are read.
+	fake.comments = append(fake.comments, git.IssueComment{ID: 21, Author: "alice", Body: "/autopr help"})
+	s.checkJobPRCommands(ctx, job, proj, fake.forge())
+	if len(fake.replies) != 1 || !strings.Contains(fake.replies[0], "AutoPR commands") {
+		t.Fatalf("replies = %q", fake.replies)
+	}
+}
+
+func TestPRCommandRequiresWriteAccess(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	s, store, job, proj, _ := setupPRCommandJob(t)
+
+	fake := &fakePRForge{
+		comments:    []git.IssueComment{{ID: 5, Author: "mallory", Body: "/autopr close"}},
+		permissions: map[string]string{"mallory": "read"},
+	}
+	s.checkJobPRCommands(ctx, job, proj, fake.forge())
+
+	if fake.closed {
+		t.Fatal("PR closed for a user without write access")
+	}
+	if len(fake.replies) != 1 || !strings.Contains(fake.replies[0], "@mallory needs write access") {
+		t.Fatalf("replies = %q", fake.replies)
+	}
+
+	// The comment has been read and is not answered again.
+	s.checkJobPRCommands(ctx, job, proj, fake.forge())
+	if len(fake.replies) != 1 {
+		t.Fatalf("comment handled twice: %q", fake.replies)
+	}
+	if got, _ := store.GetJob(ctx, job.ID); got.State != "approved" {
+		t.Fatalf("job state = %s, want approved", got.State)
+	}
+}
+
+func TestPRCommandClose(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	s, store, job, proj, _ := setupPRCommandJob(t)
+	s.deleteRemoteBranch = func(ctx context.Context, dir, branchName, token string) error { return nil }
+
+	fake := &fakePRForge{
+		comments:    []git.IssueComment{{ID: 3, Author: "alice", Body: "/autopr close"}},
+		permissions: map[string]string{"alice": "admin"},
+	}
+	s.checkJobPRCommands(ctx, job, proj, fake.forge())
+
+	if !fake.closed {
+		t.Fatal("PR was not closed")
+	}
+	got, err := store.GetJob(ctx, job.ID)
+	if err != nil {
+		t.Fatalf("get job: %v", err)
+	}
+	if got.PRClosedAt == "" || got.WorktreePath != "" {
+		t.Fatalf("closed at %q, worktree %q; want closed and cleaned up", got.PRClosedAt, got.WorktreePath)
+	}
+	if len(fake.replies) != 1 || !strings.Contains(fake.replies[0], "closed at @alice's request") {
+		t.Fatalf("replies = %q", fake.replies)
+	}
+}
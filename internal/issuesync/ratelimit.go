@@ -0,0 +1,40 @@
+package issuesync
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"autopr/internal/db"
+	"autopr/internal/httputil"
+)
+
+// persistRateLimits stores the API rate limits seen by this process so the
+// TUI and /health can show them.
+func (s *Syncer) persistRateLimits(ctx context.Context) {
+	for _, rl := range s.rateLimits() {
+		row := db.APIRateLimit{
+			Host:      rl.Host,
+			Resource:  rl.Resource,
+			Limit:     rl.Limit,
+			Remaining: rl.Remaining,
+			UpdatedAt: rl.ObservedAt.UTC().Format(time.RFC3339),
+		}
+		if !rl.ResetAt.IsZero() {
+			row.ResetAt = rl.ResetAt.UTC().Format(time.RFC3339)
+		}
+		if err := s.store.UpsertAPIRateLimit(ctx, row); err != nil {
+			slog.Warn("persist api rate limit", "host", rl.Host, "err", err)
+		}
+	}
+}
+
+// nextPollInterval returns base, stretched while any API is close to its
+// rate limit.
+func (s *Syncer) nextPollInterval(loop string, base time.Duration) time.Duration {
+	next := httputil.AdaptiveInterval(base, s.rateLimits(), time.Now())
+	if next > base {
+		slog.Info("api rate limit low, slowing down", "loop", loop, "interval", next)
+	}
+	return next
+}
@@ -0,0 +1,66 @@
+package issuesync
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"autopr/internal/config"
+	"autopr/internal/httputil"
+	"autopr/internal/idle"
+)
+
+func TestPersistRateLimitsStoresObservedLimits(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	store := openTestStore(t)
+	defer store.Close()
+
+	observed := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	s := NewSyncer(&config.Config{}, store, make(chan string, 1))
+	s.rateLimits = func() []httputil.RateLimit {
+		return []httputil.RateLimit{
+			{Host: "api.github.com", Resource: "core", Limit: 5000, Remaining: 42, ResetAt: observed.Add(time.Hour), ObservedAt: observed},
+			{Host: "gitlab.com", Limit: 2000, Remaining: 1500, ObservedAt: observed},
+		}
+	}
+
+	s.persistRateLimits(ctx)
+
+	limits, err := store.ListAPIRateLimits(ctx)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(limits) != 2 {
+		t.Fatalf("expected 2 rate limits, got %+v", limits)
+	}
+	if gh := limits[0]; gh.Host != "api.github.com" || gh.Remaining != 42 || gh.ResetAt != "2026-03-01T13:00:00Z" || gh.UpdatedAt != "2026-03-01T12:00:00Z" {
+		t.Fatalf("unexpected github rate limit %+v", gh)
+	}
+	if gl := limits[1]; gl.Host != "gitlab.com" || gl.ResetAt != "" {
+		t.Fatalf("unexpected gitlab rate limit %+v", gl)
+	}
+}
+
+func TestPollIntervalStretchesWhileIdle(t *testing.T) {
+	t.Parallel()
+
+	s := &Syncer{rateLimits: func() []httputil.RateLimit { return nil }}
+	if got := s.pollInterval("sync", 5*time.Minute); got != 5*time.Minute {
+		t.Fatalf("without idle mode: got %s, want 5m", got)
+	}
+
+	mode := idle.NewMode()
+	s.SetIdle(mode, 30*time.Minute)
+	if got := s.pollInterval("sync", 5*time.Minute); got != 5*time.Minute {
+		t.Fatalf("awake: got %s, want 5m", got)
+	}
+	mode.Sleep()
+	if got := s.pollInterval("sync", 5*time.Minute); got != 30*time.Minute {
+		t.Fatalf("idle: got %s, want 30m", got)
+	}
+	// A loop already slower than the idle interval keeps its own.
+	if got := s.pollInterval("pr", time.Hour); got != time.Hour {
+		t.Fatalf("idle with a longer interval: got %s, want 1h", got)
+	}
+}
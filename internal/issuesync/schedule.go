@@ -0,0 +1,53 @@
+package issuesync
+
+import (
+	"time"
+
+	"autopr/internal/config"
+)
+
+// schedule tracks when each project last ran one kind of polling, so a loop
+// ticking at the shortest project interval polls every project only as often
+// as its own interval allows.
+type schedule struct {
+	last map[string]time.Time
+}
+
+// take returns the names of projects whose interval has elapsed since they
+// were last taken, and records now as their last run. Projects never taken
+// are always due.
+func (sc *schedule) take(projects []*config.ProjectConfig, interval func(*config.ProjectConfig) time.Duration, now time.Time) map[string]bool {
+	if sc.last == nil {
+		sc.last = make(map[string]time.Time)
+	}
+	due := make(map[string]bool)
+	for _, p := range projects {
+		if last, ok := sc.last[p.Name]; ok && now.Sub(last) < interval(p) {
+			continue
+		}
+		sc.last[p.Name] = now
+		due[p.Name] = true
+	}
+	return due
+}
+
+// shortestInterval returns the smallest positive per-project interval, or
+// base if it is smaller or no project sets one.
+func (s *Syncer) shortestInterval(base time.Duration, interval func(*config.ProjectConfig) time.Duration) time.Duration {
+	shortest := base
+	for i := range s.cfg.Projects {
+		if d := interval(&s.cfg.Projects[i]); d > 0 && (shortest <= 0 || d < shortest) {
+			shortest = d
+		}
+	}
+	return shortest
+}
+
+// allProjects returns pointers to every configured project.
+func (s *Syncer) allProjects() []*config.ProjectConfig {
+	projects := make([]*config.ProjectConfig, len(s.cfg.Projects))
+	for i := range s.cfg.Projects {
+		projects[i] = &s.cfg.Projects[i]
+	}
+	return projects
+}
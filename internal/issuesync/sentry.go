@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"time"
 
 	"autopr/internal/config"
 	"autopr/internal/db"
@@ -29,23 +30,22 @@ func (s *Syncer) syncSentry(ctx context.Context, p *config.ProjectConfig) error
 	if p.Sentry.AssignedTeam != nil {
 		assignedTeam = *p.Sentry.AssignedTeam
 	}
-	query := sentryIssueQuery(assignedTeam)
-	baseAPIURL := fmt.Sprintf("%s/api/0/projects/%s/%s/issues/?query=%s&sort=date", baseURL, org, project, url.QueryEscape(query))
 
-	// Get cursor for pagination.
-	cursor, err := s.store.GetCursor(ctx, p.Name, "sentry")
+	// The cursor is the latest lastSeen stored, so each sync asks only for
+	// issues seen since then.
+	since, err := s.store.GetCursor(ctx, p.Name, "sentry")
 	if err != nil {
 		return err
 	}
+	query := sentryIssueQuery(assignedTeam, since)
+	baseAPIURL := fmt.Sprintf("%s/api/0/projects/%s/%s/issues/?query=%s&sort=date", baseURL, org, project, url.QueryEscape(query))
 
 	token := s.cfg.Tokens.Sentry
 
 	const maxPages = 50
-	var lastCursor string
+	var latestSeen string
+	exhausted := false
 	nextURL := baseAPIURL
-	if cursor != "" {
-		nextURL += "&cursor=" + cursor
-	}
 
 	for page := range maxPages {
 		currentURL := nextURL
@@ -80,6 +80,7 @@ func (s *Syncer) syncSentry(ctx context.Context, p *config.ProjectConfig) error
 		slog.Debug("sync: sentry issues fetched", "project", p.Name, "page", page+1, "count", len(issues))
 
 		if len(issues) == 0 {
+			exhausted = true
 			break
 		}
 
@@ -101,21 +102,29 @@ func (s *Syncer) syncSentry(ctx context.Context, p *config.ProjectConfig) error
 				slog.Error("sync: upsert sentry issue", "id", issue.ID, "err", err)
 				continue
 			}
+			if laterTimestamp(issue.LastSeen, latestSeen) {
+				latestSeen = issue.LastSeen
+			}
 
 			s.createJobIfNeeded(ctx, ffid, p.Name)
 		}
 
 		nextCursor := parseSentryNextCursor(linkHeader)
 		if nextCursor == "" {
+			exhausted = true
 			break
 		}
-		lastCursor = nextCursor
 		nextURL = baseAPIURL + "&cursor=" + nextCursor
 	}
 
-	// Save final cursor after full loop completes.
-	if lastCursor != "" {
-		if err := s.store.SetCursor(ctx, p.Name, "sentry", lastCursor); err != nil {
+	// Sentry lists newest first, so the cursor can only move once every page
+	// has been read; otherwise the unread older issues would be skipped.
+	if !exhausted {
+		slog.Warn("sync: sentry issue list exceeds page limit; cursor not advanced", "project", p.Name, "pages", maxPages)
+		return nil
+	}
+	if latestSeen != "" {
+		if err := s.store.SetCursor(ctx, p.Name, "sentry", latestSeen); err != nil {
 			slog.Error("sync: set sentry cursor", "err", err)
 		}
 	}
@@ -124,12 +133,17 @@ func (s *Syncer) syncSentry(ctx context.Context, p *config.ProjectConfig) error
 }
 
 // sentryIssueQuery builds the Sentry search query. When assignedTeam is set,
-// only issues assigned to that team are returned.
-func sentryIssueQuery(assignedTeam string) string {
+// only issues assigned to that team are returned. since, an RFC3339 time,
+// limits it to issues seen at or after then; cursors stored by older versions
+// (Sentry paging cursors) are ignored, which refetches everything once.
+func sentryIssueQuery(assignedTeam, since string) string {
 	query := "is:unresolved"
 	if team := strings.TrimSpace(assignedTeam); team != "" {
 		query += " assigned:#" + team
 	}
+	if t, err := time.Parse(time.RFC3339, since); err == nil {
+		query += " lastSeen:>=" + t.UTC().Format("2006-01-02T15:04:05")
+	}
 	return query
 }
 
@@ -1,6 +1,15 @@
 package issuesync
 
-import "testing"
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"autopr/internal/config"
+)
 
 func TestSentryIssueQuery(t *testing.T) {
 	t.Parallel()
@@ -8,6 +17,7 @@ func TestSentryIssueQuery(t *testing.T) {
 	tests := []struct {
 		name         string
 		assignedTeam string
+		since        string
 		want         string
 	}{
 		{
@@ -29,16 +39,75 @@ func TestSentryIssueQuery(t *testing.T) {
 			assignedTeam: "  my-team  ",
 			want:         "is:unresolved assigned:#my-team",
 		},
+		{
+			name:  "since limits to recently seen issues in UTC",
+			since: "2026-03-01T10:00:00.123+02:00",
+			want:  "is:unresolved lastSeen:>=2026-03-01T08:00:00",
+		},
+		{
+			name:  "legacy paging cursor is ignored",
+			since: "1700000000000:0:0",
+			want:  "is:unresolved",
+		},
 	}
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
 			t.Parallel()
-			got := sentryIssueQuery(tc.assignedTeam)
+			got := sentryIssueQuery(tc.assignedTeam, tc.since)
 			if got != tc.want {
-				t.Fatalf("sentryIssueQuery(%q): want %q, got %q", tc.assignedTeam, tc.want, got)
+				t.Fatalf("sentryIssueQuery(%q, %q): want %q, got %q", tc.assignedTeam, tc.since, tc.want, got)
 			}
 		})
 	}
 }
 
+func TestSyncSentryFetchesOnlyIssuesSeenSinceCursor(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	store := openTestStore(t)
+	defer store.Close()
+
+	var queries []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.Query().Get("query"))
+		w.Header().Set("Link", `<`+r.URL.String()+`>; rel="next"; results="false"; cursor="0:100:0"`)
+		// Newest first, as Sentry returns them with sort=date.
+		_, _ = io.WriteString(w, `[
+			{"id":"2","title":"newer","permalink":"https://sentry.io/2","count":"3","lastSeen":"2026-03-02T09:00:00.500Z"},
+			{"id":"1","title":"older","permalink":"https://sentry.io/1","count":"1","lastSeen":"2026-03-01T09:00:00Z"}]`)
+	}))
+	defer srv.Close()
+
+	cfg := &config.Config{
+		Tokens: config.TokensConfig{Sentry: "token"},
+		Sentry: config.SentryConfig{BaseURL: srv.URL},
+		Daemon: config.DaemonConfig{MaxIterations: 3},
+	}
+	project := &config.ProjectConfig{
+		Name:   "my-project",
+		Sentry: &config.ProjectSentry{Org: "org", Project: "proj"},
+	}
+	syncer := NewSyncer(cfg, store, make(chan string, 8))
+
+	for range 2 {
+		if err := syncer.syncSentry(ctx, project); err != nil {
+			t.Fatalf("sync sentry: %v", err)
+		}
+	}
+
+	if len(queries) != 2 || strings.Contains(queries[0], "lastSeen") {
+		t.Fatalf("expected a full first sync, got queries %q", queries)
+	}
+	if queries[1] != "is:unresolved lastSeen:>=2026-03-02T09:00:00" {
+		t.Fatalf("expected second sync limited to the cursor, got %q", queries[1])
+	}
+	cursor, err := store.GetCursor(ctx, "my-project", "sentry")
+	if err != nil {
+		t.Fatalf("get cursor: %v", err)
+	}
+	if cursor != "2026-03-02T09:00:00.500Z" {
+		t.Fatalf("expected cursor at latest lastSeen, got %q", cursor)
+	}
+	_ = getIssueBySourceID(t, ctx, store, "my-project", "sentry", "1")
+}
@@ -5,12 +5,26 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 
+	"autopr/internal/attachments"
 	"autopr/internal/config"
 	"autopr/internal/db"
+	"autopr/internal/epicstatus"
 	"autopr/internal/git"
+	"autopr/internal/githubapp"
+	"autopr/internal/httputil"
+	"autopr/internal/idle"
+	"autopr/internal/issuecomments"
+	"autopr/internal/issuelock"
+	"autopr/internal/issuepolicy"
+	"autopr/internal/netstate"
+	"autopr/internal/pipeline"
+	"autopr/internal/policy"
+	"autopr/internal/recurring"
 )
 
 // Syncer periodically pulls issues from configured sources.
@@ -19,12 +33,43 @@ type Syncer struct {
 	store *db.Store
 	jobCh chan<- string
 
-	findGitHubPRByBranch    func(ctx context.Context, token, owner, repo, head, state string) (string, error)
+	findGitHubPRByBranch    func(ctx context.Context, token, baseURL, owner, repo, head, state string) (string, error)
 	findGitLabMRByBranch    func(ctx context.Context, token, baseURL, projectID, sourceBranch, state string) (string, error)
-	checkGitHubPRStatus     func(ctx context.Context, token, prURL string) (git.PRMergeStatus, error)
+	findGiteaPRByBranch     func(ctx context.Context, token, baseURL, owner, repo, head, state string) (string, error)
+	checkGitHubPRStatus     func(ctx context.Context, token, baseURL, prURL string) (git.PRMergeStatus, error)
 	checkGitLabMRStatus     func(ctx context.Context, token, baseURL, mrURL string) (git.PRMergeStatus, error)
+	checkGiteaPRStatus      func(ctx context.Context, token, baseURL, prURL string) (git.PRMergeStatus, error)
 	deleteRemoteBranch      func(ctx context.Context, dir, branchName, token string) error
-	getGitHubCheckRunStatus func(ctx context.Context, token, owner, repo, ref string) (git.CheckRunStatus, error)
+	getGitHubCheckRunStatus func(ctx context.Context, token, baseURL, owner, repo, ref string) (git.CheckRunStatus, error)
+	getGiteaCommitStatus    func(ctx context.Context, token, baseURL, owner, repo, ref string) (git.CheckRunStatus, error)
+	firstGitHubApproval     func(ctx context.Context, token, baseURL, prURL string) (string, error)
+	firstGiteaApproval      func(ctx context.Context, token, baseURL, prURL string) (string, error)
+	releaseIssueLocks       func(ctx context.Context)
+	updateEpicStatus        func(ctx context.Context)
+	applyIssuePolicies      func(ctx context.Context)
+	syncIssueComments       func(ctx context.Context, issue db.Issue)
+	syncIssueAttachments    func(ctx context.Context, issue db.Issue)
+	detectStack             func(ctx context.Context, p *config.ProjectConfig)
+	runRecurring            func(ctx context.Context)
+	rateLimits              func() []httputil.RateLimit
+
+	// hostLimiter spaces sync requests to each API host.
+	hostLimiter *httputil.HostLimiter
+
+	// Per-project last run times for issue sync, PR checks, and CI polling,
+	// which may each use a different interval per project.
+	syncSchedule schedule
+	prSchedule   schedule
+	ciSchedule   schedule
+
+	// unreachable tracks projects whose last sync failed on the network so an
+	// outage is logged once rather than every interval.
+	unreachable map[string]bool
+
+	// While the daemon is idle, issue sync and PR checks run every
+	// idleInterval; see SetIdle.
+	idle         *idle.Mode
+	idleInterval time.Duration
 }
 
 func NewSyncer(cfg *config.Config, store *db.Store, jobCh chan<- string) *Syncer {
@@ -34,44 +79,211 @@ func NewSyncer(cfg *config.Config, store *db.Store, jobCh chan<- string) *Syncer
 		jobCh:                   jobCh,
 		findGitHubPRByBranch:    git.FindGitHubPRByBranch,
 		findGitLabMRByBranch:    git.FindGitLabMRByBranch,
+		findGiteaPRByBranch:     git.FindGiteaPRByBranch,
 		checkGitHubPRStatus:     git.CheckGitHubPRStatus,
 		checkGitLabMRStatus:     git.CheckGitLabMRStatus,
+		checkGiteaPRStatus:      git.CheckGiteaPRStatus,
 		deleteRemoteBranch:      git.DeleteRemoteBranchWithToken,
 		getGitHubCheckRunStatus: git.GetGitHubCheckRunStatus,
+		getGiteaCommitStatus:    git.GetGiteaCommitStatus,
+		firstGitHubApproval:     git.FirstGitHubApprovalAt,
+		firstGiteaApproval:      git.FirstGiteaApprovalAt,
+		releaseIssueLocks:       issuelock.New(cfg, store).ReleaseTerminal,
+		updateEpicStatus:        epicstatus.New(cfg, store).Update,
+		applyIssuePolicies:      issuepolicy.New(cfg, store).Apply,
+		syncIssueComments:       issuecomments.New(cfg, store).Sync,
+		syncIssueAttachments:    attachments.New(cfg, store).Sync,
+		detectStack: func(ctx context.Context, p *config.ProjectConfig) {
+			pipeline.SyncProjectStack(ctx, cfg, store, p)
+		},
+		runRecurring: recurring.New(cfg, store, jobCh).RunDue,
+		rateLimits:   httputil.RateLimits,
+		hostLimiter:  httputil.NewHostLimiter(cfg.Daemon.SyncHostRPS),
 	}
 }
 
-// RunLoop polls all configured sources at the given interval.
+// SetIdle makes RunLoop and RunPRLoop poll every interval while mode is idle,
+// and at once when the daemon wakes.
+func (s *Syncer) SetIdle(mode *idle.Mode, interval time.Duration) {
+	s.idle = mode
+	s.idleInterval = interval
+}
+
+// pollInterval is how long a loop waits for its next round: base, or the idle
+// interval while the daemon is idle, stretched near an API rate limit.
+func (s *Syncer) pollInterval(loop string, base time.Duration) time.Duration {
+	if s.idle.Idle() {
+		base = max(base, s.idleInterval)
+	}
+	return s.nextPollInterval(loop, base)
+}
+
+// RunLoop polls configured sources, each project at its own sync interval
+// (interval unless the project overrides it). The loop ticks at the shortest
+// interval, stretched while an API is close to its rate limit.
 func (s *Syncer) RunLoop(ctx context.Context, interval time.Duration) {
+	interval = s.shortestInterval(interval, s.cfg.SyncInterval)
 	slog.Info("sync loop starting", "interval", interval)
 
 	// Run immediately on start.
 	s.syncAll(ctx)
 
-	ticker := time.NewTicker(interval)
-	defer ticker.Stop()
+	timer := time.NewTimer(s.pollInterval("sync", interval))
+	defer timer.Stop()
 
 	for {
 		select {
 		case <-ctx.Done():
 			slog.Debug("sync loop stopping")
 			return
-		case <-ticker.C:
-			s.syncAll(ctx)
+		case <-timer.C:
+		case <-s.idle.Woken():
+			timer.Stop()
+		}
+		s.syncAll(ctx)
+		timer.Reset(s.pollInterval("sync", interval))
+	}
+}
+
+// RunPRLoop checks open PRs for merges and closes, each project at its own
+// PR check interval. Like RunLoop, it slows down while an API is close to its
+// rate limit.
+func (s *Syncer) RunPRLoop(ctx context.Context, interval time.Duration) {
+	interval = s.shortestInterval(interval, s.cfg.PRCheckInterval)
+	slog.Info("PR check loop starting", "interval", interval)
+
+	s.checkPRs(ctx)
+
+	timer := time.NewTimer(s.pollInterval("pr", interval))
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		case <-s.idle.Woken():
+			timer.Stop()
+		}
+		s.checkPRs(ctx)
+		timer.Reset(s.pollInterval("pr", interval))
+	}
+}
+
+// RunCILoop polls CI status, each project at its own CI check interval,
+// separately from the sync loop for responsive CI feedback. Like RunLoop, it
+// slows down while an API is close to its rate limit.
+func (s *Syncer) RunCILoop(ctx context.Context, interval time.Duration) {
+	interval = s.shortestInterval(interval, s.cfg.CICheckInterval)
+	timer := time.NewTimer(interval)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+			due := s.ciSchedule.take(s.allProjects(), s.cfg.CICheckInterval, time.Now())
+			s.checkCIStatus(ctx, due)
+			s.persistRateLimits(ctx)
+			timer.Reset(s.nextPollInterval("ci", interval))
 		}
 	}
 }
 
+// checkPRs runs one round of PR checks for the projects that are due.
+func (s *Syncer) checkPRs(ctx context.Context) {
+	due := s.prSchedule.take(s.allProjects(), s.cfg.PRCheckInterval, time.Now())
+
+	// Check if any job PRs have been merged or closed.
+	s.checkPRStatusFor(ctx, due)
+
+	// Act on /autopr comments on the PRs that are still open.
+	s.checkPRCommands(ctx, due)
+
+	// Link reverts and follow-up fixes to merged PRs for `ap stats`.
+	s.trackPROutcomes(ctx)
+
+	// Swap in-progress labels on issues whose jobs have finished.
+	s.releaseIssueLocks(ctx)
+	if s.updateEpicStatus != nil {
+		s.updateEpicStatus(ctx)
+	}
+
+	s.persistRateLimits(ctx)
+}
+
 func (s *Syncer) syncAll(ctx context.Context) {
+	overrides, err := s.store.ProjectEnabledOverrides(ctx)
+	if err != nil {
+		slog.Warn("sync: load project overrides", "err", err)
+	}
+	var projects []*config.ProjectConfig
 	for i := range s.cfg.Projects {
 		p := &s.cfg.Projects[i]
-		if err := s.syncProject(ctx, p); err != nil {
+		if !s.cfg.ProjectEnabled(p.Name, overrides) {
+			slog.Debug("sync: project disabled", "project", p.Name)
+			continue
+		}
+		projects = append(projects, p)
+	}
+	due := s.syncSchedule.take(projects, s.cfg.SyncInterval, time.Now())
+	projects = slices.DeleteFunc(projects, func(p *config.ProjectConfig) bool { return !due[p.Name] })
+
+	// Sync projects concurrently so one slow host does not hold up the
+	// rest; the host limiter keeps projects sharing a host under its rate.
+	errs := make([]error, len(projects))
+	limiterCtx := httputil.WithHostLimiter(ctx, s.hostLimiter)
+	sem := make(chan struct{}, max(s.cfg.Daemon.SyncConcurrency, 1))
+	var wg sync.WaitGroup
+	for i, p := range projects {
+		sem <- struct{}{}
+		wg.Go(func() {
+			defer func() { <-sem }()
+			start := time.Now()
+			errs[i] = s.syncProject(limiterCtx, p)
+			took := time.Since(start)
+			slog.Debug("sync: project synced", "project", p.Name, "duration", took)
+			if ctx.Err() == nil {
+				if recErr := s.store.RecordProjectSync(ctx, p.Name, took, errs[i]); recErr != nil {
+					slog.Warn("record sync status", "project", p.Name, "err", recErr)
+				}
+			}
+			if errs[i] == nil && s.detectStack != nil {
+				s.detectStack(limiterCtx, p)
+			}
+		})
+	}
+	wg.Wait()
+
+	for i, p := range projects {
+		err := errs[i]
+		switch {
+		case err != nil && netstate.IsNetworkError(err):
+			if !s.unreachable[p.Name] {
+				slog.Warn("sync paused: network unavailable", "project", p.Name, "err", err)
+			}
+			if s.unreachable == nil {
+				s.unreachable = map[string]bool{}
+			}
+			s.unreachable[p.Name] = true
+		case err != nil:
 			slog.Error("sync project failed", "project", p.Name, "err", err)
+		case s.unreachable[p.Name]:
+			delete(s.unreachable, p.Name)
+			slog.Info("sync resumed: network restored", "project", p.Name)
 		}
 	}
 
-	// Check if any job PRs have been merged or closed.
-	s.checkPRStatus(ctx)
+	// Hold issues that keep failing, and release ended cooldowns.
+	if s.applyIssuePolicies != nil {
+		s.applyIssuePolicies(ctx)
+	}
+
+	// Queue jobs for recurring maintenance tasks whose schedule fired.
+	s.runRecurring(ctx)
+
+	s.persistRateLimits(ctx)
 }
 
 func (s *Syncer) syncProject(ctx context.Context, p *config.ProjectConfig) error {
@@ -85,11 +297,21 @@ func (s *Syncer) syncProject(ctx context.Context, p *config.ProjectConfig) error
 			return fmt.Errorf("github sync: %w", err)
 		}
 	}
+	if p.Gitea != nil {
+		if err := s.syncGitea(ctx, p); err != nil {
+			return fmt.Errorf("gitea sync: %w", err)
+		}
+	}
 	if p.Sentry != nil {
 		if err := s.syncSentry(ctx, p); err != nil {
 			return fmt.Errorf("sentry sync: %w", err)
 		}
 	}
+	if p.Local != nil {
+		if err := s.syncLocal(ctx, p); err != nil {
+			return fmt.Errorf("local sync: %w", err)
+		}
+	}
 	return nil
 }
 
@@ -103,8 +325,26 @@ func (s *Syncer) createJobIfNeeded(ctx context.Context, ffid, projectName string
 	if exists {
 		return
 	}
+	// An epic's tasks are retried one by one; the epic gets no new jobs.
+	epic, err := s.store.IsEpic(ctx, ffid)
+	if err != nil {
+		slog.Error("sync: check epic", "err", err)
+		return
+	}
+	if epic {
+		return
+	}
+	if attempts, err := s.store.GetIssueAttempts(ctx, ffid); err == nil && attempts.Held() {
+		slog.Debug("sync: issue held by issue policy, skipping", "ffid", ffid, "reason", attempts.HoldReason)
+		return
+	}
 
-	jobID, err := s.store.CreateJob(ctx, ffid, projectName, s.cfg.Daemon.MaxIterations)
+	p, ok := s.cfg.ProjectByName(projectName)
+	if !ok {
+		// No config, so no policy to prioritize the job by.
+		p = &config.ProjectConfig{Name: projectName}
+	}
+	jobID, err := policy.CreateJob(ctx, s.store, p, ffid, s.cfg.Daemon.MaxIterations)
 	if err != nil {
 		if errors.Is(err, db.ErrDuplicateActiveJob) {
 			slog.Debug("sync: active job already exists, skipping", "ffid", ffid)
@@ -125,6 +365,12 @@ func (s *Syncer) createJobIfNeeded(ctx context.Context, ffid, projectName string
 
 // checkPRStatus polls GitHub/GitLab for jobs whose PR may have been merged or closed.
 func (s *Syncer) checkPRStatus(ctx context.Context) {
+	s.checkPRStatusFor(ctx, nil)
+}
+
+// checkPRStatusFor is checkPRStatus limited to jobs of the projects in due;
+// a nil due checks every project.
+func (s *Syncer) checkPRStatusFor(ctx context.Context, due map[string]bool) {
 	knownPRJobs, err := s.store.ListApprovedJobsWithPR(ctx)
 	if err != nil {
 		slog.Error("check PR status: list approved jobs", "err", err)
@@ -136,6 +382,10 @@ func (s *Syncer) checkPRStatus(ctx context.Context) {
 		slog.Error("check PR status: list fallback jobs", "err", err)
 		return
 	}
+	if due != nil {
+		knownPRJobs = jobsOfProjects(knownPRJobs, due)
+		fallbackJobs = jobsOfProjects(fallbackJobs, due)
+	}
 
 	if len(knownPRJobs) == 0 && len(fallbackJobs) == 0 {
 		return
@@ -165,13 +415,17 @@ func (s *Syncer) checkPRStatus(ctx context.Context) {
 		)
 		switch {
 		case proj.GitHub != nil:
-			if s.cfg.Tokens.GitHub == "" || branchName == "" {
+			if branchName == "" {
+				continue
+			}
+			token := s.githubToken(ctx, proj)
+			if token == "" {
 				continue
 			}
 			if strings.TrimSpace(proj.GitHub.ForkOwner) != "" {
 				forkHeadName = proj.GitHub.GitHubForkHead(branchName)
 			}
-			prURL, lookupErr = s.findGitHubPRByBranch(ctx, s.cfg.Tokens.GitHub, proj.GitHub.Owner, proj.GitHub.Repo, forkHeadName, "all")
+			prURL, lookupErr = s.findGitHubPRByBranch(ctx, token, proj.GitHub.BaseURL, proj.GitHub.Owner, proj.GitHub.Repo, forkHeadName, "all")
 		case proj.GitLab != nil:
 			if s.cfg.Tokens.GitLab == "" || branchName == "" {
 				continue
@@ -184,6 +438,11 @@ func (s *Syncer) checkPRStatus(ctx context.Context) {
 				branchName,
 				"all",
 			)
+		case proj.Gitea != nil:
+			if s.cfg.Tokens.Gitea == "" || branchName == "" {
+				continue
+			}
+			prURL, lookupErr = s.findGiteaPRByBranch(ctx, s.cfg.Tokens.Gitea, proj.Gitea.BaseURL, proj.Gitea.Owner, proj.Gitea.Repo, branchName, "all")
 		default:
 			continue
 		}
@@ -195,12 +454,22 @@ func (s *Syncer) checkPRStatus(ctx context.Context) {
 			continue
 		}
 
-		if err := s.store.UpdateJobField(ctx, job.ID, "pr_url", prURL); err != nil {
-			slog.Error("check PR status: persist discovered PR URL", "job", job.ID, "err", err)
+		// `ap approve` may be creating this very PR; leave the job to it.
+		err := s.store.WithJobLease(ctx, job.ID, db.LeaseHolder("daemon sync"), func(ctx context.Context) error {
+			if err := s.store.UpdateJobField(ctx, job.ID, "pr_url", prURL); err != nil {
+				return fmt.Errorf("persist discovered PR URL: %w", err)
+			}
+			if err := s.store.EnsureJobApproved(ctx, job.ID); err != nil {
+				return fmt.Errorf("ensure approved: %w", err)
+			}
+			return nil
+		})
+		if errors.Is(err, db.ErrJobLeased) {
+			slog.Debug("check PR status: job busy, retrying next sync", "job", job.ID, "err", err)
 			continue
 		}
-		if err := s.store.EnsureJobApproved(ctx, job.ID); err != nil {
-			slog.Error("check PR status: ensure approved", "job", job.ID, "err", err)
+		if err != nil {
+			slog.Error("check PR status", "job", job.ID, "err", err)
 			continue
 		}
 		job.PRURL = prURL
@@ -223,10 +492,11 @@ func (s *Syncer) applyTerminalPRStatus(ctx context.Context, job db.Job, proj *co
 
 	switch {
 	case proj.GitHub != nil && strings.Contains(job.PRURL, "/pull/"):
-		if s.cfg.Tokens.GitHub == "" {
+		token := s.githubToken(ctx, proj)
+		if token == "" {
 			return false
 		}
-		status, checkErr = s.checkGitHubPRStatus(ctx, s.cfg.Tokens.GitHub, job.PRURL)
+		status, checkErr = s.checkGitHubPRStatus(ctx, token, proj.GitHub.BaseURL, job.PRURL)
 	case proj.GitLab != nil && strings.Contains(job.PRURL, "/merge_requests/"):
 		if s.cfg.Tokens.GitLab == "" {
 			return false
@@ -237,6 +507,11 @@ func (s *Syncer) applyTerminalPRStatus(ctx context.Context, job db.Job, proj *co
 			git.NormalizeGitLabBaseURL(proj.GitLab.BaseURL),
 			job.PRURL,
 		)
+	case proj.Gitea != nil && strings.Contains(job.PRURL, "/pulls/"):
+		if s.cfg.Tokens.Gitea == "" {
+			return false
+		}
+		status, checkErr = s.checkGiteaPRStatus(ctx, s.cfg.Tokens.Gitea, proj.Gitea.BaseURL, job.PRURL)
 	default:
 		return false
 	}
@@ -256,6 +531,8 @@ func (s *Syncer) applyTerminalPRStatus(ctx context.Context, job db.Job, proj *co
 			return false
 		}
 		slog.Info("PR merged", "job", db.ShortID(job.ID), "pr_url", job.PRURL)
+		s.recordPROutcome(ctx, job, proj, status, mergedAt)
+		s.queueBackports(ctx, job, status)
 		s.cleanupWorktree(ctx, job)
 		return true
 	}
@@ -277,13 +554,32 @@ func (s *Syncer) applyTerminalPRStatus(ctx context.Context, job db.Job, proj *co
 	return false
 }
 
+// queueBackports creates backport jobs for backport/<branch> labels on a merged PR.
+func (s *Syncer) queueBackports(ctx context.Context, job db.Job, status git.PRMergeStatus) {
+	if len(pipeline.BackportBranches(status.Labels)) == 0 {
+		return
+	}
+	ids, err := pipeline.CreateBackportJobs(ctx, s.store, job, status.Labels, status.MergeCommitSHA)
+	if err != nil {
+		slog.Error("queue backports", "job", db.ShortID(job.ID), "err", err)
+	}
+	for _, id := range ids {
+		select {
+		case s.jobCh <- id:
+		default:
+			slog.Warn("sync: job channel full", "job_id", id)
+		}
+		slog.Info("backport job created", "job", db.ShortID(id), "parent", db.ShortID(job.ID))
+	}
+}
+
 // cleanupWorktree removes the job's worktree directory and clears the DB field.
 func (s *Syncer) cleanupWorktree(ctx context.Context, job db.Job) {
 	branchName := strings.TrimSpace(job.BranchName)
 	if branchName != "" && job.WorktreePath != "" {
 		token := ""
 		if proj, ok := s.cfg.ProjectByName(job.ProjectName); ok {
-			token = s.cfg.GitTokenForProject(proj)
+			token = pipeline.GitTokenForProject(ctx, s.cfg, proj)
 		}
 		if err := s.deleteRemoteBranch(ctx, job.WorktreePath, branchName, token); err != nil {
 			slog.Warn("cleanup worktree: delete remote branch", "job", db.ShortID(job.ID), "branch", branchName, "err", err)
@@ -300,19 +596,36 @@ func (s *Syncer) cleanupWorktree(ctx context.Context, job db.Job) {
 	slog.Info("worktree cleaned up", "job", db.ShortID(job.ID), "path", job.WorktreePath)
 }
 
-// CheckCIStatus polls GitHub check-runs for all awaiting_checks jobs and
-// transitions them to approved (all passed) or rejected (any failed / timeout).
-func (s *Syncer) CheckCIStatus(ctx context.Context) {
-	ciTimeout, _ := time.ParseDuration(s.cfg.Daemon.CICheckTimeout)
-	if ciTimeout <= 0 {
-		ciTimeout = 30 * time.Minute
+// githubToken returns the GitHub API token for proj, or "" when none is
+// available. GitHub App minting failures are logged.
+func (s *Syncer) githubToken(ctx context.Context, proj *config.ProjectConfig) string {
+	token, err := githubapp.Token(ctx, s.cfg, proj)
+	if err != nil {
+		slog.Warn("github app token unavailable", "project", proj.Name, "err", err)
+		return ""
 	}
+	return token
+}
+
+// CheckCIStatus polls GitHub check-runs (Gitea commit statuses) for all
+// awaiting_checks jobs and transitions them to approved (all passed) or
+// rejected (any failed / timeout).
+func (s *Syncer) CheckCIStatus(ctx context.Context) {
+	s.checkCIStatus(ctx, nil)
+}
 
+// checkCIStatus is CheckCIStatus limited to jobs of the projects in due; a
+// nil due checks every project.
+func (s *Syncer) checkCIStatus(ctx context.Context, due map[string]bool) {
+	ciTimeout := s.ciCheckTimeout()
 	jobs, err := s.store.ListAwaitingChecksJobs(ctx)
 	if err != nil {
 		slog.Error("check CI status: list awaiting_checks jobs", "err", err)
 		return
 	}
+	if due != nil {
+		jobs = jobsOfProjects(jobs, due)
+	}
 	if len(jobs) == 0 {
 		return
 	}
@@ -324,97 +637,134 @@ func (s *Syncer) CheckCIStatus(ctx context.Context) {
 		if !ok {
 			continue
 		}
+		s.checkJobCI(ctx, job, proj, ciTimeout)
+	}
+}
 
-		// Non-GitHub projects: auto-approve (CI polling not supported).
-		if proj.GitHub == nil {
-			if err := s.store.UpdateJobCIStatusSummary(ctx, job.ID, "CI polling skipped: non-GitHub project"); err != nil {
-				slog.Warn("check CI: persist summary", "job", job.ID, "err", err)
-			}
-			if err := s.store.TransitionState(ctx, job.ID, "awaiting_checks", "approved"); err != nil {
-				slog.Error("check CI: auto-approve non-GitHub job", "job", job.ID, "err", err)
-			}
-			continue
-		}
+// jobsOfProjects keeps the jobs whose project is in projects.
+func jobsOfProjects(jobs []db.Job, projects map[string]bool) []db.Job {
+	return slices.DeleteFunc(jobs, func(j db.Job) bool { return !projects[j.ProjectName] })
+}
 
-		// Handle PR close/merge before CI evaluation and timeout.
-		if s.applyTerminalPRStatus(ctx, job, proj) {
-			continue
-		}
+func (s *Syncer) ciCheckTimeout() time.Duration {
+	ciTimeout, _ := time.ParseDuration(s.cfg.Daemon.CICheckTimeout)
+	if ciTimeout <= 0 {
+		ciTimeout = 30 * time.Minute
+	}
+	return ciTimeout
+}
 
-		// Timeout check.
-		timeoutBase := strings.TrimSpace(job.CIStartedAt)
-		if timeoutBase == "" {
-			timeoutBase = job.UpdatedAt
+// checkJobCI polls CI for one awaiting_checks job and approves or rejects it
+// once checks settle. A job whose checks are still pending is left as is.
+func (s *Syncer) checkJobCI(ctx context.Context, job db.Job, proj *config.ProjectConfig, ciTimeout time.Duration) {
+	// Projects without GitHub or Gitea: auto-approve (CI polling not supported).
+	if proj.GitHub == nil && proj.Gitea == nil {
+		if err := s.store.UpdateJobCIStatusSummary(ctx, job.ID, "CI polling skipped: no GitHub or Gitea source"); err != nil {
+			slog.Warn("check CI: persist summary", "job", job.ID, "err", err)
 		}
-		updatedAt, ok := parseTimestamp(timeoutBase)
-		if ok && time.Since(updatedAt) > ciTimeout {
-			reason := fmt.Sprintf("CI check timeout: no result after %s", ciTimeout)
-			if err := s.store.UpdateJobCIStatusSummary(ctx, job.ID, reason); err != nil {
-				slog.Warn("check CI: persist timeout summary", "job", job.ID, "err", err)
-			}
-			if err := s.store.RejectJob(ctx, job.ID, "awaiting_checks", reason); err != nil {
-				slog.Error("check CI: reject timed-out job", "job", job.ID, "err", err)
-			} else {
-				slog.Info("CI checks timed out", "job", db.ShortID(job.ID))
-			}
-			continue
+		if err := s.store.TransitionState(ctx, job.ID, "awaiting_checks", "approved"); err != nil {
+			slog.Error("check CI: auto-approve non-GitHub job", "job", job.ID, "err", err)
 		}
+		return
+	}
+
+	// Handle PR close/merge before CI evaluation and timeout.
+	if s.applyTerminalPRStatus(ctx, job, proj) {
+		return
+	}
 
-		// Prefer commit SHA for accuracy; fall back to branch name.
-		ref := strings.TrimSpace(job.CommitSHA)
-		if ref == "" {
-			ref = strings.TrimSpace(job.BranchName)
+	// Timeout check.
+	timeoutBase := strings.TrimSpace(job.CIStartedAt)
+	if timeoutBase == "" {
+		timeoutBase = job.UpdatedAt
+	}
+	updatedAt, ok := parseTimestamp(timeoutBase)
+	if ok && time.Since(updatedAt) > ciTimeout {
+		reason := fmt.Sprintf("CI check timeout: no result after %s", ciTimeout)
+		if err := s.store.UpdateJobCIStatusSummary(ctx, job.ID, reason); err != nil {
+			slog.Warn("check CI: persist timeout summary", "job", job.ID, "err", err)
 		}
-		if s.cfg.Tokens.GitHub == "" || ref == "" {
-			continue
+		if err := s.store.RejectJob(ctx, job.ID, "awaiting_checks", reason); err != nil {
+			slog.Error("check CI: reject timed-out job", "job", job.ID, "err", err)
+		} else {
+			slog.Info("CI checks timed out", "job", db.ShortID(job.ID))
 		}
+		return
+	}
 
-		status, err := s.getGitHubCheckRunStatus(ctx, s.cfg.Tokens.GitHub, proj.GitHub.Owner, proj.GitHub.Repo, ref)
-		if err != nil {
-			slog.Warn("check CI: get check-run status", "job", job.ID, "err", err)
-			continue
+	// Prefer commit SHA for accuracy; fall back to branch name.
+	ref := strings.TrimSpace(job.CommitSHA)
+	if ref == "" {
+		ref = strings.TrimSpace(job.BranchName)
+	}
+	if ref == "" {
+		return
+	}
+
+	var (
+		status git.CheckRunStatus
+		err    error
+	)
+	if proj.GitHub != nil {
+		token := s.githubToken(ctx, proj)
+		if token == "" {
+			return
 		}
-		if err := s.store.UpdateJobCIStatusSummary(ctx, job.ID, formatCISummary(status)); err != nil {
-			slog.Warn("check CI: persist summary", "job", job.ID, "err", err)
+		status, err = s.getGitHubCheckRunStatus(ctx, token, proj.GitHub.BaseURL, proj.GitHub.Owner, proj.GitHub.Repo, ref)
+	} else {
+		if s.cfg.Tokens.Gitea == "" {
+			return
 		}
+		status, err = s.getGiteaCommitStatus(ctx, s.cfg.Tokens.Gitea, proj.Gitea.BaseURL, proj.Gitea.Owner, proj.Gitea.Repo, ref)
+	}
+	if err != nil {
+		slog.Warn("check CI: get check-run status", "job", job.ID, "err", err)
+		return
+	}
+	if err := s.store.UpdateJobCIStatusSummary(ctx, job.ID, formatCISummary(status)); err != nil {
+		slog.Warn("check CI: persist summary", "job", job.ID, "err", err)
+	}
+	checks := db.CIChecks{Total: status.Total, Passed: status.Passed, Failed: status.Failed, Pending: status.Pending}
+	if err := s.store.UpdateJobCIChecks(ctx, job.ID, checks); err != nil {
+		slog.Warn("check CI: persist check counts", "job", job.ID, "err", err)
+	}
 
-		// No checks registered yet — wait for next poll.
-		if status.Total == 0 {
-			continue
-		}
+	// No checks registered yet — wait for next poll.
+	if status.Total == 0 {
+		return
+	}
 
-		// Any failed check → reject.
-		if status.Failed > 0 {
-			reason := fmt.Sprintf("CI check failed: %s", status.FailedCheckName)
-			if status.FailedCheckURL != "" {
-				reason += " (" + status.FailedCheckURL + ")"
-			}
-			if err := s.store.UpdateJobCIStatusSummary(ctx, job.ID, reason); err != nil {
-				slog.Warn("check CI: persist failed summary", "job", job.ID, "err", err)
-			}
-			if err := s.store.RejectJob(ctx, job.ID, "awaiting_checks", reason); err != nil {
-				slog.Error("check CI: reject failed job", "job", job.ID, "err", err)
-			} else {
-				slog.Info("CI check failed", "job", db.ShortID(job.ID), "check", status.FailedCheckName)
-			}
-			continue
+	// Any failed check → reject.
+	if status.Failed > 0 {
+		reason := fmt.Sprintf("CI check failed: %s", status.FailedCheckName)
+		if status.FailedCheckURL != "" {
+			reason += " (" + status.FailedCheckURL + ")"
 		}
-
-		// All completed and passed → approve.
-		if status.Pending == 0 && status.Passed > 0 {
-			if err := s.store.UpdateJobCIStatusSummary(ctx, job.ID, fmt.Sprintf("CI checks passed: %d/%d completed", status.Passed, status.Total)); err != nil {
-				slog.Warn("check CI: persist passed summary", "job", job.ID, "err", err)
-			}
-			if err := s.store.TransitionState(ctx, job.ID, "awaiting_checks", "approved"); err != nil {
-				slog.Error("check CI: approve job", "job", job.ID, "err", err)
-			} else {
-				slog.Info("CI checks passed", "job", db.ShortID(job.ID), "passed", status.Passed)
-			}
-			continue
+		if err := s.store.UpdateJobCIStatusSummary(ctx, job.ID, reason); err != nil {
+			slog.Warn("check CI: persist failed summary", "job", job.ID, "err", err)
+		}
+		if err := s.store.RejectJob(ctx, job.ID, "awaiting_checks", reason); err != nil {
+			slog.Error("check CI: reject failed job", "job", job.ID, "err", err)
+		} else {
+			slog.Info("CI check failed", "job", db.ShortID(job.ID), "check", status.FailedCheckName)
 		}
+		return
+	}
 
-		// Still pending — wait for next poll cycle.
+	// All completed and passed → approve.
+	if status.Pending == 0 && status.Passed > 0 {
+		if err := s.store.UpdateJobCIStatusSummary(ctx, job.ID, fmt.Sprintf("CI checks passed: %d/%d completed", status.Passed, status.Total)); err != nil {
+			slog.Warn("check CI: persist passed summary", "job", job.ID, "err", err)
+		}
+		if err := s.store.TransitionState(ctx, job.ID, "awaiting_checks", "approved"); err != nil {
+			slog.Error("check CI: approve job", "job", job.ID, "err", err)
+		} else {
+			slog.Info("CI checks passed", "job", db.ShortID(job.ID), "passed", status.Passed)
+		}
+		return
 	}
+
+	// Still pending — wait for next poll cycle.
 }
 
 func formatCISummary(status git.CheckRunStatus) string {
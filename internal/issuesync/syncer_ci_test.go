@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"autopr/internal/config"
+	"autopr/internal/db"
 	"autopr/internal/git"
 )
 
@@ -28,7 +29,7 @@ func TestCheckCIStatus_AllChecksPassed(t *testing.T) {
 		},
 	}
 	s := NewSyncer(cfg, store, make(chan string, 1))
-	s.getGitHubCheckRunStatus = func(ctx context.Context, token, owner, repo, ref string) (git.CheckRunStatus, error) {
+	s.getGitHubCheckRunStatus = func(ctx context.Context, token, baseURL, owner, repo, ref string) (git.CheckRunStatus, error) {
 		if ref != "autopr/ci-pass" {
 			t.Fatalf("unexpected ref: %q", ref)
 		}
@@ -72,7 +73,7 @@ func TestCheckCIStatus_CheckFailed(t *testing.T) {
 		},
 	}
 	s := NewSyncer(cfg, store, make(chan string, 1))
-	s.getGitHubCheckRunStatus = func(ctx context.Context, token, owner, repo, ref string) (git.CheckRunStatus, error) {
+	s.getGitHubCheckRunStatus = func(ctx context.Context, token, baseURL, owner, repo, ref string) (git.CheckRunStatus, error) {
 		return git.CheckRunStatus{
 			Total:           2,
 			Completed:       2,
@@ -119,7 +120,7 @@ func TestCheckCIStatus_Pending(t *testing.T) {
 		},
 	}
 	s := NewSyncer(cfg, store, make(chan string, 1))
-	s.getGitHubCheckRunStatus = func(ctx context.Context, token, owner, repo, ref string) (git.CheckRunStatus, error) {
+	s.getGitHubCheckRunStatus = func(ctx context.Context, token, baseURL, owner, repo, ref string) (git.CheckRunStatus, error) {
 		return git.CheckRunStatus{
 			Total:     3,
 			Completed: 1,
@@ -140,6 +141,9 @@ func TestCheckCIStatus_Pending(t *testing.T) {
 	if job.CIStatusSummary == "" || !strings.Contains(job.CIStatusSummary, "pending=2") {
 		t.Fatalf("expected CI summary to include pending count, got %q", job.CIStatusSummary)
 	}
+	if want := (db.CIChecks{Total: 3, Passed: 1, Pending: 2}); job.CIChecks != want {
+		t.Fatalf("expected check counts %+v, got %+v", want, job.CIChecks)
+	}
 }
 
 func TestCheckCIStatus_NoChecksYet(t *testing.T) {
@@ -161,7 +165,7 @@ func TestCheckCIStatus_NoChecksYet(t *testing.T) {
 		},
 	}
 	s := NewSyncer(cfg, store, make(chan string, 1))
-	s.getGitHubCheckRunStatus = func(ctx context.Context, token, owner, repo, ref string) (git.CheckRunStatus, error) {
+	s.getGitHubCheckRunStatus = func(ctx context.Context, token, baseURL, owner, repo, ref string) (git.CheckRunStatus, error) {
 		return git.CheckRunStatus{Total: 0}, nil
 	}
 
@@ -204,7 +208,7 @@ UPDATE jobs SET updated_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now', '-2 hours')
 		},
 	}
 	s := NewSyncer(cfg, store, make(chan string, 1))
-	s.checkGitHubPRStatus = func(ctx context.Context, token, prURL string) (git.PRMergeStatus, error) {
+	s.checkGitHubPRStatus = func(ctx context.Context, token, baseURL, prURL string) (git.PRMergeStatus, error) {
 		if token != "token" {
 			t.Fatalf("unexpected token: %q", token)
 		}
@@ -213,7 +217,10 @@ UPDATE jobs SET updated_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now', '-2 hours')
 		}
 		return git.PRMergeStatus{Merged: true, MergedAt: "2026-02-18T12:00:00Z"}, nil
 	}
-	s.getGitHubCheckRunStatus = func(ctx context.Context, token, owner, repo, ref string) (git.CheckRunStatus, error) {
+	s.firstGitHubApproval = func(ctx context.Context, token, baseURL, prURL string) (string, error) {
+		return "", nil
+	}
+	s.getGitHubCheckRunStatus = func(ctx context.Context, token, baseURL, owner, repo, ref string) (git.CheckRunStatus, error) {
 		t.Fatalf("CI check should not run after merged PR is detected")
 		return git.CheckRunStatus{}, nil
 	}
@@ -254,7 +261,7 @@ func TestCheckCIStatus_AwaitingChecksPRClosedBeforeTimeout(t *testing.T) {
 		},
 	}
 	s := NewSyncer(cfg, store, make(chan string, 1))
-	s.checkGitHubPRStatus = func(ctx context.Context, token, prURL string) (git.PRMergeStatus, error) {
+	s.checkGitHubPRStatus = func(ctx context.Context, token, baseURL, prURL string) (git.PRMergeStatus, error) {
 		if token != "token" {
 			t.Fatalf("unexpected token: %q", token)
 		}
@@ -263,7 +270,7 @@ func TestCheckCIStatus_AwaitingChecksPRClosedBeforeTimeout(t *testing.T) {
 		}
 		return git.PRMergeStatus{Closed: true, ClosedAt: "2026-02-18T12:01:00Z"}, nil
 	}
-	s.getGitHubCheckRunStatus = func(ctx context.Context, token, owner, repo, ref string) (git.CheckRunStatus, error) {
+	s.getGitHubCheckRunStatus = func(ctx context.Context, token, baseURL, owner, repo, ref string) (git.CheckRunStatus, error) {
 		t.Fatalf("CI check should not run after closed PR is detected")
 		return git.CheckRunStatus{}, nil
 	}
@@ -311,7 +318,7 @@ WHERE id = ?`, jobID); err != nil {
 		},
 	}
 	s := NewSyncer(cfg, store, make(chan string, 1))
-	s.getGitHubCheckRunStatus = func(ctx context.Context, token, owner, repo, ref string) (git.CheckRunStatus, error) {
+	s.getGitHubCheckRunStatus = func(ctx context.Context, token, baseURL, owner, repo, ref string) (git.CheckRunStatus, error) {
 		t.Fatalf("check-run status should not be called for timed-out job")
 		return git.CheckRunStatus{}, nil
 	}
@@ -349,7 +356,7 @@ func TestCheckCIStatus_NonGitHub(t *testing.T) {
 		},
 	}
 	s := NewSyncer(cfg, store, make(chan string, 1))
-	s.getGitHubCheckRunStatus = func(ctx context.Context, token, owner, repo, ref string) (git.CheckRunStatus, error) {
+	s.getGitHubCheckRunStatus = func(ctx context.Context, token, baseURL, owner, repo, ref string) (git.CheckRunStatus, error) {
 		t.Fatalf("GitHub check-run status should not be called for GitLab project")
 		return git.CheckRunStatus{}, nil
 	}
@@ -365,3 +372,46 @@ func TestCheckCIStatus_NonGitHub(t *testing.T) {
 		t.Fatalf("expected non-GitHub job to be auto-approved, got %q", job.State)
 	}
 }
+
+func TestCheckCIStatus_GiteaCommitStatusFailed(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	store := openTestStore(t)
+	defer store.Close()
+
+	jobID := createSyncTestJob(t, ctx, store, "project-gt", "ci-gitea", "awaiting_checks", "autopr/ci-gitea", "https://gitea.example.com/org/repo/pulls/5")
+
+	cfg := &config.Config{
+		Tokens: config.TokensConfig{Gitea: "token"},
+		Daemon: config.DaemonConfig{CICheckTimeout: "30m"},
+		Projects: []config.ProjectConfig{
+			{
+				Name:  "project-gt",
+				Gitea: &config.ProjectGitea{BaseURL: "https://gitea.example.com", Owner: "org", Repo: "repo"},
+			},
+		},
+	}
+	s := NewSyncer(cfg, store, make(chan string, 1))
+	s.checkGiteaPRStatus = func(ctx context.Context, token, baseURL, prURL string) (git.PRMergeStatus, error) {
+		return git.PRMergeStatus{}, nil
+	}
+	s.getGiteaCommitStatus = func(ctx context.Context, token, baseURL, owner, repo, ref string) (git.CheckRunStatus, error) {
+		if owner != "org" || repo != "repo" || ref != "autopr/ci-gitea" {
+			t.Fatalf("unexpected commit status lookup: %s/%s@%s", owner, repo, ref)
+		}
+		return git.CheckRunStatus{Total: 1, Completed: 1, Failed: 1, FailedCheckName: "ci/woodpecker"}, nil
+	}
+
+	s.CheckCIStatus(ctx)
+
+	job, err := store.GetJob(ctx, jobID)
+	if err != nil {
+		t.Fatalf("get job: %v", err)
+	}
+	if job.State != "rejected" {
+		t.Fatalf("expected job state rejected, got %q", job.State)
+	}
+	if !strings.Contains(job.CIStatusSummary, "ci/woodpecker") {
+		t.Fatalf("expected CI summary to name the failed status, got %q", job.CIStatusSummary)
+	}
+}
@@ -30,7 +30,7 @@ func TestCheckPRStatus_BranchFallbackMergedReadyJob(t *testing.T) {
 
 	findCalls := 0
 	statusCalls := 0
-	s.findGitHubPRByBranch = func(ctx context.Context, token, owner, repo, head, state string) (string, error) {
+	s.findGitHubPRByBranch = func(ctx context.Context, token, baseURL, owner, repo, head, state string) (string, error) {
 		findCalls++
 		if state != "all" {
 			t.Fatalf("expected state=all, got %q", state)
@@ -40,13 +40,16 @@ func TestCheckPRStatus_BranchFallbackMergedReadyJob(t *testing.T) {
 		}
 		return "https://github.com/acme/repo/pull/46", nil
 	}
-	s.checkGitHubPRStatus = func(ctx context.Context, token, prURL string) (git.PRMergeStatus, error) {
+	s.checkGitHubPRStatus = func(ctx context.Context, token, baseURL, prURL string) (git.PRMergeStatus, error) {
 		statusCalls++
 		if prURL != "https://github.com/acme/repo/pull/46" {
 			t.Fatalf("unexpected PR URL: %q", prURL)
 		}
 		return git.PRMergeStatus{Merged: true, MergedAt: "2026-02-18T01:02:03Z"}, nil
 	}
+	s.firstGitHubApproval = func(ctx context.Context, token, baseURL, prURL string) (string, error) {
+		return "", nil
+	}
 
 	s.checkPRStatus(ctx)
 
@@ -89,7 +92,7 @@ func TestCheckPRStatus_BranchFallbackUsesForkQualifiedHead(t *testing.T) {
 		},
 	}
 	s := NewSyncer(cfg, store, make(chan string, 1))
-	s.findGitHubPRByBranch = func(ctx context.Context, token, owner, repo, head, state string) (string, error) {
+	s.findGitHubPRByBranch = func(ctx context.Context, token, baseURL, owner, repo, head, state string) (string, error) {
 		if head != "my-fork:autopr/branch-fork" {
 			t.Fatalf("expected fork-qualified head, got %q", head)
 		}
@@ -98,7 +101,7 @@ func TestCheckPRStatus_BranchFallbackUsesForkQualifiedHead(t *testing.T) {
 		}
 		return "", nil
 	}
-	s.checkGitHubPRStatus = func(ctx context.Context, token, prURL string) (git.PRMergeStatus, error) {
+	s.checkGitHubPRStatus = func(ctx context.Context, token, baseURL, prURL string) (git.PRMergeStatus, error) {
 		t.Fatalf("status check should not run when no PR is found")
 		return git.PRMergeStatus{}, nil
 	}
@@ -124,10 +127,10 @@ func TestCheckPRStatus_BranchFallbackOpenPRTransitionsApproved(t *testing.T) {
 		},
 	}
 	s := NewSyncer(cfg, store, make(chan string, 1))
-	s.findGitHubPRByBranch = func(ctx context.Context, token, owner, repo, head, state string) (string, error) {
+	s.findGitHubPRByBranch = func(ctx context.Context, token, baseURL, owner, repo, head, state string) (string, error) {
 		return "https://github.com/acme/repo/pull/47", nil
 	}
-	s.checkGitHubPRStatus = func(ctx context.Context, token, prURL string) (git.PRMergeStatus, error) {
+	s.checkGitHubPRStatus = func(ctx context.Context, token, baseURL, prURL string) (git.PRMergeStatus, error) {
 		return git.PRMergeStatus{}, nil
 	}
 
@@ -166,10 +169,10 @@ func TestCheckPRStatus_BranchFallbackNoRemotePRLeavesJobUnchanged(t *testing.T)
 		},
 	}
 	s := NewSyncer(cfg, store, make(chan string, 1))
-	s.findGitHubPRByBranch = func(ctx context.Context, token, owner, repo, head, state string) (string, error) {
+	s.findGitHubPRByBranch = func(ctx context.Context, token, baseURL, owner, repo, head, state string) (string, error) {
 		return "", nil
 	}
-	s.checkGitHubPRStatus = func(ctx context.Context, token, prURL string) (git.PRMergeStatus, error) {
+	s.checkGitHubPRStatus = func(ctx context.Context, token, baseURL, prURL string) (git.PRMergeStatus, error) {
 		t.Fatalf("status check should not run when no PR is found")
 		return git.PRMergeStatus{}, nil
 	}
@@ -311,16 +314,19 @@ func TestCheckPRStatus_ApprovedKnownPRStillPolled(t *testing.T) {
 		},
 	}
 	s := NewSyncer(cfg, store, make(chan string, 1))
-	s.findGitHubPRByBranch = func(ctx context.Context, token, owner, repo, head, state string) (string, error) {
+	s.findGitHubPRByBranch = func(ctx context.Context, token, baseURL, owner, repo, head, state string) (string, error) {
 		t.Fatalf("branch lookup should not run for known PR jobs")
 		return "", nil
 	}
-	s.checkGitHubPRStatus = func(ctx context.Context, token, prURL string) (git.PRMergeStatus, error) {
+	s.checkGitHubPRStatus = func(ctx context.Context, token, baseURL, prURL string) (git.PRMergeStatus, error) {
 		if prURL != "https://github.com/acme/repo/pull/88" {
 			t.Fatalf("unexpected PR URL: %q", prURL)
 		}
 		return git.PRMergeStatus{Merged: true, MergedAt: "2026-02-18T04:05:06Z"}, nil
 	}
+	s.firstGitHubApproval = func(ctx context.Context, token, baseURL, prURL string) (string, error) {
+		return "", nil
+	}
 
 	s.checkPRStatus(ctx)
 
@@ -359,3 +365,55 @@ WHERE id = ?`, state, branch, prURL, jobID); err != nil {
 	}
 	return jobID
 }
+
+func TestCheckPRStatus_MergedWithBackportLabelsQueuesBackportJobs(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	store := openTestStore(t)
+	defer store.Close()
+
+	jobID := createSyncTestJob(t, ctx, store, "project-gh", "backport-merged", "ready", "autopr/branch-backport", "")
+
+	cfg := &config.Config{
+		Tokens: config.TokensConfig{GitHub: "token"},
+		Projects: []config.ProjectConfig{
+			{
+				Name:   "project-gh",
+				GitHub: &config.ProjectGitHub{Owner: "acme", Repo: "repo"},
+			},
+		},
+	}
+	jobCh := make(chan string, 4)
+	s := NewSyncer(cfg, store, jobCh)
+	s.findGitHubPRByBranch = func(ctx context.Context, token, baseURL, owner, repo, head, state string) (string, error) {
+		return "https://github.com/acme/repo/pull/48", nil
+	}
+	s.checkGitHubPRStatus = func(ctx context.Context, token, baseURL, prURL string) (git.PRMergeStatus, error) {
+		return git.PRMergeStatus{
+			Merged:         true,
+			MergedAt:       "2026-02-18T01:02:03Z",
+			MergeCommitSHA: "abc123",
+			Labels:         []string{"bug", "backport/release-1.2", "backport/release-1.3"},
+		}, nil
+	}
+
+	s.checkPRStatus(ctx)
+
+	if len(jobCh) != 2 {
+		t.Fatalf("expected 2 backport jobs queued, got %d", len(jobCh))
+	}
+	branches := map[string]bool{}
+	for range 2 {
+		job, err := store.GetJob(ctx, <-jobCh)
+		if err != nil {
+			t.Fatalf("get backport job: %v", err)
+		}
+		if job.ParentJobID != jobID || job.BackportCommit != "abc123" || job.State != "queued" {
+			t.Fatalf("unexpected backport job: %+v", job)
+		}
+		branches[job.BackportBranch] = true
+	}
+	if !branches["release-1.2"] || !branches["release-1.3"] {
+		t.Fatalf("expected backports for both release branches, got %v", branches)
+	}
+}
@@ -0,0 +1,102 @@
+package issuesync
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"autopr/internal/config"
+)
+
+func TestSyncAllSyncsProjectsConcurrently(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	store := openTestStore(t)
+	defer store.Close()
+
+	// The slow host answers only once the fast one has been asked, which
+	// happens only if the two projects sync at the same time.
+	fastAsked := make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-fastAsked:
+		case <-time.After(5 * time.Second):
+			http.Error(w, "fast project never synced", http.StatusGatewayTimeout)
+			return
+		}
+		_, _ = io.WriteString(w, `[]`)
+	}))
+	defer slow.Close()
+	fast := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(fastAsked)
+		_, _ = io.WriteString(w, `[]`)
+	}))
+	defer fast.Close()
+
+	cfg := &config.Config{
+		Tokens: config.TokensConfig{Gitea: "token"},
+		Daemon: config.DaemonConfig{MaxIterations: 3, SyncConcurrency: 2},
+		Projects: []config.ProjectConfig{
+			{Name: "slow", Gitea: &config.ProjectGitea{BaseURL: slow.URL, Owner: "org", Repo: "slow"}},
+			{Name: "fast", Gitea: &config.ProjectGitea{BaseURL: fast.URL, Owner: "org", Repo: "fast"}},
+		},
+	}
+	syncer := NewSyncer(cfg, store, make(chan string, 8))
+	syncer.syncAll(ctx)
+
+	statuses, err := store.ListProjectSyncStatus(ctx)
+	if err != nil {
+		t.Fatalf("list sync status: %v", err)
+	}
+	if len(statuses) != 2 {
+		t.Fatalf("expected both projects recorded, got %+v", statuses)
+	}
+	for _, st := range statuses {
+		if st.LastSuccessAt == "" || st.LastError != "" {
+			t.Fatalf("expected %s to sync cleanly, got %+v", st.ProjectName, st)
+		}
+	}
+}
+
+func TestSyncAllHonoursPerProjectSyncInterval(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	store := openTestStore(t)
+	defer store.Close()
+
+	var hourlyHits, eagerHits atomic.Int32
+	hourly := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hourlyHits.Add(1)
+		_, _ = io.WriteString(w, `[]`)
+	}))
+	defer hourly.Close()
+	eager := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		eagerHits.Add(1)
+		_, _ = io.WriteString(w, `[]`)
+	}))
+	defer eager.Close()
+
+	cfg := &config.Config{
+		Tokens: config.TokensConfig{Gitea: "token"},
+		Daemon: config.DaemonConfig{MaxIterations: 3, SyncConcurrency: 2, SyncInterval: "1ms"},
+		Projects: []config.ProjectConfig{
+			{Name: "hourly", SyncInterval: "1h", Gitea: &config.ProjectGitea{BaseURL: hourly.URL, Owner: "org", Repo: "hourly"}},
+			{Name: "eager", Gitea: &config.ProjectGitea{BaseURL: eager.URL, Owner: "org", Repo: "eager"}},
+		},
+	}
+	syncer := NewSyncer(cfg, store, make(chan string, 8))
+	syncer.syncAll(ctx)
+	time.Sleep(5 * time.Millisecond)
+	syncer.syncAll(ctx)
+
+	if got := hourlyHits.Load(); got != 1 {
+		t.Fatalf("expected hourly project synced once, got %d requests", got)
+	}
+	if got := eagerHits.Load(); got != 2 {
+		t.Fatalf("expected eager project synced twice, got %d requests", got)
+	}
+}
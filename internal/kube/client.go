@@ -0,0 +1,245 @@
+// Package kube runs the commands of claimed jobs as Kubernetes Jobs.
+//
+// For projects using the kubernetes executor, the daemon stays the
+// controller: it claims jobs, clones them onto a volume shared with the worker
+// pods, and runs every pipeline step as before, but each LLM CLI, setup, test,
+// and regenerate command runs in a pod created from the worker image. The pod's log is
+// streamed back as the command's output, so sessions fill in live, and a pod
+// that dies fails the command like a crashed local process would.
+//
+// The client speaks the Kubernetes REST API directly over net/http, like the
+// forge clients, and needs only create/get/list/delete on jobs and get/list on
+// pods and pods/log in its namespace.
+package kube
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"autopr/internal/config"
+)
+
+// In-cluster service account files, mounted into every pod.
+const (
+	serviceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+	inClusterToken    = serviceAccountDir + "/token"
+	inClusterCA       = serviceAccountDir + "/ca.crt"
+	inClusterNS       = serviceAccountDir + "/namespace"
+)
+
+// Client is a minimal Kubernetes API client scoped to one namespace.
+type Client struct {
+	baseURL   string
+	namespace string
+	tokenFile string
+	http      *http.Client
+}
+
+// NewClient builds a client from cfg, falling back to the in-cluster service
+// account for the API server, token, CA, and namespace.
+func NewClient(cfg config.KubernetesConfig) (*Client, error) {
+	baseURL := strings.TrimRight(cfg.APIServer, "/")
+	tokenFile, caFile := cfg.TokenFile, cfg.CAFile
+	if baseURL == "" {
+		host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+		if host == "" || port == "" {
+			return nil, fmt.Errorf("kubernetes.api_server is not set and the daemon is not running in a cluster")
+		}
+		baseURL = "https://" + net.JoinHostPort(host, port)
+		if tokenFile == "" {
+			tokenFile = inClusterToken
+		}
+		if caFile == "" {
+			caFile = inClusterCA
+		}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if caFile != "" {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("read kubernetes ca: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("read kubernetes ca: no certificates in %s", caFile)
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+	}
+
+	namespace := cfg.Namespace
+	if namespace == "" {
+		if data, err := os.ReadFile(inClusterNS); err == nil {
+			namespace = strings.TrimSpace(string(data))
+		}
+	}
+	if namespace == "" {
+		namespace = "default"
+	}
+
+	return &Client{
+		baseURL:   baseURL,
+		namespace: namespace,
+		tokenFile: tokenFile,
+		http:      &http.Client{Transport: transport},
+	}, nil
+}
+
+// Namespace returns the namespace the client operates in.
+func (c *Client) Namespace() string { return c.namespace }
+
+// CreateJob creates job in the client's namespace.
+func (c *Client) CreateJob(ctx context.Context, job *Job) error {
+	body, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("marshal kubernetes job: %w", err)
+	}
+	resp, err := c.do(ctx, http.MethodPost, c.path("apis/batch/v1", "jobs", ""), nil, body)
+	if err != nil {
+		return fmt.Errorf("create kubernetes job %s: %w", job.Metadata.Name, err)
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// DeleteJob deletes the named job and its pods. A missing job is not an error.
+func (c *Client) DeleteJob(ctx context.Context, name string) error {
+	query := url.Values{"propagationPolicy": {"Background"}}
+	resp, err := c.do(ctx, http.MethodDelete, c.path("apis/batch/v1", "jobs", name), query, nil)
+	if err != nil {
+		if isNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("delete kubernetes job %s: %w", name, err)
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// ListJobs lists the jobs matching labelSelector.
+func (c *Client) ListJobs(ctx context.Context, labelSelector string) ([]Job, error) {
+	var list struct {
+		Items []Job `json:"items"`
+	}
+	if err := c.list(ctx, "apis/batch/v1", "jobs", labelSelector, &list); err != nil {
+		return nil, fmt.Errorf("list kubernetes jobs: %w", err)
+	}
+	return list.Items, nil
+}
+
+// ListPods lists the pods matching labelSelector.
+func (c *Client) ListPods(ctx context.Context, labelSelector string) ([]Pod, error) {
+	var list struct {
+		Items []Pod `json:"items"`
+	}
+	if err := c.list(ctx, "api/v1", "pods", labelSelector, &list); err != nil {
+		return nil, fmt.Errorf("list kubernetes pods: %w", err)
+	}
+	return list.Items, nil
+}
+
+// PodLogs follows the log of the named pod until its container exits or ctx
+// is done.
+func (c *Client) PodLogs(ctx context.Context, pod string) (io.ReadCloser, error) {
+	query := url.Values{"follow": {"true"}}
+	resp, err := c.do(ctx, http.MethodGet, c.path("api/v1", "pods", pod)+"/log", query, nil)
+	if err != nil {
+		return nil, fmt.Errorf("stream kubernetes pod log %s: %w", pod, err)
+	}
+	return resp.Body, nil
+}
+
+func (c *Client) list(ctx context.Context, group, resource, labelSelector string, out any) error {
+	query := url.Values{}
+	if labelSelector != "" {
+		query.Set("labelSelector", labelSelector)
+	}
+	resp, err := c.do(ctx, http.MethodGet, c.path(group, resource, ""), query, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decode %s: %w", resource, err)
+	}
+	return nil
+}
+
+func (c *Client) path(group, resource, name string) string {
+	p := fmt.Sprintf("%s/%s/namespaces/%s/%s", c.baseURL, group, url.PathEscape(c.namespace), resource)
+	if name != "" {
+		p += "/" + url.PathEscape(name)
+	}
+	return p
+}
+
+// do sends a request and returns the response when its status is 2xx. The
+// caller closes the body.
+func (c *Client) do(ctx context.Context, method, apiURL string, query url.Values, body []byte) (*http.Response, error) {
+	if len(query) > 0 {
+		apiURL += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, apiURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	// Re-read the token on every request: projected service account tokens
+	// are rotated while the daemon runs.
+	if c.tokenFile != "" {
+		token, err := os.ReadFile(c.tokenFile)
+		if err != nil {
+			return nil, fmt.Errorf("read kubernetes token: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		defer resp.Body.Close()
+		return nil, apiError(resp)
+	}
+	return resp, nil
+}
+
+// statusError is a non-2xx answer from the API server.
+type statusError struct {
+	code    int
+	message string
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("HTTP %d: %s", e.code, e.message)
+}
+
+func apiError(resp *http.Response) error {
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	var status struct {
+		Message string `json:"message"`
+	}
+	msg := strings.TrimSpace(string(data))
+	if json.Unmarshal(data, &status) == nil && status.Message != "" {
+		msg = status.Message
+	}
+	return &statusError{code: resp.StatusCode, message: msg}
+}
+
+func isNotFound(err error) bool {
+	se, ok := err.(*statusError)
+	return ok && se.code == http.StatusNotFound
+}
@@ -0,0 +1,419 @@
+package kube
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"autopr/internal/config"
+	"autopr/internal/executor"
+)
+
+// Labels on every Kubernetes Job the executor creates, and on its pod.
+const (
+	labelManagedBy = "app.kubernetes.io/managed-by"
+	labelJobID     = "autopr.dev/job-id"
+	managedBy      = "autopr"
+	managedLabel   = labelManagedBy + "=" + managedBy
+)
+
+// finishedJobTTL lets the cluster garbage-collect a Kubernetes Job the daemon
+// could not delete itself.
+const finishedJobTTL = 3600
+
+// stdinEnv carries a command's base64-encoded stdin into its pod.
+const stdinEnv = "AUTOPR_STDIN"
+
+// pollInterval is how often pod status is polled while a command starts and
+// after its log ends.
+const pollInterval = 2 * time.Second
+
+// fatalWaitReasons are the reasons a pending container will not start without
+// a change to the cluster or the config.
+var fatalWaitReasons = map[string]bool{
+	"ErrImagePull":               true,
+	"ImagePullBackOff":           true,
+	"InvalidImageName":           true,
+	"CreateContainerConfigError": true,
+	"CreateContainerError":       true,
+}
+
+// Executor runs commands as Kubernetes Jobs. It implements
+// executor.Executor.
+type Executor struct {
+	client       *Client
+	cfg          config.KubernetesConfig
+	image        string
+	reposRoot    string
+	startTimeout time.Duration
+	poll         time.Duration
+	running      *jobSet // shared with the executors WithImage returns
+}
+
+// jobSet holds the names of the Kubernetes Jobs commands are waiting on.
+type jobSet struct {
+	mu    sync.Mutex
+	names map[string]struct{}
+}
+
+// NewExecutor returns an executor for cfg.Kubernetes, whose pods mount the
+// worktree claim at cfg.ReposRoot.
+func NewExecutor(cfg *config.Config) (*Executor, error) {
+	client, err := NewClient(cfg.Kubernetes)
+	if err != nil {
+		return nil, err
+	}
+	return newExecutor(client, cfg), nil
+}
+
+func newExecutor(client *Client, cfg *config.Config) *Executor {
+	startTimeout, _ := time.ParseDuration(cfg.Kubernetes.StartTimeout)
+	if startTimeout <= 0 {
+		startTimeout = 5 * time.Minute
+	}
+	return &Executor{
+		client:       client,
+		cfg:          cfg.Kubernetes,
+		image:        cfg.Kubernetes.Image,
+		reposRoot:    cfg.ReposRoot,
+		startTimeout: startTimeout,
+		poll:         pollInterval,
+		running:      &jobSet{names: make(map[string]struct{})},
+	}
+}
+
+// WithImage returns an executor whose pods run image, for projects that set
+// executor_image. An empty image returns e.
+func (e *Executor) WithImage(image string) *Executor {
+	if image == "" || image == e.image {
+		return e
+	}
+	clone := *e
+	clone.image = image
+	return &clone
+}
+
+// Command returns a Cmd that runs spec in the pod of a new Kubernetes Job.
+func (e *Executor) Command(ctx context.Context, spec executor.Spec) executor.Process {
+	return &Cmd{ctx: ctx, ex: e, spec: spec}
+}
+
+// Cmd is a command running in the pod of a Kubernetes Job. Its API follows
+// exec.Cmd: the pod's log is the command's output, and cancelling its
+// context deletes the Kubernetes Job, which kills the pod.
+type Cmd struct {
+	ctx      context.Context
+	ex       *Executor
+	spec     executor.Spec
+	name     string
+	pod      string
+	stdout   io.Writer
+	pipe     *io.PipeWriter
+	stop     func() bool
+	copyDone chan error
+}
+
+// StdoutPipe returns a pipe carrying the pod's log. It must be called before
+// Start, and read to EOF before Wait.
+func (c *Cmd) StdoutPipe() (io.ReadCloser, error) {
+	if c.stdout != nil {
+		return nil, errors.New("kube: Stdout already set")
+	}
+	pr, pw := io.Pipe()
+	c.stdout, c.pipe = pw, pw
+	return pr, nil
+}
+
+// CombinedOutput runs the command and returns its log.
+func (c *Cmd) CombinedOutput() ([]byte, error) {
+	if c.stdout != nil {
+		return nil, errors.New("kube: Stdout already set")
+	}
+	var buf bytes.Buffer
+	c.stdout = &buf
+	if err := c.Start(); err != nil {
+		return nil, err
+	}
+	err := c.Wait()
+	return buf.Bytes(), err
+}
+
+// Start creates the Kubernetes Job, waits for its pod to start, and begins
+// streaming the pod's log.
+func (c *Cmd) Start() error {
+	if c.name != "" {
+		return errors.New("kube: already started")
+	}
+	if c.stdout == nil {
+		c.stdout = io.Discard
+	}
+	c.name = executor.UniqueName(c.spec.JobID)
+	c.ex.track(c.name)
+	if err := c.ex.client.CreateJob(c.ctx, c.ex.jobSpec(c)); err != nil {
+		c.ex.untrack(c.name)
+		c.closePipe(err)
+		return err
+	}
+	slog.Debug("kubernetes job created", "name", c.name, "job", c.spec.JobID, "command", c.spec.Argv[0])
+	c.stop = context.AfterFunc(c.ctx, func() { c.ex.deleteJob(c.name) })
+
+	pod, err := c.ex.waitForPod(c.ctx, c.name)
+	if err != nil {
+		c.cleanup(err)
+		return err
+	}
+	logs, err := c.ex.client.PodLogs(c.ctx, pod)
+	if err != nil {
+		c.cleanup(err)
+		return err
+	}
+	c.pod = pod
+	c.copyDone = make(chan error, 1)
+	go func() {
+		_, err := io.Copy(c.stdout, logs)
+		logs.Close()
+		c.closePipe(err)
+		c.copyDone <- err
+	}()
+	return nil
+}
+
+// Wait waits for the pod to finish and deletes the Kubernetes Job. A pod that
+// fails, is evicted, or disappears returns an error.
+func (c *Cmd) Wait() error {
+	if c.copyDone == nil {
+		return errors.New("kube: not started")
+	}
+	copyErr := <-c.copyDone
+	err := c.ex.waitForExit(c.ctx, c.name, c.pod)
+	if err == nil && copyErr != nil {
+		slog.Warn("kubernetes pod log ended early; output may be incomplete", "name", c.name, "err", copyErr)
+	}
+	c.cleanup(nil)
+	return err
+}
+
+func (c *Cmd) closePipe(err error) {
+	if c.pipe != nil {
+		c.pipe.CloseWithError(err)
+	}
+}
+
+func (c *Cmd) cleanup(err error) {
+	if c.stop != nil {
+		c.stop()
+	}
+	c.closePipe(err)
+	c.ex.deleteJob(c.name)
+	c.ex.untrack(c.name)
+}
+
+// ExitError reports a command whose pod failed.
+type ExitError struct {
+	Job      string // Kubernetes Job name
+	ExitCode int    // -1 when the container never terminated (e.g. eviction)
+	Reason   string // e.g. "Error", "OOMKilled", "Evicted"
+	Message  string
+}
+
+func (e *ExitError) Error() string {
+	var msg string
+	if e.ExitCode < 0 {
+		msg = fmt.Sprintf("kubernetes job %s: pod failed", e.Job)
+	} else {
+		msg = fmt.Sprintf("kubernetes job %s: exit status %d", e.Job, e.ExitCode)
+	}
+	if e.Reason != "" && e.Reason != "Error" {
+		msg += " (" + e.Reason + ")"
+	}
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	return msg
+}
+
+func (e *Executor) jobSpec(c *Cmd) *Job {
+	labels := map[string]string{labelManagedBy: managedBy, labelJobID: c.spec.JobID}
+	container := Container{
+		Name:         "step",
+		Image:        e.image,
+		Command:      c.spec.Argv,
+		WorkingDir:   c.spec.Dir,
+		VolumeMounts: []VolumeMount{{Name: "repos", MountPath: e.reposRoot}},
+	}
+	for _, kv := range c.spec.Env {
+		name, value, ok := strings.Cut(kv, "=")
+		if !ok || name == "" {
+			continue
+		}
+		container.Env = append(container.Env, EnvVar{Name: name, Value: value})
+	}
+	if c.spec.Stdin != nil {
+		// Pods started as Jobs have no stdin; the input is decoded from the
+		// environment into the command's.
+		container.Command = append([]string{"sh", "-c", `printf '%s' "$` + stdinEnv + `" | base64 -d | "$@"`, "sh"}, c.spec.Argv...)
+		container.Env = append(container.Env, EnvVar{Name: stdinEnv, Value: base64.StdEncoding.EncodeToString(c.spec.Stdin)})
+	}
+	if e.cfg.SecretEnv != "" {
+		container.EnvFrom = []EnvFromSource{{SecretRef: &NameRef{Name: e.cfg.SecretEnv}}}
+	}
+	if e.cfg.CPU != "" || e.cfg.Memory != "" {
+		res := &Resources{Requests: map[string]string{}}
+		if e.cfg.CPU != "" {
+			res.Requests["cpu"] = e.cfg.CPU
+		}
+		if e.cfg.Memory != "" {
+			res.Requests["memory"] = e.cfg.Memory
+			res.Limits = map[string]string{"memory": e.cfg.Memory}
+		}
+		container.Resources = res
+	}
+	return &Job{
+		APIVersion: "batch/v1",
+		Kind:       "Job",
+		Metadata:   ObjectMeta{Name: c.name, Labels: labels},
+		Spec: JobSpec{
+			BackoffLimit:            0,
+			TTLSecondsAfterFinished: finishedJobTTL,
+			Template: PodTemplate{
+				Metadata: ObjectMeta{Labels: labels},
+				Spec: PodSpec{
+					RestartPolicy:      "Never",
+					ServiceAccountName: e.cfg.ServiceAccount,
+					Containers:         []Container{container},
+					Volumes: []Volume{{
+						Name:                  "repos",
+						PersistentVolumeClaim: &ClaimRef{ClaimName: e.cfg.WorktreeClaim},
+					}},
+				},
+			},
+		},
+	}
+}
+
+// waitForPod waits until the pod of the named Kubernetes Job has started and
+// returns its name. A pod still pending after the start timeout, or waiting
+// for a reason that will not resolve itself, is an error.
+func (e *Executor) waitForPod(ctx context.Context, name string) (string, error) {
+	deadline := time.Now().Add(e.startTimeout)
+	ticker := time.NewTicker(e.poll)
+	defer ticker.Stop()
+	lastReason := "no pod scheduled"
+	for {
+		pods, err := e.client.ListPods(ctx, "job-name="+name)
+		if err != nil {
+			return "", err
+		}
+		for _, pod := range pods {
+			if pod.Status.Phase != "Pending" {
+				return pod.Metadata.Name, nil
+			}
+			lastReason = "pending"
+			if w := waitingState(pod); w != nil && w.Reason != "" {
+				lastReason = w.Reason
+				if w.Message != "" {
+					lastReason += ": " + w.Message
+				}
+				if fatalWaitReasons[w.Reason] {
+					return "", fmt.Errorf("kubernetes job %s: pod %s cannot start: %s", name, pod.Metadata.Name, lastReason)
+				}
+			}
+		}
+		if time.Now().After(deadline) {
+			return "", fmt.Errorf("kubernetes job %s: pod not started after %s: %s", name, e.startTimeout, lastReason)
+		}
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// waitForExit polls the pod until its container has terminated.
+func (e *Executor) waitForExit(ctx context.Context, name, podName string) error {
+	ticker := time.NewTicker(e.poll)
+	defer ticker.Stop()
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		pods, err := e.client.ListPods(ctx, "job-name="+name)
+		if err != nil {
+			return err
+		}
+		var pod *Pod
+		for i := range pods {
+			if pods[i].Metadata.Name == podName {
+				pod = &pods[i]
+			}
+		}
+		switch {
+		case pod == nil:
+			return fmt.Errorf("kubernetes job %s: pod %s disappeared before its command finished", name, podName)
+		case pod.Status.Phase == "Succeeded":
+			return nil
+		case pod.Status.Phase == "Failed":
+			exitErr := &ExitError{Job: name, ExitCode: -1, Reason: pod.Status.Reason, Message: pod.Status.Message}
+			if t := terminatedState(*pod); t != nil {
+				exitErr.ExitCode, exitErr.Reason, exitErr.Message = t.ExitCode, t.Reason, t.Message
+			}
+			return exitErr
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func waitingState(pod Pod) *StateReason {
+	if len(pod.Status.ContainerStatuses) == 0 {
+		return nil
+	}
+	return pod.Status.ContainerStatuses[0].State.Waiting
+}
+
+func terminatedState(pod Pod) *TerminatedReason {
+	if len(pod.Status.ContainerStatuses) == 0 {
+		return nil
+	}
+	return pod.Status.ContainerStatuses[0].State.Terminated
+}
+
+// deleteJob deletes a Kubernetes Job on a fresh context, since it usually
+// runs because the command's context was cancelled.
+func (e *Executor) deleteJob(name string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := e.client.DeleteJob(ctx, name); err != nil {
+		slog.Warn("delete kubernetes job", "name", name, "err", err)
+	}
+}
+
+func (e *Executor) track(name string) {
+	e.running.mu.Lock()
+	defer e.running.mu.Unlock()
+	e.running.names[name] = struct{}{}
+}
+
+func (e *Executor) untrack(name string) {
+	e.running.mu.Lock()
+	defer e.running.mu.Unlock()
+	delete(e.running.names, name)
+}
+
+func (e *Executor) isRunning(name string) bool {
+	e.running.mu.Lock()
+	defer e.running.mu.Unlock()
+	_, ok := e.running.names[name]
+	return ok
+}
@@ -0,0 +1,385 @@
+package kube
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"autopr/internal/config"
+	"autopr/internal/executor"
+)
+
+// fakeCluster serves the subset of the Kubernetes API the executor uses. Each
+// created Kubernetes Job gets one pod, named after it, whose status on the
+// nth poll comes from podStatus.
+type fakeCluster struct {
+	t         *testing.T
+	podStatus func(poll int) PodStatus
+	logs      string
+	blockLogs bool // hold the log stream open until the request is cancelled
+
+	mu      sync.Mutex
+	created []Job
+	deleted []string
+	polls   int
+	auth    string
+}
+
+func (f *fakeCluster) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	f.auth = r.Header.Get("Authorization")
+	f.mu.Unlock()
+	const prefix = "/apis/batch/v1/namespaces/ci/jobs"
+	const podsPrefix = "/api/v1/namespaces/ci/pods"
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == prefix:
+		var job Job
+		if err := json.NewDecoder(r.Body).Decode(&job); err != nil {
+			f.t.Errorf("decode job: %v", err)
+		}
+		f.mu.Lock()
+		f.created = append(f.created, job)
+		f.mu.Unlock()
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(job)
+	case r.Method == http.MethodDelete && strings.HasPrefix(r.URL.Path, prefix+"/"):
+		if r.URL.Query().Get("propagationPolicy") != "Background" {
+			f.t.Errorf("expected background propagation, got %q", r.URL.RawQuery)
+		}
+		name := strings.TrimPrefix(r.URL.Path, prefix+"/")
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		for _, d := range f.deleted {
+			if d == name {
+				w.WriteHeader(http.StatusNotFound)
+				_, _ = io.WriteString(w, `{"kind":"Status","message":"jobs.batch \"`+name+`\" not found"}`)
+				return
+			}
+		}
+		f.deleted = append(f.deleted, name)
+		_, _ = io.WriteString(w, `{}`)
+	case r.Method == http.MethodGet && r.URL.Path == prefix:
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		var items []Job
+		for _, job := range f.created {
+			if !f.isDeleted(job.Metadata.Name) {
+				items = append(items, job)
+			}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"items": items})
+	case r.Method == http.MethodGet && r.URL.Path == podsPrefix:
+		name := strings.TrimPrefix(r.URL.Query().Get("labelSelector"), "job-name=")
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		var items []Pod
+		if !f.isDeleted(name) {
+			f.polls++
+			items = append(items, Pod{Metadata: ObjectMeta{Name: name + "-pod"}, Status: f.podStatus(f.polls)})
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"items": items})
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, podsPrefix+"/") && strings.HasSuffix(r.URL.Path, "/log"):
+		if r.URL.Query().Get("follow") != "true" {
+			f.t.Errorf("expected a followed log, got %q", r.URL.RawQuery)
+		}
+		_, _ = io.WriteString(w, f.logs)
+		if f.blockLogs {
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+		}
+	default:
+		f.t.Errorf("unexpected request %s %s", r.Method, r.URL.String())
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (f *fakeCluster) isDeleted(name string) bool {
+	for _, d := range f.deleted {
+		if d == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *fakeCluster) snapshot() (created []Job, deleted []string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Job(nil), f.created...), append([]string(nil), f.deleted...)
+}
+
+func newTestExecutor(t *testing.T, f *fakeCluster) *Executor {
+	t.Helper()
+	f.t = t
+	srv := httptest.NewServer(f)
+	t.Cleanup(srv.Close)
+
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(tokenFile, []byte("sa-token\n"), 0o600); err != nil {
+		t.Fatalf("write token: %v", err)
+	}
+	client, err := NewClient(config.KubernetesConfig{APIServer: srv.URL, Namespace: "ci", TokenFile: tokenFile})
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	cfg := &config.Config{ReposRoot: "/data/repos"}
+	cfg.Kubernetes = config.KubernetesConfig{
+		Enabled:       true,
+		Image:         "registry.example/autopr-worker:1",
+		WorktreeClaim: "autopr-repos",
+		SecretEnv:     "llm-keys",
+		Memory:        "4Gi",
+		StartTimeout:  "1s",
+	}
+	ex := newExecutor(client, cfg)
+	ex.poll = time.Millisecond
+	return ex
+}
+
+func phase(p string) func(int) PodStatus {
+	return func(int) PodStatus { return PodStatus{Phase: p} }
+}
+
+func TestCmdRunsAsKubernetesJobAndStreamsLog(t *testing.T) {
+	t.Parallel()
+
+	f := &fakeCluster{
+		logs: "{\"type\":\"result\",\"result\":\"one\"}\n{\"type\":\"result\",\"result\":\"two\"}\n",
+		podStatus: func(poll int) PodStatus {
+			switch poll {
+			case 1:
+				return PodStatus{Phase: "Pending", ContainerStatuses: []ContainerStatus{{State: ContainerState{Waiting: &StateReason{Reason: "ContainerCreating"}}}}}
+			case 2:
+				return PodStatus{Phase: "Running"}
+			default:
+				return PodStatus{Phase: "Succeeded"}
+			}
+		},
+	}
+	ex := newTestExecutor(t, f)
+
+	ctx := executor.WithJob(context.Background(), ex.WithImage("registry.example/autopr-node:2"), "ap-job-0123456789abcdef")
+	dir := "/data/repos/worktrees/ap-job-0123456789abcdef"
+	cmd := executor.Command(ctx, executor.Spec{
+		Dir:  dir,
+		Env:  []string{"AUTOPR_STEP=implement", "malformed"},
+		Argv: []string{"claude", "--print", "--prompt", "fix it"},
+	})
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatalf("stdout pipe: %v", err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	var lines []string
+	scanner := bufio.NewScanner(stdout)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if err := cmd.Wait(); err != nil {
+		t.Fatalf("wait: %v", err)
+	}
+	if len(lines) != 2 || !strings.Contains(lines[1], `"two"`) {
+		t.Fatalf("expected the pod log as output, got %q", lines)
+	}
+
+	created, deleted := f.snapshot()
+	if len(created) != 1 {
+		t.Fatalf("expected one kubernetes job, got %d", len(created))
+	}
+	job := created[0]
+	if !strings.HasPrefix(job.Metadata.Name, "autopr-0123456789abcdef-") || len(job.Metadata.Name) > 63 {
+		t.Fatalf("unexpected job name %q", job.Metadata.Name)
+	}
+	if job.Metadata.Labels[labelJobID] != "ap-job-0123456789abcdef" || job.Metadata.Labels[labelManagedBy] != "autopr" {
+		t.Fatalf("unexpected labels %v", job.Metadata.Labels)
+	}
+	spec := job.Spec.Template.Spec
+	c := spec.Containers[0]
+	if spec.RestartPolicy != "Never" || job.Spec.BackoffLimit != 0 {
+		t.Fatalf("expected a single attempt, got restart=%q backoff=%d", spec.RestartPolicy, job.Spec.BackoffLimit)
+	}
+	if c.Image != "registry.example/autopr-node:2" || strings.Join(c.Command, " ") != "claude --print --prompt fix it" || c.WorkingDir != dir {
+		t.Fatalf("unexpected container %+v", c)
+	}
+	if len(c.Env) != 1 || c.Env[0] != (EnvVar{Name: "AUTOPR_STEP", Value: "implement"}) {
+		t.Fatalf("expected the step env, got %+v", c.Env)
+	}
+	if len(c.EnvFrom) != 1 || c.EnvFrom[0].SecretRef.Name != "llm-keys" {
+		t.Fatalf("expected the secret env, got %+v", c.EnvFrom)
+	}
+	if c.Resources == nil || c.Resources.Limits["memory"] != "4Gi" {
+		t.Fatalf("expected a memory limit, got %+v", c.Resources)
+	}
+	if c.VolumeMounts[0].MountPath != "/data/repos" || spec.Volumes[0].PersistentVolumeClaim.ClaimName != "autopr-repos" {
+		t.Fatalf("expected repos_root on the worktree claim, got %+v %+v", c.VolumeMounts, spec.Volumes)
+	}
+	if len(deleted) != 1 || deleted[0] != job.Metadata.Name {
+		t.Fatalf("expected the finished job to be deleted, got %v", deleted)
+	}
+	if ex.isRunning(job.Metadata.Name) {
+		t.Fatal("expected the finished job to be untracked")
+	}
+	if f.auth != "Bearer sa-token" {
+		t.Fatalf("expected the service account token, got %q", f.auth)
+	}
+}
+
+func TestJobSpecPassesStdinThroughEnv(t *testing.T) {
+	t.Parallel()
+
+	ex := newTestExecutor(t, &fakeCluster{})
+	job := ex.jobSpec(&Cmd{spec: executor.Spec{
+		Dir:   "/data/repos/worktrees/ap-job-1",
+		Stdin: []byte(`{"point":"pre_push"}`),
+		Argv:  []string{"./check", "--strict"},
+	}})
+	c := job.Spec.Template.Spec.Containers[0]
+	if len(c.Command) != 6 || c.Command[0] != "sh" || c.Command[4] != "./check" || c.Command[5] != "--strict" {
+		t.Fatalf("expected the command wrapped to read stdin from the env, got %q", c.Command)
+	}
+	want := EnvVar{Name: stdinEnv, Value: base64.StdEncoding.EncodeToString([]byte(`{"point":"pre_push"}`))}
+	if len(c.Env) != 1 || c.Env[0] != want {
+		t.Fatalf("expected the encoded stdin in the env, got %+v", c.Env)
+	}
+}
+
+func TestCmdReportsFailedPod(t *testing.T) {
+	t.Parallel()
+
+	f := &fakeCluster{
+		logs: "ok 1\nFAIL 2\n",
+		podStatus: func(poll int) PodStatus {
+			if poll == 1 {
+				return PodStatus{Phase: "Running"}
+			}
+			return PodStatus{Phase: "Failed", ContainerStatuses: []ContainerStatus{{State: ContainerState{
+				Terminated: &TerminatedReason{ExitCode: 137, Reason: "OOMKilled"},
+			}}}}
+		},
+	}
+	ex := newTestExecutor(t, f)
+
+	out, err := ex.Command(context.Background(), executor.Spec{JobID: "ap-job-1", Argv: []string{"go", "test", "./..."}}).CombinedOutput()
+	var exitErr *ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitCode != 137 || exitErr.Reason != "OOMKilled" {
+		t.Fatalf("expected OOMKilled exit error, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "exit status 137 (OOMKilled)") {
+		t.Fatalf("unexpected error text %q", err)
+	}
+	if string(out) != "ok 1\nFAIL 2\n" {
+		t.Fatalf("expected the log as output, got %q", out)
+	}
+}
+
+func TestCmdReportsEvictedPod(t *testing.T) {
+	t.Parallel()
+
+	f := &fakeCluster{
+		podStatus: func(poll int) PodStatus {
+			if poll == 1 {
+				return PodStatus{Phase: "Running"}
+			}
+			return PodStatus{Phase: "Failed", Reason: "Evicted", Message: "node was low on resource: memory"}
+		},
+	}
+	ex := newTestExecutor(t, f)
+
+	_, err := ex.Command(context.Background(), executor.Spec{JobID: "ap-job-1", Argv: []string{"codex", "exec"}}).CombinedOutput()
+	if err == nil || !strings.Contains(err.Error(), "pod failed (Evicted): node was low on resource: memory") {
+		t.Fatalf("expected an eviction error, got %v", err)
+	}
+}
+
+func TestCmdFailsFastWhenImageCannotBePulled(t *testing.T) {
+	t.Parallel()
+
+	f := &fakeCluster{podStatus: func(int) PodStatus {
+		return PodStatus{Phase: "Pending", ContainerStatuses: []ContainerStatus{{State: ContainerState{
+			Waiting: &StateReason{Reason: "ImagePullBackOff", Message: "Back-off pulling image"},
+		}}}}
+	}}
+	ex := newTestExecutor(t, f)
+
+	err := ex.Command(context.Background(), executor.Spec{JobID: "ap-job-1", Argv: []string{"claude"}}).Start()
+	if err == nil || !strings.Contains(err.Error(), "cannot start: ImagePullBackOff: Back-off pulling image") {
+		t.Fatalf("expected image pull error, got %v", err)
+	}
+	if _, deleted := f.snapshot(); len(deleted) != 1 {
+		t.Fatalf("expected the stuck job to be deleted, got %v", deleted)
+	}
+}
+
+func TestCmdTimesOutPendingPod(t *testing.T) {
+	t.Parallel()
+
+	f := &fakeCluster{podStatus: phase("Pending")}
+	ex := newTestExecutor(t, f)
+	ex.startTimeout = 20 * time.Millisecond
+
+	err := ex.Command(context.Background(), executor.Spec{JobID: "ap-job-1", Argv: []string{"claude"}}).Start()
+	if err == nil || !strings.Contains(err.Error(), "pod not started after 20ms: pending") {
+		t.Fatalf("expected start timeout, got %v", err)
+	}
+}
+
+func TestCmdCancelDeletesJob(t *testing.T) {
+	t.Parallel()
+
+	f := &fakeCluster{podStatus: phase("Running"), logs: "working\n", blockLogs: true}
+	ex := newTestExecutor(t, f)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cmd := ex.Command(ctx, executor.Spec{JobID: "ap-job-1", Argv: []string{"claude"}})
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatalf("stdout pipe: %v", err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	line, err := bufio.NewReader(stdout).ReadString('\n')
+	if err != nil || line != "working\n" {
+		t.Fatalf("expected streamed output before exit, got %q, %v", line, err)
+	}
+	cancel()
+	_, _ = io.Copy(io.Discard, stdout)
+	if err := cmd.Wait(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected cancellation, got %v", err)
+	}
+	if _, deleted := f.snapshot(); len(deleted) != 1 {
+		t.Fatalf("expected the cancelled job to be deleted, got %v", deleted)
+	}
+}
+
+func TestCmdReportsPodThatDisappears(t *testing.T) {
+	t.Parallel()
+
+	f := &fakeCluster{podStatus: phase("Running")}
+	ex := newTestExecutor(t, f)
+
+	cmd := ex.Command(context.Background(), executor.Spec{JobID: "ap-job-1", Argv: []string{"claude"}}).(*Cmd)
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	// Someone deletes the Kubernetes Job while the command runs.
+	if err := ex.client.DeleteJob(context.Background(), cmd.name); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if err := cmd.Wait(); err == nil || !strings.Contains(err.Error(), "disappeared before its command finished") {
+		t.Fatalf("expected a vanished pod error, got %v", err)
+	}
+}
@@ -0,0 +1,51 @@
+package kube
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// reconcileInterval is how often orphaned Kubernetes Jobs are cleaned up.
+const reconcileInterval = time.Minute
+
+// Reconcile deletes the Kubernetes Jobs labelled as autopr's that no command
+// of this daemon is waiting on: ones left behind when a daemon crashed or was
+// restarted (crash recovery has re-queued their jobs), or whose deletion
+// failed. It returns how many it deleted. Run one daemon per namespace.
+func (e *Executor) Reconcile(ctx context.Context) (int, error) {
+	jobs, err := e.client.ListJobs(ctx, managedLabel)
+	if err != nil {
+		return 0, err
+	}
+	deleted := 0
+	for _, job := range jobs {
+		name := job.Metadata.Name
+		if e.isRunning(name) {
+			continue
+		}
+		if err := e.client.DeleteJob(ctx, name); err != nil {
+			return deleted, err
+		}
+		slog.Info("deleted orphaned kubernetes job", "name", name, "job", job.Metadata.Labels[labelJobID])
+		deleted++
+	}
+	return deleted, nil
+}
+
+// RunReconcileLoop reconciles at startup and then every reconcileInterval
+// until ctx is done.
+func (e *Executor) RunReconcileLoop(ctx context.Context) {
+	ticker := time.NewTicker(reconcileInterval)
+	defer ticker.Stop()
+	for {
+		if _, err := e.Reconcile(ctx); err != nil && ctx.Err() == nil {
+			slog.Warn("reconcile kubernetes jobs", "err", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package kube
+
+import (
+	"context"
+	"testing"
+)
+
+func TestReconcileDeletesOnlyUntrackedJobs(t *testing.T) {
+	t.Parallel()
+
+	f := &fakeCluster{podStatus: phase("Running")}
+	ex := newTestExecutor(t, f)
+	ctx := context.Background()
+
+	f.created = []Job{
+		{Metadata: ObjectMeta{Name: "autopr-left-by-crash", Labels: map[string]string{labelJobID: "ap-job-old"}}},
+		{Metadata: ObjectMeta{Name: "autopr-in-flight", Labels: map[string]string{labelJobID: "ap-job-new"}}},
+	}
+	ex.track("autopr-in-flight")
+
+	deleted, err := ex.Reconcile(ctx)
+	if err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+	if deleted != 1 {
+		t.Fatalf("expected one orphan deleted, got %d", deleted)
+	}
+	if _, names := f.snapshot(); len(names) != 1 || names[0] != "autopr-left-by-crash" {
+		t.Fatalf("expected only the orphan deleted, got %v", names)
+	}
+
+	// A second pass finds nothing left to clean up.
+	if deleted, err := ex.Reconcile(ctx); err != nil || deleted != 0 {
+		t.Fatalf("expected nothing to reconcile, got %d, %v", deleted, err)
+	}
+}
@@ -0,0 +1,109 @@
+package kube
+
+// The subset of the batch/v1 Job and core/v1 Pod schemas that the executor
+// writes or reads. Field names follow the Kubernetes API.
+
+type ObjectMeta struct {
+	Name   string            `json:"name,omitempty"`
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+type Job struct {
+	APIVersion string     `json:"apiVersion"`
+	Kind       string     `json:"kind"`
+	Metadata   ObjectMeta `json:"metadata"`
+	Spec       JobSpec    `json:"spec"`
+}
+
+type JobSpec struct {
+	BackoffLimit            int         `json:"backoffLimit"`
+	TTLSecondsAfterFinished int         `json:"ttlSecondsAfterFinished,omitempty"`
+	Template                PodTemplate `json:"template"`
+}
+
+type PodTemplate struct {
+	Metadata ObjectMeta `json:"metadata"`
+	Spec     PodSpec    `json:"spec"`
+}
+
+type PodSpec struct {
+	RestartPolicy      string      `json:"restartPolicy"`
+	ServiceAccountName string      `json:"serviceAccountName,omitempty"`
+	Containers         []Container `json:"containers"`
+	Volumes            []Volume    `json:"volumes,omitempty"`
+}
+
+type Container struct {
+	Name         string          `json:"name"`
+	Image        string          `json:"image"`
+	Command      []string        `json:"command"`
+	WorkingDir   string          `json:"workingDir,omitempty"`
+	Env          []EnvVar        `json:"env,omitempty"`
+	EnvFrom      []EnvFromSource `json:"envFrom,omitempty"`
+	Resources    *Resources      `json:"resources,omitempty"`
+	VolumeMounts []VolumeMount   `json:"volumeMounts,omitempty"`
+}
+
+type EnvVar struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type EnvFromSource struct {
+	SecretRef *NameRef `json:"secretRef,omitempty"`
+}
+
+type NameRef struct {
+	Name string `json:"name"`
+}
+
+type Resources struct {
+	Requests map[string]string `json:"requests,omitempty"`
+	Limits   map[string]string `json:"limits,omitempty"`
+}
+
+type VolumeMount struct {
+	Name      string `json:"name"`
+	MountPath string `json:"mountPath"`
+}
+
+type Volume struct {
+	Name                  string    `json:"name"`
+	PersistentVolumeClaim *ClaimRef `json:"persistentVolumeClaim,omitempty"`
+}
+
+type ClaimRef struct {
+	ClaimName string `json:"claimName"`
+}
+
+type Pod struct {
+	Metadata ObjectMeta `json:"metadata"`
+	Status   PodStatus  `json:"status"`
+}
+
+type PodStatus struct {
+	Phase             string            `json:"phase"` // Pending, Running, Succeeded, Failed, Unknown
+	Reason            string            `json:"reason,omitempty"`
+	Message           string            `json:"message,omitempty"`
+	ContainerStatuses []ContainerStatus `json:"containerStatuses,omitempty"`
+}
+
+type ContainerStatus struct {
+	State ContainerState `json:"state"`
+}
+
+type ContainerState struct {
+	Waiting    *StateReason      `json:"waiting,omitempty"`
+	Terminated *TerminatedReason `json:"terminated,omitempty"`
+}
+
+type StateReason struct {
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+type TerminatedReason struct {
+	ExitCode int    `json:"exitCode"`
+	Reason   string `json:"reason,omitempty"`
+	Message  string `json:"message,omitempty"`
+}
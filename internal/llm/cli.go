@@ -11,6 +11,8 @@ import (
 	"path/filepath"
 	"strings"
 	"time"
+
+	"autopr/internal/executor"
 )
 
 // CLIProvider invokes an LLM via its CLI tool (claude or codex).
@@ -25,6 +27,10 @@ func NewCLIProvider(name string) *CLIProvider {
 func (p *CLIProvider) Name() string { return p.name }
 
 func (p *CLIProvider) Run(ctx context.Context, workDir, prompt, jsonlPath string) (Response, error) {
+	return p.RunStreaming(ctx, workDir, prompt, jsonlPath, nil)
+}
+
+func (p *CLIProvider) RunStreaming(ctx context.Context, workDir, prompt, jsonlPath string, onProgress func(Response)) (Response, error) {
 	start := time.Now()
 
 	// Use the externally-provided JSONL path so it's known at session creation
@@ -39,23 +45,26 @@ func (p *CLIProvider) Run(ctx context.Context, workDir, prompt, jsonlPath string
 		_ = os.MkdirAll(filepath.Dir(jsonlFile), 0o755)
 	}
 
-	args := p.buildArgs(prompt, jsonlFile)
+	args := p.buildArgs(prompt, jsonlFile, imagesFrom(ctx))
 
 	slog.Debug("llm exec", "provider", p.name, "workdir", workDir, "args_count", len(args))
 
-	cmd := exec.CommandContext(ctx, p.name, args...)
-	cmd.Dir = workDir
-
+	// Local processes discard stderr — LLM tools emit noisy internal warnings
+	// (e.g. codex rollout state errors); non-JSON lines are skipped below.
+	cmd := executor.Command(ctx, executor.Spec{
+		Dir:  workDir,
+		Env:  envFrom(ctx),
+		Argv: append([]string{p.name}, args...),
+	})
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
 		return Response{}, fmt.Errorf("stdout pipe: %w", err)
 	}
-	// Discard stderr — LLM tools emit noisy internal warnings (e.g. codex rollout state errors).
-	cmd.Stderr = nil
 
 	if err := cmd.Start(); err != nil {
 		return Response{}, fmt.Errorf("start %s: %w", p.name, err)
 	}
+	reportStarted(ctx, executor.PID(cmd))
 
 	// Read streaming JSONL output and capture the final text.
 	var resp Response
@@ -94,6 +103,7 @@ func (p *CLIProvider) Run(ctx context.Context, workDir, prompt, jsonlPath string
 		if err := json.Unmarshal([]byte(line), &msg); err != nil {
 			continue
 		}
+		prevText, prevIn, prevOut := lastText, totalIn, totalOut
 
 		switch {
 		// Claude format: assistant messages with content blocks.
@@ -125,6 +135,16 @@ func (p *CLIProvider) Run(ctx context.Context, workDir, prompt, jsonlPath string
 			totalIn += msg.Usage.InputTokens
 			totalOut += msg.Usage.OutputTokens
 		}
+
+		if onProgress != nil && (lastText != prevText || totalIn != prevIn || totalOut != prevOut) {
+			onProgress(Response{
+				Text:         lastText,
+				InputTokens:  totalIn,
+				OutputTokens: totalOut,
+				DurationMS:   int(time.Since(start).Milliseconds()),
+				JSONLPath:    jsonlFile,
+			})
+		}
 	}
 
 	if err := cmd.Wait(); err != nil {
@@ -142,7 +162,7 @@ func (p *CLIProvider) Run(ctx context.Context, workDir, prompt, jsonlPath string
 	return resp, nil
 }
 
-func (p *CLIProvider) buildArgs(prompt, jsonlFile string) []string {
+func (p *CLIProvider) buildArgs(prompt, jsonlFile string, images []string) []string {
 	switch p.name {
 	case "claude":
 		return []string{
@@ -153,12 +173,15 @@ func (p *CLIProvider) buildArgs(prompt, jsonlFile string) []string {
 			"--prompt", prompt,
 		}
 	case "codex":
-		return []string{
+		args := []string{
 			"exec",
 			"--full-auto",
 			"--json",
-			prompt,
 		}
+		for _, img := range images {
+			args = append(args, "--image", img)
+		}
+		return append(args, prompt)
 	default:
 		return []string{prompt}
 	}
@@ -13,6 +13,56 @@ type Provider interface {
 	Run(ctx context.Context, workDir, prompt, jsonlPath string) (Response, error)
 }
 
+// StreamingProvider is a Provider that reports progress while the LLM runs.
+type StreamingProvider interface {
+	Provider
+
+	// RunStreaming is Run, calling onProgress with the response so far each
+	// time the latest text or token counts change. onProgress may be nil.
+	RunStreaming(ctx context.Context, workDir, prompt, jsonlPath string, onProgress func(Response)) (Response, error)
+}
+
+type envKey struct{}
+
+// WithEnv returns a context under which providers add env (NAME=value pairs)
+// to the environment of the CLI they run.
+func WithEnv(ctx context.Context, env []string) context.Context {
+	return context.WithValue(ctx, envKey{}, env)
+}
+
+func envFrom(ctx context.Context) []string {
+	env, _ := ctx.Value(envKey{}).([]string)
+	return env
+}
+
+type startedKey struct{}
+
+// WithStarted returns a context under which providers call started with the
+// daemon-host PID of the CLI once it starts (see executor.PID).
+func WithStarted(ctx context.Context, started func(pid int)) context.Context {
+	return context.WithValue(ctx, startedKey{}, started)
+}
+
+func reportStarted(ctx context.Context, pid int) {
+	if started, ok := ctx.Value(startedKey{}).(func(int)); ok && pid > 0 {
+		started(pid)
+	}
+}
+
+type imagesKey struct{}
+
+// WithImages returns a context under which providers that take image files
+// on the command line (codex) attach paths to the prompt. Other providers
+// ignore them; the prompt itself should point at the files.
+func WithImages(ctx context.Context, paths []string) context.Context {
+	return context.WithValue(ctx, imagesKey{}, paths)
+}
+
+func imagesFrom(ctx context.Context) []string {
+	paths, _ := ctx.Value(imagesKey{}).([]string)
+	return paths
+}
+
 // Response captures the output of an LLM invocation.
 type Response struct {
 	Text         string
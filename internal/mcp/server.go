@@ -0,0 +1,182 @@
+// Package mcp serves AutoPR's jobs and issues to other local agents over the
+// Model Context Protocol: JSON-RPC 2.0 messages, one per line, on stdio.
+//
+// Only the tools capability is implemented. The tools list and inspect jobs,
+// show a job's diff, list synced issues, and queue a job for an issue; see
+// tools.go.
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"sync"
+
+	"autopr/internal/config"
+	"autopr/internal/db"
+)
+
+// protocolVersions are the MCP revisions the server speaks, newest first.
+var protocolVersions = []string{"2025-06-18", "2025-03-26", "2024-11-05"}
+
+// JSON-RPC error codes.
+const (
+	codeParseError     = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+)
+
+// Server answers MCP requests from a single client.
+type Server struct {
+	cfg      *config.Config
+	store    *db.Store
+	version  string
+	readOnly bool
+
+	mu  sync.Mutex // serializes writes
+	out io.Writer
+}
+
+// NewServer returns a server for cfg and store. version is reported to the
+// client as the server version. A read-only server leaves out the tools that
+// change state.
+func NewServer(cfg *config.Config, store *db.Store, version string, readOnly bool) *Server {
+	return &Server{cfg: cfg, store: store, version: version, readOnly: readOnly}
+}
+
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *rpcError) Error() string { return e.Message }
+
+// Serve reads requests from in and writes responses to out until in is
+// exhausted or ctx is done. Requests are handled in order.
+func (s *Server) Serve(ctx context.Context, in io.Reader, out io.Writer) error {
+	s.out = out
+	r := bufio.NewReader(in)
+	for {
+		if ctx.Err() != nil {
+			return nil
+		}
+		line, err := r.ReadBytes('\n')
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			s.handleLine(ctx, line)
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("read mcp request: %w", err)
+		}
+	}
+}
+
+func (s *Server) handleLine(ctx context.Context, line []byte) {
+	var req request
+	if err := json.Unmarshal(line, &req); err != nil {
+		s.write(response{ID: json.RawMessage("null"), Error: &rpcError{Code: codeParseError, Message: "parse error: " + err.Error()}})
+		return
+	}
+	if len(req.ID) == 0 {
+		// Notifications, such as notifications/initialized, need no answer.
+		slog.Debug("mcp notification", "method", req.Method)
+		return
+	}
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		s.write(response{ID: req.ID, Error: &rpcError{Code: codeInvalidRequest, Message: "invalid request"}})
+		return
+	}
+	result, err := s.handle(ctx, req)
+	if err != nil {
+		var rerr *rpcError
+		if !errors.As(err, &rerr) {
+			rerr = &rpcError{Code: codeInvalidParams, Message: err.Error()}
+		}
+		s.write(response{ID: req.ID, Error: rerr})
+		return
+	}
+	s.write(response{ID: req.ID, Result: result})
+}
+
+func (s *Server) handle(ctx context.Context, req request) (any, error) {
+	switch req.Method {
+	case "initialize":
+		var params struct {
+			ProtocolVersion string `json:"protocolVersion"`
+		}
+		if err := unmarshalParams(req.Params, &params); err != nil {
+			return nil, err
+		}
+		version := protocolVersions[0]
+		if slices.Contains(protocolVersions, params.ProtocolVersion) {
+			version = params.ProtocolVersion
+		}
+		return map[string]any{
+			"protocolVersion": version,
+			"capabilities":    map[string]any{"tools": map[string]any{}},
+			"serverInfo":      map[string]any{"name": "autopr", "version": s.version},
+			"instructions":    "AutoPR turns issues into pull requests. Use list_jobs and get_job to follow jobs, get_diff to review a job's changes, and list_issues with enqueue_job to queue work.",
+		}, nil
+	case "ping":
+		return map[string]any{}, nil
+	case "tools/list":
+		return map[string]any{"tools": s.tools()}, nil
+	case "tools/call":
+		var params struct {
+			Name      string          `json:"name"`
+			Arguments json.RawMessage `json:"arguments"`
+		}
+		if err := unmarshalParams(req.Params, &params); err != nil {
+			return nil, err
+		}
+		return s.callTool(ctx, params.Name, params.Arguments)
+	}
+	return nil, &rpcError{Code: codeMethodNotFound, Message: "method not found: " + req.Method}
+}
+
+func unmarshalParams(raw json.RawMessage, v any) error {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		return &rpcError{Code: codeInvalidParams, Message: "invalid params: " + err.Error()}
+	}
+	return nil
+}
+
+func (s *Server) write(resp response) {
+	resp.JSONRPC = "2.0"
+	data, err := json.Marshal(resp)
+	if err != nil {
+		slog.Warn("encode mcp response", "err", err)
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.out.Write(append(data, '\n')); err != nil {
+		slog.Warn("write mcp response", "err", err)
+	}
+}
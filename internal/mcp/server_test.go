@@ -0,0 +1,207 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"autopr/internal/config"
+	"autopr/internal/db"
+)
+
+func newTestServer(t *testing.T, readOnly bool) (*Server, *db.Store) {
+	t.Helper()
+	store, err := db.Open(filepath.Join(t.TempDir(), "autopr.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	cfg := &config.Config{Projects: []config.ProjectConfig{{Name: "api", BaseBranch: "main"}}}
+	cfg.Daemon.MaxIterations = 3
+	return NewServer(cfg, store, "test", readOnly), store
+}
+
+// exchange sends the request lines to the server and returns its responses
+// by ID.
+func exchange(t *testing.T, s *Server, lines ...string) map[string]response {
+	t.Helper()
+	var out strings.Builder
+	if err := s.Serve(context.Background(), strings.NewReader(strings.Join(lines, "\n")+"\n"), &out); err != nil {
+		t.Fatalf("serve: %v", err)
+	}
+	responses := map[string]response{}
+	sc := bufio.NewScanner(strings.NewReader(out.String()))
+	for sc.Scan() {
+		var resp struct {
+			response
+			Result json.RawMessage `json:"result"`
+		}
+		if err := json.Unmarshal(sc.Bytes(), &resp); err != nil {
+			t.Fatalf("decode response %q: %v", sc.Text(), err)
+		}
+		resp.response.Result = resp.Result
+		responses[string(resp.ID)] = resp.response
+	}
+	return responses
+}
+
+func toolCall(id int, name, args string) string {
+	return `{"jsonrpc":"2.0","id":` + strconv.Itoa(id) + `,"method":"tools/call","params":{"name":"` + name + `","arguments":` + args + `}}`
+}
+
+func decodeToolResult(t *testing.T, resp response, v any) toolResult {
+	t.Helper()
+	if resp.Error != nil {
+		t.Fatalf("unexpected error %+v", resp.Error)
+	}
+	var res struct {
+		toolResult
+		StructuredContent json.RawMessage `json:"structuredContent"`
+	}
+	if err := json.Unmarshal(resp.Result.(json.RawMessage), &res); err != nil {
+		t.Fatalf("decode tool result: %v", err)
+	}
+	if v != nil && !res.IsError {
+		if err := json.Unmarshal(res.StructuredContent, v); err != nil {
+			t.Fatalf("decode structured content: %v", err)
+		}
+	}
+	return res.toolResult
+}
+
+func TestServeInitializeAndListTools(t *testing.T) {
+	t.Parallel()
+	s, _ := newTestServer(t, true)
+
+	responses := exchange(t, s,
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"test","version":"1"}}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/list"}`,
+		`{"jsonrpc":"2.0","id":3,"method":"resources/list"}`,
+		`not json`,
+	)
+	if len(responses) != 4 {
+		t.Fatalf("expected 4 responses (none for the notification), got %d: %+v", len(responses), responses)
+	}
+
+	var init struct {
+		ProtocolVersion string `json:"protocolVersion"`
+		ServerInfo      struct {
+			Name string `json:"name"`
+		} `json:"serverInfo"`
+	}
+	if err := json.Unmarshal(responses["1"].Result.(json.RawMessage), &init); err != nil {
+		t.Fatalf("decode initialize: %v", err)
+	}
+	if init.ProtocolVersion != "2025-03-26" || init.ServerInfo.Name != "autopr" {
+		t.Fatalf("unexpected initialize result %+v", init)
+	}
+
+	var list struct {
+		Tools []struct {
+			Name string `json:"name"`
+		} `json:"tools"`
+	}
+	if err := json.Unmarshal(responses["2"].Result.(json.RawMessage), &list); err != nil {
+		t.Fatalf("decode tools/list: %v", err)
+	}
+	var names []string
+	for _, tool := range list.Tools {
+		names = append(names, tool.Name)
+	}
+	if got := strings.Join(names, ","); got != "list_jobs,get_job,get_diff,list_issues" {
+		t.Fatalf("read-only tools = %s", got)
+	}
+
+	if e := responses["3"].Error; e == nil || e.Code != codeMethodNotFound {
+		t.Fatalf("expected method not found, got %+v", responses["3"])
+	}
+	if e := responses["null"].Error; e == nil || e.Code != codeParseError {
+		t.Fatalf("expected parse error, got %+v", responses["null"])
+	}
+}
+
+func TestServeEnqueueAndInspectJob(t *testing.T) {
+	t.Parallel()
+	s, store := newTestServer(t, false)
+	ctx := context.Background()
+	eligible := true
+	issueID, err := store.UpsertIssue(ctx, db.IssueUpsert{
+		ProjectName:   "api",
+		Source:        "github",
+		SourceIssueID: "42",
+		Title:         "Fix login redirect",
+		Body:          "Users land on /404 after login.",
+		URL:           "https://github.com/o/r/issues/42",
+		State:         "open",
+		Eligible:      &eligible,
+	})
+	if err != nil {
+		t.Fatalf("upsert issue: %v", err)
+	}
+
+	responses := exchange(t, s,
+		toolCall(1, "list_issues", `{"eligible_only":true}`),
+		toolCall(2, "enqueue_job", `{"issue_id":"`+issueID+`"}`),
+		toolCall(3, "enqueue_job", `{"issue_id":"`+issueID+`"}`),
+		toolCall(4, "list_jobs", `{"state":"queued"}`),
+	)
+
+	var issues struct {
+		Issues []issueView `json:"issues"`
+	}
+	decodeToolResult(t, responses["1"], &issues)
+	if len(issues.Issues) != 1 || issues.Issues[0].ID != issueID || issues.Issues[0].HasJob {
+		t.Fatalf("unexpected issues %+v", issues.Issues)
+	}
+
+	var queued struct {
+		JobID string `json:"job_id"`
+	}
+	decodeToolResult(t, responses["2"], &queued)
+	if queued.JobID == "" {
+		t.Fatal("enqueue_job returned no job ID")
+	}
+	if res := decodeToolResult(t, responses["3"], nil); !res.IsError || !strings.Contains(res.Content[0].Text, "already has a job") {
+		t.Fatalf("expected duplicate enqueue to fail, got %+v", res)
+	}
+
+	var jobs struct {
+		Jobs  []jobView `json:"jobs"`
+		Total int       `json:"total"`
+	}
+	decodeToolResult(t, responses["4"], &jobs)
+	if jobs.Total != 1 || jobs.Jobs[0].ID != queued.JobID || jobs.Jobs[0].Title != "Fix login redirect" {
+		t.Fatalf("unexpected jobs %+v", jobs)
+	}
+
+	responses = exchange(t, s,
+		toolCall(1, "get_job", `{"job_id":"`+db.ShortID(queued.JobID)+`"}`),
+		toolCall(2, "get_diff", `{"job_id":"`+queued.JobID+`"}`),
+		toolCall(3, "get_job", `{}`),
+	)
+	var job jobView
+	decodeToolResult(t, responses["1"], &job)
+	if job.ID != queued.JobID || job.State != "queued" || job.IssueBody != "Users land on /404 after login." {
+		t.Fatalf("unexpected job %+v", job)
+	}
+	if res := decodeToolResult(t, responses["2"], nil); !res.IsError || !strings.Contains(res.Content[0].Text, "not started") {
+		t.Fatalf("expected get_diff of a queued job to fail, got %+v", res)
+	}
+	if e := responses["3"].Error; e == nil || e.Code != codeInvalidParams {
+		t.Fatalf("expected invalid params for a missing job_id, got %+v", responses["3"])
+	}
+}
+
+func TestReadOnlyServerRejectsEnqueue(t *testing.T) {
+	t.Parallel()
+	s, _ := newTestServer(t, true)
+	responses := exchange(t, s, toolCall(1, "enqueue_job", `{"issue_id":"x"}`))
+	if e := responses["1"].Error; e == nil || !strings.Contains(e.Message, "unknown tool") {
+		t.Fatalf("expected unknown tool, got %+v", responses["1"])
+	}
+}
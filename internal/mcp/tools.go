@@ -0,0 +1,420 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"autopr/internal/db"
+	"autopr/internal/git"
+	"autopr/internal/pipeline"
+)
+
+// maxDiffBytes caps the diff get_diff returns, so one large job can't flood
+// the client's context.
+const maxDiffBytes = 200_000
+
+const defaultListLimit = 20
+
+type tool struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	InputSchema map[string]any `json:"inputSchema"`
+	readOnly    bool
+	run         func(s *Server, ctx context.Context, args json.RawMessage) (any, error)
+}
+
+// toolResult is the result of tools/call. Tool failures are reported in the
+// result, with IsError set, so the calling model can see them.
+type toolResult struct {
+	Content           []toolContent `json:"content"`
+	StructuredContent any           `json:"structuredContent,omitempty"`
+	IsError           bool          `json:"isError,omitempty"`
+}
+
+type toolContent struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+func object(required []string, props map[string]any) map[string]any {
+	schema := map[string]any{"type": "object", "properties": props}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+func prop(typ, description string) map[string]any {
+	return map[string]any{"type": typ, "description": description}
+}
+
+var allTools = []tool{
+	{
+		Name:        "list_jobs",
+		Description: "List AutoPR jobs, most recently updated first, with their state, project, issue, and PR.",
+		InputSchema: object(nil, map[string]any{
+			"project": prop("string", "only jobs of this project"),
+			"state":   prop("string", "only jobs in this state, e.g. queued, implementing, ready, approved, failed, rejected; or \"active\""),
+			"limit":   prop("integer", fmt.Sprintf("maximum number of jobs (default %d)", defaultListLimit)),
+		}),
+		readOnly: true,
+		run:      (*Server).listJobs,
+	},
+	{
+		Name:        "get_job",
+		Description: "Show one AutoPR job in detail: state, iteration, issue, branch, PR, CI, risk, errors, and tags.",
+		InputSchema: object([]string{"job_id"}, map[string]any{
+			"job_id": prop("string", "job ID or unique prefix"),
+		}),
+		readOnly: true,
+		run:      (*Server).getJob,
+	},
+	{
+		Name:        "get_diff",
+		Description: "Show the git diff of a job's changes against its base branch.",
+		InputSchema: object([]string{"job_id"}, map[string]any{
+			"job_id": prop("string", "job ID or unique prefix"),
+			"stat":   prop("boolean", "return a diffstat summary instead of the full diff"),
+		}),
+		readOnly: true,
+		run:      (*Server).getDiff,
+	},
+	{
+		Name:        "list_issues",
+		Description: "List open synced issues, with whether they are eligible for AutoPR and whether they already have a job.",
+		InputSchema: object(nil, map[string]any{
+			"project":       prop("string", "only issues of this project"),
+			"eligible_only": prop("boolean", "only issues eligible for a job"),
+			"limit":         prop("integer", fmt.Sprintf("maximum number of issues (default %d)", defaultListLimit)),
+		}),
+		readOnly: true,
+		run:      (*Server).listIssues,
+	},
+	{
+		Name:        "enqueue_job",
+		Description: "Queue an AutoPR job for an open, eligible synced issue that has no job yet. The daemon picks it up.",
+		InputSchema: object([]string{"issue_id"}, map[string]any{
+			"issue_id": prop("string", "AutoPR issue ID from list_issues"),
+		}),
+		run: (*Server).enqueueJob,
+	},
+}
+
+func (s *Server) tools() []tool {
+	var tools []tool
+	for _, t := range allTools {
+		if t.readOnly || !s.readOnly {
+			tools = append(tools, t)
+		}
+	}
+	return tools
+}
+
+func (s *Server) callTool(ctx context.Context, name string, args json.RawMessage) (any, error) {
+	for _, t := range s.tools() {
+		if t.Name != name {
+			continue
+		}
+		v, err := t.run(s, ctx, args)
+		if err != nil {
+			var rerr *rpcError
+			if errors.As(err, &rerr) {
+				return nil, err
+			}
+			return toolResult{Content: []toolContent{{Type: "text", Text: err.Error()}}, IsError: true}, nil
+		}
+		text, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("encode %s result: %w", name, err)
+		}
+		return toolResult{Content: []toolContent{{Type: "text", Text: string(text)}}, StructuredContent: v}, nil
+	}
+	return nil, &rpcError{Code: codeInvalidParams, Message: "unknown tool: " + name}
+}
+
+// jobView is a job as the tools report it.
+type jobView struct {
+	ID            string   `json:"id"`
+	Project       string   `json:"project"`
+	State         string   `json:"state"`
+	Title         string   `json:"title"`
+	Iteration     int      `json:"iteration"`
+	MaxIterations int      `json:"max_iterations"`
+	IssueID       string   `json:"issue_id"`
+	IssueSource   string   `json:"issue_source,omitempty"`
+	IssueNumber   string   `json:"issue_number,omitempty"`
+	IssueURL      string   `json:"issue_url,omitempty"`
+	PRURL         string   `json:"pr_url,omitempty"`
+	Tags          []string `json:"tags,omitempty"`
+	CreatedAt     string   `json:"created_at"`
+	UpdatedAt     string   `json:"updated_at"`
+
+	// Set by get_job only.
+	Branch       string `json:"branch,omitempty"`
+	CommitSHA    string `json:"commit_sha,omitempty"`
+	Worktree     string `json:"worktree,omitempty"`
+	CIStatus     string `json:"ci_status,omitempty"`
+	RiskScore    int    `json:"risk_score,omitempty"`
+	RiskLevel    string `json:"risk_level,omitempty"`
+	RiskSummary  string `json:"risk_summary,omitempty"`
+	Deadline     string `json:"deadline,omitempty"`
+	ErrorMessage string `json:"error_message,omitempty"`
+	RejectReason string `json:"reject_reason,omitempty"`
+	HumanNotes   string `json:"human_notes,omitempty"`
+	ParentJobID  string `json:"parent_job_id,omitempty"`
+	IssueBody    string `json:"issue_body,omitempty"`
+}
+
+func newJobView(j db.Job) jobView {
+	return jobView{
+		ID:            j.ID,
+		Project:       j.ProjectName,
+		State:         j.State,
+		Title:         j.Title(),
+		Iteration:     j.Iteration,
+		MaxIterations: j.MaxIterations,
+		IssueID:       j.AutoPRIssueID,
+		IssueSource:   j.IssueSource,
+		IssueNumber:   j.SourceIssueID,
+		IssueURL:      j.IssueURL,
+		PRURL:         j.PRURL,
+		Tags:          j.Tags,
+		CreatedAt:     j.CreatedAt,
+		UpdatedAt:     j.UpdatedAt,
+	}
+}
+
+func decodeArgs(raw json.RawMessage, v any) error {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		return &rpcError{Code: codeInvalidParams, Message: "invalid arguments: " + err.Error()}
+	}
+	return nil
+}
+
+func listLimit(limit int) (int, error) {
+	if limit < 0 {
+		return 0, fmt.Errorf("invalid limit %d; expected >= 0", limit)
+	}
+	if limit == 0 {
+		return defaultListLimit, nil
+	}
+	return limit, nil
+}
+
+func (s *Server) listJobs(ctx context.Context, raw json.RawMessage) (any, error) {
+	var args struct {
+		Project string `json:"project"`
+		State   string `json:"state"`
+		Limit   int    `json:"limit"`
+	}
+	if err := decodeArgs(raw, &args); err != nil {
+		return nil, err
+	}
+	limit, err := listLimit(args.Limit)
+	if err != nil {
+		return nil, err
+	}
+	state := args.State
+	if state == "" {
+		state = "all"
+	}
+	jobs, total, err := s.store.ListJobsPage(ctx, args.Project, state, "updated_at", false, 1, limit)
+	if err != nil {
+		return nil, err
+	}
+	views := make([]jobView, 0, len(jobs))
+	for _, j := range jobs {
+		views = append(views, newJobView(j))
+	}
+	return map[string]any{"jobs": views, "total": total}, nil
+}
+
+func (s *Server) resolveJob(ctx context.Context, raw json.RawMessage) (db.Job, error) {
+	var args struct {
+		JobID string `json:"job_id"`
+	}
+	if err := decodeArgs(raw, &args); err != nil {
+		return db.Job{}, err
+	}
+	if args.JobID == "" {
+		return db.Job{}, &rpcError{Code: codeInvalidParams, Message: "job_id is required"}
+	}
+	id, err := s.store.ResolveJobID(ctx, args.JobID)
+	if err != nil {
+		return db.Job{}, err
+	}
+	return s.store.GetJob(ctx, id)
+}
+
+func (s *Server) getJob(ctx context.Context, raw json.RawMessage) (any, error) {
+	job, err := s.resolveJob(ctx, raw)
+	if err != nil {
+		return nil, err
+	}
+	v := newJobView(job)
+	v.Branch = job.BranchName
+	v.CommitSHA = job.CommitSHA
+	v.Worktree = job.WorktreePath
+	v.CIStatus = job.CIStatusSummary
+	v.RiskScore = job.RiskScore
+	v.RiskLevel = job.RiskLevel()
+	v.RiskSummary = job.RiskSummary
+	v.Deadline = job.Deadline
+	v.ErrorMessage = job.ErrorMessage
+	v.RejectReason = job.RejectReason
+	v.HumanNotes = job.HumanNotes
+	v.ParentJobID = job.ParentJobID
+	if issue, err := s.store.GetIssueByAPID(ctx, job.AutoPRIssueID); err == nil {
+		v.IssueSource = issue.Source
+		v.IssueNumber = issue.SourceIssueID
+		v.IssueURL = issue.URL
+		v.IssueBody = issue.Body
+	}
+	return v, nil
+}
+
+func (s *Server) getDiff(ctx context.Context, raw json.RawMessage) (any, error) {
+	var args struct {
+		Stat bool `json:"stat"`
+	}
+	if err := decodeArgs(raw, &args); err != nil {
+		return nil, err
+	}
+	job, err := s.resolveJob(ctx, raw)
+	if err != nil {
+		return nil, err
+	}
+	if job.State == "queued" {
+		return nil, fmt.Errorf("job %s has not started yet", db.ShortID(job.ID))
+	}
+	if job.WorktreePath == "" {
+		return nil, fmt.Errorf("job %s has no worktree (it may have been cleaned up)", db.ShortID(job.ID))
+	}
+	if _, err := os.Stat(job.WorktreePath); err != nil {
+		return nil, fmt.Errorf("worktree of job %s not found: %w", db.ShortID(job.ID), err)
+	}
+	base := "main"
+	if p, ok := s.cfg.ProjectByName(job.ProjectName); ok {
+		base = pipeline.TargetBranch(job, p)
+	} else if job.BackportBranch != "" {
+		base = job.BackportBranch
+	}
+
+	result := map[string]any{"job_id": job.ID, "base_branch": base}
+	if args.Stat {
+		stat, err := git.DiffStatAgainstBase(ctx, job.WorktreePath, base)
+		if err != nil {
+			return nil, err
+		}
+		result["stat"] = stat
+		return result, nil
+	}
+	diff, err := git.DiffAgainstBase(ctx, job.WorktreePath, base)
+	if err != nil {
+		return nil, err
+	}
+	if len(diff) > maxDiffBytes {
+		diff = diff[:maxDiffBytes]
+		result["truncated"] = true
+	}
+	result["diff"] = diff
+	return result, nil
+}
+
+// issueView is a synced issue as list_issues reports it.
+type issueView struct {
+	ID         string   `json:"id"`
+	Project    string   `json:"project"`
+	Source     string   `json:"source"`
+	Number     string   `json:"number"`
+	Title      string   `json:"title"`
+	URL        string   `json:"url"`
+	Labels     []string `json:"labels,omitempty"`
+	Eligible   bool     `json:"eligible"`
+	SkipReason string   `json:"skip_reason,omitempty"`
+	HasJob     bool     `json:"has_job"`
+}
+
+func (s *Server) listIssues(ctx context.Context, raw json.RawMessage) (any, error) {
+	var args struct {
+		Project      string `json:"project"`
+		EligibleOnly bool   `json:"eligible_only"`
+		Limit        int    `json:"limit"`
+	}
+	if err := decodeArgs(raw, &args); err != nil {
+		return nil, err
+	}
+	limit, err := listLimit(args.Limit)
+	if err != nil {
+		return nil, err
+	}
+	var eligible *bool
+	if args.EligibleOnly {
+		eligible = &args.EligibleOnly
+	}
+	issues, err := s.store.ListIssues(ctx, args.Project, eligible)
+	if err != nil {
+		return nil, err
+	}
+	views := []issueView{}
+	for _, issue := range issues {
+		if issue.State != "open" {
+			continue
+		}
+		if len(views) >= limit {
+			break
+		}
+		hasJob, err := s.store.HasAnyNonMergedJobForIssue(ctx, issue.AutoPRIssueID)
+		if err != nil {
+			return nil, err
+		}
+		views = append(views, issueView{
+			ID:         issue.AutoPRIssueID,
+			Project:    issue.ProjectName,
+			Source:     issue.Source,
+			Number:     issue.SourceIssueID,
+			Title:      issue.Title,
+			URL:        issue.URL,
+			Labels:     issue.Labels(),
+			Eligible:   issue.Eligible,
+			SkipReason: issue.SkipReason,
+			HasJob:     hasJob,
+		})
+	}
+	return map[string]any{"issues": views}, nil
+}
+
+// enqueueJob queues a job for an issue under the same rules as ap enqueue
+// (see pipeline.EnqueueIssue).
+func (s *Server) enqueueJob(ctx context.Context, raw json.RawMessage) (any, error) {
+	var args struct {
+		IssueID string `json:"issue_id"`
+	}
+	if err := decodeArgs(raw, &args); err != nil {
+		return nil, err
+	}
+	if args.IssueID == "" {
+		return nil, &rpcError{Code: codeInvalidParams, Message: "issue_id is required"}
+	}
+	issue, err := s.store.GetIssueByAPID(ctx, args.IssueID)
+	if err != nil {
+		return nil, fmt.Errorf("issue %s: %w", args.IssueID, err)
+	}
+	jobID, err := pipeline.EnqueueIssue(ctx, s.cfg, s.store, issue)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{
+		"job_id":  jobID,
+		"project": issue.ProjectName,
+		"issue":   strings.TrimPrefix(issue.SourceIssueID, "#"),
+		"title":   issue.Title,
+	}, nil
+}
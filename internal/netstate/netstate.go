@@ -0,0 +1,164 @@
+// Package netstate detects loss of network connectivity.
+//
+// The daemon uses it to tell "the network is down" apart from real failures:
+// operations that fail while a project's forge is unreachable are deferred to
+// the outbox and retried once connectivity returns, instead of failing jobs.
+package netstate
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"autopr/internal/config"
+	"autopr/internal/git"
+)
+
+const (
+	// cacheTTL bounds how often a host is probed.
+	cacheTTL = 15 * time.Second
+	// probeTimeout bounds a single probe.
+	probeTimeout = 5 * time.Second
+)
+
+type probeResult struct {
+	reachable bool
+	at        time.Time
+}
+
+var (
+	probesMu sync.Mutex
+	probes   = map[string]probeResult{}
+)
+
+// Reachable reports whether the host of rawURL answers HTTP requests. Any
+// response counts, including errors such as 401 or 404; only transport
+// failures (DNS, connect, TLS, timeouts) mean unreachable. Probes go through
+// http.DefaultTransport so the configured proxy is honoured, and results are
+// cached per host for a few seconds.
+func Reachable(ctx context.Context, rawURL string) bool {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || u.Host == "" {
+		return true
+	}
+	key := u.Scheme + "://" + u.Host
+
+	probesMu.Lock()
+	cached, ok := probes[key]
+	probesMu.Unlock()
+	if ok && time.Since(cached.at) < cacheTTL {
+		return cached.reachable
+	}
+
+	reachable := probe(ctx, key)
+	probesMu.Lock()
+	probes[key] = probeResult{reachable: reachable, at: time.Now()}
+	probesMu.Unlock()
+	return reachable
+}
+
+func probe(ctx context.Context, target string) bool {
+	ctx, cancel := context.WithTimeout(ctx, probeTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, target, nil)
+	if err != nil {
+		return true
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return false
+	}
+	resp.Body.Close()
+	return true
+}
+
+// ProjectURL returns the forge URL probed for proj: the API root for GitHub
+// and the instance root for GitLab and Gitea. It is empty when the project
+// has no forge configured.
+func ProjectURL(proj *config.ProjectConfig) string {
+	switch {
+	case proj == nil:
+		return ""
+	case proj.GitHub != nil:
+		return git.NormalizeGitHubAPIBaseURL(proj.GitHub.BaseURL)
+	case proj.GitLab != nil:
+		return git.NormalizeGitLabBaseURL(proj.GitLab.BaseURL)
+	case proj.Gitea != nil:
+		return git.NormalizeGiteaBaseURL(proj.Gitea.BaseURL)
+	}
+	return ""
+}
+
+// ProjectReachable reports whether proj's forge is reachable. Projects without
+// a forge are always considered reachable.
+func ProjectReachable(ctx context.Context, proj *config.ProjectConfig) bool {
+	target := ProjectURL(proj)
+	if target == "" {
+		return true
+	}
+	return Reachable(ctx, target)
+}
+
+// networkErrorMarkers are substrings of git, curl, ssh, and LLM CLI errors
+// that mean the remote could not be reached. They cover subprocess failures,
+// whose errors only carry the command output.
+var networkErrorMarkers = []string{
+	"could not resolve host",
+	"could not resolve hostname",
+	"temporary failure in name resolution",
+	"name or service not known",
+	"failed to connect to",
+	"network is unreachable",
+	"no route to host",
+	"connection refused",
+	"connection timed out",
+	"connection reset by peer",
+	"i/o timeout",
+	// Node-based LLM CLIs
+	"enotfound",
+	"econnrefused",
+	"econnreset",
+	"etimedout",
+	"fetch failed",
+}
+
+// IsNetworkError reports whether err looks like a connectivity failure rather
+// than an error returned by the remote service.
+func IsNetworkError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var dnsErr *net.DNSError
+	var opErr *net.OpError
+	if errors.As(err, &dnsErr) || errors.As(err, &opErr) {
+		return true
+	}
+	for _, errno := range []syscall.Errno{syscall.ECONNREFUSED, syscall.ECONNRESET, syscall.ENETUNREACH, syscall.EHOSTUNREACH, syscall.ETIMEDOUT} {
+		if errors.Is(err, errno) {
+			return true
+		}
+	}
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) && urlErr.Timeout() {
+		return true
+	}
+	return IsNetworkErrorText(err.Error())
+}
+
+// IsNetworkErrorText is IsNetworkError for error messages that have already
+// been flattened to strings, such as a job's recorded failure.
+func IsNetworkErrorText(msg string) bool {
+	msg = strings.ToLower(msg)
+	for _, marker := range networkErrorMarkers {
+		if strings.Contains(msg, marker) {
+			return true
+		}
+	}
+	return false
+}
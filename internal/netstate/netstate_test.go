@@ -0,0 +1,56 @@
+package netstate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"syscall"
+	"testing"
+)
+
+func TestReachable(t *testing.T) {
+	t.Parallel()
+
+	up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer up.Close()
+	if !Reachable(context.Background(), up.URL+"/api/v3") {
+		t.Fatal("expected server answering 401 to be reachable")
+	}
+
+	down := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	downURL := down.URL
+	down.Close()
+	if Reachable(context.Background(), downURL) {
+		t.Fatal("expected closed server to be unreachable")
+	}
+
+	if !Reachable(context.Background(), "") {
+		t.Fatal("expected empty URL to count as reachable")
+	}
+}
+
+func TestIsNetworkError(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"dns", fmt.Errorf("get: %w", &net.DNSError{Err: "no such host", Name: "api.github.com"}), true},
+		{"refused", fmt.Errorf("dial: %w", syscall.ECONNREFUSED), true},
+		{"git output", errors.New("git push: fatal: unable to access 'https://github.com/o/r.git/': Could not resolve host: github.com"), true},
+		{"api error", errors.New("create PR: HTTP 422: validation failed"), false},
+	}
+	for _, tc := range cases {
+		if got := IsNetworkError(tc.err); got != tc.want {
+			t.Errorf("%s: got %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
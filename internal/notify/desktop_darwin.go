@@ -21,9 +21,14 @@ func (s *desktopSender) Name() string {
 
 func (s *desktopSender) Send(ctx context.Context, payload Payload) error {
 	title := escapeAppleScriptString("AutoPR: " + EventLabel(payload.Event))
-	message := escapeAppleScriptString(fmt.Sprintf("%s - %s", payload.Project, payload.IssueTitle))
+	message := escapeAppleScriptString(fmt.Sprintf("%s - %s", payload.Project, payload.Title()))
 	if payload.PRURL != "" {
-		message = escapeAppleScriptString(fmt.Sprintf("%s - %s (%s)", payload.Project, payload.IssueTitle, payload.PRURL))
+		message = escapeAppleScriptString(fmt.Sprintf("%s - %s (%s)", payload.Project, payload.Title(), payload.PRURL))
+	}
+	if payload.JobID == "" {
+		// Daemon events: the first line of the message is the summary.
+		first, _, _ := strings.Cut(payload.Message, "\n")
+		message = escapeAppleScriptString(first)
 	}
 	script := fmt.Sprintf(`display notification "%s" with title "%s"`, message, title)
 	if err := exec.CommandContext(ctx, "osascript", "-e", script).Run(); err != nil {
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"autopr/internal/db"
+	"autopr/internal/netstate"
 )
 
 const (
@@ -27,6 +28,15 @@ type Dispatcher struct {
 	cleanupEvery time.Duration
 	retention    time.Duration
 	maxAttempts  int
+	// reachable reports whether any sender can currently deliver. While it
+	// reports false the dispatcher holds events instead of spending attempts.
+	reachable func(ctx context.Context) bool
+	offline   bool
+}
+
+// remoteSender is implemented by senders that deliver over the network.
+type remoteSender interface {
+	Endpoint() string
 }
 
 func NewDispatcher(store *db.Store, senders []Sender, triggers []string) *Dispatcher {
@@ -39,7 +49,25 @@ func NewDispatcher(store *db.Store, senders []Sender, triggers []string) *Dispat
 		cleanupEvery: defaultCleanupEvery,
 		retention:    defaultRetention,
 		maxAttempts:  defaultMaxSendAttempt,
+		reachable: func(ctx context.Context) bool {
+			return sendersReachable(ctx, senders)
+		},
+	}
+}
+
+// sendersReachable reports whether at least one sender can deliver: any local
+// sender (desktop), or a remote sender whose endpoint answers.
+func sendersReachable(ctx context.Context, senders []Sender) bool {
+	if len(senders) == 0 {
+		return true
+	}
+	for _, sender := range senders {
+		remote, ok := sender.(remoteSender)
+		if !ok || netstate.Reachable(ctx, remote.Endpoint()) {
+			return true
+		}
 	}
+	return false
 }
 
 func (d *Dispatcher) Run(ctx context.Context) {
@@ -79,6 +107,13 @@ func (d *Dispatcher) Run(ctx context.Context) {
 }
 
 func (d *Dispatcher) runOnce(ctx context.Context) (bool, error) {
+	if d.offline {
+		if !d.reachable(ctx) {
+			return false, nil
+		}
+		d.offline = false
+		slog.Info("notify: network restored, resuming notifications")
+	}
 	event, ok, err := d.store.ClaimNextNotificationEvent(ctx, d.maxAttempts)
 	if err != nil {
 		return false, err
@@ -115,30 +150,91 @@ func (d *Dispatcher) processEvent(ctx context.Context, event db.NotificationEven
 		return fmt.Errorf("build payload for event %d: %w", event.ID, err)
 	}
 
-	results := SendAll(ctx, d.senders, payload, d.sendTimeout)
-	if successCount(results) > 0 {
-		if err := d.store.MarkNotificationEventSent(ctx, event.ID); err != nil {
-			return fmt.Errorf("mark event %d sent: %w", event.ID, err)
+	deliveries, err := d.store.ListNotificationDeliveries(ctx, event.ID)
+	if err != nil {
+		return err
+	}
+	settled := make(map[string]db.NotificationDelivery, len(deliveries))
+	for _, delivery := range deliveries {
+		if delivery.Status == db.NotificationStatusSent || delivery.Status == db.NotificationStatusDead {
+			settled[delivery.Channel] = delivery
+		}
+	}
+	// Retries only go to channels that have not delivered or given up yet.
+	senders := make([]Sender, 0, len(d.senders))
+	for _, sender := range d.senders {
+		if _, ok := settled[sender.Name()]; !ok {
+			senders = append(senders, sender)
 		}
-		for _, result := range results {
-			if !result.Success {
-				slog.Warn("notify: channel send failed", "channel", result.Channel, "job", db.ShortID(event.JobID), "event", event.EventType, "err", result.Error)
-			}
+	}
+
+	results := SendAll(ctx, senders, payload, d.sendTimeout)
+	if len(results) > 0 && successCount(results) == 0 && d.reachable != nil && !d.reachable(ctx) {
+		// The failure is the network's, not the event's: hold it without
+		// spending an attempt until a channel is reachable again.
+		d.offline = true
+		slog.Warn("notify: network unavailable, holding notifications", "job", db.ShortID(event.JobID), "event", event.EventType, "err", summarizeFailures(results))
+		if err := d.store.RequeueNotificationEvent(ctx, event.ID, summarizeFailures(results)); err != nil {
+			return fmt.Errorf("requeue event %d: %w", event.ID, err)
 		}
 		return nil
 	}
 
-	summary := summarizeFailures(results)
-	if summary == "" {
-		summary = "all channels failed"
+	var retrying, dead []ChannelResult
+	for _, result := range results {
+		status, err := d.store.RecordNotificationDelivery(ctx, event.ID, result.Channel, result.Success, result.Error, d.maxAttempts)
+		if err != nil {
+			return err
+		}
+		switch status {
+		case db.NotificationStatusFailed:
+			retrying = append(retrying, result)
+			slog.Warn("notify: channel send failed, will retry", "channel", result.Channel, "job", db.ShortID(event.JobID), "event", event.EventType, "err", result.Error)
+		case db.NotificationStatusDead:
+			dead = append(dead, result)
+			slog.Warn("notify: channel send failed, giving up", "channel", result.Channel, "job", db.ShortID(event.JobID), "event", event.EventType, "err", result.Error)
+		}
 	}
-	if err := d.store.MarkNotificationEventFailed(ctx, event.ID, summary); err != nil {
-		return fmt.Errorf("mark event %d failed: %w", event.ID, err)
+	for _, delivery := range deliveries {
+		if delivery.Status == db.NotificationStatusDead {
+			dead = append(dead, ChannelResult{Channel: delivery.Channel, Error: delivery.LastError})
+		}
+	}
+
+	switch {
+	case len(retrying) > 0:
+		summary := summarizeFailures(retrying)
+		if err := d.store.MarkNotificationEventFailed(ctx, event.ID, summary); err != nil {
+			return fmt.Errorf("mark event %d failed: %w", event.ID, err)
+		}
+		if successCount(results) > 0 {
+			return nil
+		}
+		return fmt.Errorf("send event %d failed: %s", event.ID, summary)
+	case len(dead) > 0:
+		summary := summarizeFailures(dead)
+		if err := d.store.MarkNotificationEventDead(ctx, event.ID, summary); err != nil {
+			return fmt.Errorf("mark event %d dead: %w", event.ID, err)
+		}
+		return fmt.Errorf("event %d dead-lettered: %s", event.ID, summary)
+	default:
+		if err := d.store.MarkNotificationEventSent(ctx, event.ID); err != nil {
+			return fmt.Errorf("mark event %d sent: %w", event.ID, err)
+		}
+		return nil
 	}
-	return fmt.Errorf("send event %d failed: %s", event.ID, summary)
 }
 
 func (d *Dispatcher) buildPayload(ctx context.Context, event db.NotificationEvent) (Payload, error) {
+	if event.JobID == "" {
+		return Payload{
+			Event:     event.EventType,
+			State:     EventState(event.EventType),
+			Message:   event.Message,
+			Timestamp: time.Now().UTC().Format(time.RFC3339),
+		}, nil
+	}
+
 	job, err := d.store.GetJob(ctx, event.JobID)
 	if err != nil {
 		return Payload{}, fmt.Errorf("load job %s: %w", event.JobID, err)
@@ -160,6 +256,7 @@ func (d *Dispatcher) buildPayload(ctx context.Context, event db.NotificationEven
 		JobID:      job.ID,
 		State:      EventState(event.EventType),
 		IssueTitle: issueTitle,
+		Summary:    job.Summary,
 		PRURL:      strings.TrimSpace(job.PRURL),
 		Project:    job.ProjectName,
 		Timestamp:  time.Now().UTC().Format(time.RFC3339),
@@ -167,11 +264,11 @@ func (d *Dispatcher) buildPayload(ctx context.Context, event db.NotificationEven
 }
 
 func (d *Dispatcher) cleanup(ctx context.Context) {
-	skipped, err := d.store.SkipExhaustedNotificationEvents(ctx, d.maxAttempts)
+	dead, err := d.store.DeadLetterExhaustedNotificationEvents(ctx, d.maxAttempts)
 	if err != nil {
-		slog.Warn("notify: skip exhausted events failed", "err", err)
-	} else if skipped > 0 {
-		slog.Info("notify: skipped exhausted events", "count", skipped)
+		slog.Warn("notify: dead-letter exhausted events failed", "err", err)
+	} else if dead > 0 {
+		slog.Info("notify: dead-lettered exhausted events", "count", dead)
 	}
 
 	if d.retention <= 0 {
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"autopr/internal/db"
@@ -29,6 +30,9 @@ func TestDispatcherMarksEventSent(t *testing.T) {
 	defer store.Close()
 
 	jobID := createNotifyTestJob(t, ctx, store, "1000", "Fix notifications")
+	if err := store.UpdateJobField(ctx, jobID, "summary", "Retry failed webhook deliveries"); err != nil {
+		t.Fatalf("set summary: %v", err)
+	}
 	if _, err := store.EnqueueNotificationEvent(ctx, jobID, TriggerNeedsPR); err != nil {
 		t.Fatalf("enqueue: %v", err)
 	}
@@ -56,6 +60,37 @@ func TestDispatcherMarksEventSent(t *testing.T) {
 	if sender.payloads[0].IssueTitle != "Fix notifications" {
 		t.Fatalf("expected issue title in payload, got %q", sender.payloads[0].IssueTitle)
 	}
+	if sender.payloads[0].Summary != "Retry failed webhook deliveries" {
+		t.Fatalf("expected job summary in payload, got %q", sender.payloads[0].Summary)
+	}
+}
+
+func TestDispatcherSendsDaemonEventWithoutJob(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	store := openNotifyTestStore(t)
+	defer store.Close()
+
+	summary := "Restarted after an unclean shutdown: requeued 2 jobs."
+	if _, err := store.EnqueueDaemonNotificationEvent(ctx, TriggerDaemonRecovered, summary); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+
+	sender := &stubSender{name: "stub"}
+	dispatcher := NewDispatcher(store, []Sender{sender}, nil)
+	if _, err := dispatcher.runOnce(ctx); err != nil {
+		t.Fatalf("run once: %v", err)
+	}
+	if len(sender.payloads) != 1 {
+		t.Fatalf("expected 1 payload sent, got %d", len(sender.payloads))
+	}
+	payload := sender.payloads[0]
+	if payload.JobID != "" || payload.Message != summary || payload.State != "recovered" {
+		t.Fatalf("unexpected daemon payload %+v", payload)
+	}
+	if text := SlackText(payload); text != "AutoPR: Daemon Recovered After Crash\n"+summary {
+		t.Fatalf("unexpected slack text %q", text)
+	}
 }
 
 func TestDispatcherMarksDisabledTriggerSkipped(t *testing.T) {
@@ -87,7 +122,7 @@ func TestDispatcherMarksDisabledTriggerSkipped(t *testing.T) {
 	}
 }
 
-func TestDispatcherMarksFailuresAndSkipsExhausted(t *testing.T) {
+func TestDispatcherDeadLettersExhaustedChannels(t *testing.T) {
 	t.Parallel()
 	ctx := context.Background()
 	store := openNotifyTestStore(t)
@@ -108,24 +143,70 @@ func TestDispatcherMarksFailuresAndSkipsExhausted(t *testing.T) {
 		t.Fatal("expected send failure")
 	}
 
-	events, err := store.ListNotificationEvents(ctx, db.NotificationStatusFailed, 0)
+	events, err := store.ListNotificationEvents(ctx, db.NotificationStatusDead, 0)
 	if err != nil {
-		t.Fatalf("list failed events: %v", err)
+		t.Fatalf("list dead events: %v", err)
 	}
 	if len(events) != 1 {
-		t.Fatalf("expected 1 failed event, got %d", len(events))
+		t.Fatalf("expected 1 dead event, got %d", len(events))
 	}
 	if events[0].Attempts != 1 {
 		t.Fatalf("expected attempts=1, got %d", events[0].Attempts)
 	}
+	if !strings.Contains(events[0].LastError, "stub: boom") {
+		t.Fatalf("expected channel error recorded, got %q", events[0].LastError)
+	}
+}
+
+func TestDispatcherRetriesOnlyFailedChannels(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	store := openNotifyTestStore(t)
+	defer store.Close()
+
+	jobID := createNotifyTestJob(t, ctx, store, "1004", "Partial failure")
+	eventID, err := store.EnqueueNotificationEvent(ctx, jobID, TriggerFailed)
+	if err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
 
-	dispatcher.cleanup(ctx)
-	skipped, err := store.ListNotificationEvents(ctx, db.NotificationStatusSkipped, 0)
+	webhook := &stubSender{name: "webhook"}
+	slack := &stubSender{name: "slack", err: errors.New("HTTP 500")}
+	dispatcher := NewDispatcher(store, []Sender{webhook, slack}, []string{TriggerFailed})
+	if _, err := dispatcher.runOnce(ctx); err != nil {
+		t.Fatalf("run once: %v", err)
+	}
+	failed, err := store.ListNotificationEvents(ctx, db.NotificationStatusFailed, 0)
 	if err != nil {
-		t.Fatalf("list skipped events: %v", err)
+		t.Fatalf("list failed events: %v", err)
 	}
-	if len(skipped) != 1 {
-		t.Fatalf("expected exhausted event to be skipped, got %d", len(skipped))
+	if len(failed) != 1 {
+		t.Fatalf("expected event pending retry for slack, got %d failed", len(failed))
+	}
+
+	// Skip the backoff.
+	if _, err := store.Writer.ExecContext(ctx, `
+UPDATE notification_events
+SET updated_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now', '-30 seconds')
+WHERE id = ?`, eventID); err != nil {
+		t.Fatalf("age event: %v", err)
+	}
+	slack.err = nil
+	if processed, err := dispatcher.runOnce(ctx); !processed || err != nil {
+		t.Fatalf("expected retry to be processed, processed=%v err=%v", processed, err)
+	}
+	if len(webhook.payloads) != 1 {
+		t.Fatalf("expected webhook to be sent once, got %d", len(webhook.payloads))
+	}
+	if len(slack.payloads) != 2 {
+		t.Fatalf("expected slack to be retried, got %d sends", len(slack.payloads))
+	}
+	sent, err := store.ListNotificationEvents(ctx, db.NotificationStatusSent, 0)
+	if err != nil {
+		t.Fatalf("list sent events: %v", err)
+	}
+	if len(sent) != 1 {
+		t.Fatalf("expected event sent after retry, got %d", len(sent))
 	}
 }
 
@@ -157,3 +238,52 @@ func createNotifyTestJob(t *testing.T, ctx context.Context, store *db.Store, sou
 	}
 	return jobID
 }
+
+func TestDispatcherHoldsEventsWhileOffline(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	store := openNotifyTestStore(t)
+	defer store.Close()
+
+	jobID := createNotifyTestJob(t, ctx, store, "1003", "Offline sender")
+	if _, err := store.EnqueueNotificationEvent(ctx, jobID, TriggerFailed); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+
+	sender := &stubSender{name: "stub", err: errors.New("dial tcp: connection refused")}
+	dispatcher := NewDispatcher(store, []Sender{sender}, []string{TriggerFailed})
+	online := false
+	dispatcher.reachable = func(context.Context) bool { return online }
+
+	if _, err := dispatcher.runOnce(ctx); err != nil {
+		t.Fatalf("run once: %v", err)
+	}
+	pending, err := store.ListNotificationEvents(ctx, db.NotificationStatusPending, 0)
+	if err != nil {
+		t.Fatalf("list pending events: %v", err)
+	}
+	if len(pending) != 1 || pending[0].Attempts != 0 {
+		t.Fatalf("expected event held as pending without an attempt, got %+v", pending)
+	}
+
+	// While offline the dispatcher does not claim or send.
+	if processed, err := dispatcher.runOnce(ctx); processed || err != nil {
+		t.Fatalf("expected no processing while offline, processed=%v err=%v", processed, err)
+	}
+	if len(sender.payloads) != 1 {
+		t.Fatalf("expected a single send attempt while offline, got %d", len(sender.payloads))
+	}
+
+	online = true
+	sender.err = nil
+	if processed, err := dispatcher.runOnce(ctx); !processed || err != nil {
+		t.Fatalf("expected event sent once online, processed=%v err=%v", processed, err)
+	}
+	sent, err := store.ListNotificationEvents(ctx, db.NotificationStatusSent, 0)
+	if err != nil {
+		t.Fatalf("list sent events: %v", err)
+	}
+	if len(sent) != 1 {
+		t.Fatalf("expected 1 sent event, got %d", len(sent))
+	}
+}
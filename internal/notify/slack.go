@@ -27,6 +27,10 @@ func (s *SlackSender) Name() string {
 	return "slack"
 }
 
+func (s *SlackSender) Endpoint() string {
+	return s.url
+}
+
 func (s *SlackSender) Send(ctx context.Context, payload Payload) error {
 	body := map[string]string{"text": SlackText(payload)}
 	encoded, err := json.Marshal(body)
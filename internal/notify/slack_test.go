@@ -58,3 +58,19 @@ func TestSlackTextUsesEventLabel(t *testing.T) {
 		t.Fatalf("expected PR Merged label, got %q", text)
 	}
 }
+
+func TestSlackTextShowsJobSummary(t *testing.T) {
+	t.Parallel()
+	payload := Payload{Event: TriggerFailed, Project: "proj", JobID: "id", IssueTitle: "it broke again", Summary: "Fix token refresh race in auth client"}
+	text := SlackText(payload)
+	if !strings.Contains(text, "Summary: Fix token refresh race in auth client") || !strings.Contains(text, "Issue: it broke again") {
+		t.Fatalf("expected summary and issue title, got %q", text)
+	}
+	if payload.Title() != payload.Summary {
+		t.Fatalf("expected the summary as title, got %q", payload.Title())
+	}
+	payload.Summary = ""
+	if strings.Contains(SlackText(payload), "Summary:") || payload.Title() != "it broke again" {
+		t.Fatalf("expected the issue title without a summary, got %q", SlackText(payload))
+	}
+}
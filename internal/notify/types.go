@@ -12,6 +12,10 @@ const (
 	TriggerFailed   = "failed"
 	TriggerPRCreated = "pr_created"
 	TriggerPRMerged  = "pr_merged"
+	TriggerCIStuck   = "ci_stuck"
+	TriggerQueueStale = "queue_stale"
+	TriggerDaemonRecovered = "daemon_recovered"
+	TriggerDeadlineOverdue = "deadline_overdue"
 )
 
 var AllTriggers = []string{
@@ -19,18 +23,34 @@ var AllTriggers = []string{
 	TriggerFailed,
 	TriggerPRCreated,
 	TriggerPRMerged,
+	TriggerCIStuck,
+	TriggerQueueStale,
+	TriggerDaemonRecovered,
+	TriggerDeadlineOverdue,
 }
 
+// Payload is one notification. Daemon events have no job: JobID, IssueTitle,
+// and Project are empty and Message holds the text.
 type Payload struct {
 	Event      string `json:"event"`
 	JobID      string `json:"job_id"`
 	State      string `json:"state"`
 	IssueTitle string `json:"issue_title"`
+	Summary    string `json:"summary,omitempty"` // one-line LLM summary of the job, if summarized
 	PRURL      string `json:"pr_url,omitempty"`
 	Project    string `json:"project"`
+	Message    string `json:"message,omitempty"`
 	Timestamp  string `json:"timestamp"`
 }
 
+// Title is the job's summary, or its issue title when it has none.
+func (p Payload) Title() string {
+	if p.Summary != "" {
+		return p.Summary
+	}
+	return p.IssueTitle
+}
+
 type Sender interface {
 	Name() string
 	Send(ctx context.Context, payload Payload) error
@@ -44,7 +64,7 @@ type ChannelResult struct {
 
 func IsValidTrigger(trigger string) bool {
 	switch trigger {
-	case TriggerNeedsPR, TriggerFailed, TriggerPRCreated, TriggerPRMerged:
+	case TriggerNeedsPR, TriggerFailed, TriggerPRCreated, TriggerPRMerged, TriggerCIStuck, TriggerQueueStale, TriggerDaemonRecovered, TriggerDeadlineOverdue:
 		return true
 	default:
 		return false
@@ -79,6 +99,14 @@ func EventState(event string) string {
 		return "pr created"
 	case TriggerPRMerged:
 		return "pr merged"
+	case TriggerCIStuck:
+		return "ci stuck"
+	case TriggerQueueStale:
+		return "queue stale"
+	case TriggerDaemonRecovered:
+		return "recovered"
+	case TriggerDeadlineOverdue:
+		return "overdue"
 	default:
 		return "failed"
 	}
@@ -92,6 +120,14 @@ func EventLabel(event string) string {
 		return "PR Created"
 	case TriggerPRMerged:
 		return "PR Merged"
+	case TriggerCIStuck:
+		return "CI Stuck"
+	case TriggerQueueStale:
+		return "Queued Too Long"
+	case TriggerDaemonRecovered:
+		return "Daemon Recovered After Crash"
+	case TriggerDeadlineOverdue:
+		return "Deadline Missed"
 	default:
 		return "Job Failed"
 	}
@@ -111,7 +147,14 @@ func TestPayload() Payload {
 }
 
 func SlackText(payload Payload) string {
-	text := fmt.Sprintf("AutoPR: %s\nProject: %s\nJob: %s\nIssue: %s", EventLabel(payload.Event), payload.Project, payload.JobID, payload.IssueTitle)
+	if payload.JobID == "" {
+		return fmt.Sprintf("AutoPR: %s\n%s", EventLabel(payload.Event), payload.Message)
+	}
+	text := fmt.Sprintf("AutoPR: %s\nProject: %s\nJob: %s", EventLabel(payload.Event), payload.Project, payload.JobID)
+	if payload.Summary != "" {
+		text += "\nSummary: " + payload.Summary
+	}
+	text += "\nIssue: " + payload.IssueTitle
 	if payload.PRURL != "" {
 		text += "\nPR: " + payload.PRURL
 	}
@@ -31,6 +31,10 @@ func (s *WebhookSender) Name() string {
 	return "webhook"
 }
 
+func (s *WebhookSender) Endpoint() string {
+	return s.url
+}
+
 func (s *WebhookSender) Send(ctx context.Context, payload Payload) error {
 	body, err := json.Marshal(payload)
 	if err != nil {
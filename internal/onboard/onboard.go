@@ -0,0 +1,181 @@
+// Package onboard inspects a git checkout and proposes the [[projects]] block
+// `ap init project` writes into the config.
+package onboard
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"autopr/internal/config"
+	"autopr/internal/git"
+	"autopr/internal/stack"
+)
+
+// Source kinds a project can be onboarded as.
+const (
+	SourceGitHub = "github"
+	SourceGitLab = "gitlab"
+	SourceGitea  = "gitea"
+	SourceLocal  = "local"
+)
+
+// Project is what was detected about a repository. The wizard lets the user
+// change every field before it is written.
+type Project struct {
+	Name       string
+	RepoURL    string
+	BaseBranch string
+	TestCmd    string
+	Source     string
+
+	Host    string // forge web root, e.g. "https://github.com"
+	Owner   string // GitHub/Gitea owner, or the GitLab namespace
+	Repo    string
+	Labels  []string
+	TodoMD  string // local projects: issues file, relative to the repo
+	RepoDir string
+}
+
+// Remote is a parsed git remote URL.
+type Remote struct {
+	Host  string // hostname, without scheme or port
+	Path  string // "owner/repo" (GitLab: "group/subgroup/repo"), without .git
+	HTTPS bool
+}
+
+var scpLikeRemote = regexp.MustCompile(`^(?:[\w.-]+@)?([\w.-]+):([^/].*)$`)
+
+// ParseRemote splits a git remote URL into host and repository path. It
+// accepts https://, ssh:// and scp-like (git@host:owner/repo.git) forms.
+func ParseRemote(raw string) (Remote, bool) {
+	raw = strings.TrimSpace(raw)
+	var r Remote
+	if u, err := url.Parse(raw); err == nil && u.Host != "" && (u.Scheme == "https" || u.Scheme == "http" || u.Scheme == "ssh" || u.Scheme == "git") {
+		r.Host = u.Hostname()
+		r.Path = u.Path
+		r.HTTPS = u.Scheme == "https" || u.Scheme == "http"
+	} else if m := scpLikeRemote.FindStringSubmatch(raw); m != nil && !strings.Contains(m[1], "/") {
+		r.Host = m[1]
+		r.Path = m[2]
+	} else {
+		return Remote{}, false
+	}
+	r.Path = strings.TrimSuffix(strings.Trim(r.Path, "/"), ".git")
+	if r.Host == "" || !strings.Contains(r.Path, "/") {
+		return Remote{}, false
+	}
+	return r, true
+}
+
+// GuessSource picks the forge for a remote host. Hosts that are not
+// recognisably GitHub or GitLab are assumed to be Gitea/Forgejo.
+func GuessSource(host string) string {
+	h := strings.ToLower(host)
+	switch {
+	case h == "github.com" || strings.Contains(h, "github"):
+		return SourceGitHub
+	case strings.Contains(h, "gitlab"):
+		return SourceGitLab
+	default:
+		return SourceGitea
+	}
+}
+
+// Detect inspects the checkout containing dir.
+func Detect(ctx context.Context, dir string) (Project, error) {
+	root, err := git.RepoRoot(ctx, dir)
+	if err != nil {
+		return Project{}, err
+	}
+	p := Project{
+		Name:       filepath.Base(root),
+		RepoDir:    root,
+		BaseBranch: git.DefaultBranch(ctx, root),
+		TestCmd:    DetectTestCmd(root),
+		Labels:     []string{config.DefaultLabel},
+	}
+
+	remoteURL := git.RemoteURL(ctx, root, "origin")
+	remote, ok := ParseRemote(remoteURL)
+	if !ok {
+		// No usable remote: work on the checkout itself.
+		p.Source = SourceLocal
+		p.RepoURL = root
+		p.Labels = nil
+		for _, name := range []string{"TODO.md", "todo.md", "TODO"} {
+			if _, err := os.Stat(filepath.Join(root, name)); err == nil {
+				p.TodoMD = name
+				break
+			}
+		}
+		return p, nil
+	}
+
+	p.RepoURL = remoteURL
+	p.Source = GuessSource(remote.Host)
+	p.Host = "https://" + remote.Host
+	idx := strings.LastIndex(remote.Path, "/")
+	p.Owner, p.Repo = remote.Path[:idx], remote.Path[idx+1:]
+	p.Name = p.Repo
+	return p, nil
+}
+
+// DetectTestCmd suggests a test command from the build files in dir, or ""
+// when none is recognised. The command must run without a shell.
+func DetectTestCmd(dir string) string {
+	return stack.DetectDir(dir).TestCmd
+}
+
+// Block renders p as a [[projects]] TOML block ready to append to a config.
+func Block(p Project) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "\n# Added by `ap init project` for %s\n", p.RepoDir)
+	b.WriteString("[[projects]]\n")
+	fmt.Fprintf(&b, "name = %s\n", strconv.Quote(p.Name))
+	fmt.Fprintf(&b, "repo_url = %s\n", strconv.Quote(p.RepoURL))
+	fmt.Fprintf(&b, "test_cmd = %s\n", strconv.Quote(p.TestCmd))
+	fmt.Fprintf(&b, "base_branch = %s\n", strconv.Quote(p.BaseBranch))
+	b.WriteString("\n")
+
+	switch p.Source {
+	case SourceGitHub:
+		b.WriteString("  [projects.github]\n")
+		fmt.Fprintf(&b, "  owner = %s\n", strconv.Quote(p.Owner))
+		fmt.Fprintf(&b, "  repo = %s\n", strconv.Quote(p.Repo))
+		if p.Host != "" && p.Host != "https://github.com" {
+			fmt.Fprintf(&b, "  base_url = %s\n", strconv.Quote(p.Host))
+		}
+		writeLabels(&b, p.Labels)
+	case SourceGitLab:
+		b.WriteString("  [projects.gitlab]\n")
+		fmt.Fprintf(&b, "  base_url = %s\n", strconv.Quote(p.Host))
+		fmt.Fprintf(&b, "  project_id = %s\n", strconv.Quote(url.PathEscape(p.Owner+"/"+p.Repo)))
+		writeLabels(&b, p.Labels)
+	case SourceGitea:
+		b.WriteString("  [projects.gitea]\n")
+		fmt.Fprintf(&b, "  base_url = %s\n", strconv.Quote(p.Host))
+		fmt.Fprintf(&b, "  owner = %s\n", strconv.Quote(p.Owner))
+		fmt.Fprintf(&b, "  repo = %s\n", strconv.Quote(p.Repo))
+		writeLabels(&b, p.Labels)
+	case SourceLocal:
+		b.WriteString("  [projects.local]\n")
+		if p.TodoMD != "" {
+			fmt.Fprintf(&b, "  issues_file = %s\n", strconv.Quote(p.TodoMD))
+		}
+	}
+	return b.String()
+}
+
+func writeLabels(b *strings.Builder, labels []string) {
+	quoted := make([]string, len(labels))
+	for i, l := range labels {
+		quoted[i] = strconv.Quote(l)
+	}
+	fmt.Fprintf(b, "  include_labels = [%s]\n", strings.Join(quoted, ", "))
+}
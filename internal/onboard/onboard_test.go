@@ -0,0 +1,113 @@
+package onboard
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseRemote(t *testing.T) {
+	cases := []struct {
+		raw  string
+		want Remote
+		ok   bool
+	}{
+		{"https://github.com/acme/app.git", Remote{Host: "github.com", Path: "acme/app", HTTPS: true}, true},
+		{"git@github.com:acme/app.git", Remote{Host: "github.com", Path: "acme/app"}, true},
+		{"ssh://git@gitlab.example.com:2222/group/sub/app.git", Remote{Host: "gitlab.example.com", Path: "group/sub/app"}, true},
+		{"https://gitea.example.com/acme/app/", Remote{Host: "gitea.example.com", Path: "acme/app", HTTPS: true}, true},
+		{"/srv/git/app.git", Remote{}, false},
+		{"file:///srv/git/app.git", Remote{}, false},
+		{"", Remote{}, false},
+	}
+	for _, tc := range cases {
+		got, ok := ParseRemote(tc.raw)
+		if ok != tc.ok || got != tc.want {
+			t.Errorf("ParseRemote(%q) = %+v, %v; want %+v, %v", tc.raw, got, ok, tc.want, tc.ok)
+		}
+	}
+}
+
+func TestDetectTestCmd(t *testing.T) {
+	cases := []struct {
+		files map[string]string
+		want  string
+	}{
+		{map[string]string{"Makefile": "build:\n\tgo build\n\ntest:\n\tgo test ./...\n", "go.mod": "module x\n"}, "make test"},
+		{map[string]string{"Makefile": "build:\n\tgo build\n", "go.mod": "module x\n"}, "go test ./..."},
+		{map[string]string{"package.json": `{"scripts":{"test":"vitest"}}`, "pnpm-lock.yaml": ""}, "pnpm test"},
+		{map[string]string{"package.json": `{"scripts":{"build":"tsc"}}`}, ""},
+		{map[string]string{"Cargo.toml": ""}, "cargo test"},
+		{map[string]string{"pyproject.toml": ""}, "pytest"},
+	}
+	for _, tc := range cases {
+		dir := t.TempDir()
+		for name, content := range tc.files {
+			if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if got := DetectTestCmd(dir); got != tc.want {
+			t.Errorf("DetectTestCmd(%v) = %q, want %q", tc.files, got, tc.want)
+		}
+	}
+}
+
+func TestDetect(t *testing.T) {
+	dir := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	git("init", "-q", "-b", "trunk")
+	if err := os.WriteFile(filepath.Join(dir, "TODO.md"), []byte("- [ ] one\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := Detect(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("detect: %v", err)
+	}
+	if p.Source != SourceLocal || p.BaseBranch != "trunk" || p.TodoMD != "TODO.md" || p.Labels != nil {
+		t.Fatalf("unexpected local detection %+v", p)
+	}
+
+	git("remote", "add", "origin", "git@gitlab.example.com:group/sub/app.git")
+	p, err = Detect(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("detect: %v", err)
+	}
+	if p.Source != SourceGitLab || p.Name != "app" || p.Owner != "group/sub" || p.Host != "https://gitlab.example.com" {
+		t.Fatalf("unexpected gitlab detection %+v", p)
+	}
+}
+
+func TestBlock(t *testing.T) {
+	block := Block(Project{
+		Name: "app", RepoURL: "git@gitlab.example.com:group/sub/app.git", TestCmd: "make test", BaseBranch: "main",
+		Source: SourceGitLab, Host: "https://gitlab.example.com", Owner: "group/sub", Repo: "app", Labels: []string{"autopr"},
+	})
+	for _, want := range []string{
+		"[[projects]]\nname = \"app\"\n",
+		"[projects.gitlab]\n",
+		`project_id = "group%2Fsub%2Fapp"`,
+		`include_labels = ["autopr"]`,
+	} {
+		if !strings.Contains(block, want) {
+			t.Errorf("block missing %q:\n%s", want, block)
+		}
+	}
+
+	block = Block(Project{Name: "app", RepoURL: "https://github.com/acme/app.git", TestCmd: "go test ./...", BaseBranch: "main",
+		Source: SourceGitHub, Host: "https://github.com", Owner: "acme", Repo: "app", Labels: []string{}})
+	if strings.Contains(block, "base_url") || !strings.Contains(block, "include_labels = []") {
+		t.Errorf("unexpected github block:\n%s", block)
+	}
+}
@@ -0,0 +1,185 @@
+// Package patchbundle packs a job's commits as git format-patch files plus a
+// manifest into a single tar.gz, so AutoPR output can be reviewed and applied
+// on a machine that cannot reach the forge or the AutoPR host.
+package patchbundle
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// FormatVersion is bumped when the manifest layout changes incompatibly.
+const FormatVersion = 1
+
+const (
+	manifestName = "manifest.json"
+	patchDir     = "patches"
+	// maxEntrySize bounds each extracted file so a malformed bundle cannot
+	// fill the disk.
+	maxEntrySize = 256 << 20
+)
+
+// Manifest describes where the patches came from and how to apply them.
+type Manifest struct {
+	FormatVersion int     `json:"format_version"`
+	JobID         string  `json:"job_id"`
+	Project       string  `json:"project"`
+	IssueSource   string  `json:"issue_source,omitempty"`
+	IssueID       string  `json:"issue_id,omitempty"`
+	IssueTitle    string  `json:"issue_title,omitempty"`
+	IssueURL      string  `json:"issue_url,omitempty"`
+	Branch        string  `json:"branch"`
+	BaseBranch    string  `json:"base_branch"`
+	BaseCommit    string  `json:"base_commit"` // the patches apply cleanly on top of this commit
+	HeadCommit    string  `json:"head_commit"`
+	AutoPRVersion string  `json:"autopr_version"`
+	CreatedAt     string  `json:"created_at"`
+	Patches       []Patch `json:"patches"`
+}
+
+// Patch is one format-patch file in apply order.
+type Patch struct {
+	Name   string `json:"name"`
+	SHA256 string `json:"sha256"`
+}
+
+// Write packs the patch files into w as a tar.gz, filling m.Patches with
+// their names and checksums.
+func Write(w io.Writer, m *Manifest, patchFiles []string) error {
+	if len(patchFiles) == 0 {
+		return errors.New("no patches to export")
+	}
+	m.FormatVersion = FormatVersion
+	m.Patches = m.Patches[:0]
+
+	contents := make([][]byte, len(patchFiles))
+	for i, p := range patchFiles {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return fmt.Errorf("read patch: %w", err)
+		}
+		contents[i] = data
+		m.Patches = append(m.Patches, Patch{Name: filepath.Base(p), SHA256: checksum(data)})
+	}
+	manifest, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode manifest: %w", err)
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	if err := writeEntry(tw, manifestName, append(manifest, '\n')); err != nil {
+		return err
+	}
+	for i, p := range m.Patches {
+		if err := writeEntry(tw, path.Join(patchDir, p.Name), contents[i]); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("close bundle: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("close bundle: %w", err)
+	}
+	return nil
+}
+
+func writeEntry(tw *tar.Writer, name string, data []byte) error {
+	hdr := &tar.Header{Name: name, Mode: 0o644, Size: int64(len(data)), Typeflag: tar.TypeReg}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return fmt.Errorf("write %s: %w", name, err)
+	}
+	if _, err := tw.Write(data); err != nil {
+		return fmt.Errorf("write %s: %w", name, err)
+	}
+	return nil
+}
+
+// Extract unpacks a bundle into destDir, verifies every patch against the
+// manifest checksums, and returns the manifest with the patch paths in apply
+// order.
+func Extract(r io.Reader, destDir string) (Manifest, []string, error) {
+	var m Manifest
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return m, nil, fmt.Errorf("open bundle: %w", err)
+	}
+	defer gz.Close()
+
+	var manifest []byte
+	patches := map[string][]byte{}
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return m, nil, fmt.Errorf("read bundle: %w", err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		data, err := io.ReadAll(io.LimitReader(tr, maxEntrySize+1))
+		if err != nil {
+			return m, nil, fmt.Errorf("read %s: %w", hdr.Name, err)
+		}
+		if len(data) > maxEntrySize {
+			return m, nil, fmt.Errorf("bundle entry %s is too large", hdr.Name)
+		}
+		switch dir, name := path.Split(hdr.Name); {
+		case hdr.Name == manifestName:
+			manifest = data
+		case dir == patchDir+"/" && name != "":
+			patches[name] = data
+		}
+	}
+	if manifest == nil {
+		return m, nil, errors.New("bundle has no manifest.json")
+	}
+	if err := json.Unmarshal(manifest, &m); err != nil {
+		return m, nil, fmt.Errorf("decode manifest: %w", err)
+	}
+	if m.FormatVersion != FormatVersion {
+		return m, nil, fmt.Errorf("unsupported bundle format version %d (want %d)", m.FormatVersion, FormatVersion)
+	}
+	if len(m.Patches) == 0 {
+		return m, nil, errors.New("bundle manifest lists no patches")
+	}
+
+	paths := make([]string, 0, len(m.Patches))
+	for _, p := range m.Patches {
+		if p.Name == "" || p.Name != filepath.Base(p.Name) || strings.HasPrefix(p.Name, ".") {
+			return m, nil, fmt.Errorf("invalid patch name %q in manifest", p.Name)
+		}
+		data, ok := patches[p.Name]
+		if !ok {
+			return m, nil, fmt.Errorf("patch %s listed in manifest is missing", p.Name)
+		}
+		if got := checksum(data); got != p.SHA256 {
+			return m, nil, fmt.Errorf("patch %s checksum mismatch", p.Name)
+		}
+		dest := filepath.Join(destDir, p.Name)
+		if err := os.WriteFile(dest, data, 0o600); err != nil {
+			return m, nil, fmt.Errorf("write patch: %w", err)
+		}
+		paths = append(paths, dest)
+	}
+	return m, paths, nil
+}
+
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,99 @@
+package patchbundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writePatches(t *testing.T, dir string, contents ...string) []string {
+	t.Helper()
+	var paths []string
+	for i, c := range contents {
+		p := filepath.Join(dir, "000"+string(rune('1'+i))+"-change.patch")
+		if err := os.WriteFile(p, []byte(c), 0o644); err != nil {
+			t.Fatalf("write patch: %v", err)
+		}
+		paths = append(paths, p)
+	}
+	return paths
+}
+
+func TestWriteExtractRoundTrip(t *testing.T) {
+	t.Parallel()
+	src := writePatches(t, t.TempDir(), "From abc\nfirst\n", "From def\nsecond\n")
+
+	var buf bytes.Buffer
+	m := &Manifest{JobID: "ap-job-1", Project: "proj", Branch: "autopr/1", BaseBranch: "main", BaseCommit: "base", HeadCommit: "head"}
+	if err := Write(&buf, m, src); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if len(m.Patches) != 2 || m.FormatVersion != FormatVersion {
+		t.Fatalf("expected manifest filled in, got %+v", m)
+	}
+
+	dest := t.TempDir()
+	got, paths, err := Extract(&buf, dest)
+	if err != nil {
+		t.Fatalf("extract: %v", err)
+	}
+	if got.JobID != "ap-job-1" || got.BaseCommit != "base" || len(paths) != 2 {
+		t.Fatalf("unexpected manifest %+v paths %v", got, paths)
+	}
+	data, err := os.ReadFile(paths[1])
+	if err != nil || string(data) != "From def\nsecond\n" {
+		t.Fatalf("expected second patch in order, got %q (err %v)", data, err)
+	}
+}
+
+func TestWriteRequiresPatches(t *testing.T) {
+	t.Parallel()
+	if err := Write(&bytes.Buffer{}, &Manifest{}, nil); err == nil {
+		t.Fatal("expected error for empty patch list")
+	}
+}
+
+func TestExtractRejectsTamperedPatch(t *testing.T) {
+	t.Parallel()
+	src := writePatches(t, t.TempDir(), "From abc\nfirst\n")
+	var buf bytes.Buffer
+	m := &Manifest{JobID: "ap-job-1"}
+	if err := Write(&buf, m, src); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	// Repack with the manifest unchanged but the patch body altered.
+	var tampered bytes.Buffer
+	gz := gzip.NewWriter(&tampered)
+	tw := tar.NewWriter(gz)
+	in, err := gzip.NewReader(&buf)
+	if err != nil {
+		t.Fatalf("open bundle: %v", err)
+	}
+	tr := tar.NewReader(in)
+	for {
+		hdr, err := tr.Next()
+		if err != nil {
+			break
+		}
+		var body bytes.Buffer
+		_, _ = body.ReadFrom(tr)
+		data := body.Bytes()
+		if strings.HasPrefix(hdr.Name, patchDir+"/") {
+			data = []byte("From abc\nsomething else\n")
+		}
+		hdr.Size = int64(len(data))
+		_ = tw.WriteHeader(hdr)
+		_, _ = tw.Write(data)
+	}
+	_ = tw.Close()
+	_ = gz.Close()
+
+	if _, _, err := Extract(&tampered, t.TempDir()); err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Fatalf("expected checksum mismatch, got %v", err)
+	}
+}
@@ -0,0 +1,179 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+
+	"autopr/internal/config"
+	"autopr/internal/db"
+	"autopr/internal/git"
+)
+
+// Approval is a person's approval of a ready job, from `ap approve`, the TUI,
+// or chatops.
+type Approval struct {
+	Approver   string             // identity for the commit trailers; "" for none
+	Draft      bool               // open the PR as a draft
+	Exclusions git.DiffExclusions // files and hunks reverted before the push
+	// ReviewedDiff is the DiffChecksum of the diff the approver saw. When
+	// set, the approval is refused with a DiffChangedError if the diff has
+	// changed since, such as by a late LLM commit.
+	ReviewedDiff string
+}
+
+// ApproveResult is the outcome of an approval.
+type ApproveResult struct {
+	PRURL    string // "" for local projects
+	Existing bool   // the PR was opened earlier, e.g. by auto_pr
+}
+
+// DiffChangedError refuses an approval whose job's diff changed after the
+// approver reviewed it. Sum is the checksum of the diff now.
+type DiffChangedError struct {
+	Sum string
+}
+
+func (e *DiffChangedError) Error() string {
+	return "diff changed since it was reviewed"
+}
+
+// DiffChecksum returns the checksum of a job's diff against its target
+// branch, for Approval.ReviewedDiff.
+func DiffChecksum(ctx context.Context, job db.Job, proj *config.ProjectConfig) (string, error) {
+	if job.WorktreePath == "" {
+		return "", fmt.Errorf("job has no worktree")
+	}
+	out, err := git.DiffAgainstBase(ctx, job.WorktreePath, TargetBranch(job, proj))
+	if err != nil {
+		return "", err
+	}
+	return git.DiffChecksum(out), nil
+}
+
+// prPublisher pushes job branches and opens PRs. The Runner swaps its
+// functions out in tests.
+type prPublisher struct {
+	resolvePushTarget func(ctx context.Context, projectCfg *config.ProjectConfig, branchName, worktreePath, token string) (string, string, error)
+	push              func(ctx context.Context, dir, remoteName, branchName, token string) error
+	createPR          func(ctx context.Context, cfg *config.Config, proj *config.ProjectConfig, job db.Job, head, title, body string, draft bool) (string, error)
+}
+
+var defaultPublisher = prPublisher{
+	resolvePushTarget: ResolveGitHubPushTarget,
+	push:              git.PushBranchWithLeaseToRemoteWithToken,
+	createPR:          CreatePRForProject,
+}
+
+// Approve pushes a ready job's branch, opens its PR, and moves the job to
+// approved. The caller holds the job's lease.
+func Approve(ctx context.Context, cfg *config.Config, store *db.Store, jobID string, a Approval) (ApproveResult, error) {
+	job, err := store.GetJob(ctx, jobID)
+	if err != nil {
+		return ApproveResult{}, err
+	}
+	if job.State != "ready" {
+		return ApproveResult{}, fmt.Errorf("job %s is in state %q, must be 'ready' to approve", db.ShortID(jobID), job.State)
+	}
+	proj, ok := cfg.ProjectByName(job.ProjectName)
+	if !ok {
+		return ApproveResult{}, fmt.Errorf("project %q not found in config", job.ProjectName)
+	}
+	issue, err := store.GetIssueByAPID(ctx, job.AutoPRIssueID)
+	if err != nil {
+		return ApproveResult{}, fmt.Errorf("load issue: %w", err)
+	}
+
+	// Push only the diff the approver saw.
+	if a.ReviewedDiff != "" {
+		sum, err := DiffChecksum(ctx, job, proj)
+		if err != nil {
+			return ApproveResult{}, fmt.Errorf("verify diff: %w", err)
+		}
+		if sum != a.ReviewedDiff {
+			return ApproveResult{}, &DiffChangedError{Sum: sum}
+		}
+	}
+	// Partial approval: revert the excluded files and hunks in a follow-up
+	// commit.
+	if len(a.Exclusions) > 0 {
+		if _, err := ApplyPartialApproval(ctx, store, job, TargetBranch(job, proj), a.Exclusions); err != nil {
+			return ApproveResult{}, fmt.Errorf("partial approval: %w", err)
+		}
+	}
+
+	res := ApproveResult{PRURL: job.PRURL, Existing: job.PRURL != ""}
+	if res.PRURL, err = defaultPublisher.publish(ctx, cfg, store, proj, job, issue, a.Approver, a.Draft); err != nil {
+		return ApproveResult{}, err
+	}
+	if err := store.TransitionState(ctx, job.ID, "ready", "approved"); err != nil {
+		// auto_pr may have approved the job already.
+		if fresh, ferr := store.GetJob(ctx, job.ID); ferr == nil && fresh.State == "approved" {
+			return res, nil
+		}
+		return ApproveResult{}, err
+	}
+	return res, nil
+}
+
+// PushJobBranch rebases a job's branch onto its target branch, adds the
+// commit trailers, runs the pre_push hooks, and pushes the branch to the
+// remote its PR is opened from. It returns the PR head.
+func PushJobBranch(ctx context.Context, cfg *config.Config, store *db.Store, proj *config.ProjectConfig, job db.Job, issue db.Issue, approver string) (string, error) {
+	return defaultPublisher.pushBranch(ctx, cfg, store, proj, job, issue, approver)
+}
+
+func (p prPublisher) pushBranch(ctx context.Context, cfg *config.Config, store *db.Store, proj *config.ProjectConfig, job db.Job, issue db.Issue, approver string) (string, error) {
+	token := GitTokenForProject(ctx, cfg, proj)
+	if err := RebaseBeforePush(ctx, store, job.ID, job.AutoPRIssueID, TargetBranch(job, proj), job.WorktreePath, job.Iteration, token); err != nil {
+		return "", fmt.Errorf("rebase before push: %w", err)
+	}
+	if err := ApplyCommitTrailers(ctx, cfg, proj, job, job.WorktreePath, TargetBranch(job, proj), approver); err != nil {
+		return "", err
+	}
+	if err := RunHooks(ctx, cfg, store, proj, job, issue, config.HookPrePush); err != nil {
+		return "", err
+	}
+
+	remote, head := "origin", job.BranchName
+	if proj.GitHub != nil {
+		var err error
+		remote, head, err = p.resolvePushTarget(ctx, proj, job.BranchName, job.WorktreePath, token)
+		if err != nil {
+			return "", fmt.Errorf("resolve push target: %w", err)
+		}
+	}
+	if err := p.push(ctx, job.WorktreePath, remote, job.BranchName, token); err != nil {
+		return "", fmt.Errorf("push branch: %w", err)
+	}
+	return head, nil
+}
+
+// publish pushes the job's branch and opens its PR, unless it has one, such
+// as one a PR comment's feedback iteration pushes to. It returns the PR URL.
+func (p prPublisher) publish(ctx context.Context, cfg *config.Config, store *db.Store, proj *config.ProjectConfig, job db.Job, issue db.Issue, approver string, draft bool) (string, error) {
+	head, err := p.pushBranch(ctx, cfg, store, proj, job, issue, approver)
+	if err != nil {
+		return "", err
+	}
+	if job.PRURL == "" {
+		title, body := BuildPRContent(ctx, store, cfg, job, issue)
+		prURL, err := p.createPR(ctx, cfg, proj, job, head, title, body, draft)
+		if err != nil {
+			return "", fmt.Errorf("create PR: %w", err)
+		}
+		if prURL != "" {
+			if err := store.UpdateJobField(ctx, job.ID, "pr_url", prURL); err != nil {
+				return "", fmt.Errorf("store PR URL: %w", err)
+			}
+			// Commands on a PR opened while pr_commands is on are all read.
+			if cfg.Daemon.PRCommands {
+				if err := store.SetJobPRCommentCursor(ctx, job.ID, db.PRCommentCursorStart); err != nil {
+					return "", err
+				}
+			}
+		}
+		job.PRURL = prURL
+	}
+	ReportPipelineCheck(ctx, cfg, store, proj, job)
+	return job.PRURL, nil
+}
@@ -0,0 +1,67 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"autopr/internal/config"
+)
+
+func TestApproveRefusesAChangedDiff(t *testing.T) {
+	t.Parallel()
+
+	_, store, _, jobID := setupRunStepsJob(t, nil, "testing")
+	ctx := context.Background()
+	tmp := t.TempDir()
+	remote := filepath.Join(tmp, "remote.git")
+	wt := filepath.Join(tmp, "wt")
+	runGitCmdLocal(t, "", "init", "--bare", "-b", "main", remote)
+	runGitCmdLocal(t, "", "clone", remote, wt)
+	runGitCmdLocal(t, wt, "-c", "user.name=t", "-c", "user.email=t@example.com", "commit", "--allow-empty", "-m", "initial")
+	runGitCmdLocal(t, wt, "push", "origin", "HEAD:main")
+	if err := os.WriteFile(filepath.Join(wt, "a.txt"), []byte("reviewed\n"), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	if err := store.UpdateJobField(ctx, jobID, "worktree_path", wt); err != nil {
+		t.Fatalf("set worktree: %v", err)
+	}
+
+	job, err := store.GetJob(ctx, jobID)
+	if err != nil {
+		t.Fatalf("get job: %v", err)
+	}
+	cfg := &config.Config{Projects: []config.ProjectConfig{{Name: job.ProjectName, BaseBranch: "main"}}}
+
+	// Only ready jobs can be approved.
+	if _, err := Approve(ctx, cfg, store, jobID, Approval{}); err == nil || !strings.Contains(err.Error(), "must be 'ready'") {
+		t.Fatalf("expected a testing job to be refused, got %v", err)
+	}
+	if err := store.TransitionState(ctx, jobID, "testing", "ready"); err != nil {
+		t.Fatalf("testing->ready: %v", err)
+	}
+
+	reviewed, err := DiffChecksum(ctx, job, &cfg.Projects[0])
+	if err != nil {
+		t.Fatalf("checksum: %v", err)
+	}
+	// A late commit lands after the review.
+	if err := os.WriteFile(filepath.Join(wt, "a.txt"), []byte("late change\n"), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	_, err = Approve(ctx, cfg, store, jobID, Approval{ReviewedDiff: reviewed})
+	var changed *DiffChangedError
+	if !errors.As(err, &changed) || changed.Sum == reviewed {
+		t.Fatalf("expected a DiffChangedError with the new checksum, got %v", err)
+	}
+	job, err = store.GetJob(ctx, jobID)
+	if err != nil {
+		t.Fatalf("get job: %v", err)
+	}
+	if job.State != "ready" {
+		t.Fatalf("expected the job to stay ready, got %s", job.State)
+	}
+}
@@ -0,0 +1,122 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"autopr/internal/config"
+	"autopr/internal/db"
+	"autopr/internal/llm"
+)
+
+// attachmentText is a text file attached to an issue, read for the prompt.
+type attachmentText struct {
+	name    string
+	content string
+}
+
+// issueAttachments returns the attachments of issue for the plan prompt, or
+// "" when the project does not download them or there are none. They are
+// looked for again first, so files added after the job was queued are seen.
+// With images enabled, screenshots are copied into the job directory, where
+// the executor can read them, listed in the prompt, and attached to the
+// returned context for providers that take image files.
+func (r *Runner) issueAttachments(ctx context.Context, issue db.Issue, proj *config.ProjectConfig, workDir string) (context.Context, string) {
+	at := proj.Attachments
+	if at == nil {
+		return ctx, ""
+	}
+	if r.refreshIssueAttachments != nil {
+		if err := r.refreshIssueAttachments(ctx, issue); err != nil {
+			slog.Warn("refresh issue attachments", "issue", issue.Source+"#"+issue.SourceIssueID, "err", err)
+		}
+	}
+	stored, err := r.store.ListIssueAttachments(ctx, issue.AutoPRIssueID)
+	if err != nil {
+		slog.Warn("list issue attachments", "issue", issue.AutoPRIssueID, "err", err)
+		return ctx, ""
+	}
+	var (
+		texts  []attachmentText
+		images []string
+	)
+	for _, a := range stored {
+		if a.Path == "" {
+			continue
+		}
+		switch {
+		case a.Kind == db.AttachmentText:
+			data, err := os.ReadFile(a.Path)
+			if err != nil {
+				slog.Warn("read issue attachment", "path", a.Path, "err", err)
+				continue
+			}
+			texts = append(texts, attachmentText{name: a.Name, content: string(data)})
+		case a.Kind == db.AttachmentImage && at.Images:
+			dst := filepath.Join(filepath.Dir(workDir), "attachments", filepath.Base(a.Path))
+			if err := copyAttachment(a.Path, dst); err != nil {
+				slog.Warn("copy issue attachment", "path", a.Path, "err", err)
+				continue
+			}
+			images = append(images, dst)
+		}
+	}
+	if len(images) > 0 {
+		ctx = llm.WithImages(ctx, images)
+	}
+	return ctx, renderIssueAttachments(texts, images, at.MaxChars)
+}
+
+// renderIssueAttachments formats text files and image paths as an
+// <issue_attachments> block. Files share maxChars; a file that does not fit
+// keeps the whole lines at its end, where logs usually show the error.
+func renderIssueAttachments(texts []attachmentText, images []string, maxChars int) string {
+	if len(texts) == 0 && len(images) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("<issue_attachments>\n")
+	left := maxChars
+	for _, t := range texts {
+		content := SanitizeIssueContent(t.content)
+		if content == "" {
+			continue
+		}
+		if left <= 0 {
+			fmt.Fprintf(&b, "(%s left out: max_chars reached)\n\n", t.name)
+			continue
+		}
+		if len(content) > left {
+			cut := len(content) - left
+			if i := strings.IndexByte(content[cut:], '\n'); i >= 0 && i+1 < len(content)-cut {
+				cut += i + 1 // start at a whole line
+			}
+			content = fmt.Sprintf("... (%d earlier characters left out)\n%s", cut, strings.ToValidUTF8(content[cut:], ""))
+		}
+		left -= len(content)
+		fmt.Fprintf(&b, "--- %s ---\n%s\n--- end of %s ---\n\n", t.name, strings.TrimRight(content, "\n"), t.name)
+	}
+	if len(images) > 0 {
+		b.WriteString("Screenshots attached to the issue, saved as image files. Look at them before planning:\n")
+		for _, p := range images {
+			b.WriteString("- " + p + "\n")
+		}
+	}
+	return strings.TrimRight(b.String(), "\n") + "\n</issue_attachments>"
+}
+
+// copyAttachment copies the downloaded attachment src to dst.
+func copyAttachment(src, dst string) error {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(dst, data, 0o644)
+}
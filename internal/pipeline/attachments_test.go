@@ -0,0 +1,85 @@
+package pipeline
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"autopr/internal/config"
+	"autopr/internal/db"
+	"autopr/internal/llm"
+)
+
+func TestRenderIssueAttachmentsKeepsTheEndOfLongFiles(t *testing.T) {
+	t.Parallel()
+
+	texts := []attachmentText{
+		{name: "crash.log", content: "line 1\nline 2\npanic: nil map\n"},
+		{name: "env.txt", content: "GOOS=linux"},
+	}
+	got := renderIssueAttachments(texts, []string{"/jobs/x/attachments/02-shot.png"}, 20)
+	want := "<issue_attachments>\n" +
+		"--- crash.log ---\n... (14 earlier characters left out)\npanic: nil map\n--- end of crash.log ---\n\n" +
+		"(env.txt left out: max_chars reached)\n\n" +
+		"Screenshots attached to the issue, saved as image files. Look at them before planning:\n" +
+		"- /jobs/x/attachments/02-shot.png\n</issue_attachments>"
+	if got != want {
+		t.Fatalf("renderIssueAttachments =\n%s\nwant\n%s", got, want)
+	}
+	if got := renderIssueAttachments(nil, nil, 20); got != "" {
+		t.Fatalf("expected no block without attachments, got %q", got)
+	}
+}
+
+func TestRunPlanAddsIssueAttachments(t *testing.T) {
+	t.Parallel()
+
+	var gotPrompt string
+	provider := stubProvider{run: func(ctx context.Context, workDir, prompt string) (llm.Response, error) {
+		gotPrompt = prompt
+		return llm.Response{Text: "1. Guard the nil map"}, nil
+	}}
+	runner, store, issue, jobID := setupRunStepsJob(t, provider, "planning")
+	ctx := context.Background()
+	saved := t.TempDir()
+	logPath := filepath.Join(saved, "01-crash.log")
+	shotPath := filepath.Join(saved, "02-shot.png")
+	if err := os.WriteFile(logPath, []byte("panic: nil map\n"), 0o644); err != nil {
+		t.Fatalf("write log: %v", err)
+	}
+	if err := os.WriteFile(shotPath, []byte("\x89PNG\r\n\x1a\n"), 0o644); err != nil {
+		t.Fatalf("write screenshot: %v", err)
+	}
+	refreshed := false
+	runner.refreshIssueAttachments = func(ctx context.Context, issue db.Issue) error {
+		refreshed = true
+		for _, a := range []db.IssueAttachment{
+			{URL: "https://x/crash.log", Name: "crash.log", Kind: db.AttachmentText, Path: logPath},
+			{URL: "https://x/shot", Name: "shot", Kind: db.AttachmentImage, Path: shotPath},
+			{URL: "https://x/core.bin", Name: "core.bin", Error: "unsupported file type"},
+		} {
+			if err := store.UpsertIssueAttachment(ctx, issue.AutoPRIssueID, a); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	workDir := filepath.Join(t.TempDir(), "repo")
+	proj := &config.ProjectConfig{Name: issue.ProjectName, Attachments: &config.ProjectAttachments{MaxChars: 1000, Images: true}}
+
+	if err := runner.runPlan(ctx, jobID, issue, proj, workDir); err != nil {
+		t.Fatalf("run plan: %v", err)
+	}
+	copied := filepath.Join(filepath.Dir(workDir), "attachments", "02-shot.png")
+	if !refreshed || !strings.Contains(gotPrompt, "--- crash.log ---\npanic: nil map\n--- end of crash.log ---") || !strings.Contains(gotPrompt, "- "+copied) {
+		t.Fatalf("prompt missing the attachments:\n%s", gotPrompt)
+	}
+	if strings.Contains(gotPrompt, "core.bin") {
+		t.Fatalf("prompt lists an attachment that was not downloaded:\n%s", gotPrompt)
+	}
+	if _, err := os.Stat(copied); err != nil {
+		t.Fatalf("expected the screenshot copied into the job directory: %v", err)
+	}
+}
@@ -0,0 +1,97 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"autopr/internal/config"
+	"autopr/internal/db"
+	"autopr/internal/git"
+)
+
+// BackportLabelPrefix marks a merged PR for backporting: a
+// "backport/release-1.2" label requests a backport onto release-1.2.
+const BackportLabelPrefix = "backport/"
+
+// TargetBranch returns the branch a job is based on and opens its PR against:
+// the release branch for backport jobs, the branch the job was retargeted to,
+// otherwise the project base branch.
+func TargetBranch(job db.Job, proj *config.ProjectConfig) string {
+	if job.BackportBranch != "" {
+		return job.BackportBranch
+	}
+	if job.BaseBranch != "" {
+		return job.BaseBranch
+	}
+	return proj.BaseBranch
+}
+
+// BackportBranches returns the release branches requested by backport labels,
+// in label order and without duplicates.
+func BackportBranches(labels []string) []string {
+	var branches []string
+	seen := map[string]bool{}
+	for _, label := range labels {
+		branch, ok := strings.CutPrefix(strings.TrimSpace(label), BackportLabelPrefix)
+		branch = strings.TrimSpace(branch)
+		if !ok || branch == "" || seen[branch] {
+			continue
+		}
+		seen[branch] = true
+		branches = append(branches, branch)
+	}
+	return branches
+}
+
+// CreateBackportJobs queues a backport job for every backport label on a
+// merged parent job. Branches that already have a backport of the parent are
+// skipped, as are parents that are themselves backports. Returns the new job IDs.
+func CreateBackportJobs(ctx context.Context, store *db.Store, parent db.Job, labels []string, commit string) ([]string, error) {
+	branches := BackportBranches(labels)
+	if len(branches) == 0 || parent.BackportBranch != "" {
+		return nil, nil
+	}
+	if commit == "" {
+		return nil, fmt.Errorf("merge commit unknown for job %s", db.ShortID(parent.ID))
+	}
+
+	var ids []string
+	for _, branch := range branches {
+		exists, err := store.HasBackportJob(ctx, parent.ID, branch)
+		if err != nil {
+			return ids, err
+		}
+		if exists {
+			continue
+		}
+		id, err := store.CreateBackportJob(ctx, parent, branch, commit)
+		if err != nil {
+			if errors.Is(err, db.ErrDuplicateActiveJob) {
+				continue
+			}
+			return ids, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// runBackport cherry-picks the parent's merged commit onto the release branch
+// in place of the plan/implement/review loop.
+func (r *Runner) runBackport(ctx context.Context, jobID string, issue db.Issue, projectCfg *config.ProjectConfig, workDir string) error {
+	job, err := r.store.GetJob(ctx, jobID)
+	if err != nil {
+		return err
+	}
+	return r.runCommitJob(ctx, job, issue, projectCfg, workDir, commitOp{
+		verb:       "cherry-pick",
+		commit:     job.BackportCommit,
+		branch:     job.BackportBranch,
+		apply:      git.CherryPick,
+		cont:       git.CherryPickContinue,
+		inProgress: git.IsCherryPickInProgress,
+		abort:      git.CherryPickAbort,
+	})
+}
@@ -0,0 +1,141 @@
+package pipeline
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"autopr/internal/config"
+	"autopr/internal/db"
+)
+
+func TestBackportBranches(t *testing.T) {
+	t.Parallel()
+	got := BackportBranches([]string{"bug", "backport/release-1.2", " backport/release-1.3 ", "backport/", "backport/release-1.2"})
+	want := []string{"release-1.2", "release-1.3"}
+	if !slices.Equal(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+}
+
+func TestRunBackportCherryPicksMergedCommitOntoReleaseBranch(t *testing.T) {
+	t.Setenv("GIT_AUTHOR_NAME", "Test User")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "Test User")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+
+	ctx := context.Background()
+	tmp := t.TempDir()
+
+	store, err := db.Open(filepath.Join(tmp, "autopr.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer store.Close()
+
+	remote := createBareRemoteWithMain(t, tmp)
+	seed := filepath.Join(tmp, "seed")
+	runGitCmdLocal(t, seed, "push", "origin", "main:release-1.2")
+	if err := os.WriteFile(filepath.Join(seed, "README.md"), []byte("hello, fixed\n"), 0o644); err != nil {
+		t.Fatalf("write fix: %v", err)
+	}
+	runGitCmdLocal(t, seed, "commit", "-am", "fix greeting")
+	runGitCmdLocal(t, seed, "push", "origin", "main")
+	mergeCommit, err := runGitCommandOutput(t, seed, "rev-parse", "HEAD")
+	if err != nil {
+		t.Fatalf("rev-parse: %v", err)
+	}
+	mergeCommit = strings.TrimSpace(mergeCommit)
+
+	cfg := &config.Config{
+		ReposRoot: filepath.Join(tmp, "repos"),
+		Projects: []config.ProjectConfig{{
+			Name:       "myproject",
+			RepoURL:    remote,
+			BaseBranch: "main",
+			TestCmd:    "true",
+		}},
+	}
+
+	issueID, err := store.UpsertIssue(ctx, db.IssueUpsert{
+		ProjectName:   "myproject",
+		Source:        "github",
+		SourceIssueID: "7",
+		Title:         "fix greeting",
+		URL:           "https://github.com/acme/repo/issues/7",
+		State:         "closed",
+	})
+	if err != nil {
+		t.Fatalf("upsert issue: %v", err)
+	}
+	parentID, err := store.CreateJob(ctx, issueID, "myproject", 3)
+	if err != nil {
+		t.Fatalf("create parent: %v", err)
+	}
+	if _, err := store.Writer.ExecContext(ctx, `UPDATE jobs SET state = 'approved', pr_url = ?, pr_merged_at = ? WHERE id = ?`,
+		"https://github.com/acme/repo/pull/8", "2026-01-01T00:00:00Z", parentID); err != nil {
+		t.Fatalf("approve parent: %v", err)
+	}
+	parent, err := store.GetJob(ctx, parentID)
+	if err != nil {
+		t.Fatalf("get parent: %v", err)
+	}
+
+	ids, err := CreateBackportJobs(ctx, store, parent, []string{"backport/release-1.2"}, mergeCommit)
+	if err != nil || len(ids) != 1 {
+		t.Fatalf("create backport jobs: ids=%v err=%v", ids, err)
+	}
+	if again, err := CreateBackportJobs(ctx, store, parent, []string{"backport/release-1.2"}, mergeCommit); err != nil || len(again) != 0 {
+		t.Fatalf("expected existing backport to be skipped, ids=%v err=%v", again, err)
+	}
+	jobID := ids[0]
+	if claimed, err := store.ClaimJob(ctx); err != nil || claimed != jobID {
+		t.Fatalf("claim backport job: %q err=%v", claimed, err)
+	}
+
+	runner := New(store, nil, cfg)
+	if err := runner.Run(ctx, jobID); err != nil {
+		t.Fatalf("run backport: %v", err)
+	}
+
+	job, err := store.GetJob(ctx, jobID)
+	if err != nil {
+		t.Fatalf("get job: %v", err)
+	}
+	if job.State != "ready" {
+		t.Fatalf("expected ready backport job, got %q (%s)", job.State, job.ErrorMessage)
+	}
+	got, err := os.ReadFile(filepath.Join(job.WorktreePath, "README.md"))
+	if err != nil {
+		t.Fatalf("read worktree file: %v", err)
+	}
+	if string(got) != "hello, fixed\n" {
+		t.Fatalf("expected cherry-picked change, got %q", got)
+	}
+	base, err := runGitCommandOutput(t, job.WorktreePath, "merge-base", "HEAD", "origin/release-1.2")
+	if err != nil {
+		t.Fatalf("merge-base: %v", err)
+	}
+	release, _ := runGitCommandOutput(t, job.WorktreePath, "rev-parse", "origin/release-1.2")
+	if strings.TrimSpace(base) != strings.TrimSpace(release) {
+		t.Fatalf("expected backport branch to be based on release-1.2")
+	}
+	if job.CommitSHA == "" || job.CommitSHA == mergeCommit {
+		t.Fatalf("expected new commit sha on release branch, got %q", job.CommitSHA)
+	}
+
+	issue, err := store.GetIssueByAPID(ctx, issueID)
+	if err != nil {
+		t.Fatalf("get issue: %v", err)
+	}
+	title, body := BuildPRContent(ctx, store, nil, job, issue)
+	if title != "[AutoPR] [release-1.2] fix greeting" {
+		t.Fatalf("unexpected PR title %q", title)
+	}
+	if strings.Contains(body, "Closes ") || !strings.Contains(body, "Backport of https://github.com/acme/repo/pull/8 to `release-1.2`") {
+		t.Fatalf("unexpected PR body:\n%s", body)
+	}
+}
@@ -0,0 +1,171 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"autopr/internal/config"
+	"autopr/internal/db"
+	"autopr/internal/git"
+)
+
+// BisectSource is the issue source used for synthetic bisect issues.
+const BisectSource = "bisect"
+
+const bisectResultArtifactKind = "bisect_result"
+
+// maxBisectLogBytes caps the bisect log stored in the result artifact.
+const maxBisectLogBytes = 100000
+
+// BisectRequest describes a regression to bisect.
+type BisectRequest struct {
+	Good    string // commit where TestCmd passes
+	Bad     string // commit where TestCmd fails
+	TestCmd string // run at each step; exit 0 = good, 125 = skip, otherwise bad
+	Fix     bool   // queue a fix job referencing the culprit
+}
+
+// CreateBisectJob queues a job that bisects req.Good..req.Bad in proj. A
+// synthetic issue (source "bisect") describes the regression so the optional
+// fix job has something to plan against.
+func CreateBisectJob(ctx context.Context, store *db.Store, proj *config.ProjectConfig, maxIterations int, req BisectRequest) (string, error) {
+	req.Good = strings.TrimSpace(req.Good)
+	req.Bad = strings.TrimSpace(req.Bad)
+	req.TestCmd = strings.TrimSpace(req.TestCmd)
+	if req.Good == "" || req.Bad == "" {
+		return "", fmt.Errorf("good and bad commits are required")
+	}
+	if req.TestCmd == config.AutoDetect {
+		ps, _, err := store.GetProjectStack(ctx, proj.Name)
+		if err != nil {
+			return "", err
+		}
+		if ps.TestCmd == "" {
+			return "", fmt.Errorf("test_cmd is %q and no test command was detected yet; pass one explicitly", config.AutoDetect)
+		}
+		req.TestCmd = ps.TestCmd
+	}
+	if req.TestCmd == "" {
+		return "", fmt.Errorf("test command is required")
+	}
+	if _, err := bisectCommandArgs(req.TestCmd); err != nil {
+		return "", err
+	}
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	issueID, err := store.UpsertIssue(ctx, db.IssueUpsert{
+		ProjectName:   proj.Name,
+		Source:        BisectSource,
+		SourceIssueID: req.Good + ".." + req.Bad,
+		Title:         fmt.Sprintf("Regression: %s fails at %s", req.TestCmd, req.Bad),
+		Body: fmt.Sprintf("`%s` passes at %s and fails at %s on %s.",
+			req.TestCmd, req.Good, req.Bad, proj.BaseBranch),
+		State:         "open",
+		SourceUpdated: now,
+	})
+	if err != nil {
+		return "", err
+	}
+	return store.CreateBisectJob(ctx, issueID, proj.Name, maxIterations, req.Good, req.Bad, req.TestCmd, req.Fix)
+}
+
+func bisectCommandArgs(testCmd string) ([]string, error) {
+	args, err := parseTestCommand(testCmd)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateTestCommandArgs(args); err != nil {
+		return nil, err
+	}
+	return args, nil
+}
+
+// runBisect runs git bisect in place of the plan/implement/review loop. The
+// culprit is recorded as a bisect_result artifact and the job finishes as
+// approved; there is no diff to review.
+func (r *Runner) runBisect(ctx context.Context, jobID string, issue db.Issue, projectCfg *config.ProjectConfig, workDir string) error {
+	job, err := r.store.GetJob(ctx, jobID)
+	if err != nil {
+		return err
+	}
+	if job.State != "planning" {
+		return nil
+	}
+	if err := r.store.TransitionState(ctx, jobID, "planning", "testing"); err != nil {
+		if r.isJobCancelledError(ctx, jobID, err) {
+			return errJobCancelled
+		}
+		return err
+	}
+
+	args, err := bisectCommandArgs(job.BisectCmd)
+	if err != nil {
+		return r.failJob(ctx, jobID, "testing", err.Error())
+	}
+	token := GitTokenForProject(ctx, r.cfg, projectCfg)
+	if err := git.FetchBranch(ctx, workDir, projectCfg.BaseBranch, token); err != nil {
+		return r.failJob(ctx, jobID, "testing", fmt.Sprintf("fetch %s: %s", projectCfg.BaseBranch, err))
+	}
+
+	slog.Info("bisecting", "job", db.ShortID(jobID), "good", job.BisectGood, "bad", job.BisectBad)
+	culprit, bisectLog, err := git.Bisect(ctx, workDir, job.BisectGood, job.BisectBad, args)
+	if err != nil {
+		if r.isJobCancelledError(ctx, jobID, err) {
+			return errJobCancelled
+		}
+		if _, aerr := r.store.CreateArtifact(ctx, jobID, issue.AutoPRIssueID, "test_output", truncateBisectLog(bisectLog), job.Iteration, ""); aerr != nil {
+			slog.Warn("failed to store bisect log artifact", "job", jobID, "err", aerr)
+		}
+		return r.failJob(ctx, jobID, "testing", err.Error())
+	}
+
+	content := fmt.Sprintf("First bad commit: %s\nRange: %s..%s\nCommand: %s\n\n%s",
+		culprit, job.BisectGood, job.BisectBad, job.BisectCmd, truncateBisectLog(bisectLog))
+	if _, err := r.store.CreateArtifact(ctx, jobID, issue.AutoPRIssueID, bisectResultArtifactKind, content, job.Iteration, culprit); err != nil {
+		return r.failJob(ctx, jobID, "testing", "store bisect result: "+err.Error())
+	}
+	if err := r.store.TransitionState(ctx, jobID, "testing", "approved"); err != nil {
+		if r.isJobCancelledError(ctx, jobID, err) {
+			return errJobCancelled
+		}
+		return err
+	}
+	slog.Info("bisect found culprit", "job", db.ShortID(jobID), "commit", culprit)
+
+	if job.BisectFix {
+		diff, _ := git.DiffCommits(ctx, workDir, culprit+"^", culprit)
+		if len(diff) > maxFollowUpDiffBytes {
+			diff = diff[:maxFollowUpDiffBytes] + "\n... (diff truncated)\n"
+		}
+		fixID, err := r.store.CreateFollowUpJob(ctx, job, buildBisectFixNotes(job, culprit, diff))
+		if err != nil {
+			slog.Error("failed to queue fix job for bisect", "job", db.ShortID(jobID), "err", err)
+			return nil
+		}
+		slog.Info("queued fix job for bisect", "job", db.ShortID(jobID), "fix_job", db.ShortID(fixID))
+	}
+	return nil
+}
+
+func buildBisectFixNotes(job db.Job, culprit, diff string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Bisect job %s found that commit %s is the first bad commit: ", db.ShortID(job.ID), culprit)
+	fmt.Fprintf(&b, "`%s` passes at %s and fails from %s onwards. ", job.BisectCmd, job.BisectGood, culprit)
+	b.WriteString("Fix the regression it introduced so the command passes again, keeping the commit's intended behavior where possible.\n")
+	if diff != "" {
+		b.WriteString("\nChanges in the culprit commit:\n\n```diff\n")
+		b.WriteString(strings.TrimRight(diff, "\n"))
+		b.WriteString("\n```\n")
+	}
+	return b.String()
+}
+
+func truncateBisectLog(s string) string {
+	if len(s) > maxBisectLogBytes {
+		return s[:maxBisectLogBytes] + "\n... (truncated)"
+	}
+	return s
+}
@@ -0,0 +1,109 @@
+package pipeline
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"autopr/internal/config"
+	"autopr/internal/db"
+)
+
+func TestRunBisectRecordsCulpritAndQueuesFixJob(t *testing.T) {
+	t.Setenv("GIT_AUTHOR_NAME", "Test User")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "Test User")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+
+	ctx := context.Background()
+	tmp := t.TempDir()
+
+	store, err := db.Open(filepath.Join(tmp, "autopr.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer store.Close()
+
+	remote := createBareRemoteWithMain(t, tmp)
+	seed := filepath.Join(tmp, "seed")
+	good, err := runGitCommandOutput(t, seed, "rev-parse", "HEAD")
+	if err != nil {
+		t.Fatalf("rev-parse: %v", err)
+	}
+	var culprit string
+	for i, content := range []string{"hello\nmore\n", "goodbye\n", "goodbye\nagain\n"} {
+		if err := os.WriteFile(filepath.Join(seed, "README.md"), []byte(content), 0o644); err != nil {
+			t.Fatalf("write change: %v", err)
+		}
+		runGitCmdLocal(t, seed, "commit", "-am", "change "+content[:4])
+		if i == 1 {
+			culprit, err = runGitCommandOutput(t, seed, "rev-parse", "HEAD")
+			if err != nil {
+				t.Fatalf("rev-parse: %v", err)
+			}
+		}
+	}
+	runGitCmdLocal(t, seed, "push", "origin", "main")
+	culprit = strings.TrimSpace(culprit)
+
+	cfg := &config.Config{
+		ReposRoot: filepath.Join(tmp, "repos"),
+		Projects: []config.ProjectConfig{{
+			Name:       "myproject",
+			RepoURL:    remote,
+			BaseBranch: "main",
+			TestCmd:    "true",
+		}},
+	}
+	proj := &cfg.Projects[0]
+
+	if _, err := CreateBisectJob(ctx, store, proj, 3, BisectRequest{Good: "a", Bad: "b"}); err == nil {
+		t.Fatalf("expected missing test command to be rejected")
+	}
+	jobID, err := CreateBisectJob(ctx, store, proj, 3, BisectRequest{
+		Good:    strings.TrimSpace(good),
+		Bad:     "main",
+		TestCmd: "grep -q hello README.md",
+		Fix:     true,
+	})
+	if err != nil {
+		t.Fatalf("create bisect job: %v", err)
+	}
+	if claimed, err := store.ClaimJob(ctx); err != nil || claimed != jobID {
+		t.Fatalf("claim bisect job: %q err=%v", claimed, err)
+	}
+
+	runner := New(store, nil, cfg)
+	if err := runner.Run(ctx, jobID); err != nil {
+		t.Fatalf("run bisect: %v", err)
+	}
+
+	job, err := store.GetJob(ctx, jobID)
+	if err != nil {
+		t.Fatalf("get job: %v", err)
+	}
+	if job.State != "approved" {
+		t.Fatalf("expected bisect job to finish as approved, got %q (%s)", job.State, job.ErrorMessage)
+	}
+	result, err := store.GetLatestArtifact(ctx, jobID, bisectResultArtifactKind)
+	if err != nil {
+		t.Fatalf("get bisect result: %v", err)
+	}
+	if result.CommitSHA != culprit || !strings.Contains(result.Content, "First bad commit: "+culprit) {
+		t.Fatalf("unexpected bisect result %q:\n%s", result.CommitSHA, result.Content)
+	}
+
+	fixID, err := store.GetActiveJobForIssue(ctx, job.AutoPRIssueID)
+	if err != nil || fixID == "" {
+		t.Fatalf("expected a queued fix job, got %q err=%v", fixID, err)
+	}
+	fix, err := store.GetJob(ctx, fixID)
+	if err != nil {
+		t.Fatalf("get fix job: %v", err)
+	}
+	if fix.ParentJobID != jobID || fix.BisectCmd != "" || !strings.Contains(fix.HumanNotes, culprit) || !strings.Contains(fix.HumanNotes, "-hello") {
+		t.Fatalf("unexpected fix job: parent=%q cmd=%q notes:\n%s", fix.ParentJobID, fix.BisectCmd, fix.HumanNotes)
+	}
+}
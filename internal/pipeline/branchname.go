@@ -0,0 +1,96 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/user"
+	"strings"
+
+	"autopr/internal/config"
+	"autopr/internal/db"
+)
+
+// maxTemplateSlugLen caps the {slug} of a branch_template; the rest of the
+// name is up to the template.
+const maxTemplateSlugLen = 40
+
+// maxBranchSuffix bounds the "-N" suffixes tried for a free branch name.
+const maxBranchSuffix = 100
+
+// jobBranchName picks the branch of a new job clone. Without a
+// branch_template it is buildBranchName's, which ends in the job ID. A
+// template that leaves out {job-id} may produce a branch another job or a
+// person already uses, so "-2", "-3", ... is appended until the name is free
+// among the project's jobs and on the remote.
+func (r *Runner) jobBranchName(ctx context.Context, proj *config.ProjectConfig, issue db.Issue, jobID, token string) (string, error) {
+	if proj.BranchTemplate == "" {
+		return buildBranchName(issue, jobID), nil
+	}
+	name := renderBranchTemplate(proj.BranchTemplate, proj.Name, issue, jobID, branchUser())
+	if strings.Contains(proj.BranchTemplate, "{job-id}") {
+		return name, nil
+	}
+	for n := 1; n <= maxBranchSuffix; n++ {
+		candidate := name
+		if n > 1 {
+			candidate = fmt.Sprintf("%s-%d", name, n)
+		}
+		taken, err := r.store.BranchNameInUse(ctx, proj.Name, candidate)
+		if err != nil {
+			return "", err
+		}
+		if !taken && r.remoteBranchExists != nil {
+			exists, err := r.remoteBranchExists(ctx, proj.RepoURL, token, candidate)
+			if err != nil {
+				slog.Warn("check remote branch", "project", proj.Name, "branch", candidate, "err", err)
+			}
+			taken = exists
+		}
+		if !taken {
+			return candidate, nil
+		}
+	}
+	return name + "-" + db.ShortID(jobID), nil
+}
+
+// renderBranchTemplate fills in a branch_template. Placeholder values are
+// slugified, and separators left around empty values are tidied up.
+func renderBranchTemplate(tmpl, project string, issue db.Issue, jobID, login string) string {
+	slug := slugify(issue.Title)
+	if len(slug) > maxTemplateSlugLen {
+		slug = strings.TrimRight(slug[:maxTemplateSlugLen], "-")
+	}
+	name := strings.NewReplacer(
+		"{project}", slugify(project),
+		"{source}", slugify(issue.Source),
+		"{issue-id}", slugify(issue.SourceIssueID),
+		"{slug}", slug,
+		"{job-id}", db.ShortID(jobID),
+		"{user}", slugify(login),
+	).Replace(tmpl)
+	for {
+		tidied := name
+		for _, sep := range [][2]string{{"//", "/"}, {"--", "-"}, {"-/", "/"}, {"/-", "/"}} {
+			tidied = strings.ReplaceAll(tidied, sep[0], sep[1])
+		}
+		if tidied == name {
+			break
+		}
+		name = tidied
+	}
+	return strings.Trim(name, "-/")
+}
+
+// branchUser returns the login of the user running AutoPR, for {user}.
+func branchUser() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		// Windows logins are DOMAIN\user.
+		return u.Username[strings.LastIndex(u.Username, `\`)+1:]
+	}
+	if name := os.Getenv("USER"); name != "" {
+		return name
+	}
+	return os.Getenv("USERNAME")
+}
@@ -0,0 +1,68 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+
+	"autopr/internal/config"
+	"autopr/internal/db"
+)
+
+func TestRenderBranchTemplate(t *testing.T) {
+	t.Parallel()
+
+	issue := db.Issue{Source: "gitlab", SourceIssueID: "PROJ-42", Title: "Fix: login times out after 30s on Safari"}
+	for tmpl, want := range map[string]string{
+		"{user}/autopr/{issue-id}-{slug}":       "alice/autopr/proj-42-fix-login-times-out-after-30s-on-safari",
+		"bot/{project}/{source}-{job-id}":       "bot/web-app/gitlab-8aeda806",
+		"fix/{issue-id}-{slug}":                 "fix/proj-42-fix-login-times-out-after-30s-on-safari",
+		"{user}/{source}/{issue-id}/{slug}-wip": "alice/gitlab/proj-42/fix-login-times-out-after-30s-on-safari-wip",
+	} {
+		if got := renderBranchTemplate(tmpl, "Web App", issue, "ap-job-8aeda806ffff0000", "alice"); got != want {
+			t.Errorf("render(%q) = %q, want %q", tmpl, got, want)
+		}
+	}
+
+	// Empty values do not leave doubled or dangling separators.
+	if got := renderBranchTemplate("{user}/autopr/{issue-id}-{slug}", "p", db.Issue{}, "ap-job-1", ""); got != "autopr" {
+		t.Errorf("render with empty values = %q, want autopr", got)
+	}
+}
+
+func TestJobBranchNameAvoidsTakenBranches(t *testing.T) {
+	r, store, jobID := setupInvokeProviderTest(t, stubProvider{})
+	ctx := context.Background()
+	job, err := store.GetJob(ctx, jobID)
+	if err != nil {
+		t.Fatalf("get job: %v", err)
+	}
+	issue, err := store.GetIssueByAPID(ctx, job.AutoPRIssueID)
+	if err != nil {
+		t.Fatalf("get issue: %v", err)
+	}
+	proj := &config.ProjectConfig{Name: "myproject", RepoURL: "https://example.com/repo.git", BranchTemplate: "fix/{issue-id}"}
+
+	// Another job of the project holds fix/1, and fix/1-2 exists on the remote.
+	if err := store.UpdateJobField(ctx, jobID, "branch_name", "fix/"+issue.SourceIssueID); err != nil {
+		t.Fatalf("set branch: %v", err)
+	}
+	var checked []string
+	r.remoteBranchExists = func(_ context.Context, _, _, branch string) (bool, error) {
+		checked = append(checked, branch)
+		return branch == "fix/"+issue.SourceIssueID+"-2", nil
+	}
+	got, err := r.jobBranchName(ctx, proj, issue, "ap-job-other", "")
+	if err != nil {
+		t.Fatalf("branch name: %v", err)
+	}
+	if want := "fix/" + issue.SourceIssueID + "-3"; got != want {
+		t.Fatalf("branch = %q, want %q (remote checked %v)", got, want, checked)
+	}
+
+	// With {job-id} the name is unique and nothing is checked.
+	proj.BranchTemplate = "fix/{issue-id}-{job-id}"
+	checked = nil
+	if got, err := r.jobBranchName(ctx, proj, issue, jobID, ""); err != nil || got != "fix/"+issue.SourceIssueID+"-"+db.ShortID(jobID) || len(checked) != 0 {
+		t.Fatalf("branch = %q, %v; remote checked %v", got, err, checked)
+	}
+}
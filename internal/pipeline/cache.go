@@ -0,0 +1,177 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+
+	"autopr/internal/config"
+	"autopr/internal/git"
+)
+
+// cacheLockPoll is how often a command waiting for a cache checks its lock.
+const cacheLockPoll = 200 * time.Millisecond
+
+// cacheLease holds a project's cache locks while test commands use them.
+type cacheLease struct {
+	env   []string // NAME=dir for caches with env set
+	locks []*os.File
+}
+
+// acquireCaches prepares the project's shared caches for commands run in
+// workDir: it creates each cache directory, locks it (shared, or exclusive
+// when configured), and symlinks it into the test directory when it has a
+// link. A link that cannot be made is logged and skipped. Locks are taken in
+// config order, so jobs of the same project cannot deadlock on them.
+func acquireCaches(ctx context.Context, cfg *config.Config, projectCfg *config.ProjectConfig, workDir string) (*cacheLease, error) {
+	lease := &cacheLease{}
+	for _, c := range projectCfg.Caches {
+		cacheDir := cfg.CacheDir(projectCfg.Name, c.Name)
+		if err := os.MkdirAll(cacheDir, 0o755); err != nil {
+			lease.release()
+			return nil, fmt.Errorf("create cache %s: %w", c.Name, err)
+		}
+		lock, err := lockCache(ctx, cacheDir, c.Exclusive)
+		if err != nil {
+			lease.release()
+			return nil, fmt.Errorf("lock cache %s: %w", c.Name, err)
+		}
+		lease.locks = append(lease.locks, lock)
+		if c.Env != "" {
+			lease.env = append(lease.env, c.Env+"="+cacheDir)
+		}
+		if c.Link != "" {
+			rel := path.Join(projectCfg.ScopePath(), c.Link)
+			if err := linkCache(ctx, workDir, rel, cacheDir); err != nil {
+				slog.Warn("cache not linked", "cache", c.Name, "link", rel, "err", err)
+			}
+		}
+	}
+	return lease, nil
+}
+
+// release unlocks the caches. It is safe on a nil lease.
+func (l *cacheLease) release() {
+	if l == nil {
+		return
+	}
+	for _, f := range l.locks {
+		f.Close() // closing the file drops its lock
+	}
+	l.locks = nil
+}
+
+// linkCache symlinks the repository-relative path rel in workDir to cacheDir
+// and hides it from git. An existing link is repointed; a real file or
+// directory there is left alone.
+func linkCache(ctx context.Context, workDir, rel, cacheDir string) error {
+	linkPath := filepath.Join(workDir, filepath.FromSlash(rel))
+	if target, err := os.Readlink(linkPath); err == nil {
+		if target == cacheDir {
+			return nil
+		}
+		if err := os.Remove(linkPath); err != nil {
+			return fmt.Errorf("remove stale link: %w", err)
+		}
+	} else if _, err := os.Lstat(linkPath); err == nil {
+		return fmt.Errorf("%s already exists in the worktree", rel)
+	}
+	if err := git.ExcludeLocally(ctx, workDir, "/"+rel); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(linkPath), 0o755); err != nil {
+		return fmt.Errorf("create link parent: %w", err)
+	}
+	if err := os.Symlink(cacheDir, linkPath); err != nil {
+		return fmt.Errorf("create link: %w", err)
+	}
+	return nil
+}
+
+// lockCache opens cacheDir's lock file and waits for the lock until ctx ends.
+func lockCache(ctx context.Context, cacheDir string, exclusive bool) (*os.File, error) {
+	f, err := os.OpenFile(cacheDir+".lock", os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	waiting := false
+	for {
+		ok, err := tryLockFile(f, exclusive)
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		if ok {
+			return f, nil
+		}
+		if !waiting {
+			slog.Info("waiting for cache lock", "cache", cacheDir)
+			waiting = true
+		}
+		select {
+		case <-ctx.Done():
+			f.Close()
+			return nil, ctx.Err()
+		case <-time.After(cacheLockPoll):
+		}
+	}
+}
+
+// ClearCache empties a project's named cache. It waits until no command
+// holds the cache, and keeps the lock while it deletes.
+func ClearCache(ctx context.Context, cfg *config.Config, projectName, cacheName string) error {
+	cacheDir := cfg.CacheDir(projectName, cacheName)
+	if _, err := os.Stat(cacheDir); errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	lock, err := lockCache(ctx, cacheDir, true)
+	if err != nil {
+		return fmt.Errorf("lock cache %s: %w", cacheName, err)
+	}
+	defer lock.Close()
+
+	// Some tools (the Go module cache) write read-only directories, which
+	// RemoveAll cannot empty until they are writable again.
+	_ = filepath.WalkDir(cacheDir, func(p string, d fs.DirEntry, err error) error {
+		if err == nil && d.IsDir() {
+			_ = os.Chmod(p, 0o755)
+		}
+		return nil
+	})
+	if err := os.RemoveAll(cacheDir); err != nil {
+		return fmt.Errorf("clear cache %s: %w", cacheName, err)
+	}
+	return nil
+}
+
+// CacheSize returns the bytes used by a project's named cache, or 0 when it
+// does not exist yet.
+func CacheSize(cfg *config.Config, projectName, cacheName string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(cfg.CacheDir(projectName, cacheName), func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if d.Type().IsRegular() {
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			size += info.Size()
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("measure cache %s: %w", cacheName, err)
+	}
+	return size, nil
+}
@@ -0,0 +1,116 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"autopr/internal/config"
+)
+
+func TestRunTestsUsesSharedCaches(t *testing.T) {
+	t.Parallel()
+
+	runner, _, issue, jobID := setupRunStepsJob(t, nil, "testing")
+	runner.cfg = &config.Config{ReposRoot: t.TempDir()}
+	ctx := context.Background()
+	remote, workDir := cloneWithChanges(t, nil)
+
+	script := "#!/bin/sh\nset -e\necho env > \"$AUTOPR_TEST_CACHE/seen\"\necho link > deps/seen\n"
+	if err := os.WriteFile(filepath.Join(workDir, "check.sh"), []byte(script), 0o755); err != nil {
+		t.Fatalf("write script: %v", err)
+	}
+	projectCfg := &config.ProjectConfig{
+		Name:       "myproject",
+		RepoURL:    remote,
+		BaseBranch: "main",
+		TestCmd:    "./check.sh",
+		Caches: []config.ProjectCache{
+			{Name: "build", Env: "AUTOPR_TEST_CACHE"},
+			{Name: "deps", Link: "deps", Exclusive: true},
+		},
+	}
+
+	for run := range 2 {
+		if err := runner.runTests(ctx, jobID, issue, projectCfg, workDir); err != nil {
+			t.Fatalf("run %d: run tests: %v", run, err)
+		}
+	}
+	for _, cache := range []string{"build", "deps"} {
+		if _, err := os.Stat(filepath.Join(runner.cfg.CacheDir("myproject", cache), "seen")); err != nil {
+			t.Fatalf("cache %s not written: %v", cache, err)
+		}
+	}
+	status, err := exec.Command("git", "-C", workDir, "status", "--porcelain").CombinedOutput()
+	if err != nil || strings.Contains(string(status), "deps") {
+		t.Fatalf("expected the cache link to be ignored by git, status:\n%s", status)
+	}
+
+	// Held exclusively by another command, the cache makes the step wait.
+	lock, err := lockCache(ctx, runner.cfg.CacheDir("myproject", "deps"), true)
+	if err != nil {
+		t.Fatalf("lock cache: %v", err)
+	}
+	defer lock.Close()
+	waitCtx, cancel := context.WithTimeout(ctx, 300*time.Millisecond)
+	defer cancel()
+	if err := runner.runTests(waitCtx, jobID, issue, projectCfg, workDir); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the step to wait for the cache lock, got %v", err)
+	}
+}
+
+func TestCacheLocksAndClear(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	cfg := &config.Config{ReposRoot: t.TempDir()}
+	cacheDir := cfg.CacheDir("myproject", "gomod")
+	readOnly := filepath.Join(cacheDir, "mod", "example.com@v1.0.0")
+	if err := os.MkdirAll(readOnly, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(readOnly, "go.mod"), []byte("module example.com\n"), 0o444); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if err := os.Chmod(readOnly, 0o555); err != nil {
+		t.Fatalf("chmod: %v", err)
+	}
+	if size, err := CacheSize(cfg, "myproject", "gomod"); err != nil || size != int64(len("module example.com\n")) {
+		t.Fatalf("CacheSize = %d, %v", size, err)
+	}
+
+	// Shared locks coexist; clearing waits for them.
+	first, err := lockCache(ctx, cacheDir, false)
+	if err != nil {
+		t.Fatalf("first shared lock: %v", err)
+	}
+	second, err := lockCache(ctx, cacheDir, false)
+	if err != nil {
+		t.Fatalf("second shared lock: %v", err)
+	}
+	waitCtx, cancel := context.WithTimeout(ctx, 300*time.Millisecond)
+	defer cancel()
+	if err := ClearCache(waitCtx, cfg, "myproject", "gomod"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected clear to wait for shared locks, got %v", err)
+	}
+	first.Close()
+	second.Close()
+
+	if err := ClearCache(ctx, cfg, "myproject", "gomod"); err != nil {
+		t.Fatalf("clear cache: %v", err)
+	}
+	if _, err := os.Stat(cacheDir); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected cache removed, stat err=%v", err)
+	}
+	if size, err := CacheSize(cfg, "myproject", "gomod"); err != nil || size != 0 {
+		t.Fatalf("CacheSize after clear = %d, %v", size, err)
+	}
+	if err := ClearCache(ctx, cfg, "myproject", "missing"); err != nil {
+		t.Fatalf("clearing a missing cache: %v", err)
+	}
+}
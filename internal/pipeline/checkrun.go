@@ -0,0 +1,187 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"autopr/internal/config"
+	"autopr/internal/db"
+	"autopr/internal/git"
+	"autopr/internal/githubapp"
+)
+
+const (
+	checkPassed  = "✓"
+	checkFailed  = "✗"
+	checkSkipped = "–"
+
+	checkTestLines = 30
+	checkTestLimit = 4000
+)
+
+// checkStep is one row of the pipeline check: a step, its mark, and a short
+// note.
+type checkStep struct {
+	name   string
+	mark   string
+	detail string
+}
+
+// pipelineCheck is what ReportPipelineCheck posts on the PR's head commit.
+type pipelineCheck struct {
+	conclusion string // success, failure, or neutral
+	title      string
+	summary    string
+}
+
+// ReportPipelineCheck posts AutoPR's own results for a job's final changes
+// (plan, implement, review, tests) on the pushed branch's head commit, so
+// reviewers see them in the PR. GitHub App projects get a check run; personal
+// tokens cannot create check runs, so other projects get a commit status.
+// It does nothing unless the project sets report_checks, and failures are
+// logged rather than returned: the report is advisory.
+func ReportPipelineCheck(ctx context.Context, cfg *config.Config, store *db.Store, proj *config.ProjectConfig, job db.Job) {
+	if proj == nil || proj.GitHub == nil || !proj.GitHub.ReportChecks {
+		return
+	}
+	sha, err := git.LatestCommit(ctx, job.WorktreePath)
+	if err != nil {
+		slog.Warn("pipeline check: resolve head commit", "job", job.ID, "err", err)
+		return
+	}
+	artifacts, err := store.ListArtifactsByJob(ctx, job.ID)
+	if err != nil {
+		slog.Warn("pipeline check: load artifacts", "job", job.ID, "err", err)
+		return
+	}
+	testsResult, err := store.JobTestsResult(ctx, job.ID)
+	if err != nil {
+		slog.Warn("pipeline check: load tests result", "job", job.ID, "err", err)
+		return
+	}
+	check := buildPipelineCheck(job, artifacts, testsResult)
+
+	token, err := githubapp.Token(ctx, cfg, proj)
+	if err != nil || token == "" {
+		slog.Warn("pipeline check: no github token", "job", job.ID, "err", err)
+		return
+	}
+	gh := proj.GitHub
+	if gh.UsesApp() {
+		err = git.CreateGitHubCheckRun(ctx, token, gh.BaseURL, gh.Owner, gh.Repo, git.CheckRunReport{
+			HeadSHA:    sha,
+			Conclusion: check.conclusion,
+			Title:      check.title,
+			Summary:    check.summary,
+			DetailsURL: job.PRURL,
+		})
+	} else {
+		state := "success"
+		if check.conclusion == "failure" {
+			state = "failure"
+		}
+		err = git.CreateGitHubCommitStatus(ctx, token, gh.BaseURL, gh.Owner, gh.Repo, sha, state, check.title, job.PRURL)
+	}
+	if err != nil {
+		slog.Warn("pipeline check: report", "job", job.ID, "err", err)
+		return
+	}
+	slog.Info("pipeline check reported", "job", job.ID, "sha", sha, "conclusion", check.conclusion)
+}
+
+// buildPipelineCheck summarizes a job's steps from its artifacts and the
+// outcome of the test step in its final iteration (see db.JobTestsResult).
+// A job can reach ready with review or tests still failing when it runs out
+// of iterations; those steps are marked failed.
+func buildPipelineCheck(job db.Job, artifacts []db.Artifact, testsResult string) pipelineCheck {
+	var plan, test, flaky *db.Artifact
+	var reviews []db.Artifact
+	iterations := map[int]bool{}
+	for i := range artifacts {
+		switch a := &artifacts[i]; a.Kind {
+		case "plan":
+			plan = a
+		case "code_review":
+			reviews = append(reviews, *a)
+			iterations[a.Iteration] = true
+		case "test_output":
+			test = a
+		case flakyTestsArtifactKind:
+			flaky = a
+		}
+	}
+
+	steps := make([]checkStep, 0, 4)
+	if plan != nil {
+		steps = append(steps, checkStep{"Plan", checkPassed, firstLine(plan.Content, 120)})
+	} else {
+		steps = append(steps, checkStep{"Plan", checkSkipped, "no plan recorded"})
+	}
+
+	rounds := max(len(iterations), 1)
+	steps = append(steps, checkStep{"Implement", checkPassed, plural(rounds, "iteration")})
+
+	review := checkStep{"Review", checkSkipped, "not run"}
+	if len(reviews) > 0 {
+		last := reviews[len(reviews)-1]
+		if isApproved(last.Content) {
+			review = checkStep{"Review", checkPassed, "approved"}
+			if n := len(reviews) - 1; n > 0 {
+				review.detail = "approved after " + plural(n, "round") + " of changes"
+			}
+		} else {
+			review = checkStep{"Review", checkFailed, "changes requested: " + firstLine(last.Content, 120)}
+		}
+	}
+	steps = append(steps, review)
+
+	tests := checkStep{"Tests", checkSkipped, "not run on the final changes"}
+	switch testsResult {
+	case db.TestsPassed:
+		tests = checkStep{"Tests", checkPassed, "passed"}
+		if flaky != nil && test != nil && flaky.Iteration == test.Iteration {
+			tests.detail = "passed on re-run; flaky tests recorded"
+		}
+	case db.TestsFailed:
+		tests = checkStep{"Tests", checkFailed, "failed"}
+		if test != nil {
+			tests.detail = "failed: " + firstLine(test.Content, 120)
+		}
+	}
+	steps = append(steps, tests)
+
+	conclusion := "success"
+	titleParts := make([]string, 0, len(steps))
+	for _, s := range steps {
+		titleParts = append(titleParts, s.name+" "+s.mark)
+		switch {
+		case s.mark == checkFailed:
+			conclusion = "failure"
+		case s.mark == checkSkipped && conclusion == "success":
+			conclusion = "neutral"
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("AutoPR's own assessment of this change, before human review.\n\n")
+	b.WriteString("| Step | Result | |\n|---|---|---|\n")
+	for _, s := range steps {
+		fmt.Fprintf(&b, "| %s | %s | %s |\n", s.name, s.mark, strings.ReplaceAll(s.detail, "|", `\|`))
+	}
+	if level := job.RiskLevel(); level != "" {
+		fmt.Fprintf(&b, "\nReview risk: %d/100 (%s).\n", job.RiskScore, level)
+	}
+	if tests.mark == checkFailed && test != nil {
+		fmt.Fprintf(&b, "\n#### Test output (iteration %d)\n\n```\n", test.Iteration)
+		b.WriteString(truncatePRSection(tailLines(strings.TrimRight(test.Content, "\n"), checkTestLines), checkTestLimit))
+		b.WriteString("\n```\n")
+	}
+
+	return pipelineCheck{
+		conclusion: conclusion,
+		title:      strings.Join(titleParts, " · "),
+		summary:    b.String(),
+	}
+}
@@ -0,0 +1,121 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"autopr/internal/config"
+	"autopr/internal/db"
+)
+
+func TestBuildPipelineCheck(t *testing.T) {
+	t.Parallel()
+
+	artifacts := []db.Artifact{
+		{Kind: "plan", Content: "## Plan\n1. Fix the timeout in login.go", Iteration: 0},
+		{Kind: "code_review", Content: "## Issues\n- Missing test", Iteration: 0},
+		{Kind: "code_review", Content: "APPROVED. Looks good.", Iteration: 1},
+		{Kind: "test_output", Content: "ok\n", Iteration: 1},
+	}
+	job := db.Job{RiskScore: 35, RiskSummary: "1 critical file"}
+
+	check := buildPipelineCheck(job, artifacts, db.TestsPassed)
+	if check.conclusion != "success" {
+		t.Fatalf("conclusion = %q, want success", check.conclusion)
+	}
+	if check.title != "Plan ✓ · Implement ✓ · Review ✓ · Tests ✓" {
+		t.Fatalf("title = %q", check.title)
+	}
+	for _, want := range []string{
+		"| Plan | ✓ | Plan |",
+		"| Implement | ✓ | 2 iterations |",
+		"| Review | ✓ | approved after 1 round of changes |",
+		"| Tests | ✓ | passed |",
+		"Review risk: 35/100 (medium).",
+	} {
+		if !strings.Contains(check.summary, want) {
+			t.Errorf("summary missing %q:\n%s", want, check.summary)
+		}
+	}
+
+	failing := append(artifacts, db.Artifact{Kind: "test_output", Content: "--- FAIL: TestLogin\nFAIL\n", Iteration: 2})
+	check = buildPipelineCheck(db.Job{}, failing, db.TestsFailed)
+	if check.conclusion != "failure" || !strings.HasSuffix(check.title, "Tests ✗") {
+		t.Fatalf("failed tests: conclusion %q, title %q", check.conclusion, check.title)
+	}
+	if !strings.Contains(check.summary, "| Tests | ✗ | failed: FAIL: TestLogin |") || !strings.Contains(check.summary, "#### Test output (iteration 2)") {
+		t.Fatalf("failed tests summary:\n%s", check.summary)
+	}
+
+	// Out of iterations while review still wanted changes: tests never ran
+	// on the final changes.
+	changes := append(artifacts, db.Artifact{Kind: "code_review", Content: "Needs work | handle nil", Iteration: 2})
+	check = buildPipelineCheck(db.Job{}, changes, "")
+	if check.conclusion != "failure" || check.title != "Plan ✓ · Implement ✓ · Review ✗ · Tests –" {
+		t.Fatalf("review changes: conclusion %q, title %q", check.conclusion, check.title)
+	}
+	if !strings.Contains(check.summary, `changes requested: Needs work \| handle nil`) {
+		t.Fatalf("pipe in detail not escaped:\n%s", check.summary)
+	}
+}
+
+func TestReportPipelineCheckSetsCommitStatus(t *testing.T) {
+	t.Parallel()
+
+	_, store, issue, jobID := setupRunStepsJob(t, nil, "testing")
+	ctx := context.Background()
+	_, workDir := cloneWithChanges(t, nil)
+	if _, err := store.CreateArtifact(ctx, jobID, issue.AutoPRIssueID, "code_review", "APPROVED", 0, ""); err != nil {
+		t.Fatalf("create review: %v", err)
+	}
+	if err := store.SetJobTestsResult(ctx, jobID, db.TestsFailed); err != nil {
+		t.Fatalf("set tests result: %v", err)
+	}
+
+	var gotPath string
+	var gotBody map[string]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		_ = json.NewDecoder(r.Body).Decode(&gotBody)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+
+	cfg := &config.Config{Tokens: config.TokensConfig{GitHub: "tok"}}
+	proj := &config.ProjectConfig{Name: "project", GitHub: &config.ProjectGitHub{Owner: "org", Repo: "repo", BaseURL: srv.URL}}
+	job, err := store.GetJob(ctx, jobID)
+	if err != nil {
+		t.Fatalf("get job: %v", err)
+	}
+	job.WorktreePath = workDir
+	job.PRURL = "https://github.com/org/repo/pull/7"
+
+	ReportPipelineCheck(ctx, cfg, store, proj, job)
+	if gotPath != "" {
+		t.Fatalf("reported without report_checks: %s", gotPath)
+	}
+
+	proj.GitHub.ReportChecks = true
+	ReportPipelineCheck(ctx, cfg, store, proj, job)
+	if !strings.HasPrefix(gotPath, "/api/v3/repos/org/repo/statuses/") {
+		t.Fatalf("path = %q", gotPath)
+	}
+	if gotBody["state"] != "failure" || gotBody["context"] != "AutoPR" || gotBody["target_url"] != job.PRURL {
+		t.Fatalf("unexpected status: %v", gotBody)
+	}
+	if !strings.Contains(gotBody["description"], "Tests ✗") {
+		t.Fatalf("description = %q", gotBody["description"])
+	}
+
+	// A new iteration has not run its tests yet.
+	if err := store.IncrementIteration(ctx, jobID); err != nil {
+		t.Fatalf("increment iteration: %v", err)
+	}
+	if result, err := store.JobTestsResult(ctx, jobID); err != nil || result != "" {
+		t.Fatalf("tests result after new iteration = %q, %v", result, err)
+	}
+}
@@ -0,0 +1,221 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"autopr/internal/config"
+	"autopr/internal/db"
+	"autopr/internal/git"
+)
+
+// maxReviewChunks bounds the review passes of one diff. Larger diffs get
+// larger chunks.
+const maxReviewChunks = 20
+
+// chunkReviewPrompt reviews one chunk of a diff too large to review at once.
+const chunkReviewPrompt = `You are an expert code reviewer. The changes for the issue below are too large to review at once, so they are reviewed in parts. This is part {{part}} of {{parts}}.
+
+<issue>
+Title: {{title}}
+
+{{body}}
+</issue>
+
+<plan>
+{{plan}}
+</plan>
+
+Files changed across all parts:
+{{files}}
+
+<diff part="{{part}}">
+{{diff}}
+</diff>
+
+Review only the changes in this part for correctness, code quality, tests, security, and performance. You may read other files in the working directory for context. List each problem that must be fixed, with its file and line. If this part has none, respond with: NO ISSUES`
+
+// fileDiff is the diff of one file: its header lines and its hunks.
+type fileDiff struct {
+	Path   string
+	Header string
+	Hunks  []string
+	Lines  int // lines added plus removed
+}
+
+// diffChunk is a part of a diff reviewed in one pass: whole files, or some
+// hunks of one large file.
+type diffChunk struct {
+	Files []string
+	Diff  string
+	Lines int
+}
+
+// parseFileDiffs splits a unified diff into its files.
+func parseFileDiffs(diff string) []fileDiff {
+	var (
+		files []fileDiff
+		cur   *fileDiff
+		block strings.Builder // header or current hunk
+	)
+	flush := func() {
+		if cur == nil {
+			return
+		}
+		if len(cur.Hunks) == 0 && cur.Header == "" {
+			cur.Header = block.String()
+		} else {
+			cur.Hunks = append(cur.Hunks, block.String())
+		}
+		block.Reset()
+	}
+	for _, line := range strings.SplitAfter(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			flush()
+			files = append(files, fileDiff{Path: diffHeaderPath(line)})
+			cur = &files[len(files)-1]
+		case cur == nil:
+			continue
+		case strings.HasPrefix(line, "@@"):
+			flush()
+		case strings.HasPrefix(line, "+++ b/") && len(cur.Hunks) == 0:
+			cur.Path = strings.TrimSpace(strings.TrimPrefix(line, "+++ b/"))
+		case (strings.HasPrefix(line, "+") && !strings.HasPrefix(line, "+++")) || (strings.HasPrefix(line, "-") && !strings.HasPrefix(line, "---")):
+			cur.Lines++
+		}
+		block.WriteString(line)
+	}
+	flush()
+	return files
+}
+
+// diffHeaderPath reads the file path from a "diff --git a/x b/x" line.
+func diffHeaderPath(line string) string {
+	line = strings.TrimSpace(strings.TrimPrefix(line, "diff --git "))
+	if i := strings.LastIndex(line, " b/"); i >= 0 {
+		return line[i+3:]
+	}
+	return line
+}
+
+// chunkDiff groups files into chunks of at most maxLines changed lines. A
+// file over maxLines is split between its hunks, each chunk repeating the
+// file header; a single hunk over maxLines is a chunk of its own.
+func chunkDiff(files []fileDiff, maxLines int) []diffChunk {
+	var (
+		chunks []diffChunk
+		cur    diffChunk
+		diff   strings.Builder
+	)
+	flush := func() {
+		if len(cur.Files) > 0 {
+			cur.Diff = diff.String()
+			chunks = append(chunks, cur)
+		}
+		cur = diffChunk{}
+		diff.Reset()
+	}
+	for _, f := range files {
+		if f.Lines <= maxLines {
+			if cur.Lines+f.Lines > maxLines {
+				flush()
+			}
+			cur.Files = append(cur.Files, f.Path)
+			cur.Lines += f.Lines
+			diff.WriteString(f.Header)
+			for _, h := range f.Hunks {
+				diff.WriteString(h)
+			}
+			continue
+		}
+		flush()
+		for _, h := range f.Hunks {
+			n := changedLines(h)
+			if len(cur.Files) > 0 && cur.Lines+n > maxLines {
+				flush()
+			}
+			if len(cur.Files) == 0 {
+				cur.Files = []string{f.Path}
+				diff.WriteString(f.Header)
+			}
+			cur.Lines += n
+			diff.WriteString(h)
+		}
+		flush()
+	}
+	flush()
+	return chunks
+}
+
+// changedLines counts the added and removed lines of a hunk.
+func changedLines(hunk string) int {
+	n := 0
+	for _, line := range strings.Split(hunk, "\n") {
+		if strings.HasPrefix(line, "+") || strings.HasPrefix(line, "-") {
+			n++
+		}
+	}
+	return n
+}
+
+// reviewChunks returns the chunks to review the job's diff in, or nil when
+// the project does not chunk reviews or the diff is small enough to review
+// at once. Generated files are left out.
+func reviewChunks(ctx context.Context, proj *config.ProjectConfig, job db.Job, workDir string) ([]diffChunk, []string, error) {
+	cr := proj.ChunkedReview
+	if cr == nil {
+		return nil, nil, nil
+	}
+	diff, err := git.DiffAgainstBase(ctx, workDir, TargetBranch(job, proj))
+	if err != nil {
+		return nil, nil, err
+	}
+	var (
+		files []fileDiff
+		paths []string
+		total int
+	)
+	for _, f := range parseFileDiffs(diff) {
+		if proj.IsGenerated(f.Path) {
+			continue
+		}
+		files = append(files, f)
+		paths = append(paths, f.Path)
+		total += f.Lines
+	}
+	if total <= cr.MinLines {
+		return nil, nil, nil
+	}
+	chunkLines := max(cr.ChunkLines, (total+maxReviewChunks-1)/maxReviewChunks)
+	return chunkDiff(files, chunkLines), paths, nil
+}
+
+// reviewInChunks runs one review_chunk session per chunk and returns their
+// findings, to be merged by the code review session.
+func (r *Runner) reviewInChunks(ctx context.Context, job db.Job, issue db.Issue, plan string, chunks []diffChunk, paths []string, workDir string) (string, error) {
+	var findings strings.Builder
+	fmt.Fprintf(&findings, "\n\n<chunk_reviews>\nThe diff was too large to review at once, so it was first reviewed in %d parts. Their findings:\n", len(chunks))
+	for i, c := range chunks {
+		prompt := BuildPrompt(chunkReviewPrompt, map[string]string{
+			"part":  strconv.Itoa(i + 1),
+			"parts": strconv.Itoa(len(chunks)),
+			"title": issue.Title,
+			"body":  SanitizeIssueContent(issue.Body),
+			"plan":  plan,
+			"files": "- " + strings.Join(paths, "\n- "),
+			"diff":  strings.TrimRight(c.Diff, "\n"),
+		})
+		resp, err := r.invokeProvider(ctx, job.ID, "review_chunk", job.Iteration, workDir, prompt)
+		if err != nil {
+			return "", fmt.Errorf("review part %d of %d: %w", i+1, len(chunks), err)
+		}
+		fmt.Fprintf(&findings, "\n#### Part %d of %d: %s\n\n%s\n", i+1, len(chunks), strings.Join(c.Files, ", "), strings.TrimSpace(resp.Text))
+		slog.Info("reviewed diff chunk", "job", job.ID, "part", i+1, "parts", len(chunks), "lines", c.Lines)
+	}
+	findings.WriteString("</chunk_reviews>\n\nCheck each finding against the working directory, drop duplicates and findings that are wrong, and look for problems across parts that no single part could see. Then answer as instructed above, listing every finding that still holds.")
+	return findings.String(), nil
+}
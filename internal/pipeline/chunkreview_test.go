@@ -0,0 +1,128 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"autopr/internal/config"
+	"autopr/internal/llm"
+)
+
+// testDiff returns a diff of one file with a hunk per entry of sizes, each
+// adding that many lines.
+func testDiff(path string, sizes ...int) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "diff --git a/%s b/%s\nindex 1111111..2222222 100644\n--- a/%s\n+++ b/%s\n", path, path, path, path)
+	for i, n := range sizes {
+		fmt.Fprintf(&b, "@@ -%d,0 +%d,%d @@\n", i*100, i*100, n)
+		for j := range n {
+			fmt.Fprintf(&b, "+line %d\n", j)
+		}
+	}
+	return b.String()
+}
+
+func TestChunkDiffGroupsFilesAndSplitsLargeOnes(t *testing.T) {
+	t.Parallel()
+
+	diff := testDiff("a.go", 30) + testDiff("b.go", 40) + testDiff("big.go", 60, 50, 20) + testDiff("c.go", 10)
+	files := parseFileDiffs(diff)
+	if len(files) != 4 || files[2].Path != "big.go" || files[2].Lines != 130 || len(files[2].Hunks) != 3 {
+		t.Fatalf("unexpected files: %+v", files)
+	}
+
+	chunks := chunkDiff(files, 80)
+	var got []string
+	for _, c := range chunks {
+		got = append(got, fmt.Sprintf("%s:%d", strings.Join(c.Files, ","), c.Lines))
+	}
+	want := []string{"a.go,b.go:70", "big.go:60", "big.go:70", "c.go:10"}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Fatalf("chunks = %v, want %v", got, want)
+	}
+	// Each part of a split file repeats its header.
+	for _, c := range chunks[1:3] {
+		if !strings.HasPrefix(c.Diff, "diff --git a/big.go b/big.go\n") || !strings.Contains(c.Diff, "+++ b/big.go\n") {
+			t.Fatalf("split part lacks the file header:\n%s", c.Diff)
+		}
+	}
+	if !strings.Contains(chunks[2].Diff, "@@ -100,0") || !strings.Contains(chunks[2].Diff, "@@ -200,0") || strings.Contains(chunks[2].Diff, "@@ -0,0") {
+		t.Fatalf("unexpected hunks in second part:\n%s", chunks[2].Diff)
+	}
+}
+
+func TestRunCodeReviewInChunks(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu      sync.Mutex
+		prompts []string
+	)
+	provider := stubProvider{run: func(ctx context.Context, workDir, prompt string) (llm.Response, error) {
+		mu.Lock()
+		prompts = append(prompts, prompt)
+		mu.Unlock()
+		switch {
+		case strings.Contains(prompt, "<chunk_reviews>"):
+			return llm.Response{Text: "handler.go returns nil on error."}, nil
+		case strings.Contains(prompt, "+++ b/handler.go"):
+			return llm.Response{Text: "handler.go: the error is dropped."}, nil
+		default:
+			return llm.Response{Text: "NO ISSUES"}, nil
+		}
+	}}
+	runner, store, issue, jobID := setupRunStepsJob(t, provider, "reviewing")
+	ctx := context.Background()
+	if _, err := store.CreateArtifact(ctx, jobID, issue.AutoPRIssueID, "plan", "1. Add a handler", 0, ""); err != nil {
+		t.Fatalf("create plan: %v", err)
+	}
+	remote, workDir := cloneWithChanges(t, map[string]string{
+		"handler.go":    strings.Repeat("// handler\n", 70),
+		"store.go":      strings.Repeat("// store\n", 70),
+		"gen/api.pb.go": strings.Repeat("// generated\n", 500),
+	})
+	proj := &config.ProjectConfig{
+		Name:          "project",
+		RepoURL:       remote,
+		BaseBranch:    "main",
+		Generated:     &config.ProjectGenerated{Paths: []string{"*.pb.go"}},
+		ChunkedReview: &config.ProjectChunkedReview{MinLines: 100, ChunkLines: 100},
+	}
+
+	if err := runner.runCodeReview(ctx, jobID, issue, proj, workDir); err != errReviewChangesRequested {
+		t.Fatalf("run code review = %v, want changes requested", err)
+	}
+	if len(prompts) != 3 {
+		t.Fatalf("expected two chunk passes and a synthesis, got %d prompts", len(prompts))
+	}
+	for _, p := range prompts[:2] {
+		if strings.Contains(p, "api.pb.go\n+") || !strings.Contains(p, "- handler.go\n- store.go") {
+			t.Fatalf("unexpected chunk prompt:\n%s", p)
+		}
+	}
+	if !strings.Contains(prompts[2], "#### Part 1 of 2: handler.go\n\nhandler.go: the error is dropped.") || !strings.Contains(prompts[2], "#### Part 2 of 2: store.go\n\nNO ISSUES") {
+		t.Fatalf("synthesis prompt lacks chunk findings:\n%s", prompts[2])
+	}
+
+	sessions, err := store.ListSessionSummariesByJob(ctx, jobID)
+	if err != nil {
+		t.Fatalf("list sessions: %v", err)
+	}
+	var steps []string
+	for _, s := range sessions {
+		steps = append(steps, s.Step)
+	}
+	if strings.Join(steps, " ") != "review_chunk review_chunk code_review" {
+		t.Fatalf("session steps = %v", steps)
+	}
+	artifact, err := store.GetLatestArtifact(ctx, jobID, "code_review")
+	if err != nil {
+		t.Fatalf("get review: %v", err)
+	}
+	if !strings.HasPrefix(artifact.Content, "handler.go returns nil on error.") || !strings.Contains(artifact.Content, "Reviewed in 2 parts") || isApproved(artifact.Content) {
+		t.Fatalf("unexpected review artifact: %q", artifact.Content)
+	}
+}
@@ -0,0 +1,84 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"autopr/internal/config"
+	"autopr/internal/db"
+)
+
+// autoprCommentMarker starts the comments AutoPR posts on issues itself, such
+// as epic status, which are no part of the discussion.
+const autoprCommentMarker = "<!-- autopr"
+
+// issueDiscussion returns the comment thread of issue for the plan prompt,
+// or "" when the project does not sync comments or there are none. The
+// comments are fetched again first, so a retried job sees the latest ones;
+// when that fails, the ones synced before are used.
+func (r *Runner) issueDiscussion(ctx context.Context, issue db.Issue, proj *config.ProjectConfig) string {
+	if proj.IssueComments == nil {
+		return ""
+	}
+	if r.refreshIssueComments != nil {
+		if err := r.refreshIssueComments(ctx, issue); err != nil {
+			slog.Warn("refresh issue comments", "issue", issue.Source+"#"+issue.SourceIssueID, "err", err)
+		}
+	}
+	comments, err := r.store.ListIssueComments(ctx, issue.AutoPRIssueID)
+	if err != nil {
+		slog.Warn("list issue comments", "issue", issue.AutoPRIssueID, "err", err)
+		return ""
+	}
+	return renderIssueComments(comments, proj.IssueComments)
+}
+
+// renderIssueComments formats comments, oldest first, as an <issue_comments>
+// block. Comments longer than MaxCommentChars are cut, and the newest ones
+// that fit in MaxChars are kept; older ones are left out with a note.
+// AutoPR's own comments and those of ignored authors are skipped.
+func renderIssueComments(comments []db.IssueComment, ic *config.ProjectIssueComments) string {
+	var (
+		kept    []string
+		used    int
+		omitted int
+	)
+	for _, c := range slices.Backward(comments) {
+		if strings.Contains(c.Body, autoprCommentMarker) || slices.ContainsFunc(ic.IgnoreAuthors, func(a string) bool { return strings.EqualFold(a, c.Author) }) {
+			continue
+		}
+		body := SanitizeIssueContent(c.Body)
+		if body == "" {
+			continue
+		}
+		if len(body) > ic.MaxCommentChars {
+			body = strings.ToValidUTF8(body[:ic.MaxCommentChars], "") + "\n... (truncated)"
+		}
+		date := c.CreatedAt
+		if len(date) > len("2006-01-02") {
+			date = date[:len("2006-01-02")]
+		}
+		entry := fmt.Sprintf("@%s on %s:\n%s", c.Author, date, body)
+		if omitted > 0 || used+len(entry) > ic.MaxChars {
+			omitted++
+			continue
+		}
+		used += len(entry)
+		kept = append(kept, entry)
+	}
+	if len(kept) == 0 && omitted == 0 {
+		return ""
+	}
+	slices.Reverse(kept)
+	var b strings.Builder
+	b.WriteString("<issue_comments>\n")
+	if omitted > 0 {
+		fmt.Fprintf(&b, "(%d earlier comment(s) left out)\n\n", omitted)
+	}
+	b.WriteString(strings.Join(kept, "\n\n"))
+	b.WriteString("\n</issue_comments>")
+	return b.String()
+}
@@ -0,0 +1,62 @@
+package pipeline
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"autopr/internal/config"
+	"autopr/internal/db"
+	"autopr/internal/llm"
+)
+
+func TestRenderIssueCommentsKeepsNewestWithinBudget(t *testing.T) {
+	t.Parallel()
+
+	comments := []db.IssueComment{
+		{Author: "ann", Body: "First guess: the cache.", CreatedAt: "2026-01-01T09:00:00Z"},
+		{Author: "ci-bot", Body: "Build passed.", CreatedAt: "2026-01-02T09:00:00Z"},
+		{Author: "autopr", Body: "<!-- autopr-epic -->\nstatus", CreatedAt: "2026-01-02T10:00:00Z"},
+		{Author: "bob", Body: "It is the header button only. " + strings.Repeat("x", 100), CreatedAt: "2026-01-03T09:00:00Z"},
+		{Author: "ann", Body: "Confirmed on Safari.", CreatedAt: "2026-01-04T09:00:00Z"},
+	}
+	ic := &config.ProjectIssueComments{MaxChars: 120, MaxCommentChars: 40, IgnoreAuthors: []string{"CI-Bot"}}
+
+	got := renderIssueComments(comments, ic)
+	want := "<issue_comments>\n(1 earlier comment(s) left out)\n\n" +
+		"@bob on 2026-01-03:\nIt is the header button only. xxxxxxxxxx\n... (truncated)\n\n" +
+		"@ann on 2026-01-04:\nConfirmed on Safari.\n</issue_comments>"
+	if got != want {
+		t.Fatalf("renderIssueComments =\n%s\nwant\n%s", got, want)
+	}
+
+	if got := renderIssueComments(comments[1:3], ic); got != "" {
+		t.Fatalf("expected no block without discussion, got %q", got)
+	}
+}
+
+func TestRunPlanRefreshesIssueComments(t *testing.T) {
+	t.Parallel()
+
+	var gotPrompt string
+	provider := stubProvider{run: func(ctx context.Context, workDir, prompt string) (llm.Response, error) {
+		gotPrompt = prompt
+		return llm.Response{Text: "1. Fix the header button"}, nil
+	}}
+	runner, store, issue, jobID := setupRunStepsJob(t, provider, "planning")
+	ctx := context.Background()
+	// The comment was added after the job was queued; planning fetches it.
+	runner.refreshIssueComments = func(ctx context.Context, issue db.Issue) error {
+		return store.ReplaceIssueComments(ctx, issue.AutoPRIssueID, issue.SourceUpdated, []db.IssueComment{
+			{CommentID: "1", Author: "bob", Body: "Only the header button is broken.", CreatedAt: "2026-01-03T09:00:00Z"},
+		})
+	}
+	proj := &config.ProjectConfig{Name: issue.ProjectName, IssueComments: &config.ProjectIssueComments{MaxChars: 1000, MaxCommentChars: 500}}
+
+	if err := runner.runPlan(ctx, jobID, issue, proj, t.TempDir()); err != nil {
+		t.Fatalf("run plan: %v", err)
+	}
+	if !strings.Contains(gotPrompt, "<issue_comments>\n@bob on 2026-01-03:\nOnly the header button is broken.\n</issue_comments>") {
+		t.Fatalf("prompt missing the issue discussion:\n%s", gotPrompt)
+	}
+}
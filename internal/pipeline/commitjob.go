@@ -0,0 +1,147 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"autopr/internal/config"
+	"autopr/internal/db"
+	"autopr/internal/git"
+)
+
+// commitOp applies a single merged commit to a job branch: a cherry-pick for
+// backport jobs, a revert for revert jobs.
+type commitOp struct {
+	verb       string // used in messages, e.g. "cherry-pick"
+	commit     string
+	branch     string // branch the job is based on and targets
+	apply      func(ctx context.Context, dir, commit string) (bool, error)
+	cont       func(ctx context.Context, dir string) (bool, error)
+	inProgress func(dir string) bool
+	abort      func(ctx context.Context, dir string) error
+}
+
+// runCommitJob runs op in place of the plan/implement/review loop. Conflicts go
+// through the same LLM resolution step as rebases; tests run before the job
+// becomes ready.
+func (r *Runner) runCommitJob(ctx context.Context, job db.Job, issue db.Issue, projectCfg *config.ProjectConfig, workDir string, op commitOp) error {
+	jobID := job.ID
+	if job.State != "planning" {
+		return nil
+	}
+
+	if err := r.store.TransitionState(ctx, jobID, "planning", "rebasing"); err != nil {
+		if r.isJobCancelledError(ctx, jobID, err) {
+			return errJobCancelled
+		}
+		return err
+	}
+
+	token := GitTokenForProject(ctx, r.cfg, projectCfg)
+	if err := git.ConfigureDiff3(ctx, workDir); err != nil {
+		return r.failJob(ctx, jobID, "rebasing", "configure git diff3 markers: "+err.Error())
+	}
+	// The merged commit lives on the base branch; the target branch is
+	// refreshed so a retried job starts from a clean checkout.
+	for _, b := range uniqueBranches(projectCfg.BaseBranch, op.branch) {
+		if err := git.FetchBranch(ctx, workDir, b, token); err != nil {
+			return r.failJob(ctx, jobID, "rebasing", fmt.Sprintf("fetch %s: %s", b, err))
+		}
+	}
+	op.abortIfNeeded(ctx, workDir)
+	if err := git.ResetToRemoteBranch(ctx, workDir, op.branch); err != nil {
+		return r.failJob(ctx, jobID, "rebasing", err.Error())
+	}
+
+	hasConflicts, err := op.apply(ctx, workDir, op.commit)
+	if err != nil {
+		op.abortIfNeeded(ctx, workDir)
+		return r.failJob(ctx, jobID, "rebasing", op.verb+": "+err.Error())
+	}
+
+	fromState := "rebasing"
+	if hasConflicts {
+		if err := r.resolveCommitJobConflicts(ctx, job, issue, projectCfg, workDir, op); err != nil {
+			op.abortIfNeeded(ctx, workDir)
+			if r.isJobCancelledError(ctx, jobID, err) {
+				return errJobCancelled
+			}
+			return err
+		}
+		fromState = "resolving_conflicts"
+	}
+
+	sha, err := git.LatestCommit(ctx, workDir)
+	if err != nil {
+		return r.failJob(ctx, jobID, fromState, fmt.Sprintf("read head after %s: %s", op.verb, err))
+	}
+	if err := r.store.UpdateJobField(ctx, jobID, "commit_sha", sha); err != nil {
+		return r.failJob(ctx, jobID, fromState, "store commit sha: "+err.Error())
+	}
+	if !hasConflicts {
+		content := fmt.Sprintf("Clean %s of %s onto %s\nAfter: %s", op.verb, op.commit, op.branch, sha)
+		if _, err := r.store.CreateArtifact(ctx, jobID, issue.AutoPRIssueID, rebaseResultArtifactKind, content, job.Iteration, sha); err != nil {
+			slog.Warn("failed to store rebase_result artifact", "job", jobID, "err", err)
+		}
+	}
+
+	return r.rerunTestsAndMarkReady(ctx, jobID, issue, projectCfg, workDir, fromState)
+}
+
+func (r *Runner) resolveCommitJobConflicts(ctx context.Context, job db.Job, issue db.Issue, projectCfg *config.ProjectConfig, workDir string, op commitOp) error {
+	conflicts, err := r.collectRebaseConflicts(ctx, workDir)
+	if err != nil {
+		return r.failJob(ctx, job.ID, "rebasing", fmt.Sprintf("collect %s conflicts: %s", op.verb, err))
+	}
+	if len(conflicts.filePaths) == 0 || len(conflicts.conflicts) == 0 {
+		return r.failJob(ctx, job.ID, "rebasing", "no conflict files or parseable conflict regions found")
+	}
+
+	artifactText := fmt.Sprintf("Conflicts applying %s of %s onto %s\n\n%s", op.verb, op.commit, op.branch, conflicts.summary)
+	if _, err := r.store.CreateArtifact(ctx, job.ID, issue.AutoPRIssueID, rebaseConflictArtifactKind, artifactText, job.Iteration, ""); err != nil {
+		slog.Warn("failed to store rebase conflict artifact", "job", job.ID, "err", err)
+	}
+
+	limit := maxAutoResolvableConflictLines(projectCfg)
+	if conflicts.conflictLines >= limit {
+		return r.failJob(ctx, job.ID, "rebasing",
+			fmt.Sprintf("%s conflict line count %d reached limit %d (%s)",
+				op.verb, conflicts.conflictLines, limit, strings.Join(conflicts.filePaths, ", ")))
+	}
+
+	if err := r.store.TransitionState(ctx, job.ID, "rebasing", "resolving_conflicts"); err != nil {
+		return err
+	}
+	if err := r.resolveRebaseConflictsWithLLM(ctx, job.ID, issue, projectCfg, workDir, job.Iteration, conflicts, op.branch, op.cont); err != nil {
+		if r.isJobCancelledError(ctx, job.ID, err) {
+			return errJobCancelled
+		}
+		return r.failJob(ctx, job.ID, "resolving_conflicts", err.Error())
+	}
+	return nil
+}
+
+func (op commitOp) abortIfNeeded(ctx context.Context, workDir string) {
+	if !op.inProgress(workDir) {
+		return
+	}
+	abortCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancel()
+	if err := op.abort(abortCtx, workDir); err != nil {
+		slog.Warn("failed to abort "+op.verb, "workdir", workDir, "err", err)
+	}
+}
+
+func uniqueBranches(branches ...string) []string {
+	var out []string
+	for _, b := range branches {
+		if b != "" && !slices.Contains(out, b) {
+			out = append(out, b)
+		}
+	}
+	return out
+}
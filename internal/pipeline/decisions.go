@@ -0,0 +1,141 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+
+	"autopr/internal/config"
+	"autopr/internal/db"
+	"autopr/internal/git"
+	"autopr/internal/githubapp"
+)
+
+const decisionsArtifactKind = "decisions"
+
+var decisionsBlockRe = regexp.MustCompile(`(?is)<decisions>(.*?)</decisions>`)
+
+// decisionsRequest asks each step to report the choices a reviewer would
+// want explained, in a block the pipeline strips from the response.
+const decisionsRequest = `
+
+## Decisions
+
+If this step involves non-obvious choices (a library picked, existing behavior changed, a TODO or known gap left behind), list them at the very end of your response, one per line starting with "- ":
+
+<decisions>
+- ...
+</decisions>
+
+Omit the block when there is nothing worth noting.`
+
+// withDecisionsRequest appends the decisions instructions to a step prompt.
+func withDecisionsRequest(prompt string) string {
+	return prompt + decisionsRequest
+}
+
+// extractDecisions splits a step response into its <decisions> items and the
+// remaining text, so the block stays out of plans and reviews.
+func extractDecisions(text string) ([]string, string) {
+	var items []string
+	for _, m := range decisionsBlockRe.FindAllStringSubmatch(text, -1) {
+		for _, line := range strings.Split(m[1], "\n") {
+			line = strings.TrimSpace(line)
+			line = strings.TrimSpace(strings.TrimLeft(line, "-*"))
+			if line == "" || line == "..." {
+				continue
+			}
+			items = append(items, line)
+		}
+	}
+	rest := strings.TrimSpace(decisionsBlockRe.ReplaceAllString(text, ""))
+	return items, rest
+}
+
+// recordDecisions stores the decisions a step reported as a decisions
+// artifact and returns the response with the block removed.
+func (r *Runner) recordDecisions(ctx context.Context, job db.Job, issue db.Issue, step, text string) string {
+	items, rest := extractDecisions(text)
+	if len(items) == 0 {
+		return rest
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "#### %s (iteration %d)\n", stepTitle(step), job.Iteration)
+	for _, item := range items {
+		fmt.Fprintf(&b, "- %s\n", item)
+	}
+	if _, err := r.store.CreateArtifact(ctx, job.ID, issue.AutoPRIssueID, decisionsArtifactKind, strings.TrimRight(b.String(), "\n"), job.Iteration, ""); err != nil {
+		slog.Warn("failed to store decisions artifact", "job", job.ID, "err", err)
+	}
+	return rest
+}
+
+func stepTitle(step string) string {
+	switch step {
+	case "plan":
+		return "Plan"
+	case "implement":
+		return "Implement"
+	case "code_review":
+		return "Code review"
+	}
+	return step
+}
+
+// DecisionsLog collects a job's decisions artifacts into one markdown
+// document, oldest first. It returns "" when no step reported any.
+func DecisionsLog(ctx context.Context, store *db.Store, jobID string) (string, error) {
+	artifacts, err := store.ListArtifactsByJob(ctx, jobID)
+	if err != nil {
+		return "", err
+	}
+	var sections []string
+	for _, a := range artifacts {
+		if a.Kind == decisionsArtifactKind {
+			sections = append(sections, a.Content)
+		}
+	}
+	if len(sections) == 0 {
+		return "", nil
+	}
+	return "### AutoPR decisions\n\n" + strings.Join(sections, "\n\n"), nil
+}
+
+// CommentDecisions posts the job's decisions log as a comment on its PR/MR.
+func CommentDecisions(ctx context.Context, cfg *config.Config, store *db.Store, proj *config.ProjectConfig, job db.Job) error {
+	if strings.TrimSpace(job.PRURL) == "" {
+		return fmt.Errorf("job %s has no PR URL", db.ShortID(job.ID))
+	}
+	body, err := DecisionsLog(ctx, store, job.ID)
+	if err != nil {
+		return fmt.Errorf("load decisions: %w", err)
+	}
+	if body == "" {
+		return fmt.Errorf("job %s has no recorded decisions", db.ShortID(job.ID))
+	}
+	switch {
+	case proj.GitHub != nil && strings.Contains(job.PRURL, "/pull/"):
+		token, err := githubapp.Token(ctx, cfg, proj)
+		if err != nil {
+			return fmt.Errorf("github token: %w", err)
+		}
+		if token == "" {
+			return fmt.Errorf("GITHUB_TOKEN required to comment on PR")
+		}
+		return git.CommentGitHubPR(ctx, token, proj.GitHub.BaseURL, job.PRURL, body)
+	case proj.GitLab != nil && strings.Contains(job.PRURL, "/merge_requests/"):
+		if cfg.Tokens.GitLab == "" {
+			return fmt.Errorf("GITLAB_TOKEN required to comment on MR")
+		}
+		return git.CommentGitLabMR(ctx, cfg.Tokens.GitLab, proj.GitLab.BaseURL, job.PRURL, body)
+	case proj.Gitea != nil && strings.Contains(job.PRURL, "/pulls/"):
+		if cfg.Tokens.Gitea == "" {
+			return fmt.Errorf("GITEA_TOKEN required to comment on PR")
+		}
+		return git.CommentGiteaPR(ctx, cfg.Tokens.Gitea, proj.Gitea.BaseURL, job.PRURL, body)
+	default:
+		return fmt.Errorf("job %s has no GitHub PR, GitLab MR, or Gitea PR", db.ShortID(job.ID))
+	}
+}
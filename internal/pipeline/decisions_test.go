@@ -0,0 +1,69 @@
+package pipeline
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"autopr/internal/config"
+	"autopr/internal/llm"
+)
+
+func TestExtractDecisionsStripsBlock(t *testing.T) {
+	t.Parallel()
+
+	items, rest := extractDecisions("APPROVED\n\n<decisions>\n- Kept the old retry default\n* TODO: cover the timeout path\n- ...\n</decisions>\n")
+	if rest != "APPROVED" {
+		t.Fatalf("expected block stripped, got %q", rest)
+	}
+	want := []string{"Kept the old retry default", "TODO: cover the timeout path"}
+	if len(items) != len(want) {
+		t.Fatalf("expected %v, got %v", want, items)
+	}
+	for i := range want {
+		if items[i] != want[i] {
+			t.Fatalf("item %d: expected %q, got %q", i, want[i], items[i])
+		}
+	}
+
+	items, rest = extractDecisions("1. Edit login.go")
+	if len(items) != 0 || rest != "1. Edit login.go" {
+		t.Fatalf("expected no decisions, got %v / %q", items, rest)
+	}
+}
+
+func TestRunPlanRecordsDecisions(t *testing.T) {
+	t.Parallel()
+
+	var gotPrompt string
+	provider := stubProvider{run: func(ctx context.Context, workDir, prompt string) (llm.Response, error) {
+		gotPrompt = prompt
+		return llm.Response{Text: "1. Swap the HTTP client\n\n<decisions>\n- Chose net/http over resty to avoid a new dependency\n</decisions>"}, nil
+	}}
+	runner, store, issue, jobID := setupRunStepsJob(t, provider, "planning")
+	ctx := context.Background()
+
+	if err := runner.runPlan(ctx, jobID, issue, &config.ProjectConfig{}, t.TempDir()); err != nil {
+		t.Fatalf("run plan: %v", err)
+	}
+	if !strings.Contains(gotPrompt, "<decisions>") {
+		t.Fatalf("expected prompt to request decisions, got:\n%s", gotPrompt)
+	}
+	plan, err := store.GetLatestArtifact(ctx, jobID, "plan")
+	if err != nil {
+		t.Fatalf("get plan: %v", err)
+	}
+	if plan.Content != "1. Swap the HTTP client" {
+		t.Fatalf("expected decisions stripped from plan, got %q", plan.Content)
+	}
+
+	log, err := DecisionsLog(ctx, store, jobID)
+	if err != nil {
+		t.Fatalf("decisions log: %v", err)
+	}
+	for _, want := range []string{"### AutoPR decisions", "#### Plan (iteration 0)", "- Chose net/http over resty to avoid a new dependency"} {
+		if !strings.Contains(log, want) {
+			t.Errorf("decisions log missing %q:\n%s", want, log)
+		}
+	}
+}
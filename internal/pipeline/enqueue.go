@@ -0,0 +1,72 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"autopr/internal/config"
+	"autopr/internal/db"
+	"autopr/internal/policy"
+)
+
+// ErrIssueHasJob refuses to queue an issue that already has a job that is
+// not merged.
+var ErrIssueHasJob = errors.New("issue already has a job")
+
+// NotQueueableError refuses to queue an issue that is closed, not eligible,
+// or of a project that is missing or disabled.
+type NotQueueableError struct {
+	Reason string
+}
+
+func (e *NotQueueableError) Error() string { return e.Reason }
+
+// CheckEnqueue applies the rules for queueing an issue by hand, as `ap
+// enqueue`, the TUI, and MCP do: the issue is open and eligible, its project
+// is in the config and enabled, and it has no job that is not merged.
+func CheckEnqueue(ctx context.Context, cfg *config.Config, store *db.Store, issue db.Issue) (*config.ProjectConfig, error) {
+	if issue.State != "open" {
+		return nil, &NotQueueableError{Reason: fmt.Sprintf("issue is %s", issue.State)}
+	}
+	if !issue.Eligible {
+		reason := issue.SkipReason
+		if reason == "" {
+			reason = "ineligible"
+		}
+		return nil, &NotQueueableError{Reason: "issue is not eligible: " + reason}
+	}
+	proj, ok := cfg.ProjectByName(issue.ProjectName)
+	if !ok {
+		return nil, &NotQueueableError{Reason: fmt.Sprintf("project %q not found in config", issue.ProjectName)}
+	}
+	overrides, err := store.ProjectEnabledOverrides(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !cfg.ProjectEnabled(issue.ProjectName, overrides) {
+		return nil, &NotQueueableError{Reason: fmt.Sprintf("project %q is disabled", issue.ProjectName)}
+	}
+	exists, err := store.HasAnyNonMergedJobForIssue(ctx, issue.AutoPRIssueID)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, ErrIssueHasJob
+	}
+	return proj, nil
+}
+
+// EnqueueIssue queues a job for issue after CheckEnqueue, with the priority
+// the project's policy gives it.
+func EnqueueIssue(ctx context.Context, cfg *config.Config, store *db.Store, issue db.Issue) (string, error) {
+	proj, err := CheckEnqueue(ctx, cfg, store, issue)
+	if err != nil {
+		return "", err
+	}
+	jobID, err := policy.CreateJob(ctx, store, proj, issue.AutoPRIssueID, cfg.Daemon.MaxIterations)
+	if errors.Is(err, db.ErrDuplicateActiveJob) {
+		return "", ErrIssueHasJob
+	}
+	return jobID, err
+}
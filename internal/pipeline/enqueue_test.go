@@ -0,0 +1,69 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"autopr/internal/config"
+	"autopr/internal/db"
+)
+
+func TestEnqueueIssueAppliesRulesAndPriority(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	store, err := db.Open(filepath.Join(t.TempDir(), "autopr.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer store.Close()
+	path := filepath.Join(t.TempDir(), "policy.star")
+	if err := os.WriteFile(path, []byte("def priority(job, issue):\n    return 7\n"), 0o644); err != nil {
+		t.Fatalf("write policy: %v", err)
+	}
+	cfg := &config.Config{Projects: []config.ProjectConfig{{Name: "api", PolicyFile: path}}}
+	cfg.Daemon.MaxIterations = 3
+
+	issue := func(sourceID, state string) db.Issue {
+		t.Helper()
+		eligible := true
+		id, err := store.UpsertIssue(ctx, db.IssueUpsert{ProjectName: "api", Source: "github", SourceIssueID: sourceID, Title: "Fix login", State: state, Eligible: &eligible})
+		if err != nil {
+			t.Fatalf("upsert issue: %v", err)
+		}
+		got, err := store.GetIssueByAPID(ctx, id)
+		if err != nil {
+			t.Fatalf("get issue: %v", err)
+		}
+		return got
+	}
+
+	open := issue("1", "open")
+	jobID, err := EnqueueIssue(ctx, cfg, store, open)
+	if err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	job, err := store.GetJob(ctx, jobID)
+	if err != nil {
+		t.Fatalf("get job: %v", err)
+	}
+	if job.Priority != 7 {
+		t.Fatalf("priority = %d, want 7 from the policy", job.Priority)
+	}
+	if _, err := EnqueueIssue(ctx, cfg, store, open); !errors.Is(err, ErrIssueHasJob) {
+		t.Fatalf("second enqueue: %v, want ErrIssueHasJob", err)
+	}
+
+	var notQueueable *NotQueueableError
+	if _, err := EnqueueIssue(ctx, cfg, store, issue("2", "closed")); !errors.As(err, &notQueueable) {
+		t.Fatalf("closed issue: %v, want NotQueueableError", err)
+	}
+	disabled := false
+	cfg.Projects[0].Enabled = &disabled
+	if _, err := CheckEnqueue(ctx, cfg, store, issue("3", "open")); !errors.As(err, &notQueueable) {
+		t.Fatalf("disabled project: %v, want NotQueueableError", err)
+	}
+}
@@ -0,0 +1,53 @@
+package pipeline
+
+import (
+	"context"
+	"strings"
+
+	"autopr/internal/config"
+)
+
+// maskedSecret replaces secret values in stored command output.
+const maskedSecret = "[REDACTED]"
+
+// stepEnv returns the NAME=value pairs a project declares for step (see
+// config.EnvSteps), and the secret values among them so command output can
+// be masked before it is stored.
+func stepEnv(cfg *config.Config, projectCfg *config.ProjectConfig, step string) (env, secrets []string) {
+	if cfg == nil {
+		return nil, nil
+	}
+	for _, v := range cfg.ProjectEnv(projectCfg, step) {
+		env = append(env, v.Name+"="+v.Value)
+		if v.Secret && v.Value != "" {
+			secrets = append(secrets, v.Value)
+		}
+	}
+	return env, secrets
+}
+
+// providerEnv returns the env for an LLM session of jobID's project, or nil
+// when the job or project cannot be found.
+func (r *Runner) providerEnv(ctx context.Context, jobID, step string) []string {
+	if r.cfg == nil {
+		return nil
+	}
+	job, err := r.store.GetJob(ctx, jobID)
+	if err != nil {
+		return nil
+	}
+	projectCfg, ok := r.cfg.ProjectByName(job.ProjectName)
+	if !ok {
+		return nil
+	}
+	env, _ := stepEnv(r.cfg, projectCfg, step)
+	return env
+}
+
+// maskSecrets replaces every secret value in s.
+func maskSecrets(s string, secrets []string) string {
+	for _, secret := range secrets {
+		s = strings.ReplaceAll(s, secret, maskedSecret)
+	}
+	return s
+}
@@ -0,0 +1,52 @@
+package pipeline
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"autopr/internal/config"
+)
+
+func TestStepEnvInjectsVariablesAndMasksSecrets(t *testing.T) {
+	t.Parallel()
+
+	runner, store, issue, jobID := setupRunStepsJob(t, nil, "testing")
+	ctx := context.Background()
+	remote, workDir := cloneWithChanges(t, nil)
+
+	if err := os.WriteFile(filepath.Join(workDir, "env.sh"), []byte("#!/bin/sh\necho \"$API_KEY $MODE\"\n"), 0o755); err != nil {
+		t.Fatalf("write script: %v", err)
+	}
+	projectCfg := config.ProjectConfig{
+		Name:       "myproject",
+		RepoURL:    remote,
+		BaseBranch: "main",
+		TestCmd:    "./env.sh",
+		Env:        map[string]string{"API_KEY": "secret:api_key", "MODE": "plain"},
+		StepEnv:    map[string]map[string]string{"tests": {"MODE": "tests"}},
+	}
+	runner.cfg = &config.Config{
+		ReposRoot: t.TempDir(),
+		Secrets:   map[string]string{"api_key": "s3cr3t-value"},
+		Projects:  []config.ProjectConfig{projectCfg},
+	}
+
+	if err := runner.runTests(ctx, jobID, issue, &projectCfg, workDir); err != nil {
+		t.Fatalf("run tests: %v", err)
+	}
+	output, err := store.GetLatestArtifact(ctx, jobID, "test_output")
+	if err != nil {
+		t.Fatalf("get test_output: %v", err)
+	}
+	if output.Content != "[REDACTED] tests\n" {
+		t.Fatalf("test_output = %q, want the secret masked and the step override applied", output.Content)
+	}
+
+	want := []string{"API_KEY=s3cr3t-value", "MODE=plain"}
+	if got := runner.providerEnv(ctx, jobID, "plan"); !slices.Equal(got, want) {
+		t.Fatalf("providerEnv(plan) = %q, want %q", got, want)
+	}
+}
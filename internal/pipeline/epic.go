@@ -0,0 +1,121 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"slices"
+	"strings"
+
+	"autopr/internal/config"
+	"autopr/internal/db"
+)
+
+// taskListItemRE matches a Markdown task list item: "- [ ] text", with any
+// list marker and indentation.
+var taskListItemRE = regexp.MustCompile(`^(\s*)(?:[-*+]|\d+[.)])\s+\[([ xX])\]\s+(.*\S)\s*$`)
+
+// EpicItems returns the open items of the task list in body, with what each
+// waits for: the nearest open item it is nested under or, when sequential,
+// the open item before it. Items already checked off are done and left out.
+// Task lists inside fenced code blocks are ignored.
+func EpicItems(body string, sequential bool) []db.EpicItem {
+	type listed struct {
+		indent int
+		open   int // 1-based position among open items; 0 when checked
+	}
+	var (
+		items   []db.EpicItem
+		parents []listed // enclosing items of the current line, outermost first
+		fenced  bool
+	)
+	for line := range strings.SplitSeq(strings.ReplaceAll(body, "\r\n", "\n"), "\n") {
+		if trimmed := strings.TrimSpace(line); strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fenced = !fenced
+			continue
+		}
+		m := taskListItemRE.FindStringSubmatch(line)
+		if fenced || m == nil {
+			continue
+		}
+		indent := len(strings.ReplaceAll(m[1], "\t", "    "))
+		for len(parents) > 0 && parents[len(parents)-1].indent >= indent {
+			parents = parents[:len(parents)-1]
+		}
+		cur := listed{indent: indent}
+		if m[2] == " " {
+			item := db.EpicItem{Text: m[3]}
+			switch {
+			case sequential && len(items) > 0:
+				item.DependsOn = []int{len(items)}
+			case !sequential:
+				for i := len(parents) - 1; i >= 0; i-- {
+					if parents[i].open > 0 {
+						item.DependsOn = []int{parents[i].open}
+						break
+					}
+				}
+			}
+			items = append(items, item)
+			cur.open = len(items)
+		}
+		parents = append(parents, cur)
+	}
+	return items
+}
+
+// epicItemsFor returns the task jobs issue splits into under the project's
+// [projects.epics], or nil when it is not split.
+func epicItemsFor(proj *config.ProjectConfig, issue db.Issue) []db.EpicItem {
+	ep := proj.Epics
+	if ep == nil {
+		return nil
+	}
+	if ep.Label != "" && !slices.ContainsFunc(issue.Labels(), func(l string) bool { return strings.EqualFold(l, ep.Label) }) {
+		return nil
+	}
+	items := EpicItems(issue.Body, ep.Sequential)
+	if len(items) < ep.MinTasks {
+		return nil
+	}
+	return items
+}
+
+// splitEpic is the decomposition step of a new job for an issue with a task
+// list: the job takes on the first open item, and a job is queued for each
+// other one. Issues split before, and jobs created from another job, are left
+// alone. It returns job as it is after the split.
+func (r *Runner) splitEpic(ctx context.Context, proj *config.ProjectConfig, issue db.Issue, job db.Job) (db.Job, error) {
+	if job.EpicIndex > 0 || job.ParentJobID != "" || job.BackportBranch != "" || job.RevertCommit != "" || job.BisectCmd != "" {
+		return job, nil
+	}
+	items := epicItemsFor(proj, issue)
+	if items == nil {
+		return job, nil
+	}
+	if split, err := r.store.IsEpic(ctx, job.AutoPRIssueID); err != nil || split {
+		return job, err
+	}
+	ids, err := r.store.SplitEpic(ctx, job, items)
+	if err != nil {
+		return job, err
+	}
+	slog.Info("split epic into task jobs", "job", db.ShortID(job.ID), "issue", issue.Source+"#"+issue.SourceIssueID, "tasks", len(ids))
+	job.EpicIndex, job.EpicTask = 1, items[0].Text
+	return job, nil
+}
+
+// EpicTaskIssue is issue as an epic task job sees it: the task is the title,
+// and the body asks for that task alone before quoting the epic. Other jobs
+// get issue unchanged.
+func EpicTaskIssue(issue db.Issue, job db.Job) db.Issue {
+	if job.EpicIndex == 0 {
+		return issue
+	}
+	issue.Body = fmt.Sprintf("This is task %d of the epic %q. Do only this task:\n\n%s\n\n"+
+		"The epic's other tasks are done in separate jobs; leave them alone.\n\nThe epic:\n\n%s",
+		job.EpicIndex, issue.Title, job.EpicTask, issue.Body)
+	issue.Title = job.EpicTask
+	return issue
+}
@@ -0,0 +1,124 @@
+package pipeline
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"autopr/internal/config"
+	"autopr/internal/db"
+)
+
+func TestEpicItems(t *testing.T) {
+	t.Parallel()
+
+	body := "Rework login.\r\n\r\n" +
+		"- [ ] Add the session store\n" +
+		"  - [ ] Move tokens into it\n" +
+		"  - [x] Pick a backend\n" +
+		"    - [ ] Tune its pool\n" +
+		"* [X] Drop the old cookie\n" +
+		"  1. [ ] Clean up cookie tests\n" +
+		"```\n- [ ] not a task\n```\n" +
+		"- [ ] Update the docs\n"
+
+	got := EpicItems(body, false)
+	want := []db.EpicItem{
+		{Text: "Add the session store"},
+		{Text: "Move tokens into it", DependsOn: []int{1}},
+		{Text: "Tune its pool", DependsOn: []int{1}},
+		{Text: "Clean up cookie tests"},
+		{Text: "Update the docs"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("EpicItems =\n%+v\nwant\n%+v", got, want)
+	}
+
+	seq := EpicItems(body, true)
+	for i, item := range seq {
+		if i == 0 && item.DependsOn != nil || i > 0 && !reflect.DeepEqual(item.DependsOn, []int{i}) {
+			t.Fatalf("sequential item %d depends on %v", i+1, item.DependsOn)
+		}
+	}
+}
+
+func TestEpicItemsForHonorsLabelAndMinTasks(t *testing.T) {
+	t.Parallel()
+
+	issue := db.Issue{Body: "- [ ] one\n- [ ] two\n", LabelsJSON: `["Epic"]`}
+	proj := &config.ProjectConfig{Epics: &config.ProjectEpics{Label: "epic", MinTasks: 2}}
+	if items := epicItemsFor(proj, issue); len(items) != 2 {
+		t.Fatalf("expected 2 items, got %+v", items)
+	}
+	proj.Epics.MinTasks = 3
+	if items := epicItemsFor(proj, issue); items != nil {
+		t.Fatalf("expected no split below min_tasks, got %+v", items)
+	}
+	proj.Epics = &config.ProjectEpics{Label: "roadmap", MinTasks: 2}
+	if items := epicItemsFor(proj, issue); items != nil {
+		t.Fatalf("expected no split without the label, got %+v", items)
+	}
+	if items := epicItemsFor(&config.ProjectConfig{}, issue); items != nil {
+		t.Fatalf("expected no split without [projects.epics], got %+v", items)
+	}
+}
+
+func TestSplitEpicTurnsJobIntoFirstTask(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	store, err := db.Open(filepath.Join(t.TempDir(), "autopr.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+	issueID, err := store.UpsertIssue(ctx, db.IssueUpsert{
+		ProjectName:   "myproject",
+		Source:        "github",
+		SourceIssueID: "7",
+		Title:         "Rework login",
+		Body:          "- [ ] Add the session store\n- [ ] Update the docs\n",
+		URL:           "https://github.com/org/repo/issues/7",
+		State:         "open",
+	})
+	if err != nil {
+		t.Fatalf("upsert issue: %v", err)
+	}
+	jobID, err := store.CreateJob(ctx, issueID, "myproject", 3)
+	if err != nil {
+		t.Fatalf("create job: %v", err)
+	}
+	job, _ := store.GetJob(ctx, jobID)
+	issue, _ := store.GetIssueByAPID(ctx, issueID)
+
+	r := &Runner{store: store}
+	proj := &config.ProjectConfig{Name: "myproject", Epics: &config.ProjectEpics{MinTasks: 2}}
+	job, err = r.splitEpic(ctx, proj, issue, job)
+	if err != nil {
+		t.Fatalf("split epic: %v", err)
+	}
+	if job.EpicIndex != 1 || job.EpicTask != "Add the session store" {
+		t.Fatalf("job not turned into task 1: %+v", job)
+	}
+	tasks, err := store.ListEpicTasks(ctx, issueID)
+	if err != nil || len(tasks) != 2 || tasks[1].State != "queued" {
+		t.Fatalf("unexpected tasks %+v, %v", tasks, err)
+	}
+
+	// A later job for the same issue is not split again.
+	again, err := r.splitEpic(ctx, proj, issue, db.Job{ID: "other", AutoPRIssueID: issueID, ProjectName: "myproject"})
+	if err != nil || again.EpicIndex != 0 {
+		t.Fatalf("expected no second split, got %+v, %v", again, err)
+	}
+
+	task := EpicTaskIssue(issue, job)
+	if task.Title != "Add the session store" || !strings.Contains(task.Body, `task 1 of the epic "Rework login"`) || !strings.HasSuffix(task.Body, issue.Body) {
+		t.Fatalf("unexpected task issue: %+v", task)
+	}
+	title, body := BuildPRContent(ctx, store, nil, job, issue)
+	if title != "[AutoPR] Add the session store" || !strings.Contains(body, "Part of https://github.com/org/repo/issues/7 (task 1).") || strings.Contains(body, "Closes") {
+		t.Fatalf("unexpected PR content %q\n%s", title, body)
+	}
+}
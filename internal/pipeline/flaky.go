@@ -0,0 +1,84 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"slices"
+	"strings"
+
+	"autopr/internal/config"
+	"autopr/internal/db"
+)
+
+const flakyTestsArtifactKind = "flaky_tests"
+
+// unidentifiedFlakyTest stands in for the test name when the failing run's
+// output names no test in a format failedTestNames knows.
+const unidentifiedFlakyTest = "(unidentified)"
+
+// maxFlakyTestNames bounds how many names one run records, so a suite that
+// failed wholesale does not flood the report.
+const maxFlakyTestNames = 50
+
+// failedTestPatterns match the failing-test lines of common test runners;
+// the first group is the test name.
+var failedTestPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`^\s*--- FAIL: (\S+)`),           // go test
+	regexp.MustCompile(`^FAILED (\S+?)(?: - .*)?$`),     // pytest
+	regexp.MustCompile(`^test (\S+) \.\.\. FAILED$`),    // cargo test
+	regexp.MustCompile(`^rspec (\./\S+)`),               // rspec
+	regexp.MustCompile(`^\s*✕ (.+?)(?: \(\d+ m?s\))?$`), // jest, vitest
+}
+
+// failedTestNames returns the names of the tests output reports as failing,
+// sorted and deduplicated.
+func failedTestNames(output string) []string {
+	var names []string
+	for line := range strings.SplitSeq(output, "\n") {
+		line = strings.TrimRight(line, "\r")
+		for _, re := range failedTestPatterns {
+			if m := re.FindStringSubmatch(line); m != nil {
+				names = append(names, strings.TrimSpace(m[1]))
+				break
+			}
+		}
+	}
+	slices.Sort(names)
+	names = slices.Compact(names)
+	if len(names) > maxFlakyTestNames {
+		names = names[:maxFlakyTestNames]
+	}
+	return names
+}
+
+// recordFlakyTests records the tests that failed in failedOutput but passed
+// on the re-run: a flaky_tests artifact for the job and a count per test for
+// `ap stats`.
+func (r *Runner) recordFlakyTests(ctx context.Context, job db.Job, issue db.Issue, projectCfg *config.ProjectConfig, failedOutput string) {
+	names := failedTestNames(failedOutput)
+	if len(names) == 0 {
+		names = []string{unidentifiedFlakyTest}
+	}
+	slog.Info("flaky tests passed on re-run", "job", job.ID, "tests", len(names))
+	if err := r.store.RecordFlakyTests(ctx, projectCfg.Name, job.ID, names); err != nil {
+		slog.Warn("failed to record flaky tests", "job", job.ID, "err", err)
+	}
+	if _, err := r.store.CreateArtifact(ctx, job.ID, issue.AutoPRIssueID, flakyTestsArtifactKind, flakyTestsNote(names, failedOutput), job.Iteration, ""); err != nil {
+		slog.Warn("failed to store flaky_tests artifact", "job", job.ID, "err", err)
+	}
+}
+
+// flakyTestsNote is the flaky_tests artifact: the flaky tests and the output
+// of the run they failed in.
+func flakyTestsNote(names []string, failedOutput string) string {
+	var b strings.Builder
+	b.WriteString("Tests failed, then passed when the test command was re-run:\n")
+	for _, name := range names {
+		fmt.Fprintf(&b, "- %s\n", name)
+	}
+	b.WriteString("\nOutput of the failing run:\n")
+	b.WriteString(failedOutput)
+	return b.String()
+}
@@ -0,0 +1,94 @@
+package pipeline
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"autopr/internal/config"
+)
+
+func TestFailedTestNames(t *testing.T) {
+	t.Parallel()
+
+	output := strings.Join([]string{
+		"=== RUN   TestParse",
+		"--- FAIL: TestParse (0.01s)",
+		"    --- FAIL: TestParse/empty (0.00s)",
+		"FAILED tests/test_api.py::test_timeout - AssertionError: boom",
+		"test net::tests::retries ... FAILED",
+		"rspec ./spec/models/user_spec.rb:12 # User validates email",
+		"  ✕ renders the header (12 ms)",
+		"--- FAIL: TestParse (0.01s)",
+		"ok  	example.com/other	0.01s",
+	}, "\n")
+	want := []string{
+		"./spec/models/user_spec.rb:12",
+		"TestParse",
+		"TestParse/empty",
+		"net::tests::retries",
+		"renders the header",
+		"tests/test_api.py::test_timeout",
+	}
+	if got := failedTestNames(output); !slices.Equal(got, want) {
+		t.Fatalf("failedTestNames = %q, want %q", got, want)
+	}
+	if got := failedTestNames("make: *** [test] Error 1"); len(got) != 0 {
+		t.Fatalf("expected no names, got %q", got)
+	}
+}
+
+func TestRunTestsRetriesFlakyTestsOnce(t *testing.T) {
+	t.Parallel()
+
+	runner, store, issue, jobID := setupRunStepsJob(t, nil, "testing")
+	ctx := context.Background()
+	remote, workDir := cloneWithChanges(t, nil)
+
+	// Fails the first time it runs and passes after that.
+	script := "#!/bin/sh\nif [ -f .ran ]; then echo ok; exit 0; fi\ntouch .ran\necho '--- FAIL: TestFlaky (0.00s)'\nexit 1\n"
+	if err := os.WriteFile(filepath.Join(workDir, "flaky.sh"), []byte(script), 0o755); err != nil {
+		t.Fatalf("write script: %v", err)
+	}
+	projectCfg := &config.ProjectConfig{
+		Name:            "project",
+		RepoURL:         remote,
+		BaseBranch:      "main",
+		TestCmd:         "./flaky.sh",
+		RetryFlakyTests: true,
+	}
+
+	if err := runner.runTests(ctx, jobID, issue, projectCfg, workDir); err != nil {
+		t.Fatalf("run tests: %v", err)
+	}
+	artifact, err := store.GetLatestArtifact(ctx, jobID, flakyTestsArtifactKind)
+	if err != nil {
+		t.Fatalf("get flaky_tests: %v", err)
+	}
+	if !strings.Contains(artifact.Content, "- TestFlaky\n") {
+		t.Fatalf("unexpected flaky_tests artifact %q", artifact.Content)
+	}
+	output, err := store.GetLatestArtifact(ctx, jobID, "test_output")
+	if err != nil || strings.TrimSpace(output.Content) != "ok" {
+		t.Fatalf("test_output = %q, %v; want the passing run", output.Content, err)
+	}
+	flaky, err := store.ListFlakyTests(ctx, "project", "", false)
+	if err != nil {
+		t.Fatalf("list flaky tests: %v", err)
+	}
+	if len(flaky) != 1 || flaky[0].TestName != "TestFlaky" || flaky[0].Count != 1 || flaky[0].LastJobID != jobID {
+		t.Fatalf("flaky tests = %+v", flaky)
+	}
+
+	// Without retry_flaky_tests a failure stays a failure.
+	if err := os.Remove(filepath.Join(workDir, ".ran")); err != nil {
+		t.Fatalf("reset script: %v", err)
+	}
+	projectCfg.RetryFlakyTests = false
+	if err := runner.runTests(ctx, jobID, issue, projectCfg, workDir); err != errTestsFailed {
+		t.Fatalf("expected errTestsFailed, got %v", err)
+	}
+}
@@ -0,0 +1,80 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"autopr/internal/db"
+	"autopr/internal/git"
+)
+
+// maxFollowUpDiffBytes caps the merged diff embedded in follow-up notes so the
+// plan prompt stays a reasonable size.
+const maxFollowUpDiffBytes = 20000
+
+// CanFollowUp reports whether job is eligible to seed a follow-up job.
+func CanFollowUp(job *db.Job) bool {
+	return job != nil && job.State == "approved" && job.PRMergedAt != ""
+}
+
+// CreateFollowUpJob queues a new job linked to a merged parent job. The new
+// job works on the same issue and is seeded with the user's instructions and
+// the parent's merged diff (when the parent worktree is still available).
+// It is called from both the CLI and the TUI.
+func CreateFollowUpJob(ctx context.Context, store *db.Store, parent db.Job, baseBranch, instructions string) (string, error) {
+	instructions = strings.TrimSpace(instructions)
+	if instructions == "" {
+		return "", fmt.Errorf("follow-up instructions are required")
+	}
+	if !CanFollowUp(&parent) {
+		return "", fmt.Errorf("job %s must be approved with a merged PR to create a follow-up (state %q)", db.ShortID(parent.ID), parent.State)
+	}
+
+	activeID, err := store.GetActiveJobForIssue(ctx, parent.AutoPRIssueID)
+	if err != nil {
+		return "", err
+	}
+	if activeID != "" {
+		return "", fmt.Errorf("another active job (%s) already exists for this issue", db.ShortID(activeID))
+	}
+
+	notes := buildFollowUpNotes(parent, instructions, mergedDiff(ctx, parent, baseBranch))
+	return store.CreateFollowUpJob(ctx, parent, notes)
+}
+
+func mergedDiff(ctx context.Context, parent db.Job, baseBranch string) string {
+	if parent.WorktreePath == "" {
+		return ""
+	}
+	if _, err := os.Stat(parent.WorktreePath); err != nil {
+		return ""
+	}
+	diff, err := git.DiffAgainstBase(ctx, parent.WorktreePath, baseBranch)
+	if err != nil {
+		return ""
+	}
+	if len(diff) > maxFollowUpDiffBytes {
+		diff = diff[:maxFollowUpDiffBytes] + "\n... (diff truncated)\n"
+	}
+	return diff
+}
+
+func buildFollowUpNotes(parent db.Job, instructions, diff string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "This is a follow-up to job %s", db.ShortID(parent.ID))
+	if parent.PRURL != "" {
+		fmt.Fprintf(&b, " (merged PR: %s)", parent.PRURL)
+	}
+	b.WriteString(". The issue above was already addressed by that job's merged changes; do not redo them. ")
+	b.WriteString("Build on top of them according to these instructions:\n\n")
+	b.WriteString(instructions)
+	b.WriteString("\n")
+	if diff != "" {
+		b.WriteString("\nChanges merged by the previous job:\n\n```diff\n")
+		b.WriteString(strings.TrimRight(diff, "\n"))
+		b.WriteString("\n```\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,84 @@
+package pipeline
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"autopr/internal/db"
+)
+
+func TestCreateFollowUpJobRequiresMergedParent(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	store, err := db.Open(filepath.Join(t.TempDir(), "autopr.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer store.Close()
+
+	issueID, err := store.UpsertIssue(ctx, db.IssueUpsert{
+		ProjectName:   "myproject",
+		Source:        "github",
+		SourceIssueID: "5",
+		Title:         "fix parser",
+		URL:           "https://github.com/org/repo/issues/5",
+		State:         "open",
+	})
+	if err != nil {
+		t.Fatalf("upsert issue: %v", err)
+	}
+	parentID, err := store.CreateJob(ctx, issueID, "myproject", 3)
+	if err != nil {
+		t.Fatalf("create job: %v", err)
+	}
+	if _, err := store.ClaimJob(ctx); err != nil {
+		t.Fatalf("claim job: %v", err)
+	}
+	for _, step := range [][2]string{{"planning", "implementing"}, {"implementing", "reviewing"}, {"reviewing", "testing"}, {"testing", "ready"}, {"ready", "approved"}} {
+		if err := store.TransitionState(ctx, parentID, step[0], step[1]); err != nil {
+			t.Fatalf("%s->%s: %v", step[0], step[1], err)
+		}
+	}
+
+	parent, err := store.GetJob(ctx, parentID)
+	if err != nil {
+		t.Fatalf("get parent: %v", err)
+	}
+	if _, err := CreateFollowUpJob(ctx, store, parent, "main", "add tests"); err == nil {
+		t.Fatalf("expected error for unmerged parent")
+	}
+
+	if err := store.UpdateJobField(ctx, parentID, "pr_url", "https://github.com/org/repo/pull/9"); err != nil {
+		t.Fatalf("set pr_url: %v", err)
+	}
+	if err := store.UpdateJobField(ctx, parentID, "pr_merged_at", "2026-01-01T00:00:00Z"); err != nil {
+		t.Fatalf("set pr_merged_at: %v", err)
+	}
+	parent, err = store.GetJob(ctx, parentID)
+	if err != nil {
+		t.Fatalf("get parent: %v", err)
+	}
+	if _, err := CreateFollowUpJob(ctx, store, parent, "main", "   "); err == nil {
+		t.Fatalf("expected error for empty instructions")
+	}
+
+	childID, err := CreateFollowUpJob(ctx, store, parent, "main", "now add tests for the parser fix")
+	if err != nil {
+		t.Fatalf("create follow-up: %v", err)
+	}
+	child, err := store.GetJob(ctx, childID)
+	if err != nil {
+		t.Fatalf("get child: %v", err)
+	}
+	if child.ParentJobID != parentID {
+		t.Fatalf("expected parent link %s, got %q", parentID, child.ParentJobID)
+	}
+	for _, want := range []string{"follow-up to job " + db.ShortID(parentID), "https://github.com/org/repo/pull/9", "now add tests for the parser fix"} {
+		if !strings.Contains(child.HumanNotes, want) {
+			t.Fatalf("expected notes to contain %q, got:\n%s", want, child.HumanNotes)
+		}
+	}
+}
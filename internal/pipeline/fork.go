@@ -0,0 +1,76 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"autopr/internal/config"
+	"autopr/internal/git"
+)
+
+// GitHub creates forks in the background. These bound how long a push waits
+// for a fork it just asked for.
+var (
+	forkReadyTimeout = 2 * time.Minute
+	forkPollInterval = 5 * time.Second
+)
+
+// ensureGitHubFork checks that the project's fork exists and was forked from
+// the project repository, creating it first when github.create_fork is set.
+// It then syncs the fork's default branch with upstream so the fork does not
+// fall behind; a failed sync is logged, since job branches are built from
+// upstream and push fine either way.
+func ensureGitHubFork(ctx context.Context, proj *config.ProjectConfig, token string) error {
+	gh := proj.GitHub
+	forkName := gh.ForkOwner + "/" + gh.Repo
+	fork, found, err := git.GetGitHubRepo(ctx, token, gh.BaseURL, gh.ForkOwner, gh.Repo)
+	if err != nil {
+		return err
+	}
+	if !found {
+		if !gh.CreateFork {
+			return fmt.Errorf("fork %s not found; create it or set github.create_fork", forkName)
+		}
+		slog.Info("creating github fork", "project", proj.Name, "fork", forkName)
+		if err := git.CreateGitHubFork(ctx, token, gh.BaseURL, gh.Owner, gh.Repo, gh.ForkOwner); err != nil {
+			return err
+		}
+		if fork, err = waitForGitHubFork(ctx, gh, token); err != nil {
+			return err
+		}
+	}
+
+	upstream := gh.Owner + "/" + gh.Repo
+	if !fork.Fork || !strings.EqualFold(fork.ParentFullName(), upstream) {
+		return fmt.Errorf("%s is not a fork of %s", forkName, upstream)
+	}
+	if err := git.SyncGitHubFork(ctx, token, gh.BaseURL, gh.ForkOwner, gh.Repo, fork.DefaultBranch); err != nil {
+		slog.Warn("sync github fork", "project", proj.Name, "fork", forkName, "err", err)
+	}
+	return nil
+}
+
+// waitForGitHubFork polls for a fork GitHub is still creating.
+func waitForGitHubFork(ctx context.Context, gh *config.ProjectGitHub, token string) (git.GitHubRepo, error) {
+	deadline := time.Now().Add(forkReadyTimeout)
+	for {
+		fork, found, err := git.GetGitHubRepo(ctx, token, gh.BaseURL, gh.ForkOwner, gh.Repo)
+		if err != nil {
+			return git.GitHubRepo{}, err
+		}
+		if found {
+			return fork, nil
+		}
+		if time.Now().After(deadline) {
+			return git.GitHubRepo{}, fmt.Errorf("fork %s/%s was not ready after %s", gh.ForkOwner, gh.Repo, forkReadyTimeout)
+		}
+		select {
+		case <-ctx.Done():
+			return git.GitHubRepo{}, ctx.Err()
+		case <-time.After(forkPollInterval):
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"autopr/internal/config"
+)
+
+// fakeForkAPI serves the GitHub endpoints ensureGitHubFork calls for the fork
+// bots/repo of acme/repo.
+type fakeForkAPI struct {
+	mu         sync.Mutex
+	exists     bool
+	parent     string
+	login      string
+	forkBody   map[string]any
+	syncBranch string
+}
+
+func (f *fakeForkAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/api/v3/repos/bots/repo":
+		if !f.exists {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"full_name": "bots/repo", "default_branch": "main", "fork": f.parent != "",
+			"parent": map[string]string{"full_name": f.parent},
+		})
+	case r.Method == http.MethodGet && r.URL.Path == "/api/v3/user":
+		_ = json.NewEncoder(w).Encode(map[string]string{"login": f.login})
+	case r.Method == http.MethodPost && r.URL.Path == "/api/v3/repos/acme/repo/forks":
+		_ = json.NewDecoder(r.Body).Decode(&f.forkBody)
+		f.exists, f.parent = true, "acme/repo"
+		w.WriteHeader(http.StatusAccepted)
+	case r.Method == http.MethodPost && r.URL.Path == "/api/v3/repos/bots/repo/merge-upstream":
+		var body map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		f.syncBranch = body["branch"]
+		_, _ = w.Write([]byte(`{"merge_type":"fast-forward"}`))
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func forkTestProject(baseURL string, create bool) *config.ProjectConfig {
+	return &config.ProjectConfig{
+		Name:       "myproject",
+		BaseBranch: "main",
+		GitHub:     &config.ProjectGitHub{Owner: "acme", Repo: "repo", ForkOwner: "bots", BaseURL: baseURL, CreateFork: create},
+	}
+}
+
+func TestEnsureGitHubForkCreatesAndSyncsFork(t *testing.T) {
+	orig := forkPollInterval
+	forkPollInterval = time.Millisecond
+	t.Cleanup(func() { forkPollInterval = orig })
+
+	api := &fakeForkAPI{login: "autopr-bot"}
+	srv := httptest.NewServer(api)
+	defer srv.Close()
+
+	if err := ensureGitHubFork(context.Background(), forkTestProject(srv.URL, false), "tok"); err == nil || !strings.Contains(err.Error(), "set github.create_fork") {
+		t.Fatalf("expected missing fork error without create_fork, got %v", err)
+	}
+	if err := ensureGitHubFork(context.Background(), forkTestProject(srv.URL, true), "tok"); err != nil {
+		t.Fatalf("ensure fork: %v", err)
+	}
+	if api.forkBody["organization"] != "bots" || api.forkBody["default_branch_only"] != true {
+		t.Fatalf("unexpected fork request: %v", api.forkBody)
+	}
+	if api.syncBranch != "main" {
+		t.Fatalf("expected fork main to be synced, got %q", api.syncBranch)
+	}
+}
+
+func TestEnsureGitHubForkForksIntoTokenUser(t *testing.T) {
+	api := &fakeForkAPI{login: "bots"}
+	srv := httptest.NewServer(api)
+	defer srv.Close()
+
+	if err := ensureGitHubFork(context.Background(), forkTestProject(srv.URL, true), "tok"); err != nil {
+		t.Fatalf("ensure fork: %v", err)
+	}
+	if _, ok := api.forkBody["organization"]; ok {
+		t.Fatalf("expected no organization when forking into the token's user, got %v", api.forkBody)
+	}
+}
+
+func TestEnsureGitHubForkRejectsUnrelatedRepo(t *testing.T) {
+	api := &fakeForkAPI{exists: true, parent: "someone/else"}
+	srv := httptest.NewServer(api)
+	defer srv.Close()
+
+	err := ensureGitHubFork(context.Background(), forkTestProject(srv.URL, true), "tok")
+	if err == nil || !strings.Contains(err.Error(), "bots/repo is not a fork of acme/repo") {
+		t.Fatalf("expected not-a-fork error, got %v", err)
+	}
+	if api.syncBranch != "" {
+		t.Fatalf("expected no sync of an unrelated repo")
+	}
+}
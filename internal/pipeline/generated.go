@@ -0,0 +1,88 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"autopr/internal/config"
+	"autopr/internal/db"
+	"autopr/internal/git"
+)
+
+const generatedFilesArtifactKind = "generated_files"
+
+// regenerateGenerated runs the project's regenerate_cmd in the test
+// directory and commits whatever it rewrites, so generated files match their
+// sources before tests run. It returns the command output on failure.
+func (r *Runner) regenerateGenerated(ctx context.Context, jobID string, projectCfg *config.ProjectConfig, workDir string, env []string) (string, error) {
+	if projectCfg.Generated == nil || projectCfg.Generated.RegenerateCmd == "" {
+		return "", nil
+	}
+	output, err := runTestCommand(ctx, scopeDir(workDir, projectCfg), projectCfg.Generated.RegenerateCmd, env)
+	if err != nil {
+		return output, err
+	}
+	sha, commitErr := git.CommitAll(ctx, workDir, "autopr: regenerate generated files")
+	if commitErr != nil {
+		slog.Debug("regenerate left no changes", "job", jobID)
+		return "", nil
+	}
+	slog.Info("regenerated generated files", "job", jobID, "sha", sha)
+	_ = r.store.UpdateJobField(ctx, jobID, "commit_sha", sha)
+	return "", nil
+}
+
+// recordGeneratedFiles stores a generated_files artifact listing the
+// generated files the branch changes, for the PR body. Nothing is recorded
+// when the branch touches none.
+func (r *Runner) recordGeneratedFiles(ctx context.Context, job db.Job, issue db.Issue, projectCfg *config.ProjectConfig, workDir string) {
+	if projectCfg.Generated == nil || len(projectCfg.Generated.Paths) == 0 {
+		return
+	}
+	changes, err := git.DiffNumstatAgainstBase(ctx, workDir, TargetBranch(job, projectCfg))
+	if err != nil {
+		slog.Warn("failed to list generated files", "job", job.ID, "err", err)
+		return
+	}
+	var files []string
+	for _, c := range changes {
+		if projectCfg.IsGenerated(c.Path) {
+			files = append(files, c.Path)
+		}
+	}
+	if len(files) == 0 {
+		return
+	}
+	if _, err := r.store.CreateArtifact(ctx, job.ID, issue.AutoPRIssueID, generatedFilesArtifactKind, generatedFilesNote(projectCfg, files), job.Iteration, ""); err != nil {
+		slog.Warn("failed to store generated_files artifact", "job", job.ID, "err", err)
+	}
+}
+
+// generatedFilesNote is the PR body note for the generated files a branch changes.
+func generatedFilesNote(projectCfg *config.ProjectConfig, files []string) string {
+	var b strings.Builder
+	if cmd := projectCfg.Generated.RegenerateCmd; cmd != "" {
+		fmt.Fprintf(&b, "Regenerated with `%s`; not reviewed by hand and not counted in diff size limits:\n", cmd)
+	} else {
+		b.WriteString("Generated files, not reviewed by hand and not counted in diff size limits:\n")
+	}
+	for _, f := range files {
+		fmt.Fprintf(&b, "- `%s`\n", f)
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// withGeneratedRules tells the implement and review steps which files are
+// generated, so the LLM neither hand-edits nor reviews them.
+func withGeneratedRules(prompt string, projectCfg *config.ProjectConfig) string {
+	if projectCfg.Generated == nil || len(projectCfg.Generated.Paths) == 0 {
+		return prompt
+	}
+	rule := "Files matching " + strings.Join(projectCfg.Generated.Paths, ", ") + " are generated. Do not edit them by hand"
+	if cmd := projectCfg.Generated.RegenerateCmd; cmd != "" {
+		rule += "; `" + cmd + "` regenerates them before tests run"
+	}
+	return prompt + "\n\n<generated_files>\n" + rule + ".\nLeave them out of code review.\n</generated_files>"
+}
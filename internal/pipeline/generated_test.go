@@ -0,0 +1,121 @@
+package pipeline
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"autopr/internal/config"
+)
+
+func TestRunTestsRegeneratesAndRecordsGeneratedFiles(t *testing.T) {
+	t.Parallel()
+
+	runner, store, issue, jobID := setupRunStepsJob(t, nil, "testing")
+	ctx := context.Background()
+	remote, workDir := cloneWithChanges(t, map[string]string{"api/api.proto": "message Ping {}\n"})
+	runGitCmdLocal(t, workDir, "config", "user.email", "test@example.com")
+	runGitCmdLocal(t, workDir, "config", "user.name", "Test")
+
+	projectCfg := &config.ProjectConfig{
+		Name:       "project",
+		RepoURL:    remote,
+		BaseBranch: "main",
+		TestCmd:    "test -f api/api.pb.go",
+		Generated:  &config.ProjectGenerated{Paths: []string{"*.pb.go"}, RegenerateCmd: "cp api/api.proto api/api.pb.go"},
+	}
+	if err := runner.runTests(ctx, jobID, issue, projectCfg, workDir); err != nil {
+		t.Fatalf("run tests: %v", err)
+	}
+	out, err := exec.Command("git", "-C", workDir, "log", "-1", "--format=%s").Output()
+	if err != nil {
+		t.Fatalf("git log: %v", err)
+	}
+	if msg := strings.TrimSpace(string(out)); msg != "autopr: regenerate generated files" {
+		t.Fatalf("expected regenerate commit, got %q", msg)
+	}
+	artifact, err := store.GetLatestArtifact(ctx, jobID, generatedFilesArtifactKind)
+	if err != nil {
+		t.Fatalf("get generated_files: %v", err)
+	}
+	if !strings.Contains(artifact.Content, "`api/api.pb.go`") || strings.Contains(artifact.Content, "- `api/api.proto`") {
+		t.Fatalf("unexpected generated files note %q", artifact.Content)
+	}
+
+	job, err := store.GetJob(ctx, jobID)
+	if err != nil {
+		t.Fatalf("get job: %v", err)
+	}
+	_, body := BuildPRContent(ctx, store, nil, job, issue)
+	if !strings.Contains(body, "<summary>Generated files</summary>") || !strings.Contains(body, "Regenerated with `cp api/api.proto api/api.pb.go`") {
+		t.Fatalf("expected generated files note in PR body, got:\n%s", body)
+	}
+}
+
+func TestRunTestsFailsWhenRegenerateFails(t *testing.T) {
+	t.Parallel()
+
+	runner, store, issue, jobID := setupRunStepsJob(t, nil, "testing")
+	ctx := context.Background()
+	remote, workDir := cloneWithChanges(t, nil)
+
+	projectCfg := &config.ProjectConfig{
+		Name:       "project",
+		RepoURL:    remote,
+		BaseBranch: "main",
+		TestCmd:    "git status",
+		Generated:  &config.ProjectGenerated{Paths: []string{"*.pb.go"}, RegenerateCmd: "git rev-parse --verify refs/heads/no-such-branch"},
+	}
+	if err := runner.runTests(ctx, jobID, issue, projectCfg, workDir); err != errTestsFailed {
+		t.Fatalf("expected errTestsFailed, got %v", err)
+	}
+	artifact, err := store.GetLatestArtifact(ctx, jobID, "test_output")
+	if err != nil {
+		t.Fatalf("get test_output: %v", err)
+	}
+	if !strings.HasPrefix(artifact.Content, "Regenerate command failed:") {
+		t.Fatalf("unexpected test output %q", artifact.Content)
+	}
+}
+
+func TestOversizeIgnoresGeneratedFiles(t *testing.T) {
+	t.Parallel()
+
+	runner, _, _, jobID := setupRunStepsJob(t, nil, "testing")
+	ctx := context.Background()
+	remote, workDir := cloneWithChanges(t, map[string]string{
+		"main.go":           "package main\n",
+		"package-lock.json": strings.Repeat("{}\n", 50),
+	})
+
+	projectCfg := &config.ProjectConfig{
+		Name:         "project",
+		RepoURL:      remote,
+		BaseBranch:   "main",
+		TestCmd:      "git status",
+		MaxDiffFiles: 1,
+		MaxDiffLines: 10,
+		Generated:    &config.ProjectGenerated{Paths: []string{"package-lock.json"}},
+	}
+	paused, err := runner.pauseIfOversize(ctx, jobID, projectCfg, workDir, "testing")
+	if err != nil || paused {
+		t.Fatalf("expected generated lockfile to be ignored, got paused=%v err=%v", paused, err)
+	}
+}
+
+func TestWithGeneratedRules(t *testing.T) {
+	t.Parallel()
+
+	proj := &config.ProjectConfig{}
+	if got := withGeneratedRules("prompt", proj); got != "prompt" {
+		t.Fatalf("prompt without generated files changed: %q", got)
+	}
+	proj.Generated = &config.ProjectGenerated{Paths: []string{"*.pb.go", "package-lock.json"}, RegenerateCmd: "make generate"}
+	got := withGeneratedRules("prompt", proj)
+	for _, want := range []string{"Files matching *.pb.go, package-lock.json are generated", "`make generate` regenerates them"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("prompt missing %q: %q", want, got)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package pipeline
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+
+	"autopr/internal/config"
+	"autopr/internal/git"
+	"autopr/internal/githubapp"
+)
+
+// GitTokenForProject returns the token used for clone, fetch, and push. For
+// projects with a GitHub App this is a freshly minted installation token;
+// otherwise it is the configured source token. If minting fails, the error is
+// logged and the source token is used so the git error surfaces downstream.
+func GitTokenForProject(ctx context.Context, cfg *config.Config, proj *config.ProjectConfig) string {
+	if proj == nil || !proj.GitHub.UsesApp() {
+		return cfg.GitTokenForProject(proj)
+	}
+	token, err := githubapp.Token(ctx, cfg, proj)
+	if err != nil {
+		slog.Warn("github app token unavailable, falling back to github token", "project", proj.Name, "err", err)
+		return cfg.GitTokenForProject(proj)
+	}
+	return token
+}
+
+// RemoteAuthForProject returns the non-token transport settings persisted in
+// a project's job clones.
+func RemoteAuthForProject(proj *config.ProjectConfig) git.RemoteAuth {
+	if proj == nil || proj.GitAuth == nil {
+		return git.RemoteAuth{}
+	}
+	return git.RemoteAuth{
+		CredentialHelper: proj.GitAuth.CredentialHelper,
+		SSHCommand:       proj.GitAuth.SSHCommand,
+	}
+}
+
+// forkRemoteURL returns the fork remote, using SSH when the project's own
+// repo_url does so that SSH-only setups need no token for fork pushes.
+func forkRemoteURL(proj *config.ProjectConfig) string {
+	remote := proj.GitHub.GitHubForkRemote()
+	if remote != "" && git.IsSSHRemoteURL(proj.RepoURL) {
+		return git.SSHRemoteURL(remote)
+	}
+	return remote
+}
+
+// BranchRemoteURL returns the remote job branches are pushed to: the fork
+// when github.fork_owner is set, otherwise the project's repo_url.
+func BranchRemoteURL(proj *config.ProjectConfig) string {
+	if proj.GitHub != nil && strings.TrimSpace(proj.GitHub.ForkOwner) != "" {
+		return forkRemoteURL(proj)
+	}
+	return proj.RepoURL
+}
+
+// needsTokenForRemote reports whether pushing to remoteURL relies on the
+// source token, i.e. it is HTTPS without a configured credential helper.
+func needsTokenForRemote(proj *config.ProjectConfig, remoteURL string) bool {
+	if git.IsSSHRemoteURL(remoteURL) {
+		return false
+	}
+	return proj.GitAuth == nil || strings.TrimSpace(proj.GitAuth.CredentialHelper) == ""
+}
@@ -0,0 +1,194 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"autopr/internal/config"
+	"autopr/internal/db"
+	"autopr/internal/executor"
+	"autopr/internal/kube"
+)
+
+const hookOutputArtifactKind = "hook_output"
+
+// maxHookOutput caps the hook output kept in a hook_output artifact.
+const maxHookOutput = 100000
+
+// errHookBlocked marks a job held at a lifecycle point by a project hook that
+// exited non-zero.
+var errHookBlocked = errors.New("blocked by hook")
+
+// HookContext is the job context a project hook reads as JSON on stdin.
+type HookContext struct {
+	Point      string    `json:"point"`
+	Hook       string    `json:"hook"`
+	JobID      string    `json:"job_id"`
+	Project    string    `json:"project"`
+	State      string    `json:"state"`
+	Iteration  int       `json:"iteration"`
+	Worktree   string    `json:"worktree"`
+	Branch     string    `json:"branch"`
+	BaseBranch string    `json:"base_branch"`
+	CommitSHA  string    `json:"commit_sha,omitempty"`
+	PRURL      string    `json:"pr_url,omitempty"`
+	Plan       string    `json:"plan,omitempty"` // latest plan; set at post_plan
+	Issue      HookIssue `json:"issue"`
+}
+
+// HookIssue is the source issue in a HookContext.
+type HookIssue struct {
+	Source string `json:"source"`
+	ID     string `json:"id"`
+	Title  string `json:"title"`
+	Body   string `json:"body"`
+	URL    string `json:"url"`
+}
+
+// RunHooks runs the project's hooks for a lifecycle point in config order, in
+// the job worktree through the project's executor, with the env of the
+// "hooks" step. The first hook that exits non-zero stops the run: its output,
+// with secrets masked, is stored as a hook_output artifact and the returned
+// error wraps errHookBlocked.
+func RunHooks(ctx context.Context, cfg *config.Config, store *db.Store, proj *config.ProjectConfig, job db.Job, issue db.Issue, point string) error {
+	hooks := proj.HooksAt(point)
+	if len(hooks) == 0 {
+		return nil
+	}
+	ctx, err := withProjectExecutor(ctx, cfg, proj, job.ID)
+	if err != nil {
+		return fmt.Errorf("hook executor: %w", err)
+	}
+	env, secrets := stepEnv(cfg, proj, "hooks")
+	hc := HookContext{
+		Point:      point,
+		JobID:      job.ID,
+		Project:    job.ProjectName,
+		State:      job.State,
+		Iteration:  job.Iteration,
+		Worktree:   job.WorktreePath,
+		Branch:     job.BranchName,
+		BaseBranch: TargetBranch(job, proj),
+		CommitSHA:  job.CommitSHA,
+		PRURL:      job.PRURL,
+		Issue: HookIssue{
+			Source: issue.Source,
+			ID:     issue.SourceIssueID,
+			Title:  issue.Title,
+			Body:   issue.Body,
+			URL:    issue.URL,
+		},
+	}
+	if point == config.HookPostPlan {
+		if plan, err := store.GetLatestArtifact(ctx, job.ID, "plan"); err == nil {
+			hc.Plan = plan.Content
+		}
+	}
+
+	for _, hook := range hooks {
+		hc.Hook = hook.Name
+		input, err := json.Marshal(hc)
+		if err != nil {
+			return fmt.Errorf("encode hook context: %w", err)
+		}
+		slog.Info("running hook", "job", job.ID, "point", point, "hook", hook.Name)
+		output, err := runHook(ctx, hook, job.WorktreePath, env, input)
+		if err == nil {
+			continue
+		}
+		if ctx.Err() != nil {
+			return context.Canceled
+		}
+		content := maskSecrets(fmt.Sprintf("Hook %s (%s) failed: %v\n$ %s\n%s", hook.Name, point, err, strings.Join(hook.Command, " "), output), secrets)
+		if _, aerr := store.CreateArtifact(ctx, job.ID, job.AutoPRIssueID, hookOutputArtifactKind, content, job.Iteration, ""); aerr != nil {
+			slog.Warn("failed to store hook_output artifact", "job", job.ID, "err", aerr)
+		}
+		slog.Info("hook blocked job", "job", job.ID, "point", point, "hook", hook.Name, "err", err)
+		return fmt.Errorf("%w %s at %s: %v (output in the hook_output artifact)", errHookBlocked, hook.Name, point, maskSecrets(err.Error(), secrets))
+	}
+	return nil
+}
+
+// withProjectExecutor returns ctx, or when it carries no executor, as outside
+// the pipeline (ap approve, the TUI, chatops), a context that runs jobID's
+// commands through proj's executor.
+func withProjectExecutor(ctx context.Context, cfg *config.Config, proj *config.ProjectConfig, jobID string) (context.Context, error) {
+	if executor.HasJob(ctx) || cfg == nil {
+		return ctx, nil
+	}
+	var kubeEx *kube.Executor
+	if proj.Executor == config.ExecutorKubernetes {
+		var err error
+		if kubeEx, err = kube.NewExecutor(cfg); err != nil {
+			return nil, err
+		}
+	}
+	ex, err := projectExecutor(cfg, proj, kubeEx)
+	if err != nil {
+		return nil, err
+	}
+	return executor.WithJob(ctx, ex, jobID), nil
+}
+
+// runHook runs one hook with input on stdin and returns its combined output.
+// A relative command path is resolved against the worktree.
+func runHook(ctx context.Context, hook config.ProjectHook, dir string, env []string, input []byte) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, hook.TimeoutDuration())
+	defer cancel()
+
+	env = append(slices.Clip(env), "AUTOPR_HOOK_POINT="+hook.Point, "AUTOPR_HOOK_NAME="+hook.Name)
+	out, err := executor.Command(ctx, executor.Spec{Dir: dir, Env: env, Argv: hook.Command, Stdin: input}).CombinedOutput()
+	output := string(out)
+	if len(output) > maxHookOutput {
+		output = output[:maxHookOutput] + "\n... (truncated)"
+	}
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return output, fmt.Errorf("timed out after %s", hook.TimeoutDuration())
+	}
+	return output, err
+}
+
+// runPlanAndHooks runs the plan step, then the project's post_plan hooks.
+func (r *Runner) runPlanAndHooks(ctx context.Context, jobID string, issue db.Issue, projectCfg *config.ProjectConfig, workDir string) error {
+	if err := r.runPlan(ctx, jobID, issue, projectCfg, workDir); err != nil {
+		return err
+	}
+	job, err := r.store.GetJob(ctx, jobID)
+	if err != nil {
+		return err
+	}
+	job.WorktreePath = workDir
+	return RunHooks(ctx, r.cfg, r.store, projectCfg, job, issue, config.HookPostPlan)
+}
+
+// runPostReadyHooks runs the project's post_ready hooks for a job the
+// pipeline has just moved to ready, and rejects the job if one blocks it, so
+// it is neither approved nor auto-PR'd. It reports whether the job may go on.
+func (r *Runner) runPostReadyHooks(ctx context.Context, jobID string, issue db.Issue, projectCfg *config.ProjectConfig) (bool, error) {
+	if len(projectCfg.HooksAt(config.HookPostReady)) == 0 {
+		return true, nil
+	}
+	job, err := r.store.GetJob(ctx, jobID)
+	if err != nil {
+		return false, err
+	}
+	if job.State != "ready" {
+		return true, nil
+	}
+	err = RunHooks(ctx, r.cfg, r.store, projectCfg, job, issue, config.HookPostReady)
+	if err == nil {
+		return true, nil
+	}
+	if !errors.Is(err, errHookBlocked) {
+		return false, err
+	}
+	if err := r.store.RejectJob(ctx, jobID, "ready", err.Error()); err != nil {
+		return false, err
+	}
+	return false, nil
+}
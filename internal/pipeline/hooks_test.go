@@ -0,0 +1,168 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"autopr/internal/config"
+	"autopr/internal/executor"
+)
+
+// writeHookScript writes an executable shell script for a hook test.
+func writeHookScript(t *testing.T, dir, name, body string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+body), 0o755); err != nil {
+		t.Fatalf("write hook script: %v", err)
+	}
+	return path
+}
+
+func TestRunHooksPassesContextAndBlocksOnFailure(t *testing.T) {
+	t.Parallel()
+
+	_, store, issue, jobID := setupRunStepsJob(t, nil, "testing")
+	ctx := context.Background()
+	dir := t.TempDir()
+	stdinFile := filepath.Join(dir, "stdin.json")
+	pass := writeHookScript(t, dir, "pass.sh", "cat > "+stdinFile+"\n")
+	fail := writeHookScript(t, dir, "fail.sh", "echo \"license header missing in $AUTOPR_HOOK_POINT ($LICENSE_MODE, token $LICENSE_TOKEN)\"\nexit 3\n")
+
+	proj := &config.ProjectConfig{
+		Name:       "project",
+		BaseBranch: "main",
+		Hooks: []config.ProjectHook{
+			{Name: "record", Point: config.HookPrePush, Command: []string{pass}},
+			{Name: "license", Point: config.HookPrePush, Command: []string{fail}},
+			{Name: "after", Point: config.HookPostReady, Command: []string{fail}},
+		},
+		Env:     map[string]string{"LICENSE_MODE": "loose", "LICENSE_TOKEN": "secret:license"},
+		StepEnv: map[string]map[string]string{"hooks": {"LICENSE_MODE": "strict"}},
+	}
+	cfg := &config.Config{Secrets: map[string]string{"license": "s3cr3t-value"}}
+	job, err := store.GetJob(ctx, jobID)
+	if err != nil {
+		t.Fatalf("get job: %v", err)
+	}
+	job.WorktreePath = dir
+
+	err = RunHooks(ctx, cfg, store, proj, job, issue, config.HookPrePush)
+	if !errors.Is(err, errHookBlocked) || !strings.Contains(err.Error(), "license at pre_push") {
+		t.Fatalf("expected license hook to block, got %v", err)
+	}
+
+	var hc HookContext
+	data, err := os.ReadFile(stdinFile)
+	if err != nil {
+		t.Fatalf("read hook stdin: %v", err)
+	}
+	if err := json.Unmarshal(data, &hc); err != nil {
+		t.Fatalf("decode hook stdin: %v", err)
+	}
+	if hc.Point != config.HookPrePush || hc.Hook != "record" || hc.JobID != jobID || hc.BaseBranch != "main" || hc.Issue.Title != issue.Title {
+		t.Fatalf("unexpected hook context %+v", hc)
+	}
+
+	artifact, err := store.GetLatestArtifact(ctx, jobID, hookOutputArtifactKind)
+	if err != nil {
+		t.Fatalf("get hook_output artifact: %v", err)
+	}
+	if !strings.Contains(artifact.Content, "Hook license (pre_push) failed") || !strings.Contains(artifact.Content, "license header missing in pre_push (strict, token [REDACTED])") {
+		t.Fatalf("unexpected artifact content %q", artifact.Content)
+	}
+	if strings.Contains(artifact.Content, "s3cr3t-value") {
+		t.Fatalf("expected the secret masked in the artifact, got %q", artifact.Content)
+	}
+
+	// Points without hooks pass.
+	if err := RunHooks(ctx, cfg, store, proj, job, issue, config.HookPostPlan); err != nil {
+		t.Fatalf("expected no post_plan hooks to pass, got %v", err)
+	}
+}
+
+// recordingExecutor runs commands locally and records their specs.
+type recordingExecutor struct {
+	mu    sync.Mutex
+	specs []executor.Spec
+}
+
+func (e *recordingExecutor) Command(ctx context.Context, spec executor.Spec) executor.Process {
+	e.mu.Lock()
+	e.specs = append(e.specs, spec)
+	e.mu.Unlock()
+	return executor.Local{}.Command(ctx, spec)
+}
+
+func TestRunHooksUsesTheJobsExecutor(t *testing.T) {
+	t.Parallel()
+
+	_, store, issue, jobID := setupRunStepsJob(t, nil, "testing")
+	dir := t.TempDir()
+	proj := &config.ProjectConfig{
+		Name:       "project",
+		BaseBranch: "main",
+		Hooks:      []config.ProjectHook{{Name: "check", Point: config.HookPrePush, Command: []string{"cat"}}},
+	}
+	job, err := store.GetJob(context.Background(), jobID)
+	if err != nil {
+		t.Fatalf("get job: %v", err)
+	}
+	job.WorktreePath = dir
+
+	rec := &recordingExecutor{}
+	ctx := executor.WithJob(context.Background(), rec, jobID)
+	if err := RunHooks(ctx, &config.Config{}, store, proj, job, issue, config.HookPrePush); err != nil {
+		t.Fatalf("run hooks: %v", err)
+	}
+	if len(rec.specs) != 1 {
+		t.Fatalf("expected the hook to run through the job's executor, got %d commands", len(rec.specs))
+	}
+	spec := rec.specs[0]
+	if spec.JobID != jobID || spec.Dir != dir || !slices.Contains(spec.Env, "AUTOPR_HOOK_POINT=pre_push") || !strings.Contains(string(spec.Stdin), `"hook":"check"`) {
+		t.Fatalf("unexpected hook spec %+v", spec)
+	}
+}
+
+func TestRunPostReadyHooksRejectsBlockedJob(t *testing.T) {
+	t.Parallel()
+
+	runner, store, issue, jobID := setupRunStepsJob(t, nil, "testing")
+	ctx := context.Background()
+	if err := store.TransitionState(ctx, jobID, "testing", "ready"); err != nil {
+		t.Fatalf("testing->ready: %v", err)
+	}
+	fail := writeHookScript(t, t.TempDir(), "fail.sh", "echo nope\nexit 1\n")
+	proj := &config.ProjectConfig{
+		Name:       "project",
+		BaseBranch: "main",
+		Hooks:      []config.ProjectHook{{Name: "sign-off", Point: config.HookPostReady, Command: []string{fail}}},
+	}
+
+	ok, err := runner.runPostReadyHooks(ctx, jobID, issue, proj)
+	if err != nil || ok {
+		t.Fatalf("expected the job to be held back, got ok=%v err=%v", ok, err)
+	}
+	job, err := store.GetJob(ctx, jobID)
+	if err != nil {
+		t.Fatalf("get job: %v", err)
+	}
+	if job.State != "rejected" || !strings.Contains(job.RejectReason, "sign-off at post_ready") {
+		t.Fatalf("expected rejected job, got state %q reason %q", job.State, job.RejectReason)
+	}
+}
+
+func TestRunHookTimesOut(t *testing.T) {
+	t.Parallel()
+
+	hook := config.ProjectHook{Name: "slow", Point: config.HookPostPlan, Command: []string{"sleep", "5"}, Timeout: "50ms"}
+	if _, err := runHook(context.Background(), hook, t.TempDir(), nil, nil); err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Fatalf("expected timeout, got %v", err)
+	}
+}
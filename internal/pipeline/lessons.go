@@ -0,0 +1,144 @@
+package pipeline
+
+import (
+	"cmp"
+	"context"
+	"log/slog"
+	"regexp"
+	"slices"
+	"strings"
+
+	"autopr/internal/config"
+	"autopr/internal/db"
+)
+
+var lessonsBlockRe = regexp.MustCompile(`(?is)<lessons>(.*?)</lessons>`)
+
+// reviewLessonsRequest asks the code review step to state the repository
+// conventions behind the changes it asks for, for the project's later jobs.
+const reviewLessonsRequest = `
+
+## Lessons
+
+If you ask for changes because the code breaks a convention of this repository that every future change should follow (e.g. "use the internal logger, not fmt.Println"), state each convention as a short, general rule at the very end of your response, one per line starting with "- ":
+
+<lessons>
+- ...
+</lessons>
+
+Leave out anything specific to this issue. Omit the block when there is nothing to add.`
+
+// feedbackLessonsRequest asks the plan step to distill the human notes it was
+// given into rules for the project's later jobs.
+const feedbackLessonsRequest = `
+
+## Lessons
+
+The human notes above are feedback from a reviewer of this repository. If they hold guidance that applies beyond this issue (a convention, a library to use or avoid, a file to keep out of changes), state each as a short, general rule at the very end of your response, one per line starting with "- ":
+
+<lessons>
+- ...
+</lessons>
+
+Omit the block when the feedback is about this issue only.`
+
+// extractLessons splits a step response into its <lessons> items and the
+// remaining text.
+func extractLessons(text string) ([]string, string) {
+	var items []string
+	for _, m := range lessonsBlockRe.FindAllStringSubmatch(text, -1) {
+		for _, line := range strings.Split(m[1], "\n") {
+			line = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(line), "-*"))
+			if line == "" || line == "..." {
+				continue
+			}
+			items = append(items, line)
+		}
+	}
+	return items, strings.TrimSpace(lessonsBlockRe.ReplaceAllString(text, ""))
+}
+
+// recordLessons stores lessons a step reported for the job's project.
+func (r *Runner) recordLessons(ctx context.Context, job db.Job, source string, items []string) {
+	for _, item := range items {
+		_, isNew, err := r.store.AddLesson(ctx, job.ProjectName, item, source, job.ID)
+		if err != nil {
+			slog.Warn("failed to store lesson", "job", job.ID, "err", err)
+			continue
+		}
+		if isNew {
+			slog.Info("lesson learned", "job", db.ShortID(job.ID), "project", job.ProjectName, "source", source)
+		}
+	}
+}
+
+// withLessons appends the project's lessons that best match the issue to a
+// plan, implement, or review prompt.
+func (r *Runner) withLessons(ctx context.Context, prompt string, proj *config.ProjectConfig, issue db.Issue) string {
+	if proj.Lessons == nil {
+		return prompt
+	}
+	lessons, err := r.store.ListLessons(ctx, proj.Name)
+	if err != nil {
+		slog.Warn("failed to load lessons", "project", proj.Name, "err", err)
+		return prompt
+	}
+	lessons = RelevantLessons(lessons, issue, proj.Lessons.PerPrompt)
+	if len(lessons) == 0 {
+		return prompt
+	}
+	var b strings.Builder
+	b.WriteString("\n\n<project_lessons>\nLessons from earlier reviews of changes to this repository. Follow them where they apply:\n")
+	for _, l := range lessons {
+		b.WriteString("- " + l.Text + "\n")
+	}
+	b.WriteString("</project_lessons>")
+	return prompt + b.String()
+}
+
+// RelevantLessons returns at most n of lessons, those sharing the most words
+// with the issue's title and body first, then those learned most often, then
+// the most recently learned.
+func RelevantLessons(lessons []db.Lesson, issue db.Issue, n int) []db.Lesson {
+	issueWords := lessonWords(issue.Title + "\n" + issue.Body)
+	score := make(map[int64]int, len(lessons))
+	for _, l := range lessons {
+		for w := range lessonWords(l.Text) {
+			if issueWords[w] {
+				score[l.ID]++
+			}
+		}
+	}
+	ranked := slices.Clone(lessons)
+	slices.SortStableFunc(ranked, func(a, b db.Lesson) int {
+		return cmp.Or(
+			cmp.Compare(score[b.ID], score[a.ID]),
+			cmp.Compare(b.TimesLearned, a.TimesLearned),
+			strings.Compare(b.UpdatedAt, a.UpdatedAt),
+		)
+	})
+	return ranked[:min(n, len(ranked))]
+}
+
+// lessonStopWords are common words too vague to match a lesson to an issue.
+var lessonStopWords = map[string]bool{
+	"about": true, "after": true, "also": true, "always": true, "before": true, "from": true,
+	"have": true, "instead": true, "into": true, "must": true, "never": true, "only": true,
+	"should": true, "than": true, "that": true, "their": true, "them": true, "then": true,
+	"there": true, "these": true, "they": true, "this": true, "when": true, "which": true,
+	"will": true, "with": true, "without": true,
+}
+
+// lessonWords is the set of words in text worth matching on: lowercased, at
+// least four characters long, and not a stop word.
+func lessonWords(text string) map[string]bool {
+	words := map[string]bool{}
+	for _, w := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '_')
+	}) {
+		if len(w) >= 4 && !lessonStopWords[w] {
+			words[w] = true
+		}
+	}
+	return words
+}
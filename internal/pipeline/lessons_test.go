@@ -0,0 +1,86 @@
+package pipeline
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"autopr/internal/config"
+	"autopr/internal/db"
+	"autopr/internal/llm"
+)
+
+func TestRunCodeReviewLearnsLessons(t *testing.T) {
+	t.Parallel()
+
+	var gotPrompt string
+	review := "Use the internal logger in handler.go.\n\n<lessons>\n- Log with internal/log, not fmt.Println\n</lessons>"
+	provider := stubProvider{run: func(ctx context.Context, workDir, prompt string) (llm.Response, error) {
+		gotPrompt = prompt
+		return llm.Response{Text: review}, nil
+	}}
+	runner, store, issue, jobID := setupRunStepsJob(t, provider, "reviewing")
+	ctx := context.Background()
+	job, err := store.GetJob(ctx, jobID)
+	if err != nil {
+		t.Fatalf("get job: %v", err)
+	}
+	if _, err := store.CreateArtifact(ctx, jobID, issue.AutoPRIssueID, "plan", "1. Add a handler", 0, ""); err != nil {
+		t.Fatalf("create plan: %v", err)
+	}
+	if _, _, err := store.AddLesson(ctx, job.ProjectName, "Keep handler tests table-driven", db.LessonSourceManual, ""); err != nil {
+		t.Fatalf("add lesson: %v", err)
+	}
+	proj := &config.ProjectConfig{Name: job.ProjectName, Lessons: &config.ProjectLessons{PerPrompt: 5}}
+
+	if err := runner.runCodeReview(ctx, jobID, issue, proj, t.TempDir()); err != errReviewChangesRequested {
+		t.Fatalf("run code review = %v, want changes requested", err)
+	}
+	for _, want := range []string{"<project_lessons>", "- Keep handler tests table-driven", "<lessons>"} {
+		if !strings.Contains(gotPrompt, want) {
+			t.Errorf("prompt missing %q:\n%s", want, gotPrompt)
+		}
+	}
+	artifact, err := store.GetLatestArtifact(ctx, jobID, "code_review")
+	if err != nil {
+		t.Fatalf("get review: %v", err)
+	}
+	if artifact.Content != "Use the internal logger in handler.go." {
+		t.Fatalf("expected lessons stripped from review, got %q", artifact.Content)
+	}
+	lessons, err := store.ListLessons(ctx, job.ProjectName)
+	if err != nil {
+		t.Fatalf("list lessons: %v", err)
+	}
+	if len(lessons) != 2 || lessons[1].Text != "Log with internal/log, not fmt.Println" || lessons[1].Source != db.LessonSourceReview || lessons[1].JobID != jobID {
+		t.Fatalf("unexpected lessons: %+v", lessons)
+	}
+
+	// An approving review teaches nothing.
+	review = "APPROVED\n\n<lessons>\n- Prefer small functions\n</lessons>"
+	if err := runner.runCodeReview(ctx, jobID, issue, proj, t.TempDir()); err != nil {
+		t.Fatalf("run approving review: %v", err)
+	}
+	if lessons, _ := store.ListLessons(ctx, job.ProjectName); len(lessons) != 2 {
+		t.Fatalf("expected no lesson from an approving review, got %+v", lessons)
+	}
+}
+
+func TestRelevantLessonsRanksByIssueWords(t *testing.T) {
+	t.Parallel()
+
+	lessons := []db.Lesson{
+		{ID: 1, Text: "Wrap errors with %w", TimesLearned: 3, UpdatedAt: "2026-01-01T00:00:00Z"},
+		{ID: 2, Text: "Keep migrations idempotent", TimesLearned: 1, UpdatedAt: "2026-01-02T00:00:00Z"},
+		{ID: 3, Text: "Log with the internal logger", TimesLearned: 1, UpdatedAt: "2026-01-03T00:00:00Z"},
+	}
+	issue := db.Issue{Title: "Add a migration for user emails", Body: "The migrations should add a column."}
+
+	got := RelevantLessons(lessons, issue, 2)
+	if len(got) != 2 || got[0].ID != 2 || got[1].ID != 1 {
+		t.Fatalf("unexpected ranking: %+v", got)
+	}
+	if got := RelevantLessons(lessons, issue, 10); len(got) != 3 || got[2].ID != 3 {
+		t.Fatalf("expected every lesson when n is large, got %+v", got)
+	}
+}
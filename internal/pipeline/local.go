@@ -0,0 +1,48 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"autopr/internal/config"
+	"autopr/internal/db"
+	"autopr/internal/policy"
+)
+
+// LocalSource is the issue source for issues that do not come from a remote
+// tracker: items in a local project's issues file and tasks given to `ap run`.
+const LocalSource = "local"
+
+// ManualIssuePrefix starts the source issue ID of issues created by `ap run`,
+// keeping them apart from issues-file items that sync may close.
+const ManualIssuePrefix = "run-"
+
+// CreateManualJob queues a job for a task described on the command line. A
+// synthetic issue (source "local") holds the title and body for planning.
+func CreateManualJob(ctx context.Context, store *db.Store, proj *config.ProjectConfig, maxIterations int, title, body string) (string, error) {
+	issueID, err := createManualIssue(ctx, store, proj, title, body)
+	if err != nil {
+		return "", err
+	}
+	return policy.CreateJob(ctx, store, proj, issueID, maxIterations)
+}
+
+// createManualIssue records the synthetic issue of a task given to `ap run`.
+func createManualIssue(ctx context.Context, store *db.Store, proj *config.ProjectConfig, title, body string) (string, error) {
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return "", fmt.Errorf("title is required")
+	}
+	now := time.Now().UTC()
+	return store.UpsertIssue(ctx, db.IssueUpsert{
+		ProjectName:   proj.Name,
+		Source:        LocalSource,
+		SourceIssueID: ManualIssuePrefix + now.Format("20060102-150405.000000"),
+		Title:         title,
+		Body:          strings.TrimSpace(body),
+		State:         "open",
+		SourceUpdated: now.Format(time.RFC3339),
+	})
+}
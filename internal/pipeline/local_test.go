@@ -0,0 +1,25 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+
+	"autopr/internal/config"
+	"autopr/internal/db"
+)
+
+func TestCreatePRForLocalProjectLeavesBranch(t *testing.T) {
+	t.Parallel()
+	proj := &config.ProjectConfig{Name: "local", Local: &config.ProjectLocal{}}
+	url, err := CreatePRForProject(context.Background(), &config.Config{}, proj, db.Job{BranchName: "autopr/1-fix"}, "autopr/1-fix", "t", "b", false)
+	if err != nil || url != "" {
+		t.Fatalf("expected no PR for a local project, got %q (err %v)", url, err)
+	}
+}
+
+func TestCreateManualJobRequiresTitle(t *testing.T) {
+	t.Parallel()
+	if _, err := CreateManualJob(context.Background(), nil, &config.ProjectConfig{Name: "local"}, 3, "  ", ""); err == nil {
+		t.Fatal("expected error for blank title")
+	}
+}
@@ -0,0 +1,203 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"autopr/internal/config"
+	"autopr/internal/db"
+	"autopr/internal/git"
+	"autopr/internal/githubapp"
+	"autopr/internal/netstate"
+)
+
+// outboxRetention is how long finished outbox ops are kept.
+const outboxRetention = 7 * 24 * time.Hour
+
+// waitForNetwork parks a job in waiting_network instead of failing it when
+// errMsg looks like a connectivity failure and the project's forge is
+// unreachable right now. The outbox op of the given kind continues the job
+// once connectivity returns. projectCfg may be nil to look it up from the job.
+func (r *Runner) waitForNetwork(ctx context.Context, jobID, fromState, kind, errMsg string, projectCfg *config.ProjectConfig) bool {
+	if r.projectReachable == nil || !netstate.IsNetworkErrorText(errMsg) {
+		return false
+	}
+	if projectCfg == nil {
+		job, err := r.store.GetJob(ctx, jobID)
+		if err != nil {
+			return false
+		}
+		var ok bool
+		if projectCfg, ok = r.cfg.ProjectByName(job.ProjectName); !ok {
+			return false
+		}
+	}
+	if r.projectReachable(ctx, projectCfg) {
+		return false
+	}
+	if err := r.store.DeferJobForNetwork(ctx, jobID, fromState, kind, errMsg); err != nil {
+		slog.Warn("failed to defer job for network", "job", jobID, "state", fromState, "err", err)
+		return false
+	}
+	slog.Warn("network unavailable, job waiting on network", "job", jobID, "state", fromState, "op", kind, "error", errMsg)
+	return true
+}
+
+// RunOutbox replays deferred outbox operations every interval until ctx is
+// cancelled. Jobs requeued from waiting_network are announced on jobCh.
+func (r *Runner) RunOutbox(ctx context.Context, interval time.Duration, jobCh chan<- string) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		r.ProcessOutbox(ctx, jobCh)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ProcessOutbox runs each pending outbox op whose project is reachable. Ops
+// that fail on the network again stay pending; other failures are final.
+func (r *Runner) ProcessOutbox(ctx context.Context, jobCh chan<- string) {
+	ops, err := r.store.ListPendingOutboxOps(ctx)
+	if err != nil {
+		slog.Error("list outbox ops", "err", err)
+		return
+	}
+	offline := map[string]bool{}
+	for _, op := range ops {
+		if ctx.Err() != nil {
+			return
+		}
+		job, err := r.store.GetJob(ctx, op.JobID)
+		if err != nil {
+			slog.Warn("outbox: load job", "op", op.ID, "job", op.JobID, "err", err)
+			continue
+		}
+		proj, ok := r.cfg.ProjectByName(job.ProjectName)
+		if !ok {
+			_ = r.store.MarkOutboxOpFailed(ctx, op.ID, "project not found: "+job.ProjectName)
+			continue
+		}
+		if offline[proj.Name] {
+			continue
+		}
+		if r.projectReachable != nil && !r.projectReachable(ctx, proj) {
+			offline[proj.Name] = true
+			continue
+		}
+
+		err = r.runOutboxOp(ctx, op, job, proj, jobCh)
+		switch {
+		case err == nil:
+			if err := r.store.MarkOutboxOpDone(ctx, op.ID); err != nil {
+				slog.Error("outbox: mark op done", "op", op.ID, "err", err)
+			}
+			slog.Info("outbox op completed", "op", op.ID, "kind", op.Kind, "job", job.ID)
+		case netstate.IsNetworkError(err):
+			offline[proj.Name] = true
+			_ = r.store.RecordOutboxOpAttempt(ctx, op.ID, err.Error())
+			slog.Warn("outbox op still waiting on network", "op", op.ID, "kind", op.Kind, "job", job.ID, "err", err)
+		default:
+			_ = r.store.MarkOutboxOpFailed(ctx, op.ID, err.Error())
+			slog.Error("outbox op failed", "op", op.ID, "kind", op.Kind, "job", job.ID, "err", err)
+			if op.Kind == db.OutboxOpCreatePR {
+				r.returnToReady(ctx, job.ID, "create PR: "+err.Error())
+			}
+		}
+	}
+	if _, err := r.store.DeleteOldOutboxOps(ctx, outboxRetention); err != nil {
+		slog.Warn("outbox: delete old ops", "err", err)
+	}
+}
+
+func (r *Runner) runOutboxOp(ctx context.Context, op db.OutboxOp, job db.Job, proj *config.ProjectConfig, jobCh chan<- string) error {
+	switch op.Kind {
+	case db.OutboxOpResumeJob:
+		if job.State != "waiting_network" {
+			return nil // cancelled or otherwise moved on meanwhile
+		}
+		if err := r.store.TransitionState(ctx, job.ID, "waiting_network", "queued"); err != nil {
+			return err
+		}
+		_ = r.store.UpdateJobField(ctx, job.ID, "error_message", "")
+		select {
+		case jobCh <- job.ID:
+		default:
+		}
+		return nil
+
+	case db.OutboxOpCreatePR:
+		if job.State != "waiting_network" {
+			return nil
+		}
+		issue, err := r.store.GetIssueByAPID(ctx, job.AutoPRIssueID)
+		if err != nil {
+			return fmt.Errorf("get issue for job %s: %w", job.ID, err)
+		}
+		if err := r.openPR(ctx, job, issue, proj, "waiting_network"); err != nil {
+			return err
+		}
+		_ = r.store.UpdateJobField(ctx, job.ID, "error_message", "")
+		return nil
+
+	case db.OutboxOpMergePR:
+		if job.State != "approved" || job.PRMergedAt != "" {
+			return nil
+		}
+		if err := r.mergePRForProjectFn(ctx, r.cfg, proj, job, op.Payload); err != nil {
+			return err
+		}
+		return r.store.MarkJobMerged(ctx, job.ID, time.Now().UTC().Format(time.RFC3339))
+
+	default:
+		return fmt.Errorf("unsupported outbox op kind %q", op.Kind)
+	}
+}
+
+// returnToReady hands a job whose deferred PR creation failed back to a human.
+func (r *Runner) returnToReady(ctx context.Context, jobID, errMsg string) {
+	if err := r.store.TransitionState(ctx, jobID, "waiting_network", "ready"); err != nil {
+		slog.Warn("outbox: return job to ready", "job", jobID, "err", err)
+		return
+	}
+	_ = r.store.UpdateJobField(ctx, jobID, "error_message", errMsg)
+}
+
+// MergePRForProject merges the job's GitHub PR, GitLab MR, or Gitea PR with
+// method (merge, squash, or rebase; GitLab only distinguishes squash).
+func MergePRForProject(ctx context.Context, cfg *config.Config, proj *config.ProjectConfig, job db.Job, method string) error {
+	if method == "" {
+		method = "merge"
+	}
+	switch {
+	case proj.GitHub != nil:
+		token, err := githubapp.Token(ctx, cfg, proj)
+		if err != nil {
+			return fmt.Errorf("github token: %w", err)
+		}
+		if token == "" {
+			return fmt.Errorf("GITHUB_TOKEN required to merge PR")
+		}
+		return git.MergeGitHubPR(ctx, token, proj.GitHub.BaseURL, job.PRURL, method)
+
+	case proj.GitLab != nil:
+		if cfg.Tokens.GitLab == "" {
+			return fmt.Errorf("GITLAB_TOKEN required to merge MR")
+		}
+		return git.MergeGitLabMR(ctx, cfg.Tokens.GitLab, proj.GitLab.BaseURL, job.PRURL, method == "squash")
+
+	case proj.Gitea != nil:
+		if cfg.Tokens.Gitea == "" {
+			return fmt.Errorf("GITEA_TOKEN required to merge PR")
+		}
+		return git.MergeGiteaPR(ctx, cfg.Tokens.Gitea, proj.Gitea.BaseURL, job.PRURL, method)
+
+	default:
+		return fmt.Errorf("project %q has no GitHub, GitLab, or Gitea config for merge", proj.Name)
+	}
+}
@@ -0,0 +1,158 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"net"
+	"path/filepath"
+	"testing"
+
+	"autopr/internal/config"
+	"autopr/internal/db"
+)
+
+func newOutboxTestRunner(t *testing.T) (*Runner, *db.Store, *config.Config) {
+	t.Helper()
+	tmp := t.TempDir()
+	store, err := db.Open(filepath.Join(tmp, "autopr.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	cfg := &config.Config{
+		ReposRoot: filepath.Join(tmp, "repos"),
+		Tokens:    config.TokensConfig{GitHub: "token"},
+		Projects: []config.ProjectConfig{{
+			Name:       "myproject",
+			RepoURL:    "https://github.com/acme/repo.git",
+			BaseBranch: "main",
+			GitHub:     &config.ProjectGitHub{Owner: "acme", Repo: "repo"},
+		}},
+	}
+	return New(store, nil, cfg), store, cfg
+}
+
+func createOutboxTestJob(t *testing.T, store *db.Store, sourceIssueID, state, prURL string) string {
+	t.Helper()
+	ctx := context.Background()
+	issueID, err := store.UpsertIssue(ctx, db.IssueUpsert{
+		ProjectName:   "myproject",
+		Source:        "github",
+		SourceIssueID: sourceIssueID,
+		Title:         "issue " + sourceIssueID,
+		URL:           "https://github.com/acme/repo/issues/" + sourceIssueID,
+		State:         "open",
+	})
+	if err != nil {
+		t.Fatalf("upsert issue: %v", err)
+	}
+	jobID, err := store.CreateJob(ctx, issueID, "myproject", 3)
+	if err != nil {
+		t.Fatalf("create job: %v", err)
+	}
+	if _, err := store.Writer.ExecContext(ctx, `UPDATE jobs SET state = ?, pr_url = ? WHERE id = ?`, state, prURL, jobID); err != nil {
+		t.Fatalf("set job state: %v", err)
+	}
+	return jobID
+}
+
+func TestFailJobWaitsForNetworkAndOutboxRequeues(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	runner, store, _ := newOutboxTestRunner(t)
+	online := false
+	runner.projectReachable = func(context.Context, *config.ProjectConfig) bool { return online }
+
+	jobID := createOutboxTestJob(t, store, "1", "implementing", "")
+	err := runner.failJob(ctx, jobID, "implementing", "fetch: fatal: unable to access 'https://github.com/acme/repo.git/': Could not resolve host: github.com")
+	if !errors.Is(err, errWaitingOnNetwork) {
+		t.Fatalf("expected waiting-on-network error, got %v", err)
+	}
+	job, err := store.GetJob(ctx, jobID)
+	if err != nil {
+		t.Fatalf("get job: %v", err)
+	}
+	if job.State != "waiting_network" {
+		t.Fatalf("expected waiting_network, got %q", job.State)
+	}
+
+	// Non-network failures still fail the job even while offline.
+	otherID := createOutboxTestJob(t, store, "2", "implementing", "")
+	if err := runner.failJob(ctx, otherID, "implementing", "llm returned empty plan"); errors.Is(err, errWaitingOnNetwork) {
+		t.Fatal("expected non-network failure to fail the job")
+	}
+	if other, _ := store.GetJob(ctx, otherID); other.State != "failed" {
+		t.Fatalf("expected failed, got %q", other.State)
+	}
+
+	jobCh := make(chan string, 1)
+	runner.ProcessOutbox(ctx, jobCh)
+	if job, _ := store.GetJob(ctx, jobID); job.State != "waiting_network" {
+		t.Fatalf("expected job to keep waiting while offline, got %q", job.State)
+	}
+
+	online = true
+	runner.ProcessOutbox(ctx, jobCh)
+	job, err = store.GetJob(ctx, jobID)
+	if err != nil {
+		t.Fatalf("get job: %v", err)
+	}
+	if job.State != "queued" || job.ErrorMessage != "" {
+		t.Fatalf("expected requeued job with cleared error, got state=%q error=%q", job.State, job.ErrorMessage)
+	}
+	select {
+	case got := <-jobCh:
+		if got != jobID {
+			t.Fatalf("expected %s on job channel, got %s", jobID, got)
+		}
+	default:
+		t.Fatal("expected requeued job to be announced")
+	}
+	if n, _ := store.CountPendingOutboxOps(ctx); n != 0 {
+		t.Fatalf("expected no pending ops, got %d", n)
+	}
+}
+
+func TestProcessOutboxRetriesQueuedMerge(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	runner, store, _ := newOutboxTestRunner(t)
+	runner.projectReachable = func(context.Context, *config.ProjectConfig) bool { return true }
+
+	jobID := createOutboxTestJob(t, store, "3", "approved", "https://github.com/acme/repo/pull/3")
+	if _, err := store.EnqueueOutboxOp(ctx, jobID, db.OutboxOpMergePR, "squash"); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+
+	var methods []string
+	mergeErr := error(&net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")})
+	runner.mergePRForProjectFn = func(_ context.Context, _ *config.Config, _ *config.ProjectConfig, _ db.Job, method string) error {
+		methods = append(methods, method)
+		return mergeErr
+	}
+
+	runner.ProcessOutbox(ctx, nil)
+	ops, err := store.ListPendingOutboxOps(ctx)
+	if err != nil {
+		t.Fatalf("list ops: %v", err)
+	}
+	if len(ops) != 1 || ops[0].Attempts != 1 {
+		t.Fatalf("expected merge op to stay pending after a network failure, got %+v", ops)
+	}
+
+	mergeErr = nil
+	runner.ProcessOutbox(ctx, nil)
+	job, err := store.GetJob(ctx, jobID)
+	if err != nil {
+		t.Fatalf("get job: %v", err)
+	}
+	if job.PRMergedAt == "" {
+		t.Fatal("expected job to be marked merged")
+	}
+	if len(methods) != 2 || methods[1] != "squash" {
+		t.Fatalf("expected two squash merge attempts, got %v", methods)
+	}
+	if n, _ := store.CountPendingOutboxOps(ctx); n != 0 {
+		t.Fatalf("expected no pending ops, got %d", n)
+	}
+}
@@ -0,0 +1,102 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"autopr/internal/config"
+	"autopr/internal/db"
+	"autopr/internal/git"
+)
+
+// hasDiffLimits reports whether the project caps the size of a job's diff.
+func hasDiffLimits(proj *config.ProjectConfig) bool {
+	return proj.MaxDiffFiles > 0 || proj.MaxDiffLines > 0
+}
+
+// oversizeSummary describes how a diff of files/lines exceeds the project's
+// limits, or returns "" when it is within them.
+func oversizeSummary(proj *config.ProjectConfig, files, lines int) string {
+	var over []string
+	if proj.MaxDiffFiles > 0 && files > proj.MaxDiffFiles {
+		over = append(over, fmt.Sprintf("%d files changed (limit %d)", files, proj.MaxDiffFiles))
+	}
+	if proj.MaxDiffLines > 0 && lines > proj.MaxDiffLines {
+		over = append(over, fmt.Sprintf("%d lines changed (limit %d)", lines, proj.MaxDiffLines))
+	}
+	return strings.Join(over, ", ")
+}
+
+// pauseIfOversize moves the job from fromState to needs_review_oversize when
+// its diff exceeds the project's limits, so a large rewrite waits for a human
+// instead of becoming ready. It reports whether the job was paused.
+func (r *Runner) pauseIfOversize(ctx context.Context, jobID string, projectCfg *config.ProjectConfig, workDir, fromState string) (bool, error) {
+	if !hasDiffLimits(projectCfg) {
+		return false, nil
+	}
+	job, err := r.store.GetJob(ctx, jobID)
+	if err != nil {
+		return false, err
+	}
+	changes, err := git.DiffNumstatAgainstBase(ctx, workDir, TargetBranch(job, projectCfg))
+	if err != nil {
+		return false, fmt.Errorf("check diff size: %w", err)
+	}
+	// Generated files are rebuilt by tools, so they don't count.
+	files, lines := 0, 0
+	for _, c := range changes {
+		if projectCfg.IsGenerated(c.Path) {
+			continue
+		}
+		files++
+		lines += c.Lines
+	}
+	summary := oversizeSummary(projectCfg, files, lines)
+	if summary == "" {
+		return false, nil
+	}
+	if err := r.store.UpdateJobField(ctx, jobID, "oversize_summary", summary); err != nil {
+		return false, err
+	}
+	if err := r.store.TransitionState(ctx, jobID, fromState, "needs_review_oversize"); err != nil {
+		return false, err
+	}
+	slog.Info("diff exceeds size limits, waiting for review", "job", jobID, "files", files, "lines", lines)
+	return true, nil
+}
+
+// Oversize review actions offered for a job in needs_review_oversize.
+const (
+	OversizeAllow  = "allow"
+	OversizeSplit  = "split"
+	OversizeReject = "reject"
+)
+
+// ResolveOversize applies a human decision to a job paused in
+// needs_review_oversize: allow moves it to ready, reject rejects it, and
+// split rejects it and queues a retry asking for a smaller change.
+func ResolveOversize(ctx context.Context, store *db.Store, job db.Job, action string) error {
+	if job.State != "needs_review_oversize" {
+		return fmt.Errorf("job %s is %s, not needs_review_oversize", job.ID, job.State)
+	}
+	switch action {
+	case OversizeAllow:
+		return store.TransitionState(ctx, job.ID, "needs_review_oversize", "ready")
+	case OversizeReject:
+		return store.RejectJob(ctx, job.ID, "needs_review_oversize", "diff too large: "+job.OversizeSummary)
+	case OversizeSplit:
+		if err := store.RejectJob(ctx, job.ID, "needs_review_oversize", "split requested: "+job.OversizeSummary); err != nil {
+			return err
+		}
+		notes := "The previous attempt was too large to review (" + job.OversizeSummary + "). " +
+			"Implement only the smallest self-contained part of this issue that fits the limits; the rest can follow in later changes."
+		if job.HumanNotes != "" {
+			notes = job.HumanNotes + "\n\n" + notes
+		}
+		return store.ResetJobForRetry(ctx, job.ID, notes)
+	default:
+		return fmt.Errorf("unknown oversize action %q (want allow, split, or reject)", action)
+	}
+}
@@ -0,0 +1,103 @@
+package pipeline
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"autopr/internal/config"
+)
+
+func TestRunTestingAndReadinessPausesOversizeDiff(t *testing.T) {
+	t.Parallel()
+
+	runner, store, issue, jobID := setupRunStepsJob(t, nil, "testing")
+	ctx := context.Background()
+	remote, workDir := cloneWithChanges(t, map[string]string{
+		"a.go": "package a\n",
+		"b.go": "package b\n",
+	})
+
+	projectCfg := &config.ProjectConfig{
+		Name:         "project",
+		RepoURL:      remote,
+		BaseBranch:   "main",
+		TestCmd:      "git status",
+		MaxDiffFiles: 1,
+		MaxDiffLines: 100,
+	}
+	if err := runner.runTestingAndReadiness(ctx, jobID, issue, projectCfg, workDir); err != nil {
+		t.Fatalf("run testing: %v", err)
+	}
+	job, err := store.GetJob(ctx, jobID)
+	if err != nil {
+		t.Fatalf("get job: %v", err)
+	}
+	if job.State != "needs_review_oversize" || job.OversizeSummary != "2 files changed (limit 1)" {
+		t.Fatalf("expected oversize pause, got state %q summary %q", job.State, job.OversizeSummary)
+	}
+
+	if err := ResolveOversize(ctx, store, job, OversizeSplit); err != nil {
+		t.Fatalf("split: %v", err)
+	}
+	job, err = store.GetJob(ctx, jobID)
+	if err != nil {
+		t.Fatalf("get job: %v", err)
+	}
+	if job.State != "queued" || job.OversizeSummary != "" || !strings.Contains(job.HumanNotes, "smallest self-contained part") {
+		t.Fatalf("expected split to requeue with notes, got state %q summary %q notes %q", job.State, job.OversizeSummary, job.HumanNotes)
+	}
+}
+
+func TestResolveOversizeAllowAndReject(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	for _, tc := range []struct {
+		action string
+		state  string
+	}{
+		{OversizeAllow, "ready"},
+		{OversizeReject, "rejected"},
+	} {
+		_, store, _, jobID := setupRunStepsJob(t, nil, "testing")
+		if _, err := store.Writer.ExecContext(ctx, `UPDATE jobs SET state = 'needs_review_oversize', oversize_summary = '900 lines changed (limit 500)' WHERE id = ?`, jobID); err != nil {
+			t.Fatalf("pause job: %v", err)
+		}
+		job, err := store.GetJob(ctx, jobID)
+		if err != nil {
+			t.Fatalf("get job: %v", err)
+		}
+		if err := ResolveOversize(ctx, store, job, tc.action); err != nil {
+			t.Fatalf("%s: %v", tc.action, err)
+		}
+		job, err = store.GetJob(ctx, jobID)
+		if err != nil {
+			t.Fatalf("get job: %v", err)
+		}
+		if job.State != tc.state {
+			t.Fatalf("%s: expected state %q, got %q", tc.action, tc.state, job.State)
+		}
+		if tc.action == OversizeReject && !strings.Contains(job.RejectReason, "900 lines changed") {
+			t.Fatalf("expected reject reason to carry the diff size, got %q", job.RejectReason)
+		}
+		if err := ResolveOversize(ctx, store, job, OversizeAllow); err == nil {
+			t.Fatalf("expected error resolving a job no longer paused")
+		}
+	}
+}
+
+func TestOversizeSummary(t *testing.T) {
+	t.Parallel()
+
+	proj := &config.ProjectConfig{MaxDiffFiles: 10, MaxDiffLines: 500}
+	if got := oversizeSummary(proj, 10, 500); got != "" {
+		t.Fatalf("expected diff at the limits to pass, got %q", got)
+	}
+	if got := oversizeSummary(proj, 11, 501); got != "11 files changed (limit 10), 501 lines changed (limit 500)" {
+		t.Fatalf("unexpected summary %q", got)
+	}
+	if got := oversizeSummary(&config.ProjectConfig{}, 1000, 100000); got != "" {
+		t.Fatalf("expected no limits to pass, got %q", got)
+	}
+}
@@ -0,0 +1,151 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"autopr/internal/db"
+	"autopr/internal/git"
+)
+
+const excludedChangesArtifactKind = "excluded_changes"
+
+// ParseDiffSelectors parses "path" or "path:N" selectors (N is a 1-based hunk
+// number as shown in the diff) into a path → hunks map. A bare path selects
+// the whole file.
+func ParseDiffSelectors(specs []string) (git.DiffExclusions, error) {
+	out := git.DiffExclusions{}
+	for _, spec := range specs {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+		path, hunk := spec, 0
+		if idx := strings.LastIndex(spec, ":"); idx > 0 {
+			if n, err := strconv.Atoi(spec[idx+1:]); err == nil {
+				if n < 1 {
+					return nil, fmt.Errorf("invalid hunk number in %q", spec)
+				}
+				path, hunk = spec[:idx], n
+			}
+		}
+		existing, seen := out[path]
+		switch {
+		case hunk == 0:
+			out[path] = nil
+		case seen && existing == nil:
+			// Whole file already selected.
+		default:
+			out[path] = append(existing, hunk)
+		}
+	}
+	return out, nil
+}
+
+// ExclusionsFromInclude diffs the job worktree and returns the exclusions for
+// everything not selected by include.
+func ExclusionsFromInclude(ctx context.Context, worktreePath, baseBranch string, include git.DiffExclusions) (git.DiffExclusions, error) {
+	diff, err := git.DiffAgainstBase(ctx, worktreePath, baseBranch)
+	if err != nil {
+		return nil, err
+	}
+	files := git.ParseDiff(diff)
+	for path, hunks := range include {
+		idx := slices.IndexFunc(files, func(f git.DiffFile) bool { return f.Path == path })
+		if idx < 0 {
+			return nil, fmt.Errorf("file %q is not part of the diff", path)
+		}
+		for _, n := range hunks {
+			if n > len(files[idx].Hunks) {
+				return nil, fmt.Errorf("file %q has no hunk %d (hunks: %d)", path, n, len(files[idx].Hunks))
+			}
+		}
+	}
+	return InvertSelection(files, include), nil
+}
+
+// InvertSelection turns a set of included files/hunks into the exclusions
+// covering everything else in files.
+func InvertSelection(files []git.DiffFile, include git.DiffExclusions) git.DiffExclusions {
+	excl := git.DiffExclusions{}
+	for _, f := range files {
+		hunks, ok := include[f.Path]
+		switch {
+		case !ok:
+			excl[f.Path] = nil
+		case len(hunks) == 0:
+			// Whole file included.
+		default:
+			var dropped []int
+			for i := range f.Hunks {
+				if !slices.Contains(hunks, i+1) {
+					dropped = append(dropped, i+1)
+				}
+			}
+			if len(dropped) > 0 {
+				excl[f.Path] = dropped
+			}
+		}
+	}
+	return excl
+}
+
+// ApplyPartialApproval reverts the excluded files/hunks of a ready job in a
+// follow-up commit and records them as an excluded_changes artifact so they can
+// seed a follow-up job. It is called from the CLI and TUI approval paths
+// before rebasing and pushing. Returns the new HEAD commit.
+func ApplyPartialApproval(ctx context.Context, store *db.Store, job db.Job, baseBranch string, excl git.DiffExclusions) (string, error) {
+	if job.WorktreePath == "" {
+		return "", fmt.Errorf("job %s has no worktree", db.ShortID(job.ID))
+	}
+	diff, err := git.DiffAgainstBase(ctx, job.WorktreePath, baseBranch)
+	if err != nil {
+		return "", err
+	}
+	files := git.ParseDiff(diff)
+	if err := git.ValidateExclusions(files, excl); err != nil {
+		return "", err
+	}
+	patch := git.ExcludedPatch(files, excl)
+
+	if err := git.RevertExclusions(ctx, job.WorktreePath, baseBranch, files, excl); err != nil {
+		return "", err
+	}
+	sha, err := git.CommitAll(ctx, job.WorktreePath, "autopr: revert changes excluded from approval")
+	if err != nil {
+		return "", fmt.Errorf("commit exclusions: %w", err)
+	}
+	if err := store.UpdateJobField(ctx, job.ID, "commit_sha", sha); err != nil {
+		return "", fmt.Errorf("store commit sha: %w", err)
+	}
+	if _, err := store.CreateArtifact(ctx, job.ID, job.AutoPRIssueID, excludedChangesArtifactKind, formatExcludedChanges(files, excl, patch), job.Iteration, sha); err != nil {
+		return "", fmt.Errorf("store excluded changes: %w", err)
+	}
+	return sha, nil
+}
+
+func formatExcludedChanges(files []git.DiffFile, excl git.DiffExclusions, patch string) string {
+	var b strings.Builder
+	b.WriteString("The following changes were excluded at approval and reverted before push. ")
+	b.WriteString("They may be worth a follow-up job.\n\n")
+	for _, f := range files {
+		hunks, ok := excl[f.Path]
+		if !ok {
+			continue
+		}
+		if len(hunks) == 0 {
+			fmt.Fprintf(&b, "- %s (whole file)\n", f.Path)
+			continue
+		}
+		for _, n := range hunks {
+			fmt.Fprintf(&b, "- %s hunk %d: %s\n", f.Path, n, f.Hunks[n-1].Header)
+		}
+	}
+	b.WriteString("\n```diff\n")
+	b.WriteString(patch)
+	b.WriteString("```\n")
+	return b.String()
+}
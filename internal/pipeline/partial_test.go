@@ -0,0 +1,37 @@
+package pipeline
+
+import (
+	"reflect"
+	"testing"
+
+	"autopr/internal/git"
+)
+
+func TestParseDiffSelectors(t *testing.T) {
+	t.Parallel()
+	got, err := ParseDiffSelectors([]string{"a.go:2", "a.go:3", "dir/b.go", "b.go:1", "b.go", "c:d.go"})
+	if err != nil {
+		t.Fatalf("parse selectors: %v", err)
+	}
+	want := git.DiffExclusions{"a.go": {2, 3}, "dir/b.go": nil, "b.go": nil, "c:d.go": nil}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected selectors:\n got %v\nwant %v", got, want)
+	}
+	if _, err := ParseDiffSelectors([]string{"a.go:0"}); err == nil {
+		t.Fatalf("expected error for hunk 0")
+	}
+}
+
+func TestInvertSelection(t *testing.T) {
+	t.Parallel()
+	files := []git.DiffFile{
+		{Path: "a.go", Hunks: []git.DiffHunk{{}, {}, {}}},
+		{Path: "b.go", Hunks: []git.DiffHunk{{}}},
+		{Path: "c.go", Hunks: []git.DiffHunk{{}}},
+	}
+	got := InvertSelection(files, git.DiffExclusions{"a.go": {2}, "c.go": nil})
+	want := git.DiffExclusions{"a.go": {1, 3}, "b.go": nil}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected exclusions:\n got %v\nwant %v", got, want)
+	}
+}
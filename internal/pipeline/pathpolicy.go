@@ -0,0 +1,85 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"autopr/internal/config"
+	"autopr/internal/git"
+)
+
+// errPathPolicyViolation is returned by the test step when the branch changes
+// files the project's scope or [projects.paths] policy forbids.
+var errPathPolicyViolation = errors.New("changes violate the project's path policy")
+
+// pathViolation is one changed file the policy rejects.
+type pathViolation struct {
+	File   string
+	Reason string
+}
+
+// hasPathPolicy reports whether changed files need checking for proj.
+func hasPathPolicy(proj *config.ProjectConfig) bool {
+	return proj.ScopePath() != "" || proj.Paths != nil
+}
+
+// scopeDir is the directory a project's tests run in: the scope
+// subdirectory of a monorepo sub-project, else the clone root.
+func scopeDir(workDir string, proj *config.ProjectConfig) string {
+	return filepath.Join(workDir, filepath.FromSlash(proj.ScopePath()))
+}
+
+// pathPolicyViolations lists the files changed against origin/<base> that
+// the project's scope or path policy rejects.
+func pathPolicyViolations(ctx context.Context, workDir string, proj *config.ProjectConfig, base string) ([]pathViolation, error) {
+	out, err := git.DiffFilesAgainstBase(ctx, workDir, base)
+	if err != nil {
+		return nil, err
+	}
+	var violations []pathViolation
+	for _, file := range strings.Split(strings.TrimSpace(out), "\n") {
+		if file == "" {
+			continue
+		}
+		if reason := proj.PathViolation(file); reason != "" {
+			violations = append(violations, pathViolation{File: file, Reason: reason})
+		}
+	}
+	return violations, nil
+}
+
+// withPathRules appends the project's scope and path policy to a plan,
+// implement, or review prompt so the constraints are known upfront.
+func withPathRules(prompt string, proj *config.ProjectConfig) string {
+	var rules []string
+	if scope := proj.ScopePath(); scope != "" {
+		rules = append(rules, fmt.Sprintf("This repository is a monorepo and this task belongs to %s/. Only modify files under %s/. Tests run from that directory.", scope, scope))
+	}
+	if proj.Paths != nil {
+		if len(proj.Paths.Allow) > 0 {
+			rules = append(rules, "Only modify files matching: "+strings.Join(proj.Paths.Allow, ", ")+".")
+		}
+		if len(proj.Paths.Deny) > 0 {
+			rules = append(rules, "Never modify files matching: "+strings.Join(proj.Paths.Deny, ", ")+".")
+		}
+	}
+	if len(rules) == 0 {
+		return prompt
+	}
+	return prompt + "\n\n<path_policy>\n" + strings.Join(rules, "\n") +
+		"\nChanges that break these rules are rejected before tests run.\n</path_policy>"
+}
+
+// pathPolicyOutput is the test_output recorded for a violation, so the next
+// implement iteration sees which files to revert.
+func pathPolicyOutput(violations []pathViolation) string {
+	var b strings.Builder
+	b.WriteString("Path policy violation: revert changes to these files before tests can run.\n")
+	for _, v := range violations {
+		fmt.Fprintf(&b, "- %s (%s)\n", v.File, v.Reason)
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
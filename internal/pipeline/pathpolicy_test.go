@@ -0,0 +1,156 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"autopr/internal/config"
+	"autopr/internal/db"
+)
+
+// cloneWithChanges clones a fresh remote and writes files (relative path ->
+// content) into the clone without committing them.
+func cloneWithChanges(t *testing.T, files map[string]string) (remote, workDir string) {
+	t.Helper()
+	tmp := t.TempDir()
+	remote = createBareRemoteWithMain(t, tmp)
+	workDir = filepath.Join(tmp, "work")
+	runGitCmdLocal(t, "", "clone", "-q", "-b", "main", remote, workDir)
+	for name, content := range files {
+		path := filepath.Join(workDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return remote, workDir
+}
+
+func TestRunTestsRejectsChangesOutsideScope(t *testing.T) {
+	t.Parallel()
+
+	runner, store, issue, jobID := setupRunStepsJob(t, nil, "testing")
+	ctx := context.Background()
+	remote, workDir := cloneWithChanges(t, map[string]string{
+		"services/api/handler.go": "package api\n",
+		"README.md":               "changed\n",
+	})
+
+	projectCfg := &config.ProjectConfig{
+		Name:       "project",
+		RepoURL:    remote,
+		BaseBranch: "main",
+		TestCmd:    "cat handler.go",
+		Scope:      &config.ProjectScope{Path: "services/api"},
+	}
+	if err := runner.runTests(ctx, jobID, issue, projectCfg, workDir); !errors.Is(err, errPathPolicyViolation) {
+		t.Fatalf("expected errPathPolicyViolation for out-of-scope change, got %v", err)
+	}
+	artifact, err := store.GetLatestArtifact(ctx, jobID, "test_output")
+	if err != nil {
+		t.Fatalf("get test_output: %v", err)
+	}
+	if !strings.Contains(artifact.Content, "- README.md (outside scope services/api/)") || strings.Contains(artifact.Content, "handler.go") {
+		t.Fatalf("unexpected violation output %q", artifact.Content)
+	}
+	if result, _ := store.JobTestsResult(ctx, jobID); result != db.TestsFailed {
+		t.Fatalf("tests result = %q, want failed", result)
+	}
+
+	// With the stray change reverted, tests run from the scope directory.
+	runGitCmdLocal(t, workDir, "checkout", "--", "README.md")
+	if err := runner.runTests(ctx, jobID, issue, projectCfg, workDir); err != nil {
+		t.Fatalf("expected tests to pass in scope dir, got %v", err)
+	}
+	if result, _ := store.JobTestsResult(ctx, jobID); result != db.TestsPassed {
+		t.Fatalf("tests result = %q, want passed", result)
+	}
+}
+
+func TestRunTestsEnforcesPathPolicy(t *testing.T) {
+	t.Parallel()
+
+	runner, store, issue, jobID := setupRunStepsJob(t, nil, "testing")
+	ctx := context.Background()
+	remote, workDir := cloneWithChanges(t, map[string]string{
+		"src/app.go":          "package app\n",
+		"infra/prod/main.tf":  "resource {}\n",
+		"docs/notes/intro.md": "notes\n",
+	})
+
+	projectCfg := &config.ProjectConfig{
+		Name:       "project",
+		RepoURL:    remote,
+		BaseBranch: "main",
+		TestCmd:    "git status",
+		Paths:      &config.ProjectPaths{Allow: []string{"src/**", "infra/"}, Deny: []string{"infra/**"}},
+	}
+	err := runner.runTests(ctx, jobID, issue, projectCfg, workDir)
+	if !errors.Is(err, errPathPolicyViolation) {
+		t.Fatalf("expected errPathPolicyViolation, got %v", err)
+	}
+	artifact, err := store.GetLatestArtifact(ctx, jobID, "test_output")
+	if err != nil {
+		t.Fatalf("get test_output: %v", err)
+	}
+	for _, want := range []string{"- docs/notes/intro.md (not in allowed paths)", "- infra/prod/main.tf (denied by infra/**)"} {
+		if !strings.Contains(artifact.Content, want) {
+			t.Errorf("violation output missing %q:\n%s", want, artifact.Content)
+		}
+	}
+	if strings.Contains(artifact.Content, "src/app.go") {
+		t.Errorf("allowed file reported as violation:\n%s", artifact.Content)
+	}
+}
+
+func TestRunStepsFailsJobOnPathPolicyViolationAtMaxIterations(t *testing.T) {
+	t.Parallel()
+
+	runner, store, issue, jobID := setupRunStepsJob(t, nil, "testing")
+	ctx := context.Background()
+	remote, workDir := cloneWithChanges(t, map[string]string{"infra/main.tf": "resource {}\n"})
+	if _, err := store.Writer.ExecContext(ctx, `UPDATE jobs SET iteration = 1, max_iterations = 1 WHERE id = ?`, jobID); err != nil {
+		t.Fatalf("set iterations: %v", err)
+	}
+
+	projectCfg := &config.ProjectConfig{
+		Name:       "project",
+		RepoURL:    remote,
+		BaseBranch: "main",
+		TestCmd:    "git status",
+		Paths:      &config.ProjectPaths{Deny: []string{"infra/**"}},
+	}
+	if err := runner.runSteps(ctx, jobID, "testing", issue, projectCfg, workDir); err == nil || !strings.Contains(err.Error(), "path policy") {
+		t.Fatalf("expected path policy failure, got %v", err)
+	}
+	job, err := store.GetJob(ctx, jobID)
+	if err != nil {
+		t.Fatalf("get job: %v", err)
+	}
+	if job.State != "failed" || !strings.Contains(job.ErrorMessage, "path policy") {
+		t.Fatalf("expected failed job with path policy error, got state %q error %q", job.State, job.ErrorMessage)
+	}
+}
+
+func TestWithPathRules(t *testing.T) {
+	t.Parallel()
+
+	proj := &config.ProjectConfig{}
+	if got := withPathRules("prompt", proj); got != "prompt" {
+		t.Fatalf("prompt without policy changed: %q", got)
+	}
+	proj.Scope = &config.ProjectScope{Path: "services/web"}
+	proj.Paths = &config.ProjectPaths{Deny: []string{"infra/**"}}
+	got := withPathRules("prompt", proj)
+	for _, want := range []string{"Only modify files under services/web/", "Never modify files matching: infra/**"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("prompt missing %q: %q", want, got)
+		}
+	}
+}
@@ -10,10 +10,21 @@ import (
 	"strings"
 	"time"
 
+	"autopr/internal/attachments"
 	"autopr/internal/config"
 	"autopr/internal/db"
+	"autopr/internal/executor"
 	"autopr/internal/git"
+	"autopr/internal/githubapp"
+	"autopr/internal/issuecomments"
+	"autopr/internal/issuelock"
+	"autopr/internal/kube"
 	"autopr/internal/llm"
+	"autopr/internal/netstate"
+	"autopr/internal/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // errReviewChangesRequested signals that code review requested changes.
@@ -25,15 +36,26 @@ var errTestsFailed = errors.New("tests failed")
 // errJobCancelled signals that a job was explicitly cancelled by the user.
 var errJobCancelled = errors.New("job cancelled")
 
+// errWaitingOnNetwork signals that a job was parked in waiting_network instead
+// of failing because its forge was unreachable.
+var errWaitingOnNetwork = errors.New("waiting on network")
+
 // Runner orchestrates the full pipeline for a job.
 type Runner struct {
 	store                       *db.Store
 	provider                    llm.Provider
 	cfg                         *config.Config
-	cloneForJob                 func(ctx context.Context, repoURL, token, destPath, branchName, baseBranch string) error
+	cloneForJob                 func(ctx context.Context, repoURL, token, destPath, branchName, baseBranch string, remoteAuth git.RemoteAuth) error
 	prepareGitHubPushTarget     func(ctx context.Context, projectCfg *config.ProjectConfig, branchName, worktreePath, token string) (string, string, error)
 	pushBranchWithLeaseToRemote func(ctx context.Context, dir, remoteName, branchName, token string) error
 	createPRForProjectFn        func(ctx context.Context, cfg *config.Config, proj *config.ProjectConfig, job db.Job, head, title, body string, draft bool) (string, error)
+	acquireIssueLock            func(ctx context.Context, job db.Job)
+	refreshIssueComments        func(ctx context.Context, issue db.Issue) error
+	refreshIssueAttachments     func(ctx context.Context, issue db.Issue) error
+	projectReachable            func(ctx context.Context, proj *config.ProjectConfig) bool
+	mergePRForProjectFn         func(ctx context.Context, cfg *config.Config, proj *config.ProjectConfig, job db.Job, method string) error
+	remoteBranchExists          func(ctx context.Context, remoteURL, token, branch string) (bool, error)
+	kube                        *kube.Executor
 }
 
 func New(store *db.Store, provider llm.Provider, cfg *config.Config) *Runner {
@@ -41,17 +63,71 @@ func New(store *db.Store, provider llm.Provider, cfg *config.Config) *Runner {
 		store:                   store,
 		provider:                provider,
 		cfg:                     cfg,
-		cloneForJob:             git.CloneForJob,
+		cloneForJob:             git.CloneForJobWithAuth,
 		prepareGitHubPushTarget: ResolveGitHubPushTarget,
 		pushBranchWithLeaseToRemote: func(ctx context.Context, dir, remoteName, branchName, token string) error {
 			return git.PushBranchWithLeaseToRemoteWithToken(ctx, dir, remoteName, branchName, token)
 		},
-		createPRForProjectFn: CreatePRForProject,
+		createPRForProjectFn:    CreatePRForProject,
+		acquireIssueLock:        issuelock.New(cfg, store).Acquire,
+		refreshIssueComments:    issuecomments.New(cfg, store).Refresh,
+		refreshIssueAttachments: attachments.New(cfg, store).Refresh,
+		projectReachable:        netstate.ProjectReachable,
+		mergePRForProjectFn:     MergePRForProject,
+		remoteBranchExists:      git.RemoteBranchExists,
+	}
+}
+
+// publisher pushes branches and opens PRs through the Runner's functions.
+func (r *Runner) publisher() prPublisher {
+	return prPublisher{
+		resolvePushTarget: r.prepareGitHubPushTarget,
+		push:              r.pushBranchWithLeaseToRemote,
+		createPR:          r.createPRForProjectFn,
+	}
+}
+
+// SetKubernetes provides the executor for projects whose executor is
+// kubernetes.
+func (r *Runner) SetKubernetes(ex *kube.Executor) { r.kube = ex }
+
+// executorFor returns the executor that runs the LLM, setup, test, and
+// regenerate commands of the project's jobs.
+func (r *Runner) executorFor(proj *config.ProjectConfig) (executor.Executor, error) {
+	return projectExecutor(r.cfg, proj, r.kube)
+}
+
+// projectExecutor returns the executor for proj's commands; kubeEx runs
+// those of kubernetes projects.
+func projectExecutor(cfg *config.Config, proj *config.ProjectConfig, kubeEx *kube.Executor) (executor.Executor, error) {
+	switch proj.Executor {
+	case config.ExecutorDocker:
+		image := proj.ExecutorImage
+		if image == "" {
+			image = cfg.Docker.Image
+		}
+		return executor.Docker{Image: image, ReposRoot: cfg.ReposRoot}, nil
+	case config.ExecutorKubernetes:
+		if kubeEx == nil {
+			return nil, fmt.Errorf("kubernetes executor is not set up")
+		}
+		return kubeEx.WithImage(proj.ExecutorImage), nil
+	case config.ExecutorSSH:
+		return executor.NewSSH(cfg.ReposRoot, *proj.SSH), nil
+	default:
+		return executor.Local{}, nil
 	}
 }
 
 // Run processes a job through the pipeline: plan -> implement <-> review -> tests -> ready.
-func (r *Runner) Run(ctx context.Context, jobID string) error {
+// Each run is recorded as one trace rooted at a "job" span.
+func (r *Runner) Run(ctx context.Context, jobID string) (err error) {
+	ctx, span := tracing.Start(ctx, "job", attribute.String("autopr.job_id", jobID))
+	defer func() { tracing.End(span, err) }()
+	return r.run(ctx, jobID)
+}
+
+func (r *Runner) run(ctx context.Context, jobID string) error {
 	runCtx, cancelRun := context.WithCancel(ctx)
 	defer cancelRun()
 	go r.watchForJobCancellation(runCtx, jobID, cancelRun)
@@ -68,20 +144,59 @@ func (r *Runner) Run(ctx context.Context, jobID string) error {
 	if err != nil {
 		return fmt.Errorf("get issue for job %s: %w", jobID, err)
 	}
+	trace.SpanFromContext(ctx).SetAttributes(
+		attribute.String("autopr.project", job.ProjectName),
+		attribute.String("autopr.issue", issue.Source+"#"+issue.SourceIssueID),
+		attribute.String("autopr.start_state", job.State),
+	)
 
 	projectCfg, ok := r.cfg.ProjectByName(job.ProjectName)
 	if !ok {
 		return r.failJob(ctx, jobID, job.State, "project not found: "+job.ProjectName)
 	}
+	if job.WorktreePath == "" {
+		if job, err = r.splitEpic(ctx, projectCfg, issue, job); err != nil {
+			return r.failJob(ctx, jobID, job.State, "split epic: "+err.Error())
+		}
+	}
+	issue = EpicTaskIssue(issue, job)
+	ex, err := r.executorFor(projectCfg)
+	if err != nil {
+		return r.failJob(ctx, jobID, job.State, "executor: "+err.Error())
+	}
+	// Steps run on ctx (setup) or runCtx, so both carry the executor.
+	ctx = executor.WithJob(ctx, ex, jobID)
+	runCtx = executor.WithJob(runCtx, ex, jobID)
+	// Local commands add what they used to the job's resource totals.
+	recordUsage := func(u executor.Usage) {
+		if err := r.store.AddJobResourceUsage(context.WithoutCancel(ctx), jobID, u.CPU, u.MaxRSS); err != nil {
+			slog.Warn("record job resource usage", "job", jobID, "err", err)
+		}
+	}
+	ctx = executor.WithUsage(ctx, recordUsage)
+	runCtx = executor.WithUsage(runCtx, recordUsage)
+
+	// Mark the source issue as taken so humans don't start duplicate work.
+	if r.acquireIssueLock != nil {
+		r.acquireIssueLock(runCtx, job)
+	}
 
 	// Determine token for git operations.
-	token := r.cfg.GitTokenForProject(projectCfg)
+	token := GitTokenForProject(runCtx, r.cfg, projectCfg)
 
 	// Clone repo directly for this job (regular clone, not a worktree).
-	branchName := buildBranchName(issue, jobID)
+	branchIssue := issue
+	if job.RevertCommit != "" {
+		branchIssue.Title = "revert " + issue.Title
+	}
 	worktreePath := filepath.Join(r.cfg.ReposRoot, "worktrees", jobID)
+	var branchName string
 
 	if job.WorktreePath == "" {
+		branchName, err = r.jobBranchName(runCtx, projectCfg, branchIssue, jobID, token)
+		if err != nil {
+			return r.failJob(ctx, jobID, job.State, "pick branch name: "+err.Error())
+		}
 		if err := r.store.UpdateJobField(ctx, jobID, "worktree_path", worktreePath); err != nil {
 			if r.jobCancelled(jobID) {
 				return r.onJobCancelled(jobID)
@@ -95,28 +210,82 @@ func (r *Runner) Run(ctx context.Context, jobID string) error {
 			return r.failJob(ctx, jobID, job.State, "set branch name: "+err.Error())
 		}
 
-		if err := r.cloneForJob(runCtx, projectCfg.RepoURL, token, worktreePath, branchName, projectCfg.BaseBranch); err != nil {
+		if err := r.cloneForJob(runCtx, projectCfg.RepoURL, token, worktreePath, branchName, TargetBranch(job, projectCfg), RemoteAuthForProject(projectCfg)); err != nil {
 			if r.isJobCancelledError(runCtx, jobID, err) {
 				return r.onJobCancelled(jobID)
 			}
 			return r.failJob(ctx, jobID, job.State, "clone for job: "+err.Error())
 		}
+		if err := SetupSigning(runCtx, r.cfg, projectCfg, worktreePath); err != nil {
+			return r.failJob(ctx, jobID, job.State, "configure commit signing: "+err.Error())
+		}
+		if err := r.traceStep(runCtx, "setup", job.Iteration, func(ctx context.Context) error {
+			return r.runSetup(ctx, job, issue, projectCfg, worktreePath)
+		}); err != nil {
+			if r.isJobCancelledError(runCtx, jobID, err) {
+				return r.onJobCancelled(jobID)
+			}
+			return r.failJob(ctx, jobID, job.State, err.Error())
+		}
 	} else {
 		worktreePath = job.WorktreePath
 		branchName = job.BranchName
 	}
 
-	// Run pipeline steps based on current state.
-	if err := r.runSteps(runCtx, jobID, job.State, issue, projectCfg, worktreePath); err != nil {
+	// Plain jobs get a one-line summary for job lists and notifications
+	// before planning; the issue title may say little about the work.
+	if job.BackportBranch == "" && job.RevertCommit == "" && job.BisectCmd == "" {
+		r.summarizeJob(runCtx, job, issue, worktreePath)
+	}
+
+	// Run pipeline steps based on current state. Backport and revert jobs
+	// cherry-pick or revert, and bisect jobs bisect, instead of planning and
+	// implementing.
+	run := func() error { return r.runSteps(runCtx, jobID, job.State, issue, projectCfg, worktreePath) }
+	switch {
+	case job.BackportBranch != "":
+		run = func() error { return r.runBackport(runCtx, jobID, issue, projectCfg, worktreePath) }
+	case job.RevertCommit != "":
+		run = func() error { return r.runRevert(runCtx, jobID, issue, projectCfg, worktreePath) }
+	case job.BisectCmd != "":
+		run = func() error { return r.runBisect(runCtx, jobID, issue, projectCfg, worktreePath) }
+	}
+	if err := run(); err != nil {
 		if errors.Is(err, errJobCancelled) {
 			return r.onJobCancelled(jobID)
 		}
+		if errors.Is(err, errWaitingOnNetwork) {
+			return nil
+		}
 		return err
 	}
 
-	// Auto-create PR if configured.
-	if r.cfg.Daemon.AutoPR {
-		return r.maybeAutoPR(runCtx, jobID, issue, projectCfg)
+	// A post_ready hook or the policy gate can still hold the job back from
+	// approval.
+	for _, gate := range []func(context.Context, string, db.Issue, *config.ProjectConfig) (bool, error){r.runPostReadyHooks, r.runPolicyGate} {
+		if ok, err := gate(runCtx, jobID, issue, projectCfg); err != nil || !ok {
+			if err != nil && r.isJobCancelledError(runCtx, jobID, err) {
+				return r.onJobCancelled(jobID)
+			}
+			return err
+		}
+	}
+
+	// Auto-create PR if configured. Revert jobs were explicitly requested as
+	// rollbacks, so their PR is always opened. If the forge is unreachable the
+	// PR is created from the outbox once connectivity returns.
+	if r.cfg.Daemon.AutoPR || job.RevertCommit != "" {
+		if err := r.maybeAutoPR(runCtx, jobID, issue, projectCfg); err != nil {
+			if errors.Is(err, errHookBlocked) {
+				// Leave the job ready for a human, with the reason.
+				_ = r.store.UpdateJobField(ctx, jobID, "error_message", err.Error())
+				return nil
+			}
+			if r.waitForNetwork(ctx, jobID, "ready", db.OutboxOpCreatePR, "auto-PR: "+err.Error(), projectCfg) {
+				return nil
+			}
+			return err
+		}
 	}
 
 	return nil
@@ -138,7 +307,7 @@ func (r *Runner) runSteps(ctx context.Context, jobID, currentState string, issue
 	iteration := job.Iteration
 
 	steps := []pipelineStep{
-		{state: "planning", next: "implementing", run: r.runPlan},
+		{state: "planning", next: "implementing", run: r.runPlanAndHooks},
 		{state: "implementing", next: "reviewing", run: r.runImplement},
 		{state: "reviewing", next: "testing", run: r.runCodeReview},
 		{state: "testing", next: "", run: r.runTestingAndReadiness, skipDefaultFailure: true},
@@ -178,7 +347,9 @@ func (r *Runner) runSteps(ctx context.Context, jobID, currentState string, issue
 
 		slog.Info("running step", "job", jobID, "step", db.StepForState(step.state))
 
-		if err := step.run(ctx, jobID, issue, projectCfg, workDir); err != nil {
+		if err := r.traceStep(ctx, stepName, iteration, func(ctx context.Context) error {
+			return step.run(ctx, jobID, issue, projectCfg, workDir)
+		}); err != nil {
 			if r.isJobCancelledError(ctx, jobID, err) {
 				return errJobCancelled
 			}
@@ -192,6 +363,24 @@ func (r *Runner) runSteps(ctx context.Context, jobID, currentState string, issue
 				}
 				return r.handleRetryLoop(ctx, jobID, issue, projectCfg, workDir)
 			}
+			// Path policy violated — loop back so the LLM reverts the files,
+			// but never hand a violating branch to a human as ready.
+			if errors.Is(err, errPathPolicyViolation) {
+				job, jerr := r.store.GetJob(ctx, jobID)
+				if jerr != nil {
+					return jerr
+				}
+				if job.Iteration >= job.MaxIterations {
+					return r.failJob(ctx, jobID, step.state, err.Error())
+				}
+				if err := r.store.TransitionState(ctx, jobID, "testing", "implementing"); err != nil {
+					if r.jobCancelled(jobID) {
+						return errJobCancelled
+					}
+					return err
+				}
+				return r.handleRetryLoop(ctx, jobID, issue, projectCfg, workDir)
+			}
 			// Tests failed — loop back to implementing so LLM can fix.
 			if errors.Is(err, errTestsFailed) {
 				slog.Info("tests failed, looping back to implement", "job", jobID)
@@ -239,6 +428,12 @@ func (r *Runner) handleRetryLoop(ctx context.Context, jobID string, issue db.Iss
 
 	if job.Iteration >= job.MaxIterations {
 		slog.Info("max iterations reached, moving to ready for human review", "job", jobID, "iterations", job.Iteration)
+		if paused, err := r.pauseIfOversize(ctx, jobID, projectCfg, workDir, job.State); err != nil || paused {
+			if err != nil && r.jobCancelled(jobID) {
+				return errJobCancelled
+			}
+			return err
+		}
 		if err := r.store.TransitionState(ctx, jobID, job.State, "ready"); err != nil && !r.jobCancelled(jobID) {
 			return err
 		}
@@ -257,6 +452,9 @@ func (r *Runner) handleRetryLoop(ctx context.Context, jobID string, issue db.Iss
 }
 
 func (r *Runner) failJob(ctx context.Context, jobID, fromState, errMsg string) error {
+	if r.waitForNetwork(ctx, jobID, fromState, db.OutboxOpResumeJob, errMsg, nil) {
+		return fmt.Errorf("job %s in %s: %w", jobID, fromState, errWaitingOnNetwork)
+	}
 	slog.Error("job failed", "job", jobID, "state", fromState, "error", errMsg)
 	_ = r.store.TransitionState(ctx, jobID, fromState, "failed")
 	_ = r.store.UpdateJobField(ctx, jobID, "error_message", errMsg)
@@ -274,9 +472,30 @@ func (r *Runner) invokeProvider(ctx context.Context, jobID, step string, iterati
 	if err != nil {
 		return llm.Response{}, fmt.Errorf("create session: %w", err)
 	}
+	if files := contextFilesFrom(ctx); len(files) > 0 {
+		if err := r.store.SetSessionContextFiles(ctx, sessionID, files); err != nil {
+			slog.Warn("failed to record session context files", "job", jobID, "session_id", sessionID, "err", err)
+		}
+	}
+	if base, err := git.LatestCommit(ctx, workDir); err == nil {
+		if err := r.store.SetSessionBaseSHA(ctx, sessionID, base); err != nil {
+			slog.Warn("failed to record session base commit", "job", jobID, "session_id", sessionID, "err", err)
+		}
+	}
+
+	// Streaming providers report the response as it grows; buffer it so the
+	// session row shows progress without a write per streamed line.
+	var progress *db.SessionBuffer
+	streaming, isStreaming := r.provider.(llm.StreamingProvider)
+	if isStreaming {
+		progress = r.store.NewSessionBuffer(sessionID, db.SessionFlushInterval)
+	}
 
 	var resp llm.Response
 	defer func() {
+		if progress != nil {
+			progress.Close()
+		}
 		status := "completed"
 		errMsg := ""
 		panicVal := recover()
@@ -305,10 +524,55 @@ func (r *Runner) invokeProvider(ctx context.Context, jobID, step string, iterati
 		}
 	}()
 
-	resp, err = r.provider.Run(ctx, workDir, prompt, jsonlPath)
+	spanCtx, span := tracing.Start(ctx, "llm "+step,
+		attribute.String("autopr.llm.provider", r.provider.Name()),
+		attribute.Int("autopr.iteration", iteration),
+	)
+	if env := r.providerEnv(ctx, jobID, step); len(env) > 0 {
+		spanCtx = llm.WithEnv(spanCtx, env)
+	}
+	// ap fsck checks that the process of a running session is alive.
+	spanCtx = llm.WithStarted(spanCtx, func(pid int) {
+		if err := r.store.SetSessionPID(ctx, sessionID, pid); err != nil {
+			slog.Warn("failed to record session pid", "job", jobID, "session_id", sessionID, "err", err)
+		}
+	})
+	if isStreaming {
+		resp, err = streaming.RunStreaming(spanCtx, workDir, prompt, jsonlPath, func(partial llm.Response) {
+			progress.Update(partial.Text, partial.InputTokens, partial.OutputTokens)
+		})
+	} else {
+		resp, err = r.provider.Run(spanCtx, workDir, prompt, jsonlPath)
+	}
+	span.SetAttributes(
+		attribute.Int("autopr.llm.input_tokens", resp.InputTokens),
+		attribute.Int("autopr.llm.output_tokens", resp.OutputTokens),
+	)
+	tracing.End(span, err)
 	return resp, err
 }
 
+// traceStep runs fn inside a span for one pipeline step. Outcomes that loop
+// the job back to implementing are recorded as attributes, not span errors.
+func (r *Runner) traceStep(ctx context.Context, step string, iteration int, fn func(context.Context) error) error {
+	ctx, span := tracing.Start(ctx, "step "+step, attribute.Int("autopr.iteration", iteration))
+	err := fn(ctx)
+	spanErr := err
+	switch {
+	case errors.Is(err, errReviewChangesRequested):
+		span.SetAttributes(attribute.String("autopr.step.outcome", "changes_requested"))
+		spanErr = nil
+	case errors.Is(err, errTestsFailed):
+		span.SetAttributes(attribute.String("autopr.step.outcome", "tests_failed"))
+		spanErr = nil
+	case errors.Is(err, errPathPolicyViolation):
+		span.SetAttributes(attribute.String("autopr.step.outcome", "path_policy_violation"))
+		spanErr = nil
+	}
+	tracing.End(span, spanErr)
+	return err
+}
+
 func sessionErrorMessage(err error) string {
 	switch {
 	case errors.Is(err, context.Canceled):
@@ -368,6 +632,8 @@ func (r *Runner) onJobCancelled(jobID string) error {
 //
 // If github.fork_owner is set, pushes go to the "fork" remote and PR head uses
 // "fork_owner:branch". Otherwise pushes go to origin and PR head is branch.
+// With a token, the fork is checked, created when github.create_fork is set,
+// and synced with upstream first (see ensureGitHubFork).
 func ResolveGitHubPushTarget(ctx context.Context, projectCfg *config.ProjectConfig, branchName, worktreePath, token string) (string, string, error) {
 	branchName = strings.TrimSpace(branchName)
 	if projectCfg == nil || projectCfg.GitHub == nil || strings.TrimSpace(projectCfg.GitHub.ForkOwner) == "" {
@@ -378,14 +644,20 @@ func ResolveGitHubPushTarget(ctx context.Context, projectCfg *config.ProjectConf
 	if forkOwner == "" {
 		return "", "", fmt.Errorf("project %q github.fork_owner cannot be blank", projectCfg.Name)
 	}
-	if strings.TrimSpace(token) == "" {
-		return "", "", fmt.Errorf("GITHUB_TOKEN required when github.fork_owner is set")
-	}
-
-	forkRemote := projectCfg.GitHub.GitHubForkRemote()
+	forkRemote := forkRemoteURL(projectCfg)
 	if forkRemote == "" {
 		return "", "", fmt.Errorf("project %q github.fork_owner could not resolve fork remote", projectCfg.Name)
 	}
+	if strings.TrimSpace(token) == "" && needsTokenForRemote(projectCfg, forkRemote) {
+		return "", "", fmt.Errorf("GITHUB_TOKEN required when github.fork_owner is set")
+	}
+	// Managing the fork goes through the API, which SSH-only setups without a
+	// token cannot use; their fork must already exist.
+	if strings.TrimSpace(token) != "" {
+		if err := ensureGitHubFork(ctx, projectCfg, token); err != nil {
+			return "", "", fmt.Errorf("prepare fork: %w", err)
+		}
+	}
 
 	if err := git.EnsureRemote(ctx, worktreePath, "fork", forkRemote); err != nil {
 		return "", "", fmt.Errorf("ensure fork remote: %w", err)
@@ -405,39 +677,21 @@ func (r *Runner) maybeAutoPR(ctx context.Context, jobID string, issue db.Issue,
 	if job.State != "ready" {
 		return nil
 	}
+	return r.openPR(ctx, job, issue, projectCfg, "ready")
+}
 
-	// Rebase onto latest base branch before pushing.
-	if err := RebaseBeforePush(ctx, r.store, job.ID, issue.AutoPRIssueID, projectCfg.BaseBranch, job.WorktreePath, job.Iteration, r.cfg.GitTokenForProject(projectCfg)); err != nil {
-		return fmt.Errorf("rebase before auto-PR push: %w", err)
-	}
-
-	remoteName := "origin"
-	head := job.BranchName
-	if projectCfg.GitHub != nil {
-		var err error
-		remoteName, head, err = r.prepareGitHubPushTarget(ctx, projectCfg, job.BranchName, job.WorktreePath, r.cfg.GitTokenForProject(projectCfg))
-		if err != nil {
-			return fmt.Errorf("resolve auto-PR push target: %w", err)
-		}
-	}
-
-	// Push branch to remote before creating PR.
-	if err := r.pushBranchWithLeaseToRemote(ctx, job.WorktreePath, remoteName, job.BranchName, r.cfg.GitTokenForProject(projectCfg)); err != nil {
-		return fmt.Errorf("push branch for auto-PR: %w", err)
+// openPR rebases, pushes, and opens the PR for a job in fromState (ready, or
+// waiting_network when replayed from the outbox), then moves the job on to
+// awaiting_checks or approved.
+func (r *Runner) openPR(ctx context.Context, job db.Job, issue db.Issue, projectCfg *config.ProjectConfig, fromState string) error {
+	jobID := job.ID
+	if job.PRURL == "" {
+		slog.Info("auto_pr enabled, creating PR", "job", jobID)
 	}
-
-	slog.Info("auto_pr enabled, creating PR", "job", jobID)
-
-	prTitle, prBody := BuildPRContent(ctx, r.store, job, issue)
-
-	prURL, err := r.createPRForProjectFn(ctx, r.cfg, projectCfg, job, head, prTitle, prBody, false)
+	prURL, err := r.publisher().publish(ctx, r.cfg, r.store, projectCfg, job, issue, "", false)
 	if err != nil {
-		slog.Error("auto-PR creation failed", "job", jobID, "err", err)
-		return fmt.Errorf("auto-create PR: %w", err)
-	}
-
-	if prURL != "" {
-		_ = r.store.UpdateJobField(ctx, jobID, "pr_url", prURL)
+		slog.Error("auto-PR failed", "job", jobID, "err", err)
+		return err
 	}
 
 	// GitHub projects with CI: transition to awaiting_checks so the daemon
@@ -448,7 +702,7 @@ func (r *Runner) maybeAutoPR(ctx context.Context, jobID string, issue db.Issue,
 		nextState = "awaiting_checks"
 	}
 
-	if err := r.store.TransitionState(ctx, jobID, "ready", nextState); err != nil {
+	if err := r.store.TransitionState(ctx, jobID, fromState, nextState); err != nil {
 		return err
 	}
 
@@ -456,55 +710,100 @@ func (r *Runner) maybeAutoPR(ctx context.Context, jobID string, issue db.Issue,
 	return nil
 }
 
-// CreatePRForProject creates a GitHub PR or GitLab MR based on project config.
+// CreatePRForProject creates a GitHub PR, GitLab MR, or Gitea PR based on project config.
+// Local projects have no forge: it returns an empty URL and the pushed branch
+// is the result.
 func CreatePRForProject(ctx context.Context, cfg *config.Config, proj *config.ProjectConfig, job db.Job, head, title, body string, draft bool) (string, error) {
 	if job.BranchName == "" {
 		return "", fmt.Errorf("job has no branch name — was the branch pushed?")
 	}
 
 	switch {
+	case proj.IsLocal():
+		return "", nil
+
 	case proj.GitHub != nil:
-		if cfg.Tokens.GitHub == "" {
+		token, err := githubapp.Token(ctx, cfg, proj)
+		if err != nil {
+			return "", fmt.Errorf("github token: %w", err)
+		}
+		if token == "" {
 			return "", fmt.Errorf("GITHUB_TOKEN required to create PR")
 		}
-		return git.CreateGitHubPR(ctx, cfg.Tokens.GitHub, proj.GitHub.Owner, proj.GitHub.Repo,
-			head, proj.BaseBranch, title, body, draft)
+		return git.CreateGitHubPR(ctx, token, proj.GitHub.BaseURL, proj.GitHub.Owner, proj.GitHub.Repo,
+			head, TargetBranch(job, proj), title, body, draft)
 
 	case proj.GitLab != nil:
 		if cfg.Tokens.GitLab == "" {
 			return "", fmt.Errorf("GITLAB_TOKEN required to create MR")
 		}
 		return git.CreateGitLabMR(ctx, cfg.Tokens.GitLab, proj.GitLab.BaseURL, proj.GitLab.ProjectID,
-			job.BranchName, proj.BaseBranch, title, body)
+			job.BranchName, TargetBranch(job, proj), title, body)
+
+	case proj.Gitea != nil:
+		if cfg.Tokens.Gitea == "" {
+			return "", fmt.Errorf("GITEA_TOKEN required to create PR")
+		}
+		// Gitea has no draft flag on create; a WIP: title prefix marks a draft.
+		if draft {
+			title = "WIP: " + title
+		}
+		return git.CreateGiteaPR(ctx, cfg.Tokens.Gitea, proj.Gitea.BaseURL, proj.Gitea.Owner, proj.Gitea.Repo,
+			job.BranchName, TargetBranch(job, proj), title, body)
 
 	default:
-		return "", fmt.Errorf("project %q has no GitHub or GitLab config for PR creation", proj.Name)
+		return "", fmt.Errorf("project %q has no GitHub, GitLab, or Gitea config for PR creation", proj.Name)
 	}
 }
 
 // BuildPRContent assembles the PR title and body from job data and artifacts.
-func BuildPRContent(ctx context.Context, store *db.Store, job db.Job, issue db.Issue) (string, string) {
+// cfg may be nil, which keeps the default body.
+func BuildPRContent(ctx context.Context, store *db.Store, cfg *config.Config, job db.Job, issue db.Issue) (string, string) {
 	title := fmt.Sprintf("[AutoPR] %s", issue.Title)
+	if job.EpicIndex > 0 {
+		title = fmt.Sprintf("[AutoPR] %s", job.EpicTask)
+	}
 
 	var body strings.Builder
-	body.WriteString(fmt.Sprintf("Closes %s\n\n", issue.URL))
-	body.WriteString(fmt.Sprintf("**Issue:** %s\n\n", issue.Title))
+	switch {
+	case job.RevertCommit != "":
+		title = fmt.Sprintf("[AutoPR] Revert %q", issue.Title)
+		body.WriteString(fmt.Sprintf("Reverts %s (merge commit %s).\n\n", parentPRRef(ctx, store, job), job.RevertCommit))
+	case job.BackportBranch != "":
+		// The original PR already closed the issue.
+		title = fmt.Sprintf("[AutoPR] [%s] %s", job.BackportBranch, issue.Title)
+		body.WriteString(fmt.Sprintf("Backport of %s to `%s` (cherry-picked %s).\n\n", parentPRRef(ctx, store, job), job.BackportBranch, job.BackportCommit))
+	case job.EpicIndex > 0 && issue.URL != "":
+		// The epic stays open until all of its tasks are done.
+		body.WriteString(fmt.Sprintf("Part of %s (task %d).\n\n", issue.URL, job.EpicIndex))
+	case issue.URL != "":
+		body.WriteString(fmt.Sprintf("Closes %s\n\n", issue.URL))
+	}
+	// issue is the epic, or the task as EpicTaskIssue presents it.
+	if job.EpicIndex > 0 {
+		body.WriteString(fmt.Sprintf("**Task:** %s\n\n", job.EpicTask))
+	} else {
+		body.WriteString(fmt.Sprintf("**Issue:** %s\n\n", issue.Title))
+	}
 
-	if plan, err := store.GetLatestArtifact(ctx, job.ID, "plan"); err == nil {
-		content := plan.Content
-		if len(content) > 2000 {
-			content = content[:2000] + "\n\n_(truncated)_"
+	if cfg != nil && cfg.Daemon.PRContext {
+		if section := prContextSection(ctx, store, cfg, job); section != "" {
+			body.WriteString(section)
 		}
+	} else if plan, err := store.GetLatestArtifact(ctx, job.ID, "plan"); err == nil {
 		body.WriteString("<details>\n<summary>Plan</summary>\n\n")
-		body.WriteString(content)
+		body.WriteString(truncatePRSection(plan.Content, 2000))
+		body.WriteString("\n</details>\n\n")
+	}
+
+	if generated, err := store.GetLatestArtifact(ctx, job.ID, generatedFilesArtifactKind); err == nil {
+		body.WriteString("<details>\n<summary>Generated files</summary>\n\n")
+		body.WriteString(generated.Content)
 		body.WriteString("\n</details>\n\n")
 	}
 
 	if conflictArtifact, err := store.GetLatestArtifact(ctx, job.ID, "rebase_conflict"); err == nil {
-		content := conflictArtifact.Content
-		if len(content) > 2000 {
-			content = content[:2000] + "\n\n_(truncated)_"
-		}
+		content := truncatePRSection(conflictArtifact.Content, 2000)
 		if strings.TrimSpace(content) != "" {
 			body.WriteString("<details>\n<summary>Rebase Conflict Resolution</summary>\n\n")
 			body.WriteString(content)
@@ -517,6 +816,14 @@ func BuildPRContent(ctx context.Context, store *db.Store, job db.Job, issue db.I
 	return title, body.String()
 }
 
+// parentPRRef returns the parent job's PR URL, or its short ID if unknown.
+func parentPRRef(ctx context.Context, store *db.Store, job db.Job) string {
+	if parent, err := store.GetJob(ctx, job.ParentJobID); err == nil && parent.PRURL != "" {
+		return parent.PRURL
+	}
+	return db.ShortID(job.ParentJobID)
+}
+
 // buildBranchName creates a descriptive branch name from the issue.
 // Includes a job-unique suffix to avoid collisions when repeated jobs target the same issue.
 // Example: autopr/github-42-fix-login-timeout-8aeda806
@@ -242,8 +242,8 @@ func TestMaybeAutoPR_ForkOwner_UnreachableRemoteValidationError(t *testing.T) {
 	if err == nil {
 		t.Fatal("expected error")
 	}
-	if !strings.Contains(err.Error(), "resolve auto-PR push target") {
-		t.Fatalf("expected resolve auto-PR push target error, got: %v", err)
+	if !strings.Contains(err.Error(), "resolve push target") {
+		t.Fatalf("expected resolve push target error, got: %v", err)
 	}
 
 	job, err := store.GetJob(ctx, jobID)
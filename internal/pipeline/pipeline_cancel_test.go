@@ -11,6 +11,7 @@ import (
 
 	"autopr/internal/config"
 	"autopr/internal/db"
+	"autopr/internal/git"
 	"autopr/internal/llm"
 )
 
@@ -182,7 +183,7 @@ func TestRunCancelledDuringCloneKeepsWorktreeTrackable(t *testing.T) {
 	runner := New(store, &neverCalledProvider{}, cfg)
 
 	cloneStarted := make(chan struct{})
-	runner.cloneForJob = func(ctx context.Context, repoURL, token, destPath, branchName, baseBranch string) error {
+	runner.cloneForJob = func(ctx context.Context, repoURL, token, destPath, branchName, baseBranch string, _ git.RemoteAuth) error {
 		if err := os.MkdirAll(destPath, 0o755); err != nil {
 			return err
 		}
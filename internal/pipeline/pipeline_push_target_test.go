@@ -64,3 +64,33 @@ func TestResolveGitHubPushTarget_ForkOwnerValidation(t *testing.T) {
 		t.Fatalf("expected token validation error with fork owner set")
 	}
 }
+
+func TestForkRemoteFollowsSSHRepoURL(t *testing.T) {
+	t.Parallel()
+
+	proj := &config.ProjectConfig{
+		Name:    "myproject",
+		RepoURL: "git@github.com:acme/repo.git",
+		GitHub:  &config.ProjectGitHub{Owner: "acme", Repo: "repo", ForkOwner: "my-fork"},
+	}
+	remote := forkRemoteURL(proj)
+	if remote != "git@github.com:my-fork/repo.git" {
+		t.Fatalf("expected SSH fork remote, got %q", remote)
+	}
+	if needsTokenForRemote(proj, remote) {
+		t.Fatalf("expected SSH fork remote to need no token")
+	}
+
+	proj.RepoURL = "https://github.com/acme/repo.git"
+	remote = forkRemoteURL(proj)
+	if remote != "https://github.com/my-fork/repo.git" {
+		t.Fatalf("expected HTTPS fork remote, got %q", remote)
+	}
+	if !needsTokenForRemote(proj, remote) {
+		t.Fatalf("expected HTTPS fork remote to need a token")
+	}
+	proj.GitAuth = &config.ProjectGitAuth{CredentialHelper: "osxkeychain"}
+	if needsTokenForRemote(proj, remote) {
+		t.Fatalf("expected credential helper to replace the token")
+	}
+}
@@ -7,8 +7,9 @@ import (
 	"strings"
 	"testing"
 
-	"autopr/internal/db"
 	"autopr/internal/config"
+	"autopr/internal/db"
+	"autopr/internal/executor"
 	"autopr/internal/llm"
 )
 
@@ -382,3 +383,32 @@ func TestRunStepsSkipsCompletedPrefixAndStartsFromFirstIncompleteStep(t *testing
 		t.Fatalf("expected code_review to run once, got %d (before %d)", got, reviewBefore)
 	}
 }
+
+func TestExecutorForProject(t *testing.T) {
+	t.Parallel()
+
+	cfg := &config.Config{ReposRoot: "/data/repos"}
+	cfg.Docker.Image = "worker:1"
+	r := &Runner{cfg: cfg}
+
+	ex, err := r.executorFor(&config.ProjectConfig{Executor: config.ExecutorLocal})
+	if err != nil || ex != (executor.Local{}) {
+		t.Fatalf("expected local executor, got %#v, %v", ex, err)
+	}
+	ex, err = r.executorFor(&config.ProjectConfig{Executor: config.ExecutorDocker, ExecutorImage: "node:2"})
+	if err != nil || ex != (executor.Docker{Image: "node:2", ReposRoot: "/data/repos"}) {
+		t.Fatalf("expected docker executor with the project image, got %#v, %v", ex, err)
+	}
+	ex, err = r.executorFor(&config.ProjectConfig{Executor: config.ExecutorDocker})
+	if err != nil || ex != (executor.Docker{Image: "worker:1", ReposRoot: "/data/repos"}) {
+		t.Fatalf("expected docker executor with the global image, got %#v, %v", ex, err)
+	}
+	if _, err := r.executorFor(&config.ProjectConfig{Executor: config.ExecutorKubernetes}); err == nil {
+		t.Fatal("expected an error for kubernetes without an executor set up")
+	}
+	ssh := &config.ProjectSSH{Host: "ci@builder", Port: 2222, RemoteDir: ".autopr/worktrees"}
+	ex, err = r.executorFor(&config.ProjectConfig{Executor: config.ExecutorSSH, SSH: ssh})
+	if err != nil || ex != executor.NewSSH("/data/repos", *ssh) {
+		t.Fatalf("expected ssh executor for the project host, got %#v, %v", ex, err)
+	}
+}
@@ -0,0 +1,60 @@
+package pipeline
+
+import (
+	"context"
+	"log/slog"
+
+	"autopr/internal/config"
+	"autopr/internal/db"
+	"autopr/internal/git"
+	"autopr/internal/policy"
+)
+
+// runPolicyGate asks the project's policy script whether a job the pipeline
+// has just moved to ready may go on to approval, and rejects the job if not.
+// It reports whether the job may go on. A policy that fails is logged and
+// lets the job through: a human still approves it.
+func (r *Runner) runPolicyGate(ctx context.Context, jobID string, issue db.Issue, projectCfg *config.ProjectConfig) (bool, error) {
+	pol, err := policy.ForProject(projectCfg)
+	if err != nil {
+		slog.Warn("load policy", "project", projectCfg.Name, "err", err)
+		return true, nil
+	}
+	if pol == nil {
+		return true, nil
+	}
+	job, err := r.store.GetJob(ctx, jobID)
+	if err != nil {
+		return false, err
+	}
+	if job.State != "ready" {
+		return true, nil
+	}
+
+	var diff policy.Diff
+	changes, err := git.DiffNumstatAgainstBase(ctx, job.WorktreePath, TargetBranch(job, projectCfg))
+	if err != nil {
+		slog.Warn("policy gate: diff against base", "job", jobID, "err", err)
+	}
+	for _, c := range changes {
+		if projectCfg.IsGenerated(c.Path) {
+			continue
+		}
+		diff.Files = append(diff.Files, c.Path)
+		diff.Lines += c.Lines
+	}
+
+	reason, err := pol.Gate(policy.JobFromDB(job), policy.IssueFromDB(issue), diff)
+	if err != nil {
+		slog.Warn("policy gate failed; letting the job through", "job", jobID, "err", err)
+		return true, nil
+	}
+	if reason == "" {
+		return true, nil
+	}
+	if err := r.store.RejectJob(ctx, jobID, "ready", "policy gate: "+reason); err != nil {
+		return false, err
+	}
+	slog.Info("policy gate rejected job", "job", jobID, "reason", reason)
+	return false, nil
+}
@@ -0,0 +1,65 @@
+package pipeline
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"autopr/internal/config"
+)
+
+func TestRunPolicyGateRejectsBlockedJob(t *testing.T) {
+	t.Parallel()
+
+	runner, store, issue, jobID := setupRunStepsJob(t, nil, "testing")
+	ctx := context.Background()
+	if err := store.TransitionState(ctx, jobID, "testing", "ready"); err != nil {
+		t.Fatalf("testing->ready: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "policy.star")
+	script := "def gate(job, issue, diff):\n    return \"needs a design review\" if job.state == \"ready\" else None\n"
+	if err := os.WriteFile(path, []byte(script), 0o644); err != nil {
+		t.Fatalf("write policy: %v", err)
+	}
+	proj := &config.ProjectConfig{Name: "project", BaseBranch: "main", PolicyFile: path}
+
+	ok, err := runner.runPolicyGate(ctx, jobID, issue, proj)
+	if err != nil || ok {
+		t.Fatalf("expected the job to be held back, got ok=%v err=%v", ok, err)
+	}
+	job, err := store.GetJob(ctx, jobID)
+	if err != nil {
+		t.Fatalf("get job: %v", err)
+	}
+	if job.State != "rejected" || job.RejectReason != "policy gate: needs a design review" {
+		t.Fatalf("expected rejected job, got state %q reason %q", job.State, job.RejectReason)
+	}
+}
+
+func TestRunPolicyGatePassesOnPolicyError(t *testing.T) {
+	t.Parallel()
+
+	runner, store, issue, jobID := setupRunStepsJob(t, nil, "testing")
+	ctx := context.Background()
+	if err := store.TransitionState(ctx, jobID, "testing", "ready"); err != nil {
+		t.Fatalf("testing->ready: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "policy.star")
+	if err := os.WriteFile(path, []byte("def gate(job, issue, diff):\n    return 1 // 0\n"), 0o644); err != nil {
+		t.Fatalf("write policy: %v", err)
+	}
+	proj := &config.ProjectConfig{Name: "project", BaseBranch: "main", PolicyFile: path}
+
+	ok, err := runner.runPolicyGate(ctx, jobID, issue, proj)
+	if err != nil || !ok {
+		t.Fatalf("expected a failing policy to let the job through, got ok=%v err=%v", ok, err)
+	}
+	job, err := store.GetJob(ctx, jobID)
+	if err != nil {
+		t.Fatalf("get job: %v", err)
+	}
+	if job.State != "ready" {
+		t.Fatalf("expected job to stay ready, got %q", job.State)
+	}
+}
@@ -0,0 +1,112 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"autopr/internal/config"
+	"autopr/internal/db"
+)
+
+const (
+	prContextPlanLimit   = 2000
+	prContextReviewLimit = 1500
+	prContextTestLines   = 30
+)
+
+// prContextSection renders the collapsed "AutoPR context" section of a PR
+// body: the approved plan, what code review found in each iteration, and the
+// tail of the latest test output. It returns "" when the job has no
+// artifacts yet.
+func prContextSection(ctx context.Context, store *db.Store, cfg *config.Config, job db.Job) string {
+	artifacts, err := store.ListArtifactsByJob(ctx, job.ID)
+	if err != nil {
+		return ""
+	}
+	var plan, test *db.Artifact
+	var reviews []db.Artifact
+	for i := range artifacts {
+		switch a := &artifacts[i]; a.Kind {
+		case "plan":
+			plan = a
+		case "code_review":
+			reviews = append(reviews, *a)
+		case "test_output":
+			test = a
+		}
+	}
+	if plan == nil && len(reviews) == 0 && test == nil {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("<details>\n<summary>AutoPR context: plan, review, and tests</summary>\n\n")
+	if plan != nil {
+		b.WriteString("#### Plan\n\n")
+		b.WriteString(truncatePRSection(plan.Content, prContextPlanLimit))
+		b.WriteString("\n\n")
+	}
+	if len(reviews) > 0 {
+		b.WriteString("#### Review\n\n")
+		for _, r := range reviews {
+			if isApproved(r.Content) {
+				fmt.Fprintf(&b, "- Iteration %d: approved\n", r.Iteration)
+			} else {
+				fmt.Fprintf(&b, "- Iteration %d: changes requested: %s\n", r.Iteration, firstLine(r.Content, 200))
+			}
+		}
+		b.WriteString("\n")
+		b.WriteString(truncatePRSection(reviews[len(reviews)-1].Content, prContextReviewLimit))
+		b.WriteString("\n\n")
+	}
+	if test != nil {
+		b.WriteString("#### Tests\n\n")
+		testCmd := ""
+		if proj, ok := cfg.ProjectByName(job.ProjectName); ok {
+			testCmd = strings.Join(proj.TestCommands(), "`, `")
+		}
+		if testCmd != "" {
+			fmt.Fprintf(&b, "Last lines of `%s` output (iteration %d):\n\n", testCmd, test.Iteration)
+		} else {
+			fmt.Fprintf(&b, "Last lines of test output (iteration %d):\n\n", test.Iteration)
+		}
+		b.WriteString("```\n")
+		b.WriteString(tailLines(strings.TrimRight(test.Content, "\n"), prContextTestLines))
+		b.WriteString("\n```\n\n")
+	}
+	b.WriteString("</details>\n\n")
+	return b.String()
+}
+
+// truncatePRSection cuts content to limit bytes with a marker.
+func truncatePRSection(content string, limit int) string {
+	if len(content) <= limit {
+		return content
+	}
+	return content[:limit] + "\n\n_(truncated)_"
+}
+
+// firstLine returns the first non-empty line of s, cut to limit bytes.
+func firstLine(s string, limit int) string {
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(line), "#*-"))
+		if line == "" {
+			continue
+		}
+		if len(line) > limit {
+			return line[:limit] + "..."
+		}
+		return line
+	}
+	return ""
+}
+
+// tailLines returns the last n lines of s.
+func tailLines(s string, n int) string {
+	lines := strings.Split(s, "\n")
+	if len(lines) <= n {
+		return s
+	}
+	return "...\n" + strings.Join(lines[len(lines)-n:], "\n")
+}
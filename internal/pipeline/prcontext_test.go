@@ -0,0 +1,64 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"autopr/internal/config"
+)
+
+func TestBuildPRContentEmbedsContextWhenEnabled(t *testing.T) {
+	t.Parallel()
+
+	_, store, issue, jobID := setupRunStepsJob(t, nil, "testing")
+	ctx := context.Background()
+	var testOutput strings.Builder
+	for i := 1; i <= 40; i++ {
+		fmt.Fprintf(&testOutput, "line %d\n", i)
+	}
+	for _, a := range []struct {
+		kind, content string
+		iteration     int
+	}{
+		{"plan", "1. Fix the timeout in login.go", 0},
+		{"code_review", "## Issues\n- Missing test for the retry path", 0},
+		{"code_review", "APPROVED. Looks good.", 1},
+		{"test_output", testOutput.String(), 1},
+	} {
+		if _, err := store.CreateArtifact(ctx, jobID, issue.AutoPRIssueID, a.kind, a.content, a.iteration, ""); err != nil {
+			t.Fatalf("create %s artifact: %v", a.kind, err)
+		}
+	}
+	job, err := store.GetJob(ctx, jobID)
+	if err != nil {
+		t.Fatalf("get job: %v", err)
+	}
+
+	_, body := BuildPRContent(ctx, store, nil, job, issue)
+	if !strings.Contains(body, "<summary>Plan</summary>") || strings.Contains(body, "AutoPR context") {
+		t.Fatalf("expected default plan-only body, got:\n%s", body)
+	}
+
+	cfg := &config.Config{
+		Daemon:   config.DaemonConfig{PRContext: true},
+		Projects: []config.ProjectConfig{{Name: job.ProjectName, TestCmd: "go test ./..."}},
+	}
+	_, body = BuildPRContent(ctx, store, cfg, job, issue)
+	for _, want := range []string{
+		"<summary>AutoPR context: plan, review, and tests</summary>",
+		"1. Fix the timeout in login.go",
+		"- Iteration 0: changes requested: Issues",
+		"- Iteration 1: approved",
+		"Last lines of `go test ./...` output (iteration 1)",
+		"line 40",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("PR body missing %q:\n%s", want, body)
+		}
+	}
+	if strings.Contains(body, "line 10\n") || strings.Contains(body, "<summary>Plan</summary>") {
+		t.Errorf("expected only the test output tail and no separate plan section:\n%s", body)
+	}
+}
@@ -75,6 +75,7 @@ func RebaseBeforePush(ctx context.Context, store *db.Store, jobID, issueAPID, ba
 	if _, err := store.CreateArtifact(ctx, jobID, issueAPID, rebaseResultArtifactKind, content, iteration, afterSHA); err != nil {
 		slog.Warn("failed to store approval-time rebase_result artifact", "job", jobID, "err", err)
 	}
+	recordSignatureStatus(ctx, store, jobID, workDir, baseBranch)
 	return nil
 }
 
@@ -92,8 +93,10 @@ func abortRebaseIfNeeded(ctx context.Context, workDir string) {
 
 func (r *Runner) runRebaseBeforeReady(ctx context.Context, jobID string, issue db.Issue, projectCfg *config.ProjectConfig, workDir string) error {
 	iteration := 0
+	baseBranch := projectCfg.BaseBranch
 	if job, err := r.store.GetJob(ctx, jobID); err == nil {
 		iteration = job.Iteration
+		baseBranch = TargetBranch(job, projectCfg)
 	} else {
 		slog.Warn("failed to load job for iteration", "job", jobID, "err", err)
 	}
@@ -108,7 +111,7 @@ func (r *Runner) runRebaseBeforeReady(ctx context.Context, jobID string, issue d
 	if err := git.ConfigureDiff3(ctx, workDir); err != nil {
 		return r.failJob(ctx, jobID, "rebasing", "configure git diff3 markers: "+err.Error())
 	}
-	if err := git.FetchBranch(ctx, workDir, projectCfg.BaseBranch, r.cfg.GitTokenForProject(projectCfg)); err != nil {
+	if err := git.FetchBranch(ctx, workDir, baseBranch, GitTokenForProject(ctx, r.cfg, projectCfg)); err != nil {
 		return r.failJob(ctx, jobID, "rebasing", "fetch base branch: "+err.Error())
 	}
 
@@ -117,7 +120,7 @@ func (r *Runner) runRebaseBeforeReady(ctx context.Context, jobID string, issue d
 		return r.failJob(ctx, jobID, "rebasing", "read head before rebase: "+err.Error())
 	}
 
-	hasConflicts, err := git.RebaseOntoBase(ctx, workDir, projectCfg.BaseBranch)
+	hasConflicts, err := git.RebaseOntoBase(ctx, workDir, baseBranch)
 	if err != nil {
 		r.abortRebaseIfNeeded(ctx, workDir)
 		return r.failJob(ctx, jobID, "rebasing", "rebase onto base: "+err.Error())
@@ -132,10 +135,11 @@ func (r *Runner) runRebaseBeforeReady(ctx context.Context, jobID string, issue d
 	if !hasConflicts {
 		if beforeSHA == afterSHA {
 			// No-op; branch already contains the latest base commit.
-			noopContent := fmt.Sprintf("No-op: branch already up to date with %s", projectCfg.BaseBranch)
+			noopContent := fmt.Sprintf("No-op: branch already up to date with %s", baseBranch)
 			if _, err := r.store.CreateArtifact(ctx, jobID, issue.AutoPRIssueID, rebaseResultArtifactKind, noopContent, iteration, afterSHA); err != nil {
 				slog.Warn("failed to store rebase_result artifact", "job", jobID, "err", err)
 			}
+			recordSignatureStatus(ctx, r.store, jobID, workDir, baseBranch)
 			if err := r.store.TransitionState(ctx, jobID, "rebasing", "ready"); err != nil {
 				if r.isJobCancelledError(ctx, jobID, err) {
 					return errJobCancelled
@@ -144,10 +148,11 @@ func (r *Runner) runRebaseBeforeReady(ctx context.Context, jobID string, issue d
 			}
 			return nil
 		}
-		cleanContent := fmt.Sprintf("Clean rebase onto %s (no conflicts)\nBefore: %s\nAfter: %s", projectCfg.BaseBranch, beforeSHA, afterSHA)
+		cleanContent := fmt.Sprintf("Clean rebase onto %s (no conflicts)\nBefore: %s\nAfter: %s", baseBranch, beforeSHA, afterSHA)
 		if _, err := r.store.CreateArtifact(ctx, jobID, issue.AutoPRIssueID, rebaseResultArtifactKind, cleanContent, iteration, afterSHA); err != nil {
 			slog.Warn("failed to store rebase_result artifact", "job", jobID, "err", err)
 		}
+		recordSignatureStatus(ctx, r.store, jobID, workDir, baseBranch)
 		return r.rerunTestsAndMarkReady(ctx, jobID, issue, projectCfg, workDir, "rebasing")
 	}
 
@@ -161,23 +166,17 @@ func (r *Runner) runRebaseBeforeReady(ctx context.Context, jobID string, issue d
 		return r.failJob(ctx, jobID, "rebasing", "no conflict files or parseable conflict regions found")
 	}
 
-	artifactText := fmt.Sprintf("Conflicts after rebasing onto %s\n\n%s", projectCfg.BaseBranch, conflicts.summary)
+	artifactText := fmt.Sprintf("Conflicts after rebasing onto %s\n\n%s", baseBranch, conflicts.summary)
 	if _, err := r.store.CreateArtifact(ctx, jobID, issue.AutoPRIssueID, rebaseConflictArtifactKind, artifactText, iteration, ""); err != nil {
 		slog.Warn("failed to store rebase conflict artifact", "job", jobID, "err", err)
 	}
 
-	maxAutoResolvableConflictLines := config.DefaultMaxAutoResolvableConflictLines
-	if projectCfg != nil {
-		maxAutoResolvableConflictLines = projectCfg.MaxAutoResolvableConflictLines
-	}
-	if maxAutoResolvableConflictLines <= 0 {
-		maxAutoResolvableConflictLines = config.DefaultMaxAutoResolvableConflictLines
-	}
-	if conflicts.conflictLines >= maxAutoResolvableConflictLines {
+	limit := maxAutoResolvableConflictLines(projectCfg)
+	if conflicts.conflictLines >= limit {
 		r.abortRebaseIfNeeded(ctx, workDir)
 		return r.failJob(ctx, jobID, "rebasing",
 			fmt.Sprintf("rebase conflict line count %d reached limit %d (%s)",
-				conflicts.conflictLines, maxAutoResolvableConflictLines, strings.Join(conflicts.filePaths, ", ")))
+				conflicts.conflictLines, limit, strings.Join(conflicts.filePaths, ", ")))
 	}
 
 	if err := r.store.TransitionState(ctx, jobID, "rebasing", "resolving_conflicts"); err != nil {
@@ -188,7 +187,7 @@ func (r *Runner) runRebaseBeforeReady(ctx context.Context, jobID string, issue d
 		return err
 	}
 
-	if err := r.resolveRebaseConflictsWithLLM(ctx, jobID, issue, projectCfg, workDir, iteration, conflicts); err != nil {
+	if err := r.resolveRebaseConflictsWithLLM(ctx, jobID, issue, projectCfg, workDir, iteration, conflicts, baseBranch, git.RebaseContinue); err != nil {
 		r.abortRebaseIfNeeded(ctx, workDir)
 		if r.isJobCancelledError(ctx, jobID, err) {
 			return errJobCancelled
@@ -196,10 +195,14 @@ func (r *Runner) runRebaseBeforeReady(ctx context.Context, jobID string, issue d
 		return r.failJob(ctx, jobID, "resolving_conflicts", err.Error())
 	}
 
+	recordSignatureStatus(ctx, r.store, jobID, workDir, baseBranch)
 	return r.rerunTestsAndMarkReady(ctx, jobID, issue, projectCfg, workDir, "resolving_conflicts")
 }
 
-func (r *Runner) resolveRebaseConflictsWithLLM(ctx context.Context, jobID string, issue db.Issue, projectCfg *config.ProjectConfig, workDir string, iteration int, conflicts rebaseConflictReport) error {
+// resolveRebaseConflictsWithLLM asks the LLM to resolve conflicts left by
+// applying the job's commits onto baseBranch, then stages the result and
+// resumes the interrupted operation via continueFn (rebase or cherry-pick).
+func (r *Runner) resolveRebaseConflictsWithLLM(ctx context.Context, jobID string, issue db.Issue, projectCfg *config.ProjectConfig, workDir string, iteration int, conflicts rebaseConflictReport, baseBranch string, continueFn func(ctx context.Context, dir string) (bool, error)) error {
 	template := defaultConflictResolvePrompt
 	if projectCfg.Prompts != nil && projectCfg.Prompts.ConflictResolve != "" {
 		if custom := LoadTemplate(projectCfg.Prompts.ConflictResolve); custom != "" {
@@ -208,7 +211,7 @@ func (r *Runner) resolveRebaseConflictsWithLLM(ctx context.Context, jobID string
 	}
 
 	prompt := BuildPrompt(template, map[string]string{
-		"base_branch":      projectCfg.BaseBranch,
+		"base_branch":      baseBranch,
 		"conflict_files":   sanitizeConflictFilePaths(conflicts.filePaths),
 		"conflict_details": SanitizeIssueContent(conflicts.summary),
 	})
@@ -227,17 +230,28 @@ func (r *Runner) resolveRebaseConflictsWithLLM(ctx context.Context, jobID string
 		return fmt.Errorf("verify resolved conflicts: %w", err)
 	}
 
-	hasMoreConflicts, err := git.RebaseContinue(ctx, workDir)
+	hasMoreConflicts, err := continueFn(ctx, workDir)
 	if err != nil {
-		return fmt.Errorf("rebase continue: %w", err)
+		return fmt.Errorf("continue after conflict resolution: %w", err)
 	}
 	if hasMoreConflicts {
-		return errors.New("multiple rebase conflicts remain after LLM resolution")
+		return errors.New("multiple conflicts remain after LLM resolution")
 	}
 
 	return nil
 }
 
+func maxAutoResolvableConflictLines(projectCfg *config.ProjectConfig) int {
+	limit := config.DefaultMaxAutoResolvableConflictLines
+	if projectCfg != nil {
+		limit = projectCfg.MaxAutoResolvableConflictLines
+	}
+	if limit <= 0 {
+		limit = config.DefaultMaxAutoResolvableConflictLines
+	}
+	return limit
+}
+
 func (r *Runner) rerunTestsAndMarkReady(ctx context.Context, jobID string, issue db.Issue, projectCfg *config.ProjectConfig, workDir, fromState string) error {
 	err := r.runTests(ctx, jobID, issue, projectCfg, workDir)
 	if err != nil {
@@ -420,7 +434,7 @@ const defaultConflictResolvePrompt = `You are an expert software engineer. Resol
 </base_branch>
 
 <conflict_details>
-The job branch was rebased onto {{base_branch}}.
+The job branch was rebased (or, for backports, cherry-picked) onto {{base_branch}}.
 The following files have conflicts:
 
 {{conflict_files}}
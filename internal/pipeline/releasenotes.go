@@ -0,0 +1,63 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"autopr/internal/config"
+	"autopr/internal/db"
+	"autopr/internal/git"
+	"autopr/internal/releasenotes"
+)
+
+// changelogFile is the file a release notes PR updates, at the repository
+// root.
+const changelogFile = "CHANGELOG.md"
+
+// OpenReleaseNotesPR puts section at the top of the project's CHANGELOG.md on
+// a new branch, pushes it, and opens a PR against the base branch. It returns
+// the PR URL, or "" for local projects, which get the branch only. approver
+// is credited as for job commits; see ApplyCommitTrailers.
+func OpenReleaseNotesPR(ctx context.Context, cfg *config.Config, proj *config.ProjectConfig, heading, section, approver string) (string, error) {
+	branch := "autopr/release-notes-" + slugify(heading)
+	dir := filepath.Join(cfg.ReposRoot, "release-notes", proj.Name)
+	defer git.RemoveJobDir(dir)
+
+	token := GitTokenForProject(ctx, cfg, proj)
+	if err := git.CloneForJobWithAuth(ctx, proj.RepoURL, token, dir, branch, proj.BaseBranch, RemoteAuthForProject(proj)); err != nil {
+		return "", err
+	}
+	if err := SetupSigning(ctx, cfg, proj, dir); err != nil {
+		return "", fmt.Errorf("configure commit signing: %w", err)
+	}
+
+	path := filepath.Join(dir, changelogFile)
+	existing, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("read %s: %w", changelogFile, err)
+	}
+	if err := os.WriteFile(path, []byte(releasenotes.PrependChangelog(string(existing), section)), 0o644); err != nil {
+		return "", fmt.Errorf("write %s: %w", changelogFile, err)
+	}
+	if _, err := git.CommitAll(ctx, dir, "docs: release notes for "+heading); err != nil {
+		return "", err
+	}
+	if err := ApplyCommitTrailers(ctx, cfg, proj, db.Job{}, dir, proj.BaseBranch, approver); err != nil {
+		return "", err
+	}
+
+	remote, head := "origin", branch
+	if proj.GitHub != nil {
+		if remote, head, err = ResolveGitHubPushTarget(ctx, proj, branch, dir, token); err != nil {
+			return "", fmt.Errorf("resolve push target: %w", err)
+		}
+	}
+	if err := git.PushBranchWithLeaseToRemoteWithToken(ctx, dir, remote, branch, token); err != nil {
+		return "", fmt.Errorf("push branch: %w", err)
+	}
+	body := "Release notes assembled by `ap release-notes` from the AutoPR jobs merged since the last release.\n\n" + section
+	return CreatePRForProject(ctx, cfg, proj, db.Job{BranchName: branch}, head, "Release notes for "+heading, body, false)
+}
@@ -0,0 +1,153 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"autopr/internal/config"
+	"autopr/internal/db"
+	"autopr/internal/safepath"
+)
+
+// repoContextFile is the style guide and context doc a repository keeps for
+// AutoPR, added to every plan and implement prompt when present.
+const repoContextFile = ".autopr/context.md"
+
+// Limits on the repository docs added to a prompt. A file beyond
+// maxContextFileBytes, or past maxContextBytes in all, is cut off.
+const (
+	maxContextFileBytes = 32 << 10
+	maxContextBytes     = 96 << 10
+)
+
+// repoContextDoc is a repository doc loaded for a prompt.
+type repoContextDoc struct {
+	db.ContextFile
+	Content string
+}
+
+// repoContextPaths lists the docs a project's prompts draw on, relative to
+// the repository root: .autopr/context.md, the scope's own
+// .autopr/context.md, and the project's context_files.
+func repoContextPaths(proj *config.ProjectConfig) []string {
+	paths := []string{repoContextFile}
+	if scope := proj.ScopePath(); scope != "" {
+		paths = append(paths, path.Join(scope, repoContextFile))
+	}
+	for _, p := range proj.ContextFiles {
+		if !slices.Contains(paths, p) {
+			paths = append(paths, p)
+		}
+	}
+	return paths
+}
+
+// loadRepoContext reads the project's context docs from the worktree at
+// workDir. Missing .autopr/context.md files are skipped quietly; missing or
+// unreadable context_files are logged and skipped.
+func loadRepoContext(workDir string, proj *config.ProjectConfig) []repoContextDoc {
+	var (
+		docs   []repoContextDoc
+		budget = maxContextBytes
+	)
+	for _, rel := range repoContextPaths(proj) {
+		if budget <= 0 {
+			slog.Warn("repo context: prompt budget used up, skipping file", "project", proj.Name, "file", rel)
+			continue
+		}
+		content, truncated, err := readContextFile(workDir, rel, min(budget, maxContextFileBytes))
+		if err != nil {
+			if !errors.Is(err, os.ErrNotExist) || slices.Contains(proj.ContextFiles, rel) {
+				slog.Warn("repo context: skipping file", "project", proj.Name, "file", rel, "err", err)
+			}
+			continue
+		}
+		if strings.TrimSpace(content) == "" {
+			continue
+		}
+		budget -= len(content)
+		docs = append(docs, repoContextDoc{ContextFile: db.ContextFile{Path: rel, Bytes: len(content), Truncated: truncated}, Content: content})
+	}
+	return docs
+}
+
+// readContextFile reads up to limit bytes of the regular file rel in the
+// worktree, refusing paths that leave it through a symlink. It reports
+// whether the file was longer.
+func readContextFile(workDir, rel string, limit int) (string, bool, error) {
+	root, err := filepath.EvalSymlinks(workDir)
+	if err != nil {
+		return "", false, err
+	}
+	target := filepath.Join(root, filepath.FromSlash(rel))
+	if _, err := os.Lstat(target); err != nil {
+		return "", false, err
+	}
+	resolved, err := safepath.ResolveNoSymlinkPath(root, target)
+	if err != nil {
+		return "", false, err
+	}
+	f, err := os.Open(resolved)
+	if err != nil {
+		return "", false, err
+	}
+	defer f.Close()
+	if info, err := f.Stat(); err != nil {
+		return "", false, err
+	} else if !info.Mode().IsRegular() {
+		return "", false, fmt.Errorf("not a regular file")
+	}
+	data, err := io.ReadAll(io.LimitReader(f, int64(limit)+1))
+	if err != nil {
+		return "", false, err
+	}
+	if len(data) <= limit {
+		return string(data), false, nil
+	}
+	data = data[:limit]
+	// Cut at a rune boundary, so the prompt stays valid UTF-8.
+	for len(data) > 0 && !utf8.Valid(data) {
+		data = data[:len(data)-1]
+	}
+	return string(data), true, nil
+}
+
+// withRepoContext appends the repository's context docs to a plan or
+// implement prompt, and returns a context under which invokeProvider records
+// them on the session.
+func withRepoContext(ctx context.Context, prompt string, docs []repoContextDoc) (context.Context, string) {
+	if len(docs) == 0 {
+		return ctx, prompt
+	}
+	var b strings.Builder
+	b.WriteString("\n\n<repo_context>\nThe repository's maintainers provide these docs on its conventions and layout. Follow them.\n")
+	files := make([]db.ContextFile, 0, len(docs))
+	for _, d := range docs {
+		fmt.Fprintf(&b, "\n<file path=%q>\n%s\n", d.Path, strings.TrimRight(d.Content, "\n"))
+		if d.Truncated {
+			b.WriteString("[truncated]\n")
+		}
+		b.WriteString("</file>\n")
+		files = append(files, d.ContextFile)
+	}
+	b.WriteString("</repo_context>")
+	return context.WithValue(ctx, contextFilesKey{}, files), prompt + b.String()
+}
+
+type contextFilesKey struct{}
+
+// contextFilesFrom returns the docs withRepoContext added to the prompt being
+// sent under ctx.
+func contextFilesFrom(ctx context.Context) []db.ContextFile {
+	files, _ := ctx.Value(contextFilesKey{}).([]db.ContextFile)
+	return files
+}
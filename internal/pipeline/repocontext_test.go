@@ -0,0 +1,89 @@
+package pipeline
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"autopr/internal/config"
+	"autopr/internal/db"
+	"autopr/internal/llm"
+)
+
+func writeContextFile(t *testing.T, dir, rel, content string) {
+	t.Helper()
+	path := filepath.Join(dir, filepath.FromSlash(rel))
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write %s: %v", rel, err)
+	}
+}
+
+func TestRunPlanAddsRepoContext(t *testing.T) {
+	t.Parallel()
+
+	var gotPrompt string
+	provider := stubProvider{run: func(ctx context.Context, workDir, prompt string) (llm.Response, error) {
+		gotPrompt = prompt
+		return llm.Response{Text: "1. Edit main.go"}, nil
+	}}
+	runner, store, issue, jobID := setupRunStepsJob(t, provider, "planning")
+	ctx := context.Background()
+
+	workDir := t.TempDir()
+	writeContextFile(t, workDir, ".autopr/context.md", "Use the internal logger.\n")
+	writeContextFile(t, workDir, "docs/STYLE.md", "Tabs, not spaces.\n")
+	proj := &config.ProjectConfig{ContextFiles: []string{"docs/STYLE.md", "docs/MISSING.md"}}
+
+	if err := runner.runPlan(ctx, jobID, issue, proj, workDir); err != nil {
+		t.Fatalf("run plan: %v", err)
+	}
+	for _, want := range []string{"<repo_context>", "<file path=\".autopr/context.md\">\nUse the internal logger.\n</file>", "<file path=\"docs/STYLE.md\">\nTabs, not spaces.\n</file>"} {
+		if !strings.Contains(gotPrompt, want) {
+			t.Errorf("prompt missing %q:\n%s", want, gotPrompt)
+		}
+	}
+	if strings.Index(gotPrompt, "<repo_context>") > strings.Index(gotPrompt, "## Decisions") {
+		t.Errorf("expected repo context before the decisions request:\n%s", gotPrompt)
+	}
+
+	sessions, err := store.ListSessionsByJob(ctx, jobID)
+	if err != nil || len(sessions) != 1 {
+		t.Fatalf("list sessions = %d, %v", len(sessions), err)
+	}
+	sess, err := store.GetFullSession(ctx, sessions[0].ID)
+	if err != nil {
+		t.Fatalf("get session: %v", err)
+	}
+	want := []db.ContextFile{{Path: ".autopr/context.md", Bytes: 25}, {Path: "docs/STYLE.md", Bytes: 18}}
+	if !slices.Equal(sess.ContextFiles, want) {
+		t.Fatalf("session context files = %+v, want %+v", sess.ContextFiles, want)
+	}
+}
+
+func TestLoadRepoContextLimitsAndSymlinks(t *testing.T) {
+	t.Parallel()
+
+	workDir := t.TempDir()
+	writeContextFile(t, workDir, "docs/BIG.md", strings.Repeat("é", maxContextFileBytes))
+	outside := filepath.Join(t.TempDir(), "secret.md")
+	if err := os.WriteFile(outside, []byte("secret"), 0o644); err != nil {
+		t.Fatalf("write outside file: %v", err)
+	}
+	if err := os.Symlink(outside, filepath.Join(workDir, "docs", "LINK.md")); err != nil {
+		t.Skipf("symlinks unsupported: %v", err)
+	}
+
+	docs := loadRepoContext(workDir, &config.ProjectConfig{ContextFiles: []string{"docs/BIG.md", "docs/LINK.md"}})
+	if len(docs) != 1 || docs[0].Path != "docs/BIG.md" || !docs[0].Truncated {
+		t.Fatalf("unexpected docs: %+v", docs)
+	}
+	if docs[0].Bytes > maxContextFileBytes || !strings.HasSuffix(docs[0].Content, "é") {
+		t.Fatalf("expected the file cut to %d bytes at a rune boundary, got %d bytes", maxContextFileBytes, docs[0].Bytes)
+	}
+}
@@ -0,0 +1,84 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"autopr/internal/config"
+	"autopr/internal/db"
+	"autopr/internal/git"
+)
+
+// retargetStates are the states a job can be retargeted in: not yet started,
+// or stopped waiting on a human. Approved jobs have a PR against their old
+// base branch already.
+var retargetStates = map[string]bool{
+	"queued": true, "ready": true, "needs_review_oversize": true,
+	"failed": true, "rejected": true, "cancelled": true,
+}
+
+// RetargetJob moves a job onto another base branch before it is approved. A
+// job with a worktree has its commits rebased from its current target branch
+// onto branch; the move is recorded as a rebase_result artifact either way.
+// The branch is saved on the job, so later rebases, diffs, and the PR use it
+// in place of the project's base branch. Callers hold the job's lease.
+func RetargetJob(ctx context.Context, cfg *config.Config, store *db.Store, job db.Job, proj *config.ProjectConfig, branch string) error {
+	branch = strings.TrimSpace(branch)
+	if branch == "" {
+		return fmt.Errorf("branch is empty")
+	}
+	if job.BackportBranch != "" {
+		return fmt.Errorf("job %s is a backport onto %s; backports cannot be retargeted", db.ShortID(job.ID), job.BackportBranch)
+	}
+	if !retargetStates[job.State] {
+		return fmt.Errorf("job %s is %s; only queued, ready, needs_review_oversize, failed, rejected, and cancelled jobs can be retargeted", db.ShortID(job.ID), job.State)
+	}
+	from := TargetBranch(job, proj)
+	if branch == from {
+		return fmt.Errorf("job %s already targets %s", db.ShortID(job.ID), branch)
+	}
+
+	content := fmt.Sprintf("Retargeted from %s to %s before the job was cloned", from, branch)
+	var afterSHA string
+	if job.WorktreePath != "" {
+		if _, err := os.Stat(job.WorktreePath); err != nil {
+			return fmt.Errorf("worktree of job %s not found: %w", db.ShortID(job.ID), err)
+		}
+		token := GitTokenForProject(ctx, cfg, proj)
+		for _, b := range []string{from, branch} {
+			if err := git.FetchBranch(ctx, job.WorktreePath, b, token); err != nil {
+				return fmt.Errorf("fetch %s: %w", b, err)
+			}
+		}
+		beforeSHA, err := git.LatestCommit(ctx, job.WorktreePath)
+		if err != nil {
+			return fmt.Errorf("read HEAD before rebase: %w", err)
+		}
+		hasConflicts, err := git.RebaseOntoOtherBase(ctx, job.WorktreePath, from, branch)
+		if err != nil {
+			abortRebaseIfNeeded(ctx, job.WorktreePath)
+			return fmt.Errorf("rebase onto %s: %w", branch, err)
+		}
+		if hasConflicts {
+			conflictFiles, _ := git.ConflictedFiles(ctx, job.WorktreePath)
+			abortRebaseIfNeeded(ctx, job.WorktreePath)
+			return fmt.Errorf("rebase onto %s has conflicts, so the job still targets %s: %s",
+				branch, from, strings.Join(conflictFiles, ", "))
+		}
+		afterSHA, err = git.LatestCommit(ctx, job.WorktreePath)
+		if err != nil {
+			return fmt.Errorf("read HEAD after rebase: %w", err)
+		}
+		content = fmt.Sprintf("Retargeted from %s to %s\nBefore: %s\nAfter: %s", from, branch, beforeSHA, afterSHA)
+	}
+
+	if err := store.UpdateJobField(ctx, job.ID, "base_branch", branch); err != nil {
+		return err
+	}
+	if _, err := store.CreateArtifact(ctx, job.ID, job.AutoPRIssueID, rebaseResultArtifactKind, content, job.Iteration, afterSHA); err != nil {
+		return fmt.Errorf("record retarget: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package pipeline
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"autopr/internal/config"
+	"autopr/internal/db"
+)
+
+func TestRetargetJobRebasesOntoNewBase(t *testing.T) {
+	t.Setenv("GIT_AUTHOR_NAME", "Test User")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "Test User")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+
+	ctx := context.Background()
+	tmp := t.TempDir()
+
+	store, err := db.Open(filepath.Join(tmp, "autopr.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer store.Close()
+
+	// release-1.2 forks from the first commit; main then moves on.
+	remote := createBareRemoteWithMain(t, tmp)
+	seed := filepath.Join(tmp, "seed")
+	runGitCmdLocal(t, seed, "push", "origin", "main:release-1.2")
+	if err := os.WriteFile(filepath.Join(seed, "main.txt"), []byte("main only\n"), 0o644); err != nil {
+		t.Fatalf("write main file: %v", err)
+	}
+	runGitCmdLocal(t, seed, "add", "main.txt")
+	runGitCmdLocal(t, seed, "commit", "-m", "main only")
+	runGitCmdLocal(t, seed, "push", "origin", "main")
+
+	worktree := filepath.Join(tmp, "worktree")
+	runGitCmdLocal(t, "", "clone", "-b", "main", remote, worktree)
+	runGitCmdLocal(t, worktree, "checkout", "-b", "autopr/fix")
+	if err := os.WriteFile(filepath.Join(worktree, "fix.txt"), []byte("fix\n"), 0o644); err != nil {
+		t.Fatalf("write fix: %v", err)
+	}
+	runGitCmdLocal(t, worktree, "add", "fix.txt")
+	runGitCmdLocal(t, worktree, "commit", "-m", "fix")
+
+	cfg := &config.Config{Projects: []config.ProjectConfig{{Name: "myproject", RepoURL: remote, BaseBranch: "main"}}}
+	proj := &cfg.Projects[0]
+	issueID, err := store.UpsertIssue(ctx, db.IssueUpsert{
+		ProjectName:   "myproject",
+		Source:        "github",
+		SourceIssueID: "9",
+		Title:         "fix on release",
+		URL:           "https://github.com/acme/repo/issues/9",
+		State:         "open",
+	})
+	if err != nil {
+		t.Fatalf("upsert issue: %v", err)
+	}
+	jobID, err := store.CreateJob(ctx, issueID, "myproject", 3)
+	if err != nil {
+		t.Fatalf("create job: %v", err)
+	}
+	if _, err := store.Writer.ExecContext(ctx, `UPDATE jobs SET state = 'ready', worktree_path = ?, branch_name = 'autopr/fix' WHERE id = ?`, worktree, jobID); err != nil {
+		t.Fatalf("mark ready: %v", err)
+	}
+	job, err := store.GetJob(ctx, jobID)
+	if err != nil {
+		t.Fatalf("get job: %v", err)
+	}
+
+	if err := RetargetJob(ctx, cfg, store, job, proj, "main"); err == nil || !strings.Contains(err.Error(), "already targets main") {
+		t.Fatalf("expected already-targets error, got %v", err)
+	}
+	if err := RetargetJob(ctx, cfg, store, job, proj, "release-1.2"); err != nil {
+		t.Fatalf("retarget: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(worktree, "main.txt")); !os.IsNotExist(err) {
+		t.Fatalf("expected main-only commit to be left behind, stat err = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(worktree, "fix.txt")); err != nil {
+		t.Fatalf("expected the job's commit to be kept: %v", err)
+	}
+	parent, err := runGitCommandOutput(t, worktree, "rev-parse", "HEAD~1")
+	if err != nil {
+		t.Fatalf("rev-parse HEAD~1: %v", err)
+	}
+	release, err := runGitCommandOutput(t, worktree, "rev-parse", "origin/release-1.2")
+	if err != nil {
+		t.Fatalf("rev-parse release: %v", err)
+	}
+	if strings.TrimSpace(parent) != strings.TrimSpace(release) {
+		t.Fatalf("expected the job's commit on top of release-1.2, parent %s, release %s", parent, release)
+	}
+
+	job, err = store.GetJob(ctx, jobID)
+	if err != nil {
+		t.Fatalf("get job: %v", err)
+	}
+	if job.BaseBranch != "release-1.2" || TargetBranch(job, proj) != "release-1.2" {
+		t.Fatalf("expected job to target release-1.2, base_branch %q", job.BaseBranch)
+	}
+	art, err := store.GetLatestArtifact(ctx, jobID, rebaseResultArtifactKind)
+	if err != nil {
+		t.Fatalf("get artifact: %v", err)
+	}
+	if !strings.HasPrefix(art.Content, "Retargeted from main to release-1.2\n") {
+		t.Fatalf("unexpected artifact content: %q", art.Content)
+	}
+}
+
+func TestRetargetJobRejectsRunningAndBackportJobs(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	store, err := db.Open(filepath.Join(t.TempDir(), "autopr.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer store.Close()
+	cfg := &config.Config{Projects: []config.ProjectConfig{{Name: "myproject", BaseBranch: "main"}}}
+	proj := &cfg.Projects[0]
+
+	running := db.Job{ID: "ap-job-running", State: "implementing"}
+	if err := RetargetJob(ctx, cfg, store, running, proj, "release"); err == nil || !strings.Contains(err.Error(), "is implementing") {
+		t.Fatalf("expected state error, got %v", err)
+	}
+	backport := db.Job{ID: "ap-job-backport", State: "ready", BackportBranch: "release-1.1"}
+	if err := RetargetJob(ctx, cfg, store, backport, proj, "release"); err == nil || !strings.Contains(err.Error(), "backport") {
+		t.Fatalf("expected backport error, got %v", err)
+	}
+}
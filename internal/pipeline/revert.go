@@ -0,0 +1,86 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"autopr/internal/config"
+	"autopr/internal/db"
+	"autopr/internal/git"
+	"autopr/internal/githubapp"
+)
+
+// CanRevert reports whether job has a merged PR that can be rolled back.
+func CanRevert(job *db.Job) bool {
+	return job != nil && job.State == "approved" && job.PRMergedAt != ""
+}
+
+// MergeCommitForJob looks up the commit that merged the job's PR/MR.
+func MergeCommitForJob(ctx context.Context, cfg *config.Config, proj *config.ProjectConfig, job db.Job) (string, error) {
+	var (
+		status git.PRMergeStatus
+		err    error
+	)
+	switch {
+	case proj.GitHub != nil && strings.Contains(job.PRURL, "/pull/"):
+		token, tokenErr := githubapp.Token(ctx, cfg, proj)
+		if tokenErr != nil {
+			return "", fmt.Errorf("github token: %w", tokenErr)
+		}
+		if token == "" {
+			return "", fmt.Errorf("GITHUB_TOKEN required to look up the merge commit")
+		}
+		status, err = git.CheckGitHubPRStatus(ctx, token, proj.GitHub.BaseURL, job.PRURL)
+	case proj.GitLab != nil && strings.Contains(job.PRURL, "/merge_requests/"):
+		if cfg.Tokens.GitLab == "" {
+			return "", fmt.Errorf("GITLAB_TOKEN required to look up the merge commit")
+		}
+		status, err = git.CheckGitLabMRStatus(ctx, cfg.Tokens.GitLab, git.NormalizeGitLabBaseURL(proj.GitLab.BaseURL), job.PRURL)
+	case proj.Gitea != nil && strings.Contains(job.PRURL, "/pulls/"):
+		if cfg.Tokens.Gitea == "" {
+			return "", fmt.Errorf("GITEA_TOKEN required to look up the merge commit")
+		}
+		status, err = git.CheckGiteaPRStatus(ctx, cfg.Tokens.Gitea, proj.Gitea.BaseURL, job.PRURL)
+	default:
+		return "", fmt.Errorf("job %s has no GitHub PR, GitLab MR, or Gitea PR", db.ShortID(job.ID))
+	}
+	if err != nil {
+		return "", err
+	}
+	if !status.Merged || status.MergeCommitSHA == "" {
+		return "", fmt.Errorf("merge commit not found for %s", job.PRURL)
+	}
+	return status.MergeCommitSHA, nil
+}
+
+// CreateRevertJob queues a job that reverts commit, the merge of parent's PR,
+// on a fresh branch off the base branch. The job links back to parent and
+// opens its revert PR automatically once tests pass.
+func CreateRevertJob(ctx context.Context, store *db.Store, parent db.Job, commit string) (string, error) {
+	if !CanRevert(&parent) {
+		return "", fmt.Errorf("job %s must be approved with a merged PR to revert (state %q)", db.ShortID(parent.ID), parent.State)
+	}
+	if strings.TrimSpace(commit) == "" {
+		return "", fmt.Errorf("merge commit is required")
+	}
+	return store.CreateRevertJob(ctx, parent, commit)
+}
+
+// runRevert reverts the parent's merged commit on a fresh branch in place of
+// the plan/implement/review loop.
+func (r *Runner) runRevert(ctx context.Context, jobID string, issue db.Issue, projectCfg *config.ProjectConfig, workDir string) error {
+	job, err := r.store.GetJob(ctx, jobID)
+	if err != nil {
+		return err
+	}
+	return r.runCommitJob(ctx, job, issue, projectCfg, workDir, commitOp{
+		verb:       "revert",
+		commit:     job.RevertCommit,
+		branch:     projectCfg.BaseBranch,
+		apply:      git.Revert,
+		cont:       git.RevertContinue,
+		inProgress: git.IsRevertInProgress,
+		abort:      git.RevertAbort,
+	})
+}
@@ -0,0 +1,122 @@
+package pipeline
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"autopr/internal/config"
+	"autopr/internal/db"
+)
+
+func TestRunRevertRevertsMergedCommitOnFreshBranch(t *testing.T) {
+	t.Setenv("GIT_AUTHOR_NAME", "Test User")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "Test User")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+
+	ctx := context.Background()
+	tmp := t.TempDir()
+
+	store, err := db.Open(filepath.Join(tmp, "autopr.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer store.Close()
+
+	remote := createBareRemoteWithMain(t, tmp)
+	seed := filepath.Join(tmp, "seed")
+	if err := os.WriteFile(filepath.Join(seed, "README.md"), []byte("broken\n"), 0o644); err != nil {
+		t.Fatalf("write change: %v", err)
+	}
+	runGitCmdLocal(t, seed, "commit", "-am", "risky change")
+	runGitCmdLocal(t, seed, "push", "origin", "main")
+	mergeCommit, err := runGitCommandOutput(t, seed, "rev-parse", "HEAD")
+	if err != nil {
+		t.Fatalf("rev-parse: %v", err)
+	}
+	mergeCommit = strings.TrimSpace(mergeCommit)
+
+	cfg := &config.Config{
+		ReposRoot: filepath.Join(tmp, "repos"),
+		Projects: []config.ProjectConfig{{
+			Name:       "myproject",
+			RepoURL:    remote,
+			BaseBranch: "main",
+			TestCmd:    "true",
+		}},
+	}
+
+	issueID, err := store.UpsertIssue(ctx, db.IssueUpsert{
+		ProjectName:   "myproject",
+		Source:        "github",
+		SourceIssueID: "9",
+		Title:         "risky change",
+		URL:           "https://github.com/acme/repo/issues/9",
+		State:         "closed",
+	})
+	if err != nil {
+		t.Fatalf("upsert issue: %v", err)
+	}
+	parentID, err := store.CreateJob(ctx, issueID, "myproject", 3)
+	if err != nil {
+		t.Fatalf("create parent: %v", err)
+	}
+	parent, err := store.GetJob(ctx, parentID)
+	if err != nil {
+		t.Fatalf("get parent: %v", err)
+	}
+	if _, err := CreateRevertJob(ctx, store, parent, mergeCommit); err == nil {
+		t.Fatalf("expected unmerged job to be rejected")
+	}
+
+	if _, err := store.Writer.ExecContext(ctx, `UPDATE jobs SET state = 'approved', pr_url = ?, pr_merged_at = ? WHERE id = ?`,
+		"https://github.com/acme/repo/pull/10", "2026-01-01T00:00:00Z", parentID); err != nil {
+		t.Fatalf("approve parent: %v", err)
+	}
+	parent, err = store.GetJob(ctx, parentID)
+	if err != nil {
+		t.Fatalf("get parent: %v", err)
+	}
+	jobID, err := CreateRevertJob(ctx, store, parent, mergeCommit)
+	if err != nil {
+		t.Fatalf("create revert job: %v", err)
+	}
+	if claimed, err := store.ClaimJob(ctx); err != nil || claimed != jobID {
+		t.Fatalf("claim revert job: %q err=%v", claimed, err)
+	}
+
+	runner := New(store, nil, cfg)
+	var prTitle, prBody string
+	runner.pushBranchWithLeaseToRemote = func(ctx context.Context, dir, remoteName, branchName, token string) error { return nil }
+	runner.createPRForProjectFn = func(ctx context.Context, cfg *config.Config, proj *config.ProjectConfig, job db.Job, head, title, body string, draft bool) (string, error) {
+		prTitle, prBody = title, body
+		return "https://github.com/acme/repo/pull/11", nil
+	}
+	if err := runner.Run(ctx, jobID); err != nil {
+		t.Fatalf("run revert: %v", err)
+	}
+
+	job, err := store.GetJob(ctx, jobID)
+	if err != nil {
+		t.Fatalf("get job: %v", err)
+	}
+	if job.State != "approved" || job.PRURL != "https://github.com/acme/repo/pull/11" {
+		t.Fatalf("expected revert PR to be opened, got state %q pr %q (%s)", job.State, job.PRURL, job.ErrorMessage)
+	}
+	if job.ParentJobID != parentID || !strings.Contains(job.BranchName, "revert-risky-change") {
+		t.Fatalf("unexpected revert job link/branch: parent=%q branch=%q", job.ParentJobID, job.BranchName)
+	}
+	got, err := os.ReadFile(filepath.Join(job.WorktreePath, "README.md"))
+	if err != nil {
+		t.Fatalf("read worktree file: %v", err)
+	}
+	if string(got) != "hello\n" {
+		t.Fatalf("expected change to be reverted, got %q", got)
+	}
+	if prTitle != `[AutoPR] Revert "risky change"` || !strings.Contains(prBody, "Reverts https://github.com/acme/repo/pull/10") || strings.Contains(prBody, "Closes ") {
+		t.Fatalf("unexpected PR content %q:\n%s", prTitle, prBody)
+	}
+}
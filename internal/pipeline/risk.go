@@ -0,0 +1,150 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path"
+	"strings"
+
+	"autopr/internal/config"
+	"autopr/internal/db"
+	"autopr/internal/git"
+)
+
+// riskChurnSince is how far back file churn is counted, as a git date.
+const riskChurnSince = "90 days ago"
+
+// riskHotFileCommits is how many base branch commits within riskChurnSince
+// make a file "hot": frequently changed code is more likely to conflict with
+// or be broken by another change.
+const riskHotFileCommits = 10
+
+// scoreJobRisk rates the job's final diff for review and stores the score and
+// its factors on the job. Generated files don't count. Scoring is advisory: a
+// failure is logged and leaves the job unscored.
+func (r *Runner) scoreJobRisk(ctx context.Context, jobID string, projectCfg *config.ProjectConfig, workDir string) {
+	job, err := r.store.GetJob(ctx, jobID)
+	if err != nil {
+		slog.Warn("score job risk failed", "job", jobID, "err", err)
+		return
+	}
+	base := TargetBranch(job, projectCfg)
+	all, err := git.DiffNumstatAgainstBase(ctx, workDir, base)
+	if err != nil {
+		slog.Warn("score job risk failed", "job", jobID, "err", err)
+		return
+	}
+	var changes []git.FileChange
+	var files []string
+	for _, c := range all {
+		if projectCfg.IsGenerated(c.Path) {
+			continue
+		}
+		changes = append(changes, c)
+		files = append(files, c.Path)
+	}
+	churn, err := git.FileChurn(ctx, workDir, base, riskChurnSince, files)
+	if err != nil {
+		// Score the rest of the diff without churn history.
+		slog.Warn("count file churn failed", "job", jobID, "err", err)
+	}
+	score, summary := assessRisk(projectCfg, changes, churn)
+	if err := r.store.UpdateJobRisk(ctx, jobID, score, summary); err != nil {
+		slog.Warn("store job risk failed", "job", jobID, "err", err)
+		return
+	}
+	slog.Info("job risk scored", "job", jobID, "score", score, "level", db.RiskLevel(score), "factors", summary)
+}
+
+// assessRisk scores a diff from 0 to 100 and lists the factors that
+// contributed, e.g. "2 critical files, 420 lines in 9 files, no tests".
+// Critical paths weigh most, then size, missing tests, and hot files.
+func assessRisk(projectCfg *config.ProjectConfig, changes []git.FileChange, churn map[string]int) (int, string) {
+	var critical, lines, hot int
+	var tests, untested bool
+	for _, c := range changes {
+		lines += c.Lines
+		if projectCfg.IsCritical(c.Path) {
+			critical++
+		}
+		if churn[c.Path] >= riskHotFileCommits {
+			hot++
+		}
+		switch {
+		case isTestFile(c.Path):
+			tests = true
+		case !isDocFile(c.Path):
+			untested = true
+		}
+	}
+
+	score := min(critical*15, 40)
+	switch {
+	case lines >= 1000:
+		score += 30
+	case lines >= 400:
+		score += 20
+	case lines >= 100:
+		score += 10
+	}
+	switch {
+	case len(changes) >= 20:
+		score += 10
+	case len(changes) >= 8:
+		score += 5
+	}
+	if untested && !tests {
+		score += 20
+	}
+	score += min(hot*5, 15)
+	score = min(score, 100)
+
+	var factors []string
+	if critical > 0 {
+		factors = append(factors, plural(critical, "critical file"))
+	}
+	factors = append(factors, fmt.Sprintf("%s in %s", plural(lines, "line"), plural(len(changes), "file")))
+	if untested && !tests {
+		factors = append(factors, "no tests")
+	}
+	if hot > 0 {
+		factors = append(factors, plural(hot, "hot file"))
+	}
+	return score, strings.Join(factors, ", ")
+}
+
+// isTestFile reports whether the repository-relative file looks like a test
+// by the common naming conventions of Go, JavaScript, Python, and Ruby, or
+// lives in a test directory.
+func isTestFile(file string) bool {
+	name := path.Base(file)
+	if strings.Contains(name, "_test.") || strings.Contains(name, ".test.") || strings.Contains(name, ".spec.") ||
+		strings.HasPrefix(name, "test_") || strings.HasSuffix(strings.TrimSuffix(name, path.Ext(name)), "_spec") {
+		return true
+	}
+	for dir := range strings.SplitSeq(path.Dir(file), "/") {
+		switch dir {
+		case "test", "tests", "__tests__", "spec", "testdata":
+			return true
+		}
+	}
+	return false
+}
+
+// isDocFile reports whether the file is documentation, which a change can
+// touch without needing tests.
+func isDocFile(file string) bool {
+	switch strings.ToLower(path.Ext(file)) {
+	case ".md", ".rst", ".txt", ".adoc":
+		return true
+	}
+	return strings.HasPrefix(file, "docs/")
+}
+
+func plural(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
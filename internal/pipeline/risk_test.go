@@ -0,0 +1,106 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+
+	"autopr/internal/config"
+	"autopr/internal/db"
+	"autopr/internal/git"
+)
+
+func TestAssessRisk(t *testing.T) {
+	t.Parallel()
+
+	proj := &config.ProjectConfig{CriticalPaths: []string{"internal/auth/", "*.sql"}}
+	for _, tc := range []struct {
+		name    string
+		changes []git.FileChange
+		churn   map[string]int
+		score   int
+		summary string
+	}{
+		{
+			name:    "small tested change",
+			changes: []git.FileChange{{Path: "pkg/a.go", Lines: 12}, {Path: "pkg/a_test.go", Lines: 20}},
+			score:   0,
+			summary: "32 lines in 2 files",
+		},
+		{
+			name:    "docs only",
+			changes: []git.FileChange{{Path: "README.md", Lines: 3}},
+			score:   0,
+			summary: "3 lines in 1 file",
+		},
+		{
+			name: "critical untested hot change",
+			changes: []git.FileChange{
+				{Path: "internal/auth/session.go", Lines: 300},
+				{Path: "db/schema.sql", Lines: 150},
+			},
+			churn:   map[string]int{"internal/auth/session.go": 14, "db/schema.sql": 2},
+			score:   30 + 20 + 20 + 5,
+			summary: "2 critical files, 450 lines in 2 files, no tests, 1 hot file",
+		},
+		{
+			name: "capped",
+			changes: []git.FileChange{
+				{Path: "internal/auth/a.go", Lines: 900},
+				{Path: "internal/auth/b.go", Lines: 900},
+				{Path: "internal/auth/c.go", Lines: 900},
+			},
+			churn:   map[string]int{"internal/auth/a.go": 10, "internal/auth/b.go": 10, "internal/auth/c.go": 10},
+			score:   100,
+			summary: "3 critical files, 2700 lines in 3 files, no tests, 3 hot files",
+		},
+	} {
+		score, summary := assessRisk(proj, tc.changes, tc.churn)
+		if score != tc.score || summary != tc.summary {
+			t.Errorf("%s: got %d %q, want %d %q", tc.name, score, summary, tc.score, tc.summary)
+		}
+	}
+}
+
+func TestIsTestFile(t *testing.T) {
+	t.Parallel()
+
+	for file, want := range map[string]bool{
+		"pkg/a_test.go":             true,
+		"web/src/app.test.ts":       true,
+		"web/src/app.spec.js":       true,
+		"lib/test_parser.py":        true,
+		"spec/models/user_spec.rb":  true,
+		"pkg/testdata/input.json":   true,
+		"pkg/contest.go":            false,
+		"internal/testing/helps.go": false,
+	} {
+		if got := isTestFile(file); got != want {
+			t.Errorf("isTestFile(%q) = %v, want %v", file, got, want)
+		}
+	}
+}
+
+func TestScoreJobRiskStoresScore(t *testing.T) {
+	t.Parallel()
+
+	runner, store, _, jobID := setupRunStepsJob(t, nil, "testing")
+	ctx := context.Background()
+	remote, workDir := cloneWithChanges(t, map[string]string{
+		"auth/login.go": "package auth\n",
+	})
+
+	projectCfg := &config.ProjectConfig{
+		Name:          "project",
+		RepoURL:       remote,
+		BaseBranch:    "main",
+		CriticalPaths: []string{"auth/"},
+	}
+	runner.scoreJobRisk(ctx, jobID, projectCfg, workDir)
+	job, err := store.GetJob(ctx, jobID)
+	if err != nil {
+		t.Fatalf("get job: %v", err)
+	}
+	if job.RiskScore != 35 || job.RiskSummary != "1 critical file, 1 line in 1 file, no tests" || job.RiskLevel() != db.RiskMedium {
+		t.Fatalf("unexpected risk %d %q (%s)", job.RiskScore, job.RiskSummary, job.RiskLevel())
+	}
+}
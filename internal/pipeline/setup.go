@@ -0,0 +1,54 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"autopr/internal/config"
+	"autopr/internal/db"
+)
+
+const setupOutputArtifactKind = "setup_output"
+
+// errSetupFailed marks a job that failed because its project's setup_cmd did,
+// as opposed to its tests.
+var errSetupFailed = errors.New("setup_cmd failed")
+
+// runSetup runs the project's setup_cmd (npm ci, go mod download, ...) in a
+// new job worktree, with the project's caches, so dependencies are in place
+// before the LLM and the tests need them. The output is stored as a
+// setup_output artifact either way.
+func (r *Runner) runSetup(ctx context.Context, job db.Job, issue db.Issue, projectCfg *config.ProjectConfig, workDir string) error {
+	if projectCfg.SetupCmd == "" {
+		return nil
+	}
+	caches, err := acquireCaches(ctx, r.cfg, projectCfg, workDir)
+	if err != nil {
+		if ctx.Err() != nil {
+			return context.Canceled
+		}
+		return err
+	}
+	defer caches.release()
+
+	setupEnv, secrets := stepEnv(r.cfg, projectCfg, "setup")
+
+	slog.Info("running setup command", "job", job.ID)
+	start := time.Now()
+	output, err := runTestCommand(ctx, scopeDir(workDir, projectCfg), projectCfg.SetupCmd, append(caches.env, setupEnv...))
+	if errors.Is(err, context.Canceled) || ctx.Err() != nil {
+		return context.Canceled
+	}
+	content := fmt.Sprintf("$ %s\n%s", projectCfg.SetupCmd, maskSecrets(output, secrets))
+	if _, aerr := r.store.CreateArtifact(ctx, job.ID, issue.AutoPRIssueID, setupOutputArtifactKind, content, job.Iteration, ""); aerr != nil {
+		slog.Warn("failed to store setup_output artifact", "job", job.ID, "err", aerr)
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %v (output in the setup_output artifact)", errSetupFailed, err)
+	}
+	slog.Info("setup command completed", "job", job.ID, "elapsed", time.Since(start).Round(time.Second))
+	return nil
+}
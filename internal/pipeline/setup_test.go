@@ -0,0 +1,118 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"autopr/internal/config"
+	"autopr/internal/db"
+	"autopr/internal/git"
+)
+
+func TestRunSetupStoresOutput(t *testing.T) {
+	t.Parallel()
+
+	runner, store, issue, jobID := setupRunStepsJob(t, nil, "planning")
+	runner.cfg = &config.Config{ReposRoot: t.TempDir()}
+	ctx := context.Background()
+	job, err := store.GetJob(ctx, jobID)
+	if err != nil {
+		t.Fatalf("get job: %v", err)
+	}
+	workDir := t.TempDir()
+	script := "#!/bin/sh\necho installing\ntouch installed\n"
+	if err := os.WriteFile(filepath.Join(workDir, "setup.sh"), []byte(script), 0o755); err != nil {
+		t.Fatalf("write script: %v", err)
+	}
+	projectCfg := &config.ProjectConfig{Name: "myproject", SetupCmd: "./setup.sh"}
+
+	if err := runner.runSetup(ctx, job, issue, projectCfg, workDir); err != nil {
+		t.Fatalf("run setup: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(workDir, "installed")); err != nil {
+		t.Fatalf("setup_cmd did not run in the worktree: %v", err)
+	}
+	artifact, err := store.GetLatestArtifact(ctx, jobID, setupOutputArtifactKind)
+	if err != nil || artifact.Content != "$ ./setup.sh\ninstalling\n" {
+		t.Fatalf("setup_output = %q, %v", artifact.Content, err)
+	}
+
+	if err := runner.runSetup(ctx, job, issue, &config.ProjectConfig{Name: "myproject"}, workDir); err != nil {
+		t.Fatalf("run without setup_cmd: %v", err)
+	}
+	projectCfg.SetupCmd = "./missing.sh"
+	if err := runner.runSetup(ctx, job, issue, projectCfg, workDir); !errors.Is(err, errSetupFailed) {
+		t.Fatalf("expected errSetupFailed, got %v", err)
+	}
+}
+
+func TestRunFailsJobWhenSetupFails(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	tmp := t.TempDir()
+	store, err := db.Open(filepath.Join(tmp, "autopr.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+
+	issueID, err := store.UpsertIssue(ctx, db.IssueUpsert{
+		ProjectName:   "myproject",
+		Source:        "github",
+		SourceIssueID: "90",
+		Title:         "setup fails",
+		URL:           "https://github.com/org/repo/issues/90",
+		State:         "open",
+	})
+	if err != nil {
+		t.Fatalf("upsert issue: %v", err)
+	}
+	jobID, err := store.CreateJob(ctx, issueID, "myproject", 3)
+	if err != nil {
+		t.Fatalf("create job: %v", err)
+	}
+	if claimed, err := store.ClaimJob(ctx); err != nil || claimed != jobID {
+		t.Fatalf("claim job: claimed=%q err=%v", claimed, err)
+	}
+
+	cfg := &config.Config{
+		ReposRoot: filepath.Join(tmp, "repos"),
+		LLM:       config.LLMConfig{Provider: "codex"},
+		Projects: []config.ProjectConfig{{
+			Name:       "myproject",
+			RepoURL:    "https://github.com/org/repo.git",
+			BaseBranch: "main",
+			TestCmd:    "echo ok",
+			SetupCmd:   "./setup.sh",
+			GitHub:     &config.ProjectGitHub{Owner: "org", Repo: "repo"},
+		}},
+	}
+	runner := New(store, &neverCalledProvider{}, cfg)
+	runner.acquireIssueLock = nil
+	runner.cloneForJob = func(ctx context.Context, repoURL, token, destPath, branchName, baseBranch string, _ git.RemoteAuth) error {
+		if err := os.MkdirAll(destPath, 0o755); err != nil {
+			return err
+		}
+		return os.WriteFile(filepath.Join(destPath, "setup.sh"), []byte("#!/bin/sh\necho 'npm ERR! missing lockfile'\nexit 1\n"), 0o755)
+	}
+
+	if err := runner.Run(ctx, jobID); err == nil {
+		t.Fatal("expected the run to fail")
+	}
+	job, err := store.GetJob(ctx, jobID)
+	if err != nil {
+		t.Fatalf("get job: %v", err)
+	}
+	if job.State != "failed" || !strings.HasPrefix(job.ErrorMessage, "setup_cmd failed: exit status 1") {
+		t.Fatalf("job state=%q error=%q, want failed by setup_cmd", job.State, job.ErrorMessage)
+	}
+	artifact, err := store.GetLatestArtifact(ctx, jobID, setupOutputArtifactKind)
+	if err != nil || !strings.Contains(artifact.Content, "npm ERR! missing lockfile") {
+		t.Fatalf("setup_output = %q, %v", artifact.Content, err)
+	}
+}
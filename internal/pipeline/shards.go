@@ -0,0 +1,119 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"autopr/internal/config"
+	"autopr/internal/stack"
+)
+
+// shardResult is the outcome of one test shard.
+type shardResult struct {
+	cmd     string
+	output  string
+	err     error
+	elapsed time.Duration
+}
+
+// runProjectTests runs the project's tests in dir: test_cmd, or every test
+// shard at once when test_shards is set. Shard outputs are joined in config
+// order under a header per shard, and the run fails if any shard fails. Once
+// the tests pass, lint_cmd runs as a further gate. test_cmd or lint_cmd
+// "auto" runs the command detected in dir. env is added to each command's
+// environment.
+func runProjectTests(ctx context.Context, dir string, projectCfg *config.ProjectConfig, env []string) (string, error) {
+	var detected *stack.Stack
+	resolve := func(cmd string, pick func(stack.Stack) string) string {
+		if cmd != config.AutoDetect {
+			return cmd
+		}
+		if detected == nil {
+			s := stack.DetectDir(dir)
+			detected = &s
+		}
+		return pick(*detected)
+	}
+
+	output, err := runTestCommands(ctx, dir, projectCfg, resolve(projectCfg.TestCmd, func(s stack.Stack) string { return s.TestCmd }), env)
+	if err != nil || projectCfg.LintCmd == "" {
+		return output, err
+	}
+	lint := resolve(projectCfg.LintCmd, func(s stack.Stack) string { return s.LintCmd })
+	if lint == "" {
+		return output + "\n=== lint: no lint command detected, skipped\n", nil
+	}
+	lintOutput, lintErr := runTestCommand(ctx, dir, lint, env)
+	output += fmt.Sprintf("\n=== lint: %s\n%s", lint, lintOutput)
+	if lintErr != nil {
+		if ctx.Err() != nil {
+			return output, context.Canceled
+		}
+		return output, fmt.Errorf("lint (%s): %w", lint, lintErr)
+	}
+	return output, nil
+}
+
+// runTestCommands runs testCmd, the project's test_cmd as resolved, or its
+// test shards.
+func runTestCommands(ctx context.Context, dir string, projectCfg *config.ProjectConfig, testCmd string, env []string) (string, error) {
+	if len(projectCfg.TestShards) == 0 {
+		if testCmd == "" && projectCfg.TestCmd == config.AutoDetect {
+			err := fmt.Errorf("test_cmd is %q but no test command was detected", config.AutoDetect)
+			return err.Error(), err
+		}
+		return runTestCommand(ctx, dir, testCmd, env)
+	}
+	cmds := projectCfg.TestShards
+
+	results := make([]shardResult, len(cmds))
+	var wg sync.WaitGroup
+	for i, cmd := range cmds {
+		wg.Go(func() {
+			start := time.Now()
+			output, err := runTestCommand(ctx, dir, cmd, env)
+			results[i] = shardResult{cmd: cmd, output: output, err: err, elapsed: time.Since(start)}
+		})
+	}
+	wg.Wait()
+	return joinShardResults(ctx, results)
+}
+
+// joinShardResults combines shard results into one output and error. The
+// error names the first failing shard and how many failed.
+func joinShardResults(ctx context.Context, results []shardResult) (string, error) {
+	var b strings.Builder
+	var firstErr error
+	failed := 0
+	for i, r := range results {
+		status := "passed"
+		if r.err != nil {
+			status = "failed: " + r.err.Error()
+			if failed == 0 {
+				firstErr = fmt.Errorf("test shard %d (%s): %w", i+1, r.cmd, r.err)
+			}
+			failed++
+		}
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "=== shard %d/%d: %s (%s, %s)\n", i+1, len(results), r.cmd, status, r.elapsed.Round(time.Second))
+		b.WriteString(r.output)
+		if r.output != "" && !strings.HasSuffix(r.output, "\n") {
+			b.WriteString("\n")
+		}
+	}
+	if failed == 0 {
+		return b.String(), nil
+	}
+	if ctx.Err() != nil {
+		return b.String(), context.Canceled
+	}
+	if failed > 1 {
+		firstErr = fmt.Errorf("%d of %d test shards failed, first %w", failed, len(results), firstErr)
+	}
+	return b.String(), firstErr
+}
@@ -0,0 +1,114 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"autopr/internal/config"
+)
+
+func writeShardScript(t *testing.T, dir, name, body string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"+body), 0o755); err != nil {
+		t.Fatalf("write %s: %v", name, err)
+	}
+}
+
+func TestRunProjectTestsRunsShardsConcurrently(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	// Each shard waits for the other to start, so they only pass when run
+	// at the same time.
+	wait := func(self, other string) string {
+		return "touch " + self + "\ni=0\nwhile [ ! -f " + other + " ]; do\n  i=$((i+1))\n  [ $i -gt 100 ] && { echo 'timed out'; exit 1; }\n  sleep 0.05\ndone\necho " + self + " done\n"
+	}
+	writeShardScript(t, dir, "a.sh", wait("a.started", "b.started"))
+	writeShardScript(t, dir, "b.sh", wait("b.started", "a.started"))
+	writeShardScript(t, dir, "fail.sh", "echo '--- FAIL: TestShard (0.00s)'\nexit 1\n")
+
+	proj := &config.ProjectConfig{TestCmd: "./fail.sh", TestShards: []string{"./a.sh", "./b.sh"}}
+	output, err := runProjectTests(context.Background(), dir, proj, nil)
+	if err != nil {
+		t.Fatalf("run shards: %v\n%s", err, output)
+	}
+	for _, want := range []string{"=== shard 1/2: ./a.sh (passed", "a.started done", "=== shard 2/2: ./b.sh (passed", "b.started done"} {
+		if !strings.Contains(output, want) {
+			t.Fatalf("output missing %q:\n%s", want, output)
+		}
+	}
+	if strings.Index(output, "shard 1/2") > strings.Index(output, "shard 2/2") {
+		t.Fatalf("shard outputs out of order:\n%s", output)
+	}
+
+	proj.TestShards = []string{"./a.sh", "./fail.sh"}
+	output, err = runProjectTests(context.Background(), dir, proj, nil)
+	if err == nil || !strings.Contains(err.Error(), "test shard 2 (./fail.sh)") {
+		t.Fatalf("expected shard 2 failure, got %v", err)
+	}
+	if !strings.Contains(output, "=== shard 2/2: ./fail.sh (failed: exit status 1") || !strings.Contains(output, "--- FAIL: TestShard") {
+		t.Fatalf("unexpected output:\n%s", output)
+	}
+
+	proj.TestShards = nil
+	if _, err := runProjectTests(context.Background(), dir, proj, nil); err == nil {
+		t.Fatal("expected test_cmd to run without shards")
+	}
+}
+
+func TestJoinShardResultsCountsFailures(t *testing.T) {
+	t.Parallel()
+
+	results := []shardResult{
+		{cmd: "make unit", output: "ok\n"},
+		{cmd: "make e2e", output: "boom", err: errors.New("exit status 2")},
+		{cmd: "make lint", err: errors.New("exit status 1")},
+	}
+	output, err := joinShardResults(context.Background(), results)
+	if err == nil || err.Error() != "2 of 3 test shards failed, first test shard 2 (make e2e): exit status 2" {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if !strings.Contains(output, "boom\n\n=== shard 3/3: make lint (failed: exit status 1, 0s)\n") {
+		t.Fatalf("unexpected output:\n%s", output)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := joinShardResults(ctx, results); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestRunProjectTestsResolvesAutoAndRunsLint(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	makefile := "test:\n\t@echo tests ran\nlint:\n\t@echo lint ran\n"
+	if err := os.WriteFile(filepath.Join(dir, "Makefile"), []byte(makefile), 0o644); err != nil {
+		t.Fatalf("write Makefile: %v", err)
+	}
+	proj := &config.ProjectConfig{TestCmd: config.AutoDetect, LintCmd: config.AutoDetect}
+	output, err := runProjectTests(context.Background(), dir, proj, nil)
+	if err != nil {
+		t.Fatalf("run tests: %v\n%s", err, output)
+	}
+	if !strings.Contains(output, "tests ran") || !strings.Contains(output, "=== lint: make lint\nlint ran") {
+		t.Fatalf("unexpected output:\n%s", output)
+	}
+
+	writeShardScript(t, dir, "lint.sh", "echo 'unused variable x'\nexit 1\n")
+	proj.LintCmd = "./lint.sh"
+	output, err = runProjectTests(context.Background(), dir, proj, nil)
+	if err == nil || !strings.Contains(err.Error(), "lint (./lint.sh)") || !strings.Contains(output, "unused variable x") {
+		t.Fatalf("expected the lint failure, got %v:\n%s", err, output)
+	}
+
+	empty := t.TempDir()
+	if _, err := runProjectTests(context.Background(), empty, proj, nil); err == nil || !strings.Contains(err.Error(), "no test command was detected") {
+		t.Fatalf("expected an error when nothing is detected, got %v", err)
+	}
+}
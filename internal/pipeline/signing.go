@@ -0,0 +1,109 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"autopr/internal/config"
+	"autopr/internal/db"
+	"autopr/internal/git"
+)
+
+// SetupSigning configures a new job clone to sign its commits when the
+// project sets [projects.signing]. Key material from credentials.toml is
+// written under <repos_root>/signing/<project>, outside the job clones, and
+// only the user can read it.
+func SetupSigning(ctx context.Context, cfg *config.Config, proj *config.ProjectConfig, dir string) error {
+	if proj.Signing == nil {
+		return nil
+	}
+	key, secret := cfg.SigningKey(proj)
+	keyDir := filepath.Join(cfg.ReposRoot, "signing", proj.Name)
+	signing := git.CommitSigning{Format: proj.Signing.Format, Key: key}
+	switch signing.Format {
+	case "ssh":
+		if secret {
+			signing.Key = filepath.Join(keyDir, "ssh_key")
+			if err := writePrivateFile(signing.Key, strings.TrimSpace(key)+"\n"); err != nil {
+				return fmt.Errorf("write signing key: %w", err)
+			}
+		}
+		// git checks SSH signatures against an allowed signers file; list
+		// the signing key so the status recorded on the job means something.
+		pub, err := git.SSHPublicKey(ctx, signing.Key)
+		if err != nil {
+			return err
+		}
+		signing.AllowedSignersFile = filepath.Join(keyDir, "allowed_signers")
+		if err := writePrivateFile(signing.AllowedSignersFile, "* "+pub+"\n"); err != nil {
+			return fmt.Errorf("write allowed signers: %w", err)
+		}
+	case "gpg":
+		if secret {
+			home := filepath.Join(keyDir, "gnupg")
+			fingerprint, err := git.ImportGPGKey(ctx, home, key)
+			if err != nil {
+				return err
+			}
+			program, err := git.WriteGPGProgram(home)
+			if err != nil {
+				return fmt.Errorf("write gpg program: %w", err)
+			}
+			signing.Key, signing.Program = fingerprint, program
+		}
+	}
+	return git.ConfigureSigning(ctx, dir, signing)
+}
+
+// writePrivateFile replaces path with content, readable only by the user.
+// Jobs of one project set up signing concurrently, so the file is written
+// aside and renamed into place.
+func writePrivateFile(path, content string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	if _, err := f.WriteString(content); err != nil {
+		_ = f.Close()
+		_ = os.Remove(tmp)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// recordSignatureStatus saves on the job whether its commits on top of
+// origin/<baseBranch> carry good signatures. Clones that do not sign commits
+// record nothing. An unsigned commit does not stop the job; the forge decides
+// whether to accept it.
+func recordSignatureStatus(ctx context.Context, store *db.Store, jobID, workDir, baseBranch string) {
+	status, err := git.SignatureStatus(ctx, workDir, "origin/"+baseBranch)
+	if err != nil {
+		slog.Warn("check commit signatures", "job", jobID, "err", err)
+		return
+	}
+	if status == "" {
+		return
+	}
+	if !strings.HasPrefix(status, "verified") {
+		slog.Warn("job commits are not all signed", "job", jobID, "status", status)
+	}
+	if err := store.UpdateJobField(ctx, jobID, "signature_status", status); err != nil {
+		slog.Warn("save signature status", "job", jobID, "err", err)
+	}
+}
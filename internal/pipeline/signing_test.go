@@ -0,0 +1,101 @@
+package pipeline
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"autopr/internal/config"
+	"autopr/internal/db"
+)
+
+func TestSetupSigningSignsJobCommitsWithSecretSSHKey(t *testing.T) {
+	if _, err := exec.LookPath("ssh-keygen"); err != nil {
+		t.Skip("ssh-keygen not installed")
+	}
+	t.Setenv("GIT_AUTHOR_NAME", "Test User")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "Test User")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+
+	ctx := context.Background()
+	tmp := t.TempDir()
+	keyPath := filepath.Join(tmp, "id_ed25519")
+	if out, err := exec.Command("ssh-keygen", "-q", "-t", "ed25519", "-N", "", "-C", "autopr", "-f", keyPath).CombinedOutput(); err != nil {
+		t.Fatalf("ssh-keygen: %v\n%s", err, out)
+	}
+	key, err := os.ReadFile(keyPath)
+	if err != nil {
+		t.Fatalf("read key: %v", err)
+	}
+
+	remote := createBareRemoteWithMain(t, tmp)
+	worktree := filepath.Join(tmp, "worktree")
+	runGitCmdLocal(t, "", "clone", "-b", "main", remote, worktree)
+
+	cfg := &config.Config{
+		ReposRoot: filepath.Join(tmp, "repos"),
+		Secrets:   map[string]string{"signing_key": string(key)},
+		Projects: []config.ProjectConfig{{
+			Name:    "myproject",
+			Signing: &config.ProjectSigning{Format: "ssh", Key: "secret:signing_key"},
+		}},
+	}
+	if err := SetupSigning(ctx, cfg, &cfg.Projects[0], worktree); err != nil {
+		t.Fatalf("setup signing: %v", err)
+	}
+	written := filepath.Join(cfg.ReposRoot, "signing", "myproject", "ssh_key")
+	if info, err := os.Stat(written); err != nil || info.Mode().Perm() != 0o600 {
+		t.Fatalf("expected key written with mode 0600, got %v, %v", info, err)
+	}
+
+	store, err := db.Open(filepath.Join(tmp, "autopr.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer store.Close()
+	issueID, err := store.UpsertIssue(ctx, db.IssueUpsert{
+		ProjectName:   "myproject",
+		Source:        "github",
+		SourceIssueID: "4",
+		Title:         "signed fix",
+		URL:           "https://github.com/acme/repo/issues/4",
+		State:         "open",
+	})
+	if err != nil {
+		t.Fatalf("upsert issue: %v", err)
+	}
+	jobID, err := store.CreateJob(ctx, issueID, "myproject", 3)
+	if err != nil {
+		t.Fatalf("create job: %v", err)
+	}
+
+	commit := func(name string, args ...string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(worktree, name), []byte(name+"\n"), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+		runGitCmdLocal(t, worktree, "add", name)
+		runGitCmdLocal(t, worktree, append(args, "commit", "-m", name)...)
+	}
+	statusOf := func() string {
+		t.Helper()
+		recordSignatureStatus(ctx, store, jobID, worktree, "main")
+		job, err := store.GetJob(ctx, jobID)
+		if err != nil {
+			t.Fatalf("get job: %v", err)
+		}
+		return job.SignatureStatus
+	}
+
+	commit("fix.txt")
+	if got := statusOf(); got != "verified (1 commit)" {
+		t.Fatalf("signature status = %q, want verified", got)
+	}
+	commit("unsigned.txt", "-c", "commit.gpgsign=false")
+	if got := statusOf(); got != "not verified: 1 unsigned of 2 commits" {
+		t.Fatalf("signature status = %q", got)
+	}
+}
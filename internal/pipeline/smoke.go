@@ -0,0 +1,93 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"autopr/internal/config"
+	"autopr/internal/git"
+)
+
+// SmokeStep is one check of a smoke run.
+type SmokeStep struct {
+	Name   string `json:"name"`
+	OK     bool   `json:"ok"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// smokeBranch is the throwaway branch a smoke run checks out.
+const smokeBranch = "autopr/smoke-test"
+
+// maxSmokeOutput caps the test output kept in a failed step's detail.
+const maxSmokeOutput = 2000
+
+// SmokeTest dry-runs the parts of a job that need no LLM session: it checks
+// that the provider CLI is installed, clones the project onto a job branch the
+// way a worker does, and runs test_cmd (or test_shards, with the project's
+// caches) on the unchanged base branch. Nothing is pushed, no job is
+// recorded, and the clone is removed afterwards. It stops at the first
+// failing step.
+func SmokeTest(ctx context.Context, cfg *config.Config, proj *config.ProjectConfig) []SmokeStep {
+	var steps []SmokeStep
+	add := func(name string, err error, detail string) bool {
+		step := SmokeStep{Name: name, OK: err == nil, Detail: detail}
+		if err != nil {
+			step.Detail = err.Error()
+			if detail != "" {
+				step.Detail += "\n" + detail
+			}
+		}
+		steps = append(steps, step)
+		return err == nil
+	}
+
+	path, err := exec.LookPath(cfg.LLM.Provider)
+	if err != nil {
+		err = fmt.Errorf("%s CLI not found in PATH", cfg.LLM.Provider)
+	}
+	if !add("llm provider", err, path) {
+		return steps
+	}
+
+	tmp, err := os.MkdirTemp("", "autopr-smoke-")
+	if err != nil {
+		add("clone", fmt.Errorf("create temp dir: %w", err), "")
+		return steps
+	}
+	defer os.RemoveAll(tmp)
+	dir := filepath.Join(tmp, "repo")
+
+	token := GitTokenForProject(ctx, cfg, proj)
+	err = git.CloneForJobWithAuth(ctx, proj.RepoURL, token, dir, smokeBranch, proj.BaseBranch, RemoteAuthForProject(proj))
+	if !add("clone", err, fmt.Sprintf("%s @ %s", proj.RepoURL, proj.BaseBranch)) {
+		return steps
+	}
+
+	caches, err := acquireCaches(ctx, cfg, proj, dir)
+	if err != nil {
+		add("caches", err, "")
+		return steps
+	}
+	defer caches.release()
+	testEnv, secrets := stepEnv(cfg, proj, "tests")
+	out, err := runProjectTests(ctx, scopeDir(dir, proj), proj, append(caches.env, testEnv...))
+	out = maskSecrets(out, secrets)
+	detail := strings.Join(proj.TestCommands(), ", ")
+	if err != nil {
+		detail = tailOutput(out, maxSmokeOutput)
+	}
+	add("tests", err, detail)
+	return steps
+}
+
+func tailOutput(s string, n int) string {
+	s = strings.TrimSpace(s)
+	if len(s) <= n {
+		return s
+	}
+	return "..." + s[len(s)-n:]
+}
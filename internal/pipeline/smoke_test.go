@@ -0,0 +1,42 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+
+	"autopr/internal/config"
+)
+
+func TestSmokeTestClonesAndRunsTests(t *testing.T) {
+	t.Parallel()
+
+	remote := createBareRemoteWithMain(t, t.TempDir())
+	cfg := &config.Config{LLM: config.LLMConfig{Provider: "git"}}
+	proj := &config.ProjectConfig{Name: "p", RepoURL: remote, BaseBranch: "main", TestCmd: "git status"}
+
+	steps := SmokeTest(context.Background(), cfg, proj)
+	if len(steps) != 3 {
+		t.Fatalf("expected 3 steps, got %+v", steps)
+	}
+	for _, s := range steps {
+		if !s.OK {
+			t.Fatalf("step %s failed: %s", s.Name, s.Detail)
+		}
+	}
+
+	proj.TestCmd = "git rev-parse --verify refs/heads/does-not-exist"
+	steps = SmokeTest(context.Background(), cfg, proj)
+	if last := steps[len(steps)-1]; last.Name != "tests" || last.OK {
+		t.Fatalf("expected failing tests step, got %+v", steps)
+	}
+}
+
+func TestSmokeTestStopsWhenProviderMissing(t *testing.T) {
+	t.Parallel()
+
+	cfg := &config.Config{LLM: config.LLMConfig{Provider: "autopr-no-such-cli"}}
+	steps := SmokeTest(context.Background(), cfg, &config.ProjectConfig{Name: "p"})
+	if len(steps) != 1 || steps[0].OK {
+		t.Fatalf("expected single failed provider step, got %+v", steps)
+	}
+}
@@ -0,0 +1,98 @@
+package pipeline
+
+import (
+	"context"
+	"log/slog"
+	"path/filepath"
+	"time"
+
+	"autopr/internal/config"
+	"autopr/internal/db"
+	"autopr/internal/git"
+	"autopr/internal/stack"
+)
+
+const (
+	// StackRefreshInterval is how long a project's detected stack is kept
+	// before sync detects it again.
+	StackRefreshInterval = 24 * time.Hour
+	// StackRetryInterval is how long sync waits to retry a failed detection.
+	StackRetryInterval = time.Hour
+)
+
+// DetectProjectStack detects the stack of the project's base branch, under
+// its scope path in a monorepo, and records it, or the error, in store.
+func DetectProjectStack(ctx context.Context, cfg *config.Config, store *db.Store, proj *config.ProjectConfig) (stack.Stack, error) {
+	var detected stack.Stack
+	dir := filepath.Join(cfg.ReposRoot, "stack", proj.Name)
+	err := git.InspectTree(ctx, proj.RepoURL, GitTokenForProject(ctx, cfg, proj), dir, proj.BaseBranch, proj.ScopePath(), RemoteAuthForProject(proj),
+		func(paths []string, read func(string) ([]byte, error)) error {
+			detected = stack.Detect(paths, read)
+			return nil
+		})
+	if err != nil {
+		if ctx.Err() == nil {
+			if recErr := store.RecordProjectStackError(ctx, proj.Name, err); recErr != nil {
+				slog.Warn("record project stack error", "project", proj.Name, "err", recErr)
+			}
+		}
+		return stack.Stack{}, err
+	}
+	err = store.SetProjectStack(ctx, db.ProjectStack{
+		ProjectName: proj.Name,
+		Languages:   detected.Languages,
+		Frameworks:  detected.Frameworks,
+		TestCmd:     detected.TestCmd,
+		LintCmd:     detected.LintCmd,
+	})
+	return detected, err
+}
+
+// SyncProjectStack detects the project's stack when it was never detected,
+// or StackRefreshInterval after the last detection (StackRetryInterval after
+// a failure). Local projects, without a repo_url, are skipped.
+func SyncProjectStack(ctx context.Context, cfg *config.Config, store *db.Store, proj *config.ProjectConfig) {
+	if proj.RepoURL == "" {
+		return
+	}
+	prev, ok, err := store.GetProjectStack(ctx, proj.Name)
+	if err != nil {
+		slog.Warn("get project stack", "project", proj.Name, "err", err)
+		return
+	}
+	if ok {
+		wait := StackRefreshInterval
+		if prev.Error != "" {
+			wait = StackRetryInterval
+		}
+		if checked, err := time.Parse(time.RFC3339, prev.CheckedAt); err == nil && time.Since(checked) < wait {
+			return
+		}
+	}
+	if _, err := DetectProjectStack(ctx, cfg, store, proj); err != nil && ctx.Err() == nil {
+		slog.Warn("detect project stack", "project", proj.Name, "err", err)
+	}
+}
+
+// projectStack returns the project's stack as last detected by sync, or,
+// when sync has not detected it yet, as detected in the job's checkout.
+func (r *Runner) projectStack(ctx context.Context, proj *config.ProjectConfig, workDir string) stack.Stack {
+	ps, ok, err := r.store.GetProjectStack(ctx, proj.Name)
+	if err != nil {
+		slog.Warn("get project stack", "project", proj.Name, "err", err)
+	}
+	if ok && ps.DetectedAt != "" {
+		return stack.Stack{Languages: ps.Languages, Frameworks: ps.Frameworks, TestCmd: ps.TestCmd, LintCmd: ps.LintCmd}
+	}
+	return stack.DetectDir(scopeDir(workDir, proj))
+}
+
+// withStackGuidance adds the project's languages, frameworks, and advice
+// for them to the plan and implement prompts.
+func (r *Runner) withStackGuidance(ctx context.Context, prompt string, proj *config.ProjectConfig, workDir string) string {
+	guidance := stack.Guidance(r.projectStack(ctx, proj, workDir))
+	if guidance == "" {
+		return prompt
+	}
+	return prompt + "\n\n" + guidance
+}
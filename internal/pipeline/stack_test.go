@@ -0,0 +1,82 @@
+package pipeline
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"autopr/internal/config"
+	"autopr/internal/db"
+	"autopr/internal/llm"
+)
+
+func TestSyncProjectStackDetectsTheBaseBranch(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	remote, workDir := cloneWithChanges(t, map[string]string{
+		"services/api/go.mod":  "module api\n\nrequire github.com/go-chi/chi/v5 v5.0.0\n",
+		"services/api/main.go": "package main\n",
+		"web/app.ts":           "export {}\n",
+	})
+	runGitCmdLocal(t, workDir, "add", "-A")
+	runGitCmdLocal(t, workDir, "-c", "user.email=test@example.com", "-c", "user.name=Test User", "commit", "-m", "add api")
+	runGitCmdLocal(t, workDir, "push", "origin", "main")
+
+	store, err := db.Open(filepath.Join(t.TempDir(), "autopr.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer store.Close()
+	cfg := &config.Config{ReposRoot: t.TempDir()}
+	proj := &config.ProjectConfig{Name: "api", RepoURL: remote, BaseBranch: "main", Scope: &config.ProjectScope{Path: "services/api"}}
+
+	SyncProjectStack(ctx, cfg, store, proj)
+	got, ok, err := store.GetProjectStack(ctx, "api")
+	if err != nil || !ok {
+		t.Fatalf("get project stack: %v, %v", ok, err)
+	}
+	if !slices.Equal(got.Languages, []string{"Go"}) || !slices.Equal(got.Frameworks, []string{"chi"}) || got.TestCmd != "go test ./..." || got.LintCmd != "go vet ./..." {
+		t.Fatalf("unexpected stack %+v", got)
+	}
+
+	// Detected recently: sync leaves it alone even though the remote is gone.
+	proj.RepoURL = filepath.Join(t.TempDir(), "missing.git")
+	SyncProjectStack(ctx, cfg, store, proj)
+	if again, _, _ := store.GetProjectStack(ctx, "api"); again.Error != "" || again.CheckedAt != got.CheckedAt {
+		t.Fatalf("expected no detection before the refresh interval, got %+v", again)
+	}
+
+	if _, err := DetectProjectStack(ctx, cfg, store, proj); err == nil {
+		t.Fatal("expected detection against a missing remote to fail")
+	}
+	failed, _, _ := store.GetProjectStack(ctx, "api")
+	if failed.Error == "" || !slices.Equal(failed.Languages, []string{"Go"}) {
+		t.Fatalf("expected the error recorded and the earlier stack kept, got %+v", failed)
+	}
+}
+
+func TestRunPlanAddsStackGuidance(t *testing.T) {
+	t.Parallel()
+
+	var gotPrompt string
+	provider := stubProvider{run: func(ctx context.Context, workDir, prompt string) (llm.Response, error) {
+		gotPrompt = prompt
+		return llm.Response{Text: "1. Fix it"}, nil
+	}}
+	runner, store, issue, jobID := setupRunStepsJob(t, provider, "planning")
+	ctx := context.Background()
+	if err := store.SetProjectStack(ctx, db.ProjectStack{ProjectName: issue.ProjectName, Languages: []string{"Go"}, Frameworks: []string{"Cobra"}}); err != nil {
+		t.Fatalf("set project stack: %v", err)
+	}
+	proj := &config.ProjectConfig{Name: issue.ProjectName}
+
+	if err := runner.runPlan(ctx, jobID, issue, proj, t.TempDir()); err != nil {
+		t.Fatalf("run plan: %v", err)
+	}
+	if !strings.Contains(gotPrompt, "<project_stack>\nLanguages: Go\nFrameworks: Cobra\n") || !strings.Contains(gotPrompt, "gofmt") {
+		t.Fatalf("prompt missing the stack guidance:\n%s", gotPrompt)
+	}
+}
@@ -5,11 +5,11 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
-	"os/exec"
 	"strings"
 
 	"autopr/internal/config"
 	"autopr/internal/db"
+	"autopr/internal/executor"
 	"autopr/internal/git"
 )
 
@@ -23,6 +23,10 @@ Title: {{title}}
 {{body}}
 </issue>
 
+{{comments}}
+
+{{attachments}}
+
 {{human_notes}}
 
 Create a step-by-step implementation plan that includes:
@@ -94,19 +98,36 @@ func (r *Runner) runPlan(ctx context.Context, jobID string, issue db.Issue, proj
 		humanNotes = fmt.Sprintf("<human_notes>\n%s\n</human_notes>", job.HumanNotes)
 	}
 
+	// Comments are synced first: attachments may be linked from them.
+	comments := r.issueDiscussion(ctx, issue, projectCfg)
+	ctx, attached := r.issueAttachments(ctx, issue, projectCfg, workDir)
 	prompt := BuildPrompt(template, map[string]string{
 		"title":       issue.Title,
 		"body":        SanitizeIssueContent(issue.Body),
+		"comments":    comments,
+		"attachments": attached,
 		"human_notes": humanNotes,
 	})
+	ctx, prompt = withRepoContext(ctx, prompt, loadRepoContext(workDir, projectCfg))
+	prompt = r.withStackGuidance(ctx, prompt, projectCfg, workDir)
+	prompt = withDecisionsRequest(r.withLessons(ctx, withPathRules(prompt, projectCfg), projectCfg, issue))
+	learnFromNotes := projectCfg.Lessons != nil && job.HumanNotes != ""
+	if learnFromNotes {
+		prompt += feedbackLessonsRequest
+	}
 
 	resp, err := r.invokeProvider(ctx, jobID, "plan", job.Iteration, workDir, prompt)
 	if err != nil {
 		return fmt.Errorf("plan step: %w", err)
 	}
+	lessons, text := extractLessons(resp.Text)
+	if learnFromNotes {
+		r.recordLessons(ctx, job, db.LessonSourceFeedback, lessons)
+	}
+	plan := r.recordDecisions(ctx, job, issue, "plan", text)
 
 	// Store the plan as an artifact.
-	_, err = r.store.CreateArtifact(ctx, jobID, issue.AutoPRIssueID, "plan", resp.Text, job.Iteration, "")
+	_, err = r.store.CreateArtifact(ctx, jobID, issue.AutoPRIssueID, "plan", plan, job.Iteration, "")
 	if err != nil {
 		return fmt.Errorf("store plan artifact: %w", err)
 	}
@@ -152,11 +173,15 @@ func (r *Runner) runImplement(ctx context.Context, jobID string, issue db.Issue,
 		"plan":            planArtifact.Content,
 		"review_feedback": reviewFeedback,
 	})
+	ctx, prompt = withRepoContext(ctx, prompt, loadRepoContext(workDir, projectCfg))
+	prompt = r.withStackGuidance(ctx, prompt, projectCfg, workDir)
+	prompt = withDecisionsRequest(r.withLessons(ctx, withGeneratedRules(withPathRules(prompt, projectCfg), projectCfg), projectCfg, issue))
 
-	_, err = r.invokeProvider(ctx, jobID, "implement", job.Iteration, workDir, prompt)
+	resp, err := r.invokeProvider(ctx, jobID, "implement", job.Iteration, workDir, prompt)
 	if err != nil {
 		return fmt.Errorf("implement step: %w", err)
 	}
+	r.recordDecisions(ctx, job, issue, "implement", resp.Text)
 
 	// Safety-net commit: some LLM providers leave changes uncommitted.
 	sha, commitErr := git.CommitAll(ctx, workDir, "autopr: implement changes for "+issue.Title)
@@ -165,6 +190,9 @@ func (r *Runner) runImplement(ctx context.Context, jobID string, issue db.Issue,
 	} else {
 		slog.Info("safety-net commit created", "job", jobID, "sha", sha)
 		_ = r.store.UpdateJobField(ctx, jobID, "commit_sha", sha)
+		if err := r.store.SetLatestSessionCommit(ctx, jobID, "implement", sha); err != nil {
+			slog.Warn("failed to record safety-net commit on session", "job", jobID, "err", err)
+		}
 	}
 
 	slog.Info("implement step completed", "job", jobID)
@@ -194,20 +222,48 @@ func (r *Runner) runCodeReview(ctx context.Context, jobID string, issue db.Issue
 		"body":  SanitizeIssueContent(issue.Body),
 		"plan":  planArtifact.Content,
 	})
+	prompt = withDecisionsRequest(r.withLessons(ctx, withGeneratedRules(withPathRules(prompt, projectCfg), projectCfg), projectCfg, issue))
+	if projectCfg.Lessons != nil {
+		prompt += reviewLessonsRequest
+	}
+
+	// A diff too large to review at once is reviewed in parts first; this
+	// session then merges their findings into one review.
+	chunks, paths, err := reviewChunks(ctx, projectCfg, job, workDir)
+	if err != nil {
+		return fmt.Errorf("split diff for review: %w", err)
+	}
+	if len(chunks) > 0 {
+		findings, err := r.reviewInChunks(ctx, job, issue, planArtifact.Content, chunks, paths, workDir)
+		if err != nil {
+			return fmt.Errorf("code review step: %w", err)
+		}
+		prompt += findings
+	}
 
 	resp, err := r.invokeProvider(ctx, jobID, "code_review", job.Iteration, workDir, prompt)
 	if err != nil {
 		return fmt.Errorf("code review step: %w", err)
 	}
+	lessons, text := extractLessons(resp.Text)
+	review := r.recordDecisions(ctx, job, issue, "code_review", text)
+	if len(chunks) > 0 {
+		review = strings.TrimRight(review, "\n") + fmt.Sprintf("\n\n_Reviewed in %d parts; each part's findings are in its review_chunk session._", len(chunks))
+	}
+	if projectCfg.Lessons != nil && !isApproved(review) {
+		r.recordLessons(ctx, job, db.LessonSourceReview, lessons)
+	}
 
-	// Store the review as an artifact.
-	_, err = r.store.CreateArtifact(ctx, jobID, issue.AutoPRIssueID, "code_review", resp.Text, job.Iteration, "")
+	// Store the review as an artifact, pinned to the reviewed HEAD so
+	// iterations can be compared later.
+	reviewedSHA, _ := git.LatestCommit(ctx, workDir)
+	_, err = r.store.CreateArtifact(ctx, jobID, issue.AutoPRIssueID, "code_review", review, job.Iteration, reviewedSHA)
 	if err != nil {
 		return fmt.Errorf("store review artifact: %w", err)
 	}
 
 	// Check if review approved or needs changes.
-	if !isApproved(resp.Text) {
+	if !isApproved(review) {
 		slog.Info("code review requested changes", "job", jobID, "iteration", job.Iteration)
 		return errReviewChangesRequested
 	}
@@ -220,6 +276,17 @@ func (r *Runner) runTestingAndReadiness(ctx context.Context, jobID string, issue
 	if err := r.runTests(ctx, jobID, issue, projectCfg, workDir); err != nil {
 		return err
 	}
+	r.scoreJobRisk(ctx, jobID, projectCfg, workDir)
+	paused, err := r.pauseIfOversize(ctx, jobID, projectCfg, workDir, "testing")
+	if err != nil {
+		if r.isJobCancelledError(ctx, jobID, err) {
+			return errJobCancelled
+		}
+		return r.failJob(ctx, jobID, "testing", err.Error())
+	}
+	if paused {
+		return nil
+	}
 	return r.runRebaseBeforeReady(ctx, jobID, issue, projectCfg, workDir)
 }
 
@@ -229,8 +296,66 @@ func (r *Runner) runTests(ctx context.Context, jobID string, issue db.Issue, pro
 		return err
 	}
 
-	// Run the project's test command.
-	testOutput, testErr := runTestCommand(ctx, workDir, projectCfg.TestCmd)
+	// Hand the commands below the project's shared caches.
+	caches, err := acquireCaches(ctx, r.cfg, projectCfg, workDir)
+	if err != nil {
+		if ctx.Err() != nil {
+			return context.Canceled
+		}
+		return err
+	}
+	defer caches.release()
+	testEnv, secrets := stepEnv(r.cfg, projectCfg, "tests")
+	env := append(caches.env, testEnv...)
+
+	// Rebuild generated files from their sources, so the policy check and
+	// tests see what regenerate_cmd produces rather than hand edits.
+	if output, err := r.regenerateGenerated(ctx, jobID, projectCfg, workDir, env); err != nil {
+		if errors.Is(err, context.Canceled) || ctx.Err() != nil {
+			return context.Canceled
+		}
+		if _, err := r.store.CreateArtifact(ctx, jobID, issue.AutoPRIssueID, "test_output", "Regenerate command failed:\n"+maskSecrets(output, secrets), job.Iteration, ""); err != nil {
+			slog.Warn("failed to store test artifact", "err", err)
+		}
+		slog.Info("regenerate command failed", "job", jobID, "err", err)
+		r.setTestsResult(ctx, jobID, db.TestsFailed)
+		return errTestsFailed
+	}
+
+	// Check the diff against the scope and path policy before spending a
+	// test run on it.
+	if hasPathPolicy(projectCfg) {
+		violations, err := pathPolicyViolations(ctx, workDir, projectCfg, TargetBranch(job, projectCfg))
+		if err != nil {
+			return fmt.Errorf("check path policy: %w", err)
+		}
+		if len(violations) > 0 {
+			if _, err := r.store.CreateArtifact(ctx, jobID, issue.AutoPRIssueID, "test_output", pathPolicyOutput(violations), job.Iteration, ""); err != nil {
+				slog.Warn("failed to store test artifact", "err", err)
+			}
+			slog.Info("path policy violated", "job", jobID, "files", len(violations))
+			r.setTestsResult(ctx, jobID, db.TestsFailed)
+			return fmt.Errorf("%w: %s (%s)", errPathPolicyViolation, violations[0].File, violations[0].Reason)
+		}
+	}
+
+	// Run the project's test command, or its test shards, unless the job's
+	// template sets its own.
+	projectCfg = r.withTemplateGates(ctx, issue, projectCfg)
+	testOutput, testErr := runProjectTests(ctx, scopeDir(workDir, projectCfg), projectCfg, env)
+	testOutput = maskSecrets(testOutput, secrets)
+
+	// Re-run once when the project allows it; tests that pass the second time
+	// are recorded as flaky and the step goes on.
+	if testErr != nil && ctx.Err() == nil && projectCfg.RetryFlakyTests {
+		slog.Info("tests failed, re-running once", "job", jobID)
+		retryOutput, retryErr := runProjectTests(ctx, scopeDir(workDir, projectCfg), projectCfg, env)
+		retryOutput = maskSecrets(retryOutput, secrets)
+		if retryErr == nil {
+			r.recordFlakyTests(ctx, job, issue, projectCfg, testOutput)
+			testOutput, testErr = retryOutput, nil
+		}
+	}
 
 	// Store test output as artifact.
 	_, err = r.store.CreateArtifact(ctx, jobID, issue.AutoPRIssueID, "test_output", testOutput, job.Iteration, "")
@@ -243,13 +368,24 @@ func (r *Runner) runTests(ctx context.Context, jobID string, issue db.Issue, pro
 			return context.Canceled
 		}
 		slog.Info("tests failed", "job", jobID, "err", testErr)
+		r.setTestsResult(ctx, jobID, db.TestsFailed)
 		return errTestsFailed
 	}
+	r.setTestsResult(ctx, jobID, db.TestsPassed)
 
+	r.recordGeneratedFiles(ctx, job, issue, projectCfg, workDir)
 	slog.Info("test step completed", "job", jobID)
 	return nil
 }
 
+// setTestsResult records the test step's outcome for the check run reported
+// on the PR; see ReportPipelineCheck.
+func (r *Runner) setTestsResult(ctx context.Context, jobID, result string) {
+	if err := r.store.SetJobTestsResult(ctx, jobID, result); err != nil {
+		slog.Warn("failed to record tests result", "job", jobID, "err", err)
+	}
+}
+
 func isApproved(text string) bool {
 	upper := strings.ToUpper(text)
 	// Reject if it explicitly says NOT APPROVED.
@@ -259,7 +395,7 @@ func isApproved(text string) bool {
 	return strings.Contains(upper, "APPROVED")
 }
 
-func runTestCommand(ctx context.Context, dir, testCmd string) (string, error) {
+func runTestCommand(ctx context.Context, dir, testCmd string, env []string) (string, error) {
 	if testCmd == "" {
 		return "no test command configured", nil
 	}
@@ -272,9 +408,7 @@ func runTestCommand(ctx context.Context, dir, testCmd string) (string, error) {
 		return err.Error(), err
 	}
 
-	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
-	cmd.Dir = dir
-	out, err := cmd.CombinedOutput()
+	out, err := executor.Command(ctx, executor.Spec{Dir: dir, Env: env, Argv: args}).CombinedOutput()
 	output := string(out)
 
 	// Truncate output to prevent huge artifacts.
@@ -0,0 +1,80 @@
+package pipeline
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+
+	"autopr/internal/db"
+)
+
+// maxSummaryRunes caps a job summary so it fits a job list row.
+const maxSummaryRunes = 80
+
+// maxSummaryBodyRunes caps how much of the issue body the summarize prompt
+// carries, keeping the pass cheap for long issues.
+const maxSummaryBodyRunes = 4000
+
+const summarizePrompt = `Summarize the following issue as one line of at most 72 characters saying what needs to change, in the imperative mood, e.g. "Fix crash when saving an empty profile". Do not read or modify any files. Reply with the line only.
+
+<issue>
+Title: {{title}}
+
+{{body}}
+</issue>`
+
+// summarizeJob stores a one-line LLM summary of the job when [llm]
+// summarize_jobs is set and the job has none yet. A failed pass leaves the
+// issue title in place and never fails the job.
+func (r *Runner) summarizeJob(ctx context.Context, job db.Job, issue db.Issue, workDir string) {
+	if !r.cfg.LLM.SummarizeJobs || job.Summary != "" {
+		return
+	}
+	body := []rune(SanitizeIssueContent(issue.Body))
+	if len(body) > maxSummaryBodyRunes {
+		body = append(body[:maxSummaryBodyRunes], []rune("\n[truncated]")...)
+	}
+	prompt := BuildPrompt(summarizePrompt, map[string]string{
+		"title": issue.Title,
+		"body":  string(body),
+	})
+
+	resp, err := r.invokeProvider(ctx, job.ID, "summarize", job.Iteration, workDir, prompt)
+	if err != nil {
+		slog.Warn("summarize job failed; keeping issue title", "job", job.ID, "err", err)
+		return
+	}
+	summary := cleanSummary(resp.Text)
+	if summary == "" {
+		slog.Warn("summarize job returned no summary; keeping issue title", "job", job.ID)
+		return
+	}
+	if err := r.store.UpdateJobField(ctx, job.ID, "summary", summary); err != nil {
+		slog.Warn("store job summary failed", "job", job.ID, "err", err)
+		return
+	}
+	slog.Info("job summarized", "job", job.ID, "summary", summary)
+}
+
+// cleanSummary reduces an LLM reply to a single summary line: the first
+// non-empty line without markdown markers, a "Summary:" label, or wrapping
+// quotes, capped at maxSummaryRunes.
+func cleanSummary(text string) string {
+	var line string
+	for l := range strings.SplitSeq(text, "\n") {
+		if l = strings.TrimSpace(l); l != "" {
+			line = l
+			break
+		}
+	}
+	line = strings.TrimSpace(strings.TrimLeft(line, "#*->` "))
+	if label, rest, ok := strings.Cut(line, ":"); ok && strings.EqualFold(strings.TrimSpace(label), "summary") {
+		line = strings.TrimSpace(rest)
+	}
+	line = strings.Trim(line, "\"'`*")
+	line = strings.Join(strings.Fields(line), " ")
+	if runes := []rune(line); len(runes) > maxSummaryRunes {
+		line = strings.TrimSpace(string(runes[:maxSummaryRunes-1])) + "…"
+	}
+	return line
+}
@@ -0,0 +1,93 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"autopr/internal/config"
+	"autopr/internal/llm"
+)
+
+func TestCleanSummary(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		in   string
+		want string
+	}{
+		{"Fix crash when saving an empty profile", "Fix crash when saving an empty profile"},
+		{"\n\n## Summary: \"Retry flaky   upload\"\nMore text", "Retry flaky upload"},
+		{"- `Handle nil config`", "Handle nil config"},
+		{"   \n  ", ""},
+		{strings.Repeat("word ", 30), strings.TrimSpace(strings.Repeat("word ", 16)) + "…"},
+	} {
+		if got := cleanSummary(tc.in); got != tc.want {
+			t.Fatalf("cleanSummary(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestSummarizeJobStoresSummary(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	var prompts []string
+	runner, store, issue, jobID := setupRunStepsJob(t, stubProvider{run: func(ctx context.Context, workDir, prompt string) (llm.Response, error) {
+		prompts = append(prompts, prompt)
+		return llm.Response{Text: "Summary: Stop login crashing on empty passwords", InputTokens: 40, OutputTokens: 9}, nil
+	}}, "planning")
+	runner.cfg = &config.Config{LLM: config.LLMConfig{SummarizeJobs: true}}
+
+	job, err := store.GetJob(ctx, jobID)
+	if err != nil {
+		t.Fatalf("get job: %v", err)
+	}
+	runner.summarizeJob(ctx, job, issue, t.TempDir())
+
+	job, err = store.GetJob(ctx, jobID)
+	if err != nil {
+		t.Fatalf("get job: %v", err)
+	}
+	if job.Summary != "Stop login crashing on empty passwords" {
+		t.Fatalf("unexpected summary %q", job.Summary)
+	}
+	if len(prompts) != 1 || !strings.Contains(prompts[0], "Title: pipeline test issue") {
+		t.Fatalf("expected one summarize prompt with the issue title, got %q", prompts)
+	}
+	sessions, err := store.ListSessionSummariesByJob(ctx, jobID)
+	if err != nil || len(sessions) != 1 || sessions[0].Step != "summarize" || sessions[0].Status != "completed" {
+		t.Fatalf("expected a completed summarize session, got %+v, %v", sessions, err)
+	}
+
+	// A summarized job is not summarized again.
+	runner.summarizeJob(ctx, job, issue, t.TempDir())
+	if len(prompts) != 1 {
+		t.Fatalf("expected no second summarize call, got %d", len(prompts))
+	}
+}
+
+func TestSummarizeJobFailureKeepsIssueTitle(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	runner, store, issue, jobID := setupRunStepsJob(t, stubProvider{run: func(ctx context.Context, workDir, prompt string) (llm.Response, error) {
+		return llm.Response{}, errors.New("rate limited")
+	}}, "planning")
+	runner.cfg = &config.Config{LLM: config.LLMConfig{SummarizeJobs: true}}
+
+	job, err := store.GetJob(ctx, jobID)
+	if err != nil {
+		t.Fatalf("get job: %v", err)
+	}
+	runner.summarizeJob(ctx, job, issue, t.TempDir())
+
+	jobs, err := store.ListJobs(ctx, "", "all", "updated_at", false)
+	if err != nil || len(jobs) != 1 {
+		t.Fatalf("list jobs: %+v, %v", jobs, err)
+	}
+	if jobs[0].Summary != "" || jobs[0].Title() != "pipeline test issue" {
+		t.Fatalf("expected the issue title to stand, got summary %q title %q", jobs[0].Summary, jobs[0].Title())
+	}
+}
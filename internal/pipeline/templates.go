@@ -0,0 +1,93 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"autopr/internal/config"
+	"autopr/internal/db"
+	"autopr/internal/policy"
+)
+
+// ParseTemplateVars parses the key=value pairs given to `ap run --var`.
+func ParseTemplateVars(pairs []string) (map[string]string, error) {
+	vars := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		key, value, ok := strings.Cut(pair, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid --var %q: want key=value", pair)
+		}
+		if _, dup := vars[key]; dup {
+			return nil, fmt.Errorf("--var %s given twice", key)
+		}
+		vars[key] = value
+	}
+	return vars, nil
+}
+
+// RenderTemplate fills in the variables of t. vars must give a value for
+// each variable t uses, and no others, so a typo is not silently dropped.
+func RenderTemplate(t *config.JobTemplate, vars map[string]string) (title, body string, gates db.IssueTemplate, err error) {
+	used := t.Vars()
+	var missing []string
+	for _, name := range used {
+		if _, ok := vars[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return "", "", db.IssueTemplate{}, fmt.Errorf("template %s: missing --var for %s", t.Name, strings.Join(missing, ", "))
+	}
+	for name := range vars {
+		if !slices.Contains(used, name) {
+			return "", "", db.IssueTemplate{}, fmt.Errorf("template %s: unknown variable %q (uses: %s)", t.Name, name, strings.Join(used, ", "))
+		}
+	}
+	fill := func(s string) string { return config.FillTemplateVars(s, vars) }
+	gates = db.IssueTemplate{Template: t.Name, TestCmd: fill(t.TestCmd), LintCmd: fill(t.LintCmd)}
+	return fill(t.Title), fill(t.Prompt), gates, nil
+}
+
+// CreateTemplateJob queues a job for the task t describes, with vars filled
+// in. Like CreateManualJob, a synthetic issue holds the title and prompt; it
+// also records the template's gates for the test step.
+func CreateTemplateJob(ctx context.Context, store *db.Store, proj *config.ProjectConfig, maxIterations int, t *config.JobTemplate, vars map[string]string) (string, error) {
+	title, body, gates, err := RenderTemplate(t, vars)
+	if err != nil {
+		return "", err
+	}
+	issueID, err := createManualIssue(ctx, store, proj, title, body)
+	if err != nil {
+		return "", err
+	}
+	if err := store.SetIssueTemplate(ctx, issueID, gates); err != nil {
+		return "", err
+	}
+	return policy.CreateJob(ctx, store, proj, issueID, maxIterations)
+}
+
+// withTemplateGates returns projectCfg with the test and lint commands of
+// the job template issue was created from, when it sets them. A template
+// test command replaces the project's test shards too.
+func (r *Runner) withTemplateGates(ctx context.Context, issue db.Issue, projectCfg *config.ProjectConfig) *config.ProjectConfig {
+	t, ok, err := r.store.GetIssueTemplate(ctx, issue.AutoPRIssueID)
+	if err != nil {
+		slog.Warn("get issue template", "issue", issue.AutoPRIssueID, "err", err)
+	}
+	if !ok || (t.TestCmd == "" && t.LintCmd == "") {
+		return projectCfg
+	}
+	gated := *projectCfg
+	if t.TestCmd != "" {
+		gated.TestCmd = t.TestCmd
+		gated.TestShards = nil
+	}
+	if t.LintCmd != "" {
+		gated.LintCmd = t.LintCmd
+	}
+	return &gated
+}
@@ -0,0 +1,84 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"autopr/internal/config"
+	"autopr/internal/db"
+)
+
+func TestRenderTemplate(t *testing.T) {
+	t.Parallel()
+
+	tmpl := &config.JobTemplate{
+		Name:    "add-endpoint",
+		Title:   "Add {{name}} to {{service}}",
+		Prompt:  "Add a {{name}} handler in internal/{{service}}.",
+		TestCmd: "go test ./internal/{{service}}/...",
+	}
+	title, body, gates, err := RenderTemplate(tmpl, map[string]string{"name": "GetUser", "service": "{{billing}}"})
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	if title != "Add GetUser to {{billing}}" || body != "Add a GetUser handler in internal/{{billing}}." {
+		t.Fatalf("unexpected title %q and body %q", title, body)
+	}
+	if gates != (db.IssueTemplate{Template: "add-endpoint", TestCmd: "go test ./internal/{{billing}}/..."}) {
+		t.Fatalf("unexpected gates %+v", gates)
+	}
+
+	if _, _, _, err := RenderTemplate(tmpl, map[string]string{"name": "GetUser"}); err == nil || !strings.Contains(err.Error(), "missing --var for service") {
+		t.Fatalf("expected a missing variable error, got %v", err)
+	}
+	if _, _, _, err := RenderTemplate(tmpl, map[string]string{"name": "GetUser", "service": "billing", "servce": "x"}); err == nil || !strings.Contains(err.Error(), `unknown variable "servce"`) {
+		t.Fatalf("expected an unknown variable error, got %v", err)
+	}
+}
+
+func TestParseTemplateVars(t *testing.T) {
+	t.Parallel()
+
+	vars, err := ParseTemplateVars([]string{"name=GetUser", "filter=a=b"})
+	if err != nil || vars["name"] != "GetUser" || vars["filter"] != "a=b" {
+		t.Fatalf("ParseTemplateVars = %v, %v", vars, err)
+	}
+	for _, bad := range [][]string{{"name"}, {"=x"}, {"a=1", "a=2"}} {
+		if _, err := ParseTemplateVars(bad); err == nil {
+			t.Fatalf("expected an error for %q", bad)
+		}
+	}
+}
+
+func TestRunTestsUsesTemplateGates(t *testing.T) {
+	t.Parallel()
+
+	runner, store, issue, jobID := setupRunStepsJob(t, nil, "testing")
+	ctx := context.Background()
+	remote, workDir := cloneWithChanges(t, nil)
+	if err := os.WriteFile(filepath.Join(workDir, "gate.sh"), []byte("#!/bin/sh\necho template gate $1\nexit 1\n"), 0o755); err != nil {
+		t.Fatalf("write script: %v", err)
+	}
+	if err := store.SetIssueTemplate(ctx, issue.AutoPRIssueID, db.IssueTemplate{Template: "add-endpoint", LintCmd: "./gate.sh billing"}); err != nil {
+		t.Fatalf("set issue template: %v", err)
+	}
+	projectCfg := &config.ProjectConfig{Name: "myproject", RepoURL: remote, BaseBranch: "main", TestCmd: "git status"}
+
+	if err := runner.runTests(ctx, jobID, issue, projectCfg, workDir); !errors.Is(err, errTestsFailed) {
+		t.Fatalf("expected the template lint gate to fail the tests, got %v", err)
+	}
+	artifact, err := store.GetLatestArtifact(ctx, jobID, "test_output")
+	if err != nil {
+		t.Fatalf("get test_output: %v", err)
+	}
+	if !strings.Contains(artifact.Content, "=== lint: ./gate.sh billing\ntemplate gate billing") {
+		t.Fatalf("unexpected test output %q", artifact.Content)
+	}
+	if projectCfg.LintCmd != "" {
+		t.Fatalf("expected the project config left alone, got lint_cmd %q", projectCfg.LintCmd)
+	}
+}
@@ -174,7 +174,7 @@ func TestParseTestCommandRejectsMalformedSyntax(t *testing.T) {
 func TestRunTestCommandExecutesWithoutShell(t *testing.T) {
 	t.Parallel()
 
-	output, err := runTestCommand(context.Background(), t.TempDir(), "go version")
+	output, err := runTestCommand(context.Background(), t.TempDir(), "go version", nil)
 	if err != nil {
 		t.Fatalf("runTestCommand returned error: %v", err)
 	}
@@ -186,7 +186,7 @@ func TestRunTestCommandExecutesWithoutShell(t *testing.T) {
 func TestRunTestCommandRejectsUnsafeCommand(t *testing.T) {
 	t.Parallel()
 
-	output, err := runTestCommand(context.Background(), t.TempDir(), "go version && echo bad")
+	output, err := runTestCommand(context.Background(), t.TempDir(), "go version && echo bad", nil)
 	if err == nil {
 		t.Fatal("expected runTestCommand error")
 	}
@@ -201,7 +201,7 @@ func TestRunTestCommandRejectsUnsafeCommand(t *testing.T) {
 func TestRunTestCommandRejectsShellExecutable(t *testing.T) {
 	t.Parallel()
 
-	output, err := runTestCommand(context.Background(), t.TempDir(), "sh -c 'echo hi'")
+	output, err := runTestCommand(context.Background(), t.TempDir(), "sh -c 'echo hi'", nil)
 	if err == nil {
 		t.Fatal("expected runTestCommand error")
 	}
@@ -216,7 +216,7 @@ func TestRunTestCommandRejectsShellExecutable(t *testing.T) {
 func TestRunTestCommandRejectsShellViaEnv(t *testing.T) {
 	t.Parallel()
 
-	output, err := runTestCommand(context.Background(), t.TempDir(), "env sh -c 'echo hi'")
+	output, err := runTestCommand(context.Background(), t.TempDir(), "env sh -c 'echo hi'", nil)
 	if err == nil {
 		t.Fatal("expected runTestCommand error")
 	}
@@ -231,7 +231,7 @@ func TestRunTestCommandRejectsShellViaEnv(t *testing.T) {
 func TestRunTestCommandRejectsShellViaEnvAssignment(t *testing.T) {
 	t.Parallel()
 
-	output, err := runTestCommand(context.Background(), t.TempDir(), "env FOO=bar sh -c 'echo hi'")
+	output, err := runTestCommand(context.Background(), t.TempDir(), "env FOO=bar sh -c 'echo hi'", nil)
 	if err == nil {
 		t.Fatal("expected runTestCommand error")
 	}
@@ -246,7 +246,7 @@ func TestRunTestCommandRejectsShellViaEnvAssignment(t *testing.T) {
 func TestRunTestCommandRejectsShellViaBusybox(t *testing.T) {
 	t.Parallel()
 
-	output, err := runTestCommand(context.Background(), t.TempDir(), "busybox sh -c 'echo hi'")
+	output, err := runTestCommand(context.Background(), t.TempDir(), "busybox sh -c 'echo hi'", nil)
 	if err == nil {
 		t.Fatal("expected runTestCommand error")
 	}
@@ -0,0 +1,50 @@
+package pipeline
+
+import (
+	"context"
+
+	"autopr/internal/config"
+	"autopr/internal/db"
+	"autopr/internal/git"
+)
+
+// llmCoAuthors are the Co-authored-by identities of the LLM providers, used
+// unless trailers.llm_identity is set.
+var llmCoAuthors = map[string]string{
+	"claude": "Claude <noreply@anthropic.com>",
+	"codex":  "Codex <noreply@openai.com>",
+}
+
+// ApplyCommitTrailers adds the project's [projects.trailers] to the job's
+// commits on top of origin/<baseBranch>. It is called after
+// RebaseBeforePush on every push path. approver is "Name <email>" of the human
+// who approved the job, or "" when there is none (auto_pr) or they are not
+// known. Backports and reverts copy commits an LLM did not write, so they are
+// not credited to the provider.
+func ApplyCommitTrailers(ctx context.Context, cfg *config.Config, proj *config.ProjectConfig, job db.Job, workDir, baseBranch, approver string) error {
+	tr := proj.Trailers
+	if tr == nil {
+		return nil
+	}
+	var trailers []string
+	if tr.SignedOffBy {
+		ident, err := git.CommitterIdentity(ctx, workDir)
+		if err != nil {
+			return err
+		}
+		trailers = append(trailers, "Signed-off-by: "+ident)
+	}
+	if tr.CoAuthorApprover && approver != "" {
+		trailers = append(trailers, "Co-authored-by: "+approver)
+	}
+	if tr.CoAuthorLLM && job.BackportBranch == "" && job.RevertCommit == "" {
+		ident := tr.LLMIdentity
+		if ident == "" {
+			ident = llmCoAuthors[cfg.LLM.Provider]
+		}
+		if ident != "" {
+			trailers = append(trailers, "Co-authored-by: "+ident)
+		}
+	}
+	return git.AddTrailers(ctx, workDir, "origin/"+baseBranch, trailers)
+}
@@ -0,0 +1,62 @@
+package pipeline
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"autopr/internal/config"
+	"autopr/internal/db"
+)
+
+func TestApplyCommitTrailers(t *testing.T) {
+	t.Setenv("GIT_AUTHOR_NAME", "AutoPR Bot")
+	t.Setenv("GIT_AUTHOR_EMAIL", "bot@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "AutoPR Bot")
+	t.Setenv("GIT_COMMITTER_EMAIL", "bot@example.com")
+
+	ctx := context.Background()
+	tmp := t.TempDir()
+	remote := createBareRemoteWithMain(t, tmp)
+	worktree := filepath.Join(tmp, "worktree")
+	runGitCmdLocal(t, "", "clone", "-b", "main", remote, worktree)
+	if err := os.WriteFile(filepath.Join(worktree, "fix.txt"), []byte("fix\n"), 0o644); err != nil {
+		t.Fatalf("write fix: %v", err)
+	}
+	runGitCmdLocal(t, worktree, "add", "fix.txt")
+	runGitCmdLocal(t, worktree, "commit", "-m", "fix")
+
+	cfg := &config.Config{LLM: config.LLMConfig{Provider: "claude"}, Projects: []config.ProjectConfig{{
+		Name:     "myproject",
+		Trailers: &config.ProjectTrailers{SignedOffBy: true, CoAuthorApprover: true, CoAuthorLLM: true},
+	}}}
+	proj := &cfg.Projects[0]
+	message := func() string {
+		t.Helper()
+		out, err := runGitCommandOutput(t, worktree, "log", "-1", "--format=%B")
+		if err != nil {
+			t.Fatalf("git log: %v", err)
+		}
+		return out
+	}
+
+	// A backport copies human commits: no LLM credit, and no approver here.
+	if err := ApplyCommitTrailers(ctx, cfg, proj, db.Job{BackportBranch: "release"}, worktree, "main", ""); err != nil {
+		t.Fatalf("apply trailers: %v", err)
+	}
+	if msg := message(); !strings.Contains(msg, "Signed-off-by: AutoPR Bot <bot@example.com>") || strings.Contains(msg, "Co-authored-by") {
+		t.Fatalf("unexpected backport message:\n%s", msg)
+	}
+
+	if err := ApplyCommitTrailers(ctx, cfg, proj, db.Job{}, worktree, "main", "Jane Doe <jane@example.com>"); err != nil {
+		t.Fatalf("apply trailers: %v", err)
+	}
+	msg := message()
+	for _, want := range []string{"Signed-off-by: AutoPR Bot <bot@example.com>", "Co-authored-by: Jane Doe <jane@example.com>", "Co-authored-by: Claude <noreply@anthropic.com>"} {
+		if strings.Count(msg, want) != 1 {
+			t.Fatalf("expected %q once in:\n%s", want, msg)
+		}
+	}
+}
@@ -0,0 +1,397 @@
+// Package policy runs a project's Starlark policy script.
+//
+// A project's policy_file is a Starlark script, kept in version control next
+// to autopr.toml, that can define any of these functions:
+//
+//	eligible(issue)        True or None to queue, False or a reason string to skip
+//	route(issue)           name of the project that should own the issue, or None
+//	priority(job, issue)   int; queued jobs with a higher priority are claimed first
+//	gate(job, issue, diff) True or None to let a ready job through, False or a reason to reject it
+//
+// issue, job, and diff are read-only structs; see Issue, Job, and Diff for
+// their fields. Scripts have no access to files, the network, or the clock,
+// and each call is limited to maxSteps execution steps. A script that fails
+// to load or a call that fails is logged and leaves the decision to the
+// built-in rules.
+package policy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
+	"go.starlark.net/syntax"
+
+	"autopr/internal/config"
+	"autopr/internal/db"
+)
+
+// maxSteps bounds the work of one policy call, so a runaway loop can't stall
+// a sync round or a worker.
+const maxSteps = 1_000_000
+
+// funcParams are the policy functions a script may define and their
+// parameter counts.
+var funcParams = map[string]int{
+	"eligible": 1,
+	"route":    1,
+	"priority": 2,
+	"gate":     3,
+}
+
+// fileOptions allows the statements a policy reasonably needs; recursion
+// stays off.
+var fileOptions = &syntax.FileOptions{Set: true, While: true, TopLevelControl: true, GlobalReassign: true}
+
+// Issue is the issue a policy sees.
+type Issue struct {
+	Project string
+	Source  string
+	ID      string
+	Title   string
+	Body    string
+	URL     string
+	Labels  []string
+}
+
+// Job is the job a policy sees.
+type Job struct {
+	ID            string
+	Project       string
+	State         string
+	Iteration     int
+	MaxIterations int
+	Tags          []string
+	Deadline      string
+	ParentJobID   string
+	RiskScore     int
+	RiskLevel     string // "" until the job has been scored
+}
+
+// Diff is the final diff of a job, as seen by gate. Generated files are left
+// out.
+type Diff struct {
+	Files []string
+	Lines int
+}
+
+// IssueFromDB converts a synced issue.
+func IssueFromDB(i db.Issue) Issue {
+	return Issue{
+		Project: i.ProjectName,
+		Source:  i.Source,
+		ID:      i.SourceIssueID,
+		Title:   i.Title,
+		Body:    i.Body,
+		URL:     i.URL,
+		Labels:  i.Labels(),
+	}
+}
+
+// JobFromDB converts a job.
+func JobFromDB(j db.Job) Job {
+	return Job{
+		ID:            j.ID,
+		Project:       j.ProjectName,
+		State:         j.State,
+		Iteration:     j.Iteration,
+		MaxIterations: j.MaxIterations,
+		Tags:          j.Tags,
+		Deadline:      j.Deadline,
+		ParentJobID:   j.ParentJobID,
+		RiskScore:     j.RiskScore,
+		RiskLevel:     j.RiskLevel(),
+	}
+}
+
+// Policy is a loaded policy script. Its globals are frozen, so it is safe
+// for concurrent use.
+type Policy struct {
+	path    string
+	globals starlark.StringDict
+}
+
+// Load runs the policy script at path and checks the policy functions it
+// defines.
+func Load(path string) (*Policy, error) {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read policy %s: %w", path, err)
+	}
+	return load(path, src)
+}
+
+func load(path string, src []byte) (*Policy, error) {
+	thread := newThread(path)
+	globals, err := starlark.ExecFileOptions(fileOptions, thread, path, src, nil)
+	if err != nil {
+		return nil, fmt.Errorf("load policy %s: %w", path, describe(err))
+	}
+	for name, params := range funcParams {
+		v, ok := globals[name]
+		if !ok {
+			continue
+		}
+		fn, ok := v.(*starlark.Function)
+		if !ok {
+			return nil, fmt.Errorf("load policy %s: %s must be a function, got %s", path, name, v.Type())
+		}
+		if fn.NumParams() != params {
+			return nil, fmt.Errorf("load policy %s: %s must take %d parameter(s), takes %d", path, name, params, fn.NumParams())
+		}
+	}
+	globals.Freeze()
+	return &Policy{path: path, globals: globals}, nil
+}
+
+// Functions lists the policy functions the script defines, sorted.
+func (p *Policy) Functions() []string {
+	var names []string
+	for name := range funcParams {
+		if _, ok := p.globals[name]; ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Eligible asks the script whether an issue should be queued. It returns ""
+// to queue the issue, or the reason to skip it. Without eligible() every
+// issue passes.
+func (p *Policy) Eligible(issue Issue) (string, error) {
+	v, ok, err := p.call("eligible", issueValue(issue))
+	if err != nil || !ok {
+		return "", err
+	}
+	return verdict("eligible", v, "rejected by policy")
+}
+
+// Route asks the script which project should own an issue. It returns "" when
+// the script has no route() or no opinion.
+func (p *Policy) Route(issue Issue) (string, error) {
+	v, ok, err := p.call("route", issueValue(issue))
+	if err != nil || !ok || v == starlark.None {
+		return "", err
+	}
+	s, isStr := starlark.AsString(v)
+	if !isStr {
+		return "", fmt.Errorf("route returned %s, want a project name or None", v.Type())
+	}
+	return s, nil
+}
+
+// Priority asks the script for a queued job's priority. ok is false when the
+// script has no priority().
+func (p *Policy) Priority(job Job, issue Issue) (priority int, ok bool, err error) {
+	v, ok, err := p.call("priority", jobValue(job), issueValue(issue))
+	if err != nil || !ok {
+		return 0, false, err
+	}
+	if v == starlark.None {
+		return 0, true, nil
+	}
+	n, err := starlark.AsInt32(v)
+	if err != nil {
+		return 0, false, fmt.Errorf("priority returned %s, want an int: %w", v.Type(), err)
+	}
+	return n, true, nil
+}
+
+// Gate asks the script whether a ready job may go on to approval. It returns
+// "" to let it through, or the reason to reject it.
+func (p *Policy) Gate(job Job, issue Issue, diff Diff) (string, error) {
+	v, ok, err := p.call("gate", jobValue(job), issueValue(issue), diffValue(diff))
+	if err != nil || !ok {
+		return "", err
+	}
+	return verdict("gate", v, "blocked by policy")
+}
+
+// call calls the named policy function; ok is false when the script doesn't
+// define it.
+func (p *Policy) call(name string, args ...starlark.Value) (v starlark.Value, ok bool, err error) {
+	fn, ok := p.globals[name]
+	if !ok {
+		return nil, false, nil
+	}
+	v, err = starlark.Call(newThread(p.path), fn, starlark.Tuple(args), nil)
+	if err != nil {
+		return nil, true, fmt.Errorf("policy %s: %w", name, describe(err))
+	}
+	return v, true, nil
+}
+
+// verdict reads the result of eligible() or gate(): True or None pass,
+// False fails with fallback as the reason, and a string is the reason itself
+// ("" passes).
+func verdict(name string, v starlark.Value, fallback string) (string, error) {
+	switch v := v.(type) {
+	case starlark.NoneType:
+		return "", nil
+	case starlark.Bool:
+		if v {
+			return "", nil
+		}
+		return fallback, nil
+	case starlark.String:
+		return string(v), nil
+	}
+	return "", fmt.Errorf("%s returned %s, want a bool, a reason string, or None", name, v.Type())
+}
+
+func newThread(path string) *starlark.Thread {
+	thread := &starlark.Thread{
+		Name: "policy",
+		Print: func(_ *starlark.Thread, msg string) {
+			slog.Debug("policy print", "file", path, "msg", msg)
+		},
+	}
+	thread.SetMaxExecutionSteps(maxSteps)
+	return thread
+}
+
+// describe adds the Starlark backtrace to evaluation errors.
+func describe(err error) error {
+	var evalErr *starlark.EvalError
+	if errors.As(err, &evalErr) {
+		return errors.New(evalErr.Backtrace())
+	}
+	return err
+}
+
+func stringList(values []string) *starlark.List {
+	elems := make([]starlark.Value, len(values))
+	for i, s := range values {
+		elems[i] = starlark.String(s)
+	}
+	return starlark.NewList(elems)
+}
+
+func issueValue(i Issue) starlark.Value {
+	s := starlarkstruct.FromStringDict(starlarkstruct.Default, starlark.StringDict{
+		"project": starlark.String(i.Project),
+		"source":  starlark.String(i.Source),
+		"id":      starlark.String(i.ID),
+		"title":   starlark.String(i.Title),
+		"body":    starlark.String(i.Body),
+		"url":     starlark.String(i.URL),
+		"labels":  stringList(i.Labels),
+	})
+	s.Freeze()
+	return s
+}
+
+func jobValue(j Job) starlark.Value {
+	s := starlarkstruct.FromStringDict(starlarkstruct.Default, starlark.StringDict{
+		"id":             starlark.String(j.ID),
+		"project":        starlark.String(j.Project),
+		"state":          starlark.String(j.State),
+		"iteration":      starlark.MakeInt(j.Iteration),
+		"max_iterations": starlark.MakeInt(j.MaxIterations),
+		"tags":           stringList(j.Tags),
+		"deadline":       starlark.String(j.Deadline),
+		"parent_job_id":  starlark.String(j.ParentJobID),
+		"risk_score":     starlark.MakeInt(j.RiskScore),
+		"risk_level":     starlark.String(j.RiskLevel),
+	})
+	s.Freeze()
+	return s
+}
+
+func diffValue(d Diff) starlark.Value {
+	s := starlarkstruct.FromStringDict(starlarkstruct.Default, starlark.StringDict{
+		"files": stringList(d.Files),
+		"lines": starlark.MakeInt(d.Lines),
+	})
+	s.Freeze()
+	return s
+}
+
+// cache holds loaded policies by path, reloaded when the file changes.
+var cache = struct {
+	sync.Mutex
+	entries map[string]cacheEntry
+}{entries: map[string]cacheEntry{}}
+
+type cacheEntry struct {
+	modTime time.Time
+	size    int64
+	policy  *Policy
+	err     error
+}
+
+// ForProject returns the project's policy, or nil when it has no
+// policy_file. The script is loaded once and again whenever the file changes,
+// so edits take effect without a daemon restart.
+func ForProject(proj *config.ProjectConfig) (*Policy, error) {
+	if proj == nil || proj.PolicyFile == "" {
+		return nil, nil
+	}
+	info, err := os.Stat(proj.PolicyFile)
+	if err != nil {
+		return nil, fmt.Errorf("read policy %s: %w", proj.PolicyFile, err)
+	}
+	cache.Lock()
+	defer cache.Unlock()
+	if e, ok := cache.entries[proj.PolicyFile]; ok && e.modTime.Equal(info.ModTime()) && e.size == info.Size() {
+		return e.policy, e.err
+	}
+	p, err := Load(proj.PolicyFile)
+	cache.entries[proj.PolicyFile] = cacheEntry{modTime: info.ModTime(), size: info.Size(), policy: p, err: err}
+	return p, err
+}
+
+// CreateJob queues a job for an issue of proj and stores the priority the
+// project's policy gives it. Every issue job is created through it, so
+// priority() applies however the job was queued. The job keeps priority 0
+// when the script has no priority() or fails.
+func CreateJob(ctx context.Context, store *db.Store, proj *config.ProjectConfig, issueID string, maxIterations int) (string, error) {
+	jobID, err := store.CreateJob(ctx, issueID, proj.Name, maxIterations)
+	if err != nil {
+		return "", err
+	}
+	prioritizeJob(ctx, store, proj, jobID)
+	return jobID, nil
+}
+
+func prioritizeJob(ctx context.Context, store *db.Store, proj *config.ProjectConfig, jobID string) {
+	pol, err := ForProject(proj)
+	if err != nil {
+		slog.Warn("load policy", "project", proj.Name, "err", err)
+		return
+	}
+	if pol == nil {
+		return
+	}
+	job, err := store.GetJob(ctx, jobID)
+	if err != nil {
+		slog.Warn("policy priority: get job", "job", jobID, "err", err)
+		return
+	}
+	issue, err := store.GetIssueByAPID(ctx, job.AutoPRIssueID)
+	if err != nil {
+		slog.Warn("policy priority: get issue", "job", jobID, "err", err)
+		return
+	}
+	priority, ok, err := pol.Priority(JobFromDB(job), IssueFromDB(issue))
+	if err != nil {
+		slog.Warn("policy priority failed", "job", jobID, "err", err)
+		return
+	}
+	if !ok || priority == 0 {
+		return
+	}
+	if err := store.SetJobPriority(ctx, jobID, priority); err != nil {
+		slog.Warn("store job priority failed", "job", jobID, "err", err)
+		return
+	}
+	slog.Info("job prioritized by policy", "job", jobID, "priority", priority)
+}
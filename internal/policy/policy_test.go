@@ -0,0 +1,186 @@
+package policy
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"autopr/internal/config"
+)
+
+const testScript = `
+def eligible(issue):
+    if "wontfix" in issue.labels:
+        return "labelled wontfix"
+    if issue.title.startswith("WIP"):
+        return False
+    return True
+
+def route(issue):
+    if "frontend" in issue.labels:
+        return "web"
+    return None
+
+def priority(job, issue):
+    if "urgent" in issue.labels:
+        return 10
+    return len(job.tags)
+
+def gate(job, issue, diff):
+    if diff.lines > 500 and job.risk_level == "high":
+        return "too big for a high-risk change"
+    return None
+`
+
+func mustLoad(t *testing.T, src string) *Policy {
+	t.Helper()
+	p, err := load("test.star", []byte(src))
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	return p
+}
+
+func TestPolicyFunctions(t *testing.T) {
+	p := mustLoad(t, testScript)
+
+	if got := strings.Join(p.Functions(), ","); got != "eligible,gate,priority,route" {
+		t.Fatalf("functions = %q", got)
+	}
+
+	for _, tc := range []struct {
+		issue Issue
+		want  string
+	}{
+		{Issue{Title: "Fix crash"}, ""},
+		{Issue{Title: "Fix crash", Labels: []string{"wontfix"}}, "labelled wontfix"},
+		{Issue{Title: "WIP: redo"}, "rejected by policy"},
+	} {
+		got, err := p.Eligible(tc.issue)
+		if err != nil {
+			t.Fatalf("eligible(%+v): %v", tc.issue, err)
+		}
+		if got != tc.want {
+			t.Fatalf("eligible(%+v) = %q, want %q", tc.issue, got, tc.want)
+		}
+	}
+
+	if owner, err := p.Route(Issue{Labels: []string{"frontend"}}); err != nil || owner != "web" {
+		t.Fatalf("route = %q, %v; want web", owner, err)
+	}
+	if owner, err := p.Route(Issue{}); err != nil || owner != "" {
+		t.Fatalf("route = %q, %v; want none", owner, err)
+	}
+
+	if n, ok, err := p.Priority(Job{}, Issue{Labels: []string{"urgent"}}); err != nil || !ok || n != 10 {
+		t.Fatalf("priority = %d, %v, %v; want 10", n, ok, err)
+	}
+	if n, ok, err := p.Priority(Job{Tags: []string{"a", "b"}}, Issue{}); err != nil || !ok || n != 2 {
+		t.Fatalf("priority = %d, %v, %v; want 2", n, ok, err)
+	}
+
+	if reason, err := p.Gate(Job{RiskLevel: "high"}, Issue{}, Diff{Lines: 800}); err != nil || reason != "too big for a high-risk change" {
+		t.Fatalf("gate = %q, %v", reason, err)
+	}
+	if reason, err := p.Gate(Job{RiskLevel: "low"}, Issue{}, Diff{Lines: 800}); err != nil || reason != "" {
+		t.Fatalf("gate = %q, %v; want pass", reason, err)
+	}
+}
+
+func TestPolicyUndefinedFunctionsPass(t *testing.T) {
+	p := mustLoad(t, "x = 1\n")
+	if reason, err := p.Eligible(Issue{}); err != nil || reason != "" {
+		t.Fatalf("eligible = %q, %v", reason, err)
+	}
+	if _, ok, err := p.Priority(Job{}, Issue{}); err != nil || ok {
+		t.Fatalf("priority ok = %v, %v; want not defined", ok, err)
+	}
+	if reason, err := p.Gate(Job{}, Issue{}, Diff{}); err != nil || reason != "" {
+		t.Fatalf("gate = %q, %v", reason, err)
+	}
+}
+
+func TestLoadRejectsBadScripts(t *testing.T) {
+	for name, src := range map[string]string{
+		"syntax":        "def eligible(issue)\n",
+		"arity":         "def gate(job, issue):\n    return True\n",
+		"not function":  "route = \"web\"\n",
+		"runtime error": "x = 1 // 0\n",
+	} {
+		if _, err := load("bad.star", []byte(src)); err == nil {
+			t.Fatalf("%s: load succeeded, want error", name)
+		}
+	}
+}
+
+func TestPolicyCallErrors(t *testing.T) {
+	p := mustLoad(t, `
+def eligible(issue):
+    return 42
+
+def priority(job, issue):
+    return "high"
+
+def gate(job, issue, diff):
+    n = 0
+    while True:
+        n += 1
+`)
+	if _, err := p.Eligible(Issue{}); err == nil {
+		t.Fatal("eligible returning an int should fail")
+	}
+	if _, _, err := p.Priority(Job{}, Issue{}); err == nil {
+		t.Fatal("priority returning a string should fail")
+	}
+	if _, err := p.Gate(Job{}, Issue{}, Diff{}); err == nil || !strings.Contains(err.Error(), "too many steps") {
+		t.Fatalf("endless gate: err = %v, want step limit", err)
+	}
+}
+
+func TestPolicyStructsAreReadOnly(t *testing.T) {
+	p := mustLoad(t, `
+def eligible(issue):
+    issue.labels.append("x")
+    return True
+`)
+	if _, err := p.Eligible(Issue{Labels: []string{"a"}}); err == nil {
+		t.Fatal("mutating issue.labels should fail")
+	}
+}
+
+func TestForProjectReloadsChangedFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.star")
+	if err := os.WriteFile(path, []byte("def route(issue):\n    return \"a\"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	proj := &config.ProjectConfig{Name: "p", PolicyFile: path}
+
+	p, err := ForProject(proj)
+	if err != nil {
+		t.Fatalf("ForProject: %v", err)
+	}
+	if again, _ := ForProject(proj); again != p {
+		t.Fatal("unchanged policy file was loaded again")
+	}
+
+	if err := os.WriteFile(path, []byte("def route(issue):\n    return \"bb\"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	future := time.Now().Add(time.Minute)
+	if err := os.Chtimes(path, future, future); err != nil {
+		t.Fatal(err)
+	}
+	p, err = ForProject(proj)
+	if err != nil {
+		t.Fatalf("ForProject after change: %v", err)
+	}
+	if owner, _ := p.Route(Issue{}); owner != "bb" {
+		t.Fatalf("route after change = %q, want bb", owner)
+	}
+
+	if p, err := ForProject(&config.ProjectConfig{Name: "none"}); p != nil || err != nil {
+		t.Fatalf("no policy_file: got %v, %v", p, err)
+	}
+}
@@ -0,0 +1,145 @@
+// Package queuewatch reports jobs that have waited in the queue too long and
+// explains why no worker has claimed them.
+package queuewatch
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"autopr/internal/config"
+	"autopr/internal/db"
+)
+
+// checkInterval is how often the daemon looks for stale queued jobs.
+const checkInterval = 5 * time.Minute
+
+// StaleJob is a queued job past the threshold and why it is still waiting.
+type StaleJob struct {
+	db.StaleQueuedJob
+	Reasons []string
+}
+
+// Watcher finds stale queued jobs and raises queue_stale alerts.
+type Watcher struct {
+	cfg   *config.Config
+	store *db.Store
+
+	// DaemonRunning says whether workers are polling the queue at all.
+	DaemonRunning bool
+}
+
+func New(cfg *config.Config, store *db.Store) *Watcher {
+	return &Watcher{cfg: cfg, store: store, DaemonRunning: true}
+}
+
+// StaleAfter returns the configured queue_stale_after; zero disables alerts.
+func StaleAfter(cfg *config.Config) time.Duration {
+	d, _ := time.ParseDuration(cfg.Daemon.QueueStaleAfter)
+	return max(d, 0)
+}
+
+// Stale returns every queued job older than the threshold, oldest first.
+func (w *Watcher) Stale(ctx context.Context) ([]StaleJob, error) {
+	staleAfter := StaleAfter(w.cfg)
+	if staleAfter <= 0 {
+		return nil, nil
+	}
+	jobs, err := w.store.ListStaleQueuedJobs(ctx, staleAfter)
+	if err != nil || len(jobs) == 0 {
+		return nil, err
+	}
+	working, err := w.store.CountWorkingJobs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	overrides, err := w.store.ProjectEnabledOverrides(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]StaleJob, 0, len(jobs))
+	for _, job := range jobs {
+		enabled := w.cfg.ProjectEnabled(job.ProjectName, overrides)
+		out = append(out, StaleJob{
+			StaleQueuedJob: job,
+			Reasons:        Explain(job, working, w.cfg.Daemon.MaxWorkers, w.DaemonRunning, enabled),
+		})
+	}
+	return out, nil
+}
+
+// Explain lists why a queued job has not been claimed. Workers claim the
+// oldest queued job of an enabled project whose issue is eligible (follow-up
+// jobs such as backports always are), so those are the only blockers besides
+// capacity.
+func Explain(job db.StaleQueuedJob, working, maxWorkers int, daemonRunning, projectEnabled bool) []string {
+	var reasons []string
+	if !projectEnabled {
+		reasons = append(reasons, fmt.Sprintf("project %s is disabled", job.ProjectName))
+	}
+	if !job.Claimable {
+		reason := "issue is not eligible"
+		if job.SkipReason != "" {
+			reason += ": " + job.SkipReason
+		}
+		reasons = append(reasons, reason)
+	}
+	if !daemonRunning {
+		reasons = append(reasons, "daemon is not running")
+	}
+	if maxWorkers > 0 && working >= maxWorkers {
+		reasons = append(reasons, fmt.Sprintf("all %d workers are busy", maxWorkers))
+	}
+	if job.Claimable && job.Ahead > 0 {
+		reasons = append(reasons, fmt.Sprintf("%d older job(s) of its project queued ahead", job.Ahead))
+	}
+	if len(reasons) == 0 {
+		reasons = append(reasons, "no blocker found; check the daemon log for claim errors")
+	}
+	return reasons
+}
+
+// Check flags stale jobs that have not been alerted on yet, sending one
+// queue_stale notification per job, and returns them.
+func (w *Watcher) Check(ctx context.Context) []StaleJob {
+	stale, err := w.Stale(ctx)
+	if err != nil {
+		slog.Error("queue watch: list stale jobs", "err", err)
+		return nil
+	}
+	var flagged []StaleJob
+	for _, job := range stale {
+		if job.StaleAt != "" {
+			continue
+		}
+		marked, err := w.store.MarkJobQueueStale(ctx, job.JobID)
+		if err != nil {
+			slog.Error("queue watch: flag stale job", "job", job.JobID, "err", err)
+			continue
+		}
+		if marked {
+			slog.Warn("job queued too long", "job", db.ShortID(job.JobID), "queued_at", job.QueuedAt, "reasons", strings.Join(job.Reasons, "; "))
+			flagged = append(flagged, job)
+		}
+	}
+	return flagged
+}
+
+// Run calls Check periodically until ctx is cancelled.
+func (w *Watcher) Run(ctx context.Context) {
+	slog.Info("queue watch starting", "stale_after", StaleAfter(w.cfg))
+	w.Check(ctx)
+
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.Check(ctx)
+		}
+	}
+}
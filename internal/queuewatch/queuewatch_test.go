@@ -0,0 +1,155 @@
+package queuewatch
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"autopr/internal/config"
+	"autopr/internal/db"
+)
+
+func seedQueuedJob(t *testing.T, store *db.Store, suffix string, eligible bool, queuedFor time.Duration) string {
+	t.Helper()
+	ctx := context.Background()
+	issueID, err := store.UpsertIssue(ctx, db.IssueUpsert{
+		ProjectName:   "proj",
+		Source:        "github",
+		SourceIssueID: suffix,
+		Title:         "queue " + suffix,
+		URL:           "https://github.com/acme/repo/issues/" + suffix,
+		State:         "open",
+		Eligible:      &eligible,
+		SkipReason:    "missing label autopr",
+	})
+	if err != nil {
+		t.Fatalf("upsert issue: %v", err)
+	}
+	jobID, err := store.CreateJob(ctx, issueID, "proj", 3)
+	if err != nil {
+		t.Fatalf("create job: %v", err)
+	}
+	at := time.Now().UTC().Add(-queuedFor).Format(time.RFC3339)
+	if _, err := store.Writer.ExecContext(ctx, `UPDATE jobs SET created_at = ?, updated_at = ? WHERE id = ?`, at, at, jobID); err != nil {
+		t.Fatalf("age job: %v", err)
+	}
+	return jobID
+}
+
+func newTestWatcher(t *testing.T) (*Watcher, *db.Store) {
+	t.Helper()
+	store, err := db.Open(filepath.Join(t.TempDir(), "autopr.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+
+	cfg := &config.Config{}
+	cfg.Daemon.QueueStaleAfter = "24h"
+	cfg.Daemon.MaxWorkers = 2
+	return New(cfg, store), store
+}
+
+func TestExplain(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		job           db.StaleQueuedJob
+		working       int
+		daemonRunning bool
+		disabled      bool
+		want          []string
+	}{
+		{
+			name:          "ineligible issue",
+			job:           db.StaleQueuedJob{SkipReason: "missing label autopr", Ahead: 3},
+			daemonRunning: true,
+			want:          []string{"issue is not eligible: missing label autopr"},
+		},
+		{
+			name:          "busy workers and older jobs",
+			job:           db.StaleQueuedJob{Claimable: true, Ahead: 2},
+			working:       2,
+			daemonRunning: true,
+			want:          []string{"all 2 workers are busy", "2 older job(s) of its project queued ahead"},
+		},
+		{
+			name:          "disabled project",
+			job:           db.StaleQueuedJob{ProjectName: "proj", Claimable: true},
+			daemonRunning: true,
+			disabled:      true,
+			want:          []string{"project proj is disabled"},
+		},
+		{
+			name: "daemon stopped",
+			job:  db.StaleQueuedJob{Claimable: true},
+			want: []string{"daemon is not running"},
+		},
+		{
+			name:          "no blocker",
+			job:           db.StaleQueuedJob{Claimable: true},
+			daemonRunning: true,
+			want:          []string{"no blocker found; check the daemon log for claim errors"},
+		},
+	}
+	for _, tt := range tests {
+		if got := Explain(tt.job, tt.working, 2, tt.daemonRunning, !tt.disabled); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: Explain() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCheckFlagsStaleJobsOnce(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	w, store := newTestWatcher(t)
+
+	oldest := seedQueuedJob(t, store, "1", false, 48*time.Hour)
+	stale := seedQueuedJob(t, store, "2", true, 30*time.Hour)
+	_ = seedQueuedJob(t, store, "3", true, time.Hour)
+
+	flagged := w.Check(ctx)
+	if len(flagged) != 2 || flagged[0].JobID != oldest || flagged[1].JobID != stale {
+		t.Fatalf("expected the two jobs queued over 24h flagged oldest first, got %+v", flagged)
+	}
+	if want := []string{"issue is not eligible: missing label autopr"}; !reflect.DeepEqual(flagged[0].Reasons, want) {
+		t.Fatalf("expected ineligible reason, got %q", flagged[0].Reasons)
+	}
+	if want := []string{"no blocker found; check the daemon log for claim errors"}; !reflect.DeepEqual(flagged[1].Reasons, want) {
+		t.Fatalf("expected no blocker, got %q", flagged[1].Reasons)
+	}
+
+	events, err := store.ListNotificationEvents(ctx, db.NotificationStatusPending, 0)
+	if err != nil {
+		t.Fatalf("list notification events: %v", err)
+	}
+	if len(events) != 2 || events[0].EventType != db.NotificationEventQueueStale {
+		t.Fatalf("expected two queue_stale events, got %+v", events)
+	}
+
+	if again := w.Check(ctx); len(again) != 0 {
+		t.Fatalf("expected no re-alerting, got %+v", again)
+	}
+	all, err := w.Stale(ctx)
+	if err != nil {
+		t.Fatalf("stale: %v", err)
+	}
+	if len(all) != 2 || all[0].StaleAt == "" {
+		t.Fatalf("expected flagged jobs to stay listed as stale, got %+v", all)
+	}
+}
+
+func TestStaleDisabled(t *testing.T) {
+	t.Parallel()
+	w, store := newTestWatcher(t)
+	w.cfg.Daemon.QueueStaleAfter = "0"
+	_ = seedQueuedJob(t, store, "1", true, 48*time.Hour)
+
+	stale, err := w.Stale(context.Background())
+	if err != nil || len(stale) != 0 {
+		t.Fatalf("expected no stale jobs when disabled, got %+v (err %v)", stale, err)
+	}
+}
@@ -0,0 +1,184 @@
+// Package recurring queues jobs for maintenance tasks configured under
+// [[projects.recurring]] (e.g. "weekly: bump dependencies").
+//
+// Each time a task's cron schedule fires, a synthetic issue (source
+// "recurring") and a job are created. If the job from the previous run is
+// still open — in flight, ready for review, or awaiting merge — the run is
+// skipped so at most one job per task is outstanding.
+package recurring
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"autopr/internal/config"
+	"autopr/internal/cron"
+	"autopr/internal/db"
+	"autopr/internal/policy"
+)
+
+// Source is the issue source used for synthetic recurring-task issues.
+const Source = "recurring"
+
+// maxCatchUpRuns bounds how many missed activations are skipped over when the
+// daemon was down; only the latest missed run is queued.
+const maxCatchUpRuns = 100000
+
+// Scheduler creates jobs for recurring tasks whose schedule has fired.
+type Scheduler struct {
+	cfg   *config.Config
+	store *db.Store
+	jobCh chan<- string
+	now   func() time.Time
+}
+
+func New(cfg *config.Config, store *db.Store, jobCh chan<- string) *Scheduler {
+	return &Scheduler{cfg: cfg, store: store, jobCh: jobCh, now: time.Now}
+}
+
+// RunDue queues a job for every recurring task that fired since its last run.
+func (s *Scheduler) RunDue(ctx context.Context) {
+	now := s.now().UTC()
+	overrides, err := s.store.ProjectEnabledOverrides(ctx)
+	if err != nil {
+		slog.Warn("recurring: load project overrides", "err", err)
+	}
+	for i := range s.cfg.Projects {
+		p := &s.cfg.Projects[i]
+		if !s.cfg.ProjectEnabled(p.Name, overrides) {
+			continue
+		}
+		for _, task := range p.Recurring {
+			if err := s.runTask(ctx, p, task, now); err != nil {
+				slog.Error("recurring: run task", "project", p.Name, "task", task.Name, "err", err)
+			}
+		}
+	}
+}
+
+func (s *Scheduler) runTask(ctx context.Context, p *config.ProjectConfig, task config.ProjectRecurringTask, now time.Time) error {
+	sched, err := cron.Parse(task.Schedule)
+	if err != nil {
+		return err
+	}
+
+	run, ok, err := s.store.GetRecurringRun(ctx, p.Name, task.Name)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		// First time this task is seen: start counting from now instead of
+		// firing immediately on daemon start.
+		return s.store.SetRecurringRun(ctx, p.Name, task.Name, now.Format(time.RFC3339), "")
+	}
+	last, err := time.Parse(time.RFC3339, run.LastRunAt)
+	if err != nil {
+		return fmt.Errorf("parse last run %q: %w", run.LastRunAt, err)
+	}
+
+	due := latestActivation(sched, last, now)
+	if due.IsZero() {
+		return nil
+	}
+	dueAt := due.Format(time.RFC3339)
+
+	if run.LastIssueID != "" {
+		activeID, err := s.store.GetActiveJobForIssue(ctx, run.LastIssueID)
+		if err != nil {
+			return err
+		}
+		if activeID != "" {
+			slog.Info("recurring: previous run still open, skipping",
+				"project", p.Name, "task", task.Name, "job", db.ShortID(activeID), "scheduled", dueAt)
+			return s.store.SetRecurringRun(ctx, p.Name, task.Name, dueAt, "")
+		}
+	}
+
+	issueID, err := s.store.UpsertIssue(ctx, db.IssueUpsert{
+		ProjectName:   p.Name,
+		Source:        Source,
+		SourceIssueID: task.Name + "-" + due.Format("20060102-1504"),
+		Title:         task.Title,
+		Body:          task.Body,
+		State:         "open",
+		SourceUpdated: dueAt,
+	})
+	if err != nil {
+		return err
+	}
+	jobID, err := policy.CreateJob(ctx, s.store, p, issueID, s.cfg.Daemon.MaxIterations)
+	if err != nil && !errors.Is(err, db.ErrDuplicateActiveJob) {
+		return err
+	}
+	if err := s.store.SetRecurringRun(ctx, p.Name, task.Name, dueAt, issueID); err != nil {
+		return err
+	}
+	if jobID == "" {
+		return nil
+	}
+
+	select {
+	case s.jobCh <- jobID:
+	default:
+		slog.Warn("recurring: job channel full", "job_id", jobID)
+	}
+	slog.Info("recurring: created job", "project", p.Name, "task", task.Name, "job", db.ShortID(jobID), "scheduled", dueAt)
+	return nil
+}
+
+// latestActivation returns the last activation of sched in (after, now], or
+// the zero time if the schedule has not fired since after.
+func latestActivation(sched cron.Schedule, after, now time.Time) time.Time {
+	var due time.Time
+	for range maxCatchUpRuns {
+		next := sched.Next(after)
+		if next.IsZero() || next.After(now) {
+			break
+		}
+		due, after = next, next
+	}
+	return due
+}
+
+// maxUpcomingRuns bounds how many future activations Upcoming lists per task.
+const maxUpcomingRuns = 1000
+
+// Activation is a future run of a recurring task.
+type Activation struct {
+	Project string
+	Task    config.ProjectRecurringTask
+	At      time.Time
+}
+
+// Upcoming lists the activations of every recurring task of an enabled
+// project in (from, until], in time order. A run is still skipped when it
+// fires while the previous run's job is open.
+func Upcoming(cfg *config.Config, overrides map[string]bool, from, until time.Time) []Activation {
+	var out []Activation
+	for _, p := range cfg.Projects {
+		if !cfg.ProjectEnabled(p.Name, overrides) {
+			continue
+		}
+		for _, task := range p.Recurring {
+			sched, err := cron.Parse(task.Schedule)
+			if err != nil {
+				continue
+			}
+			after := from
+			for range maxUpcomingRuns {
+				next := sched.Next(after)
+				if next.IsZero() || next.After(until) {
+					break
+				}
+				out = append(out, Activation{Project: p.Name, Task: task, At: next})
+				after = next
+			}
+		}
+	}
+	slices.SortStableFunc(out, func(a, b Activation) int { return a.At.Compare(b.At) })
+	return out
+}
@@ -0,0 +1,180 @@
+package recurring
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"autopr/internal/config"
+	"autopr/internal/db"
+)
+
+func newScheduler(t *testing.T, now *time.Time) (*Scheduler, *db.Store, chan string) {
+	t.Helper()
+	store, err := db.Open(filepath.Join(t.TempDir(), "autopr.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	cfg := &config.Config{
+		Daemon: config.DaemonConfig{MaxIterations: 3},
+		Projects: []config.ProjectConfig{{
+			Name: "myproject",
+			Recurring: []config.ProjectRecurringTask{{
+				Name:     "deps",
+				Schedule: "0 9 * * 1",
+				Title:    "Bump dependencies",
+				Body:     "Update all direct dependencies.",
+			}},
+		}},
+	}
+	jobCh := make(chan string, 10)
+	s := New(cfg, store, jobCh)
+	s.now = func() time.Time { return *now }
+	return s, store, jobCh
+}
+
+func TestRunDueCreatesJobOnSchedule(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	// Sunday 2026-03-01 12:00 UTC; next run is Monday 09:00.
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	s, store, jobCh := newScheduler(t, &now)
+
+	s.RunDue(ctx)
+	if len(jobCh) != 0 {
+		t.Fatalf("expected no job on first run, got %d", len(jobCh))
+	}
+
+	now = now.Add(12 * time.Hour)
+	s.RunDue(ctx)
+	if len(jobCh) != 0 {
+		t.Fatalf("expected no job before schedule, got %d", len(jobCh))
+	}
+
+	now = time.Date(2026, 3, 2, 9, 5, 0, 0, time.UTC)
+	s.RunDue(ctx)
+	if len(jobCh) != 1 {
+		t.Fatalf("expected 1 queued job, got %d", len(jobCh))
+	}
+	jobID := <-jobCh
+	job, err := store.GetJob(ctx, jobID)
+	if err != nil {
+		t.Fatalf("get job: %v", err)
+	}
+	issue, err := store.GetIssueByAPID(ctx, job.AutoPRIssueID)
+	if err != nil {
+		t.Fatalf("get issue: %v", err)
+	}
+	if issue.Source != Source || issue.SourceIssueID != "deps-20260302-0900" {
+		t.Fatalf("unexpected issue source %q/%q", issue.Source, issue.SourceIssueID)
+	}
+	if issue.Title != "Bump dependencies" || issue.Body != "Update all direct dependencies." {
+		t.Fatalf("unexpected issue content %q / %q", issue.Title, issue.Body)
+	}
+
+	// Running again within the same period does nothing.
+	s.RunDue(ctx)
+	if len(jobCh) != 0 {
+		t.Fatalf("expected no duplicate job, got %d", len(jobCh))
+	}
+}
+
+func TestRunDueSkipsWhilePreviousRunOpen(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	s, store, jobCh := newScheduler(t, &now)
+
+	s.RunDue(ctx)
+	now = time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	s.RunDue(ctx)
+	if len(jobCh) != 1 {
+		t.Fatalf("expected 1 queued job, got %d", len(jobCh))
+	}
+	firstID := <-jobCh
+
+	// The first job is still queued a week later: skip.
+	now = now.AddDate(0, 0, 7)
+	s.RunDue(ctx)
+	if len(jobCh) != 0 {
+		t.Fatalf("expected run to be skipped while previous job is open, got %d", len(jobCh))
+	}
+
+	if err := store.CancelJob(ctx, firstID); err != nil {
+		t.Fatalf("cancel job: %v", err)
+	}
+
+	// The skipped run is not replayed; the next one fires normally.
+	s.RunDue(ctx)
+	if len(jobCh) != 0 {
+		t.Fatalf("expected skipped run not to be replayed, got %d", len(jobCh))
+	}
+	now = now.AddDate(0, 0, 7)
+	s.RunDue(ctx)
+	if len(jobCh) != 1 {
+		t.Fatalf("expected job after previous run finished, got %d", len(jobCh))
+	}
+	if id := <-jobCh; id == firstID {
+		t.Fatalf("expected a new job, got the previous one")
+	}
+}
+
+func TestRunDueQueuesOnlyLatestMissedRun(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	s, store, jobCh := newScheduler(t, &now)
+
+	s.RunDue(ctx)
+	// Daemon was down for three weeks.
+	now = time.Date(2026, 3, 20, 12, 0, 0, 0, time.UTC)
+	s.RunDue(ctx)
+	if len(jobCh) != 1 {
+		t.Fatalf("expected 1 queued job, got %d", len(jobCh))
+	}
+	job, err := store.GetJob(ctx, <-jobCh)
+	if err != nil {
+		t.Fatalf("get job: %v", err)
+	}
+	issue, err := store.GetIssueByAPID(ctx, job.AutoPRIssueID)
+	if err != nil {
+		t.Fatalf("get issue: %v", err)
+	}
+	if issue.SourceIssueID != "deps-20260316-0900" {
+		t.Fatalf("expected latest missed run, got %q", issue.SourceIssueID)
+	}
+}
+
+func TestUpcomingListsRunsOfEnabledProjects(t *testing.T) {
+	t.Parallel()
+
+	cfg := &config.Config{Projects: []config.ProjectConfig{
+		{Name: "web", Recurring: []config.ProjectRecurringTask{
+			{Name: "deps", Schedule: "0 9 * * 1", Title: "Bump dependencies"},
+			{Name: "clients", Schedule: "0 6 1 * *", Title: "Regenerate clients"},
+		}},
+		{Name: "paused", Recurring: []config.ProjectRecurringTask{
+			{Name: "deps", Schedule: "@daily", Title: "Bump dependencies"},
+		}},
+	}}
+	// Sunday 2026-02-22 12:00 UTC through Sunday 2026-03-08 12:00 UTC.
+	from := time.Date(2026, 2, 22, 12, 0, 0, 0, time.UTC)
+	runs := Upcoming(cfg, map[string]bool{"paused": false}, from, from.AddDate(0, 0, 14))
+
+	var got []string
+	for _, r := range runs {
+		got = append(got, r.Project+"/"+r.Task.Name+"@"+r.At.Format(time.RFC3339))
+	}
+	want := []string{
+		"web/deps@2026-02-23T09:00:00Z",
+		"web/clients@2026-03-01T06:00:00Z",
+		"web/deps@2026-03-02T09:00:00Z",
+	}
+	if !slices.Equal(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
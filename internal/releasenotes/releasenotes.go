@@ -0,0 +1,176 @@
+// Package releasenotes assembles release notes from the AutoPR jobs whose PRs
+// merged since a release, grouped by the conventional commit type of each
+// change.
+package releasenotes
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	"autopr/internal/db"
+)
+
+// Groups of release notes, in the order they are rendered.
+const (
+	GroupFeatures = "Features"
+	GroupFixes    = "Fixes"
+	GroupChores   = "Chores"
+)
+
+var groupOrder = []string{GroupFeatures, GroupFixes, GroupChores}
+
+// conventionalRE matches a conventional commit title: "type(scope)!: text".
+var conventionalRE = regexp.MustCompile(`^([A-Za-z]+)(\([^)]*\))?!?:\s*(.+)$`)
+
+// typeGroups maps conventional commit types to groups.
+var typeGroups = map[string]string{
+	"feat": GroupFeatures, "feature": GroupFeatures,
+	"fix": GroupFixes, "bugfix": GroupFixes, "hotfix": GroupFixes, "perf": GroupFixes, "security": GroupFixes,
+	"chore": GroupChores, "docs": GroupChores, "refactor": GroupChores, "test": GroupChores, "tests": GroupChores,
+	"build": GroupChores, "ci": GroupChores, "style": GroupChores, "deps": GroupChores,
+}
+
+// labelGroups maps issue labels to groups, for titles without a type.
+var labelGroups = map[string]string{
+	"enhancement": GroupFeatures, "feature": GroupFeatures, "feature-request": GroupFeatures,
+	"bug": GroupFixes, "defect": GroupFixes, "regression": GroupFixes, "crash": GroupFixes, "security": GroupFixes,
+	"chore": GroupChores, "documentation": GroupChores, "docs": GroupChores, "maintenance": GroupChores,
+	"dependencies": GroupChores, "refactor": GroupChores, "tech-debt": GroupChores,
+}
+
+// Entry is one line of release notes.
+type Entry struct {
+	Group string `json:"group"`
+	Text  string `json:"text"`
+	PRURL string `json:"pr_url,omitempty"`
+}
+
+// Entries turns merged jobs into release-note entries. Backports are left
+// out, since the job they copied is listed already.
+func Entries(jobs []db.MergedJob) []Entry {
+	var out []Entry
+	for _, j := range jobs {
+		if j.BackportBranch != "" {
+			continue
+		}
+		group, text := classify(j)
+		out = append(out, Entry{Group: group, Text: text, PRURL: j.PRURL})
+	}
+	return out
+}
+
+// classify picks a job's group and line. The group comes from a
+// conventional commit type on the issue title or job summary, then from the
+// issue's labels; Sentry errors and reverts are fixes, as is anything else,
+// since AutoPR jobs start from issues. The line is the job's summary, or the
+// issue title when there is none.
+func classify(j db.MergedJob) (string, string) {
+	title, titleType := splitType(j.IssueTitle)
+	summary, summaryType := splitType(j.Summary)
+	text := summary
+	if text == "" {
+		text = title
+	}
+	if j.RevertCommit != "" {
+		return GroupFixes, "Revert " + strings.TrimPrefix(text, "Revert ")
+	}
+	for _, t := range []string{titleType, summaryType} {
+		if group, ok := typeGroups[t]; ok {
+			return group, text
+		}
+	}
+	for _, label := range j.Labels() {
+		if group, ok := labelGroups[strings.ToLower(strings.TrimSpace(label))]; ok {
+			return group, text
+		}
+	}
+	return GroupFixes, text
+}
+
+// splitType returns s without a known conventional commit prefix, and the
+// prefix's type ("" when s has none).
+func splitType(s string) (string, string) {
+	s = strings.TrimSpace(s)
+	m := conventionalRE.FindStringSubmatch(s)
+	if m == nil {
+		return s, ""
+	}
+	typ := strings.ToLower(m[1])
+	if _, ok := typeGroups[typ]; !ok {
+		return s, ""
+	}
+	return m[3], typ
+}
+
+// Render formats entries as a Markdown section: heading as a level-two
+// heading, then a level-three heading per group.
+func Render(heading string, entries []Entry) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "## %s\n", heading)
+	if len(entries) == 0 {
+		b.WriteString("\nNo changes.\n")
+		return b.String()
+	}
+	for _, group := range groupOrder {
+		i := slices.IndexFunc(entries, func(e Entry) bool { return e.Group == group })
+		if i < 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\n### %s\n\n", group)
+		for _, e := range entries[i:] {
+			if e.Group != group {
+				continue
+			}
+			b.WriteString("- " + e.Text)
+			if e.PRURL != "" {
+				fmt.Fprintf(&b, " ([%s](%s))", prRef(e.PRURL), e.PRURL)
+			}
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+// prRef names a PR by its number when its URL ends in one.
+func prRef(prURL string) string {
+	n := path.Base(strings.TrimRight(prURL, "/"))
+	if n != "" && strings.Trim(n, "0123456789") == "" {
+		if strings.Contains(prURL, "/merge_requests/") {
+			return "!" + n
+		}
+		return "#" + n
+	}
+	return "PR"
+}
+
+// PrependChangelog puts section at the top of a CHANGELOG.md, below its
+// level-one title and any text before its first release.
+func PrependChangelog(changelog, section string) string {
+	section = strings.TrimRight(section, "\n") + "\n"
+	if strings.TrimSpace(changelog) == "" {
+		return "# Changelog\n\n" + section
+	}
+	lines := strings.SplitAfter(changelog, "\n")
+	insert := 0
+	if strings.HasPrefix(lines[0], "# ") {
+		insert = len(lines)
+		for i := 1; i < len(lines); i++ {
+			if strings.HasPrefix(lines[i], "## ") {
+				insert = i
+				break
+			}
+		}
+	}
+	head := strings.Join(lines[:insert], "")
+	if head != "" && !strings.HasSuffix(head, "\n\n") {
+		head = strings.TrimRight(head, "\n") + "\n\n"
+	}
+	rest := strings.Join(lines[insert:], "")
+	if rest == "" {
+		return head + section
+	}
+	return head + section + "\n" + rest
+}
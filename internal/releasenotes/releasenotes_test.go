@@ -0,0 +1,86 @@
+package releasenotes
+
+import (
+	"testing"
+
+	"autopr/internal/db"
+)
+
+func TestEntriesGroupsByCommitTypeThenLabels(t *testing.T) {
+	t.Parallel()
+
+	jobs := []db.MergedJob{
+		{IssueTitle: "feat(api): add pagination to /users", PRURL: "https://github.com/acme/web/pull/12"},
+		{IssueTitle: "Login times out", Summary: "Raise the session timeout to 30 minutes", LabelsJSON: `["bug"]`, PRURL: "https://github.com/acme/web/pull/13"},
+		{IssueTitle: "Update README badges", LabelsJSON: `["Documentation"]`},
+		{IssueTitle: "Dark mode", LabelsJSON: `["enhancement"]`, PRURL: "https://gitlab.com/acme/web/-/merge_requests/7"},
+		{IssueTitle: "TypeError in checkout", IssueSource: "sentry"},
+		{IssueTitle: "Login times out", RevertCommit: "abc123"},
+		{IssueTitle: "Login times out", BackportBranch: "release-1.2"},
+		{IssueTitle: "Note: the cache is slow", Summary: "chore: drop the unused cache layer"},
+	}
+	got := Entries(jobs)
+	want := []Entry{
+		{Group: GroupFeatures, Text: "add pagination to /users", PRURL: "https://github.com/acme/web/pull/12"},
+		{Group: GroupFixes, Text: "Raise the session timeout to 30 minutes", PRURL: "https://github.com/acme/web/pull/13"},
+		{Group: GroupChores, Text: "Update README badges"},
+		{Group: GroupFeatures, Text: "Dark mode", PRURL: "https://gitlab.com/acme/web/-/merge_requests/7"},
+		{Group: GroupFixes, Text: "TypeError in checkout"},
+		{Group: GroupFixes, Text: "Revert Login times out"},
+		{Group: GroupChores, Text: "drop the unused cache layer"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d entries, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("entry %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestRender(t *testing.T) {
+	t.Parallel()
+
+	got := Render("v1.3.0 (2026-10-18)", []Entry{
+		{Group: GroupFixes, Text: "Fix login", PRURL: "https://github.com/acme/web/pull/13"},
+		{Group: GroupFeatures, Text: "Dark mode", PRURL: "https://gitlab.com/acme/web/-/merge_requests/7"},
+		{Group: GroupFixes, Text: "Fix checkout"},
+	})
+	want := `## v1.3.0 (2026-10-18)
+
+### Features
+
+- Dark mode ([!7](https://gitlab.com/acme/web/-/merge_requests/7))
+
+### Fixes
+
+- Fix login ([#13](https://github.com/acme/web/pull/13))
+- Fix checkout
+`
+	if got != want {
+		t.Fatalf("Render =\n%s\nwant\n%s", got, want)
+	}
+	if got := Render("Unreleased", nil); got != "## Unreleased\n\nNo changes.\n" {
+		t.Fatalf("empty Render = %q", got)
+	}
+}
+
+func TestPrependChangelog(t *testing.T) {
+	t.Parallel()
+
+	section := "## v1.3.0\n\n### Fixes\n\n- Fix login\n"
+	for name, tc := range map[string]struct{ in, want string }{
+		"empty": {"", "# Changelog\n\n" + section},
+		"title and releases": {
+			"# Changelog\n\nAll notable changes.\n\n## v1.2.0\n\n- Old\n",
+			"# Changelog\n\nAll notable changes.\n\n" + section + "\n## v1.2.0\n\n- Old\n",
+		},
+		"title only": {"# Changelog\n", "# Changelog\n\n" + section},
+		"no title":   {"## v1.2.0\n\n- Old\n", section + "\n## v1.2.0\n\n- Old\n"},
+	} {
+		if got := PrependChangelog(tc.in, section); got != tc.want {
+			t.Errorf("%s: got\n%q\nwant\n%q", name, got, tc.want)
+		}
+	}
+}
@@ -22,6 +22,8 @@ const (
 	launchdDefaultPathEnv = "/usr/local/bin:/opt/homebrew/bin:/usr/bin:/bin:/usr/sbin:/sbin"
 )
 
+// ServiceStatus describes the installed service. PlistPath is the launchd
+// plist on macOS and the Task Scheduler script on Windows.
 type ServiceStatus struct {
 	Label     string `json:"label"`
 	PlistPath string `json:"plist_path"`
@@ -49,18 +51,9 @@ func Install(cfg *config.Config, resolvedConfigPath string) error {
 		return fmt.Errorf("missing config path")
 	}
 
-	exePath, err := resolveExePath()
+	exePath, resolvedConfigPath, err := absExeAndConfigPath(resolvedConfigPath)
 	if err != nil {
-		return fmt.Errorf("resolve executable: %w", err)
-	}
-	exePath, err = filepath.Abs(exePath)
-	if err != nil {
-		return fmt.Errorf("resolve executable absolute path: %w", err)
-	}
-
-	resolvedConfigPath, err = filepath.Abs(resolvedConfigPath)
-	if err != nil {
-		return fmt.Errorf("resolve config absolute path: %w", err)
+		return err
 	}
 
 	plistPath, err := PlistPath()
@@ -155,6 +148,24 @@ func Status(cfg *config.Config) (ServiceStatus, error) {
 	return status, nil
 }
 
+// absExeAndConfigPath returns absolute paths to the running ap binary and
+// the config file for a service definition.
+func absExeAndConfigPath(configPath string) (string, string, error) {
+	exePath, err := resolveExePath()
+	if err != nil {
+		return "", "", fmt.Errorf("resolve executable: %w", err)
+	}
+	exePath, err = filepath.Abs(exePath)
+	if err != nil {
+		return "", "", fmt.Errorf("resolve executable absolute path: %w", err)
+	}
+	configPath, err = filepath.Abs(configPath)
+	if err != nil {
+		return "", "", fmt.Errorf("resolve config absolute path: %w", err)
+	}
+	return exePath, configPath, nil
+}
+
 func PlistPath() (string, error) {
 	home, err := resolveHomeDir()
 	if err != nil {
@@ -0,0 +1,168 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"autopr/internal/config"
+)
+
+// TaskName is the Windows Task Scheduler task that runs the daemon.
+const TaskName = "autopr-daemon"
+
+var runSchtasksCmd = runSchtasks
+
+// InstallTask registers a Task Scheduler task that starts the daemon at logon
+// and starts it now. Task Scheduler neither redirects output nor restarts a
+// task that exits, so the task runs a script that does both, like launchd's
+// StandardOutPath and KeepAlive.
+func InstallTask(cfg *config.Config, resolvedConfigPath string) error {
+	if cfg == nil {
+		return fmt.Errorf("missing config")
+	}
+	if resolvedConfigPath == "" {
+		return fmt.Errorf("missing config path")
+	}
+
+	exePath, resolvedConfigPath, err := absExeAndConfigPath(resolvedConfigPath)
+	if err != nil {
+		return err
+	}
+
+	scriptPath, err := TaskScriptPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(scriptPath), 0o755); err != nil {
+		return fmt.Errorf("create task script dir: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(cfg.LogFile), 0o755); err != nil {
+		return fmt.Errorf("create log dir: %w", err)
+	}
+	if err := writeFileAtomic(scriptPath, renderTaskScript(exePath, resolvedConfigPath, cfg.LogFile), 0o644); err != nil {
+		return fmt.Errorf("write task script: %w", err)
+	}
+
+	if out, err := runSchtasksCmd("/Create", "/F", "/TN", TaskName, "/SC", "ONLOGON", "/RL", "LIMITED", "/TR", `"`+scriptPath+`"`); err != nil {
+		return fmt.Errorf("schtasks /Create %s: %w: %s", TaskName, err, strings.TrimSpace(out))
+	}
+	if out, err := runSchtasksCmd("/Run", "/TN", TaskName); err != nil {
+		return fmt.Errorf("schtasks /Run %s: %w: %s", TaskName, err, strings.TrimSpace(out))
+	}
+	return nil
+}
+
+// UninstallTask ends and deletes the task and removes its script. A daemon
+// already started by the task keeps running until `ap stop`.
+func UninstallTask() error {
+	scriptPath, err := TaskScriptPath()
+	if err != nil {
+		return err
+	}
+
+	// Best effort: the task may not be running.
+	_, _ = runSchtasksCmd("/End", "/TN", TaskName)
+
+	if out, err := runSchtasksCmd("/Delete", "/F", "/TN", TaskName); err != nil && !isSchtasksNotFound(out, err) {
+		return fmt.Errorf("schtasks /Delete %s: %w: %s", TaskName, err, strings.TrimSpace(out))
+	}
+	if err := os.Remove(scriptPath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("remove task script %s: %w", scriptPath, err)
+	}
+	return nil
+}
+
+// TaskStatus reports whether the task is installed and registered, and
+// whether the daemon runs. The PID comes from the daemon's PID file, since the
+// task's own process is the wrapper script.
+func TaskStatus(cfg *config.Config) (ServiceStatus, error) {
+	scriptPath, err := TaskScriptPath()
+	if err != nil {
+		return ServiceStatus{}, err
+	}
+
+	status := ServiceStatus{
+		Label:     TaskName,
+		PlistPath: scriptPath,
+	}
+
+	if _, err := os.Stat(scriptPath); err == nil {
+		status.Installed = true
+	} else if !os.IsNotExist(err) {
+		return status, fmt.Errorf("stat task script %s: %w", scriptPath, err)
+	}
+
+	out, err := runSchtasksCmd("/Query", "/TN", TaskName, "/FO", "LIST")
+	if err != nil {
+		if isSchtasksNotFound(out, err) {
+			applyPIDFallback(cfg, &status)
+			return status, nil
+		}
+		return status, fmt.Errorf("schtasks /Query %s: %w: %s", TaskName, err, strings.TrimSpace(out))
+	}
+
+	status.Loaded = true
+	status.Running = parseSchtasksQuery(out)
+	applyPIDFallback(cfg, &status)
+	return status, nil
+}
+
+// TaskScriptPath is where InstallTask writes the script the task runs.
+func TaskScriptPath() (string, error) {
+	home, err := resolveHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("resolve home directory: %w", err)
+	}
+	return filepath.Join(home, "AppData", "Local", "autopr", TaskName+".cmd"), nil
+}
+
+// renderTaskScript returns a batch script that runs the daemon in the
+// foreground with its output appended to logPath, and restarts it after
+// launchdThrottleSecs whenever it exits.
+func renderTaskScript(exePath, configPath, logPath string) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("@echo off\r\n")
+	buf.WriteString("rem Written by `ap service install`. Restarts the daemon when it exits.\r\n")
+	buf.WriteString("set AUTOPR_SKIP_UPDATE_NOTICE=1\r\n")
+	buf.WriteString(":run\r\n")
+	fmt.Fprintf(&buf, "%s start --foreground --config %s >> %s 2>&1\r\n", batchQuote(exePath), batchQuote(configPath), batchQuote(logPath))
+	// timeout fails without console input; ping is the usual batch sleep.
+	fmt.Fprintf(&buf, "ping -n %d 127.0.0.1 >nul\r\n", launchdThrottleSecs+1)
+	buf.WriteString("goto run\r\n")
+	return buf.Bytes()
+}
+
+// batchQuote quotes a path for a batch script. Windows paths cannot contain
+// '"', but '%' must be doubled so it is not expanded as a variable.
+func batchQuote(s string) string {
+	return `"` + strings.ReplaceAll(s, "%", "%%") + `"`
+}
+
+func parseSchtasksQuery(out string) bool {
+	for line := range strings.SplitSeq(out, "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if ok && strings.TrimSpace(key) == "Status" {
+			return strings.EqualFold(strings.TrimSpace(value), "Running")
+		}
+	}
+	return false
+}
+
+func isSchtasksNotFound(out string, err error) bool {
+	if err == nil {
+		return false
+	}
+	text := strings.ToLower(out + "\n" + err.Error())
+	return strings.Contains(text, "cannot find the file specified") ||
+		strings.Contains(text, "does not exist")
+}
+
+func runSchtasks(args ...string) (string, error) {
+	cmd := exec.Command("schtasks", args...)
+	out, err := cmd.CombinedOutput()
+	return string(out), err
+}
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"autopr/internal/config"
+)
+
+func TestRenderTaskScriptRestartsDaemonWithLog(t *testing.T) {
+	t.Parallel()
+
+	script := string(renderTaskScript(`C:\Program Files\ap\ap.exe`, `C:\Users\dev\100%\config.toml`, `C:\Users\dev\autopr.log`))
+	for _, want := range []string{
+		"set AUTOPR_SKIP_UPDATE_NOTICE=1\r\n",
+		":run\r\n",
+		`"C:\Program Files\ap\ap.exe" start --foreground --config "C:\Users\dev\100%%\config.toml" >> "C:\Users\dev\autopr.log" 2>&1` + "\r\n",
+		"ping -n 11 127.0.0.1 >nul\r\n",
+		"goto run\r\n",
+	} {
+		if !strings.Contains(script, want) {
+			t.Fatalf("missing %q in script:\n%s", want, script)
+		}
+	}
+}
+
+func TestInstallTaskWritesScriptAndRunsSchtasks(t *testing.T) {
+	tmp := t.TempDir()
+	home := filepath.Join(tmp, "home")
+
+	prevRun := runSchtasksCmd
+	prevExe := resolveExePath
+	prevHome := resolveHomeDir
+	t.Cleanup(func() {
+		runSchtasksCmd = prevRun
+		resolveExePath = prevExe
+		resolveHomeDir = prevHome
+	})
+
+	resolveExePath = func() (string, error) { return filepath.Join(tmp, "bin", "ap.exe"), nil }
+	resolveHomeDir = func() (string, error) { return home, nil }
+
+	var calls [][]string
+	runSchtasksCmd = func(args ...string) (string, error) {
+		calls = append(calls, append([]string(nil), args...))
+		if args[0] == "/Delete" {
+			return "ERROR: The system cannot find the file specified.", errors.New("exit status 1")
+		}
+		return "", nil
+	}
+
+	cfg := &config.Config{LogFile: filepath.Join(tmp, "state", "autopr.log")}
+	if err := InstallTask(cfg, "config.toml"); err != nil {
+		t.Fatalf("install task: %v", err)
+	}
+	scriptPath := filepath.Join(home, "AppData", "Local", "autopr", "autopr-daemon.cmd")
+	data, err := os.ReadFile(scriptPath)
+	if err != nil {
+		t.Fatalf("read task script: %v", err)
+	}
+	if !strings.Contains(string(data), `"`+filepath.Join(tmp, "bin", "ap.exe")+`" start --foreground`) {
+		t.Fatalf("script missing executable: %s", data)
+	}
+
+	if err := UninstallTask(); err != nil {
+		t.Fatalf("uninstall task: %v", err)
+	}
+	if _, err := os.Stat(scriptPath); !os.IsNotExist(err) {
+		t.Fatalf("expected task script removed, err=%v", err)
+	}
+
+	expected := [][]string{
+		{"/Create", "/F", "/TN", "autopr-daemon", "/SC", "ONLOGON", "/RL", "LIMITED", "/TR", `"` + scriptPath + `"`},
+		{"/Run", "/TN", "autopr-daemon"},
+		{"/End", "/TN", "autopr-daemon"},
+		{"/Delete", "/F", "/TN", "autopr-daemon"},
+	}
+	if !reflect.DeepEqual(expected, calls) {
+		t.Fatalf("unexpected schtasks calls:\nwant: %#v\ngot:  %#v", expected, calls)
+	}
+}
+
+func TestTaskStatusParsesQuery(t *testing.T) {
+	tmp := t.TempDir()
+
+	prevRun := runSchtasksCmd
+	prevHome := resolveHomeDir
+	t.Cleanup(func() {
+		runSchtasksCmd = prevRun
+		resolveHomeDir = prevHome
+	})
+	resolveHomeDir = func() (string, error) { return tmp, nil }
+
+	runSchtasksCmd = func(args ...string) (string, error) {
+		return "Folder: \\\r\nHostName:      DEV\r\nTaskName:      \\autopr-daemon\r\nStatus:        Running\r\n", nil
+	}
+	status, err := TaskStatus(&config.Config{})
+	if err != nil {
+		t.Fatalf("task status: %v", err)
+	}
+	if status.Label != TaskName || !status.Loaded || !status.Running || status.Installed {
+		t.Fatalf("unexpected status: %+v", status)
+	}
+
+	runSchtasksCmd = func(args ...string) (string, error) {
+		return "ERROR: The system cannot find the file specified.", errors.New("exit status 1")
+	}
+	status, err = TaskStatus(&config.Config{})
+	if err != nil {
+		t.Fatalf("task status when not registered: %v", err)
+	}
+	if status.Loaded || status.Running {
+		t.Fatalf("unexpected status: %+v", status)
+	}
+}
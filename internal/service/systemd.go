@@ -0,0 +1,208 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"autopr/internal/config"
+)
+
+const (
+	// SystemdUnit is the systemd user unit that runs the daemon.
+	SystemdUnit           = "autopr.service"
+	systemdDefaultPathEnv = "/usr/local/bin:/usr/bin:/bin"
+)
+
+var (
+	runSystemctlCmd     = runSystemctl
+	resolveXDGConfigDir = func() string { return os.Getenv("XDG_CONFIG_HOME") }
+)
+
+// InstallUnit writes a systemd user unit for the daemon, then enables and
+// (re)starts it so an updated unit takes effect.
+func InstallUnit(cfg *config.Config, resolvedConfigPath string) error {
+	if cfg == nil {
+		return fmt.Errorf("missing config")
+	}
+	if resolvedConfigPath == "" {
+		return fmt.Errorf("missing config path")
+	}
+
+	exePath, resolvedConfigPath, err := absExeAndConfigPath(resolvedConfigPath)
+	if err != nil {
+		return err
+	}
+
+	unitPath, err := UnitPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(unitPath), 0o755); err != nil {
+		return fmt.Errorf("create systemd unit dir: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(cfg.LogFile), 0o755); err != nil {
+		return fmt.Errorf("create log dir: %w", err)
+	}
+	unit := renderSystemdUnit(exePath, resolvedConfigPath, cfg.LogFile, systemdPathEnv(resolvePathEnv()))
+	if err := writeFileAtomic(unitPath, unit, 0o644); err != nil {
+		return fmt.Errorf("write systemd unit: %w", err)
+	}
+
+	for _, args := range [][]string{
+		{"daemon-reload"},
+		{"enable", SystemdUnit},
+		{"restart", SystemdUnit},
+	} {
+		if out, err := runSystemctlCmd(args...); err != nil {
+			return fmt.Errorf("systemctl --user %s: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(out))
+		}
+	}
+	return nil
+}
+
+// UninstallUnit stops and disables the unit and removes its file.
+func UninstallUnit() error {
+	unitPath, err := UnitPath()
+	if err != nil {
+		return err
+	}
+
+	if out, err := runSystemctlCmd("disable", "--now", SystemdUnit); err != nil && !isSystemdNotLoaded(out, err) {
+		return fmt.Errorf("systemctl --user disable --now %s: %w: %s", SystemdUnit, err, strings.TrimSpace(out))
+	}
+	if err := os.Remove(unitPath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("remove systemd unit %s: %w", unitPath, err)
+	}
+	// Best effort: forget the removed unit.
+	_, _ = runSystemctlCmd("daemon-reload")
+	return nil
+}
+
+// UnitStatus reports whether the unit is installed, loaded by systemd, and
+// running.
+func UnitStatus(cfg *config.Config) (ServiceStatus, error) {
+	unitPath, err := UnitPath()
+	if err != nil {
+		return ServiceStatus{}, err
+	}
+
+	status := ServiceStatus{
+		Label:     SystemdUnit,
+		PlistPath: unitPath,
+	}
+
+	if _, err := os.Stat(unitPath); err == nil {
+		status.Installed = true
+	} else if !os.IsNotExist(err) {
+		return status, fmt.Errorf("stat systemd unit %s: %w", unitPath, err)
+	}
+
+	out, err := runSystemctlCmd("show", SystemdUnit, "--property=LoadState,ActiveState,MainPID")
+	if err != nil {
+		return status, fmt.Errorf("systemctl --user show %s: %w: %s", SystemdUnit, err, strings.TrimSpace(out))
+	}
+	status.Loaded, status.Running, status.PID = parseSystemctlShow(out)
+	if !status.Running || status.PID == 0 {
+		applyPIDFallback(cfg, &status)
+	}
+	return status, nil
+}
+
+// UnitPath is where InstallUnit writes the systemd user unit.
+func UnitPath() (string, error) {
+	base := resolveXDGConfigDir()
+	if base == "" {
+		home, err := resolveHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("resolve home directory: %w", err)
+		}
+		base = filepath.Join(home, ".config")
+	}
+	return filepath.Join(base, "systemd", "user", SystemdUnit), nil
+}
+
+func systemdPathEnv(current string) string {
+	current = strings.TrimSpace(current)
+	if current == "" {
+		return systemdDefaultPathEnv
+	}
+	return current
+}
+
+// renderSystemdUnit returns a unit that runs the daemon in the foreground,
+// appends its output to logPath, and restarts it launchdThrottleSecs after it
+// exits.
+func renderSystemdUnit(exePath, configPath, logPath, pathEnv string) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("# Written by `ap service install`.\n")
+	buf.WriteString("[Unit]\n")
+	buf.WriteString("Description=AutoPR daemon\n")
+	buf.WriteString("\n[Service]\n")
+	buf.WriteString("Type=simple\n")
+	fmt.Fprintf(&buf, "ExecStart=%s start --foreground --config %s\n", systemdQuote(exePath), systemdQuote(configPath))
+	buf.WriteString("Restart=always\n")
+	fmt.Fprintf(&buf, "RestartSec=%d\n", launchdThrottleSecs)
+	fmt.Fprintf(&buf, "Environment=%s\n", systemdQuote("PATH="+pathEnv))
+	buf.WriteString("Environment=AUTOPR_SKIP_UPDATE_NOTICE=1\n")
+	fmt.Fprintf(&buf, "StandardOutput=append:%s\n", systemdEscapeSpecifiers(logPath))
+	fmt.Fprintf(&buf, "StandardError=append:%s\n", systemdEscapeSpecifiers(logPath))
+	buf.WriteString("\n[Install]\n")
+	buf.WriteString("WantedBy=default.target\n")
+	return buf.Bytes()
+}
+
+// systemdQuote double-quotes s for ExecStart= and Environment= lines.
+func systemdQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return `"` + systemdEscapeSpecifiers(s) + `"`
+}
+
+// systemdEscapeSpecifiers doubles '%' so systemd does not expand it as a
+// specifier such as %h.
+func systemdEscapeSpecifiers(s string) string {
+	return strings.ReplaceAll(s, "%", "%%")
+}
+
+// parseSystemctlShow reads `systemctl show` key=value output.
+func parseSystemctlShow(out string) (loaded, running bool, pid int) {
+	for line := range strings.SplitSeq(out, "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "LoadState":
+			loaded = value == "loaded"
+		case "ActiveState":
+			running = value == "active" || value == "activating"
+		case "MainPID":
+			pid, _ = strconv.Atoi(value)
+		}
+	}
+	if pid == 0 {
+		running = false
+	}
+	return loaded, running, pid
+}
+
+func isSystemdNotLoaded(out string, err error) bool {
+	if err == nil {
+		return false
+	}
+	text := strings.ToLower(out + "\n" + err.Error())
+	return strings.Contains(text, "not loaded") ||
+		strings.Contains(text, "does not exist") ||
+		strings.Contains(text, "not found")
+}
+
+func runSystemctl(args ...string) (string, error) {
+	cmd := exec.Command("systemctl", append([]string{"--user"}, args...)...)
+	out, err := cmd.CombinedOutput()
+	return string(out), err
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"autopr/internal/config"
+)
+
+func TestRenderSystemdUnitIncludesRequiredFields(t *testing.T) {
+	t.Parallel()
+
+	unit := string(renderSystemdUnit("/opt/ap bin/ap", "/home/dev/100%/config.toml", "/home/dev/autopr.log", "/usr/bin:/bin"))
+	for _, want := range []string{
+		"[Service]\n",
+		`ExecStart="/opt/ap bin/ap" start --foreground --config "/home/dev/100%%/config.toml"` + "\n",
+		"Restart=always\n",
+		"RestartSec=10\n",
+		`Environment="PATH=/usr/bin:/bin"` + "\n",
+		"Environment=AUTOPR_SKIP_UPDATE_NOTICE=1\n",
+		"StandardOutput=append:/home/dev/autopr.log\n",
+		"StandardError=append:/home/dev/autopr.log\n",
+		"WantedBy=default.target\n",
+	} {
+		if !strings.Contains(unit, want) {
+			t.Fatalf("missing %q in unit:\n%s", want, unit)
+		}
+	}
+}
+
+func TestInstallUnitWritesUnitAndRunsSystemctl(t *testing.T) {
+	tmp := t.TempDir()
+
+	prevRun := runSystemctlCmd
+	prevExe := resolveExePath
+	prevXDG := resolveXDGConfigDir
+	prevPATH := resolvePathEnv
+	t.Cleanup(func() {
+		runSystemctlCmd = prevRun
+		resolveExePath = prevExe
+		resolveXDGConfigDir = prevXDG
+		resolvePathEnv = prevPATH
+	})
+
+	resolveExePath = func() (string, error) { return filepath.Join(tmp, "bin", "ap"), nil }
+	resolveXDGConfigDir = func() string { return filepath.Join(tmp, "xdg") }
+	resolvePathEnv = func() string { return "/custom/bin" }
+
+	var calls [][]string
+	runSystemctlCmd = func(args ...string) (string, error) {
+		calls = append(calls, append([]string(nil), args...))
+		if args[0] == "disable" {
+			return "Failed to disable unit: Unit file autopr.service does not exist.", errors.New("exit status 1")
+		}
+		return "", nil
+	}
+
+	cfg := &config.Config{LogFile: filepath.Join(tmp, "state", "autopr.log")}
+	if err := InstallUnit(cfg, "config.toml"); err != nil {
+		t.Fatalf("install unit: %v", err)
+	}
+	unitPath := filepath.Join(tmp, "xdg", "systemd", "user", "autopr.service")
+	data, err := os.ReadFile(unitPath)
+	if err != nil {
+		t.Fatalf("read unit: %v", err)
+	}
+	if !strings.Contains(string(data), `ExecStart="`+filepath.Join(tmp, "bin", "ap")+`" start --foreground`) ||
+		!strings.Contains(string(data), `Environment="PATH=/custom/bin"`) {
+		t.Fatalf("unexpected unit:\n%s", data)
+	}
+
+	if err := UninstallUnit(); err != nil {
+		t.Fatalf("uninstall unit: %v", err)
+	}
+	if _, err := os.Stat(unitPath); !os.IsNotExist(err) {
+		t.Fatalf("expected unit removed, err=%v", err)
+	}
+
+	expected := [][]string{
+		{"daemon-reload"},
+		{"enable", "autopr.service"},
+		{"restart", "autopr.service"},
+		{"disable", "--now", "autopr.service"},
+		{"daemon-reload"},
+	}
+	if !reflect.DeepEqual(expected, calls) {
+		t.Fatalf("unexpected systemctl calls:\nwant: %#v\ngot:  %#v", expected, calls)
+	}
+}
+
+func TestParseSystemctlShow(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		out            string
+		loaded, active bool
+		pid            int
+	}{
+		{"LoadState=loaded\nActiveState=active\nMainPID=4242\n", true, true, 4242},
+		{"LoadState=loaded\nActiveState=activating\nMainPID=0\n", true, false, 0},
+		{"LoadState=not-found\nActiveState=inactive\nMainPID=0\n", false, false, 0},
+	} {
+		loaded, active, pid := parseSystemctlShow(tc.out)
+		if loaded != tc.loaded || active != tc.active || pid != tc.pid {
+			t.Fatalf("parseSystemctlShow(%q) = %t, %t, %d", tc.out, loaded, active, pid)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package stack
+
+import (
+	"fmt"
+	"strings"
+)
+
+// languageGuidance is the advice prompts get for changes in each language.
+var languageGuidance = map[string]string{
+	"Go":         "Format changed files with gofmt. Return errors wrapped with context instead of panicking, and document exported identifiers.",
+	"TypeScript": "Keep the code type-safe: no `any`, casts, or non-null assertions to silence the compiler. Follow the existing module and import style.",
+	"JavaScript": "Follow the module system (ESM or CommonJS) and style the code already uses. Do not add a dependency for a small helper.",
+	"Python":     "Follow PEP 8 and the existing type hints, and add hints to new functions. Prefer the standard library to new dependencies.",
+	"Rust":       "Keep the code free of clippy warnings. Propagate errors with ? instead of unwrap() or expect() outside tests.",
+	"Ruby":       "Follow the existing style (RuboCop, if configured). Keep methods small and tests next to the code they cover.",
+	"Java":       "Follow the existing package layout and null handling, and stay within the Java version the build targets.",
+	"Kotlin":     "Prefer val and non-null types, and follow the existing coroutine and package conventions.",
+	"C":          "Check every allocation and return value, free what you allocate, and bound every buffer write.",
+	"C++":        "Prefer RAII and standard containers to manual memory management, within the C++ standard the build uses.",
+	"C#":         "Respect the project's nullable reference type settings, and use async/await throughout rather than blocking on tasks.",
+	"PHP":        "Follow PSR-12 and the existing type declarations, keeping strict_types where files declare it.",
+	"Swift":      "Prefer value types and optional binding to force unwraps, and follow the existing concurrency model.",
+	"Elixir":     "Return and pattern match on {:ok, _} and {:error, _} tuples, as the code does.",
+	"Scala":      "Prefer immutable values and the effect or Future style the code already uses; avoid null.",
+	"Dart":       "Keep null safety intact: no ! to silence the analyzer. Follow the existing state management patterns.",
+}
+
+// Guidance returns a <project_stack> block describing s for prompts, with
+// advice for each of its languages, or "" when nothing was detected.
+func Guidance(s Stack) string {
+	if len(s.Languages) == 0 && len(s.Frameworks) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("<project_stack>\n")
+	if len(s.Languages) > 0 {
+		fmt.Fprintf(&b, "Languages: %s\n", strings.Join(s.Languages, ", "))
+	}
+	if len(s.Frameworks) > 0 {
+		fmt.Fprintf(&b, "Frameworks: %s\n", strings.Join(s.Frameworks, ", "))
+	}
+	var advice []string
+	for _, lang := range s.Languages {
+		if g, ok := languageGuidance[lang]; ok {
+			advice = append(advice, "- "+lang+": "+g)
+		}
+	}
+	if len(advice) > 0 {
+		b.WriteString("\nFollow these conventions for the languages you change:\n")
+		b.WriteString(strings.Join(advice, "\n"))
+		b.WriteString("\n")
+	}
+	b.WriteString("</project_stack>")
+	return b.String()
+}
@@ -0,0 +1,287 @@
+// Package stack detects the languages, frameworks, and build tools of a
+// repository from its files.
+//
+// The sync loop records what it detects for each project (see
+// pipeline.SyncProjectStack). The pipeline uses it to add language-specific
+// guidance to prompts, and in place of test_cmd or lint_cmd = "auto".
+package stack
+
+import (
+	"bufio"
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Stack is what was detected about a repository.
+type Stack struct {
+	Languages  []string `json:"languages"`  // most source files first
+	Frameworks []string `json:"frameworks"` // e.g. "React", "Django"
+	TestCmd    string   `json:"test_cmd"`   // suggested test command; "" when none is recognised
+	LintCmd    string   `json:"lint_cmd"`   // suggested lint command; "" when none is recognised
+}
+
+// Empty reports whether nothing was detected.
+func (s Stack) Empty() bool {
+	return len(s.Languages) == 0 && len(s.Frameworks) == 0 && s.TestCmd == "" && s.LintCmd == ""
+}
+
+// languageExtensions maps source file extensions to their language.
+var languageExtensions = map[string]string{
+	".go":    "Go",
+	".ts":    "TypeScript",
+	".tsx":   "TypeScript",
+	".js":    "JavaScript",
+	".jsx":   "JavaScript",
+	".mjs":   "JavaScript",
+	".py":    "Python",
+	".rs":    "Rust",
+	".rb":    "Ruby",
+	".java":  "Java",
+	".kt":    "Kotlin",
+	".swift": "Swift",
+	".c":     "C",
+	".h":     "C",
+	".cc":    "C++",
+	".cpp":   "C++",
+	".hpp":   "C++",
+	".cs":    "C#",
+	".php":   "PHP",
+	".ex":    "Elixir",
+	".exs":   "Elixir",
+	".scala": "Scala",
+	".dart":  "Dart",
+}
+
+// skippedDirs hold vendored or generated code, which says nothing about the
+// languages a project is written in.
+var skippedDirs = []string{".git", "node_modules", "vendor", "third_party", "dist", "build", "target", ".venv", "venv", "__pycache__"}
+
+// minLanguageShare is the share of source files below which a language is
+// left out, so a handful of scripts do not count as a project language.
+const minLanguageShare = 0.1
+
+// frameworks maps, per manifest, a dependency name to the framework it
+// stands for.
+var frameworks = map[string]map[string]string{
+	"package.json": {
+		"react":         "React",
+		"next":          "Next.js",
+		"vue":           "Vue",
+		"@angular/core": "Angular",
+		"svelte":        "Svelte",
+		"express":       "Express",
+	},
+	"go.mod": {
+		"github.com/gin-gonic/gin":           "Gin",
+		"github.com/labstack/echo":           "Echo",
+		"github.com/gofiber/fiber":           "Fiber",
+		"github.com/spf13/cobra":             "Cobra",
+		"google.golang.org/grpc":             "gRPC",
+		"github.com/go-chi/chi":              "chi",
+		"gorm.io/gorm":                       "GORM",
+		"github.com/charmbracelet/bubbletea": "Bubble Tea",
+	},
+	"python": {
+		"django":  "Django",
+		"flask":   "Flask",
+		"fastapi": "FastAPI",
+	},
+	"Gemfile": {
+		"rails":   "Rails",
+		"sinatra": "Sinatra",
+	},
+	"Cargo.toml": {
+		"tokio":     "Tokio",
+		"actix-web": "Actix Web",
+		"axum":      "Axum",
+	},
+}
+
+var (
+	makeTarget    = regexp.MustCompile(`^([\w-]+)\s*:`)
+	pythonDepName = regexp.MustCompile(`(?i)^[\s"']*([a-z0-9_.-]+)`)
+)
+
+// Detect works out the stack of a repository from the paths of its files,
+// relative to its root and slash-separated, reading build manifests with
+// read. Files read returns an error for are taken as missing.
+func Detect(paths []string, read func(path string) ([]byte, error)) Stack {
+	has := map[string]bool{}
+	counts := map[string]int{}
+	total := 0
+	for _, p := range paths {
+		if slices.ContainsFunc(strings.Split(path.Dir(p), "/"), func(d string) bool { return slices.Contains(skippedDirs, d) }) {
+			continue
+		}
+		if !strings.Contains(p, "/") {
+			has[p] = true
+		}
+		if lang, ok := languageExtensions[strings.ToLower(path.Ext(p))]; ok {
+			counts[lang]++
+			total++
+		}
+	}
+	file := func(name string) []byte {
+		if !has[name] {
+			return nil
+		}
+		data, err := read(name)
+		if err != nil {
+			return nil
+		}
+		return data
+	}
+
+	var s Stack
+	for lang, n := range counts {
+		if float64(n) >= minLanguageShare*float64(total) {
+			s.Languages = append(s.Languages, lang)
+		}
+	}
+	slices.SortFunc(s.Languages, func(a, b string) int {
+		return cmp.Or(cmp.Compare(counts[b], counts[a]), cmp.Compare(a, b))
+	})
+
+	targets := makeTargets(file("Makefile"))
+	var pkg struct {
+		Scripts         map[string]string `json:"scripts"`
+		Dependencies    map[string]string `json:"dependencies"`
+		DevDependencies map[string]string `json:"devDependencies"`
+	}
+	hasPkg := json.Unmarshal(file("package.json"), &pkg) == nil
+	npm := "npm"
+	switch {
+	case has["pnpm-lock.yaml"]:
+		npm = "pnpm"
+	case has["yarn.lock"]:
+		npm = "yarn"
+	}
+	python := has["pyproject.toml"] || has["pytest.ini"] || has["setup.py"] || has["requirements.txt"]
+	pyproject := string(file("pyproject.toml"))
+
+	// Test command: the same order `ap init project` has always suggested.
+	switch {
+	case targets["test"]:
+		s.TestCmd = "make test"
+	case has["go.mod"]:
+		s.TestCmd = "go test ./..."
+	case hasPkg && pkg.Scripts["test"] != "":
+		s.TestCmd = npm + " test"
+	case has["Cargo.toml"]:
+		s.TestCmd = "cargo test"
+	case has["pyproject.toml"] || has["pytest.ini"] || has["setup.py"]:
+		s.TestCmd = "pytest"
+	}
+
+	switch {
+	case targets["lint"]:
+		s.LintCmd = "make lint"
+	case has["go.mod"]:
+		s.LintCmd = "go vet ./..."
+	case hasPkg && pkg.Scripts["lint"] != "":
+		s.LintCmd = npm + " run lint"
+	case has["Cargo.toml"]:
+		s.LintCmd = "cargo clippy -- -D warnings"
+	case python && (has["ruff.toml"] || has[".ruff.toml"] || strings.Contains(pyproject, "[tool.ruff")):
+		s.LintCmd = "ruff check ."
+	}
+
+	if hasPkg {
+		for dep := range pkg.Dependencies {
+			s.addFramework("package.json", dep)
+		}
+		for dep := range pkg.DevDependencies {
+			s.addFramework("package.json", dep)
+		}
+	}
+	for _, line := range lines(file("go.mod")) {
+		for prefix, name := range frameworks["go.mod"] {
+			if strings.HasPrefix(strings.TrimPrefix(strings.TrimPrefix(line, "require"), " "), prefix) {
+				s.Frameworks = appendOnce(s.Frameworks, name)
+			}
+		}
+	}
+	if python {
+		for _, line := range append(lines(file("requirements.txt")), strings.Split(pyproject, "\n")...) {
+			if m := pythonDepName.FindStringSubmatch(line); m != nil {
+				s.addFramework("python", strings.ToLower(m[1]))
+			}
+		}
+	}
+	for _, line := range lines(file("Gemfile")) {
+		if rest, ok := strings.CutPrefix(line, "gem "); ok {
+			s.addFramework("Gemfile", strings.Trim(strings.SplitN(rest, ",", 2)[0], `"' `))
+		}
+	}
+	for _, line := range lines(file("Cargo.toml")) {
+		name, _, _ := strings.Cut(line, "=")
+		s.addFramework("Cargo.toml", strings.TrimSpace(name))
+	}
+	slices.Sort(s.Frameworks)
+	return s
+}
+
+// DetectDir works out the stack of the checkout in dir.
+func DetectDir(dir string) Stack {
+	var paths []string
+	_ = filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if p != dir && slices.Contains(skippedDirs, d.Name()) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if rel, err := filepath.Rel(dir, p); err == nil {
+			paths = append(paths, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	return Detect(paths, func(name string) ([]byte, error) {
+		return os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+	})
+}
+
+func (s *Stack) addFramework(manifest, dep string) {
+	if name, ok := frameworks[manifest][dep]; ok {
+		s.Frameworks = appendOnce(s.Frameworks, name)
+	}
+}
+
+func appendOnce(list []string, v string) []string {
+	if slices.Contains(list, v) {
+		return list
+	}
+	return append(list, v)
+}
+
+// makeTargets returns the targets defined in a Makefile.
+func makeTargets(makefile []byte) map[string]bool {
+	targets := map[string]bool{}
+	for _, line := range lines(makefile) {
+		if m := makeTarget.FindStringSubmatch(line); m != nil && !strings.Contains(line, ":=") {
+			targets[m[1]] = true
+		}
+	}
+	return targets
+}
+
+// lines returns the lines of data with surrounding space trimmed.
+func lines(data []byte) []string {
+	var out []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		out = append(out, strings.TrimSpace(scanner.Text()))
+	}
+	return out
+}
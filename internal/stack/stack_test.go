@@ -0,0 +1,103 @@
+package stack
+
+import (
+	"os"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func files(m map[string]string) ([]string, func(string) ([]byte, error)) {
+	var paths []string
+	for p := range m {
+		paths = append(paths, p)
+	}
+	slices.Sort(paths)
+	return paths, func(p string) ([]byte, error) {
+		content, ok := m[p]
+		if !ok {
+			return nil, os.ErrNotExist
+		}
+		return []byte(content), nil
+	}
+}
+
+func TestDetect(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  Stack
+	}{
+		{
+			name: "go with a make target",
+			files: map[string]string{
+				"Makefile":      "GOFLAGS := -race\ntest:\n\tgo test ./...\nlint: vet\n",
+				"go.mod":        "module x\n\nrequire (\n\tgithub.com/spf13/cobra v1.8.0\n)\n",
+				"main.go":       "",
+				"cmd/run.go":    "",
+				"vendor/y/y.go": "",
+				"tools/gen.py":  "",
+			},
+			want: Stack{Languages: []string{"Go", "Python"}, Frameworks: []string{"Cobra"}, TestCmd: "make test", LintCmd: "make lint"},
+		},
+		{
+			name: "typescript app on pnpm",
+			files: map[string]string{
+				"package.json":                `{"scripts":{"test":"vitest","lint":"eslint ."},"dependencies":{"react":"^18"},"devDependencies":{"typescript":"^5"}}`,
+				"pnpm-lock.yaml":              "",
+				"src/a.tsx":                   "",
+				"src/b.ts":                    "",
+				"src/c.ts":                    "",
+				"src/d.ts":                    "",
+				"src/e.ts":                    "",
+				"src/f.ts":                    "",
+				"src/g.ts":                    "",
+				"src/h.ts":                    "",
+				"src/i.ts":                    "",
+				"src/j.ts":                    "",
+				"vite.config.js":              "",
+				"node_modules/react/index.js": "",
+			},
+			want: Stack{Languages: []string{"TypeScript"}, Frameworks: []string{"React"}, TestCmd: "pnpm test", LintCmd: "pnpm run lint"},
+		},
+		{
+			name: "python with ruff",
+			files: map[string]string{
+				"pyproject.toml": "[project]\ndependencies = [\n  \"Django>=5\",\n]\n\n[tool.ruff]\nline-length = 100\n",
+				"app/views.py":   "",
+			},
+			want: Stack{Languages: []string{"Python"}, Frameworks: []string{"Django"}, TestCmd: "pytest", LintCmd: "ruff check ."},
+		},
+		{
+			name:  "nothing recognised",
+			files: map[string]string{"README.md": ""},
+			want:  Stack{},
+		},
+	}
+	for _, tt := range tests {
+		paths, read := files(tt.files)
+		got := Detect(paths, read)
+		if !slices.Equal(got.Languages, tt.want.Languages) || !slices.Equal(got.Frameworks, tt.want.Frameworks) || got.TestCmd != tt.want.TestCmd || got.LintCmd != tt.want.LintCmd {
+			t.Errorf("%s: Detect = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestGuidance(t *testing.T) {
+	t.Parallel()
+
+	got := Guidance(Stack{Languages: []string{"Go", "Elm"}, Frameworks: []string{"Cobra"}})
+	for _, want := range []string{"<project_stack>\nLanguages: Go, Elm\nFrameworks: Cobra\n", "- Go: Format changed files with gofmt.", "</project_stack>"} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("Guidance missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "- Elm") {
+		t.Fatalf("expected no advice for a language without guidance:\n%s", got)
+	}
+	if got := Guidance(Stack{TestCmd: "make test"}); got != "" {
+		t.Fatalf("expected no block without languages or frameworks, got %q", got)
+	}
+}
@@ -0,0 +1,108 @@
+// Package sysload samples the load and memory of the machine the daemon runs
+// on. Workers hold off claiming jobs while it is over the [daemon] limits, so
+// a burst of heavy test suites cannot run the machine out of memory.
+package sysload
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"autopr/internal/config"
+)
+
+// ErrUnsupported is returned by Read where the system is not sampled.
+var ErrUnsupported = errors.New("system load is only sampled on Linux")
+
+// Sample is the state of the machine at one moment.
+type Sample struct {
+	Load1        float64 // 1-minute load average
+	CPUs         int
+	MemTotal     uint64 // bytes
+	MemAvailable uint64 // bytes the kernel can hand out without swapping
+}
+
+// LoadPerCPU is the 1-minute load average divided by the number of CPUs.
+func (s Sample) LoadPerCPU() float64 {
+	if s.CPUs <= 0 {
+		return s.Load1
+	}
+	return s.Load1 / float64(s.CPUs)
+}
+
+// Limits are the thresholds past which workers claim no new job.
+type Limits struct {
+	MaxLoadPerCPU float64 // 0: no limit
+	MinFreeMemory uint64  // bytes; 0: no limit
+}
+
+// LimitsFor returns the limits set in cfg's [daemon] section.
+func LimitsFor(cfg *config.Config) Limits {
+	return Limits{
+		MaxLoadPerCPU: cfg.Daemon.MaxLoadPerCPU,
+		MinFreeMemory: uint64(cfg.Daemon.MinFreeMemoryMB) << 20,
+	}
+}
+
+// Enabled reports whether any limit is set.
+func (l Limits) Enabled() bool {
+	return l.MaxLoadPerCPU > 0 || l.MinFreeMemory > 0
+}
+
+// Exceeded returns why s is past l, or "" when it is not.
+func (l Limits) Exceeded(s Sample) string {
+	var reasons []string
+	if l.MaxLoadPerCPU > 0 && s.LoadPerCPU() > l.MaxLoadPerCPU {
+		reasons = append(reasons, fmt.Sprintf("load %.2f per CPU is above %.2f", s.LoadPerCPU(), l.MaxLoadPerCPU))
+	}
+	if l.MinFreeMemory > 0 && s.MemTotal > 0 && s.MemAvailable < l.MinFreeMemory {
+		reasons = append(reasons, fmt.Sprintf("%d MiB of memory available, below %d MiB", s.MemAvailable>>20, l.MinFreeMemory>>20))
+	}
+	return strings.Join(reasons, "; ")
+}
+
+// parseLoadavg reads the 1-minute load average from /proc/loadavg.
+func parseLoadavg(text string) (float64, error) {
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return 0, fmt.Errorf("parse loadavg: empty")
+	}
+	load, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return 0, fmt.Errorf("parse loadavg: %w", err)
+	}
+	return load, nil
+}
+
+// parseMeminfo reads the total and available memory, in bytes, from
+// /proc/meminfo.
+func parseMeminfo(text string) (total, available uint64, err error) {
+	found := 0
+	sc := bufio.NewScanner(strings.NewReader(text))
+	for sc.Scan() {
+		key, rest, ok := strings.Cut(sc.Text(), ":")
+		if !ok || (key != "MemTotal" && key != "MemAvailable") {
+			continue
+		}
+		fields := strings.Fields(rest)
+		if len(fields) == 0 {
+			return 0, 0, fmt.Errorf("parse meminfo: no value for %s", key)
+		}
+		kb, err := strconv.ParseUint(fields[0], 10, 64)
+		if err != nil {
+			return 0, 0, fmt.Errorf("parse meminfo %s: %w", key, err)
+		}
+		if key == "MemTotal" {
+			total = kb << 10
+		} else {
+			available = kb << 10
+		}
+		found++
+	}
+	if found < 2 {
+		return 0, 0, fmt.Errorf("parse meminfo: MemTotal or MemAvailable missing")
+	}
+	return total, available, nil
+}
@@ -0,0 +1,29 @@
+//go:build linux
+
+package sysload
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+)
+
+// Read samples the machine from /proc.
+func Read() (Sample, error) {
+	loadavg, err := os.ReadFile("/proc/loadavg")
+	if err != nil {
+		return Sample{}, fmt.Errorf("read load average: %w", err)
+	}
+	meminfo, err := os.ReadFile("/proc/meminfo")
+	if err != nil {
+		return Sample{}, fmt.Errorf("read memory info: %w", err)
+	}
+	s := Sample{CPUs: runtime.NumCPU()}
+	if s.Load1, err = parseLoadavg(string(loadavg)); err != nil {
+		return Sample{}, err
+	}
+	if s.MemTotal, s.MemAvailable, err = parseMeminfo(string(meminfo)); err != nil {
+		return Sample{}, err
+	}
+	return s, nil
+}
@@ -0,0 +1,8 @@
+//go:build !linux
+
+package sysload
+
+// Read returns ErrUnsupported: only Linux is sampled.
+func Read() (Sample, error) {
+	return Sample{}, ErrUnsupported
+}
@@ -0,0 +1,57 @@
+package sysload
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestParseProcFiles(t *testing.T) {
+	t.Parallel()
+
+	load, err := parseLoadavg("3.42 2.10 1.05 4/812 12345\n")
+	if err != nil || load != 3.42 {
+		t.Fatalf("parseLoadavg = %g, %v; want 3.42", load, err)
+	}
+	total, avail, err := parseMeminfo("MemTotal:       16303052 kB\nMemFree:          812344 kB\nMemAvailable:    4194304 kB\nBuffers:          120004 kB\n")
+	if err != nil || total != 16303052<<10 || avail != 4<<30 {
+		t.Fatalf("parseMeminfo = %d, %d, %v", total, avail, err)
+	}
+	if _, _, err := parseMeminfo("MemTotal: 100 kB\n"); err == nil {
+		t.Fatal("expected an error without MemAvailable")
+	}
+}
+
+func TestLimitsExceeded(t *testing.T) {
+	t.Parallel()
+
+	s := Sample{Load1: 12, CPUs: 4, MemTotal: 16 << 30, MemAvailable: 1 << 30}
+	for _, tc := range []struct {
+		limits Limits
+		want   string
+	}{
+		{Limits{}, ""},
+		{Limits{MaxLoadPerCPU: 4}, ""},
+		{Limits{MaxLoadPerCPU: 2}, "load 3.00 per CPU is above 2.00"},
+		{Limits{MinFreeMemory: 512 << 20}, ""},
+		{Limits{MaxLoadPerCPU: 2, MinFreeMemory: 2 << 30}, "load 3.00 per CPU is above 2.00; 1024 MiB of memory available, below 2048 MiB"},
+	} {
+		if got := tc.limits.Exceeded(s); got != tc.want {
+			t.Errorf("%+v: Exceeded = %q, want %q", tc.limits, got, tc.want)
+		}
+	}
+}
+
+func TestRead(t *testing.T) {
+	t.Parallel()
+
+	s, err := Read()
+	if runtime.GOOS != "linux" {
+		if err != ErrUnsupported {
+			t.Fatalf("expected ErrUnsupported, got %v", err)
+		}
+		return
+	}
+	if err != nil || s.CPUs <= 0 || s.MemTotal == 0 || s.MemAvailable > s.MemTotal {
+		t.Fatalf("Read = %+v, %v", s, err)
+	}
+}
@@ -0,0 +1,67 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"autopr/internal/config"
+)
+
+// useRecorder installs an in-memory tracer provider for the test. Tests that
+// call it must not run in parallel since the provider is global.
+func useRecorder(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	rec := tracetest.NewSpanRecorder()
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec)))
+	t.Cleanup(func() { otel.SetTracerProvider(prev) })
+	return rec
+}
+
+func TestStartNestsSpansAndEndRecordsErrors(t *testing.T) {
+	rec := useRecorder(t)
+
+	ctx, job := Start(context.Background(), "job", attribute.String("autopr.job_id", "ap-job-1"))
+	_, step := Start(ctx, "step plan")
+	End(step, errors.New("boom"))
+	End(job, nil)
+
+	spans := rec.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 spans, got %d", len(spans))
+	}
+	gotStep, gotJob := spans[0], spans[1]
+	if gotStep.Parent().SpanID() != gotJob.SpanContext().SpanID() {
+		t.Fatalf("step span is not a child of the job span")
+	}
+	if gotStep.Status().Code != codes.Error || gotStep.Status().Description != "boom" {
+		t.Fatalf("unexpected step status: %+v", gotStep.Status())
+	}
+	if gotJob.Status().Code != codes.Unset {
+		t.Fatalf("unexpected job status: %+v", gotJob.Status())
+	}
+	if attrs := gotJob.Attributes(); len(attrs) != 1 || attrs[0].Value.AsString() != "ap-job-1" {
+		t.Fatalf("unexpected job attributes: %+v", attrs)
+	}
+}
+
+func TestSetupDisabledIsNoop(t *testing.T) {
+	prev := otel.GetTracerProvider()
+	shutdown, err := Setup(context.Background(), config.TracingConfig{})
+	if err != nil {
+		t.Fatalf("setup: %v", err)
+	}
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatalf("shutdown: %v", err)
+	}
+	if otel.GetTracerProvider() != prev {
+		t.Fatalf("disabled tracing replaced the global tracer provider")
+	}
+}
@@ -2,21 +2,28 @@ package tui
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
+	"regexp"
 	"runtime"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
-	"syscall"
 	"time"
 
 	"autopr/internal/config"
+	"autopr/internal/daemon"
 	"autopr/internal/db"
 	"autopr/internal/git"
+	"autopr/internal/issuecomments"
+	"autopr/internal/netstate"
 	"autopr/internal/pipeline"
+	"autopr/internal/queuewatch"
+	"autopr/internal/sysload"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/glamour"
@@ -38,23 +45,32 @@ var (
 	dotRunning    = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("46")).Render("●")
 	dotStopped    = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("196")).Render("●")
 	stateStyle    = map[string]lipgloss.Style{
-		"queued":              lipgloss.NewStyle().Foreground(lipgloss.Color("246")),
-		"planning":            lipgloss.NewStyle().Foreground(lipgloss.Color("33")),
-		"implementing":        lipgloss.NewStyle().Foreground(lipgloss.Color("33")),
-		"reviewing":           lipgloss.NewStyle().Foreground(lipgloss.Color("214")),
-		"testing":             lipgloss.NewStyle().Foreground(lipgloss.Color("214")),
-		"ready":               lipgloss.NewStyle().Foreground(lipgloss.Color("46")),
-		"rebasing":            lipgloss.NewStyle().Foreground(lipgloss.Color("135")),
-		"resolving":           lipgloss.NewStyle().Foreground(lipgloss.Color("202")),
-		"resolving_conflicts": lipgloss.NewStyle().Foreground(lipgloss.Color("202")),
-		"checking ci":         lipgloss.NewStyle().Foreground(lipgloss.Color("33")),
-		"awaiting_checks":     lipgloss.NewStyle().Foreground(lipgloss.Color("33")),
-		"approved":            lipgloss.NewStyle().Foreground(lipgloss.Color("40")),
-		"merged":              lipgloss.NewStyle().Foreground(lipgloss.Color("141")),
-		"pr closed":           lipgloss.NewStyle().Foreground(lipgloss.Color("208")),
-		"rejected":            lipgloss.NewStyle().Foreground(lipgloss.Color("196")),
-		"failed":              lipgloss.NewStyle().Foreground(lipgloss.Color("196")),
-		"cancelled":           lipgloss.NewStyle().Foreground(lipgloss.Color("244")),
+		"queued":                lipgloss.NewStyle().Foreground(lipgloss.Color("246")),
+		"planning":              lipgloss.NewStyle().Foreground(lipgloss.Color("33")),
+		"implementing":          lipgloss.NewStyle().Foreground(lipgloss.Color("33")),
+		"reviewing":             lipgloss.NewStyle().Foreground(lipgloss.Color("214")),
+		"testing":               lipgloss.NewStyle().Foreground(lipgloss.Color("214")),
+		"ready":                 lipgloss.NewStyle().Foreground(lipgloss.Color("46")),
+		"rebasing":              lipgloss.NewStyle().Foreground(lipgloss.Color("135")),
+		"resolving":             lipgloss.NewStyle().Foreground(lipgloss.Color("202")),
+		"resolving_conflicts":   lipgloss.NewStyle().Foreground(lipgloss.Color("202")),
+		"checking ci":           lipgloss.NewStyle().Foreground(lipgloss.Color("33")),
+		"awaiting_checks":       lipgloss.NewStyle().Foreground(lipgloss.Color("33")),
+		"waiting on network":    lipgloss.NewStyle().Foreground(lipgloss.Color("214")),
+		"waiting_network":       lipgloss.NewStyle().Foreground(lipgloss.Color("214")),
+		"oversize":              lipgloss.NewStyle().Foreground(lipgloss.Color("208")),
+		"needs_review_oversize": lipgloss.NewStyle().Foreground(lipgloss.Color("208")),
+		"approved":              lipgloss.NewStyle().Foreground(lipgloss.Color("40")),
+		"merged":                lipgloss.NewStyle().Foreground(lipgloss.Color("141")),
+		"pr closed":             lipgloss.NewStyle().Foreground(lipgloss.Color("208")),
+		"rejected":              lipgloss.NewStyle().Foreground(lipgloss.Color("196")),
+		"failed":                lipgloss.NewStyle().Foreground(lipgloss.Color("196")),
+		"cancelled":             lipgloss.NewStyle().Foreground(lipgloss.Color("244")),
+	}
+	riskStyle = map[string]lipgloss.Style{
+		db.RiskLow:    lipgloss.NewStyle().Foreground(lipgloss.Color("46")),
+		db.RiskMedium: lipgloss.NewStyle().Foreground(lipgloss.Color("214")),
+		db.RiskHigh:   lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("196")),
 	}
 	sessStatusStyle = map[string]lipgloss.Style{
 		"running":   lipgloss.NewStyle().Foreground(lipgloss.Color("33")),
@@ -66,6 +82,7 @@ var (
 	diffDelStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
 	diffHunkStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("37"))
 	diffMetaStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("255"))
+	diffNoteStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("214"))
 	activeTab     = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("46")).Underline(true)
 	inactiveTab   = dimStyle
 )
@@ -80,6 +97,7 @@ func selectedCellStyle(base lipgloss.Style, selected bool) lipgloss.Style {
 const (
 	filterAllState   = "all"
 	filterAllProject = "all"
+	filterAnyTag     = "" // no tag filter
 )
 
 var filterStateCycle = []string{
@@ -87,9 +105,11 @@ var filterStateCycle = []string{
 	"queued",
 	"active",
 	"awaiting_checks",
+	"waiting_network",
 	"rebasing",
 	"resolving_conflicts",
 	"ready",
+	"needs_review_oversize",
 	"failed",
 	"merged",
 	"rejected",
@@ -106,21 +126,36 @@ var filterStateCycle = []string{
 //	selected == nil                          → Level 1 (job list)
 //	selected != nil && !showDiff && selectedSession == nil → Level 2 (job detail + sessions)
 //	showDiff                                 → Level 2d (diff view)
+//	showCompare                              → Level 2c (iteration compare view)
 //	selectedSession != nil                   → Level 3 (session detail)
+//	showSync                                 → Level 1s (per-project sync status)
+//	showIssues                               → Level 1i (planned issues)
+//	showIssues && previewIssue != nil        → Level 1p (issue preview)
 type Model struct {
-	store *db.Store
-	cfg   *config.Config
+	store      *db.Store
+	cfg        *config.Config
+	configPath string // passed to the ap commands the onboarding checklist runs
 
 	// Level 1: job list
 	jobs                []db.Job
 	allJobsCounts       []db.Job
+	jobsLoaded          bool   // the first jobs fetch has returned
+	onboardNotice       string // result of the last onboarding action
+	onboardErr          error
 	issueSummary        db.IssueSyncSummary
+	rateLimits          []db.APIRateLimit
+	notificationCounts  map[string]int
+	staleQueued         []queuewatch.StaleJob
+	disabledProjects    []string
+	syncStatuses        []db.ProjectSyncStatus
+	load                *sysload.Sample // machine load and memory; nil where not sampled
 	cursor              int
 	sortColumn          string
 	sortAsc             bool
 	page                int
 	pageSize            int
 	daemonRunning       bool
+	daemonIdle          bool // the daemon stopped its workers for lack of work
 	filterState         string
 	filterProject       string
 	filterMode          bool
@@ -128,32 +163,85 @@ type Model struct {
 	filterProjectDraft  string
 	filterStateBefore   string
 	filterProjectBefore string
+	filterTag           string
+	filterTagDraft      string
+	filterTagBefore     string
 	filterCursorBefore  int
+	groupByIssue        bool            // nest jobs of the same issue under one row
+	expandedIssues      map[string]bool // issues whose jobs are shown in grouped mode
+
+	// Level 1: job list columns (see tui.columns)
+	colOffset int                        // columns scrolled off to the right of the first column
+	jobTokens map[string]db.TokenSummary // per-job token totals, fetched for the tokens column
+
+	// Level 1s: per-project sync status and recent sync runs
+	showSync   bool
+	syncRuns   map[string][]db.SyncRun
+	syncOffset int
+
+	// Level 1i: open synced issues without a job, and the preview of one
+	showIssues      bool
+	plannedIssues   []db.Issue
+	issueCursor     int
+	issueNotice     string // result of the last enqueue
+	issueErr        error  // non-fatal error from the last enqueue
+	previewIssue    *db.Issue
+	previewComments []git.IssueComment
+	previewErr      error // comments could not be fetched
+	previewLoading  bool
+	previewLines    []string
+	previewOffset   int
 
 	// Level 2: job detail + session list
 	selected       *db.Job
 	sessions       []db.LLMSessionSummary
-	testArtifact   *db.Artifact // test_output artifact (nil if tests haven't run)
-	rebaseArtifact *db.Artifact // rebase_result or rebase_conflict artifact
+	testArtifact   *db.Artifact  // test_output artifact (nil if tests haven't run)
+	rebaseArtifact *db.Artifact  // rebase_result or rebase_conflict artifact
+	decisions      *db.Artifact  // latest decisions artifact, Content holding the full log
+	notes          []db.JobNote  // notes attached with `ap note` or the N key
+	epicTasks      []db.EpicTask // the tasks of the selected job's epic, when it is an epic task
 	sessCursor     int
 
 	// Level 2: confirmation prompt and action feedback
-	confirmAction  string // "approve", "merge", "reject", "retry", "cancel", or "" (none)
+	confirmAction  string // "approve", "merge", "reject", "retry", "cancel", "oversize-<action>", "tags", "note", or "" (none)
 	confirmDraft   bool   // true when approve should create a draft PR
 	confirmJobID   string // explicit target for confirmation actions (used by list-view cancel)
-	confirmText    bool   // true when waiting for text input (reject reason / retry notes)
+	confirmText    bool   // true when waiting for text input (reject reason / retry notes / annotations)
 	confirmTextBuf string // accumulated text from key events
 	actionErr      error  // non-fatal error from last action (shown inline)
 	actionWarn     string // non-fatal warning from last successful action
 
+	// Approval covers the diff the approver viewed: its checksum is recorded
+	// when the prompt opens, and approve pushes only if the diff still
+	// matches it. With no diff viewed yet, the diff view opens first.
+	approveDiffSum     string
+	approveDiffChanged bool // re-prompting because the diff changed
+	pendingApprove     bool // open the approve prompt once the diff loads
+
 	// Level 2d: diff view
 	showDiff   bool
 	diffLines  []string
 	diffOffset int
+	diffSum    string // git.DiffChecksum of the diff last shown for the selected job
+
+	// Level 2d: partial approval selection (files/hunks to keep)
+	partialSelect     bool
+	partialFiles      []git.DiffFile
+	partialKeep       [][]bool // per file: one flag per hunk (a single flag for hunk-less files)
+	partialCursor     int
+	approveExclusions git.DiffExclusions // set when approving a partial selection
+
+	// Level 2c: iteration compare view
+	showCompare       bool
+	compareIterations []db.JobIteration
+	compareIdx        int // index of the newer iteration of the pair
+	compareLines      []string
+	compareDiffStart  int // first line of compareLines that belongs to the code diff
+	compareOffset     int
 
 	// Level 3: session detail with scrollable output
 	selectedSession *db.LLMSession
-	showInput       bool // tab toggles input/output
+	sessionTab      sessionTab // tab cycles output, input, and context
 	scrollOffset    int
 	lines           []string // pre-split content lines
 
@@ -171,32 +259,88 @@ func NewModel(store *db.Store, cfg *config.Config) Model {
 		filterState:   filterAllState,
 		filterProject: filterAllProject,
 		daemonRunning: isDaemonRunning(cfg.Daemon.PIDFile),
+		daemonIdle:    daemon.IsIdle(cfg.Daemon.PIDFile),
 		page:          0,
 		pageSize:      1,
 	}
 }
 
+// WithConfigPath returns m with the config file it was loaded from, so the
+// onboarding checklist's init wizard and daemon start use the same config.
+func (m Model) WithConfigPath(path string) Model {
+	m.configPath = path
+	return m
+}
+
 // ── Messages ────────────────────────────────────────────────────────────────
 
 type jobsMsg struct {
 	filtered   []db.Job
 	unfiltered []db.Job
+	tokens     map[string]db.TokenSummary
+}
+type dashboardMsg struct {
+	issueSummary db.IssueSyncSummary
+	rateLimits   []db.APIRateLimit
+	notifyCounts map[string]int
+	staleQueued  []queuewatch.StaleJob
+	disabled     []string
+	syncStatuses []db.ProjectSyncStatus
+	load         *sysload.Sample // nil where the machine is not sampled
+}
+type syncRunsMsg struct {
+	runs map[string][]db.SyncRun
+}
+type plannedIssuesMsg struct {
+	issues []db.Issue
+}
+type issueCommentsMsg struct {
+	issueID  string
+	comments []git.IssueComment
+	err      error
+}
+type issueEnqueuedMsg struct {
+	issue db.Issue
+	jobID string
+	err   error
 }
-type issueSummaryMsg db.IssueSyncSummary
 type sessionsMsg struct {
 	jobID          string
 	job            db.Job
 	sessions       []db.LLMSessionSummary
 	testArtifact   *db.Artifact
 	rebaseArtifact *db.Artifact
+	decisions      *db.Artifact
+	notes          []db.JobNote
+	epicTasks      []db.EpicTask
 }
 type sessionMsg struct {
 	jobID   string
 	session db.LLMSession
 }
+type sessionDiffMsg struct {
+	sessionID int
+	lines     []string
+}
 type diffMsg struct {
 	jobID string
 	lines []string
+	sum   string
+}
+
+// approveDiffChangedMsg reports that the job's diff changed after the
+// approver reviewed it; sum is the checksum of the new diff.
+type approveDiffChangedMsg struct {
+	jobID string
+	sum   string
+}
+type compareMsg struct {
+	jobID      string
+	iterations []db.JobIteration
+	index      int
+	diff       string
+	diffErr    error
+	err        error
 }
 type actionResultMsg struct {
 	action string
@@ -204,6 +348,11 @@ type actionResultMsg struct {
 	prURL  string
 	warn   string
 }
+type onboardMsg struct {
+	action string // "init" or "start"
+	output string // last line the command printed
+	err    error
+}
 type tickMsg struct{}
 type errMsg error
 
@@ -216,13 +365,22 @@ func tick() tea.Cmd {
 }
 
 func (m Model) autoRefreshPaused() bool {
-	return m.showDiff || m.selectedSession != nil
+	return m.showDiff || m.showCompare || m.selectedSession != nil || m.previewIssue != nil
 }
 
 // ── Init / Commands ─────────────────────────────────────────────────────────
 
 func (m Model) Init() tea.Cmd {
-	return tea.Batch(m.fetchJobs, m.fetchIssueSummary, tick())
+	return tea.Batch(m.fetchJobs, m.fetchDashboard, tick(), m.wakeDaemon)
+}
+
+// wakeDaemon asks an idle daemon to start its workers and sync again, since
+// someone opened the TUI or asked for a refresh.
+func (m Model) wakeDaemon() tea.Msg {
+	if m.daemonRunning {
+		_ = daemon.RequestWake(m.cfg.Daemon.PIDFile)
+	}
+	return nil
 }
 
 func (m Model) fetchJobs() tea.Msg {
@@ -235,31 +393,111 @@ func (m Model) fetchJobs() tea.Msg {
 		stateFilter = filterAllState
 	}
 
-	filtered, err := m.store.ListJobs(context.Background(), projectFilter, stateFilter, m.sortColumn, m.sortAsc)
+	filtered, err := m.store.ListJobsTagged(context.Background(), projectFilter, stateFilter, m.filterTag, m.sortColumn, m.sortAsc)
 	if err != nil {
 		return errMsg(err)
 	}
 
 	unfiltered := filtered
-	if m.filterProject != filterAllProject || m.filterState != filterAllState {
+	if m.filterProject != filterAllProject || m.filterState != filterAllState || m.filterTag != filterAnyTag {
 		unfiltered, err = m.store.ListJobs(context.Background(), "", filterAllState, m.sortColumn, m.sortAsc)
 		if err != nil {
 			return errMsg(err)
 		}
 	}
 
-	return jobsMsg{
+	msg := jobsMsg{
 		filtered:   filtered,
 		unfiltered: unfiltered,
 	}
+	if slices.Contains(m.listColumnNames(), "tokens") && len(filtered) > 0 {
+		ids := make([]string, len(filtered))
+		for i, j := range filtered {
+			ids[i] = j.ID
+		}
+		if msg.tokens, err = m.store.AggregateTokensForJobs(context.Background(), ids); err != nil {
+			return errMsg(err)
+		}
+	}
+	return msg
 }
 
-func (m Model) fetchIssueSummary() tea.Msg {
+func (m Model) fetchDashboard() tea.Msg {
 	summary, err := m.store.GetIssueSyncSummary(context.Background(), "")
 	if err != nil {
 		return errMsg(err)
 	}
-	return issueSummaryMsg(summary)
+	rateLimits, err := m.store.ListAPIRateLimits(context.Background())
+	if err != nil {
+		return errMsg(err)
+	}
+	notifyCounts, err := m.store.CountNotificationEventsByStatus(context.Background())
+	if err != nil {
+		return errMsg(err)
+	}
+	overrides, err := m.store.ProjectEnabledOverrides(context.Background())
+	if err != nil {
+		return errMsg(err)
+	}
+	watcher := queuewatch.New(m.cfg, m.store)
+	watcher.DaemonRunning = m.daemonRunning
+	staleQueued, err := watcher.Stale(context.Background())
+	if err != nil {
+		return errMsg(err)
+	}
+	syncStatuses, err := m.store.ListProjectSyncStatus(context.Background())
+	if err != nil {
+		return errMsg(err)
+	}
+	msg := dashboardMsg{issueSummary: summary, rateLimits: rateLimits, notifyCounts: notifyCounts, staleQueued: staleQueued, disabled: m.cfg.DisabledProjects(overrides), syncStatuses: syncStatuses}
+	if load, err := sysload.Read(); err == nil {
+		msg.load = &load
+	}
+	return msg
+}
+
+// syncRunsShown is how many recent sync runs the sync status view lists per
+// project.
+const syncRunsShown = 10
+
+func (m Model) fetchSyncRuns() tea.Msg {
+	runs := make(map[string][]db.SyncRun, len(m.cfg.Projects))
+	for _, p := range m.cfg.Projects {
+		projectRuns, err := m.store.ListSyncRuns(context.Background(), p.Name, syncRunsShown)
+		if err != nil {
+			return errMsg(err)
+		}
+		runs[p.Name] = projectRuns
+	}
+	return syncRunsMsg{runs: runs}
+}
+
+func (m Model) fetchPlannedIssues() tea.Msg {
+	project := m.filterProject
+	if project == filterAllProject {
+		project = ""
+	}
+	issues, err := m.store.ListPlannedIssues(context.Background(), project)
+	if err != nil {
+		return errMsg(err)
+	}
+	return plannedIssuesMsg{issues: issues}
+}
+
+// issueHasComments reports whether comments of issues from source can be
+// fetched for the issue preview.
+func issueHasComments(source string) bool {
+	return issuecomments.Supported(source)
+}
+
+// fetchIssueComments fetches the comments of issue from its source.
+func (m Model) fetchIssueComments(issue db.Issue) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		comments, err := issuecomments.New(m.cfg, m.store).List(ctx, issue)
+		return issueCommentsMsg{issueID: issue.AutoPRIssueID, comments: comments, err: err}
+	}
 }
 
 func (m Model) fetchSessions() tea.Msg {
@@ -274,7 +512,16 @@ func (m Model) fetchSessions() tea.Msg {
 	}
 	activeStep := db.StepForState(job.State)
 	sessions = filterGhostSessions(sessions, activeStep)
-	msg := sessionsMsg{jobID: jobID, job: job, sessions: sessions}
+	notes, err := m.store.ListJobNotes(context.Background(), jobID)
+	if err != nil {
+		return errMsg(err)
+	}
+	msg := sessionsMsg{jobID: jobID, job: job, sessions: sessions, notes: notes}
+	if job.EpicIndex > 0 {
+		if msg.epicTasks, err = m.store.ListEpicTasks(context.Background(), job.AutoPRIssueID); err != nil {
+			return errMsg(err)
+		}
+	}
 	if art, err := m.store.GetLatestArtifact(context.Background(), jobID, "test_output"); err == nil {
 		msg.testArtifact = &art
 	}
@@ -283,6 +530,12 @@ func (m Model) fetchSessions() tea.Msg {
 	} else if art, err := m.store.GetLatestArtifact(context.Background(), jobID, "rebase_conflict"); err == nil {
 		msg.rebaseArtifact = &art
 	}
+	if art, err := m.store.GetLatestArtifact(context.Background(), jobID, "decisions"); err == nil {
+		if log, err := pipeline.DecisionsLog(context.Background(), m.store, jobID); err == nil && log != "" {
+			art.Content = log
+			msg.decisions = &art
+		}
+	}
 	return msg
 }
 
@@ -316,29 +569,127 @@ func (m Model) fetchFullSession() tea.Msg {
 	return sessionMsg{jobID: jobID, session: sess}
 }
 
+// fetchSessionDiff diffs the commit the selected session started from
+// against the one it ended on, which is what that step changed.
+func (m Model) fetchSessionDiff() tea.Msg {
+	sess := *m.selectedSession
+	msg := sessionDiffMsg{sessionID: sess.ID}
+	from := sess.BaseSHA
+	if from == "" && m.selected != nil {
+		// Sessions recorded before the start commit was kept: the step
+		// started where the job's previous session ended.
+		sessions, err := m.store.ListSessionsByJob(context.Background(), m.selected.ID)
+		if err != nil {
+			msg.lines = []string{fmt.Sprintf("(list sessions: %v)", err)}
+			return msg
+		}
+		from = previousSessionCommit(sessions, sess.ID)
+	}
+	switch {
+	case sess.CommitSHA == "":
+		msg.lines = []string{"(no commit recorded for this session)"}
+	case from == "":
+		msg.lines = []string{"(no start commit recorded for this session)"}
+	case from == sess.CommitSHA:
+		msg.lines = []string{"(no changes: the step made no commit)"}
+	case m.selected == nil || m.selected.WorktreePath == "":
+		msg.lines = []string{"(no worktree available)"}
+	default:
+		out, err := git.DiffCommits(context.Background(), m.selected.WorktreePath, from, sess.CommitSHA)
+		switch {
+		case err != nil:
+			msg.lines = []string{fmt.Sprintf("(git diff error: %v)", err)}
+		case out == "":
+			msg.lines = []string{"(no changes)"}
+		default:
+			msg.lines = strings.Split(git.AnnotateDiff(out), "\n")
+		}
+	}
+	return msg
+}
+
+// previousSessionCommit returns the commit the last session before id
+// ended on, or "" when none recorded one.
+func previousSessionCommit(sessions []db.LLMSession, id int) string {
+	from := ""
+	for _, s := range sessions {
+		if s.ID >= id {
+			break
+		}
+		if s.CommitSHA != "" {
+			from = s.CommitSHA
+		}
+	}
+	return from
+}
+
 func (m Model) fetchDiff() tea.Msg {
 	job := m.selected
 	if job == nil || job.WorktreePath == "" {
 		return diffMsg{jobID: "", lines: []string{"(no worktree available)"}}
 	}
 
-	baseBranch := "master"
-	if p, ok := m.cfg.ProjectByName(job.ProjectName); ok && p.BaseBranch != "" {
-		baseBranch = p.BaseBranch
-	}
-
-	out, err := git.DiffAgainstBase(context.Background(), job.WorktreePath, baseBranch)
+	out, err := git.DiffAgainstBase(context.Background(), job.WorktreePath, m.diffBaseBranch(*job))
 	if err != nil {
 		return diffMsg{jobID: job.ID, lines: []string{fmt.Sprintf("(git diff error: %v)", err)}}
 	}
+	sum := git.DiffChecksum(out)
 	if out == "" {
-		return diffMsg{jobID: job.ID, lines: []string{"(no changes)"}}
+		return diffMsg{jobID: job.ID, lines: []string{"(no changes)"}, sum: sum}
+	}
+	return diffMsg{jobID: job.ID, lines: strings.Split(git.AnnotateDiff(out), "\n"), sum: sum}
+}
+
+// diffBaseBranch is the branch the diff view compares a job against.
+func (m Model) diffBaseBranch(job db.Job) string {
+	if job.BackportBranch != "" {
+		return job.BackportBranch
+	}
+	if job.BaseBranch != "" {
+		return job.BaseBranch
+	}
+	if p, ok := m.cfg.ProjectByName(job.ProjectName); ok && p.BaseBranch != "" {
+		return p.BaseBranch
+	}
+	return "master"
+}
+
+// fetchCompare loads the job's iterations and diffs the reviewed commit of
+// iteration index against its predecessor. A negative index selects the
+// latest pair.
+func (m Model) fetchCompare(index int) tea.Cmd {
+	job := m.selected
+	return func() tea.Msg {
+		if job == nil {
+			return compareMsg{}
+		}
+		ctx := context.Background()
+		iterations, err := m.store.ListJobIterations(ctx, job.ID)
+		if err != nil {
+			return compareMsg{jobID: job.ID, err: err}
+		}
+		if len(iterations) < 2 {
+			return compareMsg{jobID: job.ID, iterations: iterations, err: fmt.Errorf("need at least 2 reviewed iterations to compare")}
+		}
+		if index < 1 || index >= len(iterations) {
+			index = len(iterations) - 1
+		}
+		prev, cur := iterations[index-1], iterations[index]
+		msg := compareMsg{jobID: job.ID, iterations: iterations, index: index}
+		switch {
+		case prev.CommitSHA == "" || cur.CommitSHA == "":
+			msg.diffErr = fmt.Errorf("commit not recorded for one of the iterations")
+		case job.WorktreePath == "":
+			msg.diffErr = fmt.Errorf("no worktree available")
+		default:
+			msg.diff, msg.diffErr = git.DiffCommits(ctx, job.WorktreePath, prev.CommitSHA, cur.CommitSHA)
+		}
+		return msg
 	}
-	return diffMsg{jobID: job.ID, lines: strings.Split(out, "\n")}
 }
 
 // openInEditor opens the worktree directory in the user's preferred editor.
-// Tries $EDITOR, then falls back to "code", then "vim".
+// Tries $EDITOR, then falls back to "code", then "vim" (Explorer on Windows).
 func (m Model) openInEditor() tea.Msg {
 	dir := m.selected.WorktreePath
 	editor := os.Getenv("EDITOR")
@@ -346,6 +697,8 @@ func (m Model) openInEditor() tea.Msg {
 		// Prefer VS Code if available, fall back to vim.
 		if _, err := exec.LookPath("code"); err == nil {
 			editor = "code"
+		} else if runtime.GOOS == "windows" {
+			editor = "explorer"
 		} else {
 			editor = "vim"
 		}
@@ -370,6 +723,12 @@ func (m Model) openIssue() tea.Msg {
 	return nil
 }
 
+// openPreviewIssue opens the previewed issue's URL in the default browser.
+func (m Model) openPreviewIssue() tea.Msg {
+	openURL(m.previewIssue.URL)
+	return nil
+}
+
 // openURL opens a URL in the default browser across platforms.
 // Stdout/Stderr are discarded so child-process diagnostics cannot corrupt the TUI.
 func openURL(url string) {
@@ -378,7 +737,9 @@ func openURL(url string) {
 	case "darwin":
 		cmd = exec.Command("open", url)
 	case "windows":
-		cmd = exec.Command("cmd", "/c", "start", url)
+		// Not "cmd /c start": it treats a quoted first argument as a window
+		// title and splits the URL at "&".
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
 	default: // linux, freebsd, etc.
 		cmd = exec.Command("xdg-open", url)
 	}
@@ -389,68 +750,54 @@ func openURL(url string) {
 
 // ── Job Actions ─────────────────────────────────────────────────────────────
 
-func (m Model) executeApprove() tea.Msg {
-	ctx := context.Background()
-	job := m.selected
+// startApprove opens the approve prompt for the diff the approver last
+// viewed, so executeApprove can tell whether the branch changed since. If they
+// have not viewed the diff, it opens the diff view with the prompt.
+func (m Model) startApprove(draft bool) (Model, tea.Cmd) {
+	m.actionErr = nil
+	m.confirmDraft = draft
+	m.approveDiffChanged = false
+	if m.diffSum == "" {
+		m.pendingApprove = true
+		return m, m.fetchDiff
+	}
+	m.approveDiffSum = m.diffSum
+	startConfirm(&m, "approve", m.selected.ID)
+	return m, nil
+}
 
-	issue, err := m.store.GetIssueByAPID(ctx, job.AutoPRIssueID)
+func (m Model) executeApprove() tea.Msg {
+	// Hold the job's lease so the daemon cannot record a PR or move the job
+	// while the TUI pushes and opens one.
+	var msg tea.Msg
+	err := m.store.WithJobLease(context.Background(), m.selected.ID, db.LeaseHolder("ap tui"), func(ctx context.Context) error {
+		msg = m.approve(ctx)
+		return nil
+	})
 	if err != nil {
-		return actionResultMsg{action: "approve", err: fmt.Errorf("load issue: %w", err)}
-	}
-
-	proj, ok := m.cfg.ProjectByName(job.ProjectName)
-	if !ok {
-		return actionResultMsg{action: "approve", err: fmt.Errorf("project %q not found", job.ProjectName)}
-	}
-
-	// Rebase onto latest base branch before pushing.
-	if err := pipeline.RebaseBeforePush(ctx, m.store, job.ID, job.AutoPRIssueID, proj.BaseBranch, job.WorktreePath, job.Iteration, m.cfg.GitTokenForProject(proj)); err != nil {
-		return actionResultMsg{action: "approve", err: fmt.Errorf("rebase before push: %w", err)}
-	}
-
-	pushRemote := "origin"
-	pushHead := job.BranchName
-	if proj.GitHub != nil {
-		var err error
-		pushRemote, pushHead, err = pipeline.ResolveGitHubPushTarget(ctx, proj, job.BranchName, job.WorktreePath, m.cfg.Tokens.GitHub)
-		if err != nil {
-			return actionResultMsg{action: "approve", err: fmt.Errorf("resolve push target: %w", err)}
-		}
-	}
-
-	// Push branch to remote before creating PR.
-	if err := git.PushBranchWithLeaseToRemoteWithToken(ctx, job.WorktreePath, pushRemote, job.BranchName, m.cfg.GitTokenForProject(proj)); err != nil {
-		return actionResultMsg{action: "approve", err: fmt.Errorf("push branch: %w", err)}
+		return actionResultMsg{action: "approve", err: err}
 	}
+	return msg
+}
 
-	prURL := job.PRURL
-	if prURL == "" {
-
-		prTitle, prBody := buildTUIPRContent(job, issue)
-		var prErr error
-		prURL, prErr = pipeline.CreatePRForProject(ctx, m.cfg, proj, *job, pushHead, prTitle, prBody, m.confirmDraft)
-		if prErr != nil {
-			return actionResultMsg{action: "approve", err: fmt.Errorf("create PR: %w", prErr)}
-		}
-
-		if prURL != "" {
-			_ = m.store.UpdateJobField(ctx, job.ID, "pr_url", prURL)
-		}
+func (m Model) approve(ctx context.Context) tea.Msg {
+	job := m.selected
+	// A late LLM commit may have changed the branch since the approver
+	// reviewed it. Push only the diff they saw; otherwise ask again.
+	res, err := pipeline.Approve(ctx, m.cfg, m.store, job.ID, pipeline.Approval{
+		Approver:     git.UserIdentity(ctx, job.WorktreePath),
+		Draft:        m.confirmDraft,
+		Exclusions:   m.approveExclusions,
+		ReviewedDiff: m.approveDiffSum,
+	})
+	var changed *pipeline.DiffChangedError
+	if errors.As(err, &changed) {
+		return approveDiffChangedMsg{jobID: job.ID, sum: changed.Sum}
 	}
-
-	// Re-fetch job state: the pipeline's maybeAutoPR may have already
-	// transitioned ready → approved while the TUI was waiting for user input.
-	fresh, err := m.store.GetJob(ctx, job.ID)
 	if err != nil {
 		return actionResultMsg{action: "approve", err: err}
 	}
-	if fresh.State == "approved" {
-		return actionResultMsg{action: "approve", prURL: prURL}
-	}
-	if err := m.store.TransitionState(ctx, fresh.ID, "ready", "approved"); err != nil {
-		return actionResultMsg{action: "approve", err: err}
-	}
-	return actionResultMsg{action: "approve", prURL: prURL}
+	return actionResultMsg{action: "approve", prURL: res.PRURL}
 }
 
 func (m Model) executeReject() tea.Msg {
@@ -481,6 +828,54 @@ func (m Model) executeRetryWith(notes string) func() tea.Msg {
 	}
 }
 
+func (m Model) executeOversize(action string) func() tea.Msg {
+	return func() tea.Msg {
+		ctx := context.Background()
+		if err := pipeline.ResolveOversize(ctx, m.store, *m.selected, action); err != nil {
+			return actionResultMsg{action: "oversize", err: err}
+		}
+		return actionResultMsg{action: "oversize"}
+	}
+}
+
+func (m Model) executeFollowUp(instructions string) func() tea.Msg {
+	return func() tea.Msg {
+		ctx := context.Background()
+		job := m.selected
+		proj, ok := m.cfg.ProjectByName(job.ProjectName)
+		if !ok {
+			return actionResultMsg{action: "follow-up", err: fmt.Errorf("project %q not found", job.ProjectName)}
+		}
+		if _, err := pipeline.CreateFollowUpJob(ctx, m.store, *job, pipeline.TargetBranch(*job, proj), instructions); err != nil {
+			return actionResultMsg{action: "follow-up", err: err}
+		}
+		return actionResultMsg{action: "follow-up"}
+	}
+}
+
+// executeSetTags replaces the selected job's tags with the space- or
+// comma-separated tags in text.
+func (m Model) executeSetTags(text string) func() tea.Msg {
+	jobID := m.confirmTargetJobID()
+	return func() tea.Msg {
+		tags := strings.FieldsFunc(text, func(r rune) bool { return r == ' ' || r == ',' })
+		if err := m.store.SetJobTags(context.Background(), jobID, tags); err != nil {
+			return actionResultMsg{action: "tags", err: err}
+		}
+		return actionResultMsg{action: "tags"}
+	}
+}
+
+func (m Model) executeAddNote(text string) func() tea.Msg {
+	jobID := m.confirmTargetJobID()
+	return func() tea.Msg {
+		if _, err := m.store.AddJobNote(context.Background(), jobID, text); err != nil {
+			return actionResultMsg{action: "note", err: err}
+		}
+		return actionResultMsg{action: "note"}
+	}
+}
+
 func (m Model) executeCancel() tea.Msg {
 	ctx := context.Background()
 	jobID := m.confirmTargetJobID()
@@ -529,23 +924,15 @@ func (m Model) executeMerge() tea.Msg {
 		return actionResultMsg{action: "merge", err: fmt.Errorf("project %q not found", job.ProjectName)}
 	}
 
-	switch {
-	case proj.GitHub != nil:
-		if m.cfg.Tokens.GitHub == "" {
-			return actionResultMsg{action: "merge", err: fmt.Errorf("GITHUB_TOKEN required to merge PR")}
-		}
-		if err := git.MergeGitHubPR(ctx, m.cfg.Tokens.GitHub, job.PRURL, "merge"); err != nil {
+	if err := pipeline.MergePRForProject(ctx, m.cfg, proj, job, "merge"); err != nil {
+		if !netstate.IsNetworkError(err) {
 			return actionResultMsg{action: "merge", err: err}
 		}
-	case proj.GitLab != nil:
-		if m.cfg.Tokens.GitLab == "" {
-			return actionResultMsg{action: "merge", err: fmt.Errorf("GITLAB_TOKEN required to merge MR")}
-		}
-		if err := git.MergeGitLabMR(ctx, m.cfg.Tokens.GitLab, proj.GitLab.BaseURL, job.PRURL, false); err != nil {
-			return actionResultMsg{action: "merge", err: err}
+		// Offline: the daemon merges from the outbox once the forge is reachable.
+		if _, qerr := m.store.EnqueueOutboxOp(ctx, job.ID, db.OutboxOpMergePR, "merge"); qerr != nil {
+			return actionResultMsg{action: "merge", err: fmt.Errorf("%w (queue merge: %v)", err, qerr)}
 		}
-	default:
-		return actionResultMsg{action: "merge", err: fmt.Errorf("project %q has no GitHub or GitLab config for PR merge", proj.Name)}
+		return actionResultMsg{action: "merge", warn: "network unavailable; merge queued until connectivity returns"}
 	}
 
 	if err := m.store.MarkJobMerged(ctx, job.ID, time.Now().UTC().Format(time.RFC3339)); err != nil {
@@ -554,6 +941,18 @@ func (m Model) executeMerge() tea.Msg {
 	return actionResultMsg{action: "merge"}
 }
 
+// executeEnqueueIssue queues a job for a planned issue, under the same rules
+// as ap enqueue (see pipeline.EnqueueIssue).
+func (m Model) executeEnqueueIssue(issue db.Issue) tea.Cmd {
+	return func() tea.Msg {
+		jobID, err := pipeline.EnqueueIssue(context.Background(), m.cfg, m.store, issue)
+		if err != nil {
+			return issueEnqueuedMsg{issue: issue, err: err}
+		}
+		return issueEnqueuedMsg{issue: issue, jobID: jobID}
+	}
+}
+
 func (m Model) cleanupCancelledJobWorktree(ctx context.Context, job db.Job) error {
 	if job.WorktreePath == "" {
 		return nil
@@ -564,14 +963,6 @@ func (m Model) cleanupCancelledJobWorktree(ctx context.Context, job db.Job) erro
 	return m.store.ClearWorktreePath(ctx, job.ID)
 }
 
-// buildTUIPRContent assembles PR title and body (mirrors pipeline.BuildPRContent).
-func buildTUIPRContent(job *db.Job, issue db.Issue) (string, string) {
-	title := fmt.Sprintf("[AutoPR] %s", issue.Title)
-	body := fmt.Sprintf("Closes %s\n\n**Issue:** %s\n\n_Generated by [AutoPR](https://github.com/ashwath-ramesh/autopr) from job `%s`_\n",
-		issue.URL, issue.Title, db.ShortID(job.ID))
-	return title, body
-}
-
 // ── Update ──────────────────────────────────────────────────────────────────
 
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -580,22 +971,31 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.width = msg.Width
 		m.height = msg.Height
 		m.pageSize = m.computedPageSize()
-		m.page, m.cursor = clampPageAndCursor(len(m.jobs), m.page, m.cursor, m.pageSize)
+		m.page, m.cursor = clampPageAndCursor(len(m.listRows()), m.page, m.cursor, m.pageSize)
 	case tickMsg:
 		m.daemonRunning = isDaemonRunning(m.cfg.Daemon.PIDFile)
+		m.daemonIdle = daemon.IsIdle(m.cfg.Daemon.PIDFile)
 		cmds := []tea.Cmd{tick()}
 		if m.autoRefreshPaused() {
 			return m, tea.Batch(cmds...)
 		}
-		cmds = append(cmds, m.fetchJobs, m.fetchIssueSummary)
+		cmds = append(cmds, m.fetchJobs, m.fetchDashboard)
 		if m.selected != nil {
 			cmds = append(cmds, m.fetchSessions)
 		}
+		if m.showSync {
+			cmds = append(cmds, m.fetchSyncRuns)
+		}
+		if m.showIssues {
+			cmds = append(cmds, m.fetchPlannedIssues)
+		}
 		return m, tea.Batch(cmds...)
 	case jobsMsg:
 		m.jobs = msg.filtered
 		m.allJobsCounts = msg.unfiltered
-		m.page, m.cursor = clampPageAndCursor(len(m.jobs), m.page, m.cursor, m.pageSize)
+		m.jobsLoaded = true
+		m.jobTokens = msg.tokens
+		m.page, m.cursor = clampPageAndCursor(len(m.listRows()), m.page, m.cursor, m.pageSize)
 		m.err = nil
 		// Re-sync selected pointer to new slice so keybindings see fresh state.
 		if m.selected != nil {
@@ -613,6 +1013,9 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.sessions = nil
 				m.testArtifact = nil
 				m.rebaseArtifact = nil
+				m.decisions = nil
+				m.notes = nil
+				m.epicTasks = nil
 				m.sessCursor = 0
 				m.confirmAction = ""
 				m.confirmJobID = ""
@@ -623,9 +1026,40 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.actionWarn = ""
 			}
 		}
-	case issueSummaryMsg:
-		m.issueSummary = db.IssueSyncSummary(msg)
+	case dashboardMsg:
+		m.issueSummary = msg.issueSummary
+		m.rateLimits = msg.rateLimits
+		m.notificationCounts = msg.notifyCounts
+		m.staleQueued = msg.staleQueued
+		m.disabledProjects = msg.disabled
+		m.syncStatuses = msg.syncStatuses
+		m.load = msg.load
+		m.pageSize = m.computedPageSize()
+		m.page, m.cursor = clampPageAndCursor(len(m.listRows()), m.page, m.cursor, m.pageSize)
 		m.err = nil
+	case syncRunsMsg:
+		m.syncRuns = msg.runs
+	case plannedIssuesMsg:
+		m.plannedIssues = msg.issues
+		m.issueCursor = min(m.issueCursor, max(len(m.plannedIssues)-1, 0))
+	case issueCommentsMsg:
+		if m.previewIssue == nil || m.previewIssue.AutoPRIssueID != msg.issueID {
+			break
+		}
+		m.previewComments = msg.comments
+		m.previewErr = msg.err
+		m.previewLoading = false
+		m.previewLines = m.issuePreviewLines()
+	case issueEnqueuedMsg:
+		if msg.err != nil {
+			m.issueErr = msg.err
+			m.issueNotice = ""
+			break
+		}
+		m.issueErr = nil
+		m.issueNotice = fmt.Sprintf("Queued job %s for %s #%s.", db.ShortID(msg.jobID), capitalize(msg.issue.Source), msg.issue.SourceIssueID)
+		m = m.closeIssuePreview()
+		return m, tea.Batch(m.fetchPlannedIssues, m.fetchJobs, m.fetchDashboard)
 	case sessionsMsg:
 		// Discard stale response if user navigated away.
 		if m.selected == nil || m.selected.ID != msg.jobID {
@@ -635,6 +1069,9 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.sessions = msg.sessions
 		m.testArtifact = msg.testArtifact
 		m.rebaseArtifact = msg.rebaseArtifact
+		m.decisions = msg.decisions
+		m.notes = msg.notes
+		m.epicTasks = msg.epicTasks
 		// Clamp cursor rather than resetting so auto-refresh doesn't jump.
 		maxIdx := len(m.sessions) + len(m.pipelineSyntheticRows())
 		if maxIdx > 0 && m.sessCursor >= maxIdx {
@@ -649,20 +1086,108 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		sess := msg.session
 		m.selectedSession = &sess
-		m.showInput = false
+		m.sessionTab = sessionTabOutput
 		m.scrollOffset = 0
 		m.lines = splitContent(sess.ResponseText, sess.Status, m.cw())
+		if sess.PurgedAt != "" {
+			m.lines = purgedLines(sess.PurgedAt)
+		}
+	case sessionDiffMsg:
+		if m.selectedSession == nil || m.selectedSession.ID != msg.sessionID {
+			break
+		}
+		m.sessionTab = sessionTabCommit
+		m.scrollOffset = 0
+		m.lines = make([]string, len(msg.lines))
+		for i, line := range msg.lines {
+			m.lines[i] = colorDiffLine(line)
+		}
 	case diffMsg:
 		if m.selected == nil || m.selected.ID != msg.jobID {
 			break
 		}
 		m.diffLines = msg.lines
+		m.diffSum = msg.sum
 		m.showDiff = true
 		m.diffOffset = 0
+		if m.pendingApprove {
+			m.pendingApprove = false
+			// A diff that failed to load has no checksum to approve.
+			if msg.sum == "" || m.selected.State != "ready" {
+				m.confirmDraft = false
+				m.approveDiffChanged = false
+				break
+			}
+			m.approveDiffSum = msg.sum
+			startConfirm(&m, "approve", msg.jobID)
+		}
+	case approveDiffChangedMsg:
+		if m.selected == nil || m.selected.ID != msg.jobID {
+			break
+		}
+		if len(m.approveExclusions) > 0 {
+			// The selected hunks refer to the old diff.
+			m.confirmAction = ""
+			m.confirmJobID = ""
+			m.confirmDraft = false
+			m.approveExclusions = nil
+			m.approveDiffSum = ""
+			m.approveDiffChanged = false
+			m.actionErr = fmt.Errorf("diff changed since you reviewed it; open it and select the changes to keep again")
+			return m, tea.Batch(m.fetchJobs, m.fetchSessions)
+		}
+		// Show the approver the new diff before asking again.
+		m.confirmAction = ""
+		m.confirmJobID = ""
+		m.approveDiffSum = ""
+		m.approveDiffChanged = true
+		m.pendingApprove = true
+		return m, tea.Batch(m.fetchDiff, m.fetchJobs, m.fetchSessions)
+	case compareMsg:
+		if m.selected == nil || m.selected.ID != msg.jobID {
+			break
+		}
+		if msg.err != nil {
+			m.actionErr = msg.err
+			break
+		}
+		m.compareIterations = msg.iterations
+		m.compareIdx = msg.index
+		m.compareLines, m.compareDiffStart = buildCompareLines(msg.iterations[msg.index-1], msg.iterations[msg.index], msg.diff, msg.diffErr, m.cw())
+		m.compareOffset = 0
+		m.showCompare = true
+	case onboardMsg:
+		m.onboardNotice, m.onboardErr = "", nil
+		if msg.err != nil {
+			m.onboardErr = msg.err
+			if msg.output != "" {
+				m.onboardErr = fmt.Errorf("%w: %s", msg.err, msg.output)
+			}
+			return m, nil
+		}
+		switch msg.action {
+		case "init":
+			if cfg, err := config.Load(m.configPath); err != nil {
+				m.onboardErr = fmt.Errorf("reload config: %w", err)
+			} else {
+				m.cfg = cfg
+				m.onboardNotice = "Project added."
+				if m.daemonRunning {
+					m.onboardNotice += " Restart the daemon (ap stop && ap start) to sync it."
+				}
+			}
+		case "start":
+			m.daemonRunning = isDaemonRunning(m.cfg.Daemon.PIDFile)
+			m.onboardNotice = msg.output
+		}
+		return m, tea.Batch(m.fetchJobs, m.fetchDashboard)
 	case actionResultMsg:
 		m.confirmAction = ""
 		m.confirmJobID = ""
 		m.confirmDraft = false
+		m.approveExclusions = nil
+		m.approveDiffSum = ""
+		m.approveDiffChanged = false
 		m.confirmText = false
 		m.confirmTextBuf = ""
 		if msg.err != nil {
@@ -673,16 +1198,19 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			// Action succeeded — refresh and keep detail view for approve/merge.
 			m.actionErr = nil
 			m.actionWarn = msg.warn
-			if (msg.action == "approve" || msg.action == "merge") && m.selected != nil {
-				return m, tea.Batch(m.fetchJobs, m.fetchSessions, m.fetchIssueSummary)
+			if (msg.action == "approve" || msg.action == "merge" || msg.action == "tags" || msg.action == "note") && m.selected != nil {
+				return m, tea.Batch(m.fetchJobs, m.fetchSessions, m.fetchDashboard)
 			}
 			// Other actions keep existing behavior: return to Level 1.
 			m.selected = nil
 			m.sessions = nil
 			m.testArtifact = nil
 			m.rebaseArtifact = nil
+			m.decisions = nil
+			m.notes = nil
+			m.epicTasks = nil
 			m.sessCursor = 0
-			return m, tea.Batch(m.fetchJobs, m.fetchIssueSummary)
+			return m, tea.Batch(m.fetchJobs, m.fetchDashboard)
 		}
 	case errMsg:
 		m.err = msg
@@ -702,6 +1230,72 @@ func splitContent(text, status string, width int) []string {
 	return renderMarkdown(text, width)
 }
 
+// sessionTab is the part of a session shown in the session detail view.
+type sessionTab int
+
+const (
+	sessionTabOutput  sessionTab = iota // the response
+	sessionTabInput                     // the prompt
+	sessionTabContext                   // the repository docs added to the prompt
+	sessionTabCommit                    // the diff of the commits the step made
+)
+
+func (t sessionTab) String() string {
+	switch t {
+	case sessionTabInput:
+		return "INPUT"
+	case sessionTabContext:
+		return "CONTEXT"
+	case sessionTabCommit:
+		return "COMMIT"
+	}
+	return "OUTPUT"
+}
+
+// next is the tab after t: output, input, then context when the session had
+// repository docs in its prompt.
+func (t sessionTab) next(hasContext bool) sessionTab {
+	switch {
+	case t == sessionTabOutput:
+		return sessionTabInput
+	case t == sessionTabInput && hasContext:
+		return sessionTabContext
+	}
+	return sessionTabOutput
+}
+
+// repoContextBlockRE matches the repository docs the pipeline adds to a
+// prompt.
+var repoContextBlockRE = regexp.MustCompile(`(?s)<repo_context>\n(.*?)</repo_context>`)
+
+// contextLines lists the repository docs added to a session's prompt, with
+// their text as sent when the prompt is still recorded.
+func contextLines(sess db.LLMSession) []string {
+	lines := []string{"Repository docs added to this prompt (.autopr/context.md and context_files):", ""}
+	for _, f := range sess.ContextFiles {
+		line := fmt.Sprintf("  %-40s %d bytes", f.Path, f.Bytes)
+		if f.Truncated {
+			line += " (truncated)"
+		}
+		lines = append(lines, line)
+	}
+	lines = append(lines, "")
+	switch m := repoContextBlockRE.FindStringSubmatch(sess.PromptText); {
+	case sess.PurgedAt != "":
+		lines = append(lines, purgedLines(sess.PurgedAt)...)
+	case m != nil:
+		lines = append(lines, strings.Split(strings.TrimRight(m[1], "\n"), "\n")...)
+	default:
+		lines = append(lines, "(prompt not recorded yet)")
+	}
+	return lines
+}
+
+// purgedLines is shown for sessions whose text the retention policy deleted.
+func purgedLines(purgedAt string) []string {
+	return []string{"(text purged at " + purgedAt + "; token counts are kept)"}
+}
+
 // renderMarkdown renders text as terminal-styled markdown via glamour.
 // Falls back to plain text splitting on error.
 func renderMarkdown(text string, width int) []string {
@@ -743,6 +1337,12 @@ func (m Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				return m, m.executeRejectWith(text)
 			case "retry":
 				return m, m.executeRetryWith(text)
+			case "follow-up":
+				return m, m.executeFollowUp(text)
+			case "tags":
+				return m, m.executeSetTags(text)
+			case "note":
+				return m, m.executeAddNote(text)
 			}
 			return m, nil
 		case "esc":
@@ -780,6 +1380,9 @@ func (m Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			action := m.confirmAction
 			switch action {
 			case "approve":
+				m.showDiff = false
+				m.diffLines = nil
+				m.diffOffset = 0
 				return m, m.executeApprove
 			case "merge":
 				return m, m.executeMerge
@@ -793,18 +1396,38 @@ func (m Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				return m, nil
 			case "cancel":
 				return m, m.executeCancel
+			case "oversize-" + pipeline.OversizeAllow, "oversize-" + pipeline.OversizeSplit, "oversize-" + pipeline.OversizeReject:
+				return m, m.executeOversize(strings.TrimPrefix(action, "oversize-"))
 			}
 		case "n", "esc":
 			m.confirmAction = ""
 			m.confirmJobID = ""
 			m.confirmDraft = false
+			m.approveExclusions = nil
+			m.approveDiffSum = ""
+			m.approveDiffChanged = false
 		}
 		return m, nil
 	}
 
+	if m.partialSelect {
+		return m.handleKeyPartialSelect(key)
+	}
 	if m.showDiff {
 		return m.handleKeyDiff(key)
 	}
+	if m.showCompare {
+		return m.handleKeyCompare(key)
+	}
+	if m.showSync {
+		return m.handleKeySync(key)
+	}
+	if m.showIssues {
+		if m.previewIssue != nil {
+			return m.handleKeyIssuePreview(key)
+		}
+		return m.handleKeyIssues(key)
+	}
 
 	if m.filterMode {
 		return m.handleKeyFilterMode(key)
@@ -839,7 +1462,7 @@ func (m Model) handleKeyLevel1(key string) (tea.Model, tea.Cmd) {
 	if pageSize < 1 {
 		pageSize = 1
 	}
-	totalJobs := len(m.jobs)
+	totalJobs := len(m.listRows())
 	totalPages := m.totalPages(totalJobs)
 
 	targetPage := m.page
@@ -870,14 +1493,18 @@ func (m Model) handleKeyLevel1(key string) (tea.Model, tea.Cmd) {
 		m.filterMode = true
 		m.filterStateBefore = m.filterState
 		m.filterProjectBefore = m.filterProject
+		m.filterTagBefore = m.filterTag
 		m.filterStateDraft = m.filterState
 		m.filterProjectDraft = m.filterProject
+		m.filterTagDraft = m.filterTag
 		m.filterCursorBefore = m.cursor
 	case "F":
 		m.filterState = filterAllState
 		m.filterProject = filterAllProject
+		m.filterTag = filterAnyTag
 		m.filterStateDraft = filterAllState
 		m.filterProjectDraft = filterAllProject
+		m.filterTagDraft = filterAnyTag
 		m.cursor = 0
 		return m.commitFilterDrafts()
 	case "esc":
@@ -885,13 +1512,15 @@ func (m Model) handleKeyLevel1(key string) (tea.Model, tea.Cmd) {
 			m.filterMode = false
 			m.filterState = m.filterStateBefore
 			m.filterProject = m.filterProjectBefore
+			m.filterTag = m.filterTagBefore
 			m.filterStateDraft = m.filterState
 			m.filterProjectDraft = m.filterProject
+			m.filterTagDraft = m.filterTag
 			m.cursor = m.filterCursorBefore
-			if len(m.jobs) == 0 {
+			if totalJobs == 0 {
 				m.cursor = 0
-			} else if m.cursor >= len(m.jobs) {
-				m.cursor = len(m.jobs) - 1
+			} else if m.cursor >= totalJobs {
+				m.cursor = totalJobs - 1
 			}
 			return m, m.fetchJobs
 		}
@@ -929,21 +1558,242 @@ func (m Model) handleKeyLevel1(key string) (tea.Model, tea.Cmd) {
 		m.sortAsc = !m.sortAsc
 		m.cursor = 0
 		return m, m.fetchJobs
-	case "enter":
-		if m.cursor < totalJobs {
-			m.selected = &m.jobs[m.cursor]
+	case "enter", " ":
+		if m.cursor >= totalJobs {
+			return m, nil
+		}
+		row := m.listRows()[m.cursor]
+		if row.job < 0 {
+			return m.toggleIssueExpanded(row.issueID), nil
+		}
+		if key == "enter" {
+			m.selected = &m.jobs[row.job]
+			m.diffSum = ""
+			m.pendingApprove = false
 			return m, m.fetchSessions
 		}
 	case "c":
-		if m.cursor < totalJobs && db.IsCancellableState(m.jobs[m.cursor].State) {
-			startConfirm(&m, "cancel", m.jobs[m.cursor].ID)
+		if job := m.cursorJob(); job != nil && db.IsCancellableState(job.State) {
+			startConfirm(&m, "cancel", job.ID)
+		}
+	case "t":
+		m.groupByIssue = !m.groupByIssue
+		m.page, m.cursor = 0, 0
+	case "h", "left":
+		if m.colOffset > 0 {
+			m.colOffset--
 		}
+	case "l", "right":
+		if _, _, hiddenRight := m.visibleListColumns(); hiddenRight > 0 {
+			m.colOffset++
+		}
+	case "y":
+		m.showSync = true
+		m.syncOffset = 0
+		return m, tea.Batch(m.fetchSyncRuns, m.fetchDashboard)
+	case "I":
+		m.showIssues = true
+		m.issueCursor = 0
+		m.issueNotice = ""
+		m.issueErr = nil
+		return m, m.fetchPlannedIssues
 	case "r":
-		return m, tea.Batch(m.fetchJobs, m.fetchIssueSummary)
-	}
+		return m, tea.Batch(m.fetchJobs, m.fetchDashboard, m.wakeDaemon)
+	case "w":
+		if m.onboarding() {
+			return m, m.runInitWizard()
+		}
+	case "D":
+		if m.onboarding() && !m.daemonRunning {
+			m.onboardNotice, m.onboardErr = "Starting the daemon...", nil
+			return m, m.startDaemon
+		}
+	}
+	return m, nil
+}
+
+// listRow is one row of the job list: a job, or in grouped mode the heading
+// of an issue with several jobs, which are listed under it while expanded.
+type listRow struct {
+	issueID string
+	job     int   // index into m.jobs, or -1 for an issue heading
+	jobs    []int // indices into m.jobs of the issue's jobs (headings only)
+	nested  bool  // job listed under its issue heading
+}
+
+// listRows returns the job list's rows: one per job, or in grouped mode one
+// per issue in the order its first job sorts, with the jobs of expanded
+// issues under it. Issues with a single job show just the job.
+func (m Model) listRows() []listRow {
+	if !m.groupByIssue {
+		rows := make([]listRow, len(m.jobs))
+		for i := range m.jobs {
+			rows[i] = listRow{issueID: m.jobs[i].AutoPRIssueID, job: i}
+		}
+		return rows
+	}
+
+	var order []string
+	byIssue := make(map[string][]int)
+	for i, job := range m.jobs {
+		if _, ok := byIssue[job.AutoPRIssueID]; !ok {
+			order = append(order, job.AutoPRIssueID)
+		}
+		byIssue[job.AutoPRIssueID] = append(byIssue[job.AutoPRIssueID], i)
+	}
+	var rows []listRow
+	for _, issueID := range order {
+		jobs := byIssue[issueID]
+		if len(jobs) == 1 {
+			rows = append(rows, listRow{issueID: issueID, job: jobs[0]})
+			continue
+		}
+		rows = append(rows, listRow{issueID: issueID, job: -1, jobs: jobs})
+		if m.expandedIssues[issueID] {
+			for _, idx := range jobs {
+				rows = append(rows, listRow{issueID: issueID, job: idx, nested: true})
+			}
+		}
+	}
+	return rows
+}
+
+// cursorJob returns the job under the list cursor, or nil when the cursor is
+// on an issue heading or the list is empty.
+func (m Model) cursorJob() *db.Job {
+	rows := m.listRows()
+	if m.cursor < 0 || m.cursor >= len(rows) || rows[m.cursor].job < 0 {
+		return nil
+	}
+	return &m.jobs[rows[m.cursor].job]
+}
+
+// toggleIssueExpanded shows or hides the jobs of issueID in grouped mode.
+func (m Model) toggleIssueExpanded(issueID string) Model {
+	expanded := make(map[string]bool, len(m.expandedIssues)+1)
+	for id, v := range m.expandedIssues {
+		expanded[id] = v
+	}
+	expanded[issueID] = !expanded[issueID]
+	m.expandedIssues = expanded
+	m.page, m.cursor = clampPageAndCursor(len(m.listRows()), m.page, m.cursor, m.pageSize)
+	return m
+}
+
+func (m Model) handleKeySync(key string) (tea.Model, tea.Cmd) {
+	avail := m.scrollHeight()
+	lines := m.syncStatusLines(time.Now())
+	switch key {
+	case "up", "k":
+		if m.syncOffset > 0 {
+			m.syncOffset--
+		}
+	case "down", "j":
+		if m.syncOffset < maxOffset(lines, avail) {
+			m.syncOffset++
+		}
+	case "u":
+		m.syncOffset = max(m.syncOffset-avail/2, 0)
+	case "d":
+		m.syncOffset = min(m.syncOffset+avail/2, maxOffset(lines, avail))
+	case "r":
+		return m, tea.Batch(m.fetchSyncRuns, m.fetchDashboard)
+	case "esc":
+		m.showSync = false
+		m.syncRuns = nil
+		m.syncOffset = 0
+	}
+	return m, nil
+}
+
+func (m Model) handleKeyIssues(key string) (tea.Model, tea.Cmd) {
+	switch key {
+	case "up", "k":
+		if m.issueCursor > 0 {
+			m.issueCursor--
+		}
+	case "down", "j":
+		if m.issueCursor < len(m.plannedIssues)-1 {
+			m.issueCursor++
+		}
+	case "g":
+		m.issueCursor = 0
+	case "G":
+		m.issueCursor = max(len(m.plannedIssues)-1, 0)
+	case "enter":
+		if m.issueCursor >= len(m.plannedIssues) {
+			return m, nil
+		}
+		issue := m.plannedIssues[m.issueCursor]
+		m.previewIssue = &issue
+		m.previewComments = nil
+		m.previewErr = nil
+		m.previewLoading = issueHasComments(issue.Source)
+		m.previewOffset = 0
+		m.previewLines = m.issuePreviewLines()
+		if m.previewLoading {
+			return m, m.fetchIssueComments(issue)
+		}
+	case "e":
+		if m.issueCursor < len(m.plannedIssues) {
+			return m, m.executeEnqueueIssue(m.plannedIssues[m.issueCursor])
+		}
+	case "r":
+		return m, m.fetchPlannedIssues
+	case "esc":
+		m.showIssues = false
+		m.plannedIssues = nil
+		m.issueCursor = 0
+		m.issueNotice = ""
+		m.issueErr = nil
+	}
+	return m, nil
+}
+
+func (m Model) handleKeyIssuePreview(key string) (tea.Model, tea.Cmd) {
+	avail := m.scrollHeight()
+	switch key {
+	case "up", "k":
+		if m.previewOffset > 0 {
+			m.previewOffset--
+		}
+	case "down", "j":
+		if m.previewOffset < maxOffset(m.previewLines, avail) {
+			m.previewOffset++
+		}
+	case "u":
+		m.previewOffset = max(m.previewOffset-avail/2, 0)
+	case "d":
+		m.previewOffset = min(m.previewOffset+avail/2, maxOffset(m.previewLines, avail))
+	case "e":
+		return m, m.executeEnqueueIssue(*m.previewIssue)
+	case "i":
+		if m.previewIssue.URL != "" {
+			return m, m.openPreviewIssue
+		}
+	case "r":
+		if issueHasComments(m.previewIssue.Source) {
+			m.previewLoading = true
+			m.previewLines = m.issuePreviewLines()
+			return m, m.fetchIssueComments(*m.previewIssue)
+		}
+	case "esc":
+		m = m.closeIssuePreview()
+	}
 	return m, nil
 }
 
+// closeIssuePreview returns from the issue preview to the planned issues.
+func (m Model) closeIssuePreview() Model {
+	m.previewIssue = nil
+	m.previewComments = nil
+	m.previewErr = nil
+	m.previewLoading = false
+	m.previewLines = nil
+	m.previewOffset = 0
+	return m
+}
+
 func (m Model) handleKeyFilterMode(key string) (tea.Model, tea.Cmd) {
 	switch key {
 	case "s":
@@ -952,24 +1802,31 @@ func (m Model) handleKeyFilterMode(key string) (tea.Model, tea.Cmd) {
 	case "p":
 		m.filterProjectDraft = m.nextFilterProject(m.filterProjectDraft)
 		return m.commitFilterDrafts()
+	case "t":
+		m.filterTagDraft = m.nextFilterTag(m.filterTagDraft)
+		return m.commitFilterDrafts()
 	case "F":
 		m.filterStateDraft = filterAllState
 		m.filterProjectDraft = filterAllProject
+		m.filterTagDraft = filterAnyTag
 		m.filterState = m.filterStateDraft
 		m.filterProject = m.filterProjectDraft
+		m.filterTag = m.filterTagDraft
 		m.cursor = 0
 		return m.commitFilterDrafts()
 	case "esc":
 		m.filterMode = false
 		m.filterState = m.filterStateBefore
 		m.filterProject = m.filterProjectBefore
+		m.filterTag = m.filterTagBefore
 		m.filterStateDraft = m.filterState
 		m.filterProjectDraft = m.filterProject
+		m.filterTagDraft = m.filterTag
 		m.cursor = m.filterCursorBefore
-		if len(m.jobs) == 0 {
+		if rows := len(m.listRows()); rows == 0 {
 			m.cursor = 0
-		} else if m.cursor >= len(m.jobs) {
-			m.cursor = len(m.jobs) - 1
+		} else if m.cursor >= rows {
+			m.cursor = rows - 1
 		}
 		return m, m.fetchJobs
 	case "f":
@@ -979,13 +1836,14 @@ func (m Model) handleKeyFilterMode(key string) (tea.Model, tea.Cmd) {
 }
 
 func (m Model) commitFilterDrafts() (tea.Model, tea.Cmd) {
-	changed := m.filterState != m.filterStateDraft || m.filterProject != m.filterProjectDraft
+	changed := m.filterState != m.filterStateDraft || m.filterProject != m.filterProjectDraft || m.filterTag != m.filterTagDraft
 	m.filterState = m.filterStateDraft
 	m.filterProject = m.filterProjectDraft
+	m.filterTag = m.filterTagDraft
 	if changed {
 		m.cursor = 0
 	}
-	return m, tea.Batch(m.fetchJobs, m.fetchIssueSummary)
+	return m, tea.Batch(m.fetchJobs, m.fetchDashboard)
 }
 
 func (m Model) nextFilterState(current string) string {
@@ -1032,11 +1890,39 @@ func (m Model) projectFilterOptions() []string {
 	return options
 }
 
+// nextFilterTag cycles through the tags of all jobs, then back to no tag
+// filter.
+func (m Model) nextFilterTag(current string) string {
+	jobs := m.allJobsCounts
+	if jobs == nil {
+		jobs = m.jobs
+	}
+	seen := map[string]struct{}{}
+	for _, job := range jobs {
+		for _, tag := range job.Tags {
+			seen[tag] = struct{}{}
+		}
+	}
+	options := make([]string, 0, len(seen)+1)
+	for tag := range seen {
+		options = append(options, tag)
+	}
+	sort.Strings(options)
+	options = append(options, filterAnyTag)
+	for i := range options {
+		if options[i] == current {
+			return options[(i+1)%len(options)]
+		}
+	}
+	return options[0]
+}
+
 type pipelineRowKind string
 
 const (
 	pipelineRowTest       pipelineRowKind = "test"
 	pipelineRowRebase     pipelineRowKind = "rebase"
+	pipelineRowDecisions  pipelineRowKind = "decisions"
 	pipelineRowCheckingCI pipelineRowKind = "checking_ci"
 	pipelineRowPRCreated  pipelineRowKind = "pr_created"
 	pipelineRowMerged     pipelineRowKind = "merged"
@@ -1059,7 +1945,7 @@ func (m Model) pipelineSyntheticRows() []pipelineSyntheticRow {
 	if job == nil {
 		return nil
 	}
-	rows := make([]pipelineSyntheticRow, 0, 6)
+	rows := make([]pipelineSyntheticRow, 0, 7)
 	if m.testArtifact != nil {
 		rows = append(rows, pipelineSyntheticRow{
 			kind:        pipelineRowTest,
@@ -1088,6 +1974,18 @@ func (m Model) pipelineSyntheticRows() []pipelineSyntheticRow {
 			duration:    "-",
 		})
 	}
+	if m.decisions != nil {
+		rows = append(rows, pipelineSyntheticRow{
+			kind:        pipelineRowDecisions,
+			stepLabel:   "decisions",
+			sessionStep: "decisions",
+			status:      "completed",
+			provider:    "-",
+			tokens:      "-",
+			start:       m.decisions.CreatedAt,
+			duration:    "-",
+		})
+	}
 	if m.shouldShowCheckingCIRow() {
 		rows = append(rows, pipelineSyntheticRow{
 			kind:        pipelineRowCheckingCI,
@@ -1256,6 +2154,9 @@ func (m Model) handleKeyLevel2(key string) (tea.Model, tea.Cmd) {
 			case pipelineRowRebase:
 				m = m.enterRebaseView()
 				return m, nil
+			case pipelineRowDecisions:
+				m = m.enterDecisionsView()
+				return m, nil
 			case pipelineRowCheckingCI:
 				m = m.enterCheckingCIView()
 				return m, nil
@@ -1278,6 +2179,11 @@ func (m Model) handleKeyLevel2(key string) (tea.Model, tea.Cmd) {
 		if m.selected != nil && m.selected.WorktreePath != "" {
 			return m, m.openInEditor
 		}
+	case "v":
+		if m.selected != nil && m.selected.Iteration > 0 {
+			m.actionErr = nil
+			return m, m.fetchCompare(-1)
+		}
 	case "b":
 		if m.selected != nil && m.selected.PRURL != "" {
 			return m, m.openInBrowser
@@ -1288,18 +2194,26 @@ func (m Model) handleKeyLevel2(key string) (tea.Model, tea.Cmd) {
 		}
 	case "a":
 		if m.selected != nil && m.selected.State == "ready" {
-			m.confirmDraft = false
-			startConfirm(&m, "approve", m.selected.ID)
+			return m.startApprove(false)
+		}
+		if m.selected != nil && m.selected.State == "needs_review_oversize" {
+			startConfirm(&m, "oversize-"+pipeline.OversizeAllow, m.selected.ID)
+		}
+	case "s":
+		if m.selected != nil && m.selected.State == "needs_review_oversize" {
+			startConfirm(&m, "oversize-"+pipeline.OversizeSplit, m.selected.ID)
 		}
 	case "A":
 		if m.selected != nil && m.selected.State == "ready" {
-			m.confirmDraft = true
-			startConfirm(&m, "approve", m.selected.ID)
+			return m.startApprove(true)
 		}
 	case "x":
 		if m.selected != nil && m.selected.State == "ready" {
 			startConfirm(&m, "reject", m.selected.ID)
 		}
+		if m.selected != nil && m.selected.State == "needs_review_oversize" {
+			startConfirm(&m, "oversize-"+pipeline.OversizeReject, m.selected.ID)
+		}
 	case "R":
 		if m.selected != nil && (m.selected.State == "failed" || m.selected.State == "rejected" || m.selected.State == "cancelled") {
 			startConfirm(&m, "retry", m.selected.ID)
@@ -1312,6 +2226,24 @@ func (m Model) handleKeyLevel2(key string) (tea.Model, tea.Cmd) {
 		if canMergePR(m.selected) {
 			startConfirm(&m, "merge", m.selected.ID)
 		}
+	case "F":
+		if pipeline.CanFollowUp(m.selected) {
+			startConfirm(&m, "follow-up", m.selected.ID)
+			m.confirmText = true
+			m.confirmTextBuf = ""
+		}
+	case "T":
+		if m.selected != nil {
+			startConfirm(&m, "tags", m.selected.ID)
+			m.confirmText = true
+			m.confirmTextBuf = strings.Join(m.selected.Tags, " ")
+		}
+	case "N":
+		if m.selected != nil {
+			startConfirm(&m, "note", m.selected.ID)
+			m.confirmText = true
+			m.confirmTextBuf = ""
+		}
 	case "esc":
 		m.confirmDraft = false
 		m.confirmText = false
@@ -1320,13 +2252,20 @@ func (m Model) handleKeyLevel2(key string) (tea.Model, tea.Cmd) {
 		m.sessions = nil
 		m.testArtifact = nil
 		m.rebaseArtifact = nil
+		m.decisions = nil
+		m.notes = nil
+		m.epicTasks = nil
 		m.sessCursor = 0
 		m.confirmAction = ""
 		m.confirmJobID = ""
+		m.approveDiffSum = ""
+		m.approveDiffChanged = false
+		m.pendingApprove = false
+		m.diffSum = ""
 		m.actionErr = nil
 		m.actionWarn = ""
 	case "r":
-		return m, tea.Batch(m.fetchJobs, m.fetchSessions, m.fetchIssueSummary)
+		return m, tea.Batch(m.fetchJobs, m.fetchSessions, m.fetchDashboard)
 	}
 	return m, nil
 }
@@ -1352,23 +2291,30 @@ func (m Model) handleKeyLevel3(key string) (tea.Model, tea.Cmd) {
 		if m.scrollOffset > maxOffset(m.lines, avail) {
 			m.scrollOffset = maxOffset(m.lines, avail)
 		}
+	case "c":
+		if m.sessionTab != sessionTabCommit {
+			return m, m.fetchSessionDiff
+		}
 	case "tab":
-		m.showInput = !m.showInput
+		m.sessionTab = m.sessionTab.next(len(m.selectedSession.ContextFiles) > 0)
 		m.scrollOffset = 0
-		if m.showInput {
-			if m.selectedSession.PromptText != "" {
-				m.lines = renderMarkdown(m.selectedSession.PromptText, m.cw())
-			} else {
-				m.lines = []string{"(no input recorded)"}
-			}
-		} else {
+		switch {
+		case m.sessionTab == sessionTabContext:
+			m.lines = contextLines(*m.selectedSession)
+		case m.selectedSession.PurgedAt != "":
+			m.lines = purgedLines(m.selectedSession.PurgedAt)
+		case m.sessionTab == sessionTabInput && m.selectedSession.PromptText != "":
+			m.lines = renderMarkdown(m.selectedSession.PromptText, m.cw())
+		case m.sessionTab == sessionTabInput:
+			m.lines = []string{"(no input recorded)"}
+		default:
 			m.lines = splitContent(m.selectedSession.ResponseText, m.selectedSession.Status, m.cw())
 		}
 	case "esc":
 		m.selectedSession = nil
 		m.lines = nil
 		m.scrollOffset = 0
-		m.showInput = false
+		m.sessionTab = sessionTabOutput
 	}
 	return m, nil
 }
@@ -1394,10 +2340,174 @@ func (m Model) handleKeyDiff(key string) (tea.Model, tea.Cmd) {
 		if m.diffOffset > maxOffset(m.diffLines, avail) {
 			m.diffOffset = maxOffset(m.diffLines, avail)
 		}
+	case "a", "A":
+		if m.selected != nil && m.selected.State == "ready" {
+			return m.startApprove(key == "A")
+		}
+	case "s":
+		if m.selected != nil && m.selected.State == "ready" {
+			m = m.enterPartialSelect()
+		}
 	case "esc":
+		// diffSum stays: it is what an approval covers.
+		m.showDiff = false
+		m.diffLines = nil
+		m.diffOffset = 0
+	}
+	return m, nil
+}
+
+// partialRow is one selectable line in the partial approval list: a file
+// header (hunk < 0) or one of its hunks.
+type partialRow struct {
+	file int
+	hunk int
+}
+
+func (m Model) enterPartialSelect() Model {
+	files := git.ParseDiff(strings.Join(m.diffLines, "\n"))
+	if len(files) == 0 {
+		return m
+	}
+	keep := make([][]bool, len(files))
+	for i, f := range files {
+		keep[i] = make([]bool, max(len(f.Hunks), 1))
+		for j := range keep[i] {
+			keep[i][j] = true
+		}
+	}
+	m.partialSelect = true
+	m.partialFiles = files
+	m.partialKeep = keep
+	m.partialCursor = 0
+	m.actionErr = nil
+	return m
+}
+
+func (m Model) partialRows() []partialRow {
+	var rows []partialRow
+	for i, f := range m.partialFiles {
+		rows = append(rows, partialRow{file: i, hunk: -1})
+		for j := range f.Hunks {
+			rows = append(rows, partialRow{file: i, hunk: j})
+		}
+	}
+	return rows
+}
+
+// partialExclusions converts the keep flags into the files/hunks to revert.
+func (m Model) partialExclusions() git.DiffExclusions {
+	excl := git.DiffExclusions{}
+	for i, f := range m.partialFiles {
+		var dropped []int
+		for j, keep := range m.partialKeep[i] {
+			if !keep {
+				dropped = append(dropped, j+1)
+			}
+		}
+		switch {
+		case len(dropped) == 0:
+		case len(dropped) == len(m.partialKeep[i]):
+			excl[f.Path] = nil
+		default:
+			excl[f.Path] = dropped
+		}
+	}
+	return excl
+}
+
+func (m Model) handleKeyPartialSelect(key string) (tea.Model, tea.Cmd) {
+	rows := m.partialRows()
+	switch key {
+	case "up", "k":
+		if m.partialCursor > 0 {
+			m.partialCursor--
+		}
+	case "down", "j":
+		if m.partialCursor < len(rows)-1 {
+			m.partialCursor++
+		}
+	case " ", "space":
+		if m.partialCursor >= len(rows) {
+			break
+		}
+		row := rows[m.partialCursor]
+		flags := m.partialKeep[row.file]
+		if row.hunk >= 0 {
+			flags[row.hunk] = !flags[row.hunk]
+			break
+		}
+		all := !slices.Contains(flags, false)
+		for j := range flags {
+			flags[j] = !all
+		}
+	case "enter":
+		excl := m.partialExclusions()
+		if err := git.ValidateExclusions(m.partialFiles, excl); err != nil && len(excl) > 0 {
+			m.actionErr = err
+			break
+		}
+		if len(excl) > 0 {
+			m.approveExclusions = excl
+		}
+		// The selection was made on the diff on screen.
+		m.approveDiffSum = m.diffSum
+		m.approveDiffChanged = false
+		m.partialSelect = false
+		m.partialFiles = nil
+		m.partialKeep = nil
+		m.partialCursor = 0
 		m.showDiff = false
 		m.diffLines = nil
 		m.diffOffset = 0
+		m.confirmDraft = false
+		startConfirm(&m, "approve", m.selected.ID)
+	case "esc":
+		m.partialSelect = false
+		m.partialFiles = nil
+		m.partialKeep = nil
+		m.partialCursor = 0
+		m.actionErr = nil
+	}
+	return m, nil
+}
+
+func (m Model) handleKeyCompare(key string) (tea.Model, tea.Cmd) {
+	avail := m.scrollHeight()
+	switch key {
+	case "up", "k":
+		if m.compareOffset > 0 {
+			m.compareOffset--
+		}
+	case "down", "j":
+		if m.compareOffset < maxOffset(m.compareLines, avail) {
+			m.compareOffset++
+		}
+	case "u":
+		m.compareOffset -= avail / 2
+		if m.compareOffset < 0 {
+			m.compareOffset = 0
+		}
+	case "d":
+		m.compareOffset += avail / 2
+		if m.compareOffset > maxOffset(m.compareLines, avail) {
+			m.compareOffset = maxOffset(m.compareLines, avail)
+		}
+	case "left", "h":
+		if m.compareIdx > 1 {
+			return m, m.fetchCompare(m.compareIdx - 1)
+		}
+	case "right", "l":
+		if m.compareIdx < len(m.compareIterations)-1 {
+			return m, m.fetchCompare(m.compareIdx + 1)
+		}
+	case "esc":
+		m.showCompare = false
+		m.compareIterations = nil
+		m.compareIdx = 0
+		m.compareLines = nil
+		m.compareDiffStart = 0
+		m.compareOffset = 0
 	}
 	return m, nil
 }
@@ -1423,7 +2533,7 @@ func (m Model) testStatus() string {
 func (m Model) enterTestView() Model {
 	testCmd := "(no test command configured)"
 	if p, ok := m.cfg.ProjectByName(m.selected.ProjectName); ok && p.TestCmd != "" {
-		testCmd = fmt.Sprintf("$ %s", p.TestCmd)
+		testCmd = "$ " + strings.Join(p.TestCommands(), "\n$ ")
 	}
 	m.selectedSession = &db.LLMSession{
 		Step:         "tests",
@@ -1434,7 +2544,7 @@ func (m Model) enterTestView() Model {
 		PromptText:   testCmd,
 		CreatedAt:    m.testArtifact.CreatedAt,
 	}
-	m.showInput = false
+	m.sessionTab = sessionTabOutput
 	m.scrollOffset = 0
 	m.lines = splitContent(m.selectedSession.ResponseText, m.selectedSession.Status, m.cw())
 	return m
@@ -1473,7 +2583,24 @@ func (m Model) enterRebaseView() Model {
 		PromptText:   "git rebase onto base branch",
 		CreatedAt:    m.rebaseArtifact.CreatedAt,
 	}
-	m.showInput = false
+	m.sessionTab = sessionTabOutput
+	m.scrollOffset = 0
+	m.lines = splitContent(m.selectedSession.ResponseText, m.selectedSession.Status, m.cw())
+	return m
+}
+
+// enterDecisionsView enters Level 3 to display the job's decisions log.
+func (m Model) enterDecisionsView() Model {
+	m.selectedSession = &db.LLMSession{
+		Step:         "decisions",
+		Iteration:    m.decisions.Iteration,
+		LLMProvider:  "-",
+		Status:       "completed",
+		ResponseText: m.decisions.Content,
+		PromptText:   "ap decisions --comment posts this log to the PR",
+		CreatedAt:    m.decisions.CreatedAt,
+	}
+	m.sessionTab = sessionTabOutput
 	m.scrollOffset = 0
 	m.lines = splitContent(m.selectedSession.ResponseText, m.selectedSession.Status, m.cw())
 	return m
@@ -1516,7 +2643,7 @@ func (m Model) enterCheckingCIView() Model {
 		PromptText:   "(polled by sync loop)",
 		CreatedAt:    createdAt,
 	}
-	m.showInput = false
+	m.sessionTab = sessionTabOutput
 	m.scrollOffset = 0
 	m.lines = renderMarkdown(content, m.cw())
 	return m
@@ -1533,7 +2660,7 @@ func (m Model) enterMergedView() Model {
 		PromptText:   "(detected by sync loop)",
 		CreatedAt:    m.selected.PRMergedAt,
 	}
-	m.showInput = false
+	m.sessionTab = sessionTabOutput
 	m.scrollOffset = 0
 	m.lines = renderMarkdown(content, m.cw())
 	return m
@@ -1551,7 +2678,7 @@ func (m Model) enterPRView() Model {
 		PromptText:   fmt.Sprintf("ap approve %s", db.ShortID(m.selected.ID)),
 		CreatedAt:    m.selected.CompletedAt,
 	}
-	m.showInput = false
+	m.sessionTab = sessionTabOutput
 	m.scrollOffset = 0
 	m.lines = renderMarkdown(content, m.cw())
 	return m
@@ -1568,7 +2695,7 @@ func (m Model) enterPRClosedView() Model {
 		PromptText:   "(detected by sync loop)",
 		CreatedAt:    m.selected.PRClosedAt,
 	}
-	m.showInput = false
+	m.sessionTab = sessionTabOutput
 	m.scrollOffset = 0
 	m.lines = renderMarkdown(content, m.cw())
 	return m
@@ -1586,6 +2713,14 @@ func (m Model) View() string {
 		content = fmt.Sprintf("Error: %v\n\nPress q to quit.", m.err)
 	} else if m.showDiff {
 		content = m.diffView()
+	} else if m.showCompare {
+		content = m.compareView()
+	} else if m.showSync {
+		content = m.syncView()
+	} else if m.showIssues && m.previewIssue != nil {
+		content = m.issuePreviewView()
+	} else if m.showIssues {
+		content = m.issuesView()
 	} else if m.selectedSession != nil {
 		content = m.sessionView()
 	} else if m.selected != nil {
@@ -1605,7 +2740,8 @@ func (m Model) listView() string {
 	if pageSize < 1 {
 		pageSize = 1
 	}
-	page, _ := clampPageAndCursor(len(m.jobs), m.page, m.cursor, pageSize)
+	rows := m.listRows()
+	page, _ := clampPageAndCursor(len(rows), m.page, m.cursor, pageSize)
 
 	// ── Title bar ──
 	b.WriteString(titleStyle.Render("AUTOPR"))
@@ -1616,7 +2752,11 @@ func (m Model) listView() string {
 	// ── Dashboard status — one row per stat ──
 	daemonDot := dotStopped
 	daemonLabel := "stopped"
-	if m.daemonRunning {
+	switch {
+	case m.daemonRunning && m.daemonIdle:
+		daemonDot = dotRunning
+		daemonLabel = "idle" + dimStyle.Render("  (workers stopped; r wakes)")
+	case m.daemonRunning:
 		daemonDot = dotRunning
 		daemonLabel = "running"
 	}
@@ -1625,14 +2765,36 @@ func (m Model) listView() string {
 		b.WriteString(fmt.Sprintf("  %s  %s\n", labelStyle.Render(padRight(k, 9)), v))
 	}
 	dashKV("daemon", daemonDot+" "+daemonLabel)
-	dashKV("sync", m.cfg.Daemon.SyncInterval)
+	syncRow := m.cfg.Daemon.SyncInterval
+	if projects := formatProjectSyncs(m.cfg.Projects, m.syncStatuses, m.disabledProjects, time.Now()); projects != "" {
+		syncRow += "  " + projects
+	}
+	dashKV("sync", syncRow)
+	if syncErrors := formatSyncErrors(m.syncStatuses, m.disabledProjects); syncErrors != "" {
+		dashKV("sync err", syncErrors)
+	}
 	dashKV("workers", fmt.Sprintf("%d", m.cfg.Daemon.MaxWorkers))
+	if api := formatRateLimits(m.rateLimits, time.Now()); api != "" {
+		dashKV("api", api)
+	}
+	if notifications := formatNotificationCounts(m.notificationCounts); notifications != "" {
+		dashKV("notify", notifications)
+	}
+	if len(m.disabledProjects) > 0 {
+		dashKV("disabled", stateStyle["cancelled"].Render(strings.Join(m.disabledProjects, ", "))+dimStyle.Render("  (ap project enable <name>)"))
+	}
+	if queue := formatStaleQueue(m.staleQueued, m.cfg.Daemon.QueueStaleAfter); queue != "" {
+		dashKV("queue", queue)
+	}
+	if m.load != nil {
+		dashKV("load", formatLoad(*m.load, sysload.LimitsFor(m.cfg)))
+	}
 	b.WriteString("\n")
 
 	// Job state counters.
 	counts := m.jobCounts()
 	active := counts["planning"] + counts["implementing"] + counts["reviewing"] + counts["testing"] +
-		counts["rebasing"] + counts["resolving_conflicts"] + counts["awaiting_checks"]
+		counts["rebasing"] + counts["resolving_conflicts"] + counts["awaiting_checks"] + counts["waiting_network"]
 	b.WriteString(fmt.Sprintf("  %s %d   %s %d   %s %d   %s %d   %s %d\n",
 		labelStyle.Render("queued"), counts["queued"],
 		labelStyle.Render("active"), active,
@@ -1640,13 +2802,18 @@ func (m Model) listView() string {
 		stateStyle["failed"].Render("failed"), counts["failed"],
 		stateStyle["cancelled"].Render("cancelled"), counts["cancelled"],
 	))
-	b.WriteString(fmt.Sprintf("  %s %d   %s %d\n",
+	b.WriteString(fmt.Sprintf("  %s %d   %s %d   %s %d   %s %d\n",
 		stateStyle["rebasing"].Render("rebasing"), counts["rebasing"],
 		stateStyle["resolving_conflicts"].Render("resolving"), counts["resolving_conflicts"],
+		stateStyle["waiting_network"].Render("offline"), counts["waiting_network"],
+		stateStyle["oversize"].Render("oversize"), counts["needs_review_oversize"],
 	))
-	if m.filterState != filterAllState || m.filterProject != filterAllProject {
-		b.WriteString(dimStyle.Render(fmt.Sprintf("  Filter: state=%s  project=%s\n",
-			m.filterState, m.filterProject)))
+	if m.filterState != filterAllState || m.filterProject != filterAllProject || m.filterTag != filterAnyTag {
+		filter := fmt.Sprintf("  Filter: state=%s  project=%s", m.filterState, m.filterProject)
+		if m.filterTag != filterAnyTag {
+			filter += "  tag=" + m.filterTag
+		}
+		b.WriteString(dimStyle.Render(filter + "\n"))
 	}
 	b.WriteString(fmt.Sprintf("  Issues: %d synced, %d eligible, %d skipped\n",
 		m.issueSummary.Synced, m.issueSummary.Eligible, m.issueSummary.Skipped))
@@ -1660,17 +2827,9 @@ func (m Model) listView() string {
 	b.WriteString("\n")
 
 	// ── Job table ──
-	const (
-		colJob     = 10
-		colState   = 20
-		colProject = 13
-		colSource  = 13
-		colRetry   = 8
-		colIssue   = 55
-		colUpdated = 19
-	)
-
-	if len(m.jobs) == 0 {
+	if m.onboarding() {
+		b.WriteString(m.onboardingView())
+	} else if len(m.jobs) == 0 {
 		b.WriteString(dimStyle.Render("No jobs found. Waiting for issues..."))
 		b.WriteString("\n")
 	} else {
@@ -1687,32 +2846,69 @@ func (m Model) listView() string {
 			return base
 		}
 
-		timestampLabel := "UPDATED"
-		if m.sortColumn == "created_at" {
-			timestampLabel = "CREATED"
-		}
-
+		columns, _, _ := m.visibleListColumns()
 		start := pageStart(page, pageSize)
-		end := min(start+pageSize, len(m.jobs))
-		header := "  " +
-			headerStyle.Render(padRight("JOB", colJob)) +
-			headerStyle.Render(padRight(sortLabel([]string{"state"}, "STATE"), colState)) +
-			headerStyle.Render(padRight(sortLabel([]string{"project"}, "PROJECT"), colProject)) +
-			headerStyle.Render(padRight("SOURCE", colSource)) +
-			headerStyle.Render(padRight("RETRY", colRetry)) +
-			headerStyle.Render(padRight("ISSUE", colIssue)) +
-			headerStyle.Render(padRight(sortLabel([]string{"updated_at", "created_at"}, timestampLabel), colUpdated))
+		end := min(start+pageSize, len(rows))
+		header := "  "
+		for _, col := range columns {
+			title := col.title
+			switch col.name {
+			case "state":
+				title = sortLabel([]string{"state"}, title)
+			case "project":
+				title = sortLabel([]string{"project"}, title)
+			case "updated":
+				if m.sortColumn == "created_at" {
+					title = "CREATED"
+				}
+				title = sortLabel([]string{"updated_at", "created_at"}, title)
+			}
+			header += headerStyle.Render(padRight(truncate(title, col.width-1), col.width))
+		}
 		b.WriteString(header)
 		b.WriteString("\n")
 
-		for i, job := range m.jobs[start:end] {
+		now := time.Now()
+		for i, row := range rows[start:end] {
 			isSelected := start+i == m.cursor
 			cursor := "  "
 			if isSelected {
 				cursor = "> "
 			}
 
-			displayState := db.DisplayState(job.State, job.PRMergedAt, job.PRClosedAt)
+			var job db.Job
+			var displayState, jobCell, retry, title, updated string
+			if row.job < 0 {
+				// Issue heading: the newest job stands in for the issue.
+				jobs := make([]db.Job, len(row.jobs))
+				for k, idx := range row.jobs {
+					jobs[k] = m.jobs[idx]
+				}
+				job = newestJob(jobs)
+				displayState = issueRollup(jobs)
+				marker := "+"
+				if m.expandedIssues[row.issueID] {
+					marker = "-"
+				}
+				jobCell = fmt.Sprintf("%s %d jobs", marker, len(jobs))
+				title = job.Title()
+				if progress := epicProgress(jobs); progress != "" {
+					title = job.IssueTitle + " " + progress
+				}
+				for _, j := range jobs {
+					updated = max(updated, j.UpdatedAt)
+				}
+			} else {
+				job = m.jobs[row.job]
+				displayState = db.DisplayState(job.State, job.PRMergedAt, job.PRClosedAt)
+				jobCell = db.ShortID(job.ID)
+				retry = fmt.Sprintf("%d/%d", job.Iteration, job.MaxIterations)
+				title = taggedTitle(job)
+				if row.nested {
+					title = "  " + jobKind(job)
+				}
+				updated = job.UpdatedAt
+			}
 			st, ok := stateStyle[displayState]
 			if !ok {
 				st, ok = stateStyle[job.State]
@@ -1721,25 +2917,53 @@ func (m Model) listView() string {
 				}
 			}
 
-			source := ""
-			if job.IssueSource != "" && job.SourceIssueID != "" {
-				source = fmt.Sprintf("%s #%s", capitalize(job.IssueSource), job.SourceIssueID)
-			}
-
-			title := truncate(job.IssueTitle, colIssue-2)
-
-			updated := formatTimestampLocal(job.UpdatedAt, "2006-01-02 15:04:05")
 			textStyle := selectedCellStyle(plainStyle, isSelected)
-			stateCell := selectedCellStyle(st, isSelected)
-			dimCell := selectedCellStyle(dimStyle, isSelected)
-
-			line := textStyle.Render(cursor+padRight(db.ShortID(job.ID), colJob)) +
-				stateCell.Render(padRight(displayState, colState)) +
-				textStyle.Render(padRight(truncate(job.ProjectName, colProject-1), colProject)) +
-				textStyle.Render(padRight(source, colSource)) +
-				textStyle.Render(padRight(fmt.Sprintf("%d/%d", job.Iteration, job.MaxIterations), colRetry)) +
-				textStyle.Render(padRight(title, colIssue)) +
-				dimCell.Render(padRight(updated, colUpdated))
+			line := textStyle.Render(cursor)
+			for _, col := range columns {
+				cell, style := "", textStyle
+				switch col.name {
+				case "job":
+					cell = jobCell
+				case "state":
+					cell, style = displayState, selectedCellStyle(st, isSelected)
+				case "ci":
+					cell = formatCIProgress(job)
+					if job.CIChecks.Failed > 0 {
+						style = selectedCellStyle(stateStyle["failed"], isSelected)
+					}
+				case "due":
+					if row.job >= 0 && db.DeadlineApplies(job.State) {
+						var st lipgloss.Style
+						cell, st = formatTimeLeft(job.Deadline, now)
+						style = selectedCellStyle(st, isSelected)
+					}
+				case "risk":
+					if level := job.RiskLevel(); level != "" && row.job >= 0 {
+						cell, style = fmt.Sprintf("%s %d", level, job.RiskScore), selectedCellStyle(riskStyle[level], isSelected)
+					}
+				case "project":
+					cell = job.ProjectName
+				case "source":
+					if job.IssueSource != "" && job.SourceIssueID != "" {
+						cell = fmt.Sprintf("%s #%s", capitalize(job.IssueSource), job.SourceIssueID)
+					}
+				case "retry":
+					cell = retry
+				case "issue":
+					cell = truncate(title, col.width-2)
+				case "updated":
+					cell, style = formatTimestampLocal(updated, "2006-01-02 15:04:05"), selectedCellStyle(dimStyle, isSelected)
+				case "branch":
+					cell = job.BranchName
+				case "tokens":
+					if ts, ok := m.jobTokens[job.ID]; ok && row.job >= 0 {
+						cell = formatTokenCount(ts.TotalInputTokens) + "/" + formatTokenCount(ts.TotalOutputTokens)
+					}
+				case "pr":
+					cell = job.PRURL
+				}
+				line += style.Render(padRight(truncate(cell, col.width-1), col.width))
+			}
 			b.WriteString(line)
 			b.WriteString("\n")
 		}
@@ -1755,7 +2979,7 @@ func (m Model) listView() string {
 		}
 		return b.String()
 	}
-	pageCount := m.totalPages(len(m.jobs))
+	pageCount := m.totalPages(len(rows))
 	pageLabel := pageCount
 	pageNum := page + 1
 	if pageCount == 0 {
@@ -1764,7 +2988,7 @@ func (m Model) listView() string {
 	}
 	if m.filterMode {
 		// Filter mode: show only filter controls (navigation is disabled).
-		filterHints := []string{"FILTER:", "s state", "p project", "F clear all", "esc done", "q quit"}
+		filterHints := []string{"FILTER:", "s state", "p project", "t tag", "F clear all", "esc done", "q quit"}
 		b.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("214")).Render(strings.Join(filterHints, "  ")))
 	} else {
 		// Normal mode: primary nav line + secondary actions line.
@@ -1772,38 +2996,419 @@ func (m Model) listView() string {
 		if pageLabel > 1 {
 			line1 = append(line1, "n/pgdown next page", "p/pgup prev page")
 		}
-		line1 = append(line1, "enter details")
-		if m.cursor < len(m.jobs) && db.IsCancellableState(m.jobs[m.cursor].State) {
+		if m.cursor < len(rows) && rows[m.cursor].job < 0 {
+			line1 = append(line1, "enter expand/collapse")
+		} else {
+			line1 = append(line1, "enter details")
+		}
+		if job := m.cursorJob(); job != nil && db.IsCancellableState(job.State) {
 			line1 = append(line1, "c cancel")
 		}
+		if m.onboarding() {
+			line1 = append(line1, "w add project")
+			if !m.daemonRunning {
+				line1 = append(line1, "D start daemon")
+			}
+		}
 		line1 = append(line1, "r refresh", "q quit")
 		b.WriteString(dimStyle.Render(strings.Join(line1, "  ")))
 		b.WriteString("\n")
 
-		line2 := []string{"f filter", "F clear filters", "s sort", "S sort dir"}
+		group := "t group by issue"
+		if m.groupByIssue {
+			group = "t ungroup"
+		}
+		line2 := []string{"f filter", "F clear filters", "s sort", "S sort dir", group, "y sync status", "I planned issues"}
+		if _, left, right := m.visibleListColumns(); left > 0 || right > 0 {
+			line2 = append(line2, fmt.Sprintf("h/l scroll columns (%d left, %d right)", left, right))
+		}
 		b.WriteString(dimStyle.Render(strings.Join(line2, "  ")))
 	}
 	return b.String()
 }
 
-// ── Level 2: Job Detail + Session List ──────────────────────────────────────
+// listColumn is one column of the job list.
+type listColumn struct {
+	name     string
+	title    string
+	width    int
+	minWidth int // auto-fit shrinks the column down to this; 0 keeps its width
+}
 
-func (m Model) detailView() string {
-	var b strings.Builder
-	w := m.cw()
-	job := m.selected
+var listColumnSpecs = map[string]listColumn{
+	"job":     {title: "JOB", width: 10},
+	"state":   {title: "STATE", width: 20},
+	"ci":      {title: "CI", width: 17},
+	"due":     {title: "DUE", width: 12},
+	"risk":    {title: "RISK", width: 11},
+	"project": {title: "PROJECT", width: 13},
+	"source":  {title: "SOURCE", width: 14},
+	"retry":   {title: "RETRY", width: 8},
+	"issue":   {title: "ISSUE", width: 55, minWidth: 20},
+	"updated": {title: "UPDATED", width: 20},
+	"branch":  {title: "BRANCH", width: 30, minWidth: 12},
+	"tokens":  {title: "TOKENS", width: 14},
+	"pr":      {title: "PR", width: 45, minWidth: 16},
+}
 
-	displayState := db.DisplayState(job.State, job.PRMergedAt, job.PRClosedAt)
-	st, ok := stateStyle[displayState]
-	if !ok {
-		st, ok = stateStyle[job.State]
-		if !ok {
-			st = dimStyle
-		}
+// listFitOrder is the order in which auto-fit shrinks columns.
+var listFitOrder = []string{"issue", "pr", "branch"}
+
+// listColumnNames returns the configured job list columns, or the defaults.
+func (m Model) listColumnNames() []string {
+	if m.cfg != nil && len(m.cfg.TUI.Columns) > 0 {
+		return m.cfg.TUI.Columns
 	}
+	return config.TUIDefaultColumns
+}
 
-	b.WriteString(titleStyle.Render("JOB"))
-	b.WriteString(dimStyle.Render("  " + job.ID))
+// listColumns returns the job list columns with their widths. The CI column
+// is dropped while no job is waiting on checks, the due column while no
+// unfinished job has a deadline, and the risk column while no job has been
+// scored. Unless tui.fixed_widths is
+// set, wide columns shrink toward their minimum to fit a known terminal
+// width.
+func (m Model) listColumns() []listColumn {
+	showCI := slices.ContainsFunc(m.jobs, func(j db.Job) bool { return j.State == "awaiting_checks" })
+	showDue := slices.ContainsFunc(m.jobs, func(j db.Job) bool { return j.Deadline != "" && db.DeadlineApplies(j.State) })
+	showRisk := slices.ContainsFunc(m.jobs, func(j db.Job) bool { return j.RiskLevel() != "" })
+	var cols []listColumn
+	total := 0
+	for _, name := range m.listColumnNames() {
+		if (name == "ci" && !showCI) || (name == "due" && !showDue) || (name == "risk" && !showRisk) {
+			continue
+		}
+		col, ok := listColumnSpecs[name]
+		if !ok {
+			continue
+		}
+		col.name = name
+		if m.cfg != nil {
+			if w, ok := m.cfg.TUI.ColumnWidths[name]; ok {
+				col.width = w
+				col.minWidth = min(col.minWidth, w)
+			}
+		}
+		cols = append(cols, col)
+		total += col.width
+	}
+	if m.width <= 0 || (m.cfg != nil && m.cfg.TUI.FixedWidths) {
+		return cols
+	}
+	over := total - (m.cw() - 2)
+	for _, name := range listFitOrder {
+		for i := range cols {
+			if over <= 0 {
+				return cols
+			}
+			if cols[i].name != name || cols[i].minWidth == 0 {
+				continue
+			}
+			shrink := min(over, cols[i].width-cols[i].minWidth)
+			cols[i].width -= shrink
+			over -= shrink
+		}
+	}
+	return cols
+}
+
+// visibleListColumns returns the columns that fit the terminal after
+// scrolling colOffset columns to the left, and how many columns are hidden
+// on each side. The first column stays in place so rows remain
+// identifiable.
+func (m Model) visibleListColumns() ([]listColumn, int, int) {
+	cols := m.listColumns()
+	if m.width <= 0 || len(cols) < 2 {
+		return cols, 0, 0
+	}
+	avail := m.cw() - 2 - cols[0].width
+	rest := cols[1:]
+	offset := min(m.colOffset, len(rest)-1)
+	visible := []listColumn{cols[0]}
+	shown := 0
+	for _, col := range rest[offset:] {
+		if col.width > avail && shown > 0 {
+			break
+		}
+		visible = append(visible, col)
+		avail -= col.width
+		shown++
+	}
+	return visible, offset, len(rest) - offset - shown
+}
+
+// formatTokenCount abbreviates a token count, e.g. 950, 12.3k, 1.2M.
+func formatTokenCount(n int) string {
+	switch {
+	case n >= 1_000_000:
+		return fmt.Sprintf("%.1fM", float64(n)/1_000_000)
+	case n >= 1000:
+		return fmt.Sprintf("%.1fk", float64(n)/1000)
+	default:
+		return fmt.Sprintf("%d", n)
+	}
+}
+
+// ── Level 1s: Sync Status ───────────────────────────────────────────────────
+
+func (m Model) syncView() string {
+	var b strings.Builder
+	w := m.cw()
+
+	b.WriteString(titleStyle.Render("SYNC STATUS"))
+	b.WriteString(dimStyle.Render("  every " + m.cfg.Daemon.SyncInterval))
+	b.WriteString("\n")
+	b.WriteString(dimStyle.Render(strings.Repeat("─", w)))
+	b.WriteString("\n")
+
+	lines := m.syncStatusLines(time.Now())
+	avail := m.scrollHeight()
+	start, end := scrollWindow(lines, m.syncOffset, avail)
+	for _, line := range lines[start:end] {
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+
+	b.WriteString(dimStyle.Render(strings.Repeat("─", w)))
+	b.WriteString("\n")
+	b.WriteString(dimStyle.Render("j/k scroll  d/u half-page  r refresh  esc back  q quit" + scrollPercent(lines, m.syncOffset, avail)))
+	return b.String()
+}
+
+// syncStatusLines renders each configured project's last successful sync,
+// last error, and recent sync runs.
+func (m Model) syncStatusLines(now time.Time) []string {
+	if len(m.cfg.Projects) == 0 {
+		return []string{dimStyle.Render("No projects configured.")}
+	}
+	statuses := make(map[string]db.ProjectSyncStatus, len(m.syncStatuses))
+	for _, st := range m.syncStatuses {
+		statuses[st.ProjectName] = st
+	}
+	errStyle := stateStyle["failed"]
+
+	var lines []string
+	for _, p := range m.cfg.Projects {
+		name := headerStyle.Render(p.Name)
+		if slices.Contains(m.disabledProjects, p.Name) {
+			name += dimStyle.Render("  disabled")
+		}
+		lines = append(lines, name)
+
+		st := statuses[p.Name]
+		success := dimStyle.Render("never")
+		if t, ok := parseTimestamp(st.LastSuccessAt); ok {
+			success = formatTimestampLocal(st.LastSuccessAt, "2006-01-02 15:04:05") + dimStyle.Render(" ("+formatAge(now.Sub(t))+")")
+		}
+		lines = append(lines, "  "+labelStyle.Render(padRight("last sync", 11))+success)
+		if t, ok := parseTimestamp(st.LastErrorAt); ok {
+			errLine := formatTimestampLocal(st.LastErrorAt, "2006-01-02 15:04:05") + dimStyle.Render(" ("+formatAge(now.Sub(t))+")")
+			if syncFailing(st) {
+				errLine = errStyle.Render(errLine)
+			}
+			lines = append(lines, "  "+labelStyle.Render(padRight("last error", 11))+errLine)
+			for _, l := range wrapPlain(st.LastError, max(m.cw()-13, 20)) {
+				lines = append(lines, strings.Repeat(" ", 13)+l)
+			}
+		}
+
+		runs := m.syncRuns[p.Name]
+		if len(runs) > 0 {
+			lines = append(lines, "  "+labelStyle.Render("recent runs"))
+		}
+		for _, run := range runs {
+			result := sessStatusStyle["completed"].Render("ok")
+			if run.Error != "" {
+				result = errStyle.Render("failed: ") + truncate(run.Error, max(m.cw()-40, 20))
+			}
+			lines = append(lines, fmt.Sprintf("    %s  %s  %s",
+				formatTimestampLocal(run.FinishedAt, "2006-01-02 15:04:05"),
+				padRight(run.Duration.Round(100*time.Millisecond).String(), 8),
+				result))
+		}
+		lines = append(lines, "")
+	}
+	return lines
+}
+
+// ── Level 1i: Planned Issues ────────────────────────────────────────────────
+
+func (m Model) issuesView() string {
+	var b strings.Builder
+	w := m.cw()
+
+	b.WriteString(titleStyle.Render("PLANNED ISSUES"))
+	b.WriteString(dimStyle.Render(fmt.Sprintf("  %d open issues without a job", len(m.plannedIssues))))
+	b.WriteString("\n")
+	b.WriteString(dimStyle.Render(strings.Repeat("─", w)))
+	b.WriteString("\n")
+
+	lines := m.plannedIssueLines()
+	avail := m.scrollHeight()
+	start, end := scrollWindow(lines, max(m.issueCursor-avail+1, 0), avail)
+	for _, line := range lines[start:end] {
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+
+	b.WriteString(dimStyle.Render(strings.Repeat("─", w)))
+	b.WriteString("\n")
+	if m.issueErr != nil {
+		b.WriteString(stateStyle["failed"].Render("Error: " + m.issueErr.Error()))
+		b.WriteString("\n")
+	} else if m.issueNotice != "" {
+		b.WriteString(sessStatusStyle["completed"].Render(m.issueNotice))
+		b.WriteString("\n")
+	}
+	b.WriteString(dimStyle.Render("j/k move  enter preview  e enqueue now  r refresh  esc back  q quit"))
+	return b.String()
+}
+
+// plannedIssueLines renders one row per planned issue: whether it is
+// eligible, its project, source and number, title, and why it is skipped.
+func (m Model) plannedIssueLines() []string {
+	if len(m.plannedIssues) == 0 {
+		return []string{dimStyle.Render("No open issues without a job.")}
+	}
+	w := m.cw()
+	lines := make([]string, len(m.plannedIssues))
+	for i, issue := range m.plannedIssues {
+		mark := "✓"
+		if !issue.Eligible {
+			mark = "✗"
+		}
+		ref := capitalize(issue.Source) + " #" + issue.SourceIssueID
+		line := fmt.Sprintf("%s %s  %s  %s", mark, padRight(truncate(issue.ProjectName, 14), 14), padRight(truncate(ref, 14), 14), issue.Title)
+		if !issue.Eligible && issue.SkipReason != "" {
+			line += "  (" + issue.SkipReason + ")"
+		}
+		line = truncate(line, w)
+		switch {
+		case i == m.issueCursor:
+			line = selectedStyle.Render(line)
+		case !issue.Eligible:
+			line = dimStyle.Render(line)
+		}
+		lines[i] = line
+	}
+	return lines
+}
+
+// ── Level 1p: Issue Preview ─────────────────────────────────────────────────
+
+func (m Model) issuePreviewView() string {
+	var b strings.Builder
+	w := m.cw()
+	issue := m.previewIssue
+
+	b.WriteString(titleStyle.Render("ISSUE"))
+	b.WriteString(dimStyle.Render("  " + truncate(issue.Title, max(w-7, 10))))
+	b.WriteString("\n")
+	b.WriteString(dimStyle.Render(strings.Repeat("─", w)))
+	b.WriteString("\n")
+
+	avail := m.scrollHeight()
+	start, end := scrollWindow(m.previewLines, m.previewOffset, avail)
+	for _, line := range m.previewLines[start:end] {
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+
+	b.WriteString(dimStyle.Render(strings.Repeat("─", w)))
+	b.WriteString("\n")
+	if m.issueErr != nil {
+		b.WriteString(stateStyle["failed"].Render("Error: " + m.issueErr.Error()))
+		b.WriteString("\n")
+	}
+	hints := []string{"j/k scroll", "d/u half-page"}
+	if issue.Eligible {
+		hints = append(hints, "e enqueue now")
+	}
+	if issue.URL != "" {
+		hints = append(hints, "i open in browser")
+	}
+	hints = append(hints, "r reload comments", "esc back", "q quit")
+	b.WriteString(dimStyle.Render(strings.Join(hints, "  ") + scrollPercent(m.previewLines, m.previewOffset, avail)))
+	return b.String()
+}
+
+// issuePreviewLines renders the previewed issue: its metadata, labels and
+// eligibility, the body as markdown, and its comments once fetched.
+func (m Model) issuePreviewLines() []string {
+	issue := m.previewIssue
+	w := m.cw()
+	var lines []string
+	kv := func(k, v string) {
+		lines = append(lines, fmt.Sprintf("%s %s", headerStyle.Render(fmt.Sprintf("%-11s", k)), v))
+	}
+	kv("Project", issue.ProjectName)
+	kv("Issue", capitalize(issue.Source)+" #"+issue.SourceIssueID)
+	if issue.URL != "" {
+		kv("URL", issue.URL)
+	}
+	labels := dimStyle.Render("none")
+	if l := issue.Labels(); len(l) > 0 {
+		labels = strings.Join(l, ", ")
+	}
+	kv("Labels", labels)
+	if issue.Eligible {
+		kv("Eligible", sessStatusStyle["completed"].Render("yes"))
+	} else {
+		reason := "no"
+		if issue.SkipReason != "" {
+			reason += ": " + issue.SkipReason
+		}
+		kv("Eligible", stateStyle["failed"].Render(reason))
+	}
+	if issue.SourceUpdated != "" {
+		kv("Updated", formatTimestampLocal(issue.SourceUpdated, "2006-01-02 15:04:05"))
+	}
+
+	lines = append(lines, "")
+	if strings.TrimSpace(issue.Body) == "" {
+		lines = append(lines, dimStyle.Render("(no description)"))
+	} else {
+		lines = append(lines, renderMarkdown(issue.Body, w)...)
+	}
+
+	lines = append(lines, "")
+	switch {
+	case !issueHasComments(issue.Source):
+		lines = append(lines, dimStyle.Render("(comments are not available for "+issue.Source+" issues)"))
+	case m.previewLoading:
+		lines = append(lines, dimStyle.Render("(loading comments…)"))
+	case m.previewErr != nil:
+		lines = append(lines, stateStyle["failed"].Render("Comments unavailable: "+m.previewErr.Error()))
+	case len(m.previewComments) == 0:
+		lines = append(lines, dimStyle.Render("(no comments)"))
+	default:
+		lines = append(lines, headerStyle.Render(fmt.Sprintf("Comments (%d)", len(m.previewComments))))
+		for _, c := range m.previewComments {
+			lines = append(lines, "", labelStyle.Render(c.Author)+dimStyle.Render("  "+formatTimestampLocal(c.CreatedAt, "2006-01-02 15:04")))
+			lines = append(lines, renderMarkdown(c.Body, w)...)
+		}
+	}
+	return lines
+}
+
+// ── Level 2: Job Detail + Session List ──────────────────────────────────────
+
+func (m Model) detailView() string {
+	var b strings.Builder
+	w := m.cw()
+	job := m.selected
+
+	displayState := db.DisplayState(job.State, job.PRMergedAt, job.PRClosedAt)
+	st, ok := stateStyle[displayState]
+	if !ok {
+		st, ok = stateStyle[job.State]
+		if !ok {
+			st = dimStyle
+		}
+	}
+
+	b.WriteString(titleStyle.Render("JOB"))
+	b.WriteString(dimStyle.Render("  " + job.ID))
 	b.WriteString("\n")
 	b.WriteString(dimStyle.Render(strings.Repeat("─", w)))
 	b.WriteString("\n")
@@ -1821,13 +3426,37 @@ func (m Model) detailView() string {
 	if job.IssueTitle != "" {
 		kv("Title", job.IssueTitle)
 	}
+	if job.Summary != "" {
+		kv("Summary", job.Summary)
+	}
 	kv("Retry", fmt.Sprintf("%d/%d", job.Iteration, job.MaxIterations))
+	switch {
+	case job.BackportBranch != "":
+		kv("Backport", fmt.Sprintf("of %s onto %s", db.ShortID(job.ParentJobID), job.BackportBranch))
+	case job.RevertCommit != "":
+		kv("Revert", "of "+db.ShortID(job.ParentJobID))
+	case job.BisectCmd != "":
+		kv("Bisect", fmt.Sprintf("%s..%s running %q", job.BisectGood, job.BisectBad, job.BisectCmd))
+	case job.ParentJobID != "":
+		kv("Follow-up", "of "+db.ShortID(job.ParentJobID))
+	case job.EpicIndex > 0:
+		kv("Epic task", formatEpicTask(*job, m.epicTasks))
+		if waits := formatEpicWaits(*job, m.epicTasks); waits != "" {
+			kv("Waits for", waits)
+		}
+	}
 	if job.BranchName != "" {
 		kv("Branch", job.BranchName)
 	}
+	if job.BaseBranch != "" {
+		kv("Base", job.BaseBranch+" (retargeted)")
+	}
 	if job.CommitSHA != "" {
 		kv("Commit", job.CommitSHA[:min(12, len(job.CommitSHA))])
 	}
+	if job.SignatureStatus != "" {
+		kv("Signatures", job.SignatureStatus)
+	}
 	if job.PRMergedAt != "" {
 		kv("Merged", stateStyle["merged"].Render(formatTimestampLocal(job.PRMergedAt, "2006-01-02 15:04:05")))
 	}
@@ -1840,6 +3469,29 @@ func (m Model) detailView() string {
 	if job.RejectReason != "" {
 		kv("Rejected", job.RejectReason)
 	}
+	if level := job.RiskLevel(); level != "" {
+		kv("Risk", riskStyle[level].Render(fmt.Sprintf("%s %d/100", level, job.RiskScore))+dimStyle.Render(" ("+job.RiskSummary+")"))
+	}
+	if job.CPUTimeMS > 0 || job.PeakRSSBytes > 0 {
+		kv("Resources", formatJobResources(*job))
+	}
+	if job.OversizeSummary != "" {
+		kv("Oversize", stateStyle["oversize"].Render(job.OversizeSummary))
+	}
+	if len(job.Tags) > 0 {
+		kv("Tags", strings.Join(job.Tags, ", "))
+	}
+	if job.Deadline != "" {
+		due := formatTimestampLocal(job.Deadline, "2006-01-02 15:04")
+		if db.DeadlineApplies(job.State) {
+			left, st := formatTimeLeft(job.Deadline, time.Now())
+			due += "  " + st.Render(left)
+		}
+		kv("Deadline", due)
+	}
+	for _, n := range m.notes {
+		kv("Note", dimStyle.Render(formatTimestamp(n.CreatedAt))+"  "+n.Body)
+	}
 	if m.actionErr != nil {
 		b.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Render(fmt.Sprintf("Action failed: %v", m.actionErr)))
 		b.WriteString("\n")
@@ -1970,6 +3622,9 @@ func (m Model) detailView() string {
 	if job.WorktreePath != "" {
 		hintParts = append(hintParts, "d diff", "o editor")
 	}
+	if job.Iteration > 0 {
+		hintParts = append(hintParts, "v compare")
+	}
 	if job.IssueURL != "" {
 		hintParts = append(hintParts, "i issue")
 	}
@@ -1979,16 +3634,22 @@ func (m Model) detailView() string {
 	if job.State == "ready" {
 		hintParts = append(hintParts, "a approve", "A draft", "x reject")
 	}
+	if job.State == "needs_review_oversize" {
+		hintParts = append(hintParts, "a allow", "s split", "x reject")
+	}
 	if canMergePR(job) {
 		hintParts = append(hintParts, "m merge")
 	}
+	if pipeline.CanFollowUp(job) {
+		hintParts = append(hintParts, "F follow-up")
+	}
 	if job.State == "failed" || job.State == "rejected" || job.State == "cancelled" {
 		hintParts = append(hintParts, "R retry")
 	}
 	if db.IsCancellableState(job.State) {
 		hintParts = append(hintParts, "c cancel")
 	}
-	hintParts = append(hintParts, "esc back", "r refresh", "q quit")
+	hintParts = append(hintParts, "T tags", "N note", "esc back", "r refresh", "q quit")
 	hints := strings.Join(hintParts, "  ")
 	b.WriteString(dimStyle.Render(hints))
 	return b.String()
@@ -2034,22 +3695,43 @@ func (m Model) sessionView() string {
 	kv("Tokens", fmt.Sprintf("%d in / %d out", sess.InputTokens, sess.OutputTokens))
 	kv("Start Time", formatTimestamp(sess.CreatedAt))
 	kv("Duration", formatDuration(sess.DurationMS))
+	if len(sess.ContextFiles) > 0 {
+		paths := make([]string, len(sess.ContextFiles))
+		for i, f := range sess.ContextFiles {
+			paths[i] = f.Path
+		}
+		kv("Context", strings.Join(paths, ", "))
+	}
+	if sess.CommitSHA != "" {
+		commit := shortCommit(sess.CommitSHA)
+		if sess.BaseSHA != "" {
+			commit = shortCommit(sess.BaseSHA) + ".." + commit
+		}
+		kv("Commit", commit)
+	}
 	if sess.ErrorMessage != "" {
 		kv("Error", lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Render(sess.ErrorMessage))
 	}
 
 	// Tab bar.
 	b.WriteString("\n")
-	inputTab := inactiveTab.Render(" INPUT ")
-	outputTab := inactiveTab.Render(" OUTPUT ")
-	if m.showInput {
-		inputTab = activeTab.Render(" INPUT ")
-	} else {
-		outputTab = activeTab.Render(" OUTPUT ")
+	tabs := []sessionTab{sessionTabInput, sessionTabOutput}
+	if len(sess.ContextFiles) > 0 {
+		tabs = append(tabs, sessionTabContext)
+	}
+	if m.sessionTab == sessionTabCommit {
+		tabs = append(tabs, sessionTabCommit)
+	}
+	for i, tab := range tabs {
+		if i > 0 {
+			b.WriteString(dimStyle.Render(" │ "))
+		}
+		style := inactiveTab
+		if tab == m.sessionTab {
+			style = activeTab
+		}
+		b.WriteString(style.Render(" " + tab.String() + " "))
 	}
-	b.WriteString(inputTab)
-	b.WriteString(dimStyle.Render(" │ "))
-	b.WriteString(outputTab)
 	b.WriteString("\n")
 	b.WriteString(dimStyle.Render(strings.Repeat("─", w)))
 	b.WriteString("\n")
@@ -2065,7 +3747,7 @@ func (m Model) sessionView() string {
 	b.WriteString(dimStyle.Render(strings.Repeat("─", w)))
 	b.WriteString("\n")
 	pct := scrollPercent(m.lines, m.scrollOffset, avail)
-	b.WriteString(dimStyle.Render(fmt.Sprintf("j/k scroll  d/u half-page  tab toggle  esc back  q quit%s", pct)))
+	b.WriteString(dimStyle.Render(fmt.Sprintf("j/k scroll  d/u half-page  tab toggle  c commit diff  esc back  q quit%s", pct)))
 	return b.String()
 }
 
@@ -2084,6 +3766,18 @@ func (m Model) diffView() string {
 	b.WriteString("\n")
 
 	avail := m.scrollHeight()
+	if m.partialSelect {
+		b.WriteString(m.partialSelectBody(avail))
+		b.WriteString(dimStyle.Render(strings.Repeat("─", w)))
+		b.WriteString("\n")
+		if m.actionErr != nil {
+			b.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Render("Error: " + m.actionErr.Error()))
+			b.WriteString("\n")
+		}
+		b.WriteString(dimStyle.Render("j/k move  space toggle  enter approve selection  esc back  q quit"))
+		return b.String()
+	}
+
 	start, end := scrollWindow(m.diffLines, m.diffOffset, avail)
 	for _, line := range m.diffLines[start:end] {
 		b.WriteString(colorDiffLine(line))
@@ -2092,11 +3786,179 @@ func (m Model) diffView() string {
 
 	b.WriteString(dimStyle.Render(strings.Repeat("─", w)))
 	b.WriteString("\n")
+	if m.confirmAction != "" {
+		b.WriteString(lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("214")).Render(m.confirmPrompt()))
+		b.WriteString(dimStyle.Render("  y confirm  n cancel"))
+		return b.String()
+	}
 	pct := scrollPercent(m.diffLines, m.diffOffset, avail)
-	b.WriteString(dimStyle.Render(fmt.Sprintf("j/k scroll  d/u half-page  esc back  q quit%s", pct)))
+	hint := "j/k scroll  d/u half-page  esc back  q quit"
+	if m.selected != nil && m.selected.State == "ready" {
+		hint = "j/k scroll  d/u half-page  a/A approve  s select for approval  esc back  q quit"
+	}
+	b.WriteString(dimStyle.Render(hint + pct))
+	return b.String()
+}
+
+// partialSelectBody renders the file/hunk checklist used for partial approval.
+func (m Model) partialSelectBody(avail int) string {
+	rows := m.partialRows()
+	lines := make([]string, len(rows))
+	for i, row := range rows {
+		flags := m.partialKeep[row.file]
+		var line string
+		if row.hunk < 0 {
+			mark := "[x]"
+			switch {
+			case !slices.Contains(flags, true):
+				mark = "[ ]"
+			case slices.Contains(flags, false):
+				mark = "[~]"
+			}
+			line = mark + " " + m.partialFiles[row.file].Path
+		} else {
+			mark := "[ ]"
+			if flags[row.hunk] {
+				mark = "[x]"
+			}
+			line = "    " + mark + " " + m.partialFiles[row.file].Hunks[row.hunk].Header
+		}
+		line = truncate(line, m.cw())
+		if i == m.partialCursor {
+			line = selectedStyle.Render(line)
+		}
+		lines[i] = line
+	}
+
+	var b strings.Builder
+	start, end := scrollWindow(lines, max(m.partialCursor-avail+1, 0), avail)
+	for _, line := range lines[start:end] {
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
 	return b.String()
 }
 
+// ── Compare View ────────────────────────────────────────────────────────────
+
+func (m Model) compareView() string {
+	var b strings.Builder
+	w := m.cw()
+
+	b.WriteString(titleStyle.Render("COMPARE"))
+	if len(m.compareIterations) > m.compareIdx && m.compareIdx > 0 {
+		prev, cur := m.compareIterations[m.compareIdx-1], m.compareIterations[m.compareIdx]
+		b.WriteString(dimStyle.Render(fmt.Sprintf("  iteration %d → %d", prev.Iteration, cur.Iteration)))
+	}
+	if m.selected != nil {
+		b.WriteString(dimStyle.Render("  " + m.selected.ID))
+	}
+	b.WriteString("\n")
+	b.WriteString(dimStyle.Render(strings.Repeat("─", w)))
+	b.WriteString("\n")
+
+	avail := m.scrollHeight()
+	start, end := scrollWindow(m.compareLines, m.compareOffset, avail)
+	for i, line := range m.compareLines[start:end] {
+		if start+i >= m.compareDiffStart {
+			line = colorDiffLine(line)
+		}
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+
+	b.WriteString(dimStyle.Render(strings.Repeat("─", w)))
+	b.WriteString("\n")
+	pct := scrollPercent(m.compareLines, m.compareOffset, avail)
+	b.WriteString(dimStyle.Render(fmt.Sprintf("j/k scroll  d/u half-page  h/l prev/next iteration  esc back  q quit%s", pct)))
+	return b.String()
+}
+
+// buildCompareLines lays out the plan and review feedback of two iterations
+// side by side (older on the left), followed by the code diff between them.
+// It returns the lines and the index where the diff starts.
+func buildCompareLines(prev, cur db.JobIteration, diff string, diffErr error, width int) ([]string, int) {
+	left := fmt.Sprintf("iteration %d", prev.Iteration)
+	right := fmt.Sprintf("iteration %d", cur.Iteration)
+
+	var lines []string
+	lines = append(lines, headerStyle.Render("PLAN"))
+	if prev.Plan == cur.Plan {
+		lines = append(lines, dimStyle.Render(fmt.Sprintf("(unchanged since iteration %d)", cur.PlanIteration)), "")
+	} else {
+		lines = append(lines, sideBySide(left, prev.Plan, right, cur.Plan, width)...)
+		lines = append(lines, "")
+	}
+	lines = append(lines, headerStyle.Render("REVIEW FEEDBACK"))
+	lines = append(lines, sideBySide(left, prev.Review, right, cur.Review, width)...)
+	lines = append(lines, "")
+	lines = append(lines, headerStyle.Render(fmt.Sprintf("CODE  %s..%s", shortCommit(prev.CommitSHA), shortCommit(cur.CommitSHA))))
+
+	diffStart := len(lines)
+	switch {
+	case diffErr != nil:
+		lines = append(lines, fmt.Sprintf("(git diff error: %v)", diffErr))
+	case diff == "":
+		lines = append(lines, "(no changes)")
+	default:
+		lines = append(lines, strings.Split(strings.TrimRight(diff, "\n"), "\n")...)
+	}
+	return lines, diffStart
+}
+
+// sideBySide renders two texts in equal-width columns separated by a rule.
+func sideBySide(leftTitle, leftText, rightTitle, rightText string, width int) []string {
+	colW := max((width-3)/2, 10)
+	l := wrapPlain(leftText, colW)
+	r := wrapPlain(rightText, colW)
+
+	lines := []string{
+		dimStyle.Render(padRight(leftTitle, colW)) + " │ " + dimStyle.Render(rightTitle),
+	}
+	for i := range max(len(l), len(r)) {
+		var a, c string
+		if i < len(l) {
+			a = l[i]
+		}
+		if i < len(r) {
+			c = r[i]
+		}
+		lines = append(lines, padRight(a, colW)+" │ "+c)
+	}
+	return lines
+}
+
+// wrapPlain hard-wraps text to width columns without markdown rendering so
+// that both sides of a comparison stay aligned.
+func wrapPlain(text string, width int) []string {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return []string{"(none)"}
+	}
+	var out []string
+	for line := range strings.SplitSeq(text, "\n") {
+		line = strings.ReplaceAll(strings.TrimRight(line, " \r"), "\t", "    ")
+		runes := []rune(line)
+		if len(runes) == 0 {
+			out = append(out, "")
+			continue
+		}
+		for len(runes) > width {
+			out = append(out, string(runes[:width]))
+			runes = runes[width:]
+		}
+		out = append(out, string(runes))
+	}
+	return out
+}
+
+func shortCommit(sha string) string {
+	if sha == "" {
+		return "?"
+	}
+	return sha[:min(12, len(sha))]
+}
+
 func colorDiffLine(line string) string {
 	switch {
 	case strings.HasPrefix(line, "+++ ") || strings.HasPrefix(line, "--- "):
@@ -2109,6 +3971,8 @@ func colorDiffLine(line string) string {
 		return diffHunkStyle.Render(line)
 	case strings.HasPrefix(line, "diff --git"):
 		return diffMetaStyle.Render(line)
+	case strings.HasPrefix(line, git.DiffNotePrefix):
+		return diffNoteStyle.Render(line)
 	default:
 		return line
 	}
@@ -2117,6 +3981,24 @@ func colorDiffLine(line string) string {
 // ── Helpers ─────────────────────────────────────────────────────────────────
 func (m Model) computedPageSize() int {
 	size := m.height - 14
+	if formatRateLimits(m.rateLimits, time.Now()) != "" {
+		size-- // "api" dashboard row
+	}
+	if formatNotificationCounts(m.notificationCounts) != "" {
+		size-- // "notify" dashboard row
+	}
+	if len(m.staleQueued) > 0 {
+		size-- // "queue" dashboard row
+	}
+	if m.load != nil {
+		size-- // "load" dashboard row
+	}
+	if len(m.disabledProjects) > 0 {
+		size-- // "disabled" dashboard row
+	}
+	if formatSyncErrors(m.syncStatuses, m.disabledProjects) != "" {
+		size-- // "sync err" dashboard row
+	}
 	if size < 1 {
 		return 1
 	}
@@ -2200,6 +4082,15 @@ func (m Model) confirmPrompt() string {
 	short := db.ShortID(jobID)
 	switch m.confirmAction {
 	case "approve":
+		if m.approveDiffChanged {
+			if m.confirmDraft {
+				return "Diff changed since you reviewed it. Approve job " + short + " as it is now and create draft PR?"
+			}
+			return "Diff changed since you reviewed it. Approve job " + short + " as it is now and create PR?"
+		}
+		if len(m.approveExclusions) > 0 {
+			return fmt.Sprintf("Approve job %s, reverting %d excluded file(s), and create PR?", short, len(m.approveExclusions))
+		}
 		if m.confirmDraft {
 			return "Approve job " + short + " and create draft PR?"
 		}
@@ -2212,6 +4103,12 @@ func (m Model) confirmPrompt() string {
 		return "Retry job " + short + "?"
 	case "cancel":
 		return "Cancel job " + short + "? (y/n)"
+	case "oversize-" + pipeline.OversizeAllow:
+		return "Allow oversize diff for job " + short + " and mark it ready?"
+	case "oversize-" + pipeline.OversizeSplit:
+		return "Reject job " + short + " and retry with a smaller change?"
+	case "oversize-" + pipeline.OversizeReject:
+		return "Reject oversize job " + short + "?"
 	default:
 		return ""
 	}
@@ -2227,8 +4124,15 @@ func canMergePR(job *db.Job) bool {
 
 func (m Model) confirmTextPrompt() string {
 	label := "Reason"
-	if m.confirmAction == "retry" {
+	switch m.confirmAction {
+	case "retry":
 		label = "Notes"
+	case "follow-up":
+		label = "Follow-up instructions"
+	case "tags":
+		label = "Tags, space-separated"
+	case "note":
+		label = "Note"
 	}
 	return fmt.Sprintf("%s (Enter to submit, Esc to cancel): %s█", label, m.confirmTextBuf)
 }
@@ -2255,11 +4159,7 @@ func isDaemonRunning(pidFile string) bool {
 	if err != nil {
 		return false
 	}
-	proc, err := os.FindProcess(pid)
-	if err != nil {
-		return false
-	}
-	return proc.Signal(syscall.Signal(0)) == nil
+	return daemon.ProcessAlive(pid)
 }
 
 func scrollWindow(lines []string, offset, avail int) (int, int) {
@@ -2324,6 +4224,14 @@ func formatTimestamp(ts string) string {
 	return t.Format("2006-01-02 15:04:05")
 }
 
+// taggedTitle prefixes a job's title with its tags, if any.
+func taggedTitle(job db.Job) string {
+	if len(job.Tags) == 0 {
+		return job.Title()
+	}
+	return "[" + strings.Join(job.Tags, ",") + "] " + job.Title()
+}
+
 func truncate(s string, max int) string {
 	if len(s) <= max {
 		return s
@@ -2348,3 +4256,481 @@ func padRight(s string, n int) string {
 	}
 	return s + strings.Repeat(" ", n-len(s))
 }
+
+// formatRateLimits renders the current API rate limits for the dashboard,
+// e.g. "api.github.com 4200/5000". Limits under 20% are highlighted with
+// their reset time; limits whose reset time has passed are omitted.
+func formatRateLimits(limits []db.APIRateLimit, now time.Time) string {
+	var parts []string
+	for _, rl := range limits {
+		if rl.Limit <= 0 {
+			continue
+		}
+		reset, hasReset := parseTimestamp(rl.ResetAt)
+		if hasReset && !reset.After(now) {
+			continue
+		}
+		name := rl.Host
+		if rl.Resource != "" && rl.Resource != "core" {
+			name += " " + rl.Resource
+		}
+		part := fmt.Sprintf("%s %d/%d", name, rl.Remaining, rl.Limit)
+		if rl.Remaining*5 < rl.Limit {
+			if hasReset {
+				part += " (resets " + reset.In(time.Local).Format("15:04") + ")"
+			}
+			part = lipgloss.NewStyle().Foreground(lipgloss.Color("214")).Render(part)
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, "  ")
+}
+
+// newestJob returns the most recently created of jobs.
+func newestJob(jobs []db.Job) db.Job {
+	newest := jobs[0]
+	for _, j := range jobs[1:] {
+		if j.CreatedAt > newest.CreatedAt {
+			newest = j
+		}
+	}
+	return newest
+}
+
+// issueRollup summarizes the jobs of one issue as a single status: that of
+// its newest job still in progress, otherwise "merged" once any job merged,
+// otherwise that of its newest job.
+func issueRollup(jobs []db.Job) string {
+	var inProgress []db.Job
+	merged := false
+	for _, j := range jobs {
+		if db.IsCancellableState(j.State) {
+			inProgress = append(inProgress, j)
+		}
+		if j.PRMergedAt != "" {
+			merged = true
+		}
+	}
+	if len(inProgress) > 0 {
+		j := newestJob(inProgress)
+		return db.DisplayState(j.State, j.PRMergedAt, j.PRClosedAt)
+	}
+	if merged {
+		return "merged"
+	}
+	j := newestJob(jobs)
+	return db.DisplayState(j.State, j.PRMergedAt, j.PRClosedAt)
+}
+
+// epicProgress renders how far the epic tasks among jobs got, e.g.
+// "(epic: 2/5 tasks merged)"; "" when none of jobs is an epic task.
+func epicProgress(jobs []db.Job) string {
+	tasks, merged := 0, 0
+	for _, j := range jobs {
+		if j.EpicIndex == 0 {
+			continue
+		}
+		tasks++
+		if j.PRMergedAt != "" {
+			merged++
+		}
+	}
+	if tasks == 0 {
+		return ""
+	}
+	return fmt.Sprintf("(epic: %d/%d tasks merged)", merged, tasks)
+}
+
+// formatEpicTask renders where an epic task job sits in its epic, e.g.
+// "2 of 5 (1 merged): Add the UI".
+func formatEpicTask(job db.Job, tasks []db.EpicTask) string {
+	if len(tasks) == 0 {
+		return fmt.Sprintf("%d: %s", job.EpicIndex, job.EpicTask)
+	}
+	merged := 0
+	for _, t := range tasks {
+		if t.PRMergedAt != "" {
+			merged++
+		}
+	}
+	return fmt.Sprintf("%d of %d (%d merged): %s", job.EpicIndex, len(tasks), merged, job.EpicTask)
+}
+
+// formatEpicWaits lists the tasks an epic task job waits for that have not
+// merged, e.g. "task 1 (8aeda806, ready)"; "" when it waits for none.
+func formatEpicWaits(job db.Job, tasks []db.EpicTask) string {
+	var deps []int
+	for _, t := range tasks {
+		if t.JobID == job.ID {
+			deps = t.DependsOn
+		}
+	}
+	var parts []string
+	for _, t := range tasks {
+		if slices.Contains(deps, t.Index) && t.PRMergedAt == "" {
+			parts = append(parts, fmt.Sprintf("task %d (%s, %s)", t.Index, db.ShortID(t.JobID), db.DisplayState(t.State, t.PRMergedAt, t.PRClosedAt)))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// jobKind describes how a job relates to the other jobs of its issue.
+func jobKind(job db.Job) string {
+	switch {
+	case job.EpicIndex > 0:
+		return fmt.Sprintf("task %d: %s", job.EpicIndex, job.EpicTask)
+	case job.BackportBranch != "":
+		return "backport onto " + job.BackportBranch
+	case job.RevertCommit != "":
+		return "revert of " + db.ShortID(job.ParentJobID)
+	case job.BisectCmd != "":
+		return "bisect"
+	case job.ParentJobID != "":
+		return "follow-up of " + db.ShortID(job.ParentJobID)
+	default:
+		return "attempt"
+	}
+}
+
+// formatCIProgress renders an awaiting_checks job's CI check counts from the
+// latest poll, e.g. "3/7 passed" or "3/7, 1 failing". Other jobs get "".
+func formatCIProgress(job db.Job) string {
+	if job.State != "awaiting_checks" {
+		return ""
+	}
+	return job.CIChecks.Progress()
+}
+
+// formatProjectSyncs renders how long ago each enabled project last synced
+// successfully for the dashboard, e.g. "api 2m ago  web failed 30s ago".
+// Projects whose latest sync failed are highlighted.
+func formatProjectSyncs(projects []config.ProjectConfig, statuses []db.ProjectSyncStatus, disabled []string, now time.Time) string {
+	byProject := make(map[string]db.ProjectSyncStatus, len(statuses))
+	for _, st := range statuses {
+		byProject[st.ProjectName] = st
+	}
+	var parts []string
+	for _, p := range projects {
+		if slices.Contains(disabled, p.Name) {
+			continue
+		}
+		st := byProject[p.Name]
+		switch {
+		case syncFailing(st):
+			lastErr, _ := parseTimestamp(st.LastErrorAt)
+			parts = append(parts, stateStyle["failed"].Render(fmt.Sprintf("%s failed %s", p.Name, formatAge(now.Sub(lastErr)))))
+		case st.LastSuccessAt != "":
+			lastOK, _ := parseTimestamp(st.LastSuccessAt)
+			parts = append(parts, fmt.Sprintf("%s %s", p.Name, formatAge(now.Sub(lastOK))))
+		default:
+			parts = append(parts, dimStyle.Render(p.Name+" not synced yet"))
+		}
+	}
+	return strings.Join(parts, "  ")
+}
+
+// formatSyncErrors lists the error of each enabled project whose latest sync
+// failed, or returns "" when none did.
+func formatSyncErrors(statuses []db.ProjectSyncStatus, disabled []string) string {
+	var parts []string
+	for _, st := range statuses {
+		if slices.Contains(disabled, st.ProjectName) || !syncFailing(st) {
+			continue
+		}
+		parts = append(parts, st.ProjectName+": "+truncate(st.LastError, 60))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return stateStyle["failed"].Render(strings.Join(parts, "; ")) + dimStyle.Render("  (y sync status)")
+}
+
+// syncFailing reports whether a project's most recent sync failed.
+func syncFailing(st db.ProjectSyncStatus) bool {
+	lastErr, hasErr := parseTimestamp(st.LastErrorAt)
+	if !hasErr {
+		return false
+	}
+	lastOK, hasOK := parseTimestamp(st.LastSuccessAt)
+	return !hasOK || lastErr.After(lastOK)
+}
+
+// ── Onboarding ──────────────────────────────────────────────────────────────
+
+// onboarding reports whether the job list shows the setup checklist: the
+// database has no jobs at all, so a filter cannot be what hides them.
+func (m Model) onboarding() bool {
+	return m.jobsLoaded && len(m.allJobsCounts) == 0 && len(m.jobs) == 0 &&
+		m.filterState == filterAllState && m.filterProject == filterAllProject && m.filterTag == filterAnyTag
+}
+
+// onboardingCheck is one row of the setup checklist.
+type onboardingCheck struct {
+	label  string
+	ok     bool
+	detail string
+}
+
+// onboardingChecks walks the steps between a fresh install and the first
+// job: a config with projects, a token for each project's issue source, a
+// running daemon, and a successful issue sync. A token counts as valid
+// until a sync of its project is rejected with HTTP 401.
+func onboardingChecks(cfg *config.Config, daemonRunning bool, statuses []db.ProjectSyncStatus) []onboardingCheck {
+	var checks []onboardingCheck
+
+	names := make([]string, 0, len(cfg.Projects))
+	for _, p := range cfg.Projects {
+		names = append(names, p.Name)
+	}
+	projects := onboardingCheck{label: "config", ok: len(names) > 0, detail: "no projects; press w to add the repository in this directory"}
+	if projects.ok {
+		projects.detail = fmt.Sprintf("%d project(s): %s", len(names), strings.Join(names, ", "))
+	}
+	checks = append(checks, projects)
+
+	byProject := make(map[string]db.ProjectSyncStatus, len(statuses))
+	for _, st := range statuses {
+		byProject[st.ProjectName] = st
+	}
+	var missing, rejected []string
+	for i := range cfg.Projects {
+		p := &cfg.Projects[i]
+		source, env, token := sourceToken(cfg, p)
+		if source == "" {
+			continue
+		}
+		if token == "" {
+			missing = append(missing, fmt.Sprintf("%s (set %s)", p.Name, env))
+			continue
+		}
+		if st := byProject[p.Name]; syncFailing(st) && strings.Contains(st.LastError, " API 401") {
+			rejected = append(rejected, fmt.Sprintf("%s (%s token rejected)", p.Name, source))
+		}
+	}
+	tokens := onboardingCheck{label: "tokens", ok: len(missing) == 0 && len(rejected) == 0, detail: "set for every issue source"}
+	switch {
+	case len(missing) > 0:
+		tokens.detail = "missing for " + strings.Join(missing, ", ") + "; set them with ap init or the env var, then restart ap"
+	case len(rejected) > 0:
+		tokens.detail = "invalid for " + strings.Join(rejected, ", ")
+	}
+	checks = append(checks, tokens)
+
+	running := onboardingCheck{label: "daemon", ok: daemonRunning, detail: "running"}
+	if !daemonRunning {
+		running.detail = "not running; press D to start it (ap start)"
+	}
+	checks = append(checks, running)
+
+	var synced, failing []string
+	for _, name := range names {
+		st := byProject[name]
+		switch {
+		case syncFailing(st):
+			failing = append(failing, name+": "+truncate(st.LastError, 60))
+		case st.LastSuccessAt != "":
+			synced = append(synced, name)
+		}
+	}
+	firstSync := onboardingCheck{label: "first sync", ok: len(names) > 0 && len(synced) == len(names)}
+	switch {
+	case len(failing) > 0:
+		firstSync.detail = "failed for " + strings.Join(failing, "; ") + " (y sync status)"
+	case len(names) == 0:
+		firstSync.detail = "waiting for a project"
+	case len(synced) == 0:
+		firstSync.detail = "not done yet; the daemon syncs issues every " + cfg.Daemon.SyncInterval
+	default:
+		firstSync.detail = fmt.Sprintf("done for %d of %d project(s); eligible issues become jobs", len(synced), len(names))
+	}
+	return append(checks, firstSync)
+}
+
+// sourceToken returns the issue source of p, the env var its token is read
+// from, and the token. The source is empty for projects that need no token:
+// local ones and GitHub projects using a GitHub App.
+func sourceToken(cfg *config.Config, p *config.ProjectConfig) (source, env, token string) {
+	switch {
+	case p.GitHub != nil && !p.GitHub.UsesApp():
+		return "github", "GITHUB_TOKEN", cfg.Tokens.GitHub
+	case p.GitLab != nil:
+		return "gitlab", "GITLAB_TOKEN", cfg.Tokens.GitLab
+	case p.Gitea != nil:
+		return "gitea", "GITEA_TOKEN", cfg.Tokens.Gitea
+	case p.Sentry != nil:
+		return "sentry", "SENTRY_TOKEN", cfg.Tokens.Sentry
+	}
+	return "", "", ""
+}
+
+// onboardingView renders the setup checklist shown in place of the empty
+// job list.
+func (m Model) onboardingView() string {
+	var b strings.Builder
+	b.WriteString(headerStyle.Render("Getting started"))
+	b.WriteString("\n\n")
+	for _, c := range onboardingChecks(m.cfg, m.daemonRunning, m.syncStatuses) {
+		mark := stateStyle["failed"].Render("✗")
+		if c.ok {
+			mark = stateStyle["ready"].Render("✓")
+		}
+		b.WriteString(fmt.Sprintf("  %s %s %s\n", mark, labelStyle.Render(padRight(c.label, 11)), c.detail))
+	}
+	b.WriteString("\n")
+	keys := "w add a project (ap init project)"
+	if !m.daemonRunning {
+		keys += "  D start the daemon (ap start)"
+	}
+	b.WriteString(dimStyle.Render("  " + keys))
+	b.WriteString("\n")
+	switch {
+	case m.onboardErr != nil:
+		b.WriteString(stateStyle["failed"].Render("  Error: " + m.onboardErr.Error()))
+		b.WriteString("\n")
+	case m.onboardNotice != "":
+		b.WriteString("  " + m.onboardNotice + "\n")
+	}
+	return b.String()
+}
+
+// apCommand returns an ap command run with the TUI's config.
+func (m Model) apCommand(args ...string) (*exec.Cmd, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("find ap executable: %w", err)
+	}
+	if m.configPath != "" {
+		args = append([]string{"--config", m.configPath}, args...)
+	}
+	return exec.Command(exe, args...), nil
+}
+
+// runInitWizard hands the terminal to `ap init project` for the repository
+// in the current directory and reloads the config when it returns.
+func (m Model) runInitWizard() tea.Cmd {
+	cmd, err := m.apCommand("init", "project")
+	if err != nil {
+		return func() tea.Msg { return onboardMsg{action: "init", err: err} }
+	}
+	var stderr strings.Builder
+	cmd.Stderr = io.MultiWriter(os.Stderr, &stderr)
+	return tea.ExecProcess(cmd, func(err error) tea.Msg {
+		return onboardMsg{action: "init", output: lastLine(stderr.String()), err: err}
+	})
+}
+
+// startDaemon runs `ap start`, which detaches the daemon and returns.
+func (m Model) startDaemon() tea.Msg {
+	cmd, err := m.apCommand("start")
+	if err != nil {
+		return onboardMsg{action: "start", err: err}
+	}
+	out, err := cmd.CombinedOutput()
+	return onboardMsg{action: "start", output: lastLine(string(out)), err: err}
+}
+
+// lastLine returns the last non-blank line of s.
+func lastLine(s string) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
+
+// formatTimeLeft renders the time until a deadline as a list badge, e.g.
+// "3d left" or "overdue 5h", styled by urgency: red once overdue, orange
+// within a day.
+func formatTimeLeft(deadline string, now time.Time) (string, lipgloss.Style) {
+	t, ok := parseTimestamp(deadline)
+	if !ok {
+		return "", dimStyle
+	}
+	left := t.Sub(now)
+	if left < 0 {
+		return "overdue " + strings.TrimSuffix(formatAge(-left), " ago"), stateStyle["failed"]
+	}
+	var text string
+	switch {
+	case left < time.Hour:
+		text = fmt.Sprintf("%dm left", int(left.Minutes()))
+	case left < 24*time.Hour:
+		text = fmt.Sprintf("%dh left", int(left.Hours()))
+	default:
+		text = fmt.Sprintf("%dd left", int(left.Hours()/24))
+	}
+	if left < 24*time.Hour {
+		return text, stateStyle["reviewing"]
+	}
+	return text, plainStyle
+}
+
+// formatAge renders a duration as a coarse age, e.g. "45s ago" or "3h ago".
+func formatAge(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds ago", max(int(d.Seconds()), 0))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd ago", int(d.Hours()/24))
+	}
+}
+
+// formatNotificationCounts summarizes undelivered notifications for the
+// dashboard, or returns "" when everything has been delivered.
+func formatNotificationCounts(counts map[string]int) string {
+	pending := counts[db.NotificationStatusPending] + counts[db.NotificationStatusProcessing]
+	failed := counts[db.NotificationStatusFailed]
+	dead := counts[db.NotificationStatusDead]
+	if pending+failed+dead == 0 {
+		return ""
+	}
+	var parts []string
+	if pending > 0 {
+		parts = append(parts, fmt.Sprintf("%d pending", pending))
+	}
+	if failed > 0 {
+		parts = append(parts, lipgloss.NewStyle().Foreground(lipgloss.Color("214")).Render(fmt.Sprintf("%d retrying", failed)))
+	}
+	if dead > 0 {
+		parts = append(parts, stateStyle["failed"].Render(fmt.Sprintf("%d dead", dead)))
+	}
+	return strings.Join(parts, ", ") + dimStyle.Render("  (ap notifications)")
+}
+
+// formatLoad renders the machine's load and free memory for the dashboard,
+// e.g. "0.52/CPU (8 CPUs)  mem 3.2 of 15.6 GiB free", with the reason job
+// claims are held while it is past limits.
+func formatLoad(s sysload.Sample, limits sysload.Limits) string {
+	text := fmt.Sprintf("%.2f/CPU (%d CPUs)  mem %.1f of %.1f GiB free", s.LoadPerCPU(), s.CPUs, float64(s.MemAvailable)/(1<<30), float64(s.MemTotal)/(1<<30))
+	if reason := limits.Exceeded(s); reason != "" {
+		text += "  " + lipgloss.NewStyle().Foreground(lipgloss.Color("214")).Render("claims held: "+reason)
+	}
+	return text
+}
+
+// formatJobResources renders what the job's local commands used, e.g.
+// "cpu 2m14s, peak 1.3 GiB".
+func formatJobResources(job db.Job) string {
+	cpu := time.Duration(job.CPUTimeMS) * time.Millisecond
+	if cpu >= time.Second {
+		cpu = cpu.Round(time.Second)
+	}
+	peak := fmt.Sprintf("%d MiB", job.PeakRSSBytes>>20)
+	if job.PeakRSSBytes >= 1<<30 {
+		peak = fmt.Sprintf("%.1f GiB", float64(job.PeakRSSBytes)/(1<<30))
+	}
+	return fmt.Sprintf("cpu %s, peak %s", cpu, peak)
+}
+
+// formatStaleQueue renders the queue aging indicator for the dashboard, e.g.
+// "2 queued > 24h, oldest 1a2b3c4d: all 3 workers are busy".
+func formatStaleQueue(stale []queuewatch.StaleJob, threshold string) string {
+	if len(stale) == 0 {
+		return ""
+	}
+	oldest := stale[0]
+	text := fmt.Sprintf("%d queued > %s", len(stale), threshold)
+	return lipgloss.NewStyle().Foreground(lipgloss.Color("214")).Render(text) +
+		fmt.Sprintf(", oldest %s: %s", db.ShortID(oldest.JobID), strings.Join(oldest.Reasons, "; "))
+}
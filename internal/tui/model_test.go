@@ -2,20 +2,25 @@ package tui
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
 	"testing"
 	"time"
 
 	"autopr/internal/config"
 	"autopr/internal/db"
+	"autopr/internal/queuewatch"
+	"autopr/internal/sysload"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -816,7 +821,7 @@ func TestFilterModeCycleStateAndProject(t *testing.T) {
 	modelAny, _ := m.handleKey(keyRunes('f'))
 	m = modelAny.(Model)
 
-	expectedStates := []string{"queued", "active", "awaiting_checks", "rebasing", "resolving_conflicts", "ready", "failed", "merged", "rejected", "cancelled", "all"}
+	expectedStates := []string{"queued", "active", "awaiting_checks", "waiting_network", "rebasing", "resolving_conflicts", "ready", "needs_review_oversize", "failed", "merged", "rejected", "cancelled", "all"}
 	for _, state := range expectedStates {
 		modelAny, _ = m.handleKey(keyRunes('s'))
 		m = modelAny.(Model)
@@ -1031,7 +1036,7 @@ func batchMsgType(msg tea.Msg) string {
 		return "jobs"
 	case sessionsMsg:
 		return "sessions"
-	case issueSummaryMsg:
+	case dashboardMsg:
 		return "summary"
 	default:
 		return ""
@@ -1102,8 +1107,9 @@ func TestManualRefreshInListViewStillWorks(t *testing.T) {
 	m, store, _ := newTestModelWithQueuedJob(t, tmp)
 	defer store.Close()
 
+	// Jobs, dashboard, and a wake request for an idle daemon.
 	_, cmd := m.handleKey(keyRunes('r'))
-	if got, want := batchCmdCount(t, cmd), 2; got != want {
+	if got, want := batchCmdCount(t, cmd), 3; got != want {
 		t.Fatalf("expected %d refresh commands in list view, got %d", want, got)
 	}
 }
@@ -1463,6 +1469,68 @@ func TestListViewHandlesZeroAndOneJob(t *testing.T) {
 	}
 }
 
+func TestListViewShowsOnboardingChecklistWithoutJobs(t *testing.T) {
+	t.Parallel()
+	cfg := &config.Config{
+		Daemon:   config.DaemonConfig{SyncInterval: "5m", MaxWorkers: 1},
+		Projects: []config.ProjectConfig{{Name: "api", GitHub: &config.ProjectGitHub{Owner: "o", Repo: "api"}}},
+	}
+	m := Model{cfg: cfg, pageSize: 10, jobsLoaded: true, filterState: filterAllState, filterProject: filterAllProject}
+
+	view := m.listView()
+	for _, want := range []string{"Getting started", "1 project(s): api", "missing for api (set GITHUB_TOKEN)", "not running; press D", "w add project", "D start daemon"} {
+		if !strings.Contains(view, want) {
+			t.Fatalf("expected %q in onboarding view, got:\n%s", want, view)
+		}
+	}
+	if strings.Contains(view, "No jobs found") {
+		t.Fatalf("expected the checklist in place of the empty list, got:\n%s", view)
+	}
+
+	m.filterState = "ready"
+	if view := m.listView(); !strings.Contains(view, "No jobs found. Waiting for issues...") || strings.Contains(view, "Getting started") {
+		t.Fatalf("expected the empty list while a filter is set, got:\n%s", view)
+	}
+}
+
+func TestOnboardingChecks(t *testing.T) {
+	t.Parallel()
+	cfg := &config.Config{
+		Daemon: config.DaemonConfig{SyncInterval: "5m"},
+		Tokens: config.TokensConfig{GitHub: "ghp_x"},
+		Projects: []config.ProjectConfig{
+			{Name: "api", GitHub: &config.ProjectGitHub{Owner: "o", Repo: "api"}},
+			{Name: "notes", Local: &config.ProjectLocal{}},
+		},
+	}
+	statuses := []db.ProjectSyncStatus{
+		{ProjectName: "api", LastError: "github API 401: Bad credentials", LastErrorAt: "2026-01-02T00:00:00Z"},
+		{ProjectName: "notes", LastSuccessAt: "2026-01-02T00:00:00Z"},
+	}
+
+	checks := onboardingChecks(cfg, true, statuses)
+	got := map[string]onboardingCheck{}
+	for _, c := range checks {
+		got[c.label] = c
+	}
+	if !got["config"].ok || !got["daemon"].ok {
+		t.Fatalf("expected config and daemon ok, got %+v", checks)
+	}
+	if got["tokens"].ok || !strings.Contains(got["tokens"].detail, "api (github token rejected)") {
+		t.Fatalf("expected the github token rejected, got %+v", got["tokens"])
+	}
+	if got["first sync"].ok || !strings.Contains(got["first sync"].detail, "failed for api") {
+		t.Fatalf("expected the failed sync reported, got %+v", got["first sync"])
+	}
+
+	statuses[0] = db.ProjectSyncStatus{ProjectName: "api", LastSuccessAt: "2026-01-02T00:00:00Z"}
+	for _, c := range onboardingChecks(cfg, false, statuses) {
+		if c.ok != (c.label != "daemon") {
+			t.Fatalf("expected only the daemon check failing, got %+v", c)
+		}
+	}
+}
+
 func TestLevel1EnterAndCancelUseCurrentPageSelection(t *testing.T) {
 	t.Parallel()
 	cfg := &config.Config{Daemon: config.DaemonConfig{
@@ -1757,7 +1825,7 @@ func TestExecuteMergeErrorPaths(t *testing.T) {
 
 		msg := m.executeMerge()
 		res := msg.(actionResultMsg)
-		if res.err == nil || !strings.Contains(res.err.Error(), "no GitHub or GitLab config") {
+		if res.err == nil || !strings.Contains(res.err.Error(), "no GitHub, GitLab, or Gitea config") {
 			t.Fatalf("expected unsupported provider error, got %v", res.err)
 		}
 	})
@@ -1887,6 +1955,395 @@ func TestStartTimeFormattingHelpers(t *testing.T) {
 	}
 }
 
+func TestFormatRateLimits(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	limits := []db.APIRateLimit{
+		{Host: "api.github.com", Resource: "core", Limit: 5000, Remaining: 4200, ResetAt: "2026-03-01T12:30:00Z"},
+		{Host: "api.github.com", Resource: "search", Limit: 30, Remaining: 30, ResetAt: "2026-03-01T11:00:00Z"},
+		{Host: "gitlab.com", Limit: 2000, Remaining: 100, ResetAt: "2026-03-01T12:45:00Z"},
+	}
+	got := formatRateLimits(limits, now)
+	if !strings.Contains(got, "api.github.com 4200/5000") {
+		t.Fatalf("expected github limit, got %q", got)
+	}
+	if strings.Contains(got, "search") {
+		t.Fatalf("expected limit past its reset to be omitted, got %q", got)
+	}
+	wantReset := time.Date(2026, 3, 1, 12, 45, 0, 0, time.UTC).In(time.Local).Format("15:04")
+	if !strings.Contains(got, "gitlab.com 100/2000 (resets "+wantReset+")") {
+		t.Fatalf("expected low gitlab limit with reset time, got %q", got)
+	}
+	if formatRateLimits(nil, now) != "" {
+		t.Fatal("expected empty output without limits")
+	}
+}
+
+func TestFormatLoadAndJobResources(t *testing.T) {
+	t.Parallel()
+
+	s := sysload.Sample{Load1: 6, CPUs: 4, MemTotal: 16 << 30, MemAvailable: 3 << 29}
+	if got := stripANSI(formatLoad(s, sysload.Limits{MaxLoadPerCPU: 2})); got != "1.50/CPU (4 CPUs)  mem 1.5 of 16.0 GiB free" {
+		t.Fatalf("unexpected load row %q", got)
+	}
+	if got := stripANSI(formatLoad(s, sysload.Limits{MinFreeMemory: 2 << 30})); !strings.HasSuffix(got, "  claims held: 1536 MiB of memory available, below 2048 MiB") {
+		t.Fatalf("expected held claims in load row, got %q", got)
+	}
+
+	if got := formatJobResources(db.Job{CPUTimeMS: 134_400, PeakRSSBytes: 1400 << 20}); got != "cpu 2m14s, peak 1.4 GiB" {
+		t.Fatalf("unexpected resources %q", got)
+	}
+	if got := formatJobResources(db.Job{CPUTimeMS: 250, PeakRSSBytes: 40 << 20}); got != "cpu 250ms, peak 40 MiB" {
+		t.Fatalf("unexpected resources %q", got)
+	}
+}
+
+func TestFormatNotificationCounts(t *testing.T) {
+	t.Parallel()
+
+	if got := formatNotificationCounts(map[string]int{db.NotificationStatusSent: 4}); got != "" {
+		t.Fatalf("expected no row when everything was delivered, got %q", got)
+	}
+	got := formatNotificationCounts(map[string]int{
+		db.NotificationStatusPending:    1,
+		db.NotificationStatusProcessing: 1,
+		db.NotificationStatusDead:       3,
+	})
+	for _, want := range []string{"2 pending", "3 dead", "ap notifications"} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("expected %q in %q", want, got)
+		}
+	}
+	if strings.Contains(got, "retrying") {
+		t.Fatalf("expected no retrying count, got %q", got)
+	}
+}
+
+func TestFormatTimeLeft(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		deadline string
+		want     string
+	}{
+		{"2025-03-04T13:00:00Z", "3d left"},
+		{"2025-03-01T17:30:00Z", "5h left"},
+		{"2025-03-01T12:40:00Z", "40m left"},
+		{"2025-03-01T09:00:00Z", "overdue 3h"},
+		{"2025-02-26T12:00:00Z", "overdue 3d"},
+		{"soon", ""},
+	} {
+		if got, _ := formatTimeLeft(tc.deadline, now); got != tc.want {
+			t.Fatalf("formatTimeLeft(%q) = %q, want %q", tc.deadline, got, tc.want)
+		}
+	}
+}
+
+func TestFormatStaleQueue(t *testing.T) {
+	t.Parallel()
+
+	if got := formatStaleQueue(nil, "24h"); got != "" {
+		t.Fatalf("expected no row without stale jobs, got %q", got)
+	}
+	got := formatStaleQueue([]queuewatch.StaleJob{
+		{StaleQueuedJob: db.StaleQueuedJob{JobID: "ap-job-2dad8b6b5f96e0df"}, Reasons: []string{"all 3 workers are busy", "4 older job(s) of its project queued ahead"}},
+		{StaleQueuedJob: db.StaleQueuedJob{JobID: "ap-job-ffffffffffffffff"}, Reasons: []string{"daemon is not running"}},
+	}, "24h")
+	for _, want := range []string{"2 queued > 24h", "oldest 2dad8b6b", "all 3 workers are busy; 4 older job(s) of its project queued ahead"} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("expected %q in %q", want, got)
+		}
+	}
+}
+
+func TestListViewShowsCIProgressForAwaitingChecksJobs(t *testing.T) {
+	t.Parallel()
+
+	jobs := []db.Job{
+		{ID: "ap-job-1111111111111111", State: "awaiting_checks", ProjectName: "autopr", CIChecks: db.CIChecks{Total: 7, Passed: 3, Pending: 4}},
+		{ID: "ap-job-2222222222222222", State: "awaiting_checks", ProjectName: "autopr", CIChecks: db.CIChecks{Total: 7, Passed: 5, Failed: 1, Pending: 1}},
+		{ID: "ap-job-3333333333333333", State: "awaiting_checks", ProjectName: "autopr"},
+		{ID: "ap-job-4444444444444444", State: "ready", ProjectName: "autopr", CIChecks: db.CIChecks{Total: 2, Passed: 2}},
+	}
+	m := newTestModelForFilterCycle(jobs)
+	m.pageSize = 10
+
+	view := stripANSI(m.listView())
+	findLineContainingAll(t, view, "JOB", "STATE", "CI", "PROJECT")
+	findLineContainingAll(t, view, "11111111", "3/7 passed")
+	findLineContainingAll(t, view, "22222222", "5/7, 1 failing")
+	findLineContainingAll(t, view, "33333333", "waiting")
+	if line := findLineContainingText(t, view, "44444444"); strings.Contains(line, "2/2") {
+		t.Fatalf("expected no CI progress for a ready job, got %q", line)
+	}
+
+	m = newTestModelForFilterCycle(jobs[3:])
+	m.pageSize = 10
+	header := findLineContainingAll(t, stripANSI(m.listView()), "JOB", "STATE", "PROJECT")
+	if strings.Contains(header, " CI ") {
+		t.Fatalf("expected no CI column without awaiting_checks jobs, got %q", header)
+	}
+}
+
+func TestGroupByIssueNestsJobsUnderIssue(t *testing.T) {
+	t.Parallel()
+
+	jobs := []db.Job{
+		{ID: "ap-job-1111111111111111", AutoPRIssueID: "issue-a", State: "queued", ProjectName: "autopr", IssueTitle: "fix login", ParentJobID: "ap-job-2222222222222222", CreatedAt: "2026-03-03T00:00:00Z"},
+		{ID: "ap-job-3333333333333333", AutoPRIssueID: "issue-b", State: "failed", ProjectName: "autopr", IssueTitle: "flaky test", CreatedAt: "2026-03-02T00:00:00Z"},
+		{ID: "ap-job-2222222222222222", AutoPRIssueID: "issue-a", State: "approved", ProjectName: "autopr", IssueTitle: "fix login", PRMergedAt: "2026-03-01T12:00:00Z", CreatedAt: "2026-03-01T00:00:00Z"},
+	}
+	m := newTestModelForFilterCycle(jobs)
+	m.pageSize = 10
+
+	modelAny, _ := m.handleKey(keyRunes('t'))
+	m = modelAny.(Model)
+	if !m.groupByIssue {
+		t.Fatal("expected t to turn on grouping")
+	}
+	rows := m.listRows()
+	if len(rows) != 2 || rows[0].job != -1 || rows[0].issueID != "issue-a" || rows[1].job != 1 {
+		t.Fatalf("expected collapsed issue-a heading then issue-b's only job, got %+v", rows)
+	}
+	view := stripANSI(m.listView())
+	// The follow-up is still queued, so it decides the issue's status.
+	findLineContainingAll(t, view, "+ 2 jobs", "queued", "fix login")
+	if strings.Contains(view, "11111111") {
+		t.Fatalf("expected collapsed issue to hide its jobs, got:\n%s", view)
+	}
+	if m.cursorJob() != nil {
+		t.Fatal("expected no job under the cursor on an issue heading")
+	}
+
+	modelAny, cmd := m.handleKey(keyType(tea.KeyEnter))
+	m = modelAny.(Model)
+	if cmd != nil || m.selected != nil {
+		t.Fatal("expected enter on an issue heading to expand it, not open a job")
+	}
+	view = stripANSI(m.listView())
+	findLineContainingAll(t, view, "- 2 jobs")
+	findLineContainingAll(t, view, "11111111", "follow-up of 22222222")
+	findLineContainingAll(t, view, "22222222", "merged", "attempt")
+
+	modelAny, _ = m.handleKey(keyRunes('j'))
+	m = modelAny.(Model)
+	modelAny, cmd = m.handleKey(keyType(tea.KeyEnter))
+	m = modelAny.(Model)
+	if m.selected == nil || m.selected.ID != "ap-job-1111111111111111" || cmd == nil {
+		t.Fatalf("expected enter on a nested job to open it, got %+v", m.selected)
+	}
+
+	if got := issueRollup(jobs[2:]); got != "merged" {
+		t.Fatalf("expected merged roll-up once nothing is in progress, got %q", got)
+	}
+}
+
+func TestGroupByIssueShowsEpicProgress(t *testing.T) {
+	t.Parallel()
+
+	jobs := []db.Job{
+		{ID: "ap-job-1111111111111111", AutoPRIssueID: "epic", State: "approved", ProjectName: "autopr", IssueTitle: "Rework login", EpicIndex: 1, EpicTask: "Add the API", PRMergedAt: "2026-03-02T00:00:00Z", CreatedAt: "2026-03-01T00:00:00Z"},
+		{ID: "ap-job-2222222222222222", AutoPRIssueID: "epic", State: "queued", ProjectName: "autopr", IssueTitle: "Rework login", EpicIndex: 2, EpicTask: "Add the UI", CreatedAt: "2026-03-01T00:00:01Z"},
+	}
+	m := newTestModelForFilterCycle(jobs)
+	m.pageSize = 10
+	m.groupByIssue = true
+	m.expandedIssues = map[string]bool{"epic": true}
+
+	view := stripANSI(m.listView())
+	findLineContainingAll(t, view, "- 2 jobs", "Rework login (epic: 1/2 tasks merged)")
+	findLineContainingAll(t, view, "22222222", "task 2: Add the UI")
+
+	tasks := []db.EpicTask{
+		{JobID: jobs[0].ID, Index: 1, State: "approved", PRMergedAt: ""},
+		{JobID: jobs[1].ID, Index: 2, State: "queued", DependsOn: []int{1}},
+	}
+	if got := formatEpicTask(jobs[1], tasks); got != "2 of 2 (0 merged): Add the UI" {
+		t.Fatalf("formatEpicTask = %q", got)
+	}
+	if got := formatEpicWaits(jobs[1], tasks); got != "task 1 (11111111, pr created)" {
+		t.Fatalf("formatEpicWaits = %q", got)
+	}
+}
+
+func TestFormatProjectSyncs(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	projects := []config.ProjectConfig{{Name: "api"}, {Name: "web"}, {Name: "new"}, {Name: "off"}}
+	statuses := []db.ProjectSyncStatus{
+		{ProjectName: "api", LastSuccessAt: "2026-03-01T11:58:00Z", LastError: "old failure", LastErrorAt: "2026-03-01T10:00:00Z"},
+		{ProjectName: "web", LastSuccessAt: "2026-03-01T09:00:00Z", LastError: "github sync: HTTP 401", LastErrorAt: "2026-03-01T11:59:30Z"},
+		{ProjectName: "off", LastError: "gitlab sync: HTTP 500", LastErrorAt: "2026-03-01T11:00:00Z"},
+	}
+	disabled := []string{"off"}
+
+	got := stripANSI(formatProjectSyncs(projects, statuses, disabled, now))
+	for _, want := range []string{"api 2m ago", "web failed 30s ago", "new not synced yet"} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("expected %q in %q", want, got)
+		}
+	}
+	if strings.Contains(got, "off") {
+		t.Fatalf("expected disabled project omitted, got %q", got)
+	}
+
+	errs := stripANSI(formatSyncErrors(statuses, disabled))
+	if !strings.Contains(errs, "web: github sync: HTTP 401") || !strings.Contains(errs, "y sync status") {
+		t.Fatalf("expected failing project error with hint, got %q", errs)
+	}
+	if strings.Contains(errs, "old failure") || strings.Contains(errs, "HTTP 500") {
+		t.Fatalf("expected recovered and disabled projects omitted, got %q", errs)
+	}
+	if formatSyncErrors(statuses[:1], nil) != "" {
+		t.Fatal("expected no error row when every project recovered")
+	}
+}
+
+func TestSyncStatusViewShowsRecentRuns(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	tmp := t.TempDir()
+
+	m, store := newTestModelWithJobs(t, tmp, nil)
+	defer store.Close()
+	m.cfg.Projects = []config.ProjectConfig{{Name: "api"}}
+	m.width, m.height = 140, 40
+	if err := store.RecordProjectSync(ctx, "api", 1200*time.Millisecond, nil); err != nil {
+		t.Fatalf("record success: %v", err)
+	}
+	if err := store.RecordProjectSync(ctx, "api", 300*time.Millisecond, errors.New("github sync: HTTP 401")); err != nil {
+		t.Fatalf("record failure: %v", err)
+	}
+	// Both syncs land in the same second; make the failure clearly the latest.
+	if _, err := store.Writer.ExecContext(ctx, `UPDATE project_sync_status SET last_success_at = '2026-01-01T00:00:00Z'`); err != nil {
+		t.Fatalf("backdate success: %v", err)
+	}
+
+	modelAny, cmd := m.handleKey(keyRunes('y'))
+	m = modelAny.(Model)
+	if !m.showSync || cmd == nil {
+		t.Fatalf("expected y to open the sync status view with a fetch")
+	}
+	modelAny, _ = m.Update(m.fetchDashboard())
+	m = modelAny.(Model)
+	modelAny, _ = m.Update(m.fetchSyncRuns())
+	m = modelAny.(Model)
+
+	view := stripANSI(m.View())
+	for _, want := range []string{"SYNC STATUS", "api", "last sync", "last error", "github sync: HTTP 401", "recent runs", "failed: github sync: HTTP 401", "1.2s"} {
+		if !strings.Contains(view, want) {
+			t.Fatalf("expected %q in sync view, got:\n%s", want, view)
+		}
+	}
+
+	modelAny, _ = m.handleKey(keyType(tea.KeyEsc))
+	m = modelAny.(Model)
+	if m.showSync {
+		t.Fatal("expected esc to close the sync status view")
+	}
+	list := stripANSI(m.listView())
+	if !strings.Contains(list, "api failed") || !strings.Contains(list, "sync err") {
+		t.Fatalf("expected failing project in dashboard header, got:\n%s", list)
+	}
+}
+
+func TestPlannedIssuesPreviewAndEnqueue(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	tmp := t.TempDir()
+
+	m, store := newTestModelWithJobs(t, tmp, []jobSeed{{state: "queued", project: "api"}})
+	defer store.Close()
+	m.cfg.Projects = []config.ProjectConfig{{Name: "api", GitLab: &config.ProjectGitLab{ProjectID: "org/api"}}}
+	m.cfg.Daemon.MaxIterations = 3
+	m.width, m.height = 140, 60
+
+	ineligible := false
+	for _, in := range []db.IssueUpsert{
+		{ProjectName: "api", Source: "gitlab", SourceIssueID: "12", Title: "Login crashes", Body: "Steps:\n\n1. open **login**", URL: "https://gitlab.com/org/api/-/issues/12", State: "open", Labels: []string{"bug", "autopr"}, SourceUpdated: "2026-01-01T00:00:00Z"},
+		{ProjectName: "api", Source: "gitlab", SourceIssueID: "13", Title: "Redesign", URL: "https://gitlab.com/org/api/-/issues/13", State: "open", Eligible: &ineligible, SkipReason: "missing required labels: autopr", SourceUpdated: "2026-01-02T00:00:00Z"},
+	} {
+		if _, err := store.UpsertIssue(ctx, in); err != nil {
+			t.Fatalf("upsert issue %s: %v", in.SourceIssueID, err)
+		}
+	}
+
+	modelAny, cmd := m.handleKey(keyRunes('I'))
+	m = modelAny.(Model)
+	if !m.showIssues || cmd == nil {
+		t.Fatal("expected I to open the planned issues view with a fetch")
+	}
+	modelAny, _ = m.Update(cmd())
+	m = modelAny.(Model)
+	view := stripANSI(m.View())
+	for _, want := range []string{"PLANNED ISSUES", "2 open issues without a job", "Gitlab #12", "Login crashes", "missing required labels: autopr"} {
+		if !strings.Contains(view, want) {
+			t.Fatalf("expected %q in planned issues view, got:\n%s", want, view)
+		}
+	}
+	if strings.Contains(view, "issue 1") {
+		t.Fatalf("expected the issue with a job to be left out, got:\n%s", view)
+	}
+
+	modelAny, cmd = m.handleKey(keyType(tea.KeyEnter))
+	m = modelAny.(Model)
+	if m.previewIssue == nil || m.previewIssue.SourceIssueID != "12" || cmd == nil {
+		t.Fatalf("expected enter to preview the first issue and fetch its comments")
+	}
+	if view := stripANSI(m.View()); !strings.Contains(view, "loading comments") {
+		t.Fatalf("expected comments to be loading, got:\n%s", view)
+	}
+	modelAny, _ = m.Update(cmd())
+	m = modelAny.(Model)
+	view = stripANSI(m.View())
+	for _, want := range []string{"ISSUE", "Labels", "bug, autopr", "Eligible", "yes", "open login", "Comments unavailable: no GitLab token configured", "e enqueue now"} {
+		if !strings.Contains(view, want) {
+			t.Fatalf("expected %q in issue preview, got:\n%s", want, view)
+		}
+	}
+
+	modelAny, cmd = m.handleKey(keyRunes('e'))
+	m = modelAny.(Model)
+	if cmd == nil {
+		t.Fatal("expected e to enqueue the issue")
+	}
+	modelAny, cmd = m.Update(cmd())
+	m = modelAny.(Model)
+	if m.previewIssue != nil || m.issueErr != nil || !strings.HasPrefix(m.issueNotice, "Queued job ") {
+		t.Fatalf("expected the issue queued and the preview closed, got notice %q, err %v", m.issueNotice, m.issueErr)
+	}
+	if cmd == nil {
+		t.Fatal("expected a refresh after enqueueing")
+	}
+	jobs, err := store.ListJobs(ctx, "api", "queued", "created_at", true)
+	if err != nil || len(jobs) != 2 || !slices.ContainsFunc(jobs, func(j db.Job) bool { return j.SourceIssueID == "12" }) {
+		t.Fatalf("expected a queued job for issue 12, got %+v, %v", jobs, err)
+	}
+	modelAny, _ = m.Update(m.fetchPlannedIssues())
+	m = modelAny.(Model)
+	if len(m.plannedIssues) != 1 || m.plannedIssues[0].SourceIssueID != "13" {
+		t.Fatalf("expected only the ineligible issue left, got %+v", m.plannedIssues)
+	}
+
+	// Ineligible issues are previewed but not queued.
+	modelAny, cmd = m.handleKey(keyRunes('e'))
+	m = modelAny.(Model)
+	modelAny, _ = m.Update(cmd())
+	m = modelAny.(Model)
+	if m.issueErr == nil || !strings.Contains(m.issueErr.Error(), "not eligible") {
+		t.Fatalf("expected an eligibility error, got %v", m.issueErr)
+	}
+
+	modelAny, _ = m.handleKey(keyType(tea.KeyEsc))
+	m = modelAny.(Model)
+	if m.showIssues {
+		t.Fatal("expected esc to close the planned issues view")
+	}
+}
+
 func TestFormatTimestampLocal(t *testing.T) {
 	t.Parallel()
 
@@ -2217,6 +2674,108 @@ func TestSessionViewShowsStartTimeAboveDuration(t *testing.T) {
 	}
 }
 
+func TestSessionViewTabsToRepoContext(t *testing.T) {
+	t.Parallel()
+
+	m := Model{
+		height: 40,
+		selectedSession: &db.LLMSession{
+			ID:           1,
+			Step:         "plan",
+			Status:       "completed",
+			ResponseText: "the plan",
+			PromptText:   "Plan this.\n\n<repo_context>\nFollow them.\n\n<file path=\"docs/STYLE.md\">\nTabs, not spaces.\n</file>\n</repo_context>",
+			ContextFiles: []db.ContextFile{{Path: "docs/STYLE.md", Bytes: 18, Truncated: true}},
+		},
+	}
+
+	for _, want := range []sessionTab{sessionTabInput, sessionTabContext, sessionTabOutput} {
+		next, _ := m.handleKeyLevel3("tab")
+		m = next.(Model)
+		if m.sessionTab != want {
+			t.Fatalf("tab moved to %v, want %v", m.sessionTab, want)
+		}
+		if want == sessionTabContext {
+			view := stripANSI(m.sessionView())
+			findLineContainingAll(t, view, "Context", "docs/STYLE.md")
+			findLineContainingAll(t, view, "docs/STYLE.md", "18 bytes", "(truncated)")
+			findLineContainingAll(t, view, "Tabs, not spaces.")
+			findLineContainingAll(t, view, "INPUT", "OUTPUT", "CONTEXT")
+		}
+	}
+
+	// Sessions without repo context skip the tab.
+	m.selectedSession.ContextFiles = nil
+	m.sessionTab = sessionTabInput
+	next, _ := m.handleKeyLevel3("tab")
+	if got := next.(Model).sessionTab; got != sessionTabOutput {
+		t.Fatalf("tab moved to %v, want output", got)
+	}
+}
+
+func TestSessionViewShowsTheStepsCommitDiff(t *testing.T) {
+	t.Parallel()
+	wt := initApproveWorktree(t, t.TempDir())
+	head := func() string {
+		out, err := exec.Command("git", "-C", wt, "rev-parse", "HEAD").Output()
+		if err != nil {
+			t.Fatalf("rev-parse: %v", err)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	base := head()
+	cmd := exec.Command("git", "-C", wt, "commit", "-am", "implement")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("commit: %v\n%s", err, out)
+	}
+
+	job := db.Job{ID: "ap-job-commit", WorktreePath: wt}
+	m := Model{
+		height:          40,
+		selected:        &job,
+		selectedSession: &db.LLMSession{ID: 2, Step: "implement", Status: "completed", BaseSHA: base, CommitSHA: head()},
+		lines:           []string{"ok"},
+	}
+	if view := m.sessionView(); !strings.Contains(view, shortCommit(base)+".."+shortCommit(head())) || !strings.Contains(view, "c commit diff") {
+		t.Fatalf("expected the session's commit range and key hint:\n%s", view)
+	}
+
+	next, cmdFn := m.handleKeyLevel3("c")
+	if cmdFn == nil {
+		t.Fatal("expected c to fetch the session diff")
+	}
+	updated, _ := next.(Model).Update(cmdFn())
+	m = updated.(Model)
+	if m.sessionTab != sessionTabCommit {
+		t.Fatalf("tab = %v, want commit", m.sessionTab)
+	}
+	view := stripANSI(m.sessionView())
+	findLineContainingAll(t, view, "COMMIT")
+	findLineContainingAll(t, view, "-base")
+	findLineContainingAll(t, view, "+reviewed change")
+
+	next, _ = m.handleKeyLevel3("tab")
+	if got := next.(Model).sessionTab; got != sessionTabOutput {
+		t.Fatalf("tab moved to %v, want output", got)
+	}
+
+	m.selectedSession = &db.LLMSession{ID: 3, Step: "code_review", BaseSHA: head(), CommitSHA: head()}
+	if msg := m.fetchSessionDiff().(sessionDiffMsg); len(msg.lines) != 1 || !strings.Contains(msg.lines[0], "no changes") {
+		t.Fatalf("expected no changes for a step without commits, got %q", msg.lines)
+	}
+}
+
+func TestPreviousSessionCommit(t *testing.T) {
+	t.Parallel()
+	sessions := []db.LLMSession{{ID: 1, CommitSHA: "a"}, {ID: 2}, {ID: 3, CommitSHA: "b"}, {ID: 4, CommitSHA: "c"}}
+	if got := previousSessionCommit(sessions, 4); got != "b" {
+		t.Fatalf("previousSessionCommit(4) = %q, want b", got)
+	}
+	if got := previousSessionCommit(sessions, 1); got != "" {
+		t.Fatalf("previousSessionCommit(1) = %q, want none", got)
+	}
+}
+
 func TestSyntheticSessionViewsCarryStartTimes(t *testing.T) {
 	t.Parallel()
 
@@ -2273,3 +2832,404 @@ func TestSyntheticSessionViewsCarryStartTimes(t *testing.T) {
 		t.Fatalf("expected pr-closed markdown to format PR closed timestamp, got:\n%s", closedView.selectedSession.ResponseText)
 	}
 }
+
+func TestCompareMsgShowsSideBySideIterations(t *testing.T) {
+	t.Parallel()
+	tmp := t.TempDir()
+
+	m, store, _ := newTestModelWithQueuedJob(t, tmp)
+	defer store.Close()
+	m.selected = &m.jobs[0]
+	m.width = 120
+	m.height = 60
+
+	iterations := []db.JobIteration{
+		{Iteration: 0, PlanIteration: 0, Plan: "original plan", Review: "add a nil check", CommitSHA: "aaaaaaaaaaaaaaaa"},
+		{Iteration: 1, PlanIteration: 0, Plan: "original plan", Review: "looks good", CommitSHA: "bbbbbbbbbbbbbbbb"},
+	}
+	updated, _ := m.Update(compareMsg{
+		jobID:      m.selected.ID,
+		iterations: iterations,
+		index:      1,
+		diff:       "diff --git a/x.go b/x.go\n+if v == nil {\n",
+	})
+	m = updated.(Model)
+	if !m.showCompare {
+		t.Fatalf("expected compare view to be shown")
+	}
+	if !m.autoRefreshPaused() {
+		t.Fatalf("expected auto refresh to pause in compare view")
+	}
+
+	view := ansiRegexp.ReplaceAllString(m.View(), "")
+	for _, want := range []string{"iteration 0 → 1", "(unchanged since iteration 0)", "add a nil check", "looks good", "aaaaaaaaaaaa..bbbbbbbbbbbb", "+if v == nil {"} {
+		if !strings.Contains(view, want) {
+			t.Fatalf("expected compare view to contain %q, got:\n%s", want, view)
+		}
+	}
+
+	updated, _ = m.handleKeyCompare("esc")
+	m = updated.(Model)
+	if m.showCompare || m.compareLines != nil {
+		t.Fatalf("expected esc to leave compare view")
+	}
+}
+
+func TestPartialSelectBuildsExclusionsForApproval(t *testing.T) {
+	t.Parallel()
+	tmp := t.TempDir()
+
+	m, store, _ := newTestModelWithQueuedJob(t, tmp)
+	defer store.Close()
+	job := m.jobs[0]
+	job.State = "ready"
+	m.selected = &job
+	m.showDiff = true
+	m.diffLines = strings.Split(strings.Join([]string{
+		"diff --git a/a.go b/a.go",
+		"--- a/a.go",
+		"+++ b/a.go",
+		"@@ -1,1 +1,1 @@",
+		"-old",
+		"+new",
+		"@@ -10,1 +10,1 @@",
+		"-old2",
+		"+new2",
+		"diff --git a/b.go b/b.go",
+		"new file mode 100644",
+		"--- /dev/null",
+		"+++ b/b.go",
+		"@@ -0,0 +1 @@",
+		"+package b",
+	}, "\n"), "\n")
+
+	updated, _ := m.handleKeyDiff("s")
+	m = updated.(Model)
+	if !m.partialSelect || len(m.partialRows()) != 5 {
+		t.Fatalf("expected partial select with 5 rows, got select=%v rows=%d", m.partialSelect, len(m.partialRows()))
+	}
+
+	// Drop the second hunk of a.go and all of b.go.
+	for _, key := range []string{"j", "j", " ", "j", " "} {
+		updated, _ = m.handleKeyPartialSelect(key)
+		m = updated.(Model)
+	}
+	updated, _ = m.handleKeyPartialSelect("enter")
+	m = updated.(Model)
+
+	if m.confirmAction != "approve" || m.showDiff || m.partialSelect {
+		t.Fatalf("expected approve confirmation after selection, got action=%q showDiff=%v select=%v", m.confirmAction, m.showDiff, m.partialSelect)
+	}
+	want := "map[a.go:[2] b.go:[]]"
+	if got := fmt.Sprint(m.approveExclusions); got != want {
+		t.Fatalf("unexpected exclusions: got %s want %s", got, want)
+	}
+	if !strings.Contains(m.confirmPrompt(), "2 excluded file(s)") {
+		t.Fatalf("expected partial approval prompt, got %q", m.confirmPrompt())
+	}
+}
+
+func TestApproveRepromptsWhenDiffChangedSinceReview(t *testing.T) {
+	t.Parallel()
+	tmp := t.TempDir()
+
+	m, store, _ := newTestModelWithQueuedJob(t, tmp)
+	defer store.Close()
+	wt := initApproveWorktree(t, tmp)
+	m.cfg.Projects = []config.ProjectConfig{{Name: "myproject", BaseBranch: "master"}}
+	job := m.jobs[0]
+	job.State = "ready"
+	job.WorktreePath = wt
+	m.selected = &job
+	if _, err := store.Writer.ExecContext(context.Background(), `UPDATE jobs SET state = 'ready', worktree_path = ? WHERE id = ?`, wt, job.ID); err != nil {
+		t.Fatalf("make job ready: %v", err)
+	}
+
+	// With no diff viewed, approve opens it with the prompt.
+	modelAny, cmd := m.handleKey(keyRunes('a'))
+	m = modelAny.(Model)
+	if m.confirmAction != "" || cmd == nil {
+		t.Fatalf("expected approve to open the diff before prompting, got action=%q", m.confirmAction)
+	}
+	modelAny, _ = m.Update(cmd())
+	m = modelAny.(Model)
+	if !m.showDiff || m.confirmAction != "approve" || m.approveDiffSum == "" || m.approveDiffSum != m.diffSum {
+		t.Fatalf("expected approve prompt over the diff, got showDiff=%v action=%q sum=%q", m.showDiff, m.confirmAction, m.approveDiffSum)
+	}
+	if !strings.Contains(m.View(), "Approve job") {
+		t.Fatalf("expected the diff view to show the prompt")
+	}
+	reviewed := m.approveDiffSum
+
+	// Cancel and close the diff; a later approval still covers that diff.
+	modelAny, _ = m.handleKey(keyRunes('n'))
+	m = modelAny.(Model)
+	modelAny, _ = m.handleKey(tea.KeyMsg{Type: tea.KeyEsc})
+	m = modelAny.(Model)
+	if m.showDiff || m.diffSum != reviewed {
+		t.Fatalf("expected closed diff to keep its checksum, got showDiff=%v sum=%q", m.showDiff, m.diffSum)
+	}
+
+	// A late commit lands after the approver viewed the diff.
+	if err := os.WriteFile(filepath.Join(wt, "a.txt"), []byte("late change\n"), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	modelAny, cmd = m.handleKey(keyRunes('a'))
+	m = modelAny.(Model)
+	if cmd != nil || m.confirmAction != "approve" || m.approveDiffSum != reviewed {
+		t.Fatalf("expected prompt for the viewed diff, got action=%q sum=%q", m.confirmAction, m.approveDiffSum)
+	}
+	msg := m.executeApprove()
+	changed, ok := msg.(approveDiffChangedMsg)
+	if !ok {
+		t.Fatalf("expected approveDiffChangedMsg, got %#v", msg)
+	}
+	modelAny, _ = m.Update(changed)
+	m = modelAny.(Model)
+	if m.confirmAction != "" || !m.pendingApprove {
+		t.Fatalf("expected the new diff to open before re-prompting, got action=%q", m.confirmAction)
+	}
+	modelAny, _ = m.Update(m.fetchDiff())
+	m = modelAny.(Model)
+	if !m.showDiff || m.confirmAction != "approve" || !m.approveDiffChanged || m.approveDiffSum == reviewed || m.approveDiffSum != m.diffSum {
+		t.Fatalf("expected re-prompt over the new diff, got showDiff=%v action=%q changed=%v", m.showDiff, m.confirmAction, m.approveDiffChanged)
+	}
+	if !strings.Contains(m.confirmPrompt(), "Diff changed since you reviewed it") {
+		t.Fatalf("unexpected prompt %q", m.confirmPrompt())
+	}
+
+	// A partial selection was made on the old diff, so it is dropped.
+	m.approveExclusions = map[string][]int{"a.txt": nil}
+	modelAny, _ = m.Update(approveDiffChangedMsg{jobID: job.ID, sum: "other"})
+	m = modelAny.(Model)
+	if m.confirmAction != "" || m.approveExclusions != nil || m.actionErr == nil || !strings.Contains(m.actionErr.Error(), "diff changed since you reviewed it") {
+		t.Fatalf("expected partial approval to be cancelled, got action=%q err=%v", m.confirmAction, m.actionErr)
+	}
+}
+
+// initApproveWorktree creates a clone of a one-commit repository with an
+// uncommitted change, so it has a diff against origin/master.
+func initApproveWorktree(t *testing.T, tmp string) string {
+	t.Helper()
+	remote := filepath.Join(tmp, "remote.git")
+	wt := filepath.Join(tmp, "wt")
+	git := func(dir string, args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+		}
+	}
+	git(tmp, "init", "--bare", "-b", "master", remote)
+	git(tmp, "clone", remote, wt)
+	git(wt, "config", "user.email", "test@example.com")
+	git(wt, "config", "user.name", "test")
+	git(wt, "checkout", "-b", "master")
+	if err := os.WriteFile(filepath.Join(wt, "a.txt"), []byte("base\n"), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	git(wt, "add", ".")
+	git(wt, "commit", "-m", "base")
+	git(wt, "push", "origin", "master")
+	if err := os.WriteFile(filepath.Join(wt, "a.txt"), []byte("reviewed change\n"), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	return wt
+}
+
+func TestHandleKeyOversizeActions(t *testing.T) {
+	t.Parallel()
+
+	job := db.Job{
+		ID:              "ap-job-oversize-1",
+		State:           "needs_review_oversize",
+		OversizeSummary: "1200 lines changed (limit 500)",
+	}
+	m := Model{selected: &job}
+	view := m.detailView()
+	for _, want := range []string{"a allow", "s split", "x reject", "1200 lines changed (limit 500)"} {
+		if !strings.Contains(view, want) {
+			t.Fatalf("expected detail view to contain %q, got:\n%s", want, view)
+		}
+	}
+
+	for key, action := range map[rune]string{'a': "oversize-allow", 's': "oversize-split", 'x': "oversize-reject"} {
+		modelAny, _ := m.handleKey(keyRunes(key))
+		got := modelAny.(Model)
+		if got.confirmAction != action || got.confirmJobID != job.ID {
+			t.Fatalf("key %q: expected confirm %q for %s, got %q for %s", key, action, job.ID, got.confirmAction, got.confirmJobID)
+		}
+		if got.confirmPrompt() == "" {
+			t.Fatalf("key %q: expected confirmation prompt", key)
+		}
+	}
+}
+
+func TestAnnotationEditorAndTagFilter(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	m, store := newTestModelWithJobs(t, t.TempDir(), []jobSeed{
+		{state: "failed", project: "autopr"},
+		{state: "queued", project: "autopr"},
+	})
+	defer store.Close()
+	m.pageSize = 10
+	tagged := m.jobs[0].ID
+	m.selected = &m.jobs[0]
+
+	typeText := func(text string) {
+		for _, r := range text {
+			modelAny, _ := m.handleKey(keyRunes(r))
+			m = modelAny.(Model)
+		}
+	}
+	submit := func() {
+		modelAny, cmd := m.handleKey(keyType(tea.KeyEnter))
+		m = modelAny.(Model)
+		if cmd == nil {
+			t.Fatal("expected submit to run an action")
+		}
+		modelAny, _ = m.Update(cmd())
+		m = modelAny.(Model)
+		if m.actionErr != nil {
+			t.Fatalf("unexpected action error: %v", m.actionErr)
+		}
+		if m.selected == nil {
+			t.Fatal("expected to stay on the job detail after annotating")
+		}
+	}
+
+	modelAny, _ := m.handleKey(keyRunes('T'))
+	m = modelAny.(Model)
+	if !m.confirmText || m.confirmAction != "tags" {
+		t.Fatalf("expected T to open the tag editor, got action %q", m.confirmAction)
+	}
+	typeText("Flaky-Tests, ci")
+	submit()
+	modelAny, _ = m.handleKey(keyRunes('N'))
+	m = modelAny.(Model)
+	typeText("fails only on arm64")
+	submit()
+
+	job, err := store.GetJob(ctx, tagged)
+	if err != nil {
+		t.Fatalf("get job: %v", err)
+	}
+	if strings.Join(job.Tags, " ") != "ci flaky-tests" {
+		t.Fatalf("expected tags to be saved, got %v", job.Tags)
+	}
+	m.selected = &job
+	modelAny, _ = m.Update(m.fetchSessions())
+	m = modelAny.(Model)
+	view := stripANSI(m.detailView())
+	findLineContainingAll(t, view, "Tags", "ci, flaky-tests")
+	findLineContainingAll(t, view, "Note", "fails only on arm64")
+
+	modelAny, _ = m.handleKey(keyType(tea.KeyEsc))
+	m = modelAny.(Model)
+	modelAny, _ = m.Update(m.fetchJobs())
+	m = modelAny.(Model)
+	findLineContainingAll(t, stripANSI(m.listView()), "[ci,flaky-tests]")
+
+	modelAny, _ = m.handleKey(keyRunes('f'))
+	m = modelAny.(Model)
+	modelAny, _ = m.handleKey(keyRunes('t'))
+	m = modelAny.(Model)
+	if m.filterTag != "ci" {
+		t.Fatalf("expected t to filter by the first tag, got %q", m.filterTag)
+	}
+	modelAny, _ = m.Update(m.fetchJobs())
+	m = modelAny.(Model)
+	if len(m.jobs) != 1 || m.jobs[0].ID != tagged {
+		t.Fatalf("expected only the tagged job, got %d jobs", len(m.jobs))
+	}
+
+	for range 2 {
+		modelAny, _ = m.handleKey(keyRunes('t'))
+		m = modelAny.(Model)
+	}
+	if m.filterTag != filterAnyTag {
+		t.Fatalf("expected the tag cycle to end at no filter, got %q", m.filterTag)
+	}
+}
+
+func TestRiskColumnAndDetailShowScoredJobs(t *testing.T) {
+	t.Parallel()
+
+	unscored := db.Job{ID: "ap-job-2222222222222222", State: "testing", ProjectName: "autopr", IssueTitle: "Tidy logs"}
+	m := newTestModelForFilterCycle([]db.Job{unscored})
+	m.pageSize = 10
+	if strings.Contains(stripANSI(m.listView()), "RISK") {
+		t.Fatal("expected the risk column to stay hidden while no job is scored")
+	}
+
+	scored := db.Job{ID: "ap-job-1111111111111111", State: "ready", ProjectName: "autopr", IssueTitle: "Rework session tokens",
+		RiskScore: 75, RiskSummary: "2 critical files, 450 lines in 2 files, no tests"}
+	m = newTestModelForFilterCycle([]db.Job{scored, unscored})
+	m.pageSize = 10
+	view := stripANSI(m.listView())
+	findLineContainingAll(t, view, "RISK")
+	findLineContainingAll(t, view, "11111111", "high 75", "Rework session tokens")
+	if line := findLineContainingText(t, view, "22222222"); strings.Contains(line, "low") {
+		t.Fatalf("expected no badge for an unscored job, got %q", line)
+	}
+
+	m.selected = &scored
+	findLineContainingAll(t, stripANSI(m.detailView()), "Risk", "high 75/100", "(2 critical files, 450 lines in 2 files, no tests)")
+}
+
+func TestListColumnsFollowConfigAndFitNarrowTerminals(t *testing.T) {
+	t.Parallel()
+
+	m := newTestModelForFilterCycle([]db.Job{
+		{ID: "ap-job-1111111111111111", State: "ready", ProjectName: "autopr", BranchName: "autopr/fix-login-redirect", IssueTitle: "Fix login redirect loop after password reset"},
+	})
+	m.pageSize = 10
+	m.cfg.TUI = config.TUIConfig{
+		Columns:      []string{"job", "state", "branch", "issue"},
+		ColumnWidths: map[string]int{"branch": 28},
+	}
+
+	// Unknown terminal width: configured columns at full width.
+	view := stripANSI(m.listView())
+	findLineContainingAll(t, view, "JOB", "STATE", "BRANCH", "ISSUE")
+	findLineContainingAll(t, view, "11111111", "autopr/fix-login-redirect", "Fix login redirect loop after password reset")
+	if strings.Contains(view, "PROJECT") {
+		t.Fatalf("expected unlisted columns to be hidden, got:\n%s", view)
+	}
+
+	// A narrow terminal shrinks the issue column before anything scrolls.
+	m.width = 100
+	cols, left, right := m.visibleListColumns()
+	if len(cols) != 4 || left != 0 || right != 0 {
+		t.Fatalf("expected all columns to fit, got %d (left %d, right %d)", len(cols), left, right)
+	}
+	if cols[3].name != "issue" || cols[3].width >= 55 {
+		t.Fatalf("expected the issue column to shrink, got %+v", cols[3])
+	}
+
+	// With fixed widths the list scrolls instead, keeping the job column.
+	m.cfg.TUI.FixedWidths = true
+	m.width = 70
+	cols, _, right = m.visibleListColumns()
+	if len(cols) != 3 || right != 1 {
+		t.Fatalf("expected the issue column to be scrolled off, got %d columns, %d right", len(cols), right)
+	}
+	findLineContainingAll(t, stripANSI(m.listView()), "h/l scroll columns (0 left, 1 right)")
+	for range 3 {
+		modelAny, _ := m.handleKey(keyRunes('l'))
+		m = modelAny.(Model)
+	}
+	cols, left, right = m.visibleListColumns()
+	if cols[0].name != "job" || cols[len(cols)-1].name != "issue" || left == 0 || right != 0 {
+		t.Fatalf("expected scrolling right to reveal the issue column, got %+v (left %d, right %d)", cols, left, right)
+	}
+	view = stripANSI(m.listView())
+	findLineContainingAll(t, view, "11111111", "Fix login redirect")
+	modelAny, _ := m.handleKey(keyRunes('h'))
+	m = modelAny.(Model)
+	if m.colOffset >= 2 {
+		t.Fatalf("expected h to scroll back left, got offset %d", m.colOffset)
+	}
+}
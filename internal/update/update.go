@@ -5,7 +5,9 @@ import (
 	"bytes"
 	"compress/gzip"
 	"context"
+	"crypto/ed25519"
 	"crypto/sha256"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -23,7 +25,10 @@ import (
 
 const (
 	latestReleaseURL = "https://api.github.com/repos/ashwath-ramesh/autopr/releases/latest"
+	releasesURL      = "https://api.github.com/repos/ashwath-ramesh/autopr/releases?per_page=30"
 	binaryName       = "ap"
+	checksumsAsset   = "checksums.txt"
+	signatureAsset   = "checksums.txt.sig"
 
 	defaultReleaseRequestTimeout = 4 * time.Second
 	defaultAssetDownloadTimeout  = 2 * time.Minute
@@ -31,6 +36,17 @@ const (
 	DefaultCheckTTL = 24 * time.Hour
 )
 
+// Release channels.
+const (
+	ChannelStable = "stable" // the latest full release
+	ChannelBeta   = "beta"   // the newest release, prereleases included
+)
+
+// signingPublicKey is the base64 Ed25519 key that signs checksums.txt in
+// official releases. Release builds set it with -ldflags -X; when it is set,
+// upgrades refuse releases without a valid checksums.txt.sig.
+var signingPublicKey = ""
+
 type VersionCheckCache struct {
 	CheckedAt time.Time `json:"checked_at"`
 	LatestTag string    `json:"latest_tag"`
@@ -54,14 +70,19 @@ type Manager struct {
 	OS             string
 	Arch           string
 	ReleaseAPI     string
+	ReleasesAPI    string // release list, used by the beta channel
+	Channel        string
+	PublicKey      string // base64 Ed25519 key; empty skips signature checks
 	UserAgent      string
 	ExecutablePath func() (string, error)
 	StatePath      string
 }
 
 type githubRelease struct {
-	TagName string        `json:"tag_name"`
-	Assets  []githubAsset `json:"assets"`
+	TagName    string        `json:"tag_name"`
+	Draft      bool          `json:"draft"`
+	Prerelease bool          `json:"prerelease"`
+	Assets     []githubAsset `json:"assets"`
 }
 
 type githubAsset struct {
@@ -73,6 +94,7 @@ type semVersion struct {
 	major int
 	minor int
 	patch int
+	pre   string // prerelease, e.g. "beta.1"; empty for releases
 }
 
 func NewManager(currentVersion string) *Manager {
@@ -86,9 +108,12 @@ func NewManager(currentVersion string) *Manager {
 		OS:     runtime.GOOS,
 		Arch:   runtime.GOARCH,
 
-		ReleaseAPI: latestReleaseURL,
-		UserAgent:  fmt.Sprintf("autopr/%s", currentVersion),
-		StatePath:  statePath,
+		ReleaseAPI:  latestReleaseURL,
+		ReleasesAPI: releasesURL,
+		Channel:     ChannelStable,
+		PublicKey:   signingPublicKey,
+		UserAgent:   fmt.Sprintf("autopr/%s", currentVersion),
+		StatePath:   statePath,
 
 		ExecutablePath: os.Executable,
 	}
@@ -126,7 +151,7 @@ func Compare(currentVersion, latestVersion string) CheckResult {
 }
 
 func (m *Manager) Check(ctx context.Context, currentVersion string) (CheckResult, error) {
-	release, err := m.fetchLatestRelease(ctx)
+	release, err := m.fetchRelease(ctx)
 	if err != nil {
 		return CheckResult{}, err
 	}
@@ -134,7 +159,7 @@ func (m *Manager) Check(ctx context.Context, currentVersion string) (CheckResult
 }
 
 func (m *Manager) Upgrade(ctx context.Context, currentVersion string) (UpgradeResult, error) {
-	release, err := m.fetchLatestRelease(ctx)
+	release, err := m.fetchRelease(ctx)
 	if err != nil {
 		return UpgradeResult{}, err
 	}
@@ -149,11 +174,18 @@ func (m *Manager) Upgrade(ctx context.Context, currentVersion string) (UpgradeRe
 	if err != nil {
 		return UpgradeResult{}, err
 	}
-	checksumURL, err := selectChecksumsURL(release.Assets)
+	checksumURL, err := selectAssetURL(release.Assets, checksumsAsset)
+	if err != nil {
+		return UpgradeResult{}, err
+	}
+	checksums, err := m.fetchReleaseFile(ctx, checksumURL)
 	if err != nil {
+		return UpgradeResult{}, fmt.Errorf("download checksums: %w", err)
+	}
+	if err := m.verifyChecksumsSignature(ctx, release.Assets, checksums); err != nil {
 		return UpgradeResult{}, err
 	}
-	expectedChecksum, err := m.fetchExpectedChecksum(ctx, checksumURL, asset.Name)
+	expectedChecksum, err := checksumForAsset(string(checksums), asset.Name)
 	if err != nil {
 		return UpgradeResult{}, err
 	}
@@ -171,7 +203,7 @@ func (m *Manager) Upgrade(ctx context.Context, currentVersion string) (UpgradeRe
 }
 
 func (m *Manager) RefreshCache(ctx context.Context) (VersionCheckCache, error) {
-	release, err := m.fetchLatestRelease(ctx)
+	release, err := m.fetchRelease(ctx)
 	if err != nil {
 		return VersionCheckCache{}, err
 	}
@@ -257,13 +289,60 @@ func (m *Manager) IsCacheFresh(entry VersionCheckCache, ttl time.Duration) bool
 	return m.Now().Sub(entry.CheckedAt) <= ttl
 }
 
+// fetchRelease returns the newest release on the manager's channel.
+func (m *Manager) fetchRelease(ctx context.Context) (githubRelease, error) {
+	if m.Channel != ChannelBeta {
+		return m.fetchLatestRelease(ctx)
+	}
+	var releases []githubRelease
+	if err := m.getReleaseJSON(ctx, m.ReleasesAPI, "releases", &releases); err != nil {
+		return githubRelease{}, err
+	}
+	return newestRelease(releases)
+}
+
 func (m *Manager) fetchLatestRelease(ctx context.Context) (githubRelease, error) {
+	var rel githubRelease
+	if err := m.getReleaseJSON(ctx, m.ReleaseAPI, "latest release", &rel); err != nil {
+		return githubRelease{}, err
+	}
+	if strings.TrimSpace(rel.TagName) == "" {
+		return githubRelease{}, errors.New("latest release missing tag_name")
+	}
+	return rel, nil
+}
+
+// newestRelease picks the highest semver among published releases, including
+// prereleases. Tags that are not semver are ignored.
+func newestRelease(releases []githubRelease) (githubRelease, error) {
+	var best githubRelease
+	var bestSem semVersion
+	found := false
+	for _, rel := range releases {
+		if rel.Draft {
+			continue
+		}
+		sem, ok := parseSemver(rel.TagName)
+		if !ok {
+			continue
+		}
+		if !found || compareSemver(sem, bestSem) > 0 {
+			best, bestSem, found = rel, sem, true
+		}
+	}
+	if !found {
+		return githubRelease{}, errors.New("no published releases found")
+	}
+	return best, nil
+}
+
+func (m *Manager) getReleaseJSON(ctx context.Context, apiURL, what string, out any) error {
 	ctx, cancel := withTimeout(ctx, defaultReleaseRequestTimeout)
 	defer cancel()
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, m.ReleaseAPI, nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
 	if err != nil {
-		return githubRelease{}, fmt.Errorf("build release request: %w", err)
+		return fmt.Errorf("build release request: %w", err)
 	}
 	req.Header.Set("Accept", "application/vnd.github+json")
 	if m.UserAgent != "" {
@@ -275,7 +354,7 @@ func (m *Manager) fetchLatestRelease(ctx context.Context) (githubRelease, error)
 
 	resp, err := m.httpClient().Do(req)
 	if err != nil {
-		return githubRelease{}, fmt.Errorf("fetch latest release: %w", err)
+		return fmt.Errorf("fetch %s: %w", what, err)
 	}
 	defer resp.Body.Close()
 
@@ -285,17 +364,13 @@ func (m *Manager) fetchLatestRelease(ctx context.Context) (githubRelease, error)
 		if msg == "" {
 			msg = "unknown response"
 		}
-		return githubRelease{}, fmt.Errorf("fetch latest release: status %d: %s", resp.StatusCode, msg)
+		return fmt.Errorf("fetch %s: status %d: %s", what, resp.StatusCode, msg)
 	}
 
-	var rel githubRelease
-	if err := json.NewDecoder(resp.Body).Decode(&rel); err != nil {
-		return githubRelease{}, fmt.Errorf("decode latest release response: %w", err)
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decode %s response: %w", what, err)
 	}
-	if strings.TrimSpace(rel.TagName) == "" {
-		return githubRelease{}, errors.New("latest release missing tag_name")
-	}
-	return rel, nil
+	return nil
 }
 
 func selectAsset(assets []githubAsset, tag, osName, arch string) (githubAsset, error) {
@@ -324,22 +399,23 @@ func selectAsset(assets []githubAsset, tag, osName, arch string) (githubAsset, e
 	return githubAsset{}, fmt.Errorf("no release asset found for %s/%s", osName, arch)
 }
 
-func selectChecksumsURL(assets []githubAsset) (string, error) {
+func selectAssetURL(assets []githubAsset, name string) (string, error) {
 	for _, asset := range assets {
-		if asset.Name == "checksums.txt" && asset.URL != "" {
+		if asset.Name == name && asset.URL != "" {
 			return asset.URL, nil
 		}
 	}
-	return "", errors.New("release is missing checksums.txt asset")
+	return "", fmt.Errorf("release is missing %s asset", name)
 }
 
-func (m *Manager) fetchExpectedChecksum(ctx context.Context, checksumURL, assetName string) (string, error) {
+// fetchReleaseFile downloads a small release asset such as checksums.txt.
+func (m *Manager) fetchReleaseFile(ctx context.Context, fileURL string) ([]byte, error) {
 	ctx, cancel := withTimeout(ctx, defaultReleaseRequestTimeout)
 	defer cancel()
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, checksumURL, nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fileURL, nil)
 	if err != nil {
-		return "", fmt.Errorf("build checksum request: %w", err)
+		return nil, fmt.Errorf("build request: %w", err)
 	}
 	if m.UserAgent != "" {
 		req.Header.Set("User-Agent", m.UserAgent)
@@ -350,19 +426,43 @@ func (m *Manager) fetchExpectedChecksum(ctx context.Context, checksumURL, assetN
 
 	resp, err := m.httpClient().Do(req)
 	if err != nil {
-		return "", fmt.Errorf("download checksums: %w", err)
+		return nil, err
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("download checksums: status %d", resp.StatusCode)
+		return nil, fmt.Errorf("status %d", resp.StatusCode)
 	}
 
 	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
 	if err != nil {
-		return "", fmt.Errorf("read checksums: %w", err)
+		return nil, fmt.Errorf("read: %w", err)
 	}
+	return body, nil
+}
 
-	return checksumForAsset(string(body), assetName)
+// verifyChecksumsSignature checks checksums.txt against its Ed25519
+// signature (checksums.txt.sig, base64) when a public key is configured.
+func (m *Manager) verifyChecksumsSignature(ctx context.Context, assets []githubAsset, checksums []byte) error {
+	if strings.TrimSpace(m.PublicKey) == "" {
+		return nil
+	}
+	pub, err := base64.StdEncoding.DecodeString(strings.TrimSpace(m.PublicKey))
+	if err != nil || len(pub) != ed25519.PublicKeySize {
+		return errors.New("invalid release signing public key")
+	}
+	sigURL, err := selectAssetURL(assets, signatureAsset)
+	if err != nil {
+		return fmt.Errorf("release is not signed: %w", err)
+	}
+	sigText, err := m.fetchReleaseFile(ctx, sigURL)
+	if err != nil {
+		return fmt.Errorf("download signature: %w", err)
+	}
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(sigText)))
+	if err != nil || !ed25519.Verify(ed25519.PublicKey(pub), checksums, sig) {
+		return errors.New("release signature verification failed")
+	}
+	return nil
 }
 
 func checksumForAsset(text, assetName string) (string, error) {
@@ -529,6 +629,9 @@ func (m *Manager) replaceCurrentBinary(payload []byte, defaultMode os.FileMode)
 
 func canonicalVersion(v string) string {
 	if sem, ok := parseSemver(v); ok {
+		if sem.pre != "" {
+			return fmt.Sprintf("v%d.%d.%d-%s", sem.major, sem.minor, sem.patch, sem.pre)
+		}
 		return fmt.Sprintf("v%d.%d.%d", sem.major, sem.minor, sem.patch)
 	}
 	return strings.TrimSpace(v)
@@ -544,10 +647,10 @@ func parseSemver(v string) (semVersion, bool) {
 		return semVersion{}, false
 	}
 
-	core := v
-	if idx := strings.IndexAny(core, "+-"); idx >= 0 {
-		core = core[:idx]
+	if idx := strings.Index(v, "+"); idx >= 0 {
+		v = v[:idx] // build metadata does not affect precedence
 	}
+	core, pre, _ := strings.Cut(v, "-")
 	parts := strings.Split(core, ".")
 	if len(parts) != 3 {
 		return semVersion{}, false
@@ -564,7 +667,7 @@ func parseSemver(v string) (semVersion, bool) {
 	if err != nil {
 		return semVersion{}, false
 	}
-	return semVersion{major: major, minor: minor, patch: patch}, true
+	return semVersion{major: major, minor: minor, patch: patch, pre: pre}, true
 }
 
 func compareSemver(a, b semVersion) int {
@@ -586,6 +689,49 @@ func compareSemver(a, b semVersion) int {
 		}
 		return 1
 	}
+	return comparePrerelease(a.pre, b.pre)
+}
+
+// comparePrerelease orders prerelease strings by semver precedence: a release
+// (empty) outranks any prerelease, and dot-separated identifiers compare
+// numerically when both are numbers, otherwise lexically.
+func comparePrerelease(a, b string) int {
+	switch {
+	case a == b:
+		return 0
+	case a == "":
+		return 1
+	case b == "":
+		return -1
+	}
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		an, aErr := strconv.Atoi(as[i])
+		bn, bErr := strconv.Atoi(bs[i])
+		switch {
+		case aErr == nil && bErr == nil:
+			if an != bn {
+				if an < bn {
+					return -1
+				}
+				return 1
+			}
+		case aErr == nil:
+			return -1 // numeric identifiers sort before alphanumeric ones
+		case bErr == nil:
+			return 1
+		default:
+			if c := strings.Compare(as[i], bs[i]); c != 0 {
+				return c
+			}
+		}
+	}
+	switch {
+	case len(as) < len(bs):
+		return -1
+	case len(as) > len(bs):
+		return 1
+	}
 	return 0
 }
 
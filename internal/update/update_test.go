@@ -5,7 +5,9 @@ import (
 	"bytes"
 	"compress/gzip"
 	"context"
+	"crypto/ed25519"
 	"crypto/sha256"
+	"encoding/base64"
 	"fmt"
 	"io"
 	"net/http"
@@ -55,6 +57,24 @@ func TestCompare(t *testing.T) {
 			wantAvailable:  true,
 			wantComparable: false,
 		},
+		{
+			name:           "prerelease to final",
+			current:        "v0.3.0-beta.2",
+			latest:         "v0.3.0",
+			wantCurrent:    "v0.3.0-beta.2",
+			wantLatest:     "v0.3.0",
+			wantAvailable:  true,
+			wantComparable: true,
+		},
+		{
+			name:           "newer prerelease",
+			current:        "v0.3.0-beta.2",
+			latest:         "v0.3.0-beta.10",
+			wantCurrent:    "v0.3.0-beta.2",
+			wantLatest:     "v0.3.0-beta.10",
+			wantAvailable:  true,
+			wantComparable: true,
+		},
 		{
 			name:           "latest non-semver",
 			current:        "v0.3.0",
@@ -321,6 +341,116 @@ func TestUpgradeDownloadsAndReplacesBinary(t *testing.T) {
 	}
 }
 
+func TestFetchReleaseBetaChannelPicksNewestPrerelease(t *testing.T) {
+	t.Parallel()
+
+	client := &http.Client{
+		Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			if r.URL.String() != "https://api.github.com/repos/ashwath-ramesh/autopr/releases" {
+				return textResponse(http.StatusNotFound, "not found"), nil
+			}
+			return jsonResponse(http.StatusOK, `[{"tag_name":"v0.4.0-rc.1","draft":true,"prerelease":true},{"tag_name":"v0.3.0"},{"tag_name":"v0.4.0-beta.2","prerelease":true},{"tag_name":"v0.4.0-beta.10","prerelease":true}]`), nil
+		}),
+	}
+	mgr := &Manager{
+		Client:      client,
+		Now:         time.Now,
+		ReleasesAPI: "https://api.github.com/repos/ashwath-ramesh/autopr/releases",
+		Channel:     ChannelBeta,
+		UserAgent:   "autopr/test",
+	}
+
+	rel, err := mgr.fetchRelease(context.Background())
+	if err != nil {
+		t.Fatalf("fetch release: %v", err)
+	}
+	if rel.TagName != "v0.4.0-beta.10" {
+		t.Fatalf("expected newest non-draft prerelease, got %q", rel.TagName)
+	}
+}
+
+func TestUpgradeVerifiesChecksumsSignature(t *testing.T) {
+	t.Parallel()
+
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	archive := mustMakeTarGz(t, "ap", []byte("new-binary"), 0o755)
+	sum := sha256.Sum256(archive)
+	checksums := fmt.Sprintf("%x  ap_0.2.0_linux_amd64.tar.gz\n", sum)
+	goodSig := base64.StdEncoding.EncodeToString(ed25519.Sign(priv, []byte(checksums)))
+	badSig := base64.StdEncoding.EncodeToString(ed25519.Sign(priv, []byte("tampered")))
+
+	tests := []struct {
+		name    string
+		sig     string // empty means the release has no signature asset
+		wantErr string
+	}{
+		{name: "valid signature", sig: goodSig},
+		{name: "bad signature", sig: badSig, wantErr: "signature verification failed"},
+		{name: "unsigned release", wantErr: "release is not signed"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			exePath := filepath.Join(t.TempDir(), "ap")
+			if err := os.WriteFile(exePath, []byte("old-binary"), 0o755); err != nil {
+				t.Fatalf("write executable: %v", err)
+			}
+			assets := `{"name":"ap_0.2.0_linux_amd64.tar.gz","browser_download_url":"https://example.com/asset/ap.tar.gz"},{"name":"checksums.txt","browser_download_url":"https://example.com/asset/checksums.txt"}`
+			if tc.sig != "" {
+				assets += `,{"name":"checksums.txt.sig","browser_download_url":"https://example.com/asset/checksums.txt.sig"}`
+			}
+			client := &http.Client{
+				Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+					switch r.URL.String() {
+					case "https://api.github.com/repos/ashwath-ramesh/autopr/releases/latest":
+						return jsonResponse(http.StatusOK, `{"tag_name":"v0.2.0","assets":[`+assets+`]}`), nil
+					case "https://example.com/asset/checksums.txt":
+						return textResponse(http.StatusOK, checksums), nil
+					case "https://example.com/asset/checksums.txt.sig":
+						return textResponse(http.StatusOK, tc.sig), nil
+					case "https://example.com/asset/ap.tar.gz":
+						return binaryResponse(http.StatusOK, archive), nil
+					default:
+						return textResponse(http.StatusNotFound, "not found"), nil
+					}
+				}),
+			}
+			mgr := &Manager{
+				Client:         client,
+				Now:            time.Now,
+				OS:             "linux",
+				Arch:           "amd64",
+				ReleaseAPI:     "https://api.github.com/repos/ashwath-ramesh/autopr/releases/latest",
+				PublicKey:      base64.StdEncoding.EncodeToString(pub),
+				UserAgent:      "autopr/test",
+				ExecutablePath: func() (string, error) { return exePath, nil },
+			}
+
+			_, err := mgr.Upgrade(context.Background(), "0.1.0")
+			got, _ := os.ReadFile(exePath)
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Fatalf("upgrade: %v", err)
+				}
+				if string(got) != "new-binary" {
+					t.Fatalf("expected binary replaced, got %q", got)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Fatalf("expected error containing %q, got %v", tc.wantErr, err)
+			}
+			if string(got) != "old-binary" {
+				t.Fatalf("expected binary untouched after failed verification, got %q", got)
+			}
+		})
+	}
+}
+
 func TestDownloadAndExtractBinaryUsesLongerRequestTimeout(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,527 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"autopr/internal/config"
+	"autopr/internal/db"
+	"autopr/internal/queuewatch"
+	"autopr/internal/sysload"
+)
+
+// Health check statuses, from best to worst.
+const (
+	healthOK      = "ok"
+	healthUnknown = "unknown"
+	healthWarn    = "warn"
+	healthError   = "error"
+)
+
+const (
+	// diskWarnBytes and diskErrorBytes are the free-space thresholds for the
+	// filesystem holding repos_root, where worktrees are created.
+	diskWarnBytes  = 5 << 30
+	diskErrorBytes = 1 << 30
+	// syncStaleIntervals is how many sync intervals may pass without a
+	// successful sync before a project is reported stale.
+	syncStaleIntervals = 3
+	// notificationBacklogMaxAge is how long a notification may wait for
+	// delivery before the backlog is reported.
+	notificationBacklogMaxAge = 15 * time.Minute
+	// dbContentionWindow is how long after an operation gave up on a locked
+	// database the db check keeps warning.
+	dbContentionWindow = 15 * time.Minute
+)
+
+// WorkerStats reports worker pool utilization for the health endpoint.
+type WorkerStats interface {
+	Busy() int
+	Size() int
+	Running() bool // false while the daemon is idle
+}
+
+// SetWorkerPool makes the health endpoint report the pool's utilization.
+func (s *Server) SetWorkerPool(workers WorkerStats) {
+	s.workers = workers
+}
+
+// healthCheck is the common part of every subsystem check. AgeSeconds is how
+// old the underlying observation is; checks run on each request report 0.
+type healthCheck struct {
+	Status     string `json:"status"`
+	AgeSeconds int    `json:"age_seconds"`
+	Detail     string `json:"detail,omitempty"`
+}
+
+type healthReport struct {
+	DB            healthDBCheck            `json:"db"`
+	Sync          healthSyncCheck          `json:"sync"`
+	Workers       healthWorkersCheck       `json:"workers"`
+	Provider      healthProviderCheck      `json:"provider"`
+	Disk          healthDiskCheck          `json:"disk"`
+	Notifications healthNotificationsCheck `json:"notifications"`
+	Queue         healthQueueCheck         `json:"queue"`
+	Resources     healthResourcesCheck     `json:"resources"`
+}
+
+type healthDBCheck struct {
+	healthCheck
+	BusyRetries  int64 `json:"busy_retries"`
+	BusyFailures int64 `json:"busy_failures"`
+}
+
+type healthSyncCheck struct {
+	healthCheck
+	Projects []healthProjectSync `json:"projects"`
+}
+
+type healthProjectSync struct {
+	healthCheck
+	Project       string `json:"project"`
+	LastSuccessAt string `json:"last_success_at,omitempty"`
+	LastErrorAt   string `json:"last_error_at,omitempty"`
+	// LastDurationMS is how long the project's most recent sync took.
+	LastDurationMS int64 `json:"last_duration_ms"`
+}
+
+type healthWorkersCheck struct {
+	healthCheck
+	Busy        int     `json:"busy"`
+	Total       int     `json:"total"`
+	Utilization float64 `json:"utilization"`
+	// Idle is set while the daemon is in idle mode with its workers
+	// stopped; see [daemon] idle_after.
+	Idle bool `json:"idle"`
+}
+
+type healthProviderCheck struct {
+	healthCheck
+	Name string `json:"name,omitempty"`
+	Path string `json:"path,omitempty"`
+}
+
+type healthDiskCheck struct {
+	healthCheck
+	Path      string `json:"path,omitempty"`
+	FreeBytes uint64 `json:"free_bytes"`
+}
+
+type healthNotificationsCheck struct {
+	healthCheck
+	Pending  int `json:"pending"`
+	Retrying int `json:"retrying"`
+	Dead     int `json:"dead"`
+}
+
+type healthQueueCheck struct {
+	healthCheck
+	Stale     int    `json:"stale"`
+	OldestJob string `json:"oldest_job,omitempty"`
+}
+
+type healthResourcesCheck struct {
+	healthCheck
+	Load1             float64 `json:"load1"`
+	LoadPerCPU        float64 `json:"load_per_cpu"`
+	CPUs              int     `json:"cpus"`
+	MemTotalBytes     uint64  `json:"mem_total_bytes"`
+	MemAvailableBytes uint64  `json:"mem_available_bytes"`
+	// ClaimsHeld is set while the machine is past the [daemon] load or
+	// memory limit, so workers claim no new job.
+	ClaimsHeld bool `json:"claims_held"`
+}
+
+// healthRateLimit is the last API rate limit observed for a host.
+type healthRateLimit struct {
+	Host      string `json:"host"`
+	Resource  string `json:"resource,omitempty"`
+	Limit     int    `json:"limit"`
+	Remaining int    `json:"remaining"`
+	ResetAt   string `json:"reset_at,omitempty"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+// handleHealth reports daemon liveness plus a readiness report with one check
+// per subsystem. It answers 503 when any check is in error.
+// handleLive answers liveness probes: it succeeds whenever the daemon serves
+// HTTP, so an orchestrator restarts only a hung daemon, not one that /health
+// reports as not ready (e.g. a missing LLM CLI that a restart cannot fix).
+func (s *Server) handleLive(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"status":         "ok",
+		"uptime_seconds": max(int(time.Since(s.startedAt).Seconds()), 0),
+	})
+}
+
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	now := time.Now().UTC()
+
+	var report healthReport
+	jobQueueDepth := 0
+	rateLimits := []healthRateLimit{}
+
+	report.DB = s.checkDB(ctx, now)
+	if report.DB.Status != healthError {
+		var err error
+		if jobQueueDepth, err = s.queuedJobDepth(ctx); err != nil {
+			slog.Error("health: queued jobs count", "err", err)
+			report.DB.healthCheck = healthCheck{Status: healthError, Detail: "count queued jobs failed"}
+		}
+		limits, err := s.store.ListAPIRateLimits(ctx)
+		if err != nil {
+			slog.Error("health: list api rate limits", "err", err)
+			report.DB.healthCheck = healthCheck{Status: healthError, Detail: "list rate limits failed"}
+		}
+		for _, rl := range limits {
+			rateLimits = append(rateLimits, healthRateLimit{
+				Host:      rl.Host,
+				Resource:  rl.Resource,
+				Limit:     rl.Limit,
+				Remaining: rl.Remaining,
+				ResetAt:   rl.ResetAt,
+				UpdatedAt: rl.UpdatedAt,
+			})
+		}
+	}
+	if report.DB.Status != healthError {
+		report.Sync = s.checkSync(ctx, now)
+		report.Notifications = s.checkNotifications(ctx, now)
+		report.Queue = s.checkQueue(ctx, now)
+	} else {
+		unavailable := healthCheck{Status: healthUnknown, Detail: "database unavailable"}
+		report.Sync = healthSyncCheck{healthCheck: unavailable, Projects: []healthProjectSync{}}
+		report.Notifications = healthNotificationsCheck{healthCheck: unavailable}
+		report.Queue = healthQueueCheck{healthCheck: unavailable}
+	}
+	report.Workers = s.checkWorkers(jobQueueDepth)
+	report.Provider = s.checkProvider()
+	report.Disk = s.checkDisk()
+	report.Resources = s.checkResources()
+
+	ready := true
+	for _, status := range []string{report.DB.Status, report.Sync.Status, report.Workers.Status, report.Provider.Status, report.Disk.Status, report.Notifications.Status, report.Queue.Status, report.Resources.Status} {
+		if status == healthError {
+			ready = false
+		}
+	}
+	code := http.StatusOK
+	if !ready {
+		code = http.StatusServiceUnavailable
+	}
+
+	uptimeSeconds := max(int(time.Since(s.startedAt).Seconds()), 0)
+
+	writeJSON(w, code, map[string]any{
+		"status":          "running",
+		"ready":           ready,
+		"uptime_seconds":  uptimeSeconds,
+		"job_queue_depth": jobQueueDepth,
+		"rate_limits":     rateLimits,
+		"checks":          report,
+	})
+}
+
+// checkDB reports whether the database answers, with the daemon's lock
+// contention counts. It warns when a write gave up on a locked database
+// recently.
+func (s *Server) checkDB(ctx context.Context, now time.Time) healthDBCheck {
+	contention := s.store.Contention()
+	check := healthDBCheck{
+		healthCheck:  healthCheck{Status: healthOK},
+		BusyRetries:  contention.Retries,
+		BusyFailures: contention.Failures,
+	}
+	var one int
+	if err := s.store.Reader.QueryRowContext(ctx, `SELECT 1`).Scan(&one); err != nil {
+		slog.Error("health: database unreachable", "err", err)
+		check.healthCheck = healthCheck{Status: healthError, Detail: "database unreachable"}
+		return check
+	}
+	if !contention.LastFailAt.IsZero() && now.Sub(contention.LastFailAt) < dbContentionWindow {
+		check.Status = healthWarn
+		check.AgeSeconds = max(int(now.Sub(contention.LastFailAt).Seconds()), 0)
+		check.Detail = "a write failed on a locked database"
+	}
+	return check
+}
+
+// checkSync reports each configured project's last successful sync. A project
+// is stale when it has not synced successfully for syncStaleIntervals of its
+// sync intervals, and degraded while its most recent sync failed.
+func (s *Server) checkSync(ctx context.Context, now time.Time) healthSyncCheck {
+	check := healthSyncCheck{healthCheck: healthCheck{Status: healthOK}, Projects: []healthProjectSync{}}
+	interval, _ := time.ParseDuration(s.cfg.Daemon.SyncInterval)
+	if interval <= 0 {
+		check.Status = healthUnknown
+		check.Detail = "sync disabled"
+		return check
+	}
+
+	statuses, err := s.store.ListProjectSyncStatus(ctx)
+	if err != nil {
+		slog.Error("health: list project sync status", "err", err)
+		check.Status = healthUnknown
+		check.Detail = "sync status unavailable"
+		return check
+	}
+	byProject := make(map[string]db.ProjectSyncStatus, len(statuses))
+	for _, st := range statuses {
+		byProject[st.ProjectName] = st
+	}
+	overrides, err := s.store.ProjectEnabledOverrides(ctx)
+	if err != nil {
+		slog.Error("health: list project overrides", "err", err)
+	}
+
+	for _, p := range s.cfg.Projects {
+		st := byProject[p.Name]
+		ps := healthProjectSync{Project: p.Name, LastSuccessAt: st.LastSuccessAt, LastErrorAt: st.LastErrorAt, LastDurationMS: st.LastDuration.Milliseconds()}
+		if !s.cfg.ProjectEnabled(p.Name, overrides) {
+			ps.Status = healthOK
+			ps.Detail = "project disabled"
+			check.Projects = append(check.Projects, ps)
+			continue
+		}
+		staleAfter := syncStaleIntervals * s.cfg.SyncInterval(&p)
+		lastSuccess, hasSuccess := parseHealthTime(st.LastSuccessAt)
+		lastError, hasError := parseHealthTime(st.LastErrorAt)
+		switch {
+		case hasSuccess:
+			ps.AgeSeconds = ageSeconds(now, lastSuccess)
+		case time.Since(s.startedAt) > staleAfter:
+			ps.AgeSeconds = ageSeconds(now, s.startedAt)
+		}
+
+		switch {
+		case hasError && (!hasSuccess || lastError.After(lastSuccess)):
+			ps.Status = healthWarn
+			ps.Detail = "last sync failed: " + st.LastError
+		case !hasSuccess && time.Since(s.startedAt) > staleAfter:
+			ps.Status = healthWarn
+			ps.Detail = "no successful sync yet"
+		case !hasSuccess:
+			ps.Status = healthUnknown
+			ps.Detail = "waiting for first sync"
+		case now.Sub(lastSuccess) > staleAfter:
+			ps.Status = healthWarn
+			ps.Detail = fmt.Sprintf("no successful sync in %s", now.Sub(lastSuccess).Round(time.Second))
+		default:
+			ps.Status = healthOK
+		}
+
+		check.Projects = append(check.Projects, ps)
+		check.Status = worseHealth(check.Status, ps.Status)
+		check.AgeSeconds = max(check.AgeSeconds, ps.AgeSeconds)
+	}
+	return check
+}
+
+// checkWorkers reports worker pool utilization. The pool is saturated when
+// every worker is busy and jobs are waiting.
+func (s *Server) checkWorkers(queueDepth int) healthWorkersCheck {
+	if s.workers == nil || s.workers.Size() <= 0 {
+		return healthWorkersCheck{healthCheck: healthCheck{Status: healthUnknown, Detail: "worker pool not attached"}}
+	}
+	if !s.workers.Running() {
+		return healthWorkersCheck{
+			healthCheck: healthCheck{Status: healthOK, Detail: "daemon idle: workers stopped until a job is queued"},
+			Total:       s.workers.Size(),
+			Idle:        true,
+		}
+	}
+	busy, total := s.workers.Busy(), s.workers.Size()
+	check := healthWorkersCheck{
+		healthCheck: healthCheck{Status: healthOK},
+		Busy:        busy,
+		Total:       total,
+		Utilization: float64(busy) / float64(total),
+	}
+	if busy >= total && queueDepth > 0 {
+		check.Status = healthWarn
+		check.Detail = fmt.Sprintf("all workers busy, %d job(s) queued", queueDepth)
+	}
+	return check
+}
+
+func (s *Server) checkProvider() healthProviderCheck {
+	name := s.cfg.LLM.Provider
+	if name == "" {
+		return healthProviderCheck{healthCheck: healthCheck{Status: healthUnknown, Detail: "no provider configured"}}
+	}
+	if len(s.cfg.Projects) > 0 && !s.cfg.UsesExecutor(config.ExecutorLocal) {
+		// Every project runs the CLI in an executor image or on a build
+		// host, so it need not be installed next to the daemon.
+		return healthProviderCheck{
+			healthCheck: healthCheck{Status: healthOK, Detail: "runs in project executors"},
+			Name:        name,
+		}
+	}
+	path, err := s.lookPath(name)
+	if err != nil {
+		return healthProviderCheck{
+			healthCheck: healthCheck{Status: healthError, Detail: fmt.Sprintf("%s CLI not found in PATH", name)},
+			Name:        name,
+		}
+	}
+	return healthProviderCheck{healthCheck: healthCheck{Status: healthOK}, Name: name, Path: path}
+}
+
+// checkDisk reports free space on the filesystem holding repos_root. Before
+// the first clone repos_root may not exist yet, so its nearest existing
+// parent is measured instead.
+func (s *Server) checkDisk() healthDiskCheck {
+	if s.cfg.ReposRoot == "" {
+		return healthDiskCheck{healthCheck: healthCheck{Status: healthUnknown, Detail: "repos_root not configured"}}
+	}
+	path := existingParent(s.cfg.ReposRoot)
+	free, err := s.diskFree(path)
+	if err != nil {
+		return healthDiskCheck{healthCheck: healthCheck{Status: healthUnknown, Detail: err.Error()}, Path: path}
+	}
+	check := healthDiskCheck{healthCheck: healthCheck{Status: healthOK}, Path: path, FreeBytes: free}
+	switch {
+	case free < diskErrorBytes:
+		check.Status = healthError
+		check.Detail = fmt.Sprintf("only %d MiB free for worktrees", free>>20)
+	case free < diskWarnBytes:
+		check.Status = healthWarn
+		check.Detail = fmt.Sprintf("only %d MiB free for worktrees", free>>20)
+	}
+	return check
+}
+
+// checkResources reports the machine's load and memory. It warns while they
+// are past the [daemon] limits, which hold back job claims.
+func (s *Server) checkResources() healthResourcesCheck {
+	sample, err := s.readLoad()
+	if err != nil {
+		return healthResourcesCheck{healthCheck: healthCheck{Status: healthUnknown, Detail: err.Error()}}
+	}
+	check := healthResourcesCheck{
+		healthCheck:       healthCheck{Status: healthOK},
+		Load1:             sample.Load1,
+		LoadPerCPU:        sample.LoadPerCPU(),
+		CPUs:              sample.CPUs,
+		MemTotalBytes:     sample.MemTotal,
+		MemAvailableBytes: sample.MemAvailable,
+	}
+	if reason := sysload.LimitsFor(s.cfg).Exceeded(sample); reason != "" {
+		check.Status = healthWarn
+		check.Detail = "job claims held: " + reason
+		check.ClaimsHeld = true
+	}
+	return check
+}
+
+// checkNotifications reports the notification backlog. Its age is that of the
+// oldest notification still waiting for delivery.
+func (s *Server) checkNotifications(ctx context.Context, now time.Time) healthNotificationsCheck {
+	counts, err := s.store.CountNotificationEventsByStatus(ctx)
+	if err != nil {
+		slog.Error("health: count notification events", "err", err)
+		return healthNotificationsCheck{healthCheck: healthCheck{Status: healthUnknown, Detail: "notification backlog unavailable"}}
+	}
+	oldest, err := s.store.OldestUndeliveredNotificationAt(ctx)
+	if err != nil {
+		slog.Error("health: oldest undelivered notification", "err", err)
+		return healthNotificationsCheck{healthCheck: healthCheck{Status: healthUnknown, Detail: "notification backlog unavailable"}}
+	}
+
+	check := healthNotificationsCheck{
+		healthCheck: healthCheck{Status: healthOK},
+		Pending:     counts[db.NotificationStatusPending] + counts[db.NotificationStatusProcessing],
+		Retrying:    counts[db.NotificationStatusFailed],
+		Dead:        counts[db.NotificationStatusDead],
+	}
+	if t, ok := parseHealthTime(oldest); ok {
+		check.AgeSeconds = ageSeconds(now, t)
+	}
+	switch {
+	case check.Dead > 0:
+		check.Status = healthWarn
+		check.Detail = fmt.Sprintf("%d dead-lettered notification(s); see `ap notifications`", check.Dead)
+	case time.Duration(check.AgeSeconds)*time.Second > notificationBacklogMaxAge:
+		check.Status = healthWarn
+		check.Detail = "notifications are not being delivered"
+	}
+	return check
+}
+
+// checkQueue reports jobs queued longer than queue_stale_after. Its age is
+// that of the oldest such job and its detail says why that job waits.
+func (s *Server) checkQueue(ctx context.Context, now time.Time) healthQueueCheck {
+	if queuewatch.StaleAfter(s.cfg) <= 0 {
+		return healthQueueCheck{healthCheck: healthCheck{Status: healthUnknown, Detail: "queue alerts disabled"}}
+	}
+	stale, err := queuewatch.New(s.cfg, s.store).Stale(ctx)
+	if err != nil {
+		slog.Error("health: list stale queued jobs", "err", err)
+		return healthQueueCheck{healthCheck: healthCheck{Status: healthUnknown, Detail: "queue ages unavailable"}}
+	}
+	check := healthQueueCheck{healthCheck: healthCheck{Status: healthOK}, Stale: len(stale)}
+	if len(stale) > 0 {
+		oldest := stale[0]
+		check.Status = healthWarn
+		check.OldestJob = oldest.JobID
+		check.Detail = fmt.Sprintf("%d job(s) queued longer than %s; oldest: %s", len(stale), s.cfg.Daemon.QueueStaleAfter, strings.Join(oldest.Reasons, "; "))
+		if t, ok := parseHealthTime(oldest.QueuedAt); ok {
+			check.AgeSeconds = ageSeconds(now, t)
+		}
+	}
+	return check
+}
+
+func (s *Server) queuedJobDepth(ctx context.Context) (int, error) {
+	const q = `SELECT COUNT(*) FROM jobs WHERE state = 'queued'`
+	var count int
+	if err := s.store.Reader.QueryRowContext(ctx, q).Scan(&count); err != nil {
+		return 0, fmt.Errorf("count queued jobs: %w", err)
+	}
+	return count, nil
+}
+
+// worseHealth returns the worse of two statuses.
+func worseHealth(a, b string) string {
+	rank := map[string]int{healthOK: 0, healthUnknown: 1, healthWarn: 2, healthError: 3}
+	if rank[b] > rank[a] {
+		return b
+	}
+	return a
+}
+
+func parseHealthTime(value string) (time.Time, bool) {
+	if value == "" {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+func ageSeconds(now, t time.Time) int {
+	return max(int(now.Sub(t).Seconds()), 0)
+}
+
+// existingParent returns path or its nearest ancestor that exists.
+func existingParent(path string) string {
+	for {
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return path
+		}
+		path = parent
+	}
+}
@@ -0,0 +1,164 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"autopr/internal/config"
+	"autopr/internal/db"
+	"autopr/internal/sysload"
+)
+
+type stubWorkers struct {
+	busy, size int
+	idle       bool
+}
+
+func (w stubWorkers) Busy() int     { return w.busy }
+func (w stubWorkers) Size() int     { return w.size }
+func (w stubWorkers) Running() bool { return !w.idle }
+
+func TestHealthReportsSubsystemChecks(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	tmp := t.TempDir()
+	store, err := db.Open(filepath.Join(tmp, "autopr.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer store.Close()
+
+	queuedID := seedQueuedJob(t, ctx, store, "h-1")
+	if _, err := store.Writer.ExecContext(ctx, `UPDATE jobs SET updated_at = ? WHERE id = ?`,
+		time.Now().UTC().Add(-2*time.Hour).Format(time.RFC3339), queuedID); err != nil {
+		t.Fatalf("age queued job: %v", err)
+	}
+	if err := store.RecordProjectSync(ctx, "fresh", 3*time.Second, nil); err != nil {
+		t.Fatalf("record sync: %v", err)
+	}
+	if err := store.RecordProjectSync(ctx, "broken", time.Second, nil); err != nil {
+		t.Fatalf("record sync: %v", err)
+	}
+	if _, err := store.Writer.ExecContext(ctx, `UPDATE project_sync_status SET last_success_at = ? WHERE project_name = 'broken'`,
+		time.Now().UTC().Add(-time.Hour).Format(time.RFC3339)); err != nil {
+		t.Fatalf("age sync: %v", err)
+	}
+	if err := store.RecordProjectSync(ctx, "broken", time.Second, errors.New("github sync: HTTP 401")); err != nil {
+		t.Fatalf("record sync error: %v", err)
+	}
+
+	cfg := &config.Config{
+		ReposRoot: filepath.Join(tmp, "repos", "not-created-yet"),
+		Projects:  []config.ProjectConfig{{Name: "broken"}, {Name: "fresh"}},
+	}
+	cfg.Daemon.SyncInterval = "5m"
+	cfg.Daemon.QueueStaleAfter = "1h"
+	cfg.Daemon.MaxWorkers = 2
+	cfg.Daemon.MaxLoadPerCPU = 2
+	cfg.LLM.Provider = "codex"
+
+	srv := NewServer(cfg, store, make(chan string, 1))
+	srv.SetWorkerPool(stubWorkers{busy: 2, size: 2})
+	srv.lookPath = func(string) (string, error) { return "", errors.New("not found") }
+	var measured string
+	srv.diskFree = func(path string) (uint64, error) {
+		measured = path
+		return 3 << 30, nil
+	}
+	srv.readLoad = func() (sysload.Sample, error) {
+		return sysload.Sample{Load1: 10, CPUs: 4, MemTotal: 8 << 30, MemAvailable: 2 << 30}, nil
+	}
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 with provider missing, status=%d body=%s", rec.Code, rec.Body.String())
+	}
+
+	var got struct {
+		Status string       `json:"status"`
+		Ready  bool         `json:"ready"`
+		Checks healthReport `json:"checks"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if got.Status != "running" || got.Ready {
+		t.Fatalf("expected running but not ready, got status=%q ready=%v", got.Status, got.Ready)
+	}
+	c := got.Checks
+	if c.DB.Status != healthOK {
+		t.Fatalf("expected db ok, got %+v", c.DB)
+	}
+	if c.Sync.Status != healthWarn || len(c.Sync.Projects) != 2 {
+		t.Fatalf("expected sync warn for two projects, got %+v", c.Sync)
+	}
+	if p := c.Sync.Projects[0]; p.Project != "broken" || p.Status != healthWarn || p.AgeSeconds < 3500 {
+		t.Fatalf("expected broken project to warn with its last success age, got %+v", p)
+	}
+	if p := c.Sync.Projects[1]; p.Project != "fresh" || p.Status != healthOK || p.LastDurationMS != 3000 {
+		t.Fatalf("expected fresh project ok, got %+v", p)
+	}
+	if c.Workers.Status != healthWarn || c.Workers.Busy != 2 || c.Workers.Total != 2 || c.Workers.Utilization != 1 {
+		t.Fatalf("expected saturated workers, got %+v", c.Workers)
+	}
+	if c.Provider.Status != healthError || c.Provider.Name != "codex" {
+		t.Fatalf("expected missing provider error, got %+v", c.Provider)
+	}
+	if c.Disk.Status != healthWarn || c.Disk.FreeBytes != 3<<30 || measured != tmp {
+		t.Fatalf("expected low disk warning measured at %s, got %+v (measured %s)", tmp, c.Disk, measured)
+	}
+	if c.Notifications.Status != healthOK || c.Notifications.Pending != 0 {
+		t.Fatalf("expected empty notification backlog, got %+v", c.Notifications)
+	}
+	if c.Queue.Status != healthWarn || c.Queue.Stale != 1 || c.Queue.OldestJob != queuedID || c.Queue.AgeSeconds < 7100 {
+		t.Fatalf("expected stale queued job warning, got %+v", c.Queue)
+	}
+	if c.Resources.Status != healthWarn || !c.Resources.ClaimsHeld || c.Resources.LoadPerCPU != 2.5 || c.Resources.MemAvailableBytes != 2<<30 || c.Resources.Detail != "job claims held: load 2.50 per CPU is above 2.00" {
+		t.Fatalf("expected held claims on an overloaded machine, got %+v", c.Resources)
+	}
+}
+
+func TestHealthProviderCheckSkipsPATHWithoutLocalExecutor(t *testing.T) {
+	t.Parallel()
+
+	cfg := &config.Config{Projects: []config.ProjectConfig{
+		{Name: "a", Executor: config.ExecutorKubernetes},
+		{Name: "b", Executor: config.ExecutorDocker},
+	}}
+	cfg.LLM.Provider = "claude"
+	srv := NewServer(cfg, nil, make(chan string, 1))
+	srv.lookPath = func(string) (string, error) { return "", errors.New("not found") }
+
+	check := srv.checkProvider()
+	if check.Status != healthOK || check.Name != "claude" || check.Detail != "runs in project executors" {
+		t.Fatalf("expected provider ok without a local executor, got %+v", check)
+	}
+
+	// One local project needs the CLI in PATH again.
+	cfg.Projects = append(cfg.Projects, config.ProjectConfig{Name: "c"})
+	if check := srv.checkProvider(); check.Status != healthError {
+		t.Fatalf("expected provider error with a local project, got %+v", check)
+	}
+}
+
+func TestHealthWorkersCheckReportsIdle(t *testing.T) {
+	t.Parallel()
+
+	srv := NewServer(&config.Config{}, nil, make(chan string, 1))
+	srv.SetWorkerPool(stubWorkers{size: 3, idle: true})
+
+	// Jobs waiting on the channel are not a saturated pool while the
+	// workers are stopped; they wake the daemon.
+	check := srv.checkWorkers(2)
+	if check.Status != healthOK || !check.Idle || check.Total != 3 || check.Detail != "daemon idle: workers stopped until a job is queued" {
+		t.Fatalf("expected idle workers check, got %+v", check)
+	}
+}
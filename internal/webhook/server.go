@@ -1,7 +1,6 @@
 package webhook
 
 import (
-	"context"
 	"crypto/subtle"
 	"encoding/json"
 	"errors"
@@ -10,13 +9,17 @@ import (
 	"log/slog"
 	"net"
 	"net/http"
+	"os/exec"
 	"strings"
 	"sync"
 	"time"
 
+	"autopr/internal/chatops"
 	"autopr/internal/config"
 	"autopr/internal/db"
 	"autopr/internal/issuesync"
+	"autopr/internal/policy"
+	"autopr/internal/sysload"
 )
 
 const maxBodySize = 1 << 20 // 1MB
@@ -29,6 +32,12 @@ type Server struct {
 	mux       *http.ServeMux
 	startedAt time.Time
 
+	// Readiness report sources; see health.go.
+	workers  WorkerStats
+	lookPath func(file string) (string, error)
+	diskFree func(path string) (uint64, error)
+	readLoad func() (sysload.Sample, error)
+
 	// Simple rate limiter: per-IP request count per window.
 	mu         sync.Mutex
 	rates      map[string]int
@@ -41,11 +50,18 @@ func NewServer(cfg *config.Config, store *db.Store, jobCh chan<- string) *Server
 		store:     store,
 		jobCh:     jobCh,
 		startedAt: time.Now(),
+		lookPath:  exec.LookPath,
+		diskFree:  diskFree,
+		readLoad:  sysload.Read,
 		rates:     make(map[string]int),
 	}
 	mux := http.NewServeMux()
 	mux.HandleFunc("POST /webhook", s.handleWebhook)
 	mux.HandleFunc("GET /health", s.handleHealth)
+	mux.HandleFunc("GET /health/live", s.handleLive)
+	chat := chatops.New(cfg, store)
+	mux.HandleFunc("POST /chatops/slack", chat.ServeSlack)
+	mux.HandleFunc("POST /chatops/mattermost", chat.ServeMattermost)
 	s.mux = mux
 	return s
 }
@@ -54,32 +70,6 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	s.mux.ServeHTTP(w, r)
 }
 
-func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
-	jobQueueDepth, err := s.queuedJobDepth(r.Context())
-	if err != nil {
-		slog.Error("health: queued jobs count", "err", err)
-		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal error"})
-		return
-	}
-
-	uptimeSeconds := max(int(time.Since(s.startedAt).Seconds()), 0)
-
-	writeJSON(w, http.StatusOK, map[string]any{
-		"status":          "running",
-		"uptime_seconds":  uptimeSeconds,
-		"job_queue_depth": jobQueueDepth,
-	})
-}
-
-func (s *Server) queuedJobDepth(ctx context.Context) (int, error) {
-	const q = `SELECT COUNT(*) FROM jobs WHERE state = 'queued'`
-	var count int
-	if err := s.store.Reader.QueryRowContext(ctx, q).Scan(&count); err != nil {
-		return 0, fmt.Errorf("count queued jobs: %w", err)
-	}
-	return count, nil
-}
-
 func writeJSON(w http.ResponseWriter, status int, payload any) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
@@ -150,6 +140,11 @@ func (s *Server) handleWebhook(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusAccepted)
 		return
 	}
+	if overrides, err := s.store.ProjectEnabledOverrides(r.Context()); err == nil && !s.cfg.ProjectEnabled(projectCfg.Name, overrides) {
+		slog.Debug("webhook: project disabled", "project", projectCfg.Name)
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
 
 	// Skip self-created mirror issues.
 	if containsAPMarker(event.ObjectAttributes.Description) {
@@ -166,6 +161,11 @@ func (s *Server) handleWebhook(w http.ResponseWriter, r *http.Request) {
 
 	labels := event.Labels()
 	eligibility := issuesync.EvaluateIssueEligibility(includeLabels, excludeLabels, labels, time.Now().UTC())
+	eligibility = issuesync.RouteIssueEligibility(projectCfg, eligibility, labels)
+	eligibility = issuesync.PolicyIssueEligibility(projectCfg, eligibility, policy.Issue{
+		Source: "gitlab", ID: fmt.Sprintf("%d", event.ObjectAttributes.IID), Title: event.ObjectAttributes.Title,
+		Body: event.ObjectAttributes.Description, URL: event.ObjectAttributes.URL, Labels: labels,
+	})
 
 	// Upsert issue.
 	ctx := r.Context()
@@ -187,6 +187,7 @@ func (s *Server) handleWebhook(w http.ResponseWriter, r *http.Request) {
 		Eligible:      &eligible,
 		SkipReason:    eligibility.SkipReason,
 		EvaluatedAt:   eligibility.EvaluatedAt,
+		DueAt:         issuesync.DueAt(event.ObjectAttributes.DueDate),
 	})
 	if err != nil {
 		slog.Error("webhook: upsert issue", "err", err)
@@ -238,9 +239,19 @@ func (s *Server) handleWebhook(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusAccepted)
 		return
 	}
+	if epic, err := s.store.IsEpic(ctx, ffid); err == nil && epic {
+		slog.Debug("webhook: issue was split into task jobs, skipping", "ffid", ffid)
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+	if attempts, err := s.store.GetIssueAttempts(ctx, ffid); err == nil && attempts.Held() {
+		slog.Info("webhook: issue held by issue policy, skipping", "ffid", ffid, "reason", attempts.HoldReason)
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
 
 	// Create job.
-	jobID, err := s.store.CreateJob(ctx, ffid, projectCfg.Name, s.cfg.Daemon.MaxIterations)
+	jobID, err := policy.CreateJob(ctx, s.store, projectCfg, ffid, s.cfg.Daemon.MaxIterations)
 	if err != nil {
 		if errors.Is(err, db.ErrDuplicateActiveJob) {
 			slog.Debug("webhook: active job already exists, skipping", "ffid", ffid)
@@ -263,15 +274,26 @@ func (s *Server) handleWebhook(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusAccepted)
 }
 
+// findProject returns the configured project for the event's GitLab project.
+// When sub-projects of a monorepo share the GitLab project, the one whose
+// route labels the issue carries wins; otherwise the first match is returned
+// and its eligibility check records why the issue was skipped.
 func (s *Server) findProject(event gitlabIssueEvent) *config.ProjectConfig {
 	projectID := fmt.Sprintf("%d", event.Project.ID)
+	var first *config.ProjectConfig
 	for i := range s.cfg.Projects {
 		p := &s.cfg.Projects[i]
-		if p.GitLab != nil && p.GitLab.ProjectID == projectID {
+		if p.GitLab == nil || p.GitLab.ProjectID != projectID {
+			continue
+		}
+		if p.RoutesLabels(event.Labels()) {
 			return p
 		}
+		if first == nil {
+			first = p
+		}
 	}
-	return nil
+	return first
 }
 
 func containsAPMarker(desc string) bool {
@@ -300,6 +322,7 @@ type gitlabIssueAttrs struct {
 	URL         string `json:"url"`
 	Action      string `json:"action"`
 	State       string `json:"state"`
+	DueDate     string `json:"due_date"`
 }
 
 type gitlabProject struct {
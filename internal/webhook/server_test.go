@@ -39,6 +39,12 @@ func TestHealth_OK(t *testing.T) {
 	}
 	_ = seedQueuedJob(t, ctx, store, "queued-1")
 	_ = seedQueuedJob(t, ctx, store, "queued-2")
+	if err := store.UpsertAPIRateLimit(ctx, db.APIRateLimit{
+		Host: "api.github.com", Resource: "core", Limit: 5000, Remaining: 4200,
+		ResetAt: "2026-03-01T13:00:00Z", UpdatedAt: "2026-03-01T12:00:00Z",
+	}); err != nil {
+		t.Fatalf("seed rate limit: %v", err)
+	}
 
 	srv := NewServer(&config.Config{}, store, make(chan string, 1))
 	req := httptest.NewRequest(http.MethodGet, "/health", nil)
@@ -56,6 +62,11 @@ func TestHealth_OK(t *testing.T) {
 		Status        string `json:"status"`
 		UptimeSeconds int    `json:"uptime_seconds"`
 		JobQueueDepth int    `json:"job_queue_depth"`
+		RateLimits    []struct {
+			Host      string `json:"host"`
+			Remaining int    `json:"remaining"`
+			Limit     int    `json:"limit"`
+		} `json:"rate_limits"`
 	}
 	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
 		t.Fatalf("decode response: %v", err)
@@ -69,6 +80,32 @@ func TestHealth_OK(t *testing.T) {
 	if got.UptimeSeconds < 0 {
 		t.Fatalf("expected non-negative uptime, got %d", got.UptimeSeconds)
 	}
+	if len(got.RateLimits) != 1 || got.RateLimits[0].Host != "api.github.com" || got.RateLimits[0].Remaining != 4200 || got.RateLimits[0].Limit != 5000 {
+		t.Fatalf("unexpected rate_limits %+v", got.RateLimits)
+	}
+}
+
+func TestHealthLiveIgnoresSubsystemErrors(t *testing.T) {
+	t.Parallel()
+
+	store, err := db.Open(filepath.Join(t.TempDir(), "autopr.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer store.Writer.Close()
+
+	srv := NewServer(&config.Config{}, store, make(chan string, 1))
+	if err := store.Reader.Close(); err != nil {
+		t.Fatalf("close reader: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/health/live", nil)
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"status":"ok"`) {
+		t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+	}
 }
 
 func TestHealth_DBError(t *testing.T) {
@@ -89,19 +126,36 @@ func TestHealth_DBError(t *testing.T) {
 	rec := httptest.NewRecorder()
 	srv.ServeHTTP(rec, req)
 
-	if rec.Code != http.StatusInternalServerError {
+	if rec.Code != http.StatusServiceUnavailable {
 		t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
 	}
 	if !strings.HasPrefix(rec.Header().Get("Content-Type"), "application/json") {
 		t.Fatalf("unexpected content type %q", rec.Header().Get("Content-Type"))
 	}
 
-	var got map[string]string
+	var got struct {
+		Ready  bool `json:"ready"`
+		Checks struct {
+			DB struct {
+				Status string `json:"status"`
+				Detail string `json:"detail"`
+			} `json:"db"`
+			Sync struct {
+				Status string `json:"status"`
+			} `json:"sync"`
+		} `json:"checks"`
+	}
 	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
 		t.Fatalf("decode response: %v", err)
 	}
-	if got["error"] != "internal error" {
-		t.Fatalf("expected internal error payload, got %+v", got)
+	if got.Ready {
+		t.Fatal("expected not ready with database unreachable")
+	}
+	if got.Checks.DB.Status != "error" || got.Checks.DB.Detail != "database unreachable" {
+		t.Fatalf("expected db check error, got %+v", got.Checks.DB)
+	}
+	if got.Checks.Sync.Status != "unknown" {
+		t.Fatalf("expected sync check unknown without database, got %q", got.Checks.Sync.Status)
 	}
 }
 
@@ -402,6 +456,49 @@ func TestIssueHookLabelGateCreatesJobForLabeled(t *testing.T) {
 	}
 }
 
+func TestIssueHookRoutesToMonorepoSubProject(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	store, err := db.Open(filepath.Join(t.TempDir(), "autopr.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer store.Close()
+
+	subProject := func(name string) config.ProjectConfig {
+		return config.ProjectConfig{
+			Name:   name,
+			GitLab: &config.ProjectGitLab{ProjectID: "123", IncludeLabels: []string{"autopr"}},
+			Scope:  &config.ProjectScope{Path: "services/" + name, RouteLabels: []string{"area:" + name}},
+		}
+	}
+	cfg := &config.Config{
+		Daemon:   config.DaemonConfig{MaxIterations: 3},
+		Projects: []config.ProjectConfig{subProject("api"), subProject("web")},
+	}
+	jobCh := make(chan string, 1)
+	srv := NewServer(cfg, store, jobCh)
+
+	req := httptest.NewRequest(http.MethodPost, "/webhook", bytes.NewReader(issueHookPayloadWithLabels(t, 123, 52, "open", "opened", []string{"autopr", "area:web"})))
+	req.Header.Set("X-Gitlab-Event", "Issue Hook")
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+	}
+	select {
+	case <-jobCh:
+	default:
+		t.Fatalf("expected job on channel for routed webhook issue")
+	}
+	issue, err := store.GetIssueByAPID(ctx, getWebhookIssueID(t, ctx, store, "web", "gitlab", "52"))
+	if err != nil || !issue.Eligible {
+		t.Fatalf("expected eligible issue under web, got %+v (err %v)", issue, err)
+	}
+}
+
 func getWebhookIssueID(t *testing.T, ctx context.Context, store *db.Store, project, source, sourceIssueID string) string {
 	t.Helper()
 	var issueID string
@@ -2,35 +2,62 @@ package worker
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log/slog"
 	"runtime/debug"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"autopr/internal/config"
 	"autopr/internal/db"
 	"autopr/internal/pipeline"
+	"autopr/internal/sysload"
 )
 
 // Pool manages N worker goroutines that process jobs.
 type Pool struct {
 	n        int
+	cfg      *config.Config
 	store    *db.Store
 	pipeline *pipeline.Runner
 	jobCh    <-chan string
+	busy     atomic.Int32
 	wg       sync.WaitGroup
-	cancel   context.CancelFunc
+	claiming sync.WaitGroup // claims under way; see Pause
+
+	// Jobs are not claimed while the machine is past limits.
+	limits   sysload.Limits
+	readLoad func() (sysload.Sample, error)
+	mu       sync.Mutex
+	held     string             // why claims are held; "" while they are not
+	paused   bool               // no claims until Resume or Start
+	cancel   context.CancelFunc // stops the workers; nil while they are stopped
 }
 
-func NewPool(n int, store *db.Store, pipeline *pipeline.Runner, jobCh <-chan string) *Pool {
+func NewPool(n int, cfg *config.Config, store *db.Store, pipeline *pipeline.Runner, jobCh <-chan string) *Pool {
 	return &Pool{
 		n:        n,
+		cfg:      cfg,
 		store:    store,
 		pipeline: pipeline,
 		jobCh:    jobCh,
+		limits:   sysload.LimitsFor(cfg),
+		readLoad: sysload.Read,
 	}
 }
 
+// Start starts the workers. A pool stopped with Stop can be started again,
+// as the daemon does when it wakes from idle mode; starting a running pool
+// does nothing.
 func (p *Pool) Start(ctx context.Context) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.paused = false
+	if p.cancel != nil {
+		return
+	}
 	ctx, p.cancel = context.WithCancel(ctx)
 	for i := range p.n {
 		p.wg.Go(func() {
@@ -39,13 +66,82 @@ func (p *Pool) Start(ctx context.Context) {
 	}
 }
 
+// Pause stops the workers from claiming jobs and waits for the claims already
+// under way, so that once it returns Busy counts every job the pool holds.
+// Resume or Start lets them claim again.
+func (p *Pool) Pause() {
+	p.mu.Lock()
+	p.paused = true
+	p.mu.Unlock()
+	p.claiming.Wait()
+}
+
+// Resume lets paused workers claim jobs again.
+func (p *Pool) Resume() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.paused = false
+}
+
+// Stop stops the workers and waits for them to exit. It cancels the jobs they
+// are running, so a caller that must not interrupt one, such as idle mode,
+// pauses the pool first and stops it only when Busy is 0.
 func (p *Pool) Stop() {
-	if p.cancel != nil {
-		p.cancel()
+	p.mu.Lock()
+	cancel := p.cancel
+	p.cancel = nil
+	p.mu.Unlock()
+	if cancel != nil {
+		cancel()
 	}
 	p.wg.Wait()
 }
 
+// Running reports whether the workers are started.
+func (p *Pool) Running() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.cancel != nil
+}
+
+// Size returns the number of workers.
+func (p *Pool) Size() int { return p.n }
+
+// Busy returns the number of workers currently running a job.
+func (p *Pool) Busy() int { return int(p.busy.Load()) }
+
+// Held returns why workers are not claiming jobs, or "" when they are.
+func (p *Pool) Held() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.held
+}
+
+// admit reports whether the machine has room for another job under the
+// [daemon] load and memory limits, logging when claims stop and resume. A
+// machine that cannot be sampled is not held back.
+func (p *Pool) admit() bool {
+	if !p.limits.Enabled() {
+		return true
+	}
+	reason := ""
+	if sample, err := p.readLoad(); err == nil {
+		reason = p.limits.Exceeded(sample)
+	} else if !errors.Is(err, sysload.ErrUnsupported) {
+		slog.Warn("sample system load", "err", err)
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	switch {
+	case reason != "" && p.held == "":
+		slog.Warn("holding job claims: machine overloaded", "reason", reason)
+	case reason == "" && p.held != "":
+		slog.Info("resuming job claims")
+	}
+	p.held = reason
+	return reason == ""
+}
+
 func (p *Pool) worker(ctx context.Context, id int) {
 	slog.Debug("worker started", "id", id)
 
@@ -68,6 +164,43 @@ func (p *Pool) worker(ctx context.Context, id int) {
 	}
 }
 
+// claim claims the next job and counts it in Busy, returning "" when the pool
+// is paused, the machine is overloaded, or no job is queued.
+func (p *Pool) claim(ctx context.Context) string {
+	p.mu.Lock()
+	if p.paused {
+		p.mu.Unlock()
+		return ""
+	}
+	p.claiming.Add(1)
+	p.mu.Unlock()
+	defer p.claiming.Done()
+
+	// An overloaded machine takes no new job; the poll tries again.
+	if !p.admit() {
+		return ""
+	}
+
+	// Claim job atomically (the notified ID is a hint; we claim from DB).
+	// Projects take turns by queue_weight, and jobs of disabled projects stay
+	// queued until the project is enabled.
+	overrides, err := p.store.ProjectEnabledOverrides(ctx)
+	if err != nil {
+		slog.Error("claim job: load project overrides", "err", err)
+		return ""
+	}
+	jobID, err := p.store.ClaimJobWeighted(ctx, p.cfg.QueueWeights(), p.cfg.DisabledProjects(overrides)...)
+	if err != nil {
+		slog.Error("claim job failed", "err", err)
+		return ""
+	}
+	// "" means no queued job (another worker may have claimed it).
+	if jobID != "" {
+		p.busy.Add(1)
+	}
+	return jobID
+}
+
 func (p *Pool) processJob(ctx context.Context, workerID int, notifiedJobID string) {
 	// Panic recovery.
 	defer func() {
@@ -82,20 +215,19 @@ func (p *Pool) processJob(ctx context.Context, workerID int, notifiedJobID strin
 		}
 	}()
 
-	// Claim job atomically (the notified ID is a hint; we claim from DB).
-	jobID, err := p.store.ClaimJob(ctx)
-	if err != nil {
-		slog.Error("claim job failed", "err", err)
-		return
-	}
+	jobID := p.claim(ctx)
 	if jobID == "" {
-		// No queued job available (another worker may have claimed it).
 		return
 	}
-
+	defer p.busy.Add(-1)
 	slog.Info("worker processing job", "worker", workerID, "job", jobID)
 
-	if err := p.pipeline.Run(ctx, jobID); err != nil {
+	// Hold the job's lease while the pipeline runs so ap commands and the TUI
+	// cannot mutate it mid-step.
+	holder := db.LeaseHolder(fmt.Sprintf("daemon worker %d", workerID))
+	if err := p.store.WithJobLease(ctx, jobID, holder, func(ctx context.Context) error {
+		return p.pipeline.Run(ctx, jobID)
+	}); err != nil {
 		slog.Error("pipeline failed", "job", jobID, "err", err)
 	}
 }
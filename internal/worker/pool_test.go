@@ -0,0 +1,94 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"autopr/internal/config"
+	"autopr/internal/db"
+	"autopr/internal/sysload"
+)
+
+func TestAdmitHoldsClaimsWhileOverloaded(t *testing.T) {
+	t.Parallel()
+
+	sample := sysload.Sample{Load1: 2, CPUs: 4, MemTotal: 8 << 30, MemAvailable: 4 << 30}
+	var readErr error
+	p := &Pool{
+		limits:   sysload.Limits{MaxLoadPerCPU: 1.5, MinFreeMemory: 1 << 30},
+		readLoad: func() (sysload.Sample, error) { return sample, readErr },
+	}
+	if !p.admit() || p.Held() != "" {
+		t.Fatalf("expected claims on an idle machine, held %q", p.Held())
+	}
+
+	sample.MemAvailable = 512 << 20
+	if p.admit() || p.Held() != "512 MiB of memory available, below 1024 MiB" {
+		t.Fatalf("expected claims held on low memory, held %q", p.Held())
+	}
+
+	// A machine that cannot be sampled is not held back.
+	readErr = errors.New("read load average: permission denied")
+	if !p.admit() || p.Held() != "" {
+		t.Fatalf("expected claims without a sample, held %q", p.Held())
+	}
+
+	// Without limits the machine is not sampled at all.
+	p.limits = sysload.Limits{}
+	p.readLoad = func() (sysload.Sample, error) { t.Fatal("sampled without limits"); return sysload.Sample{}, nil }
+	if !p.admit() {
+		t.Fatal("expected claims without limits")
+	}
+}
+
+func TestPoolRestartsAfterStop(t *testing.T) {
+	t.Parallel()
+
+	p := NewPool(2, &config.Config{}, nil, nil, make(chan string))
+	p.Start(context.Background())
+	p.Start(context.Background()) // already running: no second set of workers
+	if !p.Running() {
+		t.Fatal("expected workers running after Start")
+	}
+	p.Stop()
+	if p.Running() {
+		t.Fatal("expected workers stopped after Stop")
+	}
+	p.Start(context.Background())
+	if !p.Running() {
+		t.Fatal("expected workers running again after a restart")
+	}
+	p.Stop()
+	p.Stop()
+}
+
+func TestPausedPoolClaimsNothing(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	store, err := db.Open(filepath.Join(t.TempDir(), "autopr.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer store.Close()
+	issueID, err := store.UpsertIssue(ctx, db.IssueUpsert{ProjectName: "myproject", Source: "github", SourceIssueID: "1", Title: "bug", State: "open"})
+	if err != nil {
+		t.Fatalf("upsert issue: %v", err)
+	}
+	jobID, err := store.CreateJob(ctx, issueID, "myproject", 3)
+	if err != nil {
+		t.Fatalf("create job: %v", err)
+	}
+
+	p := NewPool(1, &config.Config{}, store, nil, make(chan string))
+	p.Pause()
+	if got := p.claim(ctx); got != "" {
+		t.Fatalf("expected no claim while paused, got %s", got)
+	}
+	p.Resume()
+	if got := p.claim(ctx); got != jobID || p.Busy() != 1 {
+		t.Fatalf("expected %s claimed and counted after Resume, got %q busy %d", jobID, got, p.Busy())
+	}
+}